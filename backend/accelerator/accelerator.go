@@ -0,0 +1,63 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package accelerator defines the interface through which the Groth16 and PLONK provers
+// delegate their multi-scalar multiplications (MSM) and FFTs, so that they can run on a
+// GPU (CUDA, Metal, ...) without modifying the provers.
+//
+// An Accelerator is set with backend.WithAccelerator. The provers call it with the types of
+// gnark-crypto for the curve of the constraint system; for example on ecc.BN254:
+//
+//	MultiExpG1(*bn254.G1Jac, []bn254.G1Affine, []fr.Element, ecc.MultiExpConfig)
+//	MultiExpG2(*bn254.G2Jac, []bn254.G2Affine, []fr.Element, ecc.MultiExpConfig)
+//	FFT(*fft.Domain, []fr.Element, Decimation, bool)
+//	FFTInverse(*fft.Domain, []fr.Element, Decimation, bool)
+//
+// where bn254, fr and fft are the packages ecc/bn254, ecc/bn254/fr and ecc/bn254/fr/fft.
+// The methods have the semantics of their gnark-crypto counterparts. CPU is the reference
+// implementation; a GPU implementation may embed it to fall back on it for the operations or
+// curves it doesn't support.
+//
+// The KZG openings of PLONK are computed by gnark-crypto and are not delegated.
+package accelerator
+
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// Decimation selects decimation in time or in frequency in FFT and FFTInverse.
+// Its values are the ones of fft.Decimation in gnark-crypto.
+type Decimation uint8
+
+const (
+	DIT Decimation = iota
+	DIF
+)
+
+// Accelerator computes the MSMs and FFTs of the provers (see package documentation for
+// the types of the parameters)
+type Accelerator interface {
+	// MultiExpG1 sets res to the multi exponentiation of points and scalars in G1
+	MultiExpG1(res, points, scalars interface{}, config ecc.MultiExpConfig) error
+
+	// MultiExpG2 sets res to the multi exponentiation of points and scalars in G2
+	MultiExpG2(res, points, scalars interface{}, config ecc.MultiExpConfig) error
+
+	// FFT evaluates in place the polynomial a on the domain, or on its coset if coset is set
+	FFT(domain, a interface{}, decimation Decimation, coset bool)
+
+	// FFTInverse interpolates in place the evaluations a on the domain, or on its coset
+	// if coset is set
+	FFTInverse(domain, a interface{}, decimation Decimation, coset bool)
+}
@@ -0,0 +1,127 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+package accelerator_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/accelerator"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+type cubicCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *cubicCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X, circuit.X), circuit.Y)
+	return nil
+}
+
+// countingAccelerator counts the calls to the reference implementation
+type countingAccelerator struct {
+	accelerator.CPU
+	nbMultiExpG1, nbMultiExpG2, nbFFT, nbFFTInverse int64
+}
+
+func (a *countingAccelerator) MultiExpG1(res, points, scalars interface{}, config ecc.MultiExpConfig) error {
+	atomic.AddInt64(&a.nbMultiExpG1, 1)
+	return a.CPU.MultiExpG1(res, points, scalars, config)
+}
+
+func (a *countingAccelerator) MultiExpG2(res, points, scalars interface{}, config ecc.MultiExpConfig) error {
+	atomic.AddInt64(&a.nbMultiExpG2, 1)
+	return a.CPU.MultiExpG2(res, points, scalars, config)
+}
+
+func (a *countingAccelerator) FFT(domain, p interface{}, decimation accelerator.Decimation, coset bool) {
+	atomic.AddInt64(&a.nbFFT, 1)
+	a.CPU.FFT(domain, p, decimation, coset)
+}
+
+func (a *countingAccelerator) FFTInverse(domain, p interface{}, decimation accelerator.Decimation, coset bool) {
+	atomic.AddInt64(&a.nbFFTInverse, 1)
+	a.CPU.FFTInverse(domain, p, decimation, coset)
+}
+
+var curves = []ecc.ID{ecc.BN254, ecc.BLS12_377, ecc.BLS12_381, ecc.BLS24_315, ecc.BW6_633, ecc.BW6_761}
+
+func TestGroth16(t *testing.T) {
+	for _, curveID := range curves {
+		t.Run(curveID.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			ccs, err := frontend.Compile(curveID, r1cs.NewBuilder, &cubicCircuit{})
+			assert.NoError(err)
+			pk, vk, err := groth16.Setup(ccs)
+			assert.NoError(err)
+			fullWitness, err := frontend.NewWitness(&cubicCircuit{X: 3, Y: 27}, curveID)
+			assert.NoError(err)
+			publicWitness, err := fullWitness.Public()
+			assert.NoError(err)
+
+			acc := &countingAccelerator{}
+			proof, err := groth16.Prove(ccs, pk, fullWitness, backend.WithAccelerator(acc))
+			assert.NoError(err)
+			assert.NoError(groth16.Verify(proof, vk, publicWitness))
+
+			// A, B, K and Z in G1, B in G2; 3 FFTs for a, b, c and 4 inverse FFTs for a, b, c and h
+			assert.EqualValues(4, acc.nbMultiExpG1)
+			assert.EqualValues(1, acc.nbMultiExpG2)
+			assert.EqualValues(3, acc.nbFFT)
+			assert.EqualValues(4, acc.nbFFTInverse)
+		})
+	}
+}
+
+func TestPlonk(t *testing.T) {
+	for _, curveID := range curves {
+		t.Run(curveID.String(), func(t *testing.T) {
+			if testing.Short() && (curveID == ecc.BW6_633 || curveID == ecc.BW6_761) {
+				t.Skip("skipping slow kzg srs generation in short mode")
+			}
+			assert := require.New(t)
+
+			ccs, err := frontend.Compile(curveID, scs.NewBuilder, &cubicCircuit{})
+			assert.NoError(err)
+			srs, err := test.NewKZGSRS(ccs)
+			assert.NoError(err)
+			pk, vk, err := plonk.Setup(ccs, srs)
+			assert.NoError(err)
+			fullWitness, err := frontend.NewWitness(&cubicCircuit{X: 3, Y: 27}, curveID)
+			assert.NoError(err)
+			publicWitness, err := fullWitness.Public()
+			assert.NoError(err)
+
+			acc := &countingAccelerator{}
+			proof, err := plonk.Prove(ccs, pk, fullWitness, backend.WithAccelerator(acc))
+			assert.NoError(err)
+			assert.NoError(plonk.Verify(proof, vk, publicWitness))
+
+			// commitments to l, r, o, z, h1, h2, h3 and the linearized polynomial;
+			// l, r, o, z, ql, qr, qm, qo, qk and L₁ are evaluated on the big domain,
+			// qk, l, r, o, z and h are interpolated
+			assert.EqualValues(8, acc.nbMultiExpG1)
+			assert.EqualValues(0, acc.nbMultiExpG2)
+			assert.EqualValues(10, acc.nbFFT)
+			assert.EqualValues(6, acc.nbFFTInverse)
+		})
+	}
+}
+
+func TestUnsupportedTypes(t *testing.T) {
+	var res int
+	err := accelerator.CPU{}.MultiExpG1(&res, nil, nil, ecc.MultiExpConfig{})
+	require.Error(t, err)
+}
@@ -0,0 +1,189 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accelerator
+
+import (
+	"errors"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12_377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	fr_bls12_377 "github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	fft_bls12_377 "github.com/consensys/gnark-crypto/ecc/bls12-377/fr/fft"
+	bls12_381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	fr_bls12_381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	fft_bls12_381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr/fft"
+	bls24_315 "github.com/consensys/gnark-crypto/ecc/bls24-315"
+	fr_bls24_315 "github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+	fft_bls24_315 "github.com/consensys/gnark-crypto/ecc/bls24-315/fr/fft"
+	bn254 "github.com/consensys/gnark-crypto/ecc/bn254"
+	fr_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	fft_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	bw6_633 "github.com/consensys/gnark-crypto/ecc/bw6-633"
+	fr_bw6_633 "github.com/consensys/gnark-crypto/ecc/bw6-633/fr"
+	fft_bw6_633 "github.com/consensys/gnark-crypto/ecc/bw6-633/fr/fft"
+	bw6_761 "github.com/consensys/gnark-crypto/ecc/bw6-761"
+	fr_bw6_761 "github.com/consensys/gnark-crypto/ecc/bw6-761/fr"
+	fft_bw6_761 "github.com/consensys/gnark-crypto/ecc/bw6-761/fr/fft"
+)
+
+var errUnsupportedTypes = errors.New("accelerator: unsupported or mismatched types")
+
+// CPU is the reference Accelerator, computing the MSMs and FFTs with gnark-crypto.
+// Its FFT and FFTInverse panic if the types of their parameters are not supported.
+type CPU struct{}
+
+// MultiExpG1 implements Accelerator
+func (CPU) MultiExpG1(res, points, scalars interface{}, config ecc.MultiExpConfig) error {
+	var err error
+	switch res := res.(type) {
+	case *bls12_377.G1Jac:
+		p, ok1 := points.([]bls12_377.G1Affine)
+		s, ok2 := scalars.([]fr_bls12_377.Element)
+		if !ok1 || !ok2 {
+			return errUnsupportedTypes
+		}
+		_, err = res.MultiExp(p, s, config)
+	case *bls12_381.G1Jac:
+		p, ok1 := points.([]bls12_381.G1Affine)
+		s, ok2 := scalars.([]fr_bls12_381.Element)
+		if !ok1 || !ok2 {
+			return errUnsupportedTypes
+		}
+		_, err = res.MultiExp(p, s, config)
+	case *bls24_315.G1Jac:
+		p, ok1 := points.([]bls24_315.G1Affine)
+		s, ok2 := scalars.([]fr_bls24_315.Element)
+		if !ok1 || !ok2 {
+			return errUnsupportedTypes
+		}
+		_, err = res.MultiExp(p, s, config)
+	case *bn254.G1Jac:
+		p, ok1 := points.([]bn254.G1Affine)
+		s, ok2 := scalars.([]fr_bn254.Element)
+		if !ok1 || !ok2 {
+			return errUnsupportedTypes
+		}
+		_, err = res.MultiExp(p, s, config)
+	case *bw6_633.G1Jac:
+		p, ok1 := points.([]bw6_633.G1Affine)
+		s, ok2 := scalars.([]fr_bw6_633.Element)
+		if !ok1 || !ok2 {
+			return errUnsupportedTypes
+		}
+		_, err = res.MultiExp(p, s, config)
+	case *bw6_761.G1Jac:
+		p, ok1 := points.([]bw6_761.G1Affine)
+		s, ok2 := scalars.([]fr_bw6_761.Element)
+		if !ok1 || !ok2 {
+			return errUnsupportedTypes
+		}
+		_, err = res.MultiExp(p, s, config)
+	default:
+		return errUnsupportedTypes
+	}
+	return err
+}
+
+// MultiExpG2 implements Accelerator
+func (CPU) MultiExpG2(res, points, scalars interface{}, config ecc.MultiExpConfig) error {
+	var err error
+	switch res := res.(type) {
+	case *bls12_377.G2Jac:
+		p, ok1 := points.([]bls12_377.G2Affine)
+		s, ok2 := scalars.([]fr_bls12_377.Element)
+		if !ok1 || !ok2 {
+			return errUnsupportedTypes
+		}
+		_, err = res.MultiExp(p, s, config)
+	case *bls12_381.G2Jac:
+		p, ok1 := points.([]bls12_381.G2Affine)
+		s, ok2 := scalars.([]fr_bls12_381.Element)
+		if !ok1 || !ok2 {
+			return errUnsupportedTypes
+		}
+		_, err = res.MultiExp(p, s, config)
+	case *bls24_315.G2Jac:
+		p, ok1 := points.([]bls24_315.G2Affine)
+		s, ok2 := scalars.([]fr_bls24_315.Element)
+		if !ok1 || !ok2 {
+			return errUnsupportedTypes
+		}
+		_, err = res.MultiExp(p, s, config)
+	case *bn254.G2Jac:
+		p, ok1 := points.([]bn254.G2Affine)
+		s, ok2 := scalars.([]fr_bn254.Element)
+		if !ok1 || !ok2 {
+			return errUnsupportedTypes
+		}
+		_, err = res.MultiExp(p, s, config)
+	case *bw6_633.G2Jac:
+		p, ok1 := points.([]bw6_633.G2Affine)
+		s, ok2 := scalars.([]fr_bw6_633.Element)
+		if !ok1 || !ok2 {
+			return errUnsupportedTypes
+		}
+		_, err = res.MultiExp(p, s, config)
+	case *bw6_761.G2Jac:
+		p, ok1 := points.([]bw6_761.G2Affine)
+		s, ok2 := scalars.([]fr_bw6_761.Element)
+		if !ok1 || !ok2 {
+			return errUnsupportedTypes
+		}
+		_, err = res.MultiExp(p, s, config)
+	default:
+		return errUnsupportedTypes
+	}
+	return err
+}
+
+// FFT implements Accelerator
+func (CPU) FFT(domain, a interface{}, decimation Decimation, coset bool) {
+	switch domain := domain.(type) {
+	case *fft_bls12_377.Domain:
+		domain.FFT(a.([]fr_bls12_377.Element), fft_bls12_377.Decimation(decimation), coset)
+	case *fft_bls12_381.Domain:
+		domain.FFT(a.([]fr_bls12_381.Element), fft_bls12_381.Decimation(decimation), coset)
+	case *fft_bls24_315.Domain:
+		domain.FFT(a.([]fr_bls24_315.Element), fft_bls24_315.Decimation(decimation), coset)
+	case *fft_bn254.Domain:
+		domain.FFT(a.([]fr_bn254.Element), fft_bn254.Decimation(decimation), coset)
+	case *fft_bw6_633.Domain:
+		domain.FFT(a.([]fr_bw6_633.Element), fft_bw6_633.Decimation(decimation), coset)
+	case *fft_bw6_761.Domain:
+		domain.FFT(a.([]fr_bw6_761.Element), fft_bw6_761.Decimation(decimation), coset)
+	default:
+		panic(errUnsupportedTypes)
+	}
+}
+
+// FFTInverse implements Accelerator
+func (CPU) FFTInverse(domain, a interface{}, decimation Decimation, coset bool) {
+	switch domain := domain.(type) {
+	case *fft_bls12_377.Domain:
+		domain.FFTInverse(a.([]fr_bls12_377.Element), fft_bls12_377.Decimation(decimation), coset)
+	case *fft_bls12_381.Domain:
+		domain.FFTInverse(a.([]fr_bls12_381.Element), fft_bls12_381.Decimation(decimation), coset)
+	case *fft_bls24_315.Domain:
+		domain.FFTInverse(a.([]fr_bls24_315.Element), fft_bls24_315.Decimation(decimation), coset)
+	case *fft_bn254.Domain:
+		domain.FFTInverse(a.([]fr_bn254.Element), fft_bn254.Decimation(decimation), coset)
+	case *fft_bw6_633.Domain:
+		domain.FFTInverse(a.([]fr_bw6_633.Element), fft_bw6_633.Decimation(decimation), coset)
+	case *fft_bw6_761.Domain:
+		domain.FFTInverse(a.([]fr_bw6_761.Element), fft_bw6_761.Decimation(decimation), coset)
+	default:
+		panic(errUnsupportedTypes)
+	}
+}
@@ -0,0 +1,410 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package circom imports the constraint systems (.r1cs files) and witnesses
+// (.wtns files) generated by circom (https://github.com/iden3/circom), to
+// prove them with the backends of gnark.
+//
+// The wires of circom keep their IDs: the wire 0 is the constant one, the
+// public outputs and public inputs are the public inputs of gnark, and the
+// private inputs and all the other wires, which are computed by the witness
+// generator of circom, are the secret inputs of gnark. As a result, the
+// constraint systems don't need any hint, but must be proved with the full
+// witness of a .wtns file.
+//
+// For example, to prove a circom circuit with Groth16:
+//
+//	r1cs, _ := circom.ReadR1CS(r1csFile)
+//	ccs, _ := r1cs.Compile(ecc.BN254)
+//	values, _ := circom.ReadWitness(wtnsFile)
+//	witness, _ := r1cs.NewWitness(values, ecc.BN254)
+//	pk, vk, _ := groth16.Setup(ccs)
+//	proof, _ := groth16.Prove(ccs, pk, witness)
+//
+// and with PlonK, the R1CS is compiled through the frontend:
+//
+//	ccs, _ := frontend.Compile(ecc.BN254, scs.NewBuilder, r1cs.Circuit())
+package circom
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/compiled"
+	"github.com/consensys/gnark/frontend/cs"
+	"github.com/consensys/gnark/frontend/schema"
+
+	backend_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/cs"
+	backend_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/cs"
+	backend_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/cs"
+	backend_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
+	backend_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/cs"
+	backend_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/cs"
+)
+
+// versions and section types of the .r1cs and .wtns files
+const (
+	r1csVersion            = 1
+	sectionR1CSHeader      = 1
+	sectionR1CSConstraints = 2
+
+	wtnsVersion          = 2
+	sectionWitnessHeader = 1
+	sectionWitnessValues = 2
+)
+
+var errInvalidFile = errors.New("invalid circom file")
+
+// R1CS is a constraint system read from a circom .r1cs file
+type R1CS struct {
+	Prime *big.Int // modulus of the field
+
+	NbWires         int // including the constant one
+	NbPublicOutputs int
+	NbPublicInputs  int
+	NbPrivateInputs int
+	Constraints     []Constraint
+}
+
+// Constraint is the rank-1 constraint A⋅B == C
+type Constraint struct {
+	A, B, C []Term
+}
+
+// Term is a term Coeff⋅wire of a linear combination
+type Term struct {
+	Wire  int
+	Coeff big.Int
+}
+
+// NbPublic returns the number of public inputs of the gnark circuit (without
+// the constant one)
+func (r1cs *R1CS) NbPublic() int {
+	return r1cs.NbPublicOutputs + r1cs.NbPublicInputs
+}
+
+// ReadR1CS reads a circom .r1cs file
+func ReadR1CS(r io.Reader) (*R1CS, error) {
+	sections, err := readSections(r, "r1cs", r1csVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	header, ok := sections[sectionR1CSHeader]
+	if !ok {
+		return nil, fmt.Errorf("%w: missing header", errInvalidFile)
+	}
+	d := &decoder{buf: header}
+	res := &R1CS{}
+	fieldSize := int(d.uint32())
+	res.Prime = d.element(fieldSize)
+	res.NbWires = int(d.uint32())
+	res.NbPublicOutputs = int(d.uint32())
+	res.NbPublicInputs = int(d.uint32())
+	res.NbPrivateInputs = int(d.uint32())
+	_ = d.uint64() // number of labels
+	nbConstraints := int(d.uint32())
+	if d.err != nil {
+		return nil, d.err
+	}
+	if res.NbWires < 1+res.NbPublic()+res.NbPrivateInputs {
+		return nil, fmt.Errorf("%w: %d wires for %d inputs", errInvalidFile, res.NbWires, res.NbPublic()+res.NbPrivateInputs)
+	}
+
+	d = &decoder{buf: sections[sectionR1CSConstraints]}
+	for i := 0; i < nbConstraints && d.err == nil; i++ {
+		var c Constraint
+		for _, lc := range []*[]Term{&c.A, &c.B, &c.C} {
+			nbTerms := int(d.uint32())
+			for j := 0; j < nbTerms && d.err == nil; j++ {
+				var t Term
+				t.Wire = int(d.uint32())
+				t.Coeff.Set(d.element(fieldSize))
+				if t.Wire >= res.NbWires {
+					return nil, fmt.Errorf("%w: constraint %d: wire %d out of range", errInvalidFile, i, t.Wire)
+				}
+				*lc = append(*lc, t)
+			}
+		}
+		res.Constraints = append(res.Constraints, c)
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return res, nil
+}
+
+// ReadWitness reads the values of all the wires from a circom .wtns file
+func ReadWitness(r io.Reader) ([]big.Int, error) {
+	sections, err := readSections(r, "wtns", wtnsVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	header, ok := sections[sectionWitnessHeader]
+	if !ok {
+		return nil, fmt.Errorf("%w: missing header", errInvalidFile)
+	}
+	d := &decoder{buf: header}
+	fieldSize := int(d.uint32())
+	_ = d.element(fieldSize) // prime, checked against the R1CS by NewWitness
+	nbValues := int(d.uint32())
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	d = &decoder{buf: sections[sectionWitnessValues]}
+	var res []big.Int
+	for i := 0; i < nbValues && d.err == nil; i++ {
+		res = append(res, *d.element(fieldSize))
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return res, nil
+}
+
+// Compile returns the constraint system of gnark over the scalar field of
+// curveID with the same constraints as r1cs (see the package documentation
+// for the mapping of the wires), to prove it with Groth16.
+func (r1cs *R1CS) Compile(curveID ecc.ID) (frontend.CompiledConstraintSystem, error) {
+	if err := r1cs.checkField(curveID); err != nil {
+		return nil, err
+	}
+	nbPublic := r1cs.NbPublic() + 1
+	nbSecret := r1cs.NbWires - nbPublic
+
+	circuit := r1cs.Circuit()
+	s, err := frontend.NewSchema(circuit)
+	if err != nil {
+		return nil, err
+	}
+	res := compiled.R1CS{
+		ConstraintSystem: compiled.ConstraintSystem{
+			Schema:             s,
+			NbPublicVariables:  nbPublic,
+			NbSecretVariables:  nbSecret,
+			Public:             []string{"one"},
+			MDebug:             make(map[int]int),
+			MHints:             make(map[int]*compiled.Hint),
+			MHintsDependencies: make(map[hint.ID]string),
+			CurveID:            curveID,
+		},
+	}
+	for i := 0; i < r1cs.NbPublic(); i++ {
+		res.Public = append(res.Public, fmt.Sprintf("Public_%d", i))
+	}
+	for i := 0; i < nbSecret; i++ {
+		res.Secret = append(res.Secret, fmt.Sprintf("Secret_%d", i))
+	}
+
+	// all the wires are inputs: the constraints are only checked by the
+	// solver, in a single level
+	st := cs.NewCoeffTable()
+	res.Constraints = make([]compiled.R1C, len(r1cs.Constraints))
+	level := make([]int, len(r1cs.Constraints))
+	for i, c := range r1cs.Constraints {
+		var les [3]compiled.LinearExpression
+		for j, lc := range [][]Term{c.A, c.B, c.C} {
+			les[j] = make(compiled.LinearExpression, len(lc))
+			for k := range lc {
+				visibility := schema.Secret
+				if lc[k].Wire < nbPublic {
+					visibility = schema.Public
+				}
+				les[j][k] = compiled.Pack(lc[k].Wire, st.CoeffID(&lc[k].Coeff), visibility)
+			}
+		}
+		res.Constraints[i] = compiled.R1C{L: les[0], R: les[1], O: les[2]}
+		level[i] = i
+	}
+	if len(level) != 0 {
+		res.Levels = [][]int{level}
+	}
+
+	switch curveID {
+	case ecc.BLS12_377:
+		return backend_bls12377.NewR1CS(res, st.Coeffs), nil
+	case ecc.BLS12_381:
+		return backend_bls12381.NewR1CS(res, st.Coeffs), nil
+	case ecc.BN254:
+		return backend_bn254.NewR1CS(res, st.Coeffs), nil
+	case ecc.BW6_761:
+		return backend_bw6761.NewR1CS(res, st.Coeffs), nil
+	case ecc.BW6_633:
+		return backend_bw6633.NewR1CS(res, st.Coeffs), nil
+	case ecc.BLS24_315:
+		return backend_bls24315.NewR1CS(res, st.Coeffs), nil
+	default:
+		return nil, fmt.Errorf("circom: unsupported curve %s", curveID.String())
+	}
+}
+
+// Circuit returns a circuit whose Define adds the constraints of r1cs, to
+// compile them with any builder of the frontend. Its public inputs are the
+// public wires of circom and its secret inputs all the other wires.
+func (r1cs *R1CS) Circuit() *Circuit {
+	return &Circuit{
+		Public:      make([]frontend.Variable, r1cs.NbPublic()),
+		Secret:      make([]frontend.Variable, r1cs.NbWires-r1cs.NbPublic()-1),
+		constraints: r1cs.Constraints,
+	}
+}
+
+// NewWitness returns the full witness of gnark (over the scalar field of
+// curveID) for the values of the wires read by ReadWitness
+func (r1cs *R1CS) NewWitness(values []big.Int, curveID ecc.ID) (*witness.Witness, error) {
+	if err := r1cs.checkField(curveID); err != nil {
+		return nil, err
+	}
+	if len(values) != r1cs.NbWires {
+		return nil, fmt.Errorf("circom: %d values for %d wires", len(values), r1cs.NbWires)
+	}
+	if !values[0].IsInt64() || values[0].Int64() != 1 {
+		return nil, errors.New("circom: the value of the wire 0 must be 1")
+	}
+	assignment := r1cs.Circuit()
+	for i := range assignment.Public {
+		assignment.Public[i] = &values[1+i]
+	}
+	for i := range assignment.Secret {
+		assignment.Secret[i] = &values[1+len(assignment.Public)+i]
+	}
+	return frontend.NewWitness(assignment, curveID)
+}
+
+// checkField returns an error if the field of r1cs is not the scalar field of
+// curveID
+func (r1cs *R1CS) checkField(curveID ecc.ID) error {
+	if r1cs.Prime.Cmp(curveID.Info().Fr.Modulus()) != 0 {
+		return fmt.Errorf("circom: the field doesn't match the scalar field of %s", curveID.String())
+	}
+	return nil
+}
+
+// Circuit is a circom constraint system as a gnark circuit (see R1CS.Circuit)
+type Circuit struct {
+	Public []frontend.Variable `gnark:",public"`
+	Secret []frontend.Variable `gnark:",secret"`
+
+	constraints []Constraint
+}
+
+// Define adds the constraints A⋅B == C
+func (circuit *Circuit) Define(api frontend.API) error {
+	lc := func(terms []Term) frontend.Variable {
+		var res frontend.Variable = 0
+		for i := range terms {
+			switch w := terms[i].Wire; {
+			case w == 0:
+				res = api.Add(res, &terms[i].Coeff)
+			case w <= len(circuit.Public):
+				res = api.Add(res, api.Mul(&terms[i].Coeff, circuit.Public[w-1]))
+			default:
+				res = api.Add(res, api.Mul(&terms[i].Coeff, circuit.Secret[w-1-len(circuit.Public)]))
+			}
+		}
+		return res
+	}
+	for _, c := range circuit.constraints {
+		api.AssertIsEqual(api.Mul(lc(c.A), lc(c.B)), lc(c.C))
+	}
+	return nil
+}
+
+// readSections reads a file made of a header (magic, version, number of
+// sections) and of sections (type, size, content), and returns the content of
+// the sections by type
+func readSections(r io.Reader, magic string, version uint32) (map[uint32][]byte, error) {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	d := &decoder{buf: buf}
+	if string(d.bytes(4)) != magic {
+		return nil, fmt.Errorf("%w: not a .%s file", errInvalidFile, magic)
+	}
+	if v := d.uint32(); d.err == nil && v != version {
+		return nil, fmt.Errorf("circom: unsupported .%s version %d", magic, v)
+	}
+	nbSections := int(d.uint32())
+
+	res := make(map[uint32][]byte)
+	for i := 0; i < nbSections && d.err == nil; i++ {
+		typ := d.uint32()
+		size := d.uint64()
+		if size > uint64(len(d.buf)) {
+			return nil, fmt.Errorf("%w: section of %d bytes", errInvalidFile, size)
+		}
+		res[typ] = d.bytes(int(size))
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return res, nil
+}
+
+// decoder reads little-endian values from buf; after a read past its end, all
+// the reads return zero values and err is set.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(d.buf) {
+		d.err = fmt.Errorf("%w: unexpected end of section", errInvalidFile)
+		return nil
+	}
+	res := d.buf[:n]
+	d.buf = d.buf[n:]
+	return res
+}
+
+func (d *decoder) uint32() uint32 {
+	if buf := d.bytes(4); buf != nil {
+		return binary.LittleEndian.Uint32(buf)
+	}
+	return 0
+}
+
+func (d *decoder) uint64() uint64 {
+	if buf := d.bytes(8); buf != nil {
+		return binary.LittleEndian.Uint64(buf)
+	}
+	return 0
+}
+
+// element reads a field element of size bytes, in little-endian
+func (d *decoder) element(size int) *big.Int {
+	buf := d.bytes(size)
+	be := make([]byte, len(buf))
+	for i := range buf {
+		be[len(buf)-1-i] = buf[i]
+	}
+	return new(big.Int).SetBytes(be)
+}
@@ -0,0 +1,152 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package circom_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/circom"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/stretchr/testify/require"
+)
+
+// fileWriter writes the sections of a circom binary file
+type fileWriter struct {
+	bytes.Buffer
+	fieldSize int
+}
+
+func (w *fileWriter) uint32(v uint32) {
+	_ = binary.Write(w, binary.LittleEndian, v)
+}
+
+func (w *fileWriter) uint64(v uint64) {
+	_ = binary.Write(w, binary.LittleEndian, v)
+}
+
+func (w *fileWriter) element(v *big.Int) {
+	buf := v.FillBytes(make([]byte, w.fieldSize))
+	for i := len(buf) - 1; i >= 0; i-- {
+		w.WriteByte(buf[i])
+	}
+}
+
+func newFile(magic string, version uint32, sections ...func(w *fileWriter)) []byte {
+	fieldSize := (ecc.BN254.Info().Fr.Modulus().BitLen() + 63) / 64 * 8
+	var res bytes.Buffer
+	res.WriteString(magic)
+	_ = binary.Write(&res, binary.LittleEndian, version)
+	_ = binary.Write(&res, binary.LittleEndian, uint32(len(sections)))
+	for i, s := range sections {
+		w := fileWriter{fieldSize: fieldSize}
+		s(&w)
+		_ = binary.Write(&res, binary.LittleEndian, uint32(i+1))
+		_ = binary.Write(&res, binary.LittleEndian, uint64(w.Len()))
+		res.Write(w.Bytes())
+	}
+	return res.Bytes()
+}
+
+// out = x²⋅y + 3, with the wires [one, out, x, y, x²]
+func r1csFile() []byte {
+	modulus := ecc.BN254.Info().Fr.Modulus()
+	lc := func(w *fileWriter, terms ...int64) {
+		w.uint32(uint32(len(terms) / 2))
+		for i := 0; i < len(terms); i += 2 {
+			w.uint32(uint32(terms[i]))
+			c := big.NewInt(terms[i+1])
+			w.element(c.Mod(c, modulus))
+		}
+	}
+	return newFile("r1cs", 1, func(w *fileWriter) {
+		w.uint32(uint32(w.fieldSize))
+		w.element(modulus)
+		w.uint32(5) // wires
+		w.uint32(1) // public outputs
+		w.uint32(0) // public inputs
+		w.uint32(2) // private inputs
+		w.uint64(5) // labels
+		w.uint32(2) // constraints
+	}, func(w *fileWriter) {
+		// x⋅x = x²
+		lc(w, 2, 1)
+		lc(w, 2, 1)
+		lc(w, 4, 1)
+		// x²⋅y = out - 3
+		lc(w, 4, 1)
+		lc(w, 3, 1)
+		lc(w, 1, 1, 0, -3)
+	})
+}
+
+func wtnsFile(values ...int64) []byte {
+	return newFile("wtns", 2, func(w *fileWriter) {
+		w.uint32(uint32(w.fieldSize))
+		w.element(ecc.BN254.Info().Fr.Modulus())
+		w.uint32(uint32(len(values)))
+	}, func(w *fileWriter) {
+		for _, v := range values {
+			w.element(big.NewInt(v))
+		}
+	})
+}
+
+func TestReadR1CS(t *testing.T) {
+	assert := require.New(t)
+
+	r1cs, err := circom.ReadR1CS(bytes.NewReader(r1csFile()))
+	assert.NoError(err)
+	assert.Equal(5, r1cs.NbWires)
+	assert.Equal(1, r1cs.NbPublic())
+	assert.Len(r1cs.Constraints, 2)
+
+	values, err := circom.ReadWitness(bytes.NewReader(wtnsFile(1, 21, 3, 2, 9)))
+	assert.NoError(err)
+	witness, err := r1cs.NewWitness(values, ecc.BN254)
+	assert.NoError(err)
+	badValues, err := circom.ReadWitness(bytes.NewReader(wtnsFile(1, 22, 3, 2, 9)))
+	assert.NoError(err)
+	badWitness, err := r1cs.NewWitness(badValues, ecc.BN254)
+	assert.NoError(err)
+
+	// Groth16
+	ccs, err := r1cs.Compile(ecc.BN254)
+	assert.NoError(err)
+	assert.Equal(2, ccs.GetNbConstraints())
+	assert.NoError(ccs.IsSolved(witness))
+	assert.Error(ccs.IsSolved(badWitness))
+
+	// PlonK
+	ccs, err = frontend.Compile(ecc.BN254, scs.NewBuilder, r1cs.Circuit())
+	assert.NoError(err)
+	assert.NoError(ccs.IsSolved(witness))
+	assert.Error(ccs.IsSolved(badWitness))
+
+	// the field must be the scalar field of the curve
+	_, err = r1cs.Compile(ecc.BLS12_381)
+	assert.Error(err)
+
+	// truncated file
+	buf := r1csFile()
+	_, err = circom.ReadR1CS(bytes.NewReader(buf[:len(buf)-1]))
+	assert.Error(err)
+}
@@ -0,0 +1,87 @@
+// Copyright 2022 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/consensys/gnark/frontend/schema"
+)
+
+// UnsatisfiedConstraintError is returned by the solvers of all the curves
+// (R1CS.Solve, SparseR1CS.Solve, and so Prove and IsSolved) when the witness
+// does not satisfy a constraint. It can be retrieved with errors.As.
+type UnsatisfiedConstraintError struct {
+	Err       error
+	CID       int     // constraint ID
+	DebugInfo *string // optional debug info
+
+	// Caller is the location (file:line) in the circuit of the API call
+	// which added the constraint, if it has debug info (assertions, divisions,
+	// ...). The full path of the file is given when compiled with the debug
+	// build tag.
+	Caller string
+
+	// L, R and O are the values of the linear expressions of the constraint
+	// L⋅R == O, evaluated on the wires solved when the solver failed. They are
+	// only set for R1CS.
+	L, R, O string
+
+	// Wires are the wires involved in the constraint, in order of appearance.
+	Wires []WireValue
+}
+
+// WireValue describes a wire of an unsatisfied constraint.
+type WireValue struct {
+	ID         int               // index of the wire in the solution
+	Name       string            // name of the input in the circuit, empty for internal wires
+	Visibility schema.Visibility // Public, Secret or Internal
+	Value      string            // value of the wire, empty if it is not solved
+}
+
+func (r *UnsatisfiedConstraintError) Error() string {
+	var sbb strings.Builder
+	if r.DebugInfo != nil {
+		// the debug info ends with the stack of the API call
+		sbb.WriteString(fmt.Sprintf("constraint #%d is not satisfied: %s", r.CID, strings.TrimSuffix(*r.DebugInfo, "\n")))
+	} else {
+		sbb.WriteString(fmt.Sprintf("constraint #%d is not satisfied: %s", r.CID, r.Err.Error()))
+	}
+	if r.L != "" {
+		sbb.WriteString(fmt.Sprintf("\nL = %s, R = %s, O = %s", r.L, r.R, r.O))
+	}
+	for _, w := range r.Wires {
+		sbb.WriteString("\n\t" + w.String())
+	}
+	return sbb.String()
+}
+
+// Unwrap returns the underlying error, if any.
+func (r *UnsatisfiedConstraintError) Unwrap() error {
+	return r.Err
+}
+
+func (w WireValue) String() string {
+	name := w.Name
+	if name == "" {
+		name = fmt.Sprintf("wire %d", w.ID)
+	}
+	value := w.Value
+	if value == "" {
+		value = "<unsolved>"
+	}
+	return fmt.Sprintf("%s (%s) = %s", name, w.Visibility, value)
+}
@@ -0,0 +1,350 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package groth16
+
+import (
+	"crypto/rand"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/witness"
+
+	witness_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/witness"
+	witness_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/witness"
+	witness_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/witness"
+	witness_bn254 "github.com/consensys/gnark/internal/backend/bn254/witness"
+	witness_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/witness"
+	witness_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/witness"
+
+	groth16_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/groth16"
+	groth16_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/groth16"
+	groth16_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/groth16"
+	groth16_bn254 "github.com/consensys/gnark/internal/backend/bn254/groth16"
+	groth16_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/groth16"
+	groth16_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/groth16"
+)
+
+type aggregationObject interface {
+	io.WriterTo
+	io.ReaderFrom
+	CurveID() ecc.ID
+}
+
+// AggregationSRS is the structured reference string used to aggregate Groth16 proofs
+// (SnarkPack, https://eprint.iacr.org/2021/529.pdf). It doesn't depend on the circuit.
+//
+// it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
+type AggregationSRS interface {
+	aggregationObject
+}
+
+// AggregationVerifyingKey is the part of the AggregationSRS needed to verify aggregated proofs
+//
+// it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
+type AggregationVerifyingKey interface {
+	aggregationObject
+}
+
+// AggregatedProof is a proof, of logarithmic size, that several Groth16 proofs of the same
+// circuit are valid
+//
+// it's underlying implementation is curve specific (see gnark/internal/backend)
+type AggregatedProof interface {
+	aggregationObject
+}
+
+// AggregationSetup returns an AggregationSRS able to aggregate up to size proofs, and the
+// corresponding AggregationVerifyingKey.
+//
+// The secrets of the SRS are sampled at random and discarded: this is meant for tests, as
+// in production the SRS must be derived from two independent powers of tau ceremonies.
+func AggregationSetup(curveID ecc.ID, size uint64) (AggregationSRS, AggregationVerifyingKey, error) {
+	a, err := rand.Int(rand.Reader, curveID.Info().Fr.Modulus())
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := rand.Int(rand.Reader, curveID.Info().Fr.Modulus())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch curveID {
+	case ecc.BN254:
+		srs, err := groth16_bn254.NewAggregationSRS(size, a, b)
+		if err != nil {
+			return nil, nil, err
+		}
+		return srs, srs.VerifyingKey(), nil
+	case ecc.BLS12_377:
+		srs, err := groth16_bls12377.NewAggregationSRS(size, a, b)
+		if err != nil {
+			return nil, nil, err
+		}
+		return srs, srs.VerifyingKey(), nil
+	case ecc.BLS12_381:
+		srs, err := groth16_bls12381.NewAggregationSRS(size, a, b)
+		if err != nil {
+			return nil, nil, err
+		}
+		return srs, srs.VerifyingKey(), nil
+	case ecc.BW6_761:
+		srs, err := groth16_bw6761.NewAggregationSRS(size, a, b)
+		if err != nil {
+			return nil, nil, err
+		}
+		return srs, srs.VerifyingKey(), nil
+	case ecc.BLS24_315:
+		srs, err := groth16_bls24315.NewAggregationSRS(size, a, b)
+		if err != nil {
+			return nil, nil, err
+		}
+		return srs, srs.VerifyingKey(), nil
+	case ecc.BW6_633:
+		srs, err := groth16_bw6633.NewAggregationSRS(size, a, b)
+		if err != nil {
+			return nil, nil, err
+		}
+		return srs, srs.VerifyingKey(), nil
+	default:
+		panic("not implemented")
+	}
+}
+
+// AggregateProofs aggregates Groth16 proofs for vk and the corresponding public witnesses.
+// Their number needs not be a power of two.
+func AggregateProofs(srs AggregationSRS, vk VerifyingKey, proofs []Proof, publicWitnesses []*witness.Witness) (AggregatedProof, error) {
+	switch _srs := srs.(type) {
+	case *groth16_bn254.AggregationSRS:
+		_proofs := make([]*groth16_bn254.Proof, len(proofs))
+		for i := range proofs {
+			_proofs[i] = proofs[i].(*groth16_bn254.Proof)
+		}
+		w := make([]witness_bn254.Witness, len(publicWitnesses))
+		for i := range publicWitnesses {
+			_w, ok := publicWitnesses[i].Vector.(*witness_bn254.Witness)
+			if !ok {
+				return nil, witness.ErrInvalidWitness
+			}
+			w[i] = *_w
+		}
+		return groth16_bn254.AggregateProofs(_srs, vk.(*groth16_bn254.VerifyingKey), _proofs, w)
+	case *groth16_bls12377.AggregationSRS:
+		_proofs := make([]*groth16_bls12377.Proof, len(proofs))
+		for i := range proofs {
+			_proofs[i] = proofs[i].(*groth16_bls12377.Proof)
+		}
+		w := make([]witness_bls12377.Witness, len(publicWitnesses))
+		for i := range publicWitnesses {
+			_w, ok := publicWitnesses[i].Vector.(*witness_bls12377.Witness)
+			if !ok {
+				return nil, witness.ErrInvalidWitness
+			}
+			w[i] = *_w
+		}
+		return groth16_bls12377.AggregateProofs(_srs, vk.(*groth16_bls12377.VerifyingKey), _proofs, w)
+	case *groth16_bls12381.AggregationSRS:
+		_proofs := make([]*groth16_bls12381.Proof, len(proofs))
+		for i := range proofs {
+			_proofs[i] = proofs[i].(*groth16_bls12381.Proof)
+		}
+		w := make([]witness_bls12381.Witness, len(publicWitnesses))
+		for i := range publicWitnesses {
+			_w, ok := publicWitnesses[i].Vector.(*witness_bls12381.Witness)
+			if !ok {
+				return nil, witness.ErrInvalidWitness
+			}
+			w[i] = *_w
+		}
+		return groth16_bls12381.AggregateProofs(_srs, vk.(*groth16_bls12381.VerifyingKey), _proofs, w)
+	case *groth16_bw6761.AggregationSRS:
+		_proofs := make([]*groth16_bw6761.Proof, len(proofs))
+		for i := range proofs {
+			_proofs[i] = proofs[i].(*groth16_bw6761.Proof)
+		}
+		w := make([]witness_bw6761.Witness, len(publicWitnesses))
+		for i := range publicWitnesses {
+			_w, ok := publicWitnesses[i].Vector.(*witness_bw6761.Witness)
+			if !ok {
+				return nil, witness.ErrInvalidWitness
+			}
+			w[i] = *_w
+		}
+		return groth16_bw6761.AggregateProofs(_srs, vk.(*groth16_bw6761.VerifyingKey), _proofs, w)
+	case *groth16_bls24315.AggregationSRS:
+		_proofs := make([]*groth16_bls24315.Proof, len(proofs))
+		for i := range proofs {
+			_proofs[i] = proofs[i].(*groth16_bls24315.Proof)
+		}
+		w := make([]witness_bls24315.Witness, len(publicWitnesses))
+		for i := range publicWitnesses {
+			_w, ok := publicWitnesses[i].Vector.(*witness_bls24315.Witness)
+			if !ok {
+				return nil, witness.ErrInvalidWitness
+			}
+			w[i] = *_w
+		}
+		return groth16_bls24315.AggregateProofs(_srs, vk.(*groth16_bls24315.VerifyingKey), _proofs, w)
+	case *groth16_bw6633.AggregationSRS:
+		_proofs := make([]*groth16_bw6633.Proof, len(proofs))
+		for i := range proofs {
+			_proofs[i] = proofs[i].(*groth16_bw6633.Proof)
+		}
+		w := make([]witness_bw6633.Witness, len(publicWitnesses))
+		for i := range publicWitnesses {
+			_w, ok := publicWitnesses[i].Vector.(*witness_bw6633.Witness)
+			if !ok {
+				return nil, witness.ErrInvalidWitness
+			}
+			w[i] = *_w
+		}
+		return groth16_bw6633.AggregateProofs(_srs, vk.(*groth16_bw6633.VerifyingKey), _proofs, w)
+	default:
+		panic("unrecognized aggregation SRS curve type")
+	}
+}
+
+// VerifyAggregatedProof verifies that an AggregatedProof is valid for vk and the public witnesses
+func VerifyAggregatedProof(proof AggregatedProof, vk VerifyingKey, avk AggregationVerifyingKey, publicWitnesses []*witness.Witness) error {
+	switch _proof := proof.(type) {
+	case *groth16_bn254.AggregatedProof:
+		w := make([]witness_bn254.Witness, len(publicWitnesses))
+		for i := range publicWitnesses {
+			_w, ok := publicWitnesses[i].Vector.(*witness_bn254.Witness)
+			if !ok {
+				return witness.ErrInvalidWitness
+			}
+			w[i] = *_w
+		}
+		return groth16_bn254.VerifyAggregatedProof(_proof, vk.(*groth16_bn254.VerifyingKey), avk.(*groth16_bn254.AggregationVerifyingKey), w)
+	case *groth16_bls12377.AggregatedProof:
+		w := make([]witness_bls12377.Witness, len(publicWitnesses))
+		for i := range publicWitnesses {
+			_w, ok := publicWitnesses[i].Vector.(*witness_bls12377.Witness)
+			if !ok {
+				return witness.ErrInvalidWitness
+			}
+			w[i] = *_w
+		}
+		return groth16_bls12377.VerifyAggregatedProof(_proof, vk.(*groth16_bls12377.VerifyingKey), avk.(*groth16_bls12377.AggregationVerifyingKey), w)
+	case *groth16_bls12381.AggregatedProof:
+		w := make([]witness_bls12381.Witness, len(publicWitnesses))
+		for i := range publicWitnesses {
+			_w, ok := publicWitnesses[i].Vector.(*witness_bls12381.Witness)
+			if !ok {
+				return witness.ErrInvalidWitness
+			}
+			w[i] = *_w
+		}
+		return groth16_bls12381.VerifyAggregatedProof(_proof, vk.(*groth16_bls12381.VerifyingKey), avk.(*groth16_bls12381.AggregationVerifyingKey), w)
+	case *groth16_bw6761.AggregatedProof:
+		w := make([]witness_bw6761.Witness, len(publicWitnesses))
+		for i := range publicWitnesses {
+			_w, ok := publicWitnesses[i].Vector.(*witness_bw6761.Witness)
+			if !ok {
+				return witness.ErrInvalidWitness
+			}
+			w[i] = *_w
+		}
+		return groth16_bw6761.VerifyAggregatedProof(_proof, vk.(*groth16_bw6761.VerifyingKey), avk.(*groth16_bw6761.AggregationVerifyingKey), w)
+	case *groth16_bls24315.AggregatedProof:
+		w := make([]witness_bls24315.Witness, len(publicWitnesses))
+		for i := range publicWitnesses {
+			_w, ok := publicWitnesses[i].Vector.(*witness_bls24315.Witness)
+			if !ok {
+				return witness.ErrInvalidWitness
+			}
+			w[i] = *_w
+		}
+		return groth16_bls24315.VerifyAggregatedProof(_proof, vk.(*groth16_bls24315.VerifyingKey), avk.(*groth16_bls24315.AggregationVerifyingKey), w)
+	case *groth16_bw6633.AggregatedProof:
+		w := make([]witness_bw6633.Witness, len(publicWitnesses))
+		for i := range publicWitnesses {
+			_w, ok := publicWitnesses[i].Vector.(*witness_bw6633.Witness)
+			if !ok {
+				return witness.ErrInvalidWitness
+			}
+			w[i] = *_w
+		}
+		return groth16_bw6633.VerifyAggregatedProof(_proof, vk.(*groth16_bw6633.VerifyingKey), avk.(*groth16_bw6633.AggregationVerifyingKey), w)
+	default:
+		panic("unrecognized aggregated proof curve type")
+	}
+}
+
+// NewAggregationSRS instantiates a curve-typed AggregationSRS and returns an interface
+// This function exists for serialization purposes
+func NewAggregationSRS(curveID ecc.ID) AggregationSRS {
+	switch curveID {
+	case ecc.BN254:
+		return &groth16_bn254.AggregationSRS{}
+	case ecc.BLS12_377:
+		return &groth16_bls12377.AggregationSRS{}
+	case ecc.BLS12_381:
+		return &groth16_bls12381.AggregationSRS{}
+	case ecc.BW6_761:
+		return &groth16_bw6761.AggregationSRS{}
+	case ecc.BLS24_315:
+		return &groth16_bls24315.AggregationSRS{}
+	case ecc.BW6_633:
+		return &groth16_bw6633.AggregationSRS{}
+	default:
+		panic("not implemented")
+	}
+}
+
+// NewAggregationVerifyingKey instantiates a curve-typed AggregationVerifyingKey and returns an interface
+// This function exists for serialization purposes
+func NewAggregationVerifyingKey(curveID ecc.ID) AggregationVerifyingKey {
+	switch curveID {
+	case ecc.BN254:
+		return &groth16_bn254.AggregationVerifyingKey{}
+	case ecc.BLS12_377:
+		return &groth16_bls12377.AggregationVerifyingKey{}
+	case ecc.BLS12_381:
+		return &groth16_bls12381.AggregationVerifyingKey{}
+	case ecc.BW6_761:
+		return &groth16_bw6761.AggregationVerifyingKey{}
+	case ecc.BLS24_315:
+		return &groth16_bls24315.AggregationVerifyingKey{}
+	case ecc.BW6_633:
+		return &groth16_bw6633.AggregationVerifyingKey{}
+	default:
+		panic("not implemented")
+	}
+}
+
+// NewAggregatedProof instantiates a curve-typed AggregatedProof and returns an interface
+// This function exists for serialization purposes
+func NewAggregatedProof(curveID ecc.ID) AggregatedProof {
+	switch curveID {
+	case ecc.BN254:
+		return &groth16_bn254.AggregatedProof{}
+	case ecc.BLS12_377:
+		return &groth16_bls12377.AggregatedProof{}
+	case ecc.BLS12_381:
+		return &groth16_bls12381.AggregatedProof{}
+	case ecc.BW6_761:
+		return &groth16_bw6761.AggregatedProof{}
+	case ecc.BLS24_315:
+		return &groth16_bls24315.AggregatedProof{}
+	case ecc.BW6_633:
+		return &groth16_bw6633.AggregatedProof{}
+	default:
+		panic("not implemented")
+	}
+}
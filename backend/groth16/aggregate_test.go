@@ -0,0 +1,94 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+package groth16
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+type aggregationCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *aggregationCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X, circuit.X), circuit.Y)
+	return nil
+}
+
+func TestAggregateProofs(t *testing.T) {
+	for _, curveID := range []ecc.ID{ecc.BN254, ecc.BLS12_377, ecc.BLS12_381, ecc.BLS24_315, ecc.BW6_633, ecc.BW6_761} {
+		t.Run(curveID.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			ccs, err := frontend.Compile(curveID, r1cs.NewBuilder, &aggregationCircuit{})
+			assert.NoError(err)
+			pk, vk, err := Setup(ccs)
+			assert.NoError(err)
+
+			const nbProofs = 3
+			proofs := make([]Proof, nbProofs)
+			publicWitnesses := make([]*witness.Witness, nbProofs)
+			for i := 0; i < nbProofs; i++ {
+				fullWitness, err := frontend.NewWitness(&aggregationCircuit{X: i + 2, Y: (i + 2) * (i + 2) * (i + 2)}, curveID)
+				assert.NoError(err)
+				publicWitnesses[i], err = fullWitness.Public()
+				assert.NoError(err)
+				proofs[i], err = Prove(ccs, pk, fullWitness)
+				assert.NoError(err)
+			}
+
+			srs, avk, err := AggregationSetup(curveID, 4)
+			assert.NoError(err)
+
+			// nbProofs is padded to the next power of two
+			for _, n := range []int{1, 2, nbProofs} {
+				aggregatedProof, err := AggregateProofs(srs, vk, proofs[:n], publicWitnesses[:n])
+				assert.NoError(err)
+				assert.NoError(VerifyAggregatedProof(aggregatedProof, vk, avk, publicWitnesses[:n]))
+			}
+
+			aggregatedProof, err := AggregateProofs(srs, vk, proofs, publicWitnesses)
+			assert.NoError(err)
+
+			// serialization round trip
+			var buf bytes.Buffer
+			_, err = aggregatedProof.WriteTo(&buf)
+			assert.NoError(err)
+			_aggregatedProof := NewAggregatedProof(curveID)
+			_, err = _aggregatedProof.ReadFrom(&buf)
+			assert.NoError(err)
+
+			buf.Reset()
+			_, err = avk.WriteTo(&buf)
+			assert.NoError(err)
+			_avk := NewAggregationVerifyingKey(curveID)
+			_, err = _avk.ReadFrom(&buf)
+			assert.NoError(err)
+			assert.NoError(VerifyAggregatedProof(_aggregatedProof, vk, _avk, publicWitnesses))
+
+			// wrong public inputs
+			swapped := []*witness.Witness{publicWitnesses[1], publicWitnesses[0], publicWitnesses[2]}
+			assert.Error(VerifyAggregatedProof(aggregatedProof, vk, avk, swapped))
+
+			// proof aggregated with a different srs
+			otherSRS, _, err := AggregationSetup(curveID, 4)
+			assert.NoError(err)
+			aggregatedProof, err = AggregateProofs(otherSRS, vk, proofs, publicWitnesses)
+			assert.NoError(err)
+			assert.Error(VerifyAggregatedProof(aggregatedProof, vk, avk, publicWitnesses))
+
+			// too many proofs for the srs
+			_, err = AggregateProofs(srs, vk, append(proofs, proofs...), append(publicWitnesses, publicWitnesses...))
+			assert.Error(err)
+		})
+	}
+}
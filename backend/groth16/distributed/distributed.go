@@ -0,0 +1,273 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package distributed shards the multi-scalar multiplications (MSM) and FFTs of the prover across
+// workers, following DIZK (https://eprint.iacr.org/2018/691.pdf).
+//
+// Workers serve a Worker with net/rpc (see Serve), and the coordinator proves with the option
+// backend.WithAccelerator(NewAccelerator(clients...)):
+//
+//   - each MSM is split in as many shards as there are workers, the coordinator summing their results
+//   - each FFT of size n = n₁n₂ is computed with the four-step algorithm: the workers compute
+//     n₁ FFTs of size n₂ (and multiply them by the twiddle factors), then n₂ FFTs of size n₁,
+//     the coordinator transposing the data in between
+//
+// The coordinator and the workers must run on the same architecture, as points and field elements
+// are sent in their memory layout. If a worker fails during an FFT, the FFT is computed locally.
+package distributed
+
+import (
+	"errors"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/accelerator"
+	"github.com/consensys/gnark/logger"
+
+	curve_bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	fr_bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	fft_bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/fr/fft"
+	curve_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	fr_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	fft_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr/fft"
+	curve_bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315"
+	fr_bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+	fft_bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/fr/fft"
+	curve_bn254 "github.com/consensys/gnark-crypto/ecc/bn254"
+	fr_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	fft_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	curve_bw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633"
+	fr_bw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/fr"
+	fft_bw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/fr/fft"
+	curve_bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761"
+	fr_bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/fr"
+	fft_bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/fr/fft"
+
+	groth16_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/groth16"
+	groth16_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/groth16"
+	groth16_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/groth16"
+	groth16_bn254 "github.com/consensys/gnark/internal/backend/bn254/groth16"
+	groth16_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/groth16"
+	groth16_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/groth16"
+)
+
+var errUnsupportedTypes = errors.New("distributed: unsupported or mismatched types")
+
+// Client calls the methods of a remote Worker; *rpc.Client implements it
+type Client interface {
+	Call(serviceMethod string, args interface{}, reply interface{}) error
+}
+
+// MultiExpTask is the shard of an MSM sent to a Worker
+type MultiExpTask struct {
+	Curve       ecc.ID
+	G2          bool
+	Points      []byte
+	Scalars     []byte
+	ScalarsMont bool
+}
+
+// FFTTask is a batch of FFTs of size N sent to a Worker. If Omega is set, the k-th coefficient
+// of the i-th result is multiplied by Omega^((Offset+i)k).
+type FFTTask struct {
+	Curve       ecc.ID
+	N           uint64
+	Inverse     bool
+	Omega       []byte
+	Offset      uint64
+	Polynomials []byte
+}
+
+// Result is the result of a task
+type Result struct {
+	Data []byte
+}
+
+// Accelerator is an accelerator.Accelerator sharding the MSMs and FFTs across workers
+type Accelerator struct {
+	workers []Client
+}
+
+// NewAccelerator returns an Accelerator sharding the MSMs and FFTs across workers
+func NewAccelerator(workers ...Client) *Accelerator {
+	return &Accelerator{workers: workers}
+}
+
+func (a *Accelerator) multiExpShard(curveID ecc.ID, g2 bool) func(int, []byte, []byte, bool) ([]byte, error) {
+	return func(shard int, points, scalars []byte, scalarsMont bool) ([]byte, error) {
+		var res Result
+		err := a.workers[shard].Call("Worker.MultiExp", &MultiExpTask{
+			Curve:       curveID,
+			G2:          g2,
+			Points:      points,
+			Scalars:     scalars,
+			ScalarsMont: scalarsMont,
+		}, &res)
+		return res.Data, err
+	}
+}
+
+func (a *Accelerator) fftShard(curveID ecc.ID) func(int, uint64, bool, []byte, uint64, []byte) ([]byte, error) {
+	return func(shard int, n uint64, inverse bool, omega []byte, offset uint64, polynomials []byte) ([]byte, error) {
+		var res Result
+		err := a.workers[shard].Call("Worker.FFT", &FFTTask{
+			Curve:       curveID,
+			N:           n,
+			Inverse:     inverse,
+			Omega:       omega,
+			Offset:      offset,
+			Polynomials: polynomials,
+		}, &res)
+		return res.Data, err
+	}
+}
+
+// MultiExpG1 implements accelerator.Accelerator
+func (a *Accelerator) MultiExpG1(res, points, scalars interface{}, config ecc.MultiExpConfig) error {
+	switch res := res.(type) {
+	case *curve_bls12377.G1Jac:
+		p, ok1 := points.([]curve_bls12377.G1Affine)
+		s, ok2 := scalars.([]fr_bls12377.Element)
+		if !ok1 || !ok2 {
+			return errUnsupportedTypes
+		}
+		return groth16_bls12377.DistributedMultiExpG1(res, p, s, config.ScalarsMont, len(a.workers), a.multiExpShard(ecc.BLS12_377, false))
+	case *curve_bls12381.G1Jac:
+		p, ok1 := points.([]curve_bls12381.G1Affine)
+		s, ok2 := scalars.([]fr_bls12381.Element)
+		if !ok1 || !ok2 {
+			return errUnsupportedTypes
+		}
+		return groth16_bls12381.DistributedMultiExpG1(res, p, s, config.ScalarsMont, len(a.workers), a.multiExpShard(ecc.BLS12_381, false))
+	case *curve_bls24315.G1Jac:
+		p, ok1 := points.([]curve_bls24315.G1Affine)
+		s, ok2 := scalars.([]fr_bls24315.Element)
+		if !ok1 || !ok2 {
+			return errUnsupportedTypes
+		}
+		return groth16_bls24315.DistributedMultiExpG1(res, p, s, config.ScalarsMont, len(a.workers), a.multiExpShard(ecc.BLS24_315, false))
+	case *curve_bn254.G1Jac:
+		p, ok1 := points.([]curve_bn254.G1Affine)
+		s, ok2 := scalars.([]fr_bn254.Element)
+		if !ok1 || !ok2 {
+			return errUnsupportedTypes
+		}
+		return groth16_bn254.DistributedMultiExpG1(res, p, s, config.ScalarsMont, len(a.workers), a.multiExpShard(ecc.BN254, false))
+	case *curve_bw6633.G1Jac:
+		p, ok1 := points.([]curve_bw6633.G1Affine)
+		s, ok2 := scalars.([]fr_bw6633.Element)
+		if !ok1 || !ok2 {
+			return errUnsupportedTypes
+		}
+		return groth16_bw6633.DistributedMultiExpG1(res, p, s, config.ScalarsMont, len(a.workers), a.multiExpShard(ecc.BW6_633, false))
+	case *curve_bw6761.G1Jac:
+		p, ok1 := points.([]curve_bw6761.G1Affine)
+		s, ok2 := scalars.([]fr_bw6761.Element)
+		if !ok1 || !ok2 {
+			return errUnsupportedTypes
+		}
+		return groth16_bw6761.DistributedMultiExpG1(res, p, s, config.ScalarsMont, len(a.workers), a.multiExpShard(ecc.BW6_761, false))
+	default:
+		return errUnsupportedTypes
+	}
+}
+
+// MultiExpG2 implements accelerator.Accelerator
+func (a *Accelerator) MultiExpG2(res, points, scalars interface{}, config ecc.MultiExpConfig) error {
+	switch res := res.(type) {
+	case *curve_bls12377.G2Jac:
+		p, ok1 := points.([]curve_bls12377.G2Affine)
+		s, ok2 := scalars.([]fr_bls12377.Element)
+		if !ok1 || !ok2 {
+			return errUnsupportedTypes
+		}
+		return groth16_bls12377.DistributedMultiExpG2(res, p, s, config.ScalarsMont, len(a.workers), a.multiExpShard(ecc.BLS12_377, true))
+	case *curve_bls12381.G2Jac:
+		p, ok1 := points.([]curve_bls12381.G2Affine)
+		s, ok2 := scalars.([]fr_bls12381.Element)
+		if !ok1 || !ok2 {
+			return errUnsupportedTypes
+		}
+		return groth16_bls12381.DistributedMultiExpG2(res, p, s, config.ScalarsMont, len(a.workers), a.multiExpShard(ecc.BLS12_381, true))
+	case *curve_bls24315.G2Jac:
+		p, ok1 := points.([]curve_bls24315.G2Affine)
+		s, ok2 := scalars.([]fr_bls24315.Element)
+		if !ok1 || !ok2 {
+			return errUnsupportedTypes
+		}
+		return groth16_bls24315.DistributedMultiExpG2(res, p, s, config.ScalarsMont, len(a.workers), a.multiExpShard(ecc.BLS24_315, true))
+	case *curve_bn254.G2Jac:
+		p, ok1 := points.([]curve_bn254.G2Affine)
+		s, ok2 := scalars.([]fr_bn254.Element)
+		if !ok1 || !ok2 {
+			return errUnsupportedTypes
+		}
+		return groth16_bn254.DistributedMultiExpG2(res, p, s, config.ScalarsMont, len(a.workers), a.multiExpShard(ecc.BN254, true))
+	case *curve_bw6633.G2Jac:
+		p, ok1 := points.([]curve_bw6633.G2Affine)
+		s, ok2 := scalars.([]fr_bw6633.Element)
+		if !ok1 || !ok2 {
+			return errUnsupportedTypes
+		}
+		return groth16_bw6633.DistributedMultiExpG2(res, p, s, config.ScalarsMont, len(a.workers), a.multiExpShard(ecc.BW6_633, true))
+	case *curve_bw6761.G2Jac:
+		p, ok1 := points.([]curve_bw6761.G2Affine)
+		s, ok2 := scalars.([]fr_bw6761.Element)
+		if !ok1 || !ok2 {
+			return errUnsupportedTypes
+		}
+		return groth16_bw6761.DistributedMultiExpG2(res, p, s, config.ScalarsMont, len(a.workers), a.multiExpShard(ecc.BW6_761, true))
+	default:
+		return errUnsupportedTypes
+	}
+}
+
+// FFT implements accelerator.Accelerator
+func (a *Accelerator) FFT(domain, p interface{}, decimation accelerator.Decimation, coset bool) {
+	if err := a.fft(domain, p, decimation, coset, false); err != nil {
+		log := logger.Logger()
+		log.Warn().Err(err).Msg("distributed fft failed, computing it locally")
+		accelerator.CPU{}.FFT(domain, p, decimation, coset)
+	}
+}
+
+// FFTInverse implements accelerator.Accelerator
+func (a *Accelerator) FFTInverse(domain, p interface{}, decimation accelerator.Decimation, coset bool) {
+	if err := a.fft(domain, p, decimation, coset, true); err != nil {
+		log := logger.Logger()
+		log.Warn().Err(err).Msg("distributed inverse fft failed, computing it locally")
+		accelerator.CPU{}.FFTInverse(domain, p, decimation, coset)
+	}
+}
+
+func (a *Accelerator) fft(domain, p interface{}, decimation accelerator.Decimation, coset, inverse bool) error {
+	switch domain := domain.(type) {
+	case *fft_bls12377.Domain:
+		return groth16_bls12377.DistributedFFT(domain, p.([]fr_bls12377.Element), fft_bls12377.Decimation(decimation), coset, inverse, len(a.workers), a.fftShard(ecc.BLS12_377))
+	case *fft_bls12381.Domain:
+		return groth16_bls12381.DistributedFFT(domain, p.([]fr_bls12381.Element), fft_bls12381.Decimation(decimation), coset, inverse, len(a.workers), a.fftShard(ecc.BLS12_381))
+	case *fft_bls24315.Domain:
+		return groth16_bls24315.DistributedFFT(domain, p.([]fr_bls24315.Element), fft_bls24315.Decimation(decimation), coset, inverse, len(a.workers), a.fftShard(ecc.BLS24_315))
+	case *fft_bn254.Domain:
+		return groth16_bn254.DistributedFFT(domain, p.([]fr_bn254.Element), fft_bn254.Decimation(decimation), coset, inverse, len(a.workers), a.fftShard(ecc.BN254))
+	case *fft_bw6633.Domain:
+		return groth16_bw6633.DistributedFFT(domain, p.([]fr_bw6633.Element), fft_bw6633.Decimation(decimation), coset, inverse, len(a.workers), a.fftShard(ecc.BW6_633))
+	case *fft_bw6761.Domain:
+		return groth16_bw6761.DistributedFFT(domain, p.([]fr_bw6761.Element), fft_bw6761.Decimation(decimation), coset, inverse, len(a.workers), a.fftShard(ecc.BW6_761))
+	default:
+		panic(errUnsupportedTypes)
+	}
+}
@@ -0,0 +1,124 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+package distributed
+
+import (
+	"errors"
+	"net"
+	"net/rpc"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/accelerator"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+type cubicCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *cubicCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X, circuit.X), circuit.Y)
+	return nil
+}
+
+// startWorkers serves nbWorkers workers on the loopback interface and returns their clients
+func startWorkers(t *testing.T, nbWorkers int) []Client {
+	clients := make([]Client, nbWorkers)
+	for i := range clients {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		go Serve(l)
+		client, err := rpc.Dial("tcp", l.Addr().String())
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			client.Close()
+			l.Close()
+		})
+		clients[i] = client
+	}
+	return clients
+}
+
+func TestFFT(t *testing.T) {
+	assert := require.New(t)
+	acc := NewAccelerator(startWorkers(t, 3)...)
+
+	// 32 is split in 4x8, 64 in 8x8
+	for _, n := range []uint64{32, 64} {
+		domain := fft.NewDomain(n)
+		p := make([]fr.Element, n)
+		for i := range p {
+			p[i].SetRandom()
+		}
+		for _, decimation := range []accelerator.Decimation{accelerator.DIT, accelerator.DIF} {
+			for _, coset := range []bool{false, true} {
+				expected := make([]fr.Element, n)
+				copy(expected, p)
+				accelerator.CPU{}.FFT(domain, expected, decimation, coset)
+				got := make([]fr.Element, n)
+				copy(got, p)
+				assert.NoError(acc.fft(domain, got, decimation, coset, false))
+				assert.Equal(expected, got, "fft")
+
+				copy(expected, p)
+				accelerator.CPU{}.FFTInverse(domain, expected, decimation, coset)
+				copy(got, p)
+				assert.NoError(acc.fft(domain, got, decimation, coset, true))
+				assert.Equal(expected, got, "inverse fft")
+			}
+		}
+	}
+}
+
+func TestProve(t *testing.T) {
+	clients := startWorkers(t, 3)
+	for _, curveID := range []ecc.ID{ecc.BN254, ecc.BLS12_377, ecc.BLS12_381, ecc.BLS24_315, ecc.BW6_633, ecc.BW6_761} {
+		t.Run(curveID.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			ccs, err := frontend.Compile(curveID, r1cs.NewBuilder, &cubicCircuit{})
+			assert.NoError(err)
+			pk, vk, err := groth16.Setup(ccs)
+			assert.NoError(err)
+			fullWitness, err := frontend.NewWitness(&cubicCircuit{X: 3, Y: 27}, curveID)
+			assert.NoError(err)
+			publicWitness, err := fullWitness.Public()
+			assert.NoError(err)
+
+			proof, err := groth16.Prove(ccs, pk, fullWitness, backend.WithAccelerator(NewAccelerator(clients...)))
+			assert.NoError(err)
+			assert.NoError(groth16.Verify(proof, vk, publicWitness))
+		})
+	}
+}
+
+type failingClient struct{}
+
+func (failingClient) Call(serviceMethod string, args interface{}, reply interface{}) error {
+	return errors.New("worker unavailable")
+}
+
+func TestFailingWorker(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &cubicCircuit{})
+	assert.NoError(err)
+	pk, _, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	fullWitness, err := frontend.NewWitness(&cubicCircuit{X: 3, Y: 27}, ecc.BN254)
+	assert.NoError(err)
+
+	// FFTs fall back to the coordinator, but MSMs fail
+	clients := append(startWorkers(t, 1), failingClient{})
+	_, err = groth16.Prove(ccs, pk, fullWitness, backend.WithAccelerator(NewAccelerator(clients...)))
+	assert.Error(err)
+}
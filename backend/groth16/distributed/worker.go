@@ -0,0 +1,115 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distributed
+
+import (
+	"net"
+	"net/rpc"
+
+	"github.com/consensys/gnark-crypto/ecc"
+
+	groth16_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/groth16"
+	groth16_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/groth16"
+	groth16_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/groth16"
+	groth16_bn254 "github.com/consensys/gnark/internal/backend/bn254/groth16"
+	groth16_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/groth16"
+	groth16_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/groth16"
+)
+
+// Worker computes the shards of the MSMs and FFTs sent by an Accelerator. Its methods follow the
+// conventions of net/rpc.
+type Worker struct{}
+
+// Serve accepts connections on l and serves a Worker to each of them with net/rpc.
+// It blocks until l fails.
+func Serve(l net.Listener) error {
+	server := rpc.NewServer()
+	if err := server.Register(&Worker{}); err != nil {
+		return err
+	}
+	server.Accept(l)
+	return nil
+}
+
+// MultiExp computes the MSM of a shard
+func (w *Worker) MultiExp(task *MultiExpTask, res *Result) error {
+	var err error
+	switch task.Curve {
+	case ecc.BLS12_377:
+		if task.G2 {
+			res.Data, err = groth16_bls12377.MultiExpShardG2(task.Points, task.Scalars, task.ScalarsMont)
+		} else {
+			res.Data, err = groth16_bls12377.MultiExpShardG1(task.Points, task.Scalars, task.ScalarsMont)
+		}
+	case ecc.BLS12_381:
+		if task.G2 {
+			res.Data, err = groth16_bls12381.MultiExpShardG2(task.Points, task.Scalars, task.ScalarsMont)
+		} else {
+			res.Data, err = groth16_bls12381.MultiExpShardG1(task.Points, task.Scalars, task.ScalarsMont)
+		}
+	case ecc.BLS24_315:
+		if task.G2 {
+			res.Data, err = groth16_bls24315.MultiExpShardG2(task.Points, task.Scalars, task.ScalarsMont)
+		} else {
+			res.Data, err = groth16_bls24315.MultiExpShardG1(task.Points, task.Scalars, task.ScalarsMont)
+		}
+	case ecc.BN254:
+		if task.G2 {
+			res.Data, err = groth16_bn254.MultiExpShardG2(task.Points, task.Scalars, task.ScalarsMont)
+		} else {
+			res.Data, err = groth16_bn254.MultiExpShardG1(task.Points, task.Scalars, task.ScalarsMont)
+		}
+	case ecc.BW6_633:
+		if task.G2 {
+			res.Data, err = groth16_bw6633.MultiExpShardG2(task.Points, task.Scalars, task.ScalarsMont)
+		} else {
+			res.Data, err = groth16_bw6633.MultiExpShardG1(task.Points, task.Scalars, task.ScalarsMont)
+		}
+	case ecc.BW6_761:
+		if task.G2 {
+			res.Data, err = groth16_bw6761.MultiExpShardG2(task.Points, task.Scalars, task.ScalarsMont)
+		} else {
+			res.Data, err = groth16_bw6761.MultiExpShardG1(task.Points, task.Scalars, task.ScalarsMont)
+		}
+	default:
+		return errUnsupportedTypes
+	}
+	return err
+}
+
+// FFT computes the FFTs of a shard
+func (w *Worker) FFT(task *FFTTask, res *Result) error {
+	var err error
+	switch task.Curve {
+	case ecc.BLS12_377:
+		res.Data, err = groth16_bls12377.FFTShard(task.N, task.Inverse, task.Omega, task.Offset, task.Polynomials)
+	case ecc.BLS12_381:
+		res.Data, err = groth16_bls12381.FFTShard(task.N, task.Inverse, task.Omega, task.Offset, task.Polynomials)
+	case ecc.BLS24_315:
+		res.Data, err = groth16_bls24315.FFTShard(task.N, task.Inverse, task.Omega, task.Offset, task.Polynomials)
+	case ecc.BN254:
+		res.Data, err = groth16_bn254.FFTShard(task.N, task.Inverse, task.Omega, task.Offset, task.Polynomials)
+	case ecc.BW6_633:
+		res.Data, err = groth16_bw6633.FFTShard(task.N, task.Inverse, task.Omega, task.Offset, task.Polynomials)
+	case ecc.BW6_761:
+		res.Data, err = groth16_bw6761.FFTShard(task.N, task.Inverse, task.Omega, task.Offset, task.Polynomials)
+	default:
+		return errUnsupportedTypes
+	}
+	return err
+}
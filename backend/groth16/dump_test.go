@@ -0,0 +1,56 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+package groth16
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMmapProvingKey(t *testing.T) {
+	for _, curveID := range []ecc.ID{ecc.BN254, ecc.BLS12_377, ecc.BLS12_381, ecc.BLS24_315, ecc.BW6_633, ecc.BW6_761} {
+		t.Run(curveID.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			ccs, err := frontend.Compile(curveID, r1cs.NewBuilder, &aggregationCircuit{})
+			assert.NoError(err)
+			pk, vk, err := Setup(ccs)
+			assert.NoError(err)
+
+			path := filepath.Join(t.TempDir(), "pk.dump")
+			f, err := os.Create(path)
+			assert.NoError(err)
+			_, err = pk.WriteDump(f)
+			assert.NoError(err)
+			assert.NoError(f.Close())
+
+			mmapped, closer, err := MmapProvingKey(curveID, path)
+			assert.NoError(err)
+			defer closer.Close()
+			assert.False(pk.IsDifferent(mmapped), "mmapped key differs from the original one")
+
+			fullWitness, err := frontend.NewWitness(&aggregationCircuit{X: 3, Y: 27}, curveID)
+			assert.NoError(err)
+			publicWitness, err := fullWitness.Public()
+			assert.NoError(err)
+			proof, err := Prove(ccs, mmapped, fullWitness)
+			assert.NoError(err)
+			assert.NoError(Verify(proof, vk, publicWitness))
+
+			// a dump of another curve is rejected
+			other := ecc.BN254
+			if curveID == ecc.BN254 {
+				other = ecc.BLS12_381
+			}
+			_, _, err = MmapProvingKey(other, path)
+			assert.Error(err)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package groth16
+
+import (
+	"fmt"
+	"io"
+)
+
+// Encoding is a byte layout of the proofs and verifying keys, to exchange them
+// with other Groth16 implementations
+type Encoding uint8
+
+const (
+	// GnarkEncoding is the layout of the WriteTo and ReadFrom methods
+	GnarkEncoding Encoding = iota
+
+	// ArkworksEncoding is the compressed layout of the CanonicalSerialize and
+	// CanonicalDeserialize traits of ark-groth16 0.4, for BN254 and BLS12-381
+	ArkworksEncoding
+
+	// BellmanEncoding is the layout of the write and read methods of bellman
+	// (Zcash), for BLS12-381
+	BellmanEncoding
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case GnarkEncoding:
+		return "gnark"
+	case ArkworksEncoding:
+		return "arkworks"
+	case BellmanEncoding:
+		return "bellman"
+	default:
+		return "unknown"
+	}
+}
+
+// EncodingOption selects the Encoding of Marshal and Unmarshal
+type EncodingOption func(*Encoding) error
+
+// WithArkworksEncoding selects ArkworksEncoding
+func WithArkworksEncoding() EncodingOption {
+	return func(e *Encoding) error {
+		*e = ArkworksEncoding
+		return nil
+	}
+}
+
+// WithBellmanEncoding selects BellmanEncoding
+func WithBellmanEncoding() EncodingOption {
+	return func(e *Encoding) error {
+		*e = BellmanEncoding
+		return nil
+	}
+}
+
+type arkworksMarshaler interface {
+	WriteArkworksTo(w io.Writer) (int64, error)
+	ReadArkworksFrom(r io.Reader) (int64, error)
+}
+
+type bellmanMarshaler interface {
+	WriteBellmanTo(w io.Writer) (int64, error)
+	ReadBellmanFrom(r io.Reader) (int64, error)
+}
+
+// Marshal writes the Proof or VerifyingKey obj to w, in GnarkEncoding unless
+// another encoding is selected by the options.
+//
+// Proving keys and objects of circuits with commitments (see
+// frontend.API.Commit) can only be marshalled with GnarkEncoding: the other
+// implementations don't share the QAP of gnark nor support commitments.
+func Marshal(w io.Writer, obj groth16Object, opts ...EncodingOption) (int64, error) {
+	encoding, err := newEncoding(opts)
+	if err != nil {
+		return 0, err
+	}
+	switch encoding {
+	case ArkworksEncoding:
+		if m, ok := obj.(arkworksMarshaler); ok {
+			return m.WriteArkworksTo(w)
+		}
+	case BellmanEncoding:
+		if m, ok := obj.(bellmanMarshaler); ok {
+			return m.WriteBellmanTo(w)
+		}
+	default:
+		return obj.WriteTo(w)
+	}
+	return 0, errUnsupportedEncoding(obj, encoding)
+}
+
+// Unmarshal reads obj from r, written by Marshal with the same options
+func Unmarshal(r io.Reader, obj groth16Object, opts ...EncodingOption) (int64, error) {
+	encoding, err := newEncoding(opts)
+	if err != nil {
+		return 0, err
+	}
+	switch encoding {
+	case ArkworksEncoding:
+		if m, ok := obj.(arkworksMarshaler); ok {
+			return m.ReadArkworksFrom(r)
+		}
+	case BellmanEncoding:
+		if m, ok := obj.(bellmanMarshaler); ok {
+			return m.ReadBellmanFrom(r)
+		}
+	default:
+		return obj.ReadFrom(r)
+	}
+	return 0, errUnsupportedEncoding(obj, encoding)
+}
+
+func newEncoding(opts []EncodingOption) (Encoding, error) {
+	encoding := GnarkEncoding
+	for _, opt := range opts {
+		if err := opt(&encoding); err != nil {
+			return GnarkEncoding, err
+		}
+	}
+	return encoding, nil
+}
+
+func errUnsupportedEncoding(obj groth16Object, encoding Encoding) error {
+	return fmt.Errorf("%s encoding of %T over %s is not supported", encoding, obj, obj.CurveID())
+}
@@ -0,0 +1,89 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+package groth16
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	gnarkio "github.com/consensys/gnark/io"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalEncodings(t *testing.T) {
+	for _, test := range []struct {
+		curveID ecc.ID
+		opts    []EncodingOption
+	}{
+		{ecc.BN254, nil},
+		{ecc.BN254, []EncodingOption{WithArkworksEncoding()}},
+		{ecc.BLS12_381, []EncodingOption{WithArkworksEncoding()}},
+		{ecc.BLS12_381, []EncodingOption{WithBellmanEncoding()}},
+	} {
+		encoding, err := newEncoding(test.opts)
+		require.NoError(t, err)
+		t.Run(test.curveID.String()+"/"+encoding.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			ccs, err := frontend.Compile(test.curveID, r1cs.NewBuilder, &aggregationCircuit{})
+			assert.NoError(err)
+			pk, vk, err := Setup(ccs)
+			assert.NoError(err)
+			fullWitness, err := frontend.NewWitness(&aggregationCircuit{X: 3, Y: 27}, test.curveID)
+			assert.NoError(err)
+			publicWitness, err := fullWitness.Public()
+			assert.NoError(err)
+			proof, err := Prove(ccs, pk, fullWitness)
+			assert.NoError(err)
+
+			var buf bytes.Buffer
+			n, err := Marshal(&buf, proof, test.opts...)
+			assert.NoError(err)
+			assert.Equal(int64(buf.Len()), n)
+			decodedProof := NewProof(test.curveID)
+			n, err = Unmarshal(&buf, decodedProof, test.opts...)
+			assert.NoError(err)
+			assert.Equal(0, buf.Len())
+			assert.NotZero(n)
+
+			n, err = Marshal(&buf, vk, test.opts...)
+			assert.NoError(err)
+			assert.Equal(int64(buf.Len()), n)
+			decodedVK := NewVerifyingKey(test.curveID)
+			_, err = Unmarshal(&buf, decodedVK, test.opts...)
+			assert.NoError(err)
+			assert.Equal(0, buf.Len())
+
+			assert.NoError(Verify(decodedProof, decodedVK, publicWitness))
+
+			// proving keys are only marshalled in the gnark encoding
+			if encoding != GnarkEncoding {
+				_, err = Marshal(&buf, pk, test.opts...)
+				assert.Error(err)
+			}
+		})
+	}
+
+	// bellman doesn't define an encoding for BN254
+	_, err := Marshal(&bytes.Buffer{}, NewProof(ecc.BN254), WithBellmanEncoding())
+	require.Error(t, err)
+}
+
+func TestHeaderMismatch(t *testing.T) {
+	assert := require.New(t)
+
+	var buf bytes.Buffer
+	_, err := NewProof(ecc.BN254).WriteTo(&buf)
+	assert.NoError(err)
+	_, err = NewProof(ecc.BLS12_381).ReadFrom(bytes.NewReader(buf.Bytes()))
+	assert.True(errors.Is(err, gnarkio.ErrHeaderMismatch), "expected a header mismatch, got %v", err)
+	_, err = NewVerifyingKey(ecc.BN254).ReadFrom(bytes.NewReader(buf.Bytes()))
+	assert.True(errors.Is(err, gnarkio.ErrHeaderMismatch), "expected a header mismatch, got %v", err)
+	_, err = NewProof(ecc.BN254).ReadFrom(bytes.NewReader(buf.Bytes()))
+	assert.NoError(err)
+}
@@ -17,21 +17,17 @@
 // See also
 //
 // https://eprint.iacr.org/2016/260.pdf
+//
+// Built with the verifieronly tag, the package only holds the verifier (see
+// examples/wasm): the setup and the prover are left out.
 package groth16
 
 import (
 	"io"
 
 	"github.com/consensys/gnark-crypto/ecc"
-	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/solidity"
 	"github.com/consensys/gnark/backend/witness"
-	"github.com/consensys/gnark/frontend"
-	backend_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/cs"
-	backend_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/cs"
-	backend_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/cs"
-	backend_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
-	backend_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/cs"
-	backend_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/cs"
 
 	witness_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/witness"
 	witness_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/witness"
@@ -64,22 +60,6 @@ type Proof interface {
 	groth16Object
 }
 
-// ProvingKey represents a Groth16 ProvingKey
-//
-// it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
-type ProvingKey interface {
-	groth16Object
-	gnarkio.UnsafeReaderFrom
-
-	// NbG1 returns the number of G1 elements in the ProvingKey
-	NbG1() int
-
-	// NbG2 returns the number of G2 elements in the ProvingKey
-	NbG2() int
-
-	IsDifferent(interface{}) bool
-}
-
 // VerifyingKey represents a Groth16 VerifyingKey
 //
 // it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
@@ -100,9 +80,13 @@ type VerifyingKey interface {
 
 	// ExportSolidity writes a solidity Verifier contract from the VerifyingKey
 	// this will return an error if not supported on the CurveID()
-	ExportSolidity(w io.Writer) error
+	ExportSolidity(w io.Writer, exportOpts ...solidity.ExportOption) error
 
 	IsDifferent(interface{}) bool
+
+	// Fingerprint returns the fingerprint of the circuit the key is set up for,
+	// equal to the Fingerprint of its constraint system
+	Fingerprint() [32]byte
 }
 
 // Verify runs the groth16.Verify algorithm on provided proof with given witness
@@ -150,188 +134,29 @@ func Verify(proof Proof, vk VerifyingKey, publicWitness *witness.Witness) error
 	}
 }
 
-// Prove runs the groth16.Prove algorithm.
-//
-// if the force flag is set:
-// 	will executes all the prover computations, even if the witness is invalid
-//  will produce an invalid proof
-//	internally, the solution vector to the R1CS will be filled with random values which may impact benchmarking
-func Prove(r1cs frontend.CompiledConstraintSystem, pk ProvingKey, fullWitness *witness.Witness, opts ...backend.ProverOption) (Proof, error) {
-
-	// apply options
-	opt, err := backend.NewProverConfig(opts...)
-	if err != nil {
-		return nil, err
-	}
-
-	switch _r1cs := r1cs.(type) {
-	case *backend_bls12377.R1CS:
-		w, ok := fullWitness.Vector.(*witness_bls12377.Witness)
-		if !ok {
-			return nil, witness.ErrInvalidWitness
-		}
-		return groth16_bls12377.Prove(_r1cs, pk.(*groth16_bls12377.ProvingKey), *w, opt)
-	case *backend_bls12381.R1CS:
-		w, ok := fullWitness.Vector.(*witness_bls12381.Witness)
-		if !ok {
-			return nil, witness.ErrInvalidWitness
-		}
-		return groth16_bls12381.Prove(_r1cs, pk.(*groth16_bls12381.ProvingKey), *w, opt)
-	case *backend_bn254.R1CS:
-		w, ok := fullWitness.Vector.(*witness_bn254.Witness)
-		if !ok {
-			return nil, witness.ErrInvalidWitness
-		}
-		return groth16_bn254.Prove(_r1cs, pk.(*groth16_bn254.ProvingKey), *w, opt)
-	case *backend_bw6761.R1CS:
-		w, ok := fullWitness.Vector.(*witness_bw6761.Witness)
-		if !ok {
-			return nil, witness.ErrInvalidWitness
-		}
-		return groth16_bw6761.Prove(_r1cs, pk.(*groth16_bw6761.ProvingKey), *w, opt)
-	case *backend_bls24315.R1CS:
-		w, ok := fullWitness.Vector.(*witness_bls24315.Witness)
-		if !ok {
-			return nil, witness.ErrInvalidWitness
-		}
-		return groth16_bls24315.Prove(_r1cs, pk.(*groth16_bls24315.ProvingKey), *w, opt)
-	case *backend_bw6633.R1CS:
-		w, ok := fullWitness.Vector.(*witness_bw6633.Witness)
-		if !ok {
-			return nil, witness.ErrInvalidWitness
-		}
-		return groth16_bw6633.Prove(_r1cs, pk.(*groth16_bw6633.ProvingKey), *w, opt)
-	default:
-		panic("unrecognized R1CS curve type")
-	}
-}
-
-// Setup runs groth16.Setup with provided R1CS and outputs a key pair associated with the circuit.
-//
-// Note that careful consideration must be given to this step in production environment.
-// groth16.Setup uses some randomness to precompute the Proving and Verifying keys. If the process
-// or machine leaks this randomness, an attacker could break the ZKP protocol.
-//
-// Two main solutions to this deployment issues are: running the Setup through a MPC (multi party computation)
-// or using a ZKP backend like PLONK where the per-circuit Setup is deterministic.
-func Setup(r1cs frontend.CompiledConstraintSystem) (ProvingKey, VerifyingKey, error) {
-
-	switch _r1cs := r1cs.(type) {
-	case *backend_bls12377.R1CS:
-		var pk groth16_bls12377.ProvingKey
-		var vk groth16_bls12377.VerifyingKey
-		if err := groth16_bls12377.Setup(_r1cs, &pk, &vk); err != nil {
-			return nil, nil, err
-		}
-		return &pk, &vk, nil
-	case *backend_bls12381.R1CS:
-		var pk groth16_bls12381.ProvingKey
-		var vk groth16_bls12381.VerifyingKey
-		if err := groth16_bls12381.Setup(_r1cs, &pk, &vk); err != nil {
-			return nil, nil, err
-		}
-		return &pk, &vk, nil
-	case *backend_bn254.R1CS:
-		var pk groth16_bn254.ProvingKey
-		var vk groth16_bn254.VerifyingKey
-		if err := groth16_bn254.Setup(_r1cs, &pk, &vk); err != nil {
-			return nil, nil, err
-		}
-		return &pk, &vk, nil
-	case *backend_bw6761.R1CS:
-		var pk groth16_bw6761.ProvingKey
-		var vk groth16_bw6761.VerifyingKey
-		if err := groth16_bw6761.Setup(_r1cs, &pk, &vk); err != nil {
-			return nil, nil, err
-		}
-		return &pk, &vk, nil
-	case *backend_bls24315.R1CS:
-		var pk groth16_bls24315.ProvingKey
-		var vk groth16_bls24315.VerifyingKey
-		if err := groth16_bls24315.Setup(_r1cs, &pk, &vk); err != nil {
-			return nil, nil, err
-		}
-		return &pk, &vk, nil
-	case *backend_bw6633.R1CS:
-		var pk groth16_bw6633.ProvingKey
-		var vk groth16_bw6633.VerifyingKey
-		if err := groth16_bw6633.Setup(_r1cs, &pk, &vk); err != nil {
-			return nil, nil, err
-		}
-		return &pk, &vk, nil
-	default:
-		panic("unrecognized R1CS curve type")
-	}
-}
-
-// DummySetup create a random ProvingKey with provided R1CS
-// it doesn't return a VerifyingKey and is use for benchmarking or test purposes only.
-func DummySetup(r1cs frontend.CompiledConstraintSystem) (ProvingKey, error) {
-	switch _r1cs := r1cs.(type) {
-	case *backend_bls12377.R1CS:
-		var pk groth16_bls12377.ProvingKey
-		if err := groth16_bls12377.DummySetup(_r1cs, &pk); err != nil {
-			return nil, err
-		}
-		return &pk, nil
-	case *backend_bls12381.R1CS:
-		var pk groth16_bls12381.ProvingKey
-		if err := groth16_bls12381.DummySetup(_r1cs, &pk); err != nil {
-			return nil, err
-		}
-		return &pk, nil
-	case *backend_bn254.R1CS:
-		var pk groth16_bn254.ProvingKey
-		if err := groth16_bn254.DummySetup(_r1cs, &pk); err != nil {
-			return nil, err
-		}
-		return &pk, nil
-	case *backend_bw6761.R1CS:
-		var pk groth16_bw6761.ProvingKey
-		if err := groth16_bw6761.DummySetup(_r1cs, &pk); err != nil {
-			return nil, err
-		}
-		return &pk, nil
-	case *backend_bls24315.R1CS:
-		var pk groth16_bls24315.ProvingKey
-		if err := groth16_bls24315.DummySetup(_r1cs, &pk); err != nil {
-			return nil, err
-		}
-		return &pk, nil
-	case *backend_bw6633.R1CS:
-		var pk groth16_bw6633.ProvingKey
-		if err := groth16_bw6633.DummySetup(_r1cs, &pk); err != nil {
-			return nil, err
-		}
-		return &pk, nil
+// Rerandomize re-randomizes proof in place, with randomness read from rng
+// (crypto/rand.Reader if rng is nil): the proof still verifies against vk and
+// the same public witness, but can't be linked to the original proof, for
+// example by a relayer publishing the proofs it receives.
+func Rerandomize(proof Proof, vk VerifyingKey, rng io.Reader) error {
+	switch _proof := proof.(type) {
+	case *groth16_bls12377.Proof:
+		return _proof.Rerandomize(vk.(*groth16_bls12377.VerifyingKey), rng)
+	case *groth16_bls12381.Proof:
+		return _proof.Rerandomize(vk.(*groth16_bls12381.VerifyingKey), rng)
+	case *groth16_bn254.Proof:
+		return _proof.Rerandomize(vk.(*groth16_bn254.VerifyingKey), rng)
+	case *groth16_bw6761.Proof:
+		return _proof.Rerandomize(vk.(*groth16_bw6761.VerifyingKey), rng)
+	case *groth16_bls24315.Proof:
+		return _proof.Rerandomize(vk.(*groth16_bls24315.VerifyingKey), rng)
+	case *groth16_bw6633.Proof:
+		return _proof.Rerandomize(vk.(*groth16_bw6633.VerifyingKey), rng)
 	default:
 		panic("unrecognized R1CS curve type")
 	}
 }
 
-// NewProvingKey instantiates a curve-typed ProvingKey and returns an interface object
-// This function exists for serialization purposes
-func NewProvingKey(curveID ecc.ID) ProvingKey {
-	var pk ProvingKey
-	switch curveID {
-	case ecc.BN254:
-		pk = &groth16_bn254.ProvingKey{}
-	case ecc.BLS12_377:
-		pk = &groth16_bls12377.ProvingKey{}
-	case ecc.BLS12_381:
-		pk = &groth16_bls12381.ProvingKey{}
-	case ecc.BW6_761:
-		pk = &groth16_bw6761.ProvingKey{}
-	case ecc.BLS24_315:
-		pk = &groth16_bls24315.ProvingKey{}
-	case ecc.BW6_633:
-		pk = &groth16_bw6633.ProvingKey{}
-	default:
-		panic("not implemented")
-	}
-	return pk
-}
-
 // NewVerifyingKey instantiates a curve-typed VerifyingKey and returns an interface
 // This function exists for serialization purposes
 func NewVerifyingKey(curveID ecc.ID) VerifyingKey {
@@ -379,26 +204,3 @@ func NewProof(curveID ecc.ID) Proof {
 
 	return proof
 }
-
-// NewCS instantiate a concrete curved-typed R1CS and return a R1CS interface
-// This method exists for (de)serialization purposes
-func NewCS(curveID ecc.ID) frontend.CompiledConstraintSystem {
-	var r1cs frontend.CompiledConstraintSystem
-	switch curveID {
-	case ecc.BN254:
-		r1cs = &backend_bn254.R1CS{}
-	case ecc.BLS12_377:
-		r1cs = &backend_bls12377.R1CS{}
-	case ecc.BLS12_381:
-		r1cs = &backend_bls12381.R1CS{}
-	case ecc.BW6_761:
-		r1cs = &backend_bw6761.R1CS{}
-	case ecc.BLS24_315:
-		r1cs = &backend_bls24315.R1CS{}
-	case ecc.BW6_633:
-		r1cs = &backend_bw6633.R1CS{}
-	default:
-		panic("not implemented")
-	}
-	return r1cs
-}
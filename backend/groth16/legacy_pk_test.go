@@ -0,0 +1,32 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+package groth16
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProveLegacyEncodings(t *testing.T) {
+	assert := require.New(t)
+
+	pk, vk := NewProvingKey(ecc.BN254), NewVerifyingKey(ecc.BN254)
+	readLegacy(t, pk, legacyPK)
+	readLegacy(t, vk, legacyVK)
+
+	// the proving key still proves
+	witness, err := frontend.NewWitness(&legacyCircuit{X: 3, Y: 9}, ecc.BN254)
+	assert.NoError(err)
+	publicWitness, err := witness.Public()
+	assert.NoError(err)
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &legacyCircuit{})
+	assert.NoError(err)
+	proof, err := Prove(ccs, pk, witness)
+	assert.NoError(err)
+	assert.NoError(Verify(proof, vk, publicWitness))
+}
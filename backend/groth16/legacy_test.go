@@ -0,0 +1,50 @@
+package groth16
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/require"
+)
+
+type legacyCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *legacyCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	return nil
+}
+
+// keys and proof of legacyCircuit over BN254, for X = 3 and Y = 9, written by
+// gnark before the encodings had a header
+const (
+	legacyPK    = "AAAAAAAAAAIYMic5cJjQFNwoIttAwKwulBn0JDzcuEih8PrJ+AAAATBkTnLhMaApuFBFtoGBWF0oM+hIeblwkUPh9ZPwAAAAMGROcuExoCm4UEW2gYFYXSgz6Eh5uXCRQ+H1k/AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABRNbUpRaE9mqSbm1fDPNVouprlzpykotBufz+9TGZmZnrTQcUMIKctkI6xKqrNLXU0VlqfO6/aFi7nLMvi+Lq0POBab7CKKzAQrZ21hCq/kpwHIzKTVi7TisKsHC84zcqtZY8ECqieBsCWR+5K+cIgvptz0ZSFdi0fwqzxME9N98AAAAArBLGkidKGAtWrbYLpcCcjqnchXVgFzsOUwkGI1c0I0J7HUaAODRJy6pmtwJor2gW+bBL7VxCTMFPg3ZJM62n6wAAAAC7HUaAODRJy6pmtwJor2gW+bBL7VxCTMFPg3ZJM62n6ywSxpInShgLVq22C6XAnI6p3IV1YBc7DlMJBiNXNCNCQAAAALMuAu/r5ANPsAZRmJmPuF8Ly2n74uA1eGFjTslbIwX6dFjhR30icfXSdfDKouGkSbTSNd85E9Zhm7ZEF7j/cFGAAAAAqWzZLww10p/+q7Qu9RfhnXCJ7aW538JzrTwYfUFjQLnijy7GMvs4ZnhO/J4Y07mVri3SmDcZri+ysdxbd0C3T/qFHUeGb2BBLbPa1k/w6TXtLEBQahEBPMkENEU0/+hISFyjxSVDUzVI/e8Xx7dWRTjzafS3ZACKjye531I9SUNzlEJr/LYJTnYYxRgUjNkPVeBwt7/Husp5DMUDynRpTMAIvTmwl3vUC/0eKlYmbo7Xl9CrdSzt2EZSau+ygsgZwAAAAKSrPDTu5okfw4kY+yDQGm3L5fOXBUn/B2OjhXKvPLmQg1pqSU2iZAZD+sUFvv147AWuxCTCD5EkHXlvnOONpRpj/HQbIQ6e1NwrkW3a5SMFC9rdcBIHUPUTEMzVaaILXgqJ2c0ugosRSyIspwJRMFYuGMG7DXeAANUydV7gRpz5gAAAAAAAAAEAAAAAAAAAAIAAAAAAAAAAgABAAEBAQAA"
+	legacyVK    = "rTQcUMIKctkI6xKqrNLXU0VlqfO6/aFi7nLMvi+Lq0POBab7CKKzAQrZ21hCq/kpwHIzKTVi7TisKsHC84zcquoUdR4ZvYEEts9rWT/DpNe0sQFBqEQE8yQQ0RTT/6EhIXKPFJUNTNUj97xfHt1ZFOPNp9LdkAIqPJ7nfUj1JQ2vK048PvfUY5pRkrDi9ntMoVy7OmolZK1q4tEpe6FOFgkTBF+sYoINJqi7kers+uns46Fmu1GFjzX4m9o6dQ8U1ljwQKqJ4GwJZH7kr5wiC+m3PRlIV2LR/CrPEwT033zOUQmv8tglOdhjFGBSM2Q9V4HC3v8e6ynkMxQPKdGlMwAi9ObCXe9QL/R4qViZujteX0Kt1LO3YRlJq77KCyBnAAAAAphxtrHXF2nK3zS1R47M3P0zMUVKEM8HlW9jpDphsXV45CongeEK6ZnKP7R95dv+Zxh9zzvgqh+k1iayDYp0s5U="
+	legacyProof = "0qpssQq0UBLOMgpaO/8RvStnv1mLj7p4RkXDicemFt7PBR+3MJmk0jDfH5XmkpM0ttycYzfxE6w26+YfV/T8IQ7j8gJHgGDppWRLu0bHazOeYZfeuaItUaMOKVobx2O0wy1y1F8fJ2xQvtvZriMh9897ScqxwkGF/E9mBbqPVII="
+)
+
+func readLegacy(t *testing.T, o io.ReaderFrom, encoded string) {
+	b, err := base64.StdEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+	n, err := o.ReadFrom(bytes.NewReader(b))
+	require.NoError(t, err)
+	require.Equal(t, int64(len(b)), n)
+}
+
+func TestReadLegacyEncodings(t *testing.T) {
+	assert := require.New(t)
+
+	vk, proof := NewVerifyingKey(ecc.BN254), NewProof(ecc.BN254)
+	readLegacy(t, vk, legacyVK)
+	readLegacy(t, proof, legacyProof)
+
+	witness, err := frontend.NewWitness(&legacyCircuit{X: 3, Y: 9}, ecc.BN254, frontend.PublicOnly())
+	assert.NoError(err)
+	assert.NoError(Verify(proof, vk, witness))
+}
@@ -0,0 +1,249 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package groth16
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve_bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	curve_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	curve_bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315"
+	curve_bn254 "github.com/consensys/gnark-crypto/ecc/bn254"
+	curve_bw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633"
+	curve_bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761"
+
+	groth16_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/groth16"
+	groth16_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/groth16"
+	groth16_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/groth16"
+	groth16_bn254 "github.com/consensys/gnark/internal/backend/bn254/groth16"
+	groth16_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/groth16"
+	groth16_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/groth16"
+)
+
+// PedersenKey is a key of Pedersen vector commitments in G1, to which the
+// commitment of a Groth16 proof can be linked (see Link)
+//
+// it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
+type PedersenKey interface {
+	CurveID() ecc.ID
+
+	// NbValues returns the number of values committed to with the key
+	NbValues() int
+}
+
+// LinkProof proves that the commitment of a Groth16 proof (see frontend.API.Commit)
+// and an external Pedersen commitment open to the same values, without revealing
+// them. Two proofs linked to the same external commitment share their committed
+// values.
+//
+// it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
+type LinkProof interface {
+	io.WriterTo
+	io.ReaderFrom
+	CurveID() ecc.ID
+}
+
+// NewPedersenKey returns the Pedersen key of nbValues values derived from seed,
+// whose points are hashed to G1
+func NewPedersenKey(curveID ecc.ID, nbValues int, seed []byte) (PedersenKey, error) {
+	switch curveID {
+	case ecc.BLS12_377:
+		return groth16_bls12377.NewPedersenKey(nbValues, seed)
+	case ecc.BLS12_381:
+		return groth16_bls12381.NewPedersenKey(nbValues, seed)
+	case ecc.BN254:
+		return groth16_bn254.NewPedersenKey(nbValues, seed)
+	case ecc.BW6_761:
+		return groth16_bw6761.NewPedersenKey(nbValues, seed)
+	case ecc.BLS24_315:
+		return groth16_bls24315.NewPedersenKey(nbValues, seed)
+	case ecc.BW6_633:
+		return groth16_bw6633.NewPedersenKey(nbValues, seed)
+	default:
+		panic("not implemented")
+	}
+}
+
+// Commit returns the compressed encoding of the commitment to values with
+// randomness
+func Commit(key PedersenKey, values []*big.Int, randomness *big.Int) ([]byte, error) {
+	switch _key := key.(type) {
+	case *groth16_bls12377.PedersenKey:
+		c, err := _key.Commit(values, randomness)
+		if err != nil {
+			return nil, err
+		}
+		b := c.Bytes()
+		return b[:], nil
+	case *groth16_bls12381.PedersenKey:
+		c, err := _key.Commit(values, randomness)
+		if err != nil {
+			return nil, err
+		}
+		b := c.Bytes()
+		return b[:], nil
+	case *groth16_bn254.PedersenKey:
+		c, err := _key.Commit(values, randomness)
+		if err != nil {
+			return nil, err
+		}
+		b := c.Bytes()
+		return b[:], nil
+	case *groth16_bw6761.PedersenKey:
+		c, err := _key.Commit(values, randomness)
+		if err != nil {
+			return nil, err
+		}
+		b := c.Bytes()
+		return b[:], nil
+	case *groth16_bls24315.PedersenKey:
+		c, err := _key.Commit(values, randomness)
+		if err != nil {
+			return nil, err
+		}
+		b := c.Bytes()
+		return b[:], nil
+	case *groth16_bw6633.PedersenKey:
+		c, err := _key.Commit(values, randomness)
+		if err != nil {
+			return nil, err
+		}
+		b := c.Bytes()
+		return b[:], nil
+	default:
+		panic("unrecognized curve type")
+	}
+}
+
+// NewLinkProof instantiates a curve-typed LinkProof and returns an interface
+// This function exists for serialization purposes
+func NewLinkProof(curveID ecc.ID) LinkProof {
+	switch curveID {
+	case ecc.BLS12_377:
+		return &groth16_bls12377.LinkProof{}
+	case ecc.BLS12_381:
+		return &groth16_bls12381.LinkProof{}
+	case ecc.BN254:
+		return &groth16_bn254.LinkProof{}
+	case ecc.BW6_761:
+		return &groth16_bw6761.LinkProof{}
+	case ecc.BLS24_315:
+		return &groth16_bls24315.LinkProof{}
+	case ecc.BW6_633:
+		return &groth16_bw6633.LinkProof{}
+	default:
+		panic("not implemented")
+	}
+}
+
+// Link returns a LinkProof between the commitment of proof and the external
+// commitment (see Commit) with the key and randomness, reading the randomness
+// of the LinkProof from rng (crypto/rand.Reader if rng is nil).
+//
+// proof must have been returned by Prove, which keeps the opening of its
+// commitment: the committed wires, in increasing wire ID order, must have the
+// values of the external commitment.
+func Link(proof Proof, vk VerifyingKey, key PedersenKey, commitment []byte, randomness *big.Int, rng io.Reader) (LinkProof, error) {
+	switch _proof := proof.(type) {
+	case *groth16_bls12377.Proof:
+		var c curve_bls12377.G1Affine
+		if _, err := c.SetBytes(commitment); err != nil {
+			return nil, err
+		}
+		return groth16_bls12377.Link(_proof, vk.(*groth16_bls12377.VerifyingKey), key.(*groth16_bls12377.PedersenKey), &c, randomness, rng)
+	case *groth16_bls12381.Proof:
+		var c curve_bls12381.G1Affine
+		if _, err := c.SetBytes(commitment); err != nil {
+			return nil, err
+		}
+		return groth16_bls12381.Link(_proof, vk.(*groth16_bls12381.VerifyingKey), key.(*groth16_bls12381.PedersenKey), &c, randomness, rng)
+	case *groth16_bn254.Proof:
+		var c curve_bn254.G1Affine
+		if _, err := c.SetBytes(commitment); err != nil {
+			return nil, err
+		}
+		return groth16_bn254.Link(_proof, vk.(*groth16_bn254.VerifyingKey), key.(*groth16_bn254.PedersenKey), &c, randomness, rng)
+	case *groth16_bw6761.Proof:
+		var c curve_bw6761.G1Affine
+		if _, err := c.SetBytes(commitment); err != nil {
+			return nil, err
+		}
+		return groth16_bw6761.Link(_proof, vk.(*groth16_bw6761.VerifyingKey), key.(*groth16_bw6761.PedersenKey), &c, randomness, rng)
+	case *groth16_bls24315.Proof:
+		var c curve_bls24315.G1Affine
+		if _, err := c.SetBytes(commitment); err != nil {
+			return nil, err
+		}
+		return groth16_bls24315.Link(_proof, vk.(*groth16_bls24315.VerifyingKey), key.(*groth16_bls24315.PedersenKey), &c, randomness, rng)
+	case *groth16_bw6633.Proof:
+		var c curve_bw6633.G1Affine
+		if _, err := c.SetBytes(commitment); err != nil {
+			return nil, err
+		}
+		return groth16_bw6633.Link(_proof, vk.(*groth16_bw6633.VerifyingKey), key.(*groth16_bw6633.PedersenKey), &c, randomness, rng)
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+}
+
+// VerifyLink returns nil if link proves that the commitment of proof and the
+// external commitment with the key open to the same values. proof itself must
+// be verified with Verify.
+func VerifyLink(link LinkProof, proof Proof, vk VerifyingKey, key PedersenKey, commitment []byte) error {
+	switch _link := link.(type) {
+	case *groth16_bls12377.LinkProof:
+		var c curve_bls12377.G1Affine
+		if _, err := c.SetBytes(commitment); err != nil {
+			return err
+		}
+		return _link.Verify(proof.(*groth16_bls12377.Proof), vk.(*groth16_bls12377.VerifyingKey), key.(*groth16_bls12377.PedersenKey), &c)
+	case *groth16_bls12381.LinkProof:
+		var c curve_bls12381.G1Affine
+		if _, err := c.SetBytes(commitment); err != nil {
+			return err
+		}
+		return _link.Verify(proof.(*groth16_bls12381.Proof), vk.(*groth16_bls12381.VerifyingKey), key.(*groth16_bls12381.PedersenKey), &c)
+	case *groth16_bn254.LinkProof:
+		var c curve_bn254.G1Affine
+		if _, err := c.SetBytes(commitment); err != nil {
+			return err
+		}
+		return _link.Verify(proof.(*groth16_bn254.Proof), vk.(*groth16_bn254.VerifyingKey), key.(*groth16_bn254.PedersenKey), &c)
+	case *groth16_bw6761.LinkProof:
+		var c curve_bw6761.G1Affine
+		if _, err := c.SetBytes(commitment); err != nil {
+			return err
+		}
+		return _link.Verify(proof.(*groth16_bw6761.Proof), vk.(*groth16_bw6761.VerifyingKey), key.(*groth16_bw6761.PedersenKey), &c)
+	case *groth16_bls24315.LinkProof:
+		var c curve_bls24315.G1Affine
+		if _, err := c.SetBytes(commitment); err != nil {
+			return err
+		}
+		return _link.Verify(proof.(*groth16_bls24315.Proof), vk.(*groth16_bls24315.VerifyingKey), key.(*groth16_bls24315.PedersenKey), &c)
+	case *groth16_bw6633.LinkProof:
+		var c curve_bw6633.G1Affine
+		if _, err := c.SetBytes(commitment); err != nil {
+			return err
+		}
+		return _link.Verify(proof.(*groth16_bw6633.Proof), vk.(*groth16_bw6633.VerifyingKey), key.(*groth16_bw6633.PedersenKey), &c)
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+}
@@ -0,0 +1,108 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+package groth16
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+// linkProductCircuit and linkSumCircuit commit to the same secrets X and Y
+type linkProductCircuit struct {
+	X, Y frontend.Variable
+	P    frontend.Variable `gnark:",public"`
+}
+
+func (circuit *linkProductCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.Y), circuit.P)
+	api.AssertIsDifferent(api.Commit(circuit.X, circuit.Y), 0)
+	return nil
+}
+
+type linkSumCircuit struct {
+	X, Y frontend.Variable
+	S    frontend.Variable `gnark:",public"`
+}
+
+func (circuit *linkSumCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Add(circuit.X, circuit.Y), circuit.S)
+	api.AssertIsDifferent(api.Commit(circuit.X, circuit.Y), 0)
+	return nil
+}
+
+func TestLink(t *testing.T) {
+	for _, curveID := range []ecc.ID{ecc.BN254, ecc.BLS12_377, ecc.BLS12_381, ecc.BLS24_315, ecc.BW6_633, ecc.BW6_761} {
+		t.Run(curveID.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			// the external commitment to X = 3, Y = 5
+			key, err := NewPedersenKey(curveID, 2, []byte("link"))
+			assert.NoError(err)
+			randomness := big.NewInt(42)
+			commitment, err := Commit(key, []*big.Int{big.NewInt(3), big.NewInt(5)}, randomness)
+			assert.NoError(err)
+			otherCommitment, err := Commit(key, []*big.Int{big.NewInt(5), big.NewInt(3)}, randomness)
+			assert.NoError(err)
+
+			prove := func(circuit, assignment frontend.Circuit) (Proof, VerifyingKey) {
+				ccs, err := frontend.Compile(curveID, r1cs.NewBuilder, circuit)
+				assert.NoError(err)
+				pk, vk, err := Setup(ccs)
+				assert.NoError(err)
+				fullWitness, err := frontend.NewWitness(assignment, curveID)
+				assert.NoError(err)
+				publicWitness, err := fullWitness.Public()
+				assert.NoError(err)
+				proof, err := Prove(ccs, pk, fullWitness)
+				assert.NoError(err)
+				assert.NoError(Verify(proof, vk, publicWitness))
+				return proof, vk
+			}
+			productProof, productVk := prove(&linkProductCircuit{}, &linkProductCircuit{X: 3, Y: 5, P: 15})
+			sumProof, sumVk := prove(&linkSumCircuit{}, &linkSumCircuit{X: 3, Y: 5, S: 8})
+
+			// both proofs are linked to the external commitment
+			productLink, err := Link(productProof, productVk, key, commitment, randomness, nil)
+			assert.NoError(err)
+			assert.NoError(VerifyLink(productLink, productProof, productVk, key, commitment))
+			sumLink, err := Link(sumProof, sumVk, key, commitment, randomness, nil)
+			assert.NoError(err)
+			assert.NoError(VerifyLink(sumLink, sumProof, sumVk, key, commitment))
+
+			// serialization round trip
+			var buf bytes.Buffer
+			_, err = sumLink.WriteTo(&buf)
+			assert.NoError(err)
+			decoded := NewLinkProof(curveID)
+			_, err = decoded.ReadFrom(&buf)
+			assert.NoError(err)
+			assert.NoError(VerifyLink(decoded, sumProof, sumVk, key, commitment))
+
+			// a link holds only for its proof and commitment
+			assert.Error(VerifyLink(productLink, sumProof, sumVk, key, commitment))
+			assert.Error(VerifyLink(productLink, productProof, productVk, key, otherCommitment))
+
+			// a proof can't be linked to a commitment to other values
+			wrongLink, err := Link(productProof, productVk, key, otherCommitment, randomness, nil)
+			assert.NoError(err)
+			assert.Error(VerifyLink(wrongLink, productProof, productVk, key, otherCommitment))
+
+			// a decoded proof doesn't hold the opening of its commitment
+			buf.Reset()
+			_, err = productProof.WriteTo(&buf)
+			assert.NoError(err)
+			decodedProof := NewProof(curveID)
+			_, err = decodedProof.ReadFrom(&buf)
+			assert.NoError(err)
+			_, err = Link(decodedProof, productVk, key, commitment, randomness, nil)
+			assert.Error(err)
+		})
+	}
+}
@@ -0,0 +1,183 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mpcsetup
+
+import (
+	"crypto/sha256"
+	"io"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+)
+
+// WriteTo implements io.WriterTo
+func (phase1 *Phase1) WriteTo(writer io.Writer) (int64, error) {
+	n, err := phase1.writeTo(writer)
+	if err != nil {
+		return n, err
+	}
+	nBytes, err := writer.Write(phase1.Hash)
+	return int64(nBytes) + n, err
+}
+
+// writeTo encodes phase1 without its hash
+func (phase1 *Phase1) writeTo(writer io.Writer) (int64, error) {
+	toEncode := []interface{}{
+		&phase1.PublicKeys.Tau.SG,
+		&phase1.PublicKeys.Tau.SXG,
+		&phase1.PublicKeys.Tau.XR,
+		&phase1.PublicKeys.Alpha.SG,
+		&phase1.PublicKeys.Alpha.SXG,
+		&phase1.PublicKeys.Alpha.XR,
+		&phase1.PublicKeys.Beta.SG,
+		&phase1.PublicKeys.Beta.SXG,
+		&phase1.PublicKeys.Beta.XR,
+		phase1.Parameters.G1.Tau,
+		phase1.Parameters.G1.AlphaTau,
+		phase1.Parameters.G1.BetaTau,
+		phase1.Parameters.G2.Tau,
+		&phase1.Parameters.G2.Beta,
+	}
+
+	enc := curve.NewEncoder(writer)
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+	return enc.BytesWritten(), nil
+}
+
+// ReadFrom implements io.ReaderFrom
+func (phase1 *Phase1) ReadFrom(reader io.Reader) (int64, error) {
+	toEncode := []interface{}{
+		&phase1.PublicKeys.Tau.SG,
+		&phase1.PublicKeys.Tau.SXG,
+		&phase1.PublicKeys.Tau.XR,
+		&phase1.PublicKeys.Alpha.SG,
+		&phase1.PublicKeys.Alpha.SXG,
+		&phase1.PublicKeys.Alpha.XR,
+		&phase1.PublicKeys.Beta.SG,
+		&phase1.PublicKeys.Beta.SXG,
+		&phase1.PublicKeys.Beta.XR,
+		&phase1.Parameters.G1.Tau,
+		&phase1.Parameters.G1.AlphaTau,
+		&phase1.Parameters.G1.BetaTau,
+		&phase1.Parameters.G2.Tau,
+		&phase1.Parameters.G2.Beta,
+	}
+
+	dec := curve.NewDecoder(reader)
+	for _, v := range toEncode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+	phase1.Hash = make([]byte, sha256.Size)
+	nBytes, err := io.ReadFull(reader, phase1.Hash)
+	return dec.BytesRead() + int64(nBytes), err
+}
+
+// WriteTo implements io.WriterTo
+func (phase2 *Phase2) WriteTo(writer io.Writer) (int64, error) {
+	n, err := phase2.writeTo(writer)
+	if err != nil {
+		return n, err
+	}
+	nBytes, err := writer.Write(phase2.Hash)
+	return int64(nBytes) + n, err
+}
+
+// writeTo encodes phase2 without its hash
+func (phase2 *Phase2) writeTo(writer io.Writer) (int64, error) {
+	toEncode := []interface{}{
+		&phase2.PublicKey.SG,
+		&phase2.PublicKey.SXG,
+		&phase2.PublicKey.XR,
+		&phase2.Parameters.G1.Delta,
+		phase2.Parameters.G1.L,
+		phase2.Parameters.G1.Z,
+		&phase2.Parameters.G2.Delta,
+	}
+
+	enc := curve.NewEncoder(writer)
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+	return enc.BytesWritten(), nil
+}
+
+// ReadFrom implements io.ReaderFrom
+func (phase2 *Phase2) ReadFrom(reader io.Reader) (int64, error) {
+	toEncode := []interface{}{
+		&phase2.PublicKey.SG,
+		&phase2.PublicKey.SXG,
+		&phase2.PublicKey.XR,
+		&phase2.Parameters.G1.Delta,
+		&phase2.Parameters.G1.L,
+		&phase2.Parameters.G1.Z,
+		&phase2.Parameters.G2.Delta,
+	}
+
+	dec := curve.NewDecoder(reader)
+	for _, v := range toEncode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+	phase2.Hash = make([]byte, sha256.Size)
+	nBytes, err := io.ReadFull(reader, phase2.Hash)
+	return dec.BytesRead() + int64(nBytes), err
+}
+
+// WriteTo implements io.WriterTo
+func (c *Phase2Evaluations) WriteTo(writer io.Writer) (int64, error) {
+	toEncode := []interface{}{
+		c.G1.A,
+		c.G1.B,
+		c.G1.VKK,
+		c.G2.B,
+	}
+
+	enc := curve.NewEncoder(writer)
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+	return enc.BytesWritten(), nil
+}
+
+// ReadFrom implements io.ReaderFrom
+func (c *Phase2Evaluations) ReadFrom(reader io.Reader) (int64, error) {
+	toEncode := []interface{}{
+		&c.G1.A,
+		&c.G1.B,
+		&c.G1.VKK,
+		&c.G2.B,
+	}
+
+	dec := curve.NewDecoder(reader)
+	for _, v := range toEncode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+	return dec.BytesRead(), nil
+}
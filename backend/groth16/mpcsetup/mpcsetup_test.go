@@ -0,0 +1,195 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mpcsetup
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+type cubicCircuit struct {
+	X frontend.Variable `gnark:"x"`
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *cubicCircuit) Define(api frontend.API) error {
+	x3 := api.Mul(circuit.X, circuit.X, circuit.X)
+	api.AssertIsEqual(circuit.Y, api.Add(x3, circuit.X, 5))
+	return nil
+}
+
+func TestSetupCircuit(t *testing.T) {
+	const (
+		nContributionsPhase1 = 3
+		nContributionsPhase2 = 3
+		power                = 4
+	)
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &cubicCircuit{})
+	assert.NoError(err)
+
+	// phase 1
+	srs1 := InitPhase1(power)
+	contributions1 := []*Phase1{clonePhase1(&srs1)}
+	for i := 0; i < nContributionsPhase1; i++ {
+		srs1.Contribute()
+		contributions1 = append(contributions1, clonePhase1(&srs1))
+	}
+	srs1.ContributeWithBeacon([]byte("beacon 1"), 8)
+	contributions1 = append(contributions1, clonePhase1(&srs1))
+	assert.NoError(VerifyPhase1(contributions1[0], contributions1[1], contributions1[2:]...))
+
+	// phase 2
+	srs2, evals, err := InitPhase2(ccs, &srs1)
+	assert.NoError(err)
+	contributions2 := []*Phase2{clonePhase2(&srs2)}
+	for i := 0; i < nContributionsPhase2; i++ {
+		srs2.Contribute()
+		contributions2 = append(contributions2, clonePhase2(&srs2))
+	}
+	srs2.ContributeWithBeacon([]byte("beacon 2"), 8)
+	contributions2 = append(contributions2, clonePhase2(&srs2))
+	assert.NoError(VerifyPhase2(contributions2[0], contributions2[1], contributions2[2:]...))
+
+	// extract the keys and prove
+	pk, vk, err := ExtractKeys(&srs1, &srs2, &evals, ccs.GetNbConstraints())
+	assert.NoError(err)
+
+	witness, err := frontend.NewWitness(&cubicCircuit{X: 3, Y: 35}, ecc.BN254)
+	assert.NoError(err)
+	publicWitness, err := witness.Public()
+	assert.NoError(err)
+	proof, err := groth16.Prove(ccs, pk, witness)
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, vk, publicWitness))
+
+	wrongWitness, err := frontend.NewWitness(&cubicCircuit{X: 3, Y: 36}, ecc.BN254, frontend.PublicOnly())
+	assert.NoError(err)
+	assert.Error(groth16.Verify(proof, vk, wrongWitness))
+
+	// serialization round trip of the evaluations
+	var buf bytes.Buffer
+	_, err = evals.WriteTo(&buf)
+	assert.NoError(err)
+	var _evals Phase2Evaluations
+	_, err = _evals.ReadFrom(&buf)
+	assert.NoError(err)
+	assert.Equal(evals, _evals)
+}
+
+func TestVerifyTamperedContribution(t *testing.T) {
+	assert := require.New(t)
+
+	// phase 1: contribution not based on the previous one
+	srs1 := InitPhase1(3)
+	srs1.Contribute()
+	prev := clonePhase1(&srs1)
+	srs1.Contribute()
+	other := InitPhase1(3)
+	other.Contribute()
+	other.Contribute()
+	assert.Error(VerifyPhase1(prev, &other))
+	assert.NoError(VerifyPhase1(prev, &srs1))
+
+	// phase 1: tampered power of τ
+	next := clonePhase1(&srs1)
+	next.Parameters.G1.Tau[2] = next.Parameters.G1.Tau[3]
+	next.Hash = next.hash()
+	assert.Error(VerifyPhase1(prev, next))
+
+	// phase 1: wrong hash
+	next = clonePhase1(&srs1)
+	next.Hash[0] ^= 1
+	assert.Error(VerifyPhase1(prev, next))
+
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &cubicCircuit{})
+	assert.NoError(err)
+
+	// phase 2: tampered L
+	srs2, _, err := InitPhase2(ccs, &srs1)
+	assert.NoError(err)
+	prev2 := clonePhase2(&srs2)
+	srs2.Contribute()
+	next2 := clonePhase2(&srs2)
+	next2.Parameters.G1.L[0] = next2.Parameters.G1.Z[0]
+	next2.Hash = next2.hash()
+	assert.Error(VerifyPhase2(prev2, next2))
+	assert.NoError(VerifyPhase2(prev2, &srs2))
+
+	// phase 1 too small for the circuit
+	_, _, err = InitPhase2(ccs, &Phase1{})
+	assert.Error(err)
+}
+
+func TestPhasesSerialization(t *testing.T) {
+	assert := require.New(t)
+
+	srs1 := InitPhase1(3)
+	srs1.Contribute()
+	var buf bytes.Buffer
+	_, err := srs1.WriteTo(&buf)
+	assert.NoError(err)
+	var _srs1 Phase1
+	_, err = _srs1.ReadFrom(&buf)
+	assert.NoError(err)
+	assert.Equal(srs1, _srs1)
+
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &cubicCircuit{})
+	assert.NoError(err)
+	srs2, _, err := InitPhase2(ccs, &srs1)
+	assert.NoError(err)
+	srs2.Contribute()
+	buf.Reset()
+	_, err = srs2.WriteTo(&buf)
+	assert.NoError(err)
+	var _srs2 Phase2
+	_, err = _srs2.ReadFrom(&buf)
+	assert.NoError(err)
+	assert.Equal(srs2, _srs2)
+}
+
+func clonePhase1(phase1 *Phase1) *Phase1 {
+	var buf bytes.Buffer
+	if _, err := phase1.WriteTo(&buf); err != nil {
+		panic(err)
+	}
+	var res Phase1
+	if _, err := res.ReadFrom(&buf); err != nil {
+		panic(err)
+	}
+	return &res
+}
+
+func clonePhase2(phase2 *Phase2) *Phase2 {
+	var buf bytes.Buffer
+	if _, err := phase2.WriteTo(&buf); err != nil {
+		panic(err)
+	}
+	var res Phase2
+	if _, err := res.ReadFrom(&buf); err != nil {
+		panic(err)
+	}
+	return &res
+}
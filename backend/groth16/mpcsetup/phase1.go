@@ -0,0 +1,212 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mpcsetup
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// Phase1 represents the Phase1 of the MPC described in
+// https://eprint.iacr.org/2017/1050.pdf
+//
+// Also known as "Powers of Tau"
+type Phase1 struct {
+	Parameters struct {
+		G1 struct {
+			Tau      []curve.G1Affine // {[τ⁰]₁, [τ¹]₁, [τ²]₁, …, [τ²ⁿ⁻¹]₁}
+			AlphaTau []curve.G1Affine // {α[τ⁰]₁, α[τ¹]₁, α[τ²]₁, …, α[τⁿ⁻¹]₁}
+			BetaTau  []curve.G1Affine // {β[τ⁰]₁, β[τ¹]₁, β[τ²]₁, …, β[τⁿ⁻¹]₁}
+		}
+		G2 struct {
+			Tau  []curve.G2Affine // {[τ⁰]₂, [τ¹]₂, [τ²]₂, …, [τⁿ⁻¹]₂}
+			Beta curve.G2Affine   // [β]₂
+		}
+	}
+	PublicKeys struct {
+		Tau, Alpha, Beta PublicKey
+	}
+	Hash []byte // sha256 hash
+}
+
+// InitPhase1 initialize phase 1 of the MPC. This is called once by the coordinator before
+// any randomness contribution is made (see Contribute()).
+// It supports circuits of up to 2ᵖᵒʷᵉʳ constraints.
+func InitPhase1(power int) (phase1 Phase1) {
+	N := int(1 << power)
+
+	_, _, g1, g2 := curve.Generators()
+
+	phase1.Parameters.G1.Tau = make([]curve.G1Affine, 2*N)
+	phase1.Parameters.G1.AlphaTau = make([]curve.G1Affine, N)
+	phase1.Parameters.G1.BetaTau = make([]curve.G1Affine, N)
+	phase1.Parameters.G2.Tau = make([]curve.G2Affine, N)
+	for i := range phase1.Parameters.G1.Tau {
+		phase1.Parameters.G1.Tau[i] = g1
+	}
+	for i := 0; i < N; i++ {
+		phase1.Parameters.G1.AlphaTau[i] = g1
+		phase1.Parameters.G1.BetaTau[i] = g1
+		phase1.Parameters.G2.Tau[i] = g2
+	}
+	phase1.Parameters.G2.Beta = g2
+
+	// Compute hash of Contribution
+	phase1.Hash = phase1.hash()
+
+	return
+}
+
+// Contribute contributes randomness to the phase1 object. This mutates phase1.
+func (phase1 *Phase1) Contribute() {
+	phase1.contribute(randomSecrets(6))
+}
+
+// ContributeWithBeacon makes a contribution derived from a public random beacon, hashed
+// nbIterations times, usually to finalize the phase. Anyone can check it by running it
+// again on the previous contribution.
+func (phase1 *Phase1) ContributeWithBeacon(beacon []byte, nbIterations int) {
+	phase1.contribute(beaconSecrets(6, beacon, nbIterations))
+}
+
+// contribute updates phase1 with the secrets τ, α, β, followed by the secrets of the
+// proofs of knowledge
+func (phase1 *Phase1) contribute(secrets []fr.Element) {
+	N := len(phase1.Parameters.G2.Tau)
+	tau, alpha, beta := secrets[0], secrets[1], secrets[2]
+
+	// compute the public keys, with the previous hash as challenge
+	phase1.PublicKeys.Tau = newPublicKey(tau, secrets[3], phase1.Hash[:], 1)
+	phase1.PublicKeys.Alpha = newPublicKey(alpha, secrets[4], phase1.Hash[:], 2)
+	phase1.PublicKeys.Beta = newPublicKey(beta, secrets[5], phase1.Hash[:], 3)
+
+	// multiply the powers by τⁱ, ατⁱ, βτⁱ
+	taus := powers(tau, 2*N)
+	alphaTaus := make([]fr.Element, N)
+	betaTaus := make([]fr.Element, N)
+	for i := 0; i < N; i++ {
+		alphaTaus[i].Mul(&taus[i], &alpha)
+		betaTaus[i].Mul(&taus[i], &beta)
+	}
+	scaleG1(phase1.Parameters.G1.Tau, taus)
+	scaleG1(phase1.Parameters.G1.AlphaTau, alphaTaus)
+	scaleG1(phase1.Parameters.G1.BetaTau, betaTaus)
+	scaleG2(phase1.Parameters.G2.Tau, taus[:N])
+	var betaBI big.Int
+	beta.ToBigIntRegular(&betaBI)
+	phase1.Parameters.G2.Beta.ScalarMultiplication(&phase1.Parameters.G2.Beta, &betaBI)
+
+	// Compute hash of Contribution
+	phase1.Hash = phase1.hash()
+}
+
+// VerifyPhase1 checks that each contribution of the phase 1 transcript is valid,
+// and based on the previous one
+func VerifyPhase1(c0, c1 *Phase1, c ...*Phase1) error {
+	contribs := append([]*Phase1{c0, c1}, c...)
+	for i := 0; i < len(contribs)-1; i++ {
+		if err := verifyPhase1(contribs[i], contribs[i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyPhase1 checks that a contribution is based on a known previous Phase1 state.
+func verifyPhase1(current, contribution *Phase1) error {
+	N := len(current.Parameters.G2.Tau)
+	if len(contribution.Parameters.G2.Tau) != N ||
+		len(contribution.Parameters.G1.Tau) != 2*N ||
+		len(contribution.Parameters.G1.AlphaTau) != N ||
+		len(contribution.Parameters.G1.BetaTau) != N {
+		return errors.New("contribution doesn't have the size of the previous one")
+	}
+
+	// Check for knowledge of toxic parameters
+	if !contribution.PublicKeys.Tau.verify(current.Hash, 1) {
+		return errors.New("couldn't verify public key of τ")
+	}
+	if !contribution.PublicKeys.Alpha.verify(current.Hash, 2) {
+		return errors.New("couldn't verify public key of α")
+	}
+	if !contribution.PublicKeys.Beta.verify(current.Hash, 3) {
+		return errors.New("couldn't verify public key of β")
+	}
+
+	// Check for valid updates using previous parameters
+	tauR := genR(contribution.PublicKeys.Tau.SG, contribution.PublicKeys.Tau.SXG, current.Hash, 1)
+	alphaR := genR(contribution.PublicKeys.Alpha.SG, contribution.PublicKeys.Alpha.SXG, current.Hash, 2)
+	betaR := genR(contribution.PublicKeys.Beta.SG, contribution.PublicKeys.Beta.SXG, current.Hash, 3)
+	if !sameRatio(contribution.Parameters.G1.Tau[1], current.Parameters.G1.Tau[1], contribution.PublicKeys.Tau.XR, tauR) {
+		return errors.New("couldn't verify that [τ]₁ is based on previous contribution")
+	}
+	if !sameRatio(contribution.Parameters.G1.AlphaTau[0], current.Parameters.G1.AlphaTau[0], contribution.PublicKeys.Alpha.XR, alphaR) {
+		return errors.New("couldn't verify that [α]₁ is based on previous contribution")
+	}
+	if !sameRatio(contribution.Parameters.G1.BetaTau[0], current.Parameters.G1.BetaTau[0], contribution.PublicKeys.Beta.XR, betaR) {
+		return errors.New("couldn't verify that [β]₁ is based on previous contribution")
+	}
+	if !sameRatio(contribution.PublicKeys.Tau.SXG, contribution.PublicKeys.Tau.SG, contribution.Parameters.G2.Tau[1], current.Parameters.G2.Tau[1]) {
+		return errors.New("couldn't verify that [τ]₂ is based on previous contribution")
+	}
+	if !sameRatio(contribution.PublicKeys.Beta.SXG, contribution.PublicKeys.Beta.SG, contribution.Parameters.G2.Beta, current.Parameters.G2.Beta) {
+		return errors.New("couldn't verify that [β]₂ is based on previous contribution")
+	}
+
+	// Check for valid updates using powers of τ
+	_, _, g1, g2 := curve.Generators()
+	if !contribution.Parameters.G1.Tau[0].Equal(&g1) || !contribution.Parameters.G2.Tau[0].Equal(&g2) {
+		return errors.New("couldn't verify that [τ⁰] are the generators")
+	}
+	tauL1, tauL2 := linearCombinationG1(contribution.Parameters.G1.Tau)
+	if !sameRatio(tauL2, tauL1, contribution.Parameters.G2.Tau[1], g2) {
+		return errors.New("couldn't verify valid powers of τ in G₁")
+	}
+	alphaL1, alphaL2 := linearCombinationG1(contribution.Parameters.G1.AlphaTau)
+	if !sameRatio(alphaL2, alphaL1, contribution.Parameters.G2.Tau[1], g2) {
+		return errors.New("couldn't verify valid powers of α(τ) in G₁")
+	}
+	betaL1, betaL2 := linearCombinationG1(contribution.Parameters.G1.BetaTau)
+	if !sameRatio(betaL2, betaL1, contribution.Parameters.G2.Tau[1], g2) {
+		return errors.New("couldn't verify valid powers of β(τ) in G₁")
+	}
+	tau2L1, tau2L2 := linearCombinationG2(contribution.Parameters.G2.Tau)
+	if !sameRatio(contribution.Parameters.G1.Tau[1], g1, tau2L2, tau2L1) {
+		return errors.New("couldn't verify valid powers of τ in G₂")
+	}
+
+	// Check hash of the contribution
+	if !bytes.Equal(contribution.Hash, contribution.hash()) {
+		return errors.New("couldn't verify hash of contribution")
+	}
+
+	return nil
+}
+
+func (phase1 *Phase1) hash() []byte {
+	sha := sha256.New()
+	if _, err := phase1.writeTo(sha); err != nil {
+		panic(err)
+	}
+	return sha.Sum(nil)
+}
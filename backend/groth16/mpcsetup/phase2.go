@@ -0,0 +1,272 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mpcsetup
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/compiled"
+	"github.com/consensys/gnark/internal/backend/bn254/cs"
+)
+
+// Phase2Evaluations holds the evaluations of the circuit polynomials at τ, computed once
+// from the result of phase 1 by InitPhase2. They are not modified by phase 2 contributions.
+type Phase2Evaluations struct {
+	G1 struct {
+		A, B, VKK []curve.G1Affine
+	}
+	G2 struct {
+		B []curve.G2Affine
+	}
+}
+
+// Phase2 represents the circuit specific Phase2 of the MPC described in
+// https://eprint.iacr.org/2017/1050.pdf
+type Phase2 struct {
+	Parameters struct {
+		G1 struct {
+			Delta curve.G1Affine
+			L, Z  []curve.G1Affine
+		}
+		G2 struct {
+			Delta curve.G2Affine
+		}
+	}
+	PublicKey PublicKey
+	Hash      []byte
+}
+
+// InitPhase2 initialize phase 2 of the MPC from the last contribution of phase 1 and the
+// compiled circuit. This is called once by the coordinator before any randomness
+// contribution is made (see Contribute()).
+func InitPhase2(r1cs frontend.CompiledConstraintSystem, srs1 *Phase1) (Phase2, Phase2Evaluations, error) {
+	var c2 Phase2
+	var evals Phase2Evaluations
+
+	_r1cs, ok := r1cs.(*cs.R1CS)
+	if !ok {
+		return c2, evals, errors.New("mpcsetup: only BN254 R1CS are supported")
+	}
+	if _r1cs.Commitment.Is() {
+		return c2, evals, errors.New("mpcsetup: circuits with a commitment are not supported")
+	}
+
+	domain := fft.NewDomain(uint64(len(_r1cs.Constraints)))
+	n := int(domain.Cardinality)
+	if len(srs1.Parameters.G2.Tau) < n {
+		return c2, evals, fmt.Errorf("mpcsetup: phase 1 supports %d constraints, circuit has %d", len(srs1.Parameters.G2.Tau), len(_r1cs.Constraints))
+	}
+
+	// Lagrange basis of the domain, evaluated at τ
+	tauL1 := lagrangeCoeffsG1(srs1.Parameters.G1.Tau[:n], domain)
+	alphaTauL1 := lagrangeCoeffsG1(srs1.Parameters.G1.AlphaTau[:n], domain)
+	betaTauL1 := lagrangeCoeffsG1(srs1.Parameters.G1.BetaTau[:n], domain)
+	tauL2 := lagrangeCoeffsG2(srs1.Parameters.G2.Tau[:n], domain)
+
+	nbWires := _r1cs.NbInternalVariables + _r1cs.NbPublicVariables + _r1cs.NbSecretVariables
+
+	// A(τ), B(τ) and βA(τ) + αB(τ) + C(τ) for each wire
+	A := make([]curve.G1Jac, nbWires)
+	B := make([]curve.G1Jac, nbWires)
+	B2 := make([]curve.G2Jac, nbWires)
+	KK := make([]curve.G1Jac, nbWires)
+
+	coeffs := make([]big.Int, len(_r1cs.Coefficients))
+	for i := range _r1cs.Coefficients {
+		_r1cs.Coefficients[i].ToBigIntRegular(&coeffs[i])
+	}
+	var two, minusOne big.Int
+	two.SetUint64(2)
+	minusOne.SetInt64(-1)
+	coefficient := func(t compiled.Term) *big.Int {
+		switch cID := t.CoeffID(); cID {
+		case compiled.CoeffIdZero:
+			return nil
+		case compiled.CoeffIdOne:
+			return big.NewInt(1)
+		case compiled.CoeffIdMinusOne:
+			return &minusOne
+		case compiled.CoeffIdTwo:
+			return &two
+		default:
+			return &coeffs[cID]
+		}
+	}
+	accumulateG1 := func(res *curve.G1Jac, t compiled.Term, value *curve.G1Affine) {
+		c := coefficient(t)
+		if c == nil {
+			return
+		}
+		var tmp curve.G1Affine
+		tmp.ScalarMultiplication(value, c)
+		res.AddMixed(&tmp)
+	}
+	accumulateG2 := func(res *curve.G2Jac, t compiled.Term, value *curve.G2Affine) {
+		c := coefficient(t)
+		if c == nil {
+			return
+		}
+		var tmp curve.G2Affine
+		tmp.ScalarMultiplication(value, c)
+		res.AddMixed(&tmp)
+	}
+
+	for i, c := range _r1cs.Constraints {
+		for _, t := range c.L {
+			accumulateG1(&A[t.WireID()], t, &tauL1[i])
+			accumulateG1(&KK[t.WireID()], t, &betaTauL1[i])
+		}
+		for _, t := range c.R {
+			accumulateG1(&B[t.WireID()], t, &tauL1[i])
+			accumulateG2(&B2[t.WireID()], t, &tauL2[i])
+			accumulateG1(&KK[t.WireID()], t, &alphaTauL1[i])
+		}
+		for _, t := range c.O {
+			accumulateG1(&KK[t.WireID()], t, &tauL1[i])
+		}
+	}
+
+	evals.G1.A = make([]curve.G1Affine, nbWires)
+	evals.G1.B = make([]curve.G1Affine, nbWires)
+	evals.G2.B = make([]curve.G2Affine, nbWires)
+	kk := make([]curve.G1Affine, nbWires)
+	for i := 0; i < nbWires; i++ {
+		evals.G1.A[i].FromJacobian(&A[i])
+		evals.G1.B[i].FromJacobian(&B[i])
+		evals.G2.B[i].FromJacobian(&B2[i])
+		kk[i].FromJacobian(&KK[i])
+	}
+
+	// public part is divided by γ = 1, private part by δ (1 before the first contribution)
+	evals.G1.VKK = kk[:_r1cs.NbPublicVariables]
+	c2.Parameters.G1.L = kk[_r1cs.NbPublicVariables:]
+
+	// Z = [τⁱ(τⁿ - 1)]₁ for i < n
+	c2.Parameters.G1.Z = make([]curve.G1Affine, n)
+	for i := range c2.Parameters.G1.Z {
+		c2.Parameters.G1.Z[i].Sub(&srs1.Parameters.G1.Tau[i+n], &srs1.Parameters.G1.Tau[i])
+	}
+
+	_, _, g1, g2 := curve.Generators()
+	c2.Parameters.G1.Delta = g1
+	c2.Parameters.G2.Delta = g2
+
+	// Compute hash of Contribution
+	c2.Hash = c2.hash()
+
+	return c2, evals, nil
+}
+
+// Contribute contributes randomness to the phase2 object. This mutates phase2.
+func (phase2 *Phase2) Contribute() {
+	phase2.contribute(randomSecrets(2))
+}
+
+// ContributeWithBeacon makes a contribution derived from a public random beacon, hashed
+// nbIterations times, usually to finalize the phase. Anyone can check it by running it
+// again on the previous contribution.
+func (phase2 *Phase2) ContributeWithBeacon(beacon []byte, nbIterations int) {
+	phase2.contribute(beaconSecrets(2, beacon, nbIterations))
+}
+
+// contribute updates phase2 with the secret δ, followed by the secret of the proof of knowledge
+func (phase2 *Phase2) contribute(secrets []fr.Element) {
+	delta := secrets[0]
+	var deltaInv fr.Element
+	deltaInv.Inverse(&delta)
+
+	// compute the public key, with the previous hash as challenge
+	phase2.PublicKey = newPublicKey(delta, secrets[1], phase2.Hash[:], 1)
+
+	var deltaBI big.Int
+	delta.ToBigIntRegular(&deltaBI)
+
+	// multiply δ by the secret, L and Z by its inverse
+	phase2.Parameters.G1.Delta.ScalarMultiplication(&phase2.Parameters.G1.Delta, &deltaBI)
+	phase2.Parameters.G2.Delta.ScalarMultiplication(&phase2.Parameters.G2.Delta, &deltaBI)
+	scaleG1(phase2.Parameters.G1.L, constants(deltaInv, len(phase2.Parameters.G1.L)))
+	scaleG1(phase2.Parameters.G1.Z, constants(deltaInv, len(phase2.Parameters.G1.Z)))
+
+	// Compute hash of Contribution
+	phase2.Hash = phase2.hash()
+}
+
+// VerifyPhase2 checks that each contribution of the phase 2 transcript is valid,
+// and based on the previous one
+func VerifyPhase2(c0, c1 *Phase2, c ...*Phase2) error {
+	contribs := append([]*Phase2{c0, c1}, c...)
+	for i := 0; i < len(contribs)-1; i++ {
+		if err := verifyPhase2(contribs[i], contribs[i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyPhase2 checks that a contribution is based on a known previous Phase2 state.
+func verifyPhase2(current, contribution *Phase2) error {
+	if len(contribution.Parameters.G1.L) != len(current.Parameters.G1.L) ||
+		len(contribution.Parameters.G1.Z) != len(current.Parameters.G1.Z) {
+		return errors.New("contribution doesn't have the size of the previous one")
+	}
+
+	// Check for knowledge of δ
+	if !contribution.PublicKey.verify(current.Hash, 1) {
+		return errors.New("couldn't verify knowledge of δ")
+	}
+
+	// Check for valid updates using previous parameters
+	deltaR := genR(contribution.PublicKey.SG, contribution.PublicKey.SXG, current.Hash, 1)
+	if !sameRatio(contribution.Parameters.G1.Delta, current.Parameters.G1.Delta, contribution.PublicKey.XR, deltaR) {
+		return errors.New("couldn't verify that [δ]₁ is based on previous contribution")
+	}
+	if !sameRatio(contribution.PublicKey.SXG, contribution.PublicKey.SG, contribution.Parameters.G2.Delta, current.Parameters.G2.Delta) {
+		return errors.New("couldn't verify that [δ]₂ is based on previous contribution")
+	}
+
+	// Check for valid updates of L and Z using δ
+	prev := append(append([]curve.G1Affine{}, current.Parameters.G1.L...), current.Parameters.G1.Z...)
+	next := append(append([]curve.G1Affine{}, contribution.Parameters.G1.L...), contribution.Parameters.G1.Z...)
+	lCur, lNew := merge(prev, next)
+	if !sameRatio(lCur, lNew, contribution.Parameters.G2.Delta, current.Parameters.G2.Delta) {
+		return errors.New("couldn't verify valid updates of L and Z using δ⁻¹")
+	}
+
+	// Check hash of the contribution
+	if !bytes.Equal(contribution.Hash, contribution.hash()) {
+		return errors.New("couldn't verify hash of contribution")
+	}
+
+	return nil
+}
+
+func (phase2 *Phase2) hash() []byte {
+	sha := sha256.New()
+	if _, err := phase2.writeTo(sha); err != nil {
+		panic(err)
+	}
+	return sha.Sum(nil)
+}
@@ -0,0 +1,92 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mpcsetup
+
+import (
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/internal/backend/bn254/groth16"
+)
+
+// ExtractKeys builds the Groth16 ProvingKey and VerifyingKey of the circuit from the last
+// contributions of phase 1 and phase 2, and the evaluations computed by InitPhase2.
+// nConstraints is the number of constraints of the circuit.
+func ExtractKeys(srs1 *Phase1, srs2 *Phase2, evals *Phase2Evaluations, nConstraints int) (groth16.ProvingKey, groth16.VerifyingKey, error) {
+	var pk groth16_bn254.ProvingKey
+	var vk groth16_bn254.VerifyingKey
+
+	_, _, _, g2 := curve.Generators()
+
+	// Initialize PK
+	pk.Domain = *fft.NewDomain(uint64(nConstraints))
+	pk.G1.Alpha.Set(&srs1.Parameters.G1.AlphaTau[0])
+	pk.G1.Beta.Set(&srs1.Parameters.G1.BetaTau[0])
+	pk.G1.Delta.Set(&srs2.Parameters.G1.Delta)
+	pk.G1.Z = append([]curve.G1Affine{}, srs2.Parameters.G1.Z...)
+	bitReverse(len(pk.G1.Z), func(i, j int) { pk.G1.Z[i], pk.G1.Z[j] = pk.G1.Z[j], pk.G1.Z[i] })
+	pk.G1.K = append([]curve.G1Affine{}, srs2.Parameters.G1.L...)
+	pk.G2.Beta.Set(&srs1.Parameters.G2.Beta)
+	pk.G2.Delta.Set(&srs2.Parameters.G2.Delta)
+
+	setWirePolynomials(&pk, evals.G1.A, evals.G1.B, evals.G2.B)
+
+	// Initialize VK
+	vk.G1.Alpha.Set(&srs1.Parameters.G1.AlphaTau[0])
+	vk.G1.Beta.Set(&srs1.Parameters.G1.BetaTau[0])
+	vk.G1.Delta.Set(&srs2.Parameters.G1.Delta)
+	vk.G1.K = append([]curve.G1Affine{}, evals.G1.VKK...)
+	vk.G2.Beta.Set(&srs1.Parameters.G2.Beta)
+	vk.G2.Delta.Set(&srs2.Parameters.G2.Delta)
+	vk.G2.Gamma.Set(&g2)
+	if err := vk.Precompute(); err != nil {
+		return nil, nil, err
+	}
+
+	return &pk, &vk, nil
+}
+
+// setWirePolynomials sets [Aᵢ(τ)]₁, [Bᵢ(τ)]₁ and [Bᵢ(τ)]₂ in the ProvingKey, filtering out
+// the points at infinity as groth16.Setup does
+func setWirePolynomials(pk *groth16_bn254.ProvingKey, A, B []curve.G1Affine, B2 []curve.G2Affine) {
+	nWires := len(A)
+
+	pk.InfinityA = make([]bool, nWires)
+	pk.G1.A = make([]curve.G1Affine, 0, nWires)
+	for i := range A {
+		if A[i].IsInfinity() {
+			pk.InfinityA[i] = true
+			continue
+		}
+		pk.G1.A = append(pk.G1.A, A[i])
+	}
+	pk.NbInfinityA = uint64(nWires - len(pk.G1.A))
+
+	pk.InfinityB = make([]bool, nWires)
+	pk.G1.B = make([]curve.G1Affine, 0, nWires)
+	pk.G2.B = make([]curve.G2Affine, 0, nWires)
+	for i := range B {
+		if B[i].IsInfinity() {
+			pk.InfinityB[i] = true
+			continue
+		}
+		pk.G1.B = append(pk.G1.B, B[i])
+		pk.G2.B = append(pk.G2.B, B2[i])
+	}
+	pk.NbInfinityB = uint64(nWires - len(pk.G1.B))
+}
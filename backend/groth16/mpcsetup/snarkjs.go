@@ -0,0 +1,390 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mpcsetup
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/internal/backend/bn254/groth16"
+)
+
+// snarkjs (https://github.com/iden3/snarkjs) files are made of numbered sections,
+// points are stored uncompressed with coordinates in little endian Montgomery form
+const (
+	ptauSectionHeader   = 1
+	ptauSectionTauG1    = 2
+	ptauSectionTauG2    = 3
+	ptauSectionAlphaTau = 4
+	ptauSectionBetaTau  = 5
+	ptauSectionBetaG2   = 6
+
+	zkeySectionHeader        = 1
+	zkeySectionGroth16Header = 2
+	zkeySectionIC            = 3
+	zkeySectionA             = 5
+	zkeySectionB1            = 6
+	zkeySectionB2            = 7
+	zkeySectionC             = 8
+	zkeySectionH             = 9
+
+	zkeyProtocolGroth16 = 1
+)
+
+// ImportPtau reads a snarkjs Powers of Tau transcript (.ptau file) for BN254, and returns it
+// as a Phase1 from which InitPhase2 can be called.
+//
+// A transcript of power p holds [τⁱ]₁ for i < 2ᵖ⁺¹-1, whereas phase 2 needs 2n powers for
+// circuits of n constraints: the returned Phase1 supports circuits of up to 2ᵖ⁻¹ constraints.
+// It has no public keys, and is not meant to be verified with VerifyPhase1.
+func ImportPtau(r io.ReadSeeker) (Phase1, error) {
+	var phase1 Phase1
+
+	sections, err := readSections(r, "ptau")
+	if err != nil {
+		return phase1, err
+	}
+
+	// header: n8, q, power, ceremonyPower
+	sr, err := sections.open(r, ptauSectionHeader)
+	if err != nil {
+		return phase1, err
+	}
+	if err := readFieldHeader(sr, fp.Modulus()); err != nil {
+		return phase1, err
+	}
+	var power uint32
+	if err := binary.Read(sr, binary.LittleEndian, &power); err != nil {
+		return phase1, err
+	}
+	if power < 1 || power > 28 {
+		return phase1, fmt.Errorf("ptau: invalid power %d", power)
+	}
+	N := 1 << (power - 1)
+
+	phase1.Parameters.G1.Tau = make([]curve.G1Affine, 2*N)
+	phase1.Parameters.G1.AlphaTau = make([]curve.G1Affine, N)
+	phase1.Parameters.G1.BetaTau = make([]curve.G1Affine, N)
+	phase1.Parameters.G2.Tau = make([]curve.G2Affine, N)
+
+	toRead := []struct {
+		section uint32
+		g1      []curve.G1Affine
+		g2      []curve.G2Affine
+	}{
+		{section: ptauSectionTauG1, g1: phase1.Parameters.G1.Tau},
+		{section: ptauSectionAlphaTau, g1: phase1.Parameters.G1.AlphaTau},
+		{section: ptauSectionBetaTau, g1: phase1.Parameters.G1.BetaTau},
+		{section: ptauSectionTauG2, g2: phase1.Parameters.G2.Tau},
+		{section: ptauSectionBetaG2, g2: []curve.G2Affine{{}}},
+	}
+	for _, s := range toRead {
+		sr, err := sections.open(r, s.section)
+		if err != nil {
+			return phase1, err
+		}
+		if err := readG1Points(sr, s.g1); err != nil {
+			return phase1, fmt.Errorf("ptau: section %d: %w", s.section, err)
+		}
+		if err := readG2Points(sr, s.g2); err != nil {
+			return phase1, fmt.Errorf("ptau: section %d: %w", s.section, err)
+		}
+		if s.section == ptauSectionBetaG2 {
+			phase1.Parameters.G2.Beta = s.g2[0]
+		}
+	}
+
+	phase1.Hash = phase1.hash()
+
+	return phase1, nil
+}
+
+// ImportZkey reads a snarkjs Groth16 proving key (.zkey file) for BN254, and returns the
+// corresponding gnark keys.
+//
+// The keys are only valid for a constraint system identical to the one the zkey was generated
+// for: same wire ordering, same constraints, followed by the constraints 0 * wᵢ == 0 that
+// snarkjs appends for each public wire wᵢ (including the constant wire).
+func ImportZkey(r io.ReadSeeker) (groth16.ProvingKey, groth16.VerifyingKey, error) {
+	var pk groth16_bn254.ProvingKey
+	var vk groth16_bn254.VerifyingKey
+
+	sections, err := readSections(r, "zkey")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sr, err := sections.open(r, zkeySectionHeader)
+	if err != nil {
+		return nil, nil, err
+	}
+	var protocol uint32
+	if err := binary.Read(sr, binary.LittleEndian, &protocol); err != nil {
+		return nil, nil, err
+	}
+	if protocol != zkeyProtocolGroth16 {
+		return nil, nil, fmt.Errorf("zkey: unsupported protocol %d", protocol)
+	}
+
+	// groth16 header: n8q, q, n8r, r, nVars, nPublic, domainSize, α₁, β₁, β₂, γ₂, δ₁, δ₂
+	sr, err = sections.open(r, zkeySectionGroth16Header)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := readFieldHeader(sr, fp.Modulus()); err != nil {
+		return nil, nil, err
+	}
+	if err := readFieldHeader(sr, fr.Modulus()); err != nil {
+		return nil, nil, err
+	}
+	var header struct {
+		NbVars, NbPublic, DomainSize uint32
+	}
+	if err := binary.Read(sr, binary.LittleEndian, &header); err != nil {
+		return nil, nil, err
+	}
+	if header.DomainSize == 0 || header.DomainSize&(header.DomainSize-1) != 0 || header.NbPublic >= header.NbVars {
+		return nil, nil, errors.New("zkey: invalid header")
+	}
+	g1 := make([]curve.G1Affine, 1)
+	g2 := make([]curve.G2Affine, 1)
+	for _, p := range []interface{}{&vk.G1.Alpha, &vk.G1.Beta, &vk.G2.Beta, &vk.G2.Gamma, &vk.G1.Delta, &vk.G2.Delta} {
+		switch p := p.(type) {
+		case *curve.G1Affine:
+			err = readG1Points(sr, g1)
+			*p = g1[0]
+		case *curve.G2Affine:
+			err = readG2Points(sr, g2)
+			*p = g2[0]
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("zkey: header: %w", err)
+		}
+	}
+
+	nbWires := int(header.NbVars)
+	nbPublic := int(header.NbPublic) + 1
+	n := int(header.DomainSize)
+	vk.G1.K = make([]curve.G1Affine, nbPublic)
+	A := make([]curve.G1Affine, nbWires)
+	B := make([]curve.G1Affine, nbWires)
+	B2 := make([]curve.G2Affine, nbWires)
+	pk.G1.K = make([]curve.G1Affine, nbWires-nbPublic)
+	H := make([]curve.G1Affine, n)
+
+	toRead := []struct {
+		section uint32
+		g1      []curve.G1Affine
+		g2      []curve.G2Affine
+	}{
+		{section: zkeySectionIC, g1: vk.G1.K},
+		{section: zkeySectionA, g1: A},
+		{section: zkeySectionB1, g1: B},
+		{section: zkeySectionB2, g2: B2},
+		{section: zkeySectionC, g1: pk.G1.K},
+		{section: zkeySectionH, g1: H},
+	}
+	for _, s := range toRead {
+		sr, err := sections.open(r, s.section)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := readG1Points(sr, s.g1); err != nil {
+			return nil, nil, fmt.Errorf("zkey: section %d: %w", s.section, err)
+		}
+		if err := readG2Points(sr, s.g2); err != nil {
+			return nil, nil, fmt.Errorf("zkey: section %d: %w", s.section, err)
+		}
+	}
+
+	pk.Domain = *fft.NewDomain(uint64(n))
+	pk.G1.Alpha = vk.G1.Alpha
+	pk.G1.Beta = vk.G1.Beta
+	pk.G1.Delta = vk.G1.Delta
+	pk.G2.Beta = vk.G2.Beta
+	pk.G2.Delta = vk.G2.Delta
+	pk.G1.Z = zFromOddLagrange(H, &pk.Domain)
+	setWirePolynomials(&pk, A, B, B2)
+
+	if err := vk.Precompute(); err != nil {
+		return nil, nil, err
+	}
+
+	return &pk, &vk, nil
+}
+
+// zFromOddLagrange returns [τʲZ(τ)/δ]₁ for j < n, in bit reversed order, from the snarkjs
+// points Hᵢ = [L₂ᵢ₊₁(τ)/δ]₁, Lₖ being the Lagrange polynomials of the domain of size 2n.
+//
+// τʲZ(τ) = τʲ⁺ⁿ - τʲ vanishes on the even powers of the 2n-th root of unity g, and is
+// -2gʲ⁽²ⁱ⁺¹⁾ on the odd ones, so [τʲZ(τ)/δ]₁ = -2gʲ Σᵢ ωⁱʲHᵢ with ω = g².
+func zFromOddLagrange(H []curve.G1Affine, domain *fft.Domain) []curve.G1Affine {
+	n := len(H)
+	g := fft.NewDomain(uint64(2 * n)).Generator
+
+	Z := fftG1(H, domain.Generator)
+
+	var minusTwo fr.Element
+	minusTwo.SetUint64(2).Neg(&minusTwo)
+	scalars := powers(g, n)
+	for i := range scalars {
+		scalars[i].Mul(&scalars[i], &minusTwo)
+	}
+	scaleG1(Z, scalars)
+
+	bitReverse(n, func(i, j int) { Z[i], Z[j] = Z[j], Z[i] })
+	return Z
+}
+
+// fileSections maps a section type to its position and size in a snarkjs file
+type fileSections map[uint32]struct {
+	offset, size int64
+}
+
+// readSections checks the magic number of a snarkjs file and indexes its sections
+func readSections(r io.ReadSeeker, magic string) (fileSections, error) {
+	var header struct {
+		Magic      [4]byte
+		Version    uint32
+		NbSections uint32
+	}
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	if string(header.Magic[:]) != magic {
+		return nil, fmt.Errorf("%s: invalid file type %q", magic, header.Magic[:])
+	}
+
+	sections := make(fileSections, header.NbSections)
+	for i := uint32(0); i < header.NbSections; i++ {
+		var section struct {
+			Type uint32
+			Size uint64
+		}
+		if err := binary.Read(r, binary.LittleEndian, &section); err != nil {
+			return nil, err
+		}
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := sections[section.Type]; ok {
+			return nil, fmt.Errorf("%s: duplicate section %d", magic, section.Type)
+		}
+		sections[section.Type] = struct{ offset, size int64 }{offset, int64(section.Size)}
+		if _, err := r.Seek(int64(section.Size), io.SeekCurrent); err != nil {
+			return nil, err
+		}
+	}
+	return sections, nil
+}
+
+// open returns a buffered reader on the content of a section
+func (sections fileSections) open(r io.ReadSeeker, section uint32) (io.Reader, error) {
+	s, ok := sections[section]
+	if !ok {
+		return nil, fmt.Errorf("missing section %d", section)
+	}
+	if _, err := r.Seek(s.offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return bufio.NewReaderSize(io.LimitReader(r, s.size), 1<<20), nil
+}
+
+// readFieldHeader reads the byte size and the modulus of a field, and checks it matches the expected one
+func readFieldHeader(r io.Reader, modulus *big.Int) error {
+	var n8 uint32
+	if err := binary.Read(r, binary.LittleEndian, &n8); err != nil {
+		return err
+	}
+	if n8 != 32 {
+		return fmt.Errorf("unexpected field size %d, only BN254 is supported", n8)
+	}
+	var buf [32]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return err
+	}
+	if leToBigInt(buf[:]).Cmp(modulus) != 0 {
+		return errors.New("unexpected field modulus, only BN254 is supported")
+	}
+	return nil
+}
+
+// readFp reads a base field element in little endian Montgomery form
+func readFp(r io.Reader, e *fp.Element) error {
+	var buf [fp.Bytes]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return err
+	}
+	if leToBigInt(buf[:]).Cmp(fp.Modulus()) >= 0 {
+		return errors.New("invalid field element")
+	}
+	// the limbs of fp.Element are the little endian words of its Montgomery form
+	for i := range e {
+		e[i] = binary.LittleEndian.Uint64(buf[8*i:])
+	}
+	return nil
+}
+
+// readG1Points reads len(points) uncompressed points, the point at infinity being encoded as (0, 0)
+func readG1Points(r io.Reader, points []curve.G1Affine) error {
+	for i := range points {
+		if err := readFp(r, &points[i].X); err != nil {
+			return err
+		}
+		if err := readFp(r, &points[i].Y); err != nil {
+			return err
+		}
+		if !points[i].IsInfinity() && !points[i].IsOnCurve() {
+			return errors.New("invalid G1 point")
+		}
+	}
+	return nil
+}
+
+// readG2Points reads len(points) uncompressed points, see readG1Points
+func readG2Points(r io.Reader, points []curve.G2Affine) error {
+	for i := range points {
+		for _, e := range []*fp.Element{&points[i].X.A0, &points[i].X.A1, &points[i].Y.A0, &points[i].Y.A1} {
+			if err := readFp(r, e); err != nil {
+				return err
+			}
+		}
+		if !points[i].IsInfinity() && !(points[i].IsOnCurve() && points[i].IsInSubGroup()) {
+			return errors.New("invalid G2 point")
+		}
+	}
+	return nil
+}
+
+func leToBigInt(buf []byte) *big.Int {
+	be := make([]byte, len(buf))
+	for i := range buf {
+		be[len(buf)-1-i] = buf[i]
+	}
+	return new(big.Int).SetBytes(be)
+}
@@ -0,0 +1,231 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mpcsetup
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	bn254cs "github.com/consensys/gnark/internal/backend/bn254/cs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportPtau(t *testing.T) {
+	assert := require.New(t)
+
+	srs1 := InitPhase1(4)
+	srs1.Contribute()
+
+	// a ptau file of power 5 holds twice the powers of srs1
+	var buf bytes.Buffer
+	writePtau(&buf, &srs1)
+	imported, err := ImportPtau(bytes.NewReader(buf.Bytes()))
+	assert.NoError(err)
+	assert.Equal(srs1.Parameters, imported.Parameters)
+
+	// run phase 2 from the imported transcript
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &cubicCircuit{})
+	assert.NoError(err)
+	srs2, evals, err := InitPhase2(ccs, &imported)
+	assert.NoError(err)
+	srs2.Contribute()
+	pk, vk, err := ExtractKeys(&imported, &srs2, &evals, ccs.GetNbConstraints())
+	assert.NoError(err)
+	assertProves(t, ccs, pk, vk)
+
+	// not a ptau file
+	_, err = ImportPtau(bytes.NewReader([]byte("zkey\x01\x00\x00\x00\x00\x00\x00\x00")))
+	assert.Error(err)
+}
+
+func TestImportZkey(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &cubicCircuit{})
+	assert.NoError(err)
+
+	srs1 := InitPhase1(4)
+	srs1.Contribute()
+	srs2, evals, err := InitPhase2(ccs, &srs1)
+	assert.NoError(err)
+	var delta fr.Element
+	delta.SetUint64(42)
+	srs2.contribute([]fr.Element{delta, fr.One()})
+
+	var buf bytes.Buffer
+	writeZkey(&buf, ccs.(*bn254cs.R1CS), &srs1, &srs2, &evals, delta)
+	pk, vk, err := ImportZkey(bytes.NewReader(buf.Bytes()))
+	assert.NoError(err)
+	assertProves(t, ccs, pk, vk)
+
+	// truncated file
+	_, _, err = ImportZkey(bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+	assert.Error(err)
+}
+
+func assertProves(t *testing.T, ccs frontend.CompiledConstraintSystem, pk groth16.ProvingKey, vk groth16.VerifyingKey) {
+	assert := require.New(t)
+
+	witness, err := frontend.NewWitness(&cubicCircuit{X: 3, Y: 35}, ecc.BN254)
+	assert.NoError(err)
+	publicWitness, err := witness.Public()
+	assert.NoError(err)
+	proof, err := groth16.Prove(ccs, pk, witness)
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, vk, publicWitness))
+}
+
+// writePtau writes phase1 as a snarkjs ptau file of twice its power
+func writePtau(buf *bytes.Buffer, phase1 *Phase1) {
+	N := len(phase1.Parameters.G2.Tau)
+	power := 1
+	for 1<<(power-1) < N {
+		power++
+	}
+
+	var header bytes.Buffer
+	writeUint32(&header, 32)
+	writeLE(&header, fp.Modulus().Bytes())
+	writeUint32(&header, uint32(power))
+	writeUint32(&header, uint32(power))
+
+	// missing powers are set to the point at infinity
+	tauG1 := make([]curve.G1Affine, 4*N-1)
+	copy(tauG1, phase1.Parameters.G1.Tau)
+	tauG2 := make([]curve.G2Affine, 2*N)
+	copy(tauG2, phase1.Parameters.G2.Tau)
+	alphaTau := make([]curve.G1Affine, 2*N)
+	copy(alphaTau, phase1.Parameters.G1.AlphaTau)
+	betaTau := make([]curve.G1Affine, 2*N)
+	copy(betaTau, phase1.Parameters.G1.BetaTau)
+
+	writeFile(buf, "ptau", map[uint32][]byte{
+		ptauSectionHeader:   header.Bytes(),
+		ptauSectionTauG1:    g1Bytes(tauG1...),
+		ptauSectionTauG2:    g2Bytes(tauG2...),
+		ptauSectionAlphaTau: g1Bytes(alphaTau...),
+		ptauSectionBetaTau:  g1Bytes(betaTau...),
+		ptauSectionBetaG2:   g2Bytes(phase1.Parameters.G2.Beta),
+	})
+}
+
+// writeZkey writes the result of the MPC as a snarkjs zkey file, δ being the secret of srs2
+func writeZkey(buf *bytes.Buffer, r1cs *bn254cs.R1CS, srs1 *Phase1, srs2 *Phase2, evals *Phase2Evaluations, delta fr.Element) {
+	n := len(srs2.Parameters.G1.Z)
+	_, _, _, g2 := curve.Generators()
+
+	var header, groth16Header bytes.Buffer
+	writeUint32(&header, zkeyProtocolGroth16)
+
+	writeUint32(&groth16Header, 32)
+	writeLE(&groth16Header, fp.Modulus().Bytes())
+	writeUint32(&groth16Header, 32)
+	writeLE(&groth16Header, fr.Modulus().Bytes())
+	writeUint32(&groth16Header, uint32(len(evals.G1.A)))
+	writeUint32(&groth16Header, uint32(r1cs.NbPublicVariables-1))
+	writeUint32(&groth16Header, uint32(n))
+	groth16Header.Write(g1Bytes(srs1.Parameters.G1.AlphaTau[0], srs1.Parameters.G1.BetaTau[0]))
+	groth16Header.Write(g2Bytes(srs1.Parameters.G2.Beta, g2))
+	groth16Header.Write(g1Bytes(srs2.Parameters.G1.Delta))
+	groth16Header.Write(g2Bytes(srs2.Parameters.G2.Delta))
+
+	// Hᵢ = [L₂ᵢ₊₁(τ)/δ]₁ on the domain of size 2n
+	L := lagrangeCoeffsG1(srs1.Parameters.G1.Tau[:2*n], fft.NewDomain(uint64(2*n)))
+	H := make([]curve.G1Affine, n)
+	for i := range H {
+		H[i] = L[2*i+1]
+	}
+	var deltaInv fr.Element
+	deltaInv.Inverse(&delta)
+	scaleG1(H, constants(deltaInv, n))
+
+	writeFile(buf, "zkey", map[uint32][]byte{
+		zkeySectionHeader:        header.Bytes(),
+		zkeySectionGroth16Header: groth16Header.Bytes(),
+		zkeySectionIC:            g1Bytes(evals.G1.VKK...),
+		zkeySectionA:             g1Bytes(evals.G1.A...),
+		zkeySectionB1:            g1Bytes(evals.G1.B...),
+		zkeySectionB2:            g2Bytes(evals.G2.B...),
+		zkeySectionC:             g1Bytes(srs2.Parameters.G1.L...),
+		zkeySectionH:             g1Bytes(H...),
+	})
+}
+
+func writeFile(buf *bytes.Buffer, magic string, sections map[uint32][]byte) {
+	buf.WriteString(magic)
+	writeUint32(buf, 1)
+	writeUint32(buf, uint32(len(sections)))
+	// sections are not sorted
+	for section, content := range sections {
+		writeUint32(buf, section)
+		var size [8]byte
+		binary.LittleEndian.PutUint64(size[:], uint64(len(content)))
+		buf.Write(size[:])
+		buf.Write(content)
+	}
+}
+
+func writeUint32(buf *bytes.Buffer, v uint32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	buf.Write(b[:])
+}
+
+// writeLE writes a big endian number of 32 bytes in little endian
+func writeLE(buf *bytes.Buffer, be []byte) {
+	var b [32]byte
+	for i := range be {
+		b[i] = be[len(be)-1-i]
+	}
+	buf.Write(b[:])
+}
+
+func fpBytes(buf *bytes.Buffer, elements ...*fp.Element) {
+	for _, e := range elements {
+		var b [8]byte
+		for i := range e {
+			binary.LittleEndian.PutUint64(b[:], e[i])
+			buf.Write(b[:])
+		}
+	}
+}
+
+func g1Bytes(points ...curve.G1Affine) []byte {
+	var buf bytes.Buffer
+	for i := range points {
+		fpBytes(&buf, &points[i].X, &points[i].Y)
+	}
+	return buf.Bytes()
+}
+
+func g2Bytes(points ...curve.G2Affine) []byte {
+	var buf bytes.Buffer
+	for i := range points {
+		fpBytes(&buf, &points[i].X.A0, &points[i].X.A1, &points[i].Y.A0, &points[i].Y.A1)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,334 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mpcsetup implements a multi-party computation (MPC) ceremony generating the
+// Groth16 keys of a BN254 circuit, following https://eprint.iacr.org/2017/1050.pdf
+//
+// Phase 1 (powers of tau) doesn't depend on the circuit: participants contribute in turn
+// with InitPhase1 / Phase1.Contribute, and anyone can check the transcript with VerifyPhase1.
+// Phase 2 is circuit specific and starts from the last phase 1 contribution (InitPhase2),
+// then proceeds in the same way with Phase2.Contribute and VerifyPhase2.
+// Both phases are usually finalized with a contribution derived from a public random
+// beacon (ContributeWithBeacon), and ExtractKeys builds the ProvingKey and VerifyingKey.
+//
+// The keys are secure as long as one participant of each phase was honest and deleted
+// its toxic waste.
+//
+// Outputs of the circom ecosystem can be reused: ImportPtau reads a snarkjs Powers of Tau
+// transcript as a Phase1, and ImportZkey reads the keys of a snarkjs Groth16 .zkey file.
+package mpcsetup
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	"github.com/consensys/gnark/internal/utils"
+)
+
+// PublicKey is a proof of knowledge of the secret x of a contribution, bound to the
+// previous contribution through the challenge R = H(SG, SXG, challenge)
+type PublicKey struct {
+	SG  curve.G1Affine // [s]₁
+	SXG curve.G1Affine // [sx]₁
+	XR  curve.G2Affine // [x]R
+}
+
+func newPublicKey(x, s fr.Element, challenge []byte, dst byte) PublicKey {
+	var pk PublicKey
+	_, _, g1, _ := curve.Generators()
+
+	var sBi, xBi big.Int
+	s.ToBigIntRegular(&sBi)
+	x.ToBigIntRegular(&xBi)
+
+	pk.SG.ScalarMultiplication(&g1, &sBi)
+	pk.SXG.ScalarMultiplication(&pk.SG, &xBi)
+	R := genR(pk.SG, pk.SXG, challenge, dst)
+	pk.XR.ScalarMultiplication(&R, &xBi)
+	return pk
+}
+
+// verify returns true if pk proves the knowledge of x
+func (pk *PublicKey) verify(challenge []byte, dst byte) bool {
+	R := genR(pk.SG, pk.SXG, challenge, dst)
+	return sameRatio(pk.SXG, pk.SG, pk.XR, R)
+}
+
+// genR returns the challenge R of a proof of knowledge, hashed to G2
+func genR(sG1, sxG1 curve.G1Affine, challenge []byte, dst byte) curve.G2Affine {
+	var buf bytes.Buffer
+	buf.Grow(len(challenge) + curve.SizeOfG1AffineUncompressed*2)
+	buf.Write(sG1.Marshal())
+	buf.Write(sxG1.Marshal())
+	buf.Write(challenge)
+	R, err := curve.HashToCurveG2Svdw(buf.Bytes(), []byte{dst})
+	if err != nil {
+		panic(err)
+	}
+	return R
+}
+
+// sameRatio returns true if a₁/b₁ == a₂/b₂ in the exponent, that is if e(a₁, b₂) == e(b₁, a₂)
+func sameRatio(a1, b1 curve.G1Affine, a2, b2 curve.G2Affine) bool {
+	if a1.IsInfinity() || b1.IsInfinity() || a2.IsInfinity() || b2.IsInfinity() {
+		return false
+	}
+	if !a1.IsInSubGroup() || !b1.IsInSubGroup() || !a2.IsInSubGroup() || !b2.IsInSubGroup() {
+		return false
+	}
+	var na1 curve.G1Affine
+	na1.Neg(&a1)
+	res, err := curve.PairingCheck([]curve.G1Affine{na1, b1}, []curve.G2Affine{b2, a2})
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// randomSecrets samples n non zero secrets
+func randomSecrets(n int) []fr.Element {
+	res := make([]fr.Element, n)
+	for i := range res {
+		for res[i].IsZero() {
+			if _, err := res[i].SetRandom(); err != nil {
+				panic(err)
+			}
+		}
+	}
+	return res
+}
+
+// beaconSecrets derives n secrets from the beacon, hashed nbIterations times
+func beaconSecrets(n int, beacon []byte, nbIterations int) []fr.Element {
+	h := sha256.Sum256(beacon)
+	for i := 1; i < nbIterations; i++ {
+		h = sha256.Sum256(h[:])
+	}
+	res := make([]fr.Element, n)
+	for i := range res {
+		for j := byte(0); res[i].IsZero(); j++ {
+			d := sha256.Sum256(append(h[:], byte(i), j))
+			res[i].SetBytes(d[:])
+		}
+	}
+	return res
+}
+
+// randomScalars returns n random scalars, for the random linear combinations of the verifier
+func randomScalars(n int) []fr.Element {
+	res := make([]fr.Element, n)
+	var b [fr.Bytes]byte
+	for i := range res {
+		if _, err := rand.Read(b[:]); err != nil {
+			panic(err)
+		}
+		res[i].SetBytes(b[:])
+	}
+	return res
+}
+
+// linearCombinationG1 returns Σ rᵢAᵢ and Σ rᵢAᵢ₊₁ for random rᵢ, if Aᵢ₊₁ = x⋅Aᵢ
+// the second one is x times the first one
+func linearCombinationG1(A []curve.G1Affine) (L1, L2 curve.G1Affine) {
+	r := randomScalars(len(A) - 1)
+	var l1, l2 curve.G1Jac
+	if _, err := l1.MultiExp(A[:len(A)-1], r, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+		panic(err)
+	}
+	if _, err := l2.MultiExp(A[1:], r, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+		panic(err)
+	}
+	L1.FromJacobian(&l1)
+	L2.FromJacobian(&l2)
+	return
+}
+
+// linearCombinationG2 returns Σ rᵢAᵢ and Σ rᵢAᵢ₊₁ for random rᵢ, if Aᵢ₊₁ = x⋅Aᵢ
+// the second one is x times the first one
+func linearCombinationG2(A []curve.G2Affine) (L1, L2 curve.G2Affine) {
+	r := randomScalars(len(A) - 1)
+	var l1, l2 curve.G2Jac
+	if _, err := l1.MultiExp(A[:len(A)-1], r, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+		panic(err)
+	}
+	if _, err := l2.MultiExp(A[1:], r, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+		panic(err)
+	}
+	L1.FromJacobian(&l1)
+	L2.FromJacobian(&l2)
+	return
+}
+
+// merge returns Σ rᵢAᵢ and Σ rᵢBᵢ for random rᵢ
+func merge(A, B []curve.G1Affine) (L1, L2 curve.G1Affine) {
+	r := randomScalars(len(A))
+	var l1, l2 curve.G1Jac
+	if _, err := l1.MultiExp(A, r, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+		panic(err)
+	}
+	if _, err := l2.MultiExp(B, r, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+		panic(err)
+	}
+	L1.FromJacobian(&l1)
+	L2.FromJacobian(&l2)
+	return
+}
+
+// powers returns [1, x, ..., xⁿ⁻¹]
+func powers(x fr.Element, n int) []fr.Element {
+	res := make([]fr.Element, n)
+	res[0].SetOne()
+	for i := 1; i < n; i++ {
+		res[i].Mul(&res[i-1], &x)
+	}
+	return res
+}
+
+// scaleG1 sets pᵢ to sᵢ⋅pᵢ
+func scaleG1(p []curve.G1Affine, s []fr.Element) {
+	utils.Parallelize(len(p), func(start, end int) {
+		var b big.Int
+		for i := start; i < end; i++ {
+			p[i].ScalarMultiplication(&p[i], s[i].ToBigIntRegular(&b))
+		}
+	})
+}
+
+// scaleG2 sets pᵢ to sᵢ⋅pᵢ
+func scaleG2(p []curve.G2Affine, s []fr.Element) {
+	utils.Parallelize(len(p), func(start, end int) {
+		var b big.Int
+		for i := start; i < end; i++ {
+			p[i].ScalarMultiplication(&p[i], s[i].ToBigIntRegular(&b))
+		}
+	})
+}
+
+// lagrangeCoeffsG1 returns [Lᵢ(τ)]₁ from [τⁱ]₁, where Lᵢ are the Lagrange polynomials of the domain:
+// [Lᵢ(τ)]₁ = 1/n Σⱼ ω⁻ⁱʲ[τʲ]₁ is an inverse FFT in the exponent
+func lagrangeCoeffsG1(powers []curve.G1Affine, domain *fft.Domain) []curve.G1Affine {
+	res := fftG1(powers, domain.GeneratorInv)
+	scaleG1(res, constants(domain.CardinalityInv, len(res)))
+	return res
+}
+
+// lagrangeCoeffsG2 returns [Lᵢ(τ)]₂ from [τⁱ]₂, see lagrangeCoeffsG1
+func lagrangeCoeffsG2(powers []curve.G2Affine, domain *fft.Domain) []curve.G2Affine {
+	res := fftG2(powers, domain.GeneratorInv)
+	scaleG2(res, constants(domain.CardinalityInv, len(res)))
+	return res
+}
+
+// fftG1 returns Σⱼ ωⁱʲ[aⱼ]₁ for i < len(a), ω being a root of unity of order len(a)
+func fftG1(a []curve.G1Affine, omega fr.Element) []curve.G1Affine {
+	n := len(a)
+	twiddles := fftTwiddles(omega, n)
+	p := make([]curve.G1Jac, n)
+	for i := range a {
+		p[i].FromAffine(&a[i])
+	}
+	bitReverse(n, func(i, j int) { p[i], p[j] = p[j], p[i] })
+	for m := 2; m <= n; m <<= 1 {
+		step := n / m
+		for k := 0; k < n; k += m {
+			for j := 0; j < m/2; j++ {
+				var t, u curve.G1Jac
+				t.ScalarMultiplication(&p[k+j+m/2], &twiddles[j*step])
+				u.Set(&p[k+j])
+				p[k+j].AddAssign(&t)
+				p[k+j+m/2].Set(&u).SubAssign(&t)
+			}
+		}
+	}
+	res := make([]curve.G1Affine, n)
+	for i := range p {
+		res[i].FromJacobian(&p[i])
+	}
+	return res
+}
+
+// fftG2 returns Σⱼ ωⁱʲ[aⱼ]₂ for i < len(a), see fftG1
+func fftG2(a []curve.G2Affine, omega fr.Element) []curve.G2Affine {
+	n := len(a)
+	twiddles := fftTwiddles(omega, n)
+	p := make([]curve.G2Jac, n)
+	for i := range a {
+		p[i].FromAffine(&a[i])
+	}
+	bitReverse(n, func(i, j int) { p[i], p[j] = p[j], p[i] })
+	for m := 2; m <= n; m <<= 1 {
+		step := n / m
+		for k := 0; k < n; k += m {
+			for j := 0; j < m/2; j++ {
+				var t, u curve.G2Jac
+				t.ScalarMultiplication(&p[k+j+m/2], &twiddles[j*step])
+				u.Set(&p[k+j])
+				p[k+j].AddAssign(&t)
+				p[k+j+m/2].Set(&u).SubAssign(&t)
+			}
+		}
+	}
+	res := make([]curve.G2Affine, n)
+	for i := range p {
+		res[i].FromJacobian(&p[i])
+	}
+	return res
+}
+
+// fftTwiddles returns ωⁱ for i < n/2
+func fftTwiddles(omega fr.Element, n int) []big.Int {
+	res := make([]big.Int, n/2)
+	var w fr.Element
+	w.SetOne()
+	for i := range res {
+		w.ToBigIntRegular(&res[i])
+		w.Mul(&w, &omega)
+	}
+	return res
+}
+
+// constants returns n copies of x
+func constants(x fr.Element, n int) []fr.Element {
+	res := make([]fr.Element, n)
+	for i := range res {
+		res[i] = x
+	}
+	return res
+}
+
+// bitReverse applies the bit reversal permutation to a slice of size n through swap
+func bitReverse(n int, swap func(i, j int)) {
+	nbBits := 0
+	for 1<<nbBits < n {
+		nbBits++
+	}
+	for i := 0; i < n; i++ {
+		irev := 0
+		for b := 0; b < nbBits; b++ {
+			irev |= ((i >> b) & 1) << (nbBits - 1 - b)
+		}
+		if irev > i {
+			swap(i, irev)
+		}
+	}
+}
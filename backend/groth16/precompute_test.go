@@ -0,0 +1,55 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+package groth16
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrecompute(t *testing.T) {
+	for _, curveID := range []ecc.ID{ecc.BN254, ecc.BLS12_377, ecc.BLS12_381, ecc.BLS24_315, ecc.BW6_633, ecc.BW6_761} {
+		t.Run(curveID.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			ccs, err := frontend.Compile(curveID, r1cs.NewBuilder, &aggregationCircuit{})
+			assert.NoError(err)
+			pk, vk, err := Setup(ccs)
+			assert.NoError(err)
+
+			fullWitness, err := frontend.NewWitness(&aggregationCircuit{X: 3, Y: 27}, curveID)
+			assert.NoError(err)
+			publicWitness, err := fullWitness.Public()
+			assert.NoError(err)
+
+			for _, level := range []int{1, 3, 8} {
+				assert.NoError(pk.Precompute(level))
+				proof, err := Prove(ccs, pk, fullWitness)
+				assert.NoError(err)
+				assert.NoError(Verify(proof, vk, publicWitness))
+
+				// the tables are encoded with the key
+				var buf bytes.Buffer
+				_, err = pk.WriteRawTo(&buf)
+				assert.NoError(err)
+				decoded := NewProvingKey(curveID)
+				_, err = decoded.ReadFrom(&buf)
+				assert.NoError(err)
+				proof, err = Prove(ccs, decoded, fullWitness)
+				assert.NoError(err)
+				assert.NoError(Verify(proof, vk, publicWitness))
+				assert.Equal(pk, decoded)
+			}
+
+			assert.NoError(pk.Precompute(0))
+			assert.Error(pk.Precompute(-1))
+			assert.Error(pk.Precompute(1000))
+		})
+	}
+}
@@ -0,0 +1,491 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package groth16
+
+import (
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	backend_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/cs"
+	backend_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/cs"
+	backend_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/cs"
+	backend_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
+	backend_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/cs"
+	backend_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/cs"
+
+	witness_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/witness"
+	witness_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/witness"
+	witness_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/witness"
+	witness_bn254 "github.com/consensys/gnark/internal/backend/bn254/witness"
+	witness_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/witness"
+	witness_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/witness"
+
+	gnarkio "github.com/consensys/gnark/io"
+
+	groth16_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/groth16"
+	groth16_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/groth16"
+	groth16_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/groth16"
+	groth16_bn254 "github.com/consensys/gnark/internal/backend/bn254/groth16"
+	groth16_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/groth16"
+	groth16_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/groth16"
+)
+
+// ProvingKey represents a Groth16 ProvingKey
+//
+// it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
+type ProvingKey interface {
+	groth16Object
+	gnarkio.UnsafeReaderFrom
+	gnarkio.Dumper
+
+	// WriteSectionsTo and WriteRawSectionsTo write the key in sections which can be
+	// read independently, see ReadProvingKeySections
+	WriteSectionsTo(w io.Writer) (int64, error)
+	WriteRawSectionsTo(w io.Writer) (int64, error)
+
+	// Precompute adds to the key tables of multiples of its largest fixed bases, with
+	// which Prove is faster at the cost of memory. Level 0 removes them.
+	Precompute(level int) error
+
+	// NbG1 returns the number of G1 elements in the ProvingKey
+	NbG1() int
+
+	// NbG2 returns the number of G2 elements in the ProvingKey
+	NbG2() int
+
+	IsDifferent(interface{}) bool
+}
+
+// Prove runs the groth16.Prove algorithm.
+//
+// if the force flag is set:
+//
+//		will executes all the prover computations, even if the witness is invalid
+//	 will produce an invalid proof
+//		internally, the solution vector to the R1CS will be filled with random values which may impact benchmarking
+func Prove(r1cs frontend.CompiledConstraintSystem, pk ProvingKey, fullWitness *witness.Witness, opts ...backend.ProverOption) (Proof, error) {
+
+	// apply options
+	opt, err := backend.NewProverConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	switch _r1cs := r1cs.(type) {
+	case *backend_bls12377.R1CS:
+		w, ok := fullWitness.Vector.(*witness_bls12377.Witness)
+		if !ok {
+			return nil, witness.ErrInvalidWitness
+		}
+		return groth16_bls12377.Prove(_r1cs, pk.(*groth16_bls12377.ProvingKey), *w, opt)
+	case *backend_bls12381.R1CS:
+		w, ok := fullWitness.Vector.(*witness_bls12381.Witness)
+		if !ok {
+			return nil, witness.ErrInvalidWitness
+		}
+		return groth16_bls12381.Prove(_r1cs, pk.(*groth16_bls12381.ProvingKey), *w, opt)
+	case *backend_bn254.R1CS:
+		w, ok := fullWitness.Vector.(*witness_bn254.Witness)
+		if !ok {
+			return nil, witness.ErrInvalidWitness
+		}
+		return groth16_bn254.Prove(_r1cs, pk.(*groth16_bn254.ProvingKey), *w, opt)
+	case *backend_bw6761.R1CS:
+		w, ok := fullWitness.Vector.(*witness_bw6761.Witness)
+		if !ok {
+			return nil, witness.ErrInvalidWitness
+		}
+		return groth16_bw6761.Prove(_r1cs, pk.(*groth16_bw6761.ProvingKey), *w, opt)
+	case *backend_bls24315.R1CS:
+		w, ok := fullWitness.Vector.(*witness_bls24315.Witness)
+		if !ok {
+			return nil, witness.ErrInvalidWitness
+		}
+		return groth16_bls24315.Prove(_r1cs, pk.(*groth16_bls24315.ProvingKey), *w, opt)
+	case *backend_bw6633.R1CS:
+		w, ok := fullWitness.Vector.(*witness_bw6633.Witness)
+		if !ok {
+			return nil, witness.ErrInvalidWitness
+		}
+		return groth16_bw6633.Prove(_r1cs, pk.(*groth16_bw6633.ProvingKey), *w, opt)
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+}
+
+// ProveReader is like Prove, but the full witness is read on demand from
+// fullWitness, which holds its binary encoding (see
+// witness.Witness.MarshalBinary), typically a file.
+//
+// The witness is decoded by the solver directly into the wire values instead
+// of being first held as a separate vector of field elements, which saves a
+// large allocation for circuits with millions of inputs.
+func ProveReader(r1cs frontend.CompiledConstraintSystem, pk ProvingKey, fullWitness io.ReaderAt, opts ...backend.ProverOption) (Proof, error) {
+
+	// apply options
+	opt, err := backend.NewProverConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	switch _r1cs := r1cs.(type) {
+	case *backend_bls12377.R1CS:
+		w, err := witness_bls12377.NewReader(fullWitness)
+		if err != nil {
+			return nil, err
+		}
+		return groth16_bls12377.ProveReader(_r1cs, pk.(*groth16_bls12377.ProvingKey), w, opt)
+	case *backend_bls12381.R1CS:
+		w, err := witness_bls12381.NewReader(fullWitness)
+		if err != nil {
+			return nil, err
+		}
+		return groth16_bls12381.ProveReader(_r1cs, pk.(*groth16_bls12381.ProvingKey), w, opt)
+	case *backend_bn254.R1CS:
+		w, err := witness_bn254.NewReader(fullWitness)
+		if err != nil {
+			return nil, err
+		}
+		return groth16_bn254.ProveReader(_r1cs, pk.(*groth16_bn254.ProvingKey), w, opt)
+	case *backend_bw6761.R1CS:
+		w, err := witness_bw6761.NewReader(fullWitness)
+		if err != nil {
+			return nil, err
+		}
+		return groth16_bw6761.ProveReader(_r1cs, pk.(*groth16_bw6761.ProvingKey), w, opt)
+	case *backend_bls24315.R1CS:
+		w, err := witness_bls24315.NewReader(fullWitness)
+		if err != nil {
+			return nil, err
+		}
+		return groth16_bls24315.ProveReader(_r1cs, pk.(*groth16_bls24315.ProvingKey), w, opt)
+	case *backend_bw6633.R1CS:
+		w, err := witness_bw6633.NewReader(fullWitness)
+		if err != nil {
+			return nil, err
+		}
+		return groth16_bw6633.ProveReader(_r1cs, pk.(*groth16_bw6633.ProvingKey), w, opt)
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+}
+
+// ProveBatch generates a proof for each of the full witnesses, all for the same
+// circuit and proving key. It is equivalent to calling Prove for each witness,
+// but pipelines the proofs: the witness of a proof is solved and its quotient
+// computed while the multi-exponentiations of the previous one run, and the
+// scratch buffers are shared across proofs (see backend.WithProverBufferPool).
+//
+// The proofs are returned in the order of the witnesses. ProveBatch stops at the
+// first witness which can't be proven, and the error tells its index.
+//
+// The progress (see backend.WithProgress) is reported for the whole batch: the
+// percentage of a phase is the share of this phase done over all the witnesses,
+// and reaches 100% once the last witness is through it.
+func ProveBatch(r1cs frontend.CompiledConstraintSystem, pk ProvingKey, fullWitnesses []*witness.Witness, opts ...backend.ProverOption) ([]Proof, error) {
+
+	// apply options
+	opt, err := backend.NewProverConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	switch _r1cs := r1cs.(type) {
+	case *backend_bls12377.R1CS:
+		witnesses := make([]witness_bls12377.Witness, len(fullWitnesses))
+		for i := range fullWitnesses {
+			w, ok := fullWitnesses[i].Vector.(*witness_bls12377.Witness)
+			if !ok {
+				return nil, witness.ErrInvalidWitness
+			}
+			witnesses[i] = *w
+		}
+		proofs, err := groth16_bls12377.ProveBatch(_r1cs, pk.(*groth16_bls12377.ProvingKey), witnesses, opt)
+		if err != nil {
+			return nil, err
+		}
+		res := make([]Proof, len(proofs))
+		for i := range proofs {
+			res[i] = proofs[i]
+		}
+		return res, nil
+	case *backend_bls12381.R1CS:
+		witnesses := make([]witness_bls12381.Witness, len(fullWitnesses))
+		for i := range fullWitnesses {
+			w, ok := fullWitnesses[i].Vector.(*witness_bls12381.Witness)
+			if !ok {
+				return nil, witness.ErrInvalidWitness
+			}
+			witnesses[i] = *w
+		}
+		proofs, err := groth16_bls12381.ProveBatch(_r1cs, pk.(*groth16_bls12381.ProvingKey), witnesses, opt)
+		if err != nil {
+			return nil, err
+		}
+		res := make([]Proof, len(proofs))
+		for i := range proofs {
+			res[i] = proofs[i]
+		}
+		return res, nil
+	case *backend_bn254.R1CS:
+		witnesses := make([]witness_bn254.Witness, len(fullWitnesses))
+		for i := range fullWitnesses {
+			w, ok := fullWitnesses[i].Vector.(*witness_bn254.Witness)
+			if !ok {
+				return nil, witness.ErrInvalidWitness
+			}
+			witnesses[i] = *w
+		}
+		proofs, err := groth16_bn254.ProveBatch(_r1cs, pk.(*groth16_bn254.ProvingKey), witnesses, opt)
+		if err != nil {
+			return nil, err
+		}
+		res := make([]Proof, len(proofs))
+		for i := range proofs {
+			res[i] = proofs[i]
+		}
+		return res, nil
+	case *backend_bw6761.R1CS:
+		witnesses := make([]witness_bw6761.Witness, len(fullWitnesses))
+		for i := range fullWitnesses {
+			w, ok := fullWitnesses[i].Vector.(*witness_bw6761.Witness)
+			if !ok {
+				return nil, witness.ErrInvalidWitness
+			}
+			witnesses[i] = *w
+		}
+		proofs, err := groth16_bw6761.ProveBatch(_r1cs, pk.(*groth16_bw6761.ProvingKey), witnesses, opt)
+		if err != nil {
+			return nil, err
+		}
+		res := make([]Proof, len(proofs))
+		for i := range proofs {
+			res[i] = proofs[i]
+		}
+		return res, nil
+	case *backend_bls24315.R1CS:
+		witnesses := make([]witness_bls24315.Witness, len(fullWitnesses))
+		for i := range fullWitnesses {
+			w, ok := fullWitnesses[i].Vector.(*witness_bls24315.Witness)
+			if !ok {
+				return nil, witness.ErrInvalidWitness
+			}
+			witnesses[i] = *w
+		}
+		proofs, err := groth16_bls24315.ProveBatch(_r1cs, pk.(*groth16_bls24315.ProvingKey), witnesses, opt)
+		if err != nil {
+			return nil, err
+		}
+		res := make([]Proof, len(proofs))
+		for i := range proofs {
+			res[i] = proofs[i]
+		}
+		return res, nil
+	case *backend_bw6633.R1CS:
+		witnesses := make([]witness_bw6633.Witness, len(fullWitnesses))
+		for i := range fullWitnesses {
+			w, ok := fullWitnesses[i].Vector.(*witness_bw6633.Witness)
+			if !ok {
+				return nil, witness.ErrInvalidWitness
+			}
+			witnesses[i] = *w
+		}
+		proofs, err := groth16_bw6633.ProveBatch(_r1cs, pk.(*groth16_bw6633.ProvingKey), witnesses, opt)
+		if err != nil {
+			return nil, err
+		}
+		res := make([]Proof, len(proofs))
+		for i := range proofs {
+			res[i] = proofs[i]
+		}
+		return res, nil
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+}
+
+// Setup runs groth16.Setup with provided R1CS and outputs a key pair associated with the circuit.
+//
+// Note that careful consideration must be given to this step in production environment.
+// groth16.Setup uses some randomness to precompute the Proving and Verifying keys. If the process
+// or machine leaks this randomness, an attacker could break the ZKP protocol.
+//
+// Two main solutions to this deployment issues are: running the Setup through a MPC (multi party computation)
+// or using a ZKP backend like PLONK where the per-circuit Setup is deterministic.
+func Setup(r1cs frontend.CompiledConstraintSystem) (ProvingKey, VerifyingKey, error) {
+
+	switch _r1cs := r1cs.(type) {
+	case *backend_bls12377.R1CS:
+		var pk groth16_bls12377.ProvingKey
+		var vk groth16_bls12377.VerifyingKey
+		if err := groth16_bls12377.Setup(_r1cs, &pk, &vk); err != nil {
+			return nil, nil, err
+		}
+		return &pk, &vk, nil
+	case *backend_bls12381.R1CS:
+		var pk groth16_bls12381.ProvingKey
+		var vk groth16_bls12381.VerifyingKey
+		if err := groth16_bls12381.Setup(_r1cs, &pk, &vk); err != nil {
+			return nil, nil, err
+		}
+		return &pk, &vk, nil
+	case *backend_bn254.R1CS:
+		var pk groth16_bn254.ProvingKey
+		var vk groth16_bn254.VerifyingKey
+		if err := groth16_bn254.Setup(_r1cs, &pk, &vk); err != nil {
+			return nil, nil, err
+		}
+		return &pk, &vk, nil
+	case *backend_bw6761.R1CS:
+		var pk groth16_bw6761.ProvingKey
+		var vk groth16_bw6761.VerifyingKey
+		if err := groth16_bw6761.Setup(_r1cs, &pk, &vk); err != nil {
+			return nil, nil, err
+		}
+		return &pk, &vk, nil
+	case *backend_bls24315.R1CS:
+		var pk groth16_bls24315.ProvingKey
+		var vk groth16_bls24315.VerifyingKey
+		if err := groth16_bls24315.Setup(_r1cs, &pk, &vk); err != nil {
+			return nil, nil, err
+		}
+		return &pk, &vk, nil
+	case *backend_bw6633.R1CS:
+		var pk groth16_bw6633.ProvingKey
+		var vk groth16_bw6633.VerifyingKey
+		if err := groth16_bw6633.Setup(_r1cs, &pk, &vk); err != nil {
+			return nil, nil, err
+		}
+		return &pk, &vk, nil
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+}
+
+// DummySetup create a random ProvingKey with provided R1CS
+// it doesn't return a VerifyingKey and is use for benchmarking or test purposes only.
+func DummySetup(r1cs frontend.CompiledConstraintSystem) (ProvingKey, error) {
+	switch _r1cs := r1cs.(type) {
+	case *backend_bls12377.R1CS:
+		var pk groth16_bls12377.ProvingKey
+		if err := groth16_bls12377.DummySetup(_r1cs, &pk); err != nil {
+			return nil, err
+		}
+		return &pk, nil
+	case *backend_bls12381.R1CS:
+		var pk groth16_bls12381.ProvingKey
+		if err := groth16_bls12381.DummySetup(_r1cs, &pk); err != nil {
+			return nil, err
+		}
+		return &pk, nil
+	case *backend_bn254.R1CS:
+		var pk groth16_bn254.ProvingKey
+		if err := groth16_bn254.DummySetup(_r1cs, &pk); err != nil {
+			return nil, err
+		}
+		return &pk, nil
+	case *backend_bw6761.R1CS:
+		var pk groth16_bw6761.ProvingKey
+		if err := groth16_bw6761.DummySetup(_r1cs, &pk); err != nil {
+			return nil, err
+		}
+		return &pk, nil
+	case *backend_bls24315.R1CS:
+		var pk groth16_bls24315.ProvingKey
+		if err := groth16_bls24315.DummySetup(_r1cs, &pk); err != nil {
+			return nil, err
+		}
+		return &pk, nil
+	case *backend_bw6633.R1CS:
+		var pk groth16_bw6633.ProvingKey
+		if err := groth16_bw6633.DummySetup(_r1cs, &pk); err != nil {
+			return nil, err
+		}
+		return &pk, nil
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+}
+
+// NewProvingKey instantiates a curve-typed ProvingKey and returns an interface object
+// This function exists for serialization purposes
+func NewProvingKey(curveID ecc.ID) ProvingKey {
+	var pk ProvingKey
+	switch curveID {
+	case ecc.BN254:
+		pk = &groth16_bn254.ProvingKey{}
+	case ecc.BLS12_377:
+		pk = &groth16_bls12377.ProvingKey{}
+	case ecc.BLS12_381:
+		pk = &groth16_bls12381.ProvingKey{}
+	case ecc.BW6_761:
+		pk = &groth16_bw6761.ProvingKey{}
+	case ecc.BLS24_315:
+		pk = &groth16_bls24315.ProvingKey{}
+	case ecc.BW6_633:
+		pk = &groth16_bw6633.ProvingKey{}
+	default:
+		panic("not implemented")
+	}
+	return pk
+}
+
+// MmapProvingKey memory maps the file at path, written by ProvingKey.WriteDump, and returns
+// the ProvingKey it holds. The points of the key are read from the file when Prove needs them,
+// so that keys larger than the available RAM can be used.
+// Closing the returned io.Closer unmaps the file: the key must not be used after.
+func MmapProvingKey(curveID ecc.ID, path string) (ProvingKey, io.Closer, error) {
+	data, closer, err := gnarkio.Mmap(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	pk := NewProvingKey(curveID)
+	if err := pk.ReadDump(data); err != nil {
+		closer.Close()
+		return nil, nil, err
+	}
+	return pk, closer, nil
+}
+
+// NewCS instantiate a concrete curved-typed R1CS and return a R1CS interface
+// This method exists for (de)serialization purposes
+func NewCS(curveID ecc.ID) frontend.CompiledConstraintSystem {
+	var r1cs frontend.CompiledConstraintSystem
+	switch curveID {
+	case ecc.BN254:
+		r1cs = &backend_bn254.R1CS{}
+	case ecc.BLS12_377:
+		r1cs = &backend_bls12377.R1CS{}
+	case ecc.BLS12_381:
+		r1cs = &backend_bls12381.R1CS{}
+	case ecc.BW6_761:
+		r1cs = &backend_bw6761.R1CS{}
+	case ecc.BLS24_315:
+		r1cs = &backend_bls24315.R1CS{}
+	case ecc.BW6_633:
+		r1cs = &backend_bw6633.R1CS{}
+	default:
+		panic("not implemented")
+	}
+	return r1cs
+}
@@ -0,0 +1,67 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+package groth16
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProveBatch(t *testing.T) {
+	for _, curveID := range []ecc.ID{ecc.BN254, ecc.BLS12_377, ecc.BLS12_381, ecc.BLS24_315, ecc.BW6_633, ecc.BW6_761} {
+		t.Run(curveID.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			ccs, err := frontend.Compile(curveID, r1cs.NewBuilder, &aggregationCircuit{})
+			assert.NoError(err)
+			pk, vk, err := Setup(ccs)
+			assert.NoError(err)
+
+			var fullWitnesses, publicWitnesses []*witness.Witness
+			for x := 1; x <= 4; x++ {
+				fullWitness, err := frontend.NewWitness(&aggregationCircuit{X: x, Y: x * x * x}, curveID)
+				assert.NoError(err)
+				publicWitness, err := fullWitness.Public()
+				assert.NoError(err)
+				fullWitnesses = append(fullWitnesses, fullWitness)
+				publicWitnesses = append(publicWitnesses, publicWitness)
+			}
+
+			// the scratch buffers are reused across the proofs, and across batches
+			pool := backend.NewBufferPool(1 << 30)
+			for i := 0; i < 2; i++ {
+				proofs, err := ProveBatch(ccs, pk, fullWitnesses, backend.WithProverBufferPool(pool))
+				assert.NoError(err)
+				assert.Len(proofs, len(fullWitnesses))
+				for j := range proofs {
+					assert.NoError(Verify(proofs[j], vk, publicWitnesses[j]))
+				}
+			}
+
+			// the progress of each phase goes up over the batch, to 100%
+			percents := make(map[backend.ProverPhase][]int)
+			_, err = ProveBatch(ccs, pk, fullWitnesses, backend.WithProgress(func(phase backend.ProverPhase, percent int) {
+				percents[phase] = append(percents[phase], percent)
+			}))
+			assert.NoError(err)
+			for _, phase := range []backend.ProverPhase{backend.PhaseSolve, backend.PhaseFFT, backend.PhaseMSM} {
+				assert.NotEmpty(percents[phase], phase.String())
+				assert.IsIncreasing(percents[phase], phase.String())
+				assert.Equal(100, percents[phase][len(percents[phase])-1], phase.String())
+			}
+
+			// no proof when a witness doesn't satisfy the circuit
+			invalid, err := frontend.NewWitness(&aggregationCircuit{X: 3, Y: 28}, curveID)
+			assert.NoError(err)
+			_, err = ProveBatch(ccs, pk, append(fullWitnesses[:2:2], invalid, fullWitnesses[2]))
+			assert.Error(err)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+package groth16
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProveReader(t *testing.T) {
+	for _, curveID := range []ecc.ID{ecc.BN254, ecc.BLS12_377, ecc.BLS12_381, ecc.BLS24_315, ecc.BW6_633, ecc.BW6_761} {
+		t.Run(curveID.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			ccs, err := frontend.Compile(curveID, r1cs.NewBuilder, &aggregationCircuit{})
+			assert.NoError(err)
+			pk, vk, err := Setup(ccs)
+			assert.NoError(err)
+
+			fullWitness, err := frontend.NewWitness(&aggregationCircuit{X: 3, Y: 27}, curveID)
+			assert.NoError(err)
+			publicWitness, err := fullWitness.Public()
+			assert.NoError(err)
+			data, err := fullWitness.MarshalBinary()
+			assert.NoError(err)
+
+			path := filepath.Join(t.TempDir(), "witness.bin")
+			assert.NoError(os.WriteFile(path, data, 0600))
+			f, err := os.Open(path)
+			assert.NoError(err)
+			defer f.Close()
+
+			proof, err := ProveReader(ccs, pk, f)
+			assert.NoError(err)
+			assert.NoError(Verify(proof, vk, publicWitness))
+
+			// truncated witness
+			_, err = ProveReader(ccs, pk, bytes.NewReader(data[:len(data)-1]))
+			assert.Error(err)
+
+			// invalid witness
+			wrongWitness, err := frontend.NewWitness(&aggregationCircuit{X: 3, Y: 28}, curveID)
+			assert.NoError(err)
+			data, err = wrongWitness.MarshalBinary()
+			assert.NoError(err)
+			_, err = ProveReader(ccs, pk, bytes.NewReader(data))
+			assert.Error(err)
+		})
+	}
+}
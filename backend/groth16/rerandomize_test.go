@@ -0,0 +1,52 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+package groth16
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRerandomize(t *testing.T) {
+	for _, curveID := range []ecc.ID{ecc.BN254, ecc.BLS12_377, ecc.BLS12_381, ecc.BLS24_315, ecc.BW6_633, ecc.BW6_761} {
+		t.Run(curveID.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			ccs, err := frontend.Compile(curveID, r1cs.NewBuilder, &aggregationCircuit{})
+			assert.NoError(err)
+			pk, vk, err := Setup(ccs)
+			assert.NoError(err)
+
+			fullWitness, err := frontend.NewWitness(&aggregationCircuit{X: 3, Y: 27}, curveID)
+			assert.NoError(err)
+			publicWitness, err := fullWitness.Public()
+			assert.NoError(err)
+
+			proof, err := Prove(ccs, pk, fullWitness)
+			assert.NoError(err)
+			var before bytes.Buffer
+			_, err = proof.WriteTo(&before)
+			assert.NoError(err)
+
+			assert.NoError(Rerandomize(proof, vk, nil))
+			var after bytes.Buffer
+			_, err = proof.WriteTo(&after)
+			assert.NoError(err)
+			assert.NotEqual(before.Bytes(), after.Bytes())
+			assert.NoError(Verify(proof, vk, publicWitness))
+
+			// the re-randomized proof is still bound to the public witness
+			wrongWitness, err := frontend.NewWitness(&aggregationCircuit{X: 3, Y: 28}, curveID)
+			assert.NoError(err)
+			wrongPublicWitness, err := wrongWitness.Public()
+			assert.NoError(err)
+			assert.Error(Verify(proof, vk, wrongPublicWitness))
+		})
+	}
+}
@@ -0,0 +1,157 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package groth16
+
+import (
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+
+	groth16_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/groth16"
+	groth16_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/groth16"
+	groth16_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/groth16"
+	groth16_bn254 "github.com/consensys/gnark/internal/backend/bn254/groth16"
+	groth16_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/groth16"
+	groth16_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/groth16"
+)
+
+// Section identifies a part of the sectioned encoding of a ProvingKey, written by
+// ProvingKey.WriteSectionsTo, which can be read independently of the others
+type Section uint8
+
+const (
+	// SectionParameters holds the domain, [α]1, [β]1, [δ]1, [β]2, [δ]2, the points
+	// at infinity of A and B, and the commitment key. It is always read.
+	SectionParameters Section = iota
+	SectionA                  // [A(i)]1
+	SectionB                  // [B(i)]1
+	SectionZ                  // [Z(i)]1
+	SectionK                  // [Kpk(i)]1
+	SectionG2B                // [B(i)]2
+)
+
+// ReadProvingKeySections reads a ProvingKey written by ProvingKey.WriteSectionsTo or
+// ProvingKey.WriteRawSectionsTo from r.
+//
+// Only the parameters of the key and the sections in load are read. Prove reads the
+// other ones from r when it needs them, one at a time, and drops them after use:
+// its MSMs then run one after the other, but it only needs the memory of one section
+// of the key at once. r must not be modified while the key is in use, and the key can
+// only be used by Prove.
+func ReadProvingKeySections(curveID ecc.ID, r io.ReaderAt, load ...Section) (ProvingKey, error) {
+	return readProvingKeySections(curveID, r, load, false)
+}
+
+// UnsafeReadProvingKeySections behaves like ReadProvingKeySections excepts it doesn't check if
+// the decoded points are on the curve or in the correct subgroup
+func UnsafeReadProvingKeySections(curveID ecc.ID, r io.ReaderAt, load ...Section) (ProvingKey, error) {
+	return readProvingKeySections(curveID, r, load, true)
+}
+
+func readProvingKeySections(curveID ecc.ID, r io.ReaderAt, load []Section, unsafe bool) (ProvingKey, error) {
+	switch curveID {
+	case ecc.BN254:
+		pk := &groth16_bn254.ProvingKey{}
+		s := make([]groth16_bn254.Section, len(load))
+		for i := range load {
+			s[i] = groth16_bn254.Section(load[i])
+		}
+		read := pk.ReadSectionsFrom
+		if unsafe {
+			read = pk.UnsafeReadSectionsFrom
+		}
+		if err := read(r, s...); err != nil {
+			return nil, err
+		}
+		return pk, nil
+	case ecc.BLS12_377:
+		pk := &groth16_bls12377.ProvingKey{}
+		s := make([]groth16_bls12377.Section, len(load))
+		for i := range load {
+			s[i] = groth16_bls12377.Section(load[i])
+		}
+		read := pk.ReadSectionsFrom
+		if unsafe {
+			read = pk.UnsafeReadSectionsFrom
+		}
+		if err := read(r, s...); err != nil {
+			return nil, err
+		}
+		return pk, nil
+	case ecc.BLS12_381:
+		pk := &groth16_bls12381.ProvingKey{}
+		s := make([]groth16_bls12381.Section, len(load))
+		for i := range load {
+			s[i] = groth16_bls12381.Section(load[i])
+		}
+		read := pk.ReadSectionsFrom
+		if unsafe {
+			read = pk.UnsafeReadSectionsFrom
+		}
+		if err := read(r, s...); err != nil {
+			return nil, err
+		}
+		return pk, nil
+	case ecc.BW6_761:
+		pk := &groth16_bw6761.ProvingKey{}
+		s := make([]groth16_bw6761.Section, len(load))
+		for i := range load {
+			s[i] = groth16_bw6761.Section(load[i])
+		}
+		read := pk.ReadSectionsFrom
+		if unsafe {
+			read = pk.UnsafeReadSectionsFrom
+		}
+		if err := read(r, s...); err != nil {
+			return nil, err
+		}
+		return pk, nil
+	case ecc.BLS24_315:
+		pk := &groth16_bls24315.ProvingKey{}
+		s := make([]groth16_bls24315.Section, len(load))
+		for i := range load {
+			s[i] = groth16_bls24315.Section(load[i])
+		}
+		read := pk.ReadSectionsFrom
+		if unsafe {
+			read = pk.UnsafeReadSectionsFrom
+		}
+		if err := read(r, s...); err != nil {
+			return nil, err
+		}
+		return pk, nil
+	case ecc.BW6_633:
+		pk := &groth16_bw6633.ProvingKey{}
+		s := make([]groth16_bw6633.Section, len(load))
+		for i := range load {
+			s[i] = groth16_bw6633.Section(load[i])
+		}
+		read := pk.ReadSectionsFrom
+		if unsafe {
+			read = pk.UnsafeReadSectionsFrom
+		}
+		if err := read(r, s...); err != nil {
+			return nil, err
+		}
+		return pk, nil
+	default:
+		panic("not implemented")
+	}
+}
@@ -0,0 +1,77 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+package groth16
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	gnarkio "github.com/consensys/gnark/io"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvingKeySections(t *testing.T) {
+	for _, curveID := range []ecc.ID{ecc.BN254, ecc.BLS12_377, ecc.BLS12_381, ecc.BLS24_315, ecc.BW6_633, ecc.BW6_761} {
+		t.Run(curveID.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			ccs, err := frontend.Compile(curveID, r1cs.NewBuilder, &aggregationCircuit{})
+			assert.NoError(err)
+			pk, vk, err := Setup(ccs)
+			assert.NoError(err)
+
+			fullWitness, err := frontend.NewWitness(&aggregationCircuit{X: 3, Y: 27}, curveID)
+			assert.NoError(err)
+			publicWitness, err := fullWitness.Public()
+			assert.NoError(err)
+
+			var compressed, raw bytes.Buffer
+			_, err = pk.WriteSectionsTo(&compressed)
+			assert.NoError(err)
+			_, err = pk.WriteRawSectionsTo(&raw)
+			assert.NoError(err)
+			assert.Less(compressed.Len(), raw.Len())
+
+			for _, buf := range [][]byte{compressed.Bytes(), raw.Bytes()} {
+				// all the sections loaded
+				loaded, err := ReadProvingKeySections(curveID, bytes.NewReader(buf), SectionA, SectionB, SectionZ, SectionK, SectionG2B)
+				assert.NoError(err)
+				assert.False(pk.IsDifferent(loaded), "loaded key differs from the original one")
+				assert.Equal(pk.NbG1(), loaded.NbG1())
+				assert.Equal(pk.NbG2(), loaded.NbG2())
+
+				// sections read by the prover, or some of them loaded
+				for _, load := range [][]Section{nil, {SectionA, SectionG2B}} {
+					lazy, err := UnsafeReadProvingKeySections(curveID, bytes.NewReader(buf), load...)
+					assert.NoError(err)
+					assert.Equal(pk.NbG1(), lazy.NbG1())
+					assert.Equal(pk.NbG2(), lazy.NbG2())
+					proof, err := Prove(ccs, lazy, fullWitness)
+					assert.NoError(err)
+					assert.NoError(Verify(proof, vk, publicWitness))
+				}
+			}
+
+			// the other encodings of the key are rejected
+			var buf bytes.Buffer
+			_, err = pk.WriteTo(&buf)
+			assert.NoError(err)
+			_, err = ReadProvingKeySections(curveID, bytes.NewReader(buf.Bytes()))
+			assert.Error(err)
+
+			// as are the headers of the versions predating the sectioned encoding
+			former := append([]byte{}, compressed.Bytes()...)
+			former[4] = gnarkio.VersionProvingKeySections - 1
+			_, err = ReadProvingKeySections(curveID, bytes.NewReader(former))
+			assert.Error(err)
+
+			// a truncated key is rejected
+			_, err = ReadProvingKeySections(curveID, bytes.NewReader(compressed.Bytes()[:compressed.Len()-1]), SectionG2B)
+			assert.Error(err)
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package hint
 
 import (
+	"errors"
+	"fmt"
 	"math/big"
 
 	"github.com/consensys/gnark-crypto/ecc"
@@ -8,6 +10,8 @@ import (
 
 func init() {
 	Register(IsZero)
+	Register(BatchInvert)
+	Register(Limbs)
 }
 
 // IsZero computes the value 1 - a^(modulus-1) for the single input a. This
@@ -33,3 +37,57 @@ func IsZero(curveID ecc.ID, inputs []*big.Int, results []*big.Int) error {
 
 	return nil
 }
+
+// BatchInvert computes the inverses of the inputs with Montgomery's trick, that
+// is with a single modular inversion and 3(n-1) multiplications. It fails if
+// one of the inputs is zero.
+func BatchInvert(curveID ecc.ID, inputs []*big.Int, results []*big.Int) error {
+	if len(inputs) != len(results) {
+		return errors.New("BatchInvert: expected as many outputs as inputs")
+	}
+
+	// get fr modulus
+	q := curveID.Info().Fr.Modulus()
+
+	// results[i] = inputs[0] * ... * inputs[i-1]
+	acc := big.NewInt(1)
+	for i := range inputs {
+		results[i].Set(acc)
+		acc.Mul(acc, inputs[i]).Mod(acc, q)
+		if acc.Sign() == 0 {
+			return fmt.Errorf("BatchInvert: input %d is zero", i)
+		}
+	}
+
+	// acc = 1 / (inputs[0] * ... * inputs[i]), from the last input to the first
+	acc.ModInverse(acc, q)
+	for i := len(inputs) - 1; i >= 0; i-- {
+		results[i].Mul(results[i], acc).Mod(results[i], q)
+		acc.Mul(acc, inputs[i]).Mod(acc, q)
+	}
+
+	return nil
+}
+
+// Limbs decomposes inputs[0] in len(results) little-endian limbs of inputs[1]
+// bits. The last limb holds all the remaining high bits of the input.
+func Limbs(_ ecc.ID, inputs []*big.Int, results []*big.Int) error {
+	if len(inputs) != 2 || !inputs[1].IsUint64() {
+		return errors.New("Limbs: expected an input and a number of bits")
+	}
+	if len(results) == 0 {
+		return errors.New("Limbs: expected at least one output")
+	}
+	nbBits := uint(inputs[1].Uint64())
+	mask := new(big.Int).Lsh(big.NewInt(1), nbBits)
+	mask.Sub(mask, big.NewInt(1))
+
+	n := new(big.Int).Set(inputs[0])
+	for i := 0; i < len(results)-1; i++ {
+		results[i].And(n, mask)
+		n.Rsh(n, nbBits)
+	}
+	results[len(results)-1].Set(n)
+
+	return nil
+}
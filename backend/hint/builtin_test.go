@@ -0,0 +1,42 @@
+package hint_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchInvert(t *testing.T) {
+	assert := require.New(t)
+	q := ecc.BN254.Info().Fr.Modulus()
+
+	inputs := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), new(big.Int).Sub(q, big.NewInt(1))}
+	results := make([]*big.Int, len(inputs))
+	for i := range results {
+		results[i] = new(big.Int)
+	}
+	assert.NoError(hint.BatchInvert(ecc.BN254, inputs, results))
+	for i := range inputs {
+		var expected big.Int
+		expected.ModInverse(inputs[i], q)
+		assert.Equal(0, expected.Cmp(results[i]), "input %d", i)
+	}
+
+	inputs[2].SetUint64(0)
+	assert.Error(hint.BatchInvert(ecc.BN254, inputs, results))
+}
+
+func TestLimbs(t *testing.T) {
+	assert := require.New(t)
+
+	results := []*big.Int{new(big.Int), new(big.Int), new(big.Int)}
+	assert.NoError(hint.Limbs(ecc.BN254, []*big.Int{big.NewInt(0x1234567), big.NewInt(8)}, results))
+	assert.Equal(uint64(0x67), results[0].Uint64())
+	assert.Equal(uint64(0x45), results[1].Uint64())
+	assert.Equal(uint64(0x123), results[2].Uint64(), "the last limb holds the remaining bits")
+
+	assert.Error(hint.Limbs(ecc.BN254, []*big.Int{big.NewInt(1)}, results))
+}
@@ -33,11 +33,17 @@ Using hint functions in circuits
 
 To use a hint function in a circuit, the developer first needs to define a hint
 function hintFn according to the Function interface. Then, in a circuit, the
-developer applies the hint function with frontend.API.NewHint(hintFn, vars...),
-where vars are the variables the hint function will be applied to (and
-correspond to the argument inputs in the Function type) which returns a new
-unconstrained variable. The returned variables must be constrained using
-frontend.API.Assert[.*] methods.
+developer applies the hint function with frontend.API.NewHint(hintFn,
+nbOutputs, vars...), where vars are the variables the hint function will be
+applied to (and correspond to the argument inputs in the Function type) which
+returns nbOutputs new unconstrained variables. The returned variables must be
+constrained using frontend.API.Assert[.*] methods.
+
+A single hint function may compute several related values at once, for example
+the quotient and the remainder of a division, or all the limbs of a
+decomposition. Returning them as outputs of a single hint call is cheaper than
+calling several hints which each redo the same computation, both at solving time
+and in the hint bookkeeping of the constraint system.
 
 As explained, the hints are essentially black boxes from the circuit point of
 view and thus the defined hints in circuits are not used when constructing a
@@ -61,6 +67,14 @@ proving option.
 
 In the init() method of the gadget, call the method Register(hintFn) method on
 the hint function hintFn to register a hint function in the package registry.
+
+By default, the ID of a hint function is derived from the name of its Go symbol,
+which changes when the function is renamed or moved, and which is not stable for
+anonymous functions. A circuit compiled with a given set of hints can then fail
+to be solved by a prover built from a slightly different code base. To prevent
+that, register the hint function under an explicit name with
+RegisterNamed(name, hintFn): its ID is then derived from the name, both at
+compile time and at solving time.
 */
 package hint
 
@@ -87,17 +101,27 @@ type Function func(curveID ecc.ID, inputs []*big.Int, outputs []*big.Int) error
 
 // UUID is a reference function for computing the hint ID based on a function name
 func UUID(fn Function) ID {
-	hf := fnv.New32a()
-	name := Name(fn)
-
-	// TODO relying on name to derive UUID is risky; if fn is an anonymous func, wil be package.glob..funcN
-	// and if new anonymous functions are added in the package, N may change, so will UUID.
-	hf.Write([]byte(name)) // #nosec G104 -- does not err
-
-	return ID(hf.Sum32())
+	// relying on the Go symbol to derive UUID is risky; if fn is an anonymous func, it will be
+	// package.glob..funcN and if new anonymous functions are added in the package, N may change,
+	// so will UUID. Hints registered with RegisterNamed have a stable name.
+	return nameUUID(Name(fn))
 }
 
+// Name returns the name of the hint function: the name it was registered with
+// through RegisterNamed, or else the name of its Go symbol.
 func Name(fn Function) string {
 	fnptr := reflect.ValueOf(fn).Pointer()
+	registryM.RLock()
+	name, ok := names[fnptr]
+	registryM.RUnlock()
+	if ok {
+		return name
+	}
 	return runtime.FuncForPC(fnptr).Name()
 }
+
+func nameUUID(name string) ID {
+	hf := fnv.New32a()
+	hf.Write([]byte(name)) // #nosec G104 -- does not err
+	return ID(hf.Sum32())
+}
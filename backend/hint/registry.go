@@ -1,20 +1,22 @@
 package hint
 
 import (
+	"reflect"
 	"sync"
 
 	"github.com/consensys/gnark/logger"
 )
 
 var registry = make(map[ID]Function)
+var names = make(map[uintptr]string) // names of the functions registered with RegisterNamed
 var registryM sync.RWMutex
 
 // Register registers an hint function in the global registry.
 func Register(hintFn Function) {
-	registryM.Lock()
-	defer registryM.Unlock()
 	key := UUID(hintFn)
 	name := Name(hintFn)
+	registryM.Lock()
+	defer registryM.Unlock()
 	if _, ok := registry[key]; ok {
 		log := logger.Logger()
 		log.Warn().Str("name", name).Msg("function registered multiple times")
@@ -23,6 +25,32 @@ func Register(hintFn Function) {
 	registry[key] = hintFn
 }
 
+// RegisterNamed registers an hint function in the global registry under the
+// given name. The ID of the hint function (see UUID) is then derived from name
+// instead of from its Go symbol, so that it doesn't change when the function is
+// renamed, moved or defined as an anonymous function.
+//
+// As functions are identified by their code pointer, all the closures created
+// from the same function literal share the same name.
+func RegisterNamed(name string, hintFn Function) {
+	key := nameUUID(name)
+	fnptr := reflect.ValueOf(hintFn).Pointer()
+	registryM.Lock()
+	defer registryM.Unlock()
+	if _, ok := registry[key]; ok {
+		log := logger.Logger()
+		log.Warn().Str("name", name).Msg("function registered multiple times")
+		return
+	}
+	if previous, ok := names[fnptr]; ok {
+		log := logger.Logger()
+		log.Warn().Str("name", name).Str("previous", previous).Msg("function registered under several names")
+		return
+	}
+	registry[key] = hintFn
+	names[fnptr] = name
+}
+
 // GetRegistered returns all registered hint functions.
 func GetRegistered() []Function {
 	registryM.RLock()
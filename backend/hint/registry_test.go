@@ -0,0 +1,72 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+package hint_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+const namedHintName = "github.com/consensys/gnark/backend/hint_test.square"
+
+func init() {
+	hint.RegisterNamed(namedHintName, func(_ ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+		outputs[0].Mul(inputs[0], inputs[0])
+		return nil
+	})
+}
+
+// namedHint returns the function registered under namedHintName
+func namedHint() hint.Function {
+	for _, fn := range hint.GetRegistered() {
+		if hint.Name(fn) == namedHintName {
+			return fn
+		}
+	}
+	return nil
+}
+
+type squareCircuit struct {
+	X, Y frontend.Variable
+}
+
+func (c *squareCircuit) Define(api frontend.API) error {
+	res, err := api.Compiler().NewHint(namedHint(), 1, c.X)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(res[0], api.Mul(c.X, c.X))
+	api.AssertIsEqual(res[0], c.Y)
+	return nil
+}
+
+func TestRegisterNamed(t *testing.T) {
+	assert := require.New(t)
+
+	fn := namedHint()
+	assert.NotNil(fn, "named hint not registered")
+	assert.Equal(namedHintName, hint.Name(fn))
+
+	// the prover resolves the hint from the registry, without backend.WithHints
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &squareCircuit{})
+	assert.NoError(err)
+	pk, _, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&squareCircuit{X: 3, Y: 9}, ecc.BN254)
+	assert.NoError(err)
+	_, err = groth16.Prove(ccs, pk, w)
+	assert.NoError(err)
+
+	opt, err := backend.NewProverConfig()
+	assert.NoError(err)
+	assert.Contains(opt.HintFunctions, hint.UUID(fn))
+}
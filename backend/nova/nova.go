@@ -0,0 +1,234 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package nova implements the folding scheme of Nova on R1CS, as an experimental
+// building block: it is NOT an incrementally verifiable computation (IVC) scheme,
+// and its proofs are neither succinct nor zero-knowledge.
+//
+// A step circuit is iterated many times: the public inputs of the circuit are the
+// state before the step, followed by the state after it, and the input state of a
+// step must be the output state of the previous one. Each step is an R1CS
+// instance, committed to with Pedersen vector commitments, which the Prover folds
+// into a running relaxed R1CS instance Az∘Bz = u⋅Cz + E. Folding costs two MSMs
+// and a pass over the constraints per step, whatever the number of steps, and
+// the folded instance keeps the size of a single step.
+//
+// The Proof is the transcript of the folds: the instances of all the steps, the
+// commitments to the cross terms, and the folded witness in clear. Verify folds
+// the instances again and decides the folded instance with the folded witness, so
+// the proof and its verification grow linearly with the number of steps, and the
+// proof reveals a combination of the witnesses of the steps.
+//
+// IVC needs the augmented circuit of Nova, which verifies the fold of the
+// previous step in-circuit over a cycle of elliptic curves, and a succinct
+// zero-knowledge proof of the final folded instance: neither is provided.
+// Circuits using frontend.API.Commit are not supported.
+package nova
+
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	backend_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/cs"
+	backend_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/cs"
+	backend_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/cs"
+	backend_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
+	backend_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/cs"
+	backend_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/cs"
+
+	witness_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/witness"
+	witness_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/witness"
+	witness_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/witness"
+	witness_bn254 "github.com/consensys/gnark/internal/backend/bn254/witness"
+	witness_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/witness"
+	witness_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/witness"
+
+	nova_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/nova"
+	nova_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/nova"
+	nova_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/nova"
+	nova_bn254 "github.com/consensys/gnark/internal/backend/bn254/nova"
+	nova_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/nova"
+	nova_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/nova"
+)
+
+// Key is the commitment key of the folding scheme of a step circuit
+//
+// it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
+type Key interface {
+	CurveID() ecc.ID
+}
+
+// Proof is the transcript of the folds of an iterated computation: the instances
+// of its steps, and the folded witness. It is neither succinct nor zero-knowledge.
+//
+// it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
+type Proof interface {
+	CurveID() ecc.ID
+
+	// NbSteps returns the number of steps of the computation
+	NbSteps() int
+}
+
+// NewKey returns the Key of the step circuit ccs derived from seed. Its points are
+// hashed to G1.
+func NewKey(ccs frontend.CompiledConstraintSystem, seed []byte) (Key, error) {
+	switch _r1cs := ccs.(type) {
+	case *backend_bls12377.R1CS:
+		return nova_bls12377.NewKey(_r1cs, seed)
+	case *backend_bls12381.R1CS:
+		return nova_bls12381.NewKey(_r1cs, seed)
+	case *backend_bn254.R1CS:
+		return nova_bn254.NewKey(_r1cs, seed)
+	case *backend_bw6761.R1CS:
+		return nova_bw6761.NewKey(_r1cs, seed)
+	case *backend_bls24315.R1CS:
+		return nova_bls24315.NewKey(_r1cs, seed)
+	case *backend_bw6633.R1CS:
+		return nova_bw6633.NewKey(_r1cs, seed)
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+}
+
+// Prover folds the steps of an iterated computation
+type Prover struct {
+	ccs frontend.CompiledConstraintSystem
+	p   interface{}
+}
+
+// NewProver returns a Prover of the computation iterating the step circuit ccs
+func NewProver(ccs frontend.CompiledConstraintSystem, key Key) (*Prover, error) {
+	var (
+		p   interface{}
+		err error
+	)
+	switch _r1cs := ccs.(type) {
+	case *backend_bls12377.R1CS:
+		p, err = nova_bls12377.NewProver(_r1cs, key.(*nova_bls12377.Key))
+	case *backend_bls12381.R1CS:
+		p, err = nova_bls12381.NewProver(_r1cs, key.(*nova_bls12381.Key))
+	case *backend_bn254.R1CS:
+		p, err = nova_bn254.NewProver(_r1cs, key.(*nova_bn254.Key))
+	case *backend_bw6761.R1CS:
+		p, err = nova_bw6761.NewProver(_r1cs, key.(*nova_bw6761.Key))
+	case *backend_bls24315.R1CS:
+		p, err = nova_bls24315.NewProver(_r1cs, key.(*nova_bls24315.Key))
+	case *backend_bw6633.R1CS:
+		p, err = nova_bw6633.NewProver(_r1cs, key.(*nova_bw6633.Key))
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Prover{ccs: ccs, p: p}, nil
+}
+
+// Step solves the step circuit with fullWitness, and folds it into the
+// computation. The input state of fullWitness must be the output state of the
+// previous step.
+func (p *Prover) Step(fullWitness *witness.Witness, opts ...backend.ProverOption) error {
+	opt, err := backend.NewProverConfig(opts...)
+	if err != nil {
+		return err
+	}
+
+	switch _p := p.p.(type) {
+	case *nova_bls12377.Prover:
+		w, ok := fullWitness.Vector.(*witness_bls12377.Witness)
+		if !ok {
+			return witness.ErrInvalidWitness
+		}
+		return _p.Step(*w, opt)
+	case *nova_bls12381.Prover:
+		w, ok := fullWitness.Vector.(*witness_bls12381.Witness)
+		if !ok {
+			return witness.ErrInvalidWitness
+		}
+		return _p.Step(*w, opt)
+	case *nova_bn254.Prover:
+		w, ok := fullWitness.Vector.(*witness_bn254.Witness)
+		if !ok {
+			return witness.ErrInvalidWitness
+		}
+		return _p.Step(*w, opt)
+	case *nova_bw6761.Prover:
+		w, ok := fullWitness.Vector.(*witness_bw6761.Witness)
+		if !ok {
+			return witness.ErrInvalidWitness
+		}
+		return _p.Step(*w, opt)
+	case *nova_bls24315.Prover:
+		w, ok := fullWitness.Vector.(*witness_bls24315.Witness)
+		if !ok {
+			return witness.ErrInvalidWitness
+		}
+		return _p.Step(*w, opt)
+	case *nova_bw6633.Prover:
+		w, ok := fullWitness.Vector.(*witness_bw6633.Witness)
+		if !ok {
+			return witness.ErrInvalidWitness
+		}
+		return _p.Step(*w, opt)
+	default:
+		panic("unrecognized prover curve type")
+	}
+}
+
+// Proof returns the proof of the steps folded so far
+func (p *Prover) Proof() Proof {
+	switch _p := p.p.(type) {
+	case *nova_bls12377.Prover:
+		return _p.Proof()
+	case *nova_bls12381.Prover:
+		return _p.Proof()
+	case *nova_bn254.Prover:
+		return _p.Proof()
+	case *nova_bw6761.Prover:
+		return _p.Proof()
+	case *nova_bls24315.Prover:
+		return _p.Proof()
+	case *nova_bw6633.Prover:
+		return _p.Proof()
+	default:
+		panic("unrecognized prover curve type")
+	}
+}
+
+// Verify returns nil if proof proves the computation iterating the step circuit
+// ccs, with key. It costs a fold per step, and a satisfiability check of ccs.
+func Verify(ccs frontend.CompiledConstraintSystem, key Key, proof Proof) error {
+	switch _r1cs := ccs.(type) {
+	case *backend_bls12377.R1CS:
+		return nova_bls12377.Verify(_r1cs, key.(*nova_bls12377.Key), proof.(*nova_bls12377.Proof))
+	case *backend_bls12381.R1CS:
+		return nova_bls12381.Verify(_r1cs, key.(*nova_bls12381.Key), proof.(*nova_bls12381.Proof))
+	case *backend_bn254.R1CS:
+		return nova_bn254.Verify(_r1cs, key.(*nova_bn254.Key), proof.(*nova_bn254.Proof))
+	case *backend_bw6761.R1CS:
+		return nova_bw6761.Verify(_r1cs, key.(*nova_bw6761.Key), proof.(*nova_bw6761.Proof))
+	case *backend_bls24315.R1CS:
+		return nova_bls24315.Verify(_r1cs, key.(*nova_bls24315.Key), proof.(*nova_bls24315.Proof))
+	case *backend_bw6633.R1CS:
+		return nova_bw6633.Verify(_r1cs, key.(*nova_bw6633.Key), proof.(*nova_bw6633.Proof))
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+}
@@ -0,0 +1,75 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+package nova_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/nova"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	nova_bn254 "github.com/consensys/gnark/internal/backend/bn254/nova"
+	"github.com/stretchr/testify/require"
+)
+
+// stepCircuit maps the state (x, y) to (y, x + y⋅k)
+type stepCircuit struct {
+	In  [2]frontend.Variable `gnark:",public"`
+	Out [2]frontend.Variable `gnark:",public"`
+	K   frontend.Variable
+}
+
+func (circuit *stepCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(circuit.Out[0], circuit.In[1])
+	api.AssertIsEqual(circuit.Out[1], api.Add(circuit.In[0], api.Mul(circuit.In[1], circuit.K)))
+	return nil
+}
+
+func TestFolding(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &stepCircuit{})
+	assert.NoError(err)
+	key, err := nova.NewKey(ccs, []byte("nova"))
+	assert.NoError(err)
+	prover, err := nova.NewProver(ccs, key)
+	assert.NoError(err)
+
+	step := func(x, y, k int) (*stepCircuit, int, int) {
+		return &stepCircuit{In: [2]frontend.Variable{x, y}, Out: [2]frontend.Variable{y, x + y*k}, K: k}, y, x + y*k
+	}
+	x, y := 1, 1
+	for k := 1; k <= 5; k++ {
+		var assignment *stepCircuit
+		assignment, x, y = step(x, y, k)
+		w, err := frontend.NewWitness(assignment, ecc.BN254)
+		assert.NoError(err)
+		assert.NoError(prover.Step(w))
+	}
+	proof := prover.Proof()
+	assert.Equal(5, proof.NbSteps())
+	assert.NoError(nova.Verify(ccs, key, proof))
+
+	// a step which doesn't start from the previous state is rejected
+	assignment, _, _ := step(x+1, y, 1)
+	w, err := frontend.NewWitness(assignment, ecc.BN254)
+	assert.NoError(err)
+	assert.Error(prover.Step(w))
+
+	// so is an unsatisfied step
+	assignment, _, _ = step(x, y, 1)
+	assignment.K = 2
+	w, err = frontend.NewWitness(assignment, ecc.BN254)
+	assert.NoError(err)
+	assert.Error(prover.Step(w))
+
+	// and a proof whose last output state is tampered with
+	tampered := *proof.(*nova_bn254.Proof)
+	tampered.Steps = append(tampered.Steps[:0:0], tampered.Steps...)
+	tampered.Steps[4].X = append(tampered.Steps[4].X[:0:0], tampered.Steps[4].X...)
+	tampered.Steps[4].X[3].SetUint64(42)
+	assert.Error(nova.Verify(ccs, key, &tampered))
+	assert.NoError(nova.Verify(ccs, key, proof))
+}
@@ -0,0 +1,65 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+package plonk_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+type cubicCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *cubicCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X, circuit.X), circuit.Y)
+	return nil
+}
+
+func TestMmapProvingKey(t *testing.T) {
+	for _, curveID := range []ecc.ID{ecc.BN254, ecc.BLS12_377, ecc.BLS12_381, ecc.BLS24_315, ecc.BW6_633, ecc.BW6_761} {
+		t.Run(curveID.String(), func(t *testing.T) {
+			if testing.Short() && (curveID == ecc.BW6_633 || curveID == ecc.BW6_761) {
+				t.Skip("skipping slow kzg srs generation in short mode")
+			}
+			assert := require.New(t)
+
+			ccs, err := frontend.Compile(curveID, scs.NewBuilder, &cubicCircuit{})
+			assert.NoError(err)
+			srs, err := test.NewKZGSRS(ccs)
+			assert.NoError(err)
+			pk, vk, err := plonk.Setup(ccs, srs)
+			assert.NoError(err)
+
+			path := filepath.Join(t.TempDir(), "pk.dump")
+			f, err := os.Create(path)
+			assert.NoError(err)
+			_, err = pk.WriteDump(f)
+			assert.NoError(err)
+			assert.NoError(f.Close())
+
+			// the kzg srs is dumped with the key
+			mmapped, closer, err := plonk.MmapProvingKey(curveID, path)
+			assert.NoError(err)
+			defer closer.Close()
+
+			fullWitness, err := frontend.NewWitness(&cubicCircuit{X: 3, Y: 27}, curveID)
+			assert.NoError(err)
+			publicWitness, err := fullWitness.Public()
+			assert.NoError(err)
+			proof, err := plonk.Prove(ccs, mmapped, fullWitness)
+			assert.NoError(err)
+			assert.NoError(plonk.Verify(proof, vk, publicWitness))
+		})
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package eip4844 imports the SRS of the Ethereum KZG ceremony (EIP-4844)
+// as a KZG SRS on BLS12-381, to be used by plonk.Setup.
+//
+// See https://github.com/ethereum/kzg-ceremony
+package eip4844
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr/kzg"
+	"github.com/consensys/gnark/internal/utils"
+)
+
+var (
+	errInconsistentPowers = errors.New("eip4844: the powers of τ are inconsistent")
+	errInvalidGenerator   = errors.New("eip4844: the first power of τ is not the generator")
+)
+
+// transcript is the JSON transcript published at the end of the ceremony, it
+// holds one sub-ceremony per size of SRS
+type transcript struct {
+	Transcripts []subCeremony `json:"transcripts"`
+}
+
+// subCeremony holds the powers of τ of a sub-ceremony, as hexadecimal
+// compressed points
+type subCeremony struct {
+	NumG1Powers int `json:"numG1Powers"`
+	NumG2Powers int `json:"numG2Powers"`
+	PowersOfTau struct {
+		G1Powers []string `json:"G1Powers"`
+		G2Powers []string `json:"G2Powers"`
+	} `json:"powersOfTau"`
+}
+
+// ImportSRS reads the transcript of the ceremony (transcript.json) from r,
+// and returns the KZG SRS made of the first size powers of τ of the smallest
+// sub-ceremony holding enough of them.
+//
+// The sub-ceremonies hold 2¹², 2¹³, 2¹⁴ and 2¹⁵ powers of τ, and plonk.Setup
+// needs plonk.SRSSize(ccs) of them: the SRS supports circuits of up to 2¹⁴
+// constraints (and public inputs).
+//
+// The points are checked to be in the subgroup, and the powers of τ in G₁ to
+// be consistent with [τ]₂.
+func ImportSRS(r io.Reader, size uint64) (*kzg.SRS, error) {
+	var t transcript
+	if err := json.NewDecoder(r).Decode(&t); err != nil {
+		return nil, fmt.Errorf("eip4844: %w", err)
+	}
+	if size < 2 {
+		return nil, kzg.ErrMinSRSSize
+	}
+
+	for _, sub := range t.Transcripts {
+		if uint64(sub.NumG1Powers) < size {
+			continue
+		}
+		if len(sub.PowersOfTau.G1Powers) != sub.NumG1Powers || len(sub.PowersOfTau.G2Powers) != sub.NumG2Powers || sub.NumG2Powers < 2 {
+			return nil, errors.New("eip4844: invalid number of powers of τ")
+		}
+
+		var srs kzg.SRS
+		srs.G1 = make([]curve.G1Affine, size)
+		if err := decodeG1(srs.G1, sub.PowersOfTau.G1Powers[:size]); err != nil {
+			return nil, err
+		}
+		for i := range srs.G2 {
+			if err := decode(&srs.G2[i], sub.PowersOfTau.G2Powers[i]); err != nil {
+				return nil, fmt.Errorf("eip4844: G2 power %d: %w", i, err)
+			}
+		}
+		if err := checkPowers(&srs); err != nil {
+			return nil, err
+		}
+		return &srs, nil
+	}
+
+	return nil, fmt.Errorf("eip4844: no sub-ceremony holds %d powers of τ", size)
+}
+
+// decodeG1 decodes the hexadecimal compressed points in parallel
+func decodeG1(points []curve.G1Affine, hexPoints []string) error {
+	var lock sync.Mutex
+	var err error
+	utils.Parallelize(len(points), func(start, end int) {
+		for i := start; i < end; i++ {
+			if _err := decode(&points[i], hexPoints[i]); _err != nil {
+				lock.Lock()
+				err = fmt.Errorf("eip4844: G1 power %d: %w", i, _err)
+				lock.Unlock()
+				return
+			}
+		}
+	})
+	return err
+}
+
+// decode sets p from its hexadecimal compressed encoding, 0x prefixed
+func decode(p interface{ SetBytes([]byte) (int, error) }, s string) error {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return err
+	}
+	n, err := p.SetBytes(b)
+	if err != nil {
+		return err
+	}
+	if n != len(b) {
+		return errors.New("trailing bytes")
+	}
+	return nil
+}
+
+// checkPowers checks that srs starts with the generators, and that
+// e([τⁱ]₁, [τ]₂) = e([τⁱ⁺¹]₁, [1]₂), with a random linear combination of the
+// powers: e(∑ ρⁱ[τⁱ]₁, [τ]₂) = e(∑ ρⁱ[τⁱ⁺¹]₁, [1]₂)
+func checkPowers(srs *kzg.SRS) error {
+	_, _, g1, g2 := curve.Generators()
+	if !srs.G1[0].Equal(&g1) || !srs.G2[0].Equal(&g2) {
+		return errInvalidGenerator
+	}
+
+	n := len(srs.G1) - 1
+	rho := make([]fr.Element, n)
+	rho[0].SetOne()
+	var r fr.Element
+	if _, err := r.SetRandom(); err != nil {
+		return err
+	}
+	for i := 1; i < n; i++ {
+		rho[i].Mul(&rho[i-1], &r)
+	}
+
+	var left, right curve.G1Affine
+	if _, err := left.MultiExp(srs.G1[:n], rho, ecc.MultiExpConfig{}); err != nil {
+		return err
+	}
+	if _, err := right.MultiExp(srs.G1[1:], rho, ecc.MultiExpConfig{}); err != nil {
+		return err
+	}
+	right.Neg(&right)
+	ok, err := curve.PairingCheck([]curve.G1Affine{left, right}, []curve.G2Affine{srs.G2[1], srs.G2[0]})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errInconsistentPowers
+	}
+	return nil
+}
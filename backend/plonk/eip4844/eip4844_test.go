@@ -0,0 +1,104 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+package eip4844
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr/kzg"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/stretchr/testify/require"
+)
+
+type cubicCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *cubicCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X, circuit.X), circuit.Y)
+	return nil
+}
+
+// newTranscript returns a transcript with sub-ceremonies of the given sizes,
+// of toxic waste tau, holding 3 powers of τ in G₂
+func newTranscript(t *testing.T, tau int64, sizes ...uint64) transcript {
+	var tr transcript
+	for _, size := range sizes {
+		srs, err := kzg.NewSRS(size, big.NewInt(tau))
+		require.NoError(t, err)
+
+		sub := subCeremony{NumG1Powers: int(size), NumG2Powers: 3}
+		for i := range srs.G1 {
+			b := srs.G1[i].Bytes()
+			sub.PowersOfTau.G1Powers = append(sub.PowersOfTau.G1Powers, "0x"+hex.EncodeToString(b[:]))
+		}
+		var tau2 curve.G2Affine
+		tau2.ScalarMultiplication(&srs.G2[1], big.NewInt(tau))
+		for _, p := range []curve.G2Affine{srs.G2[0], srs.G2[1], tau2} {
+			b := p.Bytes()
+			sub.PowersOfTau.G2Powers = append(sub.PowersOfTau.G2Powers, "0x"+hex.EncodeToString(b[:]))
+		}
+		tr.Transcripts = append(tr.Transcripts, sub)
+	}
+	return tr
+}
+
+func encode(t *testing.T, tr transcript) *bytes.Reader {
+	data, err := json.Marshal(&tr)
+	require.NoError(t, err)
+	return bytes.NewReader(data)
+}
+
+func TestImportSRS(t *testing.T) {
+	assert := require.New(t)
+	tr := newTranscript(t, 42, 8, 16, 32)
+
+	// the smallest sub-ceremony large enough is used
+	srs, err := ImportSRS(encode(t, tr), 12)
+	assert.NoError(err)
+	expected, err := kzg.NewSRS(12, big.NewInt(42))
+	assert.NoError(err)
+	assert.Equal(expected, srs)
+
+	_, err = ImportSRS(encode(t, tr), 33)
+	assert.Error(err)
+
+	// inconsistent powers of τ
+	tr.Transcripts[1].PowersOfTau.G1Powers[3] = tr.Transcripts[1].PowersOfTau.G1Powers[4]
+	_, err = ImportSRS(encode(t, tr), 12)
+	assert.ErrorIs(err, errInconsistentPowers)
+
+	// not a point
+	tr.Transcripts[1].PowersOfTau.G1Powers[3] = "0x00"
+	_, err = ImportSRS(encode(t, tr), 12)
+	assert.Error(err)
+}
+
+func TestPlonkSetup(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BLS12_381, scs.NewBuilder, &cubicCircuit{})
+	assert.NoError(err)
+	srs, err := ImportSRS(encode(t, newTranscript(t, 42, 16)), plonk.SRSSize(ccs))
+	assert.NoError(err)
+	pk, vk, err := plonk.Setup(ccs, srs)
+	assert.NoError(err)
+
+	fullWitness, err := frontend.NewWitness(&cubicCircuit{X: 3, Y: 27}, ecc.BLS12_381)
+	assert.NoError(err)
+	publicWitness, err := fullWitness.Public()
+	assert.NoError(err)
+	proof, err := plonk.Prove(ccs, pk, fullWitness)
+	assert.NoError(err)
+	assert.NoError(plonk.Verify(proof, vk, publicWitness))
+}
@@ -0,0 +1,70 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+package plonk_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	plonk_bn254 "github.com/consensys/gnark/internal/backend/bn254/plonk"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLagrangeSRS(t *testing.T) {
+	for _, curveID := range []ecc.ID{ecc.BN254, ecc.BLS12_377, ecc.BLS12_381, ecc.BLS24_315, ecc.BW6_633, ecc.BW6_761} {
+		t.Run(curveID.String(), func(t *testing.T) {
+			if testing.Short() && (curveID == ecc.BW6_633 || curveID == ecc.BW6_761) {
+				t.Skip("skipping slow kzg srs generation in short mode")
+			}
+			assert := require.New(t)
+
+			ccs, err := frontend.Compile(curveID, scs.NewBuilder, &cubicCircuit{})
+			assert.NoError(err)
+			pk, vk, err := plonk.DummySetup(ccs)
+			assert.NoError(err)
+			fullWitness, err := frontend.NewWitness(&cubicCircuit{X: 3, Y: 27}, curveID)
+			assert.NoError(err)
+			publicWitness, err := fullWitness.Public()
+			assert.NoError(err)
+
+			// the commitments don't depend on the basis of the SRS
+			prove := func() []byte {
+				proof, err := plonk.Prove(ccs, pk, fullWitness, backend.WithDeterministicRandomness([]byte("key")))
+				assert.NoError(err)
+				assert.NoError(plonk.Verify(proof, vk, publicWitness))
+				var buf bytes.Buffer
+				_, err = proof.WriteTo(&buf)
+				assert.NoError(err)
+				return buf.Bytes()
+			}
+			expected := prove()
+			assert.NoError(pk.InitLagrangeSRS())
+			assert.Equal(expected, prove())
+		})
+	}
+}
+
+func TestSetLagrangeSRS(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, scs.NewBuilder, &cubicCircuit{})
+	assert.NoError(err)
+	_pk, _, err := plonk.DummySetup(ccs)
+	assert.NoError(err)
+	pk := _pk.(*plonk_bn254.ProvingKey)
+
+	n := int(pk.Domain[0].Cardinality)
+	lagrange := plonk_bn254.ToLagrangeG1(pk.Vk.KZGSRS.G1[:n], &pk.Domain[0])
+	assert.Equal(pk.Vk.KZGSRS.G1[:n], plonk_bn254.ToMonomialG1(lagrange, &pk.Domain[0]))
+
+	assert.Error(pk.SetLagrangeSRS(lagrange[1:]))
+	assert.Error(pk.SetLagrangeSRS(pk.Vk.KZGSRS.G1[:n]))
+	assert.Error(pk.SetLagrangeSRS(nil))
+	assert.NoError(pk.SetLagrangeSRS(lagrange))
+}
@@ -0,0 +1,40 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+package plonk_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProveLegacyEncodings(t *testing.T) {
+	assert := require.New(t)
+
+	pk, vk := plonk.NewProvingKey(ecc.BN254), plonk.NewVerifyingKey(ecc.BN254)
+	readLegacy(t, pk, legacyPK)
+	readLegacy(t, vk, legacyVK)
+
+	srs, err := kzg.NewSRS(64, big.NewInt(42))
+	assert.NoError(err)
+	assert.NoError(pk.InitKZG(srs))
+	assert.NoError(vk.InitKZG(srs))
+
+	// the proving key still proves
+	witness, err := frontend.NewWitness(&legacyCircuit{X: 3, Y: 9}, ecc.BN254)
+	assert.NoError(err)
+	publicWitness, err := witness.Public()
+	assert.NoError(err)
+	ccs, err := frontend.Compile(ecc.BN254, scs.NewBuilder, &legacyCircuit{})
+	assert.NoError(err)
+	proof, err := plonk.Prove(ccs, pk, witness)
+	assert.NoError(err)
+	assert.NoError(plonk.Verify(proof, vk, publicWitness))
+}
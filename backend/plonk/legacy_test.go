@@ -0,0 +1,57 @@
+package plonk_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/require"
+)
+
+type legacyCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *legacyCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	return nil
+}
+
+// keys and proof of legacyCircuit over BN254, for X = 3 and Y = 9, written by
+// gnark before the encodings had a header, with the KZG SRS kzg.NewSRS(64, 42)
+const (
+	legacyPK    = "AAAAAAAAAAQkSzrWKOU4H0o8NEjhIQJF3ibuNltLFGzy6Xgu9AAAATBkTnLhMaApBItuGT/YQQRc6iT2/XNr7CMSBHCPcDY2AAAAAAAAAAHvobwWs9WbXuHFYdBggvT4iYyvf69N9Jo9p/r0aSox+Y6bNQggITtL/TeLeQAWsNC9jMKtrM12CbZVwM6uknXPg5wEOPqTd4Rx+sXUzomYDlU4gpPktyDf0DjjK4rpMPas1QIEYsKtUcJJUJKvaHSHvvXJ+IQXzPW7Pr1fqIq/MoT1cVW9SFa+UxIEYpP1sUQrJL6lLOrgo5nda59SARJ/62OUbSzPhSmue6UySQLn7oNLGejdZms1M4UMLtNtff6rY5RtLM+FKa57pTJJAufug0sZ6N1mazUzhQwu0219/kAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAQkSzrWKOU4H0o8NEjhIQJF3ibuNltLFGzy6Xgu9AAAATBkTnLhMaApBItuGT/YQQRc6iT2/XNr7CMSBHCPcDY2AAAAAAAAAACzxNedQakXWMtJw1F8RgSlIM/xI2CPycsAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABRNbUpRaE9mqSbm1fDPNVouprlzpykotBufz+9TGZmZnAAAAAAAAACAu4Sv/SigTKGqNw4jNdU2aPvJJBjXrpQy5wuXnUIAAAQnFMsYwa5PSlnggDUfAsqmcGNUbg47rHT7tTFM7tRLQJyRxNgO/vXkK6vPn3yXY5++PMRM0kFtNjJmYDPIQl50AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABRNbUpRaE9mqSbm1fDPNVouprlzpykotBufz+9TGZmZnAAAABAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAGDInOXCY0BTcKCLbQMCsLpQZ9CQ83LhIofD6yfgAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABgyJzlwmNAU3Cgi20DArC6UGfQkPNy4SKHw+sn4AAAAAAAABAwZE5y4TGgKbhQRbaBgVhdKDPoSHm5cJFD4fWT8AAAAJEs61ijlOB9KPDRI4SECRd4m7jZbSxRs8ul4LvQAAAEMGROcuExoCm4UEW2gYFYXSgz6Eh5uXCRQ+H1k/AAAACRLOtYo5TgfSjw0SOEhAkXeJu42W0sUbPLpeC70AAABAAAABCRLOtYo5TgfSjw0SOEhAkXeJu42W0sUbPLpeC70AAABDBkTnLhMaAqbBUdU8Mqb7XzfauZ9f91NmSx5rdQj8nMMGROcuExoCm4UEW2gYFYXSgz6Eh5uXCRQ+H1k/AAAACRLOtYo5TgfHUr+YZC2vG+rVH1h/DmTQ6q1e+Yb3A2OAAAABAwZE5y4TGgKbhQRbaBgVhdKDPoSHm5cJFD4fWT8AAAAJEs61ijlOB8dSv5hkLa8b6tUfWH8OZNDqrV75hvcDY4kSzrWKOU4H0o8NEjhIQJF3ibuNltLFGzy6Xgu9AAAAQwZE5y4TGgKmwVHVPDKm+1832rmfX/dTZksea3UI/JzAAAABAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABBgyJzlwmNAT+3IVVq6tTv+V/b/+YYUyejjtDV2/TEPDAAAAAAAAAAiaK1FGZFdd/7pHmKYyWbjm0fNJ8V7hZ/gYMic5cJjQFbzeMF/S1AldkjYoShg0PhcK9Og2MLO8PgAAAAAAAAAI9A29FQUr6awf7HpO8Hy7OWJbQoMPKUzbAAAABAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAGDInOXCY0BXpz2ZHIz5PM8UImR53Rb9AUyjkfwjXrrAAAAAAAAAAAFnia86g1IusZaThqL4jAlKQZ/iRsEfk6BgyJzlwmNAUKGNLPf8XlNXI0DDSwJazo4EhCaaXcDY0AAAABBgyJzlwmNAVvN4wX9LUCV2SNihKGDQ+Fwr06DYws7w+GDInOXCY0Bnzez4PXMqVclXwvDMB2Fn1z9SPCnQSdvoYMic5cJjQFbzeMF/S1AldkjYoShg0PhcK9Og2MLO8SxgyJzlwmNARhkEisEjdfUjOe5RhLpAiOEYVQWHtVQF2AAAAAAAAAAsAAAAAAAAABQAAAAAAAAAJAAAAAAAAAAAAAAAAAAAAAwAAAAAAAAABAAAAAAAAAAQAAAAAAAAABgAAAAAAAAAHAAAAAAAAAAIAAAAAAAAACAAAAAAAAAAK"
+	legacyVK    = "AAAAAAAAAAQkSzrWKOU4H0o8NEjhIQJF3ibuNltLFGzy6Xgu9AAAATBkTnLhMaApBItuGT/YQQRc6iT2/XNr7CMSBHCPcDY2AAAAAAAAAAHvobwWs9WbXuHFYdBggvT4iYyvf69N9Jo9p/r0aSox+Y6bNQggITtL/TeLeQAWsNC9jMKtrM12CbZVwM6uknXPg5wEOPqTd4Rx+sXUzomYDlU4gpPktyDf0DjjK4rpMPas1QIEYsKtUcJJUJKvaHSHvvXJ+IQXzPW7Pr1fqIq/MoT1cVW9SFa+UxIEYpP1sUQrJL6lLOrgo5nda59SARJ/62OUbSzPhSmue6UySQLn7oNLGejdZms1M4UMLtNtff6rY5RtLM+FKa57pTJJAufug0sZ6N1mazUzhQwu0219/kAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"
+	legacyProof = "4gLDAmbowvyVNZZzx3sWlkMzQ04OuYsYzS3PQ9nvXDOXuSAbTCj51FqPOnOXsFBle2JDfS2vTQTsUGnQ7zrtGYnD1Yawnh6yWU8rHhjeVF7jC08Hf/cmx6HUW9e9/+gU6YEsbLLtJtyCATmcFskxpsiBFdlfXoq5O58aZeDwgw3QDCCllOabOAT6ABjNY8mPWQ0Dnkfka8k4A4uC7AdoUI62GlQArudoC4VDHB2Fo7eEyg5SLE4Jb1mf6wQE5WyM6Jm6DsBXfHDWzd27+RVk139nOnTuPUD4yEWJKkZSzLPZ2IrI/kiwLpDsstVDEoYjdyANpeD4dTPtw16hTeFuuQAAAAcFEKXDOQ4YFuF5x8fksI8e4C7QDPkebdccfe6jU6YdPC+yZhw/7kFNsKcraK/H/XJfFAWZxf6J/ZpflfOos2JRKkMMFJHOMwBKuGk3MwJcuYvggilgxeEwyhNNt02pPqkKd/rYZ/HS6HnoDyLci1uPdrnWO4IPG9V3XCoE5ClwCynl6dxaOr0Nb7qH8hR6TVMSMEJiLkQv8Bfkw2GWMvVsB3H16WnALj3e7rI9OhQT7HsWG6szJesKW2VhxMsDiVkiAH2gb3cNf4Px/no5cBnOCrj51C8w3oQ/bgznvOj5XqY07BVpkW5SGvgy/ydxZqPID08IXxK/2MswiZUaYHVIC7a5tKDaba/NSDxkiXXtwmT6+0O9qX/Ma5zHwyLb7F8="
+)
+
+func readLegacy(t *testing.T, o io.ReaderFrom, encoded string) {
+	b, err := base64.StdEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+	n, err := o.ReadFrom(bytes.NewReader(b))
+	require.NoError(t, err)
+	require.Equal(t, int64(len(b)), n)
+}
+
+func TestReadLegacyEncodings(t *testing.T) {
+	assert := require.New(t)
+
+	vk, proof := plonk.NewVerifyingKey(ecc.BN254), plonk.NewProof(ecc.BN254)
+	readLegacy(t, vk, legacyVK)
+	readLegacy(t, proof, legacyProof)
+
+	srs, err := kzg.NewSRS(64, big.NewInt(42))
+	assert.NoError(err)
+	assert.NoError(vk.InitKZG(srs))
+
+	witness, err := frontend.NewWitness(&legacyCircuit{X: 3, Y: 9}, ecc.BN254, frontend.PublicOnly())
+	assert.NoError(err)
+	assert.NoError(plonk.Verify(proof, vk, witness))
+}
@@ -14,9 +14,12 @@
 
 // Package plonk implements PLONK Zero Knowledge Proof system.
 //
-// See also
+// # See also
 //
 // https://eprint.iacr.org/2019/953
+//
+// Built with the verifieronly tag, the package only holds the verifier (see
+// examples/wasm): the setup and the prover are left out.
 package plonk
 
 import (
@@ -24,16 +27,8 @@ import (
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/kzg"
-	"github.com/consensys/gnark/backend"
-	"github.com/consensys/gnark/frontend"
-
+	"github.com/consensys/gnark/backend/solidity"
 	"github.com/consensys/gnark/backend/witness"
-	cs_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/cs"
-	cs_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/cs"
-	cs_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/cs"
-	cs_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
-	cs_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/cs"
-	cs_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/cs"
 
 	plonk_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/plonk"
 	plonk_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/plonk"
@@ -48,13 +43,6 @@ import (
 	witness_bn254 "github.com/consensys/gnark/internal/backend/bn254/witness"
 	witness_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/witness"
 	witness_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/witness"
-
-	kzg_bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/fr/kzg"
-	kzg_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr/kzg"
-	kzg_bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/fr/kzg"
-	kzg_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
-	kzg_bw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/fr/kzg"
-	kzg_bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/fr/kzg"
 )
 
 // Proof represents a Plonk proof generated by plonk.Prove
@@ -65,16 +53,6 @@ type Proof interface {
 	io.ReaderFrom
 }
 
-// ProvingKey represents a plonk ProvingKey
-//
-// it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
-type ProvingKey interface {
-	io.WriterTo
-	io.ReaderFrom
-	InitKZG(srs kzg.SRS) error
-	VerifyingKey() interface{}
-}
-
 // VerifyingKey represents a plonk VerifyingKey
 //
 // it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
@@ -83,89 +61,14 @@ type VerifyingKey interface {
 	io.ReaderFrom
 	InitKZG(srs kzg.SRS) error
 	NbPublicWitness() int // number of elements expected in the public witness
-}
 
-// Setup prepares the public data associated to a circuit + public inputs.
-func Setup(ccs frontend.CompiledConstraintSystem, kzgSRS kzg.SRS) (ProvingKey, VerifyingKey, error) {
-
-	switch tccs := ccs.(type) {
-	case *cs_bn254.SparseR1CS:
-		return plonk_bn254.Setup(tccs, kzgSRS.(*kzg_bn254.SRS))
-	case *cs_bls12381.SparseR1CS:
-		return plonk_bls12381.Setup(tccs, kzgSRS.(*kzg_bls12381.SRS))
-	case *cs_bls12377.SparseR1CS:
-		return plonk_bls12377.Setup(tccs, kzgSRS.(*kzg_bls12377.SRS))
-	case *cs_bw6761.SparseR1CS:
-		return plonk_bw6761.Setup(tccs, kzgSRS.(*kzg_bw6761.SRS))
-	case *cs_bls24315.SparseR1CS:
-		return plonk_bls24315.Setup(tccs, kzgSRS.(*kzg_bls24315.SRS))
-	case *cs_bw6633.SparseR1CS:
-		return plonk_bw6633.Setup(tccs, kzgSRS.(*kzg_bw6633.SRS))
-	default:
-		panic("unrecognized SparseR1CS curve type")
-	}
+	// ExportSolidity writes a solidity Verifier contract from the VerifyingKey
+	// this will return an error if not supported on the CurveID()
+	ExportSolidity(w io.Writer, exportOpts ...solidity.ExportOption) error
 
-}
-
-// Prove generates PLONK proof from a circuit, associated preprocessed public data, and the witness
-// if the force flag is set:
-// 	will executes all the prover computations, even if the witness is invalid
-//  will produce an invalid proof
-//	internally, the solution vector to the SparseR1CS will be filled with random values which may impact benchmarking
-func Prove(ccs frontend.CompiledConstraintSystem, pk ProvingKey, fullWitness *witness.Witness, opts ...backend.ProverOption) (Proof, error) {
-
-	// apply options
-	opt, err := backend.NewProverConfig(opts...)
-	if err != nil {
-		return nil, err
-	}
-
-	switch tccs := ccs.(type) {
-	case *cs_bn254.SparseR1CS:
-		w, ok := fullWitness.Vector.(*witness_bn254.Witness)
-		if !ok {
-			return nil, witness.ErrInvalidWitness
-		}
-		return plonk_bn254.Prove(tccs, pk.(*plonk_bn254.ProvingKey), *w, opt)
-
-	case *cs_bls12381.SparseR1CS:
-		w, ok := fullWitness.Vector.(*witness_bls12381.Witness)
-		if !ok {
-			return nil, witness.ErrInvalidWitness
-		}
-		return plonk_bls12381.Prove(tccs, pk.(*plonk_bls12381.ProvingKey), *w, opt)
-
-	case *cs_bls12377.SparseR1CS:
-		w, ok := fullWitness.Vector.(*witness_bls12377.Witness)
-		if !ok {
-			return nil, witness.ErrInvalidWitness
-		}
-		return plonk_bls12377.Prove(tccs, pk.(*plonk_bls12377.ProvingKey), *w, opt)
-
-	case *cs_bw6761.SparseR1CS:
-		w, ok := fullWitness.Vector.(*witness_bw6761.Witness)
-		if !ok {
-			return nil, witness.ErrInvalidWitness
-		}
-		return plonk_bw6761.Prove(tccs, pk.(*plonk_bw6761.ProvingKey), *w, opt)
-
-	case *cs_bw6633.SparseR1CS:
-		w, ok := fullWitness.Vector.(*witness_bw6633.Witness)
-		if !ok {
-			return nil, witness.ErrInvalidWitness
-		}
-		return plonk_bw6633.Prove(tccs, pk.(*plonk_bw6633.ProvingKey), *w, opt)
-
-	case *cs_bls24315.SparseR1CS:
-		w, ok := fullWitness.Vector.(*witness_bls24315.Witness)
-		if !ok {
-			return nil, witness.ErrInvalidWitness
-		}
-		return plonk_bls24315.Prove(tccs, pk.(*plonk_bls24315.ProvingKey), *w, opt)
-
-	default:
-		panic("unrecognized SparseR1CS curve type")
-	}
+	// Fingerprint returns the fingerprint of the circuit the key is set up for,
+	// equal to the Fingerprint of its constraint system
+	Fingerprint() [32]byte
 }
 
 // Verify verifies a PLONK proof, from the proof, preprocessed public data, and public witness.
@@ -220,53 +123,6 @@ func Verify(proof Proof, vk VerifyingKey, publicWitness *witness.Witness) error
 	}
 }
 
-// NewCS instantiate a concrete curved-typed SparseR1CS and return a ConstraintSystem interface
-// This method exists for (de)serialization purposes
-func NewCS(curveID ecc.ID) frontend.CompiledConstraintSystem {
-	var r1cs frontend.CompiledConstraintSystem
-	switch curveID {
-	case ecc.BN254:
-		r1cs = &cs_bn254.SparseR1CS{}
-	case ecc.BLS12_377:
-		r1cs = &cs_bls12377.SparseR1CS{}
-	case ecc.BLS12_381:
-		r1cs = &cs_bls12381.SparseR1CS{}
-	case ecc.BW6_761:
-		r1cs = &cs_bw6761.SparseR1CS{}
-	case ecc.BLS24_315:
-		r1cs = &cs_bls24315.SparseR1CS{}
-	case ecc.BW6_633:
-		r1cs = &cs_bw6633.SparseR1CS{}
-	default:
-		panic("not implemented")
-	}
-	return r1cs
-}
-
-// NewProvingKey instantiates a curve-typed ProvingKey and returns an interface
-// This function exists for serialization purposes
-func NewProvingKey(curveID ecc.ID) ProvingKey {
-	var pk ProvingKey
-	switch curveID {
-	case ecc.BN254:
-		pk = &plonk_bn254.ProvingKey{}
-	case ecc.BLS12_377:
-		pk = &plonk_bls12377.ProvingKey{}
-	case ecc.BLS12_381:
-		pk = &plonk_bls12381.ProvingKey{}
-	case ecc.BW6_761:
-		pk = &plonk_bw6761.ProvingKey{}
-	case ecc.BLS24_315:
-		pk = &plonk_bls24315.ProvingKey{}
-	case ecc.BW6_633:
-		pk = &plonk_bw6633.ProvingKey{}
-	default:
-		panic("not implemented")
-	}
-
-	return pk
-}
-
 // NewProof instantiates a curve-typed ProvingKey and returns an interface
 // This function exists for serialization purposes
 func NewProof(curveID ecc.ID) Proof {
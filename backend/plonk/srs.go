@@ -0,0 +1,98 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plonk
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+
+	kzg_bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/fr/kzg"
+	kzg_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr/kzg"
+	kzg_bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/fr/kzg"
+	kzg_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	kzg_bw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/fr/kzg"
+	kzg_bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/fr/kzg"
+)
+
+// SRSSize returns the size of the KZG SRS needed by Setup for ccs.
+func SRSSize(ccs frontend.CompiledConstraintSystem) uint64 {
+	_, _, public := ccs.GetNbVariables()
+	size := ccs.GetNbConstraints() + public
+	// the lookup tables must fit in the domain too
+	if t, ok := ccs.(interface{ GetNbTableEntries() int }); ok && t.GetNbTableEntries() > size {
+		size = t.GetNbTableEntries()
+	}
+	return ecc.NextPowerOfTwo(uint64(size)) + 3
+}
+
+// NewSRS returns a KZG SRS of the given size on curveID, whose toxic waste is
+// derived from seed, or drawn from crypto/rand if seed is nil.
+//
+// /!\ warning /!\: the SRS is unsafe, anyone knowing seed can forge proofs.
+// It is meant for tests and benchmarks, in production the SRS must come from
+// a ceremony.
+func NewSRS(curveID ecc.ID, size uint64, seed []byte) (kzg.SRS, error) {
+	var alpha *big.Int
+	if seed == nil {
+		var err error
+		if alpha, err = rand.Int(rand.Reader, curveID.Info().Fr.Modulus()); err != nil {
+			return nil, err
+		}
+	} else {
+		h := sha256.Sum256(seed)
+		alpha = new(big.Int).SetBytes(h[:])
+		alpha.Mod(alpha, curveID.Info().Fr.Modulus())
+	}
+
+	switch curveID {
+	case ecc.BN254:
+		return kzg_bn254.NewSRS(size, alpha)
+	case ecc.BLS12_381:
+		return kzg_bls12381.NewSRS(size, alpha)
+	case ecc.BLS12_377:
+		return kzg_bls12377.NewSRS(size, alpha)
+	case ecc.BW6_761:
+		return kzg_bw6761.NewSRS(size, alpha)
+	case ecc.BLS24_315:
+		return kzg_bls24315.NewSRS(size, alpha)
+	case ecc.BW6_633:
+		return kzg_bw6633.NewSRS(size, alpha)
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+}
+
+// DummySetup runs Setup with an SRS of the size needed by ccs, whose toxic
+// waste is random (see NewSRS). It is used for benchmarking or test purposes
+// only: unlike groth16.DummySetup, the keys are valid and the proofs verify,
+// but they are insecure as the SRS doesn't come from a ceremony.
+func DummySetup(ccs frontend.CompiledConstraintSystem, opts ...backend.SetupOption) (ProvingKey, VerifyingKey, error) {
+	srs, err := NewSRS(ccs.CurveID(), SRSSize(ccs), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return Setup(ccs, srs, opts...)
+}
@@ -0,0 +1,48 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+package plonk_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDummySetup(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, scs.NewBuilder, &cubicCircuit{})
+	assert.NoError(err)
+	pk, vk, err := plonk.DummySetup(ccs)
+	assert.NoError(err)
+
+	fullWitness, err := frontend.NewWitness(&cubicCircuit{X: 3, Y: 27}, ecc.BN254)
+	assert.NoError(err)
+	publicWitness, err := fullWitness.Public()
+	assert.NoError(err)
+	proof, err := plonk.Prove(ccs, pk, fullWitness)
+	assert.NoError(err)
+	assert.NoError(plonk.Verify(proof, vk, publicWitness))
+}
+
+func TestNewSRSSeed(t *testing.T) {
+	assert := require.New(t)
+
+	encode := func(seed []byte) []byte {
+		srs, err := plonk.NewSRS(ecc.BN254, 16, seed)
+		assert.NoError(err)
+		var buf bytes.Buffer
+		_, err = srs.WriteTo(&buf)
+		assert.NoError(err)
+		return buf.Bytes()
+	}
+	assert.Equal(encode([]byte("seed")), encode([]byte("seed")))
+	assert.NotEqual(encode([]byte("seed")), encode([]byte("other seed")))
+	assert.NotEqual(encode(nil), encode(nil))
+}
@@ -0,0 +1,62 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+package plonk_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscriptHash(t *testing.T) {
+	for _, curveID := range []ecc.ID{ecc.BN254, ecc.BLS12_377} {
+		t.Run(curveID.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			ccs, err := frontend.Compile(curveID, scs.NewBuilder, &cubicCircuit{})
+			assert.NoError(err)
+			srs, err := plonk.NewSRS(curveID, plonk.SRSSize(ccs), []byte("transcript"))
+			assert.NoError(err)
+			fullWitness, err := frontend.NewWitness(&cubicCircuit{X: 3, Y: 27}, curveID)
+			assert.NoError(err)
+			publicWitness, err := fullWitness.Public()
+			assert.NoError(err)
+
+			pk, vk, err := plonk.Setup(ccs, srs, backend.WithTranscriptHash(backend.TranscriptMiMC))
+			assert.NoError(err)
+			proof, err := plonk.Prove(ccs, pk, fullWitness)
+			assert.NoError(err)
+			assert.NoError(plonk.Verify(proof, vk, publicWitness))
+
+			// the transcript hash is serialized with the verifying key
+			var buf bytes.Buffer
+			_, err = vk.WriteTo(&buf)
+			assert.NoError(err)
+			decoded := plonk.NewVerifyingKey(curveID)
+			_, err = decoded.ReadFrom(&buf)
+			assert.NoError(err)
+			assert.NoError(decoded.InitKZG(srs))
+			assert.NoError(plonk.Verify(proof, decoded, publicWitness))
+
+			// the challenges depend on the transcript hash
+			_, sha256Vk, err := plonk.Setup(ccs, srs)
+			assert.NoError(err)
+			assert.Error(plonk.Verify(proof, sha256Vk, publicWitness))
+
+			if curveID == ecc.BN254 {
+				assert.Error(vk.ExportSolidity(io.Discard))
+			}
+		})
+	}
+
+	_, err := backend.NewSetupConfig(backend.WithTranscriptHash(backend.TranscriptHash(42)))
+	require.Error(t, err)
+}
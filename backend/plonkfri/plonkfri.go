@@ -0,0 +1,171 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package plonkfri implements PLONK with FRI commitments, a transparent backend:
+// it needs no trusted setup, and its proofs rely on hash functions only.
+//
+// The polynomials are committed to with Merkle trees (SHA-256) of their
+// evaluations on a domain rho = 8 times larger, and their openings are proven
+// with the FRI low degree test, with 43 queries, for about 128 bits of
+// conjectured security. The proofs are much larger than the ones of the PLONK
+// backend (hundreds of kilobytes), and are NOT zero-knowledge: the polynomials
+// of the witness are not blinded, so a proof leaks information about the secret
+// inputs. Until they are, the backend is experimental: it isn't listed by
+// backend.Implemented, nor run by the assertions of package test.
+//
+// The circuits must be compiled with scs.NewBuilder, and must not use custom
+// gates, lookup tables or commitments (frontend.API.Commit).
+//
+// Built with the verifieronly tag, the package only holds the verifier.
+package plonkfri
+
+import (
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/witness"
+
+	plonkfri_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/plonkfri"
+	plonkfri_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/plonkfri"
+	plonkfri_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/plonkfri"
+	plonkfri_bn254 "github.com/consensys/gnark/internal/backend/bn254/plonkfri"
+	plonkfri_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/plonkfri"
+	plonkfri_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/plonkfri"
+
+	witness_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/witness"
+	witness_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/witness"
+	witness_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/witness"
+	witness_bn254 "github.com/consensys/gnark/internal/backend/bn254/witness"
+	witness_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/witness"
+	witness_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/witness"
+)
+
+// Proof represents a PLONK proof with FRI commitments, generated by plonkfri.Prove
+//
+// it's underlying implementation is curve specific (see gnark/internal/backend)
+type Proof interface {
+	io.WriterTo
+	io.ReaderFrom
+}
+
+// VerifyingKey represents a plonkfri VerifyingKey
+//
+// it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
+type VerifyingKey interface {
+	io.WriterTo
+	io.ReaderFrom
+	NbPublicWitness() int // number of elements expected in the public witness
+
+	// Fingerprint returns the fingerprint of the circuit the key is set up for,
+	// equal to the Fingerprint of its constraint system
+	Fingerprint() [32]byte
+}
+
+// Verify verifies a PLONK proof with FRI commitments, from the proof, preprocessed public data, and public witness.
+func Verify(proof Proof, vk VerifyingKey, publicWitness *witness.Witness) error {
+
+	switch _proof := proof.(type) {
+
+	case *plonkfri_bn254.Proof:
+		w, ok := publicWitness.Vector.(*witness_bn254.Witness)
+		if !ok {
+			return witness.ErrInvalidWitness
+		}
+		return plonkfri_bn254.Verify(_proof, vk.(*plonkfri_bn254.VerifyingKey), *w)
+
+	case *plonkfri_bls12381.Proof:
+		w, ok := publicWitness.Vector.(*witness_bls12381.Witness)
+		if !ok {
+			return witness.ErrInvalidWitness
+		}
+		return plonkfri_bls12381.Verify(_proof, vk.(*plonkfri_bls12381.VerifyingKey), *w)
+
+	case *plonkfri_bls12377.Proof:
+		w, ok := publicWitness.Vector.(*witness_bls12377.Witness)
+		if !ok {
+			return witness.ErrInvalidWitness
+		}
+		return plonkfri_bls12377.Verify(_proof, vk.(*plonkfri_bls12377.VerifyingKey), *w)
+
+	case *plonkfri_bw6761.Proof:
+		w, ok := publicWitness.Vector.(*witness_bw6761.Witness)
+		if !ok {
+			return witness.ErrInvalidWitness
+		}
+		return plonkfri_bw6761.Verify(_proof, vk.(*plonkfri_bw6761.VerifyingKey), *w)
+
+	case *plonkfri_bls24315.Proof:
+		w, ok := publicWitness.Vector.(*witness_bls24315.Witness)
+		if !ok {
+			return witness.ErrInvalidWitness
+		}
+		return plonkfri_bls24315.Verify(_proof, vk.(*plonkfri_bls24315.VerifyingKey), *w)
+
+	case *plonkfri_bw6633.Proof:
+		w, ok := publicWitness.Vector.(*witness_bw6633.Witness)
+		if !ok {
+			return witness.ErrInvalidWitness
+		}
+		return plonkfri_bw6633.Verify(_proof, vk.(*plonkfri_bw6633.VerifyingKey), *w)
+
+	default:
+		panic("unrecognized proof type")
+	}
+}
+
+// NewProof instantiates a curve-typed Proof and returns an interface
+// This function exists for serialization purposes
+func NewProof(curveID ecc.ID) Proof {
+	var proof Proof
+	switch curveID {
+	case ecc.BN254:
+		proof = &plonkfri_bn254.Proof{}
+	case ecc.BLS12_381:
+		proof = &plonkfri_bls12381.Proof{}
+	case ecc.BLS12_377:
+		proof = &plonkfri_bls12377.Proof{}
+	case ecc.BW6_761:
+		proof = &plonkfri_bw6761.Proof{}
+	case ecc.BLS24_315:
+		proof = &plonkfri_bls24315.Proof{}
+	case ecc.BW6_633:
+		proof = &plonkfri_bw6633.Proof{}
+	default:
+		panic("not implemented")
+	}
+	return proof
+}
+
+// NewVerifyingKey instantiates a curve-typed VerifyingKey and returns an interface
+// This function exists for serialization purposes
+func NewVerifyingKey(curveID ecc.ID) VerifyingKey {
+	var vk VerifyingKey
+	switch curveID {
+	case ecc.BN254:
+		vk = &plonkfri_bn254.VerifyingKey{}
+	case ecc.BLS12_381:
+		vk = &plonkfri_bls12381.VerifyingKey{}
+	case ecc.BLS12_377:
+		vk = &plonkfri_bls12377.VerifyingKey{}
+	case ecc.BW6_761:
+		vk = &plonkfri_bw6761.VerifyingKey{}
+	case ecc.BLS24_315:
+		vk = &plonkfri_bls24315.VerifyingKey{}
+	case ecc.BW6_633:
+		vk = &plonkfri_bw6633.VerifyingKey{}
+	default:
+		panic("not implemented")
+	}
+	return vk
+}
@@ -0,0 +1,138 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+package plonkfri_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/plonkfri"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	plonkfri_bn254 "github.com/consensys/gnark/internal/backend/bn254/plonkfri"
+	gnarkio "github.com/consensys/gnark/io"
+	"github.com/stretchr/testify/require"
+)
+
+// polyCircuit checks that y = x³ + x + 5, and that x is not zero
+type polyCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *polyCircuit) Define(api frontend.API) error {
+	x3 := api.Mul(circuit.X, circuit.X, circuit.X)
+	api.AssertIsEqual(circuit.Y, api.Add(x3, circuit.X, 5))
+	api.AssertIsDifferent(circuit.X, 0)
+	return nil
+}
+
+func TestProver(t *testing.T) {
+	for _, curveID := range []ecc.ID{ecc.BN254, ecc.BLS12_377} {
+		t.Run(curveID.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			ccs, err := frontend.Compile(curveID, scs.NewBuilder, &polyCircuit{})
+			assert.NoError(err)
+			pk, vk, err := plonkfri.Setup(ccs)
+			assert.NoError(err)
+
+			prove := func(assignment *polyCircuit) error {
+				fullWitness, err := frontend.NewWitness(assignment, curveID)
+				assert.NoError(err)
+				publicWitness, err := fullWitness.Public()
+				assert.NoError(err)
+				proof, err := plonkfri.Prove(ccs, pk, fullWitness)
+				if err != nil {
+					return err
+				}
+				return plonkfri.Verify(proof, vk, publicWitness)
+			}
+			assert.NoError(prove(&polyCircuit{X: 3, Y: 35}))
+			assert.Error(prove(&polyCircuit{X: 3, Y: 36}))
+			assert.Error(prove(&polyCircuit{X: 0, Y: 5}))
+		})
+	}
+}
+
+func TestProof(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, scs.NewBuilder, &polyCircuit{})
+	assert.NoError(err)
+	pk, vk, err := plonkfri.Setup(ccs)
+	assert.NoError(err)
+	fullWitness, err := frontend.NewWitness(&polyCircuit{X: 3, Y: 35}, ecc.BN254)
+	assert.NoError(err)
+	publicWitness, err := fullWitness.Public()
+	assert.NoError(err)
+	proof, err := plonkfri.Prove(ccs, pk, fullWitness)
+	assert.NoError(err)
+
+	// serialization
+	var buf bytes.Buffer
+	_, err = proof.WriteTo(&buf)
+	assert.NoError(err)
+	decodedProof := plonkfri.NewProof(ecc.BN254)
+	_, err = decodedProof.ReadFrom(&buf)
+	assert.NoError(err)
+	buf.Reset()
+	_, err = vk.WriteTo(&buf)
+	assert.NoError(err)
+	decodedVk := plonkfri.NewVerifyingKey(ecc.BN254)
+	_, err = decodedVk.ReadFrom(&buf)
+	assert.NoError(err)
+	assert.NoError(plonkfri.Verify(decodedProof, decodedVk, publicWitness))
+
+	// keys written before the fingerprint was recorded end before it
+	buf.Reset()
+	_, err = vk.WriteTo(&buf)
+	assert.NoError(err)
+	former := buf.Bytes()[:buf.Len()-32]
+	former[4] = gnarkio.VersionCircuitFingerprint - 1
+	formerVk := plonkfri.NewVerifyingKey(ecc.BN254)
+	_, err = formerVk.ReadFrom(bytes.NewReader(former))
+	assert.NoError(err)
+	assert.Equal([32]byte{}, formerVk.(*plonkfri_bn254.VerifyingKey).CircuitFingerprint)
+	assert.NoError(plonkfri.Verify(decodedProof, formerVk, publicWitness))
+
+	// tampered proofs are rejected
+	tamper := func(f func(p *plonkfri_bn254.Proof)) {
+		var buf bytes.Buffer
+		_, err := proof.WriteTo(&buf)
+		assert.NoError(err)
+		var p plonkfri_bn254.Proof
+		_, err = p.ReadFrom(&buf)
+		assert.NoError(err)
+		f(&p)
+		assert.Error(plonkfri.Verify(&p, vk, publicWitness))
+	}
+	tamper(func(p *plonkfri_bn254.Proof) { p.Evaluations[0].SetUint64(42) })
+	tamper(func(p *plonkfri_bn254.Proof) { p.Queries[0].Polynomials[1].Values[0].SetUint64(42) })
+	tamper(func(p *plonkfri_bn254.Proof) { p.Queries[3].Layers[0].Values[1].SetUint64(42) })
+	tamper(func(p *plonkfri_bn254.Proof) { p.FinalValue.SetUint64(42) })
+
+	// the public inputs are bound to the proof
+	wrongPublic, err := frontend.NewWitness(&polyCircuit{Y: 36}, ecc.BN254, frontend.PublicOnly())
+	assert.NoError(err)
+	assert.Error(plonkfri.Verify(proof, vk, wrongPublic))
+}
+
+type commitCircuit struct {
+	X frontend.Variable
+}
+
+func (circuit *commitCircuit) Define(api frontend.API) error {
+	c := api.Commit(circuit.X)
+	api.AssertIsDifferent(c, circuit.X)
+	return nil
+}
+
+func TestUnsupportedCircuit(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254, scs.NewBuilder, &commitCircuit{})
+	require.NoError(t, err)
+	_, _, err = plonkfri.Setup(ccs)
+	require.Error(t, err)
+}
@@ -0,0 +1,132 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plonkfri
+
+import (
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+
+	cs_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/cs"
+	cs_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/cs"
+	cs_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/cs"
+	cs_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
+	cs_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/cs"
+	cs_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/cs"
+
+	plonkfri_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/plonkfri"
+	plonkfri_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/plonkfri"
+	plonkfri_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/plonkfri"
+	plonkfri_bn254 "github.com/consensys/gnark/internal/backend/bn254/plonkfri"
+	plonkfri_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/plonkfri"
+	plonkfri_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/plonkfri"
+
+	witness_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/witness"
+	witness_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/witness"
+	witness_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/witness"
+	witness_bn254 "github.com/consensys/gnark/internal/backend/bn254/witness"
+	witness_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/witness"
+	witness_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/witness"
+)
+
+// ProvingKey represents a plonkfri ProvingKey
+//
+// it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
+//
+// It is not serialized: Setup is transparent and deterministic, and computes it
+// again from the circuit.
+type ProvingKey interface {
+	VerifyingKey() interface{}
+}
+
+// Setup prepares the public data associated to a circuit. It needs no trusted setup.
+func Setup(ccs frontend.CompiledConstraintSystem) (ProvingKey, VerifyingKey, error) {
+
+	switch tccs := ccs.(type) {
+	case *cs_bn254.SparseR1CS:
+		return plonkfri_bn254.Setup(tccs)
+	case *cs_bls12381.SparseR1CS:
+		return plonkfri_bls12381.Setup(tccs)
+	case *cs_bls12377.SparseR1CS:
+		return plonkfri_bls12377.Setup(tccs)
+	case *cs_bw6761.SparseR1CS:
+		return plonkfri_bw6761.Setup(tccs)
+	case *cs_bls24315.SparseR1CS:
+		return plonkfri_bls24315.Setup(tccs)
+	case *cs_bw6633.SparseR1CS:
+		return plonkfri_bw6633.Setup(tccs)
+	default:
+		panic("unrecognized SparseR1CS curve type")
+	}
+}
+
+// Prove generates a PLONK proof with FRI commitments from a circuit, associated preprocessed public data, and the witness
+// if the force flag is set:
+//
+//		will executes all the prover computations, even if the witness is invalid
+//	 will produce an invalid proof
+//		internally, the solution vector to the SparseR1CS will be filled with random values which may impact benchmarking
+func Prove(ccs frontend.CompiledConstraintSystem, pk ProvingKey, fullWitness *witness.Witness, opts ...backend.ProverOption) (Proof, error) {
+
+	// apply options
+	opt, err := backend.NewProverConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	switch tccs := ccs.(type) {
+	case *cs_bn254.SparseR1CS:
+		w, ok := fullWitness.Vector.(*witness_bn254.Witness)
+		if !ok {
+			return nil, witness.ErrInvalidWitness
+		}
+		return plonkfri_bn254.Prove(tccs, pk.(*plonkfri_bn254.ProvingKey), *w, opt)
+	case *cs_bls12381.SparseR1CS:
+		w, ok := fullWitness.Vector.(*witness_bls12381.Witness)
+		if !ok {
+			return nil, witness.ErrInvalidWitness
+		}
+		return plonkfri_bls12381.Prove(tccs, pk.(*plonkfri_bls12381.ProvingKey), *w, opt)
+	case *cs_bls12377.SparseR1CS:
+		w, ok := fullWitness.Vector.(*witness_bls12377.Witness)
+		if !ok {
+			return nil, witness.ErrInvalidWitness
+		}
+		return plonkfri_bls12377.Prove(tccs, pk.(*plonkfri_bls12377.ProvingKey), *w, opt)
+	case *cs_bw6761.SparseR1CS:
+		w, ok := fullWitness.Vector.(*witness_bw6761.Witness)
+		if !ok {
+			return nil, witness.ErrInvalidWitness
+		}
+		return plonkfri_bw6761.Prove(tccs, pk.(*plonkfri_bw6761.ProvingKey), *w, opt)
+	case *cs_bls24315.SparseR1CS:
+		w, ok := fullWitness.Vector.(*witness_bls24315.Witness)
+		if !ok {
+			return nil, witness.ErrInvalidWitness
+		}
+		return plonkfri_bls24315.Prove(tccs, pk.(*plonkfri_bls24315.ProvingKey), *w, opt)
+	case *cs_bw6633.SparseR1CS:
+		w, ok := fullWitness.Vector.(*witness_bw6633.Witness)
+		if !ok {
+			return nil, witness.ErrInvalidWitness
+		}
+		return plonkfri_bw6633.Prove(tccs, pk.(*plonkfri_bw6633.ProvingKey), *w, opt)
+	default:
+		panic("unrecognized SparseR1CS curve type")
+	}
+}
@@ -0,0 +1,238 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/consensys/gnark/backend/accelerator"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/internal/backend/prover"
+	"github.com/consensys/gnark/logger"
+	"github.com/rs/zerolog"
+)
+
+// ProverOption defines option for altering the behaviour of the prover in
+// Prove, ReadAndProve and IsSolved methods. See the descriptions of functions
+// returning instances of this type for implemented options.
+type ProverOption = prover.Option
+
+// ProverConfig is the configuration for the prover with the options applied.
+type ProverConfig = prover.Config
+
+// MSMConfig sets in how many tasks the prover splits its multi-scalar
+// multiplications (MSMs), see WithMSMConfig.
+type MSMConfig = prover.MSMConfig
+
+// ProverPhase is a phase of the prover, see WithProgress.
+type ProverPhase = prover.Phase
+
+const (
+	PhaseSolve = prover.PhaseSolve // the constraint system solver
+	PhaseFFT   = prover.PhaseFFT   // the FFTs of the polynomials
+	PhaseMSM   = prover.PhaseMSM   // the multi-scalar multiplications (commitments, proof elements)
+)
+
+// BufferPool holds the scratch buffers of the prover between calls to Prove,
+// see WithProverBufferPool.
+type BufferPool = prover.BufferPool
+
+// NewBufferPool returns an empty BufferPool holding at most budget bytes of
+// buffers. If budget is 0, the pool is not bounded.
+func NewBufferPool(budget int) *BufferPool {
+	return prover.NewBufferPool(budget)
+}
+
+// NewProverConfig returns a default ProverConfig with given prover options opts
+// applied.
+func NewProverConfig(opts ...ProverOption) (ProverConfig, error) {
+	return prover.NewConfig(opts...)
+}
+
+// IgnoreSolverError is a prover option that indicates that the Prove algorithm
+// should complete even if constraint system is not solved. In that case, Prove
+// will output an invalid Proof, but will execute all algorithms which is useful
+// for test and benchmarking purposes.
+func IgnoreSolverError() ProverOption {
+	return func(opt *ProverConfig) error {
+		opt.Force = true
+		return nil
+	}
+}
+
+// WithHints is a prover option that specifies additional hint functions to be used
+// by the constraint solver.
+func WithHints(hintFunctions ...hint.Function) ProverOption {
+	log := logger.Logger()
+	return func(opt *ProverConfig) error {
+		// it is an error to register hint function several times, but as the
+		// prover already checks it then omit here.
+		for _, h := range hintFunctions {
+			uuid := hint.UUID(h)
+			if _, ok := opt.HintFunctions[uuid]; ok {
+				log.Warn().Int("hintID", int(uuid)).Str("name", hint.Name(h)).Msg("duplicate hint function")
+			} else {
+				opt.HintFunctions[uuid] = h
+			}
+		}
+		return nil
+	}
+}
+
+// WithCircuitLogger is a prover option that specifies zerolog.Logger as a destination for the
+// logs printed by api.Println(). By default, uses gnark/logger.
+// zerolog.Nop() will disable logging
+func WithCircuitLogger(l zerolog.Logger) ProverOption {
+	return func(opt *ProverConfig) error {
+		opt.CircuitLogger = l
+		return nil
+	}
+}
+
+// WithAccelerator is a prover option that delegates the multi-scalar multiplications and
+// FFTs of the prover to a (see package accelerator), for example to run them on a GPU.
+func WithAccelerator(a accelerator.Accelerator) ProverOption {
+	return func(opt *ProverConfig) error {
+		opt.Accelerator = a
+		return nil
+	}
+}
+
+// WithSolverTrace is a prover option that writes to w a trace of the solver:
+// every constraint it evaluates, with the values of its linear expressions,
+// and every hint it calls, with its inputs and outputs. The constraints are
+// then solved sequentially, in the order of their dependencies, which slows
+// the solver down.
+//
+// If regions are given, only the constraints counted by the counters (see
+// frontend.Compiler.AddCounter) whose first tag has one of these names are
+// traced, for example
+//
+//	from := api.Compiler().Tag("gadget")
+//	gadget(api, ...)
+//	api.Compiler().AddCounter(from, api.Compiler().Tag("end"))
+//
+// and
+//
+//	groth16.Prove(ccs, pk, witness, backend.WithSolverTrace(os.Stderr, "gadget"))
+func WithSolverTrace(w io.Writer, regions ...string) ProverOption {
+	return func(opt *ProverConfig) error {
+		opt.SolverTrace = w
+		opt.SolverTraceRegions = regions
+		return nil
+	}
+}
+
+// WithContext is a prover option that cancels the prover once ctx is done:
+// Prove then returns ctx.Err(). The prover checks ctx between its steps (the
+// levels of the solver, the FFTs and the MSMs), so it may take a step to stop.
+func WithContext(ctx context.Context) ProverOption {
+	return func(opt *ProverConfig) error {
+		opt.Context = ctx
+		return nil
+	}
+}
+
+// WithProgress is a prover option that calls f as the prover moves on, with
+// its current phase and the percentage of this phase done, for example
+//
+//	backend.WithProgress(func(phase backend.ProverPhase, percent int) {
+//		log.Printf("%s: %d%%", phase, percent)
+//	})
+//
+// The phases may interleave: the PLONK prover computes FFTs between its MSMs.
+// f is called once per percentage and call to the prover, never concurrently
+// within a call, and must return quickly as it blocks the prover. A batch of
+// proofs (groth16.ProveBatch) is one call: the percentages are those of the
+// whole batch, not of its proofs.
+func WithProgress(f func(phase ProverPhase, percent int)) ProverOption {
+	return func(opt *ProverConfig) error {
+		var lock sync.Mutex
+		lastPhase, lastPercent := ProverPhase(0), -1
+		opt.Progress = func(phase ProverPhase, percent int) {
+			lock.Lock()
+			defer lock.Unlock()
+			if phase == lastPhase && percent == lastPercent {
+				return
+			}
+			lastPhase, lastPercent = phase, percent
+			f(phase, percent)
+		}
+		return nil
+	}
+}
+
+// WithProverBufferPool is a prover option that takes the large scratch buffers
+// of the prover (the a, b and c vectors, h and the filtered wire values of the
+// groth16 prover) from pool, and gives them back to pool once the proof is
+// computed, so that the next call to Prove with the same pool reuses them
+// instead of reallocating them. The PLONK prover doesn't use pool.
+func WithProverBufferPool(pool *BufferPool) ProverOption {
+	return func(opt *ProverConfig) error {
+		opt.BufferPool = pool
+		return nil
+	}
+}
+
+// WithMSMConfig is a prover option that sets in how many tasks the prover
+// splits its multi-scalar multiplications, in place of the default split from
+// the number of CPUs (see MSMConfig). The best split depends on the machine and
+// on the size of the circuit, and is best found by benchmarking the prover, for
+// example
+//
+//	backend.WithMSMConfig(backend.MSMConfig{NbTasks: 64, NbTasksG1: 48})
+//
+// on a machine with 64 cores. The MSMs of an accelerator (see WithAccelerator)
+// get the number of tasks in their ecc.MultiExpConfig, which it may ignore.
+func WithMSMConfig(config MSMConfig) ProverOption {
+	return func(opt *ProverConfig) error {
+		if config.NbTasks < 0 || config.NbTasksG1 < 0 || config.NbTasksG2 < 0 {
+			return errors.New("invalid MSM config: negative number of tasks")
+		}
+		opt.MSM = config
+		return nil
+	}
+}
+
+// WithRandomSource is a prover option that reads the randomness of the prover
+// (the r and s of groth16, the blinding polynomials of PLONK) from rng instead
+// of crypto/rand.Reader, for example to draw it from a hardware module. The
+// zero-knowledge of the proof relies on rng being uniform and secret.
+func WithRandomSource(rng io.Reader) ProverOption {
+	return func(opt *ProverConfig) error {
+		opt.RandomSource = rng
+		return nil
+	}
+}
+
+// WithDeterministicRandomness is a prover option that derives the randomness
+// of the prover from key and the witness, with NewDeterministicRandomSource,
+// instead of reading it from a random source. Proving the same witness with
+// the same key then outputs the same proof, which makes the proofs
+// reproducible. As with RFC 6979 nonces, key must be kept secret.
+func WithDeterministicRandomness(key []byte) ProverOption {
+	return func(opt *ProverConfig) error {
+		opt.RandomKey = key
+		return nil
+	}
+}
@@ -0,0 +1,254 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+package backend_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+type cubicCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *cubicCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X, circuit.X), circuit.Y)
+	return nil
+}
+
+// progress records the last percentage reported for each phase
+type progress map[backend.ProverPhase]int
+
+func (p progress) option(t *testing.T) backend.ProverOption {
+	return backend.WithProgress(func(phase backend.ProverPhase, percent int) {
+		if last, ok := p[phase]; ok && percent < last {
+			t.Errorf("%s: %d%% reported after %d%%", phase, percent, last)
+		}
+		p[phase] = percent
+	})
+}
+
+func TestProverProgress(t *testing.T) {
+	assert := require.New(t)
+	fullWitness, err := frontend.NewWitness(&cubicCircuit{X: 3, Y: 27}, ecc.BN254)
+	assert.NoError(err)
+
+	// groth16
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &cubicCircuit{})
+	assert.NoError(err)
+	pk, _, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	p := progress{}
+	_, err = groth16.Prove(ccs, pk, fullWitness, p.option(t))
+	assert.NoError(err)
+	assert.Equal(progress{backend.PhaseSolve: 100, backend.PhaseFFT: 100, backend.PhaseMSM: 100}, p)
+
+	// plonk
+	ccs, err = frontend.Compile(ecc.BN254, scs.NewBuilder, &cubicCircuit{})
+	assert.NoError(err)
+	srs, err := test.NewKZGSRS(ccs)
+	assert.NoError(err)
+	plonkPK, _, err := plonk.Setup(ccs, srs)
+	assert.NoError(err)
+	p = progress{}
+	_, err = plonk.Prove(ccs, plonkPK, fullWitness, p.option(t))
+	assert.NoError(err)
+	assert.Equal(progress{backend.PhaseSolve: 100, backend.PhaseFFT: 100, backend.PhaseMSM: 100}, p)
+}
+
+func TestProverProgressPerCall(t *testing.T) {
+	assert := require.New(t)
+
+	// the calls sharing the option report their progress independently
+	var reported []int
+	option := backend.WithProgress(func(phase backend.ProverPhase, percent int) {
+		reported = append(reported, percent)
+	})
+	opt1, err := backend.NewProverConfig(option)
+	assert.NoError(err)
+	opt2, err := backend.NewProverConfig(option)
+	assert.NoError(err)
+	assert.NoError(opt1.Checkpoint(backend.PhaseSolve, 1, 2))
+	assert.NoError(opt2.Checkpoint(backend.PhaseSolve, 1, 2))
+	assert.NoError(opt1.Checkpoint(backend.PhaseSolve, 1, 2))
+	assert.Equal([]int{50, 50}, reported)
+}
+
+func TestProverCancel(t *testing.T) {
+	assert := require.New(t)
+	fullWitness, err := frontend.NewWitness(&cubicCircuit{X: 3, Y: 27}, ecc.BN254)
+	assert.NoError(err)
+
+	// the prover stops at its first checkpoint, even if it ignores the solver errors
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &cubicCircuit{})
+	assert.NoError(err)
+	pk, _, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	_, err = groth16.Prove(ccs, pk, fullWitness, backend.WithContext(ctx))
+	assert.ErrorIs(err, context.Canceled)
+	_, err = groth16.Prove(ccs, pk, fullWitness, backend.WithContext(ctx), backend.IgnoreSolverError())
+	assert.ErrorIs(err, context.Canceled)
+
+	ccs, err = frontend.Compile(ecc.BN254, scs.NewBuilder, &cubicCircuit{})
+	assert.NoError(err)
+	srs, err := test.NewKZGSRS(ccs)
+	assert.NoError(err)
+	plonkPK, _, err := plonk.Setup(ccs, srs)
+	assert.NoError(err)
+	_, err = plonk.Prove(ccs, plonkPK, fullWitness, backend.WithContext(ctx))
+	assert.ErrorIs(err, context.Canceled)
+	_, err = plonk.Prove(ccs, plonkPK, fullWitness, backend.WithContext(ctx), backend.IgnoreSolverError())
+	assert.ErrorIs(err, context.Canceled)
+}
+
+func TestProverBufferPool(t *testing.T) {
+	assert := require.New(t)
+	fullWitness, err := frontend.NewWitness(&cubicCircuit{X: 3, Y: 27}, ecc.BN254)
+	assert.NoError(err)
+	publicWitness, err := fullWitness.Public()
+	assert.NoError(err)
+
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &cubicCircuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+
+	// the second proof reuses the buffers of the first one
+	pool := backend.NewBufferPool(0)
+	for i := 0; i < 2; i++ {
+		proof, err := groth16.Prove(ccs, pk, fullWitness, backend.WithProverBufferPool(pool))
+		assert.NoError(err)
+		assert.NoError(groth16.Verify(proof, vk, publicWitness))
+		assert.NotZero(pool.Size())
+	}
+
+	// the buffers exceeding the budget are dropped
+	pool = backend.NewBufferPool(1)
+	proof, err := groth16.Prove(ccs, pk, fullWitness, backend.WithProverBufferPool(pool))
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, vk, publicWitness))
+	assert.Zero(pool.Size())
+}
+
+func TestProverDeterministicRandomness(t *testing.T) {
+	assert := require.New(t)
+	fullWitness, err := frontend.NewWitness(&cubicCircuit{X: 3, Y: 27}, ecc.BN254)
+	assert.NoError(err)
+	publicWitness, err := fullWitness.Public()
+	assert.NoError(err)
+
+	// encode returns the binary encoding of p
+	encode := func(p io.WriterTo) []byte {
+		var buf bytes.Buffer
+		_, err := p.WriteTo(&buf)
+		assert.NoError(err)
+		return buf.Bytes()
+	}
+
+	// groth16
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &cubicCircuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	prove := func(opts ...backend.ProverOption) []byte {
+		proof, err := groth16.Prove(ccs, pk, fullWitness, opts...)
+		assert.NoError(err)
+		assert.NoError(groth16.Verify(proof, vk, publicWitness))
+		return encode(proof)
+	}
+	key := []byte("secret key")
+	assert.Equal(prove(backend.WithDeterministicRandomness(key)), prove(backend.WithDeterministicRandomness(key)))
+	assert.NotEqual(prove(backend.WithDeterministicRandomness(key)), prove(backend.WithDeterministicRandomness([]byte("other key"))))
+	assert.Equal(
+		prove(backend.WithRandomSource(backend.NewDeterministicRandomSource(key, nil))),
+		prove(backend.WithRandomSource(backend.NewDeterministicRandomSource(key, nil))))
+	// the key and the data don't run into each other
+	assert.NotEqual(
+		prove(backend.WithRandomSource(backend.NewDeterministicRandomSource([]byte("ab"), []byte("c")))),
+		prove(backend.WithRandomSource(backend.NewDeterministicRandomSource([]byte("a"), []byte("bc")))))
+	assert.NotEqual(prove(), prove())
+
+	// plonk
+	ccs, err = frontend.Compile(ecc.BN254, scs.NewBuilder, &cubicCircuit{})
+	assert.NoError(err)
+	srs, err := test.NewKZGSRS(ccs)
+	assert.NoError(err)
+	plonkPK, plonkVK, err := plonk.Setup(ccs, srs)
+	assert.NoError(err)
+	prove = func(opts ...backend.ProverOption) []byte {
+		proof, err := plonk.Prove(ccs, plonkPK, fullWitness, opts...)
+		assert.NoError(err)
+		assert.NoError(plonk.Verify(proof, plonkVK, publicWitness))
+		return encode(proof)
+	}
+	assert.Equal(prove(backend.WithDeterministicRandomness(key)), prove(backend.WithDeterministicRandomness(key)))
+	assert.NotEqual(prove(backend.WithDeterministicRandomness(key)), prove(backend.WithDeterministicRandomness([]byte("other key"))))
+	assert.NotEqual(prove(), prove())
+}
+
+func TestProverMSMConfig(t *testing.T) {
+	assert := require.New(t)
+	fullWitness, err := frontend.NewWitness(&cubicCircuit{X: 3, Y: 27}, ecc.BN254)
+	assert.NoError(err)
+	publicWitness, err := fullWitness.Public()
+	assert.NoError(err)
+
+	// the zero fields are set from the others
+	opt, err := backend.NewProverConfig(backend.WithMSMConfig(backend.MSMConfig{NbTasks: 64, NbTasksG1: 48}))
+	assert.NoError(err)
+	assert.Equal(backend.MSMConfig{NbTasks: 64, NbTasksG1: 48, NbTasksG2: 64}, opt.MSMTasks())
+	opt, err = backend.NewProverConfig(backend.WithMSMConfig(backend.MSMConfig{NbTasks: 4}))
+	assert.NoError(err)
+	assert.Equal(backend.MSMConfig{NbTasks: 4, NbTasksG1: 2, NbTasksG2: 8}, opt.MSMTasks())
+	_, err = backend.NewProverConfig(backend.WithMSMConfig(backend.MSMConfig{NbTasksG2: -1}))
+	assert.Error(err)
+
+	// the split of the MSMs doesn't change the proof
+	key := backend.WithDeterministicRandomness([]byte("secret key"))
+	configs := []backend.MSMConfig{{NbTasks: 1}, {NbTasks: 3, NbTasksG1: 5, NbTasksG2: 1}, {NbTasks: 128}}
+
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &cubicCircuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	expected, err := groth16.Prove(ccs, pk, fullWitness, key)
+	assert.NoError(err)
+	for _, config := range configs {
+		proof, err := groth16.Prove(ccs, pk, fullWitness, key, backend.WithMSMConfig(config))
+		assert.NoError(err)
+		assert.NoError(groth16.Verify(proof, vk, publicWitness))
+		assert.Equal(expected, proof)
+	}
+
+	ccs, err = frontend.Compile(ecc.BN254, scs.NewBuilder, &cubicCircuit{})
+	assert.NoError(err)
+	srs, err := test.NewKZGSRS(ccs)
+	assert.NoError(err)
+	plonkPK, plonkVK, err := plonk.Setup(ccs, srs)
+	assert.NoError(err)
+	plonkExpected, err := plonk.Prove(ccs, plonkPK, fullWitness, key)
+	assert.NoError(err)
+	for _, config := range configs {
+		proof, err := plonk.Prove(ccs, plonkPK, fullWitness, key, backend.WithMSMConfig(config))
+		assert.NoError(err)
+		assert.NoError(plonk.Verify(proof, plonkVK, publicWitness))
+		assert.Equal(plonkExpected, proof)
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"io"
+)
+
+// NewDeterministicRandomSource returns an HMAC-DRBG with SHA-256, as used in
+// RFC 6979 (section 3.2) to derive the nonces of deterministic signatures,
+// seeded with key and data. It returns the same stream of bytes for the same
+// key and data. Unlike RFC 6979, key and data are prefixed with their length,
+// so that ("ab", "c") and ("a", "bc") seed different streams.
+//
+// The provers derive it from the key given to WithDeterministicRandomness and
+// the witness.
+func NewDeterministicRandomSource(key, data []byte) io.Reader {
+	d := &drbg{
+		k: make([]byte, sha256.Size),
+		v: make([]byte, sha256.Size),
+	}
+	for i := range d.v {
+		d.v[i] = 0x01
+	}
+	d.update(key, data)
+	return d
+}
+
+// drbg is an HMAC-DRBG with SHA-256
+type drbg struct {
+	k, v []byte
+}
+
+func (d *drbg) mac() hash.Hash {
+	return hmac.New(sha256.New, d.k)
+}
+
+// update mixes the seed into the state (steps d to g of RFC 6979 section 3.2),
+// each part of the seed prefixed with its length on 8 bytes
+func (d *drbg) update(seed ...[]byte) {
+	var size [8]byte
+	for _, b := range []byte{0x00, 0x01} {
+		h := d.mac()
+		h.Write(d.v)
+		h.Write([]byte{b})
+		for _, s := range seed {
+			binary.BigEndian.PutUint64(size[:], uint64(len(s)))
+			h.Write(size[:])
+			h.Write(s)
+		}
+		d.k = h.Sum(d.k[:0])
+
+		h = d.mac()
+		h.Write(d.v)
+		d.v = h.Sum(d.v[:0])
+	}
+}
+
+func (d *drbg) Read(p []byte) (int, error) {
+	for n := 0; n < len(p); {
+		h := d.mac()
+		h.Write(d.v)
+		d.v = h.Sum(d.v[:0])
+		n += copy(p[n:], d.v)
+	}
+	// K = HMAC_K(V || 0x00), V = HMAC_K(V) after each request (step h.3)
+	h := d.mac()
+	h.Write(d.v)
+	h.Write([]byte{0x00})
+	d.k = h.Sum(d.k[:0])
+	h = d.mac()
+	h.Write(d.v)
+	d.v = h.Sum(d.v[:0])
+	return len(p), nil
+}
@@ -0,0 +1,54 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import "fmt"
+
+// SetupOption defines option for altering the behaviour of the PLONK Setup.
+// See the descriptions of functions returning instances of this type for
+// implemented options.
+type SetupOption func(*SetupConfig) error
+
+// SetupConfig is the configuration for the setup with the options applied.
+type SetupConfig struct {
+	TranscriptHash TranscriptHash // defaults to TranscriptSHA256
+}
+
+// NewSetupConfig returns a default SetupConfig with given setup options opts
+// applied.
+func NewSetupConfig(opts ...SetupOption) (SetupConfig, error) {
+	var opt SetupConfig
+	for _, option := range opts {
+		if err := option(&opt); err != nil {
+			return SetupConfig{}, err
+		}
+	}
+	return opt, nil
+}
+
+// WithTranscriptHash is a setup option that selects the hash function deriving
+// the Fiat-Shamir challenges of the proofs. It is recorded in the verifying key,
+// so that the prover and the verifier use the same.
+func WithTranscriptHash(h TranscriptHash) SetupOption {
+	return func(opt *SetupConfig) error {
+		if h != TranscriptSHA256 && h != TranscriptMiMC {
+			return fmt.Errorf("unknown transcript hash %d", h)
+		}
+		opt.TranscriptHash = h
+		return nil
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package solidity provides the options of the Solidity verifiers exported by
+// the backends (see groth16.VerifyingKey.ExportSolidity and
+// plonk.VerifyingKey.ExportSolidity).
+package solidity
+
+import (
+	"errors"
+	"strings"
+)
+
+// ExportOption defines option for altering the behavior of the Solidity
+// verifier export. See the descriptions of functions returning instances of
+// this type for particular options.
+type ExportOption func(*ExportConfig) error
+
+// ExportConfig is the configuration of the Solidity verifier export. Its
+// fields should not be set directly, but through the ExportOption functions.
+type ExportConfig struct {
+	// PragmaVersion is the version constraint of the pragma solidity
+	// directive of the contract.
+	PragmaVersion string
+
+	// CompressedProofs adds to the contract a verification function taking
+	// the proof with compressed points.
+	CompressedProofs bool
+}
+
+// NewExportConfig returns a default ExportConfig with given export options
+// opts applied.
+func NewExportConfig(opts ...ExportOption) (ExportConfig, error) {
+	config := ExportConfig{
+		PragmaVersion: "^0.8.0",
+	}
+	for _, option := range opts {
+		if err := option(&config); err != nil {
+			return ExportConfig{}, err
+		}
+	}
+	return config, nil
+}
+
+// WithPragmaVersion sets the version constraint of the pragma solidity
+// directive, for example "0.8.17" or ">=0.8.0 <0.9.0". Defaults to "^0.8.0".
+// The contract requires a 0.8 compiler.
+func WithPragmaVersion(version string) ExportOption {
+	return func(config *ExportConfig) error {
+		version = strings.TrimSpace(version)
+		if version == "" || strings.ContainsAny(version, ";\n") {
+			return errors.New("invalid pragma version")
+		}
+		config.PragmaVersion = version
+		return nil
+	}
+}
+
+// WithCompressedProofs adds to the contract a function verifying proofs whose
+// points are compressed, as serialized by the WriteTo method of the proof
+// after its header (see gnark/io.HeaderSize). The points are decompressed in the contract, which costs more gas but halves the
+// size of the calldata. Only supported by the Groth16 verifier.
+func WithCompressedProofs() ExportOption {
+	return func(config *ExportConfig) error {
+		config.CompressedProofs = true
+		return nil
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2022 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+// TranscriptHash identifies the hash function deriving the Fiat-Shamir
+// challenges of the PLONK prover and verifier. It is selected in setup (see
+// WithTranscriptHash) and recorded in the verifying key.
+type TranscriptHash uint8
+
+const (
+	// TranscriptSHA256 is SHA-256, the default. It is the cheapest natively and
+	// the one the solidity verifier supports.
+	TranscriptSHA256 TranscriptHash = iota
+
+	// TranscriptMiMC is MiMC over the scalar field of the curve, as computed by
+	// the MiMC hash of gnark-crypto and by std/hash/mimc in a circuit. It makes
+	// the proofs cheap to verify in another circuit.
+	TranscriptMiMC
+)
+
+// String returns the name of the hash function
+func (h TranscriptHash) String() string {
+	switch h {
+	case TranscriptSHA256:
+		return "sha256"
+	case TranscriptMiMC:
+		return "mimc"
+	default:
+		return "unknown"
+	}
+}
@@ -208,6 +208,35 @@ func (w *Witness) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// WriteJSON writes the witness on writer as a JSON object, whose keys are the
+// names of the variables in the circuit (or their gnark tag, if set) and
+// whose values are the decimal representation of the field elements.
+//
+// The Schema must be set, see frontend.NewSchema. Secret variables are omitted
+// for a public witness.
+func (w *Witness) WriteJSON(writer io.Writer) error {
+	data, err := w.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(data)
+	return err
+}
+
+// ReadJSON reads a witness in the format of WriteJSON from reader, for example
+// an assignment produced by a JavaScript frontend.
+//
+// The Schema and the CurveID must be set. Keys which are not in the Schema
+// are rejected. If a secret variable is missing, the witness is read as a
+// public witness.
+func (w *Witness) ReadJSON(reader io.Reader) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	return w.UnmarshalJSON(data)
+}
+
 func (w *Witness) toAssignment(to interface{}, toLeafType reflect.Type) error {
 	if w.Schema == nil {
 		return errMissingSchema
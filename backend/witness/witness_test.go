@@ -1,7 +1,9 @@
 package witness
 
 import (
+	"bytes"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
@@ -129,6 +131,50 @@ func TestPublic(t *testing.T) {
 	assert.Equal("8000", (*wt)[1].String())
 }
 
+func TestReadWriteJSON(t *testing.T) {
+	assert := require.New(t)
+
+	var assignment circuit
+	assignment.X = new(fr.Element).SetInt64(42)
+	assignment.Y = new(fr.Element).SetInt64(8000)
+	assignment.E = new(fr.Element).SetInt64(1)
+
+	w, err := New(ecc.BN254, nil)
+	assert.NoError(err)
+	w.Schema, err = w.Vector.FromAssignment(&assignment, tVariable, false)
+	assert.NoError(err)
+
+	// the JSON is keyed by the names of the variables, small values are encoded
+	// as numbers
+	var buf bytes.Buffer
+	assert.NoError(w.WriteJSON(&buf))
+	assert.JSONEq(`{"X":42,"Y":8000,"E":1}`, buf.String())
+
+	// a public witness omits the secret variables
+	publicW, err := w.Public()
+	assert.NoError(err)
+	buf.Reset()
+	assert.NoError(publicW.WriteJSON(&buf))
+	assert.JSONEq(`{"X":42,"Y":8000}`, buf.String())
+
+	// read an assignment produced elsewhere
+	read := Witness{CurveID: ecc.BN254, Schema: w.Schema}
+	assert.NoError(read.ReadJSON(strings.NewReader(`{"X":42,"Y":8000,"E":1}`)))
+	assert.Equal(3, read.Vector.Len())
+	wt := read.Vector.(*witness_bn254.Witness)
+	assert.Equal("42", (*wt)[0].String())
+	assert.Equal("8000", (*wt)[1].String())
+	assert.Equal("1", (*wt)[2].String())
+
+	read = Witness{CurveID: ecc.BN254, Schema: w.Schema}
+	assert.NoError(read.ReadJSON(strings.NewReader(`{"X":42,"Y":8000}`)))
+	assert.Equal(2, read.Vector.Len())
+
+	// unknown variable
+	read = Witness{CurveID: ecc.BN254, Schema: w.Schema}
+	assert.Error(read.ReadJSON(strings.NewReader(`{"X":42,"Y":8000,"Z":1}`)))
+}
+
 var tVariable reflect.Type
 
 func init() {
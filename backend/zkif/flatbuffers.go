@@ -0,0 +1,250 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zkif
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// The zkInterface messages are FlatBuffers; the few tables of the schema are
+// encoded and decoded by hand below.
+
+// fbBuilder writes a size-prefixed FlatBuffer. Unlike the builders of the
+// FlatBuffers library, it writes front to back: the objects referenced by a
+// table or a vector are written after it, as offsets must point forward.
+// Positions are relative to the start of the buffer, size prefix included, so
+// that alignments hold in the file.
+type fbBuilder struct {
+	buf []byte
+}
+
+// fbField is a field of a table: a scalar of size 1 or 8, or an offset to the
+// object written by ref. A field of size 0 and without ref is absent.
+type fbField struct {
+	size  int
+	value uint64
+	ref   func() int
+}
+
+func (b *fbBuilder) pad(align int) {
+	for len(b.buf)%align != 0 {
+		b.buf = append(b.buf, 0)
+	}
+}
+
+func (b *fbBuilder) uint32(v uint32) int {
+	b.pad(4)
+	pos := len(b.buf)
+	b.buf = append(b.buf, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(b.buf[pos:], v)
+	return pos
+}
+
+// patch sets the offset at pos to target
+func (b *fbBuilder) patch(pos, target int) {
+	binary.LittleEndian.PutUint32(b.buf[pos:], uint32(target-pos))
+}
+
+// table writes a table with the given fields, in the order of the schema, and
+// returns its position
+func (b *fbBuilder) table(fields ...fbField) int {
+	// the vtable is written before the table: its size, the size of the table
+	// and the offsets of the fields in the table
+	b.pad(2)
+	vt := len(b.buf)
+	b.buf = append(b.buf, make([]byte, 4+2*len(fields))...)
+	t := b.uint32(0)
+	binary.LittleEndian.PutUint32(b.buf[t:], uint32(int32(t-vt)))
+
+	refs := make([]int, len(fields))
+	for i, f := range fields {
+		var pos int
+		switch {
+		case f.ref != nil:
+			pos = b.uint32(0)
+			refs[i] = pos
+		case f.size == 1:
+			pos = len(b.buf)
+			b.buf = append(b.buf, byte(f.value))
+		case f.size == 8:
+			b.pad(8)
+			pos = len(b.buf)
+			b.buf = append(b.buf, make([]byte, 8)...)
+			binary.LittleEndian.PutUint64(b.buf[pos:], f.value)
+		default:
+			continue
+		}
+		binary.LittleEndian.PutUint16(b.buf[vt+4+2*i:], uint16(pos-t))
+	}
+	binary.LittleEndian.PutUint16(b.buf[vt:], uint16(4+2*len(fields)))
+	binary.LittleEndian.PutUint16(b.buf[vt+2:], uint16(len(b.buf)-t))
+
+	for i, f := range fields {
+		if f.ref != nil {
+			b.patch(refs[i], f.ref())
+		}
+	}
+	return t
+}
+
+// uint64s writes a vector of uint64 and returns its position
+func (b *fbBuilder) uint64s(v []uint64) int {
+	// the elements following the length are aligned on 8 bytes
+	b.pad(4)
+	if len(b.buf)%8 == 0 {
+		b.buf = append(b.buf, 0, 0, 0, 0)
+	}
+	pos := b.uint32(uint32(len(v)))
+	for _, x := range v {
+		b.buf = append(b.buf, make([]byte, 8)...)
+		binary.LittleEndian.PutUint64(b.buf[len(b.buf)-8:], x)
+	}
+	return pos
+}
+
+// bytes writes a vector of bytes and returns its position
+func (b *fbBuilder) bytes(v []byte) int {
+	pos := b.uint32(uint32(len(v)))
+	b.buf = append(b.buf, v...)
+	return pos
+}
+
+// tables writes a vector of n tables, written by table, and returns its
+// position
+func (b *fbBuilder) tables(n int, table func(i int) int) int {
+	pos := b.uint32(uint32(n))
+	for i := 0; i < n; i++ {
+		b.uint32(0)
+	}
+	for i := 0; i < n; i++ {
+		b.patch(pos+4+4*i, table(i))
+	}
+	return pos
+}
+
+// finishMessage returns a size-prefixed zkInterface message whose root table
+// holds the union field of type msgType written by msg
+func finishMessage(msgType byte, msg func(b *fbBuilder) int) []byte {
+	// size prefix, offset of the root table and file identifier
+	b := &fbBuilder{buf: make([]byte, 8, 1024)}
+	b.buf = append(b.buf, fileIdentifier...)
+	root := b.table(fbField{size: 1, value: uint64(msgType)}, fbField{ref: func() int { return msg(b) }})
+	b.patch(4, root)
+	b.pad(8)
+	binary.LittleEndian.PutUint32(b.buf, uint32(len(b.buf)-4))
+	return b.buf
+}
+
+var errInvalidBuffer = errors.New("invalid flatbuffer")
+
+// fbTable is a table of a FlatBuffer being read. The accessors panic if buf is
+// malformed; the panics are recovered by Read.
+type fbTable struct {
+	buf []byte
+	pos int // position of the table
+	vt  int // position of its vtable
+}
+
+func newTable(buf []byte, pos int) fbTable {
+	vt := pos - int(int32(binary.LittleEndian.Uint32(buf[pos:])))
+	return fbTable{buf: buf, pos: pos, vt: vt}
+}
+
+// rootTable returns the root table of the FlatBuffer buf (without size prefix)
+func rootTable(buf []byte) fbTable {
+	return newTable(buf, int(binary.LittleEndian.Uint32(buf)))
+}
+
+// field returns the position of the field at slot, 0 if it is absent
+func (t fbTable) field(slot int) int {
+	o := 4 + 2*slot
+	if o >= int(binary.LittleEndian.Uint16(t.buf[t.vt:])) {
+		return 0
+	}
+	off := int(binary.LittleEndian.Uint16(t.buf[t.vt+o:]))
+	if off == 0 {
+		return 0
+	}
+	return t.pos + off
+}
+
+func (t fbTable) uint8(slot int) uint8 {
+	if p := t.field(slot); p != 0 {
+		return t.buf[p]
+	}
+	return 0
+}
+
+func (t fbTable) uint64(slot int) uint64 {
+	if p := t.field(slot); p != 0 {
+		return binary.LittleEndian.Uint64(t.buf[p:])
+	}
+	return 0
+}
+
+// ref returns the position of the object referenced by the field at slot, 0
+// if it is absent
+func (t fbTable) ref(slot int) int {
+	p := t.field(slot)
+	if p == 0 {
+		return 0
+	}
+	return p + int(binary.LittleEndian.Uint32(t.buf[p:]))
+}
+
+func (t fbTable) table(slot int) (fbTable, bool) {
+	p := t.ref(slot)
+	if p == 0 {
+		return fbTable{}, false
+	}
+	return newTable(t.buf, p), true
+}
+
+// vector returns the position of the first element and the length of the
+// vector at slot
+func (t fbTable) vector(slot int) (int, int) {
+	p := t.ref(slot)
+	if p == 0 {
+		return 0, 0
+	}
+	return p + 4, int(binary.LittleEndian.Uint32(t.buf[p:]))
+}
+
+func (t fbTable) uint64s(slot int) []uint64 {
+	p, n := t.vector(slot)
+	res := make([]uint64, n)
+	for i := range res {
+		res[i] = binary.LittleEndian.Uint64(t.buf[p+8*i:])
+	}
+	return res
+}
+
+func (t fbTable) bytes(slot int) []byte {
+	p, n := t.vector(slot)
+	return t.buf[p : p+n]
+}
+
+func (t fbTable) tables(slot int) []fbTable {
+	p, n := t.vector(slot)
+	res := make([]fbTable, n)
+	for i := range res {
+		pos := p + 4*i
+		res[i] = newTable(t.buf, pos+int(binary.LittleEndian.Uint32(t.buf[pos:])))
+	}
+	return res
+}
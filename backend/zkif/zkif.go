@@ -0,0 +1,592 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package zkif exports and imports rank-1 constraint systems in the
+// zkInterface format (https://github.com/QED-it/zkinterface), to interoperate
+// with other provers and tools.
+//
+// A zkInterface file is a sequence of messages: a CircuitHeader, which lists
+// the instance (public) variables, a ConstraintSystem and optionally a
+// Witness, which assigns the other variables. The variable 0 is the constant
+// one.
+//
+// Exported R1CS keep the IDs of the wires of gnark: the public inputs are the
+// instance variables, and the secret inputs and the internal wires are the
+// witness variables. Imported R1CS are compiled as the circuit Circuit: the
+// instance variables are its public inputs and all the other variables its
+// secret inputs, such that the witness of a zkInterface file is a full gnark
+// witness, and the constraint system doesn't need any hint.
+//
+// Only R1CS are supported; in particular, the SIEVE IR, whose gates don't map
+// to rank-1 constraints, is not.
+package zkif
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/compiled"
+	"github.com/consensys/gnark/frontend/cs"
+	"github.com/consensys/gnark/frontend/schema"
+	"github.com/consensys/gnark/internal/backend/prover"
+
+	fr_bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	fr_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	fr_bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+	fr_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	fr_bw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/fr"
+	fr_bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/fr"
+
+	backend_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/cs"
+	backend_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/cs"
+	backend_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/cs"
+	backend_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
+	backend_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/cs"
+	backend_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/cs"
+
+	witness_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/witness"
+	witness_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/witness"
+	witness_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/witness"
+	witness_bn254 "github.com/consensys/gnark/internal/backend/bn254/witness"
+	witness_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/witness"
+	witness_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/witness"
+)
+
+var fileIdentifier = []byte("zkif")
+
+// types of the Message union of the root table
+const (
+	messageCircuitHeader    = 1
+	messageConstraintSystem = 2
+	messageWitness          = 3
+	messageCommand          = 4
+)
+
+// Circuit is the circuit of an imported constraint system, whose public
+// inputs are the instance variables, and secret inputs the other variables,
+// in increasing order of their zkInterface IDs.
+type Circuit struct {
+	Instance []frontend.Variable `gnark:",public"`
+	Witness  []frontend.Variable `gnark:",secret"`
+}
+
+// Define returns an error: the constraints of the circuit are read by Read
+func (c *Circuit) Define(api frontend.API) error {
+	return errors.New("zkif.Circuit is compiled by zkif.Read")
+}
+
+// Write writes the R1CS ccs on w in the zkInterface format. If fullWitness is
+// not nil, ccs is solved, with the backend.ProverOption opts, and the values of
+// the variables are written too.
+func Write(w io.Writer, ccs frontend.CompiledConstraintSystem, fullWitness *witness.Witness, opts ...prover.Option) error {
+	opt, err := prover.NewConfig(opts...)
+	if err != nil {
+		return err
+	}
+
+	// the coefficients and, if there is a witness, the values of the wires
+	var r1cs *compiled.R1CS
+	var coeffs, wires []big.Int
+	switch _r1cs := ccs.(type) {
+	case *backend_bls12377.R1CS:
+		r1cs = &_r1cs.R1CS
+		coeffs = make([]big.Int, len(_r1cs.Coefficients))
+		for i := range coeffs {
+			_r1cs.Coefficients[i].ToBigIntRegular(&coeffs[i])
+		}
+		if fullWitness != nil {
+			v, ok := fullWitness.Vector.(*witness_bls12377.Witness)
+			if !ok {
+				return witness.ErrInvalidWitness
+			}
+			n := len(_r1cs.Constraints)
+			values, err := _r1cs.Solve(*v, make([]fr_bls12377.Element, n), make([]fr_bls12377.Element, n), make([]fr_bls12377.Element, n), opt)
+			if err != nil {
+				return err
+			}
+			wires = make([]big.Int, len(values))
+			for i := range values {
+				values[i].ToBigIntRegular(&wires[i])
+			}
+		}
+	case *backend_bls12381.R1CS:
+		r1cs = &_r1cs.R1CS
+		coeffs = make([]big.Int, len(_r1cs.Coefficients))
+		for i := range coeffs {
+			_r1cs.Coefficients[i].ToBigIntRegular(&coeffs[i])
+		}
+		if fullWitness != nil {
+			v, ok := fullWitness.Vector.(*witness_bls12381.Witness)
+			if !ok {
+				return witness.ErrInvalidWitness
+			}
+			n := len(_r1cs.Constraints)
+			values, err := _r1cs.Solve(*v, make([]fr_bls12381.Element, n), make([]fr_bls12381.Element, n), make([]fr_bls12381.Element, n), opt)
+			if err != nil {
+				return err
+			}
+			wires = make([]big.Int, len(values))
+			for i := range values {
+				values[i].ToBigIntRegular(&wires[i])
+			}
+		}
+	case *backend_bn254.R1CS:
+		r1cs = &_r1cs.R1CS
+		coeffs = make([]big.Int, len(_r1cs.Coefficients))
+		for i := range coeffs {
+			_r1cs.Coefficients[i].ToBigIntRegular(&coeffs[i])
+		}
+		if fullWitness != nil {
+			v, ok := fullWitness.Vector.(*witness_bn254.Witness)
+			if !ok {
+				return witness.ErrInvalidWitness
+			}
+			n := len(_r1cs.Constraints)
+			values, err := _r1cs.Solve(*v, make([]fr_bn254.Element, n), make([]fr_bn254.Element, n), make([]fr_bn254.Element, n), opt)
+			if err != nil {
+				return err
+			}
+			wires = make([]big.Int, len(values))
+			for i := range values {
+				values[i].ToBigIntRegular(&wires[i])
+			}
+		}
+	case *backend_bw6761.R1CS:
+		r1cs = &_r1cs.R1CS
+		coeffs = make([]big.Int, len(_r1cs.Coefficients))
+		for i := range coeffs {
+			_r1cs.Coefficients[i].ToBigIntRegular(&coeffs[i])
+		}
+		if fullWitness != nil {
+			v, ok := fullWitness.Vector.(*witness_bw6761.Witness)
+			if !ok {
+				return witness.ErrInvalidWitness
+			}
+			n := len(_r1cs.Constraints)
+			values, err := _r1cs.Solve(*v, make([]fr_bw6761.Element, n), make([]fr_bw6761.Element, n), make([]fr_bw6761.Element, n), opt)
+			if err != nil {
+				return err
+			}
+			wires = make([]big.Int, len(values))
+			for i := range values {
+				values[i].ToBigIntRegular(&wires[i])
+			}
+		}
+	case *backend_bls24315.R1CS:
+		r1cs = &_r1cs.R1CS
+		coeffs = make([]big.Int, len(_r1cs.Coefficients))
+		for i := range coeffs {
+			_r1cs.Coefficients[i].ToBigIntRegular(&coeffs[i])
+		}
+		if fullWitness != nil {
+			v, ok := fullWitness.Vector.(*witness_bls24315.Witness)
+			if !ok {
+				return witness.ErrInvalidWitness
+			}
+			n := len(_r1cs.Constraints)
+			values, err := _r1cs.Solve(*v, make([]fr_bls24315.Element, n), make([]fr_bls24315.Element, n), make([]fr_bls24315.Element, n), opt)
+			if err != nil {
+				return err
+			}
+			wires = make([]big.Int, len(values))
+			for i := range values {
+				values[i].ToBigIntRegular(&wires[i])
+			}
+		}
+	case *backend_bw6633.R1CS:
+		r1cs = &_r1cs.R1CS
+		coeffs = make([]big.Int, len(_r1cs.Coefficients))
+		for i := range coeffs {
+			_r1cs.Coefficients[i].ToBigIntRegular(&coeffs[i])
+		}
+		if fullWitness != nil {
+			v, ok := fullWitness.Vector.(*witness_bw6633.Witness)
+			if !ok {
+				return witness.ErrInvalidWitness
+			}
+			n := len(_r1cs.Constraints)
+			values, err := _r1cs.Solve(*v, make([]fr_bw6633.Element, n), make([]fr_bw6633.Element, n), make([]fr_bw6633.Element, n), opt)
+			if err != nil {
+				return err
+			}
+			wires = make([]big.Int, len(values))
+			for i := range values {
+				values[i].ToBigIntRegular(&wires[i])
+			}
+		}
+	default:
+		return errors.New("zkif: only R1CS can be exported")
+	}
+
+	enc := newEncoder(ccs.CurveID().Info().Fr.Modulus())
+	nbWires := r1cs.NbPublicVariables + r1cs.NbSecretVariables + r1cs.NbInternalVariables
+
+	// header: the instance variables are the public wires but the constant one
+	instance := variables{}
+	for i := 1; i < r1cs.NbPublicVariables; i++ {
+		instance.ids = append(instance.ids, uint64(i))
+	}
+	if wires != nil {
+		instance.values = wires[1:r1cs.NbPublicVariables]
+	}
+	if _, err := w.Write(enc.header(instance, uint64(nbWires))); err != nil {
+		return err
+	}
+
+	// constraints
+	constraints := make([][3]variables, len(r1cs.Constraints))
+	for i, r1c := range r1cs.Constraints {
+		for j, l := range []compiled.LinearExpression{r1c.L, r1c.R, r1c.O} {
+			lc := &constraints[i][j]
+			lc.ids = make([]uint64, len(l))
+			lc.values = make([]big.Int, len(l))
+			for k, t := range l {
+				lc.ids[k] = uint64(t.WireID())
+				lc.values[k].Set(&coeffs[t.CoeffID()])
+			}
+		}
+	}
+	if _, err := w.Write(enc.constraintSystem(constraints)); err != nil {
+		return err
+	}
+
+	// witness: the secret and internal wires
+	if wires != nil {
+		assigned := variables{values: wires[r1cs.NbPublicVariables:]}
+		for i := r1cs.NbPublicVariables; i < nbWires; i++ {
+			assigned.ids = append(assigned.ids, uint64(i))
+		}
+		if _, err := w.Write(enc.witness(assigned)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Read reads a constraint system in the zkInterface format from r, and
+// compiles it as a R1CS over the scalar field of curveID (see Circuit). If r
+// holds a Witness message and the values of the instance variables, Read also
+// returns the corresponding full witness, nil otherwise.
+func Read(r io.Reader, curveID ecc.ID) (ccs frontend.CompiledConstraintSystem, fullWitness *witness.Witness, err error) {
+	modulus := curveID.Info().Fr.Modulus()
+	var (
+		instance    variables
+		constraints [][3]variables
+		assigned    *variables
+		hasHeader   bool
+	)
+
+	// recover from panics on malformed messages
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("%w: %v", errInvalidBuffer, e)
+		}
+	}()
+
+	var prefix [4]byte
+	for {
+		if _, err := io.ReadFull(r, prefix[:]); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, err
+		}
+		buf := make([]byte, binary.LittleEndian.Uint32(prefix[:]))
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, nil, err
+		}
+
+		root := rootTable(buf)
+		msg, ok := root.table(1)
+		if !ok {
+			return nil, nil, errors.New("zkif: empty message")
+		}
+		switch root.uint8(0) {
+		case messageCircuitHeader:
+			instance = readVariables(msg.table(0))
+			fieldMax := new(big.Int).SetBytes(reverse(msg.bytes(2)))
+			if fieldMax.Add(fieldMax, big.NewInt(1)).Cmp(modulus) != 0 {
+				return nil, nil, fmt.Errorf("zkif: the field doesn't match the scalar field of %s", curveID.String())
+			}
+			hasHeader = true
+		case messageConstraintSystem:
+			if msg.uint8(1) != 0 {
+				return nil, nil, errors.New("zkif: only R1CS constraint systems are supported")
+			}
+			for _, c := range msg.tables(0) {
+				var r1c [3]variables
+				for j := range r1c {
+					r1c[j] = readVariables(c.table(j))
+				}
+				constraints = append(constraints, r1c)
+			}
+		case messageWitness:
+			v := readVariables(msg.table(0))
+			if assigned == nil {
+				assigned = &variables{}
+			}
+			assigned.ids = append(assigned.ids, v.ids...)
+			assigned.values = append(assigned.values, v.values...)
+		case messageCommand:
+		default:
+			return nil, nil, fmt.Errorf("zkif: unknown message type %d", root.uint8(0))
+		}
+	}
+	if !hasHeader {
+		return nil, nil, errors.New("zkif: missing circuit header")
+	}
+
+	// wires: the constant one, the instance variables, then all the others
+	// in increasing order of their IDs
+	wireIDs := map[uint64]int{0: 0}
+	for _, id := range instance.ids {
+		if _, ok := wireIDs[id]; ok {
+			return nil, nil, fmt.Errorf("zkif: instance variable %d listed twice", id)
+		}
+		wireIDs[id] = len(wireIDs)
+	}
+	nbPublic := len(wireIDs)
+	var others []uint64
+	addOther := func(id uint64) {
+		if _, ok := wireIDs[id]; !ok {
+			wireIDs[id] = -1
+			others = append(others, id)
+		}
+	}
+	for _, c := range constraints {
+		for _, lc := range c {
+			for _, id := range lc.ids {
+				addOther(id)
+			}
+		}
+	}
+	if assigned != nil {
+		for _, id := range assigned.ids {
+			addOther(id)
+		}
+	}
+	sort.Slice(others, func(i, j int) bool { return others[i] < others[j] })
+	for i, id := range others {
+		wireIDs[id] = nbPublic + i
+	}
+
+	s, err := frontend.NewSchema(&Circuit{
+		Instance: make([]frontend.Variable, nbPublic-1),
+		Witness:  make([]frontend.Variable, len(others)),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	res := compiled.R1CS{
+		ConstraintSystem: compiled.ConstraintSystem{
+			Schema:             s,
+			NbPublicVariables:  nbPublic,
+			NbSecretVariables:  len(others),
+			Public:             []string{"one"},
+			MDebug:             make(map[int]int),
+			MHints:             make(map[int]*compiled.Hint),
+			MHintsDependencies: make(map[hint.ID]string),
+			CurveID:            curveID,
+		},
+	}
+	for i := 1; i < nbPublic; i++ {
+		res.Public = append(res.Public, fmt.Sprintf("Instance_%d", i-1))
+	}
+	for i := range others {
+		res.Secret = append(res.Secret, fmt.Sprintf("Witness_%d", i))
+	}
+
+	// constraints; as all the wires are inputs, they are only checked by the
+	// solver, in a single level
+	st := cs.NewCoeffTable()
+	res.Constraints = make([]compiled.R1C, len(constraints))
+	level := make([]int, len(constraints))
+	for i, c := range constraints {
+		var les [3]compiled.LinearExpression
+		for j, lc := range c {
+			les[j] = make(compiled.LinearExpression, len(lc.ids))
+			for k, id := range lc.ids {
+				wID := wireIDs[id]
+				visibility := schema.Secret
+				if wID < nbPublic {
+					visibility = schema.Public
+				}
+				coeff := new(big.Int).Mod(&lc.values[k], modulus)
+				les[j][k] = compiled.Pack(wID, st.CoeffID(coeff), visibility)
+			}
+		}
+		res.Constraints[i] = compiled.R1C{L: les[0], R: les[1], O: les[2]}
+		level[i] = i
+	}
+	if len(level) != 0 {
+		res.Levels = [][]int{level}
+	}
+
+	switch curveID {
+	case ecc.BLS12_377:
+		ccs = backend_bls12377.NewR1CS(res, st.Coeffs)
+	case ecc.BLS12_381:
+		ccs = backend_bls12381.NewR1CS(res, st.Coeffs)
+	case ecc.BN254:
+		ccs = backend_bn254.NewR1CS(res, st.Coeffs)
+	case ecc.BW6_761:
+		ccs = backend_bw6761.NewR1CS(res, st.Coeffs)
+	case ecc.BW6_633:
+		ccs = backend_bw6633.NewR1CS(res, st.Coeffs)
+	case ecc.BLS24_315:
+		ccs = backend_bls24315.NewR1CS(res, st.Coeffs)
+	default:
+		return nil, nil, fmt.Errorf("zkif: unsupported curve %s", curveID.String())
+	}
+
+	if assigned == nil || (len(instance.ids) != 0 && instance.values == nil) {
+		return ccs, nil, nil
+	}
+	values := make(map[uint64]*big.Int)
+	for i, id := range instance.ids {
+		values[id] = &instance.values[i]
+	}
+	for i, id := range assigned.ids {
+		values[id] = &assigned.values[i]
+	}
+	assignment := Circuit{
+		Instance: make([]frontend.Variable, len(instance.ids)),
+		Witness:  make([]frontend.Variable, len(others)),
+	}
+	for i, id := range instance.ids {
+		assignment.Instance[i] = values[id]
+	}
+	for i, id := range others {
+		v, ok := values[id]
+		if !ok {
+			return nil, nil, fmt.Errorf("zkif: variable %d is not assigned", id)
+		}
+		assignment.Witness[i] = v
+	}
+	fullWitness, err = frontend.NewWitness(&assignment, curveID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ccs, fullWitness, nil
+}
+
+// variables are the IDs of zkInterface variables and, optionally, their
+// values: the coefficients of a linear combination or an assignment
+type variables struct {
+	ids    []uint64
+	values []big.Int
+}
+
+// readVariables reads the Variables table t, if ok
+func readVariables(t fbTable, ok bool) variables {
+	if !ok {
+		return variables{}
+	}
+	res := variables{ids: t.uint64s(0)}
+	buf := t.bytes(1)
+	if len(buf) == 0 {
+		return res
+	}
+	if len(res.ids) == 0 || len(buf)%len(res.ids) != 0 {
+		panic("the size of the values doesn't match the number of variables")
+	}
+	size := len(buf) / len(res.ids)
+	res.values = make([]big.Int, len(res.ids))
+	for i := range res.values {
+		res.values[i].SetBytes(reverse(buf[i*size : (i+1)*size]))
+	}
+	return res
+}
+
+// encoder writes the messages, with field elements of a fixed size
+type encoder struct {
+	modulus  *big.Int
+	fieldMax []byte
+	size     int
+}
+
+func newEncoder(modulus *big.Int) *encoder {
+	e := &encoder{modulus: modulus, size: (modulus.BitLen() + 7) / 8}
+	e.fieldMax = e.elements([]big.Int{*new(big.Int).Sub(modulus, big.NewInt(1))})
+	return e
+}
+
+// elements returns the little-endian encoding of values
+func (e *encoder) elements(values []big.Int) []byte {
+	res := make([]byte, e.size*len(values))
+	var x big.Int
+	for i := range values {
+		x.Mod(&values[i], e.modulus)
+		copy(res[i*e.size:], reverse(x.FillBytes(make([]byte, e.size))))
+	}
+	return res
+}
+
+func (e *encoder) variables(b *fbBuilder, v variables) int {
+	fields := []fbField{{ref: func() int { return b.uint64s(v.ids) }}}
+	if v.values != nil {
+		fields = append(fields, fbField{ref: func() int { return b.bytes(e.elements(v.values)) }})
+	}
+	return b.table(fields...)
+}
+
+func (e *encoder) header(instance variables, freeVariableID uint64) []byte {
+	return finishMessage(messageCircuitHeader, func(b *fbBuilder) int {
+		return b.table(
+			fbField{ref: func() int { return e.variables(b, instance) }},
+			fbField{size: 8, value: freeVariableID},
+			fbField{ref: func() int { return b.bytes(e.fieldMax) }},
+		)
+	})
+}
+
+func (e *encoder) constraintSystem(constraints [][3]variables) []byte {
+	return finishMessage(messageConstraintSystem, func(b *fbBuilder) int {
+		return b.table(fbField{ref: func() int {
+			return b.tables(len(constraints), func(i int) int {
+				c := constraints[i]
+				return b.table(
+					fbField{ref: func() int { return e.variables(b, c[0]) }},
+					fbField{ref: func() int { return e.variables(b, c[1]) }},
+					fbField{ref: func() int { return e.variables(b, c[2]) }},
+				)
+			})
+		}})
+	})
+}
+
+func (e *encoder) witness(assigned variables) []byte {
+	return finishMessage(messageWitness, func(b *fbBuilder) int {
+		return b.table(fbField{ref: func() int { return e.variables(b, assigned) }})
+	})
+}
+
+// reverse returns a reversed copy of buf, to convert between big-endian and
+// little-endian encodings
+func reverse(buf []byte) []byte {
+	res := make([]byte, len(buf))
+	for i := range buf {
+		res[len(buf)-1-i] = buf[i]
+	}
+	return res
+}
@@ -0,0 +1,86 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zkif_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/zkif"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+type cubicCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *cubicCircuit) Define(api frontend.API) error {
+	x3 := api.Mul(circuit.X, circuit.X, circuit.X)
+	api.AssertIsEqual(circuit.Y, api.Add(x3, circuit.X, 5))
+	return nil
+}
+
+func TestWriteRead(t *testing.T) {
+	assert := require.New(t)
+
+	for _, curveID := range []ecc.ID{ecc.BN254, ecc.BLS12_381} {
+		ccs, err := frontend.Compile(curveID, r1cs.NewBuilder, &cubicCircuit{})
+		assert.NoError(err)
+		witness, err := frontend.NewWitness(&cubicCircuit{X: 3, Y: 35}, curveID)
+		assert.NoError(err)
+
+		var buf bytes.Buffer
+		assert.NoError(zkif.Write(&buf, ccs, witness))
+
+		imported, importedWitness, err := zkif.Read(bytes.NewReader(buf.Bytes()), curveID)
+		assert.NoError(err)
+		assert.Equal(ccs.GetNbConstraints(), imported.GetNbConstraints())
+		assert.NotNil(importedWitness)
+		assert.NoError(imported.IsSolved(importedWitness))
+
+		// the public input is the only instance variable
+		_, nbSecret, nbPublic := imported.GetNbVariables()
+		assert.Equal(2, nbPublic)
+
+		// wrong assignment of the imported circuit
+		bad := zkif.Circuit{Instance: []frontend.Variable{35}, Witness: make([]frontend.Variable, nbSecret)}
+		for i := range bad.Witness {
+			bad.Witness[i] = 1
+		}
+		badWitness, err := frontend.NewWitness(&bad, curveID)
+		assert.NoError(err)
+		assert.Error(imported.IsSolved(badWitness))
+
+		// without witness, only the constraint system is written
+		buf.Reset()
+		assert.NoError(zkif.Write(&buf, ccs, nil))
+		imported, importedWitness, err = zkif.Read(&buf, curveID)
+		assert.NoError(err)
+		assert.Equal(ccs.GetNbConstraints(), imported.GetNbConstraints())
+		assert.Nil(importedWitness)
+
+		// the field of the file must be the scalar field of the curve
+		buf.Reset()
+		assert.NoError(zkif.Write(&buf, ccs, nil))
+		_, _, err = zkif.Read(&buf, ecc.BW6_761)
+		assert.Error(err)
+	}
+}
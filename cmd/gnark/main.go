@@ -0,0 +1,396 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// gnark compiles circuits, runs their setup, proves and verifies from the
+// command line:
+//
+//	gnark compile -plugin cubic.so -circuit Circuit -o cubic.ccs
+//	gnark setup -ccs cubic.ccs -pk cubic.pk -vk cubic.vk
+//	gnark prove -ccs cubic.ccs -pk cubic.pk -witness witness.json -o cubic.proof
+//	gnark verify -ccs cubic.ccs -vk cubic.vk -witness public.json -proof cubic.proof
+//
+// Every command takes -curve (bn254 by default) and -backend (groth16 by
+// default). The keys, proofs and constraint systems are in their binary
+// encoding (WriteTo). The witnesses are JSON objects mapping the paths of the
+// variables to their values (see frontend.NewWitnessFromMap); verify takes
+// only the public variables.
+//
+// The circuit given to compile is a Go plugin (go build -buildmode=plugin),
+// exporting a variable of the type of the circuit, for example:
+//
+//	package main
+//
+//	import "github.com/consensys/gnark/examples/cubic"
+//
+//	var Circuit cubic.Circuit
+//
+// PLONK circuits need a KZG SRS (-srs) to run the setup, prove and verify.
+// -insecure-srs generates one from a known secret instead, for tests only.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"plugin"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/gnarkd/server"
+	"github.com/consensys/gnark/test"
+)
+
+const usage = `usage: gnark <command> [flags]
+
+commands:
+  compile  compile a circuit loaded from a Go plugin
+  setup    compute the proving and verifying keys of a compiled circuit
+  prove    prove a JSON witness
+  verify   verify a proof against a JSON public witness
+
+run gnark <command> -h for the flags of a command`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	commands := map[string]func([]string) error{
+		"compile": compile,
+		"setup":   setup,
+		"prove":   prove,
+		"verify":  verify,
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err := cmd(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "gnark %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+// circuitName is the name of the circuit in the server running the commands
+const circuitName = "circuit"
+
+// flags are the flags common to all the commands
+type flags struct {
+	*flag.FlagSet
+	curve, backend string
+	ccs            string
+	srs            string
+	insecureSRS    bool
+}
+
+func newFlags(name string) *flags {
+	f := &flags{FlagSet: flag.NewFlagSet("gnark "+name, flag.ExitOnError)}
+	f.StringVar(&f.curve, "curve", "bn254", "elliptic curve ("+curves()+")")
+	f.StringVar(&f.backend, "backend", "groth16", "proof system (groth16 or plonk)")
+	if name != "compile" {
+		f.StringVar(&f.ccs, "ccs", "", "compiled circuit")
+		f.StringVar(&f.srs, "srs", "", "KZG SRS (plonk)")
+		f.BoolVar(&f.insecureSRS, "insecure-srs", false, "generate the KZG SRS from a known secret, for tests only (plonk)")
+	}
+	return f
+}
+
+func (f *flags) ids() (ecc.ID, backend.ID, error) {
+	curveID, err := parseCurve(f.curve)
+	if err != nil {
+		return ecc.UNKNOWN, backend.UNKNOWN, err
+	}
+	switch strings.ToLower(f.backend) {
+	case "groth16":
+		return curveID, backend.GROTH16, nil
+	case "plonk":
+		return curveID, backend.PLONK, nil
+	default:
+		return curveID, backend.UNKNOWN, fmt.Errorf("unknown backend %q", f.backend)
+	}
+}
+
+// load reads the compiled circuit, and the KZG SRS of PLONK circuits, into a
+// server
+func (f *flags) load() (*server.Server, error) {
+	curveID, backendID, err := f.ids()
+	if err != nil {
+		return nil, err
+	}
+	if f.ccs == "" {
+		return nil, errors.New("missing -ccs")
+	}
+	var ccs frontend.CompiledConstraintSystem
+	if backendID == backend.GROTH16 {
+		ccs = groth16.NewCS(curveID)
+	} else {
+		ccs = plonk.NewCS(curveID)
+	}
+	if err := readFile(f.ccs, ccs); err != nil {
+		return nil, err
+	}
+
+	s, err := server.New()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Register(circuitName, backendID, ccs); err != nil {
+		return nil, err
+	}
+	if backendID != backend.PLONK {
+		return s, nil
+	}
+	switch {
+	case f.srs != "":
+		file, err := os.Open(f.srs)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return s, s.ReadSRS(circuitName, bufio.NewReader(file))
+	case f.insecureSRS:
+		srs, err := test.NewKZGSRS(ccs)
+		if err != nil {
+			return nil, err
+		}
+		return s, s.SetSRS(circuitName, srs)
+	default:
+		return nil, errors.New("PLONK circuits need -srs or -insecure-srs")
+	}
+}
+
+func compile(args []string) error {
+	f := newFlags("compile")
+	pluginPath := f.String("plugin", "", "Go plugin exporting the circuit")
+	symbol := f.String("circuit", "Circuit", "name of the variable of the plugin holding the circuit")
+	output := f.String("o", "circuit.ccs", "output file")
+	_ = f.Parse(args)
+
+	curveID, backendID, err := f.ids()
+	if err != nil {
+		return err
+	}
+	if *pluginPath == "" {
+		return errors.New("missing -plugin")
+	}
+	p, err := plugin.Open(*pluginPath)
+	if err != nil {
+		return err
+	}
+	sym, err := p.Lookup(*symbol)
+	if err != nil {
+		return err
+	}
+	circuit, ok := sym.(frontend.Circuit)
+	if !ok {
+		return fmt.Errorf("%s: %T doesn't implement frontend.Circuit", *symbol, sym)
+	}
+
+	var newBuilder frontend.NewBuilder = r1cs.NewBuilder
+	if backendID == backend.PLONK {
+		newBuilder = scs.NewBuilder
+	}
+	ccs, err := frontend.Compile(curveID, newBuilder, circuit)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d constraints\n", ccs.GetNbConstraints())
+	return writeFile(*output, ccs)
+}
+
+func setup(args []string) error {
+	f := newFlags("setup")
+	pkPath := f.String("pk", "circuit.pk", "output proving key")
+	vkPath := f.String("vk", "circuit.vk", "output verifying key")
+	_ = f.Parse(args)
+
+	s, err := f.load()
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	if err := s.Setup(circuitName); err != nil {
+		return err
+	}
+	if err := writeKey(s, server.ProvingKey, *pkPath); err != nil {
+		return err
+	}
+	return writeKey(s, server.VerifyingKey, *vkPath)
+}
+
+func prove(args []string) error {
+	f := newFlags("prove")
+	pkPath := f.String("pk", "circuit.pk", "proving key")
+	witnessPath := f.String("witness", "witness.json", "full witness (JSON)")
+	output := f.String("o", "circuit.proof", "output proof")
+	_ = f.Parse(args)
+
+	s, err := f.load()
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	if err := readKey(s, server.ProvingKey, *pkPath); err != nil {
+		return err
+	}
+	values, err := readJSON(*witnessPath)
+	if err != nil {
+		return err
+	}
+	fullWitness, err := s.NewWitness(circuitName, values)
+	if err != nil {
+		return err
+	}
+
+	// the server proves asynchronously: queue the job and wait for it
+	id, err := s.Prove(circuitName, fullWitness)
+	if err != nil {
+		return err
+	}
+	job, err := s.Wait(context.Background(), id)
+	if err != nil {
+		return err
+	}
+	if job.Status != server.JobDone {
+		return errors.New(job.Error)
+	}
+	return os.WriteFile(*output, job.Proof, 0600)
+}
+
+func verify(args []string) error {
+	f := newFlags("verify")
+	vkPath := f.String("vk", "circuit.vk", "verifying key")
+	witnessPath := f.String("witness", "public.json", "public witness (JSON)")
+	proofPath := f.String("proof", "circuit.proof", "proof")
+	_ = f.Parse(args)
+
+	s, err := f.load()
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	if err := readKey(s, server.VerifyingKey, *vkPath); err != nil {
+		return err
+	}
+	values, err := readJSON(*witnessPath)
+	if err != nil {
+		return err
+	}
+	publicWitness, err := s.NewWitness(circuitName, values, frontend.PublicOnly())
+	if err != nil {
+		return err
+	}
+	proof, err := os.ReadFile(*proofPath)
+	if err != nil {
+		return err
+	}
+	if err := s.Verify(circuitName, proof, publicWitness); err != nil {
+		return err
+	}
+	fmt.Println("proof verified")
+	return nil
+}
+
+func readKey(s *server.Server, kind server.KeyKind, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = s.ReadKey(circuitName, kind, bufio.NewReader(file))
+	return err
+}
+
+func writeKey(s *server.Server, kind server.KeyKind, path string) error {
+	return writeFile(path, writerToFunc(func(w io.Writer) (int64, error) {
+		return s.WriteKey(circuitName, kind, w)
+	}))
+}
+
+type writerToFunc func(io.Writer) (int64, error)
+
+func (f writerToFunc) WriteTo(w io.Writer) (int64, error) { return f(w) }
+
+func writeFile(path string, v io.WriterTo) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	if _, err := v.WriteTo(w); err != nil {
+		file.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func readFile(path string, v io.ReaderFrom) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = v.ReadFrom(bufio.NewReader(file))
+	return err
+}
+
+func readJSON(path string) (map[string]interface{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	dec := json.NewDecoder(file)
+	dec.UseNumber()
+	var values map[string]interface{}
+	if err := dec.Decode(&values); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+func parseCurve(s string) (ecc.ID, error) {
+	for _, id := range ecc.Implemented() {
+		if strings.EqualFold(id.String(), s) {
+			return id, nil
+		}
+	}
+	return ecc.UNKNOWN, fmt.Errorf("unknown curve %q", s)
+}
+
+func curves() string {
+	var names []string
+	for _, id := range ecc.Implemented() {
+		names = append(names, id.String())
+	}
+	return strings.Join(names, ", ")
+}
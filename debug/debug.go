@@ -66,3 +66,28 @@ func WriteStack(sbb *strings.Builder, forceClean ...bool) {
 		}
 	}
 }
+
+// Caller returns the location (file:line) of the first caller outside of the
+// gnark frontend, that is the line of the circuit (or of the gadget) which
+// called the frontend API. The full path of the file is returned if Debug is
+// set, its base name otherwise.
+func Caller() string {
+	pc := make([]uintptr, 20)
+	n := runtime.Callers(2, pc)
+	frames := runtime.CallersFrames(pc[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "github.com/consensys/gnark/frontend") &&
+			!strings.HasPrefix(frame.Function, "github.com/consensys/gnark/debug") &&
+			!strings.HasPrefix(frame.Function, "runtime.") {
+			file := frame.File
+			if !Debug {
+				file = filepath.Base(file)
+			}
+			return file + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
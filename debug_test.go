@@ -2,6 +2,7 @@ package gnark_test
 
 import (
 	"bytes"
+	"errors"
 	"math/big"
 	"testing"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/r1cs"
 	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/frontend/schema"
 	"github.com/consensys/gnark/test"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
@@ -46,11 +48,11 @@ func TestPrintln(t *testing.T) {
 	witness.B = 11
 
 	var expected bytes.Buffer
-	expected.WriteString("debug_test.go:28 > 13 is the addition\n")
-	expected.WriteString("debug_test.go:30 > 26 42\n")
-	expected.WriteString("debug_test.go:32 > bits 1\n")
-	expected.WriteString("debug_test.go:33 > circuit {A: 2, B: 11}\n")
-	expected.WriteString("debug_test.go:37 > m .*\n")
+	expected.WriteString("debug_test.go:30 > 13 is the addition\n")
+	expected.WriteString("debug_test.go:32 > 26 42\n")
+	expected.WriteString("debug_test.go:34 > bits 1\n")
+	expected.WriteString("debug_test.go:35 > circuit {A: 2, B: 11}\n")
+	expected.WriteString("debug_test.go:39 > m .*\n")
 
 	{
 		trace, _ := getGroth16Trace(&circuit, &witness)
@@ -174,6 +176,99 @@ func TestTraceNotBoolean(t *testing.T) {
 	}
 }
 
+// -------------------------------------------------------------------------------------------------
+// structured error
+func TestUnsatisfiedConstraintError(t *testing.T) {
+	assert := require.New(t)
+
+	var circuit, witness notEqualTrace
+	witness.A = 1
+	witness.B = 24
+	witness.C = 42
+
+	{
+		_, err := getGroth16Trace(&circuit, &witness)
+		var unsatisfiedErr *backend.UnsatisfiedConstraintError
+		assert.True(errors.As(err, &unsatisfiedErr))
+		assert.Equal(0, unsatisfiedErr.CID)
+		assert.Equal("debug_test.go:113", unsatisfiedErr.Caller)
+		assert.Equal([3]string{"1", "1", "66"}, [3]string{unsatisfiedErr.L, unsatisfiedErr.R, unsatisfiedErr.O})
+		assert.Equal([]backend.WireValue{
+			{ID: 1, Name: "A", Visibility: schema.Secret, Value: "1"},
+			{ID: 2, Name: "B", Visibility: schema.Secret, Value: "24"},
+			{ID: 3, Name: "C", Visibility: schema.Secret, Value: "42"},
+		}, unsatisfiedErr.Wires)
+		assert.Contains(err.Error(), "L = 1, R = 1, O = 66")
+		assert.Contains(err.Error(), "B (secret) = 24")
+	}
+
+	{
+		_, err := getPlonkTrace(&circuit, &witness)
+		var unsatisfiedErr *backend.UnsatisfiedConstraintError
+		assert.True(errors.As(err, &unsatisfiedErr))
+		assert.Equal(1, unsatisfiedErr.CID)
+		assert.Equal("debug_test.go:113", unsatisfiedErr.Caller)
+		assert.NotEmpty(unsatisfiedErr.Wires)
+		assert.Equal("A", unsatisfiedErr.Wires[0].Name)
+		assert.Equal("1", unsatisfiedErr.Wires[0].Value)
+	}
+}
+
+// -------------------------------------------------------------------------------------------------
+// solver trace
+type solverTraceCircuit struct {
+	A, B frontend.Variable
+}
+
+func (circuit *solverTraceCircuit) Define(api frontend.API) error {
+	c := api.Mul(circuit.A, circuit.B)
+	from := api.Compiler().Tag("region")
+	api.AssertIsEqual(api.IsZero(c), 0)
+	api.Compiler().AddCounter(from, api.Compiler().Tag("end"))
+	api.AssertIsEqual(c, 6)
+	return nil
+}
+
+func TestSolverTrace(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &solverTraceCircuit{})
+	assert.NoError(err)
+	pk, err := groth16.DummySetup(ccs)
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&solverTraceCircuit{A: 2, B: 3}, ecc.BN254)
+	assert.NoError(err)
+
+	// all the constraints
+	var buf bytes.Buffer
+	_, err = groth16.Prove(ccs, pk, w, backend.WithSolverTrace(&buf))
+	assert.NoError(err)
+	trace := buf.String()
+	assert.Contains(trace, "constraint #0: ")
+	assert.Contains(trace, "\t2 ⋅ 3 == 6\n")
+	assert.Contains(trace, "hint.IsZero([6]) = [0]")
+	assert.Contains(trace, "constraint #5: ")
+
+	// only the tagged region
+	buf.Reset()
+	_, err = groth16.Prove(ccs, pk, w, backend.WithSolverTrace(&buf, "region"))
+	assert.NoError(err)
+	trace = buf.String()
+	assert.NotContains(trace, "constraint #0: ")
+	assert.Contains(trace, "constraint #1: ")
+	assert.Contains(trace, "hint.IsZero([6]) = [0]")
+	assert.NotContains(trace, "constraint #5: ")
+
+	// the failing constraint is traced
+	w, err = frontend.NewWitness(&solverTraceCircuit{A: 2, B: 4}, ecc.BN254)
+	assert.NoError(err)
+	buf.Reset()
+	_, err = groth16.Prove(ccs, pk, w, backend.WithSolverTrace(&buf))
+	assert.Error(err)
+	assert.Contains(buf.String(), "constraint #5: ")
+	assert.Contains(buf.String(), "\tnot satisfied: ")
+}
+
 func getPlonkTrace(circuit, w frontend.Circuit) (string, error) {
 	ccs, err := frontend.Compile(ecc.BN254, scs.NewBuilder, circuit)
 	if err != nil {
@@ -219,3 +314,49 @@ func getGroth16Trace(circuit, w frontend.Circuit) (string, error) {
 	_, err = groth16.Prove(ccs, pk, sw, backend.WithCircuitLogger(log))
 	return buf.String(), err
 }
+
+// -------------------------------------------------------------------------------------------------
+// test printf
+type printfCircuit struct {
+	A, B frontend.Variable
+}
+
+func (circuit *printfCircuit) Define(api frontend.API) error {
+	c := api.Add(circuit.A, circuit.B)
+	api.Printf("%d in hex is %x, in binary %08b", c, c, c)
+	api.Printf("%s: %d%%", "rate", 42)
+	api.Printf("circuit %x", circuit)
+	api.PrintfIf(api.IsZero(circuit.A), "A is zero")
+	api.PrintfIf(api.IsZero(api.Sub(circuit.A, 2)), "A is %v", circuit.A)
+	api.PrintfIf(0, "never printed")
+	api.Printf("%d", c, circuit.B)
+	api.AssertIsDifferent(c, 0)
+	return nil
+}
+
+func TestPrintf(t *testing.T) {
+	assert := require.New(t)
+
+	var circuit, witness printfCircuit
+	witness.A = 2
+	witness.B = 11
+
+	var expected bytes.Buffer
+	expected.WriteString("debug_test.go:326 > 13 in hex is d, in binary 00001101\n")
+	expected.WriteString("debug_test.go:327 > rate: 42%\n")
+	expected.WriteString("debug_test.go:328 > circuit {A: 2, B: b}\n")
+	expected.WriteString("debug_test.go:330 > A is 2\n")
+	expected.WriteString("debug_test.go:332 > 13%!\\(EXTRA 11\\)\n")
+
+	{
+		trace, err := getGroth16Trace(&circuit, &witness)
+		assert.NoError(err)
+		assert.Regexp(expected.String(), trace)
+	}
+
+	{
+		trace, err := getPlonkTrace(&circuit, &witness)
+		assert.NoError(err)
+		assert.Regexp(expected.String(), trace)
+	}
+}
@@ -1,3 +1,6 @@
+//go:build !verifieronly
+// +build !verifieronly
+
 // Copyright 2020 ConsenSys AG
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
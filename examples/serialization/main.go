@@ -1,3 +1,6 @@
+//go:build !verifieronly
+// +build !verifieronly
+
 package main
 
 import (
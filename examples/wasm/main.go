@@ -0,0 +1,118 @@
+//go:build js && wasm
+// +build js,wasm
+
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// In this example we verify gnark proofs in the browser. The verifier is built
+// to WebAssembly with the verifieronly build tag, which leaves the prover, the
+// setup, the FFTs and the constraint solver out of the binary:
+//
+//	GOOS=js GOARCH=wasm go build -tags verifieronly -o verifier.wasm ./examples/wasm
+//	cp "$(go env GOROOT)/misc/wasm/wasm_exec.js" .
+//
+// verifier.js loads verifier.wasm and exposes verify, which takes the binary
+// encodings (WriteTo, MarshalBinary) of the verifying key, the proof and the
+// public witness:
+//
+//	const { verify } = await loadVerifier('verifier.wasm')
+//	const error = verify('groth16', vk, proof, publicWitness)
+//
+// PLONK proofs also need the KZG SRS of the circuit, as it isn't part of the
+// verifying key.
+//
+// The example verifies BN254 proofs, the curve of the Ethereum precompiles:
+// the other curves only differ by their ecc.ID and kzg.SRS.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"syscall/js"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	kzg_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+)
+
+func main() {
+	js.Global().Set("gnarkVerify", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		if err := verify(args); err != nil {
+			return err.Error()
+		}
+		return js.Null()
+	}))
+
+	// keep the Go runtime, and gnarkVerify, alive
+	select {}
+}
+
+// verify verifies a proof; args are the name of the backend, the verifying
+// key, the proof, the public witness and, for PLONK, the KZG SRS.
+func verify(args []js.Value) error {
+	if len(args) < 4 {
+		return errors.New("expected backend, vk, proof and publicWitness")
+	}
+
+	publicWitness := &witness.Witness{CurveID: ecc.BN254}
+	if err := publicWitness.UnmarshalBinary(bytesOf(args[3])); err != nil {
+		return err
+	}
+
+	switch args[0].String() {
+	case "groth16":
+		vk := groth16.NewVerifyingKey(ecc.BN254)
+		if _, err := vk.ReadFrom(bytes.NewReader(bytesOf(args[1]))); err != nil {
+			return err
+		}
+		proof := groth16.NewProof(ecc.BN254)
+		if _, err := proof.ReadFrom(bytes.NewReader(bytesOf(args[2]))); err != nil {
+			return err
+		}
+		return groth16.Verify(proof, vk, publicWitness)
+	case "plonk":
+		if len(args) < 5 || args[4].IsUndefined() {
+			return errors.New("plonk: missing KZG SRS")
+		}
+		var srs kzg_bn254.SRS
+		if _, err := srs.ReadFrom(bytes.NewReader(bytesOf(args[4]))); err != nil {
+			return err
+		}
+		vk := plonk.NewVerifyingKey(ecc.BN254)
+		if _, err := vk.ReadFrom(bytes.NewReader(bytesOf(args[1]))); err != nil {
+			return err
+		}
+		if err := vk.InitKZG(&srs); err != nil {
+			return err
+		}
+		proof := plonk.NewProof(ecc.BN254)
+		if _, err := proof.ReadFrom(bytes.NewReader(bytesOf(args[2]))); err != nil {
+			return err
+		}
+		return plonk.Verify(proof, vk, publicWitness)
+	default:
+		return errors.New("unknown backend " + args[0].String())
+	}
+}
+
+// bytesOf copies a Uint8Array
+func bytesOf(v js.Value) []byte {
+	b := make([]byte, v.Get("length").Int())
+	js.CopyBytesToGo(b, v)
+	return b
+}
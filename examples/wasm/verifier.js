@@ -0,0 +1,34 @@
+// Copyright © 2022 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// loadVerifier runs the gnark verifier compiled to WebAssembly (see main.go),
+// and returns its verify function. wasm_exec.js, from the Go distribution,
+// must be loaded first.
+//
+// verify(backend, vk, proof, publicWitness[, srs]) takes 'groth16' or 'plonk'
+// and Uint8Arrays holding the binary encodings of the objects. It returns null
+// if the proof is valid, and the error of the verifier otherwise.
+async function loadVerifier(url) {
+	const go = new Go()
+	const { instance } = await WebAssembly.instantiateStreaming(fetch(url), go.importObject)
+
+	// go.run only returns when the Go program exits: the verifier never does
+	go.run(instance)
+
+	return {
+		verify(backend, vk, proof, publicWitness, srs) {
+			return globalThis.gnarkVerify(backend, vk, proof, publicWitness, srs)
+		},
+	}
+}
@@ -0,0 +1,265 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package analyze looks for under-constrained wires in a compiled constraint
+// system.
+//
+// The analysis is static: it only looks at the wires referenced by each
+// constraint, and not at the values of the coefficients. As such it detects
+// the obvious cases only, and a circuit without issues may still be
+// under-constrained.
+package analyze
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/compiled"
+	"github.com/consensys/gnark/frontend/schema"
+
+	cs_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/cs"
+	cs_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/cs"
+	cs_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/cs"
+	cs_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
+	cs_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/cs"
+	cs_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/cs"
+)
+
+// Kind is the kind of an Issue
+type Kind uint8
+
+const (
+	// UnconstrainedHint is an output of a hint (see frontend.API.NewHint) which
+	// does not appear in any constraint: the prover may assign it any value.
+	UnconstrainedHint Kind = iota
+
+	// UnboundBoolean is an output of a hint which is only constrained by
+	// boolean assertions (see frontend.API.AssertIsBoolean), or by quadratic
+	// constraints referencing no other wire: the prover may choose either of
+	// its two possible values, independently of the rest of the circuit.
+	UnboundBoolean
+
+	// UnusedPublicInput is a public input which does not appear in any
+	// constraint: a proof is valid for any value of this input.
+	UnusedPublicInput
+)
+
+func (k Kind) String() string {
+	switch k {
+	case UnconstrainedHint:
+		return "unconstrained hint output"
+	case UnboundBoolean:
+		return "unbound boolean"
+	case UnusedPublicInput:
+		return "unused public input"
+	default:
+		return "unknown"
+	}
+}
+
+// Issue is a wire which is likely under-constrained
+type Issue struct {
+	Kind Kind
+	Wire int    // ID of the wire in the constraint system
+	Name string // name of the public input, or of the hint computing the wire
+}
+
+func (i Issue) String() string {
+	switch i.Kind {
+	case UnconstrainedHint:
+		return fmt.Sprintf("wire %d: output of hint %s is not constrained", i.Wire, i.Name)
+	case UnboundBoolean:
+		return fmt.Sprintf("wire %d: output of hint %s is only asserted to be boolean", i.Wire, i.Name)
+	case UnusedPublicInput:
+		return fmt.Sprintf("wire %d: public input %s does not appear in any constraint", i.Wire, i.Name)
+	default:
+		return fmt.Sprintf("wire %d: %s", i.Wire, i.Kind)
+	}
+}
+
+// Analyze returns the under-constrained wires of ccs: the outputs of hints
+// which are not constrained, or only asserted to be boolean, and the public
+// inputs which do not appear in any constraint. The public inputs are listed
+// first, then the hint outputs, in increasing wire order.
+//
+// Note that frontend.Compile already fails on unconstrained inputs and hint
+// outputs, unless frontend.IgnoreUnconstrainedInputs is set.
+func Analyze(ccs frontend.CompiledConstraintSystem) ([]Issue, error) {
+	switch tcs := ccs.(type) {
+	case *cs_bls12377.R1CS:
+		return analyzeR1CS(&tcs.R1CS), nil
+	case *cs_bls12381.R1CS:
+		return analyzeR1CS(&tcs.R1CS), nil
+	case *cs_bn254.R1CS:
+		return analyzeR1CS(&tcs.R1CS), nil
+	case *cs_bw6761.R1CS:
+		return analyzeR1CS(&tcs.R1CS), nil
+	case *cs_bls24315.R1CS:
+		return analyzeR1CS(&tcs.R1CS), nil
+	case *cs_bw6633.R1CS:
+		return analyzeR1CS(&tcs.R1CS), nil
+	case *cs_bls12377.SparseR1CS:
+		return analyzeSparseR1CS(&tcs.SparseR1CS), nil
+	case *cs_bls12381.SparseR1CS:
+		return analyzeSparseR1CS(&tcs.SparseR1CS), nil
+	case *cs_bn254.SparseR1CS:
+		return analyzeSparseR1CS(&tcs.SparseR1CS), nil
+	case *cs_bw6761.SparseR1CS:
+		return analyzeSparseR1CS(&tcs.SparseR1CS), nil
+	case *cs_bls24315.SparseR1CS:
+		return analyzeSparseR1CS(&tcs.SparseR1CS), nil
+	case *cs_bw6633.SparseR1CS:
+		return analyzeSparseR1CS(&tcs.SparseR1CS), nil
+	default:
+		return nil, fmt.Errorf("unsupported constraint system %T", ccs)
+	}
+}
+
+// flags of a wire
+const (
+	referenced uint8 = 1 << iota // the wire appears in a constraint
+	bound                        // the wire appears in a constraint which is not a boolean assertion
+)
+
+// usage records the wires referenced by each constraint
+type usage struct {
+	hasOneWire bool
+	flags      []uint8
+	seen       []int // index+1 of the last constraint which referenced the wire
+	refs       []int // wires referenced by the current constraint
+}
+
+func newUsage(cs *compiled.ConstraintSystem, hasOneWire bool) *usage {
+	nbWires := cs.NbPublicVariables + cs.NbSecretVariables + cs.NbInternalVariables
+	return &usage{
+		hasOneWire: hasOneWire,
+		flags:      make([]uint8, nbWires),
+		seen:       make([]int, nbWires),
+	}
+}
+
+// wire returns the ID of the wire t refers to, if any. Zero coefficients
+// are ignored, unless withZero is set.
+func (u *usage) wire(t compiled.Term, withZero bool) (int, bool) {
+	if !withZero && t.CoeffID() == compiled.CoeffIdZero {
+		return 0, false
+	}
+	switch t.VariableVisibility() {
+	case schema.Unset, schema.Virtual:
+		return 0, false
+	}
+	wID := t.WireID()
+	if u.hasOneWire && wID == 0 && t.VariableVisibility() == schema.Public {
+		return 0, false
+	}
+	return wID, true
+}
+
+// add records that the term t appears in the constraint cID
+func (u *usage) add(cID int, t compiled.Term, withZero bool) {
+	if wID, ok := u.wire(t, withZero); ok && u.seen[wID] != cID+1 {
+		u.seen[wID] = cID + 1
+		u.refs = append(u.refs, wID)
+	}
+}
+
+// end marks the wires referenced by the current constraint
+func (u *usage) end(isBoolean bool) {
+	for _, wID := range u.refs {
+		u.flags[wID] |= referenced
+		if !isBoolean {
+			u.flags[wID] |= bound
+		}
+	}
+	u.refs = u.refs[:0]
+}
+
+// references returns true if l contains the wire wID
+func (u *usage) references(l compiled.LinearExpression, wID int) bool {
+	for _, t := range l {
+		if w, ok := u.wire(t, false); ok && w == wID {
+			return true
+		}
+	}
+	return false
+}
+
+func analyzeR1CS(cs *compiled.R1CS) []Issue {
+	u := newUsage(&cs.ConstraintSystem, true)
+	for i, r1c := range cs.Constraints {
+		for _, l := range []compiled.LinearExpression{r1c.L, r1c.R, r1c.O} {
+			for _, t := range l {
+				u.add(i, t, false)
+			}
+		}
+		// w ⋅ (a + b⋅w) == c
+		isBoolean := len(u.refs) == 1 && u.references(r1c.L, u.refs[0]) && u.references(r1c.R, u.refs[0])
+		u.end(isBoolean)
+	}
+	return u.issues(&cs.ConstraintSystem)
+}
+
+func analyzeSparseR1CS(cs *compiled.SparseR1CS) []Issue {
+	u := newUsage(&cs.ConstraintSystem, false)
+	for i, c := range cs.Constraints {
+		// custom gates and lookups reference their wires with a zero coefficient
+		custom := c.Gate != 0 || c.Table != 0
+		for _, t := range []compiled.Term{c.L, c.R, c.M[0], c.M[1], c.O} {
+			u.add(i, t, custom)
+		}
+		// a⋅w + b⋅w⋅w + k == 0
+		isBoolean := false
+		if !custom && len(u.refs) == 1 {
+			m0, ok0 := u.wire(c.M[0], false)
+			m1, ok1 := u.wire(c.M[1], false)
+			isBoolean = ok0 && ok1 && m0 == u.refs[0] && m1 == u.refs[0]
+		}
+		u.end(isBoolean)
+	}
+	return u.issues(&cs.ConstraintSystem)
+}
+
+func (u *usage) issues(cs *compiled.ConstraintSystem) []Issue {
+	var issues []Issue
+
+	first := 0
+	if u.hasOneWire {
+		first = 1
+	}
+	for wID := first; wID < cs.NbPublicVariables; wID++ {
+		if u.flags[wID]&referenced == 0 {
+			issues = append(issues, Issue{Kind: UnusedPublicInput, Wire: wID, Name: cs.Public[wID]})
+		}
+	}
+
+	wires := make([]int, 0, len(cs.MHints))
+	for wID := range cs.MHints {
+		wires = append(wires, wID)
+	}
+	sort.Ints(wires)
+	for _, wID := range wires {
+		name := cs.MHintsDependencies[cs.MHints[wID].ID]
+		switch {
+		case u.flags[wID]&referenced == 0:
+			issues = append(issues, Issue{Kind: UnconstrainedHint, Wire: wID, Name: name})
+		case u.flags[wID]&bound == 0:
+			issues = append(issues, Issue{Kind: UnboundBoolean, Wire: wID, Name: name})
+		}
+	}
+
+	return issues
+}
@@ -0,0 +1,65 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/stretchr/testify/require"
+)
+
+type analyzeCircuit struct {
+	X, Y   frontend.Variable `gnark:",public"`
+	Unused frontend.Variable `gnark:",public"`
+}
+
+func (circuit *analyzeCircuit) Define(api frontend.API) error {
+	// never constrained
+	if _, err := api.NewHint(hint.IsZero, 1, circuit.X); err != nil {
+		return err
+	}
+
+	// only asserted to be boolean
+	bit, err := api.NewHint(hint.IsZero, 1, circuit.X)
+	if err != nil {
+		return err
+	}
+	api.AssertIsBoolean(bit[0])
+
+	// asserted to be boolean and bound to X
+	isZero, err := api.NewHint(hint.IsZero, 1, circuit.X)
+	if err != nil {
+		return err
+	}
+	api.AssertIsBoolean(isZero[0])
+	api.AssertIsEqual(api.Mul(isZero[0], circuit.X), 0)
+
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X), circuit.Y)
+	return nil
+}
+
+func TestAnalyze(t *testing.T) {
+	assert := require.New(t)
+
+	for _, newBuilder := range []frontend.NewBuilder{r1cs.NewBuilder, scs.NewBuilder} {
+		ccs, err := frontend.Compile(ecc.BN254, newBuilder, &analyzeCircuit{}, frontend.IgnoreUnconstrainedInputs())
+		assert.NoError(err)
+
+		issues, err := Analyze(ccs)
+		assert.NoError(err)
+		assert.Len(issues, 3)
+
+		assert.Equal(UnusedPublicInput, issues[0].Kind)
+		assert.Equal("Unused", issues[0].Name)
+
+		assert.Equal(UnconstrainedHint, issues[1].Kind)
+		assert.Equal(hint.Name(hint.IsZero), issues[1].Name)
+
+		assert.Equal(UnboundBoolean, issues[2].Kind)
+		assert.Equal(hint.Name(hint.IsZero), issues[2].Name)
+		assert.Less(issues[1].Wire, issues[2].Wire)
+	}
+}
@@ -50,6 +50,22 @@ type API interface {
 	// Inverse returns res = 1 / i1
 	Inverse(i1 Variable) Variable
 
+	// BatchInvert returns res[i] = 1 / vs[i]
+	//
+	// The inverses are computed by the solver with a single hint using Montgomery's trick,
+	// so that n inversions cost one field inversion when solving the circuit; each inverse
+	// is checked with one constraint, as with Inverse. The solver fails if one of vs is zero.
+	BatchInvert(vs []Variable) []Variable
+
+	// Exp returns res = base^exponent
+	//
+	// If exponent is a constant, it must be positive and res is computed by square-and-multiply
+	// on its bits. Otherwise, exponent is decomposed on nbBits bits (fr.Bits by default), which
+	// asserts that exponent < 2^nbBits; bounding nbBits reduces the cost of the exponentiation.
+	// If nbBits >= fr.Bits, the decomposition is the canonical one, i.e. its bits are asserted
+	// to be less than the modulus, so that the prover can't use exponent + modulus instead.
+	Exp(base, exponent Variable, nbBits ...int) Variable
+
 	// ---------------------------------------------------------------------------------------------
 	// Bit operations
 	// TODO @gbotrel move bit operations in std/math/bits
@@ -64,17 +80,43 @@ type API interface {
 	// FromBinary packs b, seen as a fr.Element in little endian
 	FromBinary(b ...Variable) Variable
 
-	// Xor returns a ^ b
-	// a and b must be 0 or 1
-	Xor(a, b Variable) Variable
+	// Xor returns a ^ b ^ ... in
+	// the inputs must be 0 or 1, more than two inputs are combined along a balanced tree
+	Xor(a, b Variable, in ...Variable) Variable
+
+	// Or returns a | b | ... in
+	// the inputs must be 0 or 1, more than two inputs are combined along a balanced tree
+	Or(a, b Variable, in ...Variable) Variable
+
+	// And returns a & b & ... in
+	// the inputs must be 0 or 1, more than two inputs are combined along a balanced tree
+	And(a, b Variable, in ...Variable) Variable
+
+	// Nand returns ¬(a & b & ... in)
+	// the inputs must be 0 or 1
+	Nand(a, b Variable, in ...Variable) Variable
+
+	// Nor returns ¬(a | b | ... in)
+	// the inputs must be 0 or 1
+	Nor(a, b Variable, in ...Variable) Variable
 
-	// Or returns a | b
-	// a and b must be 0 or 1
-	Or(a, b Variable) Variable
+	// WordXor returns a ^ b, where a and b are words of nbBits bits (8, 16 or 32)
+	//
+	// The inputs are asserted to fit in nbBits. The words are decomposed in bits, or in
+	// bytes combined with lookup tables if the builder supports them (PlonK).
+	WordXor(a, b Variable, nbBits int) Variable
+
+	// WordAnd returns a & b, where a and b are words of nbBits bits (8, 16 or 32)
+	//
+	// The inputs are asserted to fit in nbBits. The words are decomposed in bits, or in
+	// bytes combined with lookup tables if the builder supports them (PlonK).
+	WordAnd(a, b Variable, nbBits int) Variable
 
-	// Or returns a & b
-	// a and b must be 0 or 1
-	And(a, b Variable) Variable
+	// WordRotateLeft returns the word a of nbBits bits (8, 16 or 32) rotated left by k
+	// bits, or right by -k bits if k < 0
+	//
+	// The input is asserted to fit in nbBits.
+	WordRotateLeft(a Variable, k, nbBits int) Variable
 
 	// ---------------------------------------------------------------------------------------------
 	// Conditionals
@@ -87,12 +129,43 @@ type API interface {
 	// and i3 if b0=b1=1.
 	Lookup2(b0, b1 Variable, i0, i1, i2, i3 Variable) Variable
 
+	// LookupN returns values[i], where i is the integer of the bits b in little
+	// endian (b[0] is the least significant bit). It generalizes Lookup2 to any
+	// number of bits, with a multiplexer of Lookup2 and Select: len(values) must
+	// be 2^len(b).
+	LookupN(b []Variable, values []Variable) Variable
+
 	// IsZero returns 1 if a is zero, 0 otherwise
 	IsZero(i1 Variable) Variable
 
 	// Cmp returns 1 if i1>i2, 0 if i1=i2, -1 if i1<i2
+	//
+	// i1 and i2 are compared as integers in [0, modulus), using their canonical
+	// binary decomposition
 	Cmp(i1, i2 Variable) Variable
 
+	// IsLess returns 1 if i1 < i2, 0 otherwise
+	//
+	// i1 and i2 are compared as in Cmp
+	IsLess(i1, i2 Variable) Variable
+
+	// IsInRange returns 1 if lower ⩽ v ⩽ upper, 0 otherwise
+	//
+	// v, lower and upper are compared as in AssertIsInRange
+	IsInRange(v, lower, upper Variable) Variable
+
+	// ---------------------------------------------------------------------------------------------
+	// Commitment
+
+	// Commit returns a commitment to the variables v, which can be used as a random challenge
+	// of the verifier in the circuit (for example to check a polynomial identity at a random
+	// point): the prover can't choose it, as it depends on the values of v.
+	//
+	// A circuit can commit only once. With Groth16, the commitment is a blinded Pedersen
+	// commitment, which the prover can link to external commitments to the same values (see
+	// groth16.Link): proofs of different circuits can then share some of their secrets.
+	Commit(v ...Variable) Variable
+
 	// ---------------------------------------------------------------------------------------------
 	// Assertions
 
@@ -102,16 +175,49 @@ type API interface {
 	// AssertIsDifferent fails if i1 == i2
 	AssertIsDifferent(i1, i2 Variable)
 
+	// AssertIsEqualIf fails if cond != 0 and i1 != i2, it is encoded as
+	// cond * (i1 - i2) == 0
+	AssertIsEqualIf(cond, i1, i2 Variable)
+
+	// AssertIsDifferentIf fails if cond == 1 and i1 == i2, cond must be 0 or 1
+	AssertIsDifferentIf(cond, i1, i2 Variable)
+
 	// AssertIsBoolean fails if v != 0 ∥ v != 1
 	AssertIsBoolean(i1 Variable)
 
 	// AssertIsLessOrEqual fails if  v > bound
+	//
+	// v and bound are compared as integers in [0, modulus). If bound is a constant, v is
+	// decomposed on bound.BitLen() bits, so the cost of the assertion grows with the bit
+	// length of the bound; otherwise, v and bound are decomposed on fr.Bits bits.
 	AssertIsLessOrEqual(v Variable, bound Variable)
 
+	// AssertIsLess fails if v ⩾ bound
+	//
+	// v and bound are compared as in AssertIsLessOrEqual; a constant bound must be strictly positive
+	AssertIsLess(v Variable, bound Variable)
+
+	// AssertIsInRange fails if v < lower or v > upper
+	//
+	// v, lower and upper are compared as integers in [0, modulus); constant bounds must be positive.
+	// If lower and upper are constants, only v - lower is decomposed, on (upper - lower).BitLen() bits.
+	AssertIsInRange(v, lower, upper Variable)
+
 	// Println behaves like fmt.Println but accepts cd.Variable as parameter
 	// whose value will be resolved at runtime when computed by the solver
 	Println(a ...Variable)
 
+	// Printf behaves like fmt.Printf but accepts Variable as parameter
+	// whose value will be resolved at runtime when computed by the solver.
+	//
+	// Variables support the integer verbs of big.Int (for example %d, %x, %08b),
+	// %s and %v print them like Println. If an argument is a structure, its variables
+	// are printed with the verb.
+	Printf(format string, a ...Variable)
+
+	// PrintfIf behaves like Printf, but prints only if condition == 1 when the circuit is solved
+	PrintfIf(condition Variable, format string, a ...Variable)
+
 	// Compiler returns the compiler object for advanced circuit development
 	Compiler() Compiler
 
@@ -62,11 +62,17 @@ type Compiler interface {
 
 // Builder represents a constraint system builder
 type Builder interface {
-	API
+	InputBuilder
 	Compiler
 
 	// Compile is called after circuit.Define() to produce a final IR (CompiledConstraintSystem)
 	Compile() (CompiledConstraintSystem, error)
+}
+
+// InputBuilder is the part of a Builder which allocates the inputs of a circuit, before
+// circuit.Define() is called on it (see Parse)
+type InputBuilder interface {
+	API
 
 	// SetSchema is used internally by frontend.Compile to set the circuit schema
 	SetSchema(*schema.Schema)
@@ -18,10 +18,10 @@ import (
 	"io"
 
 	"github.com/consensys/gnark-crypto/ecc"
-	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/witness"
 	"github.com/consensys/gnark/frontend/compiled"
 	"github.com/consensys/gnark/frontend/schema"
+	"github.com/consensys/gnark/internal/backend/prover"
 )
 
 // CompiledConstraintSystem interface that a compiled (=typed, and correctly routed)
@@ -30,8 +30,9 @@ type CompiledConstraintSystem interface {
 	io.WriterTo
 	io.ReaderFrom
 
-	// IsSolved returns nil if given witness solves the constraint system and error otherwise
-	IsSolved(witness *witness.Witness, opts ...backend.ProverOption) error
+	// IsSolved returns nil if given witness solves the constraint system and error otherwise,
+	// opts being backend.ProverOption
+	IsSolved(witness *witness.Witness, opts ...prover.Option) error
 
 	// GetNbVariables return number of internal, secret and public Variables
 	GetNbVariables() (internal, secret, public int)
@@ -48,4 +49,25 @@ type CompiledConstraintSystem interface {
 
 	// GetConstraints return a human readable representation of the constraints
 	GetConstraints() [][]string
+
+	// Print writes the constraints on w, one per line, with the names of the
+	// inputs of the circuit
+	Print(w io.Writer) error
+
+	// ExportDOT writes the dependency graph of the wires on w, in the DOT
+	// language of graphviz
+	ExportDOT(w io.Writer) error
+
+	// StripDebugInfo removes the debug info from the constraint system and
+	// returns it, to be stored apart, see compiled.DebugData
+	StripDebugInfo() compiled.DebugData
+
+	// SetDebugInfoLoader sets the function loading the stripped debug info when
+	// the solver needs it, see compiled.ConstraintSystem.SetDebugInfoLoader
+	SetDebugInfoLoader(load func() (compiled.DebugData, error))
+
+	// Fingerprint returns a hash of the circuit of the constraint system,
+	// independent of its debug info, which identifies the circuit its keys are
+	// set up for
+	Fingerprint() [32]byte
 }
@@ -3,12 +3,16 @@ package frontend
 import (
 	"errors"
 	"fmt"
+	"io"
+	"os"
 	"reflect"
 
 	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/debug"
 	"github.com/consensys/gnark/frontend/schema"
 	"github.com/consensys/gnark/logger"
+	"github.com/consensys/gnark/metrics"
 )
 
 // Compile will generate a ConstraintSystem from the given circuit
@@ -30,6 +34,19 @@ import (
 // initialCapacity is an optional parameter that reserves memory in slices
 // it should be set to the estimated number of constraints in the circuit, if known.
 func Compile(curveID ecc.ID, newBuilder NewBuilder, circuit Circuit, opts ...CompileOption) (CompiledConstraintSystem, error) {
+	m := metrics.Start(metrics.PhaseCompile, curveID, backend.UNKNOWN)
+	ccs, err := compile(curveID, newBuilder, circuit, opts...)
+	e := metrics.Event{Err: err}
+	if err == nil {
+		internal, secret, public := ccs.GetNbVariables()
+		e.NbConstraints, e.NbWires = ccs.GetNbConstraints(), internal+secret+public
+	}
+	m.Stop(e)
+	return ccs, err
+}
+
+// compile implements Compile, which measures it
+func compile(curveID ecc.ID, newBuilder NewBuilder, circuit Circuit, opts ...CompileOption) (CompiledConstraintSystem, error) {
 	log := logger.Logger()
 	log.Info().Str("curve", curveID.String()).Msg("compiling circuit")
 	// parse options
@@ -60,7 +77,16 @@ func Compile(curveID ecc.ID, newBuilder NewBuilder, circuit Circuit, opts ...Com
 	return builder.Compile()
 }
 
-func parseCircuit(builder Builder, circuit Circuit) (err error) {
+// Parse allocates the inputs of circuit in builder, then calls circuit.Define(builder).
+//
+// Compile is Parse followed by builder.Compile(). Parse is exposed for builders which
+// don't produce a CompiledConstraintSystem, as the intermediate representation of
+// frontend/ir.
+func Parse(builder InputBuilder, circuit Circuit) error {
+	return parseCircuit(builder, circuit)
+}
+
+func parseCircuit(builder InputBuilder, circuit Circuit) (err error) {
 	// ensure circuit.Define has pointer receiver
 	if reflect.ValueOf(circuit).Kind() != reflect.Ptr {
 		return errors.New("frontend.Circuit methods must be defined on pointer receiver")
@@ -125,6 +151,9 @@ type CompileOption func(opt *CompileConfig) error
 type CompileConfig struct {
 	Capacity                  int
 	IgnoreUnconstrainedInputs bool
+	Profile                   io.Writer
+	ConstraintStore           string
+	DiscardDebugInfo          bool
 }
 
 // WithCapacity is a compile option that specifies the estimated capacity needed
@@ -151,6 +180,63 @@ func IgnoreUnconstrainedInputs() CompileOption {
 	}
 }
 
+// WithProfile is a compile option which records the call stack creating each
+// constraint, and writes on w, once the circuit is compiled, a profile of the
+// number of constraints per call site in the pprof format. For example, the
+// functions adding the most constraints are listed with
+//
+//	go tool pprof -top constraints.pprof
+//
+// The call stacks stop at the frontend.API method called by the circuit.
+// Recording them slows down the compilation.
+func WithProfile(w io.Writer) CompileOption {
+	return func(opt *CompileConfig) error {
+		opt.Profile = w
+		return nil
+	}
+}
+
+// WithConstraintStore is a compile option which holds the constraints of the
+// R1CS builder in a temporary file of dir (os.TempDir() if empty) while the
+// circuit is defined, instead of in a growing slice. They are read back at the
+// end of the definition, their linear expressions deduplicated (see
+// compiled.Interner).
+//
+// It is not an out-of-core compilation: all the constraints are back in memory
+// for the checks, the levels of the solver and the compiled system, so the peak
+// memory of the compilation is still that of the whole compiled system. The
+// option only saves the memory of the constraints before their deduplication,
+// and the copies of the slice as it grows, while the circuit is defined.
+//
+// The constraints of the PLONK builder have a fixed size, and are always held in
+// memory. For large circuits, the option is best used with WithCapacity, so that
+// the other slices of the builder are allocated once.
+func WithConstraintStore(dir string) CompileOption {
+	return func(opt *CompileConfig) error {
+		if dir == "" {
+			dir = os.TempDir()
+		}
+		opt.ConstraintStore = dir
+		return nil
+	}
+}
+
+// WithoutDebugInfo is a compile option which doesn't record the debug info of
+// the constraints: the stack traces and the values of the API calls which added
+// them. It saves the time to record them, and the size of the compiled
+// constraint system, which they may double. The solver then describes an
+// unsatisfied constraint by the values of its wires only.
+//
+// To keep the debug info for the failing proofs, but not in the constraint
+// system, it is instead stripped from the compiled constraint system and
+// stored apart, see compiled.DebugData.
+func WithoutDebugInfo() CompileOption {
+	return func(opt *CompileConfig) error {
+		opt.DiscardDebugInfo = true
+		return nil
+	}
+}
+
 var tVariable reflect.Type
 
 func init() {
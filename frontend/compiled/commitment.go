@@ -0,0 +1,74 @@
+package compiled
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"sort"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/hint"
+)
+
+func init() {
+	hint.Register(CommitmentHint)
+}
+
+// Commitment describes the commitment of a constraint system to some of its wires
+// (see frontend.API.Commit). The commitment wire is the output of a hint, which the
+// provers replace by the actual commitment to the committed wires.
+type Commitment struct {
+	// Committed holds the IDs of the committed wires, in increasing order; they are the
+	// inputs of the hint, in this order. With Groth16, they are secret or internal wires.
+	Committed []int
+
+	// Wire is the ID of the commitment wire, and HintID the ID of the hint computing it
+	Wire   int
+	HintID hint.ID
+
+	// PlonK only: CommittedConstraints holds the indexes of the constraints w - w == 0
+	// added for each committed wire w. The PlonK setup turns them into π₂ - w == 0,
+	// where π₂ is the polynomial committed to by the prover.
+	CommittedConstraints []int
+
+	// PlonK only: CommitmentConstraint is the index of the constraint c - c == 0 added for
+	// the commitment wire c. The PlonK setup turns it into a public input placeholder -c + qk == 0,
+	// where qk is completed with the value of c by the prover and the verifier.
+	CommitmentConstraint int
+}
+
+// Is returns true if the constraint system commits to some of its wires
+func (c *Commitment) Is() bool {
+	return len(c.Committed) != 0
+}
+
+// IsCommitted returns true if the wire is committed, or is the commitment wire.
+// The Groth16 prover excludes these wires from its private multi exponentiation.
+func (c *Commitment) IsCommitted(wireID int) bool {
+	if !c.Is() {
+		return false
+	}
+	if wireID == c.Wire {
+		return true
+	}
+	i := sort.SearchInts(c.Committed, wireID)
+	return i < len(c.Committed) && c.Committed[i] == wireID
+}
+
+// CommitmentHint is the hint computing the commitment wire outside of a proof (with
+// test.IsSolved or ConstraintSystem.IsSolved): it returns a hash of its inputs.
+//
+// The provers replace it with the hash of a commitment to the inputs, binding the
+// commitment wire to their values.
+func CommitmentHint(curveID ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+	modulus := curveID.Info().Fr.Modulus()
+	buf := make([]byte, (modulus.BitLen()+7)/8)
+	h := sha256.New()
+	var v big.Int
+	for _, in := range inputs {
+		v.Mod(in, modulus)
+		h.Write(v.FillBytes(buf)) // #nosec G104 -- does not err
+	}
+	outputs[0].SetBytes(h.Sum(nil))
+	outputs[0].Mod(outputs[0], modulus)
+	return nil
+}
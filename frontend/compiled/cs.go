@@ -37,6 +37,9 @@ type ConstraintSystem struct {
 	// several constraints may point to the same debug info
 	MDebug map[int]int
 
+	// loads DebugInfo and MDebug once stripped, see SetDebugInfoLoader
+	debugLoader *debugLoader
+
 	Counters []Counter // TODO @gbotrel no point in serializing these
 
 	MHints             map[int]*Hint      // maps wireID to hint
@@ -45,9 +48,23 @@ type ConstraintSystem struct {
 	// each level contains independent constraints and can be parallelized
 	// it is guaranteed that all dependncies for constraints in a level l are solved
 	// in previous levels
+	//
+	// the levels are computed at compile time and encoded with the constraint
+	// system, so that a system read back is solved without computing them again
 	Levels [][]int
 
+	// LevelsNbHints[l] is the number of constraints of Levels[l] which call a hint,
+	// that the solver weighs more than the others when it splits the level in
+	// tasks. It is nil if the constraint system has no hints.
+	LevelsNbHints []int
+
+	// commitment to some of the wires, if any (see frontend.API.Commit)
+	Commitment Commitment
+
 	CurveID ecc.ID
+
+	// current namespace path, see frontend.Namespace
+	namespaces []string
 }
 
 // GetNbVariables return number of internal, secret and public variables
@@ -63,6 +80,7 @@ func (cs *ConstraintSystem) GetSchema() *schema.Schema { return cs.Schema }
 // Counter contains measurements of useful statistics between two Tag
 type Counter struct {
 	From, To      string
+	CID           int // index of the first constraint counted
 	NbVariables   int
 	NbConstraints int
 	CurveID       ecc.ID
@@ -85,7 +103,7 @@ func (cs *ConstraintSystem) AddDebugInfo(errName string, i ...interface{}) int {
 	var sbb strings.Builder
 	sbb.Grow(minLogSize)
 	sbb.WriteString("[")
-	sbb.WriteString(errName)
+	sbb.WriteString(cs.InNamespace(errName))
 	sbb.WriteString("] ")
 
 	for _, _i := range i {
@@ -110,12 +128,44 @@ func (cs *ConstraintSystem) AddDebugInfo(errName string, i ...interface{}) int {
 	sbb.WriteByte('\n')
 	debug.WriteStack(&sbb)
 	l.Format = sbb.String()
+	l.Caller = debug.Caller()
 
 	cs.DebugInfo = append(cs.DebugInfo, l)
 
 	return len(cs.DebugInfo) - 1
 }
 
+// PushNamespace opens the namespace name, nested in the current namespace
+func (cs *ConstraintSystem) PushNamespace(name string) {
+	cs.namespaces = append(cs.namespaces, name)
+}
+
+// PopNamespace closes the current namespace
+func (cs *ConstraintSystem) PopNamespace() {
+	if len(cs.namespaces) == 0 {
+		panic("no namespace to close")
+	}
+	cs.namespaces = cs.namespaces[:len(cs.namespaces)-1]
+	if len(cs.namespaces) == 0 {
+		// the compiled constraint system doesn't keep an empty stack
+		cs.namespaces = nil
+	}
+}
+
+// Namespace returns the path of the current namespace, "" outside of any
+// namespace
+func (cs *ConstraintSystem) Namespace() string {
+	return strings.Join(cs.namespaces, "/")
+}
+
+// InNamespace returns name prefixed by the path of the current namespace
+func (cs *ConstraintSystem) InNamespace(name string) string {
+	if len(cs.namespaces) == 0 {
+		return name
+	}
+	return cs.Namespace() + "/" + name
+}
+
 // bitLen returns the number of bits needed to represent a fr.Element
 func (cs *ConstraintSystem) BitLen() int {
 	return cs.CurveID.Info().Fr.Bits
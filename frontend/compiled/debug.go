@@ -0,0 +1,162 @@
+// Copyright 2022 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiled
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/internal/backend/ioutils"
+	gnarkio "github.com/consensys/gnark/io"
+	"github.com/consensys/gnark/logger"
+	"github.com/fxamacker/cbor/v2"
+)
+
+// DebugData is the debug info of a constraint system (the stack traces and the
+// values of the API calls which added the constraints), which the solver uses
+// to describe an unsatisfied constraint. It is taken out of a constraint system
+// with StripDebugInfo, to be stored apart from it, for example
+//
+//	debugData := ccs.StripDebugInfo()
+//	ccs.WriteTo(csFile)
+//	debugData.WriteTo(debugFile)
+//
+// and is given back to the constraint system read from csFile with
+// SetDebugInfoLoader.
+type DebugData struct {
+	CurveID   ecc.ID
+	DebugInfo []LogEntry
+	MDebug    map[int]int
+}
+
+// debugLoader loads the debug info of a stripped constraint system, once
+type debugLoader struct {
+	once sync.Once
+	load func() (DebugData, error)
+}
+
+// StripDebugInfo removes the debug info from the constraint system, so that it
+// isn't serialized with it, and returns it. The constraint system still solves
+// the same witnesses, but its unsatisfied constraint errors only hold the
+// values of the wires, unless the debug info is loaded back with
+// SetDebugInfoLoader.
+//
+// See also frontend.WithoutDebugInfo, which doesn't record the debug info at
+// all.
+func (cs *ConstraintSystem) StripDebugInfo() DebugData {
+	res := DebugData{CurveID: cs.CurveID, DebugInfo: cs.DebugInfo, MDebug: cs.MDebug}
+	cs.DebugInfo = nil
+	cs.MDebug = make(map[int]int)
+	cs.debugLoader = nil
+	return res
+}
+
+// SetDebugInfoLoader sets the function loading the debug info of a stripped
+// constraint system, for example from the file written by DebugData.WriteTo:
+//
+//	ccs.SetDebugInfoLoader(func() (compiled.DebugData, error) {
+//		var debugData compiled.DebugData
+//		f, err := os.Open(debugFile)
+//		if err != nil {
+//			return debugData, err
+//		}
+//		defer f.Close()
+//		_, err = debugData.ReadFrom(f)
+//		return debugData, err
+//	})
+//
+// load is called once, the first time the debug info of a constraint is needed:
+// when the solver describes an unsatisfied constraint, or when the constraints
+// are printed. If it fails, the error is logged, and the constraint system is
+// used without debug info. The debug info the constraint system holds, if any,
+// is dropped.
+func (cs *ConstraintSystem) SetDebugInfoLoader(load func() (DebugData, error)) {
+	cs.DebugInfo = nil
+	cs.MDebug = make(map[int]int)
+	cs.debugLoader = &debugLoader{load: load}
+}
+
+// DebugEntry returns the debug info of the constraint cID, if it has one. It
+// loads the debug info of a stripped constraint system, see SetDebugInfoLoader.
+func (cs *ConstraintSystem) DebugEntry(cID int) (LogEntry, bool) {
+	if l := cs.debugLoader; l != nil {
+		l.once.Do(func() {
+			debugData, err := l.load()
+			if err == nil {
+				err = debugData.check(cs)
+			}
+			if err != nil {
+				log := logger.Logger()
+				log.Warn().Err(err).Msg("loading debug info")
+				return
+			}
+			cs.DebugInfo, cs.MDebug = debugData.DebugInfo, debugData.MDebug
+		})
+	}
+	dID, ok := cs.MDebug[cID]
+	if !ok {
+		return LogEntry{}, false
+	}
+	return cs.DebugInfo[dID], true
+}
+
+// check returns an error if d isn't the debug info of cs
+func (d *DebugData) check(cs *ConstraintSystem) error {
+	if d.CurveID != cs.CurveID {
+		return fmt.Errorf("debug info over %s, expected %s", d.CurveID, cs.CurveID)
+	}
+	for cID, dID := range d.MDebug {
+		if cID < 0 || dID < 0 || dID >= len(d.DebugInfo) {
+			return fmt.Errorf("invalid debug info of constraint %d", cID)
+		}
+	}
+	return nil
+}
+
+// WriteTo encodes d in CBOR in w, after a header (see gnark/io). The curve is
+// encoded with the debug info, and checked when it is loaded.
+func (d *DebugData) WriteTo(w io.Writer) (int64, error) {
+	_w := ioutils.WriterCounter{W: w} // wraps writer to count the bytes written
+	if _, err := gnarkio.WriteHeader(&_w, ecc.UNKNOWN, backend.UNKNOWN, gnarkio.KindDebugInfo); err != nil {
+		return _w.N, err
+	}
+	enc, err := cbor.CoreDetEncOptions().EncMode()
+	if err != nil {
+		return _w.N, err
+	}
+	err = enc.NewEncoder(&_w).Encode(d)
+	return _w.N, err
+}
+
+// ReadFrom decodes d from r, written by WriteTo
+func (d *DebugData) ReadFrom(r io.Reader) (int64, error) {
+	r, _, n, err := gnarkio.ReadHeader(r, ecc.UNKNOWN, backend.UNKNOWN, gnarkio.KindDebugInfo)
+	if err != nil {
+		return n, err
+	}
+	dm, err := cbor.DecOptions{
+		MaxArrayElements: 134217728,
+		MaxMapPairs:      134217728,
+	}.DecMode()
+	if err != nil {
+		return n, err
+	}
+	decoder := dm.NewDecoder(r)
+	err = decoder.Decode(d)
+	return n + int64(decoder.NumBytesRead()), err
+}
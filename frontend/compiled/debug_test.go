@@ -0,0 +1,85 @@
+package compiled_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/compiled"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/stretchr/testify/require"
+)
+
+type debugCircuit struct {
+	A, B frontend.Variable
+}
+
+func (circuit *debugCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.A, circuit.B), 42)
+	return nil
+}
+
+func TestStripDebugInfo(t *testing.T) {
+	assert := require.New(t)
+
+	w, err := frontend.NewWitness(&debugCircuit{A: 2, B: 20}, ecc.BN254)
+	assert.NoError(err)
+
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &debugCircuit{})
+	assert.NoError(err)
+	var full bytes.Buffer
+	_, err = ccs.WriteTo(&full)
+	assert.NoError(err)
+
+	// the constraint system and its debug info are written apart
+	debugData := ccs.StripDebugInfo()
+	var stripped, sidecar bytes.Buffer
+	_, err = ccs.WriteTo(&stripped)
+	assert.NoError(err)
+	_, err = debugData.WriteTo(&sidecar)
+	assert.NoError(err)
+	assert.Less(stripped.Len(), full.Len())
+
+	read := groth16.NewCS(ecc.BN254)
+	_, err = read.ReadFrom(&stripped)
+	assert.NoError(err)
+
+	var unsatisfiedErr *backend.UnsatisfiedConstraintError
+	assert.True(errors.As(read.IsSolved(w), &unsatisfiedErr))
+	assert.Empty(unsatisfiedErr.Caller)
+	assert.Nil(unsatisfiedErr.DebugInfo)
+	assert.NotEmpty(unsatisfiedErr.Wires)
+
+	// the debug info is loaded once, when the solver fails
+	nbLoads := 0
+	read.SetDebugInfoLoader(func() (compiled.DebugData, error) {
+		nbLoads++
+		var debugData compiled.DebugData
+		_, err := debugData.ReadFrom(bytes.NewReader(sidecar.Bytes()))
+		return debugData, err
+	})
+	for i := 0; i < 2; i++ {
+		assert.True(errors.As(read.IsSolved(w), &unsatisfiedErr))
+		assert.NotEmpty(unsatisfiedErr.Caller)
+		assert.NotNil(unsatisfiedErr.DebugInfo)
+	}
+	assert.Equal(1, nbLoads)
+
+	// the debug info of another curve is rejected
+	debugData.CurveID = ecc.BLS12_381
+	read.SetDebugInfoLoader(func() (compiled.DebugData, error) { return debugData, nil })
+	assert.True(errors.As(read.IsSolved(w), &unsatisfiedErr))
+	assert.Empty(unsatisfiedErr.Caller)
+
+	// the debug info isn't recorded at all
+	ccs, err = frontend.Compile(ecc.BN254, scs.NewBuilder, &debugCircuit{}, frontend.WithoutDebugInfo())
+	assert.NoError(err)
+	assert.True(errors.As(ccs.IsSolved(w), &unsatisfiedErr))
+	assert.Empty(unsatisfiedErr.Caller)
+	assert.Empty(ccs.StripDebugInfo().DebugInfo)
+}
@@ -0,0 +1,178 @@
+package compiled
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/consensys/gnark/frontend/schema"
+)
+
+// ExportDOT writes on w the dependency graph of the wires in the DOT language
+// of graphviz, for example to be rendered with
+//
+//	dot -Tsvg circuit.dot > circuit.svg
+//
+// Each constraint is a node with an edge from each wire it depends on, and an
+// edge to the wire it solves, if any. Each hint is a node with an edge from
+// the wires of its inputs and an edge to each of its outputs. The inputs of
+// the circuit are boxes (bold for the public ones), and the wires are named
+// as in Print.
+func (r1cs *R1CS) ExportDOT(w io.Writer) error {
+	g := newGraph(&r1cs.ConstraintSystem, true)
+	for i, r1c := range r1cs.Constraints {
+		g.constraint(i, r1c.L, r1c.R, r1c.O)
+	}
+	return g.write(w)
+}
+
+// ExportDOT writes on w the dependency graph of the wires in the DOT language
+// of graphviz, see R1CS.ExportDOT.
+func (cs *SparseR1CS) ExportDOT(w io.Writer) error {
+	g := newGraph(&cs.ConstraintSystem, false)
+	for i, c := range cs.Constraints {
+		g.constraint(i, LinearExpression{c.L, c.R, c.M[0], c.M[1], c.O})
+	}
+	return g.write(w)
+}
+
+type graph struct {
+	cs         *ConstraintSystem
+	hasOneWire bool
+	solved     []bool
+	declared   []bool
+	sbb        strings.Builder
+}
+
+func newGraph(cs *ConstraintSystem, hasOneWire bool) *graph {
+	nbWires := cs.NbPublicVariables + cs.NbSecretVariables + cs.NbInternalVariables
+	g := &graph{
+		cs:         cs,
+		hasOneWire: hasOneWire,
+		solved:     make([]bool, nbWires),
+		declared:   make([]bool, nbWires),
+	}
+	for i := 0; i < cs.NbPublicVariables+cs.NbSecretVariables; i++ {
+		g.solved[i] = true
+	}
+	g.sbb.WriteString("digraph constraints {\n")
+	return g
+}
+
+func (g *graph) write(w io.Writer) error {
+	g.sbb.WriteString("}\n")
+	_, err := io.WriteString(w, g.sbb.String())
+	return err
+}
+
+// wire returns the ID of the wire of t, if t has one and it is not the one wire
+func (g *graph) wire(t Term) (int, bool) {
+	switch t.VariableVisibility() {
+	case schema.Unset, schema.Virtual:
+		return 0, false
+	case schema.Public:
+		if g.hasOneWire && t.WireID() == 0 {
+			return 0, false
+		}
+	}
+	return t.WireID(), true
+}
+
+// node returns the node of the wire wID, and declares it the first time
+func (g *graph) node(wID int) string {
+	node := "w" + strconv.Itoa(wID)
+	if g.declared[wID] {
+		return node
+	}
+	g.declared[wID] = true
+	attributes := ""
+	switch {
+	case wID < g.cs.NbPublicVariables:
+		attributes = ", shape=box, style=bold"
+	case wID < g.cs.NbPublicVariables+g.cs.NbSecretVariables:
+		attributes = ", shape=box"
+	}
+	fmt.Fprintf(&g.sbb, "\t%s [label=%q%s];\n", node, g.cs.WireName(wID), attributes)
+	return node
+}
+
+func (g *graph) edge(from, to string) {
+	fmt.Fprintf(&g.sbb, "\t%s -> %s;\n", from, to)
+}
+
+// constraint adds the constraint cID, referencing the wires in terms
+func (g *graph) constraint(cID int, terms ...LinearExpression) {
+	var wires []int
+	seen := make(map[int]struct{})
+	for _, l := range terms {
+		for _, t := range l {
+			if wID, ok := g.wire(t); ok {
+				if _, ok := seen[wID]; !ok {
+					seen[wID] = struct{}{}
+					wires = append(wires, wID)
+				}
+			}
+		}
+	}
+
+	node := "c" + strconv.Itoa(cID)
+	label := "#" + strconv.Itoa(cID)
+	if l, ok := g.cs.DebugEntry(cID); ok && l.Caller != "" {
+		label += "\n" + l.Caller
+	}
+	fmt.Fprintf(&g.sbb, "\t%s [label=%q, shape=plaintext];\n", node, label)
+
+	for _, wID := range wires {
+		if _, isHint := g.cs.MHints[wID]; isHint && !g.solved[wID] {
+			g.hint(wID)
+		}
+	}
+	for _, wID := range wires {
+		if g.solved[wID] {
+			g.edge(g.node(wID), node)
+		} else {
+			g.solved[wID] = true
+			g.edge(node, g.node(wID))
+		}
+	}
+}
+
+// hint adds the hint computing the wire wID
+func (g *graph) hint(wID int) {
+	h := g.cs.MHints[wID]
+	node := "h" + strconv.Itoa(h.Wires[0])
+	fmt.Fprintf(&g.sbb, "\t%s [label=%q, shape=diamond];\n", node, g.cs.MHintsDependencies[h.ID])
+	for _, out := range h.Wires {
+		g.solved[out] = true
+	}
+
+	var inputs []Term
+	for _, in := range h.Inputs {
+		switch t := in.(type) {
+		case LinearExpression:
+			inputs = append(inputs, t...)
+		case Term:
+			inputs = append(inputs, t)
+		}
+	}
+	seen := make(map[int]struct{})
+	for _, t := range inputs {
+		in, ok := g.wire(t)
+		if !ok {
+			continue
+		}
+		if _, ok := seen[in]; ok {
+			continue
+		}
+		seen[in] = struct{}{}
+		if _, isHint := g.cs.MHints[in]; isHint && !g.solved[in] {
+			g.hint(in)
+		}
+		g.edge(g.node(in), node)
+	}
+
+	for _, out := range h.Wires {
+		g.edge(node, g.node(out))
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2022 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiled
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"math/big"
+	"sort"
+)
+
+// fingerprintVersion is written first in the fingerprints, and is to be
+// increased if what they encode changes
+const fingerprintVersion = 1
+
+// the kinds of the inputs of the hints in the fingerprints
+const (
+	fingerprintLinearExpression = iota
+	fingerprintTerm
+	fingerprintBigInt
+)
+
+// fingerprint writes the fields of a constraint system in a hash, in a
+// canonical encoding: integers in 8 bytes little endian, slices and strings
+// prefixed by their length.
+type fingerprint struct {
+	h   hash.Hash
+	buf [8]byte
+}
+
+func (f *fingerprint) uint64(v uint64) {
+	binary.LittleEndian.PutUint64(f.buf[:], v)
+	f.h.Write(f.buf[:])
+}
+
+func (f *fingerprint) int(v int) {
+	f.uint64(uint64(v))
+}
+
+func (f *fingerprint) ints(v []int) {
+	f.int(len(v))
+	for _, i := range v {
+		f.int(i)
+	}
+}
+
+func (f *fingerprint) string(s string) {
+	f.int(len(s))
+	f.h.Write([]byte(s))
+}
+
+func (f *fingerprint) linearExpression(l LinearExpression) {
+	f.int(len(l))
+	for _, t := range l {
+		f.uint64(uint64(t))
+	}
+}
+
+func (f *fingerprint) bigInt(v *big.Int) {
+	f.int(v.Sign())
+	b := v.Bytes()
+	f.int(len(b))
+	f.h.Write(b)
+}
+
+// WriteFingerprint writes in h the fields of the constraint system which define
+// the circuit, in a canonical encoding, for the Fingerprint of the compiled
+// constraint systems: the curve, the number of wires, the hints and the
+// commitment. The names of the inputs, the debug info, the logs, the counters
+// and the levels of the solver are not written.
+//
+// It panics if a hint has an input of an unknown type.
+func (cs *ConstraintSystem) WriteFingerprint(h hash.Hash) {
+	f := fingerprint{h: h}
+	f.string("gnark")
+	f.int(fingerprintVersion)
+	f.uint64(uint64(cs.CurveID))
+	f.int(cs.NbPublicVariables)
+	f.int(cs.NbSecretVariables)
+	f.int(cs.NbInternalVariables)
+
+	// the hints, in the order of their first output wire
+	wires := make([]int, 0, len(cs.MHints))
+	for wireID, hint := range cs.MHints {
+		if len(hint.Wires) != 0 && hint.Wires[0] == wireID {
+			wires = append(wires, wireID)
+		}
+	}
+	sort.Ints(wires)
+	f.int(len(wires))
+	for _, wireID := range wires {
+		hint := cs.MHints[wireID]
+		f.uint64(uint64(hint.ID))
+		f.ints(hint.Wires)
+		f.int(len(hint.Inputs))
+		for _, in := range hint.Inputs {
+			switch v := in.(type) {
+			case LinearExpression:
+				f.int(fingerprintLinearExpression)
+				f.linearExpression(v)
+			case Term:
+				f.int(fingerprintTerm)
+				f.uint64(uint64(v))
+			case big.Int:
+				f.int(fingerprintBigInt)
+				f.bigInt(&v)
+			case *big.Int:
+				f.int(fingerprintBigInt)
+				f.bigInt(v)
+			default:
+				panic(fmt.Sprintf("fingerprint: hint input of unknown type %T", in))
+			}
+		}
+	}
+
+	f.ints(cs.Commitment.Committed)
+	f.int(cs.Commitment.Wire)
+	f.uint64(uint64(cs.Commitment.HintID))
+	f.ints(cs.Commitment.CommittedConstraints)
+	f.int(cs.Commitment.CommitmentConstraint)
+}
+
+// WriteFingerprint writes in h the fields of the R1CS which define the circuit,
+// see ConstraintSystem.WriteFingerprint, followed by its constraints.
+func (r1cs *R1CS) WriteFingerprint(h hash.Hash) {
+	r1cs.ConstraintSystem.WriteFingerprint(h)
+	f := fingerprint{h: h}
+	f.string("r1cs")
+	f.int(len(r1cs.Constraints))
+	for _, r1c := range r1cs.Constraints {
+		f.linearExpression(r1c.L)
+		f.linearExpression(r1c.R)
+		f.linearExpression(r1c.O)
+	}
+}
+
+// WriteFingerprint writes in h the fields of the SparseR1CS which define the
+// circuit, see ConstraintSystem.WriteFingerprint, followed by its constraints,
+// custom gates and lookup tables.
+func (cs *SparseR1CS) WriteFingerprint(h hash.Hash) {
+	cs.ConstraintSystem.WriteFingerprint(h)
+	f := fingerprint{h: h}
+	f.string("sparse r1cs")
+	f.int(len(cs.Constraints))
+	for _, c := range cs.Constraints {
+		f.uint64(uint64(c.L))
+		f.uint64(uint64(c.R))
+		f.uint64(uint64(c.O))
+		f.uint64(uint64(c.M[0]))
+		f.uint64(uint64(c.M[1]))
+		f.int(c.K)
+		f.int(c.Gate)
+		f.int(c.Table)
+	}
+	f.int(len(cs.Gates))
+	for _, g := range cs.Gates {
+		f.string(g.Name)
+		f.int(len(g.Terms))
+		for _, t := range g.Terms {
+			f.int(t.CoeffID)
+			f.int(t.L)
+			f.int(t.R)
+			f.int(t.O)
+		}
+	}
+	f.int(len(cs.Tables))
+	for _, t := range cs.Tables {
+		f.string(t.Name)
+		f.ints(t.Values)
+	}
+}
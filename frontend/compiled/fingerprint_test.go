@@ -0,0 +1,96 @@
+package compiled_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+type fingerprintCircuit struct {
+	A, B frontend.Variable
+	c    int
+}
+
+func (circuit *fingerprintCircuit) Define(api frontend.API) error {
+	bits := api.ToBinary(circuit.A, 8)
+	api.AssertIsEqual(api.Mul(api.FromBinary(bits...), circuit.B), circuit.c)
+	return nil
+}
+
+func TestFingerprint(t *testing.T) {
+	assert := require.New(t)
+
+	for _, backend := range []struct {
+		builder frontend.NewBuilder
+		newCS   func(ecc.ID) frontend.CompiledConstraintSystem
+	}{{r1cs.NewBuilder, groth16.NewCS}, {scs.NewBuilder, plonk.NewCS}} {
+		builder := backend.builder
+		ccs, err := frontend.Compile(ecc.BN254, builder, &fingerprintCircuit{c: 42})
+		assert.NoError(err)
+		fingerprint := ccs.Fingerprint()
+
+		// the fingerprint is stable across compilations, but depends on the circuit
+		same, err := frontend.Compile(ecc.BN254, builder, &fingerprintCircuit{c: 42}, frontend.WithoutDebugInfo())
+		assert.NoError(err)
+		assert.Equal(fingerprint, same.Fingerprint())
+		other, err := frontend.Compile(ecc.BN254, builder, &fingerprintCircuit{c: 43})
+		assert.NoError(err)
+		assert.NotEqual(fingerprint, other.Fingerprint())
+		other, err = frontend.Compile(ecc.BLS12_381, builder, &fingerprintCircuit{c: 42})
+		assert.NoError(err)
+		assert.NotEqual(fingerprint, other.Fingerprint())
+
+		// and across serializations, with or without the debug info
+		ccs.StripDebugInfo()
+		assert.Equal(fingerprint, ccs.Fingerprint())
+		var buf bytes.Buffer
+		_, err = ccs.WriteTo(&buf)
+		assert.NoError(err)
+		read := backend.newCS(ecc.BN254)
+		_, err = read.ReadFrom(&buf)
+		assert.NoError(err)
+		assert.Equal(fingerprint, read.Fingerprint())
+	}
+}
+
+func TestVerifyingKeyFingerprint(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &fingerprintCircuit{c: 42})
+	assert.NoError(err)
+	_, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	assert.Equal(ccs.Fingerprint(), vk.Fingerprint())
+
+	var buf bytes.Buffer
+	_, err = vk.WriteTo(&buf)
+	assert.NoError(err)
+	vkRead := groth16.NewVerifyingKey(ecc.BN254)
+	_, err = vkRead.ReadFrom(&buf)
+	assert.NoError(err)
+	assert.Equal(ccs.Fingerprint(), vkRead.Fingerprint())
+
+	ccs, err = frontend.Compile(ecc.BN254, scs.NewBuilder, &fingerprintCircuit{c: 42})
+	assert.NoError(err)
+	srs, err := test.NewKZGSRS(ccs)
+	assert.NoError(err)
+	_, pvk, err := plonk.Setup(ccs, srs)
+	assert.NoError(err)
+	assert.Equal(ccs.Fingerprint(), pvk.Fingerprint())
+
+	buf.Reset()
+	_, err = pvk.WriteTo(&buf)
+	assert.NoError(err)
+	pvkRead := plonk.NewVerifyingKey(ecc.BN254)
+	_, err = pvkRead.ReadFrom(&buf)
+	assert.NoError(err)
+	assert.Equal(ccs.Fingerprint(), pvkRead.Fingerprint())
+}
@@ -0,0 +1,73 @@
+// Copyright 2022 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiled
+
+// internChunkSize is the number of terms of the chunks an Interner allocates the
+// linear expressions in
+const internChunkSize = 1 << 12
+
+// Interner deduplicates the linear expressions of a constraint system (hash-consing):
+// Intern returns the same slice for equal linear expressions, so that the
+// constraints using a linear expression share its memory.
+//
+// The linear expressions of a single term are not deduplicated, as they are smaller
+// than an entry of the table of the interned expressions. All the linear expressions
+// are allocated in chunks, instead of each in its own small slice.
+type Interner struct {
+	m     map[uint64][]LinearExpression // hash code -> interned linear expressions
+	chunk LinearExpression              // free terms of the current chunk
+}
+
+// NewInterner returns an empty Interner
+func NewInterner() *Interner {
+	return &Interner{m: make(map[uint64][]LinearExpression)}
+}
+
+// Intern returns a linear expression equal to l, which may be shared with the
+// other linear expressions equal to l, and thus must not be modified. l isn't
+// retained by the Interner.
+func (in *Interner) Intern(l LinearExpression) LinearExpression {
+	if len(l) <= 1 {
+		return in.clone(l)
+	}
+	h := l.HashCode()
+	for _, e := range in.m[h] {
+		if e.Equal(l) {
+			return e
+		}
+	}
+	res := in.clone(l)
+	in.m[h] = append(in.m[h], res)
+	return res
+}
+
+// clone returns a copy of l allocated in the chunks of the Interner
+func (in *Interner) clone(l LinearExpression) LinearExpression {
+	if len(l) == 0 {
+		return LinearExpression{}
+	}
+	if len(l) > internChunkSize/8 {
+		return l.Clone()
+	}
+	if len(l) > len(in.chunk) {
+		in.chunk = make(LinearExpression, internChunkSize)
+	}
+	// the capacity is cut so that appending to the copy doesn't overwrite the
+	// next linear expression of the chunk
+	res := in.chunk[:len(l):len(l)]
+	in.chunk = in.chunk[len(l):]
+	copy(res, l)
+	return res
+}
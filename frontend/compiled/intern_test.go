@@ -0,0 +1,45 @@
+package compiled
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark/frontend/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterner(t *testing.T) {
+	assert := require.New(t)
+	newLE := func(ids ...int) LinearExpression {
+		l := make(LinearExpression, len(ids))
+		for i, id := range ids {
+			l[i] = Pack(id, CoeffIdOne, schema.Internal)
+		}
+		return l
+	}
+
+	in := NewInterner()
+	a := in.Intern(newLE(1, 2, 3))
+	b := in.Intern(newLE(1, 2, 3))
+	c := in.Intern(newLE(1, 2, 4))
+	assert.Equal(newLE(1, 2, 3), a)
+	assert.Equal(newLE(1, 2, 4), c)
+	assert.True(&a[0] == &b[0], "equal linear expressions are not shared")
+	assert.False(&a[0] == &c[0], "different linear expressions are shared")
+
+	// appending to an interned linear expression doesn't overwrite the next one
+	_ = append(a, newLE(5)...)
+	assert.Equal(newLE(1, 2, 4), c)
+
+	// the empty and single term linear expressions are copied
+	assert.Equal(LinearExpression{}, in.Intern(nil))
+	s := newLE(7)
+	i := in.Intern(s)
+	assert.Equal(s, i)
+	assert.False(&i[0] == &s[0], "single term linear expression not copied")
+
+	// the linear expressions larger than the chunks are interned too
+	large := newLE(make([]int, internChunkSize)...)
+	l1, l2 := in.Intern(large), in.Intern(large)
+	assert.Equal(large, l1)
+	assert.True(&l1[0] == &l2[0], "large linear expressions are not shared")
+}
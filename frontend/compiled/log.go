@@ -16,6 +16,7 @@ package compiled
 
 import (
 	"strings"
+	"unicode/utf8"
 
 	"github.com/consensys/gnark/frontend/schema"
 )
@@ -27,6 +28,39 @@ type LogEntry struct {
 	Caller    string
 	Format    string
 	ToResolve []Term
+	Condition []Term // if set, the entry is logged only if the sum of the terms is 1
+}
+
+// SplitFormat splits a fmt.Printf format string on its verbs, such that
+// format == texts[0] + verbs[0] + texts[1] + ... + verbs[n-1] + texts[n].
+// A verb is kept with its flags, width and precision (for example "%08b"),
+// while escaped percent signs "%%" are left in the texts.
+func SplitFormat(format string) (texts, verbs []string) {
+	start := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		if i+1 < len(format) && format[i+1] == '%' {
+			i++
+			continue
+		}
+		// flags, argument index, width and precision, up to the verb
+		j := i + 1
+		for j < len(format) && strings.IndexByte("+-# 0123456789.*[]", format[j]) != -1 {
+			j++
+		}
+		if j < len(format) {
+			_, size := utf8.DecodeRuneInString(format[j:])
+			j += size
+		}
+		texts = append(texts, format[start:i])
+		verbs = append(verbs, format[i:j])
+		start = j
+		i = j - 1
+	}
+	texts = append(texts, format[start:])
+	return
 }
 
 func (l *LogEntry) WriteVariable(le LinearExpression, sbb *strings.Builder) {
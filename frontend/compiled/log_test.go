@@ -0,0 +1,19 @@
+package compiled
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitFormat(t *testing.T) {
+	assert := require.New(t)
+
+	texts, verbs := SplitFormat("a=%d, b=%08b, c=%#x 100%% %s")
+	assert.Equal([]string{"a=", ", b=", ", c=", " 100%% ", ""}, texts)
+	assert.Equal([]string{"%d", "%08b", "%#x", "%s"}, verbs)
+
+	texts, verbs = SplitFormat("no verb%%")
+	assert.Equal([]string{"no verb%%"}, texts)
+	assert.Empty(verbs)
+}
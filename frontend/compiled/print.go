@@ -0,0 +1,194 @@
+package compiled
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/consensys/gnark/frontend/schema"
+)
+
+// Print writes the constraints on w, one per line, as L ⋅ R == O where the
+// wires are named after the inputs of the circuit (see WireName), followed by
+// the caller which added the constraint, if known. coeffs holds the values of
+// the coefficients, and modulus is the modulus of the scalar field: the
+// coefficients larger than modulus/2 are printed as negative numbers.
+func (r1cs *R1CS) Print(w io.Writer, coeffs []big.Int, modulus *big.Int) error {
+	p := newPrinter(w, &r1cs.ConstraintSystem, true, coeffs, modulus)
+	for i, r1c := range r1cs.Constraints {
+		l, r, o := p.linearExpression(r1c.L), p.linearExpression(r1c.R), p.linearExpression(r1c.O)
+		p.constraint(i, parenthesize(l)+" ⋅ "+parenthesize(r)+" == "+o)
+	}
+	return p.flush()
+}
+
+// Print writes the constraints on w, one per line, as
+// qL⋅xa + qR⋅xb + qM⋅xa⋅xb + qO⋅xc + qC == 0 without the zero terms,
+// G(xa, xb, xc) == 0 for a custom gate, or xc == T[xa] for a lookup. The
+// wires are named after the inputs of the circuit (see WireName), and each
+// constraint is followed by the caller which added it, if known. coeffs holds
+// the values of the coefficients, and modulus is the modulus of the scalar
+// field: the coefficients larger than modulus/2 are printed as negative
+// numbers.
+func (cs *SparseR1CS) Print(w io.Writer, coeffs []big.Int, modulus *big.Int) error {
+	p := newPrinter(w, &cs.ConstraintSystem, false, coeffs, modulus)
+	for i, c := range cs.Constraints {
+		switch {
+		case c.Gate != 0:
+			p.constraint(i, fmt.Sprintf("%s(%s, %s, %s) == 0", cs.Gates[c.Gate-1].Name, p.wire(c.L), p.wire(c.R), p.wire(c.O)))
+		case c.Table != 0:
+			p.constraint(i, fmt.Sprintf("%s == %s[%s]", p.wire(c.O), cs.Tables[c.Table-1].Name, p.wire(c.L)))
+		default:
+			var terms []string
+			if c.L.CoeffID() != CoeffIdZero {
+				terms = append(terms, p.term(&coeffs[c.L.CoeffID()], p.wire(c.L)))
+			}
+			if c.R.CoeffID() != CoeffIdZero {
+				terms = append(terms, p.term(&coeffs[c.R.CoeffID()], p.wire(c.R)))
+			}
+			if c.M[0].CoeffID() != CoeffIdZero && c.M[1].CoeffID() != CoeffIdZero {
+				var qM big.Int
+				qM.Mul(&coeffs[c.M[0].CoeffID()], &coeffs[c.M[1].CoeffID()]).Mod(&qM, modulus)
+				terms = append(terms, p.term(&qM, p.wire(c.M[0])+"⋅"+p.wire(c.M[1])))
+			}
+			if c.O.CoeffID() != CoeffIdZero {
+				terms = append(terms, p.term(&coeffs[c.O.CoeffID()], p.wire(c.O)))
+			}
+			if c.K != CoeffIdZero {
+				terms = append(terms, p.term(&coeffs[c.K], ""))
+			}
+			p.constraint(i, p.sum(terms)+" == 0")
+		}
+	}
+	return p.flush()
+}
+
+// WireName returns the name of the wire wID: the name of the input for a
+// public or secret wire, vN for the Nth internal wire or hvN if it is the
+// output of a hint.
+func (cs *ConstraintSystem) WireName(wID int) string {
+	if wID < cs.NbPublicVariables {
+		return cs.Public[wID]
+	}
+	wID -= cs.NbPublicVariables
+	if wID < cs.NbSecretVariables {
+		return cs.Secret[wID]
+	}
+	wID -= cs.NbSecretVariables
+	if _, ok := cs.MHints[wID+cs.NbPublicVariables+cs.NbSecretVariables]; ok {
+		return "hv" + strconv.Itoa(wID)
+	}
+	return "v" + strconv.Itoa(wID)
+}
+
+type printer struct {
+	cs         *ConstraintSystem
+	hasOneWire bool
+	coeffs     []big.Int
+	modulus    *big.Int
+	half       big.Int // modulus / 2
+	w          *bufio.Writer
+	err        error // first write error
+}
+
+func newPrinter(w io.Writer, cs *ConstraintSystem, hasOneWire bool, coeffs []big.Int, modulus *big.Int) *printer {
+	p := &printer{cs: cs, hasOneWire: hasOneWire, coeffs: coeffs, modulus: modulus, w: bufio.NewWriter(w)}
+	p.half.Rsh(modulus, 1)
+	return p
+}
+
+// constraint writes the constraint cID
+func (p *printer) constraint(cID int, s string) {
+	if p.err != nil {
+		return
+	}
+	if l, ok := p.cs.DebugEntry(cID); ok && l.Caller != "" {
+		s += "\t// " + l.Caller
+	}
+	_, p.err = fmt.Fprintf(p.w, "#%d: %s\n", cID, s)
+}
+
+func (p *printer) flush() error {
+	if p.err != nil {
+		return p.err
+	}
+	return p.w.Flush()
+}
+
+// wire returns the name of the wire of t, or 0 if t has no wire
+func (p *printer) wire(t Term) string {
+	switch t.VariableVisibility() {
+	case schema.Unset, schema.Virtual:
+		return "0"
+	}
+	return p.cs.WireName(t.WireID())
+}
+
+// term returns c⋅name, or c alone if name is empty
+func (p *printer) term(c *big.Int, name string) string {
+	var sbb strings.Builder
+	if c.Cmp(&p.half) > 0 {
+		var neg big.Int
+		neg.Sub(p.modulus, c)
+		sbb.WriteByte('-')
+		c = &neg
+	}
+	switch {
+	case name == "":
+		sbb.WriteString(c.String())
+	case c.IsUint64() && c.Uint64() == 1:
+		sbb.WriteString(name)
+	default:
+		sbb.WriteString(c.String())
+		sbb.WriteString("⋅")
+		sbb.WriteString(name)
+	}
+	return sbb.String()
+}
+
+// linearExpression returns the sum of the terms of l with a non zero coefficient
+func (p *printer) linearExpression(l LinearExpression) string {
+	terms := make([]string, 0, len(l))
+	for _, t := range l {
+		cID := t.CoeffID()
+		if cID == CoeffIdZero {
+			continue
+		}
+		name := p.wire(t)
+		if p.hasOneWire && t.WireID() == 0 && t.VariableVisibility() == schema.Public {
+			name = "" // one wire
+		}
+		terms = append(terms, p.term(&p.coeffs[cID], name))
+	}
+	return p.sum(terms)
+}
+
+// sum joins the terms with + (or - if a term is negative), or returns 0
+func (p *printer) sum(terms []string) string {
+	if len(terms) == 0 {
+		return "0"
+	}
+	var sbb strings.Builder
+	sbb.WriteString(terms[0])
+	for _, t := range terms[1:] {
+		if strings.HasPrefix(t, "-") {
+			sbb.WriteString(" - ")
+			sbb.WriteString(t[1:])
+		} else {
+			sbb.WriteString(" + ")
+			sbb.WriteString(t)
+		}
+	}
+	return sbb.String()
+}
+
+// parenthesize returns (s) if s is a sum of several terms, s otherwise
+func parenthesize(s string) string {
+	if strings.Contains(s, " ") {
+		return "(" + s + ")"
+	}
+	return s
+}
@@ -0,0 +1,70 @@
+package compiled_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/stretchr/testify/require"
+)
+
+type printCircuit struct {
+	X frontend.Variable `gnark:",public"`
+	Y frontend.Variable
+}
+
+func (circuit *printCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.Y), api.Sub(circuit.X, 3))
+	return nil
+}
+
+func TestPrint(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &printCircuit{})
+	assert.NoError(err)
+
+	var buf bytes.Buffer
+	assert.NoError(ccs.Print(&buf))
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Len(lines, 2)
+	assert.True(strings.HasPrefix(lines[0], "#0: X ⋅ Y == v0"), lines[0])
+	assert.True(strings.HasPrefix(lines[1], "#1: 1 ⋅ v0 == "), lines[1])
+	assert.Contains(lines[1], "-3")
+	assert.Contains(lines[1], "X")
+
+	ccs, err = frontend.Compile(ecc.BN254, scs.NewBuilder, &printCircuit{})
+	assert.NoError(err)
+
+	buf.Reset()
+	assert.NoError(ccs.Print(&buf))
+	lines = strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Len(lines, ccs.GetNbConstraints())
+	assert.True(strings.HasPrefix(lines[0], "#0: "), lines[0])
+	assert.Contains(buf.String(), "X⋅Y")
+}
+
+func TestExportDOT(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &printCircuit{})
+	assert.NoError(err)
+
+	var buf bytes.Buffer
+	assert.NoError(ccs.ExportDOT(&buf))
+	dot := buf.String()
+	assert.True(strings.HasPrefix(dot, "digraph constraints {\n"))
+	assert.True(strings.HasSuffix(dot, "}\n"))
+	assert.Contains(dot, "\tw1 [label=\"X\", shape=box, style=bold];\n")
+	assert.Contains(dot, "\tw2 [label=\"Y\", shape=box];\n")
+	assert.Contains(dot, "\tw3 [label=\"v0\"];\n")
+	assert.Contains(dot, "\tw1 -> c0;\n")
+	assert.Contains(dot, "\tw2 -> c0;\n")
+	assert.Contains(dot, "\tc0 -> w3;\n")
+	assert.Contains(dot, "\tw3 -> c1;\n")
+	assert.NotContains(dot, "-> w1;")
+}
@@ -16,6 +16,7 @@ package compiled
 
 import (
 	"math/big"
+	"strconv"
 	"strings"
 )
 
@@ -23,6 +24,12 @@ import (
 type SparseR1CS struct {
 	ConstraintSystem
 	Constraints []SparseR1C
+
+	// custom gates referenced by the constraints (see SparseR1C.Gate)
+	Gates []Gate
+
+	// lookup tables referenced by the constraints (see SparseR1C.Table)
+	Tables []Table
 }
 
 // GetNbConstraints returns the number of constraints
@@ -30,17 +37,76 @@ func (cs *SparseR1CS) GetNbConstraints() int {
 	return len(cs.Constraints)
 }
 
+// GetNbTableEntries returns the total number of entries of the lookup tables
+func (cs *SparseR1CS) GetNbTableEntries() int {
+	n := 0
+	for _, t := range cs.Tables {
+		n += len(t.Values)
+	}
+	return n
+}
+
 // SparseR1C used to compute the wires
 // L+R+M[0]M[1]+O+k=0
 // if a Term is zero, it means the field doesn't exist (ex M=[0,0] means there is no multiplicative term)
+//
+// if Gate is not zero, the constraint is a custom gate: L, R, O hold the wires (with a zero coefficient)
+// and the constraint is Gates[Gate-1](L, R, O) = 0
+//
+// if Table is not zero, the constraint is a lookup: L, R, O hold the wires (with a zero coefficient)
+// and the constraint is R = Table-1, O = Tables[Table-1].Values[L]
 type SparseR1C struct {
 	L, R, O Term
 	M       [2]Term
 	K       int // stores only the ID of the constant term that is used
+	Gate    int // 0 for a regular constraint, index+1 of the custom gate otherwise
+	Table   int // 0 for a regular constraint, index+1 of the lookup table otherwise
+}
+
+// Gate is a custom PlonK gate G(l, r, o) = Σᵢ cᵢ⋅lᵃⁱ⋅rᵇⁱ⋅oᶜⁱ
+type Gate struct {
+	Name  string
+	Terms []GateTerm
+}
+
+// GateTerm is a monomial cᵢ⋅lᵃⁱ⋅rᵇⁱ⋅oᶜⁱ of a custom gate
+type GateTerm struct {
+	CoeffID int // ID of the coefficient in the coefficient table
+	L, R, O int // exponents of l, r and o
+}
+
+// Table is a lookup table
+type Table struct {
+	Name   string
+	Values []int // IDs of the values in the coefficient table
 }
 
 func (r1c *SparseR1C) String(coeffs []big.Int) string {
 	var sbb strings.Builder
+	if r1c.Table != 0 {
+		sbb.WriteString("O[")
+		r1c.O.string(&sbb, coeffs)
+		sbb.WriteString("] = T")
+		sbb.WriteString(strconv.Itoa(r1c.Table - 1))
+		sbb.WriteString("[L[")
+		r1c.L.string(&sbb, coeffs)
+		sbb.WriteString("]] (R[")
+		r1c.R.string(&sbb, coeffs)
+		sbb.WriteString("])")
+		return sbb.String()
+	}
+	if r1c.Gate != 0 {
+		sbb.WriteString("G")
+		sbb.WriteString(strconv.Itoa(r1c.Gate - 1))
+		sbb.WriteString("(")
+		r1c.L.string(&sbb, coeffs)
+		sbb.WriteString(", ")
+		r1c.R.string(&sbb, coeffs)
+		sbb.WriteString(", ")
+		r1c.O.string(&sbb, coeffs)
+		sbb.WriteString(")")
+		return sbb.String()
+	}
 	sbb.WriteString("L[")
 	r1c.L.string(&sbb, coeffs)
 	sbb.WriteString("] * R[")
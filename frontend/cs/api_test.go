@@ -0,0 +1,155 @@
+package cs_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/test"
+)
+
+type expCircuit struct {
+	X, E frontend.Variable
+	Y    frontend.Variable `gnark:",public"`
+}
+
+func (c *expCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Exp(c.X, c.E), c.Y)
+	return nil
+}
+
+// nonCanonicalBits decomposes inputs[0] + modulus instead of inputs[0] when it
+// fits on len(results) bits
+func nonCanonicalBits(curveID ecc.ID, inputs []*big.Int, results []*big.Int) error {
+	n := new(big.Int).Add(inputs[0], curveID.Info().Fr.Modulus())
+	if n.BitLen() > len(results) {
+		n = inputs[0]
+	}
+	for i := range results {
+		results[i].SetUint64(uint64(n.Bit(i)))
+	}
+	return nil
+}
+
+// withNonCanonicalBits decomposes the variables as their value plus the modulus
+// when it fits on the number of bits
+func withNonCanonicalBits(opt *backend.ProverConfig) error {
+	opt.HintFunctions[hint.UUID(bits.NBits)] = nonCanonicalBits
+	return nil
+}
+
+func TestExpNonCanonicalExponent(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// 2^(1 + p) == 2^2 by Fermat's little theorem
+	for _, newBuilder := range []frontend.NewBuilder{r1cs.NewBuilder, scs.NewBuilder} {
+		ccs, err := frontend.Compile(ecc.BN254, newBuilder, &expCircuit{})
+		assert.NoError(err)
+
+		w, err := frontend.NewWitness(&expCircuit{X: 2, E: 1, Y: 2}, ecc.BN254)
+		assert.NoError(err)
+		assert.NoError(ccs.IsSolved(w))
+
+		w, err = frontend.NewWitness(&expCircuit{X: 2, E: 1, Y: 4}, ecc.BN254)
+		assert.NoError(err)
+		assert.Error(ccs.IsSolved(w, withNonCanonicalBits), "the exponent was decomposed as 1 + p")
+	}
+}
+
+type isLessCstCircuit struct {
+	X frontend.Variable
+	R frontend.Variable `gnark:",public"`
+}
+
+func (c *isLessCstCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.IsLess(c.X, 256), c.R)
+	return nil
+}
+
+type isLessCircuit struct {
+	X, Y frontend.Variable
+	R    frontend.Variable `gnark:",public"`
+}
+
+func (c *isLessCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.IsLess(c.X, c.Y), c.R)
+	return nil
+}
+
+func TestIsLessConstant(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, newBuilder := range []frontend.NewBuilder{r1cs.NewBuilder, scs.NewBuilder} {
+		ccs, err := frontend.Compile(ecc.BN254, newBuilder, &isLessCstCircuit{})
+		assert.NoError(err)
+		ccsVar, err := frontend.Compile(ecc.BN254, newBuilder, &isLessCircuit{})
+		assert.NoError(err)
+		assert.Less(ccs.GetNbConstraints(), ccsVar.GetNbConstraints()*3/4, "the constant comparison should not cost a full width comparison")
+
+		w, err := frontend.NewWitness(&isLessCstCircuit{X: 255, R: 1}, ecc.BN254)
+		assert.NoError(err)
+		assert.NoError(ccs.IsSolved(w))
+		w, err = frontend.NewWitness(&isLessCstCircuit{X: 256, R: 0}, ecc.BN254)
+		assert.NoError(err)
+		assert.NoError(ccs.IsSolved(w))
+
+		// X must be decomposed canonically: X + p is not smaller than 256
+		w, err = frontend.NewWitness(&isLessCstCircuit{X: 255, R: 0}, ecc.BN254)
+		assert.NoError(err)
+		assert.Error(ccs.IsSolved(w, withNonCanonicalBits), "X was decomposed as X + p")
+	}
+}
+
+type isInRangeCstCircuit struct {
+	X frontend.Variable
+	R frontend.Variable `gnark:",public"`
+}
+
+func (c *isInRangeCstCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.IsInRange(c.X, 0, 255), c.R)
+	return nil
+}
+
+type isInRangeCircuit struct {
+	X, Lower, Upper frontend.Variable
+	R               frontend.Variable `gnark:",public"`
+}
+
+func (c *isInRangeCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.IsInRange(c.X, c.Lower, c.Upper), c.R)
+	return nil
+}
+
+func TestIsInRangeConstant(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, newBuilder := range []frontend.NewBuilder{r1cs.NewBuilder, scs.NewBuilder} {
+		ccs, err := frontend.Compile(ecc.BN254, newBuilder, &isInRangeCstCircuit{})
+		assert.NoError(err)
+		ccsVar, err := frontend.Compile(ecc.BN254, newBuilder, &isInRangeCircuit{})
+		assert.NoError(err)
+		assert.Less(ccs.GetNbConstraints(), ccsVar.GetNbConstraints()/2, "the constant range should not cost full width comparisons")
+
+		for _, x := range []int{0, 255} {
+			w, err := frontend.NewWitness(&isInRangeCstCircuit{X: x, R: 1}, ecc.BN254)
+			assert.NoError(err)
+			assert.NoError(ccs.IsSolved(w))
+		}
+		for _, x := range []int{256, -1} {
+			w, err := frontend.NewWitness(&isInRangeCstCircuit{X: x, R: 0}, ecc.BN254)
+			assert.NoError(err)
+			assert.NoError(ccs.IsSolved(w))
+		}
+
+		// X must be decomposed canonically: X + p is out of the range
+		w, err := frontend.NewWitness(&isInRangeCstCircuit{X: 42, R: 0}, ecc.BN254)
+		assert.NoError(err)
+		assert.Error(ccs.IsSolved(w, withNonCanonicalBits), "X was decomposed as X + p")
+	}
+}
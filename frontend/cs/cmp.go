@@ -0,0 +1,44 @@
+package cs
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// IsLessBitsCst returns 1 if the little endian integer of the boolean
+// variables b is smaller than the constant bound, 0 otherwise. The bits of b
+// above the bit length of bound must all be zero, and the others are compared
+// to bound from the most significant one, with at most 2 multiplications per
+// bit: the comparison costs in the bit length of bound, not of b.
+func IsLessBitsCst(api frontend.API, b []frontend.Variable, bound *big.Int) frontend.Variable {
+	nbBits := bound.BitLen()
+	if nbBits > len(b) {
+		return 1
+	}
+
+	// the bits above nbBits are zero iff their sum is, as they are boolean
+	var res frontend.Variable = 1
+	if high := b[nbBits:]; len(high) > 0 {
+		sum := high[0]
+		if len(high) > 1 {
+			sum = api.Add(high[0], high[1], high[2:]...)
+		}
+		res = api.IsZero(sum)
+	}
+
+	// less is set on the most significant bit where b and bound differ, while
+	// equal tells whether the bits of b above it match those of bound
+	var less, equal frontend.Variable = 0, 1
+	for i := nbBits - 1; i >= 0; i-- {
+		if bound.Bit(i) == 1 {
+			less = api.Add(less, api.Mul(equal, api.Sub(1, b[i])))
+			if i > 0 {
+				equal = api.Mul(equal, b[i])
+			}
+		} else {
+			equal = api.Mul(equal, api.Sub(1, b[i]))
+		}
+	}
+	return api.Mul(res, less)
+}
@@ -0,0 +1,37 @@
+package cs
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// LookupN returns values[i], where i is the little endian integer of the bits
+// b. It is a recursive multiplexer: the two most significant bits select with
+// api.Lookup2 one of the four quarters of values, in which the other bits
+// select the result, or the most significant bit selects one of the two halves
+// with api.Select if the number of bits is odd. len(values) must be 2^len(b).
+func LookupN(api frontend.API, b []frontend.Variable, values []frontend.Variable) frontend.Variable {
+	CheckLookupSize(len(b), len(values))
+	n := len(b)
+	if n == 0 {
+		return values[0]
+	}
+	if n%2 == 1 {
+		half := len(values) / 2
+		return api.Select(b[n-1], LookupN(api, b[:n-1], values[half:]), LookupN(api, b[:n-1], values[:half]))
+	}
+	quarter := len(values) / 4
+	q := make([]frontend.Variable, 4)
+	for i := range q {
+		q[i] = LookupN(api, b[:n-2], values[i*quarter:(i+1)*quarter])
+	}
+	return api.Lookup2(b[n-2], b[n-1], q[0], q[1], q[2], q[3])
+}
+
+// CheckLookupSize panics if nbValues is not 2^nbBits
+func CheckLookupSize(nbBits, nbValues int) {
+	if nbBits >= 31 || nbValues != 1<<nbBits {
+		panic(fmt.Sprintf("invalid lookup of %d values with %d bits, expected 2^%d values", nbValues, nbBits, nbBits))
+	}
+}
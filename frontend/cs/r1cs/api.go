@@ -22,11 +22,13 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"sort"
 	"strings"
 
 	"github.com/consensys/gnark/backend/hint"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/compiled"
+	"github.com/consensys/gnark/frontend/cs"
 	"github.com/consensys/gnark/frontend/schema"
 	"github.com/consensys/gnark/std/math/bits"
 )
@@ -99,7 +101,7 @@ func (system *r1cs) Mul(i1, i2 frontend.Variable, in ...frontend.Variable) front
 		// v1 and v2 are both unknown, this is the only case we add a constraint
 		if !v1Constant && !v2Constant {
 			res := system.newInternalVariable()
-			system.Constraints = append(system.Constraints, newR1C(v1, v2, res))
+			system.addConstraint(system.newR1C(v1, v2, res))
 			return res
 		}
 
@@ -166,7 +168,7 @@ func (system *r1cs) DivUnchecked(i1, i2 frontend.Variable) frontend.Variable {
 		res := system.newInternalVariable()
 		debug := system.AddDebugInfo("div", v1, "/", v2, " == ", res)
 		// note that here we don't ensure that divisor is != 0
-		system.addConstraint(newR1C(v2, res, v1), debug)
+		system.addConstraint(system.newR1C(v2, res, v1), debug)
 		return res
 	}
 
@@ -201,8 +203,8 @@ func (system *r1cs) Div(i1, i2 frontend.Variable) frontend.Variable {
 		debug := system.AddDebugInfo("div", v1, "/", v2, " == ", res)
 		v2Inv := system.newInternalVariable()
 		// note that here we ensure that v2 can't be 0, but it costs us one extra constraint
-		system.addConstraint(newR1C(v2, v2Inv, system.one()), debug)
-		system.addConstraint(newR1C(v1, v2Inv, res), debug)
+		system.addConstraint(system.newR1C(v2, v2Inv, system.one()), debug)
+		system.addConstraint(system.newR1C(v1, v2Inv, res), debug)
 		return res
 	}
 
@@ -239,11 +241,102 @@ func (system *r1cs) Inverse(i1 frontend.Variable) frontend.Variable {
 	res := system.newInternalVariable()
 
 	debug := system.AddDebugInfo("inverse", vars[0], "*", res, " == 1")
-	system.addConstraint(newR1C(res, vars[0], system.one()), debug)
+	system.addConstraint(system.newR1C(res, vars[0], system.one()), debug)
 
 	return res
 }
 
+// BatchInvert returns res[i] = 1 / vs[i]
+//
+// the inverses of the variables are computed by the solver with a single hint (see hint.BatchInvert)
+// and each of them is checked with one constraint
+func (system *r1cs) BatchInvert(vs []frontend.Variable) []frontend.Variable {
+	res := make([]frontend.Variable, len(vs))
+	var toInvert []frontend.Variable
+	var indexes []int
+	for i := range vs {
+		if c, ok := system.ConstantValue(vs[i]); ok {
+			res[i] = system.Inverse(c)
+			continue
+		}
+		toInvert = append(toInvert, vs[i])
+		indexes = append(indexes, i)
+	}
+	if len(toInvert) == 0 {
+		return res
+	}
+
+	inv, err := system.NewHint(hint.BatchInvert, len(toInvert), toInvert...)
+	if err != nil {
+		// the function errs only if the number of inputs is invalid.
+		panic(err)
+	}
+	for j := range toInvert {
+		v := toInvert[j].(compiled.LinearExpression)
+		debug := system.AddDebugInfo("batchInvert", v, "*", inv[j], " == 1")
+		system.addConstraint(system.newR1C(inv[j], v, system.one()), debug)
+		res[indexes[j]] = inv[j]
+	}
+	return res
+}
+
+// Exp returns res = base^exponent
+//
+// if exponent is a constant, res is computed by square-and-multiply on its bits,
+// otherwise exponent is decomposed on nbBits bits (system.BitLen() by default); if
+// nbBits >= system.BitLen(), the decomposition is the canonical one, bounded by modulus - 1
+func (system *r1cs) Exp(base, exponent frontend.Variable, nbBits ...int) frontend.Variable {
+	n := system.BitLen()
+	if len(nbBits) == 1 {
+		n = nbBits[0]
+		if n < 0 {
+			panic("invalid nbBits")
+		}
+	}
+
+	if e, ok := system.ConstantValue(exponent); ok {
+		if e.Sign() == -1 {
+			panic("Exp: exponent must be positive")
+		}
+		if e.BitLen() > n {
+			panic(fmt.Sprintf("Exp: exponent %s doesn't fit on %d bits", e.String(), n))
+		}
+		if b, ok := system.ConstantValue(base); ok {
+			modulus := system.CurveID.Info().Fr.Modulus()
+			b.Mod(b, modulus)
+			return system.toVariable(b.Exp(b, e, modulus))
+		}
+
+		// left-to-right square and multiply, the first iterations are free
+		res := system.toVariable(1)
+		for i := e.BitLen() - 1; i >= 0; i-- {
+			res = system.Mul(res, res)
+			if e.Bit(i) == 1 {
+				res = system.Mul(res, base)
+			}
+		}
+		return res
+	}
+
+	var eBits []frontend.Variable
+	if n >= system.BitLen() {
+		// exponent < modulus: with n bits, exponent + modulus would have a
+		// decomposition too, and base^(exponent + modulus) != base^exponent
+		vars, _ := system.toVariables(exponent)
+		eBits = system.toCanonicalBinary(vars[0])
+		n = len(eBits)
+	} else {
+		// exponent < 2^n
+		eBits = system.ToBinary(exponent, n)
+	}
+	res := system.toVariable(1)
+	for i := n - 1; i >= 0; i-- {
+		res = system.Mul(res, res)
+		res = system.Select(eBits[i], system.Mul(res, base), res)
+	}
+	return res
+}
+
 // ---------------------------------------------------------------------------------------------
 // Bit operations
 
@@ -270,8 +363,69 @@ func (system *r1cs) FromBinary(_b ...frontend.Variable) frontend.Variable {
 	return bits.FromBinary(system, _b)
 }
 
-// Xor compute the XOR between two frontend.Variables
-func (system *r1cs) Xor(_a, _b frontend.Variable) frontend.Variable {
+// Xor returns a ^ b ^ ... in, computed as a balanced tree of xors
+//
+// the inputs must be 0 or 1
+func (system *r1cs) Xor(a, b frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	return cs.BalancedTree(system.xor, append([]frontend.Variable{a, b}, in...))
+}
+
+// Or returns a | b | ... in, computed as a balanced tree of ors
+//
+// the inputs must be 0 or 1
+func (system *r1cs) Or(a, b frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	return cs.BalancedTree(system.or, append([]frontend.Variable{a, b}, in...))
+}
+
+// And returns a & b & ... in, computed as a balanced tree of ands
+//
+// the inputs must be 0 or 1
+func (system *r1cs) And(a, b frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	return cs.BalancedTree(system.and, append([]frontend.Variable{a, b}, in...))
+}
+
+// Nand returns ¬(a & b & ... in)
+//
+// the inputs must be 0 or 1
+func (system *r1cs) Nand(a, b frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	return system.not(system.And(a, b, in...))
+}
+
+// Nor returns ¬(a | b | ... in)
+//
+// the inputs must be 0 or 1
+func (system *r1cs) Nor(a, b frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	return system.not(system.Or(a, b, in...))
+}
+
+// not returns 1 - a, where a is 0 or 1
+func (system *r1cs) not(a frontend.Variable) frontend.Variable {
+	res := system.Sub(1, a)
+	if _, ok := system.ConstantValue(res); !ok {
+		system.MarkBoolean(res)
+	}
+	return res
+}
+
+// WordXor returns a ^ b for words of nbBits bits, xoring their bits
+func (system *r1cs) WordXor(a, b frontend.Variable, nbBits int) frontend.Variable {
+	return cs.WordBitwise(system, system.Xor, a, b, nbBits)
+}
+
+// WordAnd returns a & b for words of nbBits bits, anding their bits
+func (system *r1cs) WordAnd(a, b frontend.Variable, nbBits int) frontend.Variable {
+	return cs.WordBitwise(system, system.And, a, b, nbBits)
+}
+
+// WordRotateLeft returns the word a of nbBits bits rotated left by k bits, by
+// permuting its bits (the packing is free in R1CS)
+func (system *r1cs) WordRotateLeft(a frontend.Variable, k, nbBits int) frontend.Variable {
+	return cs.WordRotateLeft(system, a, k, nbBits)
+}
+
+// xor returns a ^ b
+// a and b must be 0 or 1
+func (system *r1cs) xor(_a, _b frontend.Variable) frontend.Variable {
 
 	vars, _ := system.toVariables(_a, _b)
 
@@ -285,15 +439,16 @@ func (system *r1cs) Xor(_a, _b frontend.Variable) frontend.Variable {
 	res := system.newInternalVariable()
 	system.MarkBoolean(res)
 	c := system.Neg(res).(compiled.LinearExpression)
-	c = append(c, a[0], b[0])
+	c = append(c, a...)
+	c = append(c, b...)
 	aa := system.Mul(a, 2)
-	system.Constraints = append(system.Constraints, newR1C(aa, b, c))
+	system.addConstraint(system.newR1C(aa, b, c))
 
 	return res
 }
 
-// Or compute the OR between two frontend.Variables
-func (system *r1cs) Or(_a, _b frontend.Variable) frontend.Variable {
+// or compute the OR between two frontend.Variables
+func (system *r1cs) or(_a, _b frontend.Variable) frontend.Variable {
 	vars, _ := system.toVariables(_a, _b)
 
 	a := vars[0]
@@ -306,14 +461,15 @@ func (system *r1cs) Or(_a, _b frontend.Variable) frontend.Variable {
 	res := system.newInternalVariable()
 	system.MarkBoolean(res)
 	c := system.Neg(res).(compiled.LinearExpression)
-	c = append(c, a[0], b[0])
-	system.Constraints = append(system.Constraints, newR1C(a, b, c))
+	c = append(c, a...)
+	c = append(c, b...)
+	system.addConstraint(system.newR1C(a, b, c))
 
 	return res
 }
 
-// And compute the AND between two frontend.Variables
-func (system *r1cs) And(_a, _b frontend.Variable) frontend.Variable {
+// and compute the AND between two frontend.Variables
+func (system *r1cs) and(_a, _b frontend.Variable) frontend.Variable {
 	vars, _ := system.toVariables(_a, _b)
 
 	a := vars[0]
@@ -423,6 +579,12 @@ func (system *r1cs) Lookup2(b0, b1 frontend.Variable, i0, i1, i2, i3 frontend.Va
 	return res
 }
 
+// LookupN returns values[i], where i is the little endian integer of the bits
+// b, see frontend.API.LookupN
+func (system *r1cs) LookupN(b []frontend.Variable, values []frontend.Variable) frontend.Variable {
+	return cs.LookupN(system, b, values)
+}
+
 // IsZero returns 1 if i1 is zero, 0 otherwise
 func (system *r1cs) IsZero(i1 frontend.Variable) frontend.Variable {
 	vars, _ := system.toVariables(i1)
@@ -447,7 +609,7 @@ func (system *r1cs) IsZero(i1 frontend.Variable) frontend.Variable {
 		panic(err)
 	}
 	m := res[0]
-	system.addConstraint(newR1C(a, m, system.toVariable(0)), debug)
+	system.addConstraint(system.newR1C(a, m, system.toVariable(0)), debug)
 
 	system.AssertIsBoolean(m)
 	ma := system.Add(m, a)
@@ -456,29 +618,114 @@ func (system *r1cs) IsZero(i1 frontend.Variable) frontend.Variable {
 }
 
 // Cmp returns 1 if i1>i2, 0 if i1=i2, -1 if i1<i2
+//
+// i1 and i2 are compared as integers in [0, modulus)
 func (system *r1cs) Cmp(i1, i2 frontend.Variable) frontend.Variable {
 
 	vars, _ := system.toVariables(i1, i2)
-	bi1 := system.ToBinary(vars[0], system.BitLen())
-	bi2 := system.ToBinary(vars[1], system.BitLen())
+	bi1 := system.toCanonicalBinary(vars[0])
+	bi2 := system.toCanonicalBinary(vars[1])
 
-	res := system.toVariable(0)
+	var res frontend.Variable
+	res = system.toVariable(0)
 
 	for i := system.BitLen() - 1; i >= 0; i-- {
+		// res is in {-1, 0, 1}, so 1 - res² == 1 iff res == 0: res is set to
+		// bi1[i] - bi2[i] on the most significant bit where i1 and i2 differ
+		d := system.Sub(bi1[i], bi2[i])
+		res = system.Add(res, system.Mul(system.Sub(1, system.Mul(res, res)), d))
+	}
+	return res
+}
 
-		iszeroi1 := system.IsZero(bi1[i])
-		iszeroi2 := system.IsZero(bi2[i])
+// IsLess returns 1 if i1 < i2, 0 otherwise
+//
+// i1 and i2 are compared as integers in [0, modulus)
+func (system *r1cs) IsLess(i1, i2 frontend.Variable) frontend.Variable {
+	modulus := system.CurveID.Info().Fr.Modulus()
+	if c, ok := system.ConstantValue(i2); ok {
+		return system.isLessCst(i1, c.Mod(c, modulus))
+	}
+	if c, ok := system.ConstantValue(i1); ok {
+		// c < i2 iff i2 ⩾ c + 1
+		c.Mod(c, modulus).Add(c, big.NewInt(1))
+		return system.Sub(1, system.isLessCst(i2, c))
+	}
+
+	// c is in {-1, 0, 1}, and c(c-1)/2 == 1 iff c == -1
+	c := system.Cmp(i1, i2)
+	return system.DivUnchecked(system.Mul(c, system.Sub(c, 1)), 2)
+}
 
-		i1i2 := system.And(bi1[i], iszeroi2)
-		i2i1 := system.And(bi2[i], iszeroi1)
+// isLessCst returns 1 if a < bound, 0 otherwise, a being compared as an
+// integer in [0, modulus) to the non negative constant bound. The result is
+// sound only on the canonical decomposition of a, but its bits are compared to
+// bound on the bit length of bound only, instead of the full width of Cmp.
+func (system *r1cs) isLessCst(a frontend.Variable, bound *big.Int) frontend.Variable {
+	if bound.Sign() != 1 {
+		return system.toVariable(0)
+	}
+	if bound.Cmp(system.CurveID.Info().Fr.Modulus()) != -1 {
+		return system.toVariable(1)
+	}
+	if c, ok := system.ConstantValue(a); ok {
+		if c.Mod(c, system.CurveID.Info().Fr.Modulus()).Cmp(bound) == -1 {
+			return system.toVariable(1)
+		}
+		return system.toVariable(0)
+	}
+	vars, _ := system.toVariables(a)
+	return cs.IsLessBitsCst(system, system.toCanonicalBinary(vars[0]), bound)
+}
 
-		n := system.Select(i2i1, -1, 0)
-		m := system.Select(i1i2, 1, n)
+// IsInRange returns 1 if lower ⩽ v ⩽ upper, 0 otherwise
+//
+// v, lower and upper are compared as integers in [0, modulus)
+func (system *r1cs) IsInRange(v, lower, upper frontend.Variable) frontend.Variable {
+	l, lOk := system.ConstantValue(lower)
+	u, uOk := system.ConstantValue(upper)
+	if lOk && uOk {
+		if l.Sign() == -1 || u.Sign() == -1 {
+			panic("IsInRange: bounds must be positive")
+		}
+		if l.Cmp(u) == 1 {
+			return system.toVariable(0)
+		}
+		// as in AssertIsInRange, lower ⩽ v ⩽ upper iff v - lower < upper - lower + 1,
+		// which is compared on the bit length of upper - lower + 1
+		bound := new(big.Int).Sub(u, l)
+		bound.Add(bound, big.NewInt(1))
+		if bound.Cmp(system.CurveID.Info().Fr.Modulus()) != -1 {
+			return system.toVariable(1)
+		}
+		return system.isLessCst(system.Sub(v, l), bound)
+	}
 
-		res = system.Select(system.IsZero(res), m, res)
+	// (v ⩾ lower) ∧ (v ⩽ upper)
+	ge := system.Sub(1, system.IsLess(v, lower))
+	le := system.Sub(1, system.IsLess(upper, v))
+	return system.Mul(ge, le)
+}
 
+// toCanonicalBinary returns the system.BitLen() bits of a, bounded by modulus - 1 so that
+// they are the canonical decomposition of a
+func (system *r1cs) toCanonicalBinary(a compiled.LinearExpression) []frontend.Variable {
+	nbBits := system.BitLen()
+	if c, ok := system.ConstantValue(a); ok {
+		return system.ToBinary(c, nbBits)
 	}
-	return res
+
+	debug := system.AddDebugInfo("toCanonicalBinary", a)
+
+	// note that at this stage, we didn't boolean-constraint these new variables yet
+	// (as opposed to ToBinary)
+	aBits := bits.ToBinary(system, a, bits.WithNbDigits(nbBits), bits.WithUnconstrainedOutputs())
+
+	var bound big.Int
+	bound.Sub(system.CurveID.Info().Fr.Modulus(), big.NewInt(1))
+	system.mustBitsBeLessOrEqCst(aBits, bound, debug)
+
+	return aBits
 }
 
 // Println enables circuit debugging and behaves almost like fmt.Println()
@@ -510,7 +757,7 @@ func (system *r1cs) Println(a ...frontend.Variable) {
 			log.ToResolve = append(log.ToResolve, v...)
 			log.ToResolve = append(log.ToResolve, compiled.TermDelimitor)
 		} else {
-			printArg(&log, &sbb, arg)
+			printArg(&log, &sbb, arg, "%v")
 		}
 	}
 
@@ -520,7 +767,80 @@ func (system *r1cs) Println(a ...frontend.Variable) {
 	system.Logs = append(system.Logs, log)
 }
 
-func printArg(log *compiled.LogEntry, sbb *strings.Builder, a frontend.Variable) {
+// Printf enables circuit debugging and behaves almost like fmt.Printf()
+//
+// the verbs applied to variables are resolved when the R1CS.Solve() method is called
+func (system *r1cs) Printf(format string, a ...frontend.Variable) {
+	system.printf(nil, format, a)
+}
+
+// PrintfIf behaves like Printf, but the line is printed only if condition == 1
+// when the R1CS.Solve() method is called
+func (system *r1cs) PrintfIf(condition frontend.Variable, format string, a ...frontend.Variable) {
+	if c, ok := system.ConstantValue(condition); ok {
+		if !(c.IsUint64() && c.Uint64() == 1) {
+			return
+		}
+		system.printf(nil, format, a)
+		return
+	}
+	system.printf(condition.(compiled.LinearExpression), format, a)
+}
+
+func (system *r1cs) printf(condition compiled.LinearExpression, format string, a []frontend.Variable) {
+	log := compiled.LogEntry{Condition: condition}
+
+	// prefix log line with file.go:line of the caller of Printf or PrintfIf
+	if _, file, line, ok := runtime.Caller(2); ok {
+		log.Caller = fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	}
+
+	var sbb strings.Builder
+	printVariable := func(arg frontend.Variable, verb string) {
+		if v, ok := arg.(compiled.LinearExpression); ok {
+			assertIsSet(v)
+
+			sbb.WriteString(verb)
+			log.ToResolve = append(log.ToResolve, compiled.TermDelimitor)
+			log.ToResolve = append(log.ToResolve, v...)
+			log.ToResolve = append(log.ToResolve, compiled.TermDelimitor)
+		} else {
+			printArg(&log, &sbb, arg, verb)
+		}
+	}
+
+	texts, verbs := compiled.SplitFormat(format)
+	for i, verb := range verbs {
+		sbb.WriteString(texts[i])
+		if i < len(a) {
+			printVariable(a[i], verb)
+		} else {
+			// fmt.Sprintf reports the missing argument
+			sbb.WriteString(verb)
+		}
+	}
+	sbb.WriteString(texts[len(verbs)])
+
+	// like fmt.Sprintf, report the extra arguments
+	if len(a) > len(verbs) {
+		sbb.WriteString("%%!(EXTRA ")
+		for i := len(verbs); i < len(a); i++ {
+			if i > len(verbs) {
+				sbb.WriteString(", ")
+			}
+			printVariable(a[i], "%v")
+		}
+		sbb.WriteByte(')')
+	}
+
+	log.Format = sbb.String()
+
+	system.Logs = append(system.Logs, log)
+}
+
+// printArg writes a with the given verb in the format of log. If a is a structure,
+// each of its variables is printed with the verb.
+func printArg(log *compiled.LogEntry, sbb *strings.Builder, a frontend.Variable, verb string) {
 
 	count := 0
 	counter := func(visibility schema.Visibility, name string, tValue reflect.Value) error {
@@ -532,7 +852,7 @@ func printArg(log *compiled.LogEntry, sbb *strings.Builder, a frontend.Variable)
 
 	// no variables in nested struct, we use fmt std print function
 	if count == 0 {
-		sbb.WriteString(fmt.Sprint(a))
+		sbb.WriteString(strings.ReplaceAll(fmt.Sprintf(verb, a), "%", "%%"))
 		return
 	}
 
@@ -541,7 +861,7 @@ func printArg(log *compiled.LogEntry, sbb *strings.Builder, a frontend.Variable)
 		count--
 		sbb.WriteString(name)
 		sbb.WriteString(": ")
-		sbb.WriteString("%s")
+		sbb.WriteString(verb)
 		if count != 0 {
 			sbb.WriteString(", ")
 		}
@@ -572,6 +892,60 @@ func (system *r1cs) negateLinExp(l compiled.LinearExpression) compiled.LinearExp
 	return res
 }
 
+// Commit returns a commitment to the variables v (see frontend.API.Commit)
+//
+// The commitment is the output of a hint, that the Groth16 prover computes from a Pedersen
+// commitment to the committed wires. Public variables and linear expressions are first
+// copied in new internal wires.
+func (system *r1cs) Commit(v ...frontend.Variable) frontend.Variable {
+	if system.Commitment.Is() {
+		panic("a circuit can commit only once")
+	}
+
+	mCommitted := make(map[int]struct{}, len(v))
+	for _, vv := range v {
+		if _, ok := system.ConstantValue(vv); ok {
+			continue
+		}
+		l := vv.(compiled.LinearExpression)
+		if len(l) == 1 && l[0].CoeffID() == compiled.CoeffIdOne && l[0].VariableVisibility() != schema.Public {
+			mCommitted[l[0].WireID()] = struct{}{}
+			continue
+		}
+		w := system.newInternalVariable()
+		system.addConstraint(system.newR1C(l, system.one(), w))
+		mCommitted[w[0].WireID()] = struct{}{}
+	}
+	if len(mCommitted) == 0 {
+		panic("commit: no variable to commit to")
+	}
+	committed := make([]int, 0, len(mCommitted))
+	for w := range mCommitted {
+		committed = append(committed, w)
+	}
+	sort.Ints(committed)
+
+	inputs := make([]frontend.Variable, len(committed))
+	for i, w := range committed {
+		visibility := schema.Internal
+		if w < system.NbPublicVariables+system.NbSecretVariables {
+			visibility = schema.Secret
+		}
+		inputs[i] = compiled.LinearExpression{compiled.Pack(w, compiled.CoeffIdOne, visibility)}
+	}
+	res, err := system.NewHint(compiled.CommitmentHint, 1, inputs...)
+	if err != nil {
+		panic(err)
+	}
+
+	system.Commitment = compiled.Commitment{
+		Committed: committed,
+		Wire:      res[0].(compiled.LinearExpression)[0].WireID(),
+		HintID:    hint.UUID(compiled.CommitmentHint),
+	}
+	return res[0]
+}
+
 func (system *r1cs) Compiler() frontend.Compiler {
 	return system
 }
@@ -34,7 +34,7 @@ func (system *r1cs) AssertIsEqual(i1, i2 frontend.Variable) {
 
 	debug := system.AddDebugInfo("assertIsEqual", r, " == ", o)
 
-	system.addConstraint(newR1C(system.one(), r, o), debug)
+	system.addConstraint(system.newR1C(system.one(), r, o), debug)
 }
 
 // AssertIsDifferent constrain i1 and i2 to be different
@@ -42,6 +42,30 @@ func (system *r1cs) AssertIsDifferent(i1, i2 frontend.Variable) {
 	system.Inverse(system.Sub(i1, i2))
 }
 
+// AssertIsEqualIf adds an assertion in the constraint system (cond != 0 ⇒ i1 == i2)
+func (system *r1cs) AssertIsEqualIf(cond, i1, i2 frontend.Variable) {
+	vars, _ := system.toVariables(cond, i1, i2)
+
+	if c, ok := system.ConstantValue(vars[0]); ok {
+		if c.Sign() != 0 {
+			system.AssertIsEqual(vars[1], vars[2])
+		}
+		return
+	}
+
+	// encoded cond * (i1 - i2) == 0
+	d := system.Sub(vars[1], vars[2]).(compiled.LinearExpression)
+	debug := system.AddDebugInfo("assertIsEqualIf", vars[0], " * ", d, " == 0")
+
+	system.addConstraint(system.newR1C(vars[0], d, system.toVariable(0)), debug)
+}
+
+// AssertIsDifferentIf constrain i1 and i2 to be different if cond == 1
+func (system *r1cs) AssertIsDifferentIf(cond, i1, i2 frontend.Variable) {
+	// i1 - i2 is invertible if cond == 1, and 1 is if cond == 0
+	system.Inverse(system.Select(cond, system.Sub(i1, i2), 1))
+}
+
 // AssertIsBoolean adds an assertion in the constraint system (v == 0 ∥ v == 1)
 func (system *r1cs) AssertIsBoolean(i1 frontend.Variable) {
 
@@ -66,7 +90,7 @@ func (system *r1cs) AssertIsBoolean(i1 frontend.Variable) {
 
 	// ensure v * (1 - v) == 0
 	_v := system.Sub(1, v)
-	system.addConstraint(newR1C(v, _v, o), debug)
+	system.addConstraint(system.newR1C(v, _v, o), debug)
 }
 
 // AssertIsLessOrEqual adds assertion in constraint system  (v ⩽ bound)
@@ -88,6 +112,61 @@ func (system *r1cs) AssertIsLessOrEqual(_v frontend.Variable, bound frontend.Var
 
 }
 
+// AssertIsLess adds assertion in constraint system  (v < bound)
+//
+// bound can be a constant or a Variable
+func (system *r1cs) AssertIsLess(v frontend.Variable, bound frontend.Variable) {
+	if b, ok := system.ConstantValue(bound); ok {
+		if b.Sign() != 1 {
+			panic("AssertIsLess: bound must be strictly positive")
+		}
+		b.Sub(b, big.NewInt(1))
+		system.AssertIsLessOrEqual(v, b)
+		return
+	}
+	system.AssertIsLessOrEqual(v, bound)
+	system.AssertIsDifferent(v, bound)
+}
+
+// AssertIsInRange adds assertion in constraint system  (lower ⩽ v ⩽ upper)
+//
+// lower and upper can be constants or Variables
+func (system *r1cs) AssertIsInRange(v, lower, upper frontend.Variable) {
+	l, lOk := system.ConstantValue(lower)
+	u, uOk := system.ConstantValue(upper)
+	if lOk && l.Sign() == -1 || uOk && u.Sign() == -1 {
+		panic("AssertIsInRange: bounds must be positive")
+	}
+
+	if lOk && uOk {
+		if l.Cmp(u) == 1 {
+			panic("AssertIsInRange: lower bound must be smaller or equal to upper bound")
+		}
+		if c, ok := system.ConstantValue(v); ok {
+			if c.Cmp(l) == -1 || c.Cmp(u) == 1 {
+				panic(fmt.Sprintf("AssertIsInRange: %s is not in [%s, %s]", c.String(), l.String(), u.String()))
+			}
+			return
+		}
+		// as upper < modulus, v - lower ⩽ upper - lower iff lower ⩽ v ⩽ upper: the
+		// interval doesn't wrap around the modulus once shifted by lower
+		system.AssertIsLessOrEqual(system.Sub(v, l), new(big.Int).Sub(u, l))
+		return
+	}
+
+	if lOk {
+		if l.Sign() != 0 {
+			// v ⩾ lower iff v - lower ⩽ modulus - 1 - lower
+			bound := new(big.Int).Sub(system.CurveID.Info().Fr.Modulus(), big.NewInt(1))
+			bound.Sub(bound, l)
+			system.AssertIsLessOrEqual(system.Sub(v, l), bound)
+		}
+	} else {
+		system.AssertIsLessOrEqual(lower, v)
+	}
+	system.AssertIsLessOrEqual(v, upper)
+}
+
 func (system *r1cs) mustBeLessOrEqVar(a, bound compiled.LinearExpression) {
 	debug := system.AddDebugInfo("mustBeLessOrEq", a, " <= ", bound)
 
@@ -124,7 +203,7 @@ func (system *r1cs) mustBeLessOrEqVar(a, bound compiled.LinearExpression) {
 		// if bound[i] == 0, t must be 0 or 1, thus ai must be 0 or 1 too
 		system.MarkBoolean(aBits[i].(compiled.LinearExpression)) // this does not create a constraint
 
-		system.addConstraint(newR1C(l, aBits[i], zero), debug)
+		system.addConstraint(system.newR1C(l, aBits[i], zero), debug)
 	}
 
 }
@@ -144,10 +223,27 @@ func (system *r1cs) mustBeLessOrEqCst(a compiled.LinearExpression, bound big.Int
 	// debug info
 	debug := system.AddDebugInfo("mustBeLessOrEq", a, " <= ", system.toVariable(bound))
 
+	// a ⩽ bound implies that a fits on bound.BitLen() bits: as bound.BitLen() ⩽ nbBits,
+	// decomposing a on bound.BitLen() bits only is enough (and cheaper for small bounds)
+	nbBits = bound.BitLen()
+	if nbBits == 0 {
+		system.AssertIsEqual(a, 0)
+		return
+	}
+
 	// note that at this stage, we didn't boolean-constraint these new variables yet
 	// (as opposed to ToBinary)
 	aBits := bits.ToBinary(system, a, bits.WithNbDigits(nbBits), bits.WithUnconstrainedOutputs())
 
+	system.mustBitsBeLessOrEqCst(aBits, bound, debug)
+}
+
+// mustBitsBeLessOrEqCst asserts that the nbBits bits aBits encode an integer
+// smaller or equal to bound, and that they are boolean
+func (system *r1cs) mustBitsBeLessOrEqCst(aBits []frontend.Variable, bound big.Int, debug int) {
+
+	nbBits := len(aBits)
+
 	// t trailing bits in the bound
 	t := 0
 	for i := 0; i < nbBits; i++ {
@@ -175,7 +271,7 @@ func (system *r1cs) mustBeLessOrEqCst(a compiled.LinearExpression, bound big.Int
 			l := system.Sub(1, p[i+1])
 			l = system.Sub(l, aBits[i])
 
-			system.addConstraint(newR1C(l, aBits[i], system.toVariable(0)), debug)
+			system.addConstraint(system.newR1C(l, aBits[i], system.toVariable(0)), debug)
 			system.MarkBoolean(aBits[i].(compiled.LinearExpression))
 		} else {
 			system.AssertIsBoolean(aBits[i])
@@ -40,24 +40,44 @@ import (
 	bn254r1cs "github.com/consensys/gnark/internal/backend/bn254/cs"
 	bw6633r1cs "github.com/consensys/gnark/internal/backend/bw6-633/cs"
 	bw6761r1cs "github.com/consensys/gnark/internal/backend/bw6-761/cs"
+	"github.com/consensys/gnark/internal/profile"
 	"github.com/consensys/gnark/internal/utils"
 	"github.com/consensys/gnark/logger"
 )
 
 // NewBuilder returns a new R1CS compiler
 func NewBuilder(curve ecc.ID, config frontend.CompileConfig) (frontend.Builder, error) {
-	return newBuilder(curve, config), nil
+	system := newBuilder(curve, config)
+	if config.ConstraintStore != "" {
+		store, err := newConstraintStore(config.ConstraintStore)
+		if err != nil {
+			return nil, err
+		}
+		system.Constraints = nil
+		system.store = store
+	}
+	return system, nil
 }
 
 type r1cs struct {
 	compiled.ConstraintSystem
 	Constraints []compiled.R1C
 
+	// constraints held on disk while the circuit is defined, instead of in
+	// Constraints, if config.ConstraintStore is set
+	store *constraintStore
+
+	// deduplicates the linear expressions of the constraints
+	interner *compiled.Interner
+
 	st     cs.CoeffTable
 	config frontend.CompileConfig
 
 	// map for recording boolean constrained variables (to not constrain them twice)
 	mtBooleans map[uint64][]compiled.LinearExpression
+
+	// call stacks of the constraints, if config.Profile is set
+	profile *profile.Recorder
 }
 
 // initialCapacity has quite some impact on frontend performance, especially on large circuits size
@@ -72,6 +92,7 @@ func newBuilder(curveID ecc.ID, config frontend.CompileConfig) *r1cs {
 		Constraints: make([]compiled.R1C, 0, config.Capacity),
 		st:          cs.NewCoeffTable(),
 		mtBooleans:  make(map[uint64][]compiled.LinearExpression),
+		interner:    compiled.NewInterner(),
 		config:      config,
 	}
 
@@ -83,9 +104,22 @@ func newBuilder(curveID ecc.ID, config frontend.CompileConfig) *r1cs {
 
 	system.CurveID = curveID
 
+	if config.Profile != nil {
+		system.profile = profile.NewRecorder()
+	}
+
 	return &system
 }
 
+// AddDebugInfo records the debug info of the next constraint and returns its
+// id, or -1 if config.DiscardDebugInfo is set
+func (system *r1cs) AddDebugInfo(errName string, i ...interface{}) int {
+	if system.config.DiscardDebugInfo {
+		return -1
+	}
+	return system.ConstraintSystem.AddDebugInfo(errName, i...)
+}
+
 // newInternalVariable creates a new wire, appends it on the list of wires of the circuit, sets
 // the wire's id to the number of wires, and returns it
 func (system *r1cs) newInternalVariable() compiled.LinearExpression {
@@ -147,9 +181,9 @@ func (system *r1cs) reduce(l compiled.LinearExpression) compiled.LinearExpressio
 	return l
 }
 
-// newR1C clones the linear expression associated with the Variables (to avoid offseting the ID multiple time)
-// and return a R1C
-func newR1C(_l, _r, _o frontend.Variable) compiled.R1C {
+// newR1C interns the linear expression associated with the Variables (which copies
+// them, to avoid offseting the ID multiple time) and return a R1C
+func (system *r1cs) newR1C(_l, _r, _o frontend.Variable) compiled.R1C {
 	l := _l.(compiled.LinearExpression)
 	r := _r.(compiled.LinearExpression)
 	o := _o.(compiled.LinearExpression)
@@ -164,14 +198,33 @@ func newR1C(_l, _r, _o frontend.Variable) compiled.R1C {
 		l, r = r, l
 	}
 
-	return compiled.R1C{L: l.Clone(), R: r.Clone(), O: o.Clone()}
+	if system.store != nil {
+		// the store writes the constraint right away, and interns it when it reads it back
+		return compiled.R1C{L: l, R: r, O: o}
+	}
+	return compiled.R1C{L: system.interner.Intern(l), R: system.interner.Intern(r), O: system.interner.Intern(o)}
 }
 
 func (system *r1cs) addConstraint(r1c compiled.R1C, debugID ...int) {
-	system.Constraints = append(system.Constraints, r1c)
-	if len(debugID) > 0 {
-		system.MDebug[len(system.Constraints)-1] = debugID[0]
+	if system.store != nil {
+		system.store.add(r1c)
+	} else {
+		system.Constraints = append(system.Constraints, r1c)
+	}
+	if system.profile != nil {
+		system.profile.Record(system.Namespace())
 	}
+	if len(debugID) > 0 && debugID[0] >= 0 {
+		system.MDebug[system.nbConstraints()-1] = debugID[0]
+	}
+}
+
+// nbConstraints returns the number of constraints added to the system
+func (system *r1cs) nbConstraints() int {
+	if system.store != nil {
+		return system.store.nbConstraints
+	}
+	return len(system.Constraints)
 }
 
 // Term packs a Variable and a coeff in a Term and returns it.
@@ -347,9 +400,20 @@ func (cs *r1cs) Compile() (frontend.CompiledConstraintSystem, error) {
 	log := logger.Logger()
 	log.Info().
 		Str("curve", cs.CurveID.String()).
-		Int("nbConstraints", len(cs.Constraints)).
+		Int("nbConstraints", cs.nbConstraints()).
 		Msg("building constraint system")
 
+	// the constraints held on disk are read back in memory: the rest of the
+	// compilation, and the compiled system, need all of them
+	if cs.store != nil {
+		constraints, err := cs.store.readAll(cs.interner)
+		if err != nil {
+			return nil, err
+		}
+		cs.Constraints, cs.store = constraints, nil
+	}
+	cs.interner = nil
+
 	// ensure all inputs and hints are constrained
 	err := cs.checkVariables()
 	if err != nil {
@@ -375,7 +439,13 @@ func (cs *r1cs) Compile() (frontend.CompiledConstraintSystem, error) {
 	}
 
 	// build levels
-	res.Levels = buildLevels(res)
+	res.Levels, res.LevelsNbHints = buildLevels(res)
+
+	if cs.profile != nil {
+		if _, err := cs.profile.WriteTo(cs.config.Profile); err != nil {
+			return nil, fmt.Errorf("write profile: %w", err)
+		}
+	}
 
 	switch cs.CurveID {
 	case ecc.BLS12_377:
@@ -404,11 +474,14 @@ func (cs *r1cs) SetSchema(s *schema.Schema) {
 	cs.NbSecretVariables = s.NbSecret
 }
 
-func buildLevels(ccs compiled.R1CS) [][]int {
+// buildLevels returns the levels of the constraints of ccs, and the number of
+// constraints of each level which call a hint (nil if there are none)
+func buildLevels(ccs compiled.R1CS) ([][]int, []int) {
 
 	b := levelBuilder{
 		mWireToNode: make(map[int]int, ccs.NbInternalVariables), // at which node we resolved which wire
 		nodeLevels:  make([]int, len(ccs.Constraints)),          // level of a node
+		nodeHints:   make([]bool, len(ccs.Constraints)),         // hint called by a node
 		mLevels:     make(map[int]int),                          // level counts
 		ccs:         ccs,
 		nbInputs:    ccs.NbPublicVariables + ccs.NbSecretVariables,
@@ -436,11 +509,18 @@ func buildLevels(ccs compiled.R1CS) [][]int {
 		levels[i] = make([]int, 0, b.mLevels[i])
 	}
 
+	var nbHints []int
+	if len(ccs.MHints) != 0 {
+		nbHints = make([]int, len(levels))
+	}
 	for n, l := range b.nodeLevels {
 		levels[l] = append(levels[l], n)
+		if b.nodeHints[n] {
+			nbHints[l]++
+		}
 	}
 
-	return levels
+	return levels, nbHints
 }
 
 type levelBuilder struct {
@@ -449,6 +529,7 @@ type levelBuilder struct {
 
 	mWireToNode map[int]int // at which node we resolved which wire
 	nodeLevels  []int       // level per node
+	nodeHints   []bool      // true if the node calls a hint
 	mLevels     map[int]int // number of constraint per level
 
 	nodeLevel int // current level
@@ -490,6 +571,7 @@ func (b *levelBuilder) processLE(l compiled.LinearExpression, cID int) {
 			for _, hwid := range h.Wires {
 				b.mWireToNode[hwid] = cID
 			}
+			b.nodeHints[cID] = true
 			continue
 		}
 
@@ -565,9 +647,9 @@ func (system *r1cs) Tag(name string) frontend.Tag {
 	_, file, line, _ := runtime.Caller(1)
 
 	return frontend.Tag{
-		Name: fmt.Sprintf("%s[%s:%d]", name, filepath.Base(file), line),
+		Name: fmt.Sprintf("%s[%s:%d]", system.InNamespace(name), filepath.Base(file), line),
 		VID:  system.NbInternalVariables,
-		CID:  len(system.Constraints),
+		CID:  system.nbConstraints(),
 	}
 }
 
@@ -576,6 +658,7 @@ func (system *r1cs) AddCounter(from, to frontend.Tag) {
 	system.Counters = append(system.Counters, compiled.Counter{
 		From:          from.Name,
 		To:            to.Name,
+		CID:           from.CID,
 		NbVariables:   to.VID - from.VID,
 		NbConstraints: to.CID - from.CID,
 		CurveID:       system.CurveID,
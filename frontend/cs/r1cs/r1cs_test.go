@@ -17,6 +17,9 @@ limitations under the License.
 package r1cs
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
 	"sort"
 	"testing"
 
@@ -24,6 +27,7 @@ import (
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/compiled"
 	"github.com/consensys/gnark/frontend/schema"
+	bn254r1cs "github.com/consensys/gnark/internal/backend/bn254/cs"
 )
 
 func TestQuickSort(t *testing.T) {
@@ -71,3 +75,86 @@ func TestReduce(t *testing.T) {
 	}
 
 }
+
+type hintsCircuit struct {
+	X [64]frontend.Variable
+}
+
+func (circuit *hintsCircuit) Define(api frontend.API) error {
+	for i := range circuit.X {
+		bits := api.ToBinary(circuit.X[i], 8)
+		api.AssertIsEqual(api.FromBinary(bits...), circuit.X[i])
+	}
+	return nil
+}
+
+func TestLevelsNbHints(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254, NewBuilder, &hintsCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r1cs := ccs.(*bn254r1cs.R1CS)
+	if len(r1cs.LevelsNbHints) != len(r1cs.Levels) {
+		t.Fatalf("%d levels, %d numbers of hints", len(r1cs.Levels), len(r1cs.LevelsNbHints))
+	}
+	nbHints := 0
+	for l, n := range r1cs.LevelsNbHints {
+		if n > len(r1cs.Levels[l]) {
+			t.Fatalf("level %d: %d hints for %d constraints", l, n, len(r1cs.Levels[l]))
+		}
+		nbHints += n
+	}
+	if nbHints != len(hintsCircuit{}.X) {
+		t.Fatalf("expected %d constraints calling a hint, got %d", len(hintsCircuit{}.X), nbHints)
+	}
+
+	// the levels with hints are solved in parallel, in more tasks
+	var circuit hintsCircuit
+	for i := range circuit.X {
+		circuit.X[i] = 3 * i
+	}
+	witness, err := frontend.NewWitness(&circuit, ecc.BN254)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ccs.IsSolved(witness); err != nil {
+		t.Fatal(err)
+	}
+	circuit.X[10] = 300
+	witness, err = frontend.NewWitness(&circuit, ecc.BN254)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ccs.IsSolved(witness); err == nil {
+		t.Fatal("expected an unsatisfied constraint")
+	}
+}
+
+func TestConstraintStore(t *testing.T) {
+	expected, err := frontend.Compile(ecc.BN254, NewBuilder, &hintsCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	ccs, err := frontend.Compile(ecc.BN254, NewBuilder, &hintsCircuit{}, frontend.WithConstraintStore(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, ccs) {
+		t.Fatal("the constraints read from the store differ from the ones compiled in memory")
+	}
+
+	// the temporary file is removed
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Fatalf("%d files left in the store directory", len(files))
+	}
+
+	if _, err := frontend.Compile(ecc.BN254, NewBuilder, &hintsCircuit{}, frontend.WithConstraintStore(filepath.Join(dir, "missing"))); err == nil {
+		t.Fatal("expected an error for a missing store directory")
+	}
+}
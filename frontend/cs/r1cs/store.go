@@ -0,0 +1,141 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package r1cs
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/consensys/gnark/frontend/compiled"
+)
+
+// constraintStore holds the constraints of the builder in a temporary file while
+// the circuit is defined, see frontend.WithConstraintStore.
+//
+// A constraint is written as the lengths of its linear expressions L, R and O
+// (uvarints), followed by their terms (little endian uint64).
+type constraintStore struct {
+	f   *os.File
+	w   *bufio.Writer
+	buf []byte
+
+	nbConstraints int
+	nbTerms       int
+
+	// first error writing the file, returned by readAll
+	err error
+}
+
+// newConstraintStore returns a store writing the constraints to a new temporary
+// file in dir
+func newConstraintStore(dir string) (*constraintStore, error) {
+	f, err := os.CreateTemp(dir, "gnark-r1cs-*")
+	if err != nil {
+		return nil, fmt.Errorf("constraint store: %w", err)
+	}
+	// the file is removed right away where the system allows it, so that it doesn't
+	// outlive the process if the compilation fails
+	_ = os.Remove(f.Name())
+	return &constraintStore{f: f, w: bufio.NewWriterSize(f, 1<<20)}, nil
+}
+
+// add writes r1c to the store
+func (s *constraintStore) add(r1c compiled.R1C) {
+	s.nbConstraints++
+	if s.err != nil {
+		return
+	}
+	les := [3]compiled.LinearExpression{r1c.L, r1c.R, r1c.O}
+	n := 3 * binary.MaxVarintLen64
+	for _, l := range les {
+		n += 8 * len(l)
+	}
+	if cap(s.buf) < n {
+		s.buf = make([]byte, n)
+	}
+	buf := s.buf[:n]
+	offset := 0
+	for _, l := range les {
+		offset += binary.PutUvarint(buf[offset:], uint64(len(l)))
+	}
+	for _, l := range les {
+		for _, t := range l {
+			binary.LittleEndian.PutUint64(buf[offset:], uint64(t))
+			offset += 8
+		}
+		s.nbTerms += len(l)
+	}
+	_, s.err = s.w.Write(buf[:offset])
+}
+
+// readAll reads the constraints back, their linear expressions interned by
+// interner, and closes the store
+func (s *constraintStore) readAll(interner *compiled.Interner) ([]compiled.R1C, error) {
+	defer s.close()
+	if s.err != nil {
+		return nil, fmt.Errorf("constraint store: %w", s.err)
+	}
+	if err := s.w.Flush(); err != nil {
+		return nil, fmt.Errorf("constraint store: %w", err)
+	}
+	if _, err := s.f.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("constraint store: %w", err)
+	}
+	r := bufio.NewReaderSize(s.f, 1<<20)
+
+	constraints := make([]compiled.R1C, s.nbConstraints)
+	var buf [8]byte
+	var l compiled.LinearExpression
+	for i := range constraints {
+		var lengths [3]int
+		for j := range lengths {
+			n, err := binary.ReadUvarint(r)
+			if err != nil {
+				return nil, fmt.Errorf("constraint store: %w", err)
+			}
+			if n > uint64(s.nbTerms) {
+				return nil, fmt.Errorf("constraint store: invalid constraint %d", i)
+			}
+			lengths[j] = int(n)
+		}
+		var les [3]compiled.LinearExpression
+		for j, n := range lengths {
+			if cap(l) < n {
+				l = make(compiled.LinearExpression, n)
+			}
+			l = l[:n]
+			for k := range l {
+				if _, err := io.ReadFull(r, buf[:]); err != nil {
+					return nil, fmt.Errorf("constraint store: %w", err)
+				}
+				l[k] = compiled.Term(binary.LittleEndian.Uint64(buf[:]))
+			}
+			les[j] = interner.Intern(l)
+		}
+		constraints[i] = compiled.R1C{L: les[0], R: les[1], O: les[2]}
+	}
+	return constraints, nil
+}
+
+// close closes and removes the file of the store
+func (s *constraintStore) close() {
+	_ = s.f.Close()
+	_ = os.Remove(s.f.Name())
+}
@@ -22,11 +22,13 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"sort"
 	"strings"
 
 	"github.com/consensys/gnark/backend/hint"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/compiled"
+	"github.com/consensys/gnark/frontend/cs"
 	"github.com/consensys/gnark/frontend/schema"
 	"github.com/consensys/gnark/std/math/bits"
 )
@@ -164,6 +166,97 @@ func (system *scs) Inverse(i1 frontend.Variable) frontend.Variable {
 	return res
 }
 
+// BatchInvert returns res[i] = 1 / vs[i]
+//
+// the inverses of the variables are computed by the solver with a single hint (see hint.BatchInvert)
+// and each of them is checked with one constraint
+func (system *scs) BatchInvert(vs []frontend.Variable) []frontend.Variable {
+	res := make([]frontend.Variable, len(vs))
+	var toInvert []frontend.Variable
+	var indexes []int
+	for i := range vs {
+		if c, ok := system.ConstantValue(vs[i]); ok {
+			res[i] = system.Inverse(c)
+			continue
+		}
+		toInvert = append(toInvert, vs[i])
+		indexes = append(indexes, i)
+	}
+	if len(toInvert) == 0 {
+		return res
+	}
+
+	inv, err := system.NewHint(hint.BatchInvert, len(toInvert), toInvert...)
+	if err != nil {
+		// the function errs only if the number of inputs is invalid.
+		panic(err)
+	}
+	for j := range toInvert {
+		t := toInvert[j].(compiled.Term)
+		cr, _, _ := t.Unpack()
+		debug := system.AddDebugInfo("batchInvert", "1/", t, " < ∞")
+		system.addPlonkConstraint(inv[j].(compiled.Term), t, system.zero(), compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdOne, cr, compiled.CoeffIdZero, compiled.CoeffIdMinusOne, debug)
+		res[indexes[j]] = inv[j]
+	}
+	return res
+}
+
+// Exp returns res = base^exponent
+//
+// if exponent is a constant, res is computed by square-and-multiply on its bits,
+// otherwise exponent is decomposed on nbBits bits (system.BitLen() by default); if
+// nbBits >= system.BitLen(), the decomposition is the canonical one, bounded by modulus - 1
+func (system *scs) Exp(base, exponent frontend.Variable, nbBits ...int) frontend.Variable {
+	n := system.BitLen()
+	if len(nbBits) == 1 {
+		n = nbBits[0]
+		if n < 0 {
+			panic("invalid nbBits")
+		}
+	}
+
+	if e, ok := system.ConstantValue(exponent); ok {
+		if e.Sign() == -1 {
+			panic("Exp: exponent must be positive")
+		}
+		if e.BitLen() > n {
+			panic(fmt.Sprintf("Exp: exponent %s doesn't fit on %d bits", e.String(), n))
+		}
+		if b, ok := system.ConstantValue(base); ok {
+			modulus := system.CurveID.Info().Fr.Modulus()
+			b.Mod(b, modulus)
+			return b.Exp(b, e, modulus)
+		}
+
+		// left-to-right square and multiply, the first iterations are free
+		res := frontend.Variable(1)
+		for i := e.BitLen() - 1; i >= 0; i-- {
+			res = system.Mul(res, res)
+			if e.Bit(i) == 1 {
+				res = system.Mul(res, base)
+			}
+		}
+		return res
+	}
+
+	var eBits []frontend.Variable
+	if n >= system.BitLen() {
+		// exponent < modulus: with n bits, exponent + modulus would have a
+		// decomposition too, and base^(exponent + modulus) != base^exponent
+		eBits = system.toCanonicalBinary(exponent)
+		n = len(eBits)
+	} else {
+		// exponent < 2^n
+		eBits = system.ToBinary(exponent, n)
+	}
+	res := frontend.Variable(1)
+	for i := n - 1; i >= 0; i-- {
+		res = system.Mul(res, res)
+		res = system.Select(eBits[i], system.Mul(res, base), res)
+	}
+	return res
+}
+
 // ---------------------------------------------------------------------------------------------
 // Bit operations
 
@@ -190,9 +283,53 @@ func (system *scs) FromBinary(b ...frontend.Variable) frontend.Variable {
 	return bits.FromBinary(system, b)
 }
 
-// Xor returns a ^ b
+// Xor returns a ^ b ^ ... in, computed as a balanced tree of xors
+//
+// the inputs must be 0 or 1
+func (system *scs) Xor(a, b frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	return cs.BalancedTree(system.xor, append([]frontend.Variable{a, b}, in...))
+}
+
+// Or returns a | b | ... in, computed as a balanced tree of ors
+//
+// the inputs must be 0 or 1
+func (system *scs) Or(a, b frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	return cs.BalancedTree(system.or, append([]frontend.Variable{a, b}, in...))
+}
+
+// And returns a & b & ... in, computed as a balanced tree of ands
+//
+// the inputs must be 0 or 1
+func (system *scs) And(a, b frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	return cs.BalancedTree(system.and, append([]frontend.Variable{a, b}, in...))
+}
+
+// Nand returns ¬(a & b & ... in)
+//
+// the inputs must be 0 or 1
+func (system *scs) Nand(a, b frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	return system.not(system.And(a, b, in...))
+}
+
+// Nor returns ¬(a | b | ... in)
+//
+// the inputs must be 0 or 1
+func (system *scs) Nor(a, b frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	return system.not(system.Or(a, b, in...))
+}
+
+// not returns 1 - a, where a is 0 or 1
+func (system *scs) not(a frontend.Variable) frontend.Variable {
+	res := system.Sub(1, a)
+	if _, ok := system.ConstantValue(res); !ok {
+		system.MarkBoolean(res)
+	}
+	return res
+}
+
+// xor returns a ^ b
 // a and b must be 0 or 1
-func (system *scs) Xor(a, b frontend.Variable) frontend.Variable {
+func (system *scs) xor(a, b frontend.Variable) frontend.Variable {
 	_a, aConstant := system.ConstantValue(a)
 	_b, bConstant := system.ConstantValue(b)
 
@@ -209,10 +346,12 @@ func (system *scs) Xor(a, b frontend.Variable) frontend.Variable {
 	if bConstant {
 		l := a.(compiled.Term)
 		r := l
+		// res = a + b - 2ab, that is (2b - 1)a + res - b == 0
+		k := system.st.CoeffID(new(big.Int).Neg(_b))
 		one := big.NewInt(1)
 		_b.Lsh(_b, 1).Sub(_b, one)
 		idl := system.st.CoeffID(_b)
-		system.addPlonkConstraint(l, r, res, idl, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdOne, compiled.CoeffIdZero)
+		system.addPlonkConstraint(l, r, res, idl, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdOne, k)
 		return res
 	}
 	l := a.(compiled.Term)
@@ -221,9 +360,9 @@ func (system *scs) Xor(a, b frontend.Variable) frontend.Variable {
 	return res
 }
 
-// Or returns a | b
+// or returns a | b
 // a and b must be 0 or 1
-func (system *scs) Or(a, b frontend.Variable) frontend.Variable {
+func (system *scs) or(a, b frontend.Variable) frontend.Variable {
 	_a, aConstant := system.ConstantValue(a)
 	_b, bConstant := system.ConstantValue(b)
 
@@ -246,10 +385,12 @@ func (system *scs) Or(a, b frontend.Variable) frontend.Variable {
 		}
 		system.AssertIsBoolean(a)
 
+		// res = a + b - ab, that is (b - 1)a + res - b == 0
+		k := system.st.CoeffID(new(big.Int).Neg(_b))
 		one := big.NewInt(1)
 		_b.Sub(_b, one)
 		idl := system.st.CoeffID(_b)
-		system.addPlonkConstraint(l, r, res, idl, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdOne, compiled.CoeffIdZero)
+		system.addPlonkConstraint(l, r, res, idl, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdOne, k)
 		return res
 	}
 	l := a.(compiled.Term)
@@ -260,9 +401,9 @@ func (system *scs) Or(a, b frontend.Variable) frontend.Variable {
 	return res
 }
 
-// Or returns a & b
+// and returns a & b
 // a and b must be 0 or 1
-func (system *scs) And(a, b frontend.Variable) frontend.Variable {
+func (system *scs) and(a, b frontend.Variable) frontend.Variable {
 	system.AssertIsBoolean(a)
 	system.AssertIsBoolean(b)
 	return system.Mul(a, b)
@@ -348,13 +489,89 @@ func (system *scs) Lookup2(b0, b1 frontend.Variable, i0, i1, i2, i3 frontend.Var
 
 }
 
+// LookupN returns values[i], where i is the little endian integer of the bits
+// b, see frontend.API.LookupN
+func (system *scs) LookupN(b []frontend.Variable, values []frontend.Variable) frontend.Variable {
+	return cs.LookupN(system, b, values)
+}
+
+// Lookup returns t.Values[index] and asserts that index < len(t.Values), with
+// a single lookup constraint
+func (system *scs) Lookup(t *frontend.Table, index frontend.Variable) frontend.Variable {
+	if err := t.Check(); err != nil {
+		panic(err)
+	}
+	tID := system.tableID(t)
+
+	if c, ok := system.ConstantValue(index); ok {
+		if !c.IsUint64() || c.Uint64() >= uint64(len(t.Values)) {
+			panic(fmt.Sprintf("table %s: index %s out of range", t.Name, c.String()))
+		}
+		return new(big.Int).Set(&system.st.Coeffs[system.tables[tID].Values[c.Uint64()]])
+	}
+
+	debug := system.AddDebugInfo("lookup", t.Name, "[", index, "]")
+
+	// the lookup acts on the wires themselves: l holds the index, r the table
+	// index and o the result
+	l := system.toWire(index)
+	l.SetCoeffID(compiled.CoeffIdZero)
+	r := system.tableWire(tID)
+	r.SetCoeffID(compiled.CoeffIdZero)
+	res := system.newInternalVariable()
+	o := res
+	o.SetCoeffID(compiled.CoeffIdZero)
+
+	system.addConstraint(compiled.SparseR1C{L: l, R: r, O: o, K: compiled.CoeffIdZero, Table: tID + 1}, debug)
+
+	return res
+}
+
+// tableID returns the index of t in system.tables, registering it if needed
+func (system *scs) tableID(t *frontend.Table) int {
+	// the values of a table already looked up are not compared again, as it
+	// is costly for large tables
+	if id, ok := system.mTablePtrs[t]; ok {
+		return id
+	}
+	values := make([]int, len(t.Values))
+	for i, v := range t.Values {
+		var c big.Int
+		c.Mod(v, system.CurveID.Info().Fr.Modulus())
+		values[i] = system.st.CoeffID(&c)
+	}
+
+	if id, ok := system.mTables[t.Name]; ok {
+		if !reflect.DeepEqual(system.tables[id].Values, values) {
+			panic(fmt.Sprintf("table %s previously registered with different values", t.Name))
+		}
+		system.mTablePtrs[t] = id
+		return id
+	}
+	id := len(system.tables)
+	system.tables = append(system.tables, compiled.Table{Name: t.Name, Values: values})
+	system.mTables[t.Name] = id
+	system.mTablePtrs[t] = id
+	return id
+}
+
+// tableWire returns a wire holding the table index tID
+func (system *scs) tableWire(tID int) compiled.Term {
+	if w, ok := system.tableWires[tID]; ok {
+		return w
+	}
+	w := system.toWire(tID)
+	system.tableWires[tID] = w
+	return w
+}
+
 // IsZero returns 1 if a is zero, 0 otherwise
 func (system *scs) IsZero(i1 frontend.Variable) frontend.Variable {
 	if a, ok := system.ConstantValue(i1); ok {
-		if !(a.IsUint64() && a.Uint64() == 0) {
-			panic("input should be zero")
+		if a.IsUint64() && a.Uint64() == 0 {
+			return 1
 		}
-		return 1
+		return 0
 	}
 
 	//m * (1 - m) = 0       // constrain m to be 0 or 1
@@ -375,29 +592,112 @@ func (system *scs) IsZero(i1 frontend.Variable) frontend.Variable {
 }
 
 // Cmp returns 1 if i1>i2, 0 if i1=i2, -1 if i1<i2
+//
+// i1 and i2 are compared as integers in [0, modulus)
 func (system *scs) Cmp(i1, i2 frontend.Variable) frontend.Variable {
 
-	bi1 := system.ToBinary(i1, system.BitLen())
-	bi2 := system.ToBinary(i2, system.BitLen())
+	bi1 := system.toCanonicalBinary(i1)
+	bi2 := system.toCanonicalBinary(i2)
 
 	var res frontend.Variable
 	res = 0
 
 	for i := system.BitLen() - 1; i >= 0; i-- {
+		// res is in {-1, 0, 1}, so 1 - res² == 1 iff res == 0: res is set to
+		// bi1[i] - bi2[i] on the most significant bit where i1 and i2 differ
+		d := system.Sub(bi1[i], bi2[i])
+		res = system.Add(res, system.Mul(system.Sub(1, system.Mul(res, res)), d))
+	}
+	return res
+}
 
-		iszeroi1 := system.IsZero(bi1[i])
-		iszeroi2 := system.IsZero(bi2[i])
+// IsLess returns 1 if i1 < i2, 0 otherwise
+//
+// i1 and i2 are compared as integers in [0, modulus)
+func (system *scs) IsLess(i1, i2 frontend.Variable) frontend.Variable {
+	modulus := system.CurveID.Info().Fr.Modulus()
+	if c, ok := system.ConstantValue(i2); ok {
+		return system.isLessCst(i1, c.Mod(c, modulus))
+	}
+	if c, ok := system.ConstantValue(i1); ok {
+		// c < i2 iff i2 ⩾ c + 1
+		c.Mod(c, modulus).Add(c, big.NewInt(1))
+		return system.Sub(1, system.isLessCst(i2, c))
+	}
 
-		i1i2 := system.And(bi1[i], iszeroi2)
-		i2i1 := system.And(bi2[i], iszeroi1)
+	// c is in {-1, 0, 1}, and c(c-1)/2 == 1 iff c == -1
+	c := system.Cmp(i1, i2)
+	return system.DivUnchecked(system.Mul(c, system.Sub(c, 1)), 2)
+}
 
-		n := system.Select(i2i1, -1, 0)
-		m := system.Select(i1i2, 1, n)
+// isLessCst returns 1 if a < bound, 0 otherwise, a being compared as an
+// integer in [0, modulus) to the non negative constant bound. The result is
+// sound only on the canonical decomposition of a, but its bits are compared to
+// bound on the bit length of bound only, instead of the full width of Cmp.
+func (system *scs) isLessCst(a frontend.Variable, bound *big.Int) frontend.Variable {
+	if bound.Sign() != 1 {
+		return 0
+	}
+	if bound.Cmp(system.CurveID.Info().Fr.Modulus()) != -1 {
+		return 1
+	}
+	if c, ok := system.ConstantValue(a); ok {
+		if c.Mod(c, system.CurveID.Info().Fr.Modulus()).Cmp(bound) == -1 {
+			return 1
+		}
+		return 0
+	}
+	return cs.IsLessBitsCst(system, system.toCanonicalBinary(a), bound)
+}
+
+// IsInRange returns 1 if lower ⩽ v ⩽ upper, 0 otherwise
+//
+// v, lower and upper are compared as integers in [0, modulus)
+func (system *scs) IsInRange(v, lower, upper frontend.Variable) frontend.Variable {
+	l, lOk := system.ConstantValue(lower)
+	u, uOk := system.ConstantValue(upper)
+	if lOk && uOk {
+		if l.Sign() == -1 || u.Sign() == -1 {
+			panic("IsInRange: bounds must be positive")
+		}
+		if l.Cmp(u) == 1 {
+			return 0
+		}
+		// as in AssertIsInRange, lower ⩽ v ⩽ upper iff v - lower < upper - lower + 1,
+		// which is compared on the bit length of upper - lower + 1
+		bound := new(big.Int).Sub(u, l)
+		bound.Add(bound, big.NewInt(1))
+		if bound.Cmp(system.CurveID.Info().Fr.Modulus()) != -1 {
+			return 1
+		}
+		return system.isLessCst(system.Sub(v, l), bound)
+	}
 
-		res = system.Select(system.IsZero(res), m, res)
+	// (v ⩾ lower) ∧ (v ⩽ upper)
+	ge := system.Sub(1, system.IsLess(v, lower))
+	le := system.Sub(1, system.IsLess(upper, v))
+	return system.Mul(ge, le)
+}
 
+// toCanonicalBinary returns the system.BitLen() bits of a, bounded by modulus - 1 so that
+// they are the canonical decomposition of a
+func (system *scs) toCanonicalBinary(a frontend.Variable) []frontend.Variable {
+	nbBits := system.BitLen()
+	if c, ok := system.ConstantValue(a); ok {
+		return system.ToBinary(c, nbBits)
 	}
-	return res
+
+	debug := system.AddDebugInfo("toCanonicalBinary", a)
+
+	// note that at this stage, we didn't boolean-constraint these new variables yet
+	// (as opposed to ToBinary)
+	aBits := bits.ToBinary(system, a, bits.WithNbDigits(nbBits), bits.WithUnconstrainedOutputs())
+
+	var bound big.Int
+	bound.Sub(system.CurveID.Info().Fr.Modulus(), big.NewInt(1))
+	system.mustBitsBeLessOrEqCst(aBits, bound, debug)
+
+	return aBits
 }
 
 // Println behaves like fmt.Println but accepts Variable as parameter
@@ -430,7 +730,7 @@ func (system *scs) Println(a ...frontend.Variable) {
 			log.ToResolve = append(log.ToResolve, v)
 			log.ToResolve = append(log.ToResolve, compiled.TermDelimitor)
 		} else {
-			printArg(&log, &sbb, arg)
+			printArg(&log, &sbb, arg, "%v")
 		}
 	}
 
@@ -440,7 +740,78 @@ func (system *scs) Println(a ...frontend.Variable) {
 	system.Logs = append(system.Logs, log)
 }
 
-func printArg(log *compiled.LogEntry, sbb *strings.Builder, a frontend.Variable) {
+// Printf enables circuit debugging and behaves almost like fmt.Printf()
+//
+// the verbs applied to variables are resolved when the R1CS.Solve() method is called
+func (system *scs) Printf(format string, a ...frontend.Variable) {
+	system.printf(nil, format, a)
+}
+
+// PrintfIf behaves like Printf, but the line is printed only if condition == 1
+// when the R1CS.Solve() method is called
+func (system *scs) PrintfIf(condition frontend.Variable, format string, a ...frontend.Variable) {
+	if c, ok := system.ConstantValue(condition); ok {
+		if !(c.IsUint64() && c.Uint64() == 1) {
+			return
+		}
+		system.printf(nil, format, a)
+		return
+	}
+	system.printf([]compiled.Term{condition.(compiled.Term)}, format, a)
+}
+
+func (system *scs) printf(condition []compiled.Term, format string, a []frontend.Variable) {
+	log := compiled.LogEntry{Condition: condition}
+
+	// prefix log line with file.go:line of the caller of Printf or PrintfIf
+	if _, file, line, ok := runtime.Caller(2); ok {
+		log.Caller = fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	}
+
+	var sbb strings.Builder
+	printVariable := func(arg frontend.Variable, verb string) {
+		if v, ok := arg.(compiled.Term); ok {
+			sbb.WriteString(verb)
+			log.ToResolve = append(log.ToResolve, compiled.TermDelimitor)
+			log.ToResolve = append(log.ToResolve, v)
+			log.ToResolve = append(log.ToResolve, compiled.TermDelimitor)
+		} else {
+			printArg(&log, &sbb, arg, verb)
+		}
+	}
+
+	texts, verbs := compiled.SplitFormat(format)
+	for i, verb := range verbs {
+		sbb.WriteString(texts[i])
+		if i < len(a) {
+			printVariable(a[i], verb)
+		} else {
+			// fmt.Sprintf reports the missing argument
+			sbb.WriteString(verb)
+		}
+	}
+	sbb.WriteString(texts[len(verbs)])
+
+	// like fmt.Sprintf, report the extra arguments
+	if len(a) > len(verbs) {
+		sbb.WriteString("%%!(EXTRA ")
+		for i := len(verbs); i < len(a); i++ {
+			if i > len(verbs) {
+				sbb.WriteString(", ")
+			}
+			printVariable(a[i], "%v")
+		}
+		sbb.WriteByte(')')
+	}
+
+	log.Format = sbb.String()
+
+	system.Logs = append(system.Logs, log)
+}
+
+// printArg writes a with the given verb in the format of log. If a is a structure,
+// each of its variables is printed with the verb.
+func printArg(log *compiled.LogEntry, sbb *strings.Builder, a frontend.Variable, verb string) {
 
 	count := 0
 	counter := func(visibility schema.Visibility, name string, tValue reflect.Value) error {
@@ -452,7 +823,7 @@ func printArg(log *compiled.LogEntry, sbb *strings.Builder, a frontend.Variable)
 
 	// no variables in nested struct, we use fmt std print function
 	if count == 0 {
-		sbb.WriteString(fmt.Sprint(a))
+		sbb.WriteString(strings.ReplaceAll(fmt.Sprintf(verb, a), "%", "%%"))
 		return
 	}
 
@@ -461,7 +832,7 @@ func printArg(log *compiled.LogEntry, sbb *strings.Builder, a frontend.Variable)
 		count--
 		sbb.WriteString(name)
 		sbb.WriteString(": ")
-		sbb.WriteString("%s")
+		sbb.WriteString(verb)
 		if count != 0 {
 			sbb.WriteString(", ")
 		}
@@ -479,6 +850,60 @@ func printArg(log *compiled.LogEntry, sbb *strings.Builder, a frontend.Variable)
 	sbb.WriteByte('}')
 }
 
+// Commit returns a commitment to the variables v (see frontend.API.Commit)
+//
+// The commitment is the output of a hint, that the PlonK prover computes from a KZG
+// commitment to the committed wires. Each committed wire w is held by a constraint
+// w - w == 0, that the PlonK setup turns into π₂ - w == 0, where π₂ is the polynomial
+// committed to by the prover.
+func (system *scs) Commit(v ...frontend.Variable) frontend.Variable {
+	if system.Commitment.Is() {
+		panic("a circuit can commit only once")
+	}
+
+	mCommitted := make(map[int]compiled.Term, len(v))
+	for _, vv := range v {
+		if _, ok := system.ConstantValue(vv); ok {
+			continue
+		}
+		t := system.toWire(vv)
+		mCommitted[t.WireID()] = t
+	}
+	if len(mCommitted) == 0 {
+		panic("commit: no variable to commit to")
+	}
+	committed := make([]int, 0, len(mCommitted))
+	for w := range mCommitted {
+		committed = append(committed, w)
+	}
+	sort.Ints(committed)
+
+	inputs := make([]frontend.Variable, len(committed))
+	committedConstraints := make([]int, len(committed))
+	for i, w := range committed {
+		t := mCommitted[w]
+		inputs[i] = t
+		committedConstraints[i] = len(system.Constraints)
+		system.addPlonkConstraint(t, t, system.zero(), compiled.CoeffIdOne, compiled.CoeffIdMinusOne, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdZero)
+	}
+	res, err := system.NewHint(compiled.CommitmentHint, 1, inputs...)
+	if err != nil {
+		panic(err)
+	}
+	c := res[0].(compiled.Term)
+	commitmentConstraint := len(system.Constraints)
+	system.addPlonkConstraint(c, c, system.zero(), compiled.CoeffIdOne, compiled.CoeffIdMinusOne, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdZero)
+
+	system.Commitment = compiled.Commitment{
+		Committed:            committed,
+		Wire:                 c.WireID(),
+		HintID:               hint.UUID(compiled.CommitmentHint),
+		CommittedConstraints: committedConstraints,
+		CommitmentConstraint: commitmentConstraint,
+	}
+	return c
+}
+
 func (system *scs) Compiler() frontend.Compiler {
 	return system
 }
@@ -19,6 +19,7 @@ package scs
 import (
 	"fmt"
 	"math/big"
+	"reflect"
 
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/compiled"
@@ -67,6 +68,39 @@ func (system *scs) AssertIsDifferent(i1, i2 frontend.Variable) {
 	system.Inverse(system.Sub(i1, i2))
 }
 
+// AssertIsEqualIf fails if cond != 0 and i1 != i2
+func (system *scs) AssertIsEqualIf(cond, i1, i2 frontend.Variable) {
+	if c, ok := system.ConstantValue(cond); ok {
+		if c.Sign() != 0 {
+			system.AssertIsEqual(i1, i2)
+		}
+		return
+	}
+
+	d := system.Sub(i1, i2)
+	if c, ok := system.ConstantValue(d); ok {
+		if c.Sign() != 0 {
+			system.AssertIsEqual(cond, 0)
+		}
+		return
+	}
+
+	// cond * (i1 - i2) == 0
+	l := cond.(compiled.Term)
+	r := d.(compiled.Term)
+	lc, _, _ := l.Unpack()
+	rc, _, _ := r.Unpack()
+
+	debug := system.AddDebugInfo("assertIsEqualIf", l, " * ", r, " == 0")
+	system.addPlonkConstraint(l, r, system.zero(), compiled.CoeffIdZero, compiled.CoeffIdZero, lc, rc, compiled.CoeffIdZero, compiled.CoeffIdZero, debug)
+}
+
+// AssertIsDifferentIf fails if cond == 1 and i1 == i2
+func (system *scs) AssertIsDifferentIf(cond, i1, i2 frontend.Variable) {
+	// i1 - i2 is invertible if cond == 1, and 1 is if cond == 0
+	system.Inverse(system.Select(cond, system.Sub(i1, i2), 1))
+}
+
 // AssertIsBoolean fails if v != 0 ∥ v != 1
 func (system *scs) AssertIsBoolean(i1 frontend.Variable) {
 	if c, ok := system.ConstantValue(i1); ok {
@@ -99,6 +133,61 @@ func (system *scs) AssertIsLessOrEqual(v frontend.Variable, bound frontend.Varia
 	}
 }
 
+// AssertIsLess adds assertion in constraint system  (v < bound)
+//
+// bound can be a constant or a Variable
+func (system *scs) AssertIsLess(v frontend.Variable, bound frontend.Variable) {
+	if b, ok := system.ConstantValue(bound); ok {
+		if b.Sign() != 1 {
+			panic("AssertIsLess: bound must be strictly positive")
+		}
+		b.Sub(b, big.NewInt(1))
+		system.AssertIsLessOrEqual(v, b)
+		return
+	}
+	system.AssertIsLessOrEqual(v, bound)
+	system.AssertIsDifferent(v, bound)
+}
+
+// AssertIsInRange adds assertion in constraint system  (lower ⩽ v ⩽ upper)
+//
+// lower and upper can be constants or Variables
+func (system *scs) AssertIsInRange(v, lower, upper frontend.Variable) {
+	l, lOk := system.ConstantValue(lower)
+	u, uOk := system.ConstantValue(upper)
+	if lOk && l.Sign() == -1 || uOk && u.Sign() == -1 {
+		panic("AssertIsInRange: bounds must be positive")
+	}
+
+	if lOk && uOk {
+		if l.Cmp(u) == 1 {
+			panic("AssertIsInRange: lower bound must be smaller or equal to upper bound")
+		}
+		if c, ok := system.ConstantValue(v); ok {
+			if c.Cmp(l) == -1 || c.Cmp(u) == 1 {
+				panic(fmt.Sprintf("AssertIsInRange: %s is not in [%s, %s]", c.String(), l.String(), u.String()))
+			}
+			return
+		}
+		// as upper < modulus, v - lower ⩽ upper - lower iff lower ⩽ v ⩽ upper: the
+		// interval doesn't wrap around the modulus once shifted by lower
+		system.AssertIsLessOrEqual(system.Sub(v, l), new(big.Int).Sub(u, l))
+		return
+	}
+
+	if lOk {
+		if l.Sign() != 0 {
+			// v ⩾ lower iff v - lower ⩽ modulus - 1 - lower
+			bound := new(big.Int).Sub(system.CurveID.Info().Fr.Modulus(), big.NewInt(1))
+			bound.Sub(bound, l)
+			system.AssertIsLessOrEqual(system.Sub(v, l), bound)
+		}
+	} else {
+		system.AssertIsLessOrEqual(lower, v)
+	}
+	system.AssertIsLessOrEqual(v, upper)
+}
+
 func (system *scs) mustBeLessOrEqVar(a compiled.Term, bound compiled.Term) {
 
 	debug := system.AddDebugInfo("mustBeLessOrEq", a, " <= ", bound)
@@ -161,10 +250,27 @@ func (system *scs) mustBeLessOrEqCst(a compiled.Term, bound big.Int) {
 	// debug info
 	debug := system.AddDebugInfo("mustBeLessOrEq", a, " <= ", bound)
 
+	// a ⩽ bound implies that a fits on bound.BitLen() bits: as bound.BitLen() ⩽ nbBits,
+	// decomposing a on bound.BitLen() bits only is enough (and cheaper for small bounds)
+	nbBits = bound.BitLen()
+	if nbBits == 0 {
+		system.AssertIsEqual(a, 0)
+		return
+	}
+
 	// note that at this stage, we didn't boolean-constraint these new variables yet
 	// (as opposed to ToBinary)
 	aBits := bits.ToBinary(system, a, bits.WithNbDigits(nbBits), bits.WithUnconstrainedOutputs())
 
+	system.mustBitsBeLessOrEqCst(aBits, bound, debug)
+}
+
+// mustBitsBeLessOrEqCst asserts that the nbBits bits aBits encode an integer
+// smaller or equal to bound, and that they are boolean
+func (system *scs) mustBitsBeLessOrEqCst(aBits []frontend.Variable, bound big.Int, debug int) {
+
+	nbBits := len(aBits)
+
 	// t trailing bits in the bound
 	t := 0
 	for i := 0; i < nbBits; i++ {
@@ -211,3 +317,61 @@ func (system *scs) mustBeLessOrEqCst(a compiled.Term, bound big.Int) {
 	}
 
 }
+
+// AssertGate adds the constraint g(l, r, o) == 0 as a single custom gate constraint
+func (system *scs) AssertGate(g *frontend.Gate, l, r, o frontend.Variable) {
+	if err := g.Check(); err != nil {
+		panic(err)
+	}
+	gID := system.gateID(g)
+
+	debug := system.AddDebugInfo("assertGate", g.Name, "(", l, ", ", r, ", ", o, ") == 0")
+
+	// the gate acts on the wires themselves, so constants and scaled terms are
+	// first copied in new wires
+	_l, _r, _o := system.toWire(l), system.toWire(r), system.toWire(o)
+	_l.SetCoeffID(compiled.CoeffIdZero)
+	_r.SetCoeffID(compiled.CoeffIdZero)
+	_o.SetCoeffID(compiled.CoeffIdZero)
+
+	system.addConstraint(compiled.SparseR1C{L: _l, R: _r, O: _o, K: compiled.CoeffIdZero, Gate: gID + 1}, debug)
+}
+
+// gateID returns the index of g in system.gates, registering it if needed
+func (system *scs) gateID(g *frontend.Gate) int {
+	terms := make([]compiled.GateTerm, len(g.Terms))
+	for i, t := range g.Terms {
+		var c big.Int
+		c.Mod(t.Coeff, system.CurveID.Info().Fr.Modulus())
+		terms[i] = compiled.GateTerm{CoeffID: system.st.CoeffID(&c), L: t.L, R: t.R, O: t.O}
+	}
+
+	if id, ok := system.mGates[g.Name]; ok {
+		if !reflect.DeepEqual(system.gates[id].Terms, terms) {
+			panic(fmt.Sprintf("gate %s previously registered with different terms", g.Name))
+		}
+		return id
+	}
+	id := len(system.gates)
+	system.gates = append(system.gates, compiled.Gate{Name: g.Name, Terms: terms})
+	system.mGates[g.Name] = id
+	return id
+}
+
+// toWire returns a term with coefficient one whose wire holds the value of v
+func (system *scs) toWire(v frontend.Variable) compiled.Term {
+	if c, ok := system.ConstantValue(v); ok {
+		o := system.newInternalVariable()
+		k := system.st.CoeffID(c)
+		system.addPlonkConstraint(system.zero(), system.zero(), o, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdMinusOne, k)
+		return o
+	}
+	t := v.(compiled.Term)
+	cID, _, _ := t.Unpack()
+	if cID == compiled.CoeffIdOne {
+		return t
+	}
+	o := system.newInternalVariable()
+	system.addPlonkConstraint(t, system.zero(), o, cID, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdMinusOne, compiled.CoeffIdZero)
+	return o
+}
@@ -40,6 +40,7 @@ import (
 	bn254r1cs "github.com/consensys/gnark/internal/backend/bn254/cs"
 	bw6633r1cs "github.com/consensys/gnark/internal/backend/bw6-633/cs"
 	bw6761r1cs "github.com/consensys/gnark/internal/backend/bw6-761/cs"
+	"github.com/consensys/gnark/internal/profile"
 	"github.com/consensys/gnark/internal/utils"
 	"github.com/consensys/gnark/logger"
 )
@@ -57,6 +58,19 @@ type scs struct {
 
 	// map for recording boolean constrained variables (to not constrain them twice)
 	mtBooleans map[int]struct{}
+
+	// custom gates, and map from gate name to gate index
+	gates  []compiled.Gate
+	mGates map[string]int
+
+	// lookup tables, map from table name (and address) to table index, and wires holding the table indexes
+	tables     []compiled.Table
+	mTables    map[string]int
+	mTablePtrs map[*frontend.Table]int
+	tableWires map[int]compiled.Term
+
+	// call stacks of the constraints, if config.Profile is set
+	profile *profile.Recorder
 }
 
 // initialCapacity has quite some impact on frontend performance, especially on large circuits size
@@ -69,6 +83,10 @@ func newBuilder(curveID ecc.ID, config frontend.CompileConfig) *scs {
 			MHintsDependencies: make(map[hint.ID]string),
 		},
 		mtBooleans:  make(map[int]struct{}),
+		mGates:      make(map[string]int),
+		mTables:     make(map[string]int),
+		mTablePtrs:  make(map[*frontend.Table]int),
+		tableWires:  make(map[int]compiled.Term),
 		Constraints: make([]compiled.SparseR1C, 0, config.Capacity),
 		st:          cs.NewCoeffTable(),
 		config:      config,
@@ -79,17 +97,17 @@ func newBuilder(curveID ecc.ID, config frontend.CompileConfig) *scs {
 
 	system.CurveID = curveID
 
+	if config.Profile != nil {
+		system.profile = profile.NewRecorder()
+	}
+
 	return &system
 }
 
 // addPlonkConstraint creates a constraint of the for al+br+clr+k=0
-//func (system *SparseR1CS) addPlonkConstraint(l, r, o frontend.Variable, cidl, cidr, cidm1, cidm2, cido, k int, debugID ...int) {
+// func (system *SparseR1CS) addPlonkConstraint(l, r, o frontend.Variable, cidl, cidr, cidm1, cidm2, cido, k int, debugID ...int) {
 func (system *scs) addPlonkConstraint(l, r, o compiled.Term, cidl, cidr, cidm1, cidm2, cido, k int, debugID ...int) {
 
-	if len(debugID) > 0 {
-		system.MDebug[len(system.Constraints)] = debugID[0]
-	}
-
 	l.SetCoeffID(cidl)
 	r.SetCoeffID(cidr)
 	o.SetCoeffID(cido)
@@ -100,7 +118,27 @@ func (system *scs) addPlonkConstraint(l, r, o compiled.Term, cidl, cidr, cidm1,
 	v.SetCoeffID(cidm2)
 
 	//system.Constraints = append(system.Constraints, compiled.SparseR1C{L: _l, R: _r, O: _o, M: [2]compiled.Term{u, v}, K: k})
-	system.Constraints = append(system.Constraints, compiled.SparseR1C{L: l, R: r, O: o, M: [2]compiled.Term{u, v}, K: k})
+	system.addConstraint(compiled.SparseR1C{L: l, R: r, O: o, M: [2]compiled.Term{u, v}, K: k}, debugID...)
+}
+
+// addConstraint appends c to the constraints
+func (system *scs) addConstraint(c compiled.SparseR1C, debugID ...int) {
+	if len(debugID) > 0 && debugID[0] >= 0 {
+		system.MDebug[len(system.Constraints)] = debugID[0]
+	}
+	system.Constraints = append(system.Constraints, c)
+	if system.profile != nil {
+		system.profile.Record(system.Namespace())
+	}
+}
+
+// AddDebugInfo records the debug info of the next constraint and returns its
+// id, or -1 if config.DiscardDebugInfo is set
+func (system *scs) AddDebugInfo(errName string, i ...interface{}) int {
+	if system.config.DiscardDebugInfo {
+		return -1
+	}
+	return system.ConstraintSystem.AddDebugInfo(errName, i...)
 }
 
 // newInternalVariable creates a new wire, appends it on the list of wires of the circuit, sets
@@ -204,13 +242,15 @@ func (system *scs) checkVariables() error {
 
 	mHintsConstrained := make(map[int]bool)
 
+	var noCoeff bool
+
 	// for each constraint, we check the terms and mark our inputs / hints as constrained
 	processTerm := func(t compiled.Term) {
 
 		// L and M[0] handles the same wire but with a different coeff
 		visibility := t.VariableVisibility()
 		vID := t.WireID()
-		if t.CoeffID() != compiled.CoeffIdZero {
+		if t.CoeffID() != compiled.CoeffIdZero || noCoeff {
 			switch visibility {
 			case schema.Public:
 				if !publicConstrained[vID] {
@@ -237,6 +277,8 @@ func (system *scs) checkVariables() error {
 
 	}
 	for _, c := range system.Constraints {
+		// custom gates and lookups reference their wires with a zero coefficient
+		noCoeff = c.Gate != 0 || c.Table != 0
 		processTerm(c.L)
 		processTerm(c.R)
 		processTerm(c.M[0])
@@ -313,6 +355,8 @@ func (cs *scs) Compile() (frontend.CompiledConstraintSystem, error) {
 	res := compiled.SparseR1CS{
 		ConstraintSystem: cs.ConstraintSystem,
 		Constraints:      cs.Constraints,
+		Gates:            cs.gates,
+		Tables:           cs.tables,
 	}
 	// sanity check
 	if res.NbPublicVariables != len(cs.Public) || res.NbPublicVariables != cs.Schema.NbPublic {
@@ -323,7 +367,13 @@ func (cs *scs) Compile() (frontend.CompiledConstraintSystem, error) {
 	}
 
 	// build levels
-	res.Levels = buildLevels(res)
+	res.Levels, res.LevelsNbHints = buildLevels(res)
+
+	if cs.profile != nil {
+		if _, err := cs.profile.WriteTo(cs.config.Profile); err != nil {
+			return nil, fmt.Errorf("write profile: %w", err)
+		}
+	}
 
 	switch cs.CurveID {
 	case ecc.BLS12_377:
@@ -353,11 +403,14 @@ func (cs *scs) SetSchema(s *schema.Schema) {
 	cs.NbSecretVariables = s.NbSecret
 }
 
-func buildLevels(ccs compiled.SparseR1CS) [][]int {
+// buildLevels returns the levels of the constraints of ccs, and the number of
+// constraints of each level which call a hint (nil if there are none)
+func buildLevels(ccs compiled.SparseR1CS) ([][]int, []int) {
 
 	b := levelBuilder{
 		mWireToNode: make(map[int]int, ccs.NbInternalVariables), // at which node we resolved which wire
 		nodeLevels:  make([]int, len(ccs.Constraints)),          // level of a node
+		nodeHints:   make([]bool, len(ccs.Constraints)),         // hint called by a node
 		mLevels:     make(map[int]int),                          // level counts
 		ccs:         ccs,
 		nbInputs:    ccs.NbPublicVariables + ccs.NbSecretVariables,
@@ -386,11 +439,18 @@ func buildLevels(ccs compiled.SparseR1CS) [][]int {
 		levels[i] = make([]int, 0, b.mLevels[i])
 	}
 
+	var nbHints []int
+	if len(ccs.MHints) != 0 {
+		nbHints = make([]int, len(levels))
+	}
 	for n, l := range b.nodeLevels {
 		levels[l] = append(levels[l], n)
+		if b.nodeHints[n] {
+			nbHints[l]++
+		}
 	}
 
-	return levels
+	return levels, nbHints
 }
 
 type levelBuilder struct {
@@ -399,6 +459,7 @@ type levelBuilder struct {
 
 	mWireToNode map[int]int // at which node we resolved which wire
 	nodeLevels  []int       // level per node
+	nodeHints   []bool      // true if the node calls a hint
 	mLevels     map[int]int // number of constraint per level
 
 	nodeLevel int // current level
@@ -440,6 +501,7 @@ func (b *levelBuilder) processTerm(t compiled.Term, cID int) {
 		for _, hwid := range h.Wires {
 			b.mWireToNode[hwid] = cID
 		}
+		b.nodeHints[cID] = true
 
 		return
 	}
@@ -471,7 +533,7 @@ func (system *scs) Tag(name string) frontend.Tag {
 	_, file, line, _ := runtime.Caller(1)
 
 	return frontend.Tag{
-		Name: fmt.Sprintf("%s[%s:%d]", name, filepath.Base(file), line),
+		Name: fmt.Sprintf("%s[%s:%d]", system.InNamespace(name), filepath.Base(file), line),
 		VID:  system.NbInternalVariables,
 		CID:  len(system.Constraints),
 	}
@@ -484,6 +546,7 @@ func (system *scs) AddCounter(from, to frontend.Tag) {
 	system.Counters = append(system.Counters, compiled.Counter{
 		From:          from.Name,
 		To:            to.Name,
+		CID:           from.CID,
 		NbVariables:   to.VID - from.VID,
 		NbConstraints: to.CID - from.CID,
 		CurveID:       system.CurveID,
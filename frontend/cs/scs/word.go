@@ -0,0 +1,172 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scs
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs"
+)
+
+// The words are split in little-endian bytes, each checked with a lookup in
+// wordTables.byte. The bitwise operations are then lookups in tables of 65536
+// entries indexed by pairs of bytes: xor.Values[256⋅a+b] = a ^ b and
+// and.Values[256⋅a+b] = a & b.
+var (
+	wordTables struct {
+		byte, xor, and frontend.Table
+	}
+	wordTablesOnce sync.Once
+)
+
+func initWordTables() {
+	wordTablesOnce.Do(func() {
+		t := &wordTables
+		t.byte.Name, t.xor.Name, t.and.Name = "word_byte", "word_xor", "word_and"
+		t.byte.Values = make([]*big.Int, 256)
+		for i := range t.byte.Values {
+			t.byte.Values[i] = big.NewInt(int64(i))
+		}
+		t.xor.Values = make([]*big.Int, 256*256)
+		t.and.Values = make([]*big.Int, 256*256)
+		for a := 0; a < 256; a++ {
+			for b := 0; b < 256; b++ {
+				t.xor.Values[256*a+b] = t.byte.Values[a^b]
+				t.and.Values[256*a+b] = t.byte.Values[a&b]
+			}
+		}
+	})
+}
+
+// WordXor returns a ^ b for words of nbBits bits, with a lookup per pair of bytes
+func (system *scs) WordXor(a, b frontend.Variable, nbBits int) frontend.Variable {
+	initWordTables()
+	return system.wordBitwise(&wordTables.xor, a, b, nbBits)
+}
+
+// WordAnd returns a & b for words of nbBits bits, with a lookup per pair of bytes
+func (system *scs) WordAnd(a, b frontend.Variable, nbBits int) frontend.Variable {
+	initWordTables()
+	return system.wordBitwise(&wordTables.and, a, b, nbBits)
+}
+
+// WordRotateLeft returns the word a of nbBits bits rotated left by k bits.
+//
+// A rotation by a multiple of 8 bits permutes the bytes of a. Otherwise a is
+// split as hi⋅2ⁿ⁻ᵏ + lo, with hi and lo range checked with byte lookups, and
+// the result is lo⋅2ᵏ + hi.
+func (system *scs) WordRotateLeft(a frontend.Variable, k, nbBits int) frontend.Variable {
+	cs.CheckWordSize(nbBits)
+	initWordTables()
+	k = cs.RotationOffset(k, nbBits)
+
+	if c, ok := system.ConstantValue(a); ok {
+		checkWordConstant(c, nbBits)
+		mask := new(big.Int).Lsh(big.NewInt(1), uint(nbBits))
+		mask.Sub(mask, big.NewInt(1))
+		res := new(big.Int).Lsh(c, uint(k))
+		res.Or(res, new(big.Int).Rsh(c, uint(nbBits-k)))
+		return res.And(res, mask)
+	}
+
+	if k%8 == 0 {
+		aBytes := system.wordBytes(a, nbBits)
+		rotated := make([]frontend.Variable, len(aBytes))
+		for i := range aBytes {
+			rotated[(i+k/8)%len(aBytes)] = aBytes[i]
+		}
+		return system.packBytes(rotated)
+	}
+
+	limbs, err := system.NewHint(hint.Limbs, 2, a, nbBits-k)
+	if err != nil {
+		panic(err)
+	}
+	lo, hi := limbs[0], limbs[1]
+	system.wordBytes(lo, nbBits-k)
+	system.wordBytes(hi, k)
+	system.AssertIsEqual(system.Add(system.Mul(hi, new(big.Int).Lsh(big.NewInt(1), uint(nbBits-k))), lo), a)
+
+	return system.Add(system.Mul(lo, new(big.Int).Lsh(big.NewInt(1), uint(k))), hi)
+}
+
+// wordBitwise returns the word whose bytes are t.Values[256⋅a[i]+b[i]], where
+// a[i] and b[i] are the bytes of a and b
+func (system *scs) wordBitwise(t *frontend.Table, a, b frontend.Variable, nbBits int) frontend.Variable {
+	cs.CheckWordSize(nbBits)
+	aBytes := system.wordBytes(a, nbBits)
+	bBytes := system.wordBytes(b, nbBits)
+	res := make([]frontend.Variable, len(aBytes))
+	for i := range res {
+		res[i] = system.Lookup(t, system.Add(system.Mul(aBytes[i], 256), bBytes[i]))
+	}
+	return system.packBytes(res)
+}
+
+// wordBytes returns the ⌈nbBits/8⌉ little-endian bytes of v, and asserts that
+// v < 2^nbBits: the bytes are looked up in wordTables.byte, and the most
+// significant one, shifted by the missing bits, too.
+func (system *scs) wordBytes(v frontend.Variable, nbBits int) []frontend.Variable {
+	nbBytes := (nbBits + 7) / 8
+
+	if c, ok := system.ConstantValue(v); ok {
+		checkWordConstant(c, nbBits)
+		res := make([]frontend.Variable, nbBytes)
+		for i := range res {
+			res[i] = new(big.Int).And(new(big.Int).Rsh(c, uint(8*i)), big.NewInt(0xff))
+		}
+		return res
+	}
+
+	res, err := system.NewHint(hint.Limbs, nbBytes, v, 8)
+	if err != nil {
+		panic(err)
+	}
+	for i := range res {
+		system.Lookup(&wordTables.byte, res[i])
+	}
+	// the most significant byte is in [0, 256), shifting it by the missing bits
+	// can't overflow
+	if r := nbBits % 8; r != 0 {
+		system.Lookup(&wordTables.byte, system.Mul(res[nbBytes-1], 1<<(8-r)))
+	}
+	system.AssertIsEqual(system.packBytes(res), v)
+
+	return res
+}
+
+// packBytes returns Σ b[i]⋅256ⁱ
+func (system *scs) packBytes(b []frontend.Variable) frontend.Variable {
+	var res frontend.Variable = 0
+	coeff := big.NewInt(1)
+	for i := range b {
+		res = system.Add(res, system.Mul(b[i], coeff))
+		coeff = new(big.Int).Lsh(coeff, 8)
+	}
+	return res
+}
+
+// checkWordConstant panics if the constant c doesn't fit in nbBits
+func checkWordConstant(c *big.Int, nbBits int) {
+	if c.BitLen() > nbBits {
+		panic(fmt.Sprintf("constant %s doesn't fit in a word of %d bits", c.String(), nbBits))
+	}
+}
@@ -0,0 +1,22 @@
+package cs
+
+import "github.com/consensys/gnark/frontend"
+
+// BalancedTree reduces vs (len(vs) ⩾ 1) with the associative binary operation op, applied
+// along a balanced binary tree: vs[0] op vs[1] op ... op vs[n-1] is computed with n-1
+// operations, and a depth of ⌈log₂(n)⌉ operations.
+func BalancedTree(op func(a, b frontend.Variable) frontend.Variable, vs []frontend.Variable) frontend.Variable {
+	level := make([]frontend.Variable, len(vs))
+	copy(level, vs)
+	for len(level) > 1 {
+		next := level[:0]
+		for i := 0; i+1 < len(level); i += 2 {
+			next = append(next, op(level[i], level[i+1]))
+		}
+		if len(level)%2 == 1 {
+			next = append(next, level[len(level)-1])
+		}
+		level = next
+	}
+	return level[0]
+}
@@ -0,0 +1,50 @@
+package cs
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// CheckWordSize panics if nbBits is not a supported word size (8, 16 or 32 bits)
+func CheckWordSize(nbBits int) {
+	if nbBits != 8 && nbBits != 16 && nbBits != 32 {
+		panic(fmt.Sprintf("invalid word size %d, expected 8, 16 or 32 bits", nbBits))
+	}
+}
+
+// RotationOffset returns k mod nbBits, in [0, nbBits)
+func RotationOffset(k, nbBits int) int {
+	k %= nbBits
+	if k < 0 {
+		k += nbBits
+	}
+	return k
+}
+
+// WordBitwise returns the nbBits-bit word whose bits are op(a[i], b[i]), where
+// a[i] and b[i] are the bits of a and b given by api.ToBinary, which asserts
+// that the words fit in nbBits.
+func WordBitwise(api frontend.API, op func(a, b frontend.Variable, in ...frontend.Variable) frontend.Variable, a, b frontend.Variable, nbBits int) frontend.Variable {
+	CheckWordSize(nbBits)
+	aBits := api.ToBinary(a, nbBits)
+	bBits := api.ToBinary(b, nbBits)
+	for i := range aBits {
+		aBits[i] = op(aBits[i], bBits[i])
+	}
+	return api.FromBinary(aBits...)
+}
+
+// WordRotateLeft returns the nbBits-bit word a rotated left by k bits (right if
+// k < 0), by permuting the bits given by api.ToBinary, which asserts that a
+// fits in nbBits.
+func WordRotateLeft(api frontend.API, a frontend.Variable, k, nbBits int) frontend.Variable {
+	CheckWordSize(nbBits)
+	k = RotationOffset(k, nbBits)
+	aBits := api.ToBinary(a, nbBits)
+	rotated := make([]frontend.Variable, nbBits)
+	for i := range aBits {
+		rotated[(i+k)%nbBits] = aBits[i]
+	}
+	return api.FromBinary(rotated...)
+}
@@ -0,0 +1,110 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// MaxGateDegree is the maximum total degree of a custom gate in l, r, o
+//
+// The PlonK quotient polynomial is split in 3 parts, which bounds the degree of
+// the gate equations it can hold.
+const MaxGateDegree = 3
+
+// Gate describes a custom gate G(l, r, o) = Σᵢ cᵢ⋅lᵃⁱ⋅rᵇⁱ⋅oᶜⁱ, enforced by
+// AssertGate as G(l, r, o) == 0.
+//
+// For example, l XOR r == o (l, r boolean) is the gate l + r - 2⋅l⋅r - o.
+//
+// Gates are identified by their Name in a circuit; two different gates must
+// not share the same name.
+type Gate struct {
+	Name  string
+	Terms []GateTerm
+}
+
+// GateTerm is a monomial Coeff⋅lᴸ⋅rᴿ⋅oᴼ of a custom gate
+type GateTerm struct {
+	Coeff   *big.Int
+	L, R, O int // exponents of l, r and o
+}
+
+// GateAPI is implemented by builders which compile custom gates natively
+// (PlonK, where each gate gets its own selector polynomial).
+type GateAPI interface {
+	// AssertGate adds the constraint g(l, r, o) == 0
+	AssertGate(g *Gate, l, r, o Variable)
+}
+
+// AssertGate adds the constraint g(l, r, o) == 0.
+//
+// If api implements GateAPI, the gate is compiled as a single custom gate constraint,
+// otherwise it is expanded with the arithmetic operations of api.
+func AssertGate(api API, g *Gate, l, r, o Variable) {
+	if err := g.Check(); err != nil {
+		panic(err)
+	}
+	if gAPI, ok := api.(GateAPI); ok {
+		gAPI.AssertGate(g, l, r, o)
+		return
+	}
+	api.AssertIsEqual(g.Evaluate(api, l, r, o), 0)
+}
+
+// Check returns an error if g is not a well formed custom gate
+func (g *Gate) Check() error {
+	if g.Name == "" {
+		return errors.New("gate: missing name")
+	}
+	if len(g.Terms) == 0 {
+		return fmt.Errorf("gate %s: no terms", g.Name)
+	}
+	for _, t := range g.Terms {
+		if t.Coeff == nil {
+			return fmt.Errorf("gate %s: missing coefficient", g.Name)
+		}
+		if t.L < 0 || t.R < 0 || t.O < 0 {
+			return fmt.Errorf("gate %s: negative exponent", g.Name)
+		}
+		if t.L+t.R+t.O > MaxGateDegree {
+			return fmt.Errorf("gate %s: degree %d exceeds %d", g.Name, t.L+t.R+t.O, MaxGateDegree)
+		}
+	}
+	return nil
+}
+
+// Evaluate returns g(l, r, o), computed with the arithmetic operations of api
+func (g *Gate) Evaluate(api API, l, r, o Variable) Variable {
+	var res Variable = 0
+	for _, t := range g.Terms {
+		var m Variable = t.Coeff
+		for i := 0; i < t.L; i++ {
+			m = api.Mul(m, l)
+		}
+		for i := 0; i < t.R; i++ {
+			m = api.Mul(m, r)
+		}
+		for i := 0; i < t.O; i++ {
+			m = api.Mul(m, o)
+		}
+		res = api.Add(res, m)
+	}
+	return res
+}
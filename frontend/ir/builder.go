@@ -0,0 +1,621 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ir
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/compiled"
+	"github.com/consensys/gnark/frontend/cs"
+	"github.com/consensys/gnark/frontend/schema"
+	"github.com/consensys/gnark/internal/utils"
+)
+
+// Build builds the intermediate representation of circuit, calling
+// circuit.Define once.
+func Build(curveID ecc.ID, circuit frontend.Circuit) (*Circuit, error) {
+	b := newBuilder(curveID)
+	if err := frontend.Parse(b, circuit); err != nil {
+		return nil, fmt.Errorf("parse circuit: %w", err)
+	}
+	return b.c, nil
+}
+
+// wire is a variable of the builder: the index of a wire of the circuit
+type wire int
+
+// builder records the calls to frontend.API in a Circuit
+type builder struct {
+	c       *Circuit
+	modulus *big.Int
+	nbWires int
+	nbTags  int
+
+	booleans  map[wire]struct{} // wires known to be boolean
+	mHints    map[hint.ID]int   // index of the hints in c.Hints
+	mGates    map[string]int    // index of the gates in c.Gates
+	committed bool
+}
+
+func newBuilder(curveID ecc.ID) *builder {
+	return &builder{
+		c:        &Circuit{CurveID: curveID, hintFunctions: make(map[hint.ID]hint.Function)},
+		modulus:  curveID.Info().Fr.Modulus(),
+		booleans: make(map[wire]struct{}),
+		mHints:   make(map[hint.ID]int),
+		mGates:   make(map[string]int),
+	}
+}
+
+// record appends the instruction op(inputs) to the circuit and returns its
+// nbOutputs output wires
+func (b *builder) record(op Op, nbOutputs int, inputs []frontend.Variable, args ...int) []frontend.Variable {
+	inst := Instruction{Op: op, NbOutputs: nbOutputs, Args: args}
+	inst.Inputs = make([]Operand, len(inputs))
+	for i := range inputs {
+		inst.Inputs[i] = b.operand(inputs[i])
+	}
+	b.c.Instructions = append(b.c.Instructions, inst)
+
+	res := make([]frontend.Variable, nbOutputs)
+	for i := range res {
+		res[i] = wire(b.nbWires)
+		b.nbWires++
+	}
+	return res
+}
+
+// recordNamed appends an instruction without inputs nor outputs
+func (b *builder) recordNamed(op Op, name string, args ...int) {
+	b.c.Instructions = append(b.c.Instructions, Instruction{Op: op, Name: name, Args: args})
+}
+
+func (b *builder) operand(v frontend.Variable) Operand {
+	if w, ok := v.(wire); ok {
+		return Operand{Kind: OperandWire, Wire: int(w)}
+	}
+	c, _ := b.constantValue(v)
+	return Operand{Kind: OperandConstant, Constant: c.Bytes()}
+}
+
+// constantValue returns the value of v reduced modulo the modulus of the
+// scalar field, and true if v is a constant
+func (b *builder) constantValue(v frontend.Variable) (*big.Int, bool) {
+	if _, ok := v.(wire); ok {
+		return nil, false
+	}
+	c := utils.FromInterface(v)
+	return c.Mod(&c, b.modulus), true
+}
+
+// constantValues returns the values of vs if they are all constants
+func (b *builder) constantValues(vs ...frontend.Variable) ([]*big.Int, bool) {
+	res := make([]*big.Int, len(vs))
+	for i := range vs {
+		c, ok := b.constantValue(vs[i])
+		if !ok {
+			return nil, false
+		}
+		res[i] = c
+	}
+	return res, true
+}
+
+func (b *builder) isConstantBool(v frontend.Variable) (bool, bool) {
+	c, ok := b.constantValue(v)
+	if !ok {
+		return false, false
+	}
+	if !c.IsUint64() || c.Uint64() > 1 {
+		panic(fmt.Sprintf("%s is not boolean", c.String()))
+	}
+	return c.Uint64() == 1, true
+}
+
+func boolToInt(b bool) *big.Int {
+	if b {
+		return big.NewInt(1)
+	}
+	return big.NewInt(0)
+}
+
+func (b *builder) Add(i1, i2 frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	vs := append([]frontend.Variable{i1, i2}, in...)
+	if c, ok := b.constantValues(vs...); ok {
+		res := new(big.Int)
+		for i := range c {
+			res.Add(res, c[i])
+		}
+		return res.Mod(res, b.modulus)
+	}
+	return b.record(OpAdd, 1, vs)[0]
+}
+
+func (b *builder) Neg(i1 frontend.Variable) frontend.Variable {
+	if c, ok := b.constantValue(i1); ok {
+		return c.Neg(c).Mod(c, b.modulus)
+	}
+	return b.record(OpNeg, 1, []frontend.Variable{i1})[0]
+}
+
+func (b *builder) Sub(i1, i2 frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	vs := append([]frontend.Variable{i1, i2}, in...)
+	if c, ok := b.constantValues(vs...); ok {
+		res := new(big.Int).Set(c[0])
+		for i := 1; i < len(c); i++ {
+			res.Sub(res, c[i])
+		}
+		return res.Mod(res, b.modulus)
+	}
+	return b.record(OpSub, 1, vs)[0]
+}
+
+func (b *builder) Mul(i1, i2 frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	vs := append([]frontend.Variable{i1, i2}, in...)
+	if c, ok := b.constantValues(vs...); ok {
+		res := big.NewInt(1)
+		for i := range c {
+			res.Mul(res, c[i]).Mod(res, b.modulus)
+		}
+		return res
+	}
+	return b.record(OpMul, 1, vs)[0]
+}
+
+func (b *builder) DivUnchecked(i1, i2 frontend.Variable) frontend.Variable {
+	if c, ok := b.constantValues(i1, i2); ok {
+		if c[0].Sign() == 0 && c[1].Sign() == 0 {
+			return c[0]
+		}
+		return b.div(c[0], c[1])
+	}
+	return b.record(OpDivUnchecked, 1, []frontend.Variable{i1, i2})[0]
+}
+
+func (b *builder) Div(i1, i2 frontend.Variable) frontend.Variable {
+	if c, ok := b.constantValues(i1, i2); ok {
+		return b.div(c[0], c[1])
+	}
+	return b.record(OpDiv, 1, []frontend.Variable{i1, i2})[0]
+}
+
+func (b *builder) div(c1, c2 *big.Int) *big.Int {
+	if c2.Sign() == 0 {
+		panic("division by zero")
+	}
+	res := new(big.Int).ModInverse(c2, b.modulus)
+	return res.Mul(res, c1).Mod(res, b.modulus)
+}
+
+func (b *builder) Inverse(i1 frontend.Variable) frontend.Variable {
+	if c, ok := b.constantValue(i1); ok {
+		return b.div(big.NewInt(1), c)
+	}
+	return b.record(OpInverse, 1, []frontend.Variable{i1})[0]
+}
+
+func (b *builder) BatchInvert(vs []frontend.Variable) []frontend.Variable {
+	if c, ok := b.constantValues(vs...); ok {
+		res := make([]frontend.Variable, len(c))
+		for i := range c {
+			res[i] = b.div(big.NewInt(1), c[i])
+		}
+		return res
+	}
+	return b.record(OpBatchInvert, len(vs), vs)
+}
+
+func (b *builder) Exp(base, exponent frontend.Variable, nbBits ...int) frontend.Variable {
+	n := b.c.CurveID.Info().Fr.Bits
+	if len(nbBits) == 1 {
+		n = nbBits[0]
+		if n < 0 {
+			panic("invalid nbBits")
+		}
+	}
+	if c, ok := b.constantValues(base, exponent); ok {
+		if c[1].BitLen() > n {
+			panic(fmt.Sprintf("Exp: exponent %s doesn't fit on %d bits", c[1].String(), n))
+		}
+		return c[0].Exp(c[0], c[1], b.modulus)
+	}
+	return b.record(OpExp, 1, []frontend.Variable{base, exponent}, n)[0]
+}
+
+func (b *builder) ToBinary(i1 frontend.Variable, n ...int) []frontend.Variable {
+	nbBits := b.c.CurveID.Info().Fr.Bits
+	if len(n) == 1 {
+		nbBits = n[0]
+		if nbBits < 0 {
+			panic("invalid n")
+		}
+	}
+
+	if c, ok := b.constantValue(i1); ok {
+		if c.BitLen() > nbBits {
+			panic(fmt.Sprintf("[ToBinary] decomposing %s (bitLen == %d) with %d bits", c.String(), c.BitLen(), nbBits))
+		}
+		res := make([]frontend.Variable, nbBits)
+		for i := range res {
+			res[i] = big.NewInt(int64(c.Bit(i)))
+		}
+		return res
+	}
+
+	res := b.record(OpToBinary, nbBits, []frontend.Variable{i1}, nbBits)
+	for i := range res {
+		b.booleans[res[i].(wire)] = struct{}{}
+	}
+	return res
+}
+
+func (b *builder) FromBinary(bits ...frontend.Variable) frontend.Variable {
+	if c, ok := b.constantValues(bits...); ok {
+		res := new(big.Int)
+		for i := len(c) - 1; i >= 0; i-- {
+			res.Lsh(res, 1).Add(res, c[i])
+		}
+		return res.Mod(res, b.modulus)
+	}
+	return b.record(OpFromBinary, 1, bits)[0]
+}
+
+func (b *builder) Xor(i1, i2 frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	return b.boolean(OpXor, (*big.Int).Xor, append([]frontend.Variable{i1, i2}, in...))
+}
+
+func (b *builder) Or(i1, i2 frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	return b.boolean(OpOr, (*big.Int).Or, append([]frontend.Variable{i1, i2}, in...))
+}
+
+func (b *builder) And(i1, i2 frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	return b.boolean(OpAnd, (*big.Int).And, append([]frontend.Variable{i1, i2}, in...))
+}
+
+func (b *builder) Nand(i1, i2 frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	vs := append([]frontend.Variable{i1, i2}, in...)
+	if c, ok := b.constantValues(vs...); ok {
+		return new(big.Int).Sub(big.NewInt(1), fold((*big.Int).And, c))
+	}
+	return b.record(OpNand, 1, vs)[0]
+}
+
+func (b *builder) Nor(i1, i2 frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	vs := append([]frontend.Variable{i1, i2}, in...)
+	if c, ok := b.constantValues(vs...); ok {
+		return new(big.Int).Sub(big.NewInt(1), fold((*big.Int).Or, c))
+	}
+	return b.record(OpNor, 1, vs)[0]
+}
+
+func (b *builder) WordXor(x, y frontend.Variable, nbBits int) frontend.Variable {
+	if _, ok := b.constantValues(x, y); ok {
+		return cs.WordBitwise(b, b.Xor, x, y, nbBits)
+	}
+	cs.CheckWordSize(nbBits)
+	return b.record(OpWordXor, 1, []frontend.Variable{x, y}, nbBits)[0]
+}
+
+func (b *builder) WordAnd(x, y frontend.Variable, nbBits int) frontend.Variable {
+	if _, ok := b.constantValues(x, y); ok {
+		return cs.WordBitwise(b, b.And, x, y, nbBits)
+	}
+	cs.CheckWordSize(nbBits)
+	return b.record(OpWordAnd, 1, []frontend.Variable{x, y}, nbBits)[0]
+}
+
+func (b *builder) WordRotateLeft(x frontend.Variable, k, nbBits int) frontend.Variable {
+	if _, ok := b.constantValue(x); ok {
+		return cs.WordRotateLeft(b, x, k, nbBits)
+	}
+	cs.CheckWordSize(nbBits)
+	return b.record(OpWordRotateLeft, 1, []frontend.Variable{x}, k, nbBits)[0]
+}
+
+// boolean records the boolean operation op on vs, or computes it if vs are constants
+func (b *builder) boolean(op Op, f func(z, x, y *big.Int) *big.Int, vs []frontend.Variable) frontend.Variable {
+	if c, ok := b.constantValues(vs...); ok {
+		return fold(f, c)
+	}
+	return b.record(op, 1, vs)[0]
+}
+
+// fold returns c[0] f c[1] f ... c[n-1]
+func fold(f func(z, x, y *big.Int) *big.Int, c []*big.Int) *big.Int {
+	res := new(big.Int).Set(c[0])
+	for i := 1; i < len(c); i++ {
+		f(res, res, c[i])
+	}
+	return res
+}
+
+func (b *builder) Select(cond frontend.Variable, i1, i2 frontend.Variable) frontend.Variable {
+	if c, ok := b.isConstantBool(cond); ok {
+		if c {
+			return i1
+		}
+		return i2
+	}
+	return b.record(OpSelect, 1, []frontend.Variable{cond, i1, i2})[0]
+}
+
+func (b *builder) Lookup2(b0, b1 frontend.Variable, i0, i1, i2, i3 frontend.Variable) frontend.Variable {
+	c0, ok0 := b.isConstantBool(b0)
+	c1, ok1 := b.isConstantBool(b1)
+	if ok0 && ok1 {
+		switch {
+		case c0 && c1:
+			return i3
+		case c1:
+			return i2
+		case c0:
+			return i1
+		default:
+			return i0
+		}
+	}
+	return b.record(OpLookup2, 1, []frontend.Variable{b0, b1, i0, i1, i2, i3})[0]
+}
+
+func (b *builder) LookupN(bits []frontend.Variable, values []frontend.Variable) frontend.Variable {
+	return cs.LookupN(b, bits, values)
+}
+
+func (b *builder) IsZero(i1 frontend.Variable) frontend.Variable {
+	if c, ok := b.constantValue(i1); ok {
+		return boolToInt(c.Sign() == 0)
+	}
+	return b.record(OpIsZero, 1, []frontend.Variable{i1})[0]
+}
+
+func (b *builder) Cmp(i1, i2 frontend.Variable) frontend.Variable {
+	if c, ok := b.constantValues(i1, i2); ok {
+		return new(big.Int).Mod(big.NewInt(int64(c[0].Cmp(c[1]))), b.modulus)
+	}
+	return b.record(OpCmp, 1, []frontend.Variable{i1, i2})[0]
+}
+
+func (b *builder) IsLess(i1, i2 frontend.Variable) frontend.Variable {
+	if c, ok := b.constantValues(i1, i2); ok {
+		return boolToInt(c[0].Cmp(c[1]) < 0)
+	}
+	return b.record(OpIsLess, 1, []frontend.Variable{i1, i2})[0]
+}
+
+func (b *builder) IsInRange(v, lower, upper frontend.Variable) frontend.Variable {
+	if c, ok := b.constantValues(v, lower, upper); ok {
+		return boolToInt(c[0].Cmp(c[1]) >= 0 && c[0].Cmp(c[2]) <= 0)
+	}
+	return b.record(OpIsInRange, 1, []frontend.Variable{v, lower, upper})[0]
+}
+
+func (b *builder) Commit(v ...frontend.Variable) frontend.Variable {
+	if b.committed {
+		panic("a circuit can commit only once")
+	}
+	b.committed = true
+	return b.record(OpCommit, 1, v)[0]
+}
+
+func (b *builder) AssertIsEqual(i1, i2 frontend.Variable) {
+	b.record(OpAssertIsEqual, 0, []frontend.Variable{i1, i2})
+}
+
+func (b *builder) AssertIsDifferent(i1, i2 frontend.Variable) {
+	b.record(OpAssertIsDifferent, 0, []frontend.Variable{i1, i2})
+}
+
+func (b *builder) AssertIsEqualIf(cond, i1, i2 frontend.Variable) {
+	if c, ok := b.constantValue(cond); ok {
+		if c.Sign() != 0 {
+			b.AssertIsEqual(i1, i2)
+		}
+		return
+	}
+	b.record(OpAssertIsEqualIf, 0, []frontend.Variable{cond, i1, i2})
+}
+
+func (b *builder) AssertIsDifferentIf(cond, i1, i2 frontend.Variable) {
+	if c, ok := b.isConstantBool(cond); ok {
+		if c {
+			b.AssertIsDifferent(i1, i2)
+		}
+		return
+	}
+	b.record(OpAssertIsDifferentIf, 0, []frontend.Variable{cond, i1, i2})
+}
+
+func (b *builder) AssertIsBoolean(i1 frontend.Variable) {
+	if w, ok := i1.(wire); ok {
+		b.booleans[w] = struct{}{}
+	}
+	b.record(OpAssertIsBoolean, 0, []frontend.Variable{i1})
+}
+
+func (b *builder) AssertIsLessOrEqual(v frontend.Variable, bound frontend.Variable) {
+	b.record(OpAssertIsLessOrEqual, 0, []frontend.Variable{v, bound})
+}
+
+func (b *builder) AssertIsLess(v frontend.Variable, bound frontend.Variable) {
+	b.record(OpAssertIsLess, 0, []frontend.Variable{v, bound})
+}
+
+func (b *builder) AssertIsInRange(v, lower, upper frontend.Variable) {
+	b.record(OpAssertIsInRange, 0, []frontend.Variable{v, lower, upper})
+}
+
+// Println records the variables of a; the other arguments are formatted when
+// building the circuit.
+func (b *builder) Println(a ...frontend.Variable) {
+	inst := Instruction{Op: OpPrintln, Inputs: make([]Operand, len(a))}
+	for i := range a {
+		if w, ok := a[i].(wire); ok {
+			inst.Inputs[i] = Operand{Kind: OperandWire, Wire: int(w)}
+		} else {
+			inst.Inputs[i] = Operand{Kind: OperandText, Text: fmt.Sprint(a[i])}
+		}
+	}
+	b.c.Instructions = append(b.c.Instructions, inst)
+}
+
+// Printf records an unconditional PrintfIf
+func (b *builder) Printf(format string, a ...frontend.Variable) {
+	b.PrintfIf(1, format, a...)
+}
+
+// PrintfIf records the condition and the variables of a; the other arguments
+// are formatted in the format string when building the circuit.
+func (b *builder) PrintfIf(condition frontend.Variable, format string, a ...frontend.Variable) {
+	inst := Instruction{Op: OpPrintf, Inputs: []Operand{b.operand(condition)}}
+
+	var sbb strings.Builder
+	texts, verbs := compiled.SplitFormat(format)
+	for i, verb := range verbs {
+		sbb.WriteString(texts[i])
+		if i >= len(a) {
+			sbb.WriteString(verb)
+			continue
+		}
+		if w, ok := a[i].(wire); ok {
+			sbb.WriteString(verb)
+			inst.Inputs = append(inst.Inputs, Operand{Kind: OperandWire, Wire: int(w)})
+		} else {
+			sbb.WriteString(strings.ReplaceAll(fmt.Sprintf(verb, a[i]), "%", "%%"))
+		}
+	}
+	sbb.WriteString(texts[len(verbs)])
+	for i := len(verbs); i < len(a); i++ {
+		if w, ok := a[i].(wire); ok {
+			inst.Inputs = append(inst.Inputs, Operand{Kind: OperandWire, Wire: int(w)})
+		} else {
+			inst.Inputs = append(inst.Inputs, Operand{Kind: OperandText, Text: fmt.Sprint(a[i])})
+		}
+	}
+	inst.Name = sbb.String()
+
+	b.c.Instructions = append(b.c.Instructions, inst)
+}
+
+func (b *builder) Compiler() frontend.Compiler {
+	return b
+}
+
+func (b *builder) MarkBoolean(v frontend.Variable) {
+	if w, ok := v.(wire); ok {
+		b.booleans[w] = struct{}{}
+		b.record(OpMarkBoolean, 0, []frontend.Variable{v})
+	}
+}
+
+func (b *builder) IsBoolean(v frontend.Variable) bool {
+	if w, ok := v.(wire); ok {
+		_, isBool := b.booleans[w]
+		return isBool
+	}
+	c, _ := b.constantValue(v)
+	return c.IsUint64() && c.Uint64() <= 1
+}
+
+func (b *builder) NewHint(f hint.Function, nbOutputs int, inputs ...frontend.Variable) ([]frontend.Variable, error) {
+	if nbOutputs <= 0 {
+		return nil, errors.New("hint function must return at least one output")
+	}
+	id := hint.UUID(f)
+	index, ok := b.mHints[id]
+	if !ok {
+		index = len(b.c.Hints)
+		b.mHints[id] = index
+		b.c.Hints = append(b.c.Hints, Hint{ID: id, Name: hint.Name(f)})
+		b.c.hintFunctions[id] = f
+	}
+	return b.record(OpNewHint, nbOutputs, inputs, index), nil
+}
+
+// Tag records a tag. The returned frontend.Tag holds the number of the tag in
+// its CID field.
+func (b *builder) Tag(name string) frontend.Tag {
+	b.recordNamed(OpTag, name)
+	b.nbTags++
+	return frontend.Tag{Name: name, CID: b.nbTags - 1}
+}
+
+func (b *builder) AddCounter(from, to frontend.Tag) {
+	b.recordNamed(OpAddCounter, "", from.CID, to.CID)
+}
+
+func (b *builder) ConstantValue(v frontend.Variable) (*big.Int, bool) {
+	return b.constantValue(v)
+}
+
+func (b *builder) Curve() ecc.ID {
+	return b.c.CurveID
+}
+
+// Backend returns backend.UNKNOWN, as the circuit is lowered to any backend
+func (b *builder) Backend() backend.ID {
+	return backend.UNKNOWN
+}
+
+func (b *builder) SetSchema(s *schema.Schema) {
+	b.c.Schema = s
+}
+
+func (b *builder) AddPublicVariable(name string) frontend.Variable {
+	res := b.record(OpPublicInput, 1, nil)[0]
+	b.c.Instructions[len(b.c.Instructions)-1].Name = name
+	return res
+}
+
+func (b *builder) AddSecretVariable(name string) frontend.Variable {
+	res := b.record(OpSecretInput, 1, nil)[0]
+	b.c.Instructions[len(b.c.Instructions)-1].Name = name
+	return res
+}
+
+// AssertGate records the custom gate g(l, r, o) == 0
+func (b *builder) AssertGate(g *frontend.Gate, l, r, o frontend.Variable) {
+	if err := g.Check(); err != nil {
+		panic(err)
+	}
+	index, ok := b.mGates[g.Name]
+	if !ok {
+		index = len(b.c.Gates)
+		b.mGates[g.Name] = index
+		gate := Gate{Name: g.Name, Terms: make([]GateTerm, len(g.Terms))}
+		for i, t := range g.Terms {
+			c := new(big.Int).Mod(t.Coeff, b.modulus)
+			gate.Terms[i] = GateTerm{Coeff: c.Bytes(), L: t.L, R: t.R, O: t.O}
+		}
+		b.c.Gates = append(b.c.Gates, gate)
+	}
+	b.record(OpAssertGate, 0, []frontend.Variable{l, r, o}, index)
+}
+
+func (b *builder) PushNamespace(name string) {
+	b.recordNamed(OpPushNamespace, name)
+}
+
+func (b *builder) PopNamespace() {
+	b.recordNamed(OpPopNamespace, "")
+}
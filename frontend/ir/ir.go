@@ -0,0 +1,189 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ir provides a backend-agnostic intermediate representation of a
+// circuit: the trace of the frontend.API calls made by circuit.Define.
+//
+// A circuit is built once in an IR with Build, which may be serialized, and
+// then lowered to the constraint system of each backend with Lower:
+//
+//	c, err := ir.Build(ecc.BN254, &circuit)
+//	r1cs, err := c.Lower(r1cs.NewBuilder)
+//	sparseR1CS, err := c.Lower(scs.NewBuilder)
+//
+// which saves running circuit.Define (and the gadgets it calls) once per
+// backend. The operations on constants are computed when building the IR.
+//
+// As the IR doesn't depend on a backend, Backend returns backend.UNKNOWN
+// during Build, and the builder of the IR doesn't implement
+// frontend.LookupAPI: gadgets choose their generic strategy instead of the
+// lookup tables of PlonK. Custom gates (frontend.GateAPI) and namespaces
+// (frontend.NamespaceAPI) are recorded and lowered natively when the target
+// builder supports them.
+package ir
+
+import (
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend/schema"
+	"github.com/consensys/gnark/internal/backend/ioutils"
+	"github.com/fxamacker/cbor/v2"
+)
+
+// Circuit is the intermediate representation of a circuit.
+//
+// Its wires are numbered in order of creation: each instruction creates the
+// next NbOutputs wires, starting with the inputs of the circuit.
+type Circuit struct {
+	CurveID ecc.ID
+	Schema  *schema.Schema
+
+	Instructions []Instruction
+
+	Hints []Hint // hint functions referenced by OpNewHint
+	Gates []Gate // custom gates referenced by OpAssertGate
+
+	// hint functions called by circuit.Define, if the circuit was built in this process
+	hintFunctions map[hint.ID]hint.Function
+}
+
+// Op is the operation of an instruction
+type Op uint8
+
+const (
+	OpPublicInput Op = iota // Name: name of the input
+	OpSecretInput           // Name: name of the input
+	OpAdd
+	OpNeg
+	OpSub
+	OpMul
+	OpDivUnchecked
+	OpDiv
+	OpInverse
+	OpToBinary // Args[0]: number of bits
+	OpFromBinary
+	OpXor
+	OpOr
+	OpAnd
+	OpSelect
+	OpLookup2
+	OpIsZero
+	OpCmp
+	OpIsLess
+	OpCommit
+	OpAssertIsEqual
+	OpAssertIsDifferent
+	OpAssertIsBoolean
+	OpAssertIsLessOrEqual
+	OpAssertIsLess
+	OpPrintln
+	OpMarkBoolean
+	OpNewHint       // Args[0]: index of the hint in Circuit.Hints
+	OpTag           // Name: name of the tag, the tags are numbered in order
+	OpAddCounter    // Args: numbers of the two tags
+	OpAssertGate    // Args[0]: index of the gate in Circuit.Gates
+	OpPushNamespace // Name: name of the namespace
+	OpPopNamespace
+	OpPrintf // Name: format string, Inputs[0]: condition
+	OpIsInRange
+	OpAssertIsInRange
+	OpExp // Args[0]: number of bits of the exponent
+	OpBatchInvert
+	OpNand
+	OpNor
+	OpWordXor        // Args[0]: number of bits of the words
+	OpWordAnd        // Args[0]: number of bits of the words
+	OpWordRotateLeft // Args: rotation, number of bits of the word
+	OpAssertIsEqualIf
+	OpAssertIsDifferentIf
+)
+
+// Instruction is a call to frontend.API
+type Instruction struct {
+	Op        Op
+	Inputs    []Operand
+	NbOutputs int
+	Args      []int
+	Name      string
+}
+
+// Operand is an input of an instruction
+type Operand struct {
+	Kind     OperandKind
+	Wire     int
+	Constant []byte // big-endian
+	Text     string
+}
+
+// OperandKind tells which field of an Operand is set
+type OperandKind uint8
+
+const (
+	OperandWire     OperandKind = iota // Wire: index of the wire
+	OperandConstant                    // Constant: value of the constant
+	OperandText                        // Text: text printed by OpPrintln or OpPrintf
+)
+
+// Hint references a hint function. The function is resolved when lowering
+// the circuit from the functions called during Build, if the circuit was built
+// in the same process, then from the registry of backend/hint.
+type Hint struct {
+	ID   hint.ID
+	Name string
+}
+
+// Gate is a custom gate, see frontend.Gate
+type Gate struct {
+	Name  string
+	Terms []GateTerm
+}
+
+// GateTerm is a monomial Coeff⋅lᴸ⋅rᴿ⋅oᴼ of a custom gate
+type GateTerm struct {
+	Coeff   []byte // big-endian
+	L, R, O int
+}
+
+// WriteTo encodes the circuit on w using cbor
+func (c *Circuit) WriteTo(w io.Writer) (int64, error) {
+	_w := ioutils.WriterCounter{W: w} // wraps writer to count the bytes written
+	enc, err := cbor.CoreDetEncOptions().EncMode()
+	if err != nil {
+		return 0, err
+	}
+	encoder := enc.NewEncoder(&_w)
+
+	err = encoder.Encode(c)
+	return _w.N, err
+}
+
+// ReadFrom decodes a circuit written by WriteTo from r
+func (c *Circuit) ReadFrom(r io.Reader) (int64, error) {
+	dm, err := cbor.DecOptions{
+		MaxArrayElements: 134217728,
+		MaxMapPairs:      134217728,
+	}.DecMode()
+	if err != nil {
+		return 0, err
+	}
+	decoder := dm.NewDecoder(r)
+	if err := decoder.Decode(c); err != nil {
+		return int64(decoder.NumBytesRead()), err
+	}
+	return int64(decoder.NumBytesRead()), nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ir_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/frontend/ir"
+	"github.com/stretchr/testify/require"
+)
+
+type irCircuit struct {
+	X, Y frontend.Variable
+	Z    frontend.Variable `gnark:",public"`
+}
+
+func (circuit *irCircuit) Define(api frontend.API) error {
+	defer frontend.Namespace(api, "body")()
+	from := api.Compiler().Tag("from")
+
+	// Z = X³ + 5 if Y != 0, X otherwise
+	x3 := api.Mul(circuit.X, circuit.X, circuit.X)
+	res, err := api.Compiler().NewHint(hint.IsZero, 1, circuit.Y)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(res[0], api.IsZero(circuit.Y))
+	z := api.Select(res[0], circuit.X, api.Add(x3, api.Mul(2, 3), -1))
+	api.AssertIsEqual(z, circuit.Z)
+	api.AssertIsEqualIf(res[0], z, circuit.X)
+	api.AssertIsDifferentIf(api.Sub(1, res[0]), z, circuit.X)
+
+	b := api.ToBinary(circuit.X, 8)
+	api.AssertIsEqual(api.FromBinary(b...), circuit.X)
+	api.AssertIsLessOrEqual(circuit.X, 200)
+	api.AssertIsInRange(circuit.X, 1, 200)
+	api.AssertIsEqual(api.Mul(api.BatchInvert([]frontend.Variable{circuit.X, 2})[0], circuit.X), 1)
+	api.AssertIsEqual(api.Exp(circuit.X, circuit.Y, 1), api.Select(circuit.Y, circuit.X, 1))
+	api.AssertIsEqual(api.IsInRange(circuit.X, circuit.Y, 200), 1)
+	api.AssertIsEqual(api.WordRotateLeft(api.WordXor(circuit.X, circuit.Y, 8), 3, 8), api.WordRotateLeft(api.WordXor(circuit.Y, circuit.X, 8), -5, 8))
+	api.AssertIsEqual(api.WordAnd(circuit.X, 0xff, 8), circuit.X)
+	api.Println("x =", circuit.X)
+	api.PrintfIf(circuit.Y, "x = %08b, %d", circuit.X, 42)
+
+	api.Compiler().AddCounter(from, api.Compiler().Tag("to"))
+	return nil
+}
+
+func TestBuildAndLower(t *testing.T) {
+	assert := require.New(t)
+
+	c, err := ir.Build(ecc.BN254, &irCircuit{})
+	assert.NoError(err)
+
+	// round trip through the serialized IR
+	var buf bytes.Buffer
+	_, err = c.WriteTo(&buf)
+	assert.NoError(err)
+	var decoded ir.Circuit
+	_, err = decoded.ReadFrom(&buf)
+	assert.NoError(err)
+
+	for _, newBuilder := range []frontend.NewBuilder{r1cs.NewBuilder, scs.NewBuilder} {
+		expected, err := frontend.Compile(ecc.BN254, newBuilder, &irCircuit{})
+		assert.NoError(err)
+
+		for _, c := range []*ir.Circuit{c, &decoded} {
+			ccs, err := c.Lower(newBuilder)
+			assert.NoError(err)
+			assert.Equal(expected.GetNbConstraints(), ccs.GetNbConstraints())
+			assert.Equal(expected.GetSchema(), ccs.GetSchema())
+			assert.Len(ccs.GetCounters(), 1)
+
+			for _, w := range []irCircuit{{X: 3, Y: 1, Z: 32}, {X: 3, Y: 0, Z: 3}} {
+				witness, err := frontend.NewWitness(&w, ecc.BN254)
+				assert.NoError(err)
+				assert.NoError(ccs.IsSolved(witness))
+			}
+			witness, err := frontend.NewWitness(&irCircuit{X: 3, Y: 1, Z: 3}, ecc.BN254)
+			assert.NoError(err)
+			assert.Error(ccs.IsSolved(witness))
+		}
+	}
+}
@@ -0,0 +1,224 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ir
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/debug"
+	"github.com/consensys/gnark/frontend"
+)
+
+// Lower compiles the circuit with the builder returned by newBuilder, as
+// frontend.Compile does by calling circuit.Define on it.
+//
+// The debug information of the constraints points to Lower instead of to the
+// code of the circuit; the namespaces (see frontend.Namespace) are kept.
+func (c *Circuit) Lower(newBuilder frontend.NewBuilder, opts ...frontend.CompileOption) (frontend.CompiledConstraintSystem, error) {
+	opt := frontend.CompileConfig{}
+	for _, o := range opts {
+		if err := o(&opt); err != nil {
+			return nil, fmt.Errorf("apply option: %w", err)
+		}
+	}
+
+	builder, err := newBuilder(c.CurveID, opt)
+	if err != nil {
+		return nil, fmt.Errorf("new compiler: %w", err)
+	}
+	if err := c.replay(builder); err != nil {
+		return nil, fmt.Errorf("lower circuit: %w", err)
+	}
+	return builder.Compile()
+}
+
+// replay calls the instructions of the circuit on builder
+func (c *Circuit) replay(builder frontend.Builder) (err error) {
+	hints, err := c.resolveHints()
+	if err != nil {
+		return err
+	}
+	gates := make([]*frontend.Gate, len(c.Gates))
+	for i, g := range c.Gates {
+		gates[i] = &frontend.Gate{Name: g.Name, Terms: make([]frontend.GateTerm, len(g.Terms))}
+		for j, t := range g.Terms {
+			gates[i].Terms[j] = frontend.GateTerm{Coeff: new(big.Int).SetBytes(t.Coeff), L: t.L, R: t.R, O: t.O}
+		}
+	}
+	nAPI, hasNamespaces := builder.(frontend.NamespaceAPI)
+
+	builder.SetSchema(c.Schema)
+
+	// recover from panics to print user-friendlier messages
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v\n%s", r, debug.Stack())
+		}
+	}()
+
+	var wires []frontend.Variable
+	var tags []frontend.Tag
+	for i, inst := range c.Instructions {
+		in := make([]frontend.Variable, len(inst.Inputs))
+		for j, op := range inst.Inputs {
+			switch op.Kind {
+			case OperandWire:
+				if op.Wire >= len(wires) {
+					return fmt.Errorf("instruction %d: undefined wire %d", i, op.Wire)
+				}
+				in[j] = wires[op.Wire]
+			case OperandConstant:
+				in[j] = new(big.Int).SetBytes(op.Constant)
+			case OperandText:
+				in[j] = op.Text
+			default:
+				return fmt.Errorf("instruction %d: unknown operand kind %d", i, op.Kind)
+			}
+		}
+
+		nbWires := len(wires)
+		switch inst.Op {
+		case OpPublicInput:
+			wires = append(wires, builder.AddPublicVariable(inst.Name))
+		case OpSecretInput:
+			wires = append(wires, builder.AddSecretVariable(inst.Name))
+		case OpAdd:
+			wires = append(wires, builder.Add(in[0], in[1], in[2:]...))
+		case OpNeg:
+			wires = append(wires, builder.Neg(in[0]))
+		case OpSub:
+			wires = append(wires, builder.Sub(in[0], in[1], in[2:]...))
+		case OpMul:
+			wires = append(wires, builder.Mul(in[0], in[1], in[2:]...))
+		case OpDivUnchecked:
+			wires = append(wires, builder.DivUnchecked(in[0], in[1]))
+		case OpDiv:
+			wires = append(wires, builder.Div(in[0], in[1]))
+		case OpInverse:
+			wires = append(wires, builder.Inverse(in[0]))
+		case OpBatchInvert:
+			wires = append(wires, builder.BatchInvert(in)...)
+		case OpExp:
+			wires = append(wires, builder.Exp(in[0], in[1], inst.Args[0]))
+		case OpToBinary:
+			wires = append(wires, builder.ToBinary(in[0], inst.Args[0])...)
+		case OpFromBinary:
+			wires = append(wires, builder.FromBinary(in...))
+		case OpXor:
+			wires = append(wires, builder.Xor(in[0], in[1], in[2:]...))
+		case OpOr:
+			wires = append(wires, builder.Or(in[0], in[1], in[2:]...))
+		case OpAnd:
+			wires = append(wires, builder.And(in[0], in[1], in[2:]...))
+		case OpNand:
+			wires = append(wires, builder.Nand(in[0], in[1], in[2:]...))
+		case OpNor:
+			wires = append(wires, builder.Nor(in[0], in[1], in[2:]...))
+		case OpWordXor:
+			wires = append(wires, builder.WordXor(in[0], in[1], inst.Args[0]))
+		case OpWordAnd:
+			wires = append(wires, builder.WordAnd(in[0], in[1], inst.Args[0]))
+		case OpWordRotateLeft:
+			wires = append(wires, builder.WordRotateLeft(in[0], inst.Args[0], inst.Args[1]))
+		case OpSelect:
+			wires = append(wires, builder.Select(in[0], in[1], in[2]))
+		case OpLookup2:
+			wires = append(wires, builder.Lookup2(in[0], in[1], in[2], in[3], in[4], in[5]))
+		case OpIsZero:
+			wires = append(wires, builder.IsZero(in[0]))
+		case OpCmp:
+			wires = append(wires, builder.Cmp(in[0], in[1]))
+		case OpIsLess:
+			wires = append(wires, builder.IsLess(in[0], in[1]))
+		case OpIsInRange:
+			wires = append(wires, builder.IsInRange(in[0], in[1], in[2]))
+		case OpCommit:
+			wires = append(wires, builder.Commit(in...))
+		case OpAssertIsEqual:
+			builder.AssertIsEqual(in[0], in[1])
+		case OpAssertIsDifferent:
+			builder.AssertIsDifferent(in[0], in[1])
+		case OpAssertIsEqualIf:
+			builder.AssertIsEqualIf(in[0], in[1], in[2])
+		case OpAssertIsDifferentIf:
+			builder.AssertIsDifferentIf(in[0], in[1], in[2])
+		case OpAssertIsBoolean:
+			builder.AssertIsBoolean(in[0])
+		case OpAssertIsLessOrEqual:
+			builder.AssertIsLessOrEqual(in[0], in[1])
+		case OpAssertIsLess:
+			builder.AssertIsLess(in[0], in[1])
+		case OpAssertIsInRange:
+			builder.AssertIsInRange(in[0], in[1], in[2])
+		case OpPrintln:
+			builder.Println(in...)
+		case OpPrintf:
+			builder.PrintfIf(in[0], inst.Name, in[1:]...)
+		case OpMarkBoolean:
+			builder.MarkBoolean(in[0])
+		case OpNewHint:
+			res, err := builder.NewHint(hints[inst.Args[0]], inst.NbOutputs, in...)
+			if err != nil {
+				return fmt.Errorf("instruction %d: %w", i, err)
+			}
+			wires = append(wires, res...)
+		case OpTag:
+			tags = append(tags, builder.Tag(inst.Name))
+		case OpAddCounter:
+			builder.AddCounter(tags[inst.Args[0]], tags[inst.Args[1]])
+		case OpAssertGate:
+			frontend.AssertGate(builder, gates[inst.Args[0]], in[0], in[1], in[2])
+		case OpPushNamespace:
+			if hasNamespaces {
+				nAPI.PushNamespace(inst.Name)
+			}
+		case OpPopNamespace:
+			if hasNamespaces {
+				nAPI.PopNamespace()
+			}
+		default:
+			return fmt.Errorf("instruction %d: unknown op %d", i, inst.Op)
+		}
+		if len(wires) != nbWires+inst.NbOutputs {
+			return fmt.Errorf("instruction %d: %d outputs instead of %d", i, len(wires)-nbWires, inst.NbOutputs)
+		}
+	}
+
+	return nil
+}
+
+// resolveHints returns the functions of c.Hints
+func (c *Circuit) resolveHints() ([]hint.Function, error) {
+	registered := make(map[hint.ID]hint.Function)
+	for _, f := range hint.GetRegistered() {
+		registered[hint.UUID(f)] = f
+	}
+
+	res := make([]hint.Function, len(c.Hints))
+	for i, h := range c.Hints {
+		if f, ok := c.hintFunctions[h.ID]; ok {
+			res[i] = f
+		} else if f, ok := registered[h.ID]; ok {
+			res[i] = f
+		} else {
+			return nil, fmt.Errorf("hint %s is not registered", h.Name)
+		}
+	}
+	return res, nil
+}
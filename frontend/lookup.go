@@ -0,0 +1,105 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+)
+
+// Table is a lookup table, Lookup(api, t, i) returns Values[i].
+//
+// Tables are identified by their Name in a circuit; two different tables must
+// not share the same name.
+type Table struct {
+	Name   string
+	Values []*big.Int
+}
+
+// LookupAPI is implemented by builders which support lookup tables natively
+// (PlonK, with a plookup argument).
+type LookupAPI interface {
+	// Lookup returns t.Values[index] and asserts that index < len(t.Values)
+	Lookup(t *Table, index Variable) Variable
+}
+
+// Lookup returns t.Values[index] and asserts that index < len(t.Values).
+//
+// If api implements LookupAPI, the lookup costs a single constraint, whatever
+// the size of the table. Otherwise, it is expanded in a multiplexer of
+// api.Select over the binary decomposition of index.
+func Lookup(api API, t *Table, index Variable) Variable {
+	if err := t.Check(); err != nil {
+		panic(err)
+	}
+	if lAPI, ok := api.(LookupAPI); ok {
+		return lAPI.Lookup(t, index)
+	}
+
+	if c, ok := api.ConstantValue(index); ok {
+		if !c.IsUint64() || c.Uint64() >= uint64(len(t.Values)) {
+			panic(fmt.Sprintf("table %s: index %s out of range", t.Name, c.String()))
+		}
+		return t.Values[c.Uint64()]
+	}
+	if len(t.Values) == 1 {
+		api.AssertIsEqual(index, 0)
+		return t.Values[0]
+	}
+
+	// values are padded to the next power of two, out of range indexes are
+	// then excluded by the bound check
+	nbBits := bits.Len(uint(len(t.Values) - 1))
+	if len(t.Values) != 1<<nbBits {
+		api.AssertIsLessOrEqual(index, len(t.Values)-1)
+	}
+	b := api.ToBinary(index, nbBits)
+
+	values := make([]Variable, 1<<nbBits)
+	for i := range values {
+		if i < len(t.Values) {
+			values[i] = t.Values[i]
+		} else {
+			values[i] = t.Values[len(t.Values)-1]
+		}
+	}
+	for i := 0; i < nbBits; i++ {
+		for j := 0; j < len(values)/2; j++ {
+			values[j] = api.Select(b[i], values[2*j+1], values[2*j])
+		}
+		values = values[:len(values)/2]
+	}
+	return values[0]
+}
+
+// Check returns an error if t is not a well formed lookup table
+func (t *Table) Check() error {
+	if t.Name == "" {
+		return errors.New("table: missing name")
+	}
+	if len(t.Values) == 0 {
+		return fmt.Errorf("table %s: no values", t.Name)
+	}
+	for _, v := range t.Values {
+		if v == nil {
+			return fmt.Errorf("table %s: missing value", t.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NamespaceAPI is implemented by builders which keep track of the components
+// of a circuit.
+type NamespaceAPI interface {
+	// PushNamespace opens the namespace name, nested in the current namespace
+	PushNamespace(name string)
+
+	// PopNamespace closes the current namespace
+	PopNamespace()
+}
+
+// Namespace opens the namespace name, nested in the current namespace of api,
+// and returns the function closing it. A gadget typically starts with
+//
+//	defer frontend.Namespace(api, "sha256")()
+//
+// The path of the namespace, for example "ecdsa/scalarMul", prefixes the debug
+// information of the constraints created in it (which shows in the errors of
+// the solver), the names of the tags and counters, and labels the constraints
+// of the profile (see WithProfile) with the key "namespace".
+//
+// If api does not implement NamespaceAPI, Namespace is a no-op.
+func Namespace(api API, name string) func() {
+	if name == "" || strings.Contains(name, "/") {
+		panic(fmt.Sprintf("invalid namespace %q", name))
+	}
+	nAPI, ok := api.(NamespaceAPI)
+	if !ok {
+		return func() {}
+	}
+	nAPI.PushNamespace(name)
+	return nAPI.PopNamespace
+}
+
+// Component is a reusable piece of circuit. As for a Circuit, its exported
+// fields are its interface: the caller sets the inputs, and Define sets the
+// outputs.
+type Component interface {
+	Define(api API) error
+}
+
+// DefineComponent calls c.Define in the namespace name, see Namespace.
+func DefineComponent(api API, name string, c Component) error {
+	defer Namespace(api, name)()
+	return c.Define(api)
+}
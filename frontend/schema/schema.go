@@ -17,6 +17,7 @@ limitations under the License.
 package schema
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"reflect"
@@ -244,6 +245,8 @@ func parse(r []Field, input interface{}, target reflect.Type, parentFullName, pa
 			// variable name is field name, unless overriden by gnark tag value
 			name := f.Name
 			var nameTag string
+			var length string
+			var hasLength bool
 
 			if ok && tag != "" {
 				// gnark tag is set
@@ -252,13 +255,14 @@ func parse(r []Field, input interface{}, target reflect.Type, parentFullName, pa
 				if !isValidTag(nameTag) {
 					nameTag = ""
 				}
+				length, hasLength, opts = opts.extract(string(optLen))
 				opts = tagOptions(strings.TrimSpace(string(opts)))
 				if opts == "" || opts.contains(string(optSecret)) {
 					visibility = Secret
 				} else if opts.contains(string(optPublic)) {
 					visibility = Public
 				} else {
-					return r, fmt.Errorf("invalid gnark struct tag option on %s. must be \"public\", \"secret\" or \"-\"", getFullName(parentGoName, name, nameTag))
+					return r, fmt.Errorf("invalid gnark struct tag option on %s. must be \"public\", \"secret\", \"len=...\" or \"-\"", getFullName(parentGoName, name, nameTag))
 				}
 			}
 
@@ -275,6 +279,12 @@ func parse(r []Field, input interface{}, target reflect.Type, parentFullName, pa
 
 			fValue := tValue.FieldByIndex(f.Index)
 
+			if hasLength {
+				if err := allocateSlice(tValue, fValue, length); err != nil {
+					return r, fmt.Errorf("%s: %w", getFullName(parentGoName, name, nameTag), err)
+				}
+			}
+
 			if fValue.CanAddr() && fValue.Addr().CanInterface() {
 				value := fValue.Addr().Interface()
 				var err error
@@ -373,6 +383,39 @@ func parse(r []Field, input interface{}, target reflect.Type, parentFullName, pa
 	return r, nil
 }
 
+// allocateSlice sets fValue to a slice of the length given by a "len" tag option, if it is empty.
+// Slices that are already allocated are left unchanged.
+// length is either a constant or the name of an integer field of parent.
+func allocateSlice(parent, fValue reflect.Value, length string) error {
+	if fValue.Kind() != reflect.Slice {
+		return errors.New("len tag option is only valid on slices")
+	}
+	n, err := strconv.Atoi(length)
+	if err != nil {
+		lValue := parent.FieldByName(length)
+		switch lValue.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = int(lValue.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n = int(lValue.Uint())
+		default:
+			return fmt.Errorf("invalid len tag option %q, must be a constant or the name of an integer field", length)
+		}
+	}
+	if n < 0 {
+		return fmt.Errorf("invalid slice length %d", n)
+	}
+	if fValue.Len() != 0 || n == 0 {
+		// already allocated, typically an assignment
+		return nil
+	}
+	if !fValue.CanSet() {
+		return errors.New("can't allocate slice, circuit must be passed by pointer")
+	}
+	fValue.Set(reflect.MakeSlice(fValue.Type(), n, n))
+	return nil
+}
+
 // specify parentName, name and tag
 // returns fully qualified name
 func getFullName(parentFullName, name, tagName string) string {
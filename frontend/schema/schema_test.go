@@ -166,6 +166,51 @@ func TestSchemaInherit(t *testing.T) {
 
 }
 
+func TestSchemaVariables(t *testing.T) {
+	assert := require.New(t)
+
+	// the variables are ordered as the witness: public, then secret, in the
+	// order of Parse
+	var public, secret []string
+	handler := func(visibility Visibility, name string, _ reflect.Value) error {
+		if visibility == Public {
+			public = append(public, name)
+		} else {
+			secret = append(secret, name)
+		}
+		return nil
+	}
+	s, err := Parse(&Circuit{Z: make([]variable, 3)}, tVariable, handler)
+	assert.NoError(err)
+
+	variables := s.Variables()
+	assert.Equal(s.NbPublic+s.NbSecret, len(variables))
+	for i, v := range variables {
+		assert.Equal(i, v.Index)
+		if i < s.NbPublic {
+			assert.Equal(Public, v.Visibility)
+			assert.Equal(public[i], v.Name)
+		} else {
+			assert.Equal(Secret, v.Visibility)
+			assert.Equal(secret[i-s.NbPublic], v.Name)
+		}
+	}
+	assert.Equal(Variable{Name: "Y", Path: "Y", Visibility: Public, Index: 0}, variables[0])
+	assert.Equal(Variable{Name: "G_B_P_0_M", Path: "G.B.P.0.M", Visibility: Public, Index: s.NbPublic - 1}, variables[s.NbPublic-1])
+	assert.Equal(Variable{Name: "x", Path: "x", Visibility: Secret, Index: s.NbPublic}, variables[s.NbPublic])
+	assert.Equal("G_super_1", variables[s.NbPublic+5].Name)
+	assert.Equal("G.super.1", variables[s.NbPublic+5].Path)
+
+	// the schema is encoded in JSON with the names of the visibilities and types
+	data, err := json.Marshal(s)
+	assert.NoError(err)
+	assert.Contains(string(data), `"Visibility":"public"`)
+	assert.Contains(string(data), `"Type":"array"`)
+	var decoded Schema
+	assert.NoError(json.Unmarshal(data, &decoded))
+	assert.Equal(*s, decoded)
+}
+
 var tVariable reflect.Type
 
 func init() {
@@ -22,6 +22,18 @@ import (
 //			Z frontend.Variable `gnark:"-"`
 // 		}
 // it is then the developer responsability to do circuit.Z = circuit.Y in the Define() method
+//
+// the "len" option binds the length of a slice when the circuit is parsed, so that the slice
+// doesn't need to be allocated at every call site. The length is either a constant or the name
+// of an integer field of the same struct. For example
+// 		type MyCircuit struct {
+// 			Depth int
+// 			Path  []frontend.Variable `gnark:",public,len=Depth"`
+// 			Leaf  [2]frontend.Variable
+// 			Salt  []frontend.Variable `gnark:",len=4"`
+// 		}
+// compiling MyCircuit{Depth: 32} allocates Path with 32 elements and Salt with 4 elements.
+// Slices that are already allocated, for example in a witness assignment, are left unchanged.
 type Tag string
 
 const (
@@ -29,6 +41,7 @@ const (
 	optPublic Tag = "public"
 	optSecret Tag = "secret"
 	optOmit   Tag = "-"
+	optLen    Tag = "len"
 )
 
 // Copyright 2011 The Go Authors. All rights reserved.
@@ -67,6 +80,23 @@ func (o tagOptions) contains(optionName string) bool {
 	return false
 }
 
+// extract removes the option "optionName=value" from the list
+// and returns its value, if any.
+func (o tagOptions) extract(optionName string) (string, bool, tagOptions) {
+	if len(o) == 0 {
+		return "", false, o
+	}
+	optList := strings.Split(string(o), ",")
+	for i := 0; i < len(optList); i++ {
+		opt := strings.TrimSpace(optList[i])
+		if idx := strings.Index(opt, "="); idx != -1 && strings.TrimSpace(opt[:idx]) == optionName {
+			optList = append(optList[:i], optList[i+1:]...)
+			return strings.TrimSpace(opt[idx+1:]), true, tagOptions(strings.Join(optList, ","))
+		}
+	}
+	return "", false, o
+}
+
 func isValidTag(s string) bool {
 	if s == "" {
 		return false
@@ -160,4 +160,60 @@ func TestStructTags(t *testing.T) {
 		t.Run("slice", func(t *testing.T) { testParseTags(t, &s, expected) })
 	}
 
+	// slice with a length bound by the len option
+	{
+		type child struct {
+			N int
+			D []variable `gnark:",public,len=N"`
+		}
+		s := struct {
+			A []variable `gnark:"a,len=2"`
+			C child
+		}{C: child{N: 3}}
+		expected := make(map[string]Visibility)
+		expected["a_0"] = Secret
+		expected["a_1"] = Secret
+		expected["C_D_0"] = Public
+		expected["C_D_1"] = Public
+		expected["C_D_2"] = Public
+		t.Run("slice_len", func(t *testing.T) { testParseTags(t, &s, expected) })
+	}
+
+}
+
+func TestStructTagLen(t *testing.T) {
+	assert := require.New(t)
+
+	// empty slices are allocated
+	s1 := struct {
+		A []variable `gnark:",len=2"`
+	}{}
+	s, err := Parse(&s1, tVariable, nil)
+	assert.NoError(err)
+	assert.Equal(2, len(s1.A))
+	assert.Equal(2, s.NbSecret)
+
+	// already allocated slices are left unchanged
+	s2 := struct {
+		A []variable `gnark:",len=2"`
+	}{A: make([]variable, 3)}
+	s, err = Parse(&s2, tVariable, nil)
+	assert.NoError(err)
+	assert.Equal(3, len(s2.A))
+	assert.Equal(3, s.NbSecret)
+
+	// len must reference an integer field
+	s3 := struct {
+		N string
+		A []variable `gnark:",len=N"`
+	}{}
+	_, err = Parse(&s3, tVariable, nil)
+	assert.Error(err)
+
+	// len is only valid on slices
+	s4 := struct {
+		A [2]variable `gnark:",len=2"`
+	}{}
+	_, err = Parse(&s4, tVariable, nil)
+	assert.Error(err)
 }
@@ -0,0 +1,134 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Variable is a leaf of a Schema: a public or secret input of the circuit
+type Variable struct {
+	// Name is the fully qualified name of the variable, its ancestors being
+	// separated by "_", as in the LeafHandler of Parse and in WriteSequence
+	Name string `json:"name"`
+
+	// Path is the dotted path of the variable from the root of the circuit,
+	// slice and array indexes being elements of the path (see
+	// frontend.NewWitnessFromMap)
+	Path string `json:"path"`
+
+	Visibility Visibility `json:"visibility"`
+
+	// Index is the position of the variable in the witness vector [public | secret]
+	Index int `json:"index"`
+}
+
+// Variables returns the variables of the schema, in the order of the witness
+// vector: the public variables, then the secret ones.
+func (s Schema) Variables() []Variable {
+	var public, secret []Variable
+	var walk func(f Field, name, path string)
+	walk = func(f Field, name, path string) {
+		switch f.Type {
+		case Leaf:
+			v := Variable{Name: name, Path: path, Visibility: f.Visibility}
+			if v.Visibility == Public {
+				public = append(public, v)
+			} else {
+				v.Visibility = Secret
+				secret = append(secret, v)
+			}
+		case Struct:
+			for _, sub := range f.SubFields {
+				walk(sub, getFullName(name, sub.Name, sub.NameTag), path+"."+fieldKey(sub))
+			}
+		case Array:
+			// the elements are leaves, or described by the first sub field
+			elem := Field{Type: Leaf, Visibility: f.Visibility}
+			if len(f.SubFields) != 0 {
+				elem = f.SubFields[0]
+			}
+			for i := 0; i < f.ArraySize; i++ {
+				walk(elem, getFullName(name, strconv.Itoa(i), ""), path+"."+strconv.Itoa(i))
+			}
+		}
+	}
+	for _, f := range s.Fields {
+		walk(f, getFullName("", f.Name, f.NameTag), fieldKey(f))
+	}
+
+	res := append(public, secret...)
+	for i := range res {
+		res[i].Index = i
+	}
+	return res
+}
+
+// fieldKey returns the name of f in the paths of the variables
+func fieldKey(f Field) string {
+	if f.NameTag != "" {
+		return f.NameTag
+	}
+	return f.Name
+}
+
+// MarshalText implements encoding.TextMarshaler, so that visibilities are
+// encoded as their names (for example in JSON)
+func (v Visibility) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (v *Visibility) UnmarshalText(text []byte) error {
+	for _, vv := range []Visibility{Unset, Internal, Secret, Public, Virtual} {
+		if vv.String() == string(text) {
+			*v = vv
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid visibility %q", text)
+}
+
+func (t FieldType) String() string {
+	switch t {
+	case Leaf:
+		return "leaf"
+	case Array:
+		return "array"
+	case Struct:
+		return "struct"
+	}
+	return "unknown"
+}
+
+// MarshalText implements encoding.TextMarshaler, so that field types are
+// encoded as their names (for example in JSON)
+func (t FieldType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (t *FieldType) UnmarshalText(text []byte) error {
+	for _, tt := range []FieldType{Leaf, Array, Struct} {
+		if tt.String() == string(text) {
+			*t = tt
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid field type %q", text)
+}
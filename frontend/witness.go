@@ -1,8 +1,18 @@
 package frontend
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend/schema"
+	"github.com/consensys/gnark/internal/utils"
 )
 
 // NewWitness build an orderded vector of field elements from the given assignment (Circuit)
@@ -29,6 +39,141 @@ func NewWitness(assignment Circuit, curveID ecc.ID, opts ...WitnessOption) (*wit
 	return w, nil
 }
 
+// NewWitnessFromMap builds a witness of the circuit of schema s (see NewSchema)
+// from values, which maps the paths of the variables to their assignment. The
+// path of a variable is the dotted path of its name (or gnark tag, if set)
+// from the root of the circuit, slice and array indexes being elements of the
+// path. For example, with
+//
+//	type Circuit struct {
+//	    X frontend.Variable `gnark:"x,public"`
+//	    P [2]struct{ A, B frontend.Variable }
+//	}
+//
+// the paths are "x", "P.0.A", "P.0.B", "P.1.A" and "P.1.B".
+//
+// The values can be of any type accepted in an assignment, or decoded from
+// JSON (float64 holding an integer, json.Number or string). It returns an
+// error if a variable is missing, if a path is not in the schema, or if a
+// secret variable is given while PublicOnly is specified.
+func NewWitnessFromMap(s *schema.Schema, values map[string]interface{}, curveID ecc.ID, opts ...WitnessOption) (*witness.Witness, error) {
+	if s == nil {
+		return nil, errors.New("missing schema")
+	}
+	opt, err := options(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	instance := s.Instantiate(tVariable, false)
+	root := reflect.ValueOf(instance).Elem()
+	used := make(map[string]bool, len(values))
+	for _, f := range s.Fields {
+		if err := assignFromMap(root.FieldByName(f.Name), f, fieldKey(f), values, used, opt.publicOnly); err != nil {
+			return nil, err
+		}
+	}
+	for path := range values {
+		if !used[path] {
+			return nil, fmt.Errorf("%s: not a variable of the circuit", path)
+		}
+	}
+
+	w, err := witness.New(curveID, nil)
+	if err != nil {
+		return nil, err
+	}
+	w.Schema, err = w.Vector.FromAssignment(instance, tVariable, opt.publicOnly)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// fieldKey returns the name of f in the paths of NewWitnessFromMap.
+func fieldKey(f schema.Field) string {
+	if f.NameTag != "" {
+		return f.NameTag
+	}
+	return f.Name
+}
+
+// assignFromMap sets the leaves of v, which is described by the field f and
+// has the given path, from values. The paths read are marked in used.
+func assignFromMap(v reflect.Value, f schema.Field, path string, values map[string]interface{}, used map[string]bool, publicOnly bool) error {
+	switch f.Type {
+	case schema.Leaf:
+		value, ok := values[path]
+		if f.Visibility == schema.Secret && publicOnly {
+			if ok {
+				return fmt.Errorf("%s: secret variable in a public witness", path)
+			}
+			return nil
+		}
+		if !ok {
+			return fmt.Errorf("%s: missing assignment", path)
+		}
+		used[path] = true
+		b, err := toBigInt(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		v.Set(reflect.ValueOf(b))
+	case schema.Struct:
+		for _, sub := range f.SubFields {
+			if err := assignFromMap(v.FieldByName(sub.Name), sub, path+"."+fieldKey(sub), values, used, publicOnly); err != nil {
+				return err
+			}
+		}
+	case schema.Array:
+		for i := 0; i < f.ArraySize; i++ {
+			// the elements are leaves, or described by the first sub field
+			elem := schema.Field{Type: schema.Leaf, Visibility: f.Visibility}
+			if len(f.SubFields) != 0 {
+				elem = f.SubFields[0]
+			}
+			if err := assignFromMap(v.Index(i), elem, path+"."+strconv.Itoa(i), values, used, publicOnly); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// toBigInt converts an assignment, possibly decoded from JSON, to a big.Int.
+func toBigInt(value interface{}) (res *big.Int, err error) {
+	switch v := value.(type) {
+	case float64:
+		if v != math.Trunc(v) || math.Abs(v) > 1<<53 {
+			return nil, fmt.Errorf("%v is not an exact integer", v)
+		}
+		return big.NewInt(int64(v)), nil
+	case json.Number:
+		value = v.String()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid assignment: %v", r)
+		}
+	}()
+	b := utils.FromInterface(value)
+	return &b, nil
+}
+
+// NewSchema returns the schema of the circuit, that is the names, the
+// visibility and the layout of its public and secret inputs, without
+// compiling it. It can be used to serialize witnesses (see
+// witness.Witness.WriteJSON and witness.Witness.ReadJSON) in a service which
+// does not compile the circuit.
+//
+// The schema describes the nesting of the inputs (schema.Field), and lists
+// them in the order of the witness vector (schema.Schema.Variables). It can
+// be encoded in JSON, to build assignments outside of Go.
+func NewSchema(circuit Circuit) (*schema.Schema, error) {
+	return schema.Parse(circuit, tVariable, nil)
+}
+
 // default options
 func options(opts ...WitnessOption) (witnessConfig, error) {
 	// apply options
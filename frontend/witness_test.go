@@ -0,0 +1,76 @@
+package frontend
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/stretchr/testify/require"
+)
+
+type mapCircuit struct {
+	X Variable `gnark:"x,public"`
+	P [2]struct {
+		A, B Variable
+	}
+	S []Variable `gnark:",public"`
+}
+
+func (c *mapCircuit) Define(api API) error {
+	return nil
+}
+
+func TestNewWitnessFromMap(t *testing.T) {
+	assert := require.New(t)
+
+	s, err := NewSchema(&mapCircuit{S: make([]Variable, 2)})
+	assert.NoError(err)
+
+	var values map[string]interface{}
+	err = json.Unmarshal([]byte(`{
+		"x": 42,
+		"P.0.A": 1, "P.0.B": "2",
+		"P.1.A": 3, "P.1.B": "0x4",
+		"S.0": 5, "S.1": 6
+	}`), &values)
+	assert.NoError(err)
+
+	// same witness from the assignment
+	assignment := mapCircuit{X: 42, S: []Variable{5, 6}}
+	assignment.P[0].A, assignment.P[0].B = 1, 2
+	assignment.P[1].A, assignment.P[1].B = 3, 4
+	expected, err := NewWitness(&assignment, ecc.BN254)
+	assert.NoError(err)
+	expectedData, err := expected.MarshalBinary()
+	assert.NoError(err)
+
+	w, err := NewWitnessFromMap(s, values, ecc.BN254)
+	assert.NoError(err)
+	data, err := w.MarshalBinary()
+	assert.NoError(err)
+	assert.Equal(expectedData, data)
+
+	// public witness
+	public := map[string]interface{}{"x": 42, "S.0": 5, "S.1": 6}
+	w, err = NewWitnessFromMap(s, public, ecc.BN254, PublicOnly())
+	assert.NoError(err)
+	assert.Equal(3, w.Vector.Len())
+
+	// secret variable in a public witness
+	public["P.0.A"] = 1
+	_, err = NewWitnessFromMap(s, public, ecc.BN254, PublicOnly())
+	assert.Error(err)
+
+	// missing, unknown and invalid values
+	delete(values, "P.1.B")
+	_, err = NewWitnessFromMap(s, values, ecc.BN254)
+	assert.Error(err)
+	values["P.1.B"] = 4
+	values["P.2.A"] = 4
+	_, err = NewWitnessFromMap(s, values, ecc.BN254)
+	assert.Error(err)
+	delete(values, "P.2.A")
+	values["x"] = 4.5
+	_, err = NewWitnessFromMap(s, values, ecc.BN254)
+	assert.Error(err)
+}
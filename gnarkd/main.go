@@ -0,0 +1,96 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// gnarkd serves the setup, the prover and the verifier of gnark over HTTP and
+// gRPC (see package gnarkd/server for the API, and gnarkd/pb/gnarkd.proto for
+// the gRPC service).
+//
+// Circuits are uploaded as compiled constraint systems:
+//
+//	curl -X PUT --data-binary @cubic.r1cs 'localhost:9002/circuits/cubic?curve=bn254&backend=groth16'
+//	curl -X POST localhost:9002/circuits/cubic/setup
+//	curl -X POST -d '{"witness": {"x": 3, "Y": 35}}' localhost:9002/circuits/cubic/prove
+//	curl 'localhost:9002/jobs/<id>?wait=true'
+//
+// To serve circuits defined in Go, build a binary registering them with
+// server.RegisterCircuit instead.
+package main
+
+import (
+	"context"
+	"flag"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/consensys/gnark/gnarkd/server"
+	"github.com/consensys/gnark/logger"
+)
+
+var (
+	fAddr      = flag.String("addr", ":9002", "listening address of the HTTP API")
+	fGRPCAddr  = flag.String("grpc-addr", ":9003", "listening address of the gRPC API, empty to disable it")
+	fWorkers   = flag.Int("workers", 1, "number of proofs computed concurrently")
+	fQueueSize = flag.Int("queue", 64, "number of queued proofs after which new ones are rejected")
+	fJobTTL    = flag.Duration("job-ttl", 10*time.Minute, "how long the proofs of finished jobs are kept")
+)
+
+func main() {
+	flag.Parse()
+	log := logger.Logger()
+
+	s, err := server.New(
+		server.WithWorkers(*fWorkers),
+		server.WithQueueSize(*fQueueSize),
+		server.WithJobTTL(*fJobTTL),
+	)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid configuration")
+	}
+
+	httpServer := &http.Server{Addr: *fAddr, Handler: s}
+	grpcServer := s.NewGRPCServer()
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		<-sig
+		log.Info().Msg("shutting down")
+		grpcServer.GracefulStop()
+		_ = httpServer.Shutdown(context.Background())
+	}()
+
+	if *fGRPCAddr != "" {
+		lis, err := net.Listen("tcp", *fGRPCAddr)
+		if err != nil {
+			log.Fatal().Err(err).Msg("gRPC listener")
+		}
+		log.Info().Str("addr", *fGRPCAddr).Msg("gnarkd gRPC listening")
+		go func() {
+			if err := grpcServer.Serve(lis); err != nil {
+				log.Fatal().Err(err).Msg("gnarkd gRPC stopped")
+			}
+		}()
+	}
+
+	log.Info().Str("addr", *fAddr).Msg("gnarkd HTTP listening")
+	if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatal().Err(err).Msg("gnarkd stopped")
+	}
+	s.Close()
+}
@@ -0,0 +1,579 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.20.1
+// source: gnarkd.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// GnarkdClient is the client API for Gnarkd service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type GnarkdClient interface {
+	// ListCircuits lists the registered circuits
+	ListCircuits(ctx context.Context, in *ListCircuitsRequest, opts ...grpc.CallOption) (*ListCircuitsResponse, error)
+	// GetCircuit describes a circuit
+	GetCircuit(ctx context.Context, in *CircuitRequest, opts ...grpc.CallOption) (*CircuitInfo, error)
+	// RegisterCircuit registers a compiled constraint system
+	RegisterCircuit(ctx context.Context, opts ...grpc.CallOption) (Gnarkd_RegisterCircuitClient, error)
+	// SetSRS sets the KZG SRS of a PLONK circuit
+	SetSRS(ctx context.Context, opts ...grpc.CallOption) (Gnarkd_SetSRSClient, error)
+	// Setup computes the keys of a circuit
+	Setup(ctx context.Context, in *CircuitRequest, opts ...grpc.CallOption) (*CircuitInfo, error)
+	// GetKey downloads a key
+	GetKey(ctx context.Context, in *KeyRequest, opts ...grpc.CallOption) (Gnarkd_GetKeyClient, error)
+	// SetKey uploads a key
+	SetKey(ctx context.Context, opts ...grpc.CallOption) (Gnarkd_SetKeyClient, error)
+	// Prove queues a proof, and returns its job
+	Prove(ctx context.Context, in *ProveRequest, opts ...grpc.CallOption) (*Job, error)
+	// GetJob returns a job, waiting for it to finish if asked
+	GetJob(ctx context.Context, in *JobRequest, opts ...grpc.CallOption) (*Job, error)
+	// Verify verifies a proof
+	Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error)
+}
+
+type gnarkdClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGnarkdClient(cc grpc.ClientConnInterface) GnarkdClient {
+	return &gnarkdClient{cc}
+}
+
+func (c *gnarkdClient) ListCircuits(ctx context.Context, in *ListCircuitsRequest, opts ...grpc.CallOption) (*ListCircuitsResponse, error) {
+	out := new(ListCircuitsResponse)
+	err := c.cc.Invoke(ctx, "/gnarkd.Gnarkd/ListCircuits", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gnarkdClient) GetCircuit(ctx context.Context, in *CircuitRequest, opts ...grpc.CallOption) (*CircuitInfo, error) {
+	out := new(CircuitInfo)
+	err := c.cc.Invoke(ctx, "/gnarkd.Gnarkd/GetCircuit", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gnarkdClient) RegisterCircuit(ctx context.Context, opts ...grpc.CallOption) (Gnarkd_RegisterCircuitClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Gnarkd_ServiceDesc.Streams[0], "/gnarkd.Gnarkd/RegisterCircuit", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gnarkdRegisterCircuitClient{stream}
+	return x, nil
+}
+
+type Gnarkd_RegisterCircuitClient interface {
+	Send(*RegisterCircuitRequest) error
+	CloseAndRecv() (*CircuitInfo, error)
+	grpc.ClientStream
+}
+
+type gnarkdRegisterCircuitClient struct {
+	grpc.ClientStream
+}
+
+func (x *gnarkdRegisterCircuitClient) Send(m *RegisterCircuitRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *gnarkdRegisterCircuitClient) CloseAndRecv() (*CircuitInfo, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(CircuitInfo)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *gnarkdClient) SetSRS(ctx context.Context, opts ...grpc.CallOption) (Gnarkd_SetSRSClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Gnarkd_ServiceDesc.Streams[1], "/gnarkd.Gnarkd/SetSRS", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gnarkdSetSRSClient{stream}
+	return x, nil
+}
+
+type Gnarkd_SetSRSClient interface {
+	Send(*UploadRequest) error
+	CloseAndRecv() (*Empty, error)
+	grpc.ClientStream
+}
+
+type gnarkdSetSRSClient struct {
+	grpc.ClientStream
+}
+
+func (x *gnarkdSetSRSClient) Send(m *UploadRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *gnarkdSetSRSClient) CloseAndRecv() (*Empty, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(Empty)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *gnarkdClient) Setup(ctx context.Context, in *CircuitRequest, opts ...grpc.CallOption) (*CircuitInfo, error) {
+	out := new(CircuitInfo)
+	err := c.cc.Invoke(ctx, "/gnarkd.Gnarkd/Setup", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gnarkdClient) GetKey(ctx context.Context, in *KeyRequest, opts ...grpc.CallOption) (Gnarkd_GetKeyClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Gnarkd_ServiceDesc.Streams[2], "/gnarkd.Gnarkd/GetKey", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gnarkdGetKeyClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Gnarkd_GetKeyClient interface {
+	Recv() (*Chunk, error)
+	grpc.ClientStream
+}
+
+type gnarkdGetKeyClient struct {
+	grpc.ClientStream
+}
+
+func (x *gnarkdGetKeyClient) Recv() (*Chunk, error) {
+	m := new(Chunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *gnarkdClient) SetKey(ctx context.Context, opts ...grpc.CallOption) (Gnarkd_SetKeyClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Gnarkd_ServiceDesc.Streams[3], "/gnarkd.Gnarkd/SetKey", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gnarkdSetKeyClient{stream}
+	return x, nil
+}
+
+type Gnarkd_SetKeyClient interface {
+	Send(*UploadRequest) error
+	CloseAndRecv() (*Empty, error)
+	grpc.ClientStream
+}
+
+type gnarkdSetKeyClient struct {
+	grpc.ClientStream
+}
+
+func (x *gnarkdSetKeyClient) Send(m *UploadRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *gnarkdSetKeyClient) CloseAndRecv() (*Empty, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(Empty)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *gnarkdClient) Prove(ctx context.Context, in *ProveRequest, opts ...grpc.CallOption) (*Job, error) {
+	out := new(Job)
+	err := c.cc.Invoke(ctx, "/gnarkd.Gnarkd/Prove", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gnarkdClient) GetJob(ctx context.Context, in *JobRequest, opts ...grpc.CallOption) (*Job, error) {
+	out := new(Job)
+	err := c.cc.Invoke(ctx, "/gnarkd.Gnarkd/GetJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gnarkdClient) Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error) {
+	out := new(VerifyResponse)
+	err := c.cc.Invoke(ctx, "/gnarkd.Gnarkd/Verify", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GnarkdServer is the server API for Gnarkd service.
+// All implementations must embed UnimplementedGnarkdServer
+// for forward compatibility
+type GnarkdServer interface {
+	// ListCircuits lists the registered circuits
+	ListCircuits(context.Context, *ListCircuitsRequest) (*ListCircuitsResponse, error)
+	// GetCircuit describes a circuit
+	GetCircuit(context.Context, *CircuitRequest) (*CircuitInfo, error)
+	// RegisterCircuit registers a compiled constraint system
+	RegisterCircuit(Gnarkd_RegisterCircuitServer) error
+	// SetSRS sets the KZG SRS of a PLONK circuit
+	SetSRS(Gnarkd_SetSRSServer) error
+	// Setup computes the keys of a circuit
+	Setup(context.Context, *CircuitRequest) (*CircuitInfo, error)
+	// GetKey downloads a key
+	GetKey(*KeyRequest, Gnarkd_GetKeyServer) error
+	// SetKey uploads a key
+	SetKey(Gnarkd_SetKeyServer) error
+	// Prove queues a proof, and returns its job
+	Prove(context.Context, *ProveRequest) (*Job, error)
+	// GetJob returns a job, waiting for it to finish if asked
+	GetJob(context.Context, *JobRequest) (*Job, error)
+	// Verify verifies a proof
+	Verify(context.Context, *VerifyRequest) (*VerifyResponse, error)
+	mustEmbedUnimplementedGnarkdServer()
+}
+
+// UnimplementedGnarkdServer must be embedded to have forward compatible implementations.
+type UnimplementedGnarkdServer struct {
+}
+
+func (UnimplementedGnarkdServer) ListCircuits(context.Context, *ListCircuitsRequest) (*ListCircuitsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCircuits not implemented")
+}
+func (UnimplementedGnarkdServer) GetCircuit(context.Context, *CircuitRequest) (*CircuitInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCircuit not implemented")
+}
+func (UnimplementedGnarkdServer) RegisterCircuit(Gnarkd_RegisterCircuitServer) error {
+	return status.Errorf(codes.Unimplemented, "method RegisterCircuit not implemented")
+}
+func (UnimplementedGnarkdServer) SetSRS(Gnarkd_SetSRSServer) error {
+	return status.Errorf(codes.Unimplemented, "method SetSRS not implemented")
+}
+func (UnimplementedGnarkdServer) Setup(context.Context, *CircuitRequest) (*CircuitInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Setup not implemented")
+}
+func (UnimplementedGnarkdServer) GetKey(*KeyRequest, Gnarkd_GetKeyServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetKey not implemented")
+}
+func (UnimplementedGnarkdServer) SetKey(Gnarkd_SetKeyServer) error {
+	return status.Errorf(codes.Unimplemented, "method SetKey not implemented")
+}
+func (UnimplementedGnarkdServer) Prove(context.Context, *ProveRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Prove not implemented")
+}
+func (UnimplementedGnarkdServer) GetJob(context.Context, *JobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJob not implemented")
+}
+func (UnimplementedGnarkdServer) Verify(context.Context, *VerifyRequest) (*VerifyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Verify not implemented")
+}
+func (UnimplementedGnarkdServer) mustEmbedUnimplementedGnarkdServer() {}
+
+// UnsafeGnarkdServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GnarkdServer will
+// result in compilation errors.
+type UnsafeGnarkdServer interface {
+	mustEmbedUnimplementedGnarkdServer()
+}
+
+func RegisterGnarkdServer(s grpc.ServiceRegistrar, srv GnarkdServer) {
+	s.RegisterService(&Gnarkd_ServiceDesc, srv)
+}
+
+func _Gnarkd_ListCircuits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCircuitsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GnarkdServer).ListCircuits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gnarkd.Gnarkd/ListCircuits",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GnarkdServer).ListCircuits(ctx, req.(*ListCircuitsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gnarkd_GetCircuit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CircuitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GnarkdServer).GetCircuit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gnarkd.Gnarkd/GetCircuit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GnarkdServer).GetCircuit(ctx, req.(*CircuitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gnarkd_RegisterCircuit_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GnarkdServer).RegisterCircuit(&gnarkdRegisterCircuitServer{stream})
+}
+
+type Gnarkd_RegisterCircuitServer interface {
+	SendAndClose(*CircuitInfo) error
+	Recv() (*RegisterCircuitRequest, error)
+	grpc.ServerStream
+}
+
+type gnarkdRegisterCircuitServer struct {
+	grpc.ServerStream
+}
+
+func (x *gnarkdRegisterCircuitServer) SendAndClose(m *CircuitInfo) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *gnarkdRegisterCircuitServer) Recv() (*RegisterCircuitRequest, error) {
+	m := new(RegisterCircuitRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Gnarkd_SetSRS_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GnarkdServer).SetSRS(&gnarkdSetSRSServer{stream})
+}
+
+type Gnarkd_SetSRSServer interface {
+	SendAndClose(*Empty) error
+	Recv() (*UploadRequest, error)
+	grpc.ServerStream
+}
+
+type gnarkdSetSRSServer struct {
+	grpc.ServerStream
+}
+
+func (x *gnarkdSetSRSServer) SendAndClose(m *Empty) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *gnarkdSetSRSServer) Recv() (*UploadRequest, error) {
+	m := new(UploadRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Gnarkd_Setup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CircuitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GnarkdServer).Setup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gnarkd.Gnarkd/Setup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GnarkdServer).Setup(ctx, req.(*CircuitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gnarkd_GetKey_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(KeyRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GnarkdServer).GetKey(m, &gnarkdGetKeyServer{stream})
+}
+
+type Gnarkd_GetKeyServer interface {
+	Send(*Chunk) error
+	grpc.ServerStream
+}
+
+type gnarkdGetKeyServer struct {
+	grpc.ServerStream
+}
+
+func (x *gnarkdGetKeyServer) Send(m *Chunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Gnarkd_SetKey_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GnarkdServer).SetKey(&gnarkdSetKeyServer{stream})
+}
+
+type Gnarkd_SetKeyServer interface {
+	SendAndClose(*Empty) error
+	Recv() (*UploadRequest, error)
+	grpc.ServerStream
+}
+
+type gnarkdSetKeyServer struct {
+	grpc.ServerStream
+}
+
+func (x *gnarkdSetKeyServer) SendAndClose(m *Empty) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *gnarkdSetKeyServer) Recv() (*UploadRequest, error) {
+	m := new(UploadRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Gnarkd_Prove_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GnarkdServer).Prove(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gnarkd.Gnarkd/Prove",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GnarkdServer).Prove(ctx, req.(*ProveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gnarkd_GetJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GnarkdServer).GetJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gnarkd.Gnarkd/GetJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GnarkdServer).GetJob(ctx, req.(*JobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gnarkd_Verify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GnarkdServer).Verify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gnarkd.Gnarkd/Verify",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GnarkdServer).Verify(ctx, req.(*VerifyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Gnarkd_ServiceDesc is the grpc.ServiceDesc for Gnarkd service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Gnarkd_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gnarkd.Gnarkd",
+	HandlerType: (*GnarkdServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListCircuits",
+			Handler:    _Gnarkd_ListCircuits_Handler,
+		},
+		{
+			MethodName: "GetCircuit",
+			Handler:    _Gnarkd_GetCircuit_Handler,
+		},
+		{
+			MethodName: "Setup",
+			Handler:    _Gnarkd_Setup_Handler,
+		},
+		{
+			MethodName: "Prove",
+			Handler:    _Gnarkd_Prove_Handler,
+		},
+		{
+			MethodName: "GetJob",
+			Handler:    _Gnarkd_GetJob_Handler,
+		},
+		{
+			MethodName: "Verify",
+			Handler:    _Gnarkd_Verify_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "RegisterCircuit",
+			Handler:       _Gnarkd_RegisterCircuit_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "SetSRS",
+			Handler:       _Gnarkd_SetSRS_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "GetKey",
+			Handler:       _Gnarkd_GetKey_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SetKey",
+			Handler:       _Gnarkd_SetKey_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "gnarkd.proto",
+}
@@ -0,0 +1,347 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/gnarkd/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// chunkSize is the size of the chunks of the keys streamed by GetKey
+const chunkSize = 64 * 1024
+
+// NewGRPCServer returns a gRPC server serving the API of the Server, the
+// gnarkd.Gnarkd service of gnarkd/pb/gnarkd.proto. Its messages are limited to
+// the maximal body size of the Server (see WithMaxBodySize), except the
+// streamed constraint systems, SRS and keys.
+func (s *Server) NewGRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	opts = append([]grpc.ServerOption{grpc.MaxRecvMsgSize(int(s.maxBodySize))}, opts...)
+	g := grpc.NewServer(opts...)
+	pb.RegisterGnarkdServer(g, &grpcServer{s: s})
+	return g
+}
+
+// grpcServer implements pb.GnarkdServer on top of a Server
+type grpcServer struct {
+	pb.UnimplementedGnarkdServer
+	s *Server
+}
+
+func (g *grpcServer) ListCircuits(ctx context.Context, req *pb.ListCircuitsRequest) (*pb.ListCircuitsResponse, error) {
+	circuits := g.s.Circuits()
+	res := &pb.ListCircuitsResponse{Circuits: make([]*pb.CircuitInfo, len(circuits))}
+	for i := range circuits {
+		res.Circuits[i] = toPBCircuitInfo(circuits[i])
+	}
+	return res, nil
+}
+
+func (g *grpcServer) GetCircuit(ctx context.Context, req *pb.CircuitRequest) (*pb.CircuitInfo, error) {
+	return g.circuitInfo(req.Name)
+}
+
+func (g *grpcServer) RegisterCircuit(stream pb.Gnarkd_RegisterCircuitServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return grpcError(err)
+	}
+	curveID, err := parseCurve(first.Curve)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	backendID, err := parseBackend(first.Backend)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	r := newChunkReader(first.Chunk, func() ([]byte, error) {
+		req, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		return req.Chunk, nil
+	})
+	if err := g.s.ReadCircuit(first.Name, curveID, backendID, bufio.NewReader(r)); err != nil {
+		return grpcError(err)
+	}
+	info, err := g.circuitInfo(first.Name)
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(info)
+}
+
+func (g *grpcServer) SetSRS(stream pb.Gnarkd_SetSRSServer) error {
+	first, r, err := receiveUpload(stream)
+	if err != nil {
+		return err
+	}
+	if err := g.s.ReadSRS(first.Name, r); err != nil {
+		return grpcError(err)
+	}
+	return stream.SendAndClose(&pb.Empty{})
+}
+
+func (g *grpcServer) Setup(ctx context.Context, req *pb.CircuitRequest) (*pb.CircuitInfo, error) {
+	if err := g.s.Setup(req.Name); err != nil {
+		return nil, grpcError(err)
+	}
+	return g.circuitInfo(req.Name)
+}
+
+// GetKey streams a key in chunks of chunkSize bytes, encoded directly in the
+// stream without being buffered
+func (g *grpcServer) GetKey(req *pb.KeyRequest, stream pb.Gnarkd_GetKeyServer) error {
+	w := bufio.NewWriterSize(chunkWriter(func(b []byte) error {
+		return stream.Send(&pb.Chunk{Data: b})
+	}), chunkSize)
+	if _, err := g.s.WriteKey(req.Name, keyKindOfPB(req.Kind), w); err != nil {
+		return grpcError(err)
+	}
+	return grpcError(w.Flush())
+}
+
+func (g *grpcServer) SetKey(stream pb.Gnarkd_SetKeyServer) error {
+	first, r, err := receiveUpload(stream)
+	if err != nil {
+		return err
+	}
+	if _, err := g.s.ReadKey(first.Name, keyKindOfPB(first.Kind), r); err != nil {
+		return grpcError(err)
+	}
+	return stream.SendAndClose(&pb.Empty{})
+}
+
+func (g *grpcServer) Prove(ctx context.Context, req *pb.ProveRequest) (*pb.Job, error) {
+	var fullWitness *witness.Witness
+	var err error
+	switch w := req.Witness.(type) {
+	case *pb.ProveRequest_BinaryWitness:
+		fullWitness, err = g.s.ReadWitness(req.Name, w.BinaryWitness)
+	case *pb.ProveRequest_JsonWitness:
+		var values map[string]interface{}
+		if err := decodeJSON(bytes.NewReader([]byte(w.JsonWitness)), &values); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		fullWitness, err = g.s.NewWitness(req.Name, values)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "missing witness")
+	}
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	id, err := g.s.Prove(req.Name, fullWitness)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	job, err := g.s.Job(id)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return toPBJob(job), nil
+}
+
+func (g *grpcServer) GetJob(ctx context.Context, req *pb.JobRequest) (*pb.Job, error) {
+	var job Job
+	var err error
+	if req.Wait {
+		job, err = g.s.Wait(ctx, req.Id)
+	} else {
+		job, err = g.s.Job(req.Id)
+	}
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return toPBJob(job), nil
+}
+
+func (g *grpcServer) Verify(ctx context.Context, req *pb.VerifyRequest) (*pb.VerifyResponse, error) {
+	var publicWitness *witness.Witness
+	var err error
+	switch w := req.PublicWitness.(type) {
+	case *pb.VerifyRequest_BinaryPublicWitness:
+		publicWitness, err = g.s.ReadWitness(req.Name, w.BinaryPublicWitness)
+	case *pb.VerifyRequest_JsonPublicWitness:
+		var values map[string]interface{}
+		if err := decodeJSON(bytes.NewReader([]byte(w.JsonPublicWitness)), &values); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		publicWitness, err = g.s.NewWitness(req.Name, values, frontend.PublicOnly())
+	default:
+		return nil, status.Error(codes.InvalidArgument, "missing public witness")
+	}
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	if err := g.s.Verify(req.Name, req.Proof, publicWitness); err != nil {
+		if errors.Is(err, ErrUnknownCircuit) || errors.Is(err, ErrMissingKeys) {
+			return nil, grpcError(err)
+		}
+		return &pb.VerifyResponse{Error: err.Error()}, nil
+	}
+	return &pb.VerifyResponse{Valid: true}, nil
+}
+
+func (g *grpcServer) circuitInfo(name string) (*pb.CircuitInfo, error) {
+	info, err := g.s.Circuit(name)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return toPBCircuitInfo(info), nil
+}
+
+// uploadStream is the stream of SetSRS and SetKey
+type uploadStream interface {
+	Recv() (*pb.UploadRequest, error)
+}
+
+// receiveUpload receives the first message of an upload, and returns it with
+// a reader of the chunks uploaded
+func receiveUpload(stream uploadStream) (*pb.UploadRequest, io.Reader, error) {
+	first, err := stream.Recv()
+	if err != nil {
+		return nil, nil, grpcError(err)
+	}
+	r := newChunkReader(first.Chunk, func() ([]byte, error) {
+		req, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		return req.Chunk, nil
+	})
+	return first, bufio.NewReader(r), nil
+}
+
+// chunkReader reads the chunks received by next, until it returns io.EOF
+type chunkReader struct {
+	chunk []byte
+	next  func() ([]byte, error)
+}
+
+func newChunkReader(first []byte, next func() ([]byte, error)) *chunkReader {
+	return &chunkReader{chunk: first, next: next}
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.chunk) == 0 {
+		chunk, err := r.next()
+		if err != nil {
+			return 0, err
+		}
+		r.chunk = chunk
+	}
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+	return n, nil
+}
+
+// chunkWriter sends the bytes written in chunks of at most chunkSize bytes
+type chunkWriter func([]byte) error
+
+func (send chunkWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := len(p)
+		if n > chunkSize {
+			n = chunkSize
+		}
+		if err := send(p[:n]); err != nil {
+			return written, err
+		}
+		written += n
+		p = p[n:]
+	}
+	return written, nil
+}
+
+func keyKindOfPB(kind pb.KeyKind) KeyKind {
+	if kind == pb.KeyKind_PROVING_KEY {
+		return ProvingKey
+	}
+	return VerifyingKey
+}
+
+func toPBCircuitInfo(info CircuitInfo) *pb.CircuitInfo {
+	return &pb.CircuitInfo{
+		Name:          info.Name,
+		Curve:         info.Curve,
+		Backend:       info.Backend,
+		NbConstraints: int64(info.NbConstraints),
+		NbPublic:      int64(info.NbPublic),
+		NbSecret:      int64(info.NbSecret),
+		Ready:         info.Ready,
+	}
+}
+
+func toPBJob(job Job) *pb.Job {
+	res := &pb.Job{
+		Id:      job.ID,
+		Circuit: job.Circuit,
+		Error:   job.Error,
+		Proof:   job.Proof,
+	}
+	switch job.Status {
+	case JobQueued:
+		res.Status = pb.JobStatus_QUEUED
+	case JobRunning:
+		res.Status = pb.JobStatus_RUNNING
+	case JobDone:
+		res.Status = pb.JobStatus_DONE
+	case JobFailed:
+		res.Status = pb.JobStatus_FAILED
+	}
+	return res
+}
+
+// grpcError returns the gRPC status matching an error of the Server
+func grpcError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, ErrUnknownCircuit), errors.Is(err, ErrUnknownJob):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrCircuitExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, ErrMissingKeys), errors.Is(err, ErrMissingSRS):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, ErrQueueFull):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, ErrClosed):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+}
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/gnarkd/pb"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGRPC(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	s, err := New(WithWorkers(2))
+	assert.NoError(err)
+	defer s.Close()
+	lis := bufconn.Listen(1 << 20)
+	g := s.NewGRPCServer()
+	go func() { _ = g.Serve(lis) }()
+	defer g.Stop()
+
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(err)
+	defer conn.Close()
+	client := pb.NewGnarkdClient(conn)
+
+	assertCode := func(expected codes.Code, err error) {
+		t.Helper()
+		assert.Equal(expected, status.Code(err), "%v", err)
+	}
+
+	// upload streams the data in small chunks, the first message holding the
+	// parameters of the upload
+	upload := func(data []byte, send func(chunk []byte, first bool) error) {
+		t.Helper()
+		for first := true; first || len(data) > 0; first = false {
+			n := len(data)
+			if n > 1000 {
+				n = 1000
+			}
+			assert.NoError(send(data[:n], first))
+			data = data[n:]
+		}
+	}
+
+	// groth16 circuit, uploaded as a compiled constraint system
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &cubicCircuit{})
+	assert.NoError(err)
+	var buf bytes.Buffer
+	_, err = ccs.WriteTo(&buf)
+	assert.NoError(err)
+	register := func() (*pb.CircuitInfo, error) {
+		stream, err := client.RegisterCircuit(ctx)
+		assert.NoError(err)
+		upload(buf.Bytes(), func(chunk []byte, first bool) error {
+			req := &pb.RegisterCircuitRequest{Chunk: chunk}
+			if first {
+				req.Name, req.Curve, req.Backend = "cubic", "bn254", "groth16"
+			}
+			return stream.Send(req)
+		})
+		return stream.CloseAndRecv()
+	}
+	info, err := register()
+	assert.NoError(err)
+	assert.Equal("cubic", info.Name)
+	assert.Equal(int64(ccs.GetNbConstraints()), info.NbConstraints)
+	_, err = register()
+	assertCode(codes.AlreadyExists, err)
+
+	// plonk circuit, registered in Go
+	assert.NoError(s.RegisterCircuit("cubic-plonk", ecc.BLS12_381, backend.PLONK, &cubicCircuit{}))
+	srs, err := test.NewKZGSRS(s.circuits["cubic-plonk"].ccs)
+	assert.NoError(err)
+	_, err = client.Setup(ctx, &pb.CircuitRequest{Name: "cubic-plonk"})
+	assertCode(codes.FailedPrecondition, err)
+	buf.Reset()
+	_, err = srs.WriteTo(&buf)
+	assert.NoError(err)
+	srsStream, err := client.SetSRS(ctx)
+	assert.NoError(err)
+	upload(buf.Bytes(), func(chunk []byte, first bool) error {
+		req := &pb.UploadRequest{Chunk: chunk}
+		if first {
+			req.Name = "cubic-plonk"
+		}
+		return srsStream.Send(req)
+	})
+	_, err = srsStream.CloseAndRecv()
+	assert.NoError(err)
+
+	circuits, err := client.ListCircuits(ctx, &pb.ListCircuitsRequest{})
+	assert.NoError(err)
+	assert.Len(circuits.Circuits, 2)
+
+	for _, name := range []string{"cubic", "cubic-plonk"} {
+		witness := &pb.ProveRequest_JsonWitness{JsonWitness: `{"x": 3, "Y": 35}`}
+		_, err = client.Prove(ctx, &pb.ProveRequest{Name: name, Witness: witness})
+		assertCode(codes.FailedPrecondition, err)
+		info, err := client.Setup(ctx, &pb.CircuitRequest{Name: name})
+		assert.NoError(err)
+		assert.True(info.Ready)
+
+		// keys round trip
+		for _, kind := range []pb.KeyKind{pb.KeyKind_PROVING_KEY, pb.KeyKind_VERIFYING_KEY} {
+			keyStream, err := client.GetKey(ctx, &pb.KeyRequest{Name: name, Kind: kind})
+			assert.NoError(err)
+			buf.Reset()
+			for {
+				chunk, err := keyStream.Recv()
+				if err == io.EOF {
+					break
+				}
+				assert.NoError(err)
+				assert.LessOrEqual(len(chunk.Data), chunkSize)
+				buf.Write(chunk.Data)
+			}
+			keyUpload, err := client.SetKey(ctx)
+			assert.NoError(err)
+			upload(buf.Bytes(), func(chunk []byte, first bool) error {
+				req := &pb.UploadRequest{Chunk: chunk}
+				if first {
+					req.Name, req.Kind = name, kind
+				}
+				return keyUpload.Send(req)
+			})
+			_, err = keyUpload.CloseAndRecv()
+			assert.NoError(err)
+		}
+
+		job, err := client.Prove(ctx, &pb.ProveRequest{Name: name, Witness: witness})
+		assert.NoError(err)
+		job, err = client.GetJob(ctx, &pb.JobRequest{Id: job.Id, Wait: true})
+		assert.NoError(err)
+		assert.Equal(pb.JobStatus_DONE, job.Status, job.Error)
+
+		verified, err := client.Verify(ctx, &pb.VerifyRequest{Name: name, Proof: job.Proof,
+			PublicWitness: &pb.VerifyRequest_JsonPublicWitness{JsonPublicWitness: `{"Y": 35}`}})
+		assert.NoError(err)
+		assert.True(verified.Valid, verified.Error)
+		publicWitness, err := s.NewWitness(name, map[string]interface{}{"Y": 36}, frontend.PublicOnly())
+		assert.NoError(err)
+		binaryPublicWitness, err := publicWitness.MarshalBinary()
+		assert.NoError(err)
+		verified, err = client.Verify(ctx, &pb.VerifyRequest{Name: name, Proof: job.Proof,
+			PublicWitness: &pb.VerifyRequest_BinaryPublicWitness{BinaryPublicWitness: binaryPublicWitness}})
+		assert.NoError(err)
+		assert.False(verified.Valid)
+
+		// invalid witness, in binary
+		fullWitness, err := s.NewWitness(name, map[string]interface{}{"x": 3, "Y": 36})
+		assert.NoError(err)
+		binaryWitness, err := fullWitness.MarshalBinary()
+		assert.NoError(err)
+		job, err = client.Prove(ctx, &pb.ProveRequest{Name: name, Witness: &pb.ProveRequest_BinaryWitness{BinaryWitness: binaryWitness}})
+		assert.NoError(err)
+		job, err = client.GetJob(ctx, &pb.JobRequest{Id: job.Id, Wait: true})
+		assert.NoError(err)
+		assert.Equal(pb.JobStatus_FAILED, job.Status)
+	}
+
+	_, err = client.GetCircuit(ctx, &pb.CircuitRequest{Name: "unknown"})
+	assertCode(codes.NotFound, err)
+	_, err = client.GetJob(ctx, &pb.JobRequest{Id: "unknown"})
+	assertCode(codes.NotFound, err)
+}
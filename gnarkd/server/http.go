@@ -0,0 +1,303 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+)
+
+// ServeHTTP serves the API of the Server:
+//
+//	GET  /circuits                                 list the circuits
+//	PUT  /circuits/{name}?curve=bn254&backend=groth16  register a constraint system (binary)
+//	GET  /circuits/{name}                          describe a circuit
+//	PUT  /circuits/{name}/srs                      set the KZG SRS of a PLONK circuit (binary)
+//	POST /circuits/{name}/setup                    compute the keys
+//	GET  /circuits/{name}/pk, /circuits/{name}/vk  download a key (binary)
+//	PUT  /circuits/{name}/pk, /circuits/{name}/vk  upload a key (binary)
+//	POST /circuits/{name}/prove                    queue a proof, returns a Job
+//	POST /circuits/{name}/verify                   verify a proof
+//	GET  /jobs/{id}[?wait=true]                    get a Job, waiting for it to finish if asked
+//
+// Keys, SRS and constraint systems are streamed in their binary encoding
+// (WriteTo). The other bodies are JSON, and errors are {"error": "..."}.
+//
+// The body of prove is {"witness": {...}}, where the witness maps the paths of
+// the variables to their values as in frontend.NewWitnessFromMap, or the
+// binary encoding of the full witness (see witness.Witness.MarshalBinary) with
+// the Content-Type application/octet-stream. The body of verify is
+// {"proof": "<base64>", "publicWitness": {...}}.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(path) == 1 && path[0] == "circuits" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.Circuits())
+	case len(path) == 2 && path[0] == "circuits":
+		s.serveCircuit(w, r, path[1])
+	case len(path) == 3 && path[0] == "circuits":
+		s.serveCircuitAction(w, r, path[1], path[2])
+	case len(path) == 2 && path[0] == "jobs" && r.Method == http.MethodGet:
+		s.serveJob(w, r, path[1])
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("%s %s: not found", r.Method, r.URL.Path))
+	}
+}
+
+func (s *Server) serveCircuit(w http.ResponseWriter, r *http.Request, name string) {
+	switch r.Method {
+	case http.MethodGet:
+		info, err := s.Circuit(name)
+		if err != nil {
+			writeError(w, statusOf(err), err)
+			return
+		}
+		writeJSON(w, http.StatusOK, info)
+	case http.MethodPut:
+		curveID, err := parseCurve(r.URL.Query().Get("curve"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		backendID, err := parseBackend(r.URL.Query().Get("backend"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := s.ReadCircuit(name, curveID, backendID, bufio.NewReader(r.Body)); err != nil {
+			writeError(w, statusOf(err), err)
+			return
+		}
+		info, _ := s.Circuit(name)
+		writeJSON(w, http.StatusCreated, info)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("%s %s: method not allowed", r.Method, r.URL.Path))
+	}
+}
+
+func (s *Server) serveCircuitAction(w http.ResponseWriter, r *http.Request, name, action string) {
+	switch {
+	case action == "srs" && r.Method == http.MethodPut:
+		if err := s.ReadSRS(name, bufio.NewReader(r.Body)); err != nil {
+			writeError(w, statusOf(err), err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case action == "setup" && r.Method == http.MethodPost:
+		if err := s.Setup(name); err != nil {
+			writeError(w, statusOf(err), err)
+			return
+		}
+		info, _ := s.Circuit(name)
+		writeJSON(w, http.StatusOK, info)
+	case (action == "pk" || action == "vk") && r.Method == http.MethodGet:
+		s.serveKey(w, name, keyKindOf(action))
+	case (action == "pk" || action == "vk") && r.Method == http.MethodPut:
+		if _, err := s.ReadKey(name, keyKindOf(action), bufio.NewReader(r.Body)); err != nil {
+			writeError(w, statusOf(err), err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case action == "prove" && r.Method == http.MethodPost:
+		s.serveProve(w, r, name)
+	case action == "verify" && r.Method == http.MethodPost:
+		s.serveVerify(w, r, name)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("%s %s: not found", r.Method, r.URL.Path))
+	}
+}
+
+// serveKey streams a key: it is encoded directly in the response, without
+// being buffered, so it can't switch to an error once the first bytes are
+// sent.
+func (s *Server) serveKey(w http.ResponseWriter, name string, kind KeyKind) {
+	if _, err := s.Circuit(name); err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	bw := bufio.NewWriter(w)
+	if _, err := s.WriteKey(name, kind, bw); err != nil {
+		if errors.Is(err, ErrMissingKeys) {
+			writeError(w, statusOf(err), err)
+			return
+		}
+		s.log.Error().Err(err).Str("circuit", name).Msgf("streaming the %s failed", kind)
+		return
+	}
+	if err := bw.Flush(); err != nil {
+		s.log.Error().Err(err).Str("circuit", name).Msgf("streaming the %s failed", kind)
+	}
+}
+
+type proveRequest struct {
+	Witness map[string]interface{} `json:"witness"`
+}
+
+func (s *Server) serveProve(w http.ResponseWriter, r *http.Request, name string) {
+	body := http.MaxBytesReader(w, r.Body, s.maxBodySize)
+	var fullWitness *witness.Witness
+	if r.Header.Get("Content-Type") == "application/octet-stream" {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if fullWitness, err = s.ReadWitness(name, data); err != nil {
+			writeError(w, statusOf(err), err)
+			return
+		}
+	} else {
+		var req proveRequest
+		if err := decodeJSON(body, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		var err error
+		if fullWitness, err = s.NewWitness(name, req.Witness); err != nil {
+			writeError(w, statusOf(err), err)
+			return
+		}
+	}
+
+	id, err := s.Prove(name, fullWitness)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	job, err := s.Job(id)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	w.Header().Set("Location", "/jobs/"+id)
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+type verifyRequest struct {
+	Proof         []byte                 `json:"proof"`
+	PublicWitness map[string]interface{} `json:"publicWitness"`
+}
+
+type verifyResponse struct {
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+func (s *Server) serveVerify(w http.ResponseWriter, r *http.Request, name string) {
+	var req verifyRequest
+	if err := decodeJSON(http.MaxBytesReader(w, r.Body, s.maxBodySize), &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	publicWitness, err := s.NewWitness(name, req.PublicWitness, frontend.PublicOnly())
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	if err := s.Verify(name, req.Proof, publicWitness); err != nil {
+		if errors.Is(err, ErrUnknownCircuit) || errors.Is(err, ErrMissingKeys) {
+			writeError(w, statusOf(err), err)
+			return
+		}
+		writeJSON(w, http.StatusOK, verifyResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, verifyResponse{Valid: true})
+}
+
+func (s *Server) serveJob(w http.ResponseWriter, r *http.Request, id string) {
+	var job Job
+	var err error
+	if r.URL.Query().Get("wait") == "true" {
+		job, err = s.Wait(r.Context(), id)
+	} else {
+		job, err = s.Job(id)
+	}
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+func keyKindOf(action string) KeyKind {
+	if action == "pk" {
+		return ProvingKey
+	}
+	return VerifyingKey
+}
+
+func parseCurve(s string) (ecc.ID, error) {
+	for _, id := range ecc.Implemented() {
+		if strings.EqualFold(id.String(), s) {
+			return id, nil
+		}
+	}
+	return ecc.UNKNOWN, fmt.Errorf("unknown curve %q", s)
+}
+
+func parseBackend(s string) (backend.ID, error) {
+	for _, id := range backend.Implemented() {
+		if strings.EqualFold(id.String(), s) {
+			return id, nil
+		}
+	}
+	return backend.UNKNOWN, fmt.Errorf("unknown backend %q", s)
+}
+
+// statusOf returns the HTTP status matching an error of the Server
+func statusOf(err error) int {
+	switch {
+	case errors.Is(err, ErrUnknownCircuit), errors.Is(err, ErrUnknownJob):
+		return http.StatusNotFound
+	case errors.Is(err, ErrCircuitExists), errors.Is(err, ErrMissingKeys), errors.Is(err, ErrMissingSRS):
+		return http.StatusConflict
+	case errors.Is(err, ErrQueueFull), errors.Is(err, ErrClosed):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func decodeJSON(r io.Reader, v interface{}) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, struct {
+		Error string `json:"error"`
+	}{err.Error()})
+}
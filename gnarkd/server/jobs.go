@@ -0,0 +1,168 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/consensys/gnark/backend/witness"
+)
+
+// JobStatus is the state of a proving job
+type JobStatus string
+
+const (
+	JobQueued  JobStatus = "queued"
+	JobRunning JobStatus = "running"
+	JobDone    JobStatus = "done"
+	JobFailed  JobStatus = "failed"
+)
+
+// Job is a snapshot of a proving job
+type Job struct {
+	ID      string    `json:"id"`
+	Circuit string    `json:"circuit"`
+	Status  JobStatus `json:"status"`
+	Error   string    `json:"error,omitempty"`
+	// Proof is the binary encoding of the proof (see Proof.WriteTo) once the
+	// job is done
+	Proof []byte `json:"proof,omitempty"`
+}
+
+// job is a proving job, guarded by Server.mu
+type job struct {
+	Job
+	circuit  *circuit
+	witness  *witness.Witness // released once the job is picked
+	done     chan struct{}    // closed when the job is done or failed
+	finished time.Time
+}
+
+// Prove queues the proof of the full witness of a circuit and returns the ID
+// of the job. It fails with ErrQueueFull if the queue is full.
+func (s *Server) Prove(name string, fullWitness *witness.Witness) (string, error) {
+	c, err := s.circuit(name)
+	if err != nil {
+		return "", err
+	}
+	c.mu.RLock()
+	ready := c.pk != nil
+	c.mu.RUnlock()
+	if !ready {
+		return "", ErrMissingKeys
+	}
+	id, err := newJobID()
+	if err != nil {
+		return "", err
+	}
+	j := &job{
+		Job:     Job{ID: id, Circuit: name, Status: JobQueued},
+		circuit: c,
+		witness: fullWitness,
+		done:    make(chan struct{}),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return "", ErrClosed
+	}
+	s.dropExpiredJobs()
+	select {
+	case s.queue <- j:
+	default:
+		return "", ErrQueueFull
+	}
+	s.jobs[id] = j
+	return id, nil
+}
+
+// Job returns the state of a job
+func (s *Server) Job(id string) (Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return Job{}, fmt.Errorf("%w: %s", ErrUnknownJob, id)
+	}
+	return j.Job, nil
+}
+
+// Wait waits for a job to be done or failed, or for ctx to be done
+func (s *Server) Wait(ctx context.Context, id string) (Job, error) {
+	s.mu.RLock()
+	j, ok := s.jobs[id]
+	s.mu.RUnlock()
+	if !ok {
+		return Job{}, fmt.Errorf("%w: %s", ErrUnknownJob, id)
+	}
+	select {
+	case <-j.done:
+		return s.Job(id)
+	case <-ctx.Done():
+		return Job{}, ctx.Err()
+	}
+}
+
+func (s *Server) work() {
+	defer s.wg.Done()
+	for j := range s.queue {
+		s.mu.Lock()
+		j.Status = JobRunning
+		fullWitness := j.witness
+		j.witness = nil
+		s.mu.Unlock()
+
+		start := time.Now()
+		proof, err := j.circuit.prove(fullWitness)
+
+		s.mu.Lock()
+		if err != nil {
+			j.Status, j.Error = JobFailed, err.Error()
+			s.log.Error().Err(err).Str("job", j.ID).Str("circuit", j.Circuit).Msg("proving failed")
+		} else {
+			j.Status, j.Proof = JobDone, proof
+			s.log.Info().Str("job", j.ID).Str("circuit", j.Circuit).Dur("took", time.Since(start)).Msg("proof done")
+		}
+		j.finished = time.Now()
+		close(j.done)
+		s.mu.Unlock()
+	}
+}
+
+// dropExpiredJobs drops the jobs finished for longer than the job TTL. Called
+// with s.mu locked.
+func (s *Server) dropExpiredJobs() {
+	now := time.Now()
+	for id, j := range s.jobs {
+		if !j.finished.IsZero() && now.Sub(j.finished) > s.jobTTL {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+func newJobID() (string, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id[:]), nil
+}
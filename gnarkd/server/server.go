@@ -0,0 +1,588 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package server implements gnarkd, a service running the setup, the prover
+// and the verifier of registered circuits.
+//
+// Circuits are registered in Go with RegisterCircuit, or as compiled
+// constraint systems (see frontend.CompiledConstraintSystem.WriteTo). Their
+// keys are computed by Setup or uploaded. Proofs are computed asynchronously:
+// Prove queues a job, picked by a fixed pool of workers. A prover already uses
+// all the cores, and memory proportional to the size of the circuit, so the
+// pool bounds the memory used by the service; the queue is bounded too, and
+// Prove fails with ErrQueueFull once it is full.
+//
+// The Server is exposed over HTTP by its ServeHTTP method, and over gRPC by the
+// server returned by NewGRPCServer (the gnarkd.Gnarkd service of
+// gnarkd/pb/gnarkd.proto). Both stream the constraint systems, SRS and keys.
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/logger"
+	"github.com/rs/zerolog"
+
+	kzg_bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/fr/kzg"
+	kzg_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr/kzg"
+	kzg_bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/fr/kzg"
+	kzg_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	kzg_bw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/fr/kzg"
+	kzg_bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/fr/kzg"
+)
+
+var (
+	ErrUnknownCircuit = errors.New("unknown circuit")
+	ErrCircuitExists  = errors.New("circuit already registered")
+	ErrMissingKeys    = errors.New("the keys of the circuit are not set, run the setup or upload them")
+	ErrMissingSRS     = errors.New("the setup of a PLONK circuit needs a KZG SRS")
+	ErrUnknownJob     = errors.New("unknown job")
+	ErrQueueFull      = errors.New("the proving queue is full")
+	ErrClosed         = errors.New("server closed")
+)
+
+// Option configures a Server
+type Option func(*config) error
+
+type config struct {
+	workers     int
+	queueSize   int
+	jobTTL      time.Duration
+	maxBodySize int64
+	log         zerolog.Logger
+}
+
+// WithWorkers sets the number of proofs computed concurrently (1 by default)
+func WithWorkers(n int) Option {
+	return func(c *config) error {
+		if n < 1 {
+			return errors.New("at least one worker is needed")
+		}
+		c.workers = n
+		return nil
+	}
+}
+
+// WithQueueSize sets the number of jobs waiting for a worker after which
+// Prove fails with ErrQueueFull (64 by default)
+func WithQueueSize(n int) Option {
+	return func(c *config) error {
+		if n < 0 {
+			return errors.New("negative queue size")
+		}
+		c.queueSize = n
+		return nil
+	}
+}
+
+// WithJobTTL sets how long the proof of a finished job is kept (10 minutes by
+// default)
+func WithJobTTL(d time.Duration) Option {
+	return func(c *config) error {
+		c.jobTTL = d
+		return nil
+	}
+}
+
+// WithMaxBodySize sets the maximal size in bytes of the witnesses and proofs
+// received over HTTP (64MiB by default). Keys and constraint systems are
+// streamed and not limited.
+func WithMaxBodySize(n int64) Option {
+	return func(c *config) error {
+		c.maxBodySize = n
+		return nil
+	}
+}
+
+// WithLogger sets the logger of the Server (gnark's logger by default)
+func WithLogger(l zerolog.Logger) Option {
+	return func(c *config) error {
+		c.log = l
+		return nil
+	}
+}
+
+// Server holds the registered circuits and the proving jobs
+type Server struct {
+	config
+
+	mu       sync.RWMutex
+	circuits map[string]*circuit
+	jobs     map[string]*job
+	closed   bool
+
+	queue chan *job
+	wg    sync.WaitGroup
+}
+
+// New returns a Server and starts its workers. Close stops them.
+func New(opts ...Option) (*Server, error) {
+	s := &Server{
+		config: config{
+			workers:     1,
+			queueSize:   64,
+			jobTTL:      10 * time.Minute,
+			maxBodySize: 64 << 20,
+			log:         logger.Logger().With().Str("component", "gnarkd").Logger(),
+		},
+		circuits: make(map[string]*circuit),
+		jobs:     make(map[string]*job),
+	}
+	for _, opt := range opts {
+		if err := opt(&s.config); err != nil {
+			return nil, err
+		}
+	}
+	s.queue = make(chan *job, s.queueSize)
+	for i := 0; i < s.workers; i++ {
+		s.wg.Add(1)
+		go s.work()
+	}
+	return s, nil
+}
+
+// Close stops accepting jobs and waits for the queued ones to finish
+func (s *Server) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	close(s.queue)
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// CircuitInfo describes a registered circuit
+type CircuitInfo struct {
+	Name          string `json:"name"`
+	Curve         string `json:"curve"`
+	Backend       string `json:"backend"`
+	NbConstraints int    `json:"nbConstraints"`
+	NbPublic      int    `json:"nbPublic"`
+	NbSecret      int    `json:"nbSecret"`
+	Ready         bool   `json:"ready"` // true if the keys are set
+}
+
+// circuit is a registered circuit. Its constraint system is immutable, its
+// keys and SRS are guarded by mu.
+type circuit struct {
+	name      string
+	backendID backend.ID
+	ccs       frontend.CompiledConstraintSystem
+
+	mu  sync.RWMutex
+	srs kzg.SRS
+	pk  key
+	vk  key
+}
+
+// key is a groth16 or plonk ProvingKey or VerifyingKey
+type key interface {
+	io.WriterTo
+	io.ReaderFrom
+}
+
+// KeyKind selects the proving or the verifying key of a circuit
+type KeyKind uint8
+
+const (
+	ProvingKey KeyKind = iota
+	VerifyingKey
+)
+
+func (k KeyKind) String() string {
+	if k == ProvingKey {
+		return "proving key"
+	}
+	return "verifying key"
+}
+
+// RegisterCircuit compiles circuit over curveID for backendID and registers
+// it under name
+func (s *Server) RegisterCircuit(name string, curveID ecc.ID, backendID backend.ID, c frontend.Circuit, opts ...frontend.CompileOption) error {
+	var newBuilder frontend.NewBuilder
+	switch backendID {
+	case backend.GROTH16:
+		newBuilder = r1cs.NewBuilder
+	case backend.PLONK:
+		newBuilder = scs.NewBuilder
+	default:
+		return fmt.Errorf("unsupported backend %s", backendID)
+	}
+	ccs, err := frontend.Compile(curveID, newBuilder, c, opts...)
+	if err != nil {
+		return err
+	}
+	return s.Register(name, backendID, ccs)
+}
+
+// Register registers the compiled constraint system ccs under name, to be
+// proven with backendID
+func (s *Server) Register(name string, backendID backend.ID, ccs frontend.CompiledConstraintSystem) error {
+	expected, err := newCS(ccs.CurveID(), backendID)
+	if err != nil {
+		return err
+	}
+	if reflect.TypeOf(ccs) != reflect.TypeOf(expected) {
+		return fmt.Errorf("%T is not a %s constraint system", ccs, backendID)
+	}
+	if ccs.GetSchema() == nil {
+		return errors.New("the constraint system has no schema")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.circuits[name]; ok {
+		return fmt.Errorf("%w: %s", ErrCircuitExists, name)
+	}
+	s.circuits[name] = &circuit{name: name, backendID: backendID, ccs: ccs}
+	s.log.Info().Str("circuit", name).Str("backend", backendID.String()).Str("curve", ccs.CurveID().String()).
+		Int("nbConstraints", ccs.GetNbConstraints()).Msg("circuit registered")
+	return nil
+}
+
+// ReadCircuit reads the binary encoding of a constraint system over curveID
+// for backendID from r, and registers it under name
+func (s *Server) ReadCircuit(name string, curveID ecc.ID, backendID backend.ID, r io.Reader) error {
+	ccs, err := newCS(curveID, backendID)
+	if err != nil {
+		return err
+	}
+	if _, err := ccs.ReadFrom(r); err != nil {
+		return err
+	}
+	return s.Register(name, backendID, ccs)
+}
+
+// Circuits returns the registered circuits
+func (s *Server) Circuits() []CircuitInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res := make([]CircuitInfo, 0, len(s.circuits))
+	for _, c := range s.circuits {
+		res = append(res, c.info())
+	}
+	return res
+}
+
+// Circuit returns the description of the circuit registered under name
+func (s *Server) Circuit(name string) (CircuitInfo, error) {
+	c, err := s.circuit(name)
+	if err != nil {
+		return CircuitInfo{}, err
+	}
+	return c.info(), nil
+}
+
+func (s *Server) circuit(name string) (*circuit, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.circuits[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCircuit, name)
+	}
+	return c, nil
+}
+
+// SetSRS sets the KZG SRS of a PLONK circuit, used by its setup and by the
+// keys read with ReadKey
+func (s *Server) SetSRS(name string, srs kzg.SRS) error {
+	c, err := s.circuit(name)
+	if err != nil {
+		return err
+	}
+	if c.backendID != backend.PLONK {
+		return fmt.Errorf("%s: only PLONK circuits use a KZG SRS", name)
+	}
+	c.mu.Lock()
+	c.srs = srs
+	c.mu.Unlock()
+	return nil
+}
+
+// ReadSRS reads the binary encoding of the KZG SRS of a PLONK circuit from r
+// (see SetSRS)
+func (s *Server) ReadSRS(name string, r io.Reader) error {
+	c, err := s.circuit(name)
+	if err != nil {
+		return err
+	}
+	srs, err := newSRS(c.ccs.CurveID())
+	if err != nil {
+		return err
+	}
+	if _, err := srs.ReadFrom(r); err != nil {
+		return err
+	}
+	return s.SetSRS(name, srs)
+}
+
+// Setup computes the keys of a circuit. PLONK circuits need a KZG SRS (see
+// SetSRS).
+func (s *Server) Setup(name string) error {
+	c, err := s.circuit(name)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	start := time.Now()
+	switch c.backendID {
+	case backend.GROTH16:
+		pk, vk, err := groth16.Setup(c.ccs)
+		if err != nil {
+			return err
+		}
+		c.pk, c.vk = pk, vk
+	case backend.PLONK:
+		if c.srs == nil {
+			return ErrMissingSRS
+		}
+		pk, vk, err := plonk.Setup(c.ccs, c.srs)
+		if err != nil {
+			return err
+		}
+		c.pk, c.vk = pk, vk
+	}
+	s.log.Info().Str("circuit", name).Dur("took", time.Since(start)).Msg("setup done")
+	return nil
+}
+
+// WriteKey writes the binary encoding of a key of a circuit to w
+func (s *Server) WriteKey(name string, kind KeyKind, w io.Writer) (int64, error) {
+	c, err := s.circuit(name)
+	if err != nil {
+		return 0, err
+	}
+	c.mu.RLock()
+	k := c.key(kind)
+	c.mu.RUnlock()
+	if k == nil {
+		return 0, ErrMissingKeys
+	}
+	return k.WriteTo(w)
+}
+
+// ReadKey reads the binary encoding of a key of a circuit from r. The keys
+// of PLONK circuits are read after their KZG SRS (see SetSRS).
+func (s *Server) ReadKey(name string, kind KeyKind, r io.Reader) (int64, error) {
+	c, err := s.circuit(name)
+	if err != nil {
+		return 0, err
+	}
+	curveID := c.ccs.CurveID()
+
+	var k key
+	switch {
+	case c.backendID == backend.GROTH16 && kind == ProvingKey:
+		k = groth16.NewProvingKey(curveID)
+	case c.backendID == backend.GROTH16:
+		k = groth16.NewVerifyingKey(curveID)
+	case kind == ProvingKey:
+		k = plonk.NewProvingKey(curveID)
+	default:
+		k = plonk.NewVerifyingKey(curveID)
+	}
+	n, err := k.ReadFrom(r)
+	if err != nil {
+		return n, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.backendID == backend.PLONK {
+		if c.srs == nil {
+			return n, ErrMissingSRS
+		}
+		if err := k.(interface{ InitKZG(kzg.SRS) error }).InitKZG(c.srs); err != nil {
+			return n, err
+		}
+	}
+	if kind == ProvingKey {
+		c.pk = k
+	} else {
+		c.vk = k
+	}
+	return n, nil
+}
+
+// Verify checks proof, the binary encoding of a proof of the circuit, against
+// the public witness
+func (s *Server) Verify(name string, proof []byte, publicWitness *witness.Witness) error {
+	c, err := s.circuit(name)
+	if err != nil {
+		return err
+	}
+	c.mu.RLock()
+	vk := c.vk
+	c.mu.RUnlock()
+	if vk == nil {
+		return ErrMissingKeys
+	}
+
+	curveID := c.ccs.CurveID()
+	switch c.backendID {
+	case backend.GROTH16:
+		p := groth16.NewProof(curveID)
+		if _, err := p.ReadFrom(bytes.NewReader(proof)); err != nil {
+			return err
+		}
+		return groth16.Verify(p, vk.(groth16.VerifyingKey), publicWitness)
+	default:
+		p := plonk.NewProof(curveID)
+		if _, err := p.ReadFrom(bytes.NewReader(proof)); err != nil {
+			return err
+		}
+		return plonk.Verify(p, vk.(plonk.VerifyingKey), publicWitness)
+	}
+}
+
+// NewWitness returns the witness of the circuit from the values of its
+// variables, as frontend.NewWitnessFromMap
+func (s *Server) NewWitness(name string, values map[string]interface{}, opts ...frontend.WitnessOption) (*witness.Witness, error) {
+	c, err := s.circuit(name)
+	if err != nil {
+		return nil, err
+	}
+	return frontend.NewWitnessFromMap(c.ccs.GetSchema(), values, c.ccs.CurveID(), opts...)
+}
+
+// ReadWitness returns the witness of the circuit from its binary encoding (see
+// witness.Witness.MarshalBinary)
+func (s *Server) ReadWitness(name string, data []byte) (*witness.Witness, error) {
+	c, err := s.circuit(name)
+	if err != nil {
+		return nil, err
+	}
+	w, err := witness.New(c.ccs.CurveID(), c.ccs.GetSchema())
+	if err != nil {
+		return nil, err
+	}
+	if err := w.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (c *circuit) info() CircuitInfo {
+	schema := c.ccs.GetSchema()
+	c.mu.RLock()
+	ready := c.pk != nil && c.vk != nil
+	c.mu.RUnlock()
+	return CircuitInfo{
+		Name:          c.name,
+		Curve:         c.ccs.CurveID().String(),
+		Backend:       c.backendID.String(),
+		NbConstraints: c.ccs.GetNbConstraints(),
+		NbPublic:      schema.NbPublic,
+		NbSecret:      schema.NbSecret,
+		Ready:         ready,
+	}
+}
+
+func (c *circuit) key(kind KeyKind) key {
+	if kind == ProvingKey {
+		return c.pk
+	}
+	return c.vk
+}
+
+// prove returns the binary encoding of a proof of the full witness
+func (c *circuit) prove(fullWitness *witness.Witness) ([]byte, error) {
+	c.mu.RLock()
+	pk := c.pk
+	c.mu.RUnlock()
+	if pk == nil {
+		return nil, ErrMissingKeys
+	}
+
+	var proof io.WriterTo
+	var err error
+	switch c.backendID {
+	case backend.GROTH16:
+		proof, err = groth16.Prove(c.ccs, pk.(groth16.ProvingKey), fullWitness)
+	default:
+		proof, err = plonk.Prove(c.ccs, pk.(plonk.ProvingKey), fullWitness)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if _, err := proof.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func newCS(curveID ecc.ID, backendID backend.ID) (frontend.CompiledConstraintSystem, error) {
+	if !isImplemented(curveID) {
+		return nil, fmt.Errorf("unsupported curve %s", curveID)
+	}
+	switch backendID {
+	case backend.GROTH16:
+		return groth16.NewCS(curveID), nil
+	case backend.PLONK:
+		return plonk.NewCS(curveID), nil
+	default:
+		return nil, fmt.Errorf("unsupported backend %s", backendID)
+	}
+}
+
+func newSRS(curveID ecc.ID) (kzg.SRS, error) {
+	switch curveID {
+	case ecc.BN254:
+		return &kzg_bn254.SRS{}, nil
+	case ecc.BLS12_381:
+		return &kzg_bls12381.SRS{}, nil
+	case ecc.BLS12_377:
+		return &kzg_bls12377.SRS{}, nil
+	case ecc.BW6_761:
+		return &kzg_bw6761.SRS{}, nil
+	case ecc.BLS24_315:
+		return &kzg_bls24315.SRS{}, nil
+	case ecc.BW6_633:
+		return &kzg_bw6633.SRS{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported curve %s", curveID)
+	}
+}
+
+func isImplemented(curveID ecc.ID) bool {
+	for _, id := range ecc.Implemented() {
+		if id == curveID {
+			return true
+		}
+	}
+	return false
+}
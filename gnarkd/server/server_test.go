@@ -0,0 +1,126 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+type cubicCircuit struct {
+	X frontend.Variable `gnark:"x"`
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *cubicCircuit) Define(api frontend.API) error {
+	x3 := api.Mul(c.X, c.X, c.X)
+	api.AssertIsEqual(c.Y, api.Add(x3, c.X, 5))
+	return nil
+}
+
+func TestServer(t *testing.T) {
+	assert := require.New(t)
+
+	s, err := New(WithWorkers(2))
+	assert.NoError(err)
+	defer s.Close()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	do := func(method, path string, body []byte, expectedStatus int, res interface{}) {
+		t.Helper()
+		req, err := http.NewRequest(method, srv.URL+path, bytes.NewReader(body))
+		assert.NoError(err)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(err)
+		defer resp.Body.Close()
+		assert.Equal(expectedStatus, resp.StatusCode, "%s %s", method, path)
+		if res != nil {
+			assert.NoError(json.NewDecoder(resp.Body).Decode(res))
+		}
+	}
+
+	// groth16 circuit, uploaded as a compiled constraint system
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &cubicCircuit{})
+	assert.NoError(err)
+	var buf bytes.Buffer
+	_, err = ccs.WriteTo(&buf)
+	assert.NoError(err)
+	var info CircuitInfo
+	do(http.MethodPut, "/circuits/cubic?curve=bn254&backend=groth16", buf.Bytes(), http.StatusCreated, &info)
+	assert.Equal(CircuitInfo{Name: "cubic", Curve: "BN254", Backend: "groth16", NbConstraints: ccs.GetNbConstraints(), NbPublic: 1, NbSecret: 1}, info)
+	do(http.MethodPut, "/circuits/cubic?curve=bn254&backend=groth16", buf.Bytes(), http.StatusConflict, nil)
+
+	// plonk circuit, registered in Go
+	assert.NoError(s.RegisterCircuit("cubic-plonk", ecc.BLS12_381, backend.PLONK, &cubicCircuit{}))
+	srs, err := test.NewKZGSRS(s.circuits["cubic-plonk"].ccs)
+	assert.NoError(err)
+	do(http.MethodPost, "/circuits/cubic-plonk/setup", nil, http.StatusConflict, nil)
+	buf.Reset()
+	_, err = srs.WriteTo(&buf)
+	assert.NoError(err)
+	do(http.MethodPut, "/circuits/cubic-plonk/srs", buf.Bytes(), http.StatusNoContent, nil)
+
+	for _, name := range []string{"cubic", "cubic-plonk"} {
+		do(http.MethodPost, "/circuits/"+name+"/prove", []byte(`{"witness": {"x": 3, "Y": 35}}`), http.StatusConflict, nil)
+		do(http.MethodPost, "/circuits/"+name+"/setup", nil, http.StatusOK, &info)
+		assert.True(info.Ready)
+
+		// keys round trip
+		for _, k := range []string{"pk", "vk"} {
+			resp, err := http.Get(srv.URL + "/circuits/" + name + "/" + k)
+			assert.NoError(err)
+			buf.Reset()
+			_, err = buf.ReadFrom(resp.Body)
+			assert.NoError(err)
+			resp.Body.Close()
+			do(http.MethodPut, "/circuits/"+name+"/"+k, buf.Bytes(), http.StatusNoContent, nil)
+		}
+
+		var job Job
+		do(http.MethodPost, "/circuits/"+name+"/prove", []byte(`{"witness": {"x": 3, "Y": 35}}`), http.StatusAccepted, &job)
+		do(http.MethodGet, "/jobs/"+job.ID+"?wait=true", nil, http.StatusOK, &job)
+		assert.Equal(JobDone, job.Status, job.Error)
+
+		var verified verifyResponse
+		request, err := json.Marshal(verifyRequest{Proof: job.Proof, PublicWitness: map[string]interface{}{"Y": 35}})
+		assert.NoError(err)
+		do(http.MethodPost, "/circuits/"+name+"/verify", request, http.StatusOK, &verified)
+		assert.True(verified.Valid, verified.Error)
+		request, err = json.Marshal(verifyRequest{Proof: job.Proof, PublicWitness: map[string]interface{}{"Y": 36}})
+		assert.NoError(err)
+		do(http.MethodPost, "/circuits/"+name+"/verify", request, http.StatusOK, &verified)
+		assert.False(verified.Valid)
+
+		// invalid witness
+		do(http.MethodPost, "/circuits/"+name+"/prove", []byte(`{"witness": {"x": 3, "Y": 36}}`), http.StatusAccepted, &job)
+		do(http.MethodGet, "/jobs/"+job.ID+"?wait=true", nil, http.StatusOK, &job)
+		assert.Equal(JobFailed, job.Status)
+	}
+
+	do(http.MethodGet, "/circuits/unknown", nil, http.StatusNotFound, nil)
+	do(http.MethodGet, "/jobs/unknown", nil, http.StatusNotFound, nil)
+}
+
+func TestClosed(t *testing.T) {
+	assert := require.New(t)
+
+	s, err := New(WithQueueSize(0))
+	assert.NoError(err)
+	assert.NoError(s.RegisterCircuit("cubic", ecc.BN254, backend.GROTH16, &cubicCircuit{}))
+	assert.NoError(s.Setup("cubic"))
+	s.Close()
+
+	w, err := s.NewWitness("cubic", map[string]interface{}{"x": 3, "Y": 35})
+	assert.NoError(err)
+	_, err = s.Prove("cubic", w)
+	assert.ErrorIs(err, ErrClosed)
+}
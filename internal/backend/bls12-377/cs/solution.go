@@ -19,12 +19,16 @@ package cs
 import (
 	"errors"
 	"fmt"
+	"io"
 	"math/big"
+	"strings"
 	"sync/atomic"
 
+	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/hint"
 	"github.com/consensys/gnark/frontend/compiled"
 	"github.com/consensys/gnark/frontend/schema"
+	"github.com/consensys/gnark/internal/backend/prover"
 	"github.com/consensys/gnark/internal/utils"
 	"github.com/rs/zerolog"
 
@@ -41,6 +45,7 @@ type solution struct {
 	nbSolved             uint64
 	mHintsFunctions      map[hint.ID]hint.Function // maps hintID to hint function
 	mHints               map[int]*compiled.Hint    // maps wireID to hint
+	trace                *solverTrace              // nil if the solver is not traced
 }
 
 func newSolution(nbWires int, hintFunctions map[hint.ID]hint.Function, hintsDependencies map[hint.ID]string, mHints map[int]*compiled.Hint, coefficients []fr.Element) (solution, error) {
@@ -62,7 +67,7 @@ func newSolution(nbWires int, hintFunctions map[hint.ID]hint.Function, hintsDepe
 	}
 
 	if len(missing) > 0 {
-		return s, fmt.Errorf("solver missing hint(s): %v", missing)
+		return s, fmt.Errorf("solver missing hint(s): %v; register them with hint.Register or provide them with backend.WithHints", missing)
 	}
 
 	return s, nil
@@ -202,7 +207,10 @@ func (s *solution) solveWithHint(vID int, h *compiled.Hint) error {
 		}
 	}
 
+	// hints may reuse their inputs, trace a copy
+	tracedInputs := s.trace.copy(inputs)
 	err := f(curve.ID, inputs, outputs)
+	s.trace.hint(f, tracedInputs, outputs, err)
 
 	var v fr.Element
 	for i := range outputs {
@@ -219,13 +227,59 @@ func (s *solution) printLogs(log zerolog.Logger, logs []compiled.LogEntry) {
 	}
 
 	for i := 0; i < len(logs); i++ {
+		if !s.isLogged(logs[i]) {
+			continue
+		}
 		logLine := s.logValue(logs[i])
 		log.Debug().Str(zerolog.CallerFieldName, logs[i].Caller).Msg(logLine)
 	}
 }
 
+// isLogged returns true if the log entry is unconditional or if its condition is solved and equal to 1
+func (s *solution) isLogged(log compiled.LogEntry) bool {
+	if len(log.Condition) == 0 {
+		return true
+	}
+	var condition fr.Element
+	for _, t := range log.Condition {
+		cID, vID, visibility := t.Unpack()
+		if visibility == schema.Virtual {
+			condition.Add(&condition, &s.coefficients[cID])
+			continue
+		}
+		if !s.solved[vID] {
+			return false
+		}
+		tv := s.computeTerm(t)
+		condition.Add(&condition, &tv)
+	}
+	return condition.IsOne()
+}
+
 const unsolvedVariable = "<unsolved>"
 
+// loggedValue formats a solved variable in a log entry: %s and %v print it like fr.Element.String(),
+// the other verbs (%d, %x, %b, ...) print its canonical value like a big.Int.
+type loggedValue struct {
+	value  fr.Element
+	solved bool
+}
+
+func (v loggedValue) Format(f fmt.State, verb rune) {
+	if !v.solved {
+		_, _ = io.WriteString(f, unsolvedVariable)
+		return
+	}
+	switch verb {
+	case 's', 'v':
+		_, _ = io.WriteString(f, v.value.String())
+	default:
+		var b big.Int
+		v.value.ToBigIntRegular(&b)
+		b.Format(f, verb)
+	}
+}
+
 func (s *solution) logValue(log compiled.LogEntry) string {
 	var toResolve []interface{}
 	var (
@@ -243,12 +297,8 @@ func (s *solution) logValue(log compiled.LogEntry) string {
 				continue
 			}
 			isEval = false
-			if missingValue {
-				toResolve = append(toResolve, unsolvedVariable)
-			} else {
-				// we have to append our accumulator
-				toResolve = append(toResolve, eval.String())
-			}
+			// we have to append our accumulator
+			toResolve = append(toResolve, loggedValue{value: eval, solved: !missingValue})
 			continue
 		}
 		cID, vID, visibility := log.ToResolve[j].Unpack()
@@ -291,15 +341,124 @@ func (s *solution) logValue(log compiled.LogEntry) string {
 }
 
 // UnsatisfiedConstraintError wraps an error with useful metadata on the unsatisfied constraint
-type UnsatisfiedConstraintError struct {
-	Err       error
-	CID       int     // constraint ID
-	DebugInfo *string // optional debug info
+type UnsatisfiedConstraintError = backend.UnsatisfiedConstraintError
+
+// wires describes the wires of the given terms in the current solution,
+// without duplicates. The constants (zero and virtual terms, and the ONE_WIRE
+// if hasOneWire is set) are omitted.
+func (s *solution) wires(cs *compiled.ConstraintSystem, hasOneWire bool, terms ...compiled.Term) []backend.WireValue {
+	var res []backend.WireValue
+	seen := make(map[int]bool, len(terms))
+	for _, t := range terms {
+		vID, visibility := t.WireID(), t.VariableVisibility()
+		if visibility == schema.Unset || visibility == schema.Virtual || (hasOneWire && vID == 0) || seen[vID] {
+			continue
+		}
+		seen[vID] = true
+		w := backend.WireValue{ID: vID, Visibility: schema.Internal}
+		if vID < cs.NbPublicVariables {
+			w.Visibility = schema.Public
+			if vID < len(cs.Public) {
+				w.Name = cs.Public[vID]
+			}
+		} else if vID < cs.NbPublicVariables+cs.NbSecretVariables {
+			w.Visibility = schema.Secret
+			if vID-cs.NbPublicVariables < len(cs.Secret) {
+				w.Name = cs.Secret[vID-cs.NbPublicVariables]
+			}
+		}
+		if s.solved[vID] {
+			w.Value = s.values[vID].String()
+		}
+		res = append(res, w)
+	}
+	return res
+}
+
+// solverTrace writes the constraints evaluated by the solver, and the hints it
+// calls, to the writer set with backend.WithSolverTrace.
+type solverTrace struct {
+	w       io.Writer
+	regions [][2]int // ranges [from, to) of the traced constraints, all if nil
+	enabled bool     // set if the constraint being solved is traced
 }
 
-func (r *UnsatisfiedConstraintError) Error() string {
-	if r.DebugInfo != nil {
-		return fmt.Sprintf("constraint #%d is not satisfied: %s", r.CID, *r.DebugInfo)
+// newSolverTrace returns the trace configured in opt, or nil if the solver is
+// not traced. counters delimit the regions of the circuit.
+func newSolverTrace(opt prover.Config, counters []compiled.Counter) *solverTrace {
+	if opt.SolverTrace == nil {
+		return nil
+	}
+	t := &solverTrace{w: opt.SolverTrace}
+	if len(opt.SolverTraceRegions) != 0 {
+		t.regions = make([][2]int, 0)
+		for _, region := range opt.SolverTraceRegions {
+			for _, c := range counters {
+				// the tags are named "name[file:line]"
+				if c.From == region || strings.HasPrefix(c.From, region+"[") {
+					t.regions = append(t.regions, [2]int{c.CID, c.CID + c.NbConstraints})
+				}
+			}
+		}
+	}
+	return t
+}
+
+// start is called before solving the constraint cID, and returns true if it
+// is traced.
+func (t *solverTrace) start(cID int) bool {
+	if t == nil {
+		return false
+	}
+	t.enabled = t.regions == nil
+	for _, r := range t.regions {
+		if cID >= r[0] && cID < r[1] {
+			t.enabled = true
+			break
+		}
+	}
+	return t.enabled
+}
+
+func (t *solverTrace) printf(format string, a ...interface{}) {
+	if t != nil && t.enabled {
+		fmt.Fprintf(t.w, format, a...)
+	}
+}
+
+// copy returns a copy of the inputs of a hint if t is enabled, nil otherwise.
+func (t *solverTrace) copy(inputs []*big.Int) []*big.Int {
+	if t == nil || !t.enabled {
+		return nil
+	}
+	res := make([]*big.Int, len(inputs))
+	for i := range inputs {
+		res[i] = new(big.Int).Set(inputs[i])
+	}
+	return res
+}
+
+// hint traces a call to the hint function f.
+func (t *solverTrace) hint(f hint.Function, inputs, outputs []*big.Int, err error) {
+	if t == nil || !t.enabled {
+		return
+	}
+	if err != nil {
+		t.printf("\thint %s(%v) failed: %v\n", hint.Name(f), inputs, err)
+		return
+	}
+	t.printf("\thint %s(%v) = %v\n", hint.Name(f), inputs, outputs)
+}
+
+// wireValue returns the value of the wire of t, to be traced.
+func (s *solution) wireValue(t compiled.Term) string {
+	vID, visibility := t.WireID(), t.VariableVisibility()
+	switch {
+	case visibility == schema.Unset || visibility == schema.Virtual:
+		return "-"
+	case !s.solved[vID]:
+		return unsolvedVariable
+	default:
+		return s.values[vID].String()
 	}
-	return fmt.Sprintf("constraint #%d is not satisfied: %s", r.CID, r.Err.Error())
 }
@@ -0,0 +1,226 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package groth16
+
+import (
+	"errors"
+	"runtime"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark/internal/utils"
+)
+
+// maxPrecomputeLevel bounds the level of ProvingKey.Precompute: each part of the scalars
+// of the MSMs has at least 8 bits
+const maxPrecomputeLevel = fr.Bits / 8
+
+// FixedBase holds the multiples [2^(j⋅w)]Pᵢ, j < Level, of the points Pᵢ of a
+// MSM, where w = ⌈(fr.Bits+2)/Level⌉.
+//
+// A MSM of the points Pᵢ with scalars sᵢ is then a MSM of Level times more points
+// with the w-bit parts of the scalars: it needs w doublings instead of fr.Bits, and
+// its bucket reductions are shared by the Level parts of the scalars.
+type FixedBase struct {
+	Level uint64
+
+	// Points[j⋅n+i] = [2^(j⋅w)]Pᵢ, n being the number of points
+	Points []curve.G1Affine
+}
+
+// Precompute adds to the key the tables of multiples of G1.Z and G1.K (see FixedBase)
+// with which Prove computes the largest of its MSMs faster, at the cost of level times
+// the memory (and encoding size) of G1.Z and G1.K. The MSMs don't use the accelerator
+// of the prover when the tables are set.
+//
+// The speedup depends on the size of the circuit and on the caches of the host, as the
+// larger levels trade fewer group operations for more memory accesses: the level is
+// to be chosen by measuring Prove, small levels (2 to 8) being a reasonable start.
+//
+// Level 0 removes the tables. They are encoded by WriteTo and WriteRawTo, but not by
+// WriteDump nor WriteSectionsTo.
+func (pk *ProvingKey) Precompute(level int) error {
+	if level < 0 || level > maxPrecomputeLevel {
+		return errors.New("invalid precomputation level")
+	}
+	if pk.sections != nil {
+		return errors.New("can't precompute the tables of a proving key whose sections are not all loaded")
+	}
+	pk.PrecomputedZ, pk.PrecomputedK = FixedBase{}, FixedBase{}
+	if level == 0 {
+		return nil
+	}
+	pk.PrecomputedZ = newFixedBase(pk.G1.Z, uint64(level))
+	pk.PrecomputedK = newFixedBase(pk.G1.K, uint64(level))
+	return nil
+}
+
+// fixedBase returns the precomputed multiples of the points of section, or nil
+func (pk *ProvingKey) fixedBase(section Section) *FixedBase {
+	switch {
+	case section == SectionZ && pk.PrecomputedZ.Level != 0:
+		return &pk.PrecomputedZ
+	case section == SectionK && pk.PrecomputedK.Level != 0:
+		return &pk.PrecomputedK
+	default:
+		return nil
+	}
+}
+
+// newFixedBase returns the multiples [2^(j⋅w)]Pᵢ, j < level, of points
+func newFixedBase(points []curve.G1Affine, level uint64) FixedBase {
+	n := len(points)
+	w := fixedBaseWidth(level)
+	res := FixedBase{Level: level, Points: make([]curve.G1Affine, int(level)*n)}
+	copy(res.Points, points)
+	utils.Parallelize(n, func(start, end int) {
+		multiples := make([]curve.G1Jac, end-start)
+		for i := range multiples {
+			multiples[i].FromAffine(&points[start+i])
+		}
+		for j := 1; j < int(level); j++ {
+			for i := range multiples {
+				for k := 0; k < w; k++ {
+					multiples[i].DoubleAssign()
+				}
+			}
+			curve.BatchJacobianToAffineG1(multiples, res.Points[j*n+start:j*n+end])
+		}
+	})
+	return res
+}
+
+// fixedBaseWidth returns the number of bits of the parts of the scalars of a MSM with a
+// FixedBase of the given level, which leaves 2 bits for the carries of the signed digits
+func fixedBaseWidth(level uint64) int {
+	return int((fr.Bits + 1 + level) / level)
+}
+
+// msm sets res to the MSM of the points of fb with scalars (in regular form), as a MSM
+// of len(fb.Points) points whose scalars have fixedBaseWidth(fb.Level) bits
+func (fb *FixedBase) msm(res *curve.G1Jac, scalars []fr.Element, nbTasks int) error {
+	level := int(fb.Level)
+	n := len(fb.Points) / level
+	if len(scalars) > n {
+		return errors.New("more scalars than points in the precomputed table")
+	}
+	w := fixedBaseWidth(fb.Level)
+	if nbTasks <= 0 {
+		nbTasks = runtime.NumCPU()
+	}
+
+	// window size c, and cost ⌈w/c⌉⋅(level⋅n + 2ᶜ) in group operations: the bucket
+	// reductions of a window are shared by the level parts of the scalars
+	c, bestCost := 1, -1
+	for cc := 2; cc <= 16 && cc <= w; cc++ {
+		cost := ((w + cc - 1) / cc) * (level*len(scalars) + (1 << cc))
+		if bestCost < 0 || cost < bestCost {
+			c, bestCost = cc, cost
+		}
+	}
+	nbWindows := (w + c - 1) / c
+
+	// the windows of the w-bit parts of the scalars, as signed digits in [-2ᶜ⁻¹, 2ᶜ⁻¹).
+	// The carry out of the last window is 0, as its 2 most significant bits are.
+	digits := make([]int16, len(scalars)*level*nbWindows)
+	utils.Parallelize(len(scalars), func(start, end int) {
+		for i := start; i < end; i++ {
+			carry := uint64(0)
+			for j := 0; j < level; j++ {
+				for t := 0; t < nbWindows; t++ {
+					width := c
+					if t == nbWindows-1 {
+						width = w - t*c
+					}
+					digit := windowBits(&scalars[i], j*w+t*c, width) + carry
+					carry = 0
+					if digit >= 1<<(c-1) {
+						digit -= 1 << width
+						carry = 1
+					}
+					digits[(i*level+j)*nbWindows+t] = int16(digit)
+				}
+			}
+		}
+	})
+
+	// each task accumulates the points of a range in the buckets of a window
+	nbRanges := nbTasks / nbWindows
+	if nbRanges < 1 {
+		nbRanges = 1
+	}
+	windowSums := make([]curve.G1Jac, nbWindows*nbRanges)
+	utils.Parallelize(len(windowSums), func(start, end int) {
+		buckets := make([]curve.G1Jac, 1<<(c-1))
+		var p curve.G1Affine
+		for task := start; task < end; task++ {
+			t, r := task/nbRanges, task%nbRanges
+			for k := range buckets {
+				buckets[k] = curve.G1Jac{}
+			}
+			for i := r * len(scalars) / nbRanges; i < (r+1)*len(scalars)/nbRanges; i++ {
+				for j := 0; j < level; j++ {
+					digit := int(digits[(i*level+j)*nbWindows+t])
+					switch {
+					case digit > 0:
+						buckets[digit-1].AddMixed(&fb.Points[j*n+i])
+					case digit < 0:
+						p.Neg(&fb.Points[j*n+i])
+						buckets[-digit-1].AddMixed(&p)
+					}
+				}
+			}
+
+			// Σ k⋅buckets[k-1]
+			var runningSum, sum curve.G1Jac
+			for k := len(buckets) - 1; k >= 0; k-- {
+				runningSum.AddAssign(&buckets[k])
+				sum.AddAssign(&runningSum)
+			}
+			windowSums[task] = sum
+		}
+	}, nbTasks)
+
+	// Σ 2^(t⋅c)⋅windowSums[t]
+	res.Set(&curve.G1Jac{})
+	for t := nbWindows - 1; t >= 0; t-- {
+		for k := 0; k < c; k++ {
+			res.DoubleAssign()
+		}
+		for r := 0; r < nbRanges; r++ {
+			res.AddAssign(&windowSums[t*nbRanges+r])
+		}
+	}
+	return nil
+}
+
+// windowBits returns the width bits of the regular scalar s starting at bit pos
+func windowBits(s *fr.Element, pos, width int) uint64 {
+	limb, shift := pos/64, pos%64
+	if limb >= fr.Limbs {
+		return 0
+	}
+	res := s[limb] >> shift
+	if shift+width > 64 && limb+1 < fr.Limbs {
+		res |= s[limb+1] << (64 - shift)
+	}
+	return res & (1<<width - 1)
+}
@@ -0,0 +1,374 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package groth16
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark/backend"
+	gnarkio "github.com/consensys/gnark/io"
+)
+
+// Section identifies a part of the sectioned encoding of a ProvingKey, which can be
+// read independently of the others (see WriteSectionsTo and ReadSectionsFrom)
+type Section uint8
+
+const (
+	// SectionParameters holds the domain, [α]1, [β]1, [δ]1, [β]2, [δ]2, the points
+	// at infinity of A and B, and the commitment key. It is always read.
+	SectionParameters Section = iota
+	SectionA                  // pk.G1.A
+	SectionB                  // pk.G1.B
+	SectionZ                  // pk.G1.Z
+	SectionK                  // pk.G1.K
+	SectionG2B                // pk.G2.B
+	nbSections
+)
+
+var errInvalidSections = errors.New("invalid sectioned proving key")
+
+// sections locates the sections of a ProvingKey in its encoding, to read the ones
+// which were not loaded by ReadSectionsFrom when Prove needs them
+type sections struct {
+	r          io.ReaderAt
+	decOptions []func(*curve.Decoder)
+
+	offset, size [nbSections]int64
+	nbPoints     [nbSections]int
+	loaded       [nbSections]bool
+}
+
+// WriteSectionsTo writes binary encoding of the key elements to writer, in sections
+// which can be read independently (see ReadSectionsFrom)
+// points are compressed
+// use WriteRawSectionsTo(...) to encode the key without point compression
+func (pk *ProvingKey) WriteSectionsTo(w io.Writer) (int64, error) {
+	return writeWithHeader(w, gnarkio.KindProvingKeySections, func(w io.Writer) (int64, error) {
+		return pk.writeSectionsTo(w, false)
+	})
+}
+
+// WriteRawSectionsTo writes binary encoding of the key elements to writer, in sections
+// which can be read independently (see ReadSectionsFrom)
+// points are not compressed
+// use WriteSectionsTo(...) to encode the key with point compression
+func (pk *ProvingKey) WriteRawSectionsTo(w io.Writer) (int64, error) {
+	return writeWithHeader(w, gnarkio.KindProvingKeySections, func(w io.Writer) (int64, error) {
+		return pk.writeSectionsTo(w, true)
+	})
+}
+
+// writeSectionsTo serialization format:
+// uint32(nbSections), then uint64(offset),uint64(size) for each section, the offsets being
+// counted from the start of the encoding (header included), followed by the sections:
+// Parameters: Domain,[α]1,[β]1,[δ]1,[β]2,[δ]2,uint64(nbWires),InfinityA,InfinityB,
+// uint32(len(Basis)),[Basis]1,uint32(len(BasisExpSigma)),[BasisExpSigma]1,[η/δ]1
+// A, B, Z, K: uint32(len(points)),[points]1
+// G2B: uint32(len(points)),[points]2
+func (pk *ProvingKey) writeSectionsTo(w io.Writer, raw bool) (int64, error) {
+	if pk.sections != nil {
+		return 0, errors.New("can't write a proving key whose sections are not all loaded")
+	}
+	var options []func(*curve.Encoder)
+	sizeG1, sizeG2 := int64(curve.SizeOfG1AffineCompressed), int64(curve.SizeOfG2AffineCompressed)
+	if raw {
+		options = append(options, curve.RawEncoding())
+		sizeG1, sizeG2 = curve.SizeOfG1AffineUncompressed, curve.SizeOfG2AffineUncompressed
+	}
+
+	// the parameters are small: they are encoded first, to know their size
+	var parameters bytes.Buffer
+	if _, err := pk.Domain.WriteTo(&parameters); err != nil {
+		return 0, err
+	}
+	enc := curve.NewEncoder(&parameters, options...)
+	toEncode := []interface{}{
+		&pk.G1.Alpha,
+		&pk.G1.Beta,
+		&pk.G1.Delta,
+		&pk.G2.Beta,
+		&pk.G2.Delta,
+		uint64(len(pk.InfinityA)),
+		pk.InfinityA,
+		pk.InfinityB,
+		pk.CommitmentKey.Basis,
+		pk.CommitmentKey.BasisExpSigma,
+		&pk.CommitmentKey.BlindingDelta,
+	}
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return 0, err
+		}
+	}
+
+	// the size of the other sections is known in advance
+	size := [nbSections]int64{
+		SectionParameters: int64(parameters.Len()),
+		SectionA:          4 + sizeG1*int64(len(pk.G1.A)),
+		SectionB:          4 + sizeG1*int64(len(pk.G1.B)),
+		SectionZ:          4 + sizeG1*int64(len(pk.G1.Z)),
+		SectionK:          4 + sizeG1*int64(len(pk.G1.K)),
+		SectionG2B:        4 + sizeG2*int64(len(pk.G2.B)),
+	}
+	table := make([]byte, 4+16*nbSections)
+	binary.BigEndian.PutUint32(table, uint32(nbSections))
+	offset := int64(gnarkio.HeaderSize + len(table))
+	for i := range size {
+		binary.BigEndian.PutUint64(table[4+16*i:], uint64(offset))
+		binary.BigEndian.PutUint64(table[12+16*i:], uint64(size[i]))
+		offset += size[i]
+	}
+
+	n, err := w.Write(table)
+	written := int64(n)
+	if err != nil {
+		return written, err
+	}
+	m, err := parameters.WriteTo(w)
+	written += m
+	if err != nil {
+		return written, err
+	}
+
+	enc = curve.NewEncoder(w, options...)
+	toEncode = []interface{}{
+		pk.G1.A,
+		pk.G1.B,
+		pk.G1.Z,
+		pk.G1.K,
+		pk.G2.B,
+	}
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return written + enc.BytesWritten(), err
+		}
+	}
+
+	return written + enc.BytesWritten(), nil
+}
+
+// ReadSectionsFrom attempts to decode a ProvingKey from r, written by WriteSectionsTo (compressed)
+// or WriteRawSectionsTo (uncompressed).
+// Only the parameters of the key and the sections in load are read: Prove reads the others from r
+// when it needs them, one at a time, and drops them after use, to bound the memory it uses.
+// r must then not be modified while the key is in use, and the key can only be used by Prove
+// and ProveReader.
+func (pk *ProvingKey) ReadSectionsFrom(r io.ReaderAt, load ...Section) error {
+	return pk.readSectionsFrom(r, load)
+}
+
+// UnsafeReadSectionsFrom behaves like ReadSectionsFrom excepts it doesn't check if the decoded points
+// are on the curve or in the correct subgroup
+func (pk *ProvingKey) UnsafeReadSectionsFrom(r io.ReaderAt, load ...Section) error {
+	return pk.readSectionsFrom(r, load, curve.NoSubgroupChecks())
+}
+
+func (pk *ProvingKey) readSectionsFrom(r io.ReaderAt, load []Section, decOptions ...func(*curve.Decoder)) error {
+	sr := io.NewSectionReader(r, 0, math.MaxInt64)
+	_, version, _, err := gnarkio.ReadHeader(sr, ecc.BLS12_377, backend.GROTH16, gnarkio.KindProvingKeySections)
+	if err != nil {
+		return err
+	}
+	if version < gnarkio.VersionProvingKeySections {
+		// the sectioned encoding was introduced with VersionProvingKeySections
+		return errInvalidSections
+	}
+
+	table := make([]byte, 4+16*nbSections)
+	if _, err := io.ReadFull(sr, table); err != nil {
+		return err
+	}
+	if binary.BigEndian.Uint32(table) != uint32(nbSections) {
+		return errInvalidSections
+	}
+	s := &sections{r: r, decOptions: decOptions}
+	for i := range s.offset {
+		s.offset[i] = int64(binary.BigEndian.Uint64(table[4+16*i:]))
+		s.size[i] = int64(binary.BigEndian.Uint64(table[12+16*i:]))
+		if s.offset[i] < 0 || s.size[i] < 4 {
+			return errInvalidSections
+		}
+	}
+
+	// parameters
+	*pk = ProvingKey{}
+	parameters := io.NewSectionReader(r, s.offset[SectionParameters], s.size[SectionParameters])
+	if _, err := pk.Domain.ReadFrom(parameters); err != nil {
+		return err
+	}
+	dec := curve.NewDecoder(parameters, decOptions...)
+	var nbWires uint64
+	toDecode := []interface{}{
+		&pk.G1.Alpha,
+		&pk.G1.Beta,
+		&pk.G1.Delta,
+		&pk.G2.Beta,
+		&pk.G2.Delta,
+		&nbWires,
+	}
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return err
+		}
+	}
+	if nbWires > uint64(s.size[SectionParameters]) {
+		return errInvalidSections
+	}
+	pk.InfinityA = make([]bool, nbWires)
+	pk.InfinityB = make([]bool, nbWires)
+	toDecode = []interface{}{
+		&pk.InfinityA,
+		&pk.InfinityB,
+		&pk.CommitmentKey.Basis,
+		&pk.CommitmentKey.BasisExpSigma,
+		&pk.CommitmentKey.BlindingDelta,
+	}
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return err
+		}
+	}
+	if len(pk.CommitmentKey.Basis) == 0 {
+		pk.CommitmentKey = CommitmentKey{}
+	}
+	for i := range pk.InfinityA {
+		if pk.InfinityA[i] {
+			pk.NbInfinityA++
+		}
+		if pk.InfinityB[i] {
+			pk.NbInfinityB++
+		}
+	}
+
+	// the number of points of the sections, which prefixes them
+	var buf [4]byte
+	for i := SectionA; i < nbSections; i++ {
+		if _, err := r.ReadAt(buf[:], s.offset[i]); err != nil {
+			return err
+		}
+		s.nbPoints[i] = int(binary.BigEndian.Uint32(buf[:]))
+	}
+	if s.nbPoints[SectionA] != int(nbWires-pk.NbInfinityA) ||
+		s.nbPoints[SectionB] != int(nbWires-pk.NbInfinityB) ||
+		s.nbPoints[SectionG2B] != s.nbPoints[SectionB] ||
+		s.nbPoints[SectionZ] != int(pk.Domain.Cardinality) {
+		return errInvalidSections
+	}
+
+	pk.sections = s
+	for _, section := range load {
+		switch section {
+		case SectionParameters:
+			continue
+		case SectionG2B:
+			err = s.read(section, &pk.G2.B)
+		case SectionA, SectionB, SectionZ, SectionK:
+			err = s.read(section, pk.fieldG1(section))
+		default:
+			err = errInvalidSections
+		}
+		if err != nil {
+			*pk = ProvingKey{}
+			return err
+		}
+		s.loaded[section] = true
+	}
+
+	// the key is complete if all its sections are loaded
+	pk.sections = nil
+	for i := SectionA; i < nbSections; i++ {
+		if !s.loaded[i] {
+			pk.sections = s
+		}
+	}
+
+	return nil
+}
+
+// read decodes the points of section into v
+func (s *sections) read(section Section, v interface{}) error {
+	dec := curve.NewDecoder(io.NewSectionReader(s.r, s.offset[section], s.size[section]), s.decOptions...)
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.BytesRead() != s.size[section] {
+		return errInvalidSections
+	}
+	return nil
+}
+
+// fieldG1 returns the G1 points of the key held in section
+func (pk *ProvingKey) fieldG1(section Section) *[]curve.G1Affine {
+	switch section {
+	case SectionA:
+		return &pk.G1.A
+	case SectionB:
+		return &pk.G1.B
+	case SectionZ:
+		return &pk.G1.Z
+	case SectionK:
+		return &pk.G1.K
+	default:
+		panic("not a section of G1 points")
+	}
+}
+
+// pointsG1 returns the G1 points of the key held in section, read from the encoding of the
+// key if they were not loaded (see ReadSectionsFrom)
+func (pk *ProvingKey) pointsG1(section Section) ([]curve.G1Affine, error) {
+	if pk.sections == nil || pk.sections.loaded[section] {
+		return *pk.fieldG1(section), nil
+	}
+	var points []curve.G1Affine
+	if err := pk.sections.read(section, &points); err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// pointsG2B returns pk.G2.B, read from the encoding of the key if it was not loaded
+// (see ReadSectionsFrom)
+func (pk *ProvingKey) pointsG2B() ([]curve.G2Affine, error) {
+	if pk.sections == nil || pk.sections.loaded[SectionG2B] {
+		return pk.G2.B, nil
+	}
+	var points []curve.G2Affine
+	if err := pk.sections.read(SectionG2B, &points); err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// nbPoints returns the number of points of section, whether it is loaded or not
+func (pk *ProvingKey) nbPoints(section Section) int {
+	if pk.sections == nil || pk.sections.loaded[section] {
+		if section == SectionG2B {
+			return len(pk.G2.B)
+		}
+		return len(*pk.fieldG1(section))
+	}
+	return pk.sections.nbPoints[section]
+}
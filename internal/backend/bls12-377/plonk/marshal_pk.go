@@ -0,0 +1,175 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package plonk
+
+import (
+	"errors"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-377"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/gnark/backend"
+	gnarkio "github.com/consensys/gnark/io"
+	"io"
+)
+
+// WriteTo writes binary encoding of ProvingKey to w
+func (pk *ProvingKey) WriteTo(w io.Writer) (int64, error) {
+	return writeWithHeader(w, gnarkio.KindProvingKey, pk.writeTo)
+}
+
+func (pk *ProvingKey) writeTo(w io.Writer) (n int64, err error) {
+	// encode the verifying key
+	n, err = pk.Vk.writeTo(w, gnarkio.HeaderVersion)
+	if err != nil {
+		return
+	}
+
+	// fft domains
+	n2, err := pk.Domain[0].WriteTo(w)
+	if err != nil {
+		return
+	}
+	n += n2
+
+	n2, err = pk.Domain[1].WriteTo(w)
+	if err != nil {
+		return
+	}
+	n += n2
+
+	// sanity check len(Permutation) == 3*int(pk.Domain[0].Cardinality)
+	if len(pk.Permutation) != (3 * int(pk.Domain[0].Cardinality)) {
+		return n, errors.New("invalid permutation size, expected 3*domain cardinality")
+	}
+
+	enc := curve.NewEncoder(w)
+	// note: type Polynomial, which is handled by default binary.Write(...) op and doesn't
+	// encode the size (nor does it convert from Montgomery to Regular form)
+	// so we explicitly transmit []fr.Element
+	toEncode := []interface{}{
+		([]fr.Element)(pk.Ql),
+		([]fr.Element)(pk.Qr),
+		([]fr.Element)(pk.Qm),
+		([]fr.Element)(pk.Qo),
+		([]fr.Element)(pk.CQk),
+		([]fr.Element)(pk.LQk),
+		([]fr.Element)(pk.S1Canonical),
+		([]fr.Element)(pk.S2Canonical),
+		([]fr.Element)(pk.S3Canonical),
+		pk.Permutation,
+	}
+	for i := range pk.Qcustom {
+		toEncode = append(toEncode, ([]fr.Element)(pk.Qcustom[i]))
+	}
+	if len(pk.Vk.T) > 0 {
+		toEncode = append(toEncode, ([]fr.Element)(pk.Qlookup))
+		for i := range pk.T {
+			toEncode = append(toEncode, ([]fr.Element)(pk.T[i]))
+		}
+	}
+	if len(pk.Vk.CommitmentConstraintIndexes) > 0 {
+		toEncode = append(toEncode, ([]fr.Element)(pk.Qcp))
+	}
+
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return n + enc.BytesWritten(), err
+		}
+	}
+
+	return n + enc.BytesWritten(), nil
+}
+
+// ReadFrom reads from binary representation in r into ProvingKey
+func (pk *ProvingKey) ReadFrom(r io.Reader) (int64, error) {
+	r, version, n, err := gnarkio.ReadHeader(r, ecc.BLS12_377, backend.PLONK, gnarkio.KindProvingKey)
+	if err != nil {
+		return n, err
+	}
+	m, err := pk.readFrom(r, version)
+	return n + m, err
+}
+
+// readFrom reads the encoding of the given version of ProvingKey from r
+func (pk *ProvingKey) readFrom(r io.Reader, version uint8) (int64, error) {
+	pk.Vk = &VerifyingKey{}
+	n, err := pk.Vk.readFrom(r, version)
+	if err != nil {
+		return n, err
+	}
+
+	n2, err := pk.Domain[0].ReadFrom(r)
+	n += n2
+	if err != nil {
+		return n, err
+	}
+
+	n2, err = pk.Domain[1].ReadFrom(r)
+	n += n2
+	if err != nil {
+		return n, err
+	}
+
+	pk.Permutation = make([]int64, 3*pk.Domain[0].Cardinality)
+
+	dec := curve.NewDecoder(r)
+	toDecode := []interface{}{
+		(*[]fr.Element)(&pk.Ql),
+		(*[]fr.Element)(&pk.Qr),
+		(*[]fr.Element)(&pk.Qm),
+		(*[]fr.Element)(&pk.Qo),
+		(*[]fr.Element)(&pk.CQk),
+		(*[]fr.Element)(&pk.LQk),
+		(*[]fr.Element)(&pk.S1Canonical),
+		(*[]fr.Element)(&pk.S2Canonical),
+		(*[]fr.Element)(&pk.S3Canonical),
+		&pk.Permutation,
+	}
+	if len(pk.Vk.Qcustom) > 0 {
+		pk.Qcustom = make([][]fr.Element, len(pk.Vk.Qcustom))
+		for i := range pk.Qcustom {
+			toDecode = append(toDecode, &pk.Qcustom[i])
+		}
+	}
+	if len(pk.Vk.T) > 0 {
+		toDecode = append(toDecode, &pk.Qlookup)
+		pk.T = make([][]fr.Element, len(pk.Vk.T))
+		for i := range pk.T {
+			toDecode = append(toDecode, &pk.T[i])
+		}
+	}
+	if len(pk.Vk.CommitmentConstraintIndexes) > 0 {
+		toDecode = append(toDecode, &pk.Qcp)
+	}
+
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return n + dec.BytesRead(), err
+		}
+	}
+
+	// the evaluations of the permutation on the big domain are not serialized
+	computePermutationBigDomain(pk)
+
+	return n + dec.BytesRead(), nil
+
+}
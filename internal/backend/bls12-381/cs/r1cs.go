@@ -17,6 +17,7 @@
 package cs
 
 import (
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"github.com/fxamacker/cbor/v2"
@@ -32,7 +33,10 @@ import (
 	"github.com/consensys/gnark/frontend/compiled"
 	"github.com/consensys/gnark/frontend/schema"
 	"github.com/consensys/gnark/internal/backend/ioutils"
+	"github.com/consensys/gnark/internal/backend/prover"
+	gnarkio "github.com/consensys/gnark/io"
 	"github.com/consensys/gnark/logger"
+	"github.com/consensys/gnark/metrics"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"math"
@@ -66,18 +70,40 @@ func NewR1CS(cs compiled.R1CS, coefficients []big.Int) *R1CS {
 // a, b, c vectors: ab-c = hz
 // witness = [publicWires | secretWires] (without the ONE_WIRE !)
 // returns  [publicWires | secretWires | internalWires ]
-func (cs *R1CS) Solve(witness, a, b, c []fr.Element, opt backend.ProverConfig) ([]fr.Element, error) {
+func (cs *R1CS) Solve(witness, a, b, c []fr.Element, opt prover.Config) ([]fr.Element, error) {
+	return cs.solve(len(witness), func(values []fr.Element) error {
+		copy(values, witness)
+		return nil
+	}, a, b, c, opt)
+}
+
+// SolveReader is like Solve, but the witness is read from witness directly
+// into the wire values, so that it is never held twice in memory.
+func (cs *R1CS) SolveReader(witness *bls12_381witness.Reader, a, b, c []fr.Element, opt prover.Config) ([]fr.Element, error) {
+	return cs.solve(witness.Len(), func(values []fr.Element) error {
+		return witness.ReadElements(values, 0)
+	}, a, b, c, opt)
+}
+
+// solve implements Solve for a witness of nbInputs elements, which are set in
+// the wire values by setInputs.
+func (cs *R1CS) solve(nbInputs int, setInputs func(values []fr.Element) error, a, b, c []fr.Element, opt prover.Config) (_ []fr.Element, err error) {
 	log := logger.Logger().With().Str("curve", cs.CurveID().String()).Int("nbConstraints", len(cs.Constraints)).Str("backend", "groth16").Logger()
 
 	nbWires := cs.NbPublicVariables + cs.NbSecretVariables + cs.NbInternalVariables
+	m := metrics.Start(metrics.PhaseSolve, cs.CurveID(), backend.GROTH16)
+	defer func() {
+		m.Stop(metrics.Event{NbConstraints: len(cs.Constraints), NbWires: nbWires, Err: err})
+	}()
 	solution, err := newSolution(nbWires, opt.HintFunctions, cs.MHintsDependencies, cs.MHints, cs.Coefficients)
 	if err != nil {
 		return make([]fr.Element, nbWires), err
 	}
+	solution.trace = newSolverTrace(opt, cs.Counters)
 	start := time.Now()
 
-	if len(witness) != int(cs.NbPublicVariables-1+cs.NbSecretVariables) { // - 1 for ONE_WIRE
-		err = fmt.Errorf("invalid witness size, got %d, expected %d = %d (public) + %d (secret)", len(witness), int(cs.NbPublicVariables-1+cs.NbSecretVariables), cs.NbPublicVariables-1, cs.NbSecretVariables)
+	if nbInputs != int(cs.NbPublicVariables-1+cs.NbSecretVariables) { // - 1 for ONE_WIRE
+		err = fmt.Errorf("invalid witness size, got %d, expected %d = %d (public) + %d (secret)", nbInputs, int(cs.NbPublicVariables-1+cs.NbSecretVariables), cs.NbPublicVariables-1, cs.NbSecretVariables)
 		log.Err(err).Send()
 		return solution.values, err
 	}
@@ -91,20 +117,23 @@ func (cs *R1CS) Solve(witness, a, b, c []fr.Element, opt backend.ProverConfig) (
 
 	solution.solved[0] = true // ONE_WIRE
 	solution.values[0].SetOne()
-	copy(solution.values[1:], witness)
-	for i := 0; i < len(witness); i++ {
+	if err := setInputs(solution.values[1 : nbInputs+1]); err != nil {
+		log.Err(err).Send()
+		return solution.values, err
+	}
+	for i := 0; i < nbInputs; i++ {
 		solution.solved[i+1] = true
 	}
 
 	// keep track of the number of wire instantiations we do, for a sanity check to ensure
 	// we instantiated all wires
-	solution.nbSolved += uint64(len(witness) + 1)
+	solution.nbSolved += uint64(nbInputs + 1)
 
 	// now that we know all inputs are set, defer log printing once all solution.values are computed
 	// (or sooner, if a constraint is not satisfied)
 	defer solution.printLogs(opt.CircuitLogger, cs.Logs)
 
-	if err := cs.parallelSolve(a, b, c, &solution); err != nil {
+	if err := cs.parallelSolve(a, b, c, &solution, &opt); err != nil {
 		if unsatisfiedErr, ok := err.(*UnsatisfiedConstraintError); ok {
 			log.Err(errors.New("unsatisfied constraint")).Int("id", unsatisfiedErr.CID).Send()
 		} else {
@@ -124,12 +153,20 @@ func (cs *R1CS) Solve(witness, a, b, c []fr.Element, opt backend.ProverConfig) (
 	return solution.values, nil
 }
 
-func (cs *R1CS) parallelSolve(a, b, c []fr.Element, solution *solution) error {
+func (cs *R1CS) parallelSolve(a, b, c []fr.Element, solution *solution, opt *prover.Config) error {
 	// minWorkPerCPU is the minimum target number of constraint a task should hold
 	// in other words, if a level has less than minWorkPerCPU, it will not be parallelized and executed
 	// sequentially without sync.
 	const minWorkPerCPU = 50.0
 
+	// hintWork is the work of a constraint which calls a hint, counted in constraints
+	// which don't: a level of a few constraints calling hints may still be parallelized
+	const hintWork = 10
+
+	// nbTasksPerCPU is the number of tasks per CPU a level with hints is split in: the
+	// workers take the tasks as they are done, which balances the costs of the hints
+	const nbTasksPerCPU = 4
+
 	// cs.Levels has a list of levels, where all constraints in a level l(n) are independent
 	// and may only have dependencies on previous levels
 	// for each constraint
@@ -142,6 +179,15 @@ func (cs *R1CS) parallelSolve(a, b, c []fr.Element, solution *solution) error {
 	chTasks := make(chan []int, runtime.NumCPU())
 	chError := make(chan *UnsatisfiedConstraintError, runtime.NumCPU())
 
+	// reportError keeps the first errors of the workers, which carry on with the
+	// next tasks of the level
+	reportError := func(err *UnsatisfiedConstraintError) {
+		select {
+		case chError <- err:
+		default:
+		}
+	}
+
 	// start a worker pool
 	// each worker wait on chTasks
 	// a task is a slice of constraint indexes to be solved
@@ -151,14 +197,8 @@ func (cs *R1CS) parallelSolve(a, b, c []fr.Element, solution *solution) error {
 				for _, i := range t {
 					// for each constraint in the task, solve it.
 					if err := cs.solveConstraint(cs.Constraints[i], solution, &a[i], &b[i], &c[i]); err != nil {
-						var debugInfo *string
-						if dID, ok := cs.MDebug[int(i)]; ok {
-							debugInfo = new(string)
-							*debugInfo = solution.logValue(cs.DebugInfo[dID])
-						}
-						chError <- &UnsatisfiedConstraintError{CID: i, Err: err, DebugInfo: debugInfo}
-						wg.Done()
-						return
+						reportError(cs.unsatisfiedConstraintError(i, err, solution, &a[i], &b[i], &c[i]))
+						break
 					}
 				}
 				wg.Done()
@@ -173,21 +213,32 @@ func (cs *R1CS) parallelSolve(a, b, c []fr.Element, solution *solution) error {
 	}()
 
 	// for each level, we push the tasks
-	for _, level := range cs.Levels {
+	for l, level := range cs.Levels {
+		if err := opt.Checkpoint(prover.PhaseSolve, l, len(cs.Levels)); err != nil {
+			return err
+		}
 
-		// max CPU to use
-		maxCPU := float64(len(level)) / minWorkPerCPU
+		// max CPU to use, the constraints calling hints weighing more than the others
+		nbHints := 0
+		if l < len(cs.LevelsNbHints) {
+			nbHints = cs.LevelsNbHints[l]
+		}
+		maxCPU := float64(len(level)+(hintWork-1)*nbHints) / minWorkPerCPU
 
-		if maxCPU <= 1.0 {
-			// we do it sequentially
+		if maxCPU <= 1.0 || solution.trace != nil {
+			// we do it sequentially (always when tracing, to keep the order of the constraints)
 			for _, i := range level {
+				traced := solution.trace.start(i)
+				if traced {
+					r := cs.Constraints[i]
+					solution.trace.printf("constraint #%d: %s ⋅ %s == %s\n", i, cs.vtoString(r.L), cs.vtoString(r.R), cs.vtoString(r.O))
+				}
 				if err := cs.solveConstraint(cs.Constraints[i], solution, &a[i], &b[i], &c[i]); err != nil {
-					var debugInfo *string
-					if dID, ok := cs.MDebug[int(i)]; ok {
-						debugInfo = new(string)
-						*debugInfo = solution.logValue(cs.DebugInfo[dID])
-					}
-					return &UnsatisfiedConstraintError{CID: i, Err: err, DebugInfo: debugInfo}
+					solution.trace.printf("\tnot satisfied: %v\n", err)
+					return cs.unsatisfiedConstraintError(i, err, solution, &a[i], &b[i], &c[i])
+				}
+				if traced {
+					solution.trace.printf("\t%s ⋅ %s == %s\n", a[i].String(), b[i].String(), c[i].String())
 				}
 			}
 			continue
@@ -196,6 +247,9 @@ func (cs *R1CS) parallelSolve(a, b, c []fr.Element, solution *solution) error {
 		// number of tasks for this level is set to num cpus
 		// but if we don't have enough work for all our CPUS, it can be lower.
 		nbTasks := runtime.NumCPU()
+		if nbHints != 0 {
+			nbTasks *= nbTasksPerCPU
+		}
 		maxTasks := int(math.Ceil(maxCPU))
 		if nbTasks > maxTasks {
 			nbTasks = maxTasks
@@ -221,8 +275,8 @@ func (cs *R1CS) parallelSolve(a, b, c []fr.Element, solution *solution) error {
 				extraTasks--
 				extraTasksOffset++
 			}
-			// since we're never pushing more than num CPU tasks
-			// we will never be blocked here
+			// we may push more tasks than num CPU: we are then
+			// blocked until the workers take them
 			chTasks <- level[_start:_end]
 		}
 
@@ -234,13 +288,13 @@ func (cs *R1CS) parallelSolve(a, b, c []fr.Element, solution *solution) error {
 		}
 	}
 
-	return nil
+	return opt.Checkpoint(prover.PhaseSolve, len(cs.Levels), len(cs.Levels))
 }
 
 // IsSolved returns nil if given witness solves the R1CS and error otherwise
 // this method wraps cs.Solve() and allocates cs.Solve() inputs
-func (cs *R1CS) IsSolved(witness *witness.Witness, opts ...backend.ProverOption) error {
-	opt, err := backend.NewProverConfig(opts...)
+func (cs *R1CS) IsSolved(witness *witness.Witness, opts ...prover.Option) error {
+	opt, err := prover.NewConfig(opts...)
 	if err != nil {
 		return err
 	}
@@ -253,6 +307,24 @@ func (cs *R1CS) IsSolved(witness *witness.Witness, opts ...backend.ProverOption)
 	return err
 }
 
+// unsatisfiedConstraintError returns the error of the constraint i, which the
+// solver failed to solve with err. a, b and c are the values of L, R and O.
+func (cs *R1CS) unsatisfiedConstraintError(i int, err error, solution *solution, a, b, c *fr.Element) *UnsatisfiedConstraintError {
+	r := &UnsatisfiedConstraintError{CID: i, Err: err, L: a.String(), R: b.String(), O: c.String()}
+	if l, ok := cs.DebugEntry(i); ok {
+		debugInfo := solution.logValue(l)
+		r.DebugInfo = &debugInfo
+		r.Caller = l.Caller
+	}
+	constraint := cs.Constraints[i]
+	terms := make([]compiled.Term, 0, len(constraint.L)+len(constraint.R)+len(constraint.O))
+	terms = append(terms, constraint.L...)
+	terms = append(terms, constraint.R...)
+	terms = append(terms, constraint.O...)
+	r.Wires = solution.wires(&cs.ConstraintSystem, true, terms...)
+	return r
+}
+
 // divByCoeff sets res = res / t.Coeff
 func (cs *R1CS) divByCoeff(res *fr.Element, t compiled.Term) {
 	cID := t.CoeffID()
@@ -383,6 +455,16 @@ func (cs *R1CS) solveConstraint(r compiled.R1C, solution *solution, a, b, c *fr.
 	return nil
 }
 
+// Print writes the constraints on w, one per line, with the names of the
+// inputs of the circuit (see compiled.R1CS.Print)
+func (cs *R1CS) Print(w io.Writer) error {
+	coeffs := make([]big.Int, len(cs.Coefficients))
+	for i := range cs.Coefficients {
+		cs.Coefficients[i].ToBigIntRegular(&coeffs[i])
+	}
+	return cs.R1CS.Print(w, coeffs, fr.Modulus())
+}
+
 // GetConstraints return a list of constraint formatted as L⋅R == O
 // such that [0] -> L, [1] -> R, [2] -> O
 func (cs *R1CS) GetConstraints() [][]string {
@@ -452,6 +534,23 @@ func (cs *R1CS) GetNbCoefficients() int {
 	return len(cs.Coefficients)
 }
 
+// Fingerprint returns a SHA-256 hash of the circuit of the constraint system: its
+// constraints, coefficients, hints and commitment, over the curve. It doesn't
+// depend on the debug info nor on the names of the inputs, and is stable across
+// serializations, so that it identifies the circuit a groth16 key is set up
+// for (see VerifyingKey.CircuitFingerprint).
+func (cs *R1CS) Fingerprint() [32]byte {
+	h := sha256.New()
+	cs.R1CS.WriteFingerprint(h)
+	for i := range cs.Coefficients {
+		b := cs.Coefficients[i].Bytes()
+		h.Write(b[:])
+	}
+	var res [32]byte
+	copy(res[:], h.Sum(nil))
+	return res
+}
+
 // CurveID returns curve ID as defined in gnark-crypto
 func (cs *R1CS) CurveID() ecc.ID {
 	return ecc.BLS12_381
@@ -463,11 +562,16 @@ func (cs *R1CS) FrSize() int {
 }
 
 // WriteTo encodes R1CS into provided io.Writer using cbor
+// The levels of the solver (see compiled.ConstraintSystem.Levels) are encoded with
+// the constraints, and reused by the solver once read back by ReadFrom.
 func (cs *R1CS) WriteTo(w io.Writer) (int64, error) {
 	_w := ioutils.WriterCounter{W: w} // wraps writer to count the bytes written
+	if _, err := gnarkio.WriteHeader(&_w, cs.CurveID(), backend.GROTH16, gnarkio.KindConstraintSystem); err != nil {
+		return _w.N, err
+	}
 	enc, err := cbor.CoreDetEncOptions().EncMode()
 	if err != nil {
-		return 0, err
+		return _w.N, err
 	}
 	encoder := enc.NewEncoder(&_w)
 
@@ -478,18 +582,22 @@ func (cs *R1CS) WriteTo(w io.Writer) (int64, error) {
 
 // ReadFrom attempts to decode R1CS from io.Reader using cbor
 func (cs *R1CS) ReadFrom(r io.Reader) (int64, error) {
+	r, _, n, err := gnarkio.ReadHeader(r, cs.CurveID(), backend.GROTH16, gnarkio.KindConstraintSystem)
+	if err != nil {
+		return n, err
+	}
 	dm, err := cbor.DecOptions{
 		MaxArrayElements: 134217728,
 		MaxMapPairs:      134217728,
 	}.DecMode()
 
 	if err != nil {
-		return 0, err
+		return n, err
 	}
 	decoder := dm.NewDecoder(r)
 	if err := decoder.Decode(&cs); err != nil {
-		return int64(decoder.NumBytesRead()), err
+		return n + int64(decoder.NumBytesRead()), err
 	}
 
-	return int64(decoder.NumBytesRead()), nil
+	return n + int64(decoder.NumBytesRead()), nil
 }
@@ -0,0 +1,926 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package groth16
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"math/bits"
+	"strconv"
+
+	"github.com/consensys/gnark-crypto/ecc"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+
+	"github.com/consensys/gnark-crypto/fiat-shamir"
+	bls12_381witness "github.com/consensys/gnark/internal/backend/bls12-381/witness"
+	"github.com/consensys/gnark/internal/utils"
+)
+
+// Aggregation follows SnarkPack (https://eprint.iacr.org/2021/529.pdf): n proofs (Aᵢ, Bᵢ, Cᵢ)
+// for the same VerifyingKey are committed to with keys vᵢ = [aⁱ]₂, [bⁱ]₂ and wᵢ = [aⁿ⁺ⁱ]₁, [bⁿ⁺ⁱ]₁,
+// then a random r is derived and the prover shows, with a generalized inner product argument
+// of log(n) rounds (TIPP for the pairings, MIPP for the multi exponentiation) that
+//
+//   Z_AB = ∏ e(Aᵢ, Bᵢ)^{rⁱ}   and   Z_C = Σ rⁱCᵢ
+//
+// the verifier finally checks the randomized Groth16 equation
+//
+//   Z_AB = e(α, β)^{Σrⁱ}⋅e(Σ rⁱSᵢ, γ)⋅e(Z_C, δ)
+//
+// where Sᵢ is the commitment to the public inputs of proof i.
+
+var (
+	errInvalidAggregatedProof = errors.New("invalid aggregated proof")
+	errAggregatedTIPPFailed   = errors.New("aggregated proof: inner pairing product check failed")
+	errAggregatedMIPPFailed   = errors.New("aggregated proof: multi exponentiation check failed")
+	errAggregatedKeysFailed   = errors.New("aggregated proof: commitment keys opening check failed")
+)
+
+// AggregationSRS is the structured reference string used to aggregate proofs. It is
+// independent of the circuits, and supports up to n proofs.
+//
+// The secrets a and b must come from two independent powers of tau ceremonies.
+type AggregationSRS struct {
+	G1 struct {
+		A, B []curve.G1Affine // [aⁱ]₁, [bⁱ]₁ for i in [0, 2n)
+	}
+	G2 struct {
+		A, B []curve.G2Affine // [aⁱ]₂, [bⁱ]₂ for i in [0, n]
+	}
+}
+
+// AggregationVerifyingKey is the part of the AggregationSRS needed to verify an aggregated proof
+type AggregationVerifyingKey struct {
+	G1 struct {
+		One, A, B curve.G1Affine // [1]₁, [a]₁, [b]₁
+	}
+	G2 struct {
+		One, A, B curve.G2Affine // [1]₂, [a]₂, [b]₂
+	}
+}
+
+// AggregatedProof is a proof that n Groth16 proofs for the same VerifyingKey are valid,
+// its size is logarithmic in n.
+type AggregatedProof struct {
+	// commitments to (A, B) and C, under the keys derived from a and b
+	ComAB, ComC [2]curve.GT
+
+	// Z_AB = ∏ e(Aᵢ, Bᵢ)^{rⁱ}, Z_C = Σ rⁱCᵢ
+	ZAB curve.GT
+	ZC  curve.G1Affine
+
+	// cross commitments and inner products of each round of the inner product argument
+	ComABL, ComABR, ComCL, ComCR [][2]curve.GT
+	ZABL, ZABR                   []curve.GT
+	ZCL, ZCR                     []curve.G1Affine
+
+	// proof elements and commitment keys folded by the inner product argument
+	A, C curve.G1Affine
+	B    curve.G2Affine
+	V    [2]curve.G2Affine
+	W    [2]curve.G1Affine
+
+	// KZG openings showing that the folded keys are well formed
+	OpeningV [2]curve.G2Affine
+	OpeningW [2]curve.G1Affine
+}
+
+// NewAggregationSRS returns an AggregationSRS supporting up to size proofs, with secrets a and b.
+//
+// This is meant for testing purposes: a and b are toxic waste and must not be known by the prover.
+func NewAggregationSRS(size uint64, a, b *big.Int) (*AggregationSRS, error) {
+	if size == 0 {
+		return nil, errors.New("aggregation SRS size must be positive")
+	}
+	_, _, g1, g2 := curve.Generators()
+
+	// xⁱ for i in [1, 2n), in regular form for the batch scalar multiplications
+	powers := func(x *big.Int) []fr.Element {
+		res := make([]fr.Element, 2*size-1)
+		res[0].SetBigInt(x)
+		for i := 1; i < len(res); i++ {
+			res[i].Mul(&res[i-1], &res[0])
+		}
+		for i := range res {
+			res[i].FromMont()
+		}
+		return res
+	}
+	pa, pb := powers(a), powers(b)
+
+	var srs AggregationSRS
+	srs.G1.A = append([]curve.G1Affine{g1}, curve.BatchScalarMultiplicationG1(&g1, pa)...)
+	srs.G1.B = append([]curve.G1Affine{g1}, curve.BatchScalarMultiplicationG1(&g1, pb)...)
+	srs.G2.A = append([]curve.G2Affine{g2}, curve.BatchScalarMultiplicationG2(&g2, pa[:size])...)
+	srs.G2.B = append([]curve.G2Affine{g2}, curve.BatchScalarMultiplicationG2(&g2, pb[:size])...)
+
+	return &srs, nil
+}
+
+// VerifyingKey returns the part of the srs needed to verify aggregated proofs
+func (srs *AggregationSRS) VerifyingKey() *AggregationVerifyingKey {
+	var avk AggregationVerifyingKey
+	avk.G1.One, avk.G1.A, avk.G1.B = srs.G1.A[0], srs.G1.A[1], srs.G1.B[1]
+	avk.G2.One, avk.G2.A, avk.G2.B = srs.G2.A[0], srs.G2.A[1], srs.G2.B[1]
+	return &avk
+}
+
+// AggregateProofs returns an AggregatedProof showing that the proofs are valid for vk and the
+// public witnesses. If the number of proofs is not a power of two, the last one is repeated.
+func AggregateProofs(srs *AggregationSRS, vk *VerifyingKey, proofs []*Proof, publicWitnesses []bls12_381witness.Witness) (*AggregatedProof, error) {
+	n, err := checkAggregationInputs(vk, len(proofs), publicWitnesses)
+	if err != nil {
+		return nil, err
+	}
+	if len(srs.G2.A) <= n || len(srs.G2.B) <= n || len(srs.G1.A) < 2*n || len(srs.G1.B) < 2*n {
+		return nil, fmt.Errorf("aggregation SRS supports up to %d proofs, got %d", len(srs.G2.A)-1, len(proofs))
+	}
+
+	a := make([]curve.G1Affine, n)
+	b := make([]curve.G2Affine, n)
+	c := make([]curve.G1Affine, n)
+	for i := 0; i < n; i++ {
+		p := proofs[min(i, len(proofs)-1)]
+		a[i], b[i], c[i] = p.Ar, p.Bs, p.Krs
+	}
+
+	// commitment keys, folded along with the proofs
+	var v [2][]curve.G2Affine
+	var w [2][]curve.G1Affine
+	v[0] = append([]curve.G2Affine(nil), srs.G2.A[:n]...)
+	v[1] = append([]curve.G2Affine(nil), srs.G2.B[:n]...)
+	w[0] = append([]curve.G1Affine(nil), srs.G1.A[n:2*n]...)
+	w[1] = append([]curve.G1Affine(nil), srs.G1.B[n:2*n]...)
+
+	proof := &AggregatedProof{}
+	for k := 0; k < 2; k++ {
+		if proof.ComAB[k], err = commitAB(v[k], w[k], a, b); err != nil {
+			return nil, err
+		}
+		if proof.ComC[k], err = curve.Pair(c, v[k]); err != nil {
+			return nil, err
+		}
+	}
+
+	fs := fiatshamir.NewTranscript(sha256.New(), aggregationChallengeIDs(n)...)
+	r, err := deriveAggregationChallenge(&fs, "r", proof.bindings(publicWitnesses, n)...)
+	if err != nil {
+		return nil, err
+	}
+	var rInv fr.Element
+	rInv.Inverse(&r)
+	rPowers, rInvPowers := powersOf(r, n), powersOf(rInv, n)
+
+	// B'ᵢ = rⁱBᵢ and w'ᵢ = r⁻ⁱwᵢ, so that the commitment to (A, B') under (v, w') is still ComAB
+	scaleG2(b, rPowers)
+	scaleG1(w[0], rInvPowers)
+	scaleG1(w[1], rInvPowers)
+
+	if proof.ZAB, err = curve.Pair(a, b); err != nil {
+		return nil, err
+	}
+	if proof.ZC, err = multiExpG1(c, rPowers); err != nil {
+		return nil, err
+	}
+
+	// inner product argument: at each round, the vectors are split in halves L, R and folded
+	// into L + x⋅R (A, C, w) or L + x⁻¹⋅R (B, r, v) for a random challenge x
+	challenges := make([]fr.Element, 0, bits.TrailingZeros(uint(n)))
+	rVec := append([]fr.Element(nil), rPowers...)
+	for round := 0; len(a) > 1; round++ {
+		h := len(a) / 2
+		var comABL, comABR, comCL, comCR [2]curve.GT
+		for k := 0; k < 2; k++ {
+			if comABL[k], err = commitAB(v[k][:h], w[k][h:], a[h:], b[:h]); err != nil {
+				return nil, err
+			}
+			if comABR[k], err = commitAB(v[k][h:], w[k][:h], a[:h], b[h:]); err != nil {
+				return nil, err
+			}
+			if comCL[k], err = curve.Pair(c[h:], v[k][:h]); err != nil {
+				return nil, err
+			}
+			if comCR[k], err = curve.Pair(c[:h], v[k][h:]); err != nil {
+				return nil, err
+			}
+		}
+		zABL, err := curve.Pair(a[h:], b[:h])
+		if err != nil {
+			return nil, err
+		}
+		zABR, err := curve.Pair(a[:h], b[h:])
+		if err != nil {
+			return nil, err
+		}
+		zCL, err := multiExpG1(c[h:], rVec[:h])
+		if err != nil {
+			return nil, err
+		}
+		zCR, err := multiExpG1(c[:h], rVec[h:])
+		if err != nil {
+			return nil, err
+		}
+		proof.ComABL = append(proof.ComABL, comABL)
+		proof.ComABR = append(proof.ComABR, comABR)
+		proof.ComCL = append(proof.ComCL, comCL)
+		proof.ComCR = append(proof.ComCR, comCR)
+		proof.ZABL = append(proof.ZABL, zABL)
+		proof.ZABR = append(proof.ZABR, zABR)
+		proof.ZCL = append(proof.ZCL, zCL)
+		proof.ZCR = append(proof.ZCR, zCR)
+
+		x, err := deriveAggregationChallenge(&fs, "x"+strconv.Itoa(round), proof.roundBindings(round)...)
+		if err != nil {
+			return nil, err
+		}
+		challenges = append(challenges, x)
+		var xInv big.Int
+		var xInvE fr.Element
+		xInvE.Inverse(&x)
+		xInvE.ToBigIntRegular(&xInv)
+		var xb big.Int
+		x.ToBigIntRegular(&xb)
+
+		a = foldG1(a, &xb)
+		c = foldG1(c, &xb)
+		b = foldG2(b, &xInv)
+		rVec = foldFr(rVec, xInvE)
+		for k := 0; k < 2; k++ {
+			v[k] = foldG2(v[k], &xInv)
+			w[k] = foldG1(w[k], &xb)
+		}
+	}
+	proof.A, proof.B, proof.C = a[0], b[0], c[0]
+	proof.V[0], proof.V[1] = v[0][0], v[1][0]
+	proof.W[0], proof.W[1] = w[0][0], w[1][0]
+
+	// the folded keys are commitments to the polynomials
+	// fv(X) = ∏ (1 + xⱼ⁻¹X^{2ᵏ}) and fw(X) = Xⁿ⋅∏ (1 + xⱼr^{-2ᵏ}X^{2ᵏ}), k = log(n)-1-j
+	// opened at a random point z
+	z, err := deriveAggregationChallenge(&fs, "z", proof.finalBindings()...)
+	if err != nil {
+		return nil, err
+	}
+	challengesInv := fr.BatchInvert(challenges)
+	var one fr.Element
+	one.SetOne()
+	fv := foldingPolynomial(challengesInv, one)
+	fw := make([]fr.Element, 2*n)
+	copy(fw[n:], foldingPolynomial(challenges, rInv))
+
+	qv, qw := kzgQuotient(fv, z), kzgQuotient(fw, z)
+	srsV := [2][]curve.G2Affine{srs.G2.A, srs.G2.B}
+	srsW := [2][]curve.G1Affine{srs.G1.A, srs.G1.B}
+	for k := 0; k < 2; k++ {
+		if len(qv) != 0 {
+			var j curve.G2Jac
+			if _, err := j.MultiExp(srsV[k][:len(qv)], qv, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+				return nil, err
+			}
+			proof.OpeningV[k].FromJacobian(&j)
+		}
+		if proof.OpeningW[k], err = multiExpG1(srsW[k][:len(qw)], qw); err != nil {
+			return nil, err
+		}
+	}
+
+	return proof, nil
+}
+
+// VerifyAggregatedProof verifies an AggregatedProof of proofs for vk and the public witnesses
+func VerifyAggregatedProof(proof *AggregatedProof, vk *VerifyingKey, avk *AggregationVerifyingKey, publicWitnesses []bls12_381witness.Witness) error {
+	n, err := checkAggregationInputs(vk, len(publicWitnesses), publicWitnesses)
+	if err != nil {
+		return err
+	}
+	nbRounds := bits.TrailingZeros(uint(n))
+	if len(proof.ComABL) != nbRounds || len(proof.ComABR) != nbRounds ||
+		len(proof.ComCL) != nbRounds || len(proof.ComCR) != nbRounds ||
+		len(proof.ZABL) != nbRounds || len(proof.ZABR) != nbRounds ||
+		len(proof.ZCL) != nbRounds || len(proof.ZCR) != nbRounds {
+		return errInvalidAggregatedProof
+	}
+	if !proof.isValid() {
+		return errCorrectSubgroupCheckFailed
+	}
+
+	fs := fiatshamir.NewTranscript(sha256.New(), aggregationChallengeIDs(n)...)
+	r, err := deriveAggregationChallenge(&fs, "r", proof.bindings(publicWitnesses, n)...)
+	if err != nil {
+		return err
+	}
+
+	// fold the commitments and inner products with the challenges of each round:
+	// X ← X_L^x ⋅ X ⋅ X_R^{x⁻¹}
+	comAB, comC, zAB, zC := proof.ComAB, proof.ComC, proof.ZAB, proof.ZC
+	challenges := make([]fr.Element, nbRounds)
+	for round := 0; round < nbRounds; round++ {
+		x, err := deriveAggregationChallenge(&fs, "x"+strconv.Itoa(round), proof.roundBindings(round)...)
+		if err != nil {
+			return err
+		}
+		challenges[round] = x
+		var xInvE fr.Element
+		xInvE.Inverse(&x)
+		var xb, xInv big.Int
+		x.ToBigIntRegular(&xb)
+		xInvE.ToBigIntRegular(&xInv)
+
+		for k := 0; k < 2; k++ {
+			foldGT(&comAB[k], &proof.ComABL[round][k], &proof.ComABR[round][k], &xb, &xInv)
+			foldGT(&comC[k], &proof.ComCL[round][k], &proof.ComCR[round][k], &xb, &xInv)
+		}
+		foldGT(&zAB, &proof.ZABL[round], &proof.ZABR[round], &xb, &xInv)
+
+		var zCJ, t curve.G1Jac
+		zCJ.FromAffine(&zC)
+		t.ScalarMultiplication(new(curve.G1Jac).FromAffine(&proof.ZCL[round]), &xb)
+		zCJ.AddAssign(&t)
+		t.ScalarMultiplication(new(curve.G1Jac).FromAffine(&proof.ZCR[round]), &xInv)
+		zCJ.AddAssign(&t)
+		zC.FromJacobian(&zCJ)
+	}
+	z, err := deriveAggregationChallenge(&fs, "z", proof.finalBindings()...)
+	if err != nil {
+		return err
+	}
+
+	// TIPP: the folded commitments and inner product match the folded proof elements
+	for k := 0; k < 2; k++ {
+		com, err := commitAB([]curve.G2Affine{proof.V[k]}, []curve.G1Affine{proof.W[k]}, []curve.G1Affine{proof.A}, []curve.G2Affine{proof.B})
+		if err != nil {
+			return err
+		}
+		if !com.Equal(&comAB[k]) {
+			return errAggregatedTIPPFailed
+		}
+	}
+	e, err := curve.Pair([]curve.G1Affine{proof.A}, []curve.G2Affine{proof.B})
+	if err != nil {
+		return err
+	}
+	if !e.Equal(&zAB) {
+		return errAggregatedTIPPFailed
+	}
+
+	// MIPP: same for C, with the folded r vector r₀ = ∏ (1 + xⱼ⁻¹r^{2ᵏ})
+	for k := 0; k < 2; k++ {
+		com, err := curve.Pair([]curve.G1Affine{proof.C}, []curve.G2Affine{proof.V[k]})
+		if err != nil {
+			return err
+		}
+		if !com.Equal(&comC[k]) {
+			return errAggregatedMIPPFailed
+		}
+	}
+	var one, r0, rInv fr.Element
+	one.SetOne()
+	challengesInv := fr.BatchInvert(challenges)
+	r0 = evaluateFoldingPolynomial(challengesInv, one, r)
+	var r0b big.Int
+	r0.ToBigIntRegular(&r0b)
+	var expectedZC curve.G1Affine
+	expectedZC.ScalarMultiplication(&proof.C, &r0b)
+	if !expectedZC.Equal(&zC) {
+		return errAggregatedMIPPFailed
+	}
+
+	// the folded keys are well formed: e([a]₁-[z]₁, π) = e([1]₁, V-[fv(z)]₂)
+	// and e(π, [a]₂-[z]₂) = e(W-[fw(z)]₁, [1]₂)
+	rInv.Inverse(&r)
+	fvz := evaluateFoldingPolynomial(challengesInv, one, z)
+	fwz := evaluateFoldingPolynomial(challenges, rInv, z)
+	var zn fr.Element
+	zn.Exp(z, big.NewInt(int64(n)))
+	fwz.Mul(&fwz, &zn)
+	var zb, fvzb, fwzb big.Int
+	z.ToBigIntRegular(&zb)
+	fvz.ToBigIntRegular(&fvzb)
+	fwz.ToBigIntRegular(&fwzb)
+
+	g1Secrets := [2]curve.G1Affine{avk.G1.A, avk.G1.B}
+	g2Secrets := [2]curve.G2Affine{avk.G2.A, avk.G2.B}
+	var g1z, g1NegOne curve.G1Affine
+	var g2z curve.G2Affine
+	g1z.ScalarMultiplication(&avk.G1.One, &zb)
+	g1NegOne.Neg(&avk.G1.One)
+	g2z.ScalarMultiplication(&avk.G2.One, &zb)
+	for k := 0; k < 2; k++ {
+		var g1SecretMinusZ, wMinusFw, t curve.G1Affine
+		g1SecretMinusZ.Sub(&g1Secrets[k], &g1z)
+		t.ScalarMultiplication(&avk.G1.One, &fwzb)
+		wMinusFw.Sub(&proof.W[k], &t)
+		wMinusFw.Neg(&wMinusFw)
+
+		var vMinusFv, g2SecretMinusZ curve.G2Affine
+		var j, t2 curve.G2Jac
+		j.FromAffine(&proof.V[k])
+		t2.FromAffine(&avk.G2.One)
+		t2.ScalarMultiplication(&t2, &fvzb)
+		j.SubAssign(&t2)
+		vMinusFv.FromJacobian(&j)
+		j.FromAffine(&g2Secrets[k])
+		t2.FromAffine(&g2z)
+		j.SubAssign(&t2)
+		g2SecretMinusZ.FromJacobian(&j)
+
+		ok, err := curve.PairingCheck([]curve.G1Affine{g1SecretMinusZ, g1NegOne}, []curve.G2Affine{proof.OpeningV[k], vMinusFv})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errAggregatedKeysFailed
+		}
+		ok, err = curve.PairingCheck([]curve.G1Affine{proof.OpeningW[k], wMinusFw}, []curve.G2Affine{g2SecretMinusZ, avk.G2.One})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errAggregatedKeysFailed
+		}
+	}
+
+	// randomized Groth16 equation: Z_AB⋅e(Σ rⁱSᵢ, -γ)⋅e(Z_C, -δ) = e(α, β)^{Σrⁱ}
+	rPowers := powersOf(r, n)
+	scalars := make([]fr.Element, len(vk.G1.K))
+	for i := 0; i < n; i++ {
+		scalars[0].Add(&scalars[0], &rPowers[i])
+		pw := publicWitnesses[min(i, len(publicWitnesses)-1)]
+		for j := range pw {
+			var t fr.Element
+			t.Mul(&pw[j], &rPowers[i])
+			scalars[j+1].Add(&scalars[j+1], &t)
+		}
+	}
+	kSum, err := multiExpG1(vk.G1.K, scalars)
+	if err != nil {
+		return err
+	}
+	ml, err := curve.MillerLoop([]curve.G1Affine{kSum, proof.ZC}, []curve.G2Affine{vk.G2.gammaNeg, vk.G2.deltaNeg})
+	if err != nil {
+		return err
+	}
+	right := curve.FinalExponentiation(&ml)
+	right.Mul(&right, &proof.ZAB)
+
+	var sumR big.Int
+	scalars[0].ToBigIntRegular(&sumR)
+	var left curve.GT
+	left.Exp(&vk.e, sumR)
+	if !left.Equal(&right) {
+		return errPairingCheckFailed
+	}
+
+	return nil
+}
+
+// CurveID returns the curveID
+func (proof *AggregatedProof) CurveID() ecc.ID {
+	return curve.ID
+}
+
+// CurveID returns the curveID
+func (srs *AggregationSRS) CurveID() ecc.ID {
+	return curve.ID
+}
+
+// CurveID returns the curveID
+func (avk *AggregationVerifyingKey) CurveID() ecc.ID {
+	return curve.ID
+}
+
+// isValid ensures the points of the aggregated proof are in the correct subgroup
+func (proof *AggregatedProof) isValid() bool {
+	if !proof.ZC.IsInSubGroup() || !proof.A.IsInSubGroup() || !proof.C.IsInSubGroup() || !proof.B.IsInSubGroup() {
+		return false
+	}
+	for k := 0; k < 2; k++ {
+		if !proof.V[k].IsInSubGroup() || !proof.W[k].IsInSubGroup() ||
+			!proof.OpeningV[k].IsInSubGroup() || !proof.OpeningW[k].IsInSubGroup() {
+			return false
+		}
+	}
+	for i := range proof.ZCL {
+		if !proof.ZCL[i].IsInSubGroup() || !proof.ZCR[i].IsInSubGroup() {
+			return false
+		}
+	}
+	return true
+}
+
+// checkAggregationInputs checks the number of proofs and the size of the public witnesses,
+// and returns the number of aggregated proofs, padded to a power of two
+func checkAggregationInputs(vk *VerifyingKey, nbProofs int, publicWitnesses []bls12_381witness.Witness) (int, error) {
+	if nbProofs == 0 {
+		return 0, errors.New("no proof to aggregate")
+	}
+	if nbProofs != len(publicWitnesses) {
+		return 0, fmt.Errorf("got %d proofs and %d public witnesses", nbProofs, len(publicWitnesses))
+	}
+	if vk.NbCommitments > 0 {
+		return 0, errors.New("aggregation of proofs with a commitment is not supported")
+	}
+	for i := range publicWitnesses {
+		if len(publicWitnesses[i]) != (len(vk.G1.K) - 1) {
+			return 0, fmt.Errorf("invalid witness size, got %d, expected %d (public - ONE_WIRE)", len(publicWitnesses[i]), len(vk.G1.K)-1)
+		}
+	}
+	return 1 << bits.Len(uint(nbProofs-1)), nil
+}
+
+// aggregationChallengeIDs returns the names of the Fiat-Shamir challenges, in order
+func aggregationChallengeIDs(n int) []string {
+	ids := []string{"r"}
+	for round := 0; 1<<round < n; round++ {
+		ids = append(ids, "x"+strconv.Itoa(round))
+	}
+	return append(ids, "z")
+}
+
+// deriveAggregationChallenge binds the values to the challenge and returns it as a field element
+func deriveAggregationChallenge(fs *fiatshamir.Transcript, challenge string, values ...[]byte) (fr.Element, error) {
+	var res fr.Element
+	for _, v := range values {
+		if err := fs.Bind(challenge, v); err != nil {
+			return res, err
+		}
+	}
+	b, err := fs.ComputeChallenge(challenge)
+	if err != nil {
+		return res, err
+	}
+	res.SetBytes(b)
+	if res.IsZero() {
+		return res, fmt.Errorf("challenge %s is zero", challenge)
+	}
+	return res, nil
+}
+
+func gtBytes(e ...*curve.GT) [][]byte {
+	res := make([][]byte, len(e))
+	for i := range e {
+		b := e[i].Bytes()
+		res[i] = b[:]
+	}
+	return res
+}
+
+// bindings returns the values the challenge r is derived from: the commitments and the public inputs
+func (proof *AggregatedProof) bindings(publicWitnesses []bls12_381witness.Witness, n int) [][]byte {
+	res := gtBytes(&proof.ComAB[0], &proof.ComAB[1], &proof.ComC[0], &proof.ComC[1])
+	for i := 0; i < n; i++ {
+		pw := publicWitnesses[min(i, len(publicWitnesses)-1)]
+		for j := range pw {
+			res = append(res, pw[j].Marshal())
+		}
+	}
+	return res
+}
+
+// roundBindings returns the values the challenge of a round of the inner product argument is derived from
+func (proof *AggregatedProof) roundBindings(round int) [][]byte {
+	res := gtBytes(
+		&proof.ComABL[round][0], &proof.ComABR[round][0], &proof.ComCL[round][0], &proof.ComCR[round][0],
+		&proof.ComABL[round][1], &proof.ComABR[round][1], &proof.ComCL[round][1], &proof.ComCR[round][1],
+		&proof.ZABL[round], &proof.ZABR[round],
+	)
+	return append(res, proof.ZCL[round].Marshal(), proof.ZCR[round].Marshal())
+}
+
+// finalBindings returns the values the opening point of the commitment keys is derived from
+func (proof *AggregatedProof) finalBindings() [][]byte {
+	return [][]byte{
+		proof.A.Marshal(), proof.B.Marshal(), proof.C.Marshal(),
+		proof.V[0].Marshal(), proof.V[1].Marshal(), proof.W[0].Marshal(), proof.W[1].Marshal(),
+	}
+}
+
+// commitAB returns ∏ e(aᵢ, vᵢ)⋅e(wᵢ, bᵢ)
+func commitAB(v []curve.G2Affine, w, a []curve.G1Affine, b []curve.G2Affine) (curve.GT, error) {
+	P := make([]curve.G1Affine, 0, len(a)+len(w))
+	Q := make([]curve.G2Affine, 0, len(v)+len(b))
+	P = append(append(P, a...), w...)
+	Q = append(append(Q, v...), b...)
+	return curve.Pair(P, Q)
+}
+
+func multiExpG1(points []curve.G1Affine, scalars []fr.Element) (curve.G1Affine, error) {
+	var res curve.G1Affine
+	var j curve.G1Jac
+	if _, err := j.MultiExp(points, scalars, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+		return res, err
+	}
+	res.FromJacobian(&j)
+	return res, nil
+}
+
+// powersOf returns [1, x, ..., xⁿ⁻¹]
+func powersOf(x fr.Element, n int) []fr.Element {
+	res := make([]fr.Element, n)
+	res[0].SetOne()
+	for i := 1; i < n; i++ {
+		res[i].Mul(&res[i-1], &x)
+	}
+	return res
+}
+
+// scaleG1 sets pᵢ to sᵢ⋅pᵢ
+func scaleG1(p []curve.G1Affine, s []fr.Element) {
+	utils.Parallelize(len(p), func(start, end int) {
+		var j curve.G1Jac
+		var b big.Int
+		for i := start; i < end; i++ {
+			j.FromAffine(&p[i])
+			j.ScalarMultiplication(&j, s[i].ToBigIntRegular(&b))
+			p[i].FromJacobian(&j)
+		}
+	})
+}
+
+// scaleG2 sets pᵢ to sᵢ⋅pᵢ
+func scaleG2(p []curve.G2Affine, s []fr.Element) {
+	utils.Parallelize(len(p), func(start, end int) {
+		var j curve.G2Jac
+		var b big.Int
+		for i := start; i < end; i++ {
+			j.FromAffine(&p[i])
+			j.ScalarMultiplication(&j, s[i].ToBigIntRegular(&b))
+			p[i].FromJacobian(&j)
+		}
+	})
+}
+
+// foldG1 returns the first half of p, set to L + x⋅R
+func foldG1(p []curve.G1Affine, x *big.Int) []curve.G1Affine {
+	h := len(p) / 2
+	utils.Parallelize(h, func(start, end int) {
+		var j curve.G1Jac
+		for i := start; i < end; i++ {
+			j.FromAffine(&p[h+i])
+			j.ScalarMultiplication(&j, x)
+			j.AddMixed(&p[i])
+			p[i].FromJacobian(&j)
+		}
+	})
+	return p[:h]
+}
+
+// foldG2 returns the first half of p, set to L + x⋅R
+func foldG2(p []curve.G2Affine, x *big.Int) []curve.G2Affine {
+	h := len(p) / 2
+	utils.Parallelize(h, func(start, end int) {
+		var j curve.G2Jac
+		for i := start; i < end; i++ {
+			j.FromAffine(&p[h+i])
+			j.ScalarMultiplication(&j, x)
+			j.AddMixed(&p[i])
+			p[i].FromJacobian(&j)
+		}
+	})
+	return p[:h]
+}
+
+// foldFr returns the first half of p, set to L + x⋅R
+func foldFr(p []fr.Element, x fr.Element) []fr.Element {
+	h := len(p) / 2
+	for i := 0; i < h; i++ {
+		var t fr.Element
+		t.Mul(&p[h+i], &x)
+		p[i].Add(&p[i], &t)
+	}
+	return p[:h]
+}
+
+// foldGT sets z to l^x ⋅ z ⋅ r^{xInv}
+func foldGT(z, l, r *curve.GT, x, xInv *big.Int) {
+	var t curve.GT
+	t.Exp(l, *x)
+	z.Mul(z, &t)
+	t.Exp(r, *xInv)
+	z.Mul(z, &t)
+}
+
+// foldingPolynomial returns the coefficients of ∏ (1 + cⱼs^{2ᵏ}X^{2ᵏ}), k = len(c)-1-j,
+// the i-th coefficient is the factor of the i-th entry of a vector folded with the challenges c
+// and scaled by sⁱ
+func foldingPolynomial(c []fr.Element, s fr.Element) []fr.Element {
+	res := make([]fr.Element, 1, 1<<len(c))
+	res[0].SetOne()
+	for j := len(c) - 1; j >= 0; j-- {
+		var t fr.Element
+		t.Mul(&c[j], &s)
+		for i := range res {
+			var u fr.Element
+			u.Mul(&res[i], &t)
+			res = append(res, u)
+		}
+		s.Square(&s)
+	}
+	return res
+}
+
+// evaluateFoldingPolynomial returns ∏ (1 + cⱼs^{2ᵏ}z^{2ᵏ}), k = len(c)-1-j
+func evaluateFoldingPolynomial(c []fr.Element, s, z fr.Element) fr.Element {
+	var res, one fr.Element
+	res.SetOne()
+	one.SetOne()
+	s.Mul(&s, &z)
+	for j := len(c) - 1; j >= 0; j-- {
+		var t fr.Element
+		t.Mul(&c[j], &s).Add(&t, &one)
+		res.Mul(&res, &t)
+		s.Square(&s)
+	}
+	return res
+}
+
+// kzgQuotient returns the coefficients of (p(X) - p(z)) / (X - z)
+func kzgQuotient(p []fr.Element, z fr.Element) []fr.Element {
+	if len(p) < 2 {
+		return nil
+	}
+	q := make([]fr.Element, len(p)-1)
+	q[len(q)-1] = p[len(p)-1]
+	for i := len(q) - 1; i > 0; i-- {
+		q[i-1].Mul(&q[i], &z).Add(&q[i-1], &p[i])
+	}
+	return q
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// WriteTo writes binary encoding of the AggregationSRS to writer
+func (srs *AggregationSRS) WriteTo(w io.Writer) (int64, error) {
+	enc := curve.NewEncoder(w)
+	toEncode := []interface{}{srs.G1.A, srs.G1.B, srs.G2.A, srs.G2.B}
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+	return enc.BytesWritten(), nil
+}
+
+// ReadFrom decodes an AggregationSRS from reader
+func (srs *AggregationSRS) ReadFrom(r io.Reader) (int64, error) {
+	dec := curve.NewDecoder(r)
+	toDecode := []interface{}{&srs.G1.A, &srs.G1.B, &srs.G2.A, &srs.G2.B}
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+	return dec.BytesRead(), nil
+}
+
+// WriteTo writes binary encoding of the AggregationVerifyingKey to writer
+func (avk *AggregationVerifyingKey) WriteTo(w io.Writer) (int64, error) {
+	enc := curve.NewEncoder(w)
+	toEncode := []interface{}{&avk.G1.One, &avk.G1.A, &avk.G1.B, &avk.G2.One, &avk.G2.A, &avk.G2.B}
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+	return enc.BytesWritten(), nil
+}
+
+// ReadFrom decodes an AggregationVerifyingKey from reader
+func (avk *AggregationVerifyingKey) ReadFrom(r io.Reader) (int64, error) {
+	dec := curve.NewDecoder(r)
+	toDecode := []interface{}{&avk.G1.One, &avk.G1.A, &avk.G1.B, &avk.G2.One, &avk.G2.A, &avk.G2.B}
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+	return dec.BytesRead(), nil
+}
+
+// WriteTo writes binary encoding of the AggregatedProof to writer
+//
+// the number of rounds is encoded first, followed by the GT elements and the points
+func (proof *AggregatedProof) WriteTo(w io.Writer) (int64, error) {
+	nbRounds := len(proof.ZCL)
+	var written int64
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], uint32(nbRounds))
+	m, err := w.Write(buf[:])
+	written += int64(m)
+	if err != nil {
+		return written, err
+	}
+
+	for _, e := range proof.gtElements() {
+		b := e.Bytes()
+		m, err := w.Write(b[:])
+		written += int64(m)
+		if err != nil {
+			return written, err
+		}
+	}
+
+	enc := curve.NewEncoder(w)
+	toEncode := []interface{}{
+		&proof.ZC, proof.ZCL, proof.ZCR,
+		&proof.A, &proof.B, &proof.C,
+		&proof.V[0], &proof.V[1], &proof.W[0], &proof.W[1],
+		&proof.OpeningV[0], &proof.OpeningV[1], &proof.OpeningW[0], &proof.OpeningW[1],
+	}
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return written + enc.BytesWritten(), err
+		}
+	}
+	return written + enc.BytesWritten(), nil
+}
+
+// ReadFrom decodes an AggregatedProof from reader
+func (proof *AggregatedProof) ReadFrom(r io.Reader) (int64, error) {
+	var read int64
+	var buf [curve.SizeOfGT]byte
+	m, err := io.ReadFull(r, buf[:4])
+	read += int64(m)
+	if err != nil {
+		return read, err
+	}
+	nbRounds := int(binary.BigEndian.Uint32(buf[:4]))
+	if nbRounds >= 64 {
+		return read, errInvalidAggregatedProof
+	}
+	proof.ComABL = make([][2]curve.GT, nbRounds)
+	proof.ComABR = make([][2]curve.GT, nbRounds)
+	proof.ComCL = make([][2]curve.GT, nbRounds)
+	proof.ComCR = make([][2]curve.GT, nbRounds)
+	proof.ZABL = make([]curve.GT, nbRounds)
+	proof.ZABR = make([]curve.GT, nbRounds)
+
+	for _, e := range proof.gtElements() {
+		m, err := io.ReadFull(r, buf[:])
+		read += int64(m)
+		if err != nil {
+			return read, err
+		}
+		if err := e.SetBytes(buf[:]); err != nil {
+			return read, err
+		}
+	}
+
+	dec := curve.NewDecoder(r)
+	toDecode := []interface{}{
+		&proof.ZC, &proof.ZCL, &proof.ZCR,
+		&proof.A, &proof.B, &proof.C,
+		&proof.V[0], &proof.V[1], &proof.W[0], &proof.W[1],
+		&proof.OpeningV[0], &proof.OpeningV[1], &proof.OpeningW[0], &proof.OpeningW[1],
+	}
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return read + dec.BytesRead(), err
+		}
+	}
+	if len(proof.ZCL) != nbRounds || len(proof.ZCR) != nbRounds {
+		return read + dec.BytesRead(), errInvalidAggregatedProof
+	}
+	return read + dec.BytesRead(), nil
+}
+
+// gtElements returns pointers to the GT elements of the proof, in serialization order
+func (proof *AggregatedProof) gtElements() []*curve.GT {
+	res := []*curve.GT{&proof.ComAB[0], &proof.ComAB[1], &proof.ComC[0], &proof.ComC[1], &proof.ZAB}
+	for i := range proof.ZABL {
+		for k := 0; k < 2; k++ {
+			res = append(res, &proof.ComABL[i][k], &proof.ComABR[i][k], &proof.ComCL[i][k], &proof.ComCR[i][k])
+		}
+		res = append(res, &proof.ZABL[i], &proof.ZABR[i])
+	}
+	return res
+}
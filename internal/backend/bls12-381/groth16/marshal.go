@@ -17,22 +17,31 @@
 package groth16
 
 import (
+	"github.com/consensys/gnark-crypto/ecc"
 	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/backend"
+	gnarkio "github.com/consensys/gnark/io"
 	"io"
 )
 
 // WriteTo writes binary encoding of the Proof elements to writer
-// points are stored in compressed form Ar | Krs | Bs
+// points are stored in compressed form Ar | Krs | Bs, followed by Commitment | CommitmentPok
+// if the circuit commits to some of its wires
 // use WriteRawTo(...) to encode the proof without point compression
 func (proof *Proof) WriteTo(w io.Writer) (n int64, err error) {
-	return proof.writeTo(w, false)
+	return writeWithHeader(w, gnarkio.KindProof, func(w io.Writer) (int64, error) {
+		return proof.writeTo(w, false)
+	})
 }
 
 // WriteRawTo writes binary encoding of the Proof elements to writer
-// points are stored in uncompressed form Ar | Krs | Bs
+// points are stored in uncompressed form Ar | Krs | Bs, followed by Commitment | CommitmentPok
+// if the circuit commits to some of its wires
 // use WriteTo(...) to encode the proof with point compression
 func (proof *Proof) WriteRawTo(w io.Writer) (n int64, err error) {
-	return proof.writeTo(w, true)
+	return writeWithHeader(w, gnarkio.KindProof, func(w io.Writer) (int64, error) {
+		return proof.writeTo(w, true)
+	})
 }
 
 func (proof *Proof) writeTo(w io.Writer, raw bool) (int64, error) {
@@ -52,12 +61,31 @@ func (proof *Proof) writeTo(w io.Writer, raw bool) (int64, error) {
 	if err := enc.Encode(&proof.Krs); err != nil {
 		return enc.BytesWritten(), err
 	}
+	if proof.Commitment.IsInfinity() && proof.CommitmentPok.IsInfinity() {
+		return enc.BytesWritten(), nil
+	}
+	if err := enc.Encode(&proof.Commitment); err != nil {
+		return enc.BytesWritten(), err
+	}
+	if err := enc.Encode(&proof.CommitmentPok); err != nil {
+		return enc.BytesWritten(), err
+	}
 	return enc.BytesWritten(), nil
 }
 
 // ReadFrom attempts to decode a Proof from reader
 // Proof must be encoded through WriteTo (compressed) or WriteRawTo (uncompressed)
 func (proof *Proof) ReadFrom(r io.Reader) (n int64, err error) {
+	r, version, n, err := gnarkio.ReadHeader(r, ecc.BLS12_381, backend.GROTH16, gnarkio.KindProof)
+	if err != nil {
+		return n, err
+	}
+	m, err := proof.readFrom(r, version)
+	return n + m, err
+}
+
+// readFrom reads the encoding of the given version of Proof from r
+func (proof *Proof) readFrom(r io.Reader, version uint8) (int64, error) {
 
 	dec := curve.NewDecoder(r)
 
@@ -71,6 +99,22 @@ func (proof *Proof) ReadFrom(r io.Reader) (n int64, err error) {
 		return dec.BytesRead(), err
 	}
 
+	// the proof of a circuit without commitment ends here, as the proofs
+	// written before the headers
+	proof.Commitment, proof.CommitmentPok = curve.G1Affine{}, curve.G1Affine{}
+	if version == 0 {
+		return dec.BytesRead(), nil
+	}
+	if err := dec.Decode(&proof.Commitment); err != nil {
+		if err == io.EOF {
+			return dec.BytesRead(), nil
+		}
+		return dec.BytesRead(), err
+	}
+	if err := dec.Decode(&proof.CommitmentPok); err != nil {
+		return dec.BytesRead(), err
+	}
+
 	return dec.BytesRead(), nil
 }
 
@@ -78,20 +122,26 @@ func (proof *Proof) ReadFrom(r io.Reader) (n int64, err error) {
 // points are compressed
 // use WriteRawTo(...) to encode the key without point compression
 func (vk *VerifyingKey) WriteTo(w io.Writer) (n int64, err error) {
-	return vk.writeTo(w, false)
+	return writeWithHeader(w, gnarkio.KindVerifyingKey, func(w io.Writer) (int64, error) {
+		return vk.writeTo(w, false)
+	})
 }
 
 // WriteRawTo writes binary encoding of the key elements to writer
 // points are not compressed
 // use WriteTo(...) to encode the key with point compression
 func (vk *VerifyingKey) WriteRawTo(w io.Writer) (n int64, err error) {
-	return vk.writeTo(w, true)
+	return writeWithHeader(w, gnarkio.KindVerifyingKey, func(w io.Writer) (int64, error) {
+		return vk.writeTo(w, true)
+	})
 }
 
 // writeTo serialization format:
 // follows bellman format:
 // https://github.com/zkcrypto/bellman/blob/fa9be45588227a8c6ec34957de3f68705f07bd92/src/groth16/mod.rs#L143
 // [α]1,[β]1,[β]2,[γ]2,[δ]1,[δ]2,uint32(len(Kvk)),[Kvk]1
+// followed by uint64(NbCommitments) and, if it is not 0, the commitment key [1]2,-[1/σ]2,
+// uint32(len(Basis)),[Basis]1, and by the 32 bytes of the CircuitFingerprint
 func (vk *VerifyingKey) writeTo(w io.Writer, raw bool) (int64, error) {
 	var enc *curve.Encoder
 	if raw {
@@ -124,6 +174,27 @@ func (vk *VerifyingKey) writeTo(w io.Writer, raw bool) (int64, error) {
 	if err := enc.Encode(vk.G1.K); err != nil {
 		return enc.BytesWritten(), err
 	}
+
+	// uint64(NbCommitments),[1]2,-[1/σ]2,uint32(len(Basis)),[Basis]1
+	if err := enc.Encode(vk.NbCommitments); err != nil {
+		return enc.BytesWritten(), err
+	}
+	if vk.NbCommitments > 0 {
+		if err := enc.Encode(&vk.CommitmentKey.G); err != nil {
+			return enc.BytesWritten(), err
+		}
+		if err := enc.Encode(&vk.CommitmentKey.GRootSigmaNeg); err != nil {
+			return enc.BytesWritten(), err
+		}
+		if err := enc.Encode(vk.CommitmentKey.Basis); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+
+	// [32]byte CircuitFingerprint
+	if err := enc.Encode(&vk.CircuitFingerprint); err != nil {
+		return enc.BytesWritten(), err
+	}
 	return enc.BytesWritten(), nil
 }
 
@@ -133,16 +204,27 @@ func (vk *VerifyingKey) writeTo(w io.Writer, raw bool) (int64, error) {
 // https://github.com/zkcrypto/bellman/blob/fa9be45588227a8c6ec34957de3f68705f07bd92/src/groth16/mod.rs#L143
 // [α]1,[β]1,[β]2,[γ]2,[δ]1,[δ]2,uint32(len(Kvk)),[Kvk]1
 func (vk *VerifyingKey) ReadFrom(r io.Reader) (int64, error) {
-	return vk.readFrom(r)
+	return vk.readWithHeader(r)
 }
 
 // UnsafeReadFrom has the same behavior as ReadFrom, except that it will not check that decode points
 // are on the curve and in the correct subgroup.
 func (vk *VerifyingKey) UnsafeReadFrom(r io.Reader) (int64, error) {
-	return vk.readFrom(r, curve.NoSubgroupChecks())
+	return vk.readWithHeader(r, curve.NoSubgroupChecks())
 }
 
-func (vk *VerifyingKey) readFrom(r io.Reader, decOptions ...func(*curve.Decoder)) (int64, error) {
+// readWithHeader reads and checks the header of a VerifyingKey, then the
+// VerifyingKey in the encoding of the version read
+func (vk *VerifyingKey) readWithHeader(r io.Reader, decOptions ...func(*curve.Decoder)) (int64, error) {
+	r, version, n, err := gnarkio.ReadHeader(r, ecc.BLS12_381, backend.GROTH16, gnarkio.KindVerifyingKey)
+	if err != nil {
+		return n, err
+	}
+	m, err := vk.readFrom(r, version, decOptions...)
+	return n + m, err
+}
+
+func (vk *VerifyingKey) readFrom(r io.Reader, version uint8, decOptions ...func(*curve.Decoder)) (int64, error) {
 	dec := curve.NewDecoder(r, decOptions...)
 
 	// [α]1,[β]1,[β]2,[γ]2,[δ]1,[δ]2
@@ -170,127 +252,63 @@ func (vk *VerifyingKey) readFrom(r io.Reader, decOptions ...func(*curve.Decoder)
 		return dec.BytesRead(), err
 	}
 
-	// recompute vk.e (e(α, β)) and  -[δ]2, -[γ]2
-	var err error
-	vk.e, err = curve.Pair([]curve.G1Affine{vk.G1.Alpha}, []curve.G2Affine{vk.G2.Beta})
-	if err != nil {
+	// uint64(NbCommitments),[1]2,-[1/σ]2,uint32(len(Basis)),[Basis]1; the keys
+	// written before the headers end here, as the bellman ones
+	vk.NbCommitments = 0
+	vk.CommitmentKey.Basis = nil
+	if version == 0 {
+		vk.CircuitFingerprint = [32]byte{}
+		return dec.BytesRead(), vk.Precompute()
+	}
+	if err := dec.Decode(&vk.NbCommitments); err != nil {
 		return dec.BytesRead(), err
 	}
-	vk.G2.deltaNeg.Neg(&vk.G2.Delta)
-	vk.G2.gammaNeg.Neg(&vk.G2.Gamma)
-
-	return dec.BytesRead(), nil
-}
-
-// WriteTo writes binary encoding of the key elements to writer
-// points are compressed
-// use WriteRawTo(...) to encode the key without point compression
-func (pk *ProvingKey) WriteTo(w io.Writer) (n int64, err error) {
-	return pk.writeTo(w, false)
-}
-
-// WriteRawTo writes binary encoding of the key elements to writer
-// points are not compressed
-// use WriteTo(...) to encode the key with point compression
-func (pk *ProvingKey) WriteRawTo(w io.Writer) (n int64, err error) {
-	return pk.writeTo(w, true)
-}
-
-func (pk *ProvingKey) writeTo(w io.Writer, raw bool) (int64, error) {
-	n, err := pk.Domain.WriteTo(w)
-	if err != nil {
-		return n, err
+	if vk.NbCommitments > 0 {
+		if err := dec.Decode(&vk.CommitmentKey.G); err != nil {
+			return dec.BytesRead(), err
+		}
+		if err := dec.Decode(&vk.CommitmentKey.GRootSigmaNeg); err != nil {
+			return dec.BytesRead(), err
+		}
+		if err := dec.Decode(&vk.CommitmentKey.Basis); err != nil {
+			return dec.BytesRead(), err
+		}
 	}
 
-	var enc *curve.Encoder
-	if raw {
-		enc = curve.NewEncoder(w, curve.RawEncoding())
-	} else {
-		enc = curve.NewEncoder(w)
-	}
-	nbWires := uint64(len(pk.InfinityA))
-
-	toEncode := []interface{}{
-		&pk.G1.Alpha,
-		&pk.G1.Beta,
-		&pk.G1.Delta,
-		pk.G1.A,
-		pk.G1.B,
-		pk.G1.Z,
-		pk.G1.K,
-		&pk.G2.Beta,
-		&pk.G2.Delta,
-		pk.G2.B,
-		nbWires,
-		pk.NbInfinityA,
-		pk.NbInfinityB,
-		pk.InfinityA,
-		pk.InfinityB,
-	}
-
-	for _, v := range toEncode {
-		if err := enc.Encode(v); err != nil {
-			return n + enc.BytesWritten(), err
+	// [32]byte CircuitFingerprint
+	vk.CircuitFingerprint = [32]byte{}
+	if version >= gnarkio.VersionCircuitFingerprint {
+		if err := dec.Decode(&vk.CircuitFingerprint); err != nil {
+			return dec.BytesRead(), err
 		}
 	}
 
-	return n + enc.BytesWritten(), nil
-
-}
-
-// ReadFrom attempts to decode a ProvingKey from reader
-// ProvingKey must be encoded through WriteTo (compressed) or WriteRawTo (uncompressed)
-// note that we don't check that the points are on the curve or in the correct subgroup at this point
-func (pk *ProvingKey) ReadFrom(r io.Reader) (int64, error) {
-	return pk.readFrom(r)
-}
+	// recompute vk.e (e(α, β)) and  -[δ]2, -[γ]2
+	if err := vk.Precompute(); err != nil {
+		return dec.BytesRead(), err
+	}
 
-// UnsafeReadFrom behaves like ReadFrom excepts it doesn't check if the decoded points are on the curve
-// or in the correct subgroup
-func (pk *ProvingKey) UnsafeReadFrom(r io.Reader) (int64, error) {
-	return pk.readFrom(r, curve.NoSubgroupChecks())
+	return dec.BytesRead(), nil
 }
 
-func (pk *ProvingKey) readFrom(r io.Reader, decOptions ...func(*curve.Decoder)) (int64, error) {
-	n, err := pk.Domain.ReadFrom(r)
+// writeWithHeader writes the header of an object of the given kind (see
+// gnarkio.WriteHeader), then the object with writeTo
+func writeWithHeader(w io.Writer, kind gnarkio.Kind, writeTo func(io.Writer) (int64, error)) (int64, error) {
+	n, err := gnarkio.WriteHeader(w, ecc.BLS12_381, backend.GROTH16, kind)
 	if err != nil {
 		return n, err
 	}
+	m, err := writeTo(w)
+	return n + m, err
+}
 
-	dec := curve.NewDecoder(r, decOptions...)
-
-	var nbWires uint64
-
-	toDecode := []interface{}{
-		&pk.G1.Alpha,
-		&pk.G1.Beta,
-		&pk.G1.Delta,
-		&pk.G1.A,
-		&pk.G1.B,
-		&pk.G1.Z,
-		&pk.G1.K,
-		&pk.G2.Beta,
-		&pk.G2.Delta,
-		&pk.G2.B,
-		&nbWires,
-		&pk.NbInfinityA,
-		&pk.NbInfinityB,
-	}
-
-	for _, v := range toDecode {
-		if err := dec.Decode(v); err != nil {
-			return n + dec.BytesRead(), err
-		}
-	}
-	pk.InfinityA = make([]bool, nbWires)
-	pk.InfinityB = make([]bool, nbWires)
-
-	if err := dec.Decode(&pk.InfinityA); err != nil {
-		return n + dec.BytesRead(), err
-	}
-	if err := dec.Decode(&pk.InfinityB); err != nil {
-		return n + dec.BytesRead(), err
+// readWithHeader reads and checks the header of an object of the given kind
+// (see gnarkio.ReadHeader), then the object with readFrom
+func readWithHeader(r io.Reader, kind gnarkio.Kind, readFrom func(io.Reader) (int64, error)) (int64, error) {
+	r, _, n, err := gnarkio.ReadHeader(r, ecc.BLS12_381, backend.GROTH16, kind)
+	if err != nil {
+		return n, err
 	}
-
-	return n + dec.BytesRead(), nil
+	m, err := readFrom(r)
+	return n + m, err
 }
@@ -0,0 +1,170 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package groth16
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
+)
+
+// The points of BLS12-381 are serialized the same way by gnark-crypto, bellman
+// and arkworks (since ark-bls12-381 0.4): in big-endian, with the Zcash flags.
+// The layouts below only differ by the compression of the points and the
+// encoding of the lengths of the vectors.
+
+var errCompatCommitment = errors.New("the arkworks and bellman encodings don't support commitments")
+
+// WriteArkworksTo writes the proof as ark-groth16 serializes it (compressed):
+// [A]1,[B]2,[C]1
+func (proof *Proof) WriteArkworksTo(w io.Writer) (int64, error) {
+	return proof.writeCompatTo(w)
+}
+
+// ReadArkworksFrom reads a proof written by WriteArkworksTo
+func (proof *Proof) ReadArkworksFrom(r io.Reader) (int64, error) {
+	return proof.readCompatFrom(r)
+}
+
+// WriteBellmanTo writes the proof as bellman serializes it: [A]1,[B]2,[C]1
+// compressed
+func (proof *Proof) WriteBellmanTo(w io.Writer) (int64, error) {
+	return proof.writeCompatTo(w)
+}
+
+// ReadBellmanFrom reads a proof written by WriteBellmanTo
+func (proof *Proof) ReadBellmanFrom(r io.Reader) (int64, error) {
+	return proof.readCompatFrom(r)
+}
+
+func (proof *Proof) writeCompatTo(w io.Writer) (int64, error) {
+	if !proof.Commitment.IsInfinity() {
+		return 0, errCompatCommitment
+	}
+	enc := curve.NewEncoder(w)
+	toEncode := []interface{}{&proof.Ar, &proof.Bs, &proof.Krs}
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+	return enc.BytesWritten(), nil
+}
+
+func (proof *Proof) readCompatFrom(r io.Reader) (int64, error) {
+	dec := curve.NewDecoder(r)
+	toDecode := []interface{}{&proof.Ar, &proof.Bs, &proof.Krs}
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+	proof.Commitment, proof.CommitmentPok = curve.G1Affine{}, curve.G1Affine{}
+	return dec.BytesRead(), nil
+}
+
+// WriteArkworksTo writes the verifying key as ark-groth16 serializes it
+// (compressed): [α]1,[β]2,[γ]2,[δ]2,uint64(len(Kvk)),[Kvk]1, with a
+// little-endian length
+func (vk *VerifyingKey) WriteArkworksTo(w io.Writer) (int64, error) {
+	if vk.NbCommitments != 0 {
+		return 0, errCompatCommitment
+	}
+	enc := curve.NewEncoder(w)
+	toEncode := []interface{}{&vk.G1.Alpha, &vk.G2.Beta, &vk.G2.Gamma, &vk.G2.Delta}
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+	var length [8]byte
+	binary.LittleEndian.PutUint64(length[:], uint64(len(vk.G1.K)))
+	n, err := w.Write(length[:])
+	if err != nil {
+		return enc.BytesWritten() + int64(n), err
+	}
+	for i := range vk.G1.K {
+		if err := enc.Encode(&vk.G1.K[i]); err != nil {
+			return enc.BytesWritten() + int64(n), err
+		}
+	}
+	return enc.BytesWritten() + int64(n), nil
+}
+
+// ReadArkworksFrom reads a verifying key written by WriteArkworksTo. As
+// arkworks doesn't store them, [β]1 and [δ]1 are left to zero.
+func (vk *VerifyingKey) ReadArkworksFrom(r io.Reader) (int64, error) {
+	dec := curve.NewDecoder(r)
+	toDecode := []interface{}{&vk.G1.Alpha, &vk.G2.Beta, &vk.G2.Gamma, &vk.G2.Delta}
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+	var length [8]byte
+	n, err := io.ReadFull(r, length[:])
+	if err != nil {
+		return dec.BytesRead() + int64(n), err
+	}
+	vk.G1.K = nil
+	for i := binary.LittleEndian.Uint64(length[:]); i > 0; i-- {
+		var p curve.G1Affine
+		if err := dec.Decode(&p); err != nil {
+			return dec.BytesRead() + int64(n), err
+		}
+		vk.G1.K = append(vk.G1.K, p)
+	}
+	vk.G1.Beta, vk.G1.Delta = curve.G1Affine{}, curve.G1Affine{}
+	vk.NbCommitments = 0
+
+	// recompute vk.e (e(α, β)) and  -[δ]2, -[γ]2
+	return dec.BytesRead() + int64(n), vk.Precompute()
+}
+
+// WriteBellmanTo writes the verifying key as bellman serializes it
+// (uncompressed): [α]1,[β]1,[β]2,[γ]2,[δ]1,[δ]2,uint32(len(Kvk)),[Kvk]1, with a
+// big-endian length
+//
+// It is the layout of WriteRawTo, without the commitment key.
+func (vk *VerifyingKey) WriteBellmanTo(w io.Writer) (int64, error) {
+	if vk.NbCommitments != 0 {
+		return 0, errCompatCommitment
+	}
+	enc := curve.NewEncoder(w, curve.RawEncoding())
+	toEncode := []interface{}{&vk.G1.Alpha, &vk.G1.Beta, &vk.G2.Beta, &vk.G2.Gamma, &vk.G1.Delta, &vk.G2.Delta, vk.G1.K}
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+	return enc.BytesWritten(), nil
+}
+
+// ReadBellmanFrom reads a verifying key written by WriteBellmanTo
+func (vk *VerifyingKey) ReadBellmanFrom(r io.Reader) (int64, error) {
+	dec := curve.NewDecoder(r)
+	toDecode := []interface{}{&vk.G1.Alpha, &vk.G1.Beta, &vk.G2.Beta, &vk.G2.Gamma, &vk.G1.Delta, &vk.G2.Delta, &vk.G1.K}
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+	vk.NbCommitments = 0
+
+	// recompute vk.e (e(α, β)) and  -[δ]2, -[γ]2
+	return dec.BytesRead(), vk.Precompute()
+}
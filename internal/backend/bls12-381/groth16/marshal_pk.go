@@ -0,0 +1,208 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package groth16
+
+import (
+	"errors"
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/backend"
+	gnarkio "github.com/consensys/gnark/io"
+	"io"
+)
+
+// WriteTo writes binary encoding of the key elements to writer
+// points are compressed
+// use WriteRawTo(...) to encode the key without point compression
+func (pk *ProvingKey) WriteTo(w io.Writer) (n int64, err error) {
+	return writeWithHeader(w, gnarkio.KindProvingKey, func(w io.Writer) (int64, error) {
+		return pk.writeTo(w, false)
+	})
+}
+
+// WriteRawTo writes binary encoding of the key elements to writer
+// points are not compressed
+// use WriteTo(...) to encode the key with point compression
+func (pk *ProvingKey) WriteRawTo(w io.Writer) (n int64, err error) {
+	return writeWithHeader(w, gnarkio.KindProvingKey, func(w io.Writer) (int64, error) {
+		return pk.writeTo(w, true)
+	})
+}
+
+func (pk *ProvingKey) writeTo(w io.Writer, raw bool) (int64, error) {
+	n, err := pk.Domain.WriteTo(w)
+	if err != nil {
+		return n, err
+	}
+
+	var enc *curve.Encoder
+	if raw {
+		enc = curve.NewEncoder(w, curve.RawEncoding())
+	} else {
+		enc = curve.NewEncoder(w)
+	}
+	nbWires := uint64(len(pk.InfinityA))
+
+	toEncode := []interface{}{
+		&pk.G1.Alpha,
+		&pk.G1.Beta,
+		&pk.G1.Delta,
+		pk.G1.A,
+		pk.G1.B,
+		pk.G1.Z,
+		pk.G1.K,
+		&pk.G2.Beta,
+		&pk.G2.Delta,
+		pk.G2.B,
+		nbWires,
+		pk.NbInfinityA,
+		pk.NbInfinityB,
+		pk.InfinityA,
+		pk.InfinityB,
+		pk.CommitmentKey.Basis,
+		pk.CommitmentKey.BasisExpSigma,
+		&pk.CommitmentKey.BlindingDelta,
+		pk.PrecomputedZ.Level,
+	}
+	if pk.PrecomputedZ.Level != 0 {
+		toEncode = append(toEncode, pk.PrecomputedZ.Points, pk.PrecomputedK.Points)
+	}
+
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return n + enc.BytesWritten(), err
+		}
+	}
+
+	return n + enc.BytesWritten(), nil
+
+}
+
+// ReadFrom attempts to decode a ProvingKey from reader
+// ProvingKey must be encoded through WriteTo (compressed) or WriteRawTo (uncompressed)
+// note that we don't check that the points are on the curve or in the correct subgroup at this point
+func (pk *ProvingKey) ReadFrom(r io.Reader) (int64, error) {
+	return pk.readWithHeader(r)
+}
+
+// UnsafeReadFrom behaves like ReadFrom excepts it doesn't check if the decoded points are on the curve
+// or in the correct subgroup
+func (pk *ProvingKey) UnsafeReadFrom(r io.Reader) (int64, error) {
+	return pk.readWithHeader(r, curve.NoSubgroupChecks())
+}
+
+// readWithHeader reads and checks the header of a ProvingKey, then the
+// ProvingKey in the encoding of the version read
+func (pk *ProvingKey) readWithHeader(r io.Reader, decOptions ...func(*curve.Decoder)) (int64, error) {
+	r, version, n, err := gnarkio.ReadHeader(r, ecc.BLS12_381, backend.GROTH16, gnarkio.KindProvingKey)
+	if err != nil {
+		return n, err
+	}
+	m, err := pk.readFrom(r, version, decOptions...)
+	return n + m, err
+}
+
+func (pk *ProvingKey) readFrom(r io.Reader, version uint8, decOptions ...func(*curve.Decoder)) (int64, error) {
+	n, err := pk.Domain.ReadFrom(r)
+	if err != nil {
+		return n, err
+	}
+
+	dec := curve.NewDecoder(r, decOptions...)
+
+	var nbWires uint64
+
+	toDecode := []interface{}{
+		&pk.G1.Alpha,
+		&pk.G1.Beta,
+		&pk.G1.Delta,
+		&pk.G1.A,
+		&pk.G1.B,
+		&pk.G1.Z,
+		&pk.G1.K,
+		&pk.G2.Beta,
+		&pk.G2.Delta,
+		&pk.G2.B,
+		&nbWires,
+		&pk.NbInfinityA,
+		&pk.NbInfinityB,
+	}
+
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return n + dec.BytesRead(), err
+		}
+	}
+	pk.InfinityA = make([]bool, nbWires)
+	pk.InfinityB = make([]bool, nbWires)
+
+	if err := dec.Decode(&pk.InfinityA); err != nil {
+		return n + dec.BytesRead(), err
+	}
+	if err := dec.Decode(&pk.InfinityB); err != nil {
+		return n + dec.BytesRead(), err
+	}
+
+	// the keys written before the headers end here, without commitment key nor
+	// precomputed tables
+	pk.PrecomputedZ, pk.PrecomputedK = FixedBase{}, FixedBase{}
+	if version == 0 {
+		pk.CommitmentKey = CommitmentKey{}
+		return n + dec.BytesRead(), nil
+	}
+	if err := dec.Decode(&pk.CommitmentKey.Basis); err != nil {
+		return n + dec.BytesRead(), err
+	}
+	if err := dec.Decode(&pk.CommitmentKey.BasisExpSigma); err != nil {
+		return n + dec.BytesRead(), err
+	}
+	if err := dec.Decode(&pk.CommitmentKey.BlindingDelta); err != nil {
+		return n + dec.BytesRead(), err
+	}
+	if len(pk.CommitmentKey.Basis) == 0 {
+		pk.CommitmentKey = CommitmentKey{}
+	}
+
+	// uint64(level),[PrecomputedZ]1,[PrecomputedK]1 if level != 0
+	if version < gnarkio.VersionPrecomputedTables {
+		return n + dec.BytesRead(), nil
+	}
+	var level uint64
+	if err := dec.Decode(&level); err != nil {
+		return n + dec.BytesRead(), err
+	}
+	if level != 0 {
+		if level > maxPrecomputeLevel {
+			return n + dec.BytesRead(), errors.New("invalid precomputation level")
+		}
+		pk.PrecomputedZ.Level, pk.PrecomputedK.Level = level, level
+		if err := dec.Decode(&pk.PrecomputedZ.Points); err != nil {
+			return n + dec.BytesRead(), err
+		}
+		if err := dec.Decode(&pk.PrecomputedK.Points); err != nil {
+			return n + dec.BytesRead(), err
+		}
+		if len(pk.PrecomputedZ.Points) != int(level)*len(pk.G1.Z) || len(pk.PrecomputedK.Points) != int(level)*len(pk.G1.K) {
+			return n + dec.BytesRead(), errors.New("invalid precomputed tables")
+		}
+	}
+
+	return n + dec.BytesRead(), nil
+}
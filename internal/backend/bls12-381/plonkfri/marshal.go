@@ -0,0 +1,266 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package plonkfri
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/internal/merkle"
+	gnarkio "github.com/consensys/gnark/io"
+)
+
+// maxLength bounds the lengths of the slices read, so that a corrupted encoding
+// can't allocate unbounded memory
+const maxLength = 1 << 24
+
+var errInvalidLength = errors.New("invalid length in encoding")
+
+// WriteTo writes binary encoding of Proof to w
+func (proof *Proof) WriteTo(w io.Writer) (int64, error) {
+	return writeWithHeader(w, gnarkio.KindProof, proof.writeTo)
+}
+
+func (proof *Proof) writeTo(w io.Writer) (int64, error) {
+	enc := encoder{w: w}
+	enc.digests(proof.LRO, proof.Z, proof.H)
+	enc.elements(proof.Evaluations[:]...)
+	enc.elements(proof.ZShifted)
+	enc.length(len(proof.Layers))
+	enc.digests(proof.Layers...)
+	enc.elements(proof.FinalValue)
+	enc.length(len(proof.Queries))
+	for i := range proof.Queries {
+		for j := range proof.Queries[i].Polynomials {
+			enc.opening(&proof.Queries[i].Polynomials[j])
+		}
+		enc.length(len(proof.Queries[i].Layers))
+		for j := range proof.Queries[i].Layers {
+			enc.opening(&proof.Queries[i].Layers[j])
+		}
+	}
+	return enc.n, enc.err
+}
+
+// ReadFrom reads binary representation of Proof from r
+func (proof *Proof) ReadFrom(r io.Reader) (int64, error) {
+	return readWithHeader(r, gnarkio.KindProof, proof.readFrom)
+}
+
+func (proof *Proof) readFrom(r io.Reader) (int64, error) {
+	dec := decoder{r: r}
+	dec.digests(&proof.LRO, &proof.Z, &proof.H)
+	for i := range proof.Evaluations {
+		dec.elements(&proof.Evaluations[i])
+	}
+	dec.elements(&proof.ZShifted)
+	proof.Layers = make([]merkle.Digest, dec.length())
+	for i := range proof.Layers {
+		dec.digests(&proof.Layers[i])
+	}
+	dec.elements(&proof.FinalValue)
+	proof.Queries = make([]Query, dec.length())
+	for i := range proof.Queries {
+		for j := range proof.Queries[i].Polynomials {
+			dec.opening(&proof.Queries[i].Polynomials[j])
+		}
+		proof.Queries[i].Layers = make([]Opening, dec.length())
+		for j := range proof.Queries[i].Layers {
+			dec.opening(&proof.Queries[i].Layers[j])
+		}
+	}
+	return dec.n, dec.err
+}
+
+// WriteTo writes binary encoding of VerifyingKey to w
+func (vk *VerifyingKey) WriteTo(w io.Writer) (int64, error) {
+	return writeWithHeader(w, gnarkio.KindVerifyingKey, vk.writeTo)
+}
+
+func (vk *VerifyingKey) writeTo(w io.Writer) (int64, error) {
+	enc := encoder{w: w}
+	enc.uint64(vk.Size)
+	enc.elements(vk.SizeInv, vk.Generator)
+	enc.uint64(vk.NbPublicVariables)
+	enc.elements(vk.CosetShift, vk.GeneratorFRI)
+	enc.digests(vk.Qpp)
+	enc.write(vk.CircuitFingerprint[:])
+	return enc.n, enc.err
+}
+
+// ReadFrom reads from binary representation in r into VerifyingKey
+func (vk *VerifyingKey) ReadFrom(r io.Reader) (int64, error) {
+	r, version, n, err := gnarkio.ReadHeader(r, ecc.BLS12_381, backend.PLONKFRI, gnarkio.KindVerifyingKey)
+	if err != nil {
+		return n, err
+	}
+	m, err := vk.readFrom(r, version)
+	return n + m, err
+}
+
+// readFrom reads the encoding of the given version of VerifyingKey from r
+func (vk *VerifyingKey) readFrom(r io.Reader, version uint8) (int64, error) {
+	dec := decoder{r: r}
+	vk.Size = dec.uint64()
+	dec.elements(&vk.SizeInv, &vk.Generator)
+	vk.NbPublicVariables = dec.uint64()
+	dec.elements(&vk.CosetShift, &vk.GeneratorFRI)
+	dec.digests(&vk.Qpp)
+
+	// circuit fingerprint
+	vk.CircuitFingerprint = [32]byte{}
+	if version >= gnarkio.VersionCircuitFingerprint {
+		dec.read(vk.CircuitFingerprint[:])
+	}
+	if dec.err == nil && (vk.Size == 0 || vk.Size&(vk.Size-1) != 0) {
+		dec.err = errors.New("the size of the circuit must be a power of 2")
+	}
+	return dec.n, dec.err
+}
+
+// encoder writes field elements, digests and integers in big-endian, and keeps
+// the first error
+type encoder struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (enc *encoder) write(b []byte) {
+	if enc.err != nil {
+		return
+	}
+	n, err := enc.w.Write(b)
+	enc.n += int64(n)
+	enc.err = err
+}
+
+func (enc *encoder) uint64(v uint64) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	enc.write(buf[:])
+}
+
+func (enc *encoder) length(l int) {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], uint32(l))
+	enc.write(buf[:])
+}
+
+func (enc *encoder) elements(v ...fr.Element) {
+	enc.write(leafBytes(v))
+}
+
+func (enc *encoder) digests(v ...merkle.Digest) {
+	for i := range v {
+		enc.write(v[i][:])
+	}
+}
+
+func (enc *encoder) opening(o *Opening) {
+	enc.length(len(o.Values))
+	enc.elements(o.Values...)
+	enc.length(len(o.Path))
+	enc.digests(o.Path...)
+}
+
+// decoder reads what encoder writes, and keeps the first error
+type decoder struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+func (dec *decoder) read(b []byte) {
+	if dec.err != nil {
+		return
+	}
+	n, err := io.ReadFull(dec.r, b)
+	dec.n += int64(n)
+	dec.err = err
+}
+
+func (dec *decoder) uint64() uint64 {
+	var buf [8]byte
+	dec.read(buf[:])
+	return binary.BigEndian.Uint64(buf[:])
+}
+
+func (dec *decoder) length() int {
+	var buf [4]byte
+	dec.read(buf[:])
+	l := binary.BigEndian.Uint32(buf[:])
+	if dec.err == nil && l > maxLength {
+		dec.err = errInvalidLength
+	}
+	if dec.err != nil {
+		return 0
+	}
+	return int(l)
+}
+
+func (dec *decoder) elements(v ...*fr.Element) {
+	var buf [fr.Bytes]byte
+	for _, e := range v {
+		dec.read(buf[:])
+		e.SetBytes(buf[:])
+	}
+}
+
+func (dec *decoder) digests(v ...*merkle.Digest) {
+	for _, d := range v {
+		dec.read(d[:])
+	}
+}
+
+func (dec *decoder) opening(o *Opening) {
+	o.Values = make([]fr.Element, dec.length())
+	for i := range o.Values {
+		dec.elements(&o.Values[i])
+	}
+	o.Path = make([]merkle.Digest, dec.length())
+	for i := range o.Path {
+		dec.digests(&o.Path[i])
+	}
+}
+
+// writeWithHeader writes the header of an object of the given kind (see
+// gnarkio.WriteHeader), then the object with writeTo
+func writeWithHeader(w io.Writer, kind gnarkio.Kind, writeTo func(io.Writer) (int64, error)) (int64, error) {
+	n, err := gnarkio.WriteHeader(w, ecc.BLS12_381, backend.PLONKFRI, kind)
+	if err != nil {
+		return n, err
+	}
+	m, err := writeTo(w)
+	return n + m, err
+}
+
+// readWithHeader reads and checks the header of an object of the given kind
+// (see gnarkio.ReadHeader), then the object with readFrom
+func readWithHeader(r io.Reader, kind gnarkio.Kind, readFrom func(io.Reader) (int64, error)) (int64, error) {
+	r, _, n, err := gnarkio.ReadHeader(r, ecc.BLS12_381, backend.PLONKFRI, kind)
+	if err != nil {
+		return n, err
+	}
+	m, err := readFrom(r)
+	return n + m, err
+}
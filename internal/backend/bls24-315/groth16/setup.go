@@ -1,3 +1,6 @@
+//go:build !verifieronly
+// +build !verifieronly
+
 // Copyright 2020 ConsenSys Software Inc.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
@@ -25,11 +28,19 @@ import (
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr/fft"
+	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/frontend/compiled"
+	"github.com/consensys/gnark/internal/utils"
+	"github.com/consensys/gnark/metrics"
 	"math/big"
 	"math/bits"
+	"sync"
 )
 
+// setupChunkSize is the number of constraints, or of scalars, Setup processes at once.
+// It bounds the memory Setup uses on top of the keys it returns.
+const setupChunkSize = 1 << 20
+
 // ProvingKey is used by a Groth16 prover to encode a proof of a statement
 // Notation follows Figure 4. in DIZK paper https://eprint.iacr.org/2018/691.pdf
 type ProvingKey struct {
@@ -53,31 +64,34 @@ type ProvingKey struct {
 	// if InfinityA[i] == true, the point G1.A[i] == infinity
 	InfinityA, InfinityB     []bool
 	NbInfinityA, NbInfinityB uint64
-}
 
-// VerifyingKey is used by a Groth16 verifier to verify the validity of a proof and a statement
-// Notation follows Figure 4. in DIZK paper https://eprint.iacr.org/2018/691.pdf
-type VerifyingKey struct {
-	// [α]1, [Kvk]1
-	G1 struct {
-		Alpha       curve.G1Affine
-		Beta, Delta curve.G1Affine   // unused, here for compatibility purposes
-		K           []curve.G1Affine // The indexes correspond to the public wires
-	}
+	// commitment to the committed wires, if any (see frontend.API.Commit)
+	CommitmentKey CommitmentKey
 
-	// [β]2, [δ]2, [γ]2,
-	// -[δ]2, -[γ]2: see proof.Verify() for more details
-	G2 struct {
-		Beta, Delta, Gamma curve.G2Affine
-		deltaNeg, gammaNeg curve.G2Affine // not serialized
-	}
+	// multiples of G1.Z and G1.K with which Prove computes its MSMs faster, if
+	// Precompute was called
+	PrecomputedZ, PrecomputedK FixedBase
+
+	// sections of the encoding of the key which are read when needed, if it was read
+	// by ReadSectionsFrom without loading all of them
+	sections *sections
+
+	// tables of the FFTs of the quotient h, computed by the first proof
+	quotient *quotientTables
+}
 
-	// e(α, β)
-	e curve.GT // not serialized
+// CommitmentKey is used by a Groth16 prover to commit to some of the private wires.
+// Basis[i] and BasisExpSigma[i] correspond to the i-th committed wire; their last
+// element is the generator [η/γ]1 of the blinding of the commitment, which the
+// prover compensates in Krs with BlindingDelta = [η/δ]1.
+type CommitmentKey struct {
+	Basis         []curve.G1Affine
+	BasisExpSigma []curve.G1Affine
+	BlindingDelta curve.G1Affine
 }
 
 // Setup constructs the SRS
-func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
+func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) (err error) {
 	/*
 		Setup
 		-----
@@ -91,7 +105,11 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	// get R1CS nb constraints, wires and public/private inputs
 	nbWires := r1cs.NbInternalVariables + r1cs.NbPublicVariables + r1cs.NbSecretVariables
 	nbPublicWires := int(r1cs.NbPublicVariables)
-	nbPrivateWires := r1cs.NbSecretVariables + r1cs.NbInternalVariables
+
+	m := metrics.Start(metrics.PhaseSetup, r1cs.CurveID(), backend.GROTH16)
+	defer func() {
+		m.Stop(metrics.Event{Err: err, NbConstraints: len(r1cs.Constraints), NbWires: nbWires})
+	}()
 
 	// Setting group for fft
 	domain := fft.NewDomain(uint64(len(r1cs.Constraints)))
@@ -109,67 +127,89 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	// and convert the resulting points to affine
 	// this is done using the curve.BatchScalarMultiplicationGX API, which takes as input the base point
 	// (in our case the generator) and the list of scalars, and outputs a list of points (len(points) == len(scalars))
-	// to use this batch call, we need to order our scalars in the same slice
-	// we have 1 batch call for G1 and 1 batch call for G1
+	// the scalars are multiplied by chunks of setupChunkSize, so that the intermediate results
+	// of the batch calls don't need memory proportional to the size of the circuit
 	// scalars are fr.Element in non montgomery form
 	_, _, g1, g2 := curve.Generators()
+	one := fr.One()
 
 	// ---------------------------------------------------------------------------------------------
 	// G1 scalars
 
-	// the G1 scalars are ordered (arbitrary) as follow:
-	//
-	// [[α], [β], [δ], [A(i)], [B(i)], [pk.K(i)], [Z(i)], [vk.K(i)]]
 	// len(A) == len(B) == nbWires
-	// len(pk.K) == nbPrivateWires
-	// len(vk.K) == nbPublicWires
+	// len(pk.K) == nbPrivateWires (minus the commitment wires)
+	// len(vk.K) == nbPublicWires (+1 with a commitment)
 	// len(Z) == domain.Cardinality
+	// with a commitment, [ck.Basis(i)], [σ.ck.Basis(i)], [η/δ]
+	// the last element of ck.Basis being the blinding generator [η/γ]
+
+	// if the circuit commits to some of its wires, the committed wires are not in pk.K:
+	// they are in the commitment key, and the commitment wire is in vk.K
+	commitment := &r1cs.Commitment
+	nbCommitmentWires := 0
+	if commitment.Is() {
+		nbCommitmentWires = len(commitment.Committed) + 1
+	}
+
+	// compute scalars for pkK, vkK and the commitment key:
+	// C[i] is overwritten with (β⋅A(i) + α⋅B(i) + C(i)) / δ for the private wires,
+	// and with the same numerator over γ for the public and committed ones
+	utils.Parallelize(nbWires, func(start, end int) {
+		var t0 fr.Element
+		for i := start; i < end; i++ {
+			t0.Mul(&B[i], &toxicWaste.alpha)
+			C[i].Add(&C[i], &t0)
+			t0.Mul(&A[i], &toxicWaste.beta)
+			C[i].Add(&C[i], &t0)
+			if i < nbPublicWires || commitment.IsCommitted(i) {
+				C[i].Mul(&C[i], &toxicWaste.gammaInv)
+			} else {
+				C[i].Mul(&C[i], &toxicWaste.deltaInv)
+			}
+		}
+	})
 
-	// compute scalars for pkK and vkK
-	pkK := make([]fr.Element, nbPrivateWires)
-	vkK := make([]fr.Element, nbPublicWires)
-
-	var t0, t1 fr.Element
-
+	vkK := make([]fr.Element, nbPublicWires, nbPublicWires+1)
 	for i := 0; i < nbPublicWires; i++ {
-		t1.Mul(&A[i], &toxicWaste.beta)
-		t0.Mul(&B[i], &toxicWaste.alpha)
-		t1.Add(&t1, &t0).
-			Add(&t1, &C[i]).
-			Mul(&t1, &toxicWaste.gammaInv)
-		vkK[i] = t1.ToRegular()
+		vkK[i] = C[i].ToRegular()
 	}
 
-	for i := 0; i < nbPrivateWires; i++ {
-		t1.Mul(&A[i+nbPublicWires], &toxicWaste.beta)
-		t0.Mul(&B[i+nbPublicWires], &toxicWaste.alpha)
-		t1.Add(&t1, &t0).
-			Add(&t1, &C[i+nbPublicWires]).
-			Mul(&t1, &toxicWaste.deltaInv)
-		pkK[i] = t1.ToRegular()
+	// pkK is compacted in place in C, which is not needed afterwards
+	pkK := C[:0]
+	ckK := make([]fr.Element, 0, nbCommitmentWires)
+	var vkKCommitment fr.Element
+	for i := nbPublicWires; i < nbWires; i++ {
+		if !commitment.IsCommitted(i) {
+			pkK = append(pkK, C[i].ToRegular())
+			continue
+		}
+		if i == commitment.Wire {
+			vkKCommitment = C[i].ToRegular()
+		} else {
+			ckK = append(ckK, C[i])
+		}
 	}
-
-	// convert A and B to regular form
-	for i := 0; i < int(nbWires); i++ {
-		A[i].FromMont()
+	var blindingDelta fr.Element
+	if commitment.Is() {
+		vkK = append(vkK, vkKCommitment)
+		var blinding fr.Element
+		blinding.Mul(&toxicWaste.eta, &toxicWaste.gammaInv)
+		ckK = append(ckK, blinding)
+		blindingDelta.Mul(&toxicWaste.eta, &toxicWaste.deltaInv).FromMont()
 	}
-	for i := 0; i < int(nbWires); i++ {
-		B[i].FromMont()
+	ckKSigma := make([]fr.Element, len(ckK))
+	for i := 0; i < len(ckK); i++ {
+		ckKSigma[i].Mul(&ckK[i], &toxicWaste.sigma).FromMont()
+		ckK[i].FromMont()
 	}
 
-	// Z part of the proving key (scalars)
-	Z := make([]fr.Element, domain.Cardinality)
-	one := fr.One()
-	var zdt fr.Element
-
-	zdt.Exp(toxicWaste.t, new(big.Int).SetUint64(domain.Cardinality)).
-		Sub(&zdt, &one).
-		Mul(&zdt, &toxicWaste.deltaInv) // sets Zdt to Zdt/delta
-
-	for i := 0; i < int(domain.Cardinality); i++ {
-		Z[i] = zdt.ToRegular()
-		zdt.Mul(&zdt, &toxicWaste.t)
-	}
+	// convert A and B to regular form
+	utils.Parallelize(nbWires, func(start, end int) {
+		for i := start; i < end; i++ {
+			A[i].FromMont()
+			B[i].FromMont()
+		}
+	})
 
 	// mark points at infinity and filter them
 	pk.InfinityA = make([]bool, len(A))
@@ -198,63 +238,73 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	B = B[:n]
 	pk.NbInfinityB = uint64(nbWires - n)
 
-	// compute our batch scalar multiplication with g1 elements
-	g1Scalars := make([]fr.Element, 0, (nbWires*3)+int(domain.Cardinality)+3)
-	g1Scalars = append(g1Scalars, toxicWaste.alphaReg, toxicWaste.betaReg, toxicWaste.deltaReg)
-	g1Scalars = append(g1Scalars, A...)
-	g1Scalars = append(g1Scalars, B...)
-	g1Scalars = append(g1Scalars, pkK...)
-	g1Scalars = append(g1Scalars, Z...)
-	g1Scalars = append(g1Scalars, vkK...)
-
-	g1PointsAff := curve.BatchScalarMultiplicationG1(&g1, g1Scalars)
-
 	// sets pk: [α]1, [β]1, [δ]1
+	g1PointsAff := curve.BatchScalarMultiplicationG1(&g1, []fr.Element{toxicWaste.alphaReg, toxicWaste.betaReg, toxicWaste.deltaReg})
 	pk.G1.Alpha = g1PointsAff[0]
 	pk.G1.Beta = g1PointsAff[1]
 	pk.G1.Delta = g1PointsAff[2]
 
-	offset := 3
-	pk.G1.A = g1PointsAff[offset : offset+len(A)]
-	offset += len(A)
-
-	pk.G1.B = g1PointsAff[offset : offset+len(B)]
-	offset += len(B)
-
-	pk.G1.K = g1PointsAff[offset : offset+nbPrivateWires]
-	offset += nbPrivateWires
-
-	pk.G1.Z = g1PointsAff[offset : offset+int(domain.Cardinality)]
+	pk.G1.A = batchScalarMultiplicationG1(&g1, A)
+	pk.G1.B = batchScalarMultiplicationG1(&g1, B)
+	pk.G1.K = batchScalarMultiplicationG1(&g1, pkK)
+	vk.G1.K = batchScalarMultiplicationG1(&g1, vkK)
+
+	pk.CommitmentKey = CommitmentKey{}
+	vk.NbCommitments = 0
+	vk.CommitmentKey.Basis = nil
+	if commitment.Is() {
+		pk.CommitmentKey.Basis = batchScalarMultiplicationG1(&g1, ckK)
+		pk.CommitmentKey.BasisExpSigma = batchScalarMultiplicationG1(&g1, ckKSigma)
+		g1PointsAff = curve.BatchScalarMultiplicationG1(&g1, []fr.Element{blindingDelta})
+		pk.CommitmentKey.BlindingDelta = g1PointsAff[0]
+		vk.NbCommitments = 1
+		vk.CommitmentKey.Basis = pk.CommitmentKey.Basis
+	}
+	vk.CircuitFingerprint = r1cs.Fingerprint()
+
+	// Z part of the proving key: [tⁱ⋅(tⁿ-1)/δ]1, whose scalars are computed chunk by chunk
+	pk.G1.Z = make([]curve.G1Affine, domain.Cardinality)
+	var zdt fr.Element
+	zdt.Exp(toxicWaste.t, new(big.Int).SetUint64(domain.Cardinality)).
+		Sub(&zdt, &one).
+		Mul(&zdt, &toxicWaste.deltaInv) // sets Zdt to Zdt/delta
+	Z := make([]fr.Element, min(setupChunkSize, len(pk.G1.Z)))
+	for chunkStart := 0; chunkStart < len(pk.G1.Z); chunkStart += len(Z) {
+		chunk := Z[:min(len(Z), len(pk.G1.Z)-chunkStart)]
+		utils.Parallelize(len(chunk), func(start, end int) {
+			var z fr.Element
+			z.Exp(toxicWaste.t, big.NewInt(int64(chunkStart+start))).
+				Mul(&z, &zdt)
+			for i := start; i < end; i++ {
+				chunk[i] = z.ToRegular()
+				z.Mul(&z, &toxicWaste.t)
+			}
+		})
+		copy(pk.G1.Z[chunkStart:], curve.BatchScalarMultiplicationG1(&g1, chunk))
+	}
 	bitReverse(pk.G1.Z)
 
-	offset += int(domain.Cardinality)
-
-	vk.G1.K = g1PointsAff[offset:]
-
 	// ---------------------------------------------------------------------------------------------
 	// G2 scalars
 
-	// the G2 scalars are ordered as follow:
-	//
-	// [[B(i)], [β], [δ], [γ]]
-	// len(B) == nbWires
+	pk.G2.B = batchScalarMultiplicationG2(&g2, B)
 
-	// compute our batch scalar multiplication with g2 elements
-	g2Scalars := append(B, toxicWaste.betaReg, toxicWaste.deltaReg, toxicWaste.gammaReg)
-
-	g2PointsAff := curve.BatchScalarMultiplicationG2(&g2, g2Scalars)
-
-	pk.G2.B = g2PointsAff[:len(B)]
+	// [β]2, [δ]2, [γ]2, [1/σ]2
+	g2PointsAff := curve.BatchScalarMultiplicationG2(&g2, []fr.Element{toxicWaste.betaReg, toxicWaste.deltaReg, toxicWaste.gammaReg, toxicWaste.sigmaInvReg})
 
 	// sets pk: [β]2, [δ]2
-	pk.G2.Beta = g2PointsAff[len(B)+0]
-	pk.G2.Delta = g2PointsAff[len(B)+1]
+	pk.G2.Beta = g2PointsAff[0]
+	pk.G2.Delta = g2PointsAff[1]
 
 	// sets vk: [δ]2, [γ]2, -[δ]2, -[γ]2
-	vk.G2.Delta = g2PointsAff[len(B)+1]
-	vk.G2.Gamma = g2PointsAff[len(B)+2]
-	vk.G2.deltaNeg.Neg(&vk.G2.Delta)
-	vk.G2.gammaNeg.Neg(&vk.G2.Gamma)
+	vk.G2.Delta = g2PointsAff[1]
+	vk.G2.Gamma = g2PointsAff[2]
+
+	// sets vk: [1]2, -[1/σ]2
+	if commitment.Is() {
+		vk.CommitmentKey.G = g2
+		vk.CommitmentKey.GRootSigmaNeg.Neg(&g2PointsAff[3])
+	}
 
 	// ---------------------------------------------------------------------------------------------
 	// Pairing: vk.e
@@ -265,8 +315,7 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	vk.G1.Beta = pk.G1.Beta
 	vk.G1.Delta = pk.G1.Delta
 
-	vk.e, err = curve.Pair([]curve.G1Affine{pk.G1.Alpha}, []curve.G2Affine{pk.G2.Beta})
-	if err != nil {
+	if err := vk.Precompute(); err != nil {
 		return err
 	}
 	// set domain
@@ -275,6 +324,11 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	return nil
 }
 
+// setupABC returns the evaluations at t of the polynomials A, B and C of the wires.
+//
+// The constraints are processed by chunks of setupChunkSize: the evaluations of the
+// Lagrange polynomials of a chunk are computed in parallel, then accumulated in A,
+// B and C by one goroutine each.
 func setupABC(r1cs *cs.R1CS, domain *fft.Domain, toxicWaste toxicWaste) (A []fr.Element, B []fr.Element, C []fr.Element) {
 
 	nbWires := r1cs.NbInternalVariables + r1cs.NbPublicVariables + r1cs.NbSecretVariables
@@ -285,27 +339,12 @@ func setupABC(r1cs *cs.R1CS, domain *fft.Domain, toxicWaste toxicWaste) (A []fr.
 
 	one := fr.One()
 
-	// first we compute [t-w^i] and its inverse
-	var w fr.Element
-	w.Set(&domain.Generator)
-	wi := fr.One()
-	t := make([]fr.Element, len(r1cs.Constraints)+1)
-	for i := 0; i < len(t); i++ {
-		t[i].Sub(&toxicWaste.t, &wi)
-		wi.Mul(&wi, &w) // TODO this is already pre computed in fft.Domain
-	}
-	tInv := fr.BatchInvert(t)
-
 	// evaluation of the i-th lagrange polynomial at t
-	var L fr.Element
-
-	// L = 1/n*(t^n-1)/(t-1), Li+1 = w*Li*(t-w^i)/(t-w^(i+1))
-
-	// Setting L0
-	L.Exp(toxicWaste.t, new(big.Int).SetUint64(uint64(domain.Cardinality))).
-		Sub(&L, &one)
-	L.Mul(&L, &tInv[0]).
-		Mul(&L, &domain.CardinalityInv)
+	// Lᵢ = ωⁱ/n⋅(tⁿ-1)/(t-ωⁱ)
+	var tn fr.Element
+	tn.Exp(toxicWaste.t, new(big.Int).SetUint64(uint64(domain.Cardinality))).
+		Sub(&tn, &one).
+		Mul(&tn, &domain.CardinalityInv)
 
 	accumulate := func(res *fr.Element, t compiled.Term, value *fr.Element) {
 		cID := t.CoeffID()
@@ -327,42 +366,99 @@ func setupABC(r1cs *cs.R1CS, domain *fft.Domain, toxicWaste toxicWaste) (A []fr.
 		}
 	}
 
-	// each constraint is in the form
-	// L * R == O
-	// L, R and O being linear expressions
-	// for each term appearing in the linear expression,
-	// we compute term.Coefficient * L, and cumulate it in
-	// A, B or C at the indice of the variable
-	for i, c := range r1cs.Constraints {
-
-		for _, t := range c.L {
-			accumulate(&A[t.WireID()], t, &L)
-		}
-		for _, t := range c.R {
-			accumulate(&B[t.WireID()], t, &L)
-		}
-		for _, t := range c.O {
-			accumulate(&C[t.WireID()], t, &L)
-		}
+	nbConstraints := len(r1cs.Constraints)
+	L := make([]fr.Element, min(setupChunkSize, nbConstraints))
+	for chunkStart := 0; chunkStart < nbConstraints; chunkStart += len(L) {
+		constraints := r1cs.Constraints[chunkStart:min(chunkStart+len(L), nbConstraints)]
+		L := L[:len(constraints)]
+
+		utils.Parallelize(len(L), func(start, end int) {
+			var wi fr.Element
+			wi.Exp(domain.Generator, big.NewInt(int64(chunkStart+start)))
+
+			// t-ωⁱ, and its inverse
+			den := make([]fr.Element, end-start)
+			w := wi
+			for i := range den {
+				den[i].Sub(&toxicWaste.t, &w)
+				w.Mul(&w, &domain.Generator)
+			}
+			den = fr.BatchInvert(den)
 
-		// Li+1 = w*Li*(t-w^i)/(t-w^(i+1))
-		L.Mul(&L, &w)
-		L.Mul(&L, &t[i])
-		L.Mul(&L, &tInv[i+1])
+			for i := start; i < end; i++ {
+				L[i].Mul(&tn, &wi).
+					Mul(&L[i], &den[i-start])
+				wi.Mul(&wi, &domain.Generator)
+			}
+		})
+
+		// each constraint is in the form
+		// L * R == O
+		// L, R and O being linear expressions
+		// for each term appearing in the linear expression,
+		// we compute term.Coefficient * L, and cumulate it in
+		// A, B or C at the indice of the variable
+		var wg sync.WaitGroup
+		wg.Add(3)
+		go func() {
+			for i, c := range constraints {
+				for _, t := range c.L {
+					accumulate(&A[t.WireID()], t, &L[i])
+				}
+			}
+			wg.Done()
+		}()
+		go func() {
+			for i, c := range constraints {
+				for _, t := range c.R {
+					accumulate(&B[t.WireID()], t, &L[i])
+				}
+			}
+			wg.Done()
+		}()
+		go func() {
+			for i, c := range constraints {
+				for _, t := range c.O {
+					accumulate(&C[t.WireID()], t, &L[i])
+				}
+			}
+			wg.Done()
+		}()
+		wg.Wait()
 	}
 	return
 
 }
 
+// batchScalarMultiplicationG1 returns [scalars[i]]base, computed by chunks of setupChunkSize
+func batchScalarMultiplicationG1(base *curve.G1Affine, scalars []fr.Element) []curve.G1Affine {
+	res := make([]curve.G1Affine, len(scalars))
+	for start := 0; start < len(scalars); start += setupChunkSize {
+		end := min(start+setupChunkSize, len(scalars))
+		copy(res[start:end], curve.BatchScalarMultiplicationG1(base, scalars[start:end]))
+	}
+	return res
+}
+
+// batchScalarMultiplicationG2 returns [scalars[i]]base, computed by chunks of setupChunkSize
+func batchScalarMultiplicationG2(base *curve.G2Affine, scalars []fr.Element) []curve.G2Affine {
+	res := make([]curve.G2Affine, len(scalars))
+	for start := 0; start < len(scalars); start += setupChunkSize {
+		end := min(start+setupChunkSize, len(scalars))
+		copy(res[start:end], curve.BatchScalarMultiplicationG2(base, scalars[start:end]))
+	}
+	return res
+}
+
 // toxicWaste toxic waste
 type toxicWaste struct {
 
 	// Montgomery form of params
-	t, alpha, beta, gamma, delta fr.Element
-	gammaInv, deltaInv           fr.Element
+	t, alpha, beta, gamma, delta, sigma, eta fr.Element
+	gammaInv, deltaInv, sigmaInv             fr.Element
 
 	// Non Montgomery form of params
-	alphaReg, betaReg, gammaReg, deltaReg fr.Element
+	alphaReg, betaReg, gammaReg, deltaReg, sigmaInvReg fr.Element
 }
 
 func sampleToxicWaste() (toxicWaste, error) {
@@ -395,13 +491,26 @@ func sampleToxicWaste() (toxicWaste, error) {
 		}
 	}
 
+	for res.sigma.IsZero() {
+		if _, err := res.sigma.SetRandom(); err != nil {
+			return res, err
+		}
+	}
+	for res.eta.IsZero() {
+		if _, err := res.eta.SetRandom(); err != nil {
+			return res, err
+		}
+	}
+
 	res.gammaInv.Inverse(&res.gamma)
 	res.deltaInv.Inverse(&res.delta)
+	res.sigmaInv.Inverse(&res.sigma)
 
 	res.alphaReg = res.alpha.ToRegular()
 	res.betaReg = res.beta.ToRegular()
 	res.gammaReg = res.gamma.ToRegular()
 	res.deltaReg = res.delta.ToRegular()
+	res.sigmaInvReg = res.sigmaInv.ToRegular()
 
 	return res, nil
 }
@@ -423,7 +532,15 @@ func DummySetup(r1cs *cs.R1CS, pk *ProvingKey) error {
 	// initialize proving key
 	pk.G1.A = make([]curve.G1Affine, nbWires-nbZeroesA)
 	pk.G1.B = make([]curve.G1Affine, nbWires-nbZeroesB)
-	pk.G1.K = make([]curve.G1Affine, nbWires-r1cs.NbPublicVariables)
+	nbCommitted := 0
+	if r1cs.Commitment.Is() {
+		nbCommitted = len(r1cs.Commitment.Committed)
+		// the last element of the basis is the blinding generator
+		pk.CommitmentKey.Basis = make([]curve.G1Affine, nbCommitted+1)
+		pk.CommitmentKey.BasisExpSigma = make([]curve.G1Affine, nbCommitted+1)
+		nbCommitted++ // the commitment wire is not in pk.G1.K either
+	}
+	pk.G1.K = make([]curve.G1Affine, nbWires-r1cs.NbPublicVariables-nbCommitted)
 	pk.G1.Z = make([]curve.G1Affine, domain.Cardinality)
 	pk.G2.B = make([]curve.G2Affine, nbWires-nbZeroesB)
 
@@ -470,6 +587,13 @@ func DummySetup(r1cs *cs.R1CS, pk *ProvingKey) error {
 	for i := 0; i < len(pk.G1.K); i++ {
 		pk.G1.K[i] = r1Aff
 	}
+	for i := 0; i < len(pk.CommitmentKey.Basis); i++ {
+		pk.CommitmentKey.Basis[i] = r1Aff
+		pk.CommitmentKey.BasisExpSigma[i] = r1Aff
+	}
+	if len(pk.CommitmentKey.Basis) > 0 {
+		pk.CommitmentKey.BlindingDelta = r1Aff
+	}
 	pk.G1.Alpha = r1Aff
 	pk.G1.Beta = r1Aff
 	pk.G1.Delta = r1Aff
@@ -509,21 +633,6 @@ func dummyInfinityCount(r1cs *cs.R1CS) (nbZeroesA, nbZeroesB int) {
 
 }
 
-// IsDifferent returns true if provided vk is different than self
-// this is used by groth16.Assert to ensure random sampling
-func (vk *VerifyingKey) IsDifferent(_other interface{}) bool {
-	vk2 := _other.(*VerifyingKey)
-	for i := 0; i < len(vk.G1.K); i++ {
-		if !vk.G1.K[i].IsInfinity() {
-			if vk.G1.K[i].Equal(&vk2.G1.K[i]) {
-				return false
-			}
-		}
-	}
-
-	return true
-}
-
 // IsDifferent returns true if provided pk is different than self
 // this is used by groth16.Assert to ensure random sampling
 func (pk *ProvingKey) IsDifferent(_other interface{}) bool {
@@ -551,34 +660,18 @@ func (pk *ProvingKey) CurveID() ecc.ID {
 	return curve.ID
 }
 
-// CurveID returns the curveID
-func (vk *VerifyingKey) CurveID() ecc.ID {
-	return curve.ID
-}
-
-// NbPublicWitness returns the number of elements in the expected public witness
-func (vk *VerifyingKey) NbPublicWitness() int {
-	return (len(vk.G1.K) - 1)
-}
-
-// NbG1 returns the number of G1 elements in the VerifyingKey
-func (vk *VerifyingKey) NbG1() int {
-	return 3 + len(vk.G1.K)
-}
-
-// NbG2 returns the number of G2 elements in the VerifyingKey
-func (vk *VerifyingKey) NbG2() int {
-	return 3
-}
-
 // NbG1 returns the number of G1 elements in the ProvingKey
 func (pk *ProvingKey) NbG1() int {
-	return 3 + len(pk.G1.A) + len(pk.G1.B) + len(pk.G1.Z) + len(pk.G1.K)
+	nb := 3 + pk.nbPoints(SectionA) + pk.nbPoints(SectionB) + pk.nbPoints(SectionZ) + pk.nbPoints(SectionK) + 2*len(pk.CommitmentKey.Basis)
+	if len(pk.CommitmentKey.Basis) > 0 {
+		nb++ // [η/δ]1
+	}
+	return nb
 }
 
 // NbG2 returns the number of G2 elements in the ProvingKey
 func (pk *ProvingKey) NbG2() int {
-	return 2 + len(pk.G2.B)
+	return 2 + pk.nbPoints(SectionG2B)
 }
 
 // bitRerverse permutation as in fft.BitReverse , but with []curve.G1Affine
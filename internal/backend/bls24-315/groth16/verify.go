@@ -19,12 +19,18 @@ package groth16
 import (
 	"github.com/consensys/gnark-crypto/ecc"
 
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+
 	curve "github.com/consensys/gnark-crypto/ecc/bls24-315"
 
+	"crypto/rand"
+	"crypto/sha256"
 	"errors"
 	"fmt"
+	"github.com/consensys/gnark/backend/solidity"
 	bls24_315witness "github.com/consensys/gnark/internal/backend/bls24-315/witness"
 	"io"
+	"math/big"
 	"time"
 
 	"github.com/consensys/gnark/logger"
@@ -33,13 +39,131 @@ import (
 var (
 	errPairingCheckFailed         = errors.New("pairing doesn't match")
 	errCorrectSubgroupCheckFailed = errors.New("points in the proof are not in the correct subgroup")
+	errCommitmentPokFailed        = errors.New("proof of knowledge of the commitment doesn't match")
 )
 
+// Proof represents a Groth16 proof that was encoded with a ProvingKey and can be verified
+// with a valid statement and a VerifyingKey
+// Notation follows Figure 4. in DIZK paper https://eprint.iacr.org/2018/691.pdf
+type Proof struct {
+	Ar, Krs curve.G1Affine
+	Bs      curve.G2Affine
+
+	// commitment to the committed wires and its proof of knowledge, if the circuit
+	// commits to some of its wires (see frontend.API.Commit)
+	Commitment, CommitmentPok curve.G1Affine
+
+	// opening of the commitment, set by the prover to link the proof (see Link)
+	opening commitmentOpening // not serialized
+}
+
+// isValid ensures proof elements are in the correct subgroup
+func (proof *Proof) isValid() bool {
+	return proof.Ar.IsInSubGroup() && proof.Krs.IsInSubGroup() && proof.Bs.IsInSubGroup() &&
+		proof.Commitment.IsInSubGroup() && proof.CommitmentPok.IsInSubGroup()
+}
+
+// CurveID returns the curveID
+func (proof *Proof) CurveID() ecc.ID {
+	return curve.ID
+}
+
+// VerifyingKey is used by a Groth16 verifier to verify the validity of a proof and a statement
+// Notation follows Figure 4. in DIZK paper https://eprint.iacr.org/2018/691.pdf
+type VerifyingKey struct {
+	// [α]1, [Kvk]1
+	G1 struct {
+		Alpha       curve.G1Affine
+		Beta, Delta curve.G1Affine   // unused, here for compatibility purposes
+		K           []curve.G1Affine // The indexes correspond to the public wires
+	}
+
+	// [β]2, [δ]2, [γ]2,
+	// -[δ]2, -[γ]2: see proof.Verify() for more details
+	G2 struct {
+		Beta, Delta, Gamma curve.G2Affine
+		deltaNeg, gammaNeg curve.G2Affine // not serialized
+	}
+
+	// NbCommitments is 1 if the circuit commits to some of its wires (see frontend.API.Commit),
+	// 0 otherwise. The commitment wire is then the last wire of G1.K.
+	NbCommitments uint64
+
+	// [1]2, -[1/σ]2: used to verify the proof of knowledge of the commitment
+	// Basis: the basis of the commitment (see ProvingKey.CommitmentKey), used to
+	// verify the links of the commitment (see LinkProof)
+	CommitmentKey struct {
+		G, GRootSigmaNeg curve.G2Affine
+		Basis            []curve.G1Affine
+	}
+
+	// CircuitFingerprint is the fingerprint of the circuit the key is set up for (see
+	// cs.R1CS.Fingerprint), zero if the key was written before it was recorded
+	CircuitFingerprint [32]byte
+
+	// e(α, β)
+	e curve.GT // not serialized
+}
+
+// Precompute sets e(α, β), -[δ]2 and -[γ]2 in the VerifyingKey, from its serialized elements.
+// It must be called when the key is not built by Setup or ReadFrom.
+func (vk *VerifyingKey) Precompute() error {
+	var err error
+	vk.e, err = curve.Pair([]curve.G1Affine{vk.G1.Alpha}, []curve.G2Affine{vk.G2.Beta})
+	if err != nil {
+		return err
+	}
+	vk.G2.deltaNeg.Neg(&vk.G2.Delta)
+	vk.G2.gammaNeg.Neg(&vk.G2.Gamma)
+	return nil
+}
+
+// Fingerprint returns the fingerprint of the circuit the key is set up for, to
+// be compared with the one of the constraint system (see cs.R1CS.Fingerprint)
+func (vk *VerifyingKey) Fingerprint() [32]byte {
+	return vk.CircuitFingerprint
+}
+
+// IsDifferent returns true if provided vk is different than self
+// this is used by groth16.Assert to ensure random sampling
+func (vk *VerifyingKey) IsDifferent(_other interface{}) bool {
+	vk2 := _other.(*VerifyingKey)
+	for i := 0; i < len(vk.G1.K); i++ {
+		if !vk.G1.K[i].IsInfinity() {
+			if vk.G1.K[i].Equal(&vk2.G1.K[i]) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// CurveID returns the curveID
+func (vk *VerifyingKey) CurveID() ecc.ID {
+	return curve.ID
+}
+
+// NbPublicWitness returns the number of elements in the expected public witness
+func (vk *VerifyingKey) NbPublicWitness() int {
+	return (len(vk.G1.K) - 1 - int(vk.NbCommitments))
+}
+
+// NbG1 returns the number of G1 elements in the VerifyingKey
+func (vk *VerifyingKey) NbG1() int {
+	return 3 + len(vk.G1.K) + len(vk.CommitmentKey.Basis)
+}
+
+// NbG2 returns the number of G2 elements in the VerifyingKey
+func (vk *VerifyingKey) NbG2() int {
+	return 3 + 2*int(vk.NbCommitments)
+}
+
 // Verify verifies a proof with given VerifyingKey and publicWitness
 func Verify(proof *Proof, vk *VerifyingKey, publicWitness bls24_315witness.Witness) error {
 
-	if len(publicWitness) != (len(vk.G1.K) - 1) {
-		return fmt.Errorf("invalid witness size, got %d, expected %d (public - ONE_WIRE)", len(publicWitness), len(vk.G1.K)-1)
+	if len(publicWitness) != vk.NbPublicWitness() {
+		return fmt.Errorf("invalid witness size, got %d, expected %d (public - ONE_WIRE)", len(publicWitness), vk.NbPublicWitness())
 	}
 	log := logger.Logger().With().Str("curve", vk.CurveID().String()).Str("backend", "groth16").Logger()
 	start := time.Now()
@@ -60,12 +184,29 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness bls24_315witness.Witne
 		close(chDone)
 	}()
 
+	// with a commitment, check its proof of knowledge, e(D, [1]2) * e(Pok, -[1/σ]2) == 1,
+	// and append the commitment wire to the public witness
+	if vk.NbCommitments > 0 {
+		ok, err := curve.PairingCheck([]curve.G1Affine{proof.Commitment, proof.CommitmentPok}, []curve.G2Affine{vk.CommitmentKey.G, vk.CommitmentKey.GRootSigmaNeg})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errCommitmentPokFailed
+		}
+		c := commitmentChallenge(&proof.Commitment)
+		publicWitness = append(publicWitness[:len(publicWitness):len(publicWitness)], c)
+	}
+
 	// compute e(Σx.[Kvk(t)]1, -[γ]2)
 	var kSum curve.G1Jac
 	if _, err := kSum.MultiExp(vk.G1.K[1:], publicWitness, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
 		return err
 	}
 	kSum.AddMixed(&vk.G1.K[0])
+	if vk.NbCommitments > 0 {
+		kSum.AddMixed(&proof.Commitment)
+	}
 	var kSumAff curve.G1Affine
 	kSumAff.FromJacobian(&kSum)
 
@@ -88,7 +229,70 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness bls24_315witness.Witne
 	return nil
 }
 
+// commitmentChallenge returns the value of the commitment wire, a hash of the commitment
+func commitmentChallenge(commitment *curve.G1Affine) fr.Element {
+	h := sha256.Sum256(commitment.Marshal())
+	var c fr.Element
+	c.SetBytes(h[:])
+	return c
+}
+
+// Rerandomize re-randomizes proof in place, so that it can't be linked to the
+// original proof while it still verifies against vk and the same public
+// witness. For r₁, r₂ read from rng (crypto/rand.Reader if rng is nil)
+//
+//	Ar ← r₁⋅Ar
+//	Bs ← r₁⁻¹⋅Bs + r₂⋅[δ]₂
+//	Krs ← Krs + r₂⋅Ar
+//
+// e(Ar, Bs) is then multiplied by e(r₂⋅Ar, [δ]₂), as is e(Krs, [δ]₂). The
+// commitment and its proof of knowledge, if any, are left unchanged.
+func (proof *Proof) Rerandomize(vk *VerifyingKey, rng io.Reader) error {
+	var r1, r1Inv, r2 fr.Element
+	for r1.IsZero() {
+		if err := randomElement(rng, &r1); err != nil {
+			return err
+		}
+	}
+	if err := randomElement(rng, &r2); err != nil {
+		return err
+	}
+	r1Inv.Inverse(&r1)
+
+	var s1, s1Inv, s2 big.Int
+	r1.ToBigIntRegular(&s1)
+	r1Inv.ToBigIntRegular(&s1Inv)
+	r2.ToBigIntRegular(&s2)
+
+	proof.Ar.ScalarMultiplication(&proof.Ar, &s1)
+
+	var deltaR2 curve.G2Affine
+	deltaR2.ScalarMultiplication(&vk.G2.Delta, &s2)
+	proof.Bs.ScalarMultiplication(&proof.Bs, &s1Inv)
+	proof.Bs.Add(&proof.Bs, &deltaR2)
+
+	var arR2 curve.G1Affine
+	arR2.ScalarMultiplication(&proof.Ar, &s2)
+	proof.Krs.Add(&proof.Krs, &arR2)
+
+	return nil
+}
+
+// randomElement sets e to a uniformly random element read from rng, or from
+// crypto/rand.Reader if rng is nil
+func randomElement(rng io.Reader, e *fr.Element) error {
+	if rng == nil {
+		rng = rand.Reader
+	}
+	v, err := rand.Int(rng, fr.Modulus())
+	if err != nil {
+		return err
+	}
+	e.SetBigInt(v)
+	return nil
+}
+
 // ExportSolidity not implemented for BLS24-315
-func (vk *VerifyingKey) ExportSolidity(w io.Writer) error {
+func (vk *VerifyingKey) ExportSolidity(w io.Writer, exportOpts ...solidity.ExportOption) error {
 	return errors.New("not implemented")
 }
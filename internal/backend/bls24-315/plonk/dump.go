@@ -0,0 +1,238 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package plonk
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"unsafe"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls24-315"
+
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr/kzg"
+
+	"github.com/consensys/gnark/internal/utils"
+)
+
+// WriteDump writes the ProvingKey in the memory layout of the running architecture.
+// ReadDump can then set the polynomials of the key from a memory mapped dump, without
+// copying nor decoding them: Prove reads them from the file as needed.
+// If the KZG SRS is set, it is dumped too.
+// A dump is not portable across architectures.
+func (pk *ProvingKey) WriteDump(w io.Writer) (int64, error) {
+	var vk, domain0, domain1 bytes.Buffer
+	if _, err := pk.Vk.WriteTo(&vk); err != nil {
+		return 0, err
+	}
+	if _, err := pk.Domain[0].WriteTo(&domain0); err != nil {
+		return 0, err
+	}
+	if _, err := pk.Domain[1].WriteTo(&domain1); err != nil {
+		return 0, err
+	}
+
+	dw := utils.NewDumpWriter(w)
+	dw.WriteBytes(vk.Bytes())
+	dw.WriteBytes(domain0.Bytes())
+	dw.WriteBytes(domain1.Bytes())
+	for _, p := range [][]fr.Element{
+		pk.Ql, pk.Qr, pk.Qm, pk.Qo, pk.CQk, pk.LQk,
+		pk.EvaluationPermutationBigDomainBitReversed,
+		pk.S1Canonical, pk.S2Canonical, pk.S3Canonical,
+	} {
+		dw.WriteBytes(frBytes(p))
+	}
+	dw.WriteBytes(int64Bytes(pk.Permutation))
+	for i := range pk.Qcustom {
+		dw.WriteBytes(frBytes(pk.Qcustom[i]))
+	}
+	if len(pk.Vk.T) > 0 {
+		dw.WriteBytes(frBytes(pk.Qlookup))
+		for i := range pk.T {
+			dw.WriteBytes(frBytes(pk.T[i]))
+		}
+	}
+	if len(pk.Vk.CommitmentConstraintIndexes) > 0 {
+		dw.WriteBytes(frBytes(pk.Qcp))
+	}
+	if pk.Vk.KZGSRS != nil {
+		dw.WriteBytes(g1Bytes(pk.Vk.KZGSRS.G1))
+		dw.WriteBytes(g2Bytes(pk.Vk.KZGSRS.G2[:]))
+	} else {
+		dw.WriteBytes(nil)
+		dw.WriteBytes(nil)
+	}
+
+	return dw.BytesWritten()
+}
+
+// ReadDump sets the ProvingKey from a dump written by WriteDump.
+// The polynomials of the key reference data, which must not be modified nor unmapped
+// while the key is in use. It doesn't perform any check on the data.
+func (pk *ProvingKey) ReadDump(data []byte) error {
+	dr, err := utils.NewDumpReader(data)
+	if err != nil {
+		return err
+	}
+
+	pk.Vk = &VerifyingKey{}
+	for _, v := range []io.ReaderFrom{pk.Vk, &pk.Domain[0], &pk.Domain[1]} {
+		b, err := dr.ReadBytes()
+		if err != nil {
+			return err
+		}
+		if _, err := v.ReadFrom(bytes.NewReader(b)); err != nil {
+			return err
+		}
+	}
+	pk.Vk.CosetShift.Set(&pk.Domain[0].FrMultiplicativeGen)
+
+	readFr := func() []fr.Element {
+		if err != nil {
+			return nil
+		}
+		var b []byte
+		b, err = dr.ReadSlice(unsafe.Sizeof(fr.Element{}))
+		return bytesToFr(b)
+	}
+
+	pk.Ql = readFr()
+	pk.Qr = readFr()
+	pk.Qm = readFr()
+	pk.Qo = readFr()
+	pk.CQk = readFr()
+	pk.LQk = readFr()
+	pk.EvaluationPermutationBigDomainBitReversed = readFr()
+	pk.S1Canonical = readFr()
+	pk.S2Canonical = readFr()
+	pk.S3Canonical = readFr()
+	if err != nil {
+		return err
+	}
+	b, err := dr.ReadSlice(unsafe.Sizeof(int64(0)))
+	if err != nil {
+		return err
+	}
+	pk.Permutation = bytesToInt64(b)
+	if len(pk.Permutation) != 3*int(pk.Domain[0].Cardinality) {
+		return errors.New("invalid dump: invalid permutation size, expected 3*domain cardinality")
+	}
+
+	pk.Qcustom = nil
+	if len(pk.Vk.Qcustom) > 0 {
+		pk.Qcustom = make([][]fr.Element, len(pk.Vk.Qcustom))
+		for i := range pk.Qcustom {
+			pk.Qcustom[i] = readFr()
+		}
+	}
+	pk.Qlookup, pk.T = nil, nil
+	if len(pk.Vk.T) > 0 {
+		pk.Qlookup = readFr()
+		pk.T = make([][]fr.Element, len(pk.Vk.T))
+		for i := range pk.T {
+			pk.T[i] = readFr()
+		}
+	}
+	pk.Qcp = nil
+	if len(pk.Vk.CommitmentConstraintIndexes) > 0 {
+		pk.Qcp = readFr()
+	}
+	if err != nil {
+		return err
+	}
+
+	// KZG SRS, if it was set
+	if b, err = dr.ReadSlice(unsafe.Sizeof(curve.G1Affine{})); err != nil {
+		return err
+	}
+	srs := &kzg.SRS{G1: bytesToG1(b)}
+	if b, err = dr.ReadSlice(unsafe.Sizeof(curve.G2Affine{})); err != nil {
+		return err
+	}
+	if len(srs.G1) == 0 {
+		return nil
+	}
+	g2 := bytesToG2(b)
+	if len(g2) != len(srs.G2) {
+		return errors.New("invalid dump: invalid kzg srs")
+	}
+	copy(srs.G2[:], g2)
+
+	return pk.InitKZG(srs)
+}
+
+func frBytes(s []fr.Element) []byte {
+	if len(s) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(&s[0])), len(s)*int(unsafe.Sizeof(s[0])))
+}
+
+func int64Bytes(s []int64) []byte {
+	if len(s) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(&s[0])), len(s)*int(unsafe.Sizeof(s[0])))
+}
+
+func g1Bytes(s []curve.G1Affine) []byte {
+	if len(s) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(&s[0])), len(s)*int(unsafe.Sizeof(s[0])))
+}
+
+func g2Bytes(s []curve.G2Affine) []byte {
+	if len(s) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(&s[0])), len(s)*int(unsafe.Sizeof(s[0])))
+}
+
+func bytesToFr(b []byte) []fr.Element {
+	if len(b) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*fr.Element)(unsafe.Pointer(&b[0])), len(b)/int(unsafe.Sizeof(fr.Element{})))
+}
+
+func bytesToInt64(b []byte) []int64 {
+	if len(b) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*int64)(unsafe.Pointer(&b[0])), len(b)/int(unsafe.Sizeof(int64(0))))
+}
+
+func bytesToG1(b []byte) []curve.G1Affine {
+	if len(b) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*curve.G1Affine)(unsafe.Pointer(&b[0])), len(b)/int(unsafe.Sizeof(curve.G1Affine{})))
+}
+
+func bytesToG2(b []byte) []curve.G2Affine {
+	if len(b) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*curve.G2Affine)(unsafe.Pointer(&b[0])), len(b)/int(unsafe.Sizeof(curve.G2Affine{})))
+}
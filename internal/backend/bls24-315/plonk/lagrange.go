@@ -0,0 +1,213 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package plonk
+
+import (
+	"errors"
+	"math/big"
+	"math/bits"
+	"runtime"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls24-315"
+
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr/kzg"
+
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr/fft"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/accelerator"
+	"github.com/consensys/gnark/internal/utils"
+)
+
+var errInvalidLagrangeSRS = errors.New("invalid Lagrange SRS")
+
+// InitLagrangeSRS computes pk.LagrangeSRS, the Lagrange basis of pk.Domain[0],
+// from the monomial basis of pk.Vk.KZGSRS (see ToLagrangeG1).
+//
+// This is done once per key, pk.LagrangeSRS is NOT serialized.
+func (pk *ProvingKey) InitLagrangeSRS() error {
+	n := int(pk.Domain[0].Cardinality)
+	if pk.Vk.KZGSRS == nil || len(pk.Vk.KZGSRS.G1) < n {
+		return kzg.ErrInvalidPolynomialSize
+	}
+	pk.LagrangeSRS = ToLagrangeG1(pk.Vk.KZGSRS.G1[:n], &pk.Domain[0])
+	return nil
+}
+
+// SetLagrangeSRS sets pk.LagrangeSRS to points, a []curve.G1Affine holding
+// [Lᵢ(τ)]₁ for the Lagrange polynomials Lᵢ of pk.Domain[0], as distributed by
+// some ceremonies.
+//
+// As ∑ Lᵢ = 1 and ∑ ωⁱLᵢ = X, the points are checked against [1]₁ and [τ]₁
+// in pk.Vk.KZGSRS.
+func (pk *ProvingKey) SetLagrangeSRS(points interface{}) error {
+	lagrange, ok := points.([]curve.G1Affine)
+	if !ok {
+		return errors.New("the points of the Lagrange SRS must be of type []curve.G1Affine")
+	}
+	n := int(pk.Domain[0].Cardinality)
+	if len(lagrange) != n || pk.Vk.KZGSRS == nil || len(pk.Vk.KZGSRS.G1) < 2 {
+		return errInvalidLagrangeSRS
+	}
+
+	ones := make([]fr.Element, n)
+	omegas := make([]fr.Element, n)
+	ones[0].SetOne()
+	omegas[0].SetOne()
+	for i := 1; i < n; i++ {
+		ones[i].SetOne()
+		omegas[i].Mul(&omegas[i-1], &pk.Domain[0].Generator)
+	}
+	var one, tau curve.G1Affine
+	if _, err := one.MultiExp(lagrange, ones, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+		return err
+	}
+	if _, err := tau.MultiExp(lagrange, omegas, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+		return err
+	}
+	if !one.Equal(&pk.Vk.KZGSRS.G1[0]) || !tau.Equal(&pk.Vk.KZGSRS.G1[1]) {
+		return errInvalidLagrangeSRS
+	}
+
+	pk.LagrangeSRS = lagrange
+	return nil
+}
+
+// ToLagrangeG1 returns the Lagrange basis [Lᵢ(τ)]₁ of domain from its
+// monomial basis [τⁱ]₁, i < domain.Cardinality. As
+// Lᵢ = 1/n ∑ⱼ ω⁻ⁱʲXʲ, it is the inverse FFT of the monomial basis, in G₁.
+func ToLagrangeG1(monomial []curve.G1Affine, domain *fft.Domain) []curve.G1Affine {
+	return fftG1(monomial, domain.GeneratorInv, &domain.CardinalityInv)
+}
+
+// ToMonomialG1 returns the monomial basis [τⁱ]₁ of domain from its Lagrange
+// basis [Lᵢ(τ)]₁. As Xʲ = ∑ᵢ ωⁱʲLᵢ, it is the FFT of the Lagrange basis, in G₁.
+func ToMonomialG1(lagrange []curve.G1Affine, domain *fft.Domain) []curve.G1Affine {
+	return fftG1(lagrange, domain.Generator, nil)
+}
+
+// fftG1 returns the evaluations of ∑ pᵢXⁱ on the powers of generator (in
+// natural order), multiplied by scale if it is set. len(p) must be a power of 2.
+func fftG1(p []curve.G1Affine, generator fr.Element, scale *fr.Element) []curve.G1Affine {
+	n := len(p)
+	a := make([]curve.G1Jac, n)
+	nn := uint64(64 - bits.TrailingZeros64(uint64(n)))
+	for i := 0; i < n; i++ {
+		a[bits.Reverse64(uint64(i))>>nn].FromAffine(&p[i])
+	}
+
+	// iterative radix-2 decimation in time: at each stage, the butterflies of
+	// the blocks of size m are independent
+	for m := 2; m <= n; m <<= 1 {
+		half := m / 2
+		var wm fr.Element
+		wm.Exp(generator, big.NewInt(int64(n/m)))
+		twiddles := make([]big.Int, half)
+		var w fr.Element
+		w.SetOne()
+		for j := 0; j < half; j++ {
+			w.ToBigIntRegular(&twiddles[j])
+			w.Mul(&w, &wm)
+		}
+		utils.Parallelize(n/2, func(start, end int) {
+			var t curve.G1Jac
+			for b := start; b < end; b++ {
+				k, j := (b/half)*m, b%half
+				t.ScalarMultiplication(&a[k+j+half], &twiddles[j])
+				a[k+j+half].Set(&a[k+j])
+				a[k+j+half].SubAssign(&t)
+				a[k+j].AddAssign(&t)
+			}
+		}, runtime.NumCPU())
+	}
+
+	if scale != nil {
+		var s big.Int
+		scale.ToBigIntRegular(&s)
+		utils.Parallelize(n, func(start, end int) {
+			for i := start; i < end; i++ {
+				a[i].ScalarMultiplication(&a[i], &s)
+			}
+		})
+	}
+
+	res := make([]curve.G1Affine, n)
+	curve.BatchJacobianToAffineG1(a, res)
+	return res
+}
+
+// commitLagrange returns the KZG commitment of p + q(X)(Xⁿ-1), where p is given
+// by its evaluations on pk.Domain[0] and committed with pk.LagrangeSRS, with acc if
+// it is set. As q(X)(Xⁿ-1) = ∑ qᵢXⁿ⁺ⁱ - qᵢXⁱ, the commitment of the blinding
+// is computed with the monomial SRS.
+func commitLagrange(acc accelerator.Accelerator, evaluations, q []fr.Element, pk *ProvingKey, nbTasks int) (kzg.Digest, error) {
+	config := ecc.MultiExpConfig{NbTasks: nbTasks, ScalarsMont: true}
+	var res, blinding curve.G1Jac
+	if acc != nil {
+		if err := acc.MultiExpG1(&res, pk.LagrangeSRS, evaluations, config); err != nil {
+			return kzg.Digest{}, err
+		}
+	} else if _, err := res.MultiExp(pk.LagrangeSRS, evaluations, config); err != nil {
+		return kzg.Digest{}, err
+	}
+
+	n := len(pk.LagrangeSRS)
+	srs := pk.Vk.KZGSRS.G1
+	if len(srs) < n+len(q) {
+		return kzg.Digest{}, kzg.ErrInvalidPolynomialSize
+	}
+	if _, err := blinding.MultiExp(srs[n:n+len(q)], q, config); err != nil {
+		return kzg.Digest{}, err
+	}
+	res.AddAssign(&blinding)
+	if _, err := blinding.MultiExp(srs[:len(q)], q, config); err != nil {
+		return kzg.Digest{}, err
+	}
+	res.SubAssign(&blinding)
+
+	var digest kzg.Digest
+	digest.FromJacobian(&res)
+	return digest, nil
+}
+
+// commitToLROLagrange fills proof.LRO with the commitments of l, r and o,
+// given by their evaluations on pk.Domain[0], blinded with blindings, each
+// computed with nbTasks tasks
+func commitToLROLagrange(acc accelerator.Accelerator, lro, blindings [3][]fr.Element, proof *Proof, pk *ProvingKey, nbTasks int) error {
+	var errs [3]error
+	var wg sync.WaitGroup
+	wg.Add(3)
+	for i := 0; i < 3; i++ {
+		go func(i int) {
+			defer wg.Done()
+			proof.LRO[i], errs[i] = commitLagrange(acc, lro[i], blindings[i], pk, nbTasks)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
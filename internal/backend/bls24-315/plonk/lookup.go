@@ -0,0 +1,259 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package plonk
+
+import (
+	"io"
+	"math/bits"
+
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr/fft"
+
+	"github.com/consensys/gnark/internal/backend/bls24-315/cs"
+
+	"github.com/consensys/gnark-crypto/fiat-shamir"
+	"github.com/consensys/gnark/backend/accelerator"
+	"github.com/consensys/gnark/internal/utils"
+)
+
+// The lookup argument is a variant of plookup (https://eprint.iacr.org/2020/315.pdf)
+// on a cyclic domain, with the three columns of the tables (index, table index, value)
+// and the wires l, r, o compressed with a random challenge η.
+//
+// * t is the compressed table, padded with its last entry
+// * f is the compressed l, r, o on lookup constraints, and t(μⁿ⁻¹) elsewhere
+// * h1, h2 interleave s, the concatenation of f and t sorted by t: h1(μⁱ)=s₂ᵢ, h2(μⁱ)=s₂ᵢ₊₁
+// * Zl is the accumulator of the grand product, Zl(1)=1 and
+// Zl(μX)*(ε(1+δ)+h1(X)+δ*h2(X))*(ε(1+δ)+h2(X)+δ*h1(μX)) = Zl(X)*(1+δ)*(ε+f(X))*(ε(1+δ)+t(X)+δ*t(μX))
+
+// nbTableEntries returns the total number of entries in the lookup tables of spr
+func nbTableEntries(spr *cs.SparseR1CS) int {
+	n := 0
+	for _, t := range spr.Tables {
+		n += len(t.Values)
+	}
+	return n
+}
+
+// lookupTables returns the columns (index, table index, value) of the concatenated
+// lookup tables of spr, in Lagrange basis on a domain of size n, padded with the last entry
+func lookupTables(spr *cs.SparseR1CS, n int) [3][]fr.Element {
+	var res [3][]fr.Element
+	for i := range res {
+		res[i] = make([]fr.Element, n)
+	}
+	k := 0
+	for tID, t := range spr.Tables {
+		for i, vID := range t.Values {
+			res[0][k].SetUint64(uint64(i))
+			res[1][k].SetUint64(uint64(tID))
+			res[2][k].Set(&spr.Coefficients[vID])
+			k++
+		}
+	}
+	for ; k < n; k++ {
+		res[0][k] = res[0][k-1]
+		res[1][k] = res[1][k-1]
+		res[2][k] = res[2][k-1]
+	}
+	return res
+}
+
+// lookupProver holds the polynomials of the lookup argument in canonical basis
+type lookupProver struct {
+	lookupChallenges
+
+	// f, h1, h2 and z are blinded
+	f, t, h1, h2, z []fr.Element
+}
+
+// computeLookup derives the challenges of the lookup argument, and computes and commits to
+// f, h1, h2 and Zl.
+//
+// * l, r, o are the solution vectors in Lagrange basis, not blinded
+func computeLookup(acc accelerator.Accelerator, fs *fiatshamir.Transcript, spr *cs.SparseR1CS, pk *ProvingKey, proof *Proof, l, r, o []fr.Element, rng io.Reader) (*lookupProver, error) {
+	n := int(pk.Domain[0].Cardinality)
+
+	// derive η from Comm(l), Comm(r), Comm(o)
+	eta, err := deriveRandomness(fs, "eta", &proof.LRO[0], &proof.LRO[1], &proof.LRO[2])
+	if err != nil {
+		return nil, err
+	}
+	c := lookupChallenges{eta: eta}
+
+	// t, f in Lagrange basis
+	columns := lookupTables(spr, n)
+	t := make([]fr.Element, n)
+	for i := 0; i < n; i++ {
+		t[i] = c.compress(columns[0][i], columns[1][i], columns[2][i])
+	}
+	f := make([]fr.Element, n)
+	for i := range f {
+		f[i] = t[n-1]
+	}
+	offset := spr.NbPublicVariables
+	for i := 0; i < len(spr.Constraints); i++ {
+		if spr.Constraints[i].Table != 0 {
+			f[offset+i] = c.compress(l[offset+i], r[offset+i], o[offset+i])
+		}
+	}
+
+	// s is the concatenation of f and t, sorted by t
+	// if a value of f is not in t (the solver failed and the prover is forced),
+	// s is not a permutation of f and t, and the proof will not verify.
+	positions := make(map[fr.Element]int, n)
+	for i := n - 1; i >= 0; i-- {
+		positions[t[i]] = i
+	}
+	counts := make([]int, n)
+	for i := range f {
+		counts[positions[f[i]]]++
+	}
+	h1 := make([]fr.Element, n)
+	h2 := make([]fr.Element, n)
+	k := 0
+	for i := range t {
+		for j := 0; j <= counts[i]; j++ {
+			if k%2 == 0 {
+				h1[k/2] = t[i]
+			} else {
+				h2[k/2] = t[i]
+			}
+			k++
+		}
+	}
+
+	lk := &lookupProver{}
+	lk.t = make([]fr.Element, n)
+	for i := 0; i < n; i++ {
+		lk.t[i] = c.compress(pk.T[0][i], pk.T[1][i], pk.T[2][i])
+	}
+	if lk.f, err = blindedCanonical(acc, f, &pk.Domain[0], 1, rng); err != nil {
+		return nil, err
+	}
+	if lk.h1, err = blindedCanonical(acc, h1, &pk.Domain[0], 2, rng); err != nil {
+		return nil, err
+	}
+	if lk.h2, err = blindedCanonical(acc, h2, &pk.Domain[0], 1, rng); err != nil {
+		return nil, err
+	}
+	if proof.F, err = commit(acc, lk.f, pk.Vk.KZGSRS); err != nil {
+		return nil, err
+	}
+	if proof.H1, err = commit(acc, lk.h1, pk.Vk.KZGSRS); err != nil {
+		return nil, err
+	}
+	if proof.H2, err = commit(acc, lk.h2, pk.Vk.KZGSRS); err != nil {
+		return nil, err
+	}
+
+	// derive δ from Comm(f), Comm(h1), Comm(h2), and ε
+	delta, err := deriveRandomness(fs, "delta", &proof.F, &proof.H1, &proof.H2)
+	if err != nil {
+		return nil, err
+	}
+	epsilon, err := deriveRandomness(fs, "epsilon")
+	if err != nil {
+		return nil, err
+	}
+	lk.lookupChallenges = newLookupChallenges(eta, delta, epsilon)
+
+	// Zl in Lagrange basis
+	z := make([]fr.Element, n)
+	den := make([]fr.Element, n)
+	z[0].SetOne()
+	den[0].SetOne()
+	utils.Parallelize(n-1, func(start, end int) {
+		for i := start; i < end; i++ {
+			z[i+1] = lk.numerator(f[i], t[i], t[i+1])
+			den[i+1] = lk.denominator(h1[i], h2[i], h1[i+1])
+		}
+	})
+	den = fr.BatchInvert(den)
+	for i := 1; i < n; i++ {
+		z[i].Mul(&z[i], &z[i-1]).Mul(&z[i], &den[i])
+	}
+	if lk.z, err = blindedCanonical(acc, z, &pk.Domain[0], 2, rng); err != nil {
+		return nil, err
+	}
+	if proof.Zl, err = commit(acc, lk.z, pk.Vk.KZGSRS); err != nil {
+		return nil, err
+	}
+
+	return lk, nil
+}
+
+// blindedCanonical returns p (Lagrange basis) in canonical basis, blinded with a polynomial of degree bo
+func blindedCanonical(acc accelerator.Accelerator, p []fr.Element, domain *fft.Domain, bo uint64, rng io.Reader) ([]fr.Element, error) {
+	cp := make([]fr.Element, domain.Cardinality, domain.Cardinality+bo+1)
+	copy(cp, p)
+	fftInverse(acc, domain, cp, fft.DIF, false)
+	fft.BitReverse(cp)
+	return blindPoly(cp, domain.Cardinality, bo, rng)
+}
+
+// evaluateDomainBigBitReversed computes the evaluation of
+// qlookup(X)*(l(X)+η*r(X)+η²*o(X)-f(X)) + α*(Zl(μX)*D(X)-Zl(X)*N(X)) + α²*L₁(X)*(Zl(X)-1)
+// on the big domain coset, where N, D are the numerator and denominator of the grand product.
+//
+// * l, r, o are the evaluations of the blinded solution vectors on the big domain coset
+// * lOne is the evaluation of L₁ on the big domain coset
+func (lk *lookupProver) evaluateDomainBigBitReversed(acc accelerator.Accelerator, pk *ProvingKey, l, r, o, lOne []fr.Element, alpha fr.Element) []fr.Element {
+	evalQ := evaluateDomainBigBitReversed(acc, pk.Qlookup, &pk.Domain[1])
+	evalF := evaluateDomainBigBitReversed(acc, lk.f, &pk.Domain[1])
+	evalT := evaluateDomainBigBitReversed(acc, lk.t, &pk.Domain[1])
+	evalH1 := evaluateDomainBigBitReversed(acc, lk.h1, &pk.Domain[1])
+	evalH2 := evaluateDomainBigBitReversed(acc, lk.h2, &pk.Domain[1])
+	evalZ := evaluateDomainBigBitReversed(acc, lk.z, &pk.Domain[1])
+
+	nbElmts := int(pk.Domain[1].Cardinality)
+	nn := uint64(64 - bits.TrailingZeros64(uint64(nbElmts)))
+
+	// needed to shift the evaluations
+	toShift := int(pk.Domain[1].Cardinality / pk.Domain[0].Cardinality)
+
+	res := make([]fr.Element, nbElmts)
+
+	utils.Parallelize(nbElmts, func(start, end int) {
+		var one, t0, t1 fr.Element
+		one.SetOne()
+		for i := start; i < end; i++ {
+
+			_i := bits.Reverse64(uint64(i)) >> nn
+			_is := bits.Reverse64(uint64((i+toShift)%nbElmts)) >> nn
+
+			t0 = lk.denominator(evalH1[_i], evalH2[_i], evalH1[_is])
+			t0.Mul(&t0, &evalZ[_is])
+			t1 = lk.numerator(evalF[_i], evalT[_i], evalT[_is])
+			t1.Mul(&t1, &evalZ[_i])
+			t0.Sub(&t0, &t1) // Zl(μX)*D(X)-Zl(X)*N(X)
+
+			t1.Sub(&evalZ[_i], &one).Mul(&t1, &lOne[_i]).Mul(&t1, &alpha) // α*L₁(X)*(Zl(X)-1)
+			t0.Add(&t0, &t1).Mul(&t0, &alpha)
+
+			t1 = lk.compress(l[_i], r[_i], o[_i])
+			t1.Sub(&t1, &evalF[_i]).Mul(&t1, &evalQ[_i]) // qlookup(X)*(l(X)+η*r(X)+η²*o(X)-f(X))
+			res[_i].Add(&t0, &t1)
+		}
+	})
+
+	return res
+}
@@ -0,0 +1,452 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package plonkfri
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"math/bits"
+	"strconv"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr/fft"
+
+	bls24_315witness "github.com/consensys/gnark/internal/backend/bls24-315/witness"
+
+	"github.com/consensys/gnark/internal/backend/bls24-315/cs"
+
+	"github.com/consensys/gnark-crypto/fiat-shamir"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/internal/merkle"
+	"github.com/consensys/gnark/internal/utils"
+	"github.com/consensys/gnark/logger"
+	"github.com/consensys/gnark/metrics"
+)
+
+// Prove from the public data
+//
+// The polynomials are not blinded: the proof is not zero-knowledge.
+func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bls24_315witness.Witness, opt backend.ProverConfig) (_ *Proof, err error) {
+
+	log := logger.Logger().With().Str("curve", spr.CurveID().String()).Int("nbConstraints", len(spr.Constraints)).Str("backend", "plonkfri").Logger()
+	start := time.Now()
+	m := metrics.Start(metrics.PhaseProve, spr.CurveID(), backend.PLONKFRI)
+	defer func() {
+		m.Stop(metrics.Event{Err: err, NbConstraints: len(spr.Constraints), NbWires: spr.NbInternalVariables + spr.NbPublicVariables + spr.NbSecretVariables})
+	}()
+
+	nbFolds := bits.TrailingZeros64(pk.Vk.Size)
+	fs := fiatshamir.NewTranscript(sha256.New(), challengeIDs(nbFolds)...)
+
+	// result
+	proof := &Proof{}
+
+	// compute the constraint system solution
+	solution, err := spr.Solve(fullWitness, opt)
+	if err != nil {
+		if !opt.Force {
+			return nil, err
+		}
+		// we need to fill solution with random values
+		var r fr.Element
+		if _, err := r.SetRandom(); err != nil {
+			return nil, err
+		}
+		for i := spr.NbPublicVariables + spr.NbSecretVariables; i < len(solution); i++ {
+			solution[i] = r
+			r.Double(&r)
+		}
+	}
+
+	// l, r, o in Lagrange basis, then in canonical basis
+	var canonical [nbPolynomials][]fr.Element
+	copy(canonical[:idL], pk.Q[:])
+	canonical[idL], canonical[idR], canonical[idO] = evaluateLROSmallDomain(spr, pk, solution)
+	lagrangeLRO := [3][]fr.Element{
+		append([]fr.Element(nil), canonical[idL]...),
+		append([]fr.Element(nil), canonical[idR]...),
+		append([]fr.Element(nil), canonical[idO]...),
+	}
+	for i := idL; i <= idO; i++ {
+		pk.Domain[0].FFTInverse(canonical[i], fft.DIF)
+		fft.BitReverse(canonical[i])
+	}
+
+	// commit to l, r, o
+	var evaluations [nbPolynomials][]fr.Element
+	copy(evaluations[:idL], pk.evaluations[:])
+	commitToPolynomials := func(from, to int) *merkle.Tree {
+		utils.Parallelize(to-from, func(start, end int) {
+			for i := from + start; i < from+end; i++ {
+				evaluations[i] = evaluateDomainFRI(canonical[i], &pk.Domain[2])
+			}
+		}, to-from)
+		return commitEvaluations(evaluations[from:to])
+	}
+	treeLRO := commitToPolynomials(idL, idZ)
+	proof.LRO = treeLRO.Root()
+
+	// derive gamma and beta from the preprocessed polynomials, the public inputs and the commitment to l, r, o
+	if err := bindPublicData(&fs, "gamma", pk.Vk, fullWitness[:spr.NbPublicVariables]); err != nil {
+		return nil, err
+	}
+	gamma, err := deriveRandomness(&fs, "gamma", proof.LRO[:])
+	if err != nil {
+		return nil, err
+	}
+	beta, err := deriveRandomness(&fs, "beta")
+	if err != nil {
+		return nil, err
+	}
+
+	// compute Z, the permutation accumulator polynomial, and commit to it
+	canonical[idZ] = computeZCanonical(lagrangeLRO, pk, beta, gamma)
+	treeZ := commitToPolynomials(idZ, idH1)
+	proof.Z = treeZ.Root()
+
+	alpha, err := deriveRandomness(&fs, "alpha", proof.Z[:])
+	if err != nil {
+		return nil, err
+	}
+
+	// compute the quotient h = h1 + Xⁿh2 + X²ⁿh3, and commit to it
+	qkCompleted := make([]fr.Element, pk.Domain[0].Cardinality)
+	copy(qkCompleted, fullWitness[:spr.NbPublicVariables])
+	copy(qkCompleted[spr.NbPublicVariables:], pk.LQk[spr.NbPublicVariables:])
+	pk.Domain[0].FFTInverse(qkCompleted, fft.DIF)
+	fft.BitReverse(qkCompleted)
+	h, ok := computeQuotientCanonical(pk, &canonical, qkCompleted, alpha, beta, gamma)
+	if !ok && !opt.Force {
+		return nil, errors.New("the quotient is not a polynomial: the constraints are not satisfied")
+	}
+	n := pk.Domain[0].Cardinality
+	canonical[idH1], canonical[idH2], canonical[idH3] = h[:n], h[n:2*n], h[2*n:3*n]
+	treeH := commitToPolynomials(idH1, nbPolynomials)
+	proof.H = treeH.Root()
+
+	zeta, err := deriveRandomness(&fs, "zeta", proof.H[:])
+	if err != nil {
+		return nil, err
+	}
+	if err := checkZeta(pk.Vk, &zeta); err != nil {
+		return nil, err
+	}
+
+	// evaluations at ζ, and of z at ωζ
+	var zetaShifted fr.Element
+	zetaShifted.Mul(&zeta, &pk.Vk.Generator)
+	utils.Parallelize(nbPolynomials, func(start, end int) {
+		for i := start; i < end; i++ {
+			proof.Evaluations[i] = eval(canonical[i], zeta)
+		}
+	}, nbPolynomials)
+	proof.ZShifted = eval(canonical[idZ], zetaShifted)
+
+	// low degree test of the quotient of the openings
+	nu, err := deriveRandomness(&fs, "nu", evaluationsBytes(proof))
+	if err != nil {
+		return nil, err
+	}
+	f := computeDeepQuotient(pk, &evaluations, proof, zeta, zetaShifted, nu)
+	layers := make([][]fr.Element, 0, nbFolds)
+	layerTrees := make([]*merkle.Tree, 0, nbFolds)
+	omega := pk.Vk.GeneratorFRI
+	for i := 0; i < nbFolds; i++ {
+		var roots [][]byte
+		if i > 0 {
+			t := commitEvaluations([][]fr.Element{f})
+			root := t.Root()
+			layers = append(layers, f)
+			layerTrees = append(layerTrees, t)
+			proof.Layers = append(proof.Layers, root)
+			roots = append(roots, root[:])
+		}
+		foldingChallenge, err := deriveRandomness(&fs, "fri"+strconv.Itoa(i), roots...)
+		if err != nil {
+			return nil, err
+		}
+		f = fold(f, &omega, &foldingChallenge)
+		omega.Square(&omega)
+	}
+	proof.FinalValue = f[0]
+	finalValue := proof.FinalValue.Bytes()
+	if err := fs.Bind("query", finalValue[:]); err != nil {
+		return nil, err
+	}
+	seed, err := fs.ComputeChallenge("query")
+	if err != nil {
+		return nil, err
+	}
+
+	// open the trees at the queries
+	polynomialTrees := [len(trees)]*merkle.Tree{pk.tree, treeLRO, treeZ, treeH}
+	proof.Queries = make([]Query, nbQueries)
+	for q, index := range queryIndexes(seed, pk.Domain[2].Cardinality/2) {
+		query := &proof.Queries[q]
+		for i, t := range trees {
+			query.Polynomials[i] = open(polynomialTrees[i], evaluations[t[0]:t[1]], int(index))
+		}
+		query.Layers = make([]Opening, len(layers))
+		for i := range layers {
+			index %= uint64(len(layers[i]) / 2)
+			query.Layers[i] = open(layerTrees[i], layers[i:i+1], int(index))
+		}
+	}
+
+	log.Debug().Dur("took", time.Since(start)).Msg("prover done")
+
+	return proof, nil
+}
+
+// eval evaluates c at p
+func eval(c []fr.Element, p fr.Element) fr.Element {
+	var r fr.Element
+	for i := len(c) - 1; i >= 0; i-- {
+		r.Mul(&r, &p).Add(&r, &c[i])
+	}
+	return r
+}
+
+// evaluateLROSmallDomain extracts the solution l, r, o, and returns it in lagrange form.
+// solution = [ public | secret | internal ]
+func evaluateLROSmallDomain(spr *cs.SparseR1CS, pk *ProvingKey, solution []fr.Element) ([]fr.Element, []fr.Element, []fr.Element) {
+
+	s := int(pk.Domain[0].Cardinality)
+
+	var l, r, o []fr.Element
+	l = make([]fr.Element, s)
+	r = make([]fr.Element, s)
+	o = make([]fr.Element, s)
+	s0 := solution[0]
+
+	for i := 0; i < spr.NbPublicVariables; i++ { // placeholders
+		l[i] = solution[i]
+		r[i] = s0
+		o[i] = s0
+	}
+	offset := spr.NbPublicVariables
+	for i := 0; i < len(spr.Constraints); i++ { // constraints
+		l[offset+i] = solution[spr.Constraints[i].L.WireID()]
+		r[offset+i] = solution[spr.Constraints[i].R.WireID()]
+		o[offset+i] = solution[spr.Constraints[i].O.WireID()]
+	}
+	offset += len(spr.Constraints)
+
+	for i := 0; i < s-offset; i++ { // offset to reach 2**n constraints (where the id of l,r,o is 0, so we assign solution[0])
+		l[offset+i] = s0
+		r[offset+i] = s0
+		o[offset+i] = s0
+	}
+
+	return l, r, o
+
+}
+
+// computeZCanonical computes Z, in canonical basis, where:
+//
+//   - Z of degree n (domainNum.Cardinality)
+//
+//   - Z(1)=1
+//     (l(g^k)+β*g^k+γ)*(r(g^k)+uβ*g^k+γ)*(o(g^k)+u²β*g^k+γ)
+//
+//   - for i>0: Z(gⁱ) = Π_{k<i} -------------------------------------------------------
+//     (l(g^k)+β*s1(g^k)+γ)*(r(g^k)+β*s2(g^k)+γ)*(o(g^k)+β*s3(\g^k)+γ)
+//
+//   - lro are the solution in Lagrange basis, evaluated on the small domain
+func computeZCanonical(lro [3][]fr.Element, pk *ProvingKey, beta, gamma fr.Element) []fr.Element {
+
+	nbElmts := int(pk.Domain[0].Cardinality)
+	z := make([]fr.Element, nbElmts)
+	gInv := make([]fr.Element, nbElmts)
+
+	z[0].SetOne()
+	gInv[0].SetOne()
+
+	evaluationIDSmallDomain := getIDSmallDomain(&pk.Domain[0])
+
+	utils.Parallelize(nbElmts-1, func(start, end int) {
+
+		var f [3]fr.Element
+		var g [3]fr.Element
+
+		for i := start; i < end; i++ {
+			for k := range lro {
+				f[k].Mul(&evaluationIDSmallDomain[i+k*nbElmts], &beta).Add(&f[k], &lro[k][i]).Add(&f[k], &gamma)                 // lᵢ+uᵏ*g^i*β+γ
+				g[k].Mul(&evaluationIDSmallDomain[pk.Permutation[i+k*nbElmts]], &beta).Add(&g[k], &lro[k][i]).Add(&g[k], &gamma) // lᵢ+sₖ(g^i)*β+γ
+			}
+			f[0].Mul(&f[0], &f[1]).Mul(&f[0], &f[2])
+			g[0].Mul(&g[0], &g[1]).Mul(&g[0], &g[2])
+
+			gInv[i+1] = g[0]
+			z[i+1] = f[0]
+		}
+	})
+
+	gInv = fr.BatchInvert(gInv)
+	for i := 1; i < nbElmts; i++ {
+		z[i].Mul(&z[i], &z[i-1]).
+			Mul(&z[i], &gInv[i])
+	}
+
+	pk.Domain[0].FFTInverse(z, fft.DIF)
+	fft.BitReverse(z)
+
+	return z
+}
+
+// computeQuotientCanonical computes h in canonical basis, from its evaluations on
+// the coset of the big domain:
+//
+//	h = (ql⋅l + qr⋅r + qm⋅l⋅r + qo⋅o + qk + α⋅ordering + α²⋅L₀⋅(z-1)) / (Xⁿ-1)
+//
+// where qk is completed with the public inputs. It returns false if h is not of
+// degree < 3n, that is if the constraints are not satisfied.
+func computeQuotientCanonical(pk *ProvingKey, canonical *[nbPolynomials][]fr.Element, qkCompleted []fr.Element, alpha, beta, gamma fr.Element) ([]fr.Element, bool) {
+	domain := &pk.Domain[1]
+	n := pk.Domain[0].Cardinality
+	ratio := domain.Cardinality / n
+
+	evaluate := func(p []fr.Element) []fr.Element {
+		res := make([]fr.Element, domain.Cardinality)
+		copy(res, p)
+		domain.FFT(res, fft.DIF, true)
+		fft.BitReverse(res)
+		return res
+	}
+	var e [idH1][]fr.Element
+	utils.Parallelize(idH1, func(start, end int) {
+		for i := start; i < end; i++ {
+			if i == idQk {
+				e[i] = evaluate(qkCompleted)
+			} else {
+				e[i] = evaluate(canonical[i])
+			}
+		}
+	}, idH1)
+
+	// the points x of the coset, 1/(xⁿ-1) which takes ratio values, and L₀(x) = (xⁿ-1)/(n(x-1))
+	x := make([]fr.Element, domain.Cardinality)
+	xMinusOneInv := make([]fr.Element, domain.Cardinality)
+	x[0].Set(&domain.FrMultiplicativeGen)
+	for i := 1; i < len(x); i++ {
+		x[i].Mul(&x[i-1], &domain.Generator)
+	}
+	var one fr.Element
+	one.SetOne()
+	for i := range x {
+		xMinusOneInv[i].Sub(&x[i], &one)
+	}
+	xMinusOneInv = fr.BatchInvert(xMinusOneInv)
+	zh := make([]fr.Element, ratio)
+	bn := new(big.Int).SetUint64(n)
+	for i := range zh {
+		zh[i].Exp(x[i], bn).Sub(&zh[i], &one)
+	}
+	zhInv := fr.BatchInvert(zh)
+
+	h := make([]fr.Element, domain.Cardinality)
+	utils.Parallelize(len(h), func(start, end int) {
+		var gate, tmp, l0 fr.Element
+		for i := start; i < end; i++ {
+			gate.Mul(&e[idQm][i], &e[idR][i]).Add(&gate, &e[idQl][i]).Mul(&gate, &e[idL][i])
+			tmp.Mul(&e[idQr][i], &e[idR][i])
+			gate.Add(&gate, &tmp)
+			tmp.Mul(&e[idQo][i], &e[idO][i])
+			gate.Add(&gate, &tmp).Add(&gate, &e[idQk][i])
+
+			// z(ωx) is the evaluation of z ratio points later on the coset
+			zShifted := e[idZ][(uint64(i)+ratio)%domain.Cardinality]
+			ordering := orderingConstraint(e[idL][i], e[idR][i], e[idO][i], e[idS1][i], e[idS2][i], e[idS3][i], e[idZ][i], zShifted, x[i], beta, gamma, pk.Vk.CosetShift)
+
+			l0.Mul(&zh[uint64(i)%ratio], &xMinusOneInv[i]).Mul(&l0, &pk.Vk.SizeInv)
+			tmp.Sub(&e[idZ][i], &one).Mul(&tmp, &l0)
+
+			h[i].Mul(&tmp, &alpha).Add(&h[i], &ordering).Mul(&h[i], &alpha).Add(&h[i], &gate).Mul(&h[i], &zhInv[uint64(i)%ratio])
+		}
+	})
+
+	domain.FFTInverse(h, fft.DIF, true)
+	fft.BitReverse(h)
+
+	for i := 3 * n; i < uint64(len(h)); i++ {
+		if !h[i].IsZero() {
+			return h, false
+		}
+	}
+	return h, true
+}
+
+// computeDeepQuotient returns the evaluations on the domain of the commitments of
+// the polynomial whose low degree proves the claimed evaluations (see deepQuotient)
+func computeDeepQuotient(pk *ProvingKey, evaluations *[nbPolynomials][]fr.Element, proof *Proof, zeta, zetaShifted, nu fr.Element) []fr.Element {
+	size := int(pk.Domain[2].Cardinality)
+	x := make([]fr.Element, size)
+	x[0].SetOne()
+	for i := 1; i < size; i++ {
+		x[i].Mul(&x[i-1], &pk.Domain[2].Generator)
+	}
+	dZeta := make([]fr.Element, size)
+	dZetaShifted := make([]fr.Element, size)
+	for i := range x {
+		dZeta[i].Sub(&x[i], &zeta)
+		dZetaShifted[i].Sub(&x[i], &zetaShifted)
+	}
+	dZeta = fr.BatchInvert(dZeta)
+	dZetaShifted = fr.BatchInvert(dZetaShifted)
+
+	var nuPowerM fr.Element
+	nuPowerM.Exp(nu, big.NewInt(nbPolynomials))
+
+	res := make([]fr.Element, size)
+	utils.Parallelize(size, func(start, end int) {
+		var tmp fr.Element
+		for i := start; i < end; i++ {
+			for j := nbPolynomials - 1; j >= 0; j-- {
+				tmp.Sub(&evaluations[j][i], &proof.Evaluations[j])
+				res[i].Mul(&res[i], &nu).Add(&res[i], &tmp)
+			}
+			res[i].Mul(&res[i], &dZeta[i])
+			tmp.Sub(&evaluations[idZ][i], &proof.ZShifted).Mul(&tmp, &nuPowerM).Mul(&tmp, &dZetaShifted[i])
+			res[i].Add(&res[i], &tmp)
+		}
+	})
+	return res
+}
+
+// fold returns the evaluations on the domain <ω²> of the folding of f, evaluated
+// on <ω> (see foldPair)
+func fold(f []fr.Element, omega, alpha *fr.Element) []fr.Element {
+	half := len(f) / 2
+	res := make([]fr.Element, half)
+	var omegaInv fr.Element
+	omegaInv.Inverse(omega)
+	utils.Parallelize(half, func(start, end int) {
+		var xInv fr.Element
+		xInv.Exp(omegaInv, big.NewInt(int64(start)))
+		for i := start; i < end; i++ {
+			res[i] = foldPair(&f[i], &f[half+i], &xInv, alpha)
+			xInv.Mul(&xInv, &omegaInv)
+		}
+	})
+	return res
+}
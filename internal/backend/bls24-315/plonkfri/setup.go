@@ -0,0 +1,271 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package plonkfri
+
+import (
+	"errors"
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr/fft"
+	"github.com/consensys/gnark/internal/backend/bls24-315/cs"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/internal/merkle"
+	"github.com/consensys/gnark/metrics"
+)
+
+// ProvingKey stores the data needed to generate a proof:
+// * ql, prepended with as many ones as they are public inputs
+// * qr, qm, qo prepended with as many zeroes as there are public inputs.
+// * qk, prepended with as many zeroes as public inputs, to be completed by the prover
+// with the list of public inputs.
+// * s1, s2, s3, and the copy constraint permutation
+// * the evaluations of these polynomials on the domain of the commitments, and their Merkle tree
+//
+// The ProvingKey is not serialized: Setup is transparent and deterministic, it
+// is computed again from the circuit.
+type ProvingKey struct {
+	// Verifying Key is embedded into the proving key (needed by Prove)
+	Vk *VerifyingKey
+
+	// Q holds ql, qr, qm, qo, qk, s1, s2, s3 in canonical basis (see idQl, ...)
+	Q [idL][]fr.Element
+
+	// LQk is qk in Lagrange basis, prepended with as many zeroes as public inputs.
+	LQk []fr.Element
+
+	// Domains used for the FFTs.
+	// Domain[0] = small Domain
+	// Domain[1] = big Domain, on a coset of which the quotient is computed
+	// Domain[2] = domain of the commitments, of size rho⋅Domain[0].Cardinality
+	Domain [3]fft.Domain
+
+	// position -> permuted position (position in [0,3*sizeSystem-1])
+	Permutation []int64
+
+	// evaluations of Q on Domain[2], and their Merkle tree
+	evaluations [idL][]fr.Element
+	tree        *merkle.Tree
+}
+
+// Setup sets proving and verifying keys. It needs no trusted setup.
+func Setup(spr *cs.SparseR1CS) (_ *ProvingKey, _ *VerifyingKey, err error) {
+	m := metrics.Start(metrics.PhaseSetup, spr.CurveID(), backend.PLONKFRI)
+	defer func() {
+		m.Stop(metrics.Event{Err: err, NbConstraints: len(spr.Constraints), NbWires: spr.NbInternalVariables + spr.NbPublicVariables + spr.NbSecretVariables})
+	}()
+
+	if len(spr.Gates) != 0 || len(spr.Tables) != 0 || spr.Commitment.Is() {
+		return nil, nil, errors.New("plonkfri doesn't support custom gates, lookup tables or commitments")
+	}
+
+	var pk ProvingKey
+	var vk VerifyingKey
+
+	// The verifying key shares data with the proving key
+	pk.Vk = &vk
+
+	nbConstraints := len(spr.Constraints)
+
+	// fft domains
+	sizeSystem := uint64(nbConstraints + spr.NbPublicVariables) // spr.NbPublicVariables is for the placeholder constraints
+	if sizeSystem < 2 {
+		sizeSystem = 2
+	}
+	pk.Domain[0] = *fft.NewDomain(sizeSystem)
+	pk.Vk.CosetShift.Set(&pk.Domain[0].FrMultiplicativeGen)
+
+	// h, the quotient polynomial, is of degree < 3n as the polynomials are not
+	// blinded: 4n evaluations determine h⋅Zₕ
+	pk.Domain[1] = *fft.NewDomain(4 * pk.Domain[0].Cardinality)
+	pk.Domain[2] = *fft.NewDomain(rho * pk.Domain[0].Cardinality)
+
+	vk.Size = pk.Domain[0].Cardinality
+	vk.SizeInv.SetUint64(vk.Size).Inverse(&vk.SizeInv)
+	vk.Generator.Set(&pk.Domain[0].Generator)
+	vk.GeneratorFRI.Set(&pk.Domain[2].Generator)
+	vk.NbPublicVariables = uint64(spr.NbPublicVariables)
+	vk.CircuitFingerprint = spr.Fingerprint()
+
+	// public polynomials corresponding to constraints: [ placholders | constraints | assertions ]
+	for i := idQl; i <= idQk; i++ {
+		pk.Q[i] = make([]fr.Element, pk.Domain[0].Cardinality)
+	}
+	ql, qr, qm, qo, qk := pk.Q[idQl], pk.Q[idQr], pk.Q[idQm], pk.Q[idQo], pk.Q[idQk]
+	for i := 0; i < spr.NbPublicVariables; i++ { // placeholders (-PUB_INPUT_i + qk_i = 0)
+		ql[i].SetOne().Neg(&ql[i])
+	}
+	offset := spr.NbPublicVariables
+	for i := 0; i < nbConstraints; i++ { // constraints
+		ql[offset+i].Set(&spr.Coefficients[spr.Constraints[i].L.CoeffID()])
+		qr[offset+i].Set(&spr.Coefficients[spr.Constraints[i].R.CoeffID()])
+		qm[offset+i].Set(&spr.Coefficients[spr.Constraints[i].M[0].CoeffID()]).
+			Mul(&qm[offset+i], &spr.Coefficients[spr.Constraints[i].M[1].CoeffID()])
+		qo[offset+i].Set(&spr.Coefficients[spr.Constraints[i].O.CoeffID()])
+		qk[offset+i].Set(&spr.Coefficients[spr.Constraints[i].K])
+	}
+	pk.LQk = make([]fr.Element, len(qk))
+	copy(pk.LQk, qk)
+
+	// build permutation. Note: at this stage, the permutation takes in account the placeholders
+	buildPermutation(spr, &pk)
+
+	// s1, s2, s3 in Lagrange basis
+	evaluationIDSmallDomain := getIDSmallDomain(&pk.Domain[0])
+	n := int(pk.Domain[0].Cardinality)
+	for k := 0; k < 3; k++ {
+		s := make([]fr.Element, n)
+		for i := range s {
+			s[i].Set(&evaluationIDSmallDomain[pk.Permutation[k*n+i]])
+		}
+		pk.Q[idS1+k] = s
+	}
+
+	// canonical basis, and evaluations on the domain of the commitments
+	for i := range pk.Q {
+		pk.Domain[0].FFTInverse(pk.Q[i], fft.DIF)
+		fft.BitReverse(pk.Q[i])
+		pk.evaluations[i] = evaluateDomainFRI(pk.Q[i], &pk.Domain[2])
+	}
+	pk.tree = commitEvaluations(pk.evaluations[:])
+	vk.Qpp = pk.tree.Root()
+
+	return &pk, &vk, nil
+}
+
+// buildPermutation builds the Permutation associated with a circuit.
+//
+// The permutation s is composed of cycles of maximum length such that
+//
+//	s. (l∥r∥o) = (l∥r∥o)
+//
+// , where l∥r∥o is the concatenation of the indices of l, r, o in
+// ql.l+qr.r+qm.l.r+qo.O+k = 0.
+//
+// The permutation is encoded as a slice s of size 3*size(l), where the
+// i-th entry of l∥r∥o is sent to the s[i]-th entry, so it acts on a tab
+// like this: for i in tab: tab[i] = tab[permutation[i]]
+func buildPermutation(spr *cs.SparseR1CS, pk *ProvingKey) {
+
+	nbVariables := spr.NbInternalVariables + spr.NbPublicVariables + spr.NbSecretVariables
+	sizeSolution := int(pk.Domain[0].Cardinality)
+
+	// init permutation
+	pk.Permutation = make([]int64, 3*sizeSolution)
+	for i := 0; i < len(pk.Permutation); i++ {
+		pk.Permutation[i] = -1
+	}
+
+	// init LRO position -> variable_ID
+	lro := make([]int, 3*sizeSolution) // position -> variable_ID
+	for i := 0; i < spr.NbPublicVariables; i++ {
+		lro[i] = i // IDs of LRO associated to placeholders (only L needs to be taken care of)
+	}
+
+	offset := spr.NbPublicVariables
+	for i := 0; i < len(spr.Constraints); i++ { // IDs of LRO associated to constraints
+		lro[offset+i] = spr.Constraints[i].L.WireID()
+		lro[sizeSolution+offset+i] = spr.Constraints[i].R.WireID()
+		lro[2*sizeSolution+offset+i] = spr.Constraints[i].O.WireID()
+	}
+
+	// init cycle:
+	// map ID -> last position the ID was seen
+	cycle := make([]int64, nbVariables)
+	for i := 0; i < len(cycle); i++ {
+		cycle[i] = -1
+	}
+
+	for i := 0; i < len(lro); i++ {
+		if cycle[lro[i]] != -1 {
+			// if != -1, it means we already encountered this value
+			// so we need to set the corresponding permutation index.
+			pk.Permutation[i] = cycle[lro[i]]
+		}
+		cycle[lro[i]] = int64(i)
+	}
+
+	// complete the Permutation by filling the first IDs encountered
+	for i := 0; i < len(pk.Permutation); i++ {
+		if pk.Permutation[i] == -1 {
+			pk.Permutation[i] = cycle[lro[i]]
+		}
+	}
+}
+
+// getIDSmallDomain returns the Lagrange form of ID on the small domain
+func getIDSmallDomain(domain *fft.Domain) []fr.Element {
+
+	res := make([]fr.Element, 3*domain.Cardinality)
+
+	res[0].SetOne()
+	res[domain.Cardinality].Set(&domain.FrMultiplicativeGen)
+	res[2*domain.Cardinality].Square(&domain.FrMultiplicativeGen)
+
+	for i := uint64(1); i < domain.Cardinality; i++ {
+		res[i].Mul(&res[i-1], &domain.Generator)
+		res[domain.Cardinality+i].Mul(&res[domain.Cardinality+i-1], &domain.Generator)
+		res[2*domain.Cardinality+i].Mul(&res[2*domain.Cardinality+i-1], &domain.Generator)
+	}
+
+	return res
+}
+
+// evaluateDomainFRI returns the evaluations of p (canonical basis) on the domain
+// of the commitments, in natural order
+func evaluateDomainFRI(p []fr.Element, domain *fft.Domain) []fr.Element {
+	res := make([]fr.Element, domain.Cardinality)
+	copy(res, p)
+	domain.FFT(res, fft.DIF)
+	fft.BitReverse(res)
+	return res
+}
+
+// commitEvaluations returns the Merkle tree of the evaluations of polynomials on
+// the domain of the commitments. Its i-th leaf holds their evaluations at ωⁱ,
+// followed by the ones at -ωⁱ, for i < size/2.
+func commitEvaluations(evaluations [][]fr.Element) *merkle.Tree {
+	half := len(evaluations[0]) / 2
+	leaves := make([][]byte, half)
+	values := make([]fr.Element, 2*len(evaluations))
+	for i := range leaves {
+		for j := range evaluations {
+			values[2*j] = evaluations[j][i]
+			values[2*j+1] = evaluations[j][half+i]
+		}
+		leaves[i] = leafBytes(values)
+	}
+	return merkle.New(leaves)
+}
+
+// open returns the opening of the i-th leaf of the tree of evaluations
+func open(tree *merkle.Tree, evaluations [][]fr.Element, i int) Opening {
+	half := len(evaluations[0]) / 2
+	o := Opening{Values: make([]fr.Element, 2*len(evaluations)), Path: tree.Path(i)}
+	for j := range evaluations {
+		o.Values[2*j] = evaluations[j][i]
+		o.Values[2*j+1] = evaluations[j][half+i]
+	}
+	return o
+}
+
+// VerifyingKey returns pk.Vk
+func (pk *ProvingKey) VerifyingKey() interface{} {
+	return pk.Vk
+}
@@ -0,0 +1,296 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package groth16
+
+import (
+	"errors"
+	"math/big"
+	"math/bits"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+
+	"github.com/consensys/gnark/internal/utils"
+)
+
+// The functions below shard the MSMs and FFTs of the prover across workers
+// (see backend/groth16/distributed). The coordinator runs DistributedMultiExpG1, DistributedMultiExpG2
+// and DistributedFFT, which call the workers for each shard; the workers run MultiExpShardG1,
+// MultiExpShardG2 and FFTShard. Points and field elements are sent in the memory layout of the
+// coordinator, which must be the one of the workers.
+
+var errInvalidShard = errors.New("invalid shard: wrong size")
+
+const frSize = int(unsafe.Sizeof(fr.Element{}))
+
+// MultiExpShardFunc computes on a worker the multi exponentiation of a shard of the points and scalars,
+// and returns the resulting point in jacobian coordinates
+type MultiExpShardFunc func(shard int, points, scalars []byte, scalarsMont bool) ([]byte, error)
+
+// FFTShardFunc computes on a worker the FFTs (or inverse FFTs) of size n of the concatenated polynomials,
+// in natural order. If omega is set, the k-th coefficient of the i-th result is multiplied by ω^((offset+i)k).
+type FFTShardFunc func(shard int, n uint64, inverse bool, omega []byte, offset uint64, polynomials []byte) ([]byte, error)
+
+// DistributedMultiExpG1 sets res to the multi exponentiation of points and scalars, split in
+// nbShards shards computed by call
+func DistributedMultiExpG1(res *curve.G1Jac, points []curve.G1Affine, scalars []fr.Element, scalarsMont bool, nbShards int, call MultiExpShardFunc) error {
+	if len(points) != len(scalars) {
+		return errors.New("len(points) != len(scalars)")
+	}
+	partials, err := callShards(len(points), nbShards, func(shard, start, end int) ([]byte, error) {
+		return call(shard, g1Bytes(points[start:end]), frBytes(scalars[start:end]), scalarsMont)
+	})
+	if err != nil {
+		return err
+	}
+	res.FromAffine(&curve.G1Affine{})
+	for _, b := range partials {
+		var p curve.G1Jac
+		if len(b) != int(unsafe.Sizeof(p)) {
+			return errInvalidShard
+		}
+		copy(unsafe.Slice((*byte)(unsafe.Pointer(&p)), len(b)), b)
+		res.AddAssign(&p)
+	}
+	return nil
+}
+
+// DistributedMultiExpG2 sets res to the multi exponentiation of points and scalars, split in
+// nbShards shards computed by call
+func DistributedMultiExpG2(res *curve.G2Jac, points []curve.G2Affine, scalars []fr.Element, scalarsMont bool, nbShards int, call MultiExpShardFunc) error {
+	if len(points) != len(scalars) {
+		return errors.New("len(points) != len(scalars)")
+	}
+	partials, err := callShards(len(points), nbShards, func(shard, start, end int) ([]byte, error) {
+		return call(shard, g2Bytes(points[start:end]), frBytes(scalars[start:end]), scalarsMont)
+	})
+	if err != nil {
+		return err
+	}
+	res.FromAffine(&curve.G2Affine{})
+	for _, b := range partials {
+		var p curve.G2Jac
+		if len(b) != int(unsafe.Sizeof(p)) {
+			return errInvalidShard
+		}
+		copy(unsafe.Slice((*byte)(unsafe.Pointer(&p)), len(b)), b)
+		res.AddAssign(&p)
+	}
+	return nil
+}
+
+// MultiExpShardG1 computes the multi exponentiation of a shard sent by DistributedMultiExpG1
+func MultiExpShardG1(points, scalars []byte, scalarsMont bool) ([]byte, error) {
+	_points := make([]curve.G1Affine, len(points)/int(unsafe.Sizeof(curve.G1Affine{})))
+	_scalars := make([]fr.Element, len(scalars)/frSize)
+	if len(g1Bytes(_points)) != len(points) || len(frBytes(_scalars)) != len(scalars) {
+		return nil, errInvalidShard
+	}
+	copy(g1Bytes(_points), points)
+	copy(frBytes(_scalars), scalars)
+
+	var res curve.G1Jac
+	if _, err := res.MultiExp(_points, _scalars, ecc.MultiExpConfig{ScalarsMont: scalarsMont}); err != nil {
+		return nil, err
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(&res)), unsafe.Sizeof(res)), nil
+}
+
+// MultiExpShardG2 computes the multi exponentiation of a shard sent by DistributedMultiExpG2
+func MultiExpShardG2(points, scalars []byte, scalarsMont bool) ([]byte, error) {
+	_points := make([]curve.G2Affine, len(points)/int(unsafe.Sizeof(curve.G2Affine{})))
+	_scalars := make([]fr.Element, len(scalars)/frSize)
+	if len(g2Bytes(_points)) != len(points) || len(frBytes(_scalars)) != len(scalars) {
+		return nil, errInvalidShard
+	}
+	copy(g2Bytes(_points), points)
+	copy(frBytes(_scalars), scalars)
+
+	var res curve.G2Jac
+	if _, err := res.MultiExp(_points, _scalars, ecc.MultiExpConfig{ScalarsMont: scalarsMont}); err != nil {
+		return nil, err
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(&res)), unsafe.Sizeof(res)), nil
+}
+
+// DistributedFFT computes domain.FFT(a, decimation, coset), or domain.FFTInverse if inverse is set,
+// with the four-step algorithm: a of size n = n₁n₂ is seen as a n₂×n₁ matrix, whose n₁ columns are
+// transformed (and multiplied by the twiddle factors) by the workers, then its n₂ rows.
+// a is left unmodified if an error occurs.
+func DistributedFFT(domain *fft.Domain, a []fr.Element, decimation fft.Decimation, coset, inverse bool, nbShards int, call FFTShardFunc) error {
+	n := int(domain.Cardinality)
+	if len(a) != n {
+		return errors.New("len(a) != domain cardinality")
+	}
+	n1 := 1 << (bits.TrailingZeros(uint(n)) / 2)
+	n2 := n / n1
+
+	p := make([]fr.Element, n)
+	copy(p, a)
+	if decimation == fft.DIT {
+		fft.BitReverse(p)
+	}
+	omega := domain.Generator
+	if inverse {
+		omega = domain.GeneratorInv
+	} else if coset {
+		scaleByPowers(p, domain.FrMultiplicativeGen)
+	}
+
+	// p[j₁+n₁j₂] → columns[j₁n₂+j₂]
+	columns := make([]fr.Element, n)
+	transpose(columns, p, n2, n1)
+	if err := fftShards(columns, n2, n1, inverse, frBytes([]fr.Element{omega}), nbShards, call); err != nil {
+		return err
+	}
+
+	// columns[j₁n₂+k₂] → p[k₂n₁+j₁]
+	transpose(p, columns, n1, n2)
+	if err := fftShards(p, n1, n2, inverse, nil, nbShards, call); err != nil {
+		return err
+	}
+
+	// p[k₂n₁+k₁] → a[k₂+n₂k₁]
+	transpose(a, p, n2, n1)
+	if inverse && coset {
+		scaleByPowers(a, domain.FrMultiplicativeGenInv)
+	}
+	if decimation == fft.DIF {
+		fft.BitReverse(a)
+	}
+	return nil
+}
+
+// FFTShard computes the FFTs of a shard sent by DistributedFFT
+func FFTShard(n uint64, inverse bool, omega []byte, offset uint64, polynomials []byte) ([]byte, error) {
+	p := make([]fr.Element, len(polynomials)/frSize)
+	if n == 0 || len(frBytes(p)) != len(polynomials) || uint64(len(p))%n != 0 || (omega != nil && len(omega) != frSize) {
+		return nil, errInvalidShard
+	}
+	copy(frBytes(p), polynomials)
+
+	domain := fft.NewDomain(n)
+	if domain.Cardinality != n {
+		return nil, errInvalidShard
+	}
+	var w, wi fr.Element
+	if omega != nil {
+		copy(unsafe.Slice((*byte)(unsafe.Pointer(&w)), frSize), omega)
+		wi.Exp(w, new(big.Int).SetUint64(offset))
+	}
+	for i := uint64(0); i < uint64(len(p)); i += n {
+		q := p[i : i+n]
+		if inverse {
+			domain.FFTInverse(q, fft.DIF)
+		} else {
+			domain.FFT(q, fft.DIF)
+		}
+		fft.BitReverse(q)
+		if omega != nil {
+			scaleByPowers(q, wi)
+			wi.Mul(&wi, &w)
+		}
+	}
+	return frBytes(p), nil
+}
+
+// fftShards calls the workers to compute the FFTs of size n of the nbPolynomials polynomials of p
+func fftShards(p []fr.Element, n, nbPolynomials int, inverse bool, omega []byte, nbShards int, call FFTShardFunc) error {
+	results, err := callShards(nbPolynomials, nbShards, func(shard, start, end int) ([]byte, error) {
+		return call(shard, uint64(n), inverse, omega, uint64(start), frBytes(p[start*n:end*n]))
+	})
+	if err != nil {
+		return err
+	}
+	offset := 0
+	for _, b := range results {
+		if len(b)%frSize != 0 || offset+len(b)/frSize > len(p) {
+			return errInvalidShard
+		}
+		copy(frBytes(p[offset:offset+len(b)/frSize]), b)
+		offset += len(b) / frSize
+	}
+	if offset != len(p) {
+		return errInvalidShard
+	}
+	return nil
+}
+
+// callShards splits [0, n) in at most nbShards intervals and calls f on each of them concurrently
+func callShards(n, nbShards int, f func(shard, start, end int) ([]byte, error)) ([][]byte, error) {
+	if nbShards > n {
+		nbShards = n
+	}
+	if nbShards < 1 {
+		nbShards = 1
+	}
+	results := make([][]byte, nbShards)
+	errs := make(chan error, nbShards)
+	for shard := 0; shard < nbShards; shard++ {
+		go func(shard int) {
+			var err error
+			results[shard], err = f(shard, shard*n/nbShards, (shard+1)*n/nbShards)
+			errs <- err
+		}(shard)
+	}
+	var err error
+	for i := 0; i < nbShards; i++ {
+		if e := <-errs; e != nil && err == nil {
+			err = e
+		}
+	}
+	return results, err
+}
+
+// transpose sets dst to the transpose of the rows×cols matrix src
+func transpose(dst, src []fr.Element, rows, cols int) {
+	utils.Parallelize(rows, func(start, end int) {
+		for i := start; i < end; i++ {
+			for j := 0; j < cols; j++ {
+				dst[j*rows+i] = src[i*cols+j]
+			}
+		}
+	})
+}
+
+// scaleByPowers multiplies a[i] by gⁱ
+func scaleByPowers(a []fr.Element, g fr.Element) {
+	utils.Parallelize(len(a), func(start, end int) {
+		var acc fr.Element
+		acc.Exp(g, big.NewInt(int64(start)))
+		for i := start; i < end; i++ {
+			a[i].Mul(&a[i], &acc)
+			acc.Mul(&acc, &g)
+		}
+	})
+}
+
+func frBytes(s []fr.Element) []byte {
+	if len(s) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(&s[0])), len(s)*int(unsafe.Sizeof(s[0])))
+}
@@ -0,0 +1,307 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package groth16
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	gnarkio "github.com/consensys/gnark/io"
+	"io"
+	"math/big"
+)
+
+var (
+	errLinkFailed = errors.New("the commitments are not linked")
+	errNoOpening  = errors.New("the opening of the commitment is unknown: the proof must be linked by its prover")
+)
+
+// commitmentOpening is the opening of the commitment of a Proof: the values of the
+// committed wires, in increasing wire ID order, and the blinding of the commitment
+type commitmentOpening struct {
+	values   []fr.Element
+	blinding fr.Element
+}
+
+// PedersenKey is a key of Pedersen vector commitments in G1: the commitment to the
+// values x₀, …, xₙ₋₁ with the randomness ρ is Σ xᵢ⋅G[i] + ρ⋅H.
+type PedersenKey struct {
+	G []curve.G1Affine
+	H curve.G1Affine
+}
+
+// NewPedersenKey returns the Pedersen key of nbValues values derived from seed. Its
+// points are hashed to G1, so that nobody knows their discrete logarithms.
+func NewPedersenKey(nbValues int, seed []byte) (*PedersenKey, error) {
+	if nbValues <= 0 {
+		return nil, fmt.Errorf("invalid number of values %d", nbValues)
+	}
+
+	// the i-th point is the sum of the Shallue and van de Woestijne maps of two field
+	// elements expanded from seed and i, with 128 bits of security. The expanded
+	// lengths are rounded to whole SHA-256 blocks, as ecc.ExpandMsgXmd requires.
+	nbBytes := (fp.Bytes + 16 + sha256.Size - 1) / sha256.Size * sha256.Size
+	points := make([]curve.G1Affine, nbValues+1)
+	msg := make([]byte, len(seed)+4)
+	copy(msg, seed)
+	for i := range points {
+		binary.BigEndian.PutUint32(msg[len(seed):], uint32(i))
+		b, err := ecc.ExpandMsgXmd(msg, []byte("gnark-groth16-pedersen"), 2*nbBytes)
+		if err != nil {
+			return nil, err
+		}
+		var u0, u1 fp.Element
+		u0.SetBytes(b[:nbBytes])
+		u1.SetBytes(b[nbBytes:])
+		q0, q1 := curve.MapToCurveG1Svdw(u0), curve.MapToCurveG1Svdw(u1)
+		points[i].Add(&q0, &q1)
+	}
+	return &PedersenKey{G: points[:nbValues], H: points[nbValues]}, nil
+}
+
+// CurveID returns the curveID
+func (key *PedersenKey) CurveID() ecc.ID {
+	return curve.ID
+}
+
+// NbValues returns the number of values committed to with the key
+func (key *PedersenKey) NbValues() int {
+	return len(key.G)
+}
+
+// Commit returns the commitment to values with randomness
+func (key *PedersenKey) Commit(values []*big.Int, randomness *big.Int) (curve.G1Affine, error) {
+	var res curve.G1Affine
+	if len(values) != len(key.G) {
+		return res, fmt.Errorf("invalid number of values, got %d, expected %d", len(values), len(key.G))
+	}
+	scalars := make([]fr.Element, len(values)+1)
+	for i := range values {
+		scalars[i].SetBigInt(values[i])
+	}
+	scalars[len(values)].SetBigInt(randomness)
+	_, err := res.MultiExp(key.points(), scalars, ecc.MultiExpConfig{ScalarsMont: true})
+	return res, err
+}
+
+// points returns G followed by H
+func (key *PedersenKey) points() []curve.G1Affine {
+	return append(key.G[:len(key.G):len(key.G)], key.H)
+}
+
+// LinkProof proves that the commitment of a Proof (see frontend.API.Commit) and an
+// external Pedersen commitment open to the same values. It is a Fiat-Shamir
+// Σ-protocol for the relation
+//
+//	D = Σ xᵢ⋅Basis[i] + v⋅Basis[n]
+//	C = Σ xᵢ⋅G[i] + ρ⋅H
+//
+// where D is proof.Commitment, Basis is vk.CommitmentKey.Basis, and C is the external
+// commitment with the key (G, H): it reveals neither the values xᵢ nor the
+// blindings v and ρ.
+type LinkProof struct {
+	// commitments to the random masks
+	T, TExternal curve.G1Affine
+
+	// responses: the masked values, blinding of D and randomness of C
+	Z                      []fr.Element
+	ZBlinding, ZRandomness fr.Element
+}
+
+// CurveID returns the curveID
+func (link *LinkProof) CurveID() ecc.ID {
+	return curve.ID
+}
+
+// Link returns a LinkProof between the commitment of proof and the external
+// commitment with the key and randomness, using the randomness read from rng
+// (crypto/rand.Reader if rng is nil) for its masks.
+//
+// The proof must have been computed by Prove, which keeps the opening of its
+// commitment: the committed values, in increasing wire ID order, must be the
+// values of the external commitment.
+func Link(proof *Proof, vk *VerifyingKey, key *PedersenKey, commitment *curve.G1Affine, randomness *big.Int, rng io.Reader) (*LinkProof, error) {
+	if err := checkLinkKeys(vk, key); err != nil {
+		return nil, err
+	}
+	if len(proof.opening.values) != len(key.G) {
+		return nil, errNoOpening
+	}
+	n := len(key.G)
+
+	// masks a, b, c
+	masks := make([]fr.Element, n+2)
+	for i := range masks {
+		if err := randomElement(rng, &masks[i]); err != nil {
+			return nil, err
+		}
+	}
+	a, b, c := masks[:n], masks[n], masks[n+1]
+
+	// T = Σ aᵢ⋅Basis[i] + b⋅Basis[n], TExternal = Σ aᵢ⋅G[i] + c⋅H
+	link := &LinkProof{Z: make([]fr.Element, n)}
+	config := ecc.MultiExpConfig{ScalarsMont: true}
+	scalars := make([]fr.Element, n+1)
+	copy(scalars, a)
+	scalars[n] = b
+	if _, err := link.T.MultiExp(vk.CommitmentKey.Basis, scalars, config); err != nil {
+		return nil, err
+	}
+	scalars[n] = c
+	if _, err := link.TExternal.MultiExp(key.points(), scalars, config); err != nil {
+		return nil, err
+	}
+
+	// z = mask + e⋅secret
+	e := link.challenge(proof, vk, key, commitment)
+	var rho, t fr.Element
+	rho.SetBigInt(randomness)
+	for i := 0; i < n; i++ {
+		t.Mul(&e, &proof.opening.values[i])
+		link.Z[i].Add(&a[i], &t)
+	}
+	t.Mul(&e, &proof.opening.blinding)
+	link.ZBlinding.Add(&b, &t)
+	t.Mul(&e, &rho)
+	link.ZRandomness.Add(&c, &t)
+
+	return link, nil
+}
+
+// Verify returns nil if link proves that the commitment of proof and the external
+// commitment with the key open to the same values, that is if
+//
+//	Σ zᵢ⋅Basis[i] + z_v⋅Basis[n] == T + e⋅D
+//	Σ zᵢ⋅G[i] + z_ρ⋅H == TExternal + e⋅C
+//
+// where e is the Fiat-Shamir challenge. The proof itself must be verified with Verify.
+func (link *LinkProof) Verify(proof *Proof, vk *VerifyingKey, key *PedersenKey, commitment *curve.G1Affine) error {
+	if err := checkLinkKeys(vk, key); err != nil {
+		return err
+	}
+	n := len(key.G)
+	if len(link.Z) != n {
+		return fmt.Errorf("invalid link proof size, got %d, expected %d", len(link.Z), n)
+	}
+	if !link.T.IsInSubGroup() || !link.TExternal.IsInSubGroup() || !commitment.IsInSubGroup() {
+		return errCorrectSubgroupCheckFailed
+	}
+
+	e := link.challenge(proof, vk, key, commitment)
+	var eBig big.Int
+	e.ToBigIntRegular(&eBig)
+
+	check := func(points []curve.G1Affine, last *fr.Element, t, c *curve.G1Affine) error {
+		scalars := make([]fr.Element, n+1)
+		copy(scalars, link.Z)
+		scalars[n] = *last
+		var left curve.G1Affine
+		if _, err := left.MultiExp(points, scalars, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+			return err
+		}
+		var right curve.G1Affine
+		right.ScalarMultiplication(c, &eBig)
+		right.Add(&right, t)
+		if !left.Equal(&right) {
+			return errLinkFailed
+		}
+		return nil
+	}
+	if err := check(vk.CommitmentKey.Basis, &link.ZBlinding, &link.T, &proof.Commitment); err != nil {
+		return err
+	}
+	return check(key.points(), &link.ZRandomness, &link.TExternal, commitment)
+}
+
+// challenge returns the Fiat-Shamir challenge of link, a hash of the keys, the
+// commitments and the commitments to the masks
+func (link *LinkProof) challenge(proof *Proof, vk *VerifyingKey, key *PedersenKey, commitment *curve.G1Affine) fr.Element {
+	h := sha256.New()
+	for i := range vk.CommitmentKey.Basis {
+		h.Write(vk.CommitmentKey.Basis[i].Marshal())
+	}
+	for i := range key.G {
+		h.Write(key.G[i].Marshal())
+	}
+	h.Write(key.H.Marshal())
+	h.Write(proof.Commitment.Marshal())
+	h.Write(commitment.Marshal())
+	h.Write(link.T.Marshal())
+	h.Write(link.TExternal.Marshal())
+	var e fr.Element
+	e.SetBytes(h.Sum(nil))
+	return e
+}
+
+// checkLinkKeys returns an error if the commitment of the circuit of vk and the
+// external commitments with key don't commit to the same number of values
+func checkLinkKeys(vk *VerifyingKey, key *PedersenKey) error {
+	if vk.NbCommitments == 0 || len(vk.CommitmentKey.Basis) == 0 {
+		return errors.New("the circuit doesn't commit to any of its wires")
+	}
+	if len(vk.CommitmentKey.Basis) != len(key.G)+1 {
+		return fmt.Errorf("invalid Pedersen key size, got %d, expected %d committed wires", len(key.G), len(vk.CommitmentKey.Basis)-1)
+	}
+	return nil
+}
+
+// WriteTo writes binary encoding of the LinkProof elements to writer
+// points are stored in compressed form T | TExternal | Z | ZBlinding | ZRandomness
+func (link *LinkProof) WriteTo(w io.Writer) (int64, error) {
+	return writeWithHeader(w, gnarkio.KindLinkProof, func(w io.Writer) (int64, error) {
+		enc := curve.NewEncoder(w)
+		toEncode := []interface{}{
+			&link.T,
+			&link.TExternal,
+			link.Z,
+			&link.ZBlinding,
+			&link.ZRandomness,
+		}
+		for _, v := range toEncode {
+			if err := enc.Encode(v); err != nil {
+				return enc.BytesWritten(), err
+			}
+		}
+		return enc.BytesWritten(), nil
+	})
+}
+
+// ReadFrom attempts to decode a LinkProof from reader
+func (link *LinkProof) ReadFrom(r io.Reader) (int64, error) {
+	return readWithHeader(r, gnarkio.KindLinkProof, func(r io.Reader) (int64, error) {
+		dec := curve.NewDecoder(r)
+		toDecode := []interface{}{
+			&link.T,
+			&link.TExternal,
+			&link.Z,
+			&link.ZBlinding,
+			&link.ZRandomness,
+		}
+		for _, v := range toDecode {
+			if err := dec.Decode(v); err != nil {
+				return dec.BytesRead(), err
+			}
+		}
+		return dec.BytesRead(), nil
+	})
+}
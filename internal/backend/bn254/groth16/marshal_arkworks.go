@@ -0,0 +1,199 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package groth16
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+)
+
+var errArkworksCommitment = errors.New("the arkworks encoding doesn't support commitments")
+
+// WriteArkworksTo writes the proof as ark-groth16 serializes it (compressed):
+// [A]1,[B]2,[C]1
+func (proof *Proof) WriteArkworksTo(w io.Writer) (int64, error) {
+	if !proof.Commitment.IsInfinity() {
+		return 0, errArkworksCommitment
+	}
+	var buf []byte
+	buf = appendArkworksG1(buf, &proof.Ar)
+	buf = appendArkworksG2(buf, &proof.Bs)
+	buf = appendArkworksG1(buf, &proof.Krs)
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+// ReadArkworksFrom reads a proof written by WriteArkworksTo
+func (proof *Proof) ReadArkworksFrom(r io.Reader) (int64, error) {
+	dec := arkworksDecoder{r: r}
+	dec.g1(&proof.Ar)
+	dec.g2(&proof.Bs)
+	dec.g1(&proof.Krs)
+	proof.Commitment, proof.CommitmentPok = curve.G1Affine{}, curve.G1Affine{}
+	return dec.n, dec.err
+}
+
+// WriteArkworksTo writes the verifying key as ark-groth16 serializes it
+// (compressed): [α]1,[β]2,[γ]2,[δ]2,uint64(len(Kvk)),[Kvk]1
+func (vk *VerifyingKey) WriteArkworksTo(w io.Writer) (int64, error) {
+	if vk.NbCommitments != 0 {
+		return 0, errArkworksCommitment
+	}
+	var buf []byte
+	buf = appendArkworksG1(buf, &vk.G1.Alpha)
+	buf = appendArkworksG2(buf, &vk.G2.Beta)
+	buf = appendArkworksG2(buf, &vk.G2.Gamma)
+	buf = appendArkworksG2(buf, &vk.G2.Delta)
+	var length [8]byte
+	binary.LittleEndian.PutUint64(length[:], uint64(len(vk.G1.K)))
+	buf = append(buf, length[:]...)
+	for i := range vk.G1.K {
+		buf = appendArkworksG1(buf, &vk.G1.K[i])
+	}
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+// ReadArkworksFrom reads a verifying key written by WriteArkworksTo. As
+// arkworks doesn't store them, [β]1 and [δ]1 are left to zero.
+func (vk *VerifyingKey) ReadArkworksFrom(r io.Reader) (int64, error) {
+	dec := arkworksDecoder{r: r}
+	dec.g1(&vk.G1.Alpha)
+	dec.g2(&vk.G2.Beta)
+	dec.g2(&vk.G2.Gamma)
+	dec.g2(&vk.G2.Delta)
+	nbK := dec.length()
+	vk.G1.K = nil
+	for i := uint64(0); i < nbK && dec.err == nil; i++ {
+		var p curve.G1Affine
+		dec.g1(&p)
+		vk.G1.K = append(vk.G1.K, p)
+	}
+	if dec.err != nil {
+		return dec.n, dec.err
+	}
+	vk.G1.Beta, vk.G1.Delta = curve.G1Affine{}, curve.G1Affine{}
+	vk.NbCommitments = 0
+
+	// recompute vk.e (e(α, β)) and  -[δ]2, -[γ]2
+	return dec.n, vk.Precompute()
+}
+
+// arkworks serializes the coordinates in little-endian, with the flags in the
+// most significant bits of the last byte: 1<<7 if y is lexicographically
+// largest, 1<<6 for the point at infinity. It is the compressed encoding of
+// gnark-crypto, reversed, with other flags.
+const (
+	arkworksLargest  = 0b10 << 6
+	arkworksInfinity = 0b01 << 6
+
+	gnarkCompressedSmallest = 0b10 << 6
+	gnarkCompressedLargest  = 0b11 << 6
+	gnarkCompressedInfinity = 0b01 << 6
+	gnarkMask               = 0b11 << 6
+)
+
+func appendArkworksG1(buf []byte, p *curve.G1Affine) []byte {
+	b := p.Bytes()
+	return appendArkworks(buf, b[:])
+}
+
+func appendArkworksG2(buf []byte, p *curve.G2Affine) []byte {
+	b := p.Bytes()
+	return appendArkworks(buf, b[:])
+}
+
+// appendArkworks appends the arkworks encoding of the compressed point b
+func appendArkworks(buf, b []byte) []byte {
+	flags := b[0] & gnarkMask
+	b[0] &^= gnarkMask
+	for i := len(b) - 1; i >= 0; i-- {
+		buf = append(buf, b[i])
+	}
+	switch flags {
+	case gnarkCompressedLargest:
+		buf[len(buf)-1] |= arkworksLargest
+	case gnarkCompressedInfinity:
+		buf[len(buf)-1] |= arkworksInfinity
+	}
+	return buf
+}
+
+// arkworksDecoder reads points and lengths; after an error, the reads are
+// no-ops and err is set
+type arkworksDecoder struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+func (dec *arkworksDecoder) read(size int) []byte {
+	if dec.err != nil {
+		return nil
+	}
+	buf := make([]byte, size)
+	n, err := io.ReadFull(dec.r, buf)
+	dec.n += int64(n)
+	dec.err = err
+	return buf
+}
+
+// compressed reads an arkworks point of size bytes, in the compressed
+// encoding of gnark-crypto
+func (dec *arkworksDecoder) compressed(size int) []byte {
+	buf := dec.read(size)
+	if dec.err != nil {
+		return nil
+	}
+	flags := buf[size-1] & gnarkMask
+	buf[size-1] &^= gnarkMask
+	res := make([]byte, size)
+	for i := range buf {
+		res[size-1-i] = buf[i]
+	}
+	switch {
+	case flags&arkworksInfinity != 0:
+		res[0] |= gnarkCompressedInfinity
+	case flags&arkworksLargest != 0:
+		res[0] |= gnarkCompressedLargest
+	default:
+		res[0] |= gnarkCompressedSmallest
+	}
+	return res
+}
+
+func (dec *arkworksDecoder) g1(p *curve.G1Affine) {
+	if buf := dec.compressed(curve.SizeOfG1AffineCompressed); buf != nil {
+		_, dec.err = p.SetBytes(buf)
+	}
+}
+
+func (dec *arkworksDecoder) g2(p *curve.G2Affine) {
+	if buf := dec.compressed(curve.SizeOfG2AffineCompressed); buf != nil {
+		_, dec.err = p.SetBytes(buf)
+	}
+}
+
+// length reads the length of a vector
+func (dec *arkworksDecoder) length() uint64 {
+	buf := dec.read(8)
+	if dec.err != nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(buf)
+}
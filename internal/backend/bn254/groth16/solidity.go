@@ -4,7 +4,7 @@ package groth16
 // audit report https://github.com/appliedzkp/semaphore/blob/master/audit/Audit%20Report%20Summary%20for%20Semaphore%20and%20MicroMix.pdf
 // this is an experimental feature and gnark solidity generator as not been thoroughly tested
 const solidityTemplate = `
-{{- $lenK := len .G1.K }}
+{{- $lenK := len .Vk.G1.K }}
 // SPDX-License-Identifier: AML
 // 
 // Copyright 2017 Christian Reitwiessner
@@ -26,7 +26,7 @@ const solidityTemplate = `
 
 // 2019 OKIMS
 
-pragma solidity ^0.8.0;
+pragma solidity {{ .Cfg.PragmaVersion }};
 
 library Pairing {
 
@@ -147,6 +147,152 @@ library Pairing {
 
         return out[0] != 0;
     }
+    {{- if .Cfg.CompressedProofs }}
+
+    // Flags stored in the two most significant bits of a compressed point,
+    // as serialized by gnark-crypto
+    uint256 constant FLAG_MASK = 0xc000000000000000000000000000000000000000000000000000000000000000;
+    uint256 constant FLAG_SMALLEST = 0x8000000000000000000000000000000000000000000000000000000000000000;
+    uint256 constant FLAG_LARGEST = 0xc000000000000000000000000000000000000000000000000000000000000000;
+    uint256 constant FLAG_INFINITY = 0x4000000000000000000000000000000000000000000000000000000000000000;
+
+    // (q + 1) / 4, such that x^EXP_SQRT is a square root of x when it exists
+    uint256 constant EXP_SQRT = 5472060717959818805561601436314318772174077789324455915672259473661306552146;
+    // (q - 1) / 2, a coordinate y is lexicographically largest if y > HALF_Q
+    uint256 constant HALF_Q = 10944121435919637611123202872628637544348155578648911831344518947322613104291;
+
+    // b coefficient of the twist y² = x³ + 3/(u+9), as a0 + a1*u
+    uint256 constant TWIST_B_A0 = 19485874751759354771024239261021720505790618469301721065564631296452457478373;
+    uint256 constant TWIST_B_A1 = 266929791119991161246907387137283842545076965332900288569378510910307636690;
+
+    /*
+     * @return base^exponent mod q, using the modexp precompile
+     */
+    function expmod(uint256 base, uint256 exponent) internal view returns (uint256 r) {
+        uint256 q = PRIME_Q;
+        bool success;
+
+        // solium-disable-next-line security/no-inline-assembly
+        assembly {
+            let p := mload(0x40)
+            mstore(p, 0x20)
+            mstore(add(p, 0x20), 0x20)
+            mstore(add(p, 0x40), 0x20)
+            mstore(add(p, 0x60), base)
+            mstore(add(p, 0x80), exponent)
+            mstore(add(p, 0xa0), q)
+            success := staticcall(sub(gas(), 2000), 5, p, 0xc0, p, 0x20)
+            r := mload(p)
+        }
+
+        require(success, "pairing-expmod-failed");
+    }
+
+    /*
+     * @return A square root of a < q and whether a is a square
+     */
+    function sqrt(uint256 a) internal view returns (uint256 r, bool isSquare) {
+        r = expmod(a, EXP_SQRT);
+        isSquare = mulmod(r, r, PRIME_Q) == a;
+    }
+
+    /*
+     * @return The product (a0 + a1*u)(b0 + b1*u) in F_q[u]/(u² + 1)
+     */
+    function mul2(uint256 a0, uint256 a1, uint256 b0, uint256 b1) internal pure returns (uint256, uint256) {
+        return (
+            addmod(mulmod(a0, b0, PRIME_Q), PRIME_Q - mulmod(a1, b1, PRIME_Q), PRIME_Q),
+            addmod(mulmod(a0, b1, PRIME_Q), mulmod(a1, b0, PRIME_Q), PRIME_Q)
+        );
+    }
+
+    /*
+     * @return A square root x0 + x1*u of a0 + a1*u in F_q[u]/(u² + 1). Reverts
+     *         if a0 + a1*u is not a square.
+     */
+    function sqrt2(uint256 a0, uint256 a1) internal view returns (uint256 x0, uint256 x1) {
+        bool isSquare;
+        if (a1 == 0) {
+            // a0 or -a0 is a square in F_q, since -1 is not
+            (x0, isSquare) = sqrt(a0);
+            if (isSquare) {
+                return (x0, 0);
+            }
+            (x1, isSquare) = sqrt(PRIME_Q - a0);
+            require(isSquare, "pairing-not-on-curve");
+            return (0, x1);
+        }
+
+        // d = sqrt(a0² + a1²), the norm of a0 + a1*u
+        uint256 d;
+        (d, isSquare) = sqrt(addmod(mulmod(a0, a0, PRIME_Q), mulmod(a1, a1, PRIME_Q), PRIME_Q));
+        require(isSquare, "pairing-not-on-curve");
+
+        // x0² = (a0 ± d) / 2, with 1/2 = (q + 1) / 2
+        (x0, isSquare) = sqrt(mulmod(addmod(a0, d, PRIME_Q), HALF_Q + 1, PRIME_Q));
+        if (!isSquare || x0 == 0) {
+            (x0, isSquare) = sqrt(mulmod(addmod(a0, PRIME_Q - d, PRIME_Q), HALF_Q + 1, PRIME_Q));
+            require(isSquare, "pairing-not-on-curve");
+        }
+
+        // x1 = a1 / (2*x0)
+        x1 = mulmod(a1, expmod(addmod(x0, x0, PRIME_Q), PRIME_Q - 2), PRIME_Q);
+
+        (uint256 s0, uint256 s1) = mul2(x0, x1, x0, x1);
+        require(s0 == a0 && s1 == a1, "pairing-not-on-curve");
+    }
+
+    /*
+     * @return The point of G1 whose compressed form is c
+     */
+    function decompressG1(uint256 c) internal view returns (G1Point memory) {
+        uint256 flag = c & FLAG_MASK;
+        uint256 x = c & ~FLAG_MASK;
+        if (flag == FLAG_INFINITY) {
+            require(x == 0, "pairing-invalid-infinity");
+            return G1Point(0, 0);
+        }
+        require(flag == FLAG_SMALLEST || flag == FLAG_LARGEST, "pairing-invalid-compression-flag");
+        require(x < PRIME_Q, "pairing-x-gte-prime-q");
+
+        // y² = x³ + 3
+        (uint256 y, bool isSquare) = sqrt(addmod(mulmod(mulmod(x, x, PRIME_Q), x, PRIME_Q), 3, PRIME_Q));
+        require(isSquare, "pairing-not-on-curve");
+        if ((y > HALF_Q) != (flag == FLAG_LARGEST)) {
+            y = PRIME_Q - y;
+        }
+        return G1Point(x, y);
+    }
+
+    /*
+     * @return The point of G2 whose compressed form is (c1, c0), c1 holding
+     *         the flags and X[0], c0 holding X[1]
+     */
+    function decompressG2(uint256 c1, uint256 c0) internal view returns (G2Point memory) {
+        uint256 flag = c1 & FLAG_MASK;
+        uint256 x1 = c1 & ~FLAG_MASK;
+        uint256 x0 = c0;
+        if (flag == FLAG_INFINITY) {
+            require(x1 == 0 && x0 == 0, "pairing-invalid-infinity");
+            return G2Point([uint256(0), uint256(0)], [uint256(0), uint256(0)]);
+        }
+        require(flag == FLAG_SMALLEST || flag == FLAG_LARGEST, "pairing-invalid-compression-flag");
+        require(x0 < PRIME_Q && x1 < PRIME_Q, "pairing-x-gte-prime-q");
+
+        // y² = x³ + b
+        (uint256 n0, uint256 n1) = mul2(x0, x1, x0, x1);
+        (n0, n1) = mul2(n0, n1, x0, x1);
+        (uint256 y0, uint256 y1) = sqrt2(addmod(n0, TWIST_B_A0, PRIME_Q), addmod(n1, TWIST_B_A1, PRIME_Q));
+
+        // y is lexicographically largest if y1 > (q-1)/2, or y1 = 0 and y0 > (q-1)/2
+        bool largest = y1 == 0 ? y0 > HALF_Q : y1 > HALF_Q;
+        if (largest != (flag == FLAG_LARGEST)) {
+            y0 = (PRIME_Q - y0) % PRIME_Q;
+            y1 = (PRIME_Q - y1) % PRIME_Q;
+        }
+        return G2Point([x1, x0], [y1, y0]);
+    }
+    {{- end }}
 }
 
 contract Verifier {
@@ -156,6 +302,9 @@ contract Verifier {
     uint256 constant SNARK_SCALAR_FIELD = 21888242871839275222246405745257275088548364400416034343698204186575808495617;
     uint256 constant PRIME_Q = 21888242871839275222246405745257275088696311157297823662689037894645226208583;
 
+    // fingerprint of the circuit the verifying key is set up for (see ConstraintSystem.Fingerprint)
+    bytes32 public constant CIRCUIT_FINGERPRINT = 0x{{ printf "%x" .Vk.CircuitFingerprint }};
+
     struct VerifyingKey {
         Pairing.G1Point alfa1;
         Pairing.G2Point beta2;
@@ -171,11 +320,11 @@ contract Verifier {
     }
 
     function verifyingKey() internal pure returns (VerifyingKey memory vk) {
-        vk.alfa1 = Pairing.G1Point(uint256({{.G1.Alpha.X.String}}), uint256({{.G1.Alpha.Y.String}}));
-        vk.beta2 = Pairing.G2Point([uint256({{.G2.Beta.X.A1.String}}), uint256({{.G2.Beta.X.A0.String}})], [uint256({{.G2.Beta.Y.A1.String}}), uint256({{.G2.Beta.Y.A0.String}})]);
-        vk.gamma2 = Pairing.G2Point([uint256({{.G2.Gamma.X.A1.String}}), uint256({{.G2.Gamma.X.A0.String}})], [uint256({{.G2.Gamma.Y.A1.String}}), uint256({{.G2.Gamma.Y.A0.String}})]);
-        vk.delta2 = Pairing.G2Point([uint256({{.G2.Delta.X.A1.String}}), uint256({{.G2.Delta.X.A0.String}})], [uint256({{.G2.Delta.Y.A1.String}}), uint256({{.G2.Delta.Y.A0.String}})]);
-        {{- range $i, $ki := .G1.K }}   
+        vk.alfa1 = Pairing.G1Point(uint256({{.Vk.G1.Alpha.X.String}}), uint256({{.Vk.G1.Alpha.Y.String}}));
+        vk.beta2 = Pairing.G2Point([uint256({{.Vk.G2.Beta.X.A1.String}}), uint256({{.Vk.G2.Beta.X.A0.String}})], [uint256({{.Vk.G2.Beta.Y.A1.String}}), uint256({{.Vk.G2.Beta.Y.A0.String}})]);
+        vk.gamma2 = Pairing.G2Point([uint256({{.Vk.G2.Gamma.X.A1.String}}), uint256({{.Vk.G2.Gamma.X.A0.String}})], [uint256({{.Vk.G2.Gamma.Y.A1.String}}), uint256({{.Vk.G2.Gamma.Y.A0.String}})]);
+        vk.delta2 = Pairing.G2Point([uint256({{.Vk.G2.Delta.X.A1.String}}), uint256({{.Vk.G2.Delta.X.A0.String}})], [uint256({{.Vk.G2.Delta.Y.A1.String}}), uint256({{.Vk.G2.Delta.Y.A0.String}})]);
+        {{- range $i, $ki := .Vk.G1.K }}   
         vk.IC[{{$i}}] = Pairing.G1Point(uint256({{$ki.X.String}}), uint256({{$ki.Y.String}}));
         {{- end}}
     }
@@ -187,8 +336,8 @@ contract Verifier {
     function verifyProof(
         uint256[2] memory a,
         uint256[2][2] memory b,
-        uint256[2] memory c,
-        uint256[{{sub $lenK 1}}] memory input
+        uint256[2] memory c{{- if gt $lenK 1 }},
+        uint256[{{sub $lenK 1}}] memory input{{- end }}
     ) public view returns (bool r) {
 
         Proof memory proof;
@@ -214,11 +363,14 @@ contract Verifier {
         require(proof.C.X < PRIME_Q, "verifier-cX-gte-prime-q");
         require(proof.C.Y < PRIME_Q, "verifier-cY-gte-prime-q");
 
+        {{- if gt $lenK 1 }}
+
         // Make sure that every input is less than the snark scalar field
         for (uint256 i = 0; i < input.length; i++) {
             require(input[i] < SNARK_SCALAR_FIELD,"verifier-gte-snark-scalar-field");
             vk_x = Pairing.plus(vk_x, Pairing.scalar_mul(vk.IC[i + 1], input[i]));
         }
+        {{- end }}
 
         vk_x = Pairing.plus(vk_x, vk.IC[0]);
 
@@ -233,5 +385,24 @@ contract Verifier {
             vk.delta2
         );
     }
+    {{- if .Cfg.CompressedProofs }}
+
+    /*
+     * @returns Whether the compressed proof is valid given the hardcoded
+     *          verifying key above and the public inputs. The proof is
+     *          serialized as by gnark's Proof.WriteTo, without its header:
+     *          compressed[0] is A, compressed[1] and compressed[2] are B and
+     *          compressed[3] is C
+     */
+    function verifyCompressedProof(
+        uint256[4] memory compressed{{- if gt $lenK 1 }},
+        uint256[{{sub $lenK 1}}] memory input{{- end }}
+    ) public view returns (bool r) {
+        Pairing.G1Point memory a = Pairing.decompressG1(compressed[0]);
+        Pairing.G2Point memory b = Pairing.decompressG2(compressed[1], compressed[2]);
+        Pairing.G1Point memory c = Pairing.decompressG1(compressed[3]);
+        return verifyProof([a.X, a.Y], [b.X, b.Y], [c.X, c.Y]{{- if gt $lenK 1 }}, input{{- end }});
+    }
+    {{- end }}
 }
 `
@@ -0,0 +1,221 @@
+package groth16
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/solidity"
+)
+
+func TestExportSolidity(t *testing.T) {
+	export := func(nbPublic int, opts ...solidity.ExportOption) string {
+		var vk VerifyingKey
+		vk.G1.K = make([]curve.G1Affine, nbPublic+1)
+		var buf bytes.Buffer
+		if err := vk.ExportSolidity(&buf, opts...); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+	mustContain := func(contract string, contains bool, s string) {
+		t.Helper()
+		if strings.Contains(contract, s) != contains {
+			t.Fatalf("contract contains %q: %v, expected %v", s, !contains, contains)
+		}
+	}
+
+	contract := export(2)
+	mustContain(contract, true, "pragma solidity ^0.8.0;")
+	mustContain(contract, true, "uint256[2] memory input")
+	mustContain(contract, false, "verifyCompressedProof")
+	mustContain(contract, false, "decompressG1")
+
+	// no public input: zero-length arrays are not valid in solidity
+	contract = export(0)
+	mustContain(contract, false, "memory input\n")
+	mustContain(contract, false, "input.length")
+	mustContain(contract, false, "uint256[0]")
+
+	contract = export(1, solidity.WithPragmaVersion("0.8.17"), solidity.WithCompressedProofs())
+	mustContain(contract, true, "pragma solidity 0.8.17;")
+	mustContain(contract, true, "uint256[4] memory compressed,\n        uint256[1] memory input")
+	mustContain(contract, true, "[c.X, c.Y], input);")
+
+	contract = export(0, solidity.WithCompressedProofs())
+	mustContain(contract, true, "uint256[4] memory compressed\n    )")
+	mustContain(contract, true, "[c.X, c.Y]);")
+
+	var vk VerifyingKey
+	vk.G1.K = make([]curve.G1Affine, 1)
+	if err := vk.ExportSolidity(&bytes.Buffer{}, solidity.WithPragmaVersion("^0.8.0; contract")); err == nil {
+		t.Fatal("expected error with invalid pragma version")
+	}
+}
+
+// solidityDecompressor mirrors the point decompression of the solidity
+// contract, to check it against gnark-crypto.
+type solidityDecompressor struct {
+	q, expSqrt, halfQ, b0, b1 *big.Int
+	mask, smallest, largest   *big.Int
+	infinity                  *big.Int
+}
+
+func newSolidityDecompressor() *solidityDecompressor {
+	parse := func(s string) *big.Int {
+		v, ok := new(big.Int).SetString(s, 0)
+		if !ok {
+			panic(s)
+		}
+		return v
+	}
+	return &solidityDecompressor{
+		q:        parse("21888242871839275222246405745257275088696311157297823662689037894645226208583"),
+		expSqrt:  parse("5472060717959818805561601436314318772174077789324455915672259473661306552146"),
+		halfQ:    parse("10944121435919637611123202872628637544348155578648911831344518947322613104291"),
+		b0:       parse("19485874751759354771024239261021720505790618469301721065564631296452457478373"),
+		b1:       parse("266929791119991161246907387137283842545076965332900288569378510910307636690"),
+		mask:     parse("0xc000000000000000000000000000000000000000000000000000000000000000"),
+		smallest: parse("0x8000000000000000000000000000000000000000000000000000000000000000"),
+		largest:  parse("0xc000000000000000000000000000000000000000000000000000000000000000"),
+		infinity: parse("0x4000000000000000000000000000000000000000000000000000000000000000"),
+	}
+}
+
+func (d *solidityDecompressor) mod(v *big.Int) *big.Int {
+	return v.Mod(v, d.q)
+}
+
+func (d *solidityDecompressor) sqrt(a *big.Int) (*big.Int, bool) {
+	r := new(big.Int).Exp(a, d.expSqrt, d.q)
+	return r, d.mod(new(big.Int).Mul(r, r)).Cmp(a) == 0
+}
+
+func (d *solidityDecompressor) mul2(a0, a1, b0, b1 *big.Int) (*big.Int, *big.Int) {
+	r0 := d.mod(new(big.Int).Sub(new(big.Int).Mul(a0, b0), new(big.Int).Mul(a1, b1)))
+	r1 := d.mod(new(big.Int).Add(new(big.Int).Mul(a0, b1), new(big.Int).Mul(a1, b0)))
+	return r0, r1
+}
+
+func (d *solidityDecompressor) sqrt2(a0, a1 *big.Int) (x0, x1 *big.Int, ok bool) {
+	if a1.Sign() == 0 {
+		if x0, ok = d.sqrt(a0); ok {
+			return x0, new(big.Int), true
+		}
+		x1, ok = d.sqrt(d.mod(new(big.Int).Neg(a0)))
+		return new(big.Int), x1, ok
+	}
+	norm := d.mod(new(big.Int).Add(new(big.Int).Mul(a0, a0), new(big.Int).Mul(a1, a1)))
+	n, ok := d.sqrt(norm)
+	if !ok {
+		return nil, nil, false
+	}
+	half := new(big.Int).Add(d.halfQ, big.NewInt(1))
+	x0, ok = d.sqrt(d.mod(new(big.Int).Mul(new(big.Int).Add(a0, n), half)))
+	if !ok || x0.Sign() == 0 {
+		if x0, ok = d.sqrt(d.mod(new(big.Int).Mul(new(big.Int).Sub(a0, n), half))); !ok {
+			return nil, nil, false
+		}
+	}
+	inv := new(big.Int).Exp(d.mod(new(big.Int).Add(x0, x0)), new(big.Int).Sub(d.q, big.NewInt(2)), d.q)
+	x1 = d.mod(new(big.Int).Mul(a1, inv))
+	s0, s1 := d.mul2(x0, x1, x0, x1)
+	return x0, x1, s0.Cmp(a0) == 0 && s1.Cmp(a1) == 0
+}
+
+func (d *solidityDecompressor) flags(c *big.Int) (flag, x *big.Int) {
+	flag = new(big.Int).And(c, d.mask)
+	x = new(big.Int).AndNot(c, d.mask)
+	return
+}
+
+func (d *solidityDecompressor) decompressG1(t *testing.T, c *big.Int) (x, y *big.Int) {
+	flag, x := d.flags(c)
+	if flag.Cmp(d.infinity) == 0 {
+		return x, new(big.Int)
+	}
+	y2 := d.mod(new(big.Int).Add(new(big.Int).Exp(x, big.NewInt(3), d.q), big.NewInt(3)))
+	y, ok := d.sqrt(y2)
+	if !ok {
+		t.Fatal("not on curve")
+	}
+	if (y.Cmp(d.halfQ) > 0) != (flag.Cmp(d.largest) == 0) {
+		y.Sub(d.q, y)
+	}
+	return x, y
+}
+
+func (d *solidityDecompressor) decompressG2(t *testing.T, c1, c0 *big.Int) (x0, x1, y0, y1 *big.Int) {
+	flag, x1 := d.flags(c1)
+	x0 = c0
+	if flag.Cmp(d.infinity) == 0 {
+		return x0, x1, new(big.Int), new(big.Int)
+	}
+	n0, n1 := d.mul2(x0, x1, x0, x1)
+	n0, n1 = d.mul2(n0, n1, x0, x1)
+	y0, y1, ok := d.sqrt2(d.mod(n0.Add(n0, d.b0)), d.mod(n1.Add(n1, d.b1)))
+	if !ok {
+		t.Fatal("not on curve")
+	}
+	var largest bool
+	if y1.Sign() == 0 {
+		largest = y0.Cmp(d.halfQ) > 0
+	} else {
+		largest = y1.Cmp(d.halfQ) > 0
+	}
+	if largest != (flag.Cmp(d.largest) == 0) {
+		y0 = d.mod(y0.Sub(d.q, y0))
+		y1 = d.mod(y1.Sub(d.q, y1))
+	}
+	return x0, x1, y0, y1
+}
+
+func TestSolidityDecompression(t *testing.T) {
+	d := newSolidityDecompressor()
+	_, _, g1, g2 := curve.Generators()
+
+	for i := 0; i < 20; i++ {
+		var s fr.Element
+		if _, err := s.SetRandom(); err != nil {
+			t.Fatal(err)
+		}
+		var sBig big.Int
+		s.ToBigIntRegular(&sBig)
+		if i == 0 {
+			sBig.SetUint64(0)
+		}
+
+		var p1 curve.G1Affine
+		p1.ScalarMultiplication(&g1, &sBig)
+		b1 := p1.Bytes()
+		x, y := d.decompressG1(t, new(big.Int).SetBytes(b1[:]))
+		if x.Cmp(p1.X.ToBigIntRegular(new(big.Int))) != 0 || y.Cmp(p1.Y.ToBigIntRegular(new(big.Int))) != 0 {
+			t.Fatal("G1 decompression mismatch")
+		}
+
+		var p2 curve.G2Affine
+		p2.ScalarMultiplication(&g2, &sBig)
+		b2 := p2.Bytes()
+		x0, x1, y0, y1 := d.decompressG2(t, new(big.Int).SetBytes(b2[:32]), new(big.Int).SetBytes(b2[32:]))
+		if x0.Cmp(p2.X.A0.ToBigIntRegular(new(big.Int))) != 0 || x1.Cmp(p2.X.A1.ToBigIntRegular(new(big.Int))) != 0 ||
+			y0.Cmp(p2.Y.A0.ToBigIntRegular(new(big.Int))) != 0 || y1.Cmp(p2.Y.A1.ToBigIntRegular(new(big.Int))) != 0 {
+			t.Fatal("G2 decompression mismatch")
+		}
+	}
+
+	// the a1 = 0 branch of the square root in F_q²
+	for _, a0 := range []int64{4, -4, 3, -3} {
+		v := d.mod(big.NewInt(a0))
+		x0, x1, ok := d.sqrt2(v, new(big.Int))
+		if !ok {
+			t.Fatal("expected a square")
+		}
+		s0, s1 := d.mul2(x0, x1, x0, x1)
+		if s0.Cmp(v) != 0 || s1.Sign() != 0 {
+			t.Fatal("wrong square root")
+		}
+	}
+}
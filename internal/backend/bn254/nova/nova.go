@@ -0,0 +1,394 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package nova
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+
+	"github.com/consensys/gnark/internal/backend/bn254/cs"
+
+	bn254witness "github.com/consensys/gnark/internal/backend/bn254/witness"
+
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend/compiled"
+	bn254groth16 "github.com/consensys/gnark/internal/backend/bn254/groth16"
+	"math/big"
+)
+
+var (
+	errCommitment     = errors.New("the folding scheme doesn't support circuits committing to their wires")
+	errNotSatisfied   = errors.New("the relaxed R1CS is not satisfied")
+	errInvalidOpening = errors.New("the witness doesn't open the commitments of the instance")
+	errStateMismatch  = errors.New("the input state of the step is not the output state of the previous step")
+)
+
+// Key is the commitment key of the folding scheme: Pedersen generators for the
+// secret and internal wires, and for the error vector (one per constraint).
+type Key struct {
+	W, E []curve.G1Affine
+}
+
+// NewKey returns the Key of r1cs derived from seed. Its generators are hashed to
+// G1, as the ones of a groth16 PedersenKey, so that nobody knows their discrete
+// logarithms.
+func NewKey(r1cs *cs.R1CS, seed []byte) (*Key, error) {
+	if r1cs.Commitment.Is() {
+		return nil, errCommitment
+	}
+	nbW := r1cs.NbSecretVariables + r1cs.NbInternalVariables
+	pk, err := bn254groth16.NewPedersenKey(nbW+len(r1cs.Constraints), seed)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{W: pk.G[:nbW], E: pk.G[nbW:]}, nil
+}
+
+// CurveID returns the curveID
+func (key *Key) CurveID() ecc.ID {
+	return curve.ID
+}
+
+// Instance is an instance of the relaxed R1CS Az∘Bz = U⋅Cz + E, where
+// z = (U, X, W): the commitments to W and E, and the public inputs X.
+type Instance struct {
+	U    fr.Element
+	W, E curve.G1Affine
+	X    []fr.Element // public inputs, without the constant wire
+}
+
+// Witness is the witness of an Instance: the secret and internal wires W, and the
+// error vector E.
+type Witness struct {
+	W, E []fr.Element
+}
+
+// NewInstance solves r1cs, and returns the Instance and Witness of the solution,
+// with U = 1 and E = 0.
+func NewInstance(r1cs *cs.R1CS, key *Key, fullWitness bn254witness.Witness, opt backend.ProverConfig) (*Instance, *Witness, error) {
+	if len(fullWitness) != int(r1cs.NbPublicVariables-1+r1cs.NbSecretVariables) {
+		return nil, nil, fmt.Errorf("invalid witness size, got %d, expected %d = %d (public) + %d (secret)", len(fullWitness), int(r1cs.NbPublicVariables-1+r1cs.NbSecretVariables), r1cs.NbPublicVariables, r1cs.NbSecretVariables)
+	}
+	a := make([]fr.Element, len(r1cs.Constraints))
+	b := make([]fr.Element, len(r1cs.Constraints))
+	c := make([]fr.Element, len(r1cs.Constraints))
+	wireValues, err := r1cs.Solve(fullWitness, a, b, c, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var instance Instance
+	instance.U.SetOne()
+	instance.X = wireValues[1:r1cs.NbPublicVariables]
+	witness := &Witness{
+		W: wireValues[r1cs.NbPublicVariables:],
+		E: make([]fr.Element, len(r1cs.Constraints)),
+	}
+	if instance.W, err = commit(key.W, witness.W); err != nil {
+		return nil, nil, err
+	}
+	return &instance, witness, nil
+}
+
+// Fold folds the instance i2 and its witness w2 into i1 and w1, and returns the
+// folded instance and witness, and the commitment to the cross term T. With the
+// challenge r derived from i1, i2 and [T]:
+//
+//	U = U₁ + r⋅U₂, X = X₁ + r⋅X₂, W = W₁ + r⋅W₂, E = E₁ + r⋅T + r²⋅E₂
+//
+// where T = Az₁∘Bz₂ + Az₂∘Bz₁ - U₁⋅Cz₂ - U₂⋅Cz₁.
+func Fold(r1cs *cs.R1CS, key *Key, i1 *Instance, w1 *Witness, i2 *Instance, w2 *Witness) (*Instance, *Witness, curve.G1Affine, error) {
+	var commitmentT curve.G1Affine
+	a1, b1, c1 := products(r1cs, i1, w1)
+	a2, b2, c2 := products(r1cs, i2, w2)
+
+	t := make([]fr.Element, len(r1cs.Constraints))
+	var tmp fr.Element
+	for i := range t {
+		t[i].Mul(&a1[i], &b2[i])
+		tmp.Mul(&a2[i], &b1[i])
+		t[i].Add(&t[i], &tmp)
+		tmp.Mul(&i1.U, &c2[i])
+		t[i].Sub(&t[i], &tmp)
+		tmp.Mul(&i2.U, &c1[i])
+		t[i].Sub(&t[i], &tmp)
+	}
+	commitmentT, err := commit(key.E, t)
+	if err != nil {
+		return nil, nil, commitmentT, err
+	}
+
+	instance := FoldInstances(i1, i2, &commitmentT)
+	r := foldingChallenge(i1, i2, &commitmentT)
+	var r2 fr.Element
+	r2.Square(&r)
+	witness := &Witness{
+		W: make([]fr.Element, len(w1.W)),
+		E: make([]fr.Element, len(w1.E)),
+	}
+	for i := range witness.W {
+		witness.W[i].Mul(&r, &w2.W[i]).Add(&witness.W[i], &w1.W[i])
+	}
+	for i := range witness.E {
+		witness.E[i].Mul(&r, &t[i]).Add(&witness.E[i], &w1.E[i])
+		tmp.Mul(&r2, &w2.E[i])
+		witness.E[i].Add(&witness.E[i], &tmp)
+	}
+	return instance, witness, commitmentT, nil
+}
+
+// FoldInstances returns the instance folded by Fold, from the instances and the
+// commitment to the cross term only: it is the folding verifier.
+func FoldInstances(i1, i2 *Instance, commitmentT *curve.G1Affine) *Instance {
+	r := foldingChallenge(i1, i2, commitmentT)
+	var rBig, r2Big big.Int
+	r.ToBigIntRegular(&rBig)
+	r2Big.Mul(&rBig, &rBig).Mod(&r2Big, fr.Modulus())
+
+	res := &Instance{X: make([]fr.Element, len(i1.X))}
+	res.U.Mul(&r, &i2.U).Add(&res.U, &i1.U)
+	for i := range res.X {
+		res.X[i].Mul(&r, &i2.X[i]).Add(&res.X[i], &i1.X[i])
+	}
+	var tmp curve.G1Affine
+	res.W.ScalarMultiplication(&i2.W, &rBig).Add(&res.W, &i1.W)
+	res.E.ScalarMultiplication(commitmentT, &rBig).Add(&res.E, &i1.E)
+	tmp.ScalarMultiplication(&i2.E, &r2Big)
+	res.E.Add(&res.E, &tmp)
+	return res
+}
+
+// Decide returns nil if the witness opens the commitments of the instance, and
+// satisfies the relaxed R1CS Az∘Bz = U⋅Cz + E.
+func Decide(r1cs *cs.R1CS, key *Key, instance *Instance, witness *Witness) error {
+	if len(instance.X) != r1cs.NbPublicVariables-1 || len(witness.W) != len(key.W) || len(witness.E) != len(key.E) || len(key.E) != len(r1cs.Constraints) {
+		return errors.New("invalid instance or witness size")
+	}
+	commitmentW, err := commit(key.W, witness.W)
+	if err != nil {
+		return err
+	}
+	commitmentE, err := commit(key.E, witness.E)
+	if err != nil {
+		return err
+	}
+	if !commitmentW.Equal(&instance.W) || !commitmentE.Equal(&instance.E) {
+		return errInvalidOpening
+	}
+
+	a, b, c := products(r1cs, instance, witness)
+	var left, right fr.Element
+	for i := range a {
+		left.Mul(&a[i], &b[i])
+		right.Mul(&instance.U, &c[i]).Add(&right, &witness.E[i])
+		if !left.Equal(&right) {
+			return fmt.Errorf("%w: constraint %d", errNotSatisfied, i)
+		}
+	}
+	return nil
+}
+
+// products returns Az, Bz and Cz, where z = (U, X, W)
+func products(r1cs *cs.R1CS, instance *Instance, witness *Witness) (a, b, c []fr.Element) {
+	z := make([]fr.Element, 0, 1+len(instance.X)+len(witness.W))
+	z = append(z, instance.U)
+	z = append(z, instance.X...)
+	z = append(z, witness.W...)
+
+	evaluate := func(l compiled.LinearExpression) fr.Element {
+		var res, tmp fr.Element
+		for _, t := range l {
+			cID, vID, _ := t.Unpack()
+			tmp.Mul(&r1cs.Coefficients[cID], &z[vID])
+			res.Add(&res, &tmp)
+		}
+		return res
+	}
+	a = make([]fr.Element, len(r1cs.Constraints))
+	b = make([]fr.Element, len(r1cs.Constraints))
+	c = make([]fr.Element, len(r1cs.Constraints))
+	for i, r := range r1cs.Constraints {
+		a[i], b[i], c[i] = evaluate(r.L), evaluate(r.R), evaluate(r.O)
+	}
+	return
+}
+
+// commit returns Σ values[i]⋅key[i]
+func commit(key []curve.G1Affine, values []fr.Element) (curve.G1Affine, error) {
+	var res curve.G1Affine
+	if len(values) == 0 {
+		return res, nil
+	}
+	_, err := res.MultiExp(key, values, ecc.MultiExpConfig{ScalarsMont: true})
+	return res, err
+}
+
+// foldingChallenge returns the challenge of the fold of i2 into i1, a hash of
+// the instances and of the commitment to the cross term
+func foldingChallenge(i1, i2 *Instance, commitmentT *curve.G1Affine) fr.Element {
+	h := sha256.New()
+	for _, instance := range []*Instance{i1, i2} {
+		b := instance.U.Bytes()
+		h.Write(b[:])
+		h.Write(instance.W.Marshal())
+		h.Write(instance.E.Marshal())
+		for i := range instance.X {
+			b = instance.X[i].Bytes()
+			h.Write(b[:])
+		}
+	}
+	h.Write(commitmentT.Marshal())
+	var r fr.Element
+	r.SetBytes(h.Sum(nil))
+	return r
+}
+
+// Proof is the transcript of the folds of an iterated computation: the instances
+// of its steps, the commitments to the cross terms of their folds, and the folded
+// witness, in clear. It is neither succinct nor zero-knowledge.
+type Proof struct {
+	Steps   []Instance
+	T       []curve.G1Affine
+	Witness Witness
+}
+
+// CurveID returns the curveID
+func (proof *Proof) CurveID() ecc.ID {
+	return curve.ID
+}
+
+// NbSteps returns the number of steps of the computation
+func (proof *Proof) NbSteps() int {
+	return len(proof.Steps)
+}
+
+// Prover folds the steps of an iterated computation. The public
+// inputs of the step circuit are the state before the step followed by the state
+// after it: the input state of a step must be the output state of the previous one.
+type Prover struct {
+	r1cs    *cs.R1CS
+	key     *Key
+	proof   Proof
+	running *Instance
+	witness *Witness
+}
+
+// NewProver returns a Prover of the computation iterating r1cs
+func NewProver(r1cs *cs.R1CS, key *Key) (*Prover, error) {
+	if err := checkStepCircuit(r1cs, key); err != nil {
+		return nil, err
+	}
+	return &Prover{r1cs: r1cs, key: key}, nil
+}
+
+// Step solves the step circuit with fullWitness, and folds it into the
+// computation.
+func (p *Prover) Step(fullWitness bn254witness.Witness, opt backend.ProverConfig) error {
+	instance, witness, err := NewInstance(p.r1cs, p.key, fullWitness, opt)
+	if err != nil {
+		return err
+	}
+	if p.running == nil {
+		p.running, p.witness = instance, witness
+		p.proof.Steps = append(p.proof.Steps, *instance)
+		return nil
+	}
+	if !chained(&p.proof.Steps[len(p.proof.Steps)-1], instance) {
+		return errStateMismatch
+	}
+	running, folded, commitmentT, err := Fold(p.r1cs, p.key, p.running, p.witness, instance, witness)
+	if err != nil {
+		return err
+	}
+	p.running, p.witness = running, folded
+	p.proof.Steps = append(p.proof.Steps, *instance)
+	p.proof.T = append(p.proof.T, commitmentT)
+	return nil
+}
+
+// Proof returns the proof of the steps folded so far
+func (p *Prover) Proof() *Proof {
+	proof := p.proof
+	if p.witness != nil {
+		proof.Witness = *p.witness
+	}
+	return &proof
+}
+
+// Verify returns nil if proof proves the computation iterating r1cs. It folds the
+// instances of the steps, checks that their states are chained, and decides the
+// folded instance with the folded witness.
+func Verify(r1cs *cs.R1CS, key *Key, proof *Proof) error {
+	if err := checkStepCircuit(r1cs, key); err != nil {
+		return err
+	}
+	if len(proof.Steps) == 0 || len(proof.T) != len(proof.Steps)-1 {
+		return errors.New("invalid proof size")
+	}
+	for i := range proof.Steps {
+		if len(proof.Steps[i].X) != r1cs.NbPublicVariables-1 {
+			return errors.New("invalid number of public inputs")
+		}
+		// the steps are fresh instances
+		var zero curve.G1Affine
+		if !proof.Steps[i].U.IsOne() || !proof.Steps[i].E.Equal(&zero) {
+			return errors.New("the steps must be R1CS instances")
+		}
+	}
+	running := &proof.Steps[0]
+	for i := 1; i < len(proof.Steps); i++ {
+		if !chained(&proof.Steps[i-1], &proof.Steps[i]) {
+			return errStateMismatch
+		}
+		running = FoldInstances(running, &proof.Steps[i], &proof.T[i-1])
+	}
+	return Decide(r1cs, key, running, &proof.Witness)
+}
+
+// checkStepCircuit returns an error if r1cs can't be the step circuit of an
+// iterated computation with key
+func checkStepCircuit(r1cs *cs.R1CS, key *Key) error {
+	if r1cs.Commitment.Is() {
+		return errCommitment
+	}
+	if (r1cs.NbPublicVariables-1)%2 != 0 {
+		return errors.New("the public inputs of the step circuit must be the input and output states, of the same size")
+	}
+	if len(key.W) != r1cs.NbSecretVariables+r1cs.NbInternalVariables || len(key.E) != len(r1cs.Constraints) {
+		return errors.New("the key doesn't match the circuit")
+	}
+	return nil
+}
+
+// chained returns true if the input state of next is the output state of prev
+func chained(prev, next *Instance) bool {
+	n := len(prev.X) / 2
+	for i := 0; i < n; i++ {
+		if !prev.X[n+i].Equal(&next.X[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -19,13 +19,21 @@ package plonk
 import (
 	curve "github.com/consensys/gnark-crypto/ecc/bn254"
 
-	"errors"
-	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	"github.com/consensys/gnark/backend"
+	gnarkio "github.com/consensys/gnark/io"
 	"io"
 )
 
 // WriteTo writes binary encoding of Proof to w
 func (proof *Proof) WriteTo(w io.Writer) (int64, error) {
+	return writeWithHeader(w, gnarkio.KindProof, proof.writeTo)
+}
+
+func (proof *Proof) writeTo(w io.Writer) (int64, error) {
 	enc := curve.NewEncoder(w)
 
 	toEncode := []interface{}{
@@ -49,12 +57,45 @@ func (proof *Proof) WriteTo(w io.Writer) (int64, error) {
 		return n + enc.BytesWritten(), err
 	}
 	n2, err := proof.ZShiftedOpening.WriteTo(w)
+	if err != nil {
+		return n + n2 + enc.BytesWritten(), err
+	}
+
+	// lookup argument
+	toEncode = []interface{}{
+		&proof.F,
+		&proof.H1,
+		&proof.H2,
+		&proof.Zl,
+	}
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return n + n2 + enc.BytesWritten(), err
+		}
+	}
+	n3, err := proof.LookupShiftedBatchedProof.WriteTo(w)
+	if err != nil {
+		return n + n2 + n3 + enc.BytesWritten(), err
+	}
 
-	return n + n2 + enc.BytesWritten(), err
+	// commitment
+	err = enc.Encode(&proof.PI2)
+
+	return n + n2 + n3 + enc.BytesWritten(), err
 }
 
 // ReadFrom reads binary representation of Proof from r
 func (proof *Proof) ReadFrom(r io.Reader) (int64, error) {
+	r, version, n, err := gnarkio.ReadHeader(r, ecc.BN254, backend.PLONK, gnarkio.KindProof)
+	if err != nil {
+		return n, err
+	}
+	m, err := proof.readFrom(r, version)
+	return n + m, err
+}
+
+// readFrom reads the encoding of the given version of Proof from r
+func (proof *Proof) readFrom(r io.Reader, version uint8) (int64, error) {
 	dec := curve.NewDecoder(r)
 	toDecode := []interface{}{
 		&proof.LRO[0],
@@ -77,109 +118,50 @@ func (proof *Proof) ReadFrom(r io.Reader) (int64, error) {
 		return n + dec.BytesRead(), err
 	}
 	n2, err := proof.ZShiftedOpening.ReadFrom(r)
-	return n + n2 + dec.BytesRead(), err
-}
-
-// WriteTo writes binary encoding of ProvingKey to w
-func (pk *ProvingKey) WriteTo(w io.Writer) (n int64, err error) {
-	// encode the verifying key
-	n, err = pk.Vk.WriteTo(w)
-	if err != nil {
-		return
-	}
-
-	// fft domains
-	n2, err := pk.Domain[0].WriteTo(w)
-	if err != nil {
-		return
-	}
-	n += n2
-
-	n2, err = pk.Domain[1].WriteTo(w)
-	if err != nil {
-		return
-	}
-	n += n2
-
-	// sanity check len(Permutation) == 3*int(pk.Domain[0].Cardinality)
-	if len(pk.Permutation) != (3 * int(pk.Domain[0].Cardinality)) {
-		return n, errors.New("invalid permutation size, expected 3*domain cardinality")
-	}
-
-	enc := curve.NewEncoder(w)
-	// note: type Polynomial, which is handled by default binary.Write(...) op and doesn't
-	// encode the size (nor does it convert from Montgomery to Regular form)
-	// so we explicitly transmit []fr.Element
-	toEncode := []interface{}{
-		([]fr.Element)(pk.Ql),
-		([]fr.Element)(pk.Qr),
-		([]fr.Element)(pk.Qm),
-		([]fr.Element)(pk.Qo),
-		([]fr.Element)(pk.CQk),
-		([]fr.Element)(pk.LQk),
-		([]fr.Element)(pk.S1Canonical),
-		([]fr.Element)(pk.S2Canonical),
-		([]fr.Element)(pk.S3Canonical),
-		pk.Permutation,
-	}
-
-	for _, v := range toEncode {
-		if err := enc.Encode(v); err != nil {
-			return n + enc.BytesWritten(), err
-		}
-	}
-
-	return n + enc.BytesWritten(), nil
-}
-
-// ReadFrom reads from binary representation in r into ProvingKey
-func (pk *ProvingKey) ReadFrom(r io.Reader) (int64, error) {
-	pk.Vk = &VerifyingKey{}
-	n, err := pk.Vk.ReadFrom(r)
 	if err != nil {
-		return n, err
+		return n + n2 + dec.BytesRead(), err
 	}
 
-	n2, err := pk.Domain[0].ReadFrom(r)
-	n += n2
-	if err != nil {
-		return n, err
+	// the proofs written before the headers end here, without lookup argument
+	// nor commitment
+	if version == 0 {
+		proof.F, proof.H1, proof.H2, proof.Zl = kzg.Digest{}, kzg.Digest{}, kzg.Digest{}, kzg.Digest{}
+		proof.LookupShiftedBatchedProof = kzg.BatchOpeningProof{}
+		proof.PI2 = kzg.Digest{}
+		return n + n2 + dec.BytesRead(), nil
 	}
 
-	n2, err = pk.Domain[1].ReadFrom(r)
-	n += n2
-	if err != nil {
-		return n, err
-	}
-
-	pk.Permutation = make([]int64, 3*pk.Domain[0].Cardinality)
-
-	dec := curve.NewDecoder(r)
-	toDecode := []interface{}{
-		(*[]fr.Element)(&pk.Ql),
-		(*[]fr.Element)(&pk.Qr),
-		(*[]fr.Element)(&pk.Qm),
-		(*[]fr.Element)(&pk.Qo),
-		(*[]fr.Element)(&pk.CQk),
-		(*[]fr.Element)(&pk.LQk),
-		(*[]fr.Element)(&pk.S1Canonical),
-		(*[]fr.Element)(&pk.S2Canonical),
-		(*[]fr.Element)(&pk.S3Canonical),
-		&pk.Permutation,
+	// lookup argument
+	toDecode = []interface{}{
+		&proof.F,
+		&proof.H1,
+		&proof.H2,
+		&proof.Zl,
 	}
-
 	for _, v := range toDecode {
 		if err := dec.Decode(v); err != nil {
-			return n + dec.BytesRead(), err
+			return n + n2 + dec.BytesRead(), err
 		}
 	}
+	n3, err := proof.LookupShiftedBatchedProof.ReadFrom(r)
+	if err != nil {
+		return n + n2 + n3 + dec.BytesRead(), err
+	}
 
-	return n + dec.BytesRead(), nil
-
+	// commitment
+	err = dec.Decode(&proof.PI2)
+	return n + n2 + n3 + dec.BytesRead(), err
 }
 
 // WriteTo writes binary encoding of VerifyingKey to w
-func (vk *VerifyingKey) WriteTo(w io.Writer) (n int64, err error) {
+func (vk *VerifyingKey) WriteTo(w io.Writer) (int64, error) {
+	return writeWithHeader(w, gnarkio.KindVerifyingKey, func(w io.Writer) (int64, error) {
+		return vk.writeTo(w, gnarkio.HeaderVersion)
+	})
+}
+
+// writeTo writes the encoding of the given version of VerifyingKey to w
+func (vk *VerifyingKey) writeTo(w io.Writer, version uint8) (n int64, err error) {
 	enc := curve.NewEncoder(w)
 
 	toEncode := []interface{}{
@@ -187,6 +169,7 @@ func (vk *VerifyingKey) WriteTo(w io.Writer) (n int64, err error) {
 		&vk.SizeInv,
 		&vk.Generator,
 		vk.NbPublicVariables,
+		&vk.CosetShift,
 		&vk.S[0],
 		&vk.S[1],
 		&vk.S[2],
@@ -195,6 +178,24 @@ func (vk *VerifyingKey) WriteTo(w io.Writer) (n int64, err error) {
 		&vk.Qm,
 		&vk.Qo,
 		&vk.Qk,
+		vk.Qcustom,
+	}
+	for i := range vk.CustomGates {
+		toEncode = append(toEncode, vk.CustomGates[i].Coefficients, vk.CustomGates[i].Exponents)
+	}
+	toEncode = append(toEncode, vk.T)
+	if len(vk.T) > 0 {
+		toEncode = append(toEncode, &vk.Qlookup)
+	}
+	toEncode = append(toEncode, uint64(len(vk.CommitmentConstraintIndexes)))
+	if len(vk.CommitmentConstraintIndexes) > 0 {
+		toEncode = append(toEncode, vk.CommitmentConstraintIndexes, &vk.Qcp)
+	}
+	if version >= gnarkio.VersionPlonkTranscriptHash {
+		toEncode = append(toEncode, uint8(vk.TranscriptHash))
+	}
+	if version >= gnarkio.VersionCircuitFingerprint {
+		toEncode = append(toEncode, &vk.CircuitFingerprint)
 	}
 
 	for _, v := range toEncode {
@@ -208,12 +209,31 @@ func (vk *VerifyingKey) WriteTo(w io.Writer) (n int64, err error) {
 
 // ReadFrom reads from binary representation in r into VerifyingKey
 func (vk *VerifyingKey) ReadFrom(r io.Reader) (int64, error) {
+	r, version, n, err := gnarkio.ReadHeader(r, ecc.BN254, backend.PLONK, gnarkio.KindVerifyingKey)
+	if err != nil {
+		return n, err
+	}
+	m, err := vk.readFrom(r, version)
+	return n + m, err
+}
+
+// readFrom reads the encoding of the given version of VerifyingKey from r
+func (vk *VerifyingKey) readFrom(r io.Reader, version uint8) (int64, error) {
 	dec := curve.NewDecoder(r)
 	toDecode := []interface{}{
 		&vk.Size,
 		&vk.SizeInv,
 		&vk.Generator,
 		&vk.NbPublicVariables,
+	}
+	if version > 0 {
+		toDecode = append(toDecode, &vk.CosetShift)
+	} else {
+		// the keys written before the headers used the multiplicative
+		// generator of fr as coset shift
+		vk.CosetShift = fft.NewDomain(1).FrMultiplicativeGen
+	}
+	toDecode = append(toDecode,
 		&vk.S[0],
 		&vk.S[1],
 		&vk.S[2],
@@ -222,7 +242,7 @@ func (vk *VerifyingKey) ReadFrom(r io.Reader) (int64, error) {
 		&vk.Qm,
 		&vk.Qo,
 		&vk.Qk,
-	}
+	)
 
 	for _, v := range toDecode {
 		if err := dec.Decode(v); err != nil {
@@ -230,5 +250,91 @@ func (vk *VerifyingKey) ReadFrom(r io.Reader) (int64, error) {
 		}
 	}
 
+	// the keys written before the headers end here, without custom gates,
+	// lookup tables nor commitment
+	if version == 0 {
+		vk.Qcustom, vk.CustomGates = nil, nil
+		vk.T, vk.Qlookup = nil, kzg.Digest{}
+		vk.CommitmentConstraintIndexes, vk.Qcp = nil, kzg.Digest{}
+		vk.TranscriptHash = backend.TranscriptSHA256
+		vk.CircuitFingerprint = [32]byte{}
+		return dec.BytesRead(), nil
+	}
+
+	if err := dec.Decode(&vk.Qcustom); err != nil {
+		return dec.BytesRead(), err
+	}
+
+	// custom gates; there is one per selector
+	vk.CustomGates = nil
+	if len(vk.Qcustom) > 0 {
+		vk.CustomGates = make([]CustomGate, len(vk.Qcustom))
+		for i := range vk.CustomGates {
+			g := &vk.CustomGates[i]
+			if err := dec.Decode(&g.Coefficients); err != nil {
+				return dec.BytesRead(), err
+			}
+			g.Exponents = make([][3]uint64, len(g.Coefficients))
+			if err := dec.Decode(&g.Exponents); err != nil {
+				return dec.BytesRead(), err
+			}
+		}
+	}
+
+	// lookup tables
+	if err := dec.Decode(&vk.T); err != nil {
+		return dec.BytesRead(), err
+	}
+	if len(vk.T) > 0 {
+		if err := dec.Decode(&vk.Qlookup); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+
+	// commitment
+	var nbCommitments uint64
+	if err := dec.Decode(&nbCommitments); err != nil {
+		return dec.BytesRead(), err
+	}
+	vk.CommitmentConstraintIndexes = nil
+	if nbCommitments > 0 {
+		vk.CommitmentConstraintIndexes = make([]uint64, nbCommitments)
+		if err := dec.Decode(&vk.CommitmentConstraintIndexes); err != nil {
+			return dec.BytesRead(), err
+		}
+		if err := dec.Decode(&vk.Qcp); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+
+	// transcript hash; former keys used the default one
+	vk.TranscriptHash = backend.TranscriptSHA256
+	if version >= gnarkio.VersionPlonkTranscriptHash {
+		var transcriptHash uint8
+		if err := dec.Decode(&transcriptHash); err != nil {
+			return dec.BytesRead(), err
+		}
+		vk.TranscriptHash = backend.TranscriptHash(transcriptHash)
+	}
+
+	// circuit fingerprint
+	vk.CircuitFingerprint = [32]byte{}
+	if version >= gnarkio.VersionCircuitFingerprint {
+		if err := dec.Decode(&vk.CircuitFingerprint); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+
 	return dec.BytesRead(), nil
 }
+
+// writeWithHeader writes the header of an object of the given kind (see
+// gnarkio.WriteHeader), then the object with writeTo
+func writeWithHeader(w io.Writer, kind gnarkio.Kind, writeTo func(io.Writer) (int64, error)) (int64, error) {
+	n, err := gnarkio.WriteHeader(w, ecc.BN254, backend.PLONK, kind)
+	if err != nil {
+		return n, err
+	}
+	m, err := writeTo(w)
+	return n + m, err
+}
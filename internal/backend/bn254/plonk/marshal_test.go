@@ -19,19 +19,22 @@ package plonk
 import (
 	curve "github.com/consensys/gnark-crypto/ecc/bn254"
 
-	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
-
 	"bytes"
-	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 	"reflect"
 	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	gnarkio "github.com/consensys/gnark/io"
 )
 
-func TestProvingKeySerialization(t *testing.T) {
+func TestVerifyingKeySerialization(t *testing.T) {
 	// create a random vk
 	var vk VerifyingKey
 	vk.Size = 42
 	vk.SizeInv = fr.One()
+	vk.CosetShift.SetUint64(5)
 
 	_, _, g1gen, _ := curve.Generators()
 	vk.S[0] = g1gen
@@ -42,44 +45,29 @@ func TestProvingKeySerialization(t *testing.T) {
 	vk.Qm = g1gen
 	vk.Qo = g1gen
 	vk.Qk = g1gen
-	vk.NbPublicVariables = 8000
-
-	// random pk
-	var pk ProvingKey
-	pk.Vk = &vk
-	pk.Domain[0] = *fft.NewDomain(42)
-	pk.Domain[1] = *fft.NewDomain(4 * 42)
-	pk.Ql = make([]fr.Element, pk.Domain[0].Cardinality)
-	pk.Qr = make([]fr.Element, pk.Domain[0].Cardinality)
-	pk.Qm = make([]fr.Element, pk.Domain[0].Cardinality)
-	pk.Qo = make([]fr.Element, pk.Domain[0].Cardinality)
-	pk.CQk = make([]fr.Element, pk.Domain[0].Cardinality)
-	pk.LQk = make([]fr.Element, pk.Domain[0].Cardinality)
-
-	for i := 0; i < 12; i++ {
-		pk.Ql[i].SetOne().Neg(&pk.Ql[i])
-		pk.Qr[i].SetOne()
-		pk.Qo[i].SetUint64(42)
+	vk.Qcustom = []curve.G1Affine{g1gen, g1gen}
+	vk.CustomGates = []CustomGate{
+		{Coefficients: []fr.Element{fr.One()}, Exponents: [][3]uint64{{1, 2, 0}}},
+		{Coefficients: []fr.Element{fr.One(), fr.One()}, Exponents: [][3]uint64{{3, 0, 0}, {0, 0, 1}}},
 	}
-
-	pk.Permutation = make([]int64, 3*pk.Domain[0].Cardinality)
-	pk.Permutation[0] = -12
-	pk.Permutation[len(pk.Permutation)-1] = 8888
+	vk.Qlookup = g1gen
+	vk.T = []curve.G1Affine{g1gen, g1gen, g1gen}
+	vk.TranscriptHash = backend.TranscriptMiMC
 
 	var buf bytes.Buffer
-	written, err := pk.WriteTo(&buf)
+	written, err := vk.WriteTo(&buf)
 	if err != nil {
 		t.Fatal("coudln't serialize", err)
 	}
 
-	var reconstructed ProvingKey
+	var reconstructed VerifyingKey
 
 	read, err := reconstructed.ReadFrom(&buf)
 	if err != nil {
 		t.Fatal("coudln't deserialize", err)
 	}
 
-	if !reflect.DeepEqual(&pk, &reconstructed) {
+	if !reflect.DeepEqual(&vk, &reconstructed) {
 		t.Fatal("reconstructed object don't match original")
 	}
 
@@ -88,40 +76,45 @@ func TestProvingKeySerialization(t *testing.T) {
 	}
 }
 
-func TestVerifyingKeySerialization(t *testing.T) {
-	// create a random vk
+func TestVerifyingKeyFormerEncodings(t *testing.T) {
 	var vk VerifyingKey
 	vk.Size = 42
 	vk.SizeInv = fr.One()
+	vk.CosetShift.SetUint64(7)
 
 	_, _, g1gen, _ := curve.Generators()
 	vk.S[0] = g1gen
-	vk.S[1] = g1gen
-	vk.S[2] = g1gen
 	vk.Ql = g1gen
-	vk.Qr = g1gen
-	vk.Qm = g1gen
-	vk.Qo = g1gen
-	vk.Qk = g1gen
-
-	var buf bytes.Buffer
-	written, err := vk.WriteTo(&buf)
-	if err != nil {
-		t.Fatal("coudln't serialize", err)
-	}
-
-	var reconstructed VerifyingKey
-
-	read, err := reconstructed.ReadFrom(&buf)
-	if err != nil {
-		t.Fatal("coudln't deserialize", err)
-	}
-
-	if !reflect.DeepEqual(&vk, &reconstructed) {
-		t.Fatal("reconstructed object don't match original")
-	}
-
-	if written != read {
-		t.Fatal("bytes written / read don't match")
+	vk.TranscriptHash = backend.TranscriptMiMC
+	vk.CircuitFingerprint[0] = 1
+
+	// the encodings written before the headers are tested against fixtures in
+	// backend/plonk
+	for version := gnarkio.VersionHeader; version <= gnarkio.HeaderVersion; version++ {
+		var buf bytes.Buffer
+		if _, err := gnarkio.WriteHeader(&buf, ecc.BN254, backend.PLONK, gnarkio.KindVerifyingKey); err != nil {
+			t.Fatal(err)
+		}
+		buf.Bytes()[4] = version
+		if _, err := vk.writeTo(&buf, version); err != nil {
+			t.Fatal(err)
+		}
+
+		var reconstructed VerifyingKey
+		if _, err := reconstructed.ReadFrom(&buf); err != nil {
+			t.Fatalf("version %d: couldn't deserialize: %v", version, err)
+		}
+
+		// the fields missing from the former encodings take their former values
+		expected := vk
+		if version < gnarkio.VersionPlonkTranscriptHash {
+			expected.TranscriptHash = backend.TranscriptSHA256
+		}
+		if version < gnarkio.VersionCircuitFingerprint {
+			expected.CircuitFingerprint = [32]byte{}
+		}
+		if !reflect.DeepEqual(&expected, &reconstructed) {
+			t.Fatalf("version %d: reconstructed object don't match original", version)
+		}
 	}
 }
@@ -1,3 +1,6 @@
+//go:build !verifieronly
+// +build !verifieronly
+
 // Copyright 2020 ConsenSys Software Inc.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
@@ -17,13 +20,15 @@
 package plonk
 
 import (
-	"errors"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
 	"github.com/consensys/gnark/internal/backend/bn254/cs"
 
 	kzgg "github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/metrics"
 )
 
 // ProvingKey stores the data needed to generate a proof:
@@ -34,6 +39,9 @@ import (
 // with the list of public inputs.
 // * sigma_1, sigma_2, sigma_3 in both basis
 // * the copy constraint permutation
+// * the selectors of the custom gates
+// * the lookup selector and the columns of the lookup tables
+// * the selector of the committed wires
 type ProvingKey struct {
 	// Verifying Key is embedded into the proving key (needed by Prove)
 	Vk *VerifyingKey
@@ -57,39 +65,42 @@ type ProvingKey struct {
 
 	// position -> permuted position (position in [0,3*sizeSystem-1])
 	Permutation []int64
-}
 
-// VerifyingKey stores the data needed to verify a proof:
-// * The commitment scheme
-// * Commitments of ql prepended with as many ones as there are public inputs
-// * Commitments of qr, qm, qo, qk prepended with as many zeroes as there are public inputs
-// * Commitments to S1, S2, S3
-type VerifyingKey struct {
-	// Size circuit
-	Size              uint64
-	SizeInv           fr.Element
-	Generator         fr.Element
-	NbPublicVariables uint64
-
-	// Commitment scheme that is used for an instantiation of PLONK
-	KZGSRS *kzg.SRS
-
-	// cosetShift generator of the coset on the small domain
-	CosetShift fr.Element
-
-	// S commitments to S1, S2, S3
-	S [3]kzg.Digest
-
-	// Commitments to ql, qr, qm, qo prepended with as many zeroes (ones for l) as there are public inputs.
-	// In particular Qk is not complete.
-	Ql, Qr, Qm, Qo, Qk kzg.Digest
+	// Qcustom[i] (canonical basis) is the selector of the i-th custom gate
+	Qcustom [][]fr.Element
+
+	// Qlookup (canonical basis) is the selector of the lookup constraints, and
+	// T the columns (index, table index, value) of the lookup tables (canonical basis).
+	// Empty if the circuit doesn't use lookup tables.
+	Qlookup []fr.Element
+	T       [][]fr.Element
+
+	// Qcp (canonical basis) is the selector of the constraints on the committed wires.
+	// Empty if the circuit doesn't commit to some of its wires (see frontend.API.Commit).
+	Qcp []fr.Element
+
+	// LagrangeSRS is the Lagrange basis [Lᵢ(τ)]₁ of Domain[0], used to commit to
+	// l, r, o without waiting for their iFFTs. It is NOT serialized; nil unless set
+	// by InitLagrangeSRS or SetLagrangeSRS.
+	LagrangeSRS []curve.G1Affine
 }
 
 // Setup sets proving and verifying keys
-func Setup(spr *cs.SparseR1CS, srs *kzg.SRS) (*ProvingKey, *VerifyingKey, error) {
+func Setup(spr *cs.SparseR1CS, srs *kzg.SRS, opt backend.SetupConfig) (_ *ProvingKey, _ *VerifyingKey, err error) {
+	m := metrics.Start(metrics.PhaseSetup, spr.CurveID(), backend.PLONK)
+	defer func() {
+		m.Stop(metrics.Event{Err: err, NbConstraints: len(spr.Constraints), NbWires: spr.NbInternalVariables + spr.NbPublicVariables + spr.NbSecretVariables})
+	}()
+
 	var pk ProvingKey
 	var vk VerifyingKey
 
+	if _, err := newTranscriptHash(opt.TranscriptHash); err != nil {
+		return nil, nil, err
+	}
+	vk.TranscriptHash = opt.TranscriptHash
+	vk.CircuitFingerprint = spr.Fingerprint()
+
 	// The verifying key shares data with the proving key
 	pk.Vk = &vk
 
@@ -97,6 +108,9 @@ func Setup(spr *cs.SparseR1CS, srs *kzg.SRS) (*ProvingKey, *VerifyingKey, error)
 
 	// fft domains
 	sizeSystem := uint64(nbConstraints + spr.NbPublicVariables) // spr.NbPublicVariables is for the placeholder constraints
+	if nbEntries := uint64(nbTableEntries(spr)); nbEntries > sizeSystem {
+		sizeSystem = nbEntries // the lookup tables must fit in the domain
+	}
 	pk.Domain[0] = *fft.NewDomain(sizeSystem)
 	pk.Vk.CosetShift.Set(&pk.Domain[0].FrMultiplicativeGen)
 
@@ -125,6 +139,17 @@ func Setup(spr *cs.SparseR1CS, srs *kzg.SRS) (*ProvingKey, *VerifyingKey, error)
 	pk.Qo = make([]fr.Element, pk.Domain[0].Cardinality)
 	pk.CQk = make([]fr.Element, pk.Domain[0].Cardinality)
 	pk.LQk = make([]fr.Element, pk.Domain[0].Cardinality)
+	if len(spr.Gates) > 0 {
+		pk.Qcustom = make([][]fr.Element, len(spr.Gates))
+		for i := range pk.Qcustom {
+			pk.Qcustom[i] = make([]fr.Element, pk.Domain[0].Cardinality)
+		}
+	}
+	if len(spr.Tables) > 0 {
+		pk.Qlookup = make([]fr.Element, pk.Domain[0].Cardinality)
+		columns := lookupTables(spr, int(pk.Domain[0].Cardinality))
+		pk.T = columns[:]
+	}
 
 	for i := 0; i < spr.NbPublicVariables; i++ { // placeholders (-PUB_INPUT_i + qk_i = 0) TODO should return error is size is inconsistant
 		pk.Ql[i].SetOne().Neg(&pk.Ql[i])
@@ -144,6 +169,27 @@ func Setup(spr *cs.SparseR1CS, srs *kzg.SRS) (*ProvingKey, *VerifyingKey, error)
 		pk.Qo[offset+i].Set(&spr.Coefficients[spr.Constraints[i].O.CoeffID()])
 		pk.CQk[offset+i].Set(&spr.Coefficients[spr.Constraints[i].K])
 		pk.LQk[offset+i].Set(&spr.Coefficients[spr.Constraints[i].K])
+		if spr.Constraints[i].Gate != 0 {
+			pk.Qcustom[spr.Constraints[i].Gate-1][offset+i].SetOne()
+		}
+		if spr.Constraints[i].Table != 0 {
+			pk.Qlookup[offset+i].SetOne()
+		}
+	}
+
+	// commitment (see frontend.API.Commit): the constraints w - w == 0 on the committed wires become
+	// π₂ - w == 0, and the constraint c - c == 0 on the commitment wire becomes a placeholder -c + qk == 0
+	if spr.Commitment.Is() {
+		pk.Qcp = make([]fr.Element, pk.Domain[0].Cardinality)
+		for _, i := range spr.Commitment.CommittedConstraints {
+			pk.Ql[offset+i].SetOne().Neg(&pk.Ql[offset+i])
+			pk.Qr[offset+i].SetZero()
+			pk.Qcp[offset+i].SetOne()
+		}
+		i := offset + spr.Commitment.CommitmentConstraint
+		pk.Ql[i].SetOne().Neg(&pk.Ql[i])
+		pk.Qr[i].SetZero()
+		vk.CommitmentConstraintIndexes = []uint64{uint64(i)}
 	}
 
 	pk.Domain[0].FFTInverse(pk.Ql, fft.DIF)
@@ -156,6 +202,23 @@ func Setup(spr *cs.SparseR1CS, srs *kzg.SRS) (*ProvingKey, *VerifyingKey, error)
 	fft.BitReverse(pk.Qm)
 	fft.BitReverse(pk.Qo)
 	fft.BitReverse(pk.CQk)
+	for i := range pk.Qcustom {
+		pk.Domain[0].FFTInverse(pk.Qcustom[i], fft.DIF)
+		fft.BitReverse(pk.Qcustom[i])
+	}
+	vk.CustomGates = customGates(spr)
+	if pk.Qcp != nil {
+		pk.Domain[0].FFTInverse(pk.Qcp, fft.DIF)
+		fft.BitReverse(pk.Qcp)
+	}
+	if pk.Qlookup != nil {
+		pk.Domain[0].FFTInverse(pk.Qlookup, fft.DIF)
+		fft.BitReverse(pk.Qlookup)
+		for i := range pk.T {
+			pk.Domain[0].FFTInverse(pk.T[i], fft.DIF)
+			fft.BitReverse(pk.T[i])
+		}
+	}
 
 	// build permutation. Note: at this stage, the permutation takes in account the placeholders
 	buildPermutation(spr, &pk)
@@ -164,7 +227,6 @@ func Setup(spr *cs.SparseR1CS, srs *kzg.SRS) (*ProvingKey, *VerifyingKey, error)
 	ccomputePermutationPolynomials(&pk)
 
 	// Commit to the polynomials to set up the verifying key
-	var err error
 	if vk.Ql, err = kzg.Commit(pk.Ql, vk.KZGSRS); err != nil {
 		return nil, nil, err
 	}
@@ -189,18 +251,59 @@ func Setup(spr *cs.SparseR1CS, srs *kzg.SRS) (*ProvingKey, *VerifyingKey, error)
 	if vk.S[2], err = kzg.Commit(pk.S3Canonical, vk.KZGSRS); err != nil {
 		return nil, nil, err
 	}
+	if len(pk.Qcustom) > 0 {
+		vk.Qcustom = make([]kzg.Digest, len(pk.Qcustom))
+		for i := range pk.Qcustom {
+			if vk.Qcustom[i], err = kzg.Commit(pk.Qcustom[i], vk.KZGSRS); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	if pk.Qcp != nil {
+		if vk.Qcp, err = kzg.Commit(pk.Qcp, vk.KZGSRS); err != nil {
+			return nil, nil, err
+		}
+	}
+	if pk.Qlookup != nil {
+		if vk.Qlookup, err = kzg.Commit(pk.Qlookup, vk.KZGSRS); err != nil {
+			return nil, nil, err
+		}
+		vk.T = make([]kzg.Digest, len(pk.T))
+		for i := range pk.T {
+			if vk.T[i], err = kzg.Commit(pk.T[i], vk.KZGSRS); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
 
 	return &pk, &vk, nil
 
 }
 
+// customGates returns the custom gates of the circuit, with their coefficients resolved
+func customGates(spr *cs.SparseR1CS) []CustomGate {
+	if len(spr.Gates) == 0 {
+		return nil
+	}
+	res := make([]CustomGate, len(spr.Gates))
+	for i, g := range spr.Gates {
+		res[i].Coefficients = make([]fr.Element, len(g.Terms))
+		res[i].Exponents = make([][3]uint64, len(g.Terms))
+		for j, t := range g.Terms {
+			res[i].Coefficients[j].Set(&spr.Coefficients[t.CoeffID])
+			res[i].Exponents[j] = [3]uint64{uint64(t.L), uint64(t.R), uint64(t.O)}
+		}
+	}
+	return res
+}
+
 // buildPermutation builds the Permutation associated with a circuit.
 //
 // The permutation s is composed of cycles of maximum length such that
 //
-// 			s. (l∥r∥o) = (l∥r∥o)
+//	s. (l∥r∥o) = (l∥r∥o)
 //
-//, where l∥r∥o is the concatenation of the indices of l, r, o in
+// , where l∥r∥o is the concatenation of the indices of l, r, o in
 // ql.l+qr.r+qm.l.r+qo.O+k = 0.
 //
 // The permutation is encoded as a slice s of size 3*size(l), where the
@@ -258,11 +361,14 @@ func buildPermutation(spr *cs.SparseR1CS, pk *ProvingKey) {
 // s1, s2, s3.
 //
 // 1	z 	..	z**n-1	|	u	uz	..	u*z**n-1	|	u**2	u**2*z	..	u**2*z**n-1  |
-//  																					 |
-//        																				 | Permutation
+//
+//																						 |
+//	      																				 | Permutation
+//
 // s11  s12 ..   s1n	   s21 s22 	 ..		s2n		     s31 	s32 	..		s3n		 v
 // \---------------/       \--------------------/        \------------------------/
-// 		s1 (LDE)                s2 (LDE)                          s3 (LDE)
+//
+//	s1 (LDE)                s2 (LDE)                          s3 (LDE)
 func ccomputePermutationPolynomials(pk *ProvingKey) {
 
 	nbElmts := int(pk.Domain[0].Cardinality)
@@ -288,7 +394,12 @@ func ccomputePermutationPolynomials(pk *ProvingKey) {
 	fft.BitReverse(pk.S2Canonical)
 	fft.BitReverse(pk.S3Canonical)
 
-	// evaluation of permutation on the big domain
+	computePermutationBigDomain(pk)
+}
+
+// computePermutationBigDomain evaluates the permutation polynomials s1, s2, s3
+// on the big domain (coset), from their canonical form.
+func computePermutationBigDomain(pk *ProvingKey) {
 	pk.EvaluationPermutationBigDomainBitReversed = make([]fr.Element, 3*pk.Domain[1].Cardinality)
 	copy(pk.EvaluationPermutationBigDomainBitReversed, pk.S1Canonical)
 	copy(pk.EvaluationPermutationBigDomainBitReversed[pk.Domain[1].Cardinality:], pk.S2Canonical)
@@ -296,7 +407,6 @@ func ccomputePermutationPolynomials(pk *ProvingKey) {
 	pk.Domain[1].FFT(pk.EvaluationPermutationBigDomainBitReversed[:pk.Domain[1].Cardinality], fft.DIF, true)
 	pk.Domain[1].FFT(pk.EvaluationPermutationBigDomainBitReversed[pk.Domain[1].Cardinality:2*pk.Domain[1].Cardinality], fft.DIF, true)
 	pk.Domain[1].FFT(pk.EvaluationPermutationBigDomainBitReversed[2*pk.Domain[1].Cardinality:], fft.DIF, true)
-
 }
 
 // getIDSmallDomain returns the Lagrange form of ID on the small domain
@@ -322,31 +432,11 @@ func getIDSmallDomain(domain *fft.Domain) []fr.Element {
 // This should be used after deserializing a ProvingKey
 // as pk.Vk.KZG is NOT serialized
 func (pk *ProvingKey) InitKZG(srs kzgg.SRS) error {
+	// the Lagrange SRS, if any, was derived from the previous SRS
+	pk.LagrangeSRS = nil
 	return pk.Vk.InitKZG(srs)
 }
 
-// InitKZG inits vk.KZG using provided SRS
-//
-// This should be used after deserializing a VerifyingKey
-// as vk.KZG is NOT serialized
-//
-// Note that this instantiate a new FFT domain using vk.Size
-func (vk *VerifyingKey) InitKZG(srs kzgg.SRS) error {
-	_srs := srs.(*kzg.SRS)
-
-	if len(_srs.G1) < int(vk.Size) {
-		return errors.New("kzg srs is too small")
-	}
-	vk.KZGSRS = _srs
-
-	return nil
-}
-
-// NbPublicWitness returns the expected public witness size (number of field elements)
-func (vk *VerifyingKey) NbPublicWitness() int {
-	return int(vk.NbPublicVariables)
-}
-
 // VerifyingKey returns pk.Vk
 func (pk *ProvingKey) VerifyingKey() interface{} {
 	return pk.Vk
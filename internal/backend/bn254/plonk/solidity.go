@@ -0,0 +1,496 @@
+package plonk
+
+// MarshalSolidity returns the proof as expected by the verifyProof function of the contract
+// generated by VerifyingKey.ExportSolidity.
+//
+// The points are uncompressed (X | Y, 64 bytes) and the field elements are 32 bytes, big endian:
+//
+//	L | R | O | Z | H[0] | H[1] | H[2] |
+//	BatchedProof.ClaimedValues[0..6] | BatchedProof.H |
+//	ZShiftedOpening.ClaimedValue | ZShiftedOpening.H
+func (proof *Proof) MarshalSolidity() []byte {
+	res := make([]byte, 0, 832)
+
+	for i := 0; i < 3; i++ {
+		res = append(res, proof.LRO[i].Marshal()...)
+	}
+	res = append(res, proof.Z.Marshal()...)
+	for i := 0; i < 3; i++ {
+		res = append(res, proof.H[i].Marshal()...)
+	}
+	for i := 0; i < len(proof.BatchedProof.ClaimedValues); i++ {
+		res = append(res, proof.BatchedProof.ClaimedValues[i].Marshal()...)
+	}
+	res = append(res, proof.BatchedProof.H.Marshal()...)
+	res = append(res, proof.ZShiftedOpening.ClaimedValue.Marshal()...)
+	res = append(res, proof.ZShiftedOpening.H.Marshal()...)
+
+	return res
+}
+
+// solidityTemplate follows the steps of Verify, see the comments there.
+const solidityTemplate = `
+{{- $vk := .Vk }}
+// SPDX-License-Identifier: Apache-2.0
+
+// Copyright 2022 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+pragma solidity {{ .Cfg.PragmaVersion }};
+
+library Pairing {
+
+    uint256 constant P_MOD = 21888242871839275222246405745257275088696311157297823662689037894645226208583;
+
+    struct G1Point {
+        uint256 X;
+        uint256 Y;
+    }
+
+    // Encoding of field elements is: X[0] * z + X[1]
+    struct G2Point {
+        uint256[2] X;
+        uint256[2] Y;
+    }
+
+    /*
+     * @return The negation of p
+     */
+    function negate(G1Point memory p) internal pure returns (G1Point memory) {
+        if (p.X == 0 && p.Y == 0) {
+            return G1Point(0, 0);
+        }
+        return G1Point(p.X, P_MOD - (p.Y % P_MOD));
+    }
+
+    /*
+     * @return The sum of two points of G1
+     */
+    function plus(G1Point memory p1, G1Point memory p2) internal view returns (G1Point memory r) {
+        uint256[4] memory input;
+        input[0] = p1.X;
+        input[1] = p1.Y;
+        input[2] = p2.X;
+        input[3] = p2.Y;
+        bool success;
+
+        // solium-disable-next-line security/no-inline-assembly
+        assembly {
+            success := staticcall(sub(gas(), 2000), 6, input, 0x80, r, 0x40)
+        }
+
+        require(success, "pairing-add-failed");
+    }
+
+    /*
+     * @return The product of a point on G1 and a scalar
+     */
+    function scalar_mul(G1Point memory p, uint256 s) internal view returns (G1Point memory r) {
+        uint256[3] memory input;
+        input[0] = p.X;
+        input[1] = p.Y;
+        input[2] = s;
+        bool success;
+
+        // solium-disable-next-line security/no-inline-assembly
+        assembly {
+            success := staticcall(sub(gas(), 2000), 7, input, 0x60, r, 0x40)
+        }
+
+        require(success, "pairing-mul-failed");
+    }
+
+    /*
+     * @return Whether e(a1, a2) * e(b1, b2) == 1
+     */
+    function pairing(
+        G1Point memory a1,
+        G2Point memory a2,
+        G1Point memory b1,
+        G2Point memory b2
+    ) internal view returns (bool) {
+        uint256[12] memory input = [
+            a1.X, a1.Y, a2.X[0], a2.X[1], a2.Y[0], a2.Y[1],
+            b1.X, b1.Y, b2.X[0], b2.X[1], b2.Y[0], b2.Y[1]
+        ];
+        uint256[1] memory out;
+        bool success;
+
+        // solium-disable-next-line security/no-inline-assembly
+        assembly {
+            success := staticcall(sub(gas(), 2000), 8, input, 0x180, out, 0x20)
+        }
+
+        require(success, "pairing-opcode-failed");
+
+        return out[0] != 0;
+    }
+}
+
+contract PlonkVerifier {
+
+    using Pairing for *;
+
+    uint256 constant R_MOD = 21888242871839275222246405745257275088548364400416034343698204186575808495617;
+    uint256 constant P_MOD = 21888242871839275222246405745257275088696311157297823662689037894645226208583;
+
+    uint256 constant PROOF_SIZE = 832;
+
+    uint256 constant VK_DOMAIN_SIZE = {{ $vk.Size }};
+    uint256 constant VK_INV_DOMAIN_SIZE = {{ fr $vk.SizeInv }};
+    uint256 constant VK_OMEGA = {{ fr $vk.Generator }};
+    uint256 constant VK_COSET_SHIFT = {{ fr $vk.CosetShift }};
+    uint256 constant VK_NB_PUBLIC_INPUTS = {{ $vk.NbPublicVariables }};
+
+    // fingerprint of the circuit the verifying key is set up for (see ConstraintSystem.Fingerprint)
+    bytes32 public constant CIRCUIT_FINGERPRINT = 0x{{ printf "%x" $vk.CircuitFingerprint }};
+
+    struct VerifyingKey {
+        Pairing.G1Point[3] s;
+        Pairing.G1Point ql;
+        Pairing.G1Point qr;
+        Pairing.G1Point qm;
+        Pairing.G1Point qo;
+        Pairing.G1Point qk;
+        Pairing.G1Point g1;
+        Pairing.G2Point g2;
+        Pairing.G2Point g2Alpha;
+    }
+
+    struct Proof {
+        Pairing.G1Point[3] lro;
+        Pairing.G1Point z;
+        Pairing.G1Point[3] h;
+        // quotient, linearized polynomial, l, r, o, s1, s2 at zeta
+        uint256[7] claimedValues;
+        Pairing.G1Point batchedH;
+        // z at omega * zeta
+        uint256 zu;
+        Pairing.G1Point zShiftedH;
+    }
+
+    struct State {
+        // Fiat-Shamir challenges
+        uint256 gamma;
+        uint256 beta;
+        uint256 alpha;
+        uint256 zeta;
+        // zeta^n - 1
+        uint256 zh;
+        // L_1(zeta)
+        uint256 lagrangeOne;
+        // alpha^2 * L_1(zeta)
+        uint256 alphaSquareLagrange;
+        // public inputs polynomial at zeta
+        uint256 pi;
+        Pairing.G1Point foldedH;
+        Pairing.G1Point linearizedPolynomialDigest;
+        Pairing.G1Point foldedDigest;
+        uint256 foldedEval;
+    }
+
+    function verifyingKey() internal pure returns (VerifyingKey memory vk) {
+        {{- range $i, $s := $vk.S }}
+        vk.s[{{ $i }}] = Pairing.G1Point(uint256({{ $s.X.String }}), uint256({{ $s.Y.String }}));
+        {{- end }}
+        vk.ql = Pairing.G1Point(uint256({{ $vk.Ql.X.String }}), uint256({{ $vk.Ql.Y.String }}));
+        vk.qr = Pairing.G1Point(uint256({{ $vk.Qr.X.String }}), uint256({{ $vk.Qr.Y.String }}));
+        vk.qm = Pairing.G1Point(uint256({{ $vk.Qm.X.String }}), uint256({{ $vk.Qm.Y.String }}));
+        vk.qo = Pairing.G1Point(uint256({{ $vk.Qo.X.String }}), uint256({{ $vk.Qo.Y.String }}));
+        vk.qk = Pairing.G1Point(uint256({{ $vk.Qk.X.String }}), uint256({{ $vk.Qk.Y.String }}));
+        {{- $g1 := index $vk.KZGSRS.G1 0 }}
+        {{- $g2 := index $vk.KZGSRS.G2 0 }}
+        {{- $g2Alpha := index $vk.KZGSRS.G2 1 }}
+        vk.g1 = Pairing.G1Point(uint256({{ $g1.X.String }}), uint256({{ $g1.Y.String }}));
+        vk.g2 = Pairing.G2Point([uint256({{ $g2.X.A1.String }}), uint256({{ $g2.X.A0.String }})], [uint256({{ $g2.Y.A1.String }}), uint256({{ $g2.Y.A0.String }})]);
+        vk.g2Alpha = Pairing.G2Point([uint256({{ $g2Alpha.X.A1.String }}), uint256({{ $g2Alpha.X.A0.String }})], [uint256({{ $g2Alpha.Y.A1.String }}), uint256({{ $g2Alpha.Y.A0.String }})]);
+    }
+
+    /*
+     * @returns Whether the proof, serialized as by gnark's Proof.MarshalSolidity,
+     *          is valid given the hardcoded verifying key above and the public inputs
+     */
+    function verifyProof(bytes memory proofBytes, uint256[] memory input) public view returns (bool) {
+        require(input.length == VK_NB_PUBLIC_INPUTS, "verifier-invalid-nb-public-inputs");
+        for (uint256 i = 0; i < input.length; i++) {
+            require(input[i] < R_MOD, "verifier-gte-snark-scalar-field");
+        }
+
+        VerifyingKey memory vk = verifyingKey();
+        Proof memory proof = parseProof(proofBytes);
+        State memory state;
+
+        deriveChallenges(vk, proof, state, input);
+        computePublicInputs(state, input);
+
+        if (computeQuotient(proof, state) != proof.claimedValues[0]) {
+            return false;
+        }
+
+        foldH(proof, state);
+        computeLinearizedPolynomialDigest(vk, proof, state);
+        foldDigests(vk, proof, state);
+
+        return batchVerify(vk, proof, state);
+    }
+
+    function word(bytes memory b, uint256 offset) internal pure returns (uint256 r) {
+        // solium-disable-next-line security/no-inline-assembly
+        assembly {
+            r := mload(add(add(b, 0x20), offset))
+        }
+    }
+
+    function point(bytes memory b, uint256 offset) internal pure returns (Pairing.G1Point memory p) {
+        p.X = word(b, offset);
+        p.Y = word(b, offset + 0x20);
+        require(p.X < P_MOD && p.Y < P_MOD, "verifier-proof-gte-prime-q");
+    }
+
+    function scalar(bytes memory b, uint256 offset) internal pure returns (uint256 s) {
+        s = word(b, offset);
+        require(s < R_MOD, "verifier-proof-gte-snark-scalar-field");
+    }
+
+    function parseProof(bytes memory b) internal pure returns (Proof memory proof) {
+        require(b.length == PROOF_SIZE, "verifier-invalid-proof-size");
+        for (uint256 i = 0; i < 3; i++) {
+            proof.lro[i] = point(b, i * 0x40);
+            proof.h[i] = point(b, 0x100 + i * 0x40);
+        }
+        proof.z = point(b, 0xc0);
+        for (uint256 i = 0; i < 7; i++) {
+            proof.claimedValues[i] = scalar(b, 0x1c0 + i * 0x20);
+        }
+        proof.batchedH = point(b, 0x2a0);
+        proof.zu = scalar(b, 0x2e0);
+        proof.zShiftedH = point(b, 0x300);
+    }
+
+    /*
+     * @return base^exponent mod r, using the modexp precompile
+     */
+    function expmod(uint256 base, uint256 exponent) internal view returns (uint256 r) {
+        uint256 q = R_MOD;
+        bool success;
+
+        // solium-disable-next-line security/no-inline-assembly
+        assembly {
+            let p := mload(0x40)
+            mstore(p, 0x20)
+            mstore(add(p, 0x20), 0x20)
+            mstore(add(p, 0x40), 0x20)
+            mstore(add(p, 0x60), base)
+            mstore(add(p, 0x80), exponent)
+            mstore(add(p, 0xa0), q)
+            success := staticcall(sub(gas(), 2000), 5, p, 0xc0, p, 0x20)
+            r := mload(p)
+        }
+
+        require(success, "verifier-expmod-failed");
+    }
+
+    function inverse(uint256 x) internal view returns (uint256) {
+        return expmod(x, R_MOD - 2);
+    }
+
+    function bind(bytes memory b, Pairing.G1Point memory p) internal pure returns (bytes memory) {
+        return abi.encodePacked(b, p.X, p.Y);
+    }
+
+    /*
+     * The challenges are sha256(name || previous challenge || bindings) mod r,
+     * as in gnark-crypto's fiat-shamir transcript
+     */
+    function deriveChallenges(
+        VerifyingKey memory vk,
+        Proof memory proof,
+        State memory state,
+        uint256[] memory input
+    ) internal view {
+        Pairing.G1Point[8] memory publicData = [vk.s[0], vk.s[1], vk.s[2], vk.ql, vk.qr, vk.qm, vk.qo, vk.qk];
+        bytes memory b = "gamma";
+        for (uint256 i = 0; i < 8; i++) {
+            b = bind(b, publicData[i]);
+        }
+        bytes32 gamma = sha256(abi.encodePacked(b, input));
+
+        bytes32 beta = sha256(abi.encodePacked("beta", gamma));
+
+        bytes32 alpha = sha256(bind(abi.encodePacked("alpha", beta), proof.z));
+
+        b = abi.encodePacked("zeta", alpha);
+        for (uint256 i = 0; i < 3; i++) {
+            b = bind(b, proof.h[i]);
+        }
+        bytes32 zeta = sha256(b);
+
+        state.gamma = uint256(gamma) % R_MOD;
+        state.beta = uint256(beta) % R_MOD;
+        state.alpha = uint256(alpha) % R_MOD;
+        state.zeta = uint256(zeta) % R_MOD;
+    }
+
+    /*
+     * Computes PI(zeta) = Σ L_i(zeta) * input[i], with
+     * L_i(zeta) = omega^i / n * (zeta^n - 1) / (zeta - omega^i)
+     */
+    function computePublicInputs(State memory state, uint256[] memory input) internal view {
+        state.zh = addmod(expmod(state.zeta, VK_DOMAIN_SIZE), R_MOD - 1, R_MOD);
+        uint256 c = mulmod(state.zh, VK_INV_DOMAIN_SIZE, R_MOD);
+
+        state.lagrangeOne = mulmod(c, inverse(addmod(state.zeta, R_MOD - 1, R_MOD)), R_MOD);
+
+        uint256 w = 1;
+        uint256 pi = 0;
+        for (uint256 i = 0; i < input.length; i++) {
+            uint256 li = mulmod(mulmod(c, w, R_MOD), inverse(addmod(state.zeta, R_MOD - w, R_MOD)), R_MOD);
+            pi = addmod(pi, mulmod(li, input[i], R_MOD), R_MOD);
+            w = mulmod(w, VK_OMEGA, R_MOD);
+        }
+        state.pi = pi;
+    }
+
+    /*
+     * @return (linearizedpolynomial(zeta) + pi(zeta) +
+     *          alpha*(Z(omega*zeta))*(l(zeta)+beta*s1(zeta)+gamma)*(r(zeta)+beta*s2(zeta)+gamma)*(o(zeta)+gamma) -
+     *          alpha²*L_1(zeta)) / (zeta^n - 1)
+     */
+    function computeQuotient(Proof memory proof, State memory state) internal view returns (uint256) {
+        uint256[7] memory v = proof.claimedValues;
+        uint256 beta = state.beta;
+        uint256 gamma = state.gamma;
+
+        uint256 t = addmod(addmod(mulmod(v[5], beta, R_MOD), v[2], R_MOD), gamma, R_MOD);
+        t = mulmod(t, addmod(addmod(mulmod(v[6], beta, R_MOD), v[3], R_MOD), gamma, R_MOD), R_MOD);
+        t = mulmod(t, addmod(v[4], gamma, R_MOD), R_MOD);
+        t = mulmod(mulmod(t, state.alpha, R_MOD), proof.zu, R_MOD);
+
+        state.alphaSquareLagrange = mulmod(mulmod(state.lagrangeOne, state.alpha, R_MOD), state.alpha, R_MOD);
+
+        t = addmod(addmod(v[1], state.pi, R_MOD), t, R_MOD);
+        t = addmod(t, R_MOD - state.alphaSquareLagrange, R_MOD);
+
+        return mulmod(t, inverse(state.zh), R_MOD);
+    }
+
+    /*
+     * Computes Comm(h1) + zeta^(n+2)*Comm(h2) + zeta^(2(n+2))*Comm(h3)
+     */
+    function foldH(Proof memory proof, State memory state) internal view {
+        uint256 zetaNPlusTwo = expmod(state.zeta, VK_DOMAIN_SIZE + 2);
+        Pairing.G1Point memory folded = Pairing.scalar_mul(proof.h[2], zetaNPlusTwo);
+        folded = Pairing.plus(folded, proof.h[1]);
+        folded = Pairing.scalar_mul(folded, zetaNPlusTwo);
+        state.foldedH = Pairing.plus(folded, proof.h[0]);
+    }
+
+    /*
+     * Computes the commitment to the linearized polynomial
+     *     l(zeta)*ql + r(zeta)*qr + r(zeta)l(zeta)*qm + o(zeta)*qo + qk +
+     *     alpha*Z(omega*zeta)*(l(zeta)+beta*s1(zeta)+gamma)*(r(zeta)+beta*s2(zeta)+gamma)*beta*s3 +
+     *     (alpha²*L_1(zeta) - alpha*(l(zeta)+beta*zeta+gamma)*(r(zeta)+beta*u*zeta+gamma)*(o(zeta)+beta*u²*zeta+gamma))*Z
+     */
+    function computeLinearizedPolynomialDigest(VerifyingKey memory vk, Proof memory proof, State memory state) internal view {
+        uint256[7] memory v = proof.claimedValues;
+        uint256 beta = state.beta;
+        uint256 gamma = state.gamma;
+
+        Pairing.G1Point memory acc = Pairing.scalar_mul(vk.ql, v[2]);
+        acc = Pairing.plus(acc, Pairing.scalar_mul(vk.qr, v[3]));
+        acc = Pairing.plus(acc, Pairing.scalar_mul(vk.qm, mulmod(v[2], v[3], R_MOD)));
+        acc = Pairing.plus(acc, Pairing.scalar_mul(vk.qo, v[4]));
+        acc = Pairing.plus(acc, vk.qk);
+
+        uint256 s = mulmod(proof.zu, beta, R_MOD);
+        s = mulmod(s, addmod(addmod(mulmod(beta, v[5], R_MOD), v[2], R_MOD), gamma, R_MOD), R_MOD);
+        s = mulmod(s, addmod(addmod(mulmod(beta, v[6], R_MOD), v[3], R_MOD), gamma, R_MOD), R_MOD);
+        s = mulmod(s, state.alpha, R_MOD);
+        acc = Pairing.plus(acc, Pairing.scalar_mul(vk.s[2], s));
+
+        uint256 betaZeta = mulmod(beta, state.zeta, R_MOD);
+        s = addmod(addmod(betaZeta, v[2], R_MOD), gamma, R_MOD);
+        betaZeta = mulmod(betaZeta, VK_COSET_SHIFT, R_MOD);
+        s = mulmod(s, addmod(addmod(betaZeta, v[3], R_MOD), gamma, R_MOD), R_MOD);
+        betaZeta = mulmod(betaZeta, VK_COSET_SHIFT, R_MOD);
+        s = mulmod(s, addmod(addmod(betaZeta, v[4], R_MOD), gamma, R_MOD), R_MOD);
+        s = mulmod(s, state.alpha, R_MOD);
+        s = addmod(R_MOD - s, state.alphaSquareLagrange, R_MOD);
+        acc = Pairing.plus(acc, Pairing.scalar_mul(proof.z, s));
+
+        state.linearizedPolynomialDigest = acc;
+    }
+
+    /*
+     * Folds the digests opened at zeta, and their claimed values, with the
+     * powers of nu = sha256("gamma" || zeta || digests) mod r, as kzg.FoldProof
+     */
+    function foldDigests(VerifyingKey memory vk, Proof memory proof, State memory state) internal view {
+        Pairing.G1Point[7] memory digests = [
+            state.foldedH,
+            state.linearizedPolynomialDigest,
+            proof.lro[0],
+            proof.lro[1],
+            proof.lro[2],
+            vk.s[0],
+            vk.s[1]
+        ];
+
+        bytes memory bindings = abi.encodePacked("gamma", state.zeta);
+        for (uint256 i = 0; i < 7; i++) {
+            bindings = bind(bindings, digests[i]);
+        }
+        uint256 nu = uint256(sha256(bindings)) % R_MOD;
+
+        Pairing.G1Point memory folded = digests[0];
+        uint256 foldedEval = proof.claimedValues[0];
+        uint256 nui = 1;
+        for (uint256 i = 1; i < 7; i++) {
+            nui = mulmod(nui, nu, R_MOD);
+            folded = Pairing.plus(folded, Pairing.scalar_mul(digests[i], nui));
+            foldedEval = addmod(foldedEval, mulmod(proof.claimedValues[i], nui, R_MOD), R_MOD);
+        }
+
+        state.foldedDigest = folded;
+        state.foldedEval = foldedEval;
+    }
+
+    /*
+     * Verifies the folded opening at zeta and the opening of Z at omega*zeta
+     * with a single pairing check, as kzg.BatchVerifyMultiPoints. The random
+     * combination factor lambda is derived from the openings with sha256
+     */
+    function batchVerify(VerifyingKey memory vk, Proof memory proof, State memory state) internal view returns (bool) {
+        bytes memory b = abi.encodePacked(bind("", state.foldedDigest), state.foldedEval);
+        b = abi.encodePacked(bind(b, proof.batchedH), state.zeta);
+        b = abi.encodePacked(bind(b, proof.z), proof.zu);
+        uint256 lambda = uint256(sha256(bind(b, proof.zShiftedH))) % R_MOD;
+
+        // Σ λ_i [f_i(α)]G1 - [Σ λ_i f_i(a_i)]G1 + Σ λ_i a_i [H_i(α)]G1
+        Pairing.G1Point memory digest = Pairing.plus(state.foldedDigest, Pairing.scalar_mul(proof.z, lambda));
+        uint256 eval = addmod(state.foldedEval, mulmod(lambda, proof.zu, R_MOD), R_MOD);
+        digest = Pairing.plus(digest, Pairing.negate(Pairing.scalar_mul(vk.g1, eval)));
+        digest = Pairing.plus(digest, Pairing.scalar_mul(proof.batchedH, state.zeta));
+        uint256 shiftedZeta = mulmod(state.zeta, VK_OMEGA, R_MOD);
+        digest = Pairing.plus(digest, Pairing.scalar_mul(proof.zShiftedH, mulmod(lambda, shiftedZeta, R_MOD)));
+
+        // -Σ λ_i [H_i(α)]G1
+        Pairing.G1Point memory quotients = Pairing.plus(proof.batchedH, Pairing.scalar_mul(proof.zShiftedH, lambda));
+
+        return Pairing.pairing(digest, vk.g2, Pairing.negate(quotients), vk.g2Alpha);
+    }
+}
+`
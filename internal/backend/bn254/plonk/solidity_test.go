@@ -0,0 +1,266 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+package plonk_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/big"
+	"strings"
+	"testing"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/solidity"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/internal/backend/bn254/cs"
+	bn254plonk "github.com/consensys/gnark/internal/backend/bn254/plonk"
+	bn254witness "github.com/consensys/gnark/internal/backend/bn254/witness"
+)
+
+type solidityCircuit struct {
+	X    frontend.Variable
+	Y, Z frontend.Variable `gnark:",public"`
+}
+
+func (circuit *solidityCircuit) Define(api frontend.API) error {
+	x3 := api.Mul(circuit.X, circuit.X, circuit.X)
+	api.AssertIsEqual(circuit.Y, api.Add(x3, circuit.X, 5))
+	api.AssertIsEqual(circuit.Z, api.Mul(circuit.Y, circuit.X))
+	return nil
+}
+
+func TestExportSolidity(t *testing.T) {
+	ccs, err := frontend.Compile(curve.ID, scs.NewBuilder, &solidityCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srs, err := kzg.NewSRS(ecc.NextPowerOfTwo(uint64(ccs.GetNbConstraints()+2))+3, big.NewInt(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, vk, err := bn254plonk.Setup(ccs.(*cs.SparseR1CS), srs, backend.SetupConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := vk.ExportSolidity(&buf, solidity.WithPragmaVersion("0.8.17")); err != nil {
+		t.Fatal(err)
+	}
+	contract := buf.String()
+	for _, s := range []string{
+		"pragma solidity 0.8.17;",
+		"uint256 constant VK_NB_PUBLIC_INPUTS = 2;",
+		"uint256 constant VK_DOMAIN_SIZE = " + new(big.Int).SetUint64(vk.Size).String() + ";",
+		"vk.s[2] = Pairing.G1Point(uint256(" + vk.S[2].X.String() + ")",
+		"function verifyProof(bytes memory proofBytes, uint256[] memory input)",
+	} {
+		if !strings.Contains(contract, s) {
+			t.Fatalf("contract doesn't contain %q", s)
+		}
+	}
+	if err := vk.ExportSolidity(&buf, solidity.WithCompressedProofs()); err == nil {
+		t.Fatal("expected error with compressed proofs")
+	}
+
+	// prove, and check the proof against a port of the contract
+	assignment := solidityCircuit{X: 3, Y: 35, Z: 105}
+	fullWitness := bn254witness.Witness{}
+	if _, err := fullWitness.FromAssignment(&assignment, tVariable, false); err != nil {
+		t.Fatal(err)
+	}
+	publicWitness := bn254witness.Witness{}
+	if _, err := publicWitness.FromAssignment(&assignment, tVariable, true); err != nil {
+		t.Fatal(err)
+	}
+	proof, err := bn254plonk.Prove(ccs.(*cs.SparseR1CS), pk, fullWitness, backend.ProverConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bn254plonk.Verify(proof, vk, publicWitness); err != nil {
+		t.Fatal(err)
+	}
+
+	proofBytes := proof.MarshalSolidity()
+	if len(proofBytes) != 832 {
+		t.Fatalf("unexpected proof size %d", len(proofBytes))
+	}
+	if !verifySolidity(vk, proofBytes, publicWitness) {
+		t.Fatal("valid proof rejected")
+	}
+
+	wrongWitness := bn254witness.Witness{publicWitness[0], publicWitness[1]}
+	wrongWitness[1].SetUint64(106)
+	if verifySolidity(vk, proofBytes, wrongWitness) {
+		t.Fatal("proof accepted with wrong public input")
+	}
+	for _, offset := range []int{0x1c0 + 31, 0x2e0 + 31} {
+		tampered := append([]byte{}, proofBytes...)
+		tampered[offset] ^= 1
+		if verifySolidity(vk, tampered, publicWitness) {
+			t.Fatalf("tampered proof accepted (offset %d)", offset)
+		}
+	}
+}
+
+// verifySolidity mirrors the verifyProof function of the contract generated
+// by ExportSolidity.
+func verifySolidity(vk *bn254plonk.VerifyingKey, b []byte, input []fr.Element) bool {
+	point := func(offset int) curve.G1Affine {
+		var p curve.G1Affine
+		p.X.SetBytes(b[offset : offset+32])
+		p.Y.SetBytes(b[offset+32 : offset+64])
+		return p
+	}
+	scalar := func(offset int) fr.Element {
+		var s fr.Element
+		s.SetBytes(b[offset : offset+32])
+		return s
+	}
+	mul := func(p curve.G1Affine, s fr.Element) curve.G1Affine {
+		var sBig big.Int
+		s.ToBigIntRegular(&sBig)
+		var r curve.G1Affine
+		r.ScalarMultiplication(&p, &sBig)
+		return r
+	}
+	add := func(p, q curve.G1Affine) curve.G1Affine {
+		var r curve.G1Affine
+		r.Add(&p, &q)
+		return r
+	}
+	hash := func(data ...[]byte) []byte {
+		h := sha256.Sum256(bytes.Join(data, nil))
+		return h[:]
+	}
+	challenge := func(h []byte) fr.Element {
+		var c fr.Element
+		c.SetBytes(h)
+		return c
+	}
+
+	var lro, h [3]curve.G1Affine
+	for i := 0; i < 3; i++ {
+		lro[i] = point(i * 0x40)
+		h[i] = point(0x100 + i*0x40)
+	}
+	z := point(0xc0)
+	var v [7]fr.Element
+	for i := 0; i < 7; i++ {
+		v[i] = scalar(0x1c0 + i*0x20)
+	}
+	batchedH := point(0x2a0)
+	zu := scalar(0x2e0)
+	zShiftedH := point(0x300)
+
+	// challenges
+	bindings := [][]byte{[]byte("gamma")}
+	for _, p := range []curve.G1Affine{vk.S[0], vk.S[1], vk.S[2], vk.Ql, vk.Qr, vk.Qm, vk.Qo, vk.Qk} {
+		bindings = append(bindings, p.Marshal())
+	}
+	for i := range input {
+		bindings = append(bindings, input[i].Marshal())
+	}
+	gammaRaw := hash(bindings...)
+	betaRaw := hash([]byte("beta"), gammaRaw)
+	alphaRaw := hash([]byte("alpha"), betaRaw, z.Marshal())
+	zetaRaw := hash([]byte("zeta"), alphaRaw, h[0].Marshal(), h[1].Marshal(), h[2].Marshal())
+	gamma, beta, alpha, zeta := challenge(gammaRaw), challenge(betaRaw), challenge(alphaRaw), challenge(zetaRaw)
+
+	// public inputs
+	var zh, c, lagrangeOne, pi, one, t fr.Element
+	one.SetOne()
+	zh.Exp(zeta, new(big.Int).SetUint64(vk.Size)).Sub(&zh, &one)
+	c.Mul(&zh, &vk.SizeInv)
+	lagrangeOne.Sub(&zeta, &one).Inverse(&lagrangeOne).Mul(&lagrangeOne, &c)
+	w := one
+	for i := range input {
+		var li fr.Element
+		li.Sub(&zeta, &w).Inverse(&li).Mul(&li, &c).Mul(&li, &w)
+		li.Mul(&li, &input[i])
+		pi.Add(&pi, &li)
+		w.Mul(&w, &vk.Generator)
+	}
+
+	// quotient
+	var t1, alphaSquareLagrange fr.Element
+	t.Mul(&v[5], &beta).Add(&t, &v[2]).Add(&t, &gamma)
+	t1.Mul(&v[6], &beta).Add(&t1, &v[3]).Add(&t1, &gamma)
+	t.Mul(&t, &t1)
+	t1.Add(&v[4], &gamma)
+	t.Mul(&t, &t1).Mul(&t, &alpha).Mul(&t, &zu)
+	alphaSquareLagrange.Mul(&lagrangeOne, &alpha).Mul(&alphaSquareLagrange, &alpha)
+	t.Add(&t, &v[1]).Add(&t, &pi).Sub(&t, &alphaSquareLagrange)
+	t1.Inverse(&zh)
+	t.Mul(&t, &t1)
+	if !t.Equal(&v[0]) {
+		return false
+	}
+
+	// folded h
+	var zetaNPlusTwo fr.Element
+	zetaNPlusTwo.Exp(zeta, new(big.Int).SetUint64(vk.Size+2))
+	foldedH := add(mul(add(mul(h[2], zetaNPlusTwo), h[1]), zetaNPlusTwo), h[0])
+
+	// linearized polynomial digest
+	var rl, s, betaZeta fr.Element
+	rl.Mul(&v[2], &v[3])
+	lin := add(add(add(add(mul(vk.Ql, v[2]), mul(vk.Qr, v[3])), mul(vk.Qm, rl)), mul(vk.Qo, v[4])), vk.Qk)
+	s.Mul(&zu, &beta)
+	t1.Mul(&beta, &v[5]).Add(&t1, &v[2]).Add(&t1, &gamma)
+	s.Mul(&s, &t1)
+	t1.Mul(&beta, &v[6]).Add(&t1, &v[3]).Add(&t1, &gamma)
+	s.Mul(&s, &t1).Mul(&s, &alpha)
+	lin = add(lin, mul(vk.S[2], s))
+	betaZeta.Mul(&beta, &zeta)
+	s.Add(&betaZeta, &v[2]).Add(&s, &gamma)
+	betaZeta.Mul(&betaZeta, &vk.CosetShift)
+	t1.Add(&betaZeta, &v[3]).Add(&t1, &gamma)
+	s.Mul(&s, &t1)
+	betaZeta.Mul(&betaZeta, &vk.CosetShift)
+	t1.Add(&betaZeta, &v[4]).Add(&t1, &gamma)
+	s.Mul(&s, &t1).Mul(&s, &alpha)
+	s.Sub(&alphaSquareLagrange, &s)
+	lin = add(lin, mul(z, s))
+
+	// fold the digests
+	digests := []curve.G1Affine{foldedH, lin, lro[0], lro[1], lro[2], vk.S[0], vk.S[1]}
+	bindings = [][]byte{[]byte("gamma"), zeta.Marshal()}
+	for i := range digests {
+		bindings = append(bindings, digests[i].Marshal())
+	}
+	nu := challenge(hash(bindings...))
+	foldedDigest := digests[0]
+	foldedEval := v[0]
+	nui := one
+	for i := 1; i < 7; i++ {
+		nui.Mul(&nui, &nu)
+		foldedDigest = add(foldedDigest, mul(digests[i], nui))
+		t1.Mul(&v[i], &nui)
+		foldedEval.Add(&foldedEval, &t1)
+	}
+
+	// batch verify
+	var eval, shiftedZeta fr.Element
+	lambda := challenge(hash(foldedDigest.Marshal(), foldedEval.Marshal(), batchedH.Marshal(), zeta.Marshal(),
+		z.Marshal(), zu.Marshal(), zShiftedH.Marshal()))
+	digest := add(foldedDigest, mul(z, lambda))
+	eval.Mul(&lambda, &zu).Add(&eval, &foldedEval)
+	eval.Neg(&eval)
+	digest = add(digest, mul(vk.KZGSRS.G1[0], eval))
+	digest = add(digest, mul(batchedH, zeta))
+	shiftedZeta.Mul(&zeta, &vk.Generator).Mul(&shiftedZeta, &lambda)
+	digest = add(digest, mul(zShiftedH, shiftedZeta))
+	quotients := add(batchedH, mul(zShiftedH, lambda))
+	quotients.Neg(&quotients)
+
+	ok, err := curve.PairingCheck([]curve.G1Affine{digest, quotients}, []curve.G2Affine{vk.KZGSRS.G2[0], vk.KZGSRS.G2[1]})
+	return err == nil && ok
+}
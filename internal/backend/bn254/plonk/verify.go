@@ -19,10 +19,16 @@ package plonk
 import (
 	"crypto/sha256"
 	"errors"
+	"fmt"
+	"hash"
+	"io"
 	"math/big"
 	"time"
 
+	"text/template"
+
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
 
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
 
@@ -32,22 +38,168 @@ import (
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/fiat-shamir"
+	kzgg "github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/solidity"
 	"github.com/consensys/gnark/logger"
 )
 
 var (
 	errWrongClaimedQuotient = errors.New("claimed quotient is not as expected")
+	errInvalidLookupProof   = errors.New("invalid number of openings for the lookup argument")
+	errInvalidCommitment    = errors.New("invalid number of openings for the commitment")
 )
 
+// Proof is a PLONK proof, computed by Prove
+type Proof struct {
+
+	// Commitments to the solution vectors
+	LRO [3]kzg.Digest
+
+	// Commitment to Z, the permutation polynomial
+	Z kzg.Digest
+
+	// Commitments to h1, h2, h3 such that h = h1 + Xh2 + X**2h3 is the quotient polynomial
+	H [3]kzg.Digest
+
+	// Batch opening proof of h1 + zeta*h2 + zeta**2h3, linearizedPolynomial, l, r, o, s1, s2
+	// (and f, t, h1, h2 of the lookup argument, if the circuit uses lookup tables)
+	BatchedProof kzg.BatchOpeningProof
+
+	// Opening proof of Z at zeta*mu
+	ZShiftedOpening kzg.OpeningProof
+
+	// Commitments to f, h1, h2 and to Zl, the accumulator polynomial of the lookup argument
+	// (only set if the circuit uses lookup tables)
+	F, H1, H2, Zl kzg.Digest
+
+	// Batch opening proof of Zl, h1, t at zeta*mu (only set if the circuit uses lookup tables)
+	LookupShiftedBatchedProof kzg.BatchOpeningProof
+
+	// Commitment to π₂, the polynomial of the committed wires (only set if the circuit
+	// commits to some of its wires, see frontend.API.Commit). π₂ is opened in BatchedProof.
+	PI2 kzg.Digest
+}
+
+// VerifyingKey stores the data needed to verify a proof:
+// * The commitment scheme
+// * Commitments of ql prepended with as many ones as there are public inputs
+// * Commitments of qr, qm, qo, qk prepended with as many zeroes as there are public inputs
+// * Commitments to S1, S2, S3
+// * The custom gates and the commitments to their selectors
+// * Commitments to the lookup selector and to the columns of the lookup tables
+// * The commitment to the selector of the committed wires
+// * The hash function of the Fiat-Shamir transcript
+// * The fingerprint of the circuit
+type VerifyingKey struct {
+	// Size circuit
+	Size              uint64
+	SizeInv           fr.Element
+	Generator         fr.Element
+	NbPublicVariables uint64
+
+	// Commitment scheme that is used for an instantiation of PLONK
+	KZGSRS *kzg.SRS
+
+	// cosetShift generator of the coset on the small domain
+	CosetShift fr.Element
+
+	// S commitments to S1, S2, S3
+	S [3]kzg.Digest
+
+	// Commitments to ql, qr, qm, qo prepended with as many zeroes (ones for l) as there are public inputs.
+	// In particular Qk is not complete.
+	Ql, Qr, Qm, Qo, Qk kzg.Digest
+
+	// Commitments to the selectors of the custom gates
+	Qcustom     []kzg.Digest
+	CustomGates []CustomGate
+
+	// Commitments to the lookup selector, and to the columns of the lookup tables.
+	// T is empty if the circuit doesn't use lookup tables.
+	Qlookup kzg.Digest
+	T       []kzg.Digest
+
+	// Commitment to the selector of the committed wires, and index of the constraint
+	// completed with the commitment wire, which is a public input of the verifier.
+	// CommitmentConstraintIndexes is empty if the circuit doesn't commit to some of its wires.
+	Qcp                         kzg.Digest
+	CommitmentConstraintIndexes []uint64
+
+	// Hash function deriving the Fiat-Shamir challenges, selected in Setup
+	TranscriptHash backend.TranscriptHash
+
+	// CircuitFingerprint is the fingerprint of the circuit the key is set up for (see
+	// cs.SparseR1CS.Fingerprint), zero if the key was written before it was recorded
+	CircuitFingerprint [32]byte
+}
+
+// CustomGate is a custom gate G(l, r, o) = Σᵢ cᵢ⋅lᵃⁱ⋅rᵇⁱ⋅oᶜⁱ
+// where Coefficients[i] = cᵢ and Exponents[i] = (aᵢ, bᵢ, cᵢ)
+type CustomGate struct {
+	Coefficients []fr.Element
+	Exponents    [][3]uint64
+}
+
+// Evaluate returns G(l, r, o)
+func (g *CustomGate) Evaluate(l, r, o fr.Element) fr.Element {
+	var res, m fr.Element
+	for i := 0; i < len(g.Coefficients); i++ {
+		m.Set(&g.Coefficients[i])
+		for j := uint64(0); j < g.Exponents[i][0]; j++ {
+			m.Mul(&m, &l)
+		}
+		for j := uint64(0); j < g.Exponents[i][1]; j++ {
+			m.Mul(&m, &r)
+		}
+		for j := uint64(0); j < g.Exponents[i][2]; j++ {
+			m.Mul(&m, &o)
+		}
+		res.Add(&res, &m)
+	}
+	return res
+}
+
+// InitKZG inits vk.KZG using provided SRS
+//
+// This should be used after deserializing a VerifyingKey
+// as vk.KZG is NOT serialized
+//
+// Note that this instantiate a new FFT domain using vk.Size
+func (vk *VerifyingKey) InitKZG(srs kzgg.SRS) error {
+	_srs := srs.(*kzg.SRS)
+
+	if len(_srs.G1) < int(vk.Size) {
+		return errors.New("kzg srs is too small")
+	}
+	vk.KZGSRS = _srs
+
+	return nil
+}
+
+// NbPublicWitness returns the expected public witness size (number of field elements)
+func (vk *VerifyingKey) NbPublicWitness() int {
+	return int(vk.NbPublicVariables)
+}
+
+// Fingerprint returns the fingerprint of the circuit the key is set up for, to
+// be compared with the one of the constraint system (see cs.SparseR1CS.Fingerprint)
+func (vk *VerifyingKey) Fingerprint() [32]byte {
+	return vk.CircuitFingerprint
+}
+
 func Verify(proof *Proof, vk *VerifyingKey, publicWitness bn254witness.Witness) error {
 	log := logger.Logger().With().Str("curve", "bn254").Str("backend", "plonk").Logger()
 	start := time.Now()
 
 	// pick a hash function to derive the challenge (the same as in the prover)
-	hFunc := sha256.New()
+	hFunc, err := newTranscriptHash(vk.TranscriptHash)
+	if err != nil {
+		return err
+	}
 
 	// transcript to derive the challenge
-	fs := fiatshamir.NewTranscript(hFunc, "gamma", "beta", "alpha", "zeta")
+	fs := fiatshamir.NewTranscript(hFunc, challengeIDs(vk)...)
 
 	// The first challenge is derived using the public data: the commitments to the permutation,
 	// the coefficients of the circuit, and the public inputs.
@@ -55,6 +207,9 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness bn254witness.Witness)
 	if err := bindPublicData(&fs, "gamma", *vk, publicWitness); err != nil {
 		return err
 	}
+	if err := bindCommitment(&fs, "gamma", *vk, proof); err != nil {
+		return err
+	}
 	bgamma, err := fs.ComputeChallenge("gamma")
 	if err != nil {
 		return err
@@ -68,8 +223,31 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness bn254witness.Witness)
 		return err
 	}
 
-	// derive alpha from Comm(l), Comm(r), Comm(o), Com(Z)
-	alpha, err := deriveRandomness(&fs, "alpha", &proof.Z)
+	// lookup argument: derive eta from Comm(l), Comm(r), Comm(o), delta from Comm(f), Comm(h1), Comm(h2), and epsilon
+	var lk lookupChallenges
+	alphaPoints := []*curve.G1Affine{&proof.Z}
+	if len(vk.T) != 0 {
+		if len(proof.BatchedProof.ClaimedValues) != 11+len(vk.CommitmentConstraintIndexes) || len(proof.LookupShiftedBatchedProof.ClaimedValues) != 3 {
+			return errInvalidLookupProof
+		}
+		eta, err := deriveRandomness(&fs, "eta", &proof.LRO[0], &proof.LRO[1], &proof.LRO[2])
+		if err != nil {
+			return err
+		}
+		delta, err := deriveRandomness(&fs, "delta", &proof.F, &proof.H1, &proof.H2)
+		if err != nil {
+			return err
+		}
+		epsilon, err := deriveRandomness(&fs, "epsilon")
+		if err != nil {
+			return err
+		}
+		lk = newLookupChallenges(eta, delta, epsilon)
+		alphaPoints = append(alphaPoints, &proof.Zl)
+	}
+
+	// derive alpha from Comm(l), Comm(r), Comm(o), Com(Z) (and Com(Zl))
+	alpha, err := deriveRandomness(&fs, "alpha", alphaPoints...)
 	if err != nil {
 		return err
 	}
@@ -109,6 +287,24 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness bn254witness.Witness)
 		lagrange.Div(&lagrange, &den)
 	}
 
+	// the commitment wire is a public input of its placeholder constraint: PI += Lᵢ*c
+	var pi2Zeta fr.Element
+	if len(vk.CommitmentConstraintIndexes) != 0 {
+		if len(proof.BatchedProof.ClaimedValues) < 8 {
+			return errInvalidCommitment
+		}
+		pi2Zeta = proof.BatchedProof.ClaimedValues[len(proof.BatchedProof.ClaimedValues)-1]
+		c := commitmentChallenge(&proof.PI2)
+		for _, i := range vk.CommitmentConstraintIndexes {
+			var wi fr.Element
+			wi.Exp(vk.Generator, new(big.Int).SetUint64(i))
+			den.Sub(&zeta, &wi)
+			lagrange.Div(&zzeta, &den).Mul(&lagrange, &wi).Mul(&lagrange, &vk.SizeInv) // Lᵢ = (ωⁱ/n)*(ζⁿ⁻¹)/(ζ-ωⁱ)
+			xiLi.Mul(&lagrange, &c)
+			pi.Add(&pi, &xiLi)
+		}
+	}
+
 	// linearizedpolynomial + pi(ζ) + α*(Z(μζ))*(l(ζ)+β*s1(ζ)+γ)*(r(ζ)+β*s2(ζ)+γ)*(o(ζ)+γ) - α²*L₁(ζ)
 	var _s1, _s2, _o, alphaSquareLagrange fr.Element
 
@@ -142,6 +338,21 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness bn254witness.Witness)
 		Add(&linearizedPolynomialZeta, &_s1).                // linearizedpolynomial+pi(zeta)+α*(Z(μζ))*(l(ζ)+s1(ζ)+γ)*(r(ζ)+s2(ζ)+γ)*(o(ζ)+γ)
 		Sub(&linearizedPolynomialZeta, &alphaSquareLagrange) // linearizedpolynomial+pi(zeta)+α*(Z(μζ))*(l(ζ)+s1(ζ)+γ)*(r(ζ)+s2(ζ)+γ)*(o(ζ)+γ)-α²*L₁(ζ)
 
+	// lookup argument: + α⁴*Zl(μζ)*D(ζ) - α⁵*L₁(ζ)
+	var lkZeta [4]fr.Element    // f(ζ), t(ζ), h1(ζ), h2(ζ)
+	var lkShifted [3]fr.Element // Zl(μζ), h1(μζ), t(μζ)
+	if len(vk.T) != 0 {
+		copy(lkZeta[:], proof.BatchedProof.ClaimedValues[7:])
+		copy(lkShifted[:], proof.LookupShiftedBatchedProof.ClaimedValues)
+		var t, alphaCube fr.Element
+		alphaCube.Square(&alpha).Mul(&alphaCube, &alpha)
+		t = lk.denominator(lkZeta[2], lkZeta[3], lkShifted[1])
+		t.Mul(&t, &lkShifted[0]).Mul(&t, &alpha).
+			Sub(&t, &alphaSquareLagrange).
+			Mul(&t, &alphaCube)
+		linearizedPolynomialZeta.Add(&linearizedPolynomialZeta, &t)
+	}
+
 	// Compute H(ζ) using the previous result: H(ζ) = prev_result/(ζⁿ-1)
 	var zetaPowerMMinusOne fr.Element
 	zetaPowerMMinusOne.Sub(&zetaPowerM, &one)
@@ -168,7 +379,9 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness bn254witness.Witness)
 	// linearizedPolynomialDigest =
 	// 		l(ζ)*ql+r(ζ)*qr+r(ζ)l(ζ)*qm+o(ζ)*qo+qk +
 	// 		α*( Z(μζ)(l(ζ)+β*s₁(ζ)+γ)*(r(ζ)+β*s₂(ζ)+γ)*s₃(X)-Z(X)(l(ζ)+β*id_1(ζ)+γ)*(r(ζ)+β*id_2(ζ)+γ)*(o(ζ)+β*id_3(ζ)+γ) ) +
-	// 		α²*L₁(ζ)*Z
+	// 		α²*L₁(ζ)*Z +
+	// 		∑ Gᵢ(l(ζ), r(ζ), o(ζ))*qcᵢ +
+	// 		π₂(ζ)*qcp
 	// first part: individual constraints
 	var rl fr.Element
 	rl.Mul(&l, &r)
@@ -201,12 +414,27 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness bn254witness.Witness)
 		l, r, rl, o, one, // first part
 		_s1, _s2, // second & third part
 	}
+	for i := range vk.Qcustom { // custom gates
+		points = append(points, vk.Qcustom[i])
+		scalars = append(scalars, vk.CustomGates[i].Evaluate(l, r, o))
+	}
+	var tDigest kzg.Digest
+	if len(vk.T) != 0 { // lookup argument
+		cq, cz := lk.linearizedCoefficients(l, r, o, lkZeta[0], lkZeta[1], lkShifted[2], alpha, alphaSquareLagrange)
+		points = append(points, vk.Qlookup, proof.Zl)
+		scalars = append(scalars, cq, cz)
+		tDigest = lk.compressDigests(vk.T)
+	}
+	if len(vk.CommitmentConstraintIndexes) != 0 { // commitment
+		points = append(points, vk.Qcp)
+		scalars = append(scalars, pi2Zeta)
+	}
 	if _, err := linearizedPolynomialDigest.MultiExp(points, scalars, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
 		return err
 	}
 
 	// Fold the first proof
-	foldedProof, foldedDigest, err := kzg.FoldProof([]kzg.Digest{
+	digests := []kzg.Digest{
 		foldedH,
 		linearizedPolynomialDigest,
 		proof.LRO[0],
@@ -214,7 +442,14 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness bn254witness.Witness)
 		proof.LRO[2],
 		vk.S[0],
 		vk.S[1],
-	},
+	}
+	if len(vk.T) != 0 {
+		digests = append(digests, proof.F, tDigest, proof.H1, proof.H2)
+	}
+	if len(vk.CommitmentConstraintIndexes) != 0 {
+		digests = append(digests, proof.PI2)
+	}
+	foldedProof, foldedDigest, err := kzg.FoldProof(digests,
 		&proof.BatchedProof,
 		zeta,
 		hFunc,
@@ -226,20 +461,33 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness bn254witness.Witness)
 	// Batch verify
 	var shiftedZeta fr.Element
 	shiftedZeta.Mul(&zeta, &vk.Generator)
-	err = kzg.BatchVerifyMultiPoints([]kzg.Digest{
+	digests = []kzg.Digest{
 		foldedDigest,
 		proof.Z,
-	},
-		[]kzg.OpeningProof{
-			foldedProof,
-			proof.ZShiftedOpening,
-		},
-		[]fr.Element{
-			zeta,
+	}
+	proofs := []kzg.OpeningProof{
+		foldedProof,
+		proof.ZShiftedOpening,
+	}
+	openingPoints := []fr.Element{
+		zeta,
+		shiftedZeta,
+	}
+	if len(vk.T) != 0 {
+		// fold the openings of the lookup argument at μζ
+		foldedProof, foldedDigest, err := kzg.FoldProof([]kzg.Digest{proof.Zl, proof.H1, tDigest},
+			&proof.LookupShiftedBatchedProof,
 			shiftedZeta,
-		},
-		vk.KZGSRS,
-	)
+			hFunc,
+		)
+		if err != nil {
+			return err
+		}
+		digests = append(digests, foldedDigest)
+		proofs = append(proofs, foldedProof)
+		openingPoints = append(openingPoints, shiftedZeta)
+	}
+	err = kzg.BatchVerifyMultiPoints(digests, proofs, openingPoints, vk.KZGSRS)
 
 	log.Debug().Dur("took", time.Since(start)).Msg("verifier done")
 
@@ -275,6 +523,30 @@ func bindPublicData(fs *fiatshamir.Transcript, challenge string, vk VerifyingKey
 	if err := fs.Bind(challenge, vk.Qk.Marshal()); err != nil {
 		return err
 	}
+	for i := range vk.Qcustom {
+		if err := fs.Bind(challenge, vk.Qcustom[i].Marshal()); err != nil {
+			return err
+		}
+	}
+
+	// commitment
+	if len(vk.CommitmentConstraintIndexes) != 0 {
+		if err := fs.Bind(challenge, vk.Qcp.Marshal()); err != nil {
+			return err
+		}
+	}
+
+	// lookup tables
+	if len(vk.T) != 0 {
+		if err := fs.Bind(challenge, vk.Qlookup.Marshal()); err != nil {
+			return err
+		}
+		for i := range vk.T {
+			if err := fs.Bind(challenge, vk.T[i].Marshal()); err != nil {
+				return err
+			}
+		}
+	}
 
 	// public inputs
 	for i := 0; i < len(publicInputs); i++ {
@@ -287,6 +559,22 @@ func bindPublicData(fs *fiatshamir.Transcript, challenge string, vk VerifyingKey
 
 }
 
+// bindCommitment binds the commitment to π₂, if the circuit commits to some of its wires
+func bindCommitment(fs *fiatshamir.Transcript, challenge string, vk VerifyingKey, proof *Proof) error {
+	if len(vk.CommitmentConstraintIndexes) == 0 {
+		return nil
+	}
+	return fs.Bind(challenge, proof.PI2.Marshal())
+}
+
+// commitmentChallenge returns the value of the commitment wire, a hash of the commitment to π₂
+func commitmentChallenge(pi2 *kzg.Digest) fr.Element {
+	h := sha256.Sum256(pi2.Marshal())
+	var c fr.Element
+	c.SetBytes(h[:])
+	return c
+}
+
 func deriveRandomness(fs *fiatshamir.Transcript, challenge string, points ...*curve.G1Affine) (fr.Element, error) {
 
 	var buf [curve.SizeOfG1AffineUncompressed]byte
@@ -306,3 +594,143 @@ func deriveRandomness(fs *fiatshamir.Transcript, challenge string, points ...*cu
 	r.SetBytes(b)
 	return r, nil
 }
+
+// newTranscriptHash returns the hash function h of the Fiat-Shamir transcript. The
+// field hashes reduce the blocks of the transcript modulo the scalar field.
+func newTranscriptHash(h backend.TranscriptHash) (hash.Hash, error) {
+	switch h {
+	case backend.TranscriptSHA256:
+		return sha256.New(), nil
+	case backend.TranscriptMiMC:
+		return mimc.NewMiMC(), nil
+	default:
+		return nil, fmt.Errorf("unsupported transcript hash %s", h)
+	}
+}
+
+// challengeIDs returns the Fiat-Shamir challenges of the protocol, the lookup
+// argument adds η, δ and ε.
+func challengeIDs(vk *VerifyingKey) []string {
+	if len(vk.T) == 0 {
+		return []string{"gamma", "beta", "alpha", "zeta"}
+	}
+	return []string{"gamma", "beta", "eta", "delta", "epsilon", "alpha", "zeta"}
+}
+
+// lookupChallenges are the challenges of the lookup argument
+type lookupChallenges struct {
+	eta, delta, epsilon fr.Element
+
+	onePlusDelta, epsilonOnePlusDelta fr.Element // 1+δ, ε(1+δ)
+}
+
+func newLookupChallenges(eta, delta, epsilon fr.Element) lookupChallenges {
+	c := lookupChallenges{eta: eta, delta: delta, epsilon: epsilon}
+	c.onePlusDelta.SetOne().Add(&c.onePlusDelta, &delta)
+	c.epsilonOnePlusDelta.Mul(&c.onePlusDelta, &epsilon)
+	return c
+}
+
+// compress returns a+η*b+η²*c
+func (c *lookupChallenges) compress(a, b, d fr.Element) fr.Element {
+	var res fr.Element
+	res.Mul(&d, &c.eta).Add(&res, &b).Mul(&res, &c.eta).Add(&res, &a)
+	return res
+}
+
+// numerator returns (1+δ)*(ε+f)*(ε(1+δ)+t+δ*tShifted)
+func (c *lookupChallenges) numerator(f, t, tShifted fr.Element) fr.Element {
+	var res, tmp fr.Element
+	res.Add(&c.epsilon, &f).Mul(&res, &c.onePlusDelta)
+	tmp.Mul(&tShifted, &c.delta).Add(&tmp, &t).Add(&tmp, &c.epsilonOnePlusDelta)
+	res.Mul(&res, &tmp)
+	return res
+}
+
+// denominator returns (ε(1+δ)+h1+δ*h2)*(ε(1+δ)+h2+δ*h1Shifted)
+func (c *lookupChallenges) denominator(h1, h2, h1Shifted fr.Element) fr.Element {
+	var res, tmp fr.Element
+	res.Mul(&h2, &c.delta).Add(&res, &h1).Add(&res, &c.epsilonOnePlusDelta)
+	tmp.Mul(&h1Shifted, &c.delta).Add(&tmp, &h2).Add(&tmp, &c.epsilonOnePlusDelta)
+	res.Mul(&res, &tmp)
+	return res
+}
+
+// linearizedCoefficients returns the coefficients of Qlookup and Zl in the linearized polynomial:
+// α³*(l(ζ)+η*r(ζ)+η²*o(ζ)-f(ζ)) and α⁵*L₁(ζ)-α⁴*(1+δ)*(ε+f(ζ))*(ε(1+δ)+t(ζ)+δ*t(μζ))
+//
+// alphaSquareLagrange is α²*L₁(ζ)
+func (c *lookupChallenges) linearizedCoefficients(l, r, o, f, t, tShifted, alpha, alphaSquareLagrange fr.Element) (fr.Element, fr.Element) {
+	var alphaCube, cq, cz fr.Element
+	alphaCube.Square(&alpha).Mul(&alphaCube, &alpha)
+
+	cq = c.compress(l, r, o)
+	cq.Sub(&cq, &f).Mul(&cq, &alphaCube)
+
+	cz = c.numerator(f, t, tShifted)
+	cz.Mul(&cz, &alpha).Neg(&cz).Add(&cz, &alphaSquareLagrange).Mul(&cz, &alphaCube)
+
+	return cq, cz
+}
+
+// compressDigests returns d[0]+η*d[1]+η²*d[2]
+func (c *lookupChallenges) compressDigests(d []kzg.Digest) kzg.Digest {
+	var res kzg.Digest
+	var eta big.Int
+	c.eta.ToBigIntRegular(&eta)
+	res.ScalarMultiplication(&d[2], &eta)
+	res.Add(&res, &d[1])
+	res.ScalarMultiplication(&res, &eta)
+	res.Add(&res, &d[0])
+	return res
+}
+
+// ExportSolidity writes a solidity Verifier contract on provided writer.
+//
+// The contract verifies proofs serialized with Proof.MarshalSolidity, and re-derives the
+// Fiat-Shamir challenges exactly as the Go prover does (sha256 transcript).
+// The KZG SRS must be set in the VerifyingKey (see InitKZG). Compressed proofs, custom gates, lookup tables,
+// commitments and transcript hashes other than sha256 are not supported.
+//
+// this is an experimental feature and gnark solidity generator as not been thoroughly tested
+func (vk *VerifyingKey) ExportSolidity(w io.Writer, exportOpts ...solidity.ExportOption) error {
+	cfg, err := solidity.NewExportConfig(exportOpts...)
+	if err != nil {
+		return err
+	}
+	if cfg.CompressedProofs {
+		return errors.New("compressed proofs are not supported by the PLONK solidity verifier")
+	}
+	if len(vk.CustomGates) > 0 {
+		return errors.New("custom gates are not supported by the PLONK solidity verifier")
+	}
+	if len(vk.T) > 0 {
+		return errors.New("lookup tables are not supported by the PLONK solidity verifier")
+	}
+	if len(vk.CommitmentConstraintIndexes) > 0 {
+		return errors.New("commitments are not supported by the PLONK solidity verifier")
+	}
+	if vk.TranscriptHash != backend.TranscriptSHA256 {
+		return fmt.Errorf("the %s transcript hash is not supported by the PLONK solidity verifier", vk.TranscriptHash)
+	}
+	if vk.KZGSRS == nil || len(vk.KZGSRS.G1) == 0 {
+		return errors.New("missing KZG SRS in verifying key")
+	}
+
+	helpers := template.FuncMap{
+		"fr": func(e fr.Element) string {
+			return e.String()
+		},
+	}
+
+	tmpl, err := template.New("").Funcs(helpers).Parse(solidityTemplate)
+	if err != nil {
+		return err
+	}
+
+	// execute template
+	return tmpl.Execute(w, struct {
+		Cfg solidity.ExportConfig
+		Vk  *VerifyingKey
+	}{cfg, vk})
+}
@@ -0,0 +1,491 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package plonkfri
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"math/bits"
+	"strconv"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+
+	bn254witness "github.com/consensys/gnark/internal/backend/bn254/witness"
+
+	"github.com/consensys/gnark-crypto/fiat-shamir"
+	"github.com/consensys/gnark/internal/merkle"
+	"github.com/consensys/gnark/logger"
+)
+
+const (
+	// rho is the inverse of the rate of the Reed-Solomon code of the commitments:
+	// the polynomials of degree < n are committed to with their evaluations on a
+	// domain of size rho⋅n
+	rho = 8
+
+	// nbQueries is the number of queries of the FRI low degree test. Each query
+	// brings log₂(rho) bits of (conjectured) security.
+	nbQueries = 43
+)
+
+// indexes of the polynomials opened at ζ, in the order of their Merkle trees
+const (
+	idQl = iota
+	idQr
+	idQm
+	idQo
+	idQk
+	idS1
+	idS2
+	idS3
+	idL
+	idR
+	idO
+	idZ
+	idH1
+	idH2
+	idH3
+	nbPolynomials
+)
+
+// trees are the ranges of the polynomials committed to in each Merkle tree: the
+// preprocessed polynomials, l, r, o, z, and the quotient h1, h2, h3
+var trees = [4][2]int{{idQl, idL}, {idL, idZ}, {idZ, idH1}, {idH1, nbPolynomials}}
+
+var (
+	errWrongClaimedQuotient = errors.New("claimed quotient is not as expected")
+	errInvalidProofSize     = errors.New("invalid number of openings")
+	errInvalidOpening       = errors.New("invalid Merkle path")
+	errLowDegreeTest        = errors.New("the low degree test failed")
+)
+
+// Proof is a PLONK proof with FRI commitments, computed by Prove
+type Proof struct {
+
+	// Roots of the Merkle trees of the evaluations of l, r, o, of z, the permutation
+	// polynomial, and of h1, h2, h3 such that h = h1 + Xⁿh2 + X²ⁿh3 is the quotient
+	LRO, Z, H merkle.Digest
+
+	// Evaluations of ql, qr, qm, qo, qk, s1, s2, s3, l, r, o, z, h1, h2, h3 at ζ,
+	// and of z at ωζ
+	Evaluations [nbPolynomials]fr.Element
+	ZShifted    fr.Element
+
+	// Roots of the Merkle trees of the layers of the FRI low degree test (but the
+	// first one, whose evaluations are computed from the openings of the
+	// polynomials), and the value of its last layer
+	Layers     []merkle.Digest
+	FinalValue fr.Element
+
+	Queries []Query
+}
+
+// Query opens the Merkle trees at a pair of points ±x for the FRI low degree test
+type Query struct {
+	// Polynomials are the openings of the trees of the polynomials, and Layers the
+	// ones of the layers of the low degree test, at ±x, ±x², ...
+	Polynomials [len(trees)]Opening
+	Layers      []Opening
+}
+
+// Opening is the opening of a leaf of a Merkle tree: the evaluations of its
+// polynomials at x followed by the ones at -x
+type Opening struct {
+	Values []fr.Element
+	Path   []merkle.Digest
+}
+
+// VerifyingKey stores the data needed to verify a proof:
+// * the size of the circuit and the generators of its domains
+// * the root of the Merkle tree of the evaluations of ql, qr, qm, qo, qk, s1, s2, s3
+// * the fingerprint of the circuit
+type VerifyingKey struct {
+	// Size circuit
+	Size              uint64
+	SizeInv           fr.Element
+	Generator         fr.Element
+	NbPublicVariables uint64
+
+	// shifters for extending the permutation set: from s=<1,z,..,z**n-1>,
+	// extended domain = s || shifter*s || shifter**2*s
+	CosetShift fr.Element
+
+	// GeneratorFRI generates the domain of the commitments, of size rho⋅Size
+	GeneratorFRI fr.Element
+
+	// Qpp is the root of the Merkle tree of the preprocessed polynomials
+	Qpp merkle.Digest
+
+	// CircuitFingerprint is the fingerprint of the circuit the key is set up for (see
+	// cs.SparseR1CS.Fingerprint), zero if the key was written before it was recorded
+	CircuitFingerprint [32]byte
+}
+
+// NbPublicWitness returns the expected public witness size (number of field elements)
+func (vk *VerifyingKey) NbPublicWitness() int {
+	return int(vk.NbPublicVariables)
+}
+
+// Fingerprint returns the fingerprint of the circuit the key is set up for, to
+// be compared with the one of the constraint system (see cs.SparseR1CS.Fingerprint)
+func (vk *VerifyingKey) Fingerprint() [32]byte {
+	return vk.CircuitFingerprint
+}
+
+// Verify verifies a PLONK proof with FRI commitments, from the proof, the
+// verifying key and the public witness.
+func Verify(proof *Proof, vk *VerifyingKey, publicWitness bn254witness.Witness) error {
+	log := logger.Logger().With().Str("curve", "bn254").Str("backend", "plonkfri").Logger()
+	start := time.Now()
+
+	if len(publicWitness) != int(vk.NbPublicVariables) {
+		return errors.New("invalid public witness size")
+	}
+	nbFolds := bits.TrailingZeros64(vk.Size)
+	if len(proof.Layers) != max(nbFolds-1, 0) || len(proof.Queries) != nbQueries {
+		return errInvalidProofSize
+	}
+
+	fs := fiatshamir.NewTranscript(sha256.New(), challengeIDs(nbFolds)...)
+
+	// derive gamma from the preprocessed polynomials, the public inputs and the
+	// commitment to l, r, o
+	if err := bindPublicData(&fs, "gamma", vk, publicWitness); err != nil {
+		return err
+	}
+	gamma, err := deriveRandomness(&fs, "gamma", proof.LRO[:])
+	if err != nil {
+		return err
+	}
+	beta, err := deriveRandomness(&fs, "beta")
+	if err != nil {
+		return err
+	}
+	alpha, err := deriveRandomness(&fs, "alpha", proof.Z[:])
+	if err != nil {
+		return err
+	}
+	zeta, err := deriveRandomness(&fs, "zeta", proof.H[:])
+	if err != nil {
+		return err
+	}
+	if err := checkZeta(vk, &zeta); err != nil {
+		return err
+	}
+
+	// check the PLONK identity at ζ
+	e := &proof.Evaluations
+	var zetaPowerN, zhZeta, one fr.Element
+	one.SetOne()
+	bSize := new(big.Int).SetUint64(vk.Size)
+	zetaPowerN.Exp(zeta, bSize)
+	zhZeta.Sub(&zetaPowerN, &one) // ζⁿ - 1
+
+	// Lᵢ(ζ) = ωⁱ/n * (ζⁿ-1)/(ζ-ωⁱ), for the public inputs and L₀
+	nbLagrange := max(len(publicWitness), 1)
+	lagrange := make([]fr.Element, nbLagrange)
+	var acc fr.Element
+	acc.SetOne()
+	for i := range lagrange {
+		lagrange[i].Sub(&zeta, &acc)
+		acc.Mul(&acc, &vk.Generator)
+	}
+	lagrange = fr.BatchInvert(lagrange)
+	acc.Set(&vk.SizeInv)
+	for i := range lagrange {
+		lagrange[i].Mul(&lagrange[i], &zhZeta).Mul(&lagrange[i], &acc)
+		acc.Mul(&acc, &vk.Generator)
+	}
+	var pi, tmp fr.Element
+	for i := range publicWitness {
+		tmp.Mul(&lagrange[i], &publicWitness[i])
+		pi.Add(&pi, &tmp)
+	}
+
+	// ql⋅l + qr⋅r + qm⋅l⋅r + qo⋅o + qk + PI
+	var gate fr.Element
+	gate.Mul(&e[idQm], &e[idR]).Add(&gate, &e[idQl]).Mul(&gate, &e[idL])
+	tmp.Mul(&e[idQr], &e[idR])
+	gate.Add(&gate, &tmp)
+	tmp.Mul(&e[idQo], &e[idO])
+	gate.Add(&gate, &tmp).Add(&gate, &e[idQk]).Add(&gate, &pi)
+
+	// z(ωζ)⋅(l+β⋅s₁+γ)(r+β⋅s₂+γ)(o+β⋅s₃+γ) - z(ζ)⋅(l+β⋅ζ+γ)(r+β⋅u⋅ζ+γ)(o+β⋅u²⋅ζ+γ)
+	ordering := orderingConstraint(e[idL], e[idR], e[idO], e[idS1], e[idS2], e[idS3], e[idZ], proof.ZShifted, zeta, beta, gamma, vk.CosetShift)
+
+	// L₀(ζ)⋅(z(ζ)-1)
+	var startsAtOne fr.Element
+	startsAtOne.Sub(&e[idZ], &one).Mul(&startsAtOne, &lagrange[0])
+
+	var lhs fr.Element
+	lhs.Mul(&startsAtOne, &alpha).Add(&lhs, &ordering).Mul(&lhs, &alpha).Add(&lhs, &gate)
+
+	// h(ζ)⋅(ζⁿ-1)
+	var rhs fr.Element
+	rhs.Mul(&e[idH3], &zetaPowerN).Add(&rhs, &e[idH2]).Mul(&rhs, &zetaPowerN).Add(&rhs, &e[idH1]).Mul(&rhs, &zhZeta)
+	if !lhs.Equal(&rhs) {
+		return errWrongClaimedQuotient
+	}
+
+	// FRI challenges
+	nu, err := deriveRandomness(&fs, "nu", evaluationsBytes(proof))
+	if err != nil {
+		return err
+	}
+	foldingChallenges := make([]fr.Element, nbFolds)
+	for i := range foldingChallenges {
+		var roots [][]byte
+		if i > 0 {
+			roots = append(roots, proof.Layers[i-1][:])
+		}
+		if foldingChallenges[i], err = deriveRandomness(&fs, "fri"+strconv.Itoa(i), roots...); err != nil {
+			return err
+		}
+	}
+	finalValue := proof.FinalValue.Bytes()
+	if err := fs.Bind("query", finalValue[:]); err != nil {
+		return err
+	}
+	seed, err := fs.ComputeChallenge("query")
+	if err != nil {
+		return err
+	}
+
+	// the queries
+	var zetaShifted fr.Element
+	zetaShifted.Mul(&zeta, &vk.Generator)
+	roots := [len(trees)]merkle.Digest{vk.Qpp, proof.LRO, proof.Z, proof.H}
+	sizeFRI := rho * vk.Size
+	for q, index := range queryIndexes(seed, sizeFRI/2) {
+		query := &proof.Queries[q]
+		if len(query.Layers) != len(proof.Layers) {
+			return errInvalidProofSize
+		}
+
+		// open the polynomials at ±x, and compute the first layer of the low degree test
+		var x fr.Element
+		x.Exp(vk.GeneratorFRI, new(big.Int).SetUint64(index))
+		for i, t := range trees {
+			o := &query.Polynomials[i]
+			if len(o.Values) != 2*(t[1]-t[0]) {
+				return errInvalidProofSize
+			}
+			if !merkle.Verify(roots[i], leafBytes(o.Values), int(index), o.Path) {
+				return errInvalidOpening
+			}
+		}
+		var pair [2]fr.Element
+		for side := range pair {
+			var values [nbPolynomials]fr.Element
+			for i, t := range trees {
+				for j := t[0]; j < t[1]; j++ {
+					values[j] = query.Polynomials[i].Values[2*(j-t[0])+side]
+				}
+			}
+			var point fr.Element
+			if side == 0 {
+				point.Set(&x)
+			} else {
+				point.Neg(&x)
+			}
+			pair[side] = deepQuotient(&values, e, &point, &proof.ZShifted, &zeta, &zetaShifted, &nu)
+		}
+
+		// fold the layers down to the final value
+		omega := vk.GeneratorFRI
+		size := sizeFRI
+		for i := 0; i < nbFolds; i++ {
+			var xInv fr.Element
+			xInv.Inverse(&x)
+			folded := foldPair(&pair[0], &pair[1], &xInv, &foldingChallenges[i])
+			omega.Square(&omega)
+			size /= 2
+			index %= size // x² = ωᵢ₊₁^index
+			if i == nbFolds-1 {
+				pair[0] = folded
+				break
+			}
+			o := &query.Layers[i]
+			if len(o.Values) != 2 || !merkle.Verify(proof.Layers[i], leafBytes(o.Values), int(index%(size/2)), o.Path) {
+				return errInvalidOpening
+			}
+			side := index / (size / 2)
+			if !o.Values[side].Equal(&folded) {
+				return errLowDegreeTest
+			}
+			pair[0], pair[1] = o.Values[0], o.Values[1]
+			index %= size / 2
+			x.Exp(omega, new(big.Int).SetUint64(index))
+		}
+		if !pair[0].Equal(&proof.FinalValue) || (nbFolds == 0 && !pair[1].Equal(&proof.FinalValue)) {
+			return errLowDegreeTest
+		}
+	}
+
+	log.Debug().Dur("took", time.Since(start)).Msg("verifier done")
+
+	return nil
+}
+
+// orderingConstraint returns z(ωζ)⋅(l+β⋅s₁+γ)(r+β⋅s₂+γ)(o+β⋅s₃+γ) - z(ζ)⋅(l+β⋅ζ+γ)(r+β⋅u⋅ζ+γ)(o+β⋅u²⋅ζ+γ),
+// which is zero on the domain if z accumulates the copy constraints
+func orderingConstraint(l, r, o, s1, s2, s3, z, zShifted, zeta, beta, gamma, u fr.Element) fr.Element {
+	var f, g, t, id fr.Element
+	g.Mul(&s1, &beta).Add(&g, &l).Add(&g, &gamma)
+	t.Mul(&s2, &beta).Add(&t, &r).Add(&t, &gamma)
+	g.Mul(&g, &t)
+	t.Mul(&s3, &beta).Add(&t, &o).Add(&t, &gamma)
+	g.Mul(&g, &t).Mul(&g, &zShifted)
+
+	id.Mul(&zeta, &beta)
+	f.Add(&id, &l).Add(&f, &gamma)
+	id.Mul(&id, &u)
+	t.Add(&id, &r).Add(&t, &gamma)
+	f.Mul(&f, &t)
+	id.Mul(&id, &u)
+	t.Add(&id, &o).Add(&t, &gamma)
+	f.Mul(&f, &t).Mul(&f, &z)
+
+	return *g.Sub(&g, &f)
+}
+
+// deepQuotient returns the evaluation at x of the polynomial whose low degree
+// proves the claimed evaluations:
+//
+//	Σᵢ νⁱ(pᵢ(x)-pᵢ(ζ))/(x-ζ) + νᵐ(z(x)-z(ωζ))/(x-ωζ)
+func deepQuotient(values, evaluations *[nbPolynomials]fr.Element, x, zShifted, zeta, zetaShifted, nu *fr.Element) fr.Element {
+	var res, tmp, d fr.Element
+	for i := nbPolynomials - 1; i >= 0; i-- {
+		tmp.Sub(&values[i], &evaluations[i])
+		res.Mul(&res, nu).Add(&res, &tmp)
+	}
+	d.Sub(x, zeta).Inverse(&d)
+	res.Mul(&res, &d)
+
+	var nuPowerM fr.Element
+	nuPowerM.Exp(*nu, big.NewInt(nbPolynomials))
+	tmp.Sub(&values[idZ], zShifted).Mul(&tmp, &nuPowerM)
+	d.Sub(x, zetaShifted).Inverse(&d)
+	tmp.Mul(&tmp, &d)
+	return *res.Add(&res, &tmp)
+}
+
+// foldPair returns the evaluation at x² of the folded polynomial, from the
+// evaluations a, b of f at x, -x:
+//
+//	(a+b)/2 + α(a-b)/(2x)
+func foldPair(a, b, xInv, alpha *fr.Element) fr.Element {
+	var even, odd fr.Element
+	even.Add(a, b)
+	odd.Sub(a, b).Mul(&odd, xInv).Mul(&odd, alpha)
+	even.Add(&even, &odd)
+	return *even.Mul(&even, &twoInv)
+}
+
+var twoInv fr.Element
+
+func init() {
+	twoInv.SetUint64(2).Inverse(&twoInv)
+}
+
+// checkZeta returns an error if ζ is in the domain of the commitments, where the
+// quotients of the openings are not defined
+func checkZeta(vk *VerifyingKey, zeta *fr.Element) error {
+	var zetaPowerN fr.Element
+	zetaPowerN.Exp(*zeta, new(big.Int).SetUint64(rho*vk.Size))
+	if zetaPowerN.IsOne() {
+		return errors.New("zeta is in the domain of the commitments")
+	}
+	return nil
+}
+
+// queryIndexes returns the indexes in [0, size) of the queries, derived from seed
+func queryIndexes(seed []byte, size uint64) []uint64 {
+	res := make([]uint64, nbQueries)
+	var buf [4]byte
+	for i := range res {
+		h := sha256.New()
+		h.Write(seed)
+		binary.BigEndian.PutUint32(buf[:], uint32(i))
+		h.Write(buf[:])
+		res[i] = binary.BigEndian.Uint64(h.Sum(nil)) % size
+	}
+	return res
+}
+
+// leafBytes returns the leaf of the Merkle tree holding values
+func leafBytes(values []fr.Element) []byte {
+	res := make([]byte, 0, len(values)*fr.Bytes)
+	for i := range values {
+		b := values[i].Bytes()
+		res = append(res, b[:]...)
+	}
+	return res
+}
+
+func evaluationsBytes(proof *Proof) []byte {
+	return leafBytes(append(proof.Evaluations[:], proof.ZShifted))
+}
+
+func bindPublicData(fs *fiatshamir.Transcript, challenge string, vk *VerifyingKey, publicInputs []fr.Element) error {
+	if err := fs.Bind(challenge, vk.Qpp[:]); err != nil {
+		return err
+	}
+	for i := range publicInputs {
+		b := publicInputs[i].Bytes()
+		if err := fs.Bind(challenge, b[:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deriveRandomness(fs *fiatshamir.Transcript, challenge string, data ...[]byte) (fr.Element, error) {
+	var r fr.Element
+	for _, d := range data {
+		if err := fs.Bind(challenge, d); err != nil {
+			return r, err
+		}
+	}
+	b, err := fs.ComputeChallenge(challenge)
+	if err != nil {
+		return r, err
+	}
+	r.SetBytes(b)
+	return r, nil
+}
+
+// challengeIDs returns the Fiat-Shamir challenges of the protocol: the ones of
+// PLONK, ν to batch the openings, one per folding of the low degree test, and
+// the seed of the queries
+func challengeIDs(nbFolds int) []string {
+	res := []string{"gamma", "beta", "alpha", "zeta", "nu"}
+	for i := 0; i < nbFolds; i++ {
+		res = append(res, "fri"+strconv.Itoa(i))
+	}
+	return append(res, "query")
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
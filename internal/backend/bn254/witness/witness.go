@@ -166,3 +166,53 @@ func (witness *Witness) String() string {
 	sbb.WriteByte(']')
 	return sbb.String()
 }
+
+// readerChunk is the number of field elements decoded at once by a Reader.
+const readerChunk = 1 << 12
+
+// Reader gives access to a binary encoded witness (see WriteTo) stored in an
+// io.ReaderAt, typically a file, without decoding it in memory. It is used by
+// the solver to read the witness directly into the wire values.
+type Reader struct {
+	r      io.ReaderAt
+	length int
+}
+
+// NewReader reads the header of the binary encoded witness stored in r.
+func NewReader(r io.ReaderAt) (*Reader, error) {
+	var buf [4]byte
+	if n, err := r.ReadAt(buf[:], 0); n != len(buf) {
+		return nil, err
+	}
+	return &Reader{r: r, length: int(binary.BigEndian.Uint32(buf[:]))}, nil
+}
+
+// Len returns the number of field elements of the witness.
+func (reader *Reader) Len() int {
+	return reader.length
+}
+
+// ReadElements decodes the field elements of the witness starting at index
+// offset into dst.
+func (reader *Reader) ReadElements(dst []fr.Element, offset int) error {
+	if offset < 0 || offset+len(dst) > reader.length {
+		return fmt.Errorf("reading elements %d to %d of a witness of size %d", offset, offset+len(dst), reader.length)
+	}
+	buf := make([]byte, fr.Bytes*readerChunk)
+	for len(dst) > 0 {
+		n := len(dst)
+		if n > readerChunk {
+			n = readerChunk
+		}
+		b := buf[:fr.Bytes*n]
+		if read, err := reader.r.ReadAt(b, 4+int64(fr.Bytes)*int64(offset)); read != len(b) {
+			return err
+		}
+		for i := 0; i < n; i++ {
+			dst[i].SetBytes(b[fr.Bytes*i : fr.Bytes*(i+1)])
+		}
+		dst = dst[n:]
+		offset += n
+	}
+	return nil
+}
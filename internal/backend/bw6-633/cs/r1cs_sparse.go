@@ -17,6 +17,7 @@
 package cs
 
 import (
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"github.com/consensys/gnark-crypto/ecc"
@@ -34,7 +35,10 @@ import (
 	"github.com/consensys/gnark/frontend/compiled"
 	"github.com/consensys/gnark/frontend/schema"
 	"github.com/consensys/gnark/internal/backend/ioutils"
+	"github.com/consensys/gnark/internal/backend/prover"
+	gnarkio "github.com/consensys/gnark/io"
 	"github.com/consensys/gnark/logger"
+	"github.com/consensys/gnark/metrics"
 
 	"github.com/consensys/gnark-crypto/ecc/bw6-633/fr"
 
@@ -65,11 +69,15 @@ func NewSparseR1CS(ccs compiled.SparseR1CS, coefficients []big.Int) *SparseR1CS
 // solution.values =  [publicInputs | secretInputs | internalVariables ]
 // witness: contains the input variables
 // it returns the full slice of wires
-func (cs *SparseR1CS) Solve(witness []fr.Element, opt backend.ProverConfig) ([]fr.Element, error) {
+func (cs *SparseR1CS) Solve(witness []fr.Element, opt prover.Config) (_ []fr.Element, err error) {
 	log := logger.Logger().With().Str("curve", cs.CurveID().String()).Int("nbConstraints", len(cs.Constraints)).Str("backend", "plonk").Logger()
 
 	// set the slices holding the solution.values and monitoring which variables have been solved
 	nbVariables := cs.NbInternalVariables + cs.NbSecretVariables + cs.NbPublicVariables
+	m := metrics.Start(metrics.PhaseSolve, cs.CurveID(), backend.PLONK)
+	defer func() {
+		m.Stop(metrics.Event{NbConstraints: len(cs.Constraints), NbWires: nbVariables, Err: err})
+	}()
 
 	start := time.Now()
 
@@ -89,6 +97,7 @@ func (cs *SparseR1CS) Solve(witness []fr.Element, opt backend.ProverConfig) ([]f
 	if err != nil {
 		return solution.values, err
 	}
+	solution.trace = newSolverTrace(opt, cs.Counters)
 
 	// solution.values = [publicInputs | secretInputs | internalVariables ] -> we fill publicInputs | secretInputs
 	copy(solution.values, witness)
@@ -109,7 +118,7 @@ func (cs *SparseR1CS) Solve(witness []fr.Element, opt backend.ProverConfig) ([]f
 		coefficientsNegInv[i].Neg(&coefficientsNegInv[i])
 	}
 
-	if err := cs.parallelSolve(&solution, coefficientsNegInv); err != nil {
+	if err := cs.parallelSolve(&solution, coefficientsNegInv, &opt); err != nil {
 		if unsatisfiedErr, ok := err.(*UnsatisfiedConstraintError); ok {
 			log.Err(errors.New("unsatisfied constraint")).Int("id", unsatisfiedErr.CID).Send()
 		} else {
@@ -130,12 +139,20 @@ func (cs *SparseR1CS) Solve(witness []fr.Element, opt backend.ProverConfig) ([]f
 
 }
 
-func (cs *SparseR1CS) parallelSolve(solution *solution, coefficientsNegInv []fr.Element) error {
+func (cs *SparseR1CS) parallelSolve(solution *solution, coefficientsNegInv []fr.Element, opt *prover.Config) error {
 	// minWorkPerCPU is the minimum target number of constraint a task should hold
 	// in other words, if a level has less than minWorkPerCPU, it will not be parallelized and executed
 	// sequentially without sync.
 	const minWorkPerCPU = 50.0
 
+	// hintWork is the work of a constraint which calls a hint, counted in constraints
+	// which don't: a level of a few constraints calling hints may still be parallelized
+	const hintWork = 10
+
+	// nbTasksPerCPU is the number of tasks per CPU a level with hints is split in: the
+	// workers take the tasks as they are done, which balances the costs of the hints
+	const nbTasksPerCPU = 4
+
 	// cs.Levels has a list of levels, where all constraints in a level l(n) are independent
 	// and may only have dependencies on previous levels
 
@@ -143,6 +160,15 @@ func (cs *SparseR1CS) parallelSolve(solution *solution, coefficientsNegInv []fr.
 	chTasks := make(chan []int, runtime.NumCPU())
 	chError := make(chan *UnsatisfiedConstraintError, runtime.NumCPU())
 
+	// reportError keeps the first errors of the workers, which carry on with the
+	// next tasks of the level
+	reportError := func(err *UnsatisfiedConstraintError) {
+		select {
+		case chError <- err:
+		default:
+		}
+	}
+
 	// start a worker pool
 	// each worker wait on chTasks
 	// a task is a slice of constraint indexes to be solved
@@ -152,19 +178,12 @@ func (cs *SparseR1CS) parallelSolve(solution *solution, coefficientsNegInv []fr.
 				for _, i := range t {
 					// for each constraint in the task, solve it.
 					if err := cs.solveConstraint(cs.Constraints[i], solution, coefficientsNegInv); err != nil {
-						chError <- &UnsatisfiedConstraintError{CID: i, Err: err}
-						wg.Done()
-						return
+						reportError(cs.unsatisfiedConstraintError(i, err, solution, false))
+						break
 					}
 					if err := cs.checkConstraint(cs.Constraints[i], solution); err != nil {
-						if dID, ok := cs.MDebug[i]; ok {
-							errMsg := solution.logValue(cs.DebugInfo[dID])
-							chError <- &UnsatisfiedConstraintError{CID: i, DebugInfo: &errMsg}
-						} else {
-							chError <- &UnsatisfiedConstraintError{CID: i, Err: err}
-						}
-						wg.Done()
-						return
+						reportError(cs.unsatisfiedConstraintError(i, err, solution, true))
+						break
 					}
 				}
 				wg.Done()
@@ -179,23 +198,36 @@ func (cs *SparseR1CS) parallelSolve(solution *solution, coefficientsNegInv []fr.
 	}()
 
 	// for each level, we push the tasks
-	for _, level := range cs.Levels {
+	for l, level := range cs.Levels {
+		if err := opt.Checkpoint(prover.PhaseSolve, l, len(cs.Levels)); err != nil {
+			return err
+		}
 
-		// max CPU to use
-		maxCPU := float64(len(level)) / minWorkPerCPU
+		// max CPU to use, the constraints calling hints weighing more than the others
+		nbHints := 0
+		if l < len(cs.LevelsNbHints) {
+			nbHints = cs.LevelsNbHints[l]
+		}
+		maxCPU := float64(len(level)+(hintWork-1)*nbHints) / minWorkPerCPU
 
-		if maxCPU <= 1.0 {
-			// we do it sequentially
+		if maxCPU <= 1.0 || solution.trace != nil {
+			// we do it sequentially (always when tracing, to keep the order of the constraints)
 			for _, i := range level {
-				if err := cs.solveConstraint(cs.Constraints[i], solution, coefficientsNegInv); err != nil {
-					return &UnsatisfiedConstraintError{CID: i, Err: err}
+				c := cs.Constraints[i]
+				traced := solution.trace.start(i)
+				if traced {
+					solution.trace.printf("constraint #%d: %s\n", i, cs.constraintString(c))
 				}
-				if err := cs.checkConstraint(cs.Constraints[i], solution); err != nil {
-					if dID, ok := cs.MDebug[i]; ok {
-						errMsg := solution.logValue(cs.DebugInfo[dID])
-						return &UnsatisfiedConstraintError{CID: i, DebugInfo: &errMsg}
-					}
-					return &UnsatisfiedConstraintError{CID: i, Err: err}
+				if err := cs.solveConstraint(c, solution, coefficientsNegInv); err != nil {
+					solution.trace.printf("\tnot satisfied: %v\n", err)
+					return cs.unsatisfiedConstraintError(i, err, solution, false)
+				}
+				if traced {
+					solution.trace.printf("\txa = %s, xb = %s, xc = %s\n", solution.wireValue(c.L), solution.wireValue(c.R), solution.wireValue(c.O))
+				}
+				if err := cs.checkConstraint(c, solution); err != nil {
+					solution.trace.printf("\tnot satisfied: %v\n", err)
+					return cs.unsatisfiedConstraintError(i, err, solution, true)
 				}
 			}
 			continue
@@ -204,6 +236,9 @@ func (cs *SparseR1CS) parallelSolve(solution *solution, coefficientsNegInv []fr.
 		// number of tasks for this level is set to num cpus
 		// but if we don't have enough work for all our CPUS, it can be lower.
 		nbTasks := runtime.NumCPU()
+		if nbHints != 0 {
+			nbTasks *= nbTasksPerCPU
+		}
 		maxTasks := int(math.Ceil(maxCPU))
 		if nbTasks > maxTasks {
 			nbTasks = maxTasks
@@ -229,8 +264,8 @@ func (cs *SparseR1CS) parallelSolve(solution *solution, coefficientsNegInv []fr.
 				extraTasks--
 				extraTasksOffset++
 			}
-			// since we're never pushing more than num CPU tasks
-			// we will never be blocked here
+			// we may push more tasks than num CPU: we are then
+			// blocked until the workers take them
 			chTasks <- level[_start:_end]
 		}
 
@@ -242,7 +277,7 @@ func (cs *SparseR1CS) parallelSolve(solution *solution, coefficientsNegInv []fr.
 		}
 	}
 
-	return nil
+	return opt.Checkpoint(prover.PhaseSolve, len(cs.Levels), len(cs.Levels))
 }
 
 // computeHints computes wires associated with a hint function, if any
@@ -293,6 +328,13 @@ func (cs *SparseR1CS) computeHints(c compiled.SparseR1C, solution *solution) (in
 // if it doesn't, then this function returns and does nothing
 func (cs *SparseR1CS) solveConstraint(c compiled.SparseR1C, solution *solution, coefficientsNegInv []fr.Element) error {
 
+	if c.Gate != 0 {
+		return cs.solveGateWires(c, solution)
+	}
+	if c.Table != 0 {
+		return cs.solveLookup(c, solution)
+	}
+
 	lro, err := cs.computeHints(c, solution)
 	if err != nil {
 		return err
@@ -360,10 +402,76 @@ func (cs *SparseR1CS) solveConstraint(c compiled.SparseR1C, solution *solution,
 	return nil
 }
 
+// solveGateWires computes the hint wires of a custom gate constraint, if any.
+// A custom gate doesn't solve wires: all other wires must be instantiated by previous constraints.
+func (cs *SparseR1CS) solveGateWires(c compiled.SparseR1C, solution *solution) error {
+	for _, wID := range [3]int{c.L.WireID(), c.R.WireID(), c.O.WireID()} {
+		if solution.solved[wID] {
+			continue
+		}
+		hint, ok := cs.MHints[wID]
+		if !ok {
+			return fmt.Errorf("custom gate %s: wire %d is not instantiated", cs.Gates[c.Gate-1].Name, wID)
+		}
+		if err := solution.solveWithHint(wID, hint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// solveLookup computes the result wire of a lookup constraint, and the hint wires, if any.
+// The index and table wires must be instantiated by previous constraints.
+func (cs *SparseR1CS) solveLookup(c compiled.SparseR1C, solution *solution) error {
+	table := &cs.Tables[c.Table-1]
+	for _, wID := range [2]int{c.L.WireID(), c.R.WireID()} {
+		if solution.solved[wID] {
+			continue
+		}
+		hint, ok := cs.MHints[wID]
+		if !ok {
+			return fmt.Errorf("lookup %s: wire %d is not instantiated", table.Name, wID)
+		}
+		if err := solution.solveWithHint(wID, hint); err != nil {
+			return err
+		}
+	}
+
+	oID := c.O.WireID()
+	if solution.solved[oID] {
+		return nil
+	}
+	index := solution.values[c.L.WireID()]
+	if !index.IsUint64() || index.Uint64() >= uint64(len(table.Values)) {
+		return fmt.Errorf("lookup %s: index %s out of range", table.Name, index.String())
+	}
+	solution.set(oID, cs.Coefficients[table.Values[index.Uint64()]])
+	return nil
+}
+
+// evaluateGate returns G(l, r, o) where G is the custom gate of index gID
+func (cs *SparseR1CS) evaluateGate(gID int, l, r, o fr.Element) fr.Element {
+	var res, m fr.Element
+	for _, t := range cs.Gates[gID].Terms {
+		m.Set(&cs.Coefficients[t.CoeffID])
+		for i := 0; i < t.L; i++ {
+			m.Mul(&m, &l)
+		}
+		for i := 0; i < t.R; i++ {
+			m.Mul(&m, &r)
+		}
+		for i := 0; i < t.O; i++ {
+			m.Mul(&m, &o)
+		}
+		res.Add(&res, &m)
+	}
+	return res
+}
+
 // IsSolved returns nil if given witness solves the SparseR1CS and error otherwise
 // this method wraps cs.Solve() and allocates cs.Solve() inputs
-func (cs *SparseR1CS) IsSolved(witness *witness.Witness, opts ...backend.ProverOption) error {
-	opt, err := backend.NewProverConfig(opts...)
+func (cs *SparseR1CS) IsSolved(witness *witness.Witness, opts ...prover.Option) error {
+	opt, err := prover.NewConfig(opts...)
 	if err != nil {
 		return err
 	}
@@ -373,15 +481,29 @@ func (cs *SparseR1CS) IsSolved(witness *witness.Witness, opts ...backend.ProverO
 	return err
 }
 
+// Print writes the constraints on w, one per line, with the names of the
+// inputs of the circuit (see compiled.SparseR1CS.Print)
+func (cs *SparseR1CS) Print(w io.Writer) error {
+	coeffs := make([]big.Int, len(cs.Coefficients))
+	for i := range cs.Coefficients {
+		cs.Coefficients[i].ToBigIntRegular(&coeffs[i])
+	}
+	return cs.SparseR1CS.Print(w, coeffs, fr.Modulus())
+}
+
 // GetConstraints return a list of constraint formatted as in the paper
 // https://eprint.iacr.org/2019/953.pdf section 6 such that
 // qL⋅xa + qR⋅xb + qO⋅xc + qM⋅(xaxb) + qC == 0
 // each constraint is thus decomposed in [5]string with
-// 		[0] = qL⋅xa
-//		[1] = qR⋅xb
-//		[2] = qO⋅xc
-//		[3] = qM⋅(xaxb)
-//		[4] = qC
+//
+//	[0] = qL⋅xa
+//	[1] = qR⋅xb
+//	[2] = qO⋅xc
+//	[3] = qM⋅(xaxb)
+//	[4] = qC
+//
+// custom gates G(xa, xb, xc) == 0 are formatted in [3], with zeroes elsewhere
+// lookups xc == T[xa] are formatted in [3], with zeroes elsewhere
 func (cs *SparseR1CS) GetConstraints() [][]string {
 	r := make([][]string, 0, len(cs.Constraints))
 	for _, c := range cs.Constraints {
@@ -391,12 +513,56 @@ func (cs *SparseR1CS) GetConstraints() [][]string {
 	return r
 }
 
+// constraintString returns the constraint c in a readable form: qL⋅xa +
+// qR⋅xb + qO⋅xc + qM⋅(xaxb) + qC == 0 without the zero terms, G(xa, xb, xc)
+// == 0 for a custom gate, or xc == T[xa] for a lookup.
+func (cs *SparseR1CS) constraintString(c compiled.SparseR1C) string {
+	fc := cs.formatConstraint(c)
+	if c.Table != 0 {
+		return fc[3]
+	}
+	terms := make([]string, 0, len(fc))
+	for _, t := range fc {
+		if t != "" && t != "0" {
+			terms = append(terms, t)
+		}
+	}
+	if len(terms) == 0 {
+		return "0 == 0"
+	}
+	return strings.Join(terms, " + ") + " == 0"
+}
+
 // r[0] = qL⋅xa
 // r[1] = qR⋅xb
 // r[2] = qO⋅xc
 // r[3] = qM⋅(xaxb)
 // r[4] = qC
 func (cs *SparseR1CS) formatConstraint(c compiled.SparseR1C) (r [5]string) {
+	if c.Gate != 0 {
+		// custom gate: r[3] = G(xa, xb, xc)
+		var sbb strings.Builder
+		sbb.WriteString(cs.Gates[c.Gate-1].Name)
+		sbb.WriteByte('(')
+		cs.termToString(c.L, &sbb, true)
+		sbb.WriteString(", ")
+		cs.termToString(c.R, &sbb, true)
+		sbb.WriteString(", ")
+		cs.termToString(c.O, &sbb, true)
+		sbb.WriteByte(')')
+		return [5]string{"0", "0", "0", sbb.String(), "0"}
+	}
+	if c.Table != 0 {
+		// lookup: r[3] = xc == T[xa]
+		var sbb strings.Builder
+		cs.termToString(c.O, &sbb, true)
+		sbb.WriteString(" == ")
+		sbb.WriteString(cs.Tables[c.Table-1].Name)
+		sbb.WriteByte('[')
+		cs.termToString(c.L, &sbb, true)
+		sbb.WriteByte(']')
+		return [5]string{"0", "0", "0", sbb.String(), "0"}
+	}
 	isZeroM := (c.M[0].CoeffID() == compiled.CoeffIdZero) && (c.M[1].CoeffID() == compiled.CoeffIdZero)
 
 	var sbb strings.Builder
@@ -467,8 +633,57 @@ func (cs *SparseR1CS) termToString(t compiled.Term, sbb *strings.Builder, vOnly
 	}
 }
 
+// unsatisfiedConstraintError returns the error of the constraint i, which the
+// solver failed to solve, or to check if checked is set, with err.
+func (cs *SparseR1CS) unsatisfiedConstraintError(i int, err error, solution *solution, checked bool) *UnsatisfiedConstraintError {
+	r := &UnsatisfiedConstraintError{CID: i, Err: err}
+	if l, ok := cs.DebugEntry(i); ok {
+		r.Caller = l.Caller
+		if checked {
+			debugInfo := solution.logValue(l)
+			r.DebugInfo = &debugInfo
+		}
+	}
+	c := cs.Constraints[i]
+	r.Wires = solution.wires(&cs.ConstraintSystem, false, c.L, c.R, c.M[0], c.M[1], c.O)
+	return r
+}
+
 // checkConstraint verifies that the constraint holds
 func (cs *SparseR1CS) checkConstraint(c compiled.SparseR1C, solution *solution) error {
+	if c.Gate != 0 {
+		l := solution.values[c.L.WireID()]
+		r := solution.values[c.R.WireID()]
+		o := solution.values[c.O.WireID()]
+		if t := cs.evaluateGate(c.Gate-1, l, r, o); !t.IsZero() {
+			return fmt.Errorf("%s(xa, xb, xc) != 0 → %s(%s, %s, %s) != 0",
+				cs.Gates[c.Gate-1].Name,
+				cs.Gates[c.Gate-1].Name,
+				l.String(),
+				r.String(),
+				o.String(),
+			)
+		}
+		return nil
+	}
+	if c.Table != 0 {
+		table := &cs.Tables[c.Table-1]
+		l := solution.values[c.L.WireID()]
+		r := solution.values[c.R.WireID()]
+		o := solution.values[c.O.WireID()]
+		var tID fr.Element
+		tID.SetUint64(uint64(c.Table - 1))
+		if !l.IsUint64() || l.Uint64() >= uint64(len(table.Values)) || !r.Equal(&tID) ||
+			!o.Equal(&cs.Coefficients[table.Values[l.Uint64()]]) {
+			return fmt.Errorf("xc != %s[xa] → %s != %s[%s]",
+				table.Name,
+				o.String(),
+				table.Name,
+				l.String(),
+			)
+		}
+		return nil
+	}
 	l := solution.computeTerm(c.L)
 	r := solution.computeTerm(c.R)
 	m0 := solution.computeTerm(c.M[0])
@@ -502,17 +717,39 @@ func (cs *SparseR1CS) GetNbCoefficients() int {
 	return len(cs.Coefficients)
 }
 
+// Fingerprint returns a SHA-256 hash of the circuit of the constraint system: its
+// constraints, coefficients, hints and commitment, over the curve. It doesn't
+// depend on the debug info nor on the names of the inputs, and is stable across
+// serializations, so that it identifies the circuit a PLONK key is set up
+// for (see VerifyingKey.CircuitFingerprint).
+func (cs *SparseR1CS) Fingerprint() [32]byte {
+	h := sha256.New()
+	cs.SparseR1CS.WriteFingerprint(h)
+	for i := range cs.Coefficients {
+		b := cs.Coefficients[i].Bytes()
+		h.Write(b[:])
+	}
+	var res [32]byte
+	copy(res[:], h.Sum(nil))
+	return res
+}
+
 // CurveID returns curve ID as defined in gnark-crypto (ecc.BW6-633)
 func (cs *SparseR1CS) CurveID() ecc.ID {
 	return ecc.BW6_633
 }
 
 // WriteTo encodes SparseR1CS into provided io.Writer using cbor
+// The levels of the solver (see compiled.ConstraintSystem.Levels) are encoded with
+// the constraints, and reused by the solver once read back by ReadFrom.
 func (cs *SparseR1CS) WriteTo(w io.Writer) (int64, error) {
 	_w := ioutils.WriterCounter{W: w} // wraps writer to count the bytes written
+	if _, err := gnarkio.WriteHeader(&_w, cs.CurveID(), backend.PLONK, gnarkio.KindConstraintSystem); err != nil {
+		return _w.N, err
+	}
 	enc, err := cbor.CoreDetEncOptions().EncMode()
 	if err != nil {
-		return 0, err
+		return _w.N, err
 	}
 	encoder := enc.NewEncoder(&_w)
 
@@ -523,14 +760,18 @@ func (cs *SparseR1CS) WriteTo(w io.Writer) (int64, error) {
 
 // ReadFrom attempts to decode SparseR1CS from io.Reader using cbor
 func (cs *SparseR1CS) ReadFrom(r io.Reader) (int64, error) {
+	r, _, n, err := gnarkio.ReadHeader(r, cs.CurveID(), backend.PLONK, gnarkio.KindConstraintSystem)
+	if err != nil {
+		return n, err
+	}
 	dm, err := cbor.DecOptions{
 		MaxArrayElements: 134217728,
 		MaxMapPairs:      134217728,
 	}.DecMode()
 	if err != nil {
-		return 0, err
+		return n, err
 	}
 	decoder := dm.NewDecoder(r)
 	err = decoder.Decode(cs)
-	return int64(decoder.NumBytesRead()), err
+	return n + int64(decoder.NumBytesRead()), err
 }
@@ -21,6 +21,7 @@ import (
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
 	"github.com/consensys/gnark/internal/backend/circuits"
 	"reflect"
 	"testing"
@@ -116,6 +117,65 @@ func TestSerialization(t *testing.T) {
 	}
 }
 
+// levelsCircuit calls hints at several levels of the solver
+type levelsCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *levelsCircuit) Define(api frontend.API) error {
+	x := api.Mul(circuit.X, circuit.X)
+	bits := api.ToBinary(x, 16)
+	y := api.Add(api.Inverse(api.Add(x, 1)), bits[0])
+	api.AssertIsEqual(api.IsZero(y), circuit.Y)
+	return nil
+}
+
+func TestSerializationLevels(t *testing.T) {
+	// the levels of the solver are read back as they were written
+	for _, builder := range []frontend.NewBuilder{r1cs.NewBuilder, scs.NewBuilder} {
+		ccs, err := frontend.Compile(ecc.BW6_633, builder, &levelsCircuit{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buffer bytes.Buffer
+		if _, err := ccs.WriteTo(&buffer); err != nil {
+			t.Fatal(err)
+		}
+		var levels, reconstructedLevels [][]int
+		var levelsNbHints, reconstructedLevelsNbHints []int
+		switch ccs := ccs.(type) {
+		case *cs.R1CS:
+			var reconstructed cs.R1CS
+			if _, err := reconstructed.ReadFrom(&buffer); err != nil {
+				t.Fatal(err)
+			}
+			levels, levelsNbHints = ccs.Levels, ccs.LevelsNbHints
+			reconstructedLevels, reconstructedLevelsNbHints = reconstructed.Levels, reconstructed.LevelsNbHints
+		case *cs.SparseR1CS:
+			var reconstructed cs.SparseR1CS
+			if _, err := reconstructed.ReadFrom(&buffer); err != nil {
+				t.Fatal(err)
+			}
+			levels, levelsNbHints = ccs.Levels, ccs.LevelsNbHints
+			reconstructedLevels, reconstructedLevelsNbHints = reconstructed.Levels, reconstructed.LevelsNbHints
+		default:
+			t.Fatalf("unexpected constraint system %T", ccs)
+		}
+
+		if len(levels) < 2 || len(levelsNbHints) == 0 {
+			t.Fatal("the circuit should have several levels calling hints")
+		}
+		if !reflect.DeepEqual(levels, reconstructedLevels) {
+			t.Fatal("the levels don't match after a round trip")
+		}
+		if !reflect.DeepEqual(levelsNbHints, reconstructedLevelsNbHints) {
+			t.Fatal("the number of hints of the levels don't match after a round trip")
+		}
+	}
+}
+
 const n = 10000
 
 type circuit struct {
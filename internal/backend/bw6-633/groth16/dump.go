@@ -0,0 +1,197 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package groth16
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"unsafe"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bw6-633"
+
+	"github.com/consensys/gnark/internal/utils"
+)
+
+// WriteDump writes the ProvingKey in the memory layout of the running architecture.
+// ReadDump can then set the points of the key from a memory mapped dump, without
+// copying nor decoding them: Prove reads them from the file as needed.
+// A dump is not portable across architectures.
+func (pk *ProvingKey) WriteDump(w io.Writer) (int64, error) {
+	var domain bytes.Buffer
+	if _, err := pk.Domain.WriteTo(&domain); err != nil {
+		return 0, err
+	}
+
+	dw := utils.NewDumpWriter(w)
+	dw.WriteBytes(domain.Bytes())
+	dw.WriteBytes(g1Bytes([]curve.G1Affine{pk.G1.Alpha, pk.G1.Beta, pk.G1.Delta, pk.CommitmentKey.BlindingDelta}))
+	dw.WriteBytes(g2Bytes([]curve.G2Affine{pk.G2.Beta, pk.G2.Delta}))
+	dw.WriteBytes(g1Bytes(pk.G1.A))
+	dw.WriteBytes(g1Bytes(pk.G1.B))
+	dw.WriteBytes(g1Bytes(pk.G1.Z))
+	dw.WriteBytes(g1Bytes(pk.G1.K))
+	dw.WriteBytes(g2Bytes(pk.G2.B))
+	dw.WriteBytes(boolBytes(pk.InfinityA))
+	dw.WriteBytes(boolBytes(pk.InfinityB))
+	dw.WriteBytes(g1Bytes(pk.CommitmentKey.Basis))
+	dw.WriteBytes(g1Bytes(pk.CommitmentKey.BasisExpSigma))
+
+	return dw.BytesWritten()
+}
+
+// ReadDump sets the ProvingKey from a dump written by WriteDump.
+// The point slices of the key reference data, which must not be modified nor unmapped
+// while the key is in use. As UnsafeReadFrom, it doesn't perform any check on the points.
+func (pk *ProvingKey) ReadDump(data []byte) error {
+	dr, err := utils.NewDumpReader(data)
+	if err != nil {
+		return err
+	}
+
+	b, err := dr.ReadBytes()
+	if err != nil {
+		return err
+	}
+	if _, err := pk.Domain.ReadFrom(bytes.NewReader(b)); err != nil {
+		return err
+	}
+
+	readG1 := func() []curve.G1Affine {
+		if err != nil {
+			return nil
+		}
+		var b []byte
+		b, err = dr.ReadSlice(unsafe.Sizeof(curve.G1Affine{}))
+		return bytesToG1(b)
+	}
+	readG2 := func() []curve.G2Affine {
+		if err != nil {
+			return nil
+		}
+		var b []byte
+		b, err = dr.ReadSlice(unsafe.Sizeof(curve.G2Affine{}))
+		return bytesToG2(b)
+	}
+	readBool := func() []bool {
+		if err != nil {
+			return nil
+		}
+		var b []byte
+		if b, err = dr.ReadBytes(); err != nil {
+			return nil
+		}
+		var s []bool
+		s, err = bytesToBool(b)
+		return s
+	}
+
+	g1 := readG1()
+	g2 := readG2()
+	pk.G1.A = readG1()
+	pk.G1.B = readG1()
+	pk.G1.Z = readG1()
+	pk.G1.K = readG1()
+	pk.G2.B = readG2()
+	pk.InfinityA = readBool()
+	pk.InfinityB = readBool()
+	pk.CommitmentKey.Basis = readG1()
+	pk.CommitmentKey.BasisExpSigma = readG1()
+	if err != nil {
+		return err
+	}
+
+	if len(g1) != 4 || len(g2) != 2 {
+		return errors.New("invalid dump: wrong number of parameters")
+	}
+	pk.G1.Alpha, pk.G1.Beta, pk.G1.Delta = g1[0], g1[1], g1[2]
+	pk.CommitmentKey.BlindingDelta = g1[3]
+	pk.G2.Beta, pk.G2.Delta = g2[0], g2[1]
+
+	pk.NbInfinityA, pk.NbInfinityB = 0, 0
+	for i := range pk.InfinityA {
+		if pk.InfinityA[i] {
+			pk.NbInfinityA++
+		}
+	}
+	for i := range pk.InfinityB {
+		if pk.InfinityB[i] {
+			pk.NbInfinityB++
+		}
+	}
+	if len(pk.InfinityA) != len(pk.InfinityB) ||
+		len(pk.G1.A) != len(pk.InfinityA)-int(pk.NbInfinityA) ||
+		len(pk.G1.B) != len(pk.InfinityB)-int(pk.NbInfinityB) ||
+		len(pk.G2.B) != len(pk.G1.B) ||
+		len(pk.CommitmentKey.Basis) != len(pk.CommitmentKey.BasisExpSigma) {
+		return errors.New("invalid dump: inconsistent number of points")
+	}
+
+	return nil
+}
+
+func g1Bytes(s []curve.G1Affine) []byte {
+	if len(s) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(&s[0])), len(s)*int(unsafe.Sizeof(s[0])))
+}
+
+func g2Bytes(s []curve.G2Affine) []byte {
+	if len(s) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(&s[0])), len(s)*int(unsafe.Sizeof(s[0])))
+}
+
+func bytesToG1(b []byte) []curve.G1Affine {
+	if len(b) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*curve.G1Affine)(unsafe.Pointer(&b[0])), len(b)/int(unsafe.Sizeof(curve.G1Affine{})))
+}
+
+func bytesToG2(b []byte) []curve.G2Affine {
+	if len(b) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*curve.G2Affine)(unsafe.Pointer(&b[0])), len(b)/int(unsafe.Sizeof(curve.G2Affine{})))
+}
+
+func boolBytes(s []bool) []byte {
+	b := make([]byte, len(s))
+	for i := range s {
+		if s[i] {
+			b[i] = 1
+		}
+	}
+	return b
+}
+
+func bytesToBool(b []byte) ([]bool, error) {
+	s := make([]bool, len(b))
+	for i := range b {
+		if b[i] > 1 {
+			return nil, errors.New("invalid dump: invalid boolean")
+		}
+		s[i] = b[i] == 1
+	}
+	return s, nil
+}
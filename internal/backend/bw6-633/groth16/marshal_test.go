@@ -19,12 +19,11 @@ package groth16
 import (
 	curve "github.com/consensys/gnark-crypto/ecc/bw6-633"
 
-	"github.com/consensys/gnark-crypto/ecc/bw6-633/fr/fft"
-
 	"bytes"
 	"math/big"
 	"reflect"
 
+	gnarkio "github.com/consensys/gnark/io"
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/prop"
 
@@ -166,85 +165,39 @@ func TestVerifyingKeySerialization(t *testing.T) {
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
 }
 
-func TestProvingKeySerialization(t *testing.T) {
-	parameters := gopter.DefaultTestParameters()
-	parameters.MinSuccessfulTests = 10
-
-	properties := gopter.NewProperties(parameters)
-
-	properties.Property("ProvingKey -> writer -> reader -> ProvingKey should stay constant", prop.ForAll(
-		func(p1 curve.G1Affine, p2 curve.G2Affine) bool {
-			var pk, pkCompressed, pkRaw ProvingKey
-
-			// create a random pk
-			domain := fft.NewDomain(8)
-			pk.Domain = *domain
-
-			nbWires := 6
-			nbPrivateWires := 4
-
-			// allocate our slices
-			pk.G1.A = make([]curve.G1Affine, nbWires)
-			pk.G1.B = make([]curve.G1Affine, nbWires)
-			pk.G1.K = make([]curve.G1Affine, nbPrivateWires)
-			pk.G1.Z = make([]curve.G1Affine, pk.Domain.Cardinality)
-			pk.G2.B = make([]curve.G2Affine, nbWires)
-
-			pk.G1.Alpha = p1
-			pk.G2.Beta = p2
-			pk.G1.K[1] = p1
-			pk.G1.B[0] = p1
-			pk.G2.B[0] = p2
-
-			// infinity flags
-			pk.NbInfinityA = 1
-			pk.InfinityA = make([]bool, nbWires)
-			pk.InfinityB = make([]bool, nbWires)
-			pk.InfinityA[2] = true
-
-			var bufCompressed bytes.Buffer
-			written, err := pk.WriteTo(&bufCompressed)
-			if err != nil {
-				t.Log(err)
-				return false
-			}
-
-			read, err := pkCompressed.ReadFrom(&bufCompressed)
-			if err != nil {
-				t.Log(err)
-				return false
-			}
-
-			if read != written {
-				t.Log("read != written")
-				return false
-			}
-
-			var bufRaw bytes.Buffer
-			written, err = pk.WriteRawTo(&bufRaw)
-			if err != nil {
-				t.Log(err)
-				return false
-			}
-
-			read, err = pkRaw.ReadFrom(&bufRaw)
-			if err != nil {
-				t.Log(err)
-				return false
-			}
+func TestVerifyingKeyFormerEncodings(t *testing.T) {
+	_, _, g1, g2 := curve.Generators()
+	var vk VerifyingKey
+	vk.G1.Alpha, vk.G1.Beta, vk.G1.Delta = g1, g1, g1
+	vk.G2.Beta, vk.G2.Gamma, vk.G2.Delta = g2, g2, g2
+	vk.G1.K = []curve.G1Affine{g1, g1}
+	vk.CircuitFingerprint[0] = 1
+	if err := vk.Precompute(); err != nil {
+		t.Fatal(err)
+	}
 
-			if read != written {
-				t.Log("read raw != written")
-				return false
-			}
+	var buf bytes.Buffer
+	if _, err := vk.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
 
-			return reflect.DeepEqual(&pk, &pkCompressed) && reflect.DeepEqual(&pk, &pkRaw)
-		},
-		GenG1(),
-		GenG2(),
-	))
+	// the keys written before the fingerprint was recorded end before it
+	former := append([]byte{}, buf.Bytes()[:buf.Len()-len(vk.CircuitFingerprint)]...)
+	former[4] = gnarkio.VersionCircuitFingerprint - 1
+	var reconstructed VerifyingKey
+	if _, err := reconstructed.ReadFrom(bytes.NewReader(former)); err != nil {
+		t.Fatal("couldn't deserialize", err)
+	}
+	expected := vk
+	expected.CircuitFingerprint = [32]byte{}
+	if !reflect.DeepEqual(&expected, &reconstructed) {
+		t.Fatal("reconstructed object don't match original")
+	}
 
-	properties.TestingRun(t, gopter.ConsoleReporter(false))
+	// while the current keys must hold it
+	if _, err := reconstructed.ReadFrom(bytes.NewReader(buf.Bytes()[:buf.Len()-1])); err == nil {
+		t.Fatal("expected an error reading a truncated key")
+	}
 }
 
 func GenG1() gopter.Gen {
@@ -1,3 +1,6 @@
+//go:build !verifieronly
+// +build !verifieronly
+
 // Copyright 2020 ConsenSys Software Inc.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
@@ -25,60 +28,259 @@ import (
 
 	"github.com/consensys/gnark-crypto/ecc/bw6-633/fr/fft"
 
+	"bytes"
 	"fmt"
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/accelerator"
+	"github.com/consensys/gnark/backend/hint"
 	bw6_633witness "github.com/consensys/gnark/internal/backend/bw6-633/witness"
 	"github.com/consensys/gnark/internal/utils"
 	"github.com/consensys/gnark/logger"
+	"github.com/consensys/gnark/metrics"
 	"math/big"
-	"runtime"
+	"sync"
 	"time"
 )
 
-// Proof represents a Groth16 proof that was encoded with a ProvingKey and can be verified
-// with a valid statement and a VerifyingKey
-// Notation follows Figure 4. in DIZK paper https://eprint.iacr.org/2018/691.pdf
-type Proof struct {
-	Ar, Krs curve.G1Affine
-	Bs      curve.G2Affine
+// Prove generates the proof of knoweldge of a r1cs with full witness (secret + public part).
+func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bw6_633witness.Witness, opt backend.ProverConfig) (*Proof, error) {
+	if len(witness) != int(r1cs.NbPublicVariables-1+r1cs.NbSecretVariables) {
+		return nil, fmt.Errorf("invalid witness size, got %d, expected %d = %d (public) + %d (secret)", len(witness), int(r1cs.NbPublicVariables-1+r1cs.NbSecretVariables), r1cs.NbPublicVariables, r1cs.NbSecretVariables)
+	}
+	return prove(r1cs, pk, func(a, b, c []fr.Element, opt backend.ProverConfig) ([]fr.Element, error) {
+		return r1cs.Solve(witness, a, b, c, opt)
+	}, opt)
 }
 
-// isValid ensures proof elements are in the correct subgroup
-func (proof *Proof) isValid() bool {
-	return proof.Ar.IsInSubGroup() && proof.Krs.IsInSubGroup() && proof.Bs.IsInSubGroup()
+// ProveReader is like Prove, but the full witness is read by the solver from
+// witness, see cs.R1CS.SolveReader.
+func ProveReader(r1cs *cs.R1CS, pk *ProvingKey, witness *bw6_633witness.Reader, opt backend.ProverConfig) (*Proof, error) {
+	if witness.Len() != int(r1cs.NbPublicVariables-1+r1cs.NbSecretVariables) {
+		return nil, fmt.Errorf("invalid witness size, got %d, expected %d = %d (public) + %d (secret)", witness.Len(), int(r1cs.NbPublicVariables-1+r1cs.NbSecretVariables), r1cs.NbPublicVariables, r1cs.NbSecretVariables)
+	}
+	return prove(r1cs, pk, func(a, b, c []fr.Element, opt backend.ProverConfig) ([]fr.Element, error) {
+		return r1cs.SolveReader(witness, a, b, c, opt)
+	}, opt)
 }
 
-// CurveID returns the curveID
-func (proof *Proof) CurveID() ecc.ID {
-	return curve.ID
+// ProveBatch generates the proofs of knowledge of r1cs with the full witnesses.
+//
+// The proofs are pipelined: the witness of a proof is solved and reduced (FFTs) while
+// the MSMs of the previous one are computed, and the scratch buffers of a proof are
+// reused by the next ones, through opt.BufferPool if it is set.
+//
+// The progress is reported for the whole batch: the percentage of a phase is the
+// share of this phase done over all the witnesses, see batchProgress.
+func ProveBatch(r1cs *cs.R1CS, pk *ProvingKey, witnesses []bw6_633witness.Witness, opt backend.ProverConfig) ([]*Proof, error) {
+	for i, witness := range witnesses {
+		if len(witness) != int(r1cs.NbPublicVariables-1+r1cs.NbSecretVariables) {
+			return nil, fmt.Errorf("witness %d: invalid witness size, got %d, expected %d = %d (public) + %d (secret)", i, len(witness), int(r1cs.NbPublicVariables-1+r1cs.NbSecretVariables), r1cs.NbPublicVariables, r1cs.NbSecretVariables)
+		}
+	}
+	if opt.BufferPool == nil {
+		opt.BufferPool = backend.NewBufferPool(0)
+	}
+	witnessOpt := batchProgress(opt, len(witnesses))
+
+	// the reductions of the witnesses are computed one ahead of the MSMs
+	type result struct {
+		reduction *reduction
+		err       error
+		m         *metrics.Measurement
+	}
+	reductions := make(chan result)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(reductions)
+		for i := range witnesses {
+			witness := witnesses[i]
+			m := metrics.Start(metrics.PhaseProve, r1cs.CurveID(), backend.GROTH16)
+			red, err := reduce(r1cs, pk, func(a, b, c []fr.Element, opt backend.ProverConfig) ([]fr.Element, error) {
+				return r1cs.Solve(witness, a, b, c, opt)
+			}, witnessOpt(i))
+			select {
+			case reductions <- result{red, err, m}:
+			case <-done:
+				m.Stop(pk.proveEvent(r1cs, fmt.Errorf("witness %d: not proven, a previous witness failed", i)))
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	proofs := make([]*Proof, 0, len(witnesses))
+	for res := range reductions {
+		if res.err != nil {
+			res.m.Stop(pk.proveEvent(r1cs, res.err))
+			return nil, fmt.Errorf("witness %d: %w", len(proofs), res.err)
+		}
+		proof, err := res.reduction.prove(r1cs, pk, witnessOpt(len(proofs)))
+		res.m.Stop(pk.proveEvent(r1cs, err))
+		if err != nil {
+			return nil, fmt.Errorf("witness %d: %w", len(proofs), err)
+		}
+		proofs = append(proofs, proof)
+	}
+	return proofs, nil
 }
 
-// Prove generates the proof of knoweldge of a r1cs with full witness (secret + public part).
-func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bw6_633witness.Witness, opt backend.ProverConfig) (*Proof, error) {
-	if len(witness) != int(r1cs.NbPublicVariables-1+r1cs.NbSecretVariables) {
-		return nil, fmt.Errorf("invalid witness size, got %d, expected %d = %d (public) + %d (secret)", len(witness), int(r1cs.NbPublicVariables-1+r1cs.NbSecretVariables), r1cs.NbPublicVariables, r1cs.NbSecretVariables)
+// batchProgress returns the prover configuration of the i-th of n witnesses
+// proven by ProveBatch: it reports the progress of phase for the witness i at
+// percent as (100 * i + percent) / n for the batch. As the witnesses go through
+// each phase in order, this is non-decreasing for a phase, even though the phases
+// of two witnesses interleave, and reaches 100% once the last witness is done.
+func batchProgress(opt backend.ProverConfig, n int) func(i int) backend.ProverConfig {
+	if opt.Progress == nil || n <= 1 {
+		return func(int) backend.ProverConfig { return opt }
+	}
+	progress := opt.Progress
+	var lock sync.Mutex
+	last := make(map[backend.ProverPhase]int)
+	return func(i int) backend.ProverConfig {
+		witnessOpt := opt
+		witnessOpt.Progress = func(phase backend.ProverPhase, percent int) {
+			percent = (100*i + percent) / n
+			lock.Lock()
+			defer lock.Unlock()
+			if l, ok := last[phase]; ok && percent <= l {
+				return
+			}
+			last[phase] = percent
+			progress(phase, percent)
+		}
+		return witnessOpt
 	}
+}
 
-	log := logger.Logger().With().Str("curve", r1cs.CurveID().String()).Int("nbConstraints", len(r1cs.Constraints)).Str("backend", "groth16").Logger()
+// prove implements Prove, the wire values being computed by solve.
+func prove(r1cs *cs.R1CS, pk *ProvingKey, solve func(a, b, c []fr.Element, opt backend.ProverConfig) ([]fr.Element, error), opt backend.ProverConfig) (proof *Proof, err error) {
+	m := metrics.Start(metrics.PhaseProve, r1cs.CurveID(), backend.GROTH16)
+	defer func() {
+		m.Stop(pk.proveEvent(r1cs, err))
+	}()
+
+	red, err := reduce(r1cs, pk, solve, opt)
+	if err != nil {
+		return nil, err
+	}
+	return red.prove(r1cs, pk, opt)
+}
+
+// proveEvent returns the metrics.Event of a proof of r1cs with pk ending with err
+func (pk *ProvingKey) proveEvent(r1cs *cs.R1CS, err error) metrics.Event {
+	// the largest MSM is the one of the largest section of points
+	msmSize := 0
+	for section := SectionA; section < nbSections; section++ {
+		if n := pk.nbPoints(section); n > msmSize {
+			msmSize = n
+		}
+	}
+	return metrics.Event{
+		Err:           err,
+		NbConstraints: len(r1cs.Constraints),
+		NbWires:       r1cs.NbInternalVariables + r1cs.NbPublicVariables + r1cs.NbSecretVariables,
+		MSMSize:       msmSize,
+	}
+}
+
+// reduction holds what the MSMs of a proof need, computed from its witness by reduce
+type reduction struct {
+	proof *Proof
+
+	// the wire values in regular form, and the ones multiplied by the points
+	// of pk.G1.A and pk.G1.B which are not at infinity
+	wireValues, wireValuesA, wireValuesB []fr.Element
+
+	// h, the coefficients of the quotient (ab-c)/z, reuses the memory of a
+	h, b, c []fr.Element
+
+	// the randomness of the proof, and r[δ], s[δ], kr[δ]
+	r, s   big.Int
+	deltas []curve.G1Affine
+
+	start time.Time
+}
+
+// reduce solves r1cs with solve, and computes the wire values and the quotient h
+// of the proof (witness reduction / FFT part).
+func reduce(r1cs *cs.R1CS, pk *ProvingKey, solve func(a, b, c []fr.Element, opt backend.ProverConfig) ([]fr.Element, error), opt backend.ProverConfig) (*reduction, error) {
+	proof := &Proof{}
+
+	// the commitment wire is computed from the commitment to the committed wires,
+	// which the solver gives to its hint. The commitment is blinded by a random
+	// multiple of the last element of the commitment key, derived from the committed
+	// values in deterministic mode.
+	if r1cs.Commitment.Is() {
+		hintFunctions := make(map[hint.ID]hint.Function, len(opt.HintFunctions)+1)
+		for id, f := range opt.HintFunctions {
+			hintFunctions[id] = f
+		}
+		hintFunctions[r1cs.Commitment.HintID] = func(_ ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+			values := make([]fr.Element, len(inputs)+1)
+			for i := 0; i < len(inputs); i++ {
+				values[i].SetBigInt(inputs[i])
+			}
+			rng := opt.RandomSource
+			if opt.RandomKey != nil {
+				w := bw6_633witness.Witness(values[:len(inputs)])
+				buf := bytes.NewBufferString("commitment")
+				if _, err := w.WriteTo(buf); err != nil {
+					return err
+				}
+				rng = backend.NewDeterministicRandomSource(opt.RandomKey, buf.Bytes())
+			}
+			if err := randomElement(rng, &values[len(inputs)]); err != nil {
+				return err
+			}
+			proof.opening = commitmentOpening{values: values[:len(inputs)], blinding: values[len(inputs)]}
+			config := ecc.MultiExpConfig{ScalarsMont: true}
+			if _, err := proof.Commitment.MultiExp(pk.CommitmentKey.Basis, values, config); err != nil {
+				return err
+			}
+			if _, err := proof.CommitmentPok.MultiExp(pk.CommitmentKey.BasisExpSigma, values, config); err != nil {
+				return err
+			}
+			c := commitmentChallenge(&proof.Commitment)
+			c.ToBigIntRegular(outputs[0])
+			return nil
+		}
+		opt.HintFunctions = hintFunctions
+	}
 
 	// solve the R1CS and compute the a, b, c vectors
-	a := make([]fr.Element, len(r1cs.Constraints), pk.Domain.Cardinality)
-	b := make([]fr.Element, len(r1cs.Constraints), pk.Domain.Cardinality)
-	c := make([]fr.Element, len(r1cs.Constraints), pk.Domain.Cardinality)
+	pool := opt.BufferPool
+	a := allocate(pool, "a", len(r1cs.Constraints), int(pk.Domain.Cardinality))
+	b := allocate(pool, "b", len(r1cs.Constraints), int(pk.Domain.Cardinality))
+	c := allocate(pool, "c", len(r1cs.Constraints), int(pk.Domain.Cardinality))
 	var wireValues []fr.Element
 	var err error
-	if wireValues, err = r1cs.Solve(witness, a, b, c, opt); err != nil {
-		if !opt.Force {
+	wireValues, err = solve(a, b, c, opt)
+	if err != nil && !opt.Force {
+		return nil, err
+	}
+
+	// the randomness of the proof, derived from the witness in deterministic mode
+	rng := opt.RandomSource
+	if opt.RandomKey != nil {
+		w := bw6_633witness.Witness(wireValues[1 : r1cs.NbPublicVariables+r1cs.NbSecretVariables])
+		var buf bytes.Buffer
+		if _, err := w.WriteTo(&buf); err != nil {
 			return nil, err
-		} else {
-			// we need to fill wireValues with random values else multi exps don't do much
-			var r fr.Element
-			_, _ = r.SetRandom()
-			for i := r1cs.NbPublicVariables + r1cs.NbSecretVariables; i < len(wireValues); i++ {
-				wireValues[i] = r
-				r.Double(&r)
-			}
+		}
+		rng = backend.NewDeterministicRandomSource(opt.RandomKey, buf.Bytes())
+	}
+
+	if err != nil {
+		// we need to fill wireValues with random values else multi exps don't do much
+		var r fr.Element
+		_ = randomElement(rng, &r)
+		for i := r1cs.NbPublicVariables + r1cs.NbSecretVariables; i < len(wireValues); i++ {
+			wireValues[i] = r
+			r.Double(&r)
 		}
 	}
 	start := time.Now()
@@ -91,13 +293,14 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bw6_633witness.Witness, opt ba
 	})
 
 	// H (witness reduction / FFT part)
+	if err := opt.Checkpoint(backend.PhaseFFT, 0, 1); err != nil {
+		return nil, err
+	}
 	var h []fr.Element
 	chHDone := make(chan struct{}, 1)
 	go func() {
-		h = computeH(a, b, c, &pk.Domain)
-		a = nil
-		b = nil
-		c = nil
+		// h reuses the memory of a
+		h = computeH(opt.Accelerator, a, b, c, pk)
 		chHDone <- struct{}{}
 	}()
 
@@ -107,7 +310,7 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bw6_633witness.Witness, opt ba
 	chWireValuesA, chWireValuesB := make(chan struct{}, 1), make(chan struct{}, 1)
 
 	go func() {
-		wireValuesA = make([]fr.Element, len(wireValues)-int(pk.NbInfinityA))
+		wireValuesA = allocate(pool, "wireValuesA", len(wireValues)-int(pk.NbInfinityA), 0)
 		for i, j := 0, 0; j < len(wireValuesA); i++ {
 			if pk.InfinityA[i] {
 				continue
@@ -118,7 +321,7 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bw6_633witness.Witness, opt ba
 		close(chWireValuesA)
 	}()
 	go func() {
-		wireValuesB = make([]fr.Element, len(wireValues)-int(pk.NbInfinityB))
+		wireValuesB = allocate(pool, "wireValuesB", len(wireValues)-int(pk.NbInfinityB), 0)
 		for i, j := 0, 0; j < len(wireValuesB); i++ {
 			if pk.InfinityB[i] {
 				continue
@@ -132,10 +335,10 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bw6_633witness.Witness, opt ba
 	// sample random r and s
 	var r, s big.Int
 	var _r, _s, _kr fr.Element
-	if _, err := _r.SetRandom(); err != nil {
+	if err := randomElement(rng, &_r); err != nil {
 		return nil, err
 	}
-	if _, err := _s.SetRandom(); err != nil {
+	if err := randomElement(rng, &_s); err != nil {
 		return nil, err
 	}
 	_kr.Mul(&_r, &_s).Neg(&_kr)
@@ -149,15 +352,82 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bw6_633witness.Witness, opt ba
 	// computes r[δ], s[δ], kr[δ]
 	deltas := curve.BatchScalarMultiplicationG1(&pk.G1.Delta, []fr.Element{_r, _s, _kr})
 
-	proof := &Proof{}
+	// wait for FFT to end, as it uses all our CPUs
+	<-chHDone
+	if err := opt.Checkpoint(backend.PhaseFFT, 1, 1); err != nil {
+		return nil, err
+	}
+	<-chWireValuesA
+	<-chWireValuesB
+
+	return &reduction{
+		proof:       proof,
+		wireValues:  wireValues,
+		wireValuesA: wireValuesA,
+		wireValuesB: wireValuesB,
+		h:           h,
+		b:           b,
+		c:           c,
+		r:           r,
+		s:           s,
+		deltas:      deltas,
+		start:       start,
+	}, nil
+}
+
+// prove computes the MSMs of the proof of red.
+func (red *reduction) prove(r1cs *cs.R1CS, pk *ProvingKey, opt backend.ProverConfig) (*Proof, error) {
+	log := logger.Logger().With().Str("curve", r1cs.CurveID().String()).Int("nbConstraints", len(r1cs.Constraints)).Str("backend", "groth16").Logger()
+
+	proof, deltas := red.proof, red.deltas
+	wireValues, wireValuesA, wireValuesB, h := red.wireValues, red.wireValuesA, red.wireValuesB, red.h
+	r, s := &red.r, &red.s
+	pool := opt.BufferPool
+
 	var bs1, ar curve.G1Jac
 
-	n := runtime.NumCPU()
+	msm := opt.MSMTasks()
+	acc := opt.Accelerator
+
+	// the MSMs report their progress as they are done
+	var msmLock sync.Mutex
+	nbMSMDone := 0
+	msmDone := func() error {
+		msmLock.Lock()
+		defer msmLock.Unlock()
+		nbMSMDone++
+		return opt.Checkpoint(backend.PhaseMSM, nbMSMDone, 5)
+	}
+
+	// the points of a key read by sections (see ProvingKey.ReadSectionsFrom) may not be loaded:
+	// the MSMs then run one at a time, each with all the CPUs, so that at most one section
+	// is read in memory at once
+	var sectionLock sync.Mutex
+	msmSectionG1 := func(res *curve.G1Jac, section Section, scalars []fr.Element, config ecc.MultiExpConfig) error {
+		if pk.sections != nil {
+			sectionLock.Lock()
+			defer sectionLock.Unlock()
+			config.NbTasks = msm.NbTasks
+		}
+		if acc == nil {
+			if fb := pk.fixedBase(section); fb != nil {
+				return fb.msm(res, scalars, config.NbTasks)
+			}
+		}
+		points, err := pk.pointsG1(section)
+		if err != nil {
+			return err
+		}
+		return msmG1(acc, res, points, scalars, config)
+	}
 
 	chBs1Done := make(chan error, 1)
 	computeBS1 := func() {
-		<-chWireValuesB
-		if _, err := bs1.MultiExp(pk.G1.B, wireValuesB, ecc.MultiExpConfig{NbTasks: n / 2}); err != nil {
+		err := msmSectionG1(&bs1, SectionB, wireValuesB, ecc.MultiExpConfig{NbTasks: msm.NbTasksG1})
+		if err == nil {
+			err = msmDone()
+		}
+		if err != nil {
 			chBs1Done <- err
 			close(chBs1Done)
 			return
@@ -169,8 +439,11 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bw6_633witness.Witness, opt ba
 
 	chArDone := make(chan error, 1)
 	computeAR1 := func() {
-		<-chWireValuesA
-		if _, err := ar.MultiExp(pk.G1.A, wireValuesA, ecc.MultiExpConfig{NbTasks: n / 2}); err != nil {
+		err := msmSectionG1(&ar, SectionA, wireValuesA, ecc.MultiExpConfig{NbTasks: msm.NbTasksG1})
+		if err == nil {
+			err = msmDone()
+		}
+		if err != nil {
 			chArDone <- err
 			close(chArDone)
 			return
@@ -189,14 +462,40 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bw6_633witness.Witness, opt ba
 		var krs, krs2, p1 curve.G1Jac
 		chKrs2Done := make(chan error, 1)
 		go func() {
-			_, err := krs2.MultiExp(pk.G1.Z, h, ecc.MultiExpConfig{NbTasks: n / 2})
+			err := msmSectionG1(&krs2, SectionZ, h, ecc.MultiExpConfig{NbTasks: msm.NbTasksG1})
+			if err == nil {
+				err = msmDone()
+			}
 			chKrs2Done <- err
 		}()
-		if _, err := krs.MultiExp(pk.G1.K, wireValues[r1cs.NbPublicVariables:], ecc.MultiExpConfig{NbTasks: n / 2}); err != nil {
+		// the committed wires and the commitment wire are not in pk.G1.K
+		privateWireValues := wireValues[r1cs.NbPublicVariables:]
+		if r1cs.Commitment.Is() {
+			privateWireValues = make([]fr.Element, 0, pk.nbPoints(SectionK))
+			for i := r1cs.NbPublicVariables; i < len(wireValues); i++ {
+				if !r1cs.Commitment.IsCommitted(i) {
+					privateWireValues = append(privateWireValues, wireValues[i])
+				}
+			}
+		}
+		err := msmSectionG1(&krs, SectionK, privateWireValues, ecc.MultiExpConfig{NbTasks: msm.NbTasksG1})
+		if err == nil {
+			err = msmDone()
+		}
+		if err != nil {
 			chKrsDone <- err
 			return
 		}
 		krs.AddMixed(&deltas[2])
+		if r1cs.Commitment.Is() {
+			// -v[η/δ] compensates the blinding v[η/γ] of the commitment
+			var v big.Int
+			var blinding curve.G1Affine
+			var blindingNeg fr.Element
+			blindingNeg.Neg(&proof.opening.blinding).ToBigIntRegular(&v)
+			blinding.ScalarMultiplication(&pk.CommitmentKey.BlindingDelta, &v)
+			krs.AddMixed(&blinding)
+		}
 		n := 3
 		for n != 0 {
 			select {
@@ -211,14 +510,14 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bw6_633witness.Witness, opt ba
 					chKrsDone <- err
 					return
 				}
-				p1.ScalarMultiplication(&ar, &s)
+				p1.ScalarMultiplication(&ar, s)
 				krs.AddAssign(&p1)
 			case err := <-chBs1Done:
 				if err != nil {
 					chKrsDone <- err
 					return
 				}
-				p1.ScalarMultiplication(&bs1, &r)
+				p1.ScalarMultiplication(&bs1, r)
 				krs.AddAssign(&p1)
 			}
 			n--
@@ -232,18 +531,23 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bw6_633witness.Witness, opt ba
 		// Bs2 (1 multi exp G2 - size = len(wires))
 		var Bs, deltaS curve.G2Jac
 
-		nbTasks := n
-		if nbTasks <= 16 {
-			// if we don't have a lot of CPUs, this may artificially split the MSM
-			nbTasks *= 2
+		if pk.sections != nil {
+			sectionLock.Lock()
+			defer sectionLock.Unlock()
+		}
+		points, err := pk.pointsG2B()
+		if err != nil {
+			return err
+		}
+		if err := msmG2(acc, &Bs, points, wireValuesB, ecc.MultiExpConfig{NbTasks: msm.NbTasksG2}); err != nil {
+			return err
 		}
-		<-chWireValuesB
-		if _, err := Bs.MultiExp(pk.G2.B, wireValuesB, ecc.MultiExpConfig{NbTasks: nbTasks}); err != nil {
+		if err := msmDone(); err != nil {
 			return err
 		}
 
 		deltaS.FromAffine(&pk.G2.Delta)
-		deltaS.ScalarMultiplication(&deltaS, &s)
+		deltaS.ScalarMultiplication(&deltaS, s)
 		Bs.AddAssign(&deltaS)
 		Bs.AddMixed(&pk.G2.Beta)
 
@@ -251,8 +555,9 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bw6_633witness.Witness, opt ba
 		return nil
 	}
 
-	// wait for FFT to end, as it uses all our CPUs
-	<-chHDone
+	if err := opt.Checkpoint(backend.PhaseMSM, 0, 5); err != nil {
+		return nil, err
+	}
 
 	// schedule our proof part computations
 	go computeKRS()
@@ -267,58 +572,216 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bw6_633witness.Witness, opt ba
 		return nil, err
 	}
 
-	log.Debug().Dur("took", time.Since(start)).Msg("prover done")
+	// the MSMs are done, the buffers can be reused by the next proof
+	release(pool, "a", h)
+	release(pool, "b", red.b)
+	release(pool, "c", red.c)
+	release(pool, "wireValuesA", wireValuesA)
+	release(pool, "wireValuesB", wireValuesB)
+
+	log.Debug().Dur("took", time.Since(red.start)).Msg("prover done")
 
 	return proof, nil
 }
 
-func computeH(a, b, c []fr.Element, domain *fft.Domain) []fr.Element {
+func computeH(acc accelerator.Accelerator, a, b, c []fr.Element, pk *ProvingKey) []fr.Element {
 	// H part of Krs
 	// Compute H (hz=ab-c, where z=-2 on ker X^n+1 (z(x)=x^n-1))
 	// 	1 - _a = ifft(a), _b = ifft(b), _c = ifft(c)
 	// 	2 - ca = fft_coset(_a), ba = fft_coset(_b), cc = fft_coset(_c)
 	// 	3 - h = ifft_coset(ca o cb - cc)
 
-	n := len(a)
+	domain := &pk.Domain
+	n := int(domain.Cardinality)
 
-	// add padding to ensure input length is domain cardinality
-	padding := make([]fr.Element, int(domain.Cardinality)-n)
-	a = append(a, padding...)
-	b = append(b, padding...)
-	c = append(c, padding...)
-	n = len(a)
+	// pad the inputs with zeroes to the domain cardinality, in the capacity
+	// reserved by allocate
+	a, b, c = extend(a, n), extend(b, n), extend(c, n)
 
-	domain.FFTInverse(a, fft.DIF)
-	domain.FFTInverse(b, fft.DIF)
-	domain.FFTInverse(c, fft.DIF)
+	if acc != nil {
+		for _, v := range [][]fr.Element{a, b, c} {
+			fftInverse(acc, domain, v, fft.DIF, false)
+			fftDomain(acc, domain, v, fft.DIT, true)
+		}
 
-	domain.FFT(a, fft.DIT, true)
-	domain.FFT(b, fft.DIT, true)
-	domain.FFT(c, fft.DIT, true)
+		var den, one fr.Element
+		one.SetOne()
+		den.Exp(domain.FrMultiplicativeGen, big.NewInt(int64(domain.Cardinality)))
+		den.Sub(&den, &one).Inverse(&den)
+
+		// h = ifft_coset(ca o cb - cc)
+		// reusing a to avoid unecessary memalloc
+		utils.Parallelize(n, func(start, end int) {
+			for i := start; i < end; i++ {
+				a[i].Mul(&a[i], &b[i]).
+					Sub(&a[i], &c[i]).
+					Mul(&a[i], &den)
+			}
+		})
 
-	var den, one fr.Element
-	one.SetOne()
-	den.Exp(domain.FrMultiplicativeGen, big.NewInt(int64(domain.Cardinality)))
-	den.Sub(&den, &one).Inverse(&den)
+		// ifft_coset
+		fftInverse(acc, domain, a, fft.DIF, true)
+
+		utils.Parallelize(n, func(start, end int) {
+			for i := start; i < end; i++ {
+				a[i].FromMont()
+			}
+		})
+		return a
+	}
+
+	// the inverse FFTs are computed without their scaling by 1/n, which is
+	// folded with the coset shifts and the division by z in the tables of pk,
+	// so that each of the passes over the vectors does a single multiplication
+	tables := pk.quotientTables()
+	inverse := *domain
+	inverse.Twiddles = domain.TwiddlesInv
+
+	for _, v := range [][]fr.Element{a, b, c} {
+		v := v
+		inverse.FFT(v, fft.DIF)
+		utils.Parallelize(n, func(start, end int) {
+			for i := start; i < end; i++ {
+				v[i].Mul(&v[i], &tables.coset[i])
+			}
+		})
+		domain.FFT(v, fft.DIT)
+	}
 
 	// h = ifft_coset(ca o cb - cc)
 	// reusing a to avoid unecessary memalloc
 	utils.Parallelize(n, func(start, end int) {
 		for i := start; i < end; i++ {
-			a[i].Mul(&a[i], &b[i]).
-				Sub(&a[i], &c[i]).
-				Mul(&a[i], &den)
+			a[i].Mul(&a[i], &b[i]).Sub(&a[i], &c[i])
 		}
 	})
 
 	// ifft_coset
-	domain.FFTInverse(a, fft.DIF, true)
-
-	utils.Parallelize(len(a), func(start, end int) {
+	inverse.FFT(a, fft.DIF)
+	utils.Parallelize(n, func(start, end int) {
 		for i := start; i < end; i++ {
-			a[i].FromMont()
+			a[i].Mul(&a[i], &tables.cosetInv[i]).FromMont()
 		}
 	})
 
 	return a
 }
+
+// quotientTables holds the coset tables of the FFTs of computeH, scaled by the
+// constants of its other steps
+type quotientTables struct {
+	// coset[i] = CosetTableReversed[i] / n, for the coset FFTs of a, b and c
+	coset []fr.Element
+
+	// cosetInv[i] = CosetTableInvReversed[i] / (n⋅(gⁿ-1)), g being the shift of
+	// the coset, for the inverse coset FFT of h
+	cosetInv []fr.Element
+}
+
+// quotientTablesLock guards the computation of the tables of the proving keys
+var quotientTablesLock sync.Mutex
+
+// quotientTables returns the tables of computeH for pk.Domain. They are computed
+// on the first call, and reused by the next proofs with pk.
+func (pk *ProvingKey) quotientTables() *quotientTables {
+	quotientTablesLock.Lock()
+	defer quotientTablesLock.Unlock()
+	domain := &pk.Domain
+	if pk.quotient != nil && len(pk.quotient.coset) == int(domain.Cardinality) {
+		return pk.quotient
+	}
+
+	var den, one fr.Element
+	one.SetOne()
+	den.Exp(domain.FrMultiplicativeGen, big.NewInt(int64(domain.Cardinality)))
+	den.Sub(&den, &one).Inverse(&den).Mul(&den, &domain.CardinalityInv)
+
+	tables := &quotientTables{
+		coset:    make([]fr.Element, domain.Cardinality),
+		cosetInv: make([]fr.Element, domain.Cardinality),
+	}
+	utils.Parallelize(len(tables.coset), func(start, end int) {
+		for i := start; i < end; i++ {
+			tables.coset[i].Mul(&domain.CosetTableReversed[i], &domain.CardinalityInv)
+			tables.cosetInv[i].Mul(&domain.CosetTableInvReversed[i], &den)
+		}
+	})
+	pk.quotient = tables
+	return tables
+}
+
+// extend returns v padded with zeroes to length n, in place if its capacity allows it
+func extend(v []fr.Element, n int) []fr.Element {
+	if cap(v) < n {
+		res := make([]fr.Element, n)
+		copy(res, v)
+		return res
+	}
+	l := len(v)
+	v = v[:n]
+	for i := l; i < n; i++ {
+		v[i] = fr.Element{}
+	}
+	return v
+}
+
+// allocate returns a zeroed slice of length n and capacity at least c, taken
+// from pool if it holds a large enough one
+func allocate(pool *backend.BufferPool, name string, n, c int) []fr.Element {
+	if c < n {
+		c = n
+	}
+	if pool != nil {
+		if buf, ok := pool.Get(name).([]fr.Element); ok && cap(buf) >= c {
+			buf = buf[:n]
+			for i := range buf {
+				buf[i] = fr.Element{}
+			}
+			return buf
+		}
+	}
+	return make([]fr.Element, n, c)
+}
+
+// release gives buf back to pool, if it is set
+func release(pool *backend.BufferPool, name string, buf []fr.Element) {
+	if pool != nil {
+		pool.Put(name, buf, cap(buf)*fr.Bytes)
+	}
+}
+
+// msmG1 sets res to the multi exponentiation of points and scalars, with acc if it is set
+func msmG1(acc accelerator.Accelerator, res *curve.G1Jac, points []curve.G1Affine, scalars []fr.Element, config ecc.MultiExpConfig) error {
+	if acc != nil {
+		return acc.MultiExpG1(res, points, scalars, config)
+	}
+	_, err := res.MultiExp(points, scalars, config)
+	return err
+}
+
+// msmG2 sets res to the multi exponentiation of points and scalars, with acc if it is set
+func msmG2(acc accelerator.Accelerator, res *curve.G2Jac, points []curve.G2Affine, scalars []fr.Element, config ecc.MultiExpConfig) error {
+	if acc != nil {
+		return acc.MultiExpG2(res, points, scalars, config)
+	}
+	_, err := res.MultiExp(points, scalars, config)
+	return err
+}
+
+// fftDomain evaluates a on the domain (or its coset), with acc if it is set
+func fftDomain(acc accelerator.Accelerator, domain *fft.Domain, a []fr.Element, decimation fft.Decimation, coset bool) {
+	if acc != nil {
+		acc.FFT(domain, a, accelerator.Decimation(decimation), coset)
+		return
+	}
+	domain.FFT(a, decimation, coset)
+}
+
+// fftInverse interpolates a on the domain (or its coset), with acc if it is set
+func fftInverse(acc accelerator.Accelerator, domain *fft.Domain, a []fr.Element, decimation fft.Decimation, coset bool) {
+	if acc != nil {
+		acc.FFTInverse(domain, a, accelerator.Decimation(decimation), coset)
+		return
+	}
+	domain.FFTInverse(a, decimation, coset)
+}
@@ -1,3 +1,6 @@
+//go:build !verifieronly
+// +build !verifieronly
+
 // Copyright 2020 ConsenSys Software Inc.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
@@ -17,10 +20,11 @@
 package plonk
 
 import (
-	"crypto/sha256"
+	"bytes"
+	"crypto/rand"
+	"io"
 	"math/big"
 	"math/bits"
-	"runtime"
 	"sync"
 	"time"
 
@@ -36,77 +40,152 @@ import (
 
 	"github.com/consensys/gnark/internal/backend/bw6-633/cs"
 
+	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/fiat-shamir"
 	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/accelerator"
+	"github.com/consensys/gnark/backend/hint"
 	"github.com/consensys/gnark/internal/utils"
 	"github.com/consensys/gnark/logger"
+	"github.com/consensys/gnark/metrics"
 )
 
-type Proof struct {
-
-	// Commitments to the solution vectors
-	LRO [3]kzg.Digest
-
-	// Commitment to Z, the permutation polynomial
-	Z kzg.Digest
-
-	// Commitments to h1, h2, h3 such that h = h1 + Xh2 + X**2h3 is the quotient polynomial
-	H [3]kzg.Digest
-
-	// Batch opening proof of h1 + zeta*h2 + zeta**2h3, linearizedPolynomial, l, r, o, s1, s2
-	BatchedProof kzg.BatchOpeningProof
-
-	// Opening proof of Z at zeta*mu
-	ZShiftedOpening kzg.OpeningProof
-}
-
 // Prove from the public data
-func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bw6_633witness.Witness, opt backend.ProverConfig) (*Proof, error) {
+func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bw6_633witness.Witness, opt backend.ProverConfig) (_ *Proof, err error) {
 
 	log := logger.Logger().With().Str("curve", spr.CurveID().String()).Int("nbConstraints", len(spr.Constraints)).Str("backend", "plonk").Logger()
 	start := time.Now()
+	m := metrics.Start(metrics.PhaseProve, spr.CurveID(), backend.PLONK)
+	defer func() {
+		m.Stop(metrics.Event{
+			Err:           err,
+			NbConstraints: len(spr.Constraints),
+			NbWires:       spr.NbInternalVariables + spr.NbPublicVariables + spr.NbSecretVariables,
+			MSMSize:       int(pk.Domain[0].Cardinality),
+		})
+	}()
 	// pick a hash function that will be used to derive the challenges
-	hFunc := sha256.New()
+	hFunc, err := newTranscriptHash(pk.Vk.TranscriptHash)
+	if err != nil {
+		return nil, err
+	}
 
 	// create a transcript manager to apply Fiat Shamir
-	fs := fiatshamir.NewTranscript(hFunc, "gamma", "beta", "alpha", "zeta")
+	fs := fiatshamir.NewTranscript(hFunc, challengeIDs(pk.Vk)...)
 
 	// result
 	proof := &Proof{}
 
+	// MSMs and FFTs are delegated to acc if it is set
+	acc := opt.Accelerator
+	msm := opt.MSMTasks()
+
+	// the randomness of the proof, derived from the witness in deterministic mode
+	rng := opt.RandomSource
+	if opt.RandomKey != nil {
+		var buf bytes.Buffer
+		if _, err := fullWitness.WriteTo(&buf); err != nil {
+			return nil, err
+		}
+		rng = backend.NewDeterministicRandomSource(opt.RandomKey, buf.Bytes())
+	}
+
+	// the commitment wire is computed from the commitment to π₂, the polynomial equal to the
+	// committed wires on their constraints, which the solver gives to its hint
+	var pi2Canonical []fr.Element
+	if spr.Commitment.Is() {
+		hintFunctions := make(map[hint.ID]hint.Function, len(opt.HintFunctions)+1)
+		for id, f := range opt.HintFunctions {
+			hintFunctions[id] = f
+		}
+		hintFunctions[spr.Commitment.HintID] = func(_ ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+			pi2Canonical = make([]fr.Element, pk.Domain[0].Cardinality, pk.Domain[0].Cardinality+2)
+			for i := 0; i < len(inputs); i++ {
+				pi2Canonical[spr.NbPublicVariables+spr.Commitment.CommittedConstraints[i]].SetBigInt(inputs[i])
+			}
+			fftInverse(acc, &pk.Domain[0], pi2Canonical, fft.DIF, false)
+			fft.BitReverse(pi2Canonical)
+			var err error
+			if pi2Canonical, err = blindPoly(pi2Canonical, pk.Domain[0].Cardinality, 1, rng); err != nil {
+				return err
+			}
+			if proof.PI2, err = commit(acc, pi2Canonical, pk.Vk.KZGSRS); err != nil {
+				return err
+			}
+			c := commitmentChallenge(&proof.PI2)
+			c.ToBigIntRegular(outputs[0])
+			return nil
+		}
+		opt.HintFunctions = hintFunctions
+	}
+
 	// compute the constraint system solution
 	var solution []fr.Element
-	var err error
 	if solution, err = spr.Solve(fullWitness, opt); err != nil {
 		if !opt.Force {
 			return nil, err
 		} else {
 			// we need to fill solution with random values
 			var r fr.Element
-			_, _ = r.SetRandom()
+			_ = randomElement(rng, &r)
 			for i := spr.NbPublicVariables + spr.NbSecretVariables; i < len(solution); i++ {
 				solution[i] = r
 				r.Double(&r)
 			}
+			if spr.Commitment.Is() && pi2Canonical == nil {
+				pi2Canonical = make([]fr.Element, pk.Domain[0].Cardinality)
+			}
 		}
 	}
 
 	// query l, r, o in Lagrange basis, not blinded
 	evaluationLDomainSmall, evaluationRDomainSmall, evaluationODomainSmall := evaluateLROSmallDomain(spr, pk, solution)
 
+	// the blinding polynomials are read from rng in a fixed order, so that
+	// a deterministic rng gives a deterministic proof
+	var blindings [3][]fr.Element
+	for i := range blindings {
+		if blindings[i], err = randomPoly(rng, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	// with a Lagrange SRS, l, r, o are committed from their evaluations,
+	// concurrently with their iFFTs
+	chCommitLRO := make(chan error, 1)
+	if pk.LagrangeSRS != nil {
+		go func() {
+			lro := [3][]fr.Element{evaluationLDomainSmall, evaluationRDomainSmall, evaluationODomainSmall}
+			chCommitLRO <- commitToLROLagrange(acc, lro, blindings, proof, pk, msm.NbTasksG1)
+		}()
+	}
+
 	// save ll, lr, lo, and make a copy of them in canonical basis.
 	// note that we allocate more capacity to reuse for blinded polynomials
-	blindedLCanonical, blindedRCanonical, blindedOCanonical, err := computeBlindedLROCanonical(
+	blindedLCanonical, blindedRCanonical, blindedOCanonical := computeBlindedLROCanonical(
+		acc,
 		evaluationLDomainSmall,
 		evaluationRDomainSmall,
 		evaluationODomainSmall,
+		blindings,
 		&pk.Domain[0])
-	if err != nil {
+	if err := opt.Checkpoint(backend.PhaseFFT, 1, 3); err != nil {
+		if pk.LagrangeSRS != nil {
+			<-chCommitLRO
+		}
 		return nil, err
 	}
 
 	// compute kzg commitments of bcl, bcr and bco
-	if err := commitToLRO(blindedLCanonical, blindedRCanonical, blindedOCanonical, proof, pk.Vk.KZGSRS); err != nil {
+	if pk.LagrangeSRS != nil {
+		err = <-chCommitLRO
+	} else {
+		err = commitToLRO(acc, blindedLCanonical, blindedRCanonical, blindedOCanonical, proof, pk.Vk.KZGSRS, msm.NbTasksG1)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := opt.Checkpoint(backend.PhaseMSM, 1, 5); err != nil {
 		return nil, err
 	}
 
@@ -116,6 +195,9 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bw6_633witness.Witnes
 	if err := bindPublicData(&fs, "gamma", *pk.Vk, fullWitness[:spr.NbPublicVariables]); err != nil {
 		return nil, err
 	}
+	if err := bindCommitment(&fs, "gamma", *pk.Vk, proof); err != nil {
+		return nil, err
+	}
 	bgamma, err := fs.ComputeChallenge("gamma")
 	if err != nil {
 		return nil, err
@@ -129,6 +211,16 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bw6_633witness.Witnes
 		return nil, err
 	}
 
+	// lookup argument: f, h1, h2 and the accumulator polynomial Zl
+	var lk *lookupProver
+	alphaPoints := []*curve.G1Affine{&proof.Z}
+	if len(pk.Vk.T) != 0 {
+		if lk, err = computeLookup(acc, &fs, spr, pk, proof, evaluationLDomainSmall, evaluationRDomainSmall, evaluationODomainSmall, rng); err != nil {
+			return nil, err
+		}
+		alphaPoints = append(alphaPoints, &proof.Zl)
+	}
+
 	// compute Z, the permutation accumulator polynomial, in canonical basis
 	// ll, lr, lo are NOT blinded
 	var blindedZCanonical []fr.Element
@@ -137,10 +229,11 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bw6_633witness.Witnes
 	go func() {
 		var err error
 		blindedZCanonical, err = computeBlindedZCanonical(
+			acc,
 			evaluationLDomainSmall,
 			evaluationRDomainSmall,
 			evaluationODomainSmall,
-			pk, beta, gamma)
+			pk, beta, gamma, rng)
 		if err != nil {
 			chZ <- err
 			close(chZ)
@@ -148,18 +241,18 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bw6_633witness.Witnes
 		}
 
 		// commit to the blinded version of z
-		// note that we explicitly double the number of tasks for the multi exp in kzg.Commit
+		// note that we explicitly double the number of tasks for the multi exp in commit
 		// this may add additional arithmetic operations, but with smaller tasks
 		// we ensure that this commitment is well parallelized, without having a "unbalanced task" making
 		// the rest of the code wait too long.
-		if proof.Z, err = kzg.Commit(blindedZCanonical, pk.Vk.KZGSRS, runtime.NumCPU()*2); err != nil {
+		if proof.Z, err = commit(acc, blindedZCanonical, pk.Vk.KZGSRS, msm.NbTasks*2); err != nil {
 			chZ <- err
 			close(chZ)
 			return
 		}
 
-		// derive alpha from the Comm(l), Comm(r), Comm(o), Com(Z)
-		alpha, err = deriveRandomness(&fs, "alpha", &proof.Z)
+		// derive alpha from the Comm(l), Comm(r), Comm(o), Com(Z) (and Com(Zl))
+		alpha, err = deriveRandomness(&fs, "alpha", alphaPoints...)
 		chZ <- err
 		close(chZ)
 	}()
@@ -176,15 +269,15 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bw6_633witness.Witnes
 	chEvalBR := make(chan struct{}, 1)
 	chEvalBO := make(chan struct{}, 1)
 	go func() {
-		evaluationBlindedLDomainBigBitReversed = evaluateDomainBigBitReversed(blindedLCanonical, &pk.Domain[1])
+		evaluationBlindedLDomainBigBitReversed = evaluateDomainBigBitReversed(acc, blindedLCanonical, &pk.Domain[1])
 		close(chEvalBL)
 	}()
 	go func() {
-		evaluationBlindedRDomainBigBitReversed = evaluateDomainBigBitReversed(blindedRCanonical, &pk.Domain[1])
+		evaluationBlindedRDomainBigBitReversed = evaluateDomainBigBitReversed(acc, blindedRCanonical, &pk.Domain[1])
 		close(chEvalBR)
 	}()
 	go func() {
-		evaluationBlindedODomainBigBitReversed = evaluateDomainBigBitReversed(blindedOCanonical, &pk.Domain[1])
+		evaluationBlindedODomainBigBitReversed = evaluateDomainBigBitReversed(acc, blindedOCanonical, &pk.Domain[1])
 		close(chEvalBO)
 	}()
 
@@ -195,7 +288,10 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bw6_633witness.Witnes
 		qkCompletedCanonical := make([]fr.Element, pk.Domain[0].Cardinality)
 		copy(qkCompletedCanonical, fullWitness[:spr.NbPublicVariables])
 		copy(qkCompletedCanonical[spr.NbPublicVariables:], pk.LQk[spr.NbPublicVariables:])
-		pk.Domain[0].FFTInverse(qkCompletedCanonical, fft.DIF)
+		for _, i := range pk.Vk.CommitmentConstraintIndexes {
+			qkCompletedCanonical[i] = solution[spr.Commitment.Wire]
+		}
+		fftInverse(acc, &pk.Domain[0], qkCompletedCanonical, fft.DIF, false)
 		fft.BitReverse(qkCompletedCanonical)
 
 		// compute the evaluation of qlL+qrR+qmL.R+qoO+k on the coset of the big domain
@@ -204,11 +300,13 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bw6_633witness.Witnes
 		<-chEvalBR
 		<-chEvalBO
 		constraintsInd = evaluateConstraintsDomainBigBitReversed(
+			acc,
 			pk,
 			evaluationBlindedLDomainBigBitReversed,
 			evaluationBlindedRDomainBigBitReversed,
 			evaluationBlindedODomainBigBitReversed,
-			qkCompletedCanonical)
+			qkCompletedCanonical,
+			pi2Canonical)
 		close(chConstraintInd)
 	}()
 
@@ -219,7 +317,7 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bw6_633witness.Witnes
 			return
 		}
 
-		evaluationBlindedZDomainBigBitReversed = evaluateDomainBigBitReversed(blindedZCanonical, &pk.Domain[1])
+		evaluationBlindedZDomainBigBitReversed = evaluateDomainBigBitReversed(acc, blindedZCanonical, &pk.Domain[1])
 		// compute zu*g1*g2*g3-z*f1*f2*f3 on the coset of the big domain
 		// evalL, evalO, evalR are the evaluations of the blinded versions of l, r, o.
 		<-chEvalBL
@@ -242,12 +340,40 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bw6_633witness.Witnes
 	}
 
 	<-chConstraintInd
+	if err := opt.Checkpoint(backend.PhaseFFT, 2, 3); err != nil {
+		return nil, err
+	}
+	if err := opt.Checkpoint(backend.PhaseMSM, 2, 5); err != nil {
+		return nil, err
+	}
+
+	// L₁ on the coset of the big domain
+	evaluationL1DomainBigBitReversed := evaluateL1DomainBigBitReversed(acc, pk)
+
+	// compute the lookup constraints on the coset of the big domain
+	var constraintsLookup []fr.Element
+	if lk != nil {
+		constraintsLookup = lk.evaluateDomainBigBitReversed(
+			acc,
+			pk,
+			evaluationBlindedLDomainBigBitReversed,
+			evaluationBlindedRDomainBigBitReversed,
+			evaluationBlindedODomainBigBitReversed,
+			evaluationL1DomainBigBitReversed,
+			alpha)
+	}
 
 	// compute h in canonical form
-	h1, h2, h3 := computeQuotientCanonical(pk, constraintsInd, constraintsOrdering, evaluationBlindedZDomainBigBitReversed, alpha)
+	h1, h2, h3 := computeQuotientCanonical(acc, pk, constraintsInd, constraintsOrdering, constraintsLookup, evaluationL1DomainBigBitReversed, evaluationBlindedZDomainBigBitReversed, alpha)
+	if err := opt.Checkpoint(backend.PhaseFFT, 3, 3); err != nil {
+		return nil, err
+	}
 
 	// compute kzg commitments of h1, h2 and h3
-	if err := commitToQuotient(h1, h2, h3, proof, pk.Vk.KZGSRS); err != nil {
+	if err := commitToQuotient(acc, h1, h2, h3, proof, pk.Vk.KZGSRS, msm.NbTasksG1); err != nil {
+		return nil, err
+	}
+	if err := opt.Checkpoint(backend.PhaseMSM, 3, 5); err != nil {
 		return nil, err
 	}
 
@@ -285,10 +411,39 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bw6_633witness.Witnes
 	if err != nil {
 		return nil, err
 	}
+	if err := opt.Checkpoint(backend.PhaseMSM, 4, 5); err != nil {
+		return nil, err
+	}
 
 	// blinded z evaluated at u*zeta
 	bzuzeta := proof.ZShiftedOpening.ClaimedValue
 
+	// lookup argument: open Zl, h1, t at u*zeta, and evaluate f, t at zeta
+	var lkZeta [3]fr.Element // f(ζ), t(ζ), t(μζ)
+	var tDigest kzg.Digest
+	if lk != nil {
+		tDigest = lk.compressDigests(pk.Vk.T)
+		proof.LookupShiftedBatchedProof, err = kzg.BatchOpenSinglePoint(
+			[][]fr.Element{lk.z, lk.h1, lk.t},
+			[]kzg.Digest{proof.Zl, proof.H1, tDigest},
+			zetaShifted,
+			hFunc,
+			pk.Vk.KZGSRS,
+		)
+		if err != nil {
+			return nil, err
+		}
+		lkZeta[0] = eval(lk.f, zeta)
+		lkZeta[1] = eval(lk.t, zeta)
+		lkZeta[2] = proof.LookupShiftedBatchedProof.ClaimedValues[2]
+	}
+
+	// commitment: evaluate π₂ at zeta
+	var pi2Zeta fr.Element
+	if pi2Canonical != nil {
+		pi2Zeta = eval(pi2Canonical, zeta)
+	}
+
 	var (
 		linearizedPolynomialCanonical []fr.Element
 		linearizedPolynomialDigest    curve.G1Affine
@@ -310,11 +465,14 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bw6_633witness.Witnes
 			bzuzeta,
 			blindedZCanonical,
 			pk,
+			lk,
+			lkZeta,
+			pi2Zeta,
 		)
 
 		// TODO this commitment is only necessary to derive the challenge, we should
 		// be able to avoid doing it and get the challenge in another way
-		linearizedPolynomialDigest, errLPoly = kzg.Commit(linearizedPolynomialCanonical, pk.Vk.KZGSRS)
+		linearizedPolynomialDigest, errLPoly = commit(acc, linearizedPolynomialCanonical, pk.Vk.KZGSRS)
 		close(chLpoly)
 	}()
 
@@ -347,35 +505,48 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bw6_633witness.Witnes
 	}
 
 	// Batch open the first list of polynomials
+	polynomials := [][]fr.Element{
+		foldedH,
+		linearizedPolynomialCanonical,
+		blindedLCanonical,
+		blindedRCanonical,
+		blindedOCanonical,
+		pk.S1Canonical,
+		pk.S2Canonical,
+	}
+	digests := []kzg.Digest{
+		foldedHDigest,
+		linearizedPolynomialDigest,
+		proof.LRO[0],
+		proof.LRO[1],
+		proof.LRO[2],
+		pk.Vk.S[0],
+		pk.Vk.S[1],
+	}
+	if lk != nil {
+		polynomials = append(polynomials, lk.f, lk.t, lk.h1, lk.h2)
+		digests = append(digests, proof.F, tDigest, proof.H1, proof.H2)
+	}
+	if pi2Canonical != nil {
+		polynomials = append(polynomials, pi2Canonical)
+		digests = append(digests, proof.PI2)
+	}
 	proof.BatchedProof, err = kzg.BatchOpenSinglePoint(
-		[][]fr.Element{
-			foldedH,
-			linearizedPolynomialCanonical,
-			blindedLCanonical,
-			blindedRCanonical,
-			blindedOCanonical,
-			pk.S1Canonical,
-			pk.S2Canonical,
-		},
-		[]kzg.Digest{
-			foldedHDigest,
-			linearizedPolynomialDigest,
-			proof.LRO[0],
-			proof.LRO[1],
-			proof.LRO[2],
-			pk.Vk.S[0],
-			pk.Vk.S[1],
-		},
+		polynomials,
+		digests,
 		zeta,
 		hFunc,
 		pk.Vk.KZGSRS,
 	)
 
-	log.Debug().Dur("took", time.Since(start)).Msg("prover done")
-
 	if err != nil {
 		return nil, err
 	}
+	if err := opt.Checkpoint(backend.PhaseMSM, 5, 5); err != nil {
+		return nil, err
+	}
+
+	log.Debug().Dur("took", time.Since(start)).Msg("prover done")
 
 	return proof, nil
 
@@ -390,21 +561,20 @@ func eval(c []fr.Element, p fr.Element) fr.Element {
 	return r
 }
 
-// fills proof.LRO with kzg commits of bcl, bcr and bco
-func commitToLRO(bcl, bcr, bco []fr.Element, proof *Proof, srs *kzg.SRS) error {
-	n := runtime.NumCPU() / 2
+// fills proof.LRO with kzg commits of bcl, bcr and bco, each computed with n tasks
+func commitToLRO(acc accelerator.Accelerator, bcl, bcr, bco []fr.Element, proof *Proof, srs *kzg.SRS, n int) error {
 	var err0, err1, err2 error
 	chCommit0 := make(chan struct{}, 1)
 	chCommit1 := make(chan struct{}, 1)
 	go func() {
-		proof.LRO[0], err0 = kzg.Commit(bcl, srs, n)
+		proof.LRO[0], err0 = commit(acc, bcl, srs, n)
 		close(chCommit0)
 	}()
 	go func() {
-		proof.LRO[1], err1 = kzg.Commit(bcr, srs, n)
+		proof.LRO[1], err1 = commit(acc, bcr, srs, n)
 		close(chCommit1)
 	}()
-	if proof.LRO[2], err2 = kzg.Commit(bco, srs, n); err2 != nil {
+	if proof.LRO[2], err2 = commit(acc, bco, srs, n); err2 != nil {
 		return err2
 	}
 	<-chCommit0
@@ -417,20 +587,19 @@ func commitToLRO(bcl, bcr, bco []fr.Element, proof *Proof, srs *kzg.SRS) error {
 	return err1
 }
 
-func commitToQuotient(h1, h2, h3 []fr.Element, proof *Proof, srs *kzg.SRS) error {
-	n := runtime.NumCPU() / 2
+func commitToQuotient(acc accelerator.Accelerator, h1, h2, h3 []fr.Element, proof *Proof, srs *kzg.SRS, n int) error {
 	var err0, err1, err2 error
 	chCommit0 := make(chan struct{}, 1)
 	chCommit1 := make(chan struct{}, 1)
 	go func() {
-		proof.H[0], err0 = kzg.Commit(h1, srs, n)
+		proof.H[0], err0 = commit(acc, h1, srs, n)
 		close(chCommit0)
 	}()
 	go func() {
-		proof.H[1], err1 = kzg.Commit(h2, srs, n)
+		proof.H[1], err1 = commit(acc, h2, srs, n)
 		close(chCommit1)
 	}()
-	if proof.H[2], err2 = kzg.Commit(h3, srs, n); err2 != nil {
+	if proof.H[2], err2 = commit(acc, h3, srs, n); err2 != nil {
 		return err2
 	}
 	<-chCommit0
@@ -444,42 +613,36 @@ func commitToQuotient(h1, h2, h3 []fr.Element, proof *Proof, srs *kzg.SRS) error
 }
 
 // computeBlindedLROCanonical l, r, o in canonical basis with blinding
-func computeBlindedLROCanonical(ll, lr, lo []fr.Element, domain *fft.Domain) (bcl, bcr, bco []fr.Element, err error) {
+func computeBlindedLROCanonical(acc accelerator.Accelerator, ll, lr, lo []fr.Element, blindings [3][]fr.Element, domain *fft.Domain) (bcl, bcr, bco []fr.Element) {
 
 	// note that bcl, bcr and bco reuses cl, cr and co memory
 	cl := make([]fr.Element, domain.Cardinality, domain.Cardinality+2)
 	cr := make([]fr.Element, domain.Cardinality, domain.Cardinality+2)
 	co := make([]fr.Element, domain.Cardinality, domain.Cardinality+2)
 
-	chDone := make(chan error, 2)
+	chDone := make(chan struct{}, 2)
 
 	go func() {
-		var err error
 		copy(cl, ll)
-		domain.FFTInverse(cl, fft.DIF)
+		fftInverse(acc, domain, cl, fft.DIF, false)
 		fft.BitReverse(cl)
-		bcl, err = blindPoly(cl, domain.Cardinality, 1)
-		chDone <- err
+		bcl = blind(cl, domain.Cardinality, blindings[0])
+		chDone <- struct{}{}
 	}()
 	go func() {
-		var err error
 		copy(cr, lr)
-		domain.FFTInverse(cr, fft.DIF)
+		fftInverse(acc, domain, cr, fft.DIF, false)
 		fft.BitReverse(cr)
-		bcr, err = blindPoly(cr, domain.Cardinality, 1)
-		chDone <- err
+		bcr = blind(cr, domain.Cardinality, blindings[1])
+		chDone <- struct{}{}
 	}()
 	copy(co, lo)
-	domain.FFTInverse(co, fft.DIF)
+	fftInverse(acc, domain, co, fft.DIF, false)
 	fft.BitReverse(co)
-	if bco, err = blindPoly(co, domain.Cardinality, 1); err != nil {
-		return
-	}
-	err = <-chDone
-	if err != nil {
-		return
-	}
-	err = <-chDone
+	bco = blind(co, domain.Cardinality, blindings[2])
+	<-chDone
+	<-chDone
+
 	return
 
 }
@@ -489,33 +652,46 @@ func computeBlindedLROCanonical(ll, lr, lo []fr.Element, domain *fft.Domain) (bc
 // * cp polynomial in canonical form
 // * rou root of unity, meaning the blinding factor is multiple of X**rou-1
 // * bo blinding order,  it's the degree of Q, where the blinding is Q(X)*(X**degree-1)
+// * rng source of the coefficients of Q
 //
 // WARNING:
 // pre condition degree(cp) ⩽ rou + bo
 // pre condition cap(cp) ⩾ int(totalDegree + 1)
-func blindPoly(cp []fr.Element, rou, bo uint64) ([]fr.Element, error) {
+func blindPoly(cp []fr.Element, rou, bo uint64, rng io.Reader) ([]fr.Element, error) {
+	blindingPoly, err := randomPoly(rng, bo)
+	if err != nil {
+		return nil, err
+	}
+	return blind(cp, rou, blindingPoly), nil
+}
+
+// randomPoly returns a polynomial of degree bo, with coefficients read from rng
+func randomPoly(rng io.Reader, bo uint64) ([]fr.Element, error) {
+	res := make([]fr.Element, bo+1)
+	for i := range res {
+		if err := randomElement(rng, &res[i]); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// blind returns cp + q*(X**rou-1), re-using the memory of cp (see blindPoly)
+func blind(cp []fr.Element, rou uint64, q []fr.Element) []fr.Element {
 
 	// degree of the blinded polynomial is max(rou+order, cp.Degree)
-	totalDegree := rou + bo
+	totalDegree := rou + uint64(len(q)) - 1
 
 	// re-use cp
 	res := cp[:totalDegree+1]
 
-	// random polynomial
-	blindingPoly := make([]fr.Element, bo+1)
-	for i := uint64(0); i < bo+1; i++ {
-		if _, err := blindingPoly[i].SetRandom(); err != nil {
-			return nil, err
-		}
-	}
-
 	// blinding
-	for i := uint64(0); i < bo+1; i++ {
-		res[i].Sub(&res[i], &blindingPoly[i])
-		res[rou+i].Add(&res[rou+i], &blindingPoly[i])
+	for i := range q {
+		res[i].Sub(&res[i], &q[i])
+		res[rou+uint64(i)].Add(&res[rou+uint64(i)], &q[i])
 	}
 
-	return res, nil
+	return res
 
 }
 
@@ -556,14 +732,16 @@ func evaluateLROSmallDomain(spr *cs.SparseR1CS, pk *ProvingKey, solution []fr.El
 
 // computeZ computes Z, in canonical basis, where:
 //
-// * Z of degree n (domainNum.Cardinality)
-// * Z(1)=1
-// 								   (l(g^k)+β*g^k+γ)*(r(g^k)+uβ*g^k+γ)*(o(g^k)+u²β*g^k+γ)
-// * for i>0: Z(gⁱ) = Π_{k<i} -------------------------------------------------------
-//								     (l(g^k)+β*s1(g^k)+γ)*(r(g^k)+β*s2(g^k)+γ)*(o(g^k)+β*s3(\g^k)+γ)
+//   - Z of degree n (domainNum.Cardinality)
+//
+//   - Z(1)=1
+//     (l(g^k)+β*g^k+γ)*(r(g^k)+uβ*g^k+γ)*(o(g^k)+u²β*g^k+γ)
 //
-//	* l, r, o are the solution in Lagrange basis, evaluated on the small domain
-func computeBlindedZCanonical(l, r, o []fr.Element, pk *ProvingKey, beta, gamma fr.Element) ([]fr.Element, error) {
+//   - for i>0: Z(gⁱ) = Π_{k<i} -------------------------------------------------------
+//     (l(g^k)+β*s1(g^k)+γ)*(r(g^k)+β*s2(g^k)+γ)*(o(g^k)+β*s3(\g^k)+γ)
+//
+//   - l, r, o are the solution in Lagrange basis, evaluated on the small domain
+func computeBlindedZCanonical(acc accelerator.Accelerator, l, r, o []fr.Element, pk *ProvingKey, beta, gamma fr.Element, rng io.Reader) ([]fr.Element, error) {
 
 	// note that z has more capacity has its memory is reused for blinded z later on
 	z := make([]fr.Element, pk.Domain[0].Cardinality, pk.Domain[0].Cardinality+3)
@@ -604,40 +782,50 @@ func computeBlindedZCanonical(l, r, o []fr.Element, pk *ProvingKey, beta, gamma
 			Mul(&z[i], &gInv[i])
 	}
 
-	pk.Domain[0].FFTInverse(z, fft.DIF)
+	fftInverse(acc, &pk.Domain[0], z, fft.DIF, false)
 	fft.BitReverse(z)
 
-	return blindPoly(z, pk.Domain[0].Cardinality, 2)
+	return blindPoly(z, pk.Domain[0].Cardinality, 2, rng)
 
 }
 
-// evaluateConstraintsDomainBigBitReversed computes the evaluation of lL+qrR+qqmL.R+qoO+k on
-// the big domain coset.
+// evaluateConstraintsDomainBigBitReversed computes the evaluation of lL+qrR+qqmL.R+qoO+k+∑qcᵢGᵢ(L,R,O)+qcp.π₂ on
+// the big domain coset, where Gᵢ are the custom gates and qcᵢ their selectors.
 //
 // * evalL, evalR, evalO are the evaluation of the blinded solution vectors on odd cosets
 // * qk is the completed version of qk, in canonical version
-func evaluateConstraintsDomainBigBitReversed(pk *ProvingKey, evalL, evalR, evalO, qk []fr.Element) []fr.Element {
+// * pi2 is the polynomial of the committed wires, in canonical version (nil without commitment)
+func evaluateConstraintsDomainBigBitReversed(acc accelerator.Accelerator, pk *ProvingKey, evalL, evalR, evalO, qk, pi2 []fr.Element) []fr.Element {
 	var evalQl, evalQr, evalQm, evalQo, evalQk []fr.Element
 	var wg sync.WaitGroup
 	wg.Add(4)
 
 	go func() {
-		evalQl = evaluateDomainBigBitReversed(pk.Ql, &pk.Domain[1])
+		evalQl = evaluateDomainBigBitReversed(acc, pk.Ql, &pk.Domain[1])
 		wg.Done()
 	}()
 	go func() {
-		evalQr = evaluateDomainBigBitReversed(pk.Qr, &pk.Domain[1])
+		evalQr = evaluateDomainBigBitReversed(acc, pk.Qr, &pk.Domain[1])
 		wg.Done()
 	}()
 	go func() {
-		evalQm = evaluateDomainBigBitReversed(pk.Qm, &pk.Domain[1])
+		evalQm = evaluateDomainBigBitReversed(acc, pk.Qm, &pk.Domain[1])
 		wg.Done()
 	}()
 	go func() {
-		evalQo = evaluateDomainBigBitReversed(pk.Qo, &pk.Domain[1])
+		evalQo = evaluateDomainBigBitReversed(acc, pk.Qo, &pk.Domain[1])
 		wg.Done()
 	}()
-	evalQk = evaluateDomainBigBitReversed(qk, &pk.Domain[1])
+	evalQk = evaluateDomainBigBitReversed(acc, qk, &pk.Domain[1])
+	evalQcustom := make([][]fr.Element, len(pk.Qcustom))
+	for i := range pk.Qcustom {
+		evalQcustom[i] = evaluateDomainBigBitReversed(acc, pk.Qcustom[i], &pk.Domain[1])
+	}
+	var evalQcp, evalPi2 []fr.Element
+	if pi2 != nil {
+		evalQcp = evaluateDomainBigBitReversed(acc, pk.Qcp, &pk.Domain[1])
+		evalPi2 = evaluateDomainBigBitReversed(acc, pi2, &pk.Domain[1])
+	}
 	wg.Wait()
 
 	// computes the evaluation of qrR+qlL+qmL.R+qoO+k on the coset of the big domain
@@ -654,6 +842,17 @@ func evaluateConstraintsDomainBigBitReversed(pk *ProvingKey, evalL, evalR, evalO
 			t1.Mul(&evalQo[i], &evalO[i])
 			t0.Add(&t0, &t1)               // ql.l + qr.r + qm.l.r + qo.o
 			evalQk[i].Add(&t0, &evalQk[i]) // ql.l + qr.r + qm.l.r + qo.o + k
+
+			for j := range evalQcustom {
+				t0 = pk.Vk.CustomGates[j].Evaluate(evalL[i], evalR[i], evalO[i])
+				t0.Mul(&t0, &evalQcustom[j][i])
+				evalQk[i].Add(&evalQk[i], &t0) // + qcⱼ.Gⱼ(l, r, o)
+			}
+
+			if evalPi2 != nil {
+				t0.Mul(&evalQcp[i], &evalPi2[i])
+				evalQk[i].Add(&evalQk[i], &t0) // + qcp.π₂
+			}
 		}
 	})
 
@@ -723,10 +922,10 @@ func evaluateOrderingDomainBigBitReversed(pk *ProvingKey, z, l, r, o []fr.Elemen
 //
 // Puts the result in res of size n.
 // Warning: result is in bit reversed order, we do a bit reverse operation only once in computeQuotientCanonical
-func evaluateDomainBigBitReversed(poly []fr.Element, domainH *fft.Domain) []fr.Element {
+func evaluateDomainBigBitReversed(acc accelerator.Accelerator, poly []fr.Element, domainH *fft.Domain) []fr.Element {
 	res := make([]fr.Element, domainH.Cardinality)
 	copy(res, poly)
-	domainH.FFT(res, fft.DIF, true)
+	fftDomain(acc, domainH, res, fft.DIF, true)
 	return res
 }
 
@@ -756,12 +955,25 @@ func evaluateXnMinusOneDomainBigCoset(domainBig, domainSmall *fft.Domain) []fr.E
 	return res
 }
 
+// evaluateL1DomainBigBitReversed evaluates L₁ on the big domain (coset), in bit reversed order
+func evaluateL1DomainBigBitReversed(acc accelerator.Accelerator, pk *ProvingKey) []fr.Element {
+	// computes L₁ (canonical form)
+	startsAtOne := make([]fr.Element, pk.Domain[1].Cardinality)
+	for i := 0; i < int(pk.Domain[0].Cardinality); i++ {
+		startsAtOne[i].Set(&pk.Domain[0].CardinalityInv)
+	}
+	fftDomain(acc, &pk.Domain[1], startsAtOne, fft.DIF, true)
+	return startsAtOne
+}
+
 // computeQuotientCanonical computes h in canonical form, split as h1+X^mh2+X²mh3 such that
 //
-// ql(X)L(X)+qr(X)R(X)+qm(X)L(X)R(X)+qo(X)O(X)+k(X) + α.(z(μX)*g₁(X)*g₂(X)*g₃(X)-z(X)*f₁(X)*f₂(X)*f₃(X)) + α²*L₁(X)*(Z(X)-1)= h(X)Z(X)
+// ql(X)L(X)+qr(X)R(X)+qm(X)L(X)R(X)+qo(X)O(X)+k(X) + α.(z(μX)*g₁(X)*g₂(X)*g₃(X)-z(X)*f₁(X)*f₂(X)*f₃(X)) + α²*L₁(X)*(Z(X)-1)
+// + α³*lookup(X) = h(X)Z(X)
 //
-// constraintInd, constraintOrdering are evaluated on the big domain (coset).
-func computeQuotientCanonical(pk *ProvingKey, evaluationConstraintsIndBitReversed, evaluationConstraintOrderingBitReversed, evaluationBlindedZDomainBigBitReversed []fr.Element, alpha fr.Element) ([]fr.Element, []fr.Element, []fr.Element) {
+// constraintInd, constraintOrdering, constraintLookup and L₁ are evaluated on the big domain (coset).
+// constraintLookup is nil if the circuit doesn't use lookup tables.
+func computeQuotientCanonical(acc accelerator.Accelerator, pk *ProvingKey, evaluationConstraintsIndBitReversed, evaluationConstraintOrderingBitReversed, evaluationConstraintLookupBitReversed, startsAtOne, evaluationBlindedZDomainBigBitReversed []fr.Element, alpha fr.Element) ([]fr.Element, []fr.Element, []fr.Element) {
 
 	h := make([]fr.Element, pk.Domain[1].Cardinality)
 
@@ -769,13 +981,6 @@ func computeQuotientCanonical(pk *ProvingKey, evaluationConstraintsIndBitReverse
 	evaluationXnMinusOneInverse := evaluateXnMinusOneDomainBigCoset(&pk.Domain[1], &pk.Domain[0])
 	evaluationXnMinusOneInverse = fr.BatchInvert(evaluationXnMinusOneInverse)
 
-	// computes L₁ (canonical form)
-	startsAtOne := make([]fr.Element, pk.Domain[1].Cardinality)
-	for i := 0; i < int(pk.Domain[0].Cardinality); i++ {
-		startsAtOne[i].Set(&pk.Domain[0].CardinalityInv)
-	}
-	pk.Domain[1].FFT(startsAtOne, fft.DIF, true)
-
 	// ql(X)L(X)+qr(X)R(X)+qm(X)L(X)R(X)+qo(X)O(X)+k(X) + α.(z(μX)*g₁(X)*g₂(X)*g₃(X)-z(X)*f₁(X)*f₂(X)*f₃(X)) + α**2*L₁(X)(Z(X)-1)
 	// on a coset of the big domain
 	nn := uint64(64 - bits.TrailingZeros64(pk.Domain[1].Cardinality))
@@ -786,13 +991,18 @@ func computeQuotientCanonical(pk *ProvingKey, evaluationConstraintsIndBitReverse
 	ratio := pk.Domain[1].Cardinality / pk.Domain[0].Cardinality
 
 	utils.Parallelize(int(pk.Domain[1].Cardinality), func(start, end int) {
-		var t fr.Element
+		var t, tl fr.Element
 		for i := uint64(start); i < uint64(end); i++ {
 
 			_i := bits.Reverse64(i) >> nn
 
 			t.Sub(&evaluationBlindedZDomainBigBitReversed[_i], &one) // evaluates L₁(X)*(Z(X)-1) on a coset of the big domain
-			h[_i].Mul(&startsAtOne[_i], &alpha).Mul(&h[_i], &t).
+			t.Mul(&t, &startsAtOne[_i])
+			if evaluationConstraintLookupBitReversed != nil {
+				tl.Mul(&evaluationConstraintLookupBitReversed[_i], &alpha)
+				t.Add(&t, &tl) // L₁(X)*(Z(X)-1) + α*lookup(X)
+			}
+			h[_i].Mul(&t, &alpha).
 				Add(&h[_i], &evaluationConstraintOrderingBitReversed[_i]).
 				Mul(&h[_i], &alpha).
 				Add(&h[_i], &evaluationConstraintsIndBitReversed[_i]).
@@ -802,7 +1012,7 @@ func computeQuotientCanonical(pk *ProvingKey, evaluationConstraintsIndBitReverse
 
 	// put h in canonical form. h is of degree 3*(n+1)+2.
 	// using fft.DIT put h revert bit reverse
-	pk.Domain[1].FFTInverse(h, fft.DIT, true)
+	fftInverse(acc, &pk.Domain[1], h, fft.DIT, true)
 
 	// degree of hi is n+2 because of the blinding
 	h1 := h[:pk.Domain[0].Cardinality+2]
@@ -824,11 +1034,20 @@ func computeQuotientCanonical(pk *ProvingKey, evaluationConstraintsIndBitReverse
 // α²*L₁(ζ)*Z(X)
 // + α*( (l(ζ)+β*s1(ζ)+γ)*(r(ζ)+β*s2(ζ)+γ)*Z(μζ)*s3(X) - Z(X)*(l(ζ)+β*id1(ζ)+γ)*(r(ζ)+β*id2(ζ)+γ)*(o(ζ)+β*id3(ζ)+γ))
 // + l(ζ)*Ql(X) + l(ζ)r(ζ)*Qm(X) + r(ζ)*Qr(X) + o(ζ)*Qo(X) + Qk(X)
-func computeLinearizedPolynomial(lZeta, rZeta, oZeta, alpha, beta, gamma, zeta, zu fr.Element, blindedZCanonical []fr.Element, pk *ProvingKey) []fr.Element {
+// + ∑ Gᵢ(l(ζ), r(ζ), o(ζ))*Qcᵢ(X)
+// + α³*(l(ζ)+η*r(ζ)+η²*o(ζ)-f(ζ))*Qlookup(X) + (α⁵*L₁(ζ)-α⁴*N(ζ))*Zl(X) (lookup argument, if lk != nil)
+// + π₂(ζ)*Qcp(X) (commitment, if pk.Qcp != nil)
+//
+// lkZeta holds f(ζ), t(ζ), t(μζ) for the lookup argument.
+func computeLinearizedPolynomial(lZeta, rZeta, oZeta, alpha, beta, gamma, zeta, zu fr.Element, blindedZCanonical []fr.Element, pk *ProvingKey, lk *lookupProver, lkZeta [3]fr.Element, pi2Zeta fr.Element) []fr.Element {
 
 	// first part: individual constraints
 	var rl fr.Element
 	rl.Mul(&rZeta, &lZeta)
+	gZeta := make([]fr.Element, len(pk.Qcustom))
+	for i := range gZeta {
+		gZeta[i] = pk.Vk.CustomGates[i].Evaluate(lZeta, rZeta, oZeta) // Gᵢ(l(ζ), r(ζ), o(ζ))
+	}
 
 	// second part:
 	// Z(μζ)(l(ζ)+β*s1(ζ)+γ)*(r(ζ)+β*s2(ζ)+γ)*β*s3(X)-Z(X)(l(ζ)+β*id1(ζ)+γ)*(r(ζ)+β*id2(ζ)+γ)*(o(ζ)+β*id3(ζ)+γ)
@@ -870,6 +1089,12 @@ func computeLinearizedPolynomial(lZeta, rZeta, oZeta, alpha, beta, gamma, zeta,
 						Mul(&lagrangeZeta, &alpha).
 						Mul(&lagrangeZeta, &pk.Domain[0].CardinalityInv) // (1/n)*α²*L₁(ζ)
 
+	// fourth part: lookup argument
+	var cLookupQ, cLookupZ fr.Element
+	if lk != nil {
+		cLookupQ, cLookupZ = lk.linearizedCoefficients(lZeta, rZeta, oZeta, lkZeta[0], lkZeta[1], lkZeta[2], alpha, lagrangeZeta)
+	}
+
 	linPol := make([]fr.Element, len(blindedZCanonical))
 	copy(linPol, blindedZCanonical)
 
@@ -902,12 +1127,86 @@ func computeLinearizedPolynomial(lZeta, rZeta, oZeta, alpha, beta, gamma, zeta,
 
 				t0.Mul(&pk.Qo[i], &oZeta).Add(&t0, &pk.CQk[i])
 				linPol[i].Add(&linPol[i], &t0) // linPol = linPol + o(ζ)*Qo(X) + Qk(X)
+
+				for j := range gZeta {
+					t0.Mul(&pk.Qcustom[j][i], &gZeta[j])
+					linPol[i].Add(&linPol[i], &t0) // linPol = linPol + Gⱼ(l(ζ), r(ζ), o(ζ))*Qcⱼ(X)
+				}
+			}
+
+			if i < len(pk.Qcp) {
+				t0.Mul(&pk.Qcp[i], &pi2Zeta)
+				linPol[i].Add(&linPol[i], &t0) // linPol = linPol + π₂(ζ)*Qcp(X)
 			}
 
 			t0.Mul(&blindedZCanonical[i], &lagrangeZeta)
 			linPol[i].Add(&linPol[i], &t0) // finish the computation
+
+			if lk != nil {
+				if i < len(pk.Qlookup) {
+					t0.Mul(&pk.Qlookup[i], &cLookupQ)
+					linPol[i].Add(&linPol[i], &t0) // linPol = linPol + α³*(l(ζ)+η*r(ζ)+η²*o(ζ)-f(ζ))*Qlookup(X)
+				}
+				if i < len(lk.z) {
+					t0.Mul(&lk.z[i], &cLookupZ)
+					linPol[i].Add(&linPol[i], &t0) // linPol = linPol + (α⁵*L₁(ζ)-α⁴*N(ζ))*Zl(X)
+				}
+			}
 		}
 	})
 
 	return linPol
 }
+
+// randomElement sets e to a uniformly random element read from rng, or from
+// crypto/rand.Reader if rng is nil
+func randomElement(rng io.Reader, e *fr.Element) error {
+	if rng == nil {
+		rng = rand.Reader
+	}
+	v, err := rand.Int(rng, fr.Modulus())
+	if err != nil {
+		return err
+	}
+	e.SetBigInt(v)
+	return nil
+}
+
+// commit computes the KZG commitment of p, with acc if it is set
+func commit(acc accelerator.Accelerator, p []fr.Element, srs *kzg.SRS, nbTasks ...int) (kzg.Digest, error) {
+	if acc == nil {
+		return kzg.Commit(p, srs, nbTasks...)
+	}
+	if len(p) == 0 || len(p) > len(srs.G1) {
+		return kzg.Digest{}, kzg.ErrInvalidPolynomialSize
+	}
+	config := ecc.MultiExpConfig{ScalarsMont: true}
+	if len(nbTasks) > 0 {
+		config.NbTasks = nbTasks[0]
+	}
+	var res curve.G1Jac
+	if err := acc.MultiExpG1(&res, srs.G1[:len(p)], p, config); err != nil {
+		return kzg.Digest{}, err
+	}
+	var digest kzg.Digest
+	digest.FromJacobian(&res)
+	return digest, nil
+}
+
+// fftDomain evaluates a on the domain (or its coset), with acc if it is set
+func fftDomain(acc accelerator.Accelerator, domain *fft.Domain, a []fr.Element, decimation fft.Decimation, coset bool) {
+	if acc != nil {
+		acc.FFT(domain, a, accelerator.Decimation(decimation), coset)
+		return
+	}
+	domain.FFT(a, decimation, coset)
+}
+
+// fftInverse interpolates a on the domain (or its coset), with acc if it is set
+func fftInverse(acc accelerator.Accelerator, domain *fft.Domain, a []fr.Element, decimation fft.Decimation, coset bool) {
+	if acc != nil {
+		acc.FFTInverse(domain, a, accelerator.Decimation(decimation), coset)
+		return
+	}
+	domain.FFTInverse(a, decimation, coset)
+}
@@ -0,0 +1,38 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark"
+	"github.com/consensys/gnark/frontend"
+)
+
+// assertIfCircuit checks that Y == X if Cond == 1, and Y != X otherwise
+type assertIfCircuit struct {
+	Cond, X frontend.Variable
+	Y       frontend.Variable `gnark:",public"`
+}
+
+func (circuit *assertIfCircuit) Define(api frontend.API) error {
+	api.AssertIsEqualIf(circuit.Cond, circuit.X, circuit.Y)
+	api.AssertIsDifferentIf(api.Sub(1, circuit.Cond), circuit.X, circuit.Y)
+
+	// constant conditions
+	api.AssertIsEqualIf(0, circuit.X, 42)
+	api.AssertIsDifferentIf(0, circuit.X, circuit.X)
+	api.AssertIsEqualIf(1, circuit.Y, api.Add(circuit.Y, 0))
+	return nil
+}
+
+func init() {
+	good := []frontend.Circuit{
+		&assertIfCircuit{Cond: 1, X: 6, Y: 6},
+		&assertIfCircuit{Cond: 0, X: 6, Y: 37},
+	}
+
+	bad := []frontend.Circuit{
+		&assertIfCircuit{Cond: 1, X: 6, Y: 37},
+		&assertIfCircuit{Cond: 0, X: 6, Y: 6},
+		&assertIfCircuit{Cond: 2, X: 6, Y: 37},
+	}
+
+	addNewEntry("assert_if", &assertIfCircuit{}, good, bad, gnark.Curves())
+}
@@ -0,0 +1,35 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark"
+	"github.com/consensys/gnark/frontend"
+)
+
+type batchInvCircuit struct {
+	A [4]frontend.Variable
+	P frontend.Variable `gnark:",public"`
+}
+
+func (circuit *batchInvCircuit) Define(api frontend.API) error {
+	inv := api.BatchInvert([]frontend.Variable{circuit.A[0], circuit.A[1], 2, circuit.A[2], api.Mul(circuit.A[3], 3)})
+
+	// P = A[0] * A[1] * A[2] * A[3]
+	p := api.Mul(inv[0], inv[1], inv[3], inv[4])
+	api.AssertIsEqual(api.Mul(p, circuit.P, 3), 1)
+	api.AssertIsEqual(api.Mul(inv[2], 2), 1)
+	return nil
+}
+
+func init() {
+	good := []frontend.Circuit{
+		&batchInvCircuit{A: [4]frontend.Variable{1, 2, 3, 4}, P: 24},
+		&batchInvCircuit{A: [4]frontend.Variable{-1, 7, 7, 13}, P: -637},
+	}
+
+	bad := []frontend.Circuit{
+		&batchInvCircuit{A: [4]frontend.Variable{1, 2, 3, 4}, P: 25},
+		&batchInvCircuit{A: [4]frontend.Variable{1, 0, 3, 4}, P: 0},
+	}
+
+	addNewEntry("batch_inv", &batchInvCircuit{}, good, bad, gnark.Curves())
+}
@@ -0,0 +1,36 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark"
+	"github.com/consensys/gnark/frontend"
+)
+
+// bitwiseCircuit applies the boolean operations to linear expressions of
+// several terms, and IsZero to constants
+type bitwiseCircuit struct {
+	X, Y    frontend.Variable
+	Xor, Or frontend.Variable `gnark:",public"`
+}
+
+func (circuit *bitwiseCircuit) Define(api frontend.API) error {
+	notX := api.Sub(1, circuit.X)
+	api.AssertIsEqual(api.Xor(notX, circuit.Y), circuit.Xor)
+	api.AssertIsEqual(api.Or(notX, circuit.Y), circuit.Or)
+
+	api.AssertIsEqual(api.IsZero(0), 1)
+	api.AssertIsEqual(api.IsZero(3), 0)
+	return nil
+}
+
+func init() {
+	var good, bad []frontend.Circuit
+	for x := 0; x < 2; x++ {
+		for y := 0; y < 2; y++ {
+			notX := 1 - x
+			good = append(good, &bitwiseCircuit{X: x, Y: y, Xor: notX ^ y, Or: notX | y})
+			bad = append(bad, &bitwiseCircuit{X: x, Y: y, Xor: 1 - (notX ^ y), Or: notX | y})
+		}
+	}
+
+	addNewEntry("bitwise_linear_expressions", &bitwiseCircuit{}, good, bad, gnark.Curves())
+}
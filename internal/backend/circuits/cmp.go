@@ -35,6 +35,16 @@ func init() {
 			B: 12345,
 			R: 0,
 		},
+		&cmpCircuit{
+			A: -1, // modulus - 1
+			B: 0,
+			R: 1,
+		},
+		&cmpCircuit{
+			A: 0,
+			B: -1,
+			R: -1,
+		},
 	}
 
 	bad := []frontend.Circuit{
@@ -53,6 +63,11 @@ func init() {
 			B: 12345,
 			R: 1,
 		},
+		&cmpCircuit{
+			A: -1,
+			B: 0,
+			R: -1,
+		},
 	}
 
 	addNewEntry("cmp", &cmpCircuit{}, good, bad, gnark.Curves())
@@ -0,0 +1,46 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark"
+	"github.com/consensys/gnark/frontend"
+)
+
+// permutationCircuit checks that Y is a permutation of X, with the challenge
+// c from api.Commit: Π(c-X[i]) == Π(c-Y[i])
+type permutationCircuit struct {
+	X [4]frontend.Variable
+	Y [4]frontend.Variable
+	S frontend.Variable `gnark:",public"`
+}
+
+func (circuit *permutationCircuit) Define(api frontend.API) error {
+	toCommit := make([]frontend.Variable, 0, 2*len(circuit.X))
+	toCommit = append(toCommit, circuit.X[:]...)
+	toCommit = append(toCommit, circuit.Y[:]...)
+	c := api.Commit(toCommit...)
+
+	px, py := frontend.Variable(1), frontend.Variable(1)
+	sum := frontend.Variable(0)
+	for i := 0; i < len(circuit.X); i++ {
+		px = api.Mul(px, api.Sub(c, circuit.X[i]))
+		py = api.Mul(py, api.Sub(c, circuit.Y[i]))
+		sum = api.Add(sum, circuit.X[i])
+	}
+	api.AssertIsEqual(px, py)
+	api.AssertIsEqual(sum, circuit.S)
+	return nil
+}
+
+func init() {
+	good := []frontend.Circuit{
+		&permutationCircuit{X: [4]frontend.Variable{1, 2, 3, 4}, Y: [4]frontend.Variable{4, 2, 1, 3}, S: 10},
+		&permutationCircuit{X: [4]frontend.Variable{0, 0, 7, 7}, Y: [4]frontend.Variable{7, 0, 7, 0}, S: 14},
+	}
+
+	bad := []frontend.Circuit{
+		&permutationCircuit{X: [4]frontend.Variable{1, 2, 3, 4}, Y: [4]frontend.Variable{4, 2, 1, 1}, S: 10},
+		&permutationCircuit{X: [4]frontend.Variable{1, 2, 3, 4}, Y: [4]frontend.Variable{4, 2, 1, 3}, S: 11},
+	}
+
+	addNewEntry("commit", &permutationCircuit{}, good, bad, gnark.Curves())
+}
@@ -1,6 +1,8 @@
 package circuits
 
 import (
+	"math/big"
+
 	"github.com/consensys/gnark"
 	"github.com/consensys/gnark/frontend"
 )
@@ -38,3 +40,38 @@ func init() {
 
 	addEntry("expo", &circuit, &good, &bad, gnark.Curves())
 }
+
+type expAPICircuit struct {
+	X, E frontend.Variable
+	Y, Z frontend.Variable `gnark:",public"`
+}
+
+func (circuit *expAPICircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Exp(circuit.X, circuit.E, 4), circuit.Y)
+	api.AssertIsEqual(api.Exp(circuit.X, circuit.E), circuit.Y)
+	api.AssertIsEqual(api.Exp(circuit.X, 12), circuit.Z)
+	api.AssertIsEqual(api.Exp(circuit.X, 0), 1)
+	api.AssertIsEqual(api.Exp(2, 12), 4096)
+
+	// Fermat inverse
+	e := new(big.Int).Sub(api.Compiler().Curve().Info().Fr.Modulus(), big.NewInt(2))
+	api.AssertIsEqual(api.Mul(api.Exp(circuit.X, e), circuit.X), 1)
+	return nil
+}
+
+func init() {
+	good := []frontend.Circuit{
+		&expAPICircuit{X: 2, E: 12, Y: 4096, Z: 4096},
+		&expAPICircuit{X: 3, E: 0, Y: 1, Z: 531441},
+		&expAPICircuit{X: -1, E: 15, Y: -1, Z: 1},
+	}
+
+	bad := []frontend.Circuit{
+		&expAPICircuit{X: 2, E: 11, Y: 4096, Z: 4096},
+		&expAPICircuit{X: 2, E: 12, Y: 4096, Z: 4095},
+		&expAPICircuit{X: 2, E: 16, Y: 65536, Z: 4096}, // exponent doesn't fit on 4 bits
+		&expAPICircuit{X: 0, E: 1, Y: 0, Z: 0},         // 0 has no inverse
+	}
+
+	addNewEntry("exp_api", &expAPICircuit{}, good, bad, gnark.Curves())
+}
@@ -0,0 +1,63 @@
+package circuits
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark"
+	"github.com/consensys/gnark/frontend"
+)
+
+// xorGate is l + r - 2⋅l⋅r - o
+var xorGate = frontend.Gate{
+	Name: "xor",
+	Terms: []frontend.GateTerm{
+		{Coeff: big.NewInt(1), L: 1},
+		{Coeff: big.NewInt(1), R: 1},
+		{Coeff: big.NewInt(-2), L: 1, R: 1},
+		{Coeff: big.NewInt(-1), O: 1},
+	},
+}
+
+// cubeGate is l³ + r - o
+var cubeGate = frontend.Gate{
+	Name: "cube",
+	Terms: []frontend.GateTerm{
+		{Coeff: big.NewInt(1), L: 3},
+		{Coeff: big.NewInt(1), R: 1},
+		{Coeff: big.NewInt(-1), O: 1},
+	},
+}
+
+type gateCircuit struct {
+	A, B, C frontend.Variable
+	X, Z    frontend.Variable
+	Y       frontend.Variable `gnark:",public"`
+}
+
+func (circuit *gateCircuit) Define(api frontend.API) error {
+	api.AssertIsBoolean(circuit.A)
+	api.AssertIsBoolean(circuit.B)
+	frontend.AssertGate(api, &xorGate, circuit.A, circuit.B, circuit.C)
+
+	// x³ + 5 == y
+	frontend.AssertGate(api, &cubeGate, circuit.X, 5, circuit.Y)
+
+	// (2x)³ + x == z
+	frontend.AssertGate(api, &cubeGate, api.Mul(circuit.X, 2), circuit.X, circuit.Z)
+	return nil
+}
+
+func init() {
+	good := []frontend.Circuit{
+		&gateCircuit{A: 1, B: 0, C: 1, X: 3, Y: 32, Z: 219},
+		&gateCircuit{A: 1, B: 1, C: 0, X: 0, Y: 5, Z: 0},
+	}
+
+	bad := []frontend.Circuit{
+		&gateCircuit{A: 1, B: 1, C: 1, X: 3, Y: 32, Z: 219},
+		&gateCircuit{A: 1, B: 0, C: 1, X: 3, Y: 33, Z: 219},
+		&gateCircuit{A: 1, B: 0, C: 1, X: 3, Y: 32, Z: 30},
+	}
+
+	addNewEntry("gate", &gateCircuit{}, good, bad, gnark.Curves())
+}
@@ -0,0 +1,45 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark"
+	"github.com/consensys/gnark/frontend"
+)
+
+type inRangeCircuit struct {
+	X            frontend.Variable
+	Lower, Upper frontend.Variable `gnark:",public"`
+	R, RC        frontend.Variable
+}
+
+func (circuit *inRangeCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.IsInRange(circuit.X, circuit.Lower, circuit.Upper), circuit.R)
+	api.AssertIsEqual(api.IsInRange(circuit.X, 10, 20), circuit.RC)
+
+	api.AssertIsInRange(circuit.X, 5, 1000)
+	api.AssertIsInRange(circuit.Lower, 1, circuit.Upper)
+	return nil
+}
+
+func init() {
+
+	good := []frontend.Circuit{
+		&inRangeCircuit{X: 15, Lower: 10, Upper: 20, R: 1, RC: 1},
+		&inRangeCircuit{X: 10, Lower: 10, Upper: 10, R: 1, RC: 1},
+		&inRangeCircuit{X: 20, Lower: 10, Upper: 20, R: 1, RC: 1},
+		&inRangeCircuit{X: 21, Lower: 10, Upper: 20, R: 0, RC: 0},
+		&inRangeCircuit{X: 9, Lower: 10, Upper: 20, R: 0, RC: 0},
+		&inRangeCircuit{X: 1000, Lower: 1, Upper: -1, R: 1, RC: 0}, // upper = modulus - 1
+	}
+
+	bad := []frontend.Circuit{
+		&inRangeCircuit{X: 4, Lower: 10, Upper: 20, R: 0, RC: 0},
+		&inRangeCircuit{X: 1001, Lower: 10, Upper: 20, R: 0, RC: 0},
+		&inRangeCircuit{X: -1, Lower: 10, Upper: 20, R: 0, RC: 0}, // modulus - 1
+		&inRangeCircuit{X: 21, Lower: 10, Upper: 20, R: 0, RC: 1},
+		&inRangeCircuit{X: 20, Lower: 10, Upper: 19, R: 1, RC: 1},
+		&inRangeCircuit{X: 15, Lower: 0, Upper: 20, R: 1, RC: 1},
+		&inRangeCircuit{X: 15, Lower: 10, Upper: 5, R: 0, RC: 1},
+	}
+
+	addNewEntry("inrange", &inRangeCircuit{}, good, bad, gnark.Curves())
+}
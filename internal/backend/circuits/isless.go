@@ -0,0 +1,111 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark"
+	"github.com/consensys/gnark/frontend"
+)
+
+type isLessCircuit struct {
+	A     frontend.Variable
+	B     frontend.Variable `gnark:",public"`
+	Bound frontend.Variable `gnark:",public"`
+	R     frontend.Variable
+
+	// A < 13 and 12 < A, with a constant operand
+	RCst, RCstFirst frontend.Variable
+}
+
+func (circuit *isLessCircuit) Define(api frontend.API) error {
+	r := api.IsLess(circuit.A, circuit.B)
+	api.AssertIsEqual(r, circuit.R)
+	api.AssertIsEqual(api.IsLess(circuit.A, 13), circuit.RCst)
+	api.AssertIsEqual(api.IsLess(12, circuit.A), circuit.RCstFirst)
+
+	api.AssertIsLess(circuit.A, circuit.Bound)
+	api.AssertIsLess(circuit.A, 1000)
+	return nil
+}
+
+func init() {
+
+	good := []frontend.Circuit{
+		&isLessCircuit{
+			A:         12,
+			B:         13,
+			Bound:     13,
+			R:         1,
+			RCst:      1,
+			RCstFirst: 0,
+		},
+		&isLessCircuit{
+			A:         13,
+			B:         12,
+			Bound:     14,
+			R:         0,
+			RCst:      0,
+			RCstFirst: 1,
+		},
+		&isLessCircuit{
+			A:         0,
+			B:         0,
+			Bound:     1,
+			R:         0,
+			RCst:      1,
+			RCstFirst: 0,
+		},
+		&isLessCircuit{
+			A:         999,
+			B:         -1, // modulus - 1
+			Bound:     1000,
+			R:         1,
+			RCst:      0,
+			RCstFirst: 1,
+		},
+	}
+
+	bad := []frontend.Circuit{
+		&isLessCircuit{
+			A:         13,
+			B:         12,
+			Bound:     14,
+			R:         1,
+			RCst:      0,
+			RCstFirst: 1,
+		},
+		&isLessCircuit{
+			A:         12,
+			B:         13,
+			Bound:     12,
+			R:         1,
+			RCst:      1,
+			RCstFirst: 0,
+		},
+		&isLessCircuit{
+			A:         1000,
+			B:         1001,
+			Bound:     1001,
+			R:         1,
+			RCst:      0,
+			RCstFirst: 1,
+		},
+	}
+
+	// the constant comparisons are checked
+	bad = append(bad, &isLessCircuit{
+		A:         12,
+		B:         13,
+		Bound:     13,
+		R:         1,
+		RCst:      0,
+		RCstFirst: 0,
+	}, &isLessCircuit{
+		A:         13,
+		B:         12,
+		Bound:     14,
+		R:         0,
+		RCst:      0,
+		RCstFirst: 0,
+	})
+
+	addNewEntry("isless", &isLessCircuit{}, good, bad, gnark.Curves())
+}
@@ -0,0 +1,56 @@
+package circuits
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark"
+	"github.com/consensys/gnark/frontend"
+)
+
+// squareTable holds the squares of 0..9
+var squareTable = frontend.Table{Name: "square"}
+
+// xorTable holds a⊕b for 2-bit a, b at index 4a+b
+var xorTable = frontend.Table{Name: "xor2"}
+
+func init() {
+	for i := int64(0); i < 10; i++ {
+		squareTable.Values = append(squareTable.Values, big.NewInt(i*i))
+	}
+	for a := int64(0); a < 4; a++ {
+		for b := int64(0); b < 4; b++ {
+			xorTable.Values = append(xorTable.Values, big.NewInt(a^b))
+		}
+	}
+}
+
+type lookupCircuit struct {
+	X, Y, A, B frontend.Variable
+	Z          frontend.Variable `gnark:",public"`
+}
+
+func (circuit *lookupCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(frontend.Lookup(api, &squareTable, circuit.X), circuit.Y)
+	api.AssertIsEqual(frontend.Lookup(api, &squareTable, 3), 9)
+
+	i := api.Add(api.Mul(circuit.A, 4), circuit.B)
+	api.AssertIsEqual(frontend.Lookup(api, &xorTable, i), circuit.Z)
+	return nil
+}
+
+func init() {
+	good := []frontend.Circuit{
+		&lookupCircuit{X: 3, Y: 9, A: 1, B: 3, Z: 2},
+		&lookupCircuit{X: 9, Y: 81, A: 2, B: 2, Z: 0},
+		&lookupCircuit{X: 0, Y: 0, A: 3, B: 0, Z: 3},
+	}
+
+	bad := []frontend.Circuit{
+		&lookupCircuit{X: 10, Y: 100, A: 1, B: 3, Z: 2},
+		&lookupCircuit{X: 3, Y: 10, A: 1, B: 3, Z: 2},
+		&lookupCircuit{X: 3, Y: 9, A: 1, B: 3, Z: 3},
+		&lookupCircuit{X: 3, Y: 9, A: 4, B: 0, Z: 0},
+	}
+
+	addNewEntry("lookup", &lookupCircuit{}, good, bad, gnark.Curves())
+}
@@ -0,0 +1,44 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark"
+	"github.com/consensys/gnark/frontend"
+)
+
+type lookupNCircuit struct {
+	V        [8]frontend.Variable `gnark:",secret"`
+	Selector [3]frontend.Variable `gnark:",secret"`
+	Expected frontend.Variable    `gnark:",public"`
+}
+
+func (c *lookupNCircuit) Define(api frontend.API) error {
+	selected := api.LookupN(c.Selector[:], c.V[:])
+	api.AssertIsEqual(selected, c.Expected)
+
+	// with a constant most significant bit, the lower half of the values is never selected
+	values := make([]frontend.Variable, 16)
+	for i := 0; i < 8; i++ {
+		values[i] = 0
+		values[8+i] = c.V[i]
+	}
+	selected = api.LookupN([]frontend.Variable{c.Selector[0], c.Selector[1], c.Selector[2], 1}, values)
+	api.AssertIsEqual(selected, c.Expected)
+	return nil
+}
+
+func init() {
+	var v [8]frontend.Variable
+	for i := range v {
+		v[i] = 10 + i
+	}
+	good := []frontend.Circuit{}
+	bad := []frontend.Circuit{}
+	for i := 0; i < len(v); i++ {
+		selector := [3]frontend.Variable{i & 1, (i >> 1) & 1, i >> 2}
+		good = append(good, &lookupNCircuit{v, selector, 10 + i})
+		bad = append(bad, &lookupNCircuit{v, selector, 10 + (i+1)%8})
+	}
+	bad = append(bad, &lookupNCircuit{v, [3]frontend.Variable{2, 0, 0}, 12})
+
+	addNewEntry("lookupN", &lookupNCircuit{}, good, bad, gnark.Curves())
+}
@@ -0,0 +1,42 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark"
+	"github.com/consensys/gnark/frontend"
+)
+
+type variadicBooleanCircuit struct {
+	B                       [5]frontend.Variable
+	Xor, Or, And, Nand, Nor frontend.Variable `gnark:",public"`
+}
+
+func (circuit *variadicBooleanCircuit) Define(api frontend.API) error {
+	b := circuit.B
+	api.AssertIsEqual(api.Xor(b[0], b[1], b[2], b[3], b[4]), circuit.Xor)
+	api.AssertIsEqual(api.Or(b[0], b[1], b[2], b[3], b[4]), circuit.Or)
+	api.AssertIsEqual(api.And(b[0], b[1], b[2], b[3], b[4]), circuit.And)
+	api.AssertIsEqual(api.Nand(b[0], b[1], b[2], b[3], b[4]), circuit.Nand)
+	api.AssertIsEqual(api.Nor(b[0], b[1], b[2], b[3], b[4]), circuit.Nor)
+
+	// constant inputs
+	api.AssertIsEqual(api.Xor(b[0], 1, b[1], 1), api.Xor(b[0], b[1]))
+	api.AssertIsEqual(api.And(b[0], 1, 1), b[0])
+	api.AssertIsEqual(api.Nor(b[0], 0, b[0]), api.Nand(b[0], 1))
+	return nil
+}
+
+func init() {
+	good := []frontend.Circuit{
+		&variadicBooleanCircuit{B: [5]frontend.Variable{1, 0, 1, 1, 0}, Xor: 1, Or: 1, And: 0, Nand: 1, Nor: 0},
+		&variadicBooleanCircuit{B: [5]frontend.Variable{1, 1, 1, 1, 1}, Xor: 1, Or: 1, And: 1, Nand: 0, Nor: 0},
+		&variadicBooleanCircuit{B: [5]frontend.Variable{0, 0, 0, 0, 0}, Xor: 0, Or: 0, And: 0, Nand: 1, Nor: 1},
+	}
+
+	bad := []frontend.Circuit{
+		&variadicBooleanCircuit{B: [5]frontend.Variable{1, 0, 1, 1, 0}, Xor: 0, Or: 1, And: 0, Nand: 1, Nor: 0},
+		&variadicBooleanCircuit{B: [5]frontend.Variable{1, 1, 1, 1, 1}, Xor: 1, Or: 1, And: 1, Nand: 1, Nor: 0},
+		&variadicBooleanCircuit{B: [5]frontend.Variable{0, 0, 0, 0, 2}, Xor: 0, Or: 0, And: 0, Nand: 1, Nor: 1},
+	}
+
+	addNewEntry("variadic_boolean", &variadicBooleanCircuit{}, good, bad, gnark.Curves())
+}
@@ -0,0 +1,82 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prover
+
+import "sync"
+
+// BufferPool holds the scratch buffers of the prover between calls to Prove,
+// so that proving back-to-back with the same key doesn't reallocate them, see
+// backend.WithProverBufferPool.
+//
+// A BufferPool may be shared by concurrent provers: a buffer is used by one
+// prover at a time, the others allocate their own. As the buffers are typed
+// by the scalar field, a pool should only be shared by provers on one curve.
+type BufferPool struct {
+	lock    sync.Mutex
+	budget  int
+	size    int
+	buffers map[string]pooledBuffer
+}
+
+type pooledBuffer struct {
+	buf  interface{}
+	size int
+}
+
+// NewBufferPool returns an empty BufferPool holding at most budget bytes of
+// buffers. If budget is 0, the pool is not bounded.
+func NewBufferPool(budget int) *BufferPool {
+	return &BufferPool{budget: budget, buffers: make(map[string]pooledBuffer)}
+}
+
+// Get removes the buffer named name from the pool and returns it, or nil if
+// the pool doesn't hold it.
+func (p *BufferPool) Get(name string) interface{} {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	b, ok := p.buffers[name]
+	if !ok {
+		return nil
+	}
+	delete(p.buffers, name)
+	p.size -= b.size
+	return b.buf
+}
+
+// Put stores buf, of size bytes, in the pool under name, replacing the buffer
+// it held under this name if any. buf is dropped if it would exceed the budget
+// of the pool.
+func (p *BufferPool) Put(name string, buf interface{}, size int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if b, ok := p.buffers[name]; ok {
+		delete(p.buffers, name)
+		p.size -= b.size
+	}
+	if p.budget != 0 && p.size+size > p.budget {
+		return
+	}
+	p.buffers[name] = pooledBuffer{buf: buf, size: size}
+	p.size += size
+}
+
+// Size returns the number of bytes of buffers held by the pool.
+func (p *BufferPool) Size() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.size
+}
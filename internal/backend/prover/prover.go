@@ -0,0 +1,150 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package prover holds the configuration of the provers and of the constraint
+// system solver. It is apart from package backend, which exposes it as
+// backend.ProverConfig and backend.ProverOption, so that the verifiers built
+// with the verifieronly tag don't link the hints and the accelerators.
+package prover
+
+import (
+	"context"
+	"crypto/rand"
+	"io"
+	"runtime"
+
+	"github.com/consensys/gnark/backend/accelerator"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/logger"
+	"github.com/rs/zerolog"
+)
+
+// Option defines option for altering the behaviour of the prover in Prove,
+// ReadAndProve and IsSolved methods, see backend.ProverOption.
+type Option func(*Config) error
+
+// Config is the configuration for the prover with the options applied, see
+// backend.ProverConfig.
+type Config struct {
+	Force         bool                      // defaults to false
+	HintFunctions map[hint.ID]hint.Function // defaults to all built-in hint functions
+	CircuitLogger zerolog.Logger            // defaults to gnark.Logger
+	Accelerator   accelerator.Accelerator   // defaults to nil, computes MSMs and FFTs with gnark-crypto
+
+	SolverTrace        io.Writer // defaults to nil, the solver is not traced
+	SolverTraceRegions []string  // defaults to nil, all the constraints are traced
+
+	Context  context.Context                // defaults to context.Background()
+	Progress func(phase Phase, percent int) // defaults to nil, the progress isn't reported
+
+	BufferPool *BufferPool // defaults to nil, the scratch buffers are allocated by each call
+	MSM        MSMConfig   // defaults to the split of MSMConfig, from the number of CPUs
+
+	RandomSource io.Reader // defaults to crypto/rand.Reader
+	RandomKey    []byte    // defaults to nil, the randomness is read from RandomSource
+}
+
+// MSMConfig sets in how many tasks the prover splits its multi-scalar
+// multiplications (MSMs), see backend.WithMSMConfig. A zero field is set from the
+// others as described below.
+type MSMConfig struct {
+	// NbTasks is the number of tasks the prover runs its MSMs with, split among
+	// the MSMs running concurrently. Defaults to runtime.NumCPU().
+	NbTasks int
+
+	// NbTasksG1 is the number of tasks of each of the G1 MSMs running
+	// concurrently: the MSMs of A, B and K of groth16, the commitments to l, r, o
+	// and to the quotient of PLONK. Defaults to NbTasks/2.
+	NbTasksG1 int
+
+	// NbTasksG2 is the number of tasks of the G2 MSM of groth16, which is the
+	// longest one. Defaults to NbTasks, doubled if NbTasks ≤ 16 to balance the
+	// tasks.
+	NbTasksG2 int
+}
+
+// MSMTasks returns opt.MSM with its zero fields set to their default.
+func (opt *Config) MSMTasks() MSMConfig {
+	res := opt.MSM
+	if res.NbTasks == 0 {
+		res.NbTasks = runtime.NumCPU()
+	}
+	if res.NbTasksG1 == 0 {
+		res.NbTasksG1 = res.NbTasks / 2
+	}
+	if res.NbTasksG2 == 0 {
+		res.NbTasksG2 = res.NbTasks
+		if res.NbTasksG2 <= 16 {
+			// if we don't have a lot of CPUs, this may artificially split the MSM
+			res.NbTasksG2 *= 2
+		}
+	}
+	return res
+}
+
+// Phase is a phase of the prover, see backend.WithProgress.
+type Phase uint8
+
+const (
+	PhaseSolve Phase = iota // the constraint system solver
+	PhaseFFT                // the FFTs of the polynomials
+	PhaseMSM                // the multi-scalar multiplications (commitments, proof elements)
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseSolve:
+		return "solve"
+	case PhaseFFT:
+		return "fft"
+	case PhaseMSM:
+		return "msm"
+	default:
+		return "unknown"
+	}
+}
+
+// Checkpoint is called by the prover once done of the total steps of phase are
+// done. It reports the progress to opt.Progress, and returns the error of
+// opt.Context if it is done, in which case the prover stops.
+func (opt *Config) Checkpoint(phase Phase, done, total int) error {
+	if opt.Progress != nil {
+		percent := 100
+		if total != 0 {
+			percent = 100 * done / total
+		}
+		opt.Progress(phase, percent)
+	}
+	if opt.Context == nil {
+		return nil
+	}
+	return opt.Context.Err()
+}
+
+// NewConfig returns a default Config with given prover options opts applied.
+func NewConfig(opts ...Option) (Config, error) {
+	log := logger.Logger()
+	opt := Config{CircuitLogger: log, HintFunctions: make(map[hint.ID]hint.Function), Context: context.Background(), RandomSource: rand.Reader}
+	for _, v := range hint.GetRegistered() {
+		opt.HintFunctions[hint.UUID(v)] = v
+	}
+	for _, option := range opts {
+		if err := option(&opt); err != nil {
+			return Config{}, err
+		}
+	}
+	return opt, nil
+}
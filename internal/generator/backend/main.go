@@ -72,9 +72,17 @@ func main() {
 			if err := os.MkdirAll(d.RootPath+"plonk", 0700); err != nil {
 				panic(err)
 			}
+			if err := os.MkdirAll(d.RootPath+"nova", 0700); err != nil {
+				panic(err)
+			}
+			if err := os.MkdirAll(d.RootPath+"plonkfri", 0700); err != nil {
+				panic(err)
+			}
 
 			groth16Dir := filepath.Join(d.RootPath, "groth16")
 			plonkDir := filepath.Join(d.RootPath, "plonk")
+			novaDir := filepath.Join(d.RootPath, "nova")
+			plonkFRIDir := filepath.Join(d.RootPath, "plonkfri")
 			backendCSDir := filepath.Join(d.RootPath, "cs")
 			witnessDir := filepath.Join(d.RootPath, "witness")
 
@@ -104,17 +112,25 @@ func main() {
 
 			entries = []bavard.Entry{
 				{File: filepath.Join(groth16Dir, "verify.go"), Templates: []string{"groth16/groth16.verify.go.tmpl", importCurve}},
-				{File: filepath.Join(groth16Dir, "prove.go"), Templates: []string{"groth16/groth16.prove.go.tmpl", importCurve}},
-				{File: filepath.Join(groth16Dir, "setup.go"), Templates: []string{"groth16/groth16.setup.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16Dir, "prove.go"), Templates: []string{"groth16/groth16.prove.go.tmpl", importCurve}, BuildTag: "!verifieronly"},
+				{File: filepath.Join(groth16Dir, "setup.go"), Templates: []string{"groth16/groth16.setup.go.tmpl", importCurve}, BuildTag: "!verifieronly"},
 				{File: filepath.Join(groth16Dir, "marshal.go"), Templates: []string{"groth16/groth16.marshal.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16Dir, "marshal_pk.go"), Templates: []string{"groth16/groth16.marshal_pk.go.tmpl", importCurve}, BuildTag: "!verifieronly"},
+				{File: filepath.Join(groth16Dir, "aggregate.go"), Templates: []string{"groth16/groth16.aggregate.go.tmpl", importCurve}, BuildTag: "!verifieronly"},
+				{File: filepath.Join(groth16Dir, "dump.go"), Templates: []string{"groth16/groth16.dump.go.tmpl", importCurve}, BuildTag: "!verifieronly"},
+				{File: filepath.Join(groth16Dir, "sections.go"), Templates: []string{"groth16/groth16.sections.go.tmpl", importCurve}, BuildTag: "!verifieronly"},
+				{File: filepath.Join(groth16Dir, "precompute.go"), Templates: []string{"groth16/groth16.precompute.go.tmpl", importCurve}, BuildTag: "!verifieronly"},
+				{File: filepath.Join(groth16Dir, "distributed.go"), Templates: []string{"groth16/groth16.distributed.go.tmpl", importCurve}, BuildTag: "!verifieronly"},
+				{File: filepath.Join(groth16Dir, "link.go"), Templates: []string{"groth16/groth16.link.go.tmpl", importCurve}},
 				{File: filepath.Join(groth16Dir, "marshal_test.go"), Templates: []string{"groth16/tests/groth16.marshal.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16Dir, "marshal_pk_test.go"), Templates: []string{"groth16/tests/groth16.marshal_pk.go.tmpl", importCurve}, BuildTag: "!verifieronly"},
 			}
 			if err := bgen.Generate(d, "groth16", "./template/zkpschemes/", entries...); err != nil {
 				panic(err) // TODO handle
 			}
 
 			entries = []bavard.Entry{
-				{File: filepath.Join(groth16Dir, "groth16_test.go"), Templates: []string{"groth16/tests/groth16.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16Dir, "groth16_test.go"), Templates: []string{"groth16/tests/groth16.go.tmpl", importCurve}, BuildTag: "!verifieronly"},
 			}
 			if err := bgen.Generate(d, "groth16_test", "./template/zkpschemes/", entries...); err != nil {
 				panic(err) // TODO handle
@@ -123,22 +139,46 @@ func main() {
 			// plonk
 			entries = []bavard.Entry{
 				{File: filepath.Join(plonkDir, "verify.go"), Templates: []string{"plonk/plonk.verify.go.tmpl", importCurve}},
-				{File: filepath.Join(plonkDir, "prove.go"), Templates: []string{"plonk/plonk.prove.go.tmpl", importCurve}},
-				{File: filepath.Join(plonkDir, "setup.go"), Templates: []string{"plonk/plonk.setup.go.tmpl", importCurve}},
+				{File: filepath.Join(plonkDir, "prove.go"), Templates: []string{"plonk/plonk.prove.go.tmpl", importCurve}, BuildTag: "!verifieronly"},
+				{File: filepath.Join(plonkDir, "setup.go"), Templates: []string{"plonk/plonk.setup.go.tmpl", importCurve}, BuildTag: "!verifieronly"},
 				{File: filepath.Join(plonkDir, "marshal.go"), Templates: []string{"plonk/plonk.marshal.go.tmpl", importCurve}},
+				{File: filepath.Join(plonkDir, "marshal_pk.go"), Templates: []string{"plonk/plonk.marshal_pk.go.tmpl", importCurve}, BuildTag: "!verifieronly"},
+				{File: filepath.Join(plonkDir, "lookup.go"), Templates: []string{"plonk/plonk.lookup.go.tmpl", importCurve}, BuildTag: "!verifieronly"},
+				{File: filepath.Join(plonkDir, "lagrange.go"), Templates: []string{"plonk/plonk.lagrange.go.tmpl", importCurve}, BuildTag: "!verifieronly"},
+				{File: filepath.Join(plonkDir, "dump.go"), Templates: []string{"plonk/plonk.dump.go.tmpl", importCurve}, BuildTag: "!verifieronly"},
 				{File: filepath.Join(plonkDir, "marshal_test.go"), Templates: []string{"plonk/tests/marshal.go.tmpl", importCurve}},
+				{File: filepath.Join(plonkDir, "marshal_pk_test.go"), Templates: []string{"plonk/tests/marshal_pk.go.tmpl", importCurve}, BuildTag: "!verifieronly"},
 			}
 			if err := bgen.Generate(d, "plonk", "./template/zkpschemes/", entries...); err != nil {
 				panic(err)
 			}
 
 			entries = []bavard.Entry{
-				{File: filepath.Join(plonkDir, "plonk_test.go"), Templates: []string{"plonk/tests/plonk.go.tmpl", importCurve}},
+				{File: filepath.Join(plonkDir, "plonk_test.go"), Templates: []string{"plonk/tests/plonk.go.tmpl", importCurve}, BuildTag: "!verifieronly"},
 			}
 			if err := bgen.Generate(d, "plonk_test", "./template/zkpschemes/", entries...); err != nil {
 				panic(err)
 			}
 
+			// plonkfri
+			entries = []bavard.Entry{
+				{File: filepath.Join(plonkFRIDir, "verify.go"), Templates: []string{"plonkfri/plonkfri.verify.go.tmpl", importCurve}},
+				{File: filepath.Join(plonkFRIDir, "prove.go"), Templates: []string{"plonkfri/plonkfri.prove.go.tmpl", importCurve}, BuildTag: "!verifieronly"},
+				{File: filepath.Join(plonkFRIDir, "setup.go"), Templates: []string{"plonkfri/plonkfri.setup.go.tmpl", importCurve}, BuildTag: "!verifieronly"},
+				{File: filepath.Join(plonkFRIDir, "marshal.go"), Templates: []string{"plonkfri/plonkfri.marshal.go.tmpl", importCurve}},
+			}
+			if err := bgen.Generate(d, "plonkfri", "./template/zkpschemes/", entries...); err != nil {
+				panic(err)
+			}
+
+			// nova
+			entries = []bavard.Entry{
+				{File: filepath.Join(novaDir, "nova.go"), Templates: []string{"nova/nova.go.tmpl", importCurve}, BuildTag: "!verifieronly"},
+			}
+			if err := bgen.Generate(d, "nova", "./template/zkpschemes/", entries...); err != nil {
+				panic(err)
+			}
+
 		}(d)
 
 	}
@@ -0,0 +1,112 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package merkle implements binary Merkle trees with SHA-256, with which the
+// transparent backends commit to vectors of evaluations.
+//
+// Leaves and inner nodes are hashed with distinct prefixes, so that an inner
+// node can't be opened as a leaf.
+package merkle
+
+import (
+	"crypto/sha256"
+
+	"github.com/consensys/gnark/internal/utils"
+)
+
+const (
+	prefixLeaf byte = iota
+	prefixNode
+)
+
+// Digest is the root of a Tree, or one of its nodes
+type Digest [sha256.Size]byte
+
+// Tree is a binary Merkle tree, whose number of leaves is a power of 2
+type Tree struct {
+	// nodes[1] is the root, the children of nodes[i] are nodes[2i] and nodes[2i+1],
+	// and the hashes of the leaves are nodes[nbLeaves:]
+	nodes []Digest
+}
+
+// New returns the Merkle tree of leaves. len(leaves) must be a power of 2.
+func New(leaves [][]byte) *Tree {
+	n := len(leaves)
+	if n == 0 || n&(n-1) != 0 {
+		panic("the number of leaves must be a power of 2")
+	}
+	t := &Tree{nodes: make([]Digest, 2*n)}
+	utils.Parallelize(n, func(start, end int) {
+		for i := start; i < end; i++ {
+			t.nodes[n+i] = hashLeaf(leaves[i])
+		}
+	})
+	for i := n - 1; i > 0; i-- {
+		t.nodes[i] = hashNode(&t.nodes[2*i], &t.nodes[2*i+1])
+	}
+	return t
+}
+
+// Root returns the root of the tree
+func (t *Tree) Root() Digest {
+	return t.nodes[1]
+}
+
+// Path returns the authentication path of the i-th leaf: the siblings of the
+// nodes from the leaf to the root
+func (t *Tree) Path(i int) []Digest {
+	var path []Digest
+	for j := len(t.nodes)/2 + i; j > 1; j /= 2 {
+		path = append(path, t.nodes[j^1])
+	}
+	return path
+}
+
+// Verify returns true if path authenticates leaf as the i-th leaf of the tree
+// of the given root
+func Verify(root Digest, leaf []byte, i int, path []Digest) bool {
+	if i < 0 || i>>len(path) != 0 {
+		return false
+	}
+	h := hashLeaf(leaf)
+	for _, sibling := range path {
+		sibling := sibling
+		if i&1 == 0 {
+			h = hashNode(&h, &sibling)
+		} else {
+			h = hashNode(&sibling, &h)
+		}
+		i >>= 1
+	}
+	return h == root
+}
+
+func hashLeaf(leaf []byte) Digest {
+	h := sha256.New()
+	h.Write([]byte{prefixLeaf})
+	h.Write(leaf)
+	var res Digest
+	copy(res[:], h.Sum(nil))
+	return res
+}
+
+func hashNode(left, right *Digest) Digest {
+	h := sha256.New()
+	h.Write([]byte{prefixNode})
+	h.Write(left[:])
+	h.Write(right[:])
+	var res Digest
+	copy(res[:], h.Sum(nil))
+	return res
+}
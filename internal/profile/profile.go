@@ -0,0 +1,297 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package profile records the call stacks creating the constraints of a
+// circuit, and writes them as a profile in the pprof format, such that
+//
+//	go tool pprof -top constraints.pprof
+//
+// shows the functions which add the most constraints. The samples of the
+// constraints created in a namespace (see frontend.Namespace) have the label
+// "namespace", such that
+//
+//	go tool pprof -tagfocus namespace=sha256 -top constraints.pprof
+//
+// restricts the profile to a component of the circuit.
+package profile
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"runtime"
+	"strings"
+)
+
+// maxDepth is the maximum number of frames recorded per call stack
+const maxDepth = 64
+
+// frames of the functions with these prefixes are omitted from the profile
+var omitted = []string{
+	"runtime.",
+	"github.com/consensys/gnark/frontend/cs/",
+	"github.com/consensys/gnark/internal/profile.",
+}
+
+// Recorder counts the constraints created by each call stack and namespace
+type Recorder struct {
+	stacks     [][]uintptr
+	namespaces []string
+	counts     []int64
+	mStacks    map[string]int // maps a namespace and a stack (as a string) to its index in stacks
+	pcs        [maxDepth]uintptr
+	key        [maxDepth * 8]byte
+}
+
+// NewRecorder returns an empty Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{mStacks: make(map[string]int)}
+}
+
+// Record adds a constraint created by the caller of Record, in the namespace
+// namespace ("" outside of any namespace)
+func (r *Recorder) Record(namespace string) {
+	// skip runtime.Callers and Record
+	n := runtime.Callers(2, r.pcs[:])
+	pcs := r.pcs[:n]
+
+	for i, pc := range pcs {
+		binary.LittleEndian.PutUint64(r.key[8*i:], uint64(pc))
+	}
+	key := namespace + "\x00" + string(r.key[:8*n])
+	if i, ok := r.mStacks[key]; ok {
+		r.counts[i]++
+		return
+	}
+	r.mStacks[key] = len(r.stacks)
+	r.stacks = append(r.stacks, append([]uintptr(nil), pcs...))
+	r.namespaces = append(r.namespaces, namespace)
+	r.counts = append(r.counts, 1)
+}
+
+// NbConstraints returns the number of constraints recorded
+func (r *Recorder) NbConstraints() int64 {
+	var res int64
+	for _, c := range r.counts {
+		res += c
+	}
+	return res
+}
+
+// WriteTo writes the profile on w, in the gzipped protocol buffer format
+// read by pprof. The profile has a single sample type, "constraints".
+func (r *Recorder) WriteTo(w io.Writer) (int64, error) {
+	b := newBuilder()
+
+	var sampleType protobuf
+	sampleType.int64(1, b.stringID("constraints"))
+	sampleType.int64(2, b.stringID("count"))
+
+	var p protobuf
+	p.message(1, &sampleType)
+
+	for i, stack := range r.stacks {
+		var locations []uint64
+		for _, pc := range stack {
+			locations = append(locations, b.locations(pc)...)
+		}
+		var sample protobuf
+		sample.uint64s(1, locations)
+		sample.uint64s(2, []uint64{uint64(r.counts[i])})
+		if r.namespaces[i] != "" {
+			var label protobuf
+			label.int64(1, b.stringID("namespace"))
+			label.int64(2, b.stringID(r.namespaces[i]))
+			sample.message(3, &label)
+		}
+		p.message(2, &sample)
+	}
+	p.data = append(p.data, b.locationsData.data...)
+	p.data = append(p.data, b.functionsData.data...)
+	for _, s := range b.strings {
+		p.string(6, s)
+	}
+	p.int64(14, b.stringID("constraints"))
+
+	cw := &countWriter{w: w}
+	zw := gzip.NewWriter(cw)
+	if _, err := zw.Write(p.data); err != nil {
+		return cw.n, err
+	}
+	err := zw.Close()
+	return cw.n, err
+}
+
+// builder assigns the IDs of the strings, functions and locations of a profile
+type builder struct {
+	strings    []string
+	mStrings   map[string]int64
+	mFunctions map[string]uint64
+	mLocations map[location]uint64
+	mPCs       map[uintptr][]uint64
+
+	functionsData, locationsData protobuf
+}
+
+type location struct {
+	function string
+	file     string
+	line     int
+}
+
+func newBuilder() *builder {
+	return &builder{
+		strings:    []string{""},
+		mStrings:   map[string]int64{"": 0},
+		mFunctions: make(map[string]uint64),
+		mLocations: make(map[location]uint64),
+		mPCs:       make(map[uintptr][]uint64),
+	}
+}
+
+func (b *builder) stringID(s string) int64 {
+	if id, ok := b.mStrings[s]; ok {
+		return id
+	}
+	id := int64(len(b.strings))
+	b.strings = append(b.strings, s)
+	b.mStrings[s] = id
+	return id
+}
+
+// locations returns the IDs of the locations of the frames at pc (several
+// if some calls were inlined), innermost first
+func (b *builder) locations(pc uintptr) []uint64 {
+	if ids, ok := b.mPCs[pc]; ok {
+		return ids
+	}
+	var ids []uint64
+	frames := runtime.CallersFrames([]uintptr{pc})
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && !isOmitted(frame.Function) {
+			ids = append(ids, b.location(location{function: frame.Function, file: frame.File, line: frame.Line}))
+		}
+		if !more {
+			break
+		}
+	}
+	b.mPCs[pc] = ids
+	return ids
+}
+
+func (b *builder) location(l location) uint64 {
+	if id, ok := b.mLocations[l]; ok {
+		return id
+	}
+	id := uint64(len(b.mLocations) + 1)
+	b.mLocations[l] = id
+
+	var line protobuf
+	line.uint64(1, b.function(l.function, l.file))
+	line.int64(2, int64(l.line))
+
+	var loc protobuf
+	loc.uint64(1, id)
+	loc.message(4, &line)
+	b.locationsData.message(4, &loc)
+	return id
+}
+
+func (b *builder) function(name, file string) uint64 {
+	if id, ok := b.mFunctions[name]; ok {
+		return id
+	}
+	id := uint64(len(b.mFunctions) + 1)
+	b.mFunctions[name] = id
+
+	var f protobuf
+	f.uint64(1, id)
+	f.int64(2, b.stringID(name))
+	f.int64(3, b.stringID(name))
+	f.int64(4, b.stringID(file))
+	b.functionsData.message(5, &f)
+	return id
+}
+
+func isOmitted(function string) bool {
+	for _, prefix := range omitted {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// protobuf encodes the fields of a protocol buffer message
+type protobuf struct {
+	data []byte
+}
+
+func (p *protobuf) varint(x uint64) {
+	for x >= 0x80 {
+		p.data = append(p.data, byte(x)|0x80)
+		x >>= 7
+	}
+	p.data = append(p.data, byte(x))
+}
+
+func (p *protobuf) key(tag int, wireType uint64) {
+	p.varint(uint64(tag)<<3 | wireType)
+}
+
+func (p *protobuf) uint64(tag int, x uint64) {
+	p.key(tag, 0)
+	p.varint(x)
+}
+
+func (p *protobuf) int64(tag int, x int64) {
+	p.uint64(tag, uint64(x))
+}
+
+func (p *protobuf) bytes(tag int, b []byte) {
+	p.key(tag, 2)
+	p.varint(uint64(len(b)))
+	p.data = append(p.data, b...)
+}
+
+func (p *protobuf) string(tag int, s string) {
+	p.bytes(tag, []byte(s))
+}
+
+func (p *protobuf) message(tag int, m *protobuf) {
+	p.bytes(tag, m.data)
+}
+
+// uint64s encodes a packed repeated field
+func (p *protobuf) uint64s(tag int, x []uint64) {
+	var packed protobuf
+	for _, v := range x {
+		packed.varint(v)
+	}
+	p.bytes(tag, packed.data)
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
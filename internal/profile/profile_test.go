@@ -0,0 +1,101 @@
+package profile_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/stretchr/testify/require"
+)
+
+type profiledCircuit struct {
+	X, Y frontend.Variable
+}
+
+func (circuit *profiledCircuit) Define(api frontend.API) error {
+	x3 := cube(api, circuit.X)
+	api.AssertIsEqual(x3, circuit.Y)
+	return nil
+}
+
+func cube(api frontend.API, x frontend.Variable) frontend.Variable {
+	defer frontend.Namespace(api, "cube")()
+	return api.Mul(x, x, x)
+}
+
+func TestWithProfile(t *testing.T) {
+	assert := require.New(t)
+
+	for _, newBuilder := range []frontend.NewBuilder{r1cs.NewBuilder, scs.NewBuilder} {
+		var buf bytes.Buffer
+		ccs, err := frontend.Compile(ecc.BN254, newBuilder, &profiledCircuit{}, frontend.WithProfile(&buf))
+		assert.NoError(err)
+
+		zr, err := gzip.NewReader(&buf)
+		assert.NoError(err)
+		data, err := io.ReadAll(zr)
+		assert.NoError(err)
+
+		var strings []string
+		var nbConstraints uint64
+		for _, f := range fields(t, data) {
+			switch f.tag {
+			case 2: // sample
+				for _, s := range fields(t, f.data) {
+					if s.tag == 2 { // packed values
+						v, _ := binary.Uvarint(s.data)
+						nbConstraints += v
+					}
+				}
+			case 6: // string table
+				strings = append(strings, string(f.data))
+			}
+		}
+		assert.Equal(uint64(ccs.GetNbConstraints()), nbConstraints)
+		assert.Equal("", strings[0])
+		assert.Contains(strings, "constraints")
+		assert.Contains(strings, "namespace")
+		assert.Contains(strings, "cube")
+		assert.Contains(strings, "github.com/consensys/gnark/internal/profile_test.cube")
+		assert.Contains(strings, "github.com/consensys/gnark/internal/profile_test.(*profiledCircuit).Define")
+		for _, s := range strings {
+			assert.NotContains(s, "gnark/frontend/cs/")
+		}
+	}
+}
+
+type field struct {
+	tag  uint64
+	data []byte // bytes of a length-delimited field, nil for a varint
+}
+
+// fields decodes the fields of a protocol buffer message
+func fields(t *testing.T, data []byte) []field {
+	var res []field
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		require.Greater(t, n, 0)
+		data = data[n:]
+		switch key & 7 {
+		case 0:
+			_, n = binary.Uvarint(data)
+			require.Greater(t, n, 0)
+			data = data[n:]
+			res = append(res, field{tag: key >> 3})
+		case 2:
+			l, n := binary.Uvarint(data)
+			require.Greater(t, n, 0)
+			res = append(res, field{tag: key >> 3, data: data[n : n+int(l)]})
+			data = data[n+int(l):]
+		default:
+			t.Fatalf("unexpected wire type %d", key&7)
+		}
+	}
+	return res
+}
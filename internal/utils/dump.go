@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"unsafe"
+)
+
+// dumpMagic starts every dump, followed by dumpEndianness written in the byte order of the
+// running architecture
+const (
+	dumpMagic      = "gnarkdmp"
+	dumpEndianness = uint64(0x0102030405060708)
+	dumpAlignment  = 8
+)
+
+var errInvalidDump = errors.New("invalid dump: written by a different architecture or corrupted")
+
+// DumpWriter writes byte sections to a dump, each section starting on an 8 bytes boundary
+// so that it can be cast back to a slice of its original type (see DumpReader)
+type DumpWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+// NewDumpWriter returns a DumpWriter on w, and writes the dump header
+func NewDumpWriter(w io.Writer) *DumpWriter {
+	dw := &DumpWriter{w: w}
+	dw.write([]byte(dumpMagic))
+	var e [8]byte
+	*(*uint64)(unsafe.Pointer(&e[0])) = dumpEndianness
+	dw.write(e[:])
+	return dw
+}
+
+func (dw *DumpWriter) write(b []byte) {
+	if dw.err != nil {
+		return
+	}
+	n, err := dw.w.Write(b)
+	dw.n += int64(n)
+	dw.err = err
+}
+
+// WriteBytes writes the size of b, b, and pads it to the next 8 bytes boundary
+func (dw *DumpWriter) WriteBytes(b []byte) {
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(b)))
+	dw.write(size[:])
+	dw.write(b)
+	if r := len(b) % dumpAlignment; r != 0 {
+		dw.write(make([]byte, dumpAlignment-r))
+	}
+}
+
+// BytesWritten returns the number of bytes written and the first error encountered
+func (dw *DumpWriter) BytesWritten() (int64, error) {
+	return dw.n, dw.err
+}
+
+// DumpReader reads the sections of a dump written by a DumpWriter, from a buffer that is
+// usually memory mapped. The sections it returns are not copied.
+type DumpReader struct {
+	data   []byte
+	offset int
+}
+
+// NewDumpReader returns a DumpReader on data, after checking the dump header
+func NewDumpReader(data []byte) (*DumpReader, error) {
+	if len(data) < 16 || string(data[:8]) != dumpMagic || *(*uint64)(unsafe.Pointer(&data[8])) != dumpEndianness {
+		return nil, errInvalidDump
+	}
+	if uintptr(unsafe.Pointer(&data[0]))%dumpAlignment != 0 {
+		return nil, errors.New("invalid dump: buffer is not aligned")
+	}
+	return &DumpReader{data: data, offset: 16}, nil
+}
+
+// ReadBytes returns the next section of the dump
+func (dr *DumpReader) ReadBytes() ([]byte, error) {
+	if len(dr.data)-dr.offset < 8 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	size := binary.LittleEndian.Uint64(dr.data[dr.offset:])
+	dr.offset += 8
+	if size > uint64(len(dr.data)-dr.offset) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := dr.data[dr.offset : dr.offset+int(size) : dr.offset+int(size)]
+	dr.offset += int(size)
+	if r := dr.offset % dumpAlignment; r != 0 {
+		dr.offset += dumpAlignment - r
+	}
+	return b, nil
+}
+
+// ReadSlice returns the next section of the dump, checking its size is a multiple of elemSize
+// so that the caller can cast it to a slice of len(b)/elemSize elements
+func (dr *DumpReader) ReadSlice(elemSize uintptr) ([]byte, error) {
+	b, err := dr.ReadBytes()
+	if err != nil {
+		return nil, err
+	}
+	if uintptr(len(b))%elemSize != 0 {
+		return nil, errInvalidDump
+	}
+	return b, nil
+}
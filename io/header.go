@@ -0,0 +1,174 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+)
+
+// Kind is the type of a serialized object
+type Kind uint8
+
+const (
+	KindUnknown Kind = iota
+	KindProof
+	KindVerifyingKey
+	KindProvingKey
+	KindConstraintSystem
+	KindLinkProof
+	KindProvingKeySections
+	KindDebugInfo
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindProof:
+		return "proof"
+	case KindVerifyingKey:
+		return "verifying key"
+	case KindProvingKey:
+		return "proving key"
+	case KindConstraintSystem:
+		return "constraint system"
+	case KindLinkProof:
+		return "link proof"
+	case KindProvingKeySections:
+		return "sectioned proving key"
+	case KindDebugInfo:
+		return "debug info"
+	default:
+		return "unknown object"
+	}
+}
+
+// Versions of the encodings. Each change of the encoding of an object bumps
+// HeaderVersion, and the readers branch on the version read to decode the
+// former encodings. The encodings written before the headers were introduced
+// are of version 0, see ReadHeader.
+const (
+	// VersionHeader introduced the headers
+	VersionHeader uint8 = 1
+
+	// VersionPlonkTranscriptHash records the hash of the Fiat-Shamir
+	// transcript in PLONK verifying keys
+	VersionPlonkTranscriptHash uint8 = 2
+
+	// VersionProvingKeySections introduced the sectioned encoding of Groth16
+	// proving keys (KindProvingKeySections)
+	VersionProvingKeySections uint8 = 3
+
+	// VersionPrecomputedTables records the fixed-base tables of Groth16
+	// proving keys
+	VersionPrecomputedTables uint8 = 4
+
+	// VersionCircuitFingerprint records the fingerprint of the circuit in the
+	// verifying keys
+	VersionCircuitFingerprint uint8 = 5
+)
+
+// HeaderVersion is the version of the encodings written by this version of
+// gnark
+const HeaderVersion = VersionCircuitFingerprint
+
+// HeaderSize is the size in bytes of a header
+const HeaderSize = 10
+
+// headerMagic starts the headers. Its first byte can't start the encodings
+// written before the headers were introduced (an elliptic curve point or a
+// big-endian integer).
+var headerMagic = []byte("gnrk")
+
+// ErrHeaderMismatch is returned by ReadHeader when the object read doesn't
+// match the expected one
+var ErrHeaderMismatch = errors.New("header mismatch")
+
+// WriteHeader writes to w the header of the binary encoding of an object of
+// the given kind, over the curve curveID and for the backend backendID:
+//
+//	magic "gnrk" | version (uint8) | curveID (uint16) | backendID (uint16) | kind (uint8)
+//
+// in big-endian.
+func WriteHeader(w io.Writer, curveID ecc.ID, backendID backend.ID, kind Kind) (int64, error) {
+	var buf [HeaderSize]byte
+	copy(buf[:], headerMagic)
+	buf[4] = HeaderVersion
+	binary.BigEndian.PutUint16(buf[5:], uint16(curveID))
+	binary.BigEndian.PutUint16(buf[7:], uint16(backendID))
+	buf[9] = uint8(kind)
+	n, err := w.Write(buf[:])
+	return int64(n), err
+}
+
+// ReadHeader reads from r a header written by WriteHeader and returns the
+// version of the encoding which follows, or an error wrapping
+// ErrHeaderMismatch if it doesn't match curveID, backendID and kind, or if it
+// was written by a newer version of gnark.
+//
+// If r doesn't start with a header, it holds an encoding written before the
+// headers were introduced. Only the proofs and keys of Groth16 and PLONK were
+// encoded then: ReadHeader returns a reader which reads the bytes consumed
+// again, the version 0 and 0 bytes read, and the object must be read from the
+// returned reader in the layout of these encodings. For the other objects,
+// ReadHeader returns an error wrapping ErrHeaderMismatch.
+func ReadHeader(r io.Reader, curveID ecc.ID, backendID backend.ID, kind Kind) (io.Reader, uint8, int64, error) {
+	var buf [HeaderSize]byte
+	n, err := io.ReadFull(r, buf[:len(headerMagic)])
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return r, 0, int64(n), err
+	}
+	if !bytes.Equal(buf[:n], headerMagic) {
+		if !encodedWithoutHeader(backendID, kind) {
+			return r, 0, int64(n), fmt.Errorf("%w: missing header, expected a %s %s over %s", ErrHeaderMismatch, backendID, kind, curveID)
+		}
+		return io.MultiReader(bytes.NewReader(buf[:n]), r), 0, 0, nil
+	}
+
+	m, err := io.ReadFull(r, buf[len(headerMagic):])
+	read := int64(n + m)
+	if err != nil {
+		return r, 0, read, err
+	}
+	version := buf[4]
+	gotCurveID := ecc.ID(binary.BigEndian.Uint16(buf[5:]))
+	gotBackendID := backend.ID(binary.BigEndian.Uint16(buf[7:]))
+	gotKind := Kind(buf[9])
+
+	if version > HeaderVersion {
+		return r, version, read, fmt.Errorf("%w: encoding version %d is not supported, upgrade gnark", ErrHeaderMismatch, version)
+	}
+	if gotCurveID != curveID || gotBackendID != backendID || gotKind != kind {
+		return r, version, read, fmt.Errorf("%w: read a %s %s over %s, expected a %s %s over %s", ErrHeaderMismatch,
+			gotBackendID, gotKind, gotCurveID, backendID, kind, curveID)
+	}
+	return r, version, read, nil
+}
+
+// encodedWithoutHeader returns true if the objects of the given kind for the
+// backend backendID were encoded before the headers were introduced
+func encodedWithoutHeader(backendID backend.ID, kind Kind) bool {
+	if backendID != backend.GROTH16 && backendID != backend.PLONK {
+		return false
+	}
+	return kind == KindProof || kind == KindVerifyingKey || kind == KindProvingKey
+}
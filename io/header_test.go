@@ -0,0 +1,80 @@
+package io
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeader(t *testing.T) {
+	assert := require.New(t)
+
+	var buf bytes.Buffer
+	n, err := WriteHeader(&buf, ecc.BLS12_381, backend.GROTH16, KindVerifyingKey)
+	assert.NoError(err)
+	assert.Equal(int64(HeaderSize), n)
+	buf.WriteString("payload")
+	encoded := buf.Bytes()
+
+	// matching header
+	r, version, n, err := ReadHeader(bytes.NewReader(encoded), ecc.BLS12_381, backend.GROTH16, KindVerifyingKey)
+	assert.NoError(err)
+	assert.Equal(HeaderVersion, version)
+	assert.Equal(int64(HeaderSize), n)
+	payload, err := io.ReadAll(r)
+	assert.NoError(err)
+	assert.Equal("payload", string(payload))
+
+	// mismatches
+	for _, expected := range []struct {
+		curveID   ecc.ID
+		backendID backend.ID
+		kind      Kind
+	}{
+		{ecc.BN254, backend.GROTH16, KindVerifyingKey},
+		{ecc.BLS12_381, backend.PLONK, KindVerifyingKey},
+		{ecc.BLS12_381, backend.GROTH16, KindProvingKey},
+	} {
+		_, _, _, err = ReadHeader(bytes.NewReader(encoded), expected.curveID, expected.backendID, expected.kind)
+		assert.True(errors.Is(err, ErrHeaderMismatch), "expected a header mismatch, got %v", err)
+	}
+
+	// newer version
+	future := append([]byte{}, encoded...)
+	future[len(headerMagic)] = HeaderVersion + 1
+	_, _, _, err = ReadHeader(bytes.NewReader(future), ecc.BLS12_381, backend.GROTH16, KindVerifyingKey)
+	assert.True(errors.Is(err, ErrHeaderMismatch), "expected a header mismatch, got %v", err)
+
+	// the proofs and keys encoded without header are read from the start, as
+	// version 0
+	for _, legacy := range []string{"", "ab", "legacy payload"} {
+		r, version, n, err = ReadHeader(bytes.NewReader([]byte(legacy)), ecc.BN254, backend.PLONK, KindProof)
+		assert.NoError(err)
+		assert.Equal(uint8(0), version)
+		assert.Equal(int64(0), n)
+		payload, err = io.ReadAll(r)
+		assert.NoError(err)
+		assert.Equal(legacy, string(payload))
+	}
+
+	// the other objects were always encoded with a header
+	for _, expected := range []struct {
+		backendID backend.ID
+		kind      Kind
+	}{
+		{backend.GROTH16, KindConstraintSystem},
+		{backend.PLONK, KindConstraintSystem},
+		{backend.GROTH16, KindLinkProof},
+		{backend.GROTH16, KindProvingKeySections},
+		{backend.PLONKFRI, KindProof},
+		{backend.UNKNOWN, KindDebugInfo},
+	} {
+		_, _, _, err = ReadHeader(bytes.NewReader([]byte("legacy payload")), ecc.BN254, expected.backendID, expected.kind)
+		assert.True(errors.Is(err, ErrHeaderMismatch), "expected a header mismatch, got %v", err)
+	}
+}
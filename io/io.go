@@ -39,3 +39,14 @@ type WriterRawTo interface {
 type UnsafeReaderFrom interface {
 	UnsafeReadFrom(r io.Reader) (int64, error)
 }
+
+// Dumper is the interface that wraps the WriteDump and ReadDump methods.
+//
+// WriteDump writes the object in the memory layout of the running architecture, so that
+// ReadDump can set its large slices from a memory mapped file (see Mmap), without copying
+// nor decoding them. A dump is not portable across architectures, and ReadDump doesn't
+// perform any check on the data: it must come from a trusted source.
+type Dumper interface {
+	WriteDump(w io.Writer) (n int64, err error)
+	ReadDump(data []byte) error
+}
@@ -0,0 +1,24 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd
+
+package io
+
+import (
+	"io"
+	"os"
+)
+
+// Mmap reads the file at path in memory: memory mapping is not supported on this platform.
+func Mmap(path string) (data []byte, closer io.Closer, err error) {
+	data, err = os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, closerFunc(func() error { return nil }), nil
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
@@ -0,0 +1,41 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+// +build linux darwin freebsd netbsd openbsd
+
+package io
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+// Mmap maps the file at path in memory, read only. The pages of the file are loaded when
+// accessed and can be evicted by the OS, so that the file can be larger than the available RAM.
+// Closing the returned io.Closer unmaps the file: data must not be used after.
+func Mmap(path string) (data []byte, closer io.Closer, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	if info.Size() == 0 {
+		return nil, closerFunc(func() error { return nil }), nil
+	}
+
+	data, err = syscall.Mmap(int(f.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, closerFunc(func() error { return syscall.Munmap(data) }), nil
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
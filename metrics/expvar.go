@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"expvar"
+	"sync"
+)
+
+// ExpvarRecorder is a Recorder publishing the measurements as expvar
+// variables, see NewExpvarRecorder.
+type ExpvarRecorder struct {
+	vars *expvar.Map
+	lock sync.Mutex // serializes the updates of the peaks
+}
+
+// NewExpvarRecorder returns a Recorder publishing the measurements in the
+// expvar map name, served by the expvar handler (/debug/vars). For each phase
+// and backend, it holds the keys
+//
+//	<phase>.<backend>.count           number of measurements
+//	<phase>.<backend>.errors          number of measurements ending with an error
+//	<phase>.<backend>.seconds         total duration in seconds
+//	<phase>.<backend>.last_seconds    duration of the last measurement
+//	<phase>.<backend>.constraints     number of constraints of the last measurement
+//	<phase>.<backend>.msm_size        MSM size of the last measurement
+//	<phase>.<backend>.peak_heap_bytes highest peak of the heap of the measurements
+//
+// for example prove.groth16.seconds. Like expvar.NewMap, it panics if name is
+// already published.
+func NewExpvarRecorder(name string) *ExpvarRecorder {
+	return &ExpvarRecorder{vars: expvar.NewMap(name)}
+}
+
+// Record implements Recorder
+func (r *ExpvarRecorder) Record(e Event) {
+	prefix := e.Phase.String() + "." + e.Backend.String() + "."
+	r.vars.Add(prefix+"count", 1)
+	if e.Err != nil {
+		r.vars.Add(prefix+"errors", 1)
+	}
+	r.vars.AddFloat(prefix+"seconds", e.Duration.Seconds())
+	r.set(prefix+"last_seconds", e.Duration.Seconds())
+	r.setInt(prefix+"constraints", int64(e.NbConstraints))
+	if e.MSMSize != 0 {
+		r.setInt(prefix+"msm_size", int64(e.MSMSize))
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	peak, ok := r.vars.Get(prefix + "peak_heap_bytes").(*expvar.Int)
+	if !ok || peak.Value() < int64(e.PeakHeap) {
+		r.setInt(prefix+"peak_heap_bytes", int64(e.PeakHeap))
+	}
+}
+
+func (r *ExpvarRecorder) set(key string, v float64) {
+	f := new(expvar.Float)
+	f.Set(v)
+	r.vars.Set(key, f)
+}
+
+func (r *ExpvarRecorder) setInt(key string, v int64) {
+	i := new(expvar.Int)
+	i.Set(v)
+	r.vars.Set(key, i)
+}
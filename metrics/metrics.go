@@ -0,0 +1,184 @@
+// Package metrics reports measurements of the phases of gnark (compilation,
+// setup, solver and prover) to a Recorder, for example to export them to
+// Prometheus or expvar.
+//
+// No measurement is taken until a Recorder is set with Set. For example, with
+// the Prometheus client:
+//
+//	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+//		Name: "gnark_phase_duration_seconds",
+//	}, []string{"phase", "backend", "curve"})
+//	prometheus.MustRegister(duration)
+//	metrics.Set(metrics.RecorderFunc(func(e metrics.Event) {
+//		duration.WithLabelValues(e.Phase.String(), e.Backend.String(), e.CurveID.String()).
+//			Observe(e.Duration.Seconds())
+//	}))
+//
+// or with the Recorder of this package publishing expvar variables:
+//
+//	metrics.Set(metrics.NewExpvarRecorder("gnark"))
+package metrics
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+)
+
+// Phase is a measured phase of gnark
+type Phase uint8
+
+const (
+	PhaseCompile Phase = iota // frontend.Compile
+	PhaseSetup                // the setup of the keys of a circuit
+	PhaseSolve                // the constraint system solver, also run by the provers
+	PhaseProve                // the prover, its solver included
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseCompile:
+		return "compile"
+	case PhaseSetup:
+		return "setup"
+	case PhaseSolve:
+		return "solve"
+	case PhaseProve:
+		return "prove"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is the measurement of a phase, given to the Recorder when the phase ends
+type Event struct {
+	Phase   Phase
+	CurveID ecc.ID
+	// Backend is UNKNOWN for PhaseCompile, as the builder doesn't tell the
+	// backend, and PLONK for the solver of all the sparse constraint systems
+	Backend backend.ID
+
+	Duration time.Duration
+	Err      error // error the phase ended with, if any
+
+	// NbConstraints and NbWires are the number of constraints and of wires of
+	// the constraint system
+	NbConstraints int
+	NbWires       int
+
+	// MSMSize is the number of points of the largest multi-scalar multiplication
+	// of the prover: the largest set of points of the proving key for groth16,
+	// the size of the domain for PLONK, 0 otherwise
+	MSMSize int
+
+	// PeakHeap is the highest runtime.MemStats.HeapInuse sampled during the
+	// phase, in bytes. The heap is sampled every HeapSamplingPeriod, so that the
+	// peak of a short phase may be missed.
+	PeakHeap uint64
+}
+
+// Recorder records the measurements of the phases. Record may be called
+// concurrently, and must return quickly as it blocks the measured call.
+type Recorder interface {
+	Record(e Event)
+}
+
+// RecorderFunc is a function implementing Recorder
+type RecorderFunc func(e Event)
+
+// Record calls f(e)
+func (f RecorderFunc) Record(e Event) {
+	f(e)
+}
+
+// HeapSamplingPeriod is the period at which the heap is sampled during a
+// measured phase, see Event.PeakHeap
+const HeapSamplingPeriod = 100 * time.Millisecond
+
+var recorder struct {
+	sync.RWMutex
+	r Recorder
+}
+
+// Set sets the Recorder of the measurements, nil to stop measuring
+func Set(r Recorder) {
+	recorder.Lock()
+	recorder.r = r
+	recorder.Unlock()
+}
+
+func get() Recorder {
+	recorder.RLock()
+	defer recorder.RUnlock()
+	return recorder.r
+}
+
+// Measurement measures a phase, from Start to Stop
+type Measurement struct {
+	r     Recorder
+	event Event
+	start time.Time
+
+	peakHeap uint64 // accessed atomically
+	done     chan struct{}
+}
+
+// Start starts measuring a phase. It returns nil, on which Stop does nothing,
+// if no Recorder is set.
+func Start(phase Phase, curveID ecc.ID, backendID backend.ID) *Measurement {
+	r := get()
+	if r == nil {
+		return nil
+	}
+	m := &Measurement{
+		r:     r,
+		event: Event{Phase: phase, CurveID: curveID, Backend: backendID},
+		start: time.Now(),
+		done:  make(chan struct{}),
+	}
+	m.sampleHeap()
+	go func() {
+		ticker := time.NewTicker(HeapSamplingPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.sampleHeap()
+			case <-m.done:
+				return
+			}
+		}
+	}()
+	return m
+}
+
+// sampleHeap updates the peak of the heap with its current size
+func (m *Measurement) sampleHeap() {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	for {
+		peak := atomic.LoadUint64(&m.peakHeap)
+		if stats.HeapInuse <= peak || atomic.CompareAndSwapUint64(&m.peakHeap, peak, stats.HeapInuse) {
+			return
+		}
+	}
+}
+
+// Stop ends the measurement and gives it to the Recorder, with the counts and
+// the error of e. The phase, curve, backend, duration and peak of the heap
+// are set by the measurement.
+func (m *Measurement) Stop(e Event) {
+	if m == nil {
+		return
+	}
+	e.Duration = time.Since(m.start)
+	close(m.done)
+	m.sampleHeap()
+	e.Phase, e.CurveID, e.Backend = m.event.Phase, m.event.CurveID, m.event.Backend
+	e.PeakHeap = atomic.LoadUint64(&m.peakHeap)
+	m.r.Record(e)
+}
@@ -0,0 +1,99 @@
+package metrics_test
+
+import (
+	"expvar"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+type circuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *circuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	return nil
+}
+
+func TestRecorder(t *testing.T) {
+	assert := require.New(t)
+
+	var lock sync.Mutex
+	var events []metrics.Event
+	metrics.Set(metrics.RecorderFunc(func(e metrics.Event) {
+		lock.Lock()
+		events = append(events, e)
+		lock.Unlock()
+	}))
+	defer metrics.Set(nil)
+
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &circuit{})
+	assert.NoError(err)
+	pk, _, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&circuit{X: 3, Y: 9}, ecc.BN254)
+	assert.NoError(err)
+	_, err = groth16.Prove(ccs, pk, w)
+	assert.NoError(err)
+	w, err = frontend.NewWitness(&circuit{X: 3, Y: 10}, ecc.BN254)
+	assert.NoError(err)
+	_, err = groth16.Prove(ccs, pk, w)
+	assert.Error(err)
+
+	// compile, setup, and twice solve and prove
+	assert.Len(events, 6)
+	phases := []metrics.Phase{metrics.PhaseCompile, metrics.PhaseSetup, metrics.PhaseSolve, metrics.PhaseProve, metrics.PhaseSolve, metrics.PhaseProve}
+	for i, e := range events {
+		assert.Equal(phases[i], e.Phase, "event %d", i)
+		assert.Equal(ecc.BN254, e.CurveID)
+		assert.Equal(ccs.GetNbConstraints(), e.NbConstraints)
+		assert.NotZero(e.Duration)
+		assert.NotZero(e.PeakHeap)
+		if e.Phase == metrics.PhaseCompile {
+			assert.Equal(backend.UNKNOWN, e.Backend)
+		} else {
+			assert.Equal(backend.GROTH16, e.Backend)
+		}
+		assert.NotZero(e.NbWires)
+		assert.Equal(e.Phase == metrics.PhaseProve, e.MSMSize != 0, "event %d", i)
+		assert.Equal(i >= 4, e.Err != nil, "event %d", i)
+	}
+
+	// no event without a recorder
+	metrics.Set(nil)
+	_, err = frontend.Compile(ecc.BN254, r1cs.NewBuilder, &circuit{})
+	assert.NoError(err)
+	assert.Len(events, 6)
+}
+
+func TestExpvarRecorder(t *testing.T) {
+	assert := require.New(t)
+
+	r := metrics.NewExpvarRecorder("gnark_test")
+	metrics.Set(r)
+	defer metrics.Set(nil)
+
+	var ccs frontend.CompiledConstraintSystem
+	for i := 0; i < 2; i++ {
+		var err error
+		ccs, err = frontend.Compile(ecc.BN254, r1cs.NewBuilder, &circuit{})
+		assert.NoError(err)
+	}
+
+	vars := expvar.Get("gnark_test").(*expvar.Map)
+	assert.Equal("2", vars.Get("compile.unknown.count").String())
+	assert.Nil(vars.Get("compile.unknown.errors"))
+	assert.Equal(strconv.Itoa(ccs.GetNbConstraints()), vars.Get("compile.unknown.constraints").String())
+	assert.NotNil(vars.Get("compile.unknown.seconds"))
+	assert.NotNil(vars.Get("compile.unknown.peak_heap_bytes"))
+}
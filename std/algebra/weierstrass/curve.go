@@ -0,0 +1,219 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package weierstrass implements the group law of short Weierstrass curves
+// y² = x³ + a*x + b whose base and scalar fields are emulated with
+// std/math/nonnative, so that the operations of curves such as secp256k1,
+// P-256 or BLS12-381 can be performed in a circuit defined over any curve.
+//
+// The points are represented in affine coordinates and the addition formulas
+// are incomplete: the point at infinity is not representable and the
+// operations which would involve it make the solver fail. The scalar
+// multiplications avoid the exceptional cases by adding an offset point of
+// unknown discrete logarithm to the accumulator, which is compensated at the
+// end.
+package weierstrass
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"sync"
+
+	"github.com/consensys/gnark/std/math/nonnative"
+)
+
+// Curve defines a short Weierstrass curve y² = x³ + a*x + b over an emulated
+// field, along with a generator of prime order.
+type Curve struct {
+	Fp     *nonnative.Params // base field
+	Fr     *nonnative.Params // scalar field, of modulus the order of the generator
+	A, B   *big.Int
+	Gx, Gy *big.Int
+
+	// offset is a point of unknown discrete logarithm used to avoid the point
+	// at infinity in the scalar multiplications.
+	offset [2]*big.Int
+}
+
+var (
+	secp256k1, p256, bls12381             *Curve
+	secp256k1Once, p256Once, bls12381Once sync.Once
+)
+
+// Secp256k1 returns the parameters of the secp256k1 curve, with the base and
+// scalar fields emulated with 4 limbs of 64 bits.
+func Secp256k1() *Curve {
+	secp256k1Once.Do(func() {
+		secp256k1 = mustCurve(
+			"fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f",
+			"fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141",
+			"0",
+			"7",
+			"79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+			"483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8",
+		)
+	})
+	return secp256k1
+}
+
+// P256 returns the parameters of the NIST P-256 curve (secp256r1), with the
+// base and scalar fields emulated with 4 limbs of 64 bits.
+func P256() *Curve {
+	p256Once.Do(func() {
+		p256 = mustCurve(
+			"ffffffff00000001000000000000000000000000ffffffffffffffffffffffff",
+			"ffffffff00000000ffffffffffffffffbce6faada7179e84f3b9cac2fc632551",
+			"ffffffff00000001000000000000000000000000fffffffffffffffffffffffc",
+			"5ac635d8aa3a93e7b3ebbd55769886bc651d06b0cc53b0f63bce3c3e27d2604b",
+			"6b17d1f2e12c4247f8bce6e563a440f277037d812deb33a0f4a13945d898c296",
+			"4fe342e2fe1a7f9b8ee7eb4a7c0f9e162bce33576b315ececbb6406837bf51f5",
+		)
+	})
+	return p256
+}
+
+// BLS12381 returns the parameters of the group G1 of BLS12-381, with the base
+// field emulated with 6 limbs of 64 bits and the scalar field with 4 limbs of
+// 64 bits.
+func BLS12381() *Curve {
+	bls12381Once.Do(func() {
+		bls12381 = mustCurve(
+			"1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab",
+			"73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001",
+			"0",
+			"4",
+			"17f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb",
+			"08b3f481e3aaa0f1a09e30ed741d8ae4fcf5e095d5d00af600db18cb2c04b3edd03cc744a2888ae40caa232946c5e7e1",
+		)
+	})
+	return bls12381
+}
+
+// mustCurve returns the curve of the given hexadecimal parameters, with limbs
+// of 64 bits. It panics if the parameters are invalid.
+func mustCurve(p, n, a, b, gx, gy string) *Curve {
+	var v [6]*big.Int
+	for i, s := range []string{p, n, a, b, gx, gy} {
+		var ok bool
+		if v[i], ok = new(big.Int).SetString(s, 16); !ok {
+			panic("invalid curve parameter")
+		}
+	}
+	c, err := NewCurve(v[0], v[1], v[2], v[3], v[4], v[5], 64)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// NewCurve returns the curve y² = x³ + a*x + b over the field of modulus p,
+// with a generator (gx, gy) of prime order n. The field elements are
+// decomposed into limbs of nbBits bits.
+func NewCurve(p, n, a, b, gx, gy *big.Int, nbBits int) (*Curve, error) {
+	fp, err := nonnative.NewParams(nbBits, p)
+	if err != nil {
+		return nil, err
+	}
+	fr, err := nonnative.NewParams(nbBits, n)
+	if err != nil {
+		return nil, err
+	}
+	c := &Curve{
+		Fp: fp,
+		Fr: fr,
+		A:  new(big.Int).Mod(a, p),
+		B:  new(big.Int).Mod(b, p),
+		Gx: new(big.Int).Set(gx),
+		Gy: new(big.Int).Set(gy),
+	}
+
+	// the offset point is derived by try-and-increment from a fixed seed, so
+	// that its discrete logarithm is unknown.
+	seed := sha256.Sum256([]byte("gnark/std/algebra/weierstrass/offset"))
+	x := new(big.Int).SetBytes(seed[:])
+	for {
+		x.Mod(x, p)
+		if y := new(big.Int).ModSqrt(c.rhs(x), p); y != nil {
+			c.offset = [2]*big.Int{x, y}
+			break
+		}
+		x.Add(x, big.NewInt(1))
+	}
+	return c, nil
+}
+
+// rhs returns x³ + a*x + b mod p.
+func (c *Curve) rhs(x *big.Int) *big.Int {
+	p := c.Fp.Modulus()
+	res := new(big.Int).Exp(x, big.NewInt(3), p)
+	res.Add(res, new(big.Int).Mul(c.A, x))
+	res.Add(res, c.B)
+	return res.Mod(res, p)
+}
+
+// NativeAdd returns (x1, y1) + (x2, y2) outside of a circuit. The point at
+// infinity is represented by nil coordinates.
+func (c *Curve) NativeAdd(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	if x1 == nil {
+		return x2, y2
+	}
+	if x2 == nil {
+		return x1, y1
+	}
+	p := c.Fp.Modulus()
+	var λ, num, den big.Int
+	if x1.Cmp(x2) == 0 {
+		if num.Add(y1, y2).Mod(&num, p).Sign() == 0 {
+			return nil, nil
+		}
+		// λ = (3x² + a) / 2y
+		num.Mul(x1, x1).Mul(&num, big.NewInt(3)).Add(&num, c.A)
+		den.Lsh(y1, 1)
+	} else {
+		// λ = (y2 - y1) / (x2 - x1)
+		num.Sub(y2, y1)
+		den.Sub(x2, x1)
+	}
+	den.Mod(&den, p).ModInverse(&den, p)
+	λ.Mul(&num, &den)
+	λ.Mod(&λ, p)
+	x3 := new(big.Int).Mul(&λ, &λ)
+	x3.Sub(x3, x1).Sub(x3, x2).Mod(x3, p)
+	y3 := new(big.Int).Sub(x1, x3)
+	y3.Mul(y3, &λ).Sub(y3, y1).Mod(y3, p)
+	return x3, y3
+}
+
+// NativeScalarMul returns [k](x, y) outside of a circuit.
+func (c *Curve) NativeScalarMul(x, y, k *big.Int) (*big.Int, *big.Int) {
+	var rx, ry *big.Int
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		rx, ry = c.NativeAdd(rx, ry, rx, ry)
+		if k.Bit(i) == 1 {
+			rx, ry = c.NativeAdd(rx, ry, x, y)
+		}
+	}
+	return rx, ry
+}
+
+// nativeNeg returns -(x, y) outside of a circuit.
+func (c *Curve) nativeNeg(x, y *big.Int) (*big.Int, *big.Int) {
+	if x == nil {
+		return nil, nil
+	}
+	ny := new(big.Int).Neg(y)
+	return x, ny.Mod(ny, c.Fp.Modulus())
+}
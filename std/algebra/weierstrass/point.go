@@ -0,0 +1,235 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package weierstrass
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/nonnative"
+)
+
+// AffinePoint is an affine point of the curve, in a circuit. The point at
+// infinity is not representable.
+type AffinePoint struct {
+	X, Y nonnative.Element
+}
+
+// Placeholder returns a point whose coordinates are allocated but unset, to
+// be used in the circuit definition.
+func (c *Curve) Placeholder() AffinePoint {
+	return AffinePoint{X: c.Fp.Placeholder(), Y: c.Fp.Placeholder()}
+}
+
+// Constant returns the point (x, y). It can be used both as a constant in the
+// circuit and as an assignment of the witness.
+func (c *Curve) Constant(x, y *big.Int) AffinePoint {
+	return AffinePoint{X: c.Fp.ConstantFromBig(x), Y: c.Fp.ConstantFromBig(y)}
+}
+
+// Generator returns the generator of the curve, as a constant of the circuit.
+func (c *Curve) Generator() AffinePoint {
+	return c.Constant(c.Gx, c.Gy)
+}
+
+// AssertIsOnCurve asserts that y² = x³ + a*x + b.
+func (c *Curve) AssertIsOnCurve(api frontend.API, p AffinePoint) {
+	var lhs, rhs, t nonnative.Element
+	lhs.Mul(api, p.Y, p.Y)
+	rhs.Mul(api, p.X, p.X)
+	rhs.Mul(api, rhs, p.X)
+	if c.A.Sign() != 0 {
+		rhs.Add(api, rhs, *t.Mul(api, c.Fp.ConstantFromBig(c.A), p.X))
+	}
+	rhs.Add(api, rhs, c.Fp.ConstantFromBig(c.B))
+	lhs.AssertIsEqual(api, lhs, rhs)
+}
+
+// Neg returns -p.
+func (c *Curve) Neg(api frontend.API, p AffinePoint) AffinePoint {
+	var r AffinePoint
+	r.X.Set(api, p.X)
+	r.Y.Neg(api, p.Y)
+	return r
+}
+
+// Add returns p + q. p and q must be different and not opposite.
+func (c *Curve) Add(api frontend.API, p, q AffinePoint) AffinePoint {
+	var λ, num, den nonnative.Element
+	var r AffinePoint
+
+	// λ = (q.y - p.y) / (q.x - p.x)
+	num.Sub(api, q.Y, p.Y)
+	den.Sub(api, q.X, p.X)
+	λ.Div(api, num, den)
+
+	// x = λ² - p.x - q.x
+	r.X.Mul(api, λ, λ)
+	r.X.Sub(api, r.X, p.X)
+	r.X.Sub(api, r.X, q.X)
+
+	// y = λ(p.x - x) - p.y
+	r.Y.Sub(api, p.X, r.X)
+	r.Y.Mul(api, λ, r.Y)
+	r.Y.Sub(api, r.Y, p.Y)
+
+	return r
+}
+
+// Double returns 2p. p must not be of order 2.
+func (c *Curve) Double(api frontend.API, p AffinePoint) AffinePoint {
+	var λ, num, den nonnative.Element
+	var r AffinePoint
+
+	// λ = (3x² + a) / 2y
+	num.Mul(api, p.X, p.X)
+	num.Add(api, num, *den.Add(api, num, num))
+	if c.A.Sign() != 0 {
+		num.Add(api, num, c.Fp.ConstantFromBig(c.A))
+	}
+	den.Add(api, p.Y, p.Y)
+	λ.Div(api, num, den)
+
+	// x = λ² - 2p.x
+	r.X.Mul(api, λ, λ)
+	r.X.Sub(api, r.X, p.X)
+	r.X.Sub(api, r.X, p.X)
+
+	// y = λ(p.x - x) - p.y
+	r.Y.Sub(api, p.X, r.X)
+	r.Y.Mul(api, λ, r.Y)
+	r.Y.Sub(api, r.Y, p.Y)
+
+	return r
+}
+
+// Select returns p if sel is 1 and q if sel is 0. sel must be boolean.
+func (c *Curve) Select(api frontend.API, sel frontend.Variable, p, q AffinePoint) AffinePoint {
+	var r AffinePoint
+	r.X.Select(api, sel, p.X, q.X)
+	r.Y.Select(api, sel, p.Y, q.Y)
+	return r
+}
+
+// lookup2 returns p0, p1, p2 or p3 depending on the bits b0 and b1, as
+// frontend.API.Lookup2.
+func (c *Curve) lookup2(api frontend.API, b0, b1 frontend.Variable, p0, p1, p2, p3 AffinePoint) AffinePoint {
+	var r AffinePoint
+	r.X.Lookup2(api, b0, b1, p0.X, p1.X, p2.X, p3.X)
+	r.Y.Lookup2(api, b0, b1, p0.Y, p1.Y, p2.Y, p3.Y)
+	return r
+}
+
+// ScalarMul returns [k]p, where k is an element of the scalar field. The
+// result must not be the point at infinity.
+//
+// The scalar is processed by windows of 2 bits, from a table of the points
+// [j]p + H for j in 0..3, where H is the offset point (see scalarMulWindowed).
+func (c *Curve) ScalarMul(api frontend.API, p AffinePoint, k nonnative.Element) AffinePoint {
+	var t [4]AffinePoint
+	t[0] = c.Constant(c.offset[0], c.offset[1])
+	t[1] = c.Add(api, p, t[0])
+	t[2] = c.Add(api, t[1], p)
+	t[3] = c.Add(api, t[2], p)
+	return c.scalarMulWindowed(api, t, k.ToBits(api))
+}
+
+// ScalarMulBase returns [k]G, where G is the generator of the curve and k is
+// an element of the scalar field. The result must not be the point at
+// infinity.
+//
+// It is cheaper than ScalarMul, as the table of the windows is constant.
+func (c *Curve) ScalarMulBase(api frontend.API, k nonnative.Element) AffinePoint {
+	var t [4]AffinePoint
+	x, y := c.offset[0], c.offset[1]
+	for j := range t {
+		t[j] = c.Constant(x, y)
+		x, y = c.NativeAdd(x, y, c.Gx, c.Gy)
+	}
+	return c.scalarMulWindowed(api, t, k.ToBits(api))
+}
+
+// scalarMulWindowed returns Σ [k[i]*2^i]p, where k is given by its bits, least
+// significant first, and table[j] = [j]p + H for j in 0..3.
+//
+// To avoid handling the point at infinity, the accumulator is initialized with
+// the offset point H, of unknown discrete logarithm:
+//
+//	acc = 4*acc + table[k[2i] + 2*k[2i+1]]
+//
+// For m windows, the result is [k]p + [4^m + (4^m - 1)/3]H, which is corrected
+// at the end. The exceptional cases of the incomplete addition formulas then
+// only happen with negligible probability.
+func (c *Curve) scalarMulWindowed(api frontend.API, table [4]AffinePoint, k []frontend.Variable) AffinePoint {
+	if len(k)%2 == 1 {
+		k = append(k, 0)
+	}
+	m := len(k) / 2
+
+	acc := c.Constant(c.offset[0], c.offset[1])
+	for i := m - 1; i >= 0; i-- {
+		acc = c.Double(api, acc)
+		acc = c.Double(api, acc)
+		acc = c.Add(api, acc, c.lookup2(api, k[2*i], k[2*i+1], table[0], table[1], table[2], table[3]))
+	}
+
+	// 4^m + (4^m - 1)/3
+	e := new(big.Int).Lsh(big.NewInt(1), uint(2*m))
+	e.Add(e, new(big.Int).Div(new(big.Int).Sub(e, big.NewInt(1)), big.NewInt(3)))
+	return c.Add(api, acc, c.correction(e))
+}
+
+// JointScalarMulBase returns [u1]G + [u2]q, where G is the generator of the
+// curve and u1 and u2 are elements of the scalar field. The result must not be
+// the point at infinity.
+//
+// The accumulator is initialized with the offset point H and the scalars are
+// processed bit by bit:
+//
+//	acc = 2*acc + [u1[i]]G + [u2[i]]q + H
+//
+// For n bits, the result is [u1]G + [u2]q + [2^(n+1) - 1]H, which is
+// corrected at the end.
+func (c *Curve) JointScalarMulBase(api frontend.API, q AffinePoint, u1, u2 nonnative.Element) AffinePoint {
+	b1, b2 := u1.ToBits(api), u2.ToBits(api)
+	h := c.Constant(c.offset[0], c.offset[1])
+	gx, gy := c.NativeAdd(c.Gx, c.Gy, c.offset[0], c.offset[1])
+
+	// table[b0 + 2*b1] = [b0]G + [b1]q + H
+	t0 := h
+	t1 := c.Constant(gx, gy)
+	t2 := c.Add(api, q, h)
+	t3 := c.Add(api, t2, c.Generator())
+
+	acc := h
+	for i := len(b1) - 1; i >= 0; i-- {
+		acc = c.Double(api, acc)
+		acc = c.Add(api, acc, c.lookup2(api, b1[i], b2[i], t0, t1, t2, t3))
+	}
+
+	e := new(big.Int).Lsh(big.NewInt(1), uint(len(b1)+1))
+	e.Sub(e, big.NewInt(1))
+	return c.Add(api, acc, c.correction(e))
+}
+
+// correction returns -[e]H as a constant of the circuit, where H is the
+// offset point.
+func (c *Curve) correction(e *big.Int) AffinePoint {
+	x, y := c.NativeScalarMul(c.offset[0], c.offset[1], e)
+	x, y = c.nativeNeg(x, y)
+	return c.Constant(x, y)
+}
@@ -0,0 +1,103 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package weierstrass
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/nonnative"
+	"github.com/consensys/gnark/test"
+)
+
+func TestCurves(t *testing.T) {
+	assert := test.NewAssert(t)
+	for _, c := range []*Curve{Secp256k1(), P256(), BLS12381()} {
+		// the generator is on the curve and of order n
+		assert.Equal(0, c.rhs(c.Gx).Cmp(new(big.Int).Exp(c.Gy, big.NewInt(2), c.Fp.Modulus())))
+		x, _ := c.NativeScalarMul(c.Gx, c.Gy, c.Fr.Modulus())
+		assert.Nil(x)
+
+		// the offset point is on the curve
+		assert.Equal(0, c.rhs(c.offset[0]).Cmp(new(big.Int).Exp(c.offset[1], big.NewInt(2), c.Fp.Modulus())))
+	}
+}
+
+type pointCircuit struct {
+	P, Q          AffinePoint
+	K             nonnative.Element
+	Sum, Double   AffinePoint
+	KP, KG, Joint AffinePoint
+
+	curve *Curve
+}
+
+func (circuit *pointCircuit) Define(api frontend.API) error {
+	c := circuit.curve
+	assertEqual := func(p, q AffinePoint) {
+		p.X.AssertIsEqual(api, p.X, q.X)
+		p.Y.AssertIsEqual(api, p.Y, q.Y)
+	}
+	c.AssertIsOnCurve(api, circuit.P)
+	assertEqual(c.Add(api, circuit.P, circuit.Q), circuit.Sum)
+	assertEqual(c.Double(api, circuit.P), circuit.Double)
+	assertEqual(c.ScalarMul(api, circuit.P, circuit.K), circuit.KP)
+	assertEqual(c.ScalarMulBase(api, circuit.K), circuit.KG)
+	assertEqual(c.JointScalarMulBase(api, circuit.P, circuit.K, circuit.K), circuit.Joint)
+	return nil
+}
+
+func TestPoint(t *testing.T) {
+	assert := test.NewAssert(t)
+	c := Secp256k1()
+
+	random := func() *big.Int {
+		k, err := rand.Int(rand.Reader, c.Fr.Modulus())
+		assert.NoError(err)
+		return k
+	}
+	px, py := c.NativeScalarMul(c.Gx, c.Gy, random())
+	qx, qy := c.NativeScalarMul(c.Gx, c.Gy, random())
+	k := random()
+
+	sx, sy := c.NativeAdd(px, py, qx, qy)
+	dx, dy := c.NativeAdd(px, py, px, py)
+	kpx, kpy := c.NativeScalarMul(px, py, k)
+	kgx, kgy := c.NativeScalarMul(c.Gx, c.Gy, k)
+	jx, jy := c.NativeAdd(kpx, kpy, kgx, kgy)
+
+	circuit := pointCircuit{
+		P: c.Placeholder(), Q: c.Placeholder(), K: c.Fr.Placeholder(),
+		Sum: c.Placeholder(), Double: c.Placeholder(),
+		KP: c.Placeholder(), KG: c.Placeholder(), Joint: c.Placeholder(),
+		curve: c,
+	}
+	witness := pointCircuit{
+		P: c.Constant(px, py), Q: c.Constant(qx, qy), K: c.Fr.ConstantFromBig(k),
+		Sum: c.Constant(sx, sy), Double: c.Constant(dx, dy),
+		KP: c.Constant(kpx, kpy), KG: c.Constant(kgx, kgy), Joint: c.Constant(jx, jy),
+	}
+	assert.NoError(test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16))
+
+	// wrong scalar
+	witness.K = c.Fr.ConstantFromBig(new(big.Int).Add(k, big.NewInt(1)))
+	assert.Error(test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16))
+}
@@ -0,0 +1,102 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kzg_bls12377 provides a ZKP-circuit function to verify BLS12_377 KZG
+// polynomial commitment openings inside a BW6_761 circuit.
+package kzg_bls12377
+
+import (
+	"math/big"
+
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr/kzg"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/fields_bls12377"
+	"github.com/consensys/gnark/std/algebra/sw_bls12377"
+)
+
+// Digest is a KZG commitment [f(α)]G₁
+type Digest = sw_bls12377.G1Affine
+
+// OpeningProof is a KZG proof that f(z) = ClaimedValue
+type OpeningProof struct {
+	// H quotient polynomial (f - f(z))/(x-z), committed
+	H sw_bls12377.G1Affine
+
+	// ClaimedValue f(z), a BLS12_377 scalar
+	ClaimedValue frontend.Variable
+}
+
+// VerifyingKey is the part of the KZG SRS used to verify openings: [G₂, [α]G₂]
+type VerifyingKey struct {
+	G2 [2]sw_bls12377.G2Affine
+}
+
+// Verify checks that proof is a valid opening of commitment at point, that is,
+// that the committed polynomial f satisfies f(point) = proof.ClaimedValue.
+//
+// Compared to kzg.Verify, the scalar multiplication by point is moved from G₂
+// to G₁, where it is cheaper:
+//
+//	e([f(α)]G₁ - [f(z)]G₁ + [z]H, G₂) ⋅ e(-H, [α]G₂) == 1
+//
+// The points are added with the incomplete affine formulas of sw_bls12377: the
+// point and the claimed value must not be 0, which holds with overwhelming
+// probability when point is a random challenge.
+//
+// As for groth16_bls12377.Verify, the verifying key is either part of the
+// witness, or embedded in the circuit as constants.
+func Verify(api frontend.API, commitment Digest, proof OpeningProof, point frontend.Variable, vk VerifyingKey) {
+	_, _, g1, _ := bls12377.Generators()
+	var g1Gen sw_bls12377.G1Affine
+	g1Gen.Assign(&g1)
+
+	// [f(α) - f(z) + z⋅H(α)]G₁
+	var claimedValueG1, pointH, total sw_bls12377.G1Affine
+	claimedValueG1.ScalarMul(api, g1Gen, proof.ClaimedValue)
+	pointH.ScalarMul(api, proof.H, point)
+	total.Neg(api, claimedValueG1)
+	total.AddAssign(api, commitment)
+	total.AddAssign(api, pointH)
+
+	// [-H(α)]G₁
+	var negH sw_bls12377.G1Affine
+	negH.Neg(api, proof.H)
+
+	ml, err := sw_bls12377.MillerLoop(api, []sw_bls12377.G1Affine{total, negH}, vk.G2[:])
+	if err != nil {
+		panic(err)
+	}
+	pairing := sw_bls12377.FinalExponentiation(api, ml)
+
+	var one fields_bls12377.E12
+	one.SetOne()
+	pairing.AssertIsEqual(api, one)
+}
+
+// Assign values to the "in-circuit" VerifyingKey from a "out-of-circuit" SRS
+func (vk *VerifyingKey) Assign(srs *kzg.SRS) {
+	vk.G2[0].Assign(&srs.G2[0])
+	vk.G2[1].Assign(&srs.G2[1])
+}
+
+// Assign values to the "in-circuit" OpeningProof from a "out-of-circuit" OpeningProof
+func (proof *OpeningProof) Assign(oproof *kzg.OpeningProof) {
+	proof.H.Assign(&oproof.H)
+	var claimedValue big.Int
+	oproof.ClaimedValue.ToBigIntRegular(&claimedValue)
+	proof.ClaimedValue = claimedValue
+}
@@ -0,0 +1,122 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kzg_bls12377
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr/kzg"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+type verifierCircuit struct {
+	Commitment Digest
+	Proof      OpeningProof
+	Point      frontend.Variable
+	Vk         VerifyingKey
+}
+
+func (circuit *verifierCircuit) Define(api frontend.API) error {
+	Verify(api, circuit.Commitment, circuit.Proof, circuit.Point, circuit.Vk)
+	return nil
+}
+
+// generateOpening returns the commitment to a random polynomial, and its
+// opening at a random point
+func generateOpening(t *testing.T) (*kzg.SRS, kzg.Digest, kzg.OpeningProof, fr.Element) {
+	const size = 16
+	srs, err := kzg.NewSRS(size, big.NewInt(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := make([]fr.Element, size)
+	for i := range p {
+		p[i].SetRandom()
+	}
+	var point fr.Element
+	point.SetRandom()
+
+	digest, err := kzg.Commit(p, srs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := kzg.Open(p, point, srs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// before returning verifies that the opening passes on bls12377
+	if err := kzg.Verify(&digest, &proof, point, srs); err != nil {
+		t.Fatal(err)
+	}
+	return srs, digest, proof, point
+}
+
+func TestVerifier(t *testing.T) {
+	srs, digest, proof, point := generateOpening(t)
+
+	var witness verifierCircuit
+	witness.Commitment.Assign(&digest)
+	witness.Proof.Assign(&proof)
+	witness.Point = point.String()
+	witness.Vk.Assign(srs)
+
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(&verifierCircuit{}, &witness, test.WithCurves(ecc.BW6_761))
+
+	// wrong claimed value
+	var wrong fr.Element
+	wrong.SetRandom()
+	witness.Proof.ClaimedValue = wrong.String()
+	assert.SolvingFailed(&verifierCircuit{}, &witness, test.WithCurves(ecc.BW6_761))
+}
+
+type constantVkCircuit struct {
+	Commitment Digest
+	Proof      OpeningProof
+	Point      frontend.Variable
+	Vk         VerifyingKey `gnark:"-"`
+}
+
+func (circuit *constantVkCircuit) Define(api frontend.API) error {
+	Verify(api, circuit.Commitment, circuit.Proof, circuit.Point, circuit.Vk)
+	return nil
+}
+
+func TestVerifierConstantVk(t *testing.T) {
+	srs, digest, proof, point := generateOpening(t)
+
+	// the verifying key is embedded in the circuit as constants
+	var circuit, witness constantVkCircuit
+	circuit.Vk.Assign(srs)
+	witness.Vk = circuit.Vk
+	witness.Commitment.Assign(&digest)
+	witness.Proof.Assign(&proof)
+	witness.Point = point.String()
+
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BW6_761))
+
+	// wrong point
+	var wrong fr.Element
+	wrong.SetRandom()
+	witness.Point = wrong.String()
+	assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BW6_761))
+}
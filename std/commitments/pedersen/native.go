@@ -0,0 +1,175 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pedersen
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/twistededwards"
+	tedwards "github.com/consensys/gnark/std/algebra/twistededwards"
+)
+
+// domain separates the hashes deriving the generators from other uses of sha256
+const domain = "gnark pedersen commitment generator"
+
+// Point is a point of a twisted Edwards curve, out of circuit
+type Point struct {
+	X, Y big.Int
+}
+
+// Params are the generators of a Pedersen commitment to a vector of values: G[i]
+// is the generator of the i-th value, and H the generator of the randomness.
+type Params struct {
+	Curve twistededwards.ID
+	G     []Point
+	H     Point
+
+	curve   *tedwards.CurveParams
+	modulus *big.Int
+}
+
+// NewParams returns the parameters of commitments to vectors of n values on
+// the twisted Edwards curve id, whose base field is the scalar field of the
+// SNARK curve.
+//
+// The generators are derived deterministically by hashing their index to the
+// curve, so that no discrete logarithm relation between them is known.
+func NewParams(id twistededwards.ID, n int) (*Params, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("invalid number of values %d", n)
+	}
+	curve, err := tedwards.GetCurveParams(id)
+	if err != nil {
+		return nil, err
+	}
+	snarkCurve, err := tedwards.GetSnarkCurve(id)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Params{
+		Curve:   id,
+		G:       make([]Point, n),
+		curve:   curve,
+		modulus: snarkCurve.Info().Fr.Modulus(),
+	}
+	p.H = p.hashToCurve(0)
+	for i := range p.G {
+		p.G[i] = p.hashToCurve(uint64(i + 1))
+	}
+	return p, nil
+}
+
+// Commit returns the commitment Σ [values[i]]G[i] + [randomness]H to the values.
+//
+// The scalars are reduced modulo the order of the generators: the commitment
+// binds the values only if they are lower than this order.
+func (p *Params) Commit(values []*big.Int, randomness *big.Int) (Point, error) {
+	if len(values) != len(p.G) {
+		return Point{}, errors.New("number of values doesn't match the number of generators")
+	}
+	res := p.scalarMul(&p.H, randomness)
+	for i := range values {
+		tmp := p.scalarMul(&p.G[i], values[i])
+		res = p.add(&res, &tmp)
+	}
+	return res, nil
+}
+
+// hashToCurve returns the point of the prime order subgroup derived from index,
+// with try-and-increment: y = sha256(domain, curve, index, counter) mod p is
+// tried until (x, y) is on the curve for some x, and the point is multiplied by
+// the cofactor.
+func (p *Params) hashToCurve(index uint64) Point {
+	one := big.NewInt(1)
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], index)
+	for counter := uint64(0); ; counter++ {
+		binary.BigEndian.PutUint64(buf[8:], counter)
+		h := sha256.New()
+		h.Write([]byte(domain))
+		h.Write([]byte{byte(p.Curve)})
+		h.Write(buf[:])
+
+		var pt Point
+		pt.Y.SetBytes(h.Sum(nil))
+		pt.Y.Mod(&pt.Y, p.modulus)
+
+		// x² = (1 - y²) / (a - d⋅y²)
+		var y2, num, den big.Int
+		y2.Mul(&pt.Y, &pt.Y)
+		num.Sub(one, &y2)
+		den.Mul(p.curve.D, &y2).Sub(p.curve.A, &den).Mod(&den, p.modulus)
+		if den.Sign() == 0 {
+			continue
+		}
+		den.ModInverse(&den, p.modulus)
+		num.Mul(&num, &den).Mod(&num, p.modulus)
+		if pt.X.ModSqrt(&num, p.modulus) == nil {
+			continue
+		}
+
+		res := p.scalarMul(&pt, p.curve.Cofactor)
+		if res.X.Sign() == 0 {
+			// the identity, or a point of order 2
+			continue
+		}
+		return res
+	}
+}
+
+// add returns p1 + p2, with the complete addition law
+//
+//	x3 = (x1⋅y2 + y1⋅x2) / (1 + d⋅x1⋅x2⋅y1⋅y2)
+//	y3 = (y1⋅y2 - a⋅x1⋅x2) / (1 - d⋅x1⋅x2⋅y1⋅y2)
+func (p *Params) add(p1, p2 *Point) Point {
+	m := p.modulus
+	var x1x2, y1y2, x1y2, y1x2, dxy, num, den big.Int
+	x1x2.Mul(&p1.X, &p2.X)
+	y1y2.Mul(&p1.Y, &p2.Y)
+	x1y2.Mul(&p1.X, &p2.Y)
+	y1x2.Mul(&p1.Y, &p2.X)
+	dxy.Mul(&x1x2, &y1y2).Mul(&dxy, p.curve.D).Mod(&dxy, m)
+
+	var res Point
+	num.Add(&x1y2, &y1x2)
+	den.Add(big.NewInt(1), &dxy).ModInverse(&den, m)
+	res.X.Mul(&num, &den).Mod(&res.X, m)
+
+	num.Mul(p.curve.A, &x1x2).Sub(&y1y2, &num)
+	den.Sub(big.NewInt(1), &dxy).Mod(&den, m).ModInverse(&den, m)
+	res.Y.Mul(&num, &den).Mod(&res.Y, m)
+
+	return res
+}
+
+// scalarMul returns [s]p1, with the double-and-add algorithm
+func (p *Params) scalarMul(p1 *Point, s *big.Int) Point {
+	var res Point
+	res.Y.SetUint64(1)
+	for i := s.BitLen() - 1; i >= 0; i-- {
+		res = p.add(&res, &res)
+		if s.Bit(i) == 1 {
+			res = p.add(&res, p1)
+		}
+	}
+	return res
+}
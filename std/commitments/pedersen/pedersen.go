@@ -0,0 +1,73 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pedersen provides Pedersen vector commitments on the twisted Edwards
+// curves of std/algebra/twistededwards, computed out of circuit by
+// Params.Commit, and opened in a circuit by Verify.
+//
+// A commitment to the values m[0..n) with the randomness r is the point
+// Σ [m[i]]G[i] + [r]H, where the generators G[i] and H are derived
+// deterministically by NewParams. It is hiding when r is random, and binding as
+// long as the discrete logarithm problem is hard on the curve. This lets a
+// proof reveal nothing about data committed to outside of the circuit, and
+// still prove statements about it (commit-and-prove).
+package pedersen
+
+import (
+	"errors"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+)
+
+// Commit returns the commitment Σ [values[i]]G[i] + [randomness]H to the values,
+// with (len(values)+1)/2 double-base scalar multiplications.
+func Commit(curve twistededwards.Curve, params *Params, values []frontend.Variable, randomness frontend.Variable) (twistededwards.Point, error) {
+	if len(values) != len(params.G) {
+		return twistededwards.Point{}, errors.New("number of values doesn't match the number of generators")
+	}
+
+	points := make([]twistededwards.Point, 0, len(values)+1)
+	scalars := make([]frontend.Variable, 0, len(values)+1)
+	for i := range values {
+		points = append(points, twistededwards.Point{X: &params.G[i].X, Y: &params.G[i].Y})
+		scalars = append(scalars, values[i])
+	}
+	points = append(points, twistededwards.Point{X: &params.H.X, Y: &params.H.Y})
+	scalars = append(scalars, randomness)
+
+	res := twistededwards.Point{X: 0, Y: 1}
+	for i := 0; i+1 < len(points); i += 2 {
+		res = curve.Add(res, curve.DoubleBaseScalarMul(points[i], points[i+1], scalars[i], scalars[i+1]))
+	}
+	if len(points)%2 == 1 {
+		res = curve.Add(res, curve.ScalarMul(points[len(points)-1], scalars[len(scalars)-1]))
+	}
+	return res, nil
+}
+
+// Verify checks that commitment is the commitment to the values with the
+// randomness, computed with the same parameters by Params.Commit.
+func Verify(curve twistededwards.Curve, params *Params, commitment twistededwards.Point, values []frontend.Variable, randomness frontend.Variable) error {
+	res, err := Commit(curve, params, values, randomness)
+	if err != nil {
+		return err
+	}
+	api := curve.API()
+	api.AssertIsEqual(res.X, commitment.X)
+	api.AssertIsEqual(res.Y, commitment.Y)
+	return nil
+}
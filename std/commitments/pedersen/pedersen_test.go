@@ -0,0 +1,110 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pedersen
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/frontend"
+	tedwards "github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/test"
+)
+
+type commitmentCircuit struct {
+	curveID    twistededwards.ID
+	Values     [3]frontend.Variable
+	Randomness frontend.Variable
+	Commitment tedwards.Point `gnark:",public"`
+}
+
+func (circuit *commitmentCircuit) Define(api frontend.API) error {
+	curve, err := tedwards.NewEdCurve(api, circuit.curveID)
+	if err != nil {
+		return err
+	}
+	params, err := NewParams(circuit.curveID, len(circuit.Values))
+	if err != nil {
+		return err
+	}
+	return Verify(curve, params, circuit.Commitment, circuit.Values[:], circuit.Randomness)
+}
+
+func TestCommitment(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, id := range []twistededwards.ID{twistededwards.BN254, twistededwards.BLS12_381} {
+		snarkCurve, err := tedwards.GetSnarkCurve(id)
+		assert.NoError(err)
+
+		params, err := NewParams(id, 3)
+		assert.NoError(err)
+		values := []*big.Int{big.NewInt(42), big.NewInt(0), new(big.Int).Sub(snarkCurve.Info().Fr.Modulus(), big.NewInt(1))}
+		randomness := big.NewInt(123456789)
+		c, err := params.Commit(values, randomness)
+		assert.NoError(err)
+
+		var witness commitmentCircuit
+		for i := range values {
+			witness.Values[i] = values[i]
+		}
+		witness.Randomness = randomness
+		witness.Commitment = tedwards.Point{X: &c.X, Y: &c.Y}
+
+		circuit := commitmentCircuit{curveID: id}
+		assert.ProverSucceeded(&circuit, &witness, test.WithCurves(snarkCurve))
+
+		witness.Randomness = big.NewInt(123456788)
+		assert.SolvingFailed(&circuit, &witness, test.WithCurves(snarkCurve))
+	}
+}
+
+func TestCommitmentNative(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	params, err := NewParams(twistededwards.BN254, 2)
+	assert.NoError(err)
+
+	// the generators are distinct points of the prime order subgroup
+	order := params.curve.Order
+	for _, g := range []Point{params.G[0], params.G[1], params.H} {
+		res := params.scalarMul(&g, order)
+		assert.Equal(0, res.X.Sign())
+		assert.Equal(int64(1), res.Y.Int64())
+	}
+	assert.NotEqual(params.G[0], params.G[1])
+	assert.NotEqual(params.G[0], params.H)
+
+	// the generators are deterministic
+	other, err := NewParams(twistededwards.BN254, 3)
+	assert.NoError(err)
+	assert.Equal(params.G, other.G[:2])
+	assert.Equal(params.H, other.H)
+
+	// the commitment is additively homomorphic
+	c1, err := params.Commit([]*big.Int{big.NewInt(1), big.NewInt(2)}, big.NewInt(3))
+	assert.NoError(err)
+	c2, err := params.Commit([]*big.Int{big.NewInt(10), big.NewInt(20)}, big.NewInt(30))
+	assert.NoError(err)
+	c3, err := params.Commit([]*big.Int{big.NewInt(11), big.NewInt(22)}, big.NewInt(33))
+	assert.NoError(err)
+	assert.Equal(c3, params.add(&c1, &c2))
+
+	_, err = params.Commit([]*big.Int{big.NewInt(1)}, big.NewInt(3))
+	assert.Error(err)
+}
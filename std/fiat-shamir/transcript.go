@@ -14,26 +14,45 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
+// Package fiatshamir provides a ZKP-circuit Fiat-Shamir transcript, which
+// derives the same challenges as the fiat-shamir package of gnark-crypto.
+//
+// The native transcript hashes a stream of bytes: the challenge name, the
+// previous challenge and the bound values. The circuit transcript hashes the
+// same bytes, a bound variable standing for the Fr.Bytes bytes of its big
+// endian encoding:
+//   - with a field hash (NewTranscript, for example MiMC), the stream is split
+//     in blocks of Fr.Bytes bytes, the last one being padded with zeroes on the
+//     left, as the MiMC hash of gnark-crypto does. The challenges are the
+//     digests of the hash;
+//   - with a binary hash (NewBinaryTranscript, for example SHA-256), the stream
+//     is written byte by byte. The challenges are the digests of the hash, read
+//     as big endian integers modulo the field modulus.
+//
+// A variable is decomposed in bytes only when the stream requires it, that is
+// when it is not aligned on a block of a field hash, or with a binary hash.
 package fiatshamir
 
 import (
 	"errors"
+	"math/big"
 
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/std/hash"
 )
 
-// errChallengeNotFound is returned when a wrong challenge name is provided.
 var (
 	errChallengeNotFound            = errors.New("challenge not recorded in the Transcript")
 	errChallengeAlreadyComputed     = errors.New("challenge already computed, cannot be binded to other values")
 	errPreviousChallengeNotComputed = errors.New("the previous challenge is needed and has not been computed")
+	errEmptyChallenge               = errors.New("the challenge has no name and no binded values")
 )
 
 // Transcript handles the creation of challenges for Fiat Shamir.
 type Transcript struct {
-	// hash function that is used.
-	h hash.Hash
+	// hash function that is used, h for a field hash and bh for a binary hash.
+	h  hash.Hash
+	bh hash.BinaryHasher
 
 	challenges map[string]challenge
 	previous   *challenge
@@ -43,21 +62,41 @@ type Transcript struct {
 }
 
 type challenge struct {
-	position   int                 // position of the challenge in the transcript. order matters.
-	bindings   []frontend.Variable // bindings stores the variables a challenge is binded to.
-	value      frontend.Variable   // value stores the computed challenge
+	position   int               // position of the challenge in the transcript. order matters.
+	bindings   []segment         // bindings stores the variables a challenge is binded to.
+	value      frontend.Variable // value stores the computed challenge
+	digest     []segment         // digest stores the bytes of the hash, written by the next challenge
 	isComputed bool
 }
 
-// NewTranscript returns a new transcript.
-// h is the hash function that is used to compute the challenges.
-// challenges are the name of the challenges. The order is important.
+// segment is a part of the hashed stream: a byte, or a field element standing
+// for the Fr.Bytes bytes of its big endian encoding
+type segment struct {
+	v      frontend.Variable
+	isByte bool
+}
+
+// NewTranscript returns a new transcript, deriving the challenges with the field
+// hash h (for example MiMC). The order of the challenges IDs matters.
 func NewTranscript(api frontend.API, h hash.Hash, challengesID ...string) Transcript {
+	t := newTranscript(api, challengesID)
+	t.h = h
+	return t
+}
+
+// NewBinaryTranscript returns a new transcript, deriving the challenges with the
+// binary hash h (for example SHA-256). The order of the challenges IDs matters.
+func NewBinaryTranscript(api frontend.API, h hash.BinaryHasher, challengesID ...string) Transcript {
+	t := newTranscript(api, challengesID)
+	t.bh = h
+	return t
+}
+
+func newTranscript(api frontend.API, challengesID []string) Transcript {
 	n := len(challengesID)
 	t := Transcript{
 		challenges: make(map[string]challenge, n),
 		api:        api,
-		h:          h,
 	}
 
 	for i := 0; i < n; i++ {
@@ -67,11 +106,29 @@ func NewTranscript(api frontend.API, h hash.Hash, challengesID ...string) Transc
 	return t
 }
 
-// Bind binds the challenge to value. A challenge can be binded to an
-// arbitrary number of values, but the order in which the binded values
-// are added is important. Once a challenge is computed, it cannot be
-// binded to other values.
+// Bind binds the challenge to values, each of them standing for the Fr.Bytes
+// bytes of its big endian encoding. A challenge can be binded to an arbitrary
+// number of values, but the order in which the binded values are added is
+// important. Once a challenge is computed, it cannot be binded to other values.
 func (t *Transcript) Bind(challengeID string, values []frontend.Variable) error {
+	bindings := make([]segment, len(values))
+	for i := range values {
+		bindings[i] = segment{v: values[i]}
+	}
+	return t.bind(challengeID, bindings)
+}
+
+// BindBytes binds the challenge to bytes, as Bind. The bytes are not range
+// checked: they must be constrained by the caller.
+func (t *Transcript) BindBytes(challengeID string, bytes []frontend.Variable) error {
+	bindings := make([]segment, len(bytes))
+	for i := range bytes {
+		bindings[i] = segment{v: bytes[i], isByte: true}
+	}
+	return t.bind(challengeID, bindings)
+}
+
+func (t *Transcript) bind(challengeID string, bindings []segment) error {
 
 	challenge, ok := t.challenges[challengeID]
 
@@ -82,7 +139,7 @@ func (t *Transcript) Bind(challengeID string, values []frontend.Variable) error
 		return errChallengeAlreadyComputed
 	}
 
-	challenge.bindings = append(challenge.bindings, values...)
+	challenge.bindings = append(challenge.bindings, bindings...)
 	t.challenges[challengeID] = challenge
 
 	return nil
@@ -90,9 +147,9 @@ func (t *Transcript) Bind(challengeID string, values []frontend.Variable) error
 }
 
 // ComputeChallenge computes the challenge corresponding to the given name.
-// The resulting variable is:
-// * H(name ∥ previous_challenge ∥ binded_values...) if the challenge is not the first one
-// * H(name ∥ binded_values... ) if it's is the first challenge
+// The challenge is:
+// * H(name || previous_challenge || binded_values...) if the challenge is not the first one
+// * H(name || binded_values... ) if it's is the first challenge
 func (t *Transcript) ComputeChallenge(challengeID string) (frontend.Variable, error) {
 
 	challenge, ok := t.challenges[challengeID]
@@ -106,32 +163,143 @@ func (t *Transcript) ComputeChallenge(challengeID string) (frontend.Variable, er
 		return challenge.value, nil
 	}
 
-	t.h.Reset()
-
 	// write the challenge name, the purpose is to have a domain separator
-	cChallenge := []byte(challengeID) // if we send a string, it is assumed to be a base10 number
-	t.h.Write(cChallenge)
+	var stream []segment
+	for _, b := range []byte(challengeID) {
+		stream = append(stream, segment{v: b, isByte: true})
+	}
 
 	// write the previous challenge if it's not the first challenge
 	if challenge.position != 0 {
 		if t.previous == nil || (t.previous.position != challenge.position-1) {
 			return nil, errPreviousChallengeNotComputed
 		}
-		t.h.Write(t.previous.value)
+		stream = append(stream, t.previous.digest...)
 	}
 
 	// write the binded values in the order they were added
-	t.h.Write(challenge.bindings...)
+	stream = append(stream, challenge.bindings...)
+	if len(stream) == 0 {
+		return nil, errEmptyChallenge
+	}
 
 	// compute the hash of the accumulated values
-	challenge.value = t.h.Sum()
+	if t.h != nil {
+		t.h.Reset()
+		t.h.Write(t.fieldBlocks(stream)...)
+		challenge.value = t.h.Sum()
+		challenge.digest = []segment{{v: challenge.value}}
+		t.h.Reset()
+	} else {
+		t.bh.Reset()
+		for _, s := range stream {
+			if s.isByte {
+				t.bh.Write(s.v)
+			} else {
+				t.bh.Write(t.toBytes(s.v)...)
+			}
+		}
+		digest := t.bh.Sum()
+		challenge.value = t.pack(digest)
+		challenge.digest = make([]segment, len(digest))
+		for i := range digest {
+			challenge.digest[i] = segment{v: digest[i], isByte: true}
+		}
+		t.bh.Reset()
+	}
 	challenge.isComputed = true
 	t.previous = &challenge
 
 	t.challenges[challengeID] = challenge
 
-	t.h.Reset()
-
 	return challenge.value, nil
 
 }
+
+// fieldBlocks splits stream in blocks of Fr.Bytes bytes, the last one being
+// padded with zeroes on the left. A field element starting a block is the block
+// itself, the others are decomposed in bytes.
+func (t *Transcript) fieldBlocks(stream []segment) []frontend.Variable {
+	blockSize := t.api.Compiler().Curve().Info().Fr.Bytes
+	var blocks, block []frontend.Variable
+	for _, s := range stream {
+		if !s.isByte && len(block) == 0 {
+			blocks = append(blocks, s.v)
+			continue
+		}
+		bytes := []frontend.Variable{s.v}
+		if !s.isByte {
+			bytes = t.toBytes(s.v)
+		}
+		for _, b := range bytes {
+			block = append(block, b)
+			if len(block) == blockSize {
+				blocks = append(blocks, t.pack(block))
+				block = nil
+			}
+		}
+	}
+	if len(block) != 0 {
+		blocks = append(blocks, t.pack(block))
+	}
+	return blocks
+}
+
+// toBytes returns the Fr.Bytes bytes of the big endian encoding of v. The bits
+// of v are asserted to encode an integer lower than the modulus, so that the
+// encoding is unique.
+func (t *Transcript) toBytes(v frontend.Variable) []frontend.Variable {
+	api := t.api
+	info := api.Compiler().Curve().Info()
+	res := make([]frontend.Variable, info.Fr.Bytes)
+
+	if c, ok := api.Compiler().ConstantValue(v); ok {
+		b := c.FillBytes(make([]byte, info.Fr.Bytes))
+		for i := range res {
+			res[i] = b[i]
+		}
+		return res
+	}
+
+	bits := api.ToBinary(v, info.Fr.Bits)
+	t.assertIsLessThanModulus(bits)
+	for i := range res {
+		// the byte i holds the bits 8*(len(res)-1-i) to 8*(len(res)-i)
+		var b frontend.Variable = 0
+		for j := 7; j >= 0; j-- {
+			k := 8*(len(res)-1-i) + j
+			if k < len(bits) {
+				b = api.Add(api.Mul(b, 2), bits[k])
+			} else {
+				b = api.Mul(b, 2)
+			}
+		}
+		res[i] = b
+	}
+	return res
+}
+
+// assertIsLessThanModulus asserts that the little endian bits encode an integer
+// lower than the modulus: for each bit of the modulus minus one which is 0, the
+// bit must be 0 if the higher bits are equal to the ones of the modulus minus one.
+func (t *Transcript) assertIsLessThanModulus(bits []frontend.Variable) {
+	api := t.api
+	bound := new(big.Int).Sub(api.Compiler().Curve().Info().Fr.Modulus(), big.NewInt(1))
+	var equal frontend.Variable = 1
+	for i := len(bits) - 1; i >= 0; i-- {
+		if bound.Bit(i) == 1 {
+			equal = api.Mul(equal, bits[i])
+		} else {
+			api.AssertIsEqual(api.Mul(equal, bits[i]), 0)
+		}
+	}
+}
+
+// pack returns the integer of the big endian bytes, modulo the field modulus
+func (t *Transcript) pack(bytes []frontend.Variable) frontend.Variable {
+	var res frontend.Variable = 0
+	for _, b := range bytes {
+		res = t.api.Add(t.api.Mul(res, 256), b)
+	}
+	return res
+}
@@ -17,15 +17,19 @@ limitations under the License.
 package fiatshamir
 
 import (
+	cryptosha256 "crypto/sha256"
+	stdhash "hash"
 	"math/big"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	fiatshamir "github.com/consensys/gnark-crypto/fiat-shamir"
 	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/scs"
 	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/hash/sha256"
 	"github.com/consensys/gnark/test"
 )
 
@@ -149,6 +153,113 @@ func TestFiatShamir(t *testing.T) {
 
 }
 
+// transcriptCircuit derives challenges with unaligned names and byte bindings
+type transcriptCircuit struct {
+	Bindings   [3][2]frontend.Variable
+	Bytes      [5]frontend.Variable
+	Challenges [3]frontend.Variable `gnark:",public"`
+
+	hash string
+}
+
+func (circuit *transcriptCircuit) Define(api frontend.API) error {
+	var ts Transcript
+	switch circuit.hash {
+	case "mimc":
+		h, err := mimc.NewMiMC(api)
+		if err != nil {
+			return err
+		}
+		ts = NewTranscript(api, &h, "alpha", "beta", "gamma")
+	case "sha256":
+		ts = NewBinaryTranscript(api, sha256.New(api), "alpha", "beta", "gamma")
+	}
+
+	for i := range circuit.Bytes {
+		api.ToBinary(circuit.Bytes[i], 8)
+	}
+	if err := ts.Bind("alpha", circuit.Bindings[0][:]); err != nil {
+		return err
+	}
+	if err := ts.BindBytes("alpha", circuit.Bytes[:]); err != nil {
+		return err
+	}
+	if err := ts.Bind("beta", circuit.Bindings[1][:]); err != nil {
+		return err
+	}
+	if err := ts.Bind("gamma", circuit.Bindings[2][:]); err != nil {
+		return err
+	}
+	for i, id := range []string{"alpha", "beta", "gamma"} {
+		c, err := ts.ComputeChallenge(id)
+		if err != nil {
+			return err
+		}
+		api.AssertIsEqual(c, circuit.Challenges[i])
+	}
+	return nil
+}
+
+func TestTranscriptHashes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, tc := range []struct {
+		hash    string
+		curveID ecc.ID
+	}{
+		{"mimc", ecc.BN254},
+		{"mimc", ecc.BW6_761},
+		{"sha256", ecc.BN254},
+	} {
+		assert.Run(func(assert *test.Assert) {
+			modulus := tc.curveID.Info().Fr.Modulus()
+			var h stdhash.Hash
+			switch tc.hash {
+			case "mimc":
+				h = hash.MIMC_BN254.New()
+				if tc.curveID == ecc.BW6_761 {
+					h = hash.MIMC_BW6_761.New()
+				}
+			case "sha256":
+				h = cryptosha256.New()
+			}
+			ts := fiatshamir.NewTranscript(h, "alpha", "beta", "gamma")
+
+			var witness transcriptCircuit
+			buf := make([]byte, tc.curveID.Info().Fr.Bytes)
+			for i := 0; i < 3; i++ {
+				for j := 0; j < 2; j++ {
+					// the last binding is the largest field element
+					v := big.NewInt(int64(1000*i + j + 1))
+					if i == 2 && j == 1 {
+						v.Sub(modulus, big.NewInt(1))
+					}
+					witness.Bindings[i][j] = v
+					assert.NoError(ts.Bind([]string{"alpha", "beta", "gamma"}[i], v.FillBytes(buf)))
+				}
+				if i == 0 {
+					bytes := []byte{0xde, 0xad, 0x00, 0xbe, 0xef}
+					for k := range bytes {
+						witness.Bytes[k] = bytes[k]
+					}
+					assert.NoError(ts.Bind("alpha", bytes))
+				}
+			}
+			for i, id := range []string{"alpha", "beta", "gamma"} {
+				c, err := ts.ComputeChallenge(id)
+				assert.NoError(err)
+				witness.Challenges[i] = new(big.Int).Mod(new(big.Int).SetBytes(c), modulus)
+			}
+
+			circuit := transcriptCircuit{hash: tc.hash}
+			assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(tc.curveID), test.WithBackends(backend.GROTH16))
+
+			witness.Challenges[2] = new(big.Int).Add(witness.Challenges[2].(*big.Int), big.NewInt(1))
+			assert.SolvingFailed(&circuit, &witness, test.WithCurves(tc.curveID), test.WithBackends(backend.GROTH16))
+		}, tc.hash, tc.curveID.String())
+	}
+}
+
 func BenchmarkCompile(b *testing.B) {
 	// create an empty cs
 	var circuit FiatShamirCircuit
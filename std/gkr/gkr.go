@@ -0,0 +1,523 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gkr provides a ZKP-circuit verifier of GKR proofs, to evaluate a
+// small sub-circuit on many instances (hash trees, batched signatures, ...)
+// outside of the SNARK.
+//
+// A Circuit is data-parallel: each of its wires holds one value per instance,
+// and is either an input or the output of a polynomial Gate applied instance
+// by instance to other wires. The instances are added with API.AddInstance,
+// whose outputs are computed by a hint, then Finalize proves them with GKR:
+//   - the instances are padded with zeroes to a power of two N = 2ᵇ, so that a
+//     wire is a multilinear polynomial in b variables;
+//   - a random point is derived from api.Commit of the inputs and outputs,
+//     and the claims on the evaluations of the output wires at this point are
+//     reduced, wire by wire, to claims on the evaluations of their inputs
+//     with sumchecks, whose proofs are computed by a hint;
+//   - the claims on the input wires are checked against the inputs.
+//
+// The further challenges are derived with MiMC. The verification costs about N
+// constraints per input and output wire, and b MiMC hashes of d+3 field
+// elements for each gate wire of degree d, instead of about d⋅N constraints
+// per gate wire. As a MiMC hash of a field element costs a few hundred
+// constraints, GKR pays off for thousands of instances.
+//
+// As a circuit can commit only once, it can use only one API, and can't call
+// api.Commit itself.
+package gkr
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+)
+
+func init() {
+	hint.Register(EvaluateInstance)
+	hint.Register(Prove)
+}
+
+// Gate is a polynomial G(x₀, x₁, …) = Σᵢ cᵢ⋅Πⱼ xⱼ^eᵢⱼ of the input wires xⱼ of
+// a gate wire.
+type Gate struct {
+	Terms []GateTerm
+}
+
+// GateTerm is a monomial Coeff⋅Πⱼ xⱼ^Exponents[j] of a Gate
+type GateTerm struct {
+	Coeff     *big.Int
+	Exponents []int
+}
+
+// Degree returns the total degree of g
+func (g *Gate) Degree() int {
+	res := 0
+	for _, t := range g.Terms {
+		d := 0
+		for _, e := range t.Exponents {
+			d += e
+		}
+		if d > res {
+			res = d
+		}
+	}
+	return res
+}
+
+// Evaluate returns g(in...), computed with the arithmetic operations of api
+func (g *Gate) Evaluate(api frontend.API, in ...frontend.Variable) frontend.Variable {
+	var res frontend.Variable = 0
+	for _, t := range g.Terms {
+		var m frontend.Variable = t.Coeff
+		for j, e := range t.Exponents {
+			for k := 0; k < e; k++ {
+				m = api.Mul(m, in[j])
+			}
+		}
+		res = api.Add(res, m)
+	}
+	return res
+}
+
+// check returns an error if g is not a well formed gate of nbInputs inputs
+func (g *Gate) check(nbInputs int) error {
+	if len(g.Terms) == 0 {
+		return errors.New("gate: no terms")
+	}
+	for _, t := range g.Terms {
+		if t.Coeff == nil {
+			return errors.New("gate: missing coefficient")
+		}
+		if len(t.Exponents) != nbInputs {
+			return fmt.Errorf("gate: %d exponents for %d inputs", len(t.Exponents), nbInputs)
+		}
+		for _, e := range t.Exponents {
+			if e < 0 {
+				return errors.New("gate: negative exponent")
+			}
+		}
+	}
+	return nil
+}
+
+// Wire is a wire of a Circuit
+type Wire int
+
+// wire is an input of the circuit if gate is nil
+type wire struct {
+	gate   *Gate
+	inputs []int
+}
+
+// Circuit is a data-parallel circuit. Its outputs are the gate wires which
+// are not an input of another gate.
+type Circuit struct {
+	wires []wire
+}
+
+// Input returns a new input wire. The inputs of an instance are given to
+// API.AddInstance in the order of their creation.
+func (c *Circuit) Input() Wire {
+	c.wires = append(c.wires, wire{})
+	return Wire(len(c.wires) - 1)
+}
+
+// Gate returns a new wire, the output of g applied to the inputs.
+func (c *Circuit) Gate(g Gate, inputs ...Wire) Wire {
+	if len(inputs) == 0 {
+		panic("gkr: a gate must have inputs")
+	}
+	if err := g.check(len(inputs)); err != nil {
+		panic("gkr: " + err.Error())
+	}
+	w := wire{gate: &g, inputs: make([]int, len(inputs))}
+	for i, in := range inputs {
+		if in < 0 || int(in) >= len(c.wires) {
+			panic("gkr: unknown wire")
+		}
+		w.inputs[i] = int(in)
+	}
+	c.wires = append(c.wires, w)
+	return Wire(len(c.wires) - 1)
+}
+
+// Add returns a new wire a + b
+func (c *Circuit) Add(a, b Wire) Wire {
+	return c.Gate(Gate{Terms: []GateTerm{
+		{Coeff: big.NewInt(1), Exponents: []int{1, 0}},
+		{Coeff: big.NewInt(1), Exponents: []int{0, 1}},
+	}}, a, b)
+}
+
+// Mul returns a new wire a ⋅ b
+func (c *Circuit) Mul(a, b Wire) Wire {
+	return c.Gate(Gate{Terms: []GateTerm{
+		{Coeff: big.NewInt(1), Exponents: []int{1, 1}},
+	}}, a, b)
+}
+
+// Inputs returns the input wires, in the order of their creation
+func (c *Circuit) Inputs() []Wire {
+	var res []Wire
+	for i, w := range c.wires {
+		if w.gate == nil {
+			res = append(res, Wire(i))
+		}
+	}
+	return res
+}
+
+// Outputs returns the output wires, in the order of their creation
+func (c *Circuit) Outputs() []Wire {
+	isInput := make([]bool, len(c.wires))
+	for _, w := range c.wires {
+		for _, in := range w.inputs {
+			isInput[in] = true
+		}
+	}
+	var res []Wire
+	for i, w := range c.wires {
+		if w.gate != nil && !isInput[i] {
+			res = append(res, Wire(i))
+		}
+	}
+	return res
+}
+
+// nbClaims returns the number of claims on the evaluations of each wire: one
+// for the outputs, and one per use as the input of a gate
+func (c *Circuit) nbClaims() []int {
+	res := make([]int, len(c.wires))
+	for _, o := range c.Outputs() {
+		res[o] = 1
+	}
+	for _, w := range c.wires {
+		for _, in := range w.inputs {
+			res[in]++
+		}
+	}
+	return res
+}
+
+// proofSize returns the number of field elements of a proof on 2ᵇ instances
+func (c *Circuit) proofSize(b int) int {
+	res := 0
+	for i, n := range c.nbClaims() {
+		w := c.wires[i]
+		if w.gate != nil {
+			res += b*(w.gate.Degree()+2) + len(w.inputs)
+		} else if n > 1 {
+			res += b * 3
+		}
+	}
+	return res
+}
+
+// encode returns the description of c given to the hints:
+// nbWires | for each wire: nbInputs | inputs | nbTerms | for each term: coeff | exponents
+func (c *Circuit) encode() []frontend.Variable {
+	res := []frontend.Variable{len(c.wires)}
+	for _, w := range c.wires {
+		res = append(res, len(w.inputs))
+		for _, in := range w.inputs {
+			res = append(res, in)
+		}
+		if w.gate == nil {
+			continue
+		}
+		res = append(res, len(w.gate.Terms))
+		for _, t := range w.gate.Terms {
+			res = append(res, t.Coeff)
+			for _, e := range t.Exponents {
+				res = append(res, e)
+			}
+		}
+	}
+	return res
+}
+
+// API evaluates a Circuit on instances, and proves the evaluations with GKR.
+type API struct {
+	api       frontend.API
+	circuit   *Circuit
+	inputs    [][]frontend.Variable // inputs[i] are the inputs of the i-th instance
+	outputs   [][]frontend.Variable // outputs[i] are the outputs of the i-th instance
+	finalized bool
+}
+
+// New returns an API evaluating c. c must not be modified afterwards.
+func New(api frontend.API, c *Circuit) *API {
+	if len(c.Outputs()) == 0 {
+		panic("gkr: the circuit has no outputs")
+	}
+	return &API{api: api, circuit: c}
+}
+
+// AddInstance returns the outputs of the circuit on the inputs, in the order of
+// Circuit.Outputs. They are computed by a hint, and constrained by Finalize.
+func (g *API) AddInstance(inputs ...frontend.Variable) []frontend.Variable {
+	if g.finalized {
+		panic("gkr: AddInstance after Finalize")
+	}
+	if len(inputs) != len(g.circuit.Inputs()) {
+		panic(fmt.Sprintf("gkr: %d inputs given, the circuit has %d", len(inputs), len(g.circuit.Inputs())))
+	}
+	hintInputs := append(g.circuit.encode(), inputs...)
+	outputs, err := g.api.Compiler().NewHint(EvaluateInstance, len(g.circuit.Outputs()), hintInputs...)
+	if err != nil {
+		panic(err)
+	}
+	g.inputs = append(g.inputs, inputs)
+	g.outputs = append(g.outputs, outputs)
+	return outputs
+}
+
+// Finalize adds the constraints ensuring that the outputs returned by
+// AddInstance are the evaluations of the circuit. It must be called once,
+// after the last instance.
+func (g *API) Finalize() {
+	if g.finalized {
+		panic("gkr: already finalized")
+	}
+	g.finalized = true
+	api := g.api
+	c := g.circuit
+
+	if len(g.inputs) == 0 {
+		return
+	}
+
+	// pad the instances to a power of two, the outputs of the padding instances
+	// are computed at compile time
+	b := bits.Len(uint(len(g.inputs) - 1))
+	n := 1 << b
+	modulus := api.Compiler().Curve().Info().Fr.Modulus()
+	inputWires, outputWires := c.Inputs(), c.Outputs()
+	zeroes := make([]*big.Int, len(inputWires))
+	for i := range zeroes {
+		zeroes[i] = new(big.Int)
+	}
+	values := c.evaluate(modulus, zeroes)
+	padding := make([]frontend.Variable, len(outputWires))
+	for i, o := range outputWires {
+		padding[i] = values[o]
+	}
+
+	// columns[w][j] is the value of the wire w for the j-th instance
+	columns := make([][]frontend.Variable, len(c.wires))
+	for i, w := range inputWires {
+		columns[w] = make([]frontend.Variable, n)
+		for j := range columns[w] {
+			columns[w][j] = 0
+			if j < len(g.inputs) {
+				columns[w][j] = g.inputs[j][i]
+			}
+		}
+	}
+	for i, w := range outputWires {
+		columns[w] = make([]frontend.Variable, n)
+		for j := range columns[w] {
+			columns[w][j] = padding[i]
+			if j < len(g.outputs) {
+				columns[w][j] = g.outputs[j][i]
+			}
+		}
+	}
+
+	// the first challenge is derived from a commitment to the inputs and outputs
+	committed := make([]frontend.Variable, 0, len(g.inputs)*(len(inputWires)+len(outputWires)))
+	for j := range g.inputs {
+		committed = append(committed, g.inputs[j]...)
+		committed = append(committed, g.outputs[j]...)
+	}
+	α := api.Commit(committed...)
+
+	// the proof
+	hintInputs := append(c.encode(), b)
+	for _, w := range inputWires {
+		hintInputs = append(hintInputs, columns[w]...)
+	}
+	hintInputs = append(hintInputs, α)
+	proof, err := api.Compiler().NewHint(Prove, c.proofSize(b), hintInputs...)
+	if err != nil {
+		panic(err)
+	}
+	read := func(k int) []frontend.Variable {
+		res := proof[:k]
+		proof = proof[k:]
+		return res
+	}
+
+	t, err := newTranscript(api, α)
+	if err != nil {
+		panic(err)
+	}
+
+	// claims on the outputs at a random point
+	claims := make([][]claim, len(c.wires))
+	r := make([]frontend.Variable, b)
+	for i := range r {
+		r[i] = t.next()
+	}
+	for _, o := range outputWires {
+		claims[o] = []claim{{point: r, value: g.evaluateMLE(columns[o], r)}}
+	}
+
+	// the claims on each wire are reduced to claims on its inputs
+	identity := &Gate{Terms: []GateTerm{{Coeff: big.NewInt(1), Exponents: []int{1}}}}
+	for w := len(c.wires) - 1; w >= 0; w-- {
+		wc := claims[w]
+		gate := c.wires[w].gate
+		if len(wc) == 0 {
+			continue
+		}
+		if gate == nil && len(wc) == 1 {
+			api.AssertIsEqual(g.evaluateMLE(columns[w], wc[0].point), wc[0].value)
+			continue
+		}
+		if gate == nil {
+			gate = identity
+		}
+
+		// the claims are combined with the powers of λ
+		var λ frontend.Variable = 1
+		if len(wc) > 1 {
+			λ = t.next()
+		}
+		var sum frontend.Variable = 0
+		var λk frontend.Variable = 1
+		for k := range wc {
+			sum = api.Add(sum, api.Mul(λk, wc[k].value))
+			λk = api.Mul(λk, λ)
+		}
+
+		// sumcheck of Σⱼ E(j)⋅G(inputs(j)), where E(j) = Σₖ λᵏ⋅eq(pointₖ, j)
+		ρ := make([]frontend.Variable, b)
+		for i := range ρ {
+			evals := read(gate.Degree() + 2)
+			api.AssertIsEqual(api.Add(evals[0], evals[1]), sum)
+			ρ[i] = t.next(evals...)
+			sum = g.interpolate(evals, ρ[i])
+		}
+		var e frontend.Variable = 0
+		λk = 1
+		for k := range wc {
+			e = api.Add(e, api.Mul(λk, g.eq(wc[k].point, ρ)))
+			λk = api.Mul(λk, λ)
+		}
+
+		if c.wires[w].gate == nil {
+			api.AssertIsEqual(sum, api.Mul(e, g.evaluateMLE(columns[w], ρ)))
+			continue
+		}
+		y := read(len(c.wires[w].inputs))
+		t.next(y...)
+		api.AssertIsEqual(sum, api.Mul(e, gate.Evaluate(api, y...)))
+		for i, in := range c.wires[w].inputs {
+			claims[in] = append(claims[in], claim{point: ρ, value: y[i]})
+		}
+	}
+}
+
+// claim is a claim on the evaluation of the multilinear extension of a wire
+type claim struct {
+	point []frontend.Variable
+	value frontend.Variable
+}
+
+// evaluateMLE returns the evaluation at r of the multilinear extension of the
+// 2ᵇ values, the first variable being the most significant bit of the index
+func (g *API) evaluateMLE(values []frontend.Variable, r []frontend.Variable) frontend.Variable {
+	api := g.api
+	v := append([]frontend.Variable(nil), values...)
+	for _, ri := range r {
+		half := len(v) / 2
+		for j := 0; j < half; j++ {
+			v[j] = api.Add(v[j], api.Mul(ri, api.Sub(v[j+half], v[j])))
+		}
+		v = v[:half]
+	}
+	return v[0]
+}
+
+// eq returns Πᵢ (pᵢ⋅qᵢ + (1-pᵢ)⋅(1-qᵢ))
+func (g *API) eq(p, q []frontend.Variable) frontend.Variable {
+	api := g.api
+	var res frontend.Variable = 1
+	for i := range p {
+		pq := api.Mul(p[i], q[i])
+		res = api.Mul(res, api.Sub(api.Add(1, pq, pq), p[i], q[i]))
+	}
+	return res
+}
+
+// interpolate returns P(x), where P is the polynomial of degree len(evals)-1
+// such that P(i) = evals[i]
+func (g *API) interpolate(evals []frontend.Variable, x frontend.Variable) frontend.Variable {
+	api := g.api
+	m := len(evals)
+	modulus := api.Compiler().Curve().Info().Fr.Modulus()
+
+	// P(x) = Σᵢ evals[i]⋅Πⱼ≠ᵢ (x-j)/(i-j), with prefix and suffix products of (x-j)
+	prefix := make([]frontend.Variable, m)
+	suffix := make([]frontend.Variable, m)
+	prefix[0], suffix[m-1] = 1, 1
+	for i := 1; i < m; i++ {
+		prefix[i] = api.Mul(prefix[i-1], api.Sub(x, i-1))
+		suffix[m-1-i] = api.Mul(suffix[m-i], api.Sub(x, m-i))
+	}
+	var res frontend.Variable = 0
+	for i := 0; i < m; i++ {
+		den := big.NewInt(1)
+		for j := 0; j < m; j++ {
+			if j != i {
+				den.Mul(den, big.NewInt(int64(i-j)))
+			}
+		}
+		den.Mod(den, modulus).ModInverse(den, modulus)
+		res = api.Add(res, api.Mul(evals[i], den, prefix[i], suffix[i]))
+	}
+	return res
+}
+
+// transcript derives the challenges of the sumchecks: each challenge is the
+// MiMC hash of the previous one and of the values sent by the prover
+type transcript struct {
+	h     mimc.MiMC
+	state frontend.Variable
+}
+
+func newTranscript(api frontend.API, seed frontend.Variable) (*transcript, error) {
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		return nil, err
+	}
+	return &transcript{h: h, state: seed}, nil
+}
+
+func (t *transcript) next(values ...frontend.Variable) frontend.Variable {
+	t.h.Reset()
+	t.h.Write(t.state)
+	t.h.Write(values...)
+	t.state = t.h.Sum()
+	return t.state
+}
@@ -0,0 +1,79 @@
+package gkr
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+const nbInstances = 3
+
+var roundConstants = []int64{1, 2}
+
+// cubeGate returns the gate (y + c)³
+func cubeGate(c int64) Gate {
+	term := func(coeff int64, e int) GateTerm {
+		return GateTerm{Coeff: big.NewInt(coeff), Exponents: []int{e}}
+	}
+	return Gate{Terms: []GateTerm{term(1, 3), term(3*c, 2), term(3*c*c, 1), term(c*c*c, 0)}}
+}
+
+// cubeCircuit returns a circuit of inputs x, k and outputs s, x⋅k, where s is
+// the result of the rounds s ← (s + k + cᵢ)³, starting from x
+func cubeCircuit() *Circuit {
+	var c Circuit
+	x, k := c.Input(), c.Input()
+	s := x
+	for _, rc := range roundConstants {
+		s = c.Gate(cubeGate(rc), c.Add(s, k))
+	}
+	c.Mul(x, k)
+	return &c
+}
+
+type gkrCircuit struct {
+	X, K   [nbInstances]frontend.Variable
+	S, P   [nbInstances]frontend.Variable `gnark:",public"`
+	tamper bool
+}
+
+func (circuit *gkrCircuit) Define(api frontend.API) error {
+	g := New(api, cubeCircuit())
+	for i := 0; i < nbInstances; i++ {
+		outputs := g.AddInstance(circuit.X[i], circuit.K[i])
+		if circuit.tamper && i == 0 {
+			outputs[0] = api.Add(outputs[0], 1)
+			g.outputs[0] = outputs
+		}
+		api.AssertIsEqual(outputs[0], circuit.S[i])
+		api.AssertIsEqual(outputs[1], circuit.P[i])
+	}
+	g.Finalize()
+	return nil
+}
+
+func TestGKR(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	var assignment gkrCircuit
+	for i := 0; i < nbInstances; i++ {
+		x, k := big.NewInt(int64(2+i)), big.NewInt(int64(5-i))
+		s := new(big.Int).Set(x)
+		for _, rc := range roundConstants {
+			s.Add(s, k).Add(s, big.NewInt(rc))
+			s.Exp(s, big.NewInt(3), nil)
+		}
+		assignment.X[i], assignment.K[i] = x, k
+		assignment.S[i], assignment.P[i] = s, new(big.Int).Mul(x, k)
+	}
+	assert.ProverSucceeded(&gkrCircuit{}, &assignment, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+
+	// outputs which are not the evaluations of the circuit are rejected
+	tampered := assignment
+	tampered.S[0] = new(big.Int).Add(assignment.S[0].(*big.Int), big.NewInt(1))
+	assert.ProverFailed(&gkrCircuit{tamper: true}, &tampered, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+}
@@ -0,0 +1,376 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gkr
+
+import (
+	"errors"
+	"fmt"
+	stdhash "hash"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/hash"
+)
+
+// EvaluateInstance returns the outputs of a circuit on the inputs of an
+// instance. The inputs are the description of the circuit (see Circuit.encode),
+// followed by the inputs of the instance.
+func EvaluateInstance(curveID ecc.ID, inputs []*big.Int, results []*big.Int) error {
+	c, inputs, err := decodeCircuit(inputs)
+	if err != nil {
+		return err
+	}
+	inputWires, outputWires := c.Inputs(), c.Outputs()
+	if len(inputs) != len(inputWires) || len(results) != len(outputWires) {
+		return errors.New("EvaluateInstance: invalid number of inputs or results")
+	}
+	values := c.evaluate(curveID.Info().Fr.Modulus(), inputs)
+	for i, o := range outputWires {
+		results[i].Set(values[o])
+	}
+	return nil
+}
+
+// Prove returns the GKR proof of the evaluations of a circuit on 2ᵇ instances,
+// read by API.Finalize. The inputs are the description of the circuit (see
+// Circuit.encode), b, the columns of the input wires and the first challenge.
+func Prove(curveID ecc.ID, inputs []*big.Int, results []*big.Int) error {
+	c, inputs, err := decodeCircuit(inputs)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 || !inputs[0].IsUint64() || inputs[0].Uint64() >= 32 {
+		return errors.New("Prove: invalid number of instances")
+	}
+	b := int(inputs[0].Uint64())
+	n := 1 << b
+	inputs = inputs[1:]
+	inputWires := c.Inputs()
+	if len(inputs) != len(inputWires)*n+1 || len(results) != c.proofSize(b) {
+		return errors.New("Prove: invalid number of inputs or results")
+	}
+	p := prover{modulus: curveID.Info().Fr.Modulus(), b: b}
+	if p.t, err = newNativeTranscript(curveID, inputs[len(inputs)-1]); err != nil {
+		return err
+	}
+
+	// columns[w][j] is the value of the wire w for the j-th instance
+	columns := make([][]*big.Int, len(c.wires))
+	for w := range columns {
+		columns[w] = make([]*big.Int, n)
+	}
+	instance := make([]*big.Int, len(inputWires))
+	for j := 0; j < n; j++ {
+		for i := range inputWires {
+			instance[i] = inputs[i*n+j]
+		}
+		for w, v := range c.evaluate(p.modulus, instance) {
+			columns[w][j] = v
+		}
+	}
+
+	// claims on the outputs at a random point
+	claims := make([][]nativeClaim, len(c.wires))
+	r := make([]*big.Int, b)
+	for i := range r {
+		r[i] = p.t.next()
+	}
+	for _, o := range c.Outputs() {
+		claims[o] = []nativeClaim{{point: r}}
+	}
+
+	identity := &Gate{Terms: []GateTerm{{Coeff: big.NewInt(1), Exponents: []int{1}}}}
+	for w := len(c.wires) - 1; w >= 0; w-- {
+		wc := claims[w]
+		gate := c.wires[w].gate
+		inputs := c.wires[w].inputs
+		if len(wc) == 0 || (gate == nil && len(wc) == 1) {
+			continue
+		}
+		if gate == nil {
+			gate, inputs = identity, []int{w}
+		}
+
+		// E(j) = Σₖ λᵏ⋅eq(pointₖ, j)
+		λ := big.NewInt(1)
+		if len(wc) > 1 {
+			λ = p.t.next()
+		}
+		e := make([]*big.Int, n)
+		for j := range e {
+			e[j] = new(big.Int)
+		}
+		λk := big.NewInt(1)
+		for k := range wc {
+			for j, v := range p.eqTable(wc[k].point) {
+				v.Mul(v, λk)
+				e[j].Add(e[j], v).Mod(e[j], p.modulus)
+			}
+			λk = new(big.Int).Mul(λk, λ)
+			λk.Mod(λk, p.modulus)
+		}
+		tables := make([][]*big.Int, len(inputs))
+		for i, in := range inputs {
+			tables[i] = make([]*big.Int, n)
+			for j := range tables[i] {
+				tables[i][j] = new(big.Int).Set(columns[in][j])
+			}
+		}
+
+		ρ := p.sumcheck(gate, e, tables)
+		if c.wires[w].gate == nil {
+			continue
+		}
+		y := make([]*big.Int, len(tables))
+		for i := range tables {
+			y[i] = tables[i][0]
+		}
+		p.send(y...)
+		p.t.next(y...)
+		for _, in := range inputs {
+			claims[in] = append(claims[in], nativeClaim{point: ρ})
+		}
+	}
+
+	if len(p.proof) != len(results) {
+		return fmt.Errorf("Prove: proof of %d elements, expected %d", len(p.proof), len(results))
+	}
+	for i := range results {
+		results[i].Set(p.proof[i])
+	}
+	return nil
+}
+
+// nativeClaim is a claim on the evaluation of a wire at a point, the value is
+// known to the verifier
+type nativeClaim struct {
+	point []*big.Int
+}
+
+type prover struct {
+	modulus *big.Int
+	b       int
+	t       *nativeTranscript
+	proof   []*big.Int
+}
+
+func (p *prover) send(values ...*big.Int) {
+	p.proof = append(p.proof, values...)
+}
+
+// sumcheck proves the sum Σⱼ E(j)⋅G(tables(j)), and returns the challenges.
+// The tables are folded in place: tables[i][0] is the evaluation of the
+// multilinear extension of the i-th table at the challenges.
+func (p *prover) sumcheck(gate *Gate, e []*big.Int, tables [][]*big.Int) []*big.Int {
+	ρ := make([]*big.Int, p.b)
+	x := make([]*big.Int, len(tables))
+	for i := range x {
+		x[i] = new(big.Int)
+	}
+	var ex, tmp big.Int
+	for r := range ρ {
+		half := len(e) / 2
+
+		// P(X) = Σⱼ E(X, j)⋅G(tables(X, j)), of degree d+1
+		evals := make([]*big.Int, gate.Degree()+2)
+		for k := range evals {
+			evals[k] = new(big.Int)
+			for j := 0; j < half; j++ {
+				p.line(&ex, e[j], e[j+half], k)
+				for i := range tables {
+					p.line(x[i], tables[i][j], tables[i][j+half], k)
+				}
+				tmp.Mul(&ex, gate.evaluate(p.modulus, x))
+				evals[k].Add(evals[k], &tmp)
+			}
+			evals[k].Mod(evals[k], p.modulus)
+		}
+		p.send(evals...)
+		ρ[r] = p.t.next(evals...)
+
+		// fold E and the tables at ρ
+		for j := 0; j < half; j++ {
+			p.fold(e[j], e[j+half], ρ[r])
+			for i := range tables {
+				p.fold(tables[i][j], tables[i][j+half], ρ[r])
+			}
+		}
+		e = e[:half]
+		for i := range tables {
+			tables[i] = tables[i][:half]
+		}
+	}
+	return ρ
+}
+
+// line sets res = a + k⋅(b - a)
+func (p *prover) line(res, a, b *big.Int, k int) {
+	res.Sub(b, a)
+	res.Mul(res, big.NewInt(int64(k)))
+	res.Add(res, a).Mod(res, p.modulus)
+}
+
+// fold sets a = a + ρ⋅(b - a)
+func (p *prover) fold(a, b, ρ *big.Int) {
+	var t big.Int
+	t.Sub(b, a)
+	t.Mul(&t, ρ)
+	a.Add(a, &t).Mod(a, p.modulus)
+}
+
+// eqTable returns the 2ᵇ values eq(r, j), the first variable being the most
+// significant bit of j
+func (p *prover) eqTable(r []*big.Int) []*big.Int {
+	res := []*big.Int{big.NewInt(1)}
+	for _, ri := range r {
+		next := make([]*big.Int, 2*len(res))
+		for j, v := range res {
+			next[2*j+1] = new(big.Int).Mul(v, ri)
+			next[2*j+1].Mod(next[2*j+1], p.modulus)
+			next[2*j] = new(big.Int).Sub(v, next[2*j+1])
+			next[2*j].Mod(next[2*j], p.modulus)
+		}
+		res = next
+	}
+	return res
+}
+
+// evaluate returns the values of the wires of c on the inputs of an instance
+func (c *Circuit) evaluate(modulus *big.Int, inputs []*big.Int) []*big.Int {
+	values := make([]*big.Int, len(c.wires))
+	next := 0
+	for i, w := range c.wires {
+		if w.gate == nil {
+			values[i] = new(big.Int).Mod(inputs[next], modulus)
+			next++
+			continue
+		}
+		x := make([]*big.Int, len(w.inputs))
+		for j, in := range w.inputs {
+			x[j] = values[in]
+		}
+		values[i] = w.gate.evaluate(modulus, x)
+	}
+	return values
+}
+
+// evaluate returns g(x) modulo the modulus
+func (g *Gate) evaluate(modulus *big.Int, x []*big.Int) *big.Int {
+	res := new(big.Int)
+	var m big.Int
+	for _, t := range g.Terms {
+		m.Set(t.Coeff)
+		for j, e := range t.Exponents {
+			for k := 0; k < e; k++ {
+				m.Mul(&m, x[j]).Mod(&m, modulus)
+			}
+		}
+		res.Add(res, &m)
+	}
+	return res.Mod(res, modulus)
+}
+
+// decodeCircuit returns the circuit described at the beginning of inputs (see
+// Circuit.encode), and the remaining inputs
+func decodeCircuit(inputs []*big.Int) (*Circuit, []*big.Int, error) {
+	errInvalid := errors.New("invalid circuit description")
+	readInt := func() (int, error) {
+		if len(inputs) == 0 || !inputs[0].IsInt64() || inputs[0].Int64() < 0 || inputs[0].Int64() > 1<<31 {
+			return 0, errInvalid
+		}
+		v := int(inputs[0].Int64())
+		inputs = inputs[1:]
+		return v, nil
+	}
+	nbWires, err := readInt()
+	if err != nil {
+		return nil, nil, err
+	}
+	c := &Circuit{wires: make([]wire, nbWires)}
+	for i := range c.wires {
+		nbInputs, err := readInt()
+		if err != nil {
+			return nil, nil, err
+		}
+		if nbInputs == 0 {
+			continue
+		}
+		w := &c.wires[i]
+		w.inputs = make([]int, nbInputs)
+		for j := range w.inputs {
+			if w.inputs[j], err = readInt(); err != nil {
+				return nil, nil, err
+			}
+			if w.inputs[j] >= i {
+				return nil, nil, errInvalid
+			}
+		}
+		nbTerms, err := readInt()
+		if err != nil {
+			return nil, nil, err
+		}
+		w.gate = &Gate{Terms: make([]GateTerm, nbTerms)}
+		for k := range w.gate.Terms {
+			if len(inputs) == 0 {
+				return nil, nil, errInvalid
+			}
+			t := &w.gate.Terms[k]
+			t.Coeff, inputs = inputs[0], inputs[1:]
+			t.Exponents = make([]int, nbInputs)
+			for j := range t.Exponents {
+				if t.Exponents[j], err = readInt(); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+	}
+	return c, inputs, nil
+}
+
+// nativeTranscript computes the challenges of transcript out of the circuit
+type nativeTranscript struct {
+	h     stdhash.Hash
+	size  int
+	state *big.Int
+}
+
+var mimcHashes = map[ecc.ID]hash.Hash{
+	ecc.BN254:     hash.MIMC_BN254,
+	ecc.BLS12_381: hash.MIMC_BLS12_381,
+	ecc.BLS12_377: hash.MIMC_BLS12_377,
+	ecc.BW6_761:   hash.MIMC_BW6_761,
+	ecc.BLS24_315: hash.MIMC_BLS24_315,
+	ecc.BW6_633:   hash.MIMC_BW6_633,
+}
+
+func newNativeTranscript(curveID ecc.ID, seed *big.Int) (*nativeTranscript, error) {
+	h, ok := mimcHashes[curveID]
+	if !ok {
+		return nil, errors.New("unknown curve id")
+	}
+	return &nativeTranscript{h: h.New(), size: curveID.Info().Fr.Bytes, state: seed}, nil
+}
+
+func (t *nativeTranscript) next(values ...*big.Int) *big.Int {
+	t.h.Reset()
+	buf := make([]byte, t.size)
+	for _, v := range append([]*big.Int{t.state}, values...) {
+		t.h.Write(v.FillBytes(buf))
+	}
+	t.state = new(big.Int).SetBytes(t.h.Sum(nil))
+	return t.state
+}
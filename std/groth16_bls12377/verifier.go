@@ -55,10 +55,19 @@ type VerifyingKey struct {
 // Verify implements the verification function of Groth16.
 // Notation follows Figure 4. in DIZK paper https://eprint.iacr.org/2018/691.pdf
 // publicInputs do NOT contain the ONE_WIRE
+//
+// The verifying key is either part of the witness (see VerifyingKey.Assign),
+// or embedded in the circuit as constants. In the latter case, the field
+// holding the verifying key in the circuit must be tagged `gnark:"-"` and
+// assigned in the circuit definition, which saves the cost of the
+// corresponding inputs.
 func Verify(api frontend.API, vk VerifyingKey, proof Proof, publicInputs []frontend.Variable) {
 	if len(vk.G1.K) == 0 {
 		panic("innver verifying key needs at least one point; VerifyingKey.G1 must be initialized before compiling circuit")
 	}
+	if len(publicInputs)+1 != len(vk.G1.K) {
+		panic("number of public inputs does not match the inner verifying key")
+	}
 
 	// compute kSum = Σx.[Kvk(t)]1
 	var kSum sw_bls12377.G1Affine
@@ -104,3 +113,14 @@ func (vk *VerifyingKey) Assign(_ovk groth16.VerifyingKey) {
 	vk.G2.DeltaNeg.Assign(&deltaNeg)
 	vk.G2.GammaNeg.Assign(&gammaNeg)
 }
+
+// Assign values to the "in-circuit" Proof from a "out-of-circuit" Proof
+func (proof *Proof) Assign(_oproof groth16.Proof) {
+	oproof, ok := _oproof.(*groth16_bls12377.Proof)
+	if !ok {
+		panic("expected *groth16_bls12377.Proof, got " + reflect.TypeOf(_oproof).String())
+	}
+	proof.Ar.Assign(&oproof.Ar)
+	proof.Krs.Assign(&oproof.Krs)
+	proof.Bs.Assign(&oproof.Bs)
+}
@@ -126,9 +126,7 @@ func TestVerifier(t *testing.T) {
 	// the public part is exactly the public part of the inner proof,
 	// up to the renaming of the inner ONE_WIRE to not conflict with the one wire of the outer proof.
 	var witness verifierCircuit
-	witness.InnerProof.Ar.Assign(&innerProof.Ar)
-	witness.InnerProof.Krs.Assign(&innerProof.Krs)
-	witness.InnerProof.Bs.Assign(&innerProof.Bs)
+	witness.InnerProof.Assign(&innerProof)
 
 	witness.InnerVk.Assign(&innerVk)
 	witness.Hash = publicHash
@@ -139,6 +137,37 @@ func TestVerifier(t *testing.T) {
 	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BW6_761))
 }
 
+type constantVkCircuit struct {
+	InnerProof Proof
+	InnerVk    VerifyingKey `gnark:"-"`
+	Hash       frontend.Variable
+}
+
+func (circuit *constantVkCircuit) Define(api frontend.API) error {
+	Verify(api, circuit.InnerVk, circuit.InnerProof, []frontend.Variable{circuit.Hash})
+	return nil
+}
+
+func TestVerifierConstantVk(t *testing.T) {
+	var innerVk groth16_bls12377.VerifyingKey
+	var innerProof groth16_bls12377.Proof
+	generateBls12377InnerProof(t, &innerVk, &innerProof)
+
+	// the verifying key is embedded in the circuit as constants
+	var circuit, witness constantVkCircuit
+	circuit.InnerVk.Assign(&innerVk)
+	witness.InnerVk = circuit.InnerVk
+
+	witness.InnerProof.Assign(&innerProof)
+	witness.Hash = publicHash
+
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BW6_761))
+
+	witness.Hash = "42"
+	assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BW6_761))
+}
+
 func BenchmarkCompile(b *testing.B) {
 	// get the data
 	var innerVk groth16_bls12377.VerifyingKey
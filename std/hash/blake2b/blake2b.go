@@ -0,0 +1,185 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package blake2b provides a ZKP-circuit function to compute a BLAKE2b hash
+// (RFC 7693), without key.
+//
+// The inputs and the digest are bytes, each byte stored in a
+// frontend.Variable. Input bytes are range checked by the gadget. Internally,
+// the 64-bit words are handled by std/math/uints.
+package blake2b
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+const (
+	// Size is the size of a BLAKE2b-512 digest in bytes.
+	Size = 64
+
+	// Size256 is the size of a BLAKE2b-256 digest in bytes.
+	Size256 = 32
+
+	// BlockSize is the block size of BLAKE2b in bytes.
+	BlockSize = 128
+)
+
+var iv = [8]uint64{
+	0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b, 0xa54ff53a5f1d36f1,
+	0x510e527fade682d1, 0x9b05688c2b3e6c1f, 0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
+}
+
+var sigma = [10][16]int{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+	{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+	{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+	{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+	{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+	{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+	{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+	{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+	{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+}
+
+// Digest is the in-circuit counterpart of golang.org/x/crypto/blake2b.
+type Digest struct {
+	h    [8]uints.U64        // current chaining value
+	buf  []frontend.Variable // bytes not yet compressed (at most BlockSize)
+	len  uint64              // number of bytes compressed
+	size int                 // digest size in bytes
+	bf   *uints.BinaryField  // word arithmetic on the underlying constraint system
+}
+
+// New512 returns a new BLAKE2b-512 hash gadget, which can be used in a gnark
+// circuit.
+func New512(api frontend.API) hash.BinaryHasher {
+	return newDigest(api, Size)
+}
+
+// New256 returns a new BLAKE2b-256 hash gadget, which can be used in a gnark
+// circuit.
+func New256(api frontend.API) hash.BinaryHasher {
+	return newDigest(api, Size256)
+}
+
+func newDigest(api frontend.API, size int) *Digest {
+	d := &Digest{bf: uints.New(api), size: size}
+	d.Reset()
+	return d
+}
+
+// Reset resets the Digest to its initial state.
+func (d *Digest) Reset() {
+	for i := range d.h {
+		d.h[i] = d.bf.ConstU64(iv[i])
+	}
+	// parameter block: digest size, no key, fanout and depth 1
+	d.h[0] = d.bf.ConstU64(iv[0] ^ 0x01010000 ^ uint64(d.size))
+	d.buf = nil
+	d.len = 0
+}
+
+// Size returns the number of bytes Sum returns.
+func (d *Digest) Size() int {
+	return d.size
+}
+
+// Write adds more bytes to the running hash. As the last block is compressed
+// differently, full blocks are only compressed once followed by more bytes;
+// the remaining bytes are buffered until the next call to Write or Sum.
+func (d *Digest) Write(data ...frontend.Variable) {
+	for i := range data {
+		d.buf = append(d.buf, d.bf.ByteValueOf(data[i]))
+	}
+	for len(d.buf) > BlockSize {
+		d.len += BlockSize
+		d.h = d.compress(d.h, d.buf[:BlockSize], d.len, false)
+		d.buf = d.buf[BlockSize:]
+	}
+}
+
+// Sum pads the buffered data and returns the bytes of the digest. The running
+// state of the Digest is not modified.
+func (d *Digest) Sum() []frontend.Variable {
+	// padding: zeroes up to the end of the block
+	block := make([]frontend.Variable, BlockSize)
+	copy(block, d.buf)
+	for i := len(d.buf); i < BlockSize; i++ {
+		block[i] = 0
+	}
+	h := d.compress(d.h, block, d.len+uint64(len(d.buf)), true)
+
+	res := make([]frontend.Variable, 0, 8*len(h))
+	for i := range h {
+		res = append(res, h[i][:]...)
+	}
+	return res[:d.size]
+}
+
+// compress applies the BLAKE2b compression function on the chaining value h
+// and the message block, t being the number of bytes hashed so far including
+// the block.
+func (d *Digest) compress(h [8]uints.U64, block []frontend.Variable, t uint64, final bool) [8]uints.U64 {
+	bf := d.bf
+
+	// words are little endian, as the bytes of uints.U64
+	var m [16]uints.U64
+	for i := range m {
+		copy(m[i][:], block[8*i:8*i+8])
+	}
+
+	var v [16]uints.U64
+	copy(v[:8], h[:])
+	for i := 0; i < 8; i++ {
+		v[8+i] = bf.ConstU64(iv[i])
+	}
+	// the counter is less than 2⁶⁴, its high word is zero
+	v[12] = bf.XorU64(v[12], bf.ConstU64(t))
+	if final {
+		v[14] = bf.NotU64(v[14])
+	}
+
+	g := func(a, b, c, dd int, x, y uints.U64) {
+		v[a] = bf.AddU64(v[a], v[b], x)
+		v[dd] = bf.RotateLeftU64(bf.XorU64(v[dd], v[a]), -32)
+		v[c] = bf.AddU64(v[c], v[dd])
+		v[b] = bf.RotateLeftU64(bf.XorU64(v[b], v[c]), -24)
+		v[a] = bf.AddU64(v[a], v[b], y)
+		v[dd] = bf.RotateLeftU64(bf.XorU64(v[dd], v[a]), -16)
+		v[c] = bf.AddU64(v[c], v[dd])
+		v[b] = bf.RotateLeftU64(bf.XorU64(v[b], v[c]), -63)
+	}
+	for r := 0; r < 12; r++ {
+		s := sigma[r%10]
+		g(0, 4, 8, 12, m[s[0]], m[s[1]])
+		g(1, 5, 9, 13, m[s[2]], m[s[3]])
+		g(2, 6, 10, 14, m[s[4]], m[s[5]])
+		g(3, 7, 11, 15, m[s[6]], m[s[7]])
+		g(0, 5, 10, 15, m[s[8]], m[s[9]])
+		g(1, 6, 11, 12, m[s[10]], m[s[11]])
+		g(2, 7, 8, 13, m[s[12]], m[s[13]])
+		g(3, 4, 9, 14, m[s[14]], m[s[15]])
+	}
+
+	var res [8]uints.U64
+	for i := range res {
+		res[i] = bf.XorU64(h[i], v[i], v[i+8])
+	}
+	return res
+}
@@ -0,0 +1,70 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blake2s
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"golang.org/x/crypto/blake2s"
+)
+
+type blake2sCircuit struct {
+	ExpectedResult [Size]frontend.Variable `gnark:"data,public"`
+	Data           []frontend.Variable
+}
+
+func (circuit *blake2sCircuit) Define(api frontend.API) error {
+	h := New256(api)
+	// write in two chunks to exercise the streaming interface
+	h.Write(circuit.Data[:len(circuit.Data)/2]...)
+	h.Write(circuit.Data[len(circuit.Data)/2:]...)
+	result := h.Sum()
+	for i := range result {
+		api.AssertIsEqual(result[i], circuit.ExpectedResult[i])
+	}
+	return nil
+}
+
+func TestBlake2s(t *testing.T) {
+	// lengths chosen to cover an empty message, one block, a full block which
+	// is the last one and several blocks.
+	for _, n := range []int{0, 3, 64, 150} {
+		data := make([]byte, n)
+		for i := range data {
+			data[i] = byte(i*7 + 3)
+		}
+		expected := blake2s.Sum256(data)
+
+		circuit := blake2sCircuit{Data: make([]frontend.Variable, n)}
+		witness := blake2sCircuit{Data: make([]frontend.Variable, n)}
+		for i := range data {
+			witness.Data[i] = data[i]
+		}
+		for i := range expected {
+			witness.ExpectedResult[i] = expected[i]
+		}
+		assert := test.NewAssert(t)
+		assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+
+		wrongWitness := witness
+		wrongWitness.ExpectedResult[0] = expected[0] ^ 1
+		assert.SolvingFailed(&circuit, &wrongWitness, test.WithCurves(ecc.BN254))
+	}
+}
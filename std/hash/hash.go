@@ -30,3 +30,24 @@ type Hash interface {
 	// Reset empty the internal state and put the intermediate state to zero.
 	Reset()
 }
+
+// BinaryHasher is the interface that byte-oriented hash functions (as gadget)
+// such as SHA2 or Keccak should implement. Contrary to Hash, the inputs and the
+// digest are sequences of bytes, each byte being stored in a frontend.Variable.
+type BinaryHasher interface {
+
+	// Sum computes the digest of the data written so far. It does not change
+	// the internal state of the hash function.
+	Sum() []frontend.Variable
+
+	// Write populate the internal state of the hash function with data. Every
+	// element of data must be a byte.
+	Write(data ...frontend.Variable)
+
+	// Reset empty the internal state and put the hash function in its initial
+	// state.
+	Reset()
+
+	// Size returns the number of bytes Sum returns.
+	Size() int
+}
@@ -0,0 +1,191 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package keccak provides ZKP-circuit functions to compute the Keccak-f[1600]
+// permutation and the Keccak-256 (as used in Ethereum) and SHA3-256 hashes.
+//
+// The inputs and the digests are bytes, each byte stored in a
+// frontend.Variable. Input bytes are range checked by the gadget. The lanes of
+// the state are represented as little-endian slices of boolean variables.
+package keccak
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/multiplexer"
+)
+
+// Digest is a Keccak sponge instance, the in-circuit counterpart of
+// golang.org/x/crypto/sha3.
+type Digest struct {
+	a         State               // current state
+	buf       []frontend.Variable // bytes not yet absorbed (less than rate)
+	rate      int                 // number of bytes absorbed per permutation
+	dsbyte    byte                // domain separation byte
+	outputLen int                 // digest size in bytes
+	api       frontend.API        // underlying constraint system
+}
+
+// NewLegacyKeccak256 returns a new Keccak-256 hash gadget, which can be used in
+// a gnark circuit. This is the hash function used in Ethereum, it differs from
+// SHA3-256 by its padding.
+func NewLegacyKeccak256(api frontend.API) hash.BinaryHasher {
+	return newDigest(api, 136, 0x01, 32)
+}
+
+// New256 returns a new SHA3-256 hash gadget, which can be used in a gnark
+// circuit.
+func New256(api frontend.API) hash.BinaryHasher {
+	return newDigest(api, 136, 0x06, 32)
+}
+
+func newDigest(api frontend.API, rate int, dsbyte byte, outputLen int) *Digest {
+	d := &Digest{api: api, rate: rate, dsbyte: dsbyte, outputLen: outputLen}
+	d.Reset()
+	return d
+}
+
+// Reset resets the Digest to its initial state.
+func (d *Digest) Reset() {
+	d.a = NewState()
+	d.buf = nil
+}
+
+// Size returns the number of bytes Sum returns.
+func (d *Digest) Size() int {
+	return d.outputLen
+}
+
+// Write adds more bytes to the running hash. Full blocks are absorbed
+// directly, the remaining bytes are buffered until the next call to Write or
+// Sum.
+func (d *Digest) Write(data ...frontend.Variable) {
+	d.buf = append(d.buf, data...)
+	for len(d.buf) >= d.rate {
+		d.a = d.absorb(d.a, d.buf[:d.rate])
+		d.buf = d.buf[d.rate:]
+	}
+}
+
+// Sum pads the buffered data and returns the bytes of the digest. The running
+// state of the Digest is not modified.
+func (d *Digest) Sum() []frontend.Variable {
+	// padding: dsbyte, then zeroes, last byte is or-ed with 0x80
+	block := make([]frontend.Variable, d.rate)
+	copy(block, d.buf)
+	for i := len(d.buf); i < d.rate; i++ {
+		block[i] = 0
+	}
+	if len(d.buf) == d.rate-1 {
+		block[len(d.buf)] = d.dsbyte | 0x80
+	} else {
+		block[len(d.buf)] = d.dsbyte
+		block[d.rate-1] = 0x80
+	}
+	return d.squeeze(d.absorb(d.a, block))
+}
+
+// SumVariableLength returns the bytes of the digest of the data written so far
+// followed by data[:length], where length is only known at solving time and
+// must be in [0, len(data)]. The bytes of data beyond length are ignored. The
+// running state of the Digest is not modified.
+//
+// The cost is the one of hashing len(data) bytes, as all the blocks which may
+// be absorbed are permuted, plus a few constraints per byte of data.
+func (d *Digest) SumVariableLength(data []frontend.Variable, length frontend.Variable) []frontend.Variable {
+	api := d.api
+	m := append(append([]frontend.Variable(nil), d.buf...), data...)
+	n := api.Add(length, len(d.buf))
+	nbBlocks := len(m)/d.rate + 1
+
+	// eq[p] = (p == n), constrained by eq[p] * (n - p) == 0 and Σeq == 1
+	// such that n is in [len(d.buf), len(m)]
+	eq, err := api.Compiler().NewHint(multiplexer.Indicators, len(m)+1, n)
+	if err != nil {
+		panic(err)
+	}
+	var Σeq frontend.Variable = 0
+	for p := range eq {
+		api.AssertIsEqual(api.Mul(eq[p], api.Sub(n, p)), 0)
+		Σeq = api.Add(Σeq, eq[p])
+	}
+	api.AssertIsEqual(Σeq, 1)
+	for i := 0; i < len(d.buf); i++ {
+		api.AssertIsEqual(eq[i], 0)
+	}
+
+	// padding: dsbyte at n, zeroes, last byte of the final block is or-ed
+	// with 0x80. The final block is the one holding the position n.
+	a := d.a
+	var Σeqp frontend.Variable = 0 // Σeq[q] for q < p, that is p > n
+	candidates := make([][]frontend.Variable, nbBlocks)
+	isFinal := make([]frontend.Variable, nbBlocks)
+	for b := 0; b < nbBlocks; b++ {
+		block := make([]frontend.Variable, d.rate)
+		isFinal[b] = 0
+		for i := range block {
+			p := b*d.rate + i
+			block[i] = 0
+			if p < len(eq) {
+				isFinal[b] = api.Add(isFinal[b], eq[p])
+				if p < len(m) {
+					// m[p] if p < n
+					block[i] = api.Mul(m[p], api.Sub(1, api.Add(Σeqp, eq[p])))
+				}
+				block[i] = api.Add(block[i], api.Mul(eq[p], int(d.dsbyte)))
+				Σeqp = api.Add(Σeqp, eq[p])
+			}
+		}
+		block[d.rate-1] = api.Add(block[d.rate-1], api.Mul(isFinal[b], 0x80))
+		a = d.absorb(a, block)
+		candidates[b] = d.squeeze(a)
+	}
+
+	res := make([]frontend.Variable, d.outputLen)
+	for i := range res {
+		res[i] = 0
+		for b := range candidates {
+			res[i] = api.Add(res[i], api.Mul(isFinal[b], candidates[b][i]))
+		}
+	}
+	return res
+}
+
+// squeeze returns the bytes of the digest from the state a. The output length
+// of the supported instances is lower than the rate, so no additional
+// permutation is needed.
+func (d *Digest) squeeze(a State) []frontend.Variable {
+	res := make([]frontend.Variable, d.outputLen)
+	for i := range res {
+		lane := a[i/8][8*(i%8) : 8*(i%8)+8]
+		res[i] = bits.FromBinary(d.api, lane, bits.WithUnconstrainedInputs())
+	}
+	return res
+}
+
+// absorb xors the block of rate bytes into the state a and applies the
+// permutation.
+func (d *Digest) absorb(a State, block []frontend.Variable) State {
+	for i := range block {
+		b := bits.ToBinary(d.api, block[i], bits.WithNbDigits(8))
+		lane := &a[i/8]
+		for j := range b {
+			lane[8*(i%8)+j] = xor(d.api, lane[8*(i%8)+j], b[j])
+		}
+	}
+	return Permute(d.api, a)
+}
@@ -0,0 +1,138 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keccak
+
+import (
+	gohash "hash"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+	"github.com/consensys/gnark/test"
+	"golang.org/x/crypto/sha3"
+)
+
+type keccakCircuit struct {
+	ExpectedResult [32]frontend.Variable `gnark:"data,public"`
+	Data           []frontend.Variable
+
+	sha3 bool
+}
+
+func (circuit *keccakCircuit) Define(api frontend.API) error {
+	var h hash.BinaryHasher
+	if circuit.sha3 {
+		h = New256(api)
+	} else {
+		h = NewLegacyKeccak256(api)
+	}
+	h.Write(circuit.Data...)
+	result := h.Sum()
+	for i := range result {
+		api.AssertIsEqual(result[i], circuit.ExpectedResult[i])
+	}
+	return nil
+}
+
+func TestKeccak(t *testing.T) {
+	hashes := map[string]struct {
+		sha3   bool
+		native func() gohash.Hash
+	}{
+		"keccak256": {false, sha3.NewLegacyKeccak256},
+		"sha3-256":  {true, sha3.New256},
+	}
+
+	for name, h := range hashes {
+		// lengths chosen to cover an empty message, a single padding byte and
+		// several blocks.
+		for _, n := range []int{0, 135, 140} {
+			data := make([]byte, n)
+			for i := range data {
+				data[i] = byte(i*7 + 3)
+			}
+			native := h.native()
+			native.Write(data)
+			expected := native.Sum(nil)
+
+			circuit := keccakCircuit{Data: make([]frontend.Variable, n), sha3: h.sha3}
+			witness := keccakCircuit{Data: make([]frontend.Variable, n)}
+			for i := range data {
+				witness.Data[i] = data[i]
+			}
+			for i := range expected {
+				witness.ExpectedResult[i] = expected[i]
+			}
+
+			t.Run(name, func(t *testing.T) {
+				assert := test.NewAssert(t)
+				assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+
+				wrongWitness := witness
+				wrongWitness.ExpectedResult[31] = expected[31] ^ 0x80
+				assert.SolvingFailed(&circuit, &wrongWitness, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+			})
+		}
+	}
+}
+
+type keccakVariableLengthCircuit struct {
+	ExpectedResult [32]frontend.Variable `gnark:"data,public"`
+	Data           [140]frontend.Variable
+	Length         frontend.Variable
+}
+
+func (circuit *keccakVariableLengthCircuit) Define(api frontend.API) error {
+	h := NewLegacyKeccak256(api).(*Digest)
+	h.Write(circuit.Data[:3]...)
+	result := h.SumVariableLength(circuit.Data[3:], circuit.Length)
+	for i := range result {
+		api.AssertIsEqual(result[i], circuit.ExpectedResult[i])
+	}
+	return nil
+}
+
+func TestKeccakVariableLength(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	var data [140]byte
+	for i := range data {
+		data[i] = byte(i*7 + 3)
+	}
+	// lengths chosen to cover an empty message, a single padding byte, a
+	// full block and the maximum length.
+	for _, n := range []int{0, 132, 133, 137} {
+		native := sha3.NewLegacyKeccak256()
+		native.Write(data[:3+n])
+		expected := native.Sum(nil)
+
+		var witness keccakVariableLengthCircuit
+		for i := range data {
+			witness.Data[i] = data[i]
+		}
+		for i := range expected {
+			witness.ExpectedResult[i] = expected[i]
+		}
+		witness.Length = n
+		assert.SolvingSucceeded(&keccakVariableLengthCircuit{}, &witness, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+
+		witness.Length = n - 1
+		assert.SolvingFailed(&keccakVariableLengthCircuit{}, &witness, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keccak
+
+import (
+	"github.com/consensys/gnark/frontend"
+)
+
+// rc stores the round constants of the ι step.
+var rc = [24]uint64{
+	0x0000000000000001, 0x0000000000008082, 0x800000000000808A, 0x8000000080008000,
+	0x000000000000808B, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+	0x000000000000008A, 0x0000000000000088, 0x0000000080008009, 0x000000008000000A,
+	0x000000008000808B, 0x800000000000008B, 0x8000000000008089, 0x8000000000008003,
+	0x8000000000008002, 0x8000000000000080, 0x000000000000800A, 0x800000008000000A,
+	0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+}
+
+// rotc stores the rotation offsets of the ρ step, indexed by x+5y.
+var rotc = [25]int{
+	0, 1, 62, 28, 27,
+	36, 44, 6, 55, 20,
+	3, 10, 43, 25, 39,
+	41, 45, 15, 21, 8,
+	18, 2, 61, 56, 14,
+}
+
+// Lane is a 64-bit lane of the Keccak state, stored as little-endian bits.
+// Each bit is either a constant or a variable constrained to be boolean.
+type Lane [64]frontend.Variable
+
+// State is the Keccak-f[1600] state. The lane at coordinates (x, y) is stored
+// at index x+5y.
+type State [25]Lane
+
+// NewState returns the all-zero Keccak state.
+func NewState() State {
+	var s State
+	for i := range s {
+		for j := range s[i] {
+			s[i][j] = 0
+		}
+	}
+	return s
+}
+
+// Permute applies the Keccak-f[1600] permutation on a and returns the result.
+// The bits of a must be boolean, this is not checked.
+func Permute(api frontend.API, a State) State {
+	for r := 0; r < 24; r++ {
+		// θ step
+		var c [5]Lane
+		for x := 0; x < 5; x++ {
+			c[x] = xorLanes(api, a[x], a[x+5], a[x+10], a[x+15], a[x+20])
+		}
+		for x := 0; x < 5; x++ {
+			d := xorLanes(api, c[(x+4)%5], rotl(c[(x+1)%5], 1))
+			for y := 0; y < 5; y++ {
+				a[x+5*y] = xorLanes(api, a[x+5*y], d)
+			}
+		}
+
+		// ρ and π steps
+		var b State
+		for x := 0; x < 5; x++ {
+			for y := 0; y < 5; y++ {
+				b[y+5*((2*x+3*y)%5)] = rotl(a[x+5*y], rotc[x+5*y])
+			}
+		}
+
+		// χ step
+		for x := 0; x < 5; x++ {
+			for y := 0; y < 5; y++ {
+				a[x+5*y] = chi(api, b[x+5*y], b[(x+1)%5+5*y], b[(x+2)%5+5*y])
+			}
+		}
+
+		// ι step
+		for i := 0; i < 64; i++ {
+			if (rc[r]>>i)&1 == 1 {
+				a[0][i] = api.Sub(1, a[0][i])
+			}
+		}
+	}
+	return a
+}
+
+// rotl returns the lane x rotated left by n bits.
+func rotl(x Lane, n int) Lane {
+	var res Lane
+	for i := range res {
+		res[i] = x[(i+64-n)%64]
+	}
+	return res
+}
+
+// xorLanes returns the bitwise xor of the given lanes.
+func xorLanes(api frontend.API, lanes ...Lane) Lane {
+	res := lanes[0]
+	for _, l := range lanes[1:] {
+		for i := range res {
+			res[i] = xor(api, res[i], l[i])
+		}
+	}
+	return res
+}
+
+// chi returns a ^ (^b & c).
+func chi(api frontend.API, a, b, c Lane) Lane {
+	var res Lane
+	for i := range res {
+		// ^b & c = c - bc
+		nbc := api.Sub(c[i], api.Mul(b[i], c[i]))
+		res[i] = xor(api, a[i], nbc)
+	}
+	return res
+}
+
+// xor returns a ^ b = (a - b)² for boolean a and b.
+//
+// Contrary to a + b - 2ab, the result is a single new variable (or a constant);
+// this keeps the linear expressions short across the rounds of the permutation.
+func xor(api frontend.API, a, b frontend.Variable) frontend.Variable {
+	if _, ok := api.Compiler().ConstantValue(a); ok {
+		a, b = b, a
+	}
+	if c, ok := api.Compiler().ConstantValue(b); ok {
+		if c.Sign() == 0 {
+			return a
+		}
+		return api.Sub(1, a)
+	}
+	d := api.Sub(a, b)
+	return api.Mul(d, d)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poseidon
+
+import "math/big"
+
+// grain is the Grain LFSR in self-shrinking mode used by the reference
+// implementation of Poseidon to derive the round constants and the MDS matrix.
+type grain struct {
+	state []byte
+}
+
+func newGrain(nbBits, t, rf, rp int) *grain {
+	var init []byte
+	push := func(v, width int) {
+		for i := width - 1; i >= 0; i-- {
+			init = append(init, byte(v>>i)&1)
+		}
+	}
+	push(1, 2) // prime field
+	push(0, 4) // x^α S-box
+	push(nbBits, 12)
+	push(t, 12)
+	push(rf, 10)
+	push(rp, 10)
+	push(1<<30-1, 30)
+
+	g := &grain{state: init}
+	for i := 0; i < 160; i++ {
+		g.next()
+	}
+	return g
+}
+
+// next updates the LFSR and returns the new bit.
+func (g *grain) next() byte {
+	s := g.state
+	b := s[62] ^ s[51] ^ s[38] ^ s[23] ^ s[13] ^ s[0]
+	g.state = append(s[1:], b)
+	return b
+}
+
+// bit returns the next bit of the self-shrinking generator.
+func (g *grain) bit() byte {
+	for {
+		if g.next() == 1 {
+			return g.next()
+		}
+		g.next()
+	}
+}
+
+// bigInt returns a nbBits integer built from the next bits, most significant
+// bit first.
+func (g *grain) bigInt(nbBits int) *big.Int {
+	r := new(big.Int)
+	for i := 0; i < nbBits; i++ {
+		r.Lsh(r, 1)
+		r.SetBit(r, 0, uint(g.bit()))
+	}
+	return r
+}
+
+// fieldElement returns the next integer smaller than modulus, using rejection
+// sampling.
+func (g *grain) fieldElement(modulus *big.Int) *big.Int {
+	for {
+		r := g.bigInt(modulus.BitLen())
+		if r.Cmp(modulus) < 0 {
+			return r
+		}
+	}
+}
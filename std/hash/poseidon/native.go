@@ -0,0 +1,169 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poseidon
+
+import (
+	"fmt"
+	"hash"
+	"math/big"
+)
+
+// Permute applies the Poseidon permutation on state, in place, outside of a
+// circuit. len(state) must be p.T.
+func (p *Parameters) Permute(state []*big.Int) {
+	if len(state) != p.T {
+		panic("invalid state size")
+	}
+	alpha := big.NewInt(int64(p.Alpha))
+	tmp := make([]*big.Int, p.T)
+	for i := range tmp {
+		tmp[i] = new(big.Int)
+	}
+	var t big.Int
+
+	for r := 0; r < p.RF+p.RP; r++ {
+		for i := range state {
+			state[i].Add(state[i], p.RoundConstants[r*p.T+i]).Mod(state[i], p.Modulus)
+		}
+		if p.isFullRound(r) {
+			for i := range state {
+				state[i].Exp(state[i], alpha, p.Modulus)
+			}
+		} else {
+			state[0].Exp(state[0], alpha, p.Modulus)
+		}
+		for i := range tmp {
+			tmp[i].SetUint64(0)
+			for j := range state {
+				tmp[i].Add(tmp[i], t.Mul(p.MDS[i][j], state[j]))
+			}
+		}
+		for i := range state {
+			state[i].Mod(tmp[i], p.Modulus)
+		}
+	}
+}
+
+// Hash returns the Poseidon hash of data, outside of a circuit. It matches the
+// result of the gadget for the same parameters: see Poseidon.Sum. With
+// p.T = len(data)+1 on BN254, it matches the Poseidon hash of circomlib.
+func (p *Parameters) Hash(data ...*big.Int) *big.Int {
+	return p.hash(new(big.Int), data)
+}
+
+// HashWithLength returns the Poseidon hash of data, outside of a circuit, with
+// the number of elements hashed added to the capacity element. It matches the
+// result of the gadget created with NewPoseidonWithLength.
+func (p *Parameters) HashWithLength(data ...*big.Int) *big.Int {
+	return p.hash(new(big.Int).SetUint64(uint64(len(data))), data)
+}
+
+// hash absorbs data into a sponge whose capacity element is initialized with
+// capacity, and returns the element at index 0 of the state. The permutation is
+// applied at least once, on the capacity alone if data is empty.
+func (p *Parameters) hash(capacity *big.Int, data []*big.Int) *big.Int {
+	state := make([]*big.Int, p.T)
+	state[0] = capacity
+	for i := 1; i < len(state); i++ {
+		state[i] = new(big.Int)
+	}
+	rate := p.T - 1
+	for {
+		n := rate
+		if len(data) < n {
+			n = len(data)
+		}
+		for i := 0; i < n; i++ {
+			state[1+i].Add(state[1+i], data[i]).Mod(state[1+i], p.Modulus)
+		}
+		p.Permute(state)
+		data = data[n:]
+		if len(data) == 0 {
+			return state[0]
+		}
+	}
+}
+
+// isFullRound returns true if the round r applies the S-box on the full state.
+func (p *Parameters) isFullRound(r int) bool {
+	return r < p.RF/2 || r >= p.RF/2+p.RP
+}
+
+// NewHasher returns a hash.Hash computing the Poseidon hash of field elements
+// outside of a circuit.
+//
+// The data written must be the big endian encodings of field elements, of
+// Size() bytes each and lower than the field modulus: Write returns an error,
+// and doesn't write anything, if a call writes a partial or a non-canonical
+// element. The digest is the big endian encoding on Size() bytes of
+// HashWithLength of the elements written, so that it matches the gadget
+// created with NewPoseidonWithLength, and the data of different lengths don't
+// collide.
+//
+// As the transcripts of the fiat-shamir package of gnark-crypto write the names
+// of the challenges byte by byte, they can't be computed with this hasher.
+func (p *Parameters) NewHasher() hash.Hash {
+	return &hasher{params: p, size: (p.Modulus.BitLen() + 7) / 8}
+}
+
+type hasher struct {
+	params *Parameters
+	size   int
+	data   []*big.Int
+}
+
+// Write adds the field elements encoded in p to the running hash. It returns an
+// error if len(p) is not a multiple of Size(), or if an element is not lower
+// than the modulus.
+func (h *hasher) Write(p []byte) (int, error) {
+	if len(p)%h.size != 0 {
+		return 0, fmt.Errorf("poseidon: %d bytes written, not a multiple of the %d bytes of a field element", len(p), h.size)
+	}
+	elements := make([]*big.Int, 0, len(p)/h.size)
+	for i := 0; i < len(p); i += h.size {
+		e := new(big.Int).SetBytes(p[i : i+h.size])
+		if e.Cmp(h.params.Modulus) != -1 {
+			return 0, fmt.Errorf("poseidon: element %d is not lower than the field modulus", i/h.size)
+		}
+		elements = append(elements, e)
+	}
+	h.data = append(h.data, elements...)
+	return len(p), nil
+}
+
+// Sum appends the digest of the data written so far to b. It does not change
+// the running hash.
+func (h *hasher) Sum(b []byte) []byte {
+	digest := make([]byte, h.size)
+	h.params.HashWithLength(h.data...).FillBytes(digest)
+	return append(b, digest...)
+}
+
+// Reset resets the hash to its initial state.
+func (h *hasher) Reset() {
+	h.data = nil
+}
+
+// Size returns the number of bytes Sum appends.
+func (h *hasher) Size() int {
+	return h.size
+}
+
+// BlockSize returns the number of bytes of a field element.
+func (h *hasher) BlockSize() int {
+	return h.size
+}
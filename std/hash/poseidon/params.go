@@ -0,0 +1,222 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poseidon
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// securityLevel is the targeted security level in bits when computing the
+// number of rounds.
+const securityLevel = 128
+
+// Parameters of a Poseidon permutation over a prime field.
+type Parameters struct {
+	Modulus        *big.Int     // modulus of the field
+	T              int          // width of the permutation, in field elements
+	Alpha          int          // exponent of the S-box x^α
+	RF             int          // number of full rounds, half at the beginning, half at the end
+	RP             int          // number of partial rounds
+	RoundConstants []*big.Int   // (RF + RP) * T round constants
+	MDS            [][]*big.Int // T x T MDS matrix
+}
+
+// roundsBN254 are the numbers of partial rounds used by circomlib for the
+// widths 2 to 17 on the BN254 scalar field. They are used instead of the
+// computed ones to be compatible with circom circuits.
+var roundsBN254 = [...]int{56, 57, 56, 60, 60, 63, 64, 63, 60, 66, 60, 65, 70, 60, 64, 68}
+
+var (
+	paramsCache = make(map[string]*Parameters)
+	paramsM     sync.Mutex
+)
+
+// GetParameters returns the Poseidon parameters of width t for the scalar
+// field of the given curve. The parameters are generated on first use and
+// cached.
+//
+// On BN254, the parameters are the ones used by circomlib.
+func GetParameters(curveID ecc.ID, t int) (*Parameters, error) {
+	key := fmt.Sprintf("%s/%d", curveID.String(), t)
+
+	paramsM.Lock()
+	defer paramsM.Unlock()
+	if p, ok := paramsCache[key]; ok {
+		return p, nil
+	}
+
+	if curveID == ecc.UNKNOWN {
+		return nil, errors.New("unknown curve id")
+	}
+	modulus := curveID.Info().Fr.Modulus()
+	alpha, err := SBoxExponent(modulus)
+	if err != nil {
+		return nil, err
+	}
+	rf, rp := RoundNumbers(modulus, t, alpha)
+	if curveID == ecc.BN254 && t >= 2 && t-2 < len(roundsBN254) {
+		rp = roundsBN254[t-2]
+	}
+
+	p, err := GenerateParameters(modulus, t, alpha, rf, rp)
+	if err != nil {
+		return nil, err
+	}
+	paramsCache[key] = p
+	return p, nil
+}
+
+// SBoxExponent returns the smallest α ⩾ 3 such that x^α is a permutation of the
+// field, that is gcd(α, modulus - 1) = 1.
+func SBoxExponent(modulus *big.Int) (int, error) {
+	pMinusOne := new(big.Int).Sub(modulus, big.NewInt(1))
+	var gcd big.Int
+	for alpha := int64(3); alpha < 256; alpha++ {
+		if gcd.GCD(nil, nil, big.NewInt(alpha), pMinusOne).IsInt64() && gcd.Int64() == 1 {
+			return int(alpha), nil
+		}
+	}
+	return 0, errors.New("no suitable S-box exponent")
+}
+
+// RoundNumbers returns the number of full and partial rounds needed to reach
+// 128 bits of security for a permutation of width t and S-box x^α on the
+// given field.
+//
+// It follows the script calc_round_numbers.py of the reference implementation:
+// it finds the cheapest (in number of S-boxes) parameters resisting the
+// statistical, interpolation and Gröbner basis attacks, then adds 2 full rounds
+// and 7.5% partial rounds as security margin.
+func RoundNumbers(modulus *big.Int, t, alpha int) (rf, rp int) {
+	minCost := math.MaxInt64
+	for rpt := 1; rpt < 500; rpt++ {
+		for rft := 4; rft < 100; rft += 2 {
+			if !isSecure(modulus, t, rft, rpt, alpha) {
+				continue
+			}
+			rfm, rpm := rft+2, int(math.Ceil(float64(rpt)*1.075))
+			if cost := t*rfm + rpm; cost < minCost || (cost == minCost && rfm < rf) {
+				rf, rp, minCost = rfm, rpm, cost
+			}
+			break
+		}
+	}
+	return
+}
+
+func isSecure(modulus *big.Int, t, rf, rp, alpha int) bool {
+	log := func(x, base float64) float64 { return math.Log(x) / math.Log(base) }
+	m := float64(securityLevel)
+	n := float64(modulus.BitLen())
+	fp, _ := new(big.Float).SetInt(modulus).Float64()
+	log2p := math.Log2(fp)
+	a, tf, rpf := float64(alpha), float64(t), float64(rp)
+
+	// statistical
+	r1 := 10.0
+	if m <= math.Floor(log2p-(a-1)/2)*(tf+1) {
+		r1 = 6
+	}
+	// interpolation
+	r2 := 1 + math.Ceil(log(2, a)*math.Min(m, n)) + math.Ceil(log(tf, a)) - rpf
+	// Gröbner basis
+	r3 := log(2, a)*math.Min(m, log2p) - rpf
+	r4 := tf - 1 + log(2, a)*math.Min(m/(tf+1), log2p/2) - rpf
+	r5 := (tf - 2 + m/(2*log(a, 2)) - rpf) / (tf - 1)
+
+	rfMin := math.Ceil(r1)
+	for _, r := range []float64{r2, r3, r4, r5} {
+		rfMin = math.Max(rfMin, math.Ceil(r))
+	}
+	return float64(rf) >= rfMin
+}
+
+// GenerateParameters generates the round constants and the MDS matrix of a
+// Poseidon permutation of width t, with S-box x^α, rf full rounds and rp
+// partial rounds over the field of given modulus.
+//
+// The constants are sampled with the Grain LFSR seeded with the parameters, as
+// in the reference implementation. The MDS matrix is the Cauchy matrix
+// M[i][j] = 1 / (x[i] + y[j]) where the x[i] and y[j] are sampled with the same
+// LFSR. Note that, contrary to the reference implementation, the matrix is not
+// tested against infinitely long invariant subspace trails.
+func GenerateParameters(modulus *big.Int, t, alpha, rf, rp int) (*Parameters, error) {
+	if t < 2 {
+		return nil, errors.New("width must be at least 2")
+	}
+	if rf%2 != 0 {
+		return nil, errors.New("number of full rounds must be even")
+	}
+
+	p := &Parameters{
+		Modulus: new(big.Int).Set(modulus),
+		T:       t,
+		Alpha:   alpha,
+		RF:      rf,
+		RP:      rp,
+	}
+	nbBits := modulus.BitLen()
+	g := newGrain(nbBits, t, rf, rp)
+
+	p.RoundConstants = make([]*big.Int, (rf+rp)*t)
+	for i := range p.RoundConstants {
+		p.RoundConstants[i] = g.fieldElement(modulus)
+	}
+
+	p.MDS = make([][]*big.Int, t)
+	for i := range p.MDS {
+		p.MDS[i] = make([]*big.Int, t)
+	}
+	for {
+		// sample 2t distinct elements
+		xy := make([]*big.Int, 2*t)
+		for distinct := false; !distinct; {
+			distinct = true
+			for i := range xy {
+				xy[i] = g.bigInt(nbBits)
+				xy[i].Mod(xy[i], modulus)
+			}
+			for i := range xy {
+				for j := 0; j < i; j++ {
+					distinct = distinct && xy[i].Cmp(xy[j]) != 0
+				}
+			}
+		}
+
+		ok := true
+		for i := 0; i < t && ok; i++ {
+			for j := 0; j < t && ok; j++ {
+				e := new(big.Int).Add(xy[i], xy[t+j])
+				e.Mod(e, modulus)
+				if e.Sign() == 0 {
+					ok = false
+				} else {
+					p.MDS[i][j] = e.ModInverse(e, modulus)
+				}
+			}
+		}
+		if ok {
+			return p, nil
+		}
+	}
+}
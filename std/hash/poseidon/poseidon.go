@@ -0,0 +1,171 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package poseidon provides a ZKP-circuit function to compute a Poseidon hash,
+// along with the parameter generation and the matching out-of-circuit
+// implementation.
+//
+// The hash is a sponge over a permutation of width t, with a capacity of one
+// field element stored at index 0 of the state and a rate of t-1 elements. The
+// data is absorbed t-1 elements at a time (the last chunk being implicitly
+// padded with zeroes), and the digest is the element at index 0 of the state.
+// With t = len(data)+1, this matches the Poseidon hash of circomlib.
+//
+// Since the padding is implicit, hash(x) and hash(x, 0) are equal. When the
+// number of elements hashed is not fixed by the circuit, use
+// NewPoseidonWithLength (and Parameters.HashWithLength outside of a circuit),
+// which adds the number of elements hashed to the capacity element before
+// absorbing them. The digests then differ from the ones of circomlib.
+package poseidon
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// DefaultWidth is the width of the permutation used by NewPoseidon.
+const DefaultWidth = 3
+
+// Poseidon contains the parameters of the Poseidon hash function and the state
+// of the sponge.
+type Poseidon struct {
+	params     *Parameters
+	state      []frontend.Variable // current state of the sponge
+	data       []frontend.Variable // data not yet absorbed. data is updated when Write() is called. Sum absorbs the data.
+	api        frontend.API        // underlying constraint system
+	withLength bool                // if set, Sum adds the number of elements absorbed to the capacity element
+}
+
+// NewPoseidon returns a Poseidon instance of width DefaultWidth, than can be
+// used in a gnark circuit.
+func NewPoseidon(api frontend.API) (Poseidon, error) {
+	return NewPoseidonWithWidth(api, DefaultWidth)
+}
+
+// NewPoseidonWithWidth returns a Poseidon instance of width t, than can be used
+// in a gnark circuit.
+func NewPoseidonWithWidth(api frontend.API, t int) (Poseidon, error) {
+	params, err := GetParameters(api.Compiler().Curve(), t)
+	if err != nil {
+		return Poseidon{}, err
+	}
+	h := Poseidon{params: params, api: api}
+	h.Reset()
+	return h, nil
+}
+
+// NewPoseidonWithLength returns a Poseidon instance of width t, than can be
+// used in a gnark circuit, which separates the inputs of different lengths: Sum
+// adds the number of elements written to the capacity element before absorbing
+// them, so that the implicit zero padding is unambiguous. The digests differ
+// from the ones of circomlib; they match Parameters.HashWithLength.
+func NewPoseidonWithLength(api frontend.API, t int) (Poseidon, error) {
+	h, err := NewPoseidonWithWidth(api, t)
+	if err != nil {
+		return Poseidon{}, err
+	}
+	h.withLength = true
+	return h, nil
+}
+
+// Write adds more data to the running hash.
+func (h *Poseidon) Write(data ...frontend.Variable) {
+	h.data = append(h.data, data...)
+}
+
+// Reset resets the Hash to its initial state.
+func (h *Poseidon) Reset() {
+	h.data = nil
+	h.state = make([]frontend.Variable, h.params.T)
+	for i := range h.state {
+		h.state[i] = 0
+	}
+}
+
+// Sum absorbs the data written so far into the sponge and returns the element
+// at index 0 of the state. If h was created with NewPoseidonWithLength, the
+// number of elements written is first added to the capacity element. The
+// permutation is applied at least once, even if no data was written.
+func (h *Poseidon) Sum() frontend.Variable {
+	rate := h.params.T - 1
+	if h.withLength {
+		h.state[0] = h.api.Add(h.state[0], len(h.data))
+	}
+	for {
+		n := rate
+		if len(h.data) < n {
+			n = len(h.data)
+		}
+		for i := 0; i < n; i++ {
+			h.state[1+i] = h.api.Add(h.state[1+i], h.data[i])
+		}
+		h.state = Permute(h.api, h.params, h.state)
+		h.data = h.data[n:]
+		if len(h.data) == 0 {
+			break
+		}
+	}
+
+	h.data = nil // flush the data already hashed
+
+	return h.state[0]
+}
+
+// Permute applies the Poseidon permutation on state and returns the result.
+// len(state) must be params.T.
+func Permute(api frontend.API, params *Parameters, state []frontend.Variable) []frontend.Variable {
+	if len(state) != params.T {
+		panic("invalid state size")
+	}
+	res := make([]frontend.Variable, params.T)
+	copy(res, state)
+	tmp := make([]frontend.Variable, params.T)
+
+	for r := 0; r < params.RF+params.RP; r++ {
+		for i := range res {
+			res[i] = api.Add(res[i], params.RoundConstants[r*params.T+i])
+		}
+		if params.isFullRound(r) {
+			for i := range res {
+				res[i] = sBox(api, res[i], params.Alpha)
+			}
+		} else {
+			res[0] = sBox(api, res[0], params.Alpha)
+		}
+		for i := range tmp {
+			tmp[i] = 0
+			for j := range res {
+				tmp[i] = api.Add(tmp[i], api.Mul(params.MDS[i][j], res[j]))
+			}
+		}
+		res, tmp = tmp, res
+	}
+	return res
+}
+
+// sBox returns x^α.
+func sBox(api frontend.API, x frontend.Variable, alpha int) frontend.Variable {
+	e := big.NewInt(int64(alpha))
+	var res frontend.Variable = 1
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		res = api.Mul(res, res)
+		if e.Bit(i) == 1 {
+			res = api.Mul(res, x)
+		}
+	}
+	return res
+}
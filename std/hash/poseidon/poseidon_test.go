@@ -0,0 +1,225 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poseidon
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+func TestCircomlibCompatibility(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	params, err := GetParameters(ecc.BN254, 3)
+	assert.NoError(err)
+	assert.Equal(5, params.Alpha)
+	assert.Equal(8, params.RF)
+	assert.Equal(57, params.RP)
+
+	// digests from circomlibjs, with t = len(inputs)+1
+	vectors := []struct {
+		inputs   []int64
+		expected string
+	}{
+		{[]int64{1}, "29176100eaa962bdc1fe6c654d6a3c130e96a4d1168b33848b897dc502820133"},
+		{[]int64{1, 2}, "115cc0f5e7d690413df64c6b9662e9cf2a3617f2743245519e19607a4417189a"},
+		{[]int64{1, 2, 3, 4}, "299c867db6c1fdd79dcefa40e4510b9837e60ebb1ce0663dbaa525df65250465"},
+	}
+	for _, v := range vectors {
+		params, err := GetParameters(ecc.BN254, len(v.inputs)+1)
+		assert.NoError(err)
+		expected, _ := new(big.Int).SetString(v.expected, 16)
+
+		inputs := make([]*big.Int, len(v.inputs))
+		witness := circomlibCircuit{Data: make([]frontend.Variable, len(v.inputs)), ExpectedResult: expected}
+		for i := range v.inputs {
+			inputs[i] = big.NewInt(v.inputs[i])
+			witness.Data[i] = v.inputs[i]
+		}
+		assert.Equal(expected.String(), params.Hash(inputs...).String())
+
+		circuit := circomlibCircuit{Data: make([]frontend.Variable, len(v.inputs))}
+		assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+	}
+}
+
+type circomlibCircuit struct {
+	ExpectedResult frontend.Variable `gnark:"data,public"`
+	Data           []frontend.Variable
+}
+
+func (circuit *circomlibCircuit) Define(api frontend.API) error {
+	poseidon, err := NewPoseidonWithWidth(api, len(circuit.Data)+1)
+	if err != nil {
+		return err
+	}
+	poseidon.Write(circuit.Data...)
+	api.AssertIsEqual(poseidon.Sum(), circuit.ExpectedResult)
+	return nil
+}
+
+func TestLengthSeparation(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	params, err := GetParameters(ecc.BN254, DefaultWidth)
+	assert.NoError(err)
+
+	x := big.NewInt(42)
+	assert.Equal(params.Hash(x).String(), params.Hash(x, big.NewInt(0)).String())
+	assert.NotEqual(params.HashWithLength(x).String(), params.HashWithLength(x, big.NewInt(0)).String())
+	assert.NotEqual(params.HashWithLength().String(), params.HashWithLength(big.NewInt(0)).String())
+
+	// the gadget must not accept the digest of the zero-padded data
+	var circuit poseidonPaddingCircuit
+	assert.SolvingSucceeded(&circuit, &poseidonPaddingCircuit{X: x, ExpectedResult: params.HashWithLength(x)}, test.WithCurves(ecc.BN254))
+	assert.SolvingFailed(&circuit, &poseidonPaddingCircuit{X: x, ExpectedResult: params.HashWithLength(x, big.NewInt(0))}, test.WithCurves(ecc.BN254))
+	assert.SolvingFailed(&circuit, &poseidonPaddingCircuit{X: x, ExpectedResult: params.Hash(x)}, test.WithCurves(ecc.BN254))
+}
+
+func TestHasher(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	params, err := GetParameters(ecc.BN254, DefaultWidth)
+	assert.NoError(err)
+	h := params.NewHasher()
+	digest := func(data ...[]byte) []byte {
+		h.Reset()
+		for _, d := range data {
+			_, err := h.Write(d)
+			assert.NoError(err)
+		}
+		return h.Sum(nil)
+	}
+	element := func(v *big.Int) []byte {
+		return v.FillBytes(make([]byte, h.BlockSize()))
+	}
+	one, zero := element(big.NewInt(1)), element(big.NewInt(0))
+	assert.Equal(params.HashWithLength(big.NewInt(1)).Bytes(), new(big.Int).SetBytes(digest(one)).Bytes())
+
+	// partial and non-canonical elements are rejected
+	h.Reset()
+	_, err = h.Write([]byte{1})
+	assert.Error(err)
+	_, err = h.Write(append(one, 1))
+	assert.Error(err)
+	_, err = h.Write(element(params.Modulus))
+	assert.Error(err)
+	_, err = h.Write(element(new(big.Int).Add(params.Modulus, big.NewInt(1))))
+	assert.Error(err)
+	sum := h.Sum(nil)
+	assert.Equal(digest(), sum, "the rejected writes must not change the running hash")
+
+	// the inputs of different lengths don't collide, and the empty input is permuted
+	assert.NotEqual(digest(one), digest(one, zero))
+	assert.NotEqual(digest(zero), digest())
+	assert.NotEqual(make([]byte, h.Size()), digest())
+	assert.NotEqual(0, params.Hash().Sign())
+	assert.NotEqual(digest(one), digest(element(big.NewInt(2))))
+
+	// the gadget permutes the empty input as well
+	var circuit poseidonEmptyCircuit
+	assert.SolvingSucceeded(&circuit, &poseidonEmptyCircuit{ExpectedResult: new(big.Int).SetBytes(digest())}, test.WithCurves(ecc.BN254))
+	assert.SolvingFailed(&circuit, &poseidonEmptyCircuit{ExpectedResult: 0}, test.WithCurves(ecc.BN254))
+}
+
+type poseidonEmptyCircuit struct {
+	ExpectedResult frontend.Variable `gnark:"data,public"`
+}
+
+func (circuit *poseidonEmptyCircuit) Define(api frontend.API) error {
+	poseidon, err := NewPoseidonWithLength(api, DefaultWidth)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(poseidon.Sum(), circuit.ExpectedResult)
+	return nil
+}
+
+type poseidonPaddingCircuit struct {
+	ExpectedResult frontend.Variable `gnark:"data,public"`
+	X              frontend.Variable
+}
+
+func (circuit *poseidonPaddingCircuit) Define(api frontend.API) error {
+	poseidon, err := NewPoseidonWithLength(api, DefaultWidth)
+	if err != nil {
+		return err
+	}
+	poseidon.Write(circuit.X)
+	api.AssertIsEqual(poseidon.Sum(), circuit.ExpectedResult)
+	return nil
+}
+
+type poseidonCircuit struct {
+	ExpectedResult frontend.Variable `gnark:"data,public"`
+	Data           [5]frontend.Variable
+}
+
+func (circuit *poseidonCircuit) Define(api frontend.API) error {
+	poseidon, err := NewPoseidon(api)
+	if err != nil {
+		return err
+	}
+	poseidon.Write(circuit.Data[:]...)
+	result := poseidon.Sum()
+	api.AssertIsEqual(result, circuit.ExpectedResult)
+	return nil
+}
+
+func TestPoseidonAll(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, curve := range gnark.Curves() {
+		params, err := GetParameters(curve, DefaultWidth)
+		assert.NoError(err)
+
+		var circuit, witness, wrongWitness poseidonCircuit
+
+		modulus := curve.Info().Fr.Modulus()
+		var data [5]big.Int
+		data[0].Sub(modulus, big.NewInt(1))
+		for i := 1; i < len(data); i++ {
+			data[i].Add(&data[i-1], &data[i-1]).Mod(&data[i], modulus)
+		}
+
+		// running Poseidon (Go)
+		inputs := make([]*big.Int, len(data))
+		for i := range data {
+			inputs[i] = new(big.Int).Set(&data[i])
+		}
+		expected := params.Hash(inputs...)
+
+		// assert correctness against correct witness
+		for i := range data {
+			witness.Data[i] = data[i].String()
+		}
+		witness.ExpectedResult = expected
+		assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(curve))
+
+		// assert failure against wrong witness
+		for i := range data {
+			wrongWitness.Data[i] = data[i].Sub(&data[i], big.NewInt(1)).String()
+		}
+		wrongWitness.ExpectedResult = expected
+		assert.SolvingFailed(&circuit, &wrongWitness, test.WithCurves(curve))
+	}
+}
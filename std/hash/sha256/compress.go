@@ -0,0 +1,151 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sha256
+
+import (
+	stdbits "math/bits"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/bits"
+)
+
+var _K = [64]uint32{
+	0x428a2f98, 0x71374491, 0xb5c0fbcf, 0xe9b5dba5, 0x3956c25b, 0x59f111f1, 0x923f82a4, 0xab1c5ed5,
+	0xd807aa98, 0x12835b01, 0x243185be, 0x550c7dc3, 0x72be5d74, 0x80deb1fe, 0x9bdc06a7, 0xc19bf174,
+	0xe49b69c1, 0xefbe4786, 0x0fc19dc6, 0x240ca1cc, 0x2de92c6f, 0x4a7484aa, 0x5cb0a9dc, 0x76f988da,
+	0x983e5152, 0xa831c66d, 0xb00327c8, 0xbf597fc7, 0xc6e00bf3, 0xd5a79147, 0x06ca6351, 0x14292967,
+	0x27b70a85, 0x2e1b2138, 0x4d2c6dfc, 0x53380d13, 0x650a7354, 0x766a0abb, 0x81c2c92e, 0x92722c85,
+	0xa2bfe8a1, 0xa81a664b, 0xc24b8b70, 0xc76c51a3, 0xd192e819, 0xd6990624, 0xf40e3585, 0x106aa070,
+	0x19a4c116, 0x1e376c08, 0x2748774c, 0x34b0bcb5, 0x391c0cb3, 0x4ed8aa4a, 0x5b9cca4f, 0x682e6ff3,
+	0x748f82ee, 0x78a5636f, 0x84c87814, 0x8cc70208, 0x90befffa, 0xa4506ceb, 0xbef9a3f7, 0xc67178f2,
+}
+
+// word is a 32-bit word, stored as little-endian bits. Each bit is either a
+// constant or a variable constrained to be boolean.
+type word [32]frontend.Variable
+
+func constWord(v uint32) word {
+	var w word
+	for i := range w {
+		w[i] = (v >> i) & 1
+	}
+	return w
+}
+
+// compress applies the SHA-256 compression function on the chaining value h
+// and the message block w.
+func compress(api frontend.API, h [8]word, w [16]word) [8]word {
+	// message schedule
+	var W [64]word
+	copy(W[:], w[:])
+	for t := 16; t < 64; t++ {
+		s0 := xor3(api, rotr(W[t-15], 7), rotr(W[t-15], 18), shr(W[t-15], 3))
+		s1 := xor3(api, rotr(W[t-2], 17), rotr(W[t-2], 19), shr(W[t-2], 10))
+		W[t] = add(api, s1, W[t-7], s0, W[t-16])
+	}
+
+	a, b, c, d, e, f, g, hh := h[0], h[1], h[2], h[3], h[4], h[5], h[6], h[7]
+
+	for t := 0; t < 64; t++ {
+		S1 := xor3(api, rotr(e, 6), rotr(e, 11), rotr(e, 25))
+		ch := choose(api, e, f, g)
+		S0 := xor3(api, rotr(a, 2), rotr(a, 13), rotr(a, 22))
+		maj := majority(api, a, b, c)
+
+		// T1 = hh + S1 + ch + K[t] + W[t] and T2 = S0 + maj are not reduced
+		// separately; we directly compute a = T1 + T2 and e = d + T1.
+		k := constWord(_K[t])
+		newE := add(api, d, hh, S1, ch, k, W[t])
+		newA := add(api, hh, S1, ch, k, W[t], S0, maj)
+		hh, g, f, e = g, f, e, newE
+		d, c, b, a = c, b, a, newA
+	}
+
+	return [8]word{
+		add(api, h[0], a), add(api, h[1], b), add(api, h[2], c), add(api, h[3], d),
+		add(api, h[4], e), add(api, h[5], f), add(api, h[6], g), add(api, h[7], hh),
+	}
+}
+
+// add returns the sum of the words modulo 2**32. The sum is computed in the
+// native field and then decomposed into 32 + ⌈log₂(len(words))⌉ bits.
+func add(api frontend.API, words ...word) word {
+	var sum frontend.Variable = 0
+	for i := range words {
+		sum = api.Add(sum, bits.FromBinary(api, words[i][:], bits.WithUnconstrainedInputs()))
+	}
+	nbCarries := stdbits.Len(uint(len(words) - 1))
+	b := bits.ToBinary(api, sum, bits.WithNbDigits(32+nbCarries))
+	var res word
+	copy(res[:], b[:32])
+	return res
+}
+
+// rotr returns the word x rotated right by n bits.
+func rotr(x word, n int) word {
+	var res word
+	for i := range res {
+		res[i] = x[(i+n)%32]
+	}
+	return res
+}
+
+// shr returns the word x shifted right by n bits.
+func shr(x word, n int) word {
+	var res word
+	for i := range res {
+		if i+n < 32 {
+			res[i] = x[i+n]
+		} else {
+			res[i] = 0
+		}
+	}
+	return res
+}
+
+// xor3 returns x ^ y ^ z.
+func xor3(api frontend.API, x, y, z word) word {
+	var res word
+	for i := range res {
+		res[i] = xor(api, xor(api, x[i], y[i]), z[i])
+	}
+	return res
+}
+
+// xor returns a ^ b = a + b - 2ab for boolean a and b.
+func xor(api frontend.API, a, b frontend.Variable) frontend.Variable {
+	return api.Sub(api.Add(a, b), api.Mul(api.Mul(a, b), 2))
+}
+
+// choose returns (e & f) ^ (^e & g) = g + e(f - g).
+func choose(api frontend.API, e, f, g word) word {
+	var res word
+	for i := range res {
+		res[i] = api.Add(g[i], api.Mul(e[i], api.Sub(f[i], g[i])))
+	}
+	return res
+}
+
+// majority returns (a & b) ^ (a & c) ^ (b & c) = ab + c(a + b - 2ab).
+func majority(api frontend.API, a, b, c word) word {
+	var res word
+	for i := range res {
+		ab := api.Mul(a[i], b[i])
+		res[i] = api.Add(ab, api.Mul(c[i], api.Sub(api.Add(a[i], b[i]), api.Mul(ab, 2))))
+	}
+	return res
+}
@@ -0,0 +1,128 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sha256 provides a ZKP-circuit function to compute a SHA-256 hash
+// (FIPS 180-4).
+//
+// The inputs and the digest are bytes, each byte stored in a
+// frontend.Variable. Input bytes are range checked by the gadget. Internally,
+// 32-bit words are represented as little-endian slices of boolean variables
+// and the modular additions are performed in the native field before being
+// decomposed back into bits.
+package sha256
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+	"github.com/consensys/gnark/std/math/bits"
+)
+
+const (
+	// Size is the size of a SHA-256 digest in bytes.
+	Size = 32
+
+	// BlockSize is the block size of SHA-256 in bytes.
+	BlockSize = 64
+)
+
+var initialState = [8]uint32{
+	0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a,
+	0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19,
+}
+
+// Digest is the in-circuit counterpart of crypto/sha256.
+type Digest struct {
+	h   [8]word             // current chaining value
+	buf []frontend.Variable // bytes not yet compressed (less than BlockSize)
+	len uint64              // number of bytes written
+	api frontend.API        // underlying constraint system
+}
+
+// New returns a new SHA-256 hash gadget, which can be used in a gnark circuit.
+func New(api frontend.API) hash.BinaryHasher {
+	d := &Digest{api: api}
+	d.Reset()
+	return d
+}
+
+// Reset resets the Digest to its initial state.
+func (d *Digest) Reset() {
+	for i := range d.h {
+		d.h[i] = constWord(initialState[i])
+	}
+	d.buf = nil
+	d.len = 0
+}
+
+// Size returns the number of bytes Sum returns.
+func (d *Digest) Size() int {
+	return Size
+}
+
+// Write adds more bytes to the running hash. Full blocks are compressed
+// directly, the remaining bytes are buffered until the next call to Write or
+// Sum.
+func (d *Digest) Write(data ...frontend.Variable) {
+	d.len += uint64(len(data))
+	d.buf = append(d.buf, data...)
+	for len(d.buf) >= BlockSize {
+		d.h = compress(d.api, d.h, d.toBits(d.buf[:BlockSize]))
+		d.buf = d.buf[BlockSize:]
+	}
+}
+
+// Sum pads the buffered data and returns the 32 bytes of the digest. The
+// running state of the Digest is not modified.
+func (d *Digest) Sum() []frontend.Variable {
+	// padding: 0x80, then zeroes, then the length in bits as a big endian uint64
+	block := make([]frontend.Variable, 0, 2*BlockSize)
+	block = append(block, d.buf...)
+	block = append(block, 0x80)
+	for len(block)%BlockSize != BlockSize-8 {
+		block = append(block, 0)
+	}
+	bitLen := d.len << 3
+	for i := 7; i >= 0; i-- {
+		block = append(block, (bitLen>>(8*i))&0xff)
+	}
+
+	h := d.h
+	for len(block) > 0 {
+		h = compress(d.api, h, d.toBits(block[:BlockSize]))
+		block = block[BlockSize:]
+	}
+
+	res := make([]frontend.Variable, 0, Size)
+	for i := range h {
+		// words are big endian, bits are little endian
+		for j := 3; j >= 0; j-- {
+			res = append(res, bits.FromBinary(d.api, h[i][8*j:8*j+8], bits.WithUnconstrainedInputs()))
+		}
+	}
+	return res
+}
+
+// toBits decomposes a block of 64 bytes into 16 big endian words.
+func (d *Digest) toBits(block []frontend.Variable) [16]word {
+	var w [16]word
+	for i := range w {
+		for j := 0; j < 4; j++ {
+			b := bits.ToBinary(d.api, block[4*i+j], bits.WithNbDigits(8))
+			copy(w[i][8*(3-j):], b)
+		}
+	}
+	return w
+}
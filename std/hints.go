@@ -6,7 +6,16 @@ import (
 	"github.com/consensys/gnark/backend/hint"
 	"github.com/consensys/gnark/std/algebra/sw_bls12377"
 	"github.com/consensys/gnark/std/algebra/sw_bls24315"
+	"github.com/consensys/gnark/std/gkr"
 	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/fixedpoint"
+	"github.com/consensys/gnark/std/math/float"
+	"github.com/consensys/gnark/std/math/nonnative"
+	"github.com/consensys/gnark/std/memory"
+	"github.com/consensys/gnark/std/multiplexer"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/consensys/gnark/std/set"
+	"github.com/consensys/gnark/std/signature/bls"
 )
 
 var registerOnce sync.Once
@@ -27,4 +36,19 @@ func registerHints() {
 	hint.Register(bits.NNAF)
 	hint.Register(bits.IthBit)
 	hint.Register(bits.NBits)
+	hint.Register(fixedpoint.EuclideanDiv)
+	hint.Register(gkr.EvaluateInstance)
+	hint.Register(gkr.Prove)
+	hint.Register(float.NbLeadingZeros)
+	hint.Register(multiplexer.Indicators)
+	hint.Register(memory.LoadValue)
+	hint.Register(memory.SortTranscript)
+	hint.Register(rangecheck.Bytes)
+	hint.Register(set.Index)
+	hint.Register(set.Gap)
+	hint.Register(bls.SqrtHint)
+	hint.Register(bls.ParityHint)
+	for _, h := range nonnative.GetHints() {
+		hint.Register(h)
+	}
 }
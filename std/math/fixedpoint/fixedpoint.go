@@ -0,0 +1,236 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fixedpoint provides ZKP-circuit functions for signed fixed-point
+// arithmetic.
+//
+// A fixed-point value x with fracBits fractional bits is represented by the
+// integer x⋅2^fracBits, which must be in [-2^(nbBits-1), 2^(nbBits-1)); negative
+// integers are represented by their opposite modulo the field modulus. Every
+// Value returned by the API is range checked, which costs about nbBits
+// constraints, so that the operations can't silently overflow:
+//   - Add, Sub and Neg are exact;
+//   - Mul rounds the product to the nearest value, half toward +∞;
+//   - Div rounds the quotient toward -∞, and fails if the divisor is zero.
+//
+// The roundings of Mul and Div are computed by a hint, and checked with a
+// Euclidean division constraint and range checks of the remainder.
+package fixedpoint
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+)
+
+func init() {
+	hint.Register(EuclideanDiv)
+}
+
+// Value is a fixed-point value, created by API.Variable or API.Constant.
+type Value struct {
+	v frontend.Variable
+}
+
+// Variable returns the integer x⋅2^fracBits representing the value x
+func (x Value) Variable() frontend.Variable {
+	return x.v
+}
+
+// API provides the fixed-point operations on Values of nbBits bits, with
+// fracBits fractional bits.
+type API struct {
+	api              frontend.API
+	nbBits, fracBits int
+}
+
+// New returns an API for fixed-point values of nbBits bits, of which fracBits
+// are fractional. The products of two values must fit in the field:
+// 2⋅nbBits+2 must be lower than the number of bits of the field modulus.
+func New(api frontend.API, nbBits, fracBits int) (*API, error) {
+	if fracBits <= 0 || fracBits >= nbBits {
+		return nil, fmt.Errorf("invalid number of fractional bits %d for %d bits", fracBits, nbBits)
+	}
+	if 2*nbBits+2 >= api.Compiler().Curve().Info().Fr.Bits {
+		return nil, fmt.Errorf("%d bits values don't fit in the field", nbBits)
+	}
+	return &API{api: api, nbBits: nbBits, fracBits: fracBits}, nil
+}
+
+// Variable returns the Value represented by the integer v = x⋅2^fracBits, and
+// asserts that v is in [-2^(nbBits-1), 2^(nbBits-1)).
+func (f *API) Variable(v frontend.Variable) Value {
+	f.assertIsInRange(v)
+	return Value{v: v}
+}
+
+// Constant returns the Value x, rounded to the nearest multiple of
+// 2^-fracBits. It panics if x is not a finite number in the range of the
+// values.
+func (f *API) Constant(x float64) Value {
+	v := FromFloat(x, f.fracBits)
+	f.assertIsInRange(v)
+	return Value{v: v}
+}
+
+// Add returns x + y
+func (f *API) Add(x, y Value) Value {
+	return f.Variable(f.api.Add(x.v, y.v))
+}
+
+// Sub returns x - y
+func (f *API) Sub(x, y Value) Value {
+	return f.Variable(f.api.Sub(x.v, y.v))
+}
+
+// Neg returns -x
+func (f *API) Neg(x Value) Value {
+	return f.Variable(f.api.Neg(x.v))
+}
+
+// Mul returns x ⋅ y, rounded to the nearest value.
+//
+// If z is the product of the integers representing x and y, the result is
+// q = ⌊(z + 2^(fracBits-1)) / 2^fracBits⌋, checked with
+// z + 2^(fracBits-1) == q⋅2^fracBits + r and r ∈ [0, 2^fracBits).
+func (f *API) Mul(x, y Value) Value {
+	api := f.api
+	z := api.Add(api.Mul(x.v, y.v), f.pow2(f.fracBits-1))
+	q, r := f.euclideanDiv(z, f.pow2(f.fracBits))
+	api.ToBinary(r, f.fracBits)
+	api.AssertIsEqual(api.Add(api.Mul(q, f.pow2(f.fracBits)), r), z)
+	return f.Variable(q)
+}
+
+// Div returns x / y, rounded toward -∞. It fails if y is zero.
+//
+// If a and b are the integers representing x and y, and s is the sign of b,
+// the result is q = ⌊s⋅a⋅2^fracBits / |b|⌋, checked with
+// s⋅a⋅2^fracBits == q⋅|b| + r and r ∈ [0, |b|).
+func (f *API) Div(x, y Value) Value {
+	api := f.api
+	isNeg := f.isNegative(y)
+	num := api.Mul(x.v, f.pow2(f.fracBits))
+	num = api.Select(isNeg, api.Neg(num), num)
+	den := api.Select(isNeg, api.Neg(y.v), y.v)
+
+	q, r := f.euclideanDiv(num, den)
+	api.AssertIsEqual(api.Add(api.Mul(q, den), r), num)
+
+	// r ∈ [0, |b|), which implies b ≠ 0
+	api.ToBinary(r, f.nbBits)
+	api.ToBinary(api.Sub(den, r, 1), f.nbBits)
+
+	return f.Variable(q)
+}
+
+// IsLess returns 1 if x < y, 0 otherwise
+func (f *API) IsLess(x, y Value) frontend.Variable {
+	// x - y + 2^nbBits ∈ (0, 2^(nbBits+1)), and its bit nbBits is set iff x ⩾ y
+	d := f.api.Add(f.api.Sub(x.v, y.v), f.pow2(f.nbBits))
+	return f.api.Sub(1, f.api.ToBinary(d, f.nbBits+1)[f.nbBits])
+}
+
+// AssertIsLessOrEqual fails if x > y
+func (f *API) AssertIsLessOrEqual(x, y Value) {
+	f.api.ToBinary(f.api.Sub(y.v, x.v), f.nbBits)
+}
+
+// AssertIsEqual fails if x != y
+func (f *API) AssertIsEqual(x, y Value) {
+	f.api.AssertIsEqual(x.v, y.v)
+}
+
+// Select returns x if b is 1, y otherwise
+func (f *API) Select(b frontend.Variable, x, y Value) Value {
+	return Value{v: f.api.Select(b, x.v, y.v)}
+}
+
+// isNegative returns 1 if x < 0, 0 otherwise
+func (f *API) isNegative(x Value) frontend.Variable {
+	b := f.api.ToBinary(f.api.Add(x.v, f.pow2(f.nbBits-1)), f.nbBits)
+	return f.api.Sub(1, b[f.nbBits-1])
+}
+
+// assertIsInRange asserts that v + 2^(nbBits-1) ∈ [0, 2^nbBits)
+func (f *API) assertIsInRange(v frontend.Variable) {
+	f.api.ToBinary(f.api.Add(v, f.pow2(f.nbBits-1)), f.nbBits)
+}
+
+// euclideanDiv returns the quotient and remainder of the Euclidean division of
+// the signed integers a and b, computed by a hint
+func (f *API) euclideanDiv(a, b frontend.Variable) (q, r frontend.Variable) {
+	res, err := f.api.Compiler().NewHint(EuclideanDiv, 2, a, b)
+	if err != nil {
+		panic(err)
+	}
+	return res[0], res[1]
+}
+
+func (f *API) pow2(n int) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(n))
+}
+
+// FromFloat returns the integer x⋅2^fracBits representing x, rounded to the
+// nearest integer, to assign a Value in a witness. It panics if x is not
+// finite.
+func FromFloat(x float64, fracBits int) *big.Int {
+	if math.IsInf(x, 0) || math.IsNaN(x) {
+		panic(fmt.Sprintf("fixedpoint: %v is not a finite number", x))
+	}
+	v := new(big.Float).SetFloat64(x)
+	v.SetMantExp(v, fracBits)
+	if v.Sign() >= 0 {
+		v.Add(v, big.NewFloat(0.5))
+	} else {
+		v.Sub(v, big.NewFloat(0.5))
+	}
+	res, _ := v.Int(nil)
+	return res
+}
+
+// EuclideanDiv returns the quotient q and the remainder r of the Euclidean
+// division of a by b, such that a = q⋅b + r and 0 ⩽ r < |b|. The inputs a and b
+// are field elements seen as signed integers in (-p/2, p/2). It fails if b is
+// zero.
+func EuclideanDiv(curveID ecc.ID, inputs []*big.Int, results []*big.Int) error {
+	if len(inputs) != 2 || len(results) != 2 {
+		return errors.New("EuclideanDiv: expected two inputs and two outputs")
+	}
+	p := curveID.Info().Fr.Modulus()
+	half := new(big.Int).Rsh(p, 1)
+	signed := func(x *big.Int) *big.Int {
+		res := new(big.Int).Mod(x, p)
+		if res.Cmp(half) > 0 {
+			res.Sub(res, p)
+		}
+		return res
+	}
+	a, b := signed(inputs[0]), signed(inputs[1])
+	if b.Sign() == 0 {
+		return errors.New("EuclideanDiv: division by zero")
+	}
+
+	var q big.Int
+	q.DivMod(a, b, results[1])
+	results[0].Mod(&q, p)
+	return nil
+}
@@ -0,0 +1,136 @@
+package fixedpoint_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/fixedpoint"
+	"github.com/consensys/gnark/test"
+)
+
+const (
+	nbBits   = 32
+	fracBits = 16
+)
+
+type fixedpointCircuit struct {
+	X, Y          frontend.Variable
+	Sum, Mul, Div frontend.Variable `gnark:",public"`
+	IsLess        frontend.Variable `gnark:",public"`
+}
+
+func (c *fixedpointCircuit) Define(api frontend.API) error {
+	f, err := fixedpoint.New(api, nbBits, fracBits)
+	if err != nil {
+		return err
+	}
+	x, y := f.Variable(c.X), f.Variable(c.Y)
+
+	f.AssertIsEqual(f.Add(x, y), f.Variable(c.Sum))
+	f.AssertIsEqual(f.Sub(f.Add(x, y), y), x)
+	f.AssertIsEqual(f.Mul(x, y), f.Variable(c.Mul))
+	f.AssertIsEqual(f.Div(x, y), f.Variable(c.Div))
+	api.AssertIsEqual(f.IsLess(x, y), c.IsLess)
+
+	one := f.Constant(1)
+	f.AssertIsEqual(f.Mul(x, one), x)
+	f.AssertIsEqual(f.Div(x, one), x)
+	f.AssertIsLessOrEqual(f.Neg(f.Mul(x, x)), f.Mul(x, x))
+
+	return nil
+}
+
+// witness returns the assignment of the circuit for the values x and y,
+// computed with integers
+func witness(x, y float64) *fixedpointCircuit {
+	a, b := fixedpoint.FromFloat(x, fracBits), fixedpoint.FromFloat(y, fracBits)
+	one := new(big.Int).Lsh(big.NewInt(1), fracBits)
+
+	mul := new(big.Int).Mul(a, b)
+	mul.Add(mul, new(big.Int).Rsh(one, 1))
+	mul = floorDiv(mul, one)
+
+	div := new(big.Int)
+	if b.Sign() != 0 {
+		div = floorDiv(new(big.Int).Mul(a, one), b)
+	}
+
+	isLess := 0
+	if a.Cmp(b) < 0 {
+		isLess = 1
+	}
+
+	return &fixedpointCircuit{
+		X:      a,
+		Y:      b,
+		Sum:    new(big.Int).Add(a, b),
+		Mul:    mul,
+		Div:    div,
+		IsLess: isLess,
+	}
+}
+
+// floorDiv returns ⌊a / b⌋
+func floorDiv(a, b *big.Int) *big.Int {
+	q, m := new(big.Int).DivMod(a, b, new(big.Int))
+	if b.Sign() < 0 && m.Sign() != 0 {
+		q.Sub(q, big.NewInt(1))
+	}
+	return q
+}
+
+func TestFixedPoint(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, v := range [][2]float64{
+		{3.5, -1.25},
+		{-3.5, 1.25},
+		{-7.1, -0.3},
+		{1.0 / 3, 3},
+		{0, 100.5},
+		{-100.0001, 2.5},
+	} {
+		assert.ProverSucceeded(&fixedpointCircuit{}, witness(v[0], v[1]), test.WithCurves(ecc.BN254))
+	}
+
+	// wrong results
+	w := witness(3.5, -1.25)
+	w.Mul = fixedpoint.FromFloat(-4.375+1.0/(1<<fracBits), fracBits)
+	assert.ProverFailed(&fixedpointCircuit{}, w, test.WithCurves(ecc.BN254))
+
+	w = witness(1.0/3, 3)
+	w.Div = new(big.Int).Add(w.Div.(*big.Int), big.NewInt(1))
+	assert.ProverFailed(&fixedpointCircuit{}, w, test.WithCurves(ecc.BN254))
+
+	w = witness(2, 3)
+	w.IsLess = 0
+	assert.ProverFailed(&fixedpointCircuit{}, w, test.WithCurves(ecc.BN254))
+
+	// division by zero, and overflows
+	assert.ProverFailed(&fixedpointCircuit{}, witness(2, 0), test.WithCurves(ecc.BN254))
+	assert.ProverFailed(&fixedpointCircuit{}, witness(30000, 2), test.WithCurves(ecc.BN254))
+	assert.ProverFailed(&fixedpointCircuit{}, witness(40000, 1), test.WithCurves(ecc.BN254))
+}
+
+func TestFromFloat(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, v := range []struct {
+		x        float64
+		expected int64
+	}{
+		{1, 1 << fracBits},
+		{-1, -1 << fracBits},
+		{0.5, 1 << (fracBits - 1)},
+		{1.5 / (1 << fracBits), 2},
+		{-1.5 / (1 << fracBits), -2},
+		{1.25 / (1 << fracBits), 1},
+	} {
+		assert.Equal(big.NewInt(v.expected), fixedpoint.FromFloat(v.x, fracBits), "FromFloat(%v)", v.x)
+	}
+	assert.Panics(func() { fixedpoint.FromFloat(1.0/zero, fracBits) })
+}
+
+var zero float64
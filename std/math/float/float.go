@@ -0,0 +1,314 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package float provides ZKP-circuit functions for IEEE-754 single precision
+// (float32) floating-point arithmetic.
+//
+// The values are packed in variables as the 32 bits of their IEEE-754
+// encoding (math.Float32bits), and unpacked in a sign, a biased exponent and a
+// mantissa with its implicit leading bit. Add and Mul round to the nearest
+// value, ties to even, as IEEE-754 does by default, with two simplifications:
+//   - subnormal numbers are flushed to zero, when they are unpacked and when
+//     they are the result of an operation;
+//   - infinities and NaNs are not supported: unpacking them, or computing a
+//     result which overflows the range of float32, makes the proof fail.
+//
+// Each operation costs a few hundred constraints: the exact result is computed
+// on integers, normalized with a shift amount given by a hint and checked by a
+// range check, and rounded with its guard and sticky bits.
+package float
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	stdbits "math/bits"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+)
+
+func init() {
+	hint.Register(NbLeadingZeros)
+}
+
+const (
+	mantissaBits = 23
+	exponentBits = 8
+	bias         = 127
+	maxExponent  = 1<<exponentBits - 2 // the largest biased exponent of a finite value
+
+	// maxShift is the largest exponent difference for which the mantissas of
+	// Add are aligned: if the exponents differ by more, the smaller operand is
+	// lower than a quarter of the unit in the last place of the larger one,
+	// and it can only change the rounding as a non-zero value in the sticky bit.
+	maxShift = mantissaBits + 3
+)
+
+// Float is an unpacked float32 value, created by API.Unpack or API.Constant.
+type Float struct {
+	sign     frontend.Variable // 1 if the value is negative, 0 otherwise
+	exponent frontend.Variable // biased exponent, 0 if the value is zero
+	mantissa frontend.Variable // mantissa with its leading bit, 0 if the value is zero
+	isZero   frontend.Variable
+}
+
+// API provides the float32 operations
+type API struct {
+	api frontend.API
+}
+
+// New returns an API for float32 operations
+func New(api frontend.API) *API {
+	return &API{api: api}
+}
+
+// Unpack returns the Float encoded in the 32 bits of v, and asserts that v is a
+// 32 bits integer which doesn't encode an infinity or a NaN. Subnormal values
+// are flushed to zero.
+func (f *API) Unpack(v frontend.Variable) Float {
+	api := f.api
+	bits := api.ToBinary(v, 32)
+	fraction := api.FromBinary(bits[:mantissaBits]...)
+	exponent := api.FromBinary(bits[mantissaBits : mantissaBits+exponentBits]...)
+
+	api.AssertIsDifferent(exponent, maxExponent+1)
+	isZero := api.IsZero(exponent)
+
+	return Float{
+		sign:     bits[31],
+		exponent: exponent,
+		mantissa: api.Select(isZero, 0, api.Add(fraction, 1<<mantissaBits)),
+		isZero:   isZero,
+	}
+}
+
+// Pack returns the 32 bits of the IEEE-754 encoding of x
+func (f *API) Pack(x Float) frontend.Variable {
+	api := f.api
+	// mantissa - 2²³ is the fraction of a non-zero value, and mantissa is 0 for 0
+	fraction := api.Add(api.Sub(x.mantissa, 1<<mantissaBits), api.Mul(x.isZero, 1<<mantissaBits))
+	return api.Add(api.Mul(x.sign, 1<<31), api.Mul(x.exponent, 1<<mantissaBits), fraction)
+}
+
+// Constant returns the Float x. Subnormal values are flushed to zero, and it
+// fails if x is an infinity or a NaN.
+func (f *API) Constant(x float32) Float {
+	return f.Unpack(math.Float32bits(x))
+}
+
+// Neg returns -x
+func (f *API) Neg(x Float) Float {
+	x.sign = f.api.Sub(1, x.sign)
+	return x
+}
+
+// Add returns x + y, rounded to the nearest value.
+//
+// The operands are ordered by magnitude, such that |x| ⩾ |y|, and the exact sum
+// of their mantissas is computed with the mantissa of x shifted left by the
+// difference of the exponents d, bounded by maxShift:
+// S = mx⋅2ᵈ ± my, of at most 51 bits, is then normalized and rounded.
+func (f *API) Add(x, y Float) Float {
+	api := f.api
+
+	// |x| < |y| iff (ex, mx) < (ey, my) in lexicographic order
+	swap := f.isLess(f.magnitude(x), f.magnitude(y), 32)
+	x, y = f.swap(swap, x, y)
+
+	d := api.Sub(x.exponent, y.exponent)
+	// d ∈ [0, 254], d + 256 - (maxShift+1) has its bit 8 set iff d > maxShift
+	isFar := api.ToBinary(api.Add(d, 256-(maxShift+1)), 9)[8]
+	d = api.Select(isFar, maxShift, d)
+
+	isSub := api.Xor(x.sign, y.sign)
+	s := api.Mul(x.mantissa, f.pow2(d, stdbits.Len(maxShift)))
+	s = api.Add(s, api.Mul(y.mantissa, api.Sub(1, api.Mul(isSub, 2))))
+
+	// x + y is an exact 0 iff S == 0, and its sign is then sx ∧ sy
+	isExactZero := api.IsZero(s)
+	mantissa, k := f.normalize(api.Add(s, isExactZero), 2*mantissaBits+5)
+	sign := api.Select(isExactZero, api.Mul(x.sign, y.sign), x.sign)
+
+	// S⋅2^(ex-d-bias-23) = mantissa⋅2^(k+ex-d-bias-23)
+	exponent := api.Sub(api.Add(x.exponent, k), d)
+
+	return f.result(sign, exponent, mantissa, isExactZero)
+}
+
+// Sub returns x - y, rounded to the nearest value
+func (f *API) Sub(x, y Float) Float {
+	return f.Add(x, f.Neg(y))
+}
+
+// Mul returns x ⋅ y, rounded to the nearest value.
+//
+// The product of the mantissas S = mx⋅my, of at most 48 bits, is normalized and
+// rounded.
+func (f *API) Mul(x, y Float) Float {
+	api := f.api
+
+	isZero := api.Or(x.isZero, y.isZero)
+	s := api.Add(api.Mul(x.mantissa, y.mantissa), isZero)
+	mantissa, k := f.normalize(s, 2*mantissaBits+2)
+
+	// S⋅2^(ex+ey-2⋅bias-46) = mantissa⋅2^(k+ex+ey-2⋅bias-46)
+	exponent := api.Add(x.exponent, y.exponent, k, -bias-mantissaBits)
+
+	return f.result(api.Xor(x.sign, y.sign), exponent, mantissa, isZero)
+}
+
+// IsLess returns 1 if x < y, 0 otherwise. -0 and +0 are equal.
+func (f *API) IsLess(x, y Float) frontend.Variable {
+	return f.isLess(f.signedMagnitude(x), f.signedMagnitude(y), 33)
+}
+
+// IsEqual returns 1 if x == y, 0 otherwise. -0 and +0 are equal.
+func (f *API) IsEqual(x, y Float) frontend.Variable {
+	return f.api.IsZero(f.api.Sub(f.signedMagnitude(x), f.signedMagnitude(y)))
+}
+
+// AssertIsEqual fails if x != y. -0 and +0 are equal.
+func (f *API) AssertIsEqual(x, y Float) {
+	f.api.AssertIsEqual(f.signedMagnitude(x), f.signedMagnitude(y))
+}
+
+// Select returns x if b is 1, y otherwise
+func (f *API) Select(b frontend.Variable, x, y Float) Float {
+	api := f.api
+	return Float{
+		sign:     api.Select(b, x.sign, y.sign),
+		exponent: api.Select(b, x.exponent, y.exponent),
+		mantissa: api.Select(b, x.mantissa, y.mantissa),
+		isZero:   api.Select(b, x.isZero, y.isZero),
+	}
+}
+
+// normalize returns the mantissa m ∈ [2²³, 2²⁴) and the exponent k such that
+// m⋅2ᵏ is s rounded to 24 significant bits, to the nearest value, ties to even.
+// s must be in [1, 2^width).
+//
+// A hint returns the shift amount t such that N = s⋅2ᵗ has its leading bit at
+// width - 1; N is decomposed in width bits, which checks t. m is the 24 most
+// significant bits of N, rounded up if the next bit (the guard bit) is 1, and
+// either one of the lower bits (the sticky bit) or the last bit of m is 1.
+func (f *API) normalize(s frontend.Variable, width int) (m, k frontend.Variable) {
+	api := f.api
+
+	t, err := api.Compiler().NewHint(NbLeadingZeros, 1, s, width)
+	if err != nil {
+		panic(err)
+	}
+	n := api.Mul(s, f.pow2(t[0], stdbits.Len(uint(width-1))))
+	bits := api.ToBinary(n, width)
+	api.AssertIsEqual(bits[width-1], 1)
+
+	lsb := width - mantissaBits - 1
+	m = api.FromBinary(bits[lsb:]...)
+	guard := bits[lsb-1]
+	sticky := api.Sub(1, api.IsZero(api.FromBinary(bits[:lsb-1]...)))
+	m = api.Add(m, api.Mul(guard, api.Or(sticky, bits[lsb])))
+
+	// rounding up 2²⁴-1 carries to 2²⁴ = 2²³⋅2
+	carry := api.IsZero(api.Sub(m, 1<<(mantissaBits+1)))
+	m = api.Select(carry, 1<<mantissaBits, m)
+	k = api.Sub(lsb, api.Sub(t[0], carry))
+
+	return m, k
+}
+
+// result returns the Float of the given sign, biased exponent and mantissa, or
+// zero if isZero is 1 or if the exponent underflows, and asserts that the
+// exponent doesn't overflow.
+func (f *API) result(sign, exponent, mantissa, isZero frontend.Variable) Float {
+	api := f.api
+
+	// the exponents of the results are in (-2¹⁰, 2¹⁰): exponent - 1 + 2¹⁰ has its
+	// bit 10 set iff exponent ⩾ 1
+	isNormal := api.ToBinary(api.Add(exponent, 1<<10-1), 11)[10]
+	isZero = api.Or(isZero, api.Sub(1, isNormal))
+
+	exponent = api.Select(isZero, 0, exponent)
+	api.ToBinary(api.Sub(maxExponent, exponent), exponentBits)
+
+	return Float{
+		sign:     sign,
+		exponent: exponent,
+		mantissa: api.Select(isZero, 0, mantissa),
+		isZero:   isZero,
+	}
+}
+
+// magnitude returns |x| as the integer exponent⋅2²⁴ + mantissa, lower than 2³²
+func (f *API) magnitude(x Float) frontend.Variable {
+	return f.api.Add(f.api.Mul(x.exponent, 1<<(mantissaBits+1)), x.mantissa)
+}
+
+// signedMagnitude returns ±magnitude(x), in (-2³², 2³²)
+func (f *API) signedMagnitude(x Float) frontend.Variable {
+	api := f.api
+	return api.Mul(f.magnitude(x), api.Sub(1, api.Mul(x.sign, 2)))
+}
+
+// isLess returns 1 if a < b, 0 otherwise, for a - b in (-2ⁿ, 2ⁿ)
+func (f *API) isLess(a, b frontend.Variable, n int) frontend.Variable {
+	// a - b + 2ⁿ ∈ (0, 2ⁿ⁺¹), and its bit n is set iff a ⩾ b
+	d := f.api.Add(f.api.Sub(a, b), new(big.Int).Lsh(big.NewInt(1), uint(n)))
+	return f.api.Sub(1, f.api.ToBinary(d, n+1)[n])
+}
+
+// swap returns (y, x) if b is 1, (x, y) otherwise
+func (f *API) swap(b frontend.Variable, x, y Float) (Float, Float) {
+	lo := f.Select(b, y, x)
+	api := f.api
+	hi := Float{
+		sign:     api.Sub(api.Add(x.sign, y.sign), lo.sign),
+		exponent: api.Sub(api.Add(x.exponent, y.exponent), lo.exponent),
+		mantissa: api.Sub(api.Add(x.mantissa, y.mantissa), lo.mantissa),
+		isZero:   api.Sub(api.Add(x.isZero, y.isZero), lo.isZero),
+	}
+	return lo, hi
+}
+
+// pow2 returns 2ᵛ, for v ∈ [0, 2ⁿ), with n constraints to decompose v and n-1
+// multiplications
+func (f *API) pow2(v frontend.Variable, n int) frontend.Variable {
+	api := f.api
+	bits := api.ToBinary(v, n)
+	var res frontend.Variable = 1
+	for i := range bits {
+		// 2^(2ⁱ⋅b) = 1 + b⋅(2^(2ⁱ) - 1)
+		c := new(big.Int).Lsh(big.NewInt(1), uint(1<<i))
+		c.Sub(c, big.NewInt(1))
+		res = api.Mul(res, api.Add(1, api.Mul(bits[i], c)))
+	}
+	return res
+}
+
+// NbLeadingZeros returns the number of leading zeros of the first input, in a
+// word of bits of the size given by the second input.
+func NbLeadingZeros(_ ecc.ID, inputs []*big.Int, results []*big.Int) error {
+	if len(inputs) != 2 || len(results) != 1 {
+		return errors.New("NbLeadingZeros: expected two inputs and one output")
+	}
+	width := int(inputs[1].Int64())
+	if inputs[0].BitLen() > width {
+		return errors.New("NbLeadingZeros: input larger than the word size")
+	}
+	results[0].SetUint64(uint64(width - inputs[0].BitLen()))
+	return nil
+}
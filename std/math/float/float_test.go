@@ -0,0 +1,142 @@
+package float_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/float"
+	"github.com/consensys/gnark/test"
+)
+
+type floatCircuit struct {
+	X, Y            frontend.Variable
+	Sum, Diff, Prod frontend.Variable `gnark:",public"`
+	IsLess, IsEqual frontend.Variable `gnark:",public"`
+}
+
+func (c *floatCircuit) Define(api frontend.API) error {
+	f := float.New(api)
+	x, y := f.Unpack(c.X), f.Unpack(c.Y)
+
+	api.AssertIsEqual(f.Pack(f.Add(x, y)), c.Sum)
+	api.AssertIsEqual(f.Pack(f.Sub(x, y)), c.Diff)
+	api.AssertIsEqual(f.Pack(f.Mul(x, y)), c.Prod)
+	api.AssertIsEqual(f.IsLess(x, y), c.IsLess)
+	api.AssertIsEqual(f.IsEqual(x, y), c.IsEqual)
+
+	f.AssertIsEqual(f.Mul(x, f.Constant(1)), x)
+	f.AssertIsEqual(f.Add(x, f.Constant(0)), x)
+
+	return nil
+}
+
+func witness(x, y float32) *floatCircuit {
+	w := &floatCircuit{
+		X:    math.Float32bits(x),
+		Y:    math.Float32bits(y),
+		Sum:  math.Float32bits(x + y),
+		Diff: math.Float32bits(x - y),
+		Prod: math.Float32bits(x * y),
+	}
+	w.IsLess, w.IsEqual = 0, 0
+	if x < y {
+		w.IsLess = 1
+	}
+	if x == y {
+		w.IsEqual = 1
+	}
+	return w
+}
+
+// isNormal returns true if x is zero or a normal number, false if it is
+// subnormal, infinite or NaN
+func isNormal(x float32) bool {
+	e := (math.Float32bits(x) >> 23) & 0xff
+	return e != 0xff && (e != 0 || math.Float32bits(x)&0x7fffff == 0)
+}
+
+func TestFloat(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	ulp := float32(math.Pow(2, -23))
+	values := [][2]float32{
+		{1.5, 2.25},
+		{-1.5, 2.25},
+		{0.1, 0.2},
+		{3, -3},
+		{0, 0},
+		{0, float32(math.Copysign(0, -1))},
+		{1, ulp / 2},                 // tie, rounded to even
+		{1 + ulp, ulp / 2},           // tie, rounded to even
+		{1, -ulp / 4},                // cancellation of the leading bit
+		{math.MaxFloat32 / 2, 1e-30}, // far exponents
+		{1 - ulp/2, ulp / 4},         // carry of the rounding
+		{-7e-20, 3e19},
+	}
+	r := rand.New(rand.NewSource(42)) //#nosec G404 weak rng is fine here
+	for len(values) < 30 {
+		x := float32(r.NormFloat64() * math.Pow(2, float64(r.Intn(40)-20)))
+		y := float32(r.NormFloat64() * math.Pow(2, float64(r.Intn(40)-20)))
+		values = append(values, [2]float32{x, y})
+	}
+
+	for _, v := range values {
+		x, y := v[0], v[1]
+		if !isNormal(x+y) || !isNormal(x-y) || !isNormal(x*y) {
+			t.Fatalf("invalid test values %v, %v", x, y)
+		}
+		assert.SolvingSucceeded(&floatCircuit{}, witness(x, y), test.WithCurves(ecc.BN254))
+	}
+	assert.ProverSucceeded(&floatCircuit{}, witness(0.1, -3.7), test.WithCurves(ecc.BN254))
+
+	// wrong results
+	w := witness(0.1, 0.2)
+	w.Sum = w.Sum.(uint32) + 1
+	assert.ProverFailed(&floatCircuit{}, w, test.WithCurves(ecc.BN254))
+	w = witness(0.1, 0.2)
+	w.Prod = w.Prod.(uint32) - 1
+	assert.ProverFailed(&floatCircuit{}, w, test.WithCurves(ecc.BN254))
+	w = witness(0.1, 0.2)
+	w.IsLess = 0
+	assert.ProverFailed(&floatCircuit{}, w, test.WithCurves(ecc.BN254))
+
+	// overflow, infinity and NaN
+	assert.ProverFailed(&floatCircuit{}, witness(math.MaxFloat32, 2), test.WithCurves(ecc.BN254))
+	w = witness(1, 2)
+	w.X = math.Float32bits(float32(math.Inf(1)))
+	assert.ProverFailed(&floatCircuit{}, w, test.WithCurves(ecc.BN254))
+	w.X = math.Float32bits(float32(math.NaN()))
+	assert.ProverFailed(&floatCircuit{}, w, test.WithCurves(ecc.BN254))
+}
+
+type underflowCircuit struct {
+	X, Y frontend.Variable
+	Prod frontend.Variable `gnark:",public"`
+}
+
+func (c *underflowCircuit) Define(api frontend.API) error {
+	f := float.New(api)
+	api.AssertIsEqual(f.Pack(f.Mul(f.Unpack(c.X), f.Unpack(c.Y))), c.Prod)
+	return nil
+}
+
+func TestFloatFlushToZero(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// the subnormal result is flushed to a zero of the same sign
+	assert.SolvingSucceeded(&underflowCircuit{}, &underflowCircuit{
+		X:    math.Float32bits(-1e-20),
+		Y:    math.Float32bits(1e-20),
+		Prod: math.Float32bits(float32(math.Copysign(0, -1))),
+	}, test.WithCurves(ecc.BN254))
+
+	// the subnormal operand is flushed to zero
+	assert.SolvingSucceeded(&underflowCircuit{}, &underflowCircuit{
+		X:    math.Float32bits(1e-40),
+		Y:    math.Float32bits(1e30),
+		Prod: 0,
+	}, test.WithCurves(ecc.BN254))
+}
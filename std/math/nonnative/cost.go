@@ -0,0 +1,98 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nonnative
+
+import (
+	"errors"
+	"math/big"
+	"math/bits"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// NewParamsFor returns the parameters of the emulated field of modulus mod,
+// for circuits defined over the curve native. The number of bits per limb is
+// the one minimizing the estimated cost of a multiplication (see MulCost).
+func NewParamsFor(native ecc.ID, mod *big.Int) (*Params, error) {
+	if mod == nil || mod.Cmp(big.NewInt(2)) < 0 {
+		return nil, errors.New("modulus must be at least 2")
+	}
+	best, bestCost := 0, -1
+	for nbBits := 1; nbBits < native.Info().Fr.Bits; nbBits++ {
+		cost, err := MulCost(native, nbBits, mod)
+		if err != nil {
+			continue
+		}
+		if bestCost == -1 || cost < bestCost {
+			best, bestCost = nbBits, cost
+		}
+	}
+	if bestCost == -1 {
+		return nil, errors.New("native field too small to emulate the modulus")
+	}
+	return NewParams(best, mod)
+}
+
+// MulCost returns an estimate of the number of R1CS constraints of the
+// multiplication of two reduced elements modulo mod, decomposed into limbs of
+// nbBits bits, in a circuit defined over the curve native. It returns an
+// error if the limbs are too large for the native field.
+//
+// The estimate accounts for the limb products (with the default MulAuto
+// strategy), the range checks of the quotient and of the remainder, and the
+// carries of the equality check, that is for the constraints of Element.Mul.
+// The overhead of additions and of the occasional reductions they trigger is
+// not included.
+func MulCost(native ecc.ID, nbBits int, mod *big.Int) (int, error) {
+	fp, err := NewParams(nbBits, mod)
+	if err != nil {
+		return 0, err
+	}
+	nativeBits := uint(native.Info().Fr.Bits)
+	w, n := fp.nbBits, fp.nbLimbs
+
+	// see Params.maxOverflow
+	if 2*w+uint(bits.Len(n))+10 > nativeBits {
+		return 0, errors.New("limbs too large for the native field")
+	}
+
+	// product of the limbs: 2n-1 limbs of prodBits bits
+	prodBits := 2*w + uint(bits.Len(n))
+	nbX := 2*n - 1
+	cost := fp.nbProducts()
+
+	// quotient and remainder, see Params.reduceLimbs
+	valueBits := prodBits + w*(nbX-1) + 1
+	qBits := uint(1)
+	if pBits := uint(mod.BitLen()); valueBits >= pBits {
+		qBits = valueBits - pBits + 1
+	}
+	nbQ := (qBits + w - 1) / w
+	cost += (nbQ + n) * (w + 1)
+
+	// carries of the equality check, see Params.assertLimbsEquality
+	qpBits := 2*w + uint(bits.Len(min(nbQ, n)))
+	maxBits := max(prodBits, qpBits+1)
+	if maxBits+3 > nativeBits {
+		return 0, errors.New("limbs too large for the native field")
+	}
+	carryBits := maxBits - w + 1
+	nbLimbs := max(nbX, nbQ+n-1)
+	cost += (nbLimbs-1)*(carryBits+2) + 1
+
+	return int(cost), nil
+}
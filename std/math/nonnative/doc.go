@@ -0,0 +1,86 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package nonnative implements arithmetic modulo an integer p which is not the
+modulus of the scalar field of the curve the circuit is defined on (the
+native field). p is typically a prime, the base or scalar field of another
+curve, but it may also be a large composite such as an RSA modulus.
+
+# Representation
+
+An element x of the emulated field of modulus p is represented as a slice of
+limbs (x[0], x[1], ..., x[n-1]), each limb being a native variable, such that
+
+	x = Σ x[i] * 2^(w*i)
+
+where w is the number of bits per limb and n the number of limbs needed to
+represent p. Each limb of a freshly reduced element is range-checked to w
+bits. Additions and subtractions are performed limb-wise without any carry
+propagation: the limbs of the result may then be larger than 2^w and the
+number of excess bits is tracked as the overflow of the element. When the
+overflow would not fit in the native field anymore, the operands are reduced.
+
+Multiplications, reductions and equality checks rely on hints: the quotient
+and remainder of the division by p are computed outside of the circuit, and
+the circuit asserts that x = q*p + r as integers by propagating the carries
+between the limbs of both sides.
+
+The values are not guaranteed to be strictly smaller than p after a
+reduction, only to have limbs of w bits. As such, the decomposition returned
+by ToBits may not be canonical, unless the element is first reduced with
+ReduceStrict.
+
+# Composite moduli
+
+Nothing in the representation depends on p being prime: the limbs, the
+padding used by subtractions and the quotients computed by the reduction
+hints are sized from the bit length of p only, so that moduli of several
+thousand bits (RSA-2048, or n² for Paillier with a 2048-bit n) are supported.
+The number of limbs grows linearly with the size of p and the cost of a
+multiplication quadratically.
+
+When p is composite, the elements form a ring and not a field: Div and
+Inverse make the solver fail if the divisor is not a unit modulo p. Exp and
+ExpBits only rely on multiplications and are defined for every element.
+
+# Usage
+
+The parameters of the emulated field are constructed with NewParams, or with
+NewParamsFor which picks the number of bits per limb minimizing the cost of
+a multiplication for the given native field (see MulCost), and are shared by
+all the elements. In the circuit definition, the emulated inputs
+are declared with Params.Placeholder and assigned with Params.ConstantFromBig:
+
+	type Circuit struct {
+	    X, Y nonnative.Element
+	}
+
+	circuit := Circuit{X: params.Placeholder(), Y: params.Placeholder()}
+	witness := Circuit{X: params.ConstantFromBig(x), Y: params.ConstantFromBig(y)}
+
+The limbs of the inputs are range-checked every time they are used in an
+operation. To avoid the repeated checks, an input used several times should
+first be copied with Element.Set.
+
+Alternatively, NewAPI returns a frontend.API whose operations are performed in
+the emulated field, so that existing circuits can be reused over an emulated
+field. As with Element, the results of additions and subtractions are only
+reduced when their overflow would exceed the capacity of the native field, and
+the operations with constant operands are folded when compiling:
+multiplications by small constants scale the limbs without any reduction.
+*/
+package nonnative
@@ -0,0 +1,504 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nonnative
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+
+	"github.com/consensys/gnark/frontend"
+	gbits "github.com/consensys/gnark/std/math/bits"
+)
+
+// Element is an element of the emulated field, decomposed into limbs.
+type Element struct {
+	// Limbs of the element, least significant first.
+	Limbs []frontend.Variable
+
+	overflow uint    // the limbs are bounded by 2^(nbBits+overflow)
+	internal bool    // the limbs are known to be bounded (the element is the result of an operation)
+	params   *Params // parameters of the emulated field
+}
+
+// Set sets e to a, range-checking the limbs of a if needed, and returns e.
+func (e *Element) Set(api frontend.API, a Element) *Element {
+	*e = enforceWidth(api, a)
+	return e
+}
+
+// Add sets e to a+b and returns e.
+func (e *Element) Add(api frontend.API, a, b Element) *Element {
+	fp := params(a, b)
+	a, b = enforceWidth(api, a), enforceWidth(api, b)
+	for max(a.overflow, b.overflow)+1 > fp.maxOverflow(api) {
+		a, b = reduceLargest(api, a, b)
+	}
+	limbs := make([]frontend.Variable, fp.nbLimbs)
+	for i := range limbs {
+		limbs[i] = api.Add(a.Limbs[i], b.Limbs[i])
+	}
+	*e = Element{Limbs: limbs, overflow: max(a.overflow, b.overflow) + 1, internal: true, params: fp}
+	return e
+}
+
+// Sub sets e to a-b and returns e.
+func (e *Element) Sub(api frontend.API, a, b Element) *Element {
+	fp := params(a, b)
+	a, b = enforceWidth(api, a), enforceWidth(api, b)
+	for max(a.overflow, b.overflow+1)+1 > fp.maxOverflow(api) {
+		a, b = reduceLargest(api, a, b)
+	}
+	// we add to a a multiple of p whose limbs are larger than the limbs of b,
+	// so that the limbs of the result are non-negative.
+	pad := fp.subPadding(b.overflow, fp.nbLimbs)
+	limbs := make([]frontend.Variable, fp.nbLimbs)
+	for i := range limbs {
+		limbs[i] = api.Sub(api.Add(a.Limbs[i], pad[i]), b.Limbs[i])
+	}
+	*e = Element{Limbs: limbs, overflow: max(a.overflow, b.overflow+1) + 1, internal: true, params: fp}
+	return e
+}
+
+// Neg sets e to -a and returns e.
+func (e *Element) Neg(api frontend.API, a Element) *Element {
+	return e.Sub(api, a.params.ConstantFromBig(new(big.Int)), a)
+}
+
+// Mul sets e to a*b and returns e. The result is reduced.
+func (e *Element) Mul(api frontend.API, a, b Element) *Element {
+	fp := params(a, b)
+	a, b = enforceWidth(api, a), enforceWidth(api, b)
+	prod := fp.mulPoly(api, a.Limbs, b.Limbs)
+	prodBits := 2*fp.nbBits + a.overflow + b.overflow + uint(bits.Len(fp.nbLimbs))
+	*e = fp.reduceLimbs(api, prod, prodBits)
+	return e
+}
+
+// MulConst sets e to a*c and returns e, where c is a constant of the circuit.
+// If c is small enough, the limbs of a are multiplied by c and the result is
+// not reduced, as for an addition. Otherwise, it falls back to Mul.
+func (e *Element) MulConst(api frontend.API, a Element, c *big.Int) *Element {
+	fp := params(a)
+	k := new(big.Int).Mod(c, fp.r)
+	cBits := uint(k.BitLen())
+	a = enforceWidth(api, a)
+	if cBits > fp.nbBits || cBits > fp.maxOverflow(api) {
+		return e.Mul(api, a, fp.ConstantFromBig(k))
+	}
+	if a.overflow+cBits > fp.maxOverflow(api) {
+		a.Reduce(api, a)
+	}
+	limbs := make([]frontend.Variable, fp.nbLimbs)
+	for i := range limbs {
+		limbs[i] = api.Mul(a.Limbs[i], k)
+	}
+	*e = Element{Limbs: limbs, overflow: a.overflow + cBits, internal: true, params: fp}
+	return e
+}
+
+// Reduce sets e to a reduced representation of a, that is with limbs of
+// nbBits bits, and returns e.
+func (e *Element) Reduce(api frontend.API, a Element) *Element {
+	a = enforceWidth(api, a)
+	if a.overflow == 0 {
+		*e = a
+		return e
+	}
+	*e = a.params.reduceLimbs(api, a.Limbs, a.params.nbBits+a.overflow)
+	return e
+}
+
+// ReduceStrict sets e to the representative of a in [0, p), with limbs of
+// nbBits bits, and returns e. Contrary to Reduce, the representation of the
+// result is unique.
+func (e *Element) ReduceStrict(api frontend.API, a Element) *Element {
+	a = enforceWidth(api, a)
+	fp := a.params
+	r := fp.reduceLimbs(api, a.Limbs, fp.nbBits+a.overflow)
+
+	// r ⩽ p-1: d = p-1-r has limbs of nbBits bits, and r + d = p-1 as integers
+	res, err := api.Compiler().NewHint(BoundHint, int(fp.nbLimbs), fp.hintInputs(r.Limbs)...)
+	if err != nil {
+		panic(err)
+	}
+	d := enforceWidth(api, Element{Limbs: res, params: fp})
+	sum := make([]frontend.Variable, fp.nbLimbs)
+	for i := range sum {
+		sum[i] = api.Add(r.Limbs[i], d.Limbs[i])
+	}
+	pMinusOne := fp.split(new(big.Int).Sub(fp.r, big.NewInt(1)), fp.nbLimbs)
+	fp.assertLimbsEquality(api, sum, pMinusOne, fp.nbBits+1)
+
+	*e = r
+	return e
+}
+
+// Div sets e to a/b and returns e. The solver fails if b is not invertible.
+func (e *Element) Div(api frontend.API, a, b Element) *Element {
+	fp := params(a, b)
+	a, b = enforceWidth(api, a), enforceWidth(api, b)
+	res, err := api.Compiler().NewHint(DivHint, int(fp.nbLimbs), fp.hintInputs(a.Limbs, b.Limbs)...)
+	if err != nil {
+		panic(err)
+	}
+	d := enforceWidth(api, Element{Limbs: res, params: fp})
+
+	// d*b - a = 0 mod p
+	prod := fp.mulPoly(api, b.Limbs, d.Limbs)
+	prodBits := 2*fp.nbBits + b.overflow + uint(bits.Len(fp.nbLimbs))
+	diff, diffBits := fp.subLimbs(api, prod, prodBits, a)
+	fp.assertZeroLimbs(api, diff, diffBits)
+
+	*e = d
+	return e
+}
+
+// Inverse sets e to 1/a and returns e. The solver fails if a is not
+// invertible.
+func (e *Element) Inverse(api frontend.API, a Element) *Element {
+	return e.Div(api, a.params.ConstantFromBig(big.NewInt(1)), a)
+}
+
+// Exp sets e to a^exp and returns e. The exponent is a non-negative constant
+// of the circuit.
+func (e *Element) Exp(api frontend.API, a Element, exp *big.Int) *Element {
+	fp := params(a)
+	if exp.Sign() < 0 {
+		panic("nonnative: negative exponent")
+	}
+	if exp.Sign() == 0 {
+		*e = fp.ConstantFromBig(big.NewInt(1))
+		return e
+	}
+
+	// left-to-right square and multiply
+	a = enforceWidth(api, a)
+	res := a
+	for i := exp.BitLen() - 2; i >= 0; i-- {
+		res.Mul(api, res, res)
+		if exp.Bit(i) == 1 {
+			res.Mul(api, res, a)
+		}
+	}
+	*e = res
+	return e
+}
+
+// ExpBits sets e to a^k and returns e, where k is a variable exponent given by
+// its binary decomposition, least significant bit first. The bits must be
+// boolean, for example the output of api.ToBinary or Element.ToBits.
+func (e *Element) ExpBits(api frontend.API, a Element, k []frontend.Variable) *Element {
+	fp := params(a)
+	one := fp.ConstantFromBig(big.NewInt(1))
+	if len(k) == 0 {
+		*e = one
+		return e
+	}
+
+	// left-to-right square and multiply, the multiplication being selected
+	// by the bits of k
+	a = enforceWidth(api, a)
+	var res, t Element
+	res.Select(api, k[len(k)-1], a, one)
+	for i := len(k) - 2; i >= 0; i-- {
+		res.Mul(api, res, res)
+		t.Mul(api, res, a)
+		res.Select(api, k[i], t, res)
+	}
+	*e = res
+	return e
+}
+
+// AssertIsEqual asserts that a and b are equal modulo p.
+func (e *Element) AssertIsEqual(api frontend.API, a, b Element) {
+	fp := params(a, b)
+	a, b = enforceWidth(api, a), enforceWidth(api, b)
+	diff, diffBits := fp.subLimbs(api, a.Limbs, fp.nbBits+a.overflow, b)
+	fp.assertZeroLimbs(api, diff, diffBits)
+}
+
+// Select sets e to a if sel is 1 and to b if sel is 0, and returns e. sel must
+// be boolean.
+func (e *Element) Select(api frontend.API, sel frontend.Variable, a, b Element) *Element {
+	fp := params(a, b)
+	a, b = enforceWidth(api, a), enforceWidth(api, b)
+	limbs := make([]frontend.Variable, fp.nbLimbs)
+	for i := range limbs {
+		limbs[i] = api.Select(sel, a.Limbs[i], b.Limbs[i])
+	}
+	*e = Element{Limbs: limbs, overflow: max(a.overflow, b.overflow), internal: true, params: fp}
+	return e
+}
+
+// Lookup2 sets e to a0, a1, a2 or a3 depending on the bits b0 and b1 (see
+// frontend.API.Lookup2) and returns e.
+func (e *Element) Lookup2(api frontend.API, b0, b1 frontend.Variable, a0, a1, a2, a3 Element) *Element {
+	fp := params(a0, a1, a2, a3)
+	a0, a1, a2, a3 = enforceWidth(api, a0), enforceWidth(api, a1), enforceWidth(api, a2), enforceWidth(api, a3)
+	limbs := make([]frontend.Variable, fp.nbLimbs)
+	for i := range limbs {
+		limbs[i] = api.Lookup2(b0, b1, a0.Limbs[i], a1.Limbs[i], a2.Limbs[i], a3.Limbs[i])
+	}
+	overflow := max(max(a0.overflow, a1.overflow), max(a2.overflow, a3.overflow))
+	*e = Element{Limbs: limbs, overflow: overflow, internal: true, params: fp}
+	return e
+}
+
+// ToBits returns the binary decomposition of a reduced representation of e,
+// least significant bit first. The decomposition has nbBits*nbLimbs bits and
+// is not necessarily the one of the smallest non-negative representative.
+func (e *Element) ToBits(api frontend.API) []frontend.Variable {
+	var r Element
+	r.Reduce(api, *e)
+	res := make([]frontend.Variable, 0, r.params.nbBits*r.params.nbLimbs)
+	for _, l := range r.Limbs {
+		res = append(res, gbits.ToBinary(api, l, gbits.WithNbDigits(int(r.params.nbBits)))...)
+	}
+	return res
+}
+
+// params returns the parameters shared by the elements. It panics if they
+// differ.
+func params(elements ...Element) *Params {
+	fp := elements[0].params
+	if fp == nil {
+		panic("nonnative: element without parameters, use Params.Placeholder or Params.ConstantFromBig")
+	}
+	for _, el := range elements[1:] {
+		if el.params != fp && (el.params == nil || !el.params.equal(fp)) {
+			panic("nonnative: elements of different fields")
+		}
+	}
+	return fp
+}
+
+// enforceWidth returns a with limbs range-checked to nbBits bits, unless a
+// is the result of an operation. Constant limbs are checked when compiling.
+func enforceWidth(api frontend.API, a Element) Element {
+	fp := params(a)
+	if a.internal {
+		return a
+	}
+	if uint(len(a.Limbs)) != fp.nbLimbs {
+		panic(fmt.Sprintf("nonnative: expected %d limbs, got %d", fp.nbLimbs, len(a.Limbs)))
+	}
+	for _, l := range a.Limbs {
+		if c, ok := api.Compiler().ConstantValue(l); ok {
+			if uint(c.BitLen()) > fp.nbBits {
+				panic("nonnative: constant limb overflows")
+			}
+			continue
+		}
+		gbits.ToBinary(api, l, gbits.WithNbDigits(int(fp.nbBits)))
+	}
+	return Element{Limbs: a.Limbs, internal: true, params: fp}
+}
+
+// constantValue returns the value of a modulo p if all its limbs are constants
+// of the circuit.
+func (fp *Params) constantValue(api frontend.API, a Element) (*big.Int, bool) {
+	limbs := make([]*big.Int, len(a.Limbs))
+	for i, l := range a.Limbs {
+		c, ok := api.Compiler().ConstantValue(l)
+		if !ok {
+			return nil, false
+		}
+		limbs[i] = c
+	}
+	v := recompose(limbs, fp.nbBits)
+	return v.Mod(v, fp.r), true
+}
+
+// reduceLargest reduces the element of a and b with the largest overflow.
+func reduceLargest(api frontend.API, a, b Element) (Element, Element) {
+	if a.overflow >= b.overflow {
+		a.Reduce(api, a)
+	} else {
+		b.Reduce(api, b)
+	}
+	return a, b
+}
+
+// reduceLimbs returns the reduced element equal to x mod p, where x is given by
+// non-negative limbs bounded by 2^xBits. A single hint computes both the
+// quotient q = x / p and the remainder r, and it asserts that x = q*p + r as
+// integers.
+func (fp *Params) reduceLimbs(api frontend.API, x []frontend.Variable, xBits uint) Element {
+	nbQ := fp.nbQuoLimbs(x, xBits)
+	res, err := api.Compiler().NewHint(QuoRemHint, int(nbQ+fp.nbLimbs), fp.hintInputs(x)...)
+	if err != nil {
+		panic(err)
+	}
+	r := enforceWidth(api, Element{Limbs: res[nbQ:], params: fp})
+
+	qp, qpBits := fp.mulModulus(api, res[:nbQ])
+	for i := range r.Limbs {
+		qp[i] = api.Add(qp[i], r.Limbs[i])
+	}
+	fp.assertLimbsEquality(api, x, qp, max(xBits, qpBits+1))
+	return r
+}
+
+// subLimbs returns the limbs of x - b + k*p, for some multiple k*p ensuring the
+// limbs of the result are non-negative, along with their bound in bits. The
+// limbs of x are bounded by 2^xBits.
+func (fp *Params) subLimbs(api frontend.API, x []frontend.Variable, xBits uint, b Element) ([]frontend.Variable, uint) {
+	n := max(uint(len(x)), fp.nbLimbs)
+	pad := fp.subPadding(b.overflow, n)
+	res := make([]frontend.Variable, n)
+	for i := range res {
+		res[i] = pad[i]
+		if i < len(x) {
+			res[i] = api.Add(res[i], x[i])
+		}
+		if uint(i) < fp.nbLimbs {
+			res[i] = api.Sub(res[i], b.Limbs[i])
+		}
+	}
+	return res, max(xBits, fp.nbBits+b.overflow+1) + 1
+}
+
+// subPadding returns n limbs of a multiple of p, such that the limbs are
+// bounded by 2^(nbBits+overflow+1) and the nbLimbs first limbs are at least
+// 2^(nbBits+overflow).
+func (fp *Params) subPadding(overflow uint, n uint) []*big.Int {
+	pad := make([]*big.Int, n)
+	v := new(big.Int)
+	for i := range pad {
+		pad[i] = new(big.Int)
+		if uint(i) < fp.nbLimbs {
+			pad[i].Lsh(big.NewInt(1), fp.nbBits+overflow)
+		}
+	}
+	v.Set(recompose(pad, fp.nbBits))
+
+	// we complete the padding so that it is a multiple of p
+	v.Neg(v).Mod(v, fp.r)
+	for i, l := range splitBig(v, fp.nbBits, fp.nbLimbs) {
+		pad[i].Add(pad[i], l)
+	}
+	return pad
+}
+
+// assertZeroLimbs asserts that x = 0 mod p, where x is given by non-negative
+// limbs bounded by 2^xBits. It computes the quotient q = x / p with a hint and
+// asserts that x = q*p as integers.
+func (fp *Params) assertZeroLimbs(api frontend.API, x []frontend.Variable, xBits uint) {
+	q, err := api.Compiler().NewHint(QuoHint, int(fp.nbQuoLimbs(x, xBits)), fp.hintInputs(x)...)
+	if err != nil {
+		panic(err)
+	}
+	qp, qpBits := fp.mulModulus(api, q)
+	fp.assertLimbsEquality(api, x, qp, max(xBits, qpBits))
+}
+
+// nbQuoLimbs returns the number of limbs of the quotient x / p, where x is
+// given by non-negative limbs bounded by 2^xBits.
+func (fp *Params) nbQuoLimbs(x []frontend.Variable, xBits uint) uint {
+	valueBits := xBits + fp.nbBits*uint(len(x)-1) + 1
+	qBits := uint(1)
+	if pBits := uint(fp.r.BitLen()); valueBits >= pBits {
+		qBits = valueBits - pBits + 1
+	}
+	return (qBits + fp.nbBits - 1) / fp.nbBits
+}
+
+// mulModulus range checks the limbs of q and returns the limbs of q*p, along
+// with their bound in bits.
+func (fp *Params) mulModulus(api frontend.API, q []frontend.Variable) ([]frontend.Variable, uint) {
+	for _, l := range q {
+		gbits.ToBinary(api, l, gbits.WithNbDigits(int(fp.nbBits)))
+	}
+
+	nbQ := uint(len(q))
+	p := splitBig(fp.r, fp.nbBits, fp.nbLimbs)
+	qp := make([]frontend.Variable, nbQ+fp.nbLimbs-1)
+	for i := range qp {
+		qp[i] = 0
+	}
+	for i := range q {
+		for j := range p {
+			qp[i+j] = api.Add(qp[i+j], api.Mul(q[i], p[j]))
+		}
+	}
+	return qp, 2*fp.nbBits + uint(bits.Len(min(nbQ, fp.nbLimbs)))
+}
+
+// assertLimbsEquality asserts that Σ x[i] * 2^(nbBits*i) = Σ y[i] * 2^(nbBits*i)
+// as integers, where the limbs are non-negative and bounded by 2^maxBits. It
+// propagates the carries from the least significant limbs, and range-checks
+// them to ensure that each step is an exact division.
+func (fp *Params) assertLimbsEquality(api frontend.API, x, y []frontend.Variable, maxBits uint) {
+	nativeBits := uint(api.Compiler().Curve().Info().Fr.Bits)
+	if maxBits+3 > nativeBits {
+		panic("nonnative: limbs overflow the native field")
+	}
+	n := max(uint(len(x)), uint(len(y)))
+
+	// the carries are bounded in absolute value by 2^carryBits
+	carryBits := maxBits - fp.nbBits + 1
+	carryOffset := new(big.Int).Lsh(big.NewInt(1), carryBits)
+	inv := new(big.Int).Lsh(big.NewInt(1), fp.nbBits)
+	inv.ModInverse(inv, api.Compiler().Curve().Info().Fr.Modulus())
+
+	var carry frontend.Variable = 0
+	for i := uint(0); i < n; i++ {
+		diff := carry
+		if i < uint(len(x)) {
+			diff = api.Add(diff, x[i])
+		}
+		if i < uint(len(y)) {
+			diff = api.Sub(diff, y[i])
+		}
+		if i == n-1 {
+			api.AssertIsEqual(diff, 0)
+			break
+		}
+		carry = api.Mul(diff, inv)
+		gbits.ToBinary(api, api.Add(carry, carryOffset), gbits.WithNbDigits(int(carryBits+1)))
+	}
+}
+
+// mulLimbs returns the coefficients of the product of the polynomials of
+// coefficients a and b, with the schoolbook method.
+func mulLimbs(api frontend.API, a, b []frontend.Variable) []frontend.Variable {
+	res := make([]frontend.Variable, len(a)+len(b)-1)
+	for i := range res {
+		res[i] = 0
+	}
+	for i := range a {
+		for j := range b {
+			res[i+j] = api.Add(res[i+j], api.Mul(a[i], b[j]))
+		}
+	}
+	return res
+}
+
+func max(a, b uint) uint {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b uint) uint {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,323 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nonnative
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+// secp256k1 base field
+var testModulus, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f", 16)
+
+func testParams(t *testing.T, nbBits int) *Params {
+	params, err := NewParams(nbBits, testModulus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return params
+}
+
+func randomElement(t *testing.T) *big.Int {
+	v, err := rand.Int(rand.Reader, testModulus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+type arithmeticCircuit struct {
+	A, B                      Element
+	Sum, Diff, Prod, Quo, Inv Element
+}
+
+func (c *arithmeticCircuit) Define(api frontend.API) error {
+	var a, b, r Element
+	a.Set(api, c.A)
+	b.Set(api, c.B)
+	r.AssertIsEqual(api, *r.Add(api, a, b), c.Sum)
+	r.AssertIsEqual(api, *r.Sub(api, a, b), c.Diff)
+	r.AssertIsEqual(api, *r.Mul(api, a, b), c.Prod)
+	r.AssertIsEqual(api, *r.Div(api, a, b), c.Quo)
+	r.AssertIsEqual(api, *r.Inverse(api, b), c.Inv)
+	return nil
+}
+
+func TestArithmetic(t *testing.T) {
+	assert := test.NewAssert(t)
+	for _, nbBits := range []int{32, 64} {
+		params := testParams(t, nbBits)
+		a, b := randomElement(t), randomElement(t)
+		p := params.Modulus()
+
+		sum := new(big.Int).Add(a, b)
+		diff := new(big.Int).Sub(a, b)
+		prod := new(big.Int).Mul(a, b)
+		inv := new(big.Int).ModInverse(b, p)
+		quo := new(big.Int).Mul(a, inv)
+
+		circuit := arithmeticCircuit{
+			A: params.Placeholder(), B: params.Placeholder(),
+			Sum: params.Placeholder(), Diff: params.Placeholder(), Prod: params.Placeholder(),
+			Quo: params.Placeholder(), Inv: params.Placeholder(),
+		}
+		witness := arithmeticCircuit{
+			A: params.ConstantFromBig(a), B: params.ConstantFromBig(b),
+			Sum: params.ConstantFromBig(sum), Diff: params.ConstantFromBig(diff), Prod: params.ConstantFromBig(prod),
+			Quo: params.ConstantFromBig(quo), Inv: params.ConstantFromBig(inv),
+		}
+		assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+
+		witness.Prod = params.ConstantFromBig(new(big.Int).Add(prod, big.NewInt(1)))
+		assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BN254))
+	}
+}
+
+type lazyCircuit struct {
+	A, B     Element
+	Expected Element
+}
+
+// Define computes ((a-b)^2 + a+b)^2 with many chained additions, forcing
+// intermediate reductions.
+func (c *lazyCircuit) Define(api frontend.API) error {
+	var a, b, d, s Element
+	a.Set(api, c.A)
+	b.Set(api, c.B)
+	d.Sub(api, a, b)
+	d.Mul(api, d, d)
+	s.Add(api, a, b)
+	for i := 0; i < 200; i++ {
+		d.Add(api, d, s)
+		d.Sub(api, d, s)
+	}
+	d.Add(api, d, s)
+	d.Mul(api, d, d)
+	d.AssertIsEqual(api, d, c.Expected)
+	return nil
+}
+
+func TestOverflow(t *testing.T) {
+	assert := test.NewAssert(t)
+	params := testParams(t, 64)
+	a, b := randomElement(t), randomElement(t)
+
+	expected := new(big.Int).Sub(a, b)
+	expected.Mul(expected, expected)
+	expected.Add(expected, a).Add(expected, b)
+	expected.Mul(expected, expected)
+
+	circuit := lazyCircuit{A: params.Placeholder(), B: params.Placeholder(), Expected: params.Placeholder()}
+	witness := lazyCircuit{A: params.ConstantFromBig(a), B: params.ConstantFromBig(b), Expected: params.ConstantFromBig(expected)}
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+}
+
+type toBitsCircuit struct {
+	A    Element
+	Bits [256]frontend.Variable
+}
+
+func (c *toBitsCircuit) Define(api frontend.API) error {
+	bits := c.A.ToBits(api)
+	for i := range c.Bits {
+		api.AssertIsEqual(bits[i], c.Bits[i])
+	}
+	return nil
+}
+
+func TestToBits(t *testing.T) {
+	assert := test.NewAssert(t)
+	params := testParams(t, 64)
+	a := randomElement(t)
+
+	circuit := toBitsCircuit{A: params.Placeholder()}
+	witness := toBitsCircuit{A: params.ConstantFromBig(a)}
+	for i := range witness.Bits {
+		witness.Bits[i] = a.Bit(i)
+	}
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+}
+
+func TestParams(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	params, err := NewParams(64, testModulus)
+	assert.NoError(err)
+	assert.Equal(uint(4), params.NbLimbs())
+	assert.Equal(uint(64), params.NbBits())
+
+	_, err = NewParams(0, testModulus)
+	assert.Error(err)
+	_, err = NewParams(64, big.NewInt(1))
+	assert.Error(err)
+
+	// composite moduli are supported
+	params, err = NewParams(64, big.NewInt(15))
+	assert.NoError(err)
+	assert.Equal(uint(1), params.NbLimbs())
+}
+
+type expCircuit struct {
+	A, Expected Element
+}
+
+func (c *expCircuit) Define(api frontend.API) error {
+	var r Element
+	r.Exp(api, c.A, big.NewInt(65537))
+	r.ReduceStrict(api, r)
+	for i := range r.Limbs {
+		api.AssertIsEqual(r.Limbs[i], c.Expected.Limbs[i])
+	}
+	return nil
+}
+
+func TestExp(t *testing.T) {
+	assert := test.NewAssert(t)
+	params := testParams(t, 64)
+	a := randomElement(t)
+	expected := new(big.Int).Exp(a, big.NewInt(65537), testModulus)
+
+	circuit := expCircuit{A: params.Placeholder(), Expected: params.Placeholder()}
+	witness := expCircuit{A: params.ConstantFromBig(a), Expected: params.ConstantFromBig(expected)}
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+
+	witness.Expected = params.ConstantFromBig(new(big.Int).Add(expected, big.NewInt(1)))
+	assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BN254))
+}
+
+type compositeCircuit struct {
+	A, B      Element
+	K         [8]frontend.Variable
+	Sum, Prod Element
+	Pow       Element
+}
+
+func (c *compositeCircuit) Define(api frontend.API) error {
+	var a, b, r Element
+	a.Set(api, c.A)
+	b.Set(api, c.B)
+	r.AssertIsEqual(api, *r.Add(api, a, b), c.Sum)
+	r.AssertIsEqual(api, *r.Mul(api, a, b), c.Prod)
+	r.AssertIsEqual(api, *r.ExpBits(api, a, c.K[:]), c.Pow)
+	return nil
+}
+
+// TestComposite checks the arithmetic modulo an RSA-2048 modulus n and modulo
+// n², as used by the Paillier cryptosystem.
+func TestComposite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping large moduli in short mode")
+	}
+	assert := test.NewAssert(t)
+
+	p, err := rand.Prime(rand.Reader, 1024)
+	assert.NoError(err)
+	q, err := rand.Prime(rand.Reader, 1024)
+	assert.NoError(err)
+	n := new(big.Int).Mul(p, q)
+
+	for _, mod := range []*big.Int{n, new(big.Int).Mul(n, n)} {
+		params, err := NewParams(64, mod)
+		assert.NoError(err)
+		a, err := rand.Int(rand.Reader, mod)
+		assert.NoError(err)
+		b, err := rand.Int(rand.Reader, mod)
+		assert.NoError(err)
+		k := int64(0xa7)
+
+		circuit := compositeCircuit{
+			A: params.Placeholder(), B: params.Placeholder(),
+			Sum: params.Placeholder(), Prod: params.Placeholder(), Pow: params.Placeholder(),
+		}
+		witness := compositeCircuit{
+			A: params.ConstantFromBig(a), B: params.ConstantFromBig(b),
+			Sum:  params.ConstantFromBig(new(big.Int).Add(a, b)),
+			Prod: params.ConstantFromBig(new(big.Int).Mul(a, b)),
+			Pow:  params.ConstantFromBig(new(big.Int).Exp(a, big.NewInt(k), mod)),
+		}
+		for i := range witness.K {
+			witness.K[i] = (k >> i) & 1
+		}
+		assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+
+		witness.Pow = params.ConstantFromBig(new(big.Int).Exp(a, big.NewInt(k+1), mod))
+		assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+	}
+}
+
+func TestNewParamsFor(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	params, err := NewParamsFor(ecc.BN254, testModulus)
+	assert.NoError(err)
+	best, err := MulCost(ecc.BN254, int(params.NbBits()), testModulus)
+	assert.NoError(err)
+	for _, nbBits := range []int{16, 32, 43, 64} {
+		cost, err := MulCost(ecc.BN254, nbBits, testModulus)
+		assert.NoError(err)
+		assert.LessOrEqual(best, cost)
+	}
+
+	// limbs larger than the native field can hold
+	_, err = MulCost(ecc.BN254, 128, testModulus)
+	assert.Error(err)
+}
+
+func TestMulStrategy(t *testing.T) {
+	assert := test.NewAssert(t)
+	// BLS12-381 base field, 6 limbs of 64 bits
+	p, _ := new(big.Int).SetString("1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab", 16)
+	params, err := NewParams(64, p)
+	assert.NoError(err)
+	assert.True(params.useKaratsuba())
+	assert.Equal(uint(21), params.nbProducts())
+	assert.Equal(uint(36), params.WithMulStrategy(MulSchoolbook).nbProducts())
+
+	for _, strategy := range []MulStrategy{MulSchoolbook, MulKaratsuba} {
+		fp := params.WithMulStrategy(strategy)
+		a, err := rand.Int(rand.Reader, p)
+		assert.NoError(err)
+		b, err := rand.Int(rand.Reader, p)
+		assert.NoError(err)
+		inv := new(big.Int).ModInverse(b, p)
+		prod := new(big.Int).Mul(a, b)
+
+		circuit := arithmeticCircuit{
+			A: fp.Placeholder(), B: fp.Placeholder(),
+			Sum: fp.Placeholder(), Diff: fp.Placeholder(), Prod: fp.Placeholder(),
+			Quo: fp.Placeholder(), Inv: fp.Placeholder(),
+		}
+		witness := arithmeticCircuit{
+			A: fp.ConstantFromBig(a), B: fp.ConstantFromBig(b),
+			Sum:  fp.ConstantFromBig(new(big.Int).Add(a, b)),
+			Diff: fp.ConstantFromBig(new(big.Int).Sub(a, b)),
+			Prod: fp.ConstantFromBig(prod),
+			Quo:  fp.ConstantFromBig(new(big.Int).Mul(a, inv)),
+			Inv:  fp.ConstantFromBig(inv),
+		}
+		assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+
+		witness.Prod = fp.ConstantFromBig(new(big.Int).Add(prod, big.NewInt(1)))
+		assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nonnative
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+)
+
+func init() {
+	for _, h := range GetHints() {
+		hint.Register(h)
+	}
+}
+
+// GetHints returns all the hints used in this package.
+func GetHints() []hint.Function {
+	return []hint.Function{
+		QuoHint,
+		RemHint,
+		QuoRemHint,
+		DivHint,
+		BoundHint,
+	}
+}
+
+// hintInputs returns the inputs of the hints: the number of bits per limb, the
+// number of limbs of the modulus, the limbs of the modulus and the given
+// limbs.
+func (fp *Params) hintInputs(limbs ...[]frontend.Variable) []frontend.Variable {
+	res := []frontend.Variable{fp.nbBits, fp.nbLimbs}
+	res = append(res, fp.split(fp.r, fp.nbLimbs)...)
+	for _, l := range limbs {
+		res = append(res, l...)
+	}
+	return res
+}
+
+// parseHintInputs parses the inputs built by hintInputs and returns the number
+// of bits per limb, the number of limbs, the modulus and the remaining limbs.
+func parseHintInputs(inputs []*big.Int) (nbBits, nbLimbs uint, p *big.Int, limbs []*big.Int, err error) {
+	if len(inputs) < 2 {
+		return 0, 0, nil, nil, errors.New("missing parameters")
+	}
+	nbBits, nbLimbs = uint(inputs[0].Uint64()), uint(inputs[1].Uint64())
+	if uint(len(inputs)) < 2+nbLimbs {
+		return 0, 0, nil, nil, errors.New("missing modulus limbs")
+	}
+	p = recompose(inputs[2:2+nbLimbs], nbBits)
+	if p.Sign() == 0 {
+		return 0, 0, nil, nil, errors.New("zero modulus")
+	}
+	return nbBits, nbLimbs, p, inputs[2+nbLimbs:], nil
+}
+
+// setLimbs decomposes v into the limbs outputs.
+func setLimbs(v *big.Int, nbBits uint, outputs []*big.Int) error {
+	if uint(v.BitLen()) > nbBits*uint(len(outputs)) {
+		return errors.New("result does not fit in the limbs")
+	}
+	for i, l := range splitBig(v, nbBits, uint(len(outputs))) {
+		outputs[i].Set(l)
+	}
+	return nil
+}
+
+// QuoHint sets the outputs to the limbs of ⌊x / p⌋, where x is given by its
+// limbs.
+func QuoHint(_ ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+	nbBits, _, p, limbs, err := parseHintInputs(inputs)
+	if err != nil {
+		return err
+	}
+	q := new(big.Int).Quo(recompose(limbs, nbBits), p)
+	return setLimbs(q, nbBits, outputs)
+}
+
+// RemHint sets the outputs to the limbs of x mod p, where x is given by its
+// limbs.
+func RemHint(_ ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+	nbBits, _, p, limbs, err := parseHintInputs(inputs)
+	if err != nil {
+		return err
+	}
+	r := new(big.Int).Mod(recompose(limbs, nbBits), p)
+	return setLimbs(r, nbBits, outputs)
+}
+
+// QuoRemHint sets the outputs to the limbs of ⌊x / p⌋ followed by the nbLimbs
+// limbs of x mod p, where x is given by its limbs. The number of limbs of the
+// quotient is given by the number of outputs.
+func QuoRemHint(_ ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+	nbBits, nbLimbs, p, limbs, err := parseHintInputs(inputs)
+	if err != nil {
+		return err
+	}
+	if uint(len(outputs)) < nbLimbs {
+		return errors.New("missing remainder outputs")
+	}
+	nbQ := uint(len(outputs)) - nbLimbs
+	q, r := new(big.Int).QuoRem(recompose(limbs, nbBits), p, new(big.Int))
+	if err := setLimbs(q, nbBits, outputs[:nbQ]); err != nil {
+		return err
+	}
+	return setLimbs(r, nbBits, outputs[nbQ:])
+}
+
+// DivHint sets the outputs to the limbs of a / b mod p, where a and b are
+// given by their limbs.
+func DivHint(_ ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+	nbBits, nbLimbs, p, limbs, err := parseHintInputs(inputs)
+	if err != nil {
+		return err
+	}
+	if uint(len(limbs)) != 2*nbLimbs {
+		return errors.New("expecting two elements")
+	}
+	a := recompose(limbs[:nbLimbs], nbBits)
+	b := recompose(limbs[nbLimbs:], nbBits)
+	if b.ModInverse(b, p) == nil {
+		return errors.New("no inverse")
+	}
+	res := new(big.Int).Mul(a, b)
+	res.Mod(res, p)
+	return setLimbs(res, nbBits, outputs)
+}
+
+// BoundHint sets the outputs to the limbs of p - 1 - x, where x is given by
+// its limbs.
+func BoundHint(_ ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+	nbBits, _, p, limbs, err := parseHintInputs(inputs)
+	if err != nil {
+		return err
+	}
+	d := new(big.Int).Sub(p, big.NewInt(1))
+	d.Sub(d, recompose(limbs, nbBits))
+	if d.Sign() < 0 {
+		return errors.New("value is not smaller than the modulus")
+	}
+	return setLimbs(d, nbBits, outputs)
+}
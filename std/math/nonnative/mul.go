@@ -0,0 +1,139 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nonnative
+
+import "github.com/consensys/gnark/frontend"
+
+// MulStrategy selects the algorithm used to multiply the limbs of two
+// elements, seen as the coefficients of polynomials. In both cases, the
+// product is then reduced with a single equality check over all its limbs.
+type MulStrategy int
+
+const (
+	// MulAuto uses Karatsuba for elements of karatsubaMinLimbs limbs or more,
+	// and schoolbook multiplication otherwise.
+	MulAuto MulStrategy = iota
+
+	// MulSchoolbook computes the n² products of the limbs.
+	MulSchoolbook
+
+	// MulKaratsuba splits the limbs in halves recursively and computes three
+	// products of half size instead of four, that is about n^1.58 products
+	// at the cost of more additions.
+	MulKaratsuba
+)
+
+// karatsubaMinLimbs is the number of limbs from which MulAuto uses Karatsuba.
+// For smaller elements, the additions of Karatsuba outweigh the products it
+// saves in PLONK circuits.
+const karatsubaMinLimbs = 6
+
+// WithMulStrategy returns a copy of fp using the multiplication strategy s.
+// The elements of fp and of the copy can be mixed in the operations.
+func (fp *Params) WithMulStrategy(s MulStrategy) *Params {
+	res := *fp
+	res.mulStrategy = s
+	return &res
+}
+
+// useKaratsuba returns true if the products of elements use Karatsuba.
+func (fp *Params) useKaratsuba() bool {
+	switch fp.mulStrategy {
+	case MulSchoolbook:
+		return false
+	case MulKaratsuba:
+		return true
+	default:
+		return fp.nbLimbs >= karatsubaMinLimbs
+	}
+}
+
+// mulPoly returns the coefficients of the product of the polynomials of
+// coefficients a and b, with the strategy of fp.
+func (fp *Params) mulPoly(api frontend.API, a, b []frontend.Variable) []frontend.Variable {
+	if fp.useKaratsuba() && len(a) == len(b) {
+		return karatsubaLimbs(api, a, b)
+	}
+	return mulLimbs(api, a, b)
+}
+
+// nbProducts returns the number of products of limbs (that is, of R1CS
+// constraints) of the multiplication of two elements.
+func (fp *Params) nbProducts() uint {
+	if fp.useKaratsuba() {
+		return karatsubaProducts(fp.nbLimbs)
+	}
+	return fp.nbLimbs * fp.nbLimbs
+}
+
+// karatsubaLimbs returns the coefficients of the product of the polynomials of
+// coefficients a and b, which must have the same length. With a = a0 + X^m a1
+// and b = b0 + X^m b1:
+//
+//	a*b = a0b0 + X^m ((a0+a1)(b0+b1) - a0b0 - a1b1) + X^2m a1b1
+//
+// The subtractions are exact as the result coefficients are the ones of
+// a0b1 + a1b0, which are non-negative and fit in the native field.
+func karatsubaLimbs(api frontend.API, a, b []frontend.Variable) []frontend.Variable {
+	n := len(a)
+	if n == 1 {
+		return []frontend.Variable{api.Mul(a[0], b[0])}
+	}
+	m := n / 2
+
+	z0 := karatsubaLimbs(api, a[:m], b[:m])
+	z2 := karatsubaLimbs(api, a[m:], b[m:])
+
+	// a[m:] has at least as many limbs as a[:m]
+	sa := make([]frontend.Variable, n-m)
+	sb := make([]frontend.Variable, n-m)
+	for i := range sa {
+		sa[i], sb[i] = a[m+i], b[m+i]
+		if i < m {
+			sa[i] = api.Add(sa[i], a[i])
+			sb[i] = api.Add(sb[i], b[i])
+		}
+	}
+	z1 := karatsubaLimbs(api, sa, sb)
+
+	res := make([]frontend.Variable, 2*n-1)
+	for i := range res {
+		res[i] = 0
+	}
+	for i := range z0 {
+		res[i] = api.Add(res[i], z0[i])
+		z1[i] = api.Sub(z1[i], z0[i])
+	}
+	for i := range z2 {
+		res[2*m+i] = api.Add(res[2*m+i], z2[i])
+		z1[i] = api.Sub(z1[i], z2[i])
+	}
+	for i := range z1 {
+		res[m+i] = api.Add(res[m+i], z1[i])
+	}
+	return res
+}
+
+// karatsubaProducts returns the number of products of karatsubaLimbs for
+// polynomials of n coefficients.
+func karatsubaProducts(n uint) uint {
+	if n == 1 {
+		return 1
+	}
+	m := n / 2
+	return karatsubaProducts(m) + 2*karatsubaProducts(n-m)
+}
@@ -0,0 +1,156 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nonnative
+
+import (
+	"errors"
+	"math/big"
+	"math/bits"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// Params defines the emulated field and the representation of its elements.
+type Params struct {
+	r       *big.Int // modulus of the emulated field
+	nbBits  uint     // number of bits per limb
+	nbLimbs uint     // number of limbs per element
+
+	mulStrategy MulStrategy // algorithm of the products of limbs
+}
+
+// NewParams returns the parameters of the emulated field of modulus mod,
+// whose elements are decomposed into limbs of nbBits bits. NewParamsFor
+// selects nbBits automatically for a given native field.
+//
+// The modulus may be composite (for example an RSA modulus), in which case the
+// elements form a ring: Div and Inverse then fail at solving time if the
+// divisor is not invertible.
+func NewParams(nbBits int, mod *big.Int) (*Params, error) {
+	if nbBits <= 0 {
+		return nil, errors.New("number of bits per limb must be positive")
+	}
+	if mod == nil || mod.Cmp(big.NewInt(2)) < 0 {
+		return nil, errors.New("modulus must be at least 2")
+	}
+	nbLimbs := (mod.BitLen() + nbBits - 1) / nbBits
+	return &Params{
+		r:       new(big.Int).Set(mod),
+		nbBits:  uint(nbBits),
+		nbLimbs: uint(nbLimbs),
+	}, nil
+}
+
+// Modulus returns the modulus of the emulated field.
+func (fp *Params) Modulus() *big.Int {
+	return new(big.Int).Set(fp.r)
+}
+
+// NbBits returns the number of bits per limb.
+func (fp *Params) NbBits() uint {
+	return fp.nbBits
+}
+
+// NbLimbs returns the number of limbs per element.
+func (fp *Params) NbLimbs() uint {
+	return fp.nbLimbs
+}
+
+// Placeholder returns an element whose limbs are allocated but unset, to be
+// used in the circuit definition.
+func (fp *Params) Placeholder() Element {
+	return Element{
+		Limbs:  make([]frontend.Variable, fp.nbLimbs),
+		params: fp,
+	}
+}
+
+// ConstantFromBig returns the element of value v mod p. It can be used both as
+// a constant in the circuit and as an assignment of the witness.
+func (fp *Params) ConstantFromBig(v *big.Int) Element {
+	r := new(big.Int).Mod(v, fp.r)
+	return Element{
+		Limbs:  fp.split(r, fp.nbLimbs),
+		params: fp,
+	}
+}
+
+// FromLimbs returns the element of given limbs, in little-endian order. The
+// limbs are range-checked when the element is used.
+func (fp *Params) FromLimbs(limbs []frontend.Variable) Element {
+	if uint(len(limbs)) != fp.nbLimbs {
+		panic("invalid number of limbs")
+	}
+	return Element{
+		Limbs:  append([]frontend.Variable(nil), limbs...),
+		params: fp,
+	}
+}
+
+// equal returns true if fp and other define the same field and representation.
+func (fp *Params) equal(other *Params) bool {
+	return fp.nbBits == other.nbBits && fp.nbLimbs == other.nbLimbs && fp.r.Cmp(other.r) == 0
+}
+
+// split decomposes v into nbLimbs limbs of fp.nbBits bits. It panics if v does
+// not fit.
+func (fp *Params) split(v *big.Int, nbLimbs uint) []frontend.Variable {
+	limbs := make([]frontend.Variable, nbLimbs)
+	for i, l := range splitBig(v, fp.nbBits, nbLimbs) {
+		limbs[i] = l
+	}
+	return limbs
+}
+
+// maxOverflow returns the largest overflow of an element such that the
+// product of any two elements fits in the native field, with enough room for
+// the carries of the equality checks.
+func (fp *Params) maxOverflow(api frontend.API) uint {
+	nativeBits := uint(api.Compiler().Curve().Info().Fr.Bits)
+	used := 2*fp.nbBits + uint(bits.Len(fp.nbLimbs)) + 6
+	if used+4 > nativeBits {
+		panic("nonnative: limbs too large for the native field")
+	}
+	return (nativeBits - used) / 2
+}
+
+// splitBig decomposes v into nbLimbs limbs of nbBits bits, least significant
+// first. It panics if v does not fit.
+func splitBig(v *big.Int, nbBits, nbLimbs uint) []*big.Int {
+	if v.Sign() < 0 || uint(v.BitLen()) > nbBits*nbLimbs {
+		panic("value does not fit in the limbs")
+	}
+	mask := new(big.Int).Lsh(big.NewInt(1), nbBits)
+	mask.Sub(mask, big.NewInt(1))
+	limbs := make([]*big.Int, nbLimbs)
+	tmp := new(big.Int).Set(v)
+	for i := range limbs {
+		limbs[i] = new(big.Int).And(tmp, mask)
+		tmp.Rsh(tmp, nbBits)
+	}
+	return limbs
+}
+
+// recompose returns Σ limbs[i] * 2^(nbBits*i).
+func recompose(limbs []*big.Int, nbBits uint) *big.Int {
+	res := new(big.Int)
+	for i := len(limbs) - 1; i >= 0; i-- {
+		res.Lsh(res, nbBits)
+		res.Add(res, limbs[i])
+	}
+	return res
+}
@@ -0,0 +1,89 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package presets provides the parameters of the fields commonly emulated with
+// std/math/nonnative.
+//
+// All the fields are decomposed into limbs of NbBits bits. The representation
+// of the elements, and thus the witnesses of the circuits using them, only
+// depend on the modulus and on the number of bits per limb: circuits and
+// services using the same preset can exchange assignments. The hints of the
+// elements are registered by the nonnative package.
+package presets
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/std/math/nonnative"
+)
+
+// NbBits is the number of bits per limb of the preset parameters.
+const NbBits = 64
+
+var (
+	secp256k1Fp = mustParams("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f")
+	secp256k1Fr = mustParams("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141")
+	p256Fp      = mustParams("ffffffff00000001000000000000000000000000ffffffffffffffffffffffff")
+	p256Fr      = mustParams("ffffffff00000000ffffffffffffffffbce6faada7179e84f3b9cac2fc632551")
+	ed25519Fp   = mustParams("7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffed")
+	ed25519Fr   = mustParams("1000000000000000000000000000000014def9dea2f79cd65812631a5cf5d3ed")
+	bls12381Fp  = mustParams("1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab")
+	bls12381Fr  = mustParams("73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001")
+	starkFp     = mustParams("800000000000011000000000000000000000000000000000000000000000001")
+)
+
+// mustParams returns the parameters of the field of hexadecimal modulus mod.
+func mustParams(mod string) *nonnative.Params {
+	p, ok := new(big.Int).SetString(mod, 16)
+	if !ok {
+		panic("invalid modulus")
+	}
+	params, err := nonnative.NewParams(NbBits, p)
+	if err != nil {
+		panic(err)
+	}
+	return params
+}
+
+// Secp256k1Fp returns the parameters of the base field of secp256k1.
+func Secp256k1Fp() *nonnative.Params { return secp256k1Fp }
+
+// Secp256k1Fr returns the parameters of the scalar field of secp256k1.
+func Secp256k1Fr() *nonnative.Params { return secp256k1Fr }
+
+// P256Fp returns the parameters of the base field of P-256.
+func P256Fp() *nonnative.Params { return p256Fp }
+
+// P256Fr returns the parameters of the scalar field of P-256.
+func P256Fr() *nonnative.Params { return p256Fr }
+
+// Ed25519Fp returns the parameters of the base field of Ed25519, of modulus
+// 2^255 - 19.
+func Ed25519Fp() *nonnative.Params { return ed25519Fp }
+
+// Ed25519Fr returns the parameters of the scalar field of Ed25519, the order
+// of its prime subgroup.
+func Ed25519Fr() *nonnative.Params { return ed25519Fr }
+
+// BLS12381Fp returns the parameters of the base field of BLS12-381.
+func BLS12381Fp() *nonnative.Params { return bls12381Fp }
+
+// BLS12381Fr returns the parameters of the scalar field of BLS12-381.
+func BLS12381Fr() *nonnative.Params { return bls12381Fr }
+
+// StarkFp returns the parameters of the STARK field, of modulus
+// 2^251 + 17*2^192 + 1.
+func StarkFp() *nonnative.Params { return starkFp }
@@ -0,0 +1,68 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package presets
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark/std/math/nonnative"
+	"github.com/consensys/gnark/test"
+)
+
+// pow2 returns 2^n.
+func pow2(n uint) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), n)
+}
+
+func TestPresets(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// moduli with a closed form
+	secp256k1 := new(big.Int).Sub(pow2(256), pow2(32))
+	secp256k1.Sub(secp256k1, big.NewInt(977))
+	p256 := new(big.Int).Sub(pow2(256), pow2(224))
+	p256.Add(p256, pow2(192)).Add(p256, pow2(96)).Sub(p256, big.NewInt(1))
+	ed25519 := new(big.Int).Sub(pow2(255), big.NewInt(19))
+	l, _ := new(big.Int).SetString("27742317777372353535851937790883648493", 10)
+	l.Add(l, pow2(252))
+	stark := new(big.Int).Mul(big.NewInt(17), pow2(192))
+	stark.Add(stark, pow2(251)).Add(stark, big.NewInt(1))
+
+	for _, tc := range []struct {
+		params  *nonnative.Params
+		modulus *big.Int
+		nbLimbs uint
+	}{
+		{Secp256k1Fp(), secp256k1, 4},
+		{P256Fp(), p256, 4},
+		{Ed25519Fp(), ed25519, 4},
+		{Ed25519Fr(), l, 4},
+		{StarkFp(), stark, 4},
+		{Secp256k1Fr(), nil, 4},
+		{P256Fr(), nil, 4},
+		{BLS12381Fp(), nil, 6},
+		{BLS12381Fr(), nil, 4},
+	} {
+		if tc.modulus != nil {
+			assert.Equal(0, tc.params.Modulus().Cmp(tc.modulus))
+		}
+		assert.True(tc.params.Modulus().ProbablyPrime(20))
+		assert.Equal(uint(NbBits), tc.params.NbBits())
+		assert.Equal(tc.nbLimbs, tc.params.NbLimbs())
+	}
+}
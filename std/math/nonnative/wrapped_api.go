@@ -0,0 +1,549 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nonnative
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs"
+	"github.com/consensys/gnark/internal/utils"
+)
+
+// fakeAPI implements frontend.API over the emulated field. The variables are
+// Element values. The results of the additions and subtractions are not
+// reduced, and the operations on constants are computed when compiling.
+type fakeAPI struct {
+	api    frontend.API
+	params *Params
+}
+
+// NewAPI returns a frontend.API whose variables are elements of the emulated
+// field defined by params. The variables given to the returned API must be
+// Element, *Element or constants convertible to *big.Int.
+//
+// The binary operations (ToBinary, Xor, Select, ...) return elements of value
+// 0 or 1, and expect their boolean inputs to be such elements. The comparisons
+// are performed on the canonical representatives of the elements in [0, p).
+// Commit commits to the limbs of the elements with the native API. NewHint is
+// not supported.
+func NewAPI(native frontend.API, params *Params) (frontend.API, error) {
+	if native == nil {
+		return nil, errors.New("missing native API")
+	}
+	if params == nil {
+		return nil, errors.New("missing parameters")
+	}
+	return &fakeAPI{api: native, params: params}, nil
+}
+
+// varToElement converts v to an element of the emulated field. The limbs of
+// the inputs are range-checked at this point.
+func (f *fakeAPI) varToElement(v frontend.Variable) Element {
+	switch vv := v.(type) {
+	case Element:
+		return enforceWidth(f.api, vv)
+	case *Element:
+		return enforceWidth(f.api, *vv)
+	default:
+		c := utils.FromInterface(v)
+		return f.params.ConstantFromBig(&c)
+	}
+}
+
+func (f *fakeAPI) varsToElements(in ...frontend.Variable) []Element {
+	res := make([]Element, len(in))
+	for i := range in {
+		res[i] = f.varToElement(in[i])
+	}
+	return res
+}
+
+// constantValues returns the values of the elements if they are all constants
+// of the circuit.
+func (f *fakeAPI) constantValues(els ...Element) ([]*big.Int, bool) {
+	res := make([]*big.Int, len(els))
+	for i := range els {
+		c, ok := f.params.constantValue(f.api, els[i])
+		if !ok {
+			return nil, false
+		}
+		res[i] = c
+	}
+	return res, true
+}
+
+// Add returns the sum of the inputs. The result is not reduced: the overflow
+// of the limbs is tracked and the operands are only reduced when needed.
+func (f *fakeAPI) Add(i1, i2 frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	els := f.varsToElements(append([]frontend.Variable{i1, i2}, in...)...)
+	if cs, ok := f.constantValues(els...); ok {
+		res := new(big.Int)
+		for _, c := range cs {
+			res.Add(res, c)
+		}
+		return f.params.ConstantFromBig(res)
+	}
+	var res Element
+	res.Add(f.api, els[0], els[1])
+	for _, e := range els[2:] {
+		res.Add(f.api, res, e)
+	}
+	return res
+}
+
+func (f *fakeAPI) Neg(i1 frontend.Variable) frontend.Variable {
+	return f.Sub(0, i1)
+}
+
+// Sub returns i1 minus the other inputs. As for Add, the result is not
+// reduced.
+func (f *fakeAPI) Sub(i1, i2 frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	els := f.varsToElements(append([]frontend.Variable{i1, i2}, in...)...)
+	if cs, ok := f.constantValues(els...); ok {
+		res := new(big.Int).Set(cs[0])
+		for _, c := range cs[1:] {
+			res.Sub(res, c)
+		}
+		return f.params.ConstantFromBig(res)
+	}
+	var res Element
+	res.Sub(f.api, els[0], els[1])
+	for _, e := range els[2:] {
+		res.Sub(f.api, res, e)
+	}
+	return res
+}
+
+// Mul returns the product of the inputs. A multiplication by a constant is
+// performed with Element.MulConst, which does not reduce small constants.
+func (f *fakeAPI) Mul(i1, i2 frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	els := f.varsToElements(append([]frontend.Variable{i1, i2}, in...)...)
+	res := els[0]
+	for _, e := range els[1:] {
+		res = f.mul(res, e)
+	}
+	return res
+}
+
+func (f *fakeAPI) mul(a, b Element) Element {
+	ca, aConst := f.params.constantValue(f.api, a)
+	cb, bConst := f.params.constantValue(f.api, b)
+	var res Element
+	switch {
+	case aConst && bConst:
+		res = f.params.ConstantFromBig(ca.Mul(ca, cb))
+	case aConst:
+		res.MulConst(f.api, b, ca)
+	case bConst:
+		res.MulConst(f.api, a, cb)
+	default:
+		res.Mul(f.api, a, b)
+	}
+	return res
+}
+
+func (f *fakeAPI) DivUnchecked(i1, i2 frontend.Variable) frontend.Variable {
+	return f.Div(i1, i2)
+}
+
+func (f *fakeAPI) Div(i1, i2 frontend.Variable) frontend.Variable {
+	a, b := f.varToElement(i1), f.varToElement(i2)
+	if cb, ok := f.params.constantValue(f.api, b); ok {
+		if cb.ModInverse(cb, f.params.r) == nil {
+			panic("nonnative: division by a non-invertible constant")
+		}
+		return f.mul(a, f.params.ConstantFromBig(cb))
+	}
+	var res Element
+	res.Div(f.api, a, b)
+	return res
+}
+
+func (f *fakeAPI) Inverse(i1 frontend.Variable) frontend.Variable {
+	return f.Div(1, i1)
+}
+
+// BatchInvert returns the inverses of the inputs. A nonnative inversion costs
+// about as much as a multiplication, so the inverses are computed one by one.
+func (f *fakeAPI) BatchInvert(vs []frontend.Variable) []frontend.Variable {
+	res := make([]frontend.Variable, len(vs))
+	for i := range vs {
+		res[i] = f.Inverse(vs[i])
+	}
+	return res
+}
+
+// Exp returns base^exponent. If exponent is not a constant, it is decomposed
+// on nbBits bits (the bit length of p by default).
+func (f *fakeAPI) Exp(base, exponent frontend.Variable, nbBits ...int) frontend.Variable {
+	if e, ok := f.ConstantValue(exponent); ok {
+		var res Element
+		res.Exp(f.api, f.varToElement(base), e)
+		return res
+	}
+	eBits := f.ToBinary(exponent, nbBits...)
+	var res frontend.Variable = 1
+	for i := len(eBits) - 1; i >= 0; i-- {
+		res = f.Mul(res, res)
+		res = f.Select(eBits[i], f.Mul(res, base), res)
+	}
+	return res
+}
+
+// ToBinary returns the n least significant bits of the canonical
+// representative of i1 in [0, p), and asserts that the other bits are zero.
+// By default, n is the bit length of p. The bits are elements of the emulated
+// field.
+func (f *fakeAPI) ToBinary(i1 frontend.Variable, n ...int) []frontend.Variable {
+	nbBits := f.params.r.BitLen()
+	if len(n) == 1 {
+		nbBits = n[0]
+	} else if len(n) > 1 {
+		panic("only one argument allowed for the number of bits")
+	}
+	var e Element
+	e.ReduceStrict(f.api, f.varToElement(i1))
+	bits := e.ToBits(f.api)
+	for i := nbBits; i < len(bits); i++ {
+		f.api.AssertIsEqual(bits[i], 0)
+	}
+	res := make([]frontend.Variable, nbBits)
+	for i := range res {
+		if i < len(bits) {
+			res[i] = f.bitToElement(bits[i])
+		} else {
+			res[i] = f.bitToElement(0)
+		}
+	}
+	return res
+}
+
+// FromBinary returns Σ b[i] * 2^i mod p, where the b[i] must be boolean.
+func (f *fakeAPI) FromBinary(b ...frontend.Variable) frontend.Variable {
+	fp := f.params
+	chunk := int(fp.nbBits * fp.nbLimbs)
+	var res Element
+	for start := 0; start < len(b) || start == 0; start += chunk {
+		// the bits of the chunk are packed into the limbs, without reduction
+		limbs := make([]frontend.Variable, fp.nbLimbs)
+		for i := range limbs {
+			limbs[i] = 0
+		}
+		for i := start; i < len(b) && i < start+chunk; i++ {
+			l, shift := uint(i-start)/fp.nbBits, uint(i-start)%fp.nbBits
+			limbs[l] = f.api.Add(limbs[l], f.api.Mul(f.toBit(b[i]), new(big.Int).Lsh(big.NewInt(1), shift)))
+		}
+		e := Element{Limbs: limbs, internal: true, params: fp}
+		if start == 0 {
+			res = e
+			continue
+		}
+		e.MulConst(f.api, e, new(big.Int).Lsh(big.NewInt(1), uint(start)))
+		res.Add(f.api, res, e)
+	}
+	return res
+}
+
+func (f *fakeAPI) Xor(a, b frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	return f.bitToElement(f.api.Xor(f.toBit(a), f.toBit(b), f.toBits(in)...))
+}
+
+func (f *fakeAPI) Or(a, b frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	return f.bitToElement(f.api.Or(f.toBit(a), f.toBit(b), f.toBits(in)...))
+}
+
+func (f *fakeAPI) And(a, b frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	return f.bitToElement(f.api.And(f.toBit(a), f.toBit(b), f.toBits(in)...))
+}
+
+func (f *fakeAPI) Nand(a, b frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	return f.bitToElement(f.api.Nand(f.toBit(a), f.toBit(b), f.toBits(in)...))
+}
+
+func (f *fakeAPI) Nor(a, b frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	return f.bitToElement(f.api.Nor(f.toBit(a), f.toBit(b), f.toBits(in)...))
+}
+
+func (f *fakeAPI) WordXor(a, b frontend.Variable, nbBits int) frontend.Variable {
+	return cs.WordBitwise(f, f.Xor, a, b, nbBits)
+}
+
+func (f *fakeAPI) WordAnd(a, b frontend.Variable, nbBits int) frontend.Variable {
+	return cs.WordBitwise(f, f.And, a, b, nbBits)
+}
+
+func (f *fakeAPI) WordRotateLeft(a frontend.Variable, k, nbBits int) frontend.Variable {
+	return cs.WordRotateLeft(f, a, k, nbBits)
+}
+
+// toBits returns the native bits of the elements in
+func (f *fakeAPI) toBits(in []frontend.Variable) []frontend.Variable {
+	res := make([]frontend.Variable, len(in))
+	for i := range in {
+		res[i] = f.toBit(in[i])
+	}
+	return res
+}
+
+func (f *fakeAPI) Select(b frontend.Variable, i1, i2 frontend.Variable) frontend.Variable {
+	var res Element
+	res.Select(f.api, f.toBit(b), f.varToElement(i1), f.varToElement(i2))
+	return res
+}
+
+func (f *fakeAPI) Lookup2(b0, b1 frontend.Variable, i0, i1, i2, i3 frontend.Variable) frontend.Variable {
+	els := f.varsToElements(i0, i1, i2, i3)
+	var res Element
+	res.Lookup2(f.api, f.toBit(b0), f.toBit(b1), els[0], els[1], els[2], els[3])
+	return res
+}
+
+func (f *fakeAPI) LookupN(b []frontend.Variable, values []frontend.Variable) frontend.Variable {
+	return cs.LookupN(f, b, values)
+}
+
+// IsZero returns 1 if i1 = 0 mod p, 0 otherwise.
+func (f *fakeAPI) IsZero(i1 frontend.Variable) frontend.Variable {
+	var e Element
+	e.ReduceStrict(f.api, f.varToElement(i1))
+
+	// the limbs are non-negative and small, their sum is zero iff they are all zero
+	var sum frontend.Variable = 0
+	for _, l := range e.Limbs {
+		sum = f.api.Add(sum, l)
+	}
+	return f.bitToElement(f.api.IsZero(sum))
+}
+
+// Cmp compares the canonical representatives of i1 and i2 in [0, p) and
+// returns 1 if i1 > i2, 0 if i1 = i2 and -1 if i1 < i2.
+func (f *fakeAPI) Cmp(i1, i2 frontend.Variable) frontend.Variable {
+	greater, less := f.cmp(i1, i2)
+	return f.Sub(greater, less)
+}
+
+// IsLess returns 1 if the canonical representative of i1 in [0, p) is smaller
+// than the one of i2, 0 otherwise.
+func (f *fakeAPI) IsLess(i1, i2 frontend.Variable) frontend.Variable {
+	_, less := f.cmp(i1, i2)
+	return less
+}
+
+// IsInRange compares the canonical representatives of v, lower and upper in
+// [0, p).
+func (f *fakeAPI) IsInRange(v, lower, upper frontend.Variable) frontend.Variable {
+	_, less := f.cmp(v, lower)
+	greater, _ := f.cmp(v, upper)
+	return f.Mul(f.Sub(1, less), f.Sub(1, greater))
+}
+
+// Commit commits to the limbs of the elements v with the native API. The
+// native commitment is returned as an element whose limbs hold its
+// nbLimbs*nbBits lowest bits.
+func (f *fakeAPI) Commit(v ...frontend.Variable) frontend.Variable {
+	els := f.varsToElements(v...)
+	var limbs []frontend.Variable
+	for _, e := range els {
+		limbs = append(limbs, e.Limbs...)
+	}
+	commitment := f.api.Commit(limbs...)
+	bits := f.api.ToBinary(commitment)
+	nbBits := int(f.params.nbBits)
+	res := make([]frontend.Variable, f.params.nbLimbs)
+	for i := range res {
+		start, end := i*nbBits, (i+1)*nbBits
+		if start >= len(bits) {
+			res[i] = 0
+			continue
+		}
+		if end > len(bits) {
+			end = len(bits)
+		}
+		res[i] = f.api.FromBinary(bits[start:end]...)
+	}
+	return Element{Limbs: res, internal: true, params: f.params}
+}
+
+func (f *fakeAPI) AssertIsEqual(i1, i2 frontend.Variable) {
+	els := f.varsToElements(i1, i2)
+	if cs, ok := f.constantValues(els...); ok {
+		if cs[0].Cmp(cs[1]) != 0 {
+			panic("nonnative: constants are not equal: " + cs[0].String() + " != " + cs[1].String())
+		}
+		return
+	}
+	var e Element
+	e.AssertIsEqual(f.api, els[0], els[1])
+}
+
+// AssertIsDifferent asserts that i1 and i2 are different modulo p. The solver
+// fails if i1 - i2 is not invertible.
+func (f *fakeAPI) AssertIsDifferent(i1, i2 frontend.Variable) {
+	var e Element
+	e.Inverse(f.api, f.Sub(i1, i2).(Element))
+}
+
+func (f *fakeAPI) AssertIsEqualIf(cond, i1, i2 frontend.Variable) {
+	f.AssertIsEqual(f.Mul(cond, f.Sub(i1, i2)), 0)
+}
+
+func (f *fakeAPI) AssertIsDifferentIf(cond, i1, i2 frontend.Variable) {
+	f.AssertIsDifferent(f.Select(cond, f.Sub(i1, i2), 1), 0)
+}
+
+func (f *fakeAPI) AssertIsBoolean(i1 frontend.Variable) {
+	f.toBit(i1)
+}
+
+// AssertIsLessOrEqual compares the canonical representatives of v and bound in
+// [0, p).
+func (f *fakeAPI) AssertIsLessOrEqual(v frontend.Variable, bound frontend.Variable) {
+	greater, _ := f.cmp(v, bound)
+	f.api.AssertIsEqual(greater.Limbs[0], 0)
+}
+
+// AssertIsLess compares the canonical representatives of v and bound in
+// [0, p).
+func (f *fakeAPI) AssertIsLess(v frontend.Variable, bound frontend.Variable) {
+	_, less := f.cmp(v, bound)
+	f.api.AssertIsEqual(less.Limbs[0], 1)
+}
+
+// AssertIsInRange compares the canonical representatives of v, lower and
+// upper in [0, p).
+func (f *fakeAPI) AssertIsInRange(v, lower, upper frontend.Variable) {
+	f.AssertIsLessOrEqual(lower, v)
+	f.AssertIsLessOrEqual(v, upper)
+}
+
+// bitToElement returns the element of value b, where b is a native boolean
+// variable.
+func (f *fakeAPI) bitToElement(b frontend.Variable) Element {
+	limbs := make([]frontend.Variable, f.params.nbLimbs)
+	limbs[0] = b
+	for i := 1; i < len(limbs); i++ {
+		limbs[i] = 0
+	}
+	return Element{Limbs: limbs, internal: true, params: f.params}
+}
+
+// toBit returns the native boolean variable of the value of v, and asserts
+// that v is 0 or 1 modulo p. The elements built by bitToElement are converted
+// without any reduction.
+func (f *fakeAPI) toBit(v frontend.Variable) frontend.Variable {
+	e := f.varToElement(v)
+	if e.overflow != 0 || !f.highLimbsAreZero(e) {
+		e.ReduceStrict(f.api, e)
+		for _, l := range e.Limbs[1:] {
+			f.api.AssertIsEqual(l, 0)
+		}
+	}
+	f.api.AssertIsBoolean(e.Limbs[0])
+	return e.Limbs[0]
+}
+
+// highLimbsAreZero returns true if all the limbs of e but the first are the
+// constant 0.
+func (f *fakeAPI) highLimbsAreZero(e Element) bool {
+	for _, l := range e.Limbs[1:] {
+		if c, ok := f.api.Compiler().ConstantValue(l); !ok || c.Sign() != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// cmp returns the elements (i1 > i2) and (i1 < i2), comparing the canonical
+// representatives in [0, p). The limbs are compared with the native Cmp, from
+// the most significant one.
+func (f *fakeAPI) cmp(i1, i2 frontend.Variable) (greater, less Element) {
+	var a, b Element
+	a.ReduceStrict(f.api, f.varToElement(i1))
+	b.ReduceStrict(f.api, f.varToElement(i2))
+
+	// c ∈ {-1, 0, 1} is the comparison of the most significant limbs which differ
+	var c frontend.Variable = 0
+	for i := len(a.Limbs) - 1; i >= 0; i-- {
+		ci := f.api.Cmp(a.Limbs[i], b.Limbs[i])
+		c = f.api.Add(c, f.api.Mul(f.api.Sub(1, f.api.Mul(c, c)), ci))
+	}
+
+	// greater = (c² + c) / 2, less = (c² - c) / 2
+	c2 := f.api.Mul(c, c)
+	greater = f.bitToElement(f.api.DivUnchecked(f.api.Add(c2, c), 2))
+	less = f.bitToElement(f.api.DivUnchecked(f.api.Sub(c2, c), 2))
+	return greater, less
+}
+
+// Println prints the limbs of the elements, least significant first.
+func (f *fakeAPI) Println(a ...frontend.Variable) {
+	var args []frontend.Variable
+	for i := range a {
+		switch v := a[i].(type) {
+		case Element:
+			args = append(args, v.Limbs...)
+		case *Element:
+			args = append(args, v.Limbs...)
+		default:
+			args = append(args, v)
+		}
+	}
+	f.api.Println(args...)
+}
+
+// Printf prints the limbs of the elements with the verb, least significant first.
+func (f *fakeAPI) Printf(format string, a ...frontend.Variable) {
+	f.api.Printf(format, a...)
+}
+
+// PrintfIf prints the limbs of the elements with the verb, least significant first.
+func (f *fakeAPI) PrintfIf(condition frontend.Variable, format string, a ...frontend.Variable) {
+	f.api.PrintfIf(condition, format, a...)
+}
+
+func (f *fakeAPI) Compiler() frontend.Compiler {
+	return f.api.Compiler()
+}
+
+func (f *fakeAPI) NewHint(hf hint.Function, nbOutputs int, inputs ...frontend.Variable) ([]frontend.Variable, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeAPI) Tag(name string) frontend.Tag {
+	return f.api.Compiler().Tag(name)
+}
+
+func (f *fakeAPI) AddCounter(from, to frontend.Tag) {
+	f.api.Compiler().AddCounter(from, to)
+}
+
+func (f *fakeAPI) ConstantValue(v frontend.Variable) (*big.Int, bool) {
+	return f.params.constantValue(f.api, f.varToElement(v))
+}
+
+func (f *fakeAPI) Curve() ecc.ID {
+	return f.api.Compiler().Curve()
+}
+
+func (f *fakeAPI) Backend() backend.ID {
+	return f.api.Compiler().Backend()
+}
@@ -0,0 +1,241 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nonnative
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+// polyCircuit evaluates x^3 + 3x - 5 / x with a generic frontend.API.
+type polyCircuit struct {
+	X, Y frontend.Variable
+}
+
+func (c *polyCircuit) Define(api frontend.API) error {
+	x3 := api.Mul(c.X, c.X, c.X)
+	res := api.Add(x3, api.Mul(3, c.X))
+	res = api.Sub(res, api.Div(5, c.X))
+	api.AssertIsEqual(res, c.Y)
+	return nil
+}
+
+type wrappedCircuit struct {
+	X, Y   Element
+	params *Params
+}
+
+func (c *wrappedCircuit) Define(api frontend.API) error {
+	wrapped, err := NewAPI(api, c.params)
+	if err != nil {
+		return err
+	}
+	inner := polyCircuit{X: c.X, Y: c.Y}
+	return inner.Define(wrapped)
+}
+
+func TestWrappedAPI(t *testing.T) {
+	assert := test.NewAssert(t)
+	params := testParams(t, 64)
+	p := params.Modulus()
+
+	x := randomElement(t)
+	y := new(big.Int).Exp(x, big.NewInt(3), p)
+	y.Add(y, new(big.Int).Mul(x, big.NewInt(3)))
+	inv := new(big.Int).ModInverse(x, p)
+	y.Sub(y, inv.Mul(inv, big.NewInt(5)))
+	y.Mod(y, p)
+
+	circuit := wrappedCircuit{X: params.Placeholder(), Y: params.Placeholder(), params: params}
+	witness := wrappedCircuit{X: params.ConstantFromBig(x), Y: params.ConstantFromBig(y), params: params}
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+
+	witness.Y = params.ConstantFromBig(new(big.Int).Add(y, big.NewInt(1)))
+	assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BN254))
+}
+
+// sumCircuit computes 2 * (7*6 + Σ_{i<n} x) - x with a generic
+// frontend.API, with long chains of additions and constant operands.
+type sumCircuit struct {
+	X, Y frontend.Variable
+	n    int
+}
+
+func (c *sumCircuit) Define(api frontend.API) error {
+	res := api.Mul(7, 6)
+	for i := 0; i < c.n; i++ {
+		res = api.Add(res, c.X)
+	}
+	res = api.Sub(api.Mul(res, 2), c.X)
+	api.AssertIsEqual(res, c.Y)
+	return nil
+}
+
+type wrappedSumCircuit struct {
+	X, Y   Element
+	params *Params
+}
+
+func (c *wrappedSumCircuit) Define(api frontend.API) error {
+	wrapped, err := NewAPI(api, c.params)
+	if err != nil {
+		return err
+	}
+	inner := sumCircuit{X: c.X, Y: c.Y, n: 300}
+	return inner.Define(wrapped)
+}
+
+func TestWrappedAPILazy(t *testing.T) {
+	assert := test.NewAssert(t)
+	params := testParams(t, 64)
+
+	x := randomElement(t)
+	y := new(big.Int).Mul(x, big.NewInt(300))
+	y.Add(y, big.NewInt(42)).Lsh(y, 1).Sub(y, x)
+
+	circuit := wrappedSumCircuit{X: params.Placeholder(), Y: params.Placeholder(), params: params}
+	witness := wrappedSumCircuit{X: params.ConstantFromBig(x), Y: params.ConstantFromBig(y), params: params}
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+
+	witness.Y = params.ConstantFromBig(new(big.Int).Add(y, big.NewInt(1)))
+	assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BN254))
+}
+
+// bitsCircuit exercises the binary operations and the comparisons with a
+// generic frontend.API.
+type bitsCircuit struct {
+	X, Y frontend.Variable
+}
+
+func (c *bitsCircuit) Define(api frontend.API) error {
+	// X > Y
+	api.AssertIsEqual(api.Cmp(c.X, c.Y), 1)
+	api.AssertIsEqual(api.Cmp(c.Y, c.X), -1)
+	api.AssertIsEqual(api.Cmp(c.X, c.X), 0)
+	api.AssertIsEqual(api.IsLess(c.Y, c.X), 1)
+	api.AssertIsLessOrEqual(c.Y, c.X)
+	api.AssertIsLess(c.Y, c.X)
+	api.AssertIsDifferent(c.X, c.Y)
+
+	api.AssertIsEqual(api.IsZero(api.Sub(c.X, c.X)), 1)
+	api.AssertIsEqual(api.IsZero(c.X), 0)
+
+	xBits := api.ToBinary(c.X)
+	api.AssertIsEqual(api.FromBinary(xBits...), c.X)
+	yBits := api.ToBinary(c.Y, 16)
+	api.AssertIsEqual(api.FromBinary(yBits...), c.Y)
+
+	b := api.Xor(xBits[0], yBits[0])
+	api.AssertIsBoolean(b)
+	api.AssertIsEqual(api.Select(b, c.X, c.Y), api.Lookup2(xBits[0], yBits[0], c.Y, c.X, c.X, c.Y))
+	api.AssertIsEqual(api.Or(xBits[0], yBits[0]), api.Add(api.Xor(xBits[0], yBits[0]), api.And(xBits[0], yBits[0])))
+
+	// Y = 0xbeef
+	api.AssertIsInRange(c.Y, 0xbeef, c.X)
+	api.AssertIsEqual(api.IsInRange(c.X, 0, c.Y), 0)
+	y3 := api.Mul(c.Y, c.Y, c.Y)
+	api.AssertIsEqual(api.Exp(c.Y, 3), y3)
+	api.AssertIsEqual(api.Exp(c.Y, api.Add(yBits[0], 2), 2), y3)
+	api.AssertIsEqual(api.WordXor(c.Y, 0xffff, 16), 0x4110)
+	api.AssertIsEqual(api.WordAnd(c.Y, 0xff00, 16), 0xbe00)
+	api.AssertIsEqual(api.WordRotateLeft(c.Y, 3, 16), 0xf77d)
+	return nil
+}
+
+type wrappedBitsCircuit struct {
+	X, Y   Element
+	params *Params
+}
+
+func (c *wrappedBitsCircuit) Define(api frontend.API) error {
+	wrapped, err := NewAPI(api, c.params)
+	if err != nil {
+		return err
+	}
+	inner := bitsCircuit{X: c.X, Y: c.Y}
+	return inner.Define(wrapped)
+}
+
+func TestWrappedAPIBits(t *testing.T) {
+	assert := test.NewAssert(t)
+	params := testParams(t, 64)
+
+	x := randomElement(t)
+	y := big.NewInt(0xbeef)
+	if x.Cmp(y) <= 0 {
+		x.Add(x, y)
+	}
+
+	circuit := wrappedBitsCircuit{X: params.Placeholder(), Y: params.Placeholder(), params: params}
+	witness := wrappedBitsCircuit{X: params.ConstantFromBig(x), Y: params.ConstantFromBig(y), params: params}
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+
+	// Y does not fit on 16 bits
+	witness.Y = params.ConstantFromBig(big.NewInt(1 << 16))
+	assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BN254))
+}
+
+type wrappedCommitCircuit struct {
+	A, B   [3]Element
+	params *Params
+}
+
+func (c *wrappedCommitCircuit) Define(api frontend.API) error {
+	wrapped, err := NewAPI(api, c.params)
+	if err != nil {
+		return err
+	}
+	// B is a permutation of A iff prod(A_i + r) == prod(B_i + r) for a random r
+	vars := make([]frontend.Variable, 0, len(c.A)+len(c.B))
+	for i := range c.A {
+		vars = append(vars, c.A[i], c.B[i])
+	}
+	r := wrapped.Commit(vars...)
+	prodA, prodB := frontend.Variable(1), frontend.Variable(1)
+	for i := range c.A {
+		prodA = wrapped.Mul(prodA, wrapped.Add(c.A[i], r))
+		prodB = wrapped.Mul(prodB, wrapped.Add(c.B[i], r))
+	}
+	wrapped.AssertIsEqual(prodA, prodB)
+	return nil
+}
+
+func TestWrappedAPICommit(t *testing.T) {
+	assert := test.NewAssert(t)
+	params := testParams(t, 64)
+
+	var circuit, witness wrappedCommitCircuit
+	circuit.params, witness.params = params, params
+	values := make([]*big.Int, len(circuit.A))
+	for i := range values {
+		values[i] = randomElement(t)
+		circuit.A[i], circuit.B[i] = params.Placeholder(), params.Placeholder()
+	}
+	for i := range values {
+		witness.A[i] = params.ConstantFromBig(values[i])
+		witness.B[i] = params.ConstantFromBig(values[(i+1)%len(values)])
+	}
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+
+	// B is not a permutation of A
+	witness.B[0] = params.ConstantFromBig(new(big.Int).Add(values[1], big.NewInt(1)))
+	assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BN254))
+}
@@ -0,0 +1,258 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package uints provides ZKP-circuit functions for the arithmetic of 32-bit
+// and 64-bit unsigned words, as needed by the hash functions and ciphers
+// working on words (SHA-2, BLAKE2, ChaCha, ...).
+//
+// The words are stored as little-endian bytes, each byte stored in a
+// frontend.Variable. All the functions returning words constrain their bytes
+// to be in [0, 256), and expect their inputs to be constrained so; words can
+// thus also be built directly from bytes already range checked, such as the
+// bytes of the input of a hash gadget.
+//
+// The strategy depends on the builder:
+//   - if it supports lookup tables (see frontend.LookupAPI), the bytes are
+//     range checked with a table of 256 entries, and the bitwise operations
+//     are lookups in tables of 65536 entries indexed by pairs of bytes, which
+//     costs a constraint per byte;
+//   - otherwise, the words are decomposed in bits when needed, which costs
+//     about a constraint per bit.
+//
+// Additions are performed in the native field before being decomposed back
+// into bytes, and rotations and shifts by multiples of 8 bits are free.
+package uints
+
+import (
+	"github.com/consensys/gnark/frontend"
+)
+
+// U32 is a 32-bit word, stored as its little-endian bytes.
+type U32 [4]frontend.Variable
+
+// U64 is a 64-bit word, stored as its little-endian bytes.
+type U64 [8]frontend.Variable
+
+// BinaryField provides the operations on U32 and U64 words.
+type BinaryField struct {
+	api    frontend.API
+	lookup bool // the builder supports lookup tables
+}
+
+// New returns a BinaryField adding its constraints to api.
+func New(api frontend.API) *BinaryField {
+	_, lookup := api.(frontend.LookupAPI)
+	return &BinaryField{api: api, lookup: lookup}
+}
+
+// ConstU32 returns the constant word v.
+func (bf *BinaryField) ConstU32(v uint32) U32 {
+	var res U32
+	for i := range res {
+		res[i] = (v >> (8 * i)) & 0xff
+	}
+	return res
+}
+
+// ConstU64 returns the constant word v.
+func (bf *BinaryField) ConstU64(v uint64) U64 {
+	var res U64
+	for i := range res {
+		res[i] = (v >> (8 * i)) & 0xff
+	}
+	return res
+}
+
+// ValueOfU32 returns the word of value v, and asserts that v < 2³².
+func (bf *BinaryField) ValueOfU32(v frontend.Variable) U32 {
+	b, _ := bf.unpack(v, 4, 0)
+	return toU32(b)
+}
+
+// ValueOfU64 returns the word of value v, and asserts that v < 2⁶⁴.
+func (bf *BinaryField) ValueOfU64(v frontend.Variable) U64 {
+	b, _ := bf.unpack(v, 8, 0)
+	return toU64(b)
+}
+
+// ByteValueOf returns v, and asserts that v is in [0, 256). It is meant to
+// build words from bytes which are not range checked yet.
+func (bf *BinaryField) ByteValueOf(v frontend.Variable) frontend.Variable {
+	b, _ := bf.unpack(v, 1, 0)
+	return b[0]
+}
+
+// ToValueU32 returns the value of the word x.
+func (bf *BinaryField) ToValueU32(x U32) frontend.Variable {
+	return bf.pack(x[:])
+}
+
+// ToValueU64 returns the value of the word x.
+func (bf *BinaryField) ToValueU64(x U64) frontend.Variable {
+	return bf.pack(x[:])
+}
+
+// AddU32 returns the sum of the words modulo 2³².
+func (bf *BinaryField) AddU32(xs ...U32) U32 {
+	words := make([][]frontend.Variable, len(xs))
+	for i := range xs {
+		words[i] = xs[i][:]
+	}
+	res, _ := bf.add(4, words...)
+	return toU32(res)
+}
+
+// AddU64 returns the sum of the words modulo 2⁶⁴.
+func (bf *BinaryField) AddU64(xs ...U64) U64 {
+	words := make([][]frontend.Variable, len(xs))
+	for i := range xs {
+		words[i] = xs[i][:]
+	}
+	res, _ := bf.add(8, words...)
+	return toU64(res)
+}
+
+// AddCarryU32 returns x + y + carry modulo 2³², and the carry out. carry
+// must be 0 or 1.
+func (bf *BinaryField) AddCarryU32(x, y U32, carry frontend.Variable) (U32, frontend.Variable) {
+	res, carryOut := bf.addCarry(x[:], y[:], carry)
+	return toU32(res), carryOut
+}
+
+// AddCarryU64 returns x + y + carry modulo 2⁶⁴, and the carry out. carry
+// must be 0 or 1.
+func (bf *BinaryField) AddCarryU64(x, y U64, carry frontend.Variable) (U64, frontend.Variable) {
+	res, carryOut := bf.addCarry(x[:], y[:], carry)
+	return toU64(res), carryOut
+}
+
+// XorU32 returns the exclusive or of the words.
+func (bf *BinaryField) XorU32(x U32, ys ...U32) U32 {
+	res := x[:]
+	for _, y := range ys {
+		res = bf.bitwise(&xorTable, bf.xor, res, y[:])
+	}
+	return toU32(res)
+}
+
+// XorU64 returns the exclusive or of the words.
+func (bf *BinaryField) XorU64(x U64, ys ...U64) U64 {
+	res := x[:]
+	for _, y := range ys {
+		res = bf.bitwise(&xorTable, bf.xor, res, y[:])
+	}
+	return toU64(res)
+}
+
+// AndU32 returns x & y.
+func (bf *BinaryField) AndU32(x, y U32) U32 {
+	return toU32(bf.bitwise(&andTable, bf.and, x[:], y[:]))
+}
+
+// AndU64 returns x & y.
+func (bf *BinaryField) AndU64(x, y U64) U64 {
+	return toU64(bf.bitwise(&andTable, bf.and, x[:], y[:]))
+}
+
+// NotU32 returns ^x.
+func (bf *BinaryField) NotU32(x U32) U32 {
+	return toU32(bf.not(x[:]))
+}
+
+// NotU64 returns ^x.
+func (bf *BinaryField) NotU64(x U64) U64 {
+	return toU64(bf.not(x[:]))
+}
+
+// RotateLeftU32 returns x rotated left by (k mod 32) bits. To rotate right by
+// k bits, call RotateLeftU32(x, -k).
+func (bf *BinaryField) RotateLeftU32(x U32, k int) U32 {
+	return toU32(bf.rotateLeft(x[:], ((k%32)+32)%32))
+}
+
+// RotateLeftU64 returns x rotated left by (k mod 64) bits. To rotate right by
+// k bits, call RotateLeftU64(x, -k).
+func (bf *BinaryField) RotateLeftU64(x U64, k int) U64 {
+	return toU64(bf.rotateLeft(x[:], ((k%64)+64)%64))
+}
+
+// ShiftRightU32 returns x >> k, for k ≥ 0.
+func (bf *BinaryField) ShiftRightU32(x U32, k int) U32 {
+	if k >= 32 {
+		return bf.ConstU32(0)
+	}
+	return toU32(bf.shiftRight(x[:], k))
+}
+
+// ShiftRightU64 returns x >> k, for k ≥ 0.
+func (bf *BinaryField) ShiftRightU64(x U64, k int) U64 {
+	if k >= 64 {
+		return bf.ConstU64(0)
+	}
+	return toU64(bf.shiftRight(x[:], k))
+}
+
+// ShiftLeftU32 returns x << k, for k ≥ 0.
+func (bf *BinaryField) ShiftLeftU32(x U32, k int) U32 {
+	if k >= 32 {
+		return bf.ConstU32(0)
+	}
+	return toU32(bf.shiftLeft(x[:], k))
+}
+
+// ShiftLeftU64 returns x << k, for k ≥ 0.
+func (bf *BinaryField) ShiftLeftU64(x U64, k int) U64 {
+	if k >= 64 {
+		return bf.ConstU64(0)
+	}
+	return toU64(bf.shiftLeft(x[:], k))
+}
+
+// IsLessU32 returns 1 if x < y, 0 otherwise.
+func (bf *BinaryField) IsLessU32(x, y U32) frontend.Variable {
+	return bf.isLess(x[:], y[:])
+}
+
+// IsLessU64 returns 1 if x < y, 0 otherwise.
+func (bf *BinaryField) IsLessU64(x, y U64) frontend.Variable {
+	return bf.isLess(x[:], y[:])
+}
+
+// AssertEqU32 asserts that x == y.
+func (bf *BinaryField) AssertEqU32(x, y U32) {
+	for i := range x {
+		bf.api.AssertIsEqual(x[i], y[i])
+	}
+}
+
+// AssertEqU64 asserts that x == y.
+func (bf *BinaryField) AssertEqU64(x, y U64) {
+	for i := range x {
+		bf.api.AssertIsEqual(x[i], y[i])
+	}
+}
+
+func toU32(b []frontend.Variable) U32 {
+	var res U32
+	copy(res[:], b)
+	return res
+}
+
+func toU64(b []frontend.Variable) U64 {
+	var res U64
+	copy(res[:], b)
+	return res
+}
@@ -0,0 +1,132 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uints
+
+import (
+	stdbits "math/bits"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+type u32Circuit struct {
+	X, Y                                frontend.Variable
+	Add, AddCarry, Carry                frontend.Variable
+	Xor, And, Not                       frontend.Variable
+	Rotl, Rotr, Rotl8, Shr, Shl, IsLess frontend.Variable
+}
+
+func (c *u32Circuit) Define(api frontend.API) error {
+	bf := New(api)
+	x, y := bf.ValueOfU32(c.X), bf.ValueOfU32(c.Y)
+	k := bf.ConstU32(0x9e3779b9)
+
+	api.AssertIsEqual(bf.ToValueU32(bf.AddU32(x, y, k)), c.Add)
+	sum, carry := bf.AddCarryU32(x, y, 1)
+	api.AssertIsEqual(bf.ToValueU32(sum), c.AddCarry)
+	api.AssertIsEqual(carry, c.Carry)
+	api.AssertIsEqual(bf.ToValueU32(bf.XorU32(x, y, k)), c.Xor)
+	api.AssertIsEqual(bf.ToValueU32(bf.AndU32(x, y)), c.And)
+	api.AssertIsEqual(bf.ToValueU32(bf.NotU32(x)), c.Not)
+	api.AssertIsEqual(bf.ToValueU32(bf.RotateLeftU32(x, 13)), c.Rotl)
+	api.AssertIsEqual(bf.ToValueU32(bf.RotateLeftU32(x, -7)), c.Rotr)
+	api.AssertIsEqual(bf.ToValueU32(bf.RotateLeftU32(x, 8)), c.Rotl8)
+	api.AssertIsEqual(bf.ToValueU32(bf.ShiftRightU32(x, 10)), c.Shr)
+	api.AssertIsEqual(bf.ToValueU32(bf.ShiftLeftU32(x, 3)), c.Shl)
+	api.AssertIsEqual(bf.IsLessU32(x, y), c.IsLess)
+	bf.AssertEqU32(bf.XorU32(x, x), bf.ConstU32(0))
+	return nil
+}
+
+func u32Witness(x, y uint32) *u32Circuit {
+	const k = 0x9e3779b9
+	sum, carry := stdbits.Add32(x, y, 1)
+	isLess := 0
+	if x < y {
+		isLess = 1
+	}
+	return &u32Circuit{
+		X: x, Y: y,
+		Add: x + y + k, AddCarry: sum, Carry: carry,
+		Xor: x ^ y ^ k, And: x & y, Not: ^x,
+		Rotl: stdbits.RotateLeft32(x, 13), Rotr: stdbits.RotateLeft32(x, -7), Rotl8: stdbits.RotateLeft32(x, 8),
+		Shr: x >> 10, Shl: x << 3, IsLess: isLess,
+	}
+}
+
+func TestU32(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, v := range [][2]uint32{{0xdeadbeef, 0x12345678}, {0, 0xffffffff}, {0xffffffff, 0xffffffff}} {
+		assert.SolvingSucceeded(&u32Circuit{}, u32Witness(v[0], v[1]), test.WithCurves(ecc.BN254))
+	}
+
+	w := u32Witness(0xdeadbeef, 0x12345678)
+	w.Rotl = 0
+	assert.SolvingFailed(&u32Circuit{}, w, test.WithCurves(ecc.BN254))
+
+	// inputs out of range
+	w = u32Witness(0xdeadbeef, 0x12345678)
+	w.X = uint64(1) << 32
+	assert.SolvingFailed(&u32Circuit{}, w, test.WithCurves(ecc.BN254))
+}
+
+type u64Circuit struct {
+	X, Y                         frontend.Variable
+	Add, AddCarry, Carry         frontend.Variable
+	Xor, And, Not                frontend.Variable
+	Rotl, Rotr, Shr, Shl, IsLess frontend.Variable
+}
+
+func (c *u64Circuit) Define(api frontend.API) error {
+	bf := New(api)
+	x, y := bf.ValueOfU64(c.X), bf.ValueOfU64(c.Y)
+
+	api.AssertIsEqual(bf.ToValueU64(bf.AddU64(x, y, x, y, x)), c.Add)
+	sum, carry := bf.AddCarryU64(x, y, 0)
+	api.AssertIsEqual(bf.ToValueU64(sum), c.AddCarry)
+	api.AssertIsEqual(carry, c.Carry)
+	api.AssertIsEqual(bf.ToValueU64(bf.XorU64(x, y)), c.Xor)
+	api.AssertIsEqual(bf.ToValueU64(bf.AndU64(x, y)), c.And)
+	api.AssertIsEqual(bf.ToValueU64(bf.NotU64(y)), c.Not)
+	api.AssertIsEqual(bf.ToValueU64(bf.RotateLeftU64(x, 63)), c.Rotl)
+	api.AssertIsEqual(bf.ToValueU64(bf.RotateLeftU64(x, -24)), c.Rotr)
+	api.AssertIsEqual(bf.ToValueU64(bf.ShiftRightU64(x, 17)), c.Shr)
+	api.AssertIsEqual(bf.ToValueU64(bf.ShiftLeftU64(x, 40)), c.Shl)
+	api.AssertIsEqual(bf.IsLessU64(x, y), c.IsLess)
+	return nil
+}
+
+func TestU64(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	x, y := uint64(0xfedcba9876543210), uint64(0x0123456789abcdef)
+	sum, carry := stdbits.Add64(x, y, 0)
+	witness := u64Circuit{
+		X: x, Y: y,
+		Add: 3*x + 2*y, AddCarry: sum, Carry: carry,
+		Xor: x ^ y, And: x & y, Not: ^y,
+		Rotl: stdbits.RotateLeft64(x, 63), Rotr: stdbits.RotateLeft64(x, -24),
+		Shr: x >> 17, Shl: x << 40, IsLess: 0,
+	}
+	assert.SolvingSucceeded(&u64Circuit{}, &witness, test.WithCurves(ecc.BN254))
+
+	witness.IsLess = 1
+	assert.SolvingFailed(&u64Circuit{}, &witness, test.WithCurves(ecc.BN254))
+}
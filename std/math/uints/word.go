@@ -0,0 +1,309 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uints
+
+import (
+	"fmt"
+	"math/big"
+	stdbits "math/bits"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/rangecheck"
+)
+
+func init() {
+	hint.Register(SplitByte)
+}
+
+// tables of the lookup strategy: byteTable.Values[i] = i, and
+// xorTable.Values[256⋅a+b] = a ^ b, andTable.Values[256⋅a+b] = a & b
+var (
+	byteTable = frontend.Table{Name: "uints_byte"}
+	xorTable  = frontend.Table{Name: "uints_xor"}
+	andTable  = frontend.Table{Name: "uints_and"}
+)
+
+func init() {
+	for i := int64(0); i < 256; i++ {
+		byteTable.Values = append(byteTable.Values, big.NewInt(i))
+	}
+	xorTable.Values = make([]*big.Int, 256*256)
+	andTable.Values = make([]*big.Int, 256*256)
+	for a := 0; a < 256; a++ {
+		for b := 0; b < 256; b++ {
+			xorTable.Values[256*a+b] = byteTable.Values[a^b]
+			andTable.Values[256*a+b] = byteTable.Values[a&b]
+		}
+	}
+}
+
+// The words are handled as little-endian slices of bytes by the functions
+// below, which the methods of BinaryField wrap for U32 and U64.
+
+// pack returns Σ x[i]⋅256ⁱ
+func (bf *BinaryField) pack(x []frontend.Variable) frontend.Variable {
+	var res frontend.Variable = 0
+	coeff := big.NewInt(1)
+	for i := range x {
+		res = bf.api.Add(res, bf.api.Mul(x[i], coeff))
+		coeff = new(big.Int).Lsh(coeff, 8)
+	}
+	return res
+}
+
+// unpack returns the n little-endian bytes of v, and the carry v >> 8n, which
+// is constrained to have at most nbCarryBits bits.
+func (bf *BinaryField) unpack(v frontend.Variable, n, nbCarryBits int) ([]frontend.Variable, frontend.Variable) {
+	api := bf.api
+	if nbCarryBits > 8 {
+		panic(fmt.Sprintf("uints: carry of %d bits", nbCarryBits))
+	}
+
+	if c, ok := api.Compiler().ConstantValue(v); ok {
+		if c.Sign() < 0 || c.BitLen() > 8*n+nbCarryBits {
+			panic(fmt.Sprintf("uints: constant %s does not fit in %d bits", c.String(), 8*n+nbCarryBits))
+		}
+		res := make([]frontend.Variable, n)
+		for i := range res {
+			res[i] = new(big.Int).And(new(big.Int).Rsh(c, uint(8*i)), big.NewInt(0xff))
+		}
+		return res, new(big.Int).Rsh(c, uint(8*n))
+	}
+
+	if !bf.lookup {
+		b := bits.ToBinary(api, v, bits.WithNbDigits(8*n+nbCarryBits))
+		res := make([]frontend.Variable, n)
+		for i := range res {
+			res[i] = bits.FromBinary(api, b[8*i:8*i+8], bits.WithUnconstrainedInputs())
+		}
+		var carry frontend.Variable = 0
+		if nbCarryBits > 0 {
+			carry = bits.FromBinary(api, b[8*n:], bits.WithUnconstrainedInputs())
+		}
+		return res, carry
+	}
+
+	nbLimbs := n
+	if nbCarryBits > 0 {
+		nbLimbs++
+	}
+	limbs, err := api.Compiler().NewHint(rangecheck.Bytes, nbLimbs, v)
+	if err != nil {
+		panic(err)
+	}
+	for i := range limbs {
+		frontend.Lookup(api, &byteTable, limbs[i])
+	}
+	api.AssertIsEqual(bf.pack(limbs), v)
+
+	var carry frontend.Variable = 0
+	if nbCarryBits > 0 {
+		carry = limbs[n]
+		// the carry is in [0, 256), shifting it by the missing bits can't
+		// overflow
+		if nbCarryBits < 8 {
+			frontend.Lookup(api, &byteTable, api.Mul(carry, 1<<(8-nbCarryBits)))
+		}
+	}
+	return limbs[:n], carry
+}
+
+// add returns the sum of the words modulo 2^(8n), and the carry
+func (bf *BinaryField) add(n int, xs ...[]frontend.Variable) ([]frontend.Variable, frontend.Variable) {
+	if len(xs) == 0 {
+		panic("uints: no words to add")
+	}
+	var sum frontend.Variable = 0
+	for _, x := range xs {
+		sum = bf.api.Add(sum, bf.pack(x))
+	}
+	return bf.unpack(sum, n, stdbits.Len(uint(len(xs)-1)))
+}
+
+// addCarry returns x + y + carry modulo 2^(8n), and the carry out
+func (bf *BinaryField) addCarry(x, y []frontend.Variable, carry frontend.Variable) ([]frontend.Variable, frontend.Variable) {
+	bf.api.AssertIsBoolean(carry)
+	return bf.unpack(bf.api.Add(bf.pack(x), bf.pack(y), carry), len(x), 1)
+}
+
+// isLess returns 1 if x < y, 0 otherwise. x - y + 2^(8n) has a carry iff x ≥ y.
+func (bf *BinaryField) isLess(x, y []frontend.Variable) frontend.Variable {
+	offset := new(big.Int).Lsh(big.NewInt(1), uint(8*len(x)))
+	_, carry := bf.unpack(bf.api.Add(bf.api.Sub(bf.pack(x), bf.pack(y)), offset), len(x), 1)
+	return bf.api.Sub(1, carry)
+}
+
+// bitwise returns the bytes op(x[i], y[i]), with a lookup in t if the builder
+// supports it
+func (bf *BinaryField) bitwise(t *frontend.Table, op func(a, b frontend.Variable) frontend.Variable, x, y []frontend.Variable) []frontend.Variable {
+	api := bf.api
+	res := make([]frontend.Variable, len(x))
+	for i := range res {
+		if bf.lookup {
+			res[i] = frontend.Lookup(api, t, api.Add(api.Mul(x[i], 256), y[i]))
+			continue
+		}
+		xb := bits.ToBinary(api, x[i], bits.WithNbDigits(8))
+		yb := bits.ToBinary(api, y[i], bits.WithNbDigits(8))
+		for j := range xb {
+			xb[j] = op(xb[j], yb[j])
+		}
+		res[i] = bits.FromBinary(api, xb, bits.WithUnconstrainedInputs())
+	}
+	return res
+}
+
+// xor returns a ^ b = a + b - 2ab for boolean a and b
+func (bf *BinaryField) xor(a, b frontend.Variable) frontend.Variable {
+	return bf.api.Sub(bf.api.Add(a, b), bf.api.Mul(bf.api.Mul(a, b), 2))
+}
+
+// and returns a & b = ab for boolean a and b
+func (bf *BinaryField) and(a, b frontend.Variable) frontend.Variable {
+	return bf.api.Mul(a, b)
+}
+
+// not returns the bytes 255 - x[i]
+func (bf *BinaryField) not(x []frontend.Variable) []frontend.Variable {
+	res := make([]frontend.Variable, len(x))
+	for i := range res {
+		res[i] = bf.api.Sub(255, x[i])
+	}
+	return res
+}
+
+// split returns x >> s and x & (2ˢ-1) for a byte x and s in (0, 8)
+func (bf *BinaryField) split(x frontend.Variable, s int) (hi, lo frontend.Variable) {
+	api := bf.api
+	if c, ok := api.Compiler().ConstantValue(x); ok {
+		return new(big.Int).Rsh(c, uint(s)), new(big.Int).And(c, big.NewInt(1<<s-1))
+	}
+
+	if !bf.lookup {
+		b := bits.ToBinary(api, x, bits.WithNbDigits(8))
+		return bits.FromBinary(api, b[s:], bits.WithUnconstrainedInputs()), bits.FromBinary(api, b[:s], bits.WithUnconstrainedInputs())
+	}
+
+	res, err := api.Compiler().NewHint(SplitByte, 2, x, s)
+	if err != nil {
+		panic(err)
+	}
+	hi, lo = res[0], res[1]
+	// hi < 2^(8-s) and lo < 2^s
+	frontend.Lookup(api, &byteTable, api.Mul(hi, 1<<s))
+	frontend.Lookup(api, &byteTable, api.Mul(lo, 1<<(8-s)))
+	api.AssertIsEqual(api.Add(api.Mul(hi, 1<<s), lo), x)
+	return hi, lo
+}
+
+// rotateLeft returns x rotated left by k bits, k being in [0, 8⋅len(x))
+func (bf *BinaryField) rotateLeft(x []frontend.Variable, k int) []frontend.Variable {
+	n := len(x)
+	q, r := k/8, k%8
+	res := make([]frontend.Variable, n)
+	if r == 0 {
+		for j := range res {
+			res[j] = x[(j-q+n)%n]
+		}
+		return res
+	}
+
+	// byte j is made of the 8-r low bits of x[j-q] shifted by r, and of the r
+	// high bits of x[j-q-1]
+	hi, lo := bf.splitAll(x, 8-r)
+	for j := range res {
+		res[j] = bf.api.Add(bf.api.Mul(lo[(j-q+n)%n], 1<<r), hi[(j-q-1+2*n)%n])
+	}
+	return res
+}
+
+// shiftRight returns x >> k
+func (bf *BinaryField) shiftRight(x []frontend.Variable, k int) []frontend.Variable {
+	n := len(x)
+	q, r := k/8, k%8
+	res := make([]frontend.Variable, n)
+	for j := range res {
+		res[j] = 0
+	}
+	if r == 0 {
+		for j := 0; j+q < n; j++ {
+			res[j] = x[j+q]
+		}
+		return res
+	}
+
+	// byte j is made of the 8-r high bits of x[j+q], and of the r low bits of
+	// x[j+q+1] shifted by 8-r
+	hi, lo := bf.splitAll(x, r)
+	for j := 0; j+q < n; j++ {
+		res[j] = hi[j+q]
+		if j+q+1 < n {
+			res[j] = bf.api.Add(res[j], bf.api.Mul(lo[j+q+1], 1<<(8-r)))
+		}
+	}
+	return res
+}
+
+// shiftLeft returns x << k, truncated to len(x) bytes
+func (bf *BinaryField) shiftLeft(x []frontend.Variable, k int) []frontend.Variable {
+	n := len(x)
+	q, r := k/8, k%8
+	res := make([]frontend.Variable, n)
+	for j := range res {
+		res[j] = 0
+	}
+	if r == 0 {
+		for j := q; j < n; j++ {
+			res[j] = x[j-q]
+		}
+		return res
+	}
+
+	// byte j is made of the 8-r low bits of x[j-q] shifted by r, and of the r
+	// high bits of x[j-q-1]
+	hi, lo := bf.splitAll(x, 8-r)
+	for j := q; j < n; j++ {
+		res[j] = bf.api.Mul(lo[j-q], 1<<r)
+		if j-q-1 >= 0 {
+			res[j] = bf.api.Add(res[j], hi[j-q-1])
+		}
+	}
+	return res
+}
+
+// splitAll splits all the bytes of x at the bit s, see split
+func (bf *BinaryField) splitAll(x []frontend.Variable, s int) (hi, lo []frontend.Variable) {
+	hi, lo = make([]frontend.Variable, len(x)), make([]frontend.Variable, len(x))
+	for i := range x {
+		hi[i], lo[i] = bf.split(x[i], s)
+	}
+	return hi, lo
+}
+
+// SplitByte returns x >> s and x & (2ˢ-1) for the inputs x and s.
+func SplitByte(_ ecc.ID, inputs []*big.Int, results []*big.Int) error {
+	if len(inputs) != 2 || len(results) != 2 {
+		return fmt.Errorf("SplitByte expects 2 inputs and 2 results")
+	}
+	s := uint(inputs[1].Uint64())
+	results[0].Rsh(inputs[0], s)
+	results[1].And(inputs[0], new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), s), big.NewInt(1)))
+	return nil
+}
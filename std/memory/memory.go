@@ -0,0 +1,253 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package memory provides a ZKP-circuit random access memory, which can be
+// read and written at addresses only known at solving time.
+//
+// Every access to the memory is recorded in a transcript of (address, time,
+// value, isWrite) tuples, where the time is the index of the access. When the
+// circuit is done with the memory, Finalize checks the consistency of the
+// transcript:
+//   - a hint sorts the transcript by address, then by time;
+//   - the sorted transcript is proven to be a permutation of the transcript,
+//     using a random challenge obtained from api.Commit;
+//   - in the sorted transcript, a read must return the value of the previous
+//     access to the same address, and the first access to an address must be a
+//     write.
+//
+// As a circuit can commit only once, it can use only one Memory, and can't
+// call api.Commit itself.
+package memory
+
+import (
+	"errors"
+	"math/big"
+	stdbits "math/bits"
+	"sort"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/math/bits"
+)
+
+func init() {
+	hint.Register(LoadValue)
+	hint.Register(SortTranscript)
+}
+
+// access is an entry of the memory transcript
+type access struct {
+	addr, time, value, isWrite frontend.Variable
+}
+
+// Memory is a random access memory of fixed size, whose cells are
+// frontend.Variable.
+type Memory struct {
+	api        frontend.API
+	size       int
+	transcript []access
+	finalized  bool
+}
+
+// New returns a new Memory with size cells, with addresses in [0, size).
+// The cells are not initialized: they must be written (see Init and Store)
+// before being read.
+func New(api frontend.API, size int) *Memory {
+	if size <= 0 {
+		panic("memory: size must be positive")
+	}
+	return &Memory{api: api, size: size}
+}
+
+// Init writes values at addresses 0, 1, ..., len(values)-1
+func (m *Memory) Init(values ...frontend.Variable) {
+	if len(values) > m.size {
+		panic("memory: too many initial values")
+	}
+	for i := 0; i < len(values); i++ {
+		m.record(i, values[i], 1)
+	}
+}
+
+// Load returns the value stored at address addr.
+func (m *Memory) Load(addr frontend.Variable) frontend.Variable {
+	m.assertIsAddress(addr)
+
+	// the hint needs the previous writes to compute the value
+	inputs := []frontend.Variable{addr}
+	for _, a := range m.transcript {
+		if a.isWrite == 1 {
+			inputs = append(inputs, a.addr, a.value)
+		}
+	}
+	value, err := m.api.Compiler().NewHint(LoadValue, 1, inputs...)
+	if err != nil {
+		panic(err)
+	}
+	m.record(addr, value[0], 0)
+	return value[0]
+}
+
+// Store writes value at address addr.
+func (m *Memory) Store(addr, value frontend.Variable) {
+	m.assertIsAddress(addr)
+	m.record(addr, value, 1)
+}
+
+// Finalize adds the constraints ensuring that the values returned by Load
+// are consistent with the values written by Init and Store. It must be called
+// once, after the last access to the memory.
+func (m *Memory) Finalize() {
+	if m.finalized {
+		panic("memory: already finalized")
+	}
+	m.finalized = true
+	api := m.api
+
+	if len(m.transcript) == 0 {
+		return
+	}
+
+	n := len(m.transcript)
+
+	// sort the transcript by (address, time) out of the circuit
+	flat := make([]frontend.Variable, 0, 4*n)
+	for _, a := range m.transcript {
+		flat = append(flat, a.addr, a.time, a.value, a.isWrite)
+	}
+	res, err := api.Compiler().NewHint(SortTranscript, 4*n, flat...)
+	if err != nil {
+		panic(err)
+	}
+	sorted := make([]access, n)
+	for i := range sorted {
+		sorted[i] = access{addr: res[4*i], time: res[4*i+1], value: res[4*i+2], isWrite: res[4*i+3]}
+	}
+
+	// the challenges α and β are derived from a commitment to both transcripts
+	α := api.Commit(append(flat, res...)...)
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		panic(err)
+	}
+	h.Write(α)
+	β := h.Sum()
+
+	// Π(β - f(transcript[i])) == Π(β - f(sorted[i])), where f(a) = addr + α*time + α²*value + α³*isWrite
+	p, q := frontend.Variable(1), frontend.Variable(1)
+	for i := 0; i < n; i++ {
+		p = api.Mul(p, api.Sub(β, m.fingerprint(α, m.transcript[i])))
+		q = api.Mul(q, api.Sub(β, m.fingerprint(α, sorted[i])))
+	}
+	api.AssertIsEqual(p, q)
+
+	// the sorted transcript is strictly increasing in addr * 2^nbTimeBits + time
+	nbAddrBits := stdbits.Len(uint(m.size - 1))
+	nbTimeBits := stdbits.Len(uint(n - 1))
+	shift := new(big.Int).Lsh(big.NewInt(1), uint(nbTimeBits))
+
+	api.AssertIsEqual(sorted[0].isWrite, 1)
+	for i := 0; i < n; i++ {
+		api.AssertIsBoolean(sorted[i].isWrite)
+	}
+	for i := 1; i < n; i++ {
+		prev, cur := sorted[i-1], sorted[i]
+
+		// cur.key - prev.key - 1 ∈ [0, 2^(nbAddrBits+nbTimeBits))
+		δ := api.Sub(api.Mul(api.Sub(cur.addr, prev.addr), shift), prev.time, 1)
+		δ = api.Add(δ, cur.time)
+		bits.ToBinary(api, δ, bits.WithNbDigits(nbAddrBits+nbTimeBits))
+
+		// the first access to an address is a write
+		sameAddr := api.IsZero(api.Sub(cur.addr, prev.addr))
+		isRead := api.Sub(1, cur.isWrite)
+		api.AssertIsEqual(api.Mul(api.Sub(1, sameAddr), isRead), 0)
+
+		// a read returns the value of the previous access
+		api.AssertIsEqual(api.Mul(sameAddr, isRead, api.Sub(cur.value, prev.value)), 0)
+	}
+}
+
+// record appends an access at the current time to the transcript
+func (m *Memory) record(addr, value, isWrite frontend.Variable) {
+	if m.finalized {
+		panic("memory: access after Finalize")
+	}
+	m.transcript = append(m.transcript, access{
+		addr:    addr,
+		time:    len(m.transcript),
+		value:   value,
+		isWrite: isWrite,
+	})
+}
+
+func (m *Memory) assertIsAddress(addr frontend.Variable) {
+	m.api.AssertIsLessOrEqual(addr, m.size-1)
+}
+
+func (m *Memory) fingerprint(α frontend.Variable, a access) frontend.Variable {
+	api := m.api
+	r := api.Mul(a.isWrite, α)
+	r = api.Mul(api.Add(r, a.value), α)
+	r = api.Mul(api.Add(r, a.time), α)
+	return api.Add(r, a.addr)
+}
+
+// LoadValue returns the value stored at an address. The first input is the
+// address, followed by the (address, value) pairs of the writes to the memory,
+// in the order of the transcript. It fails if the address was never written.
+func LoadValue(_ ecc.ID, inputs []*big.Int, results []*big.Int) error {
+	addr := inputs[0]
+	found := false
+	for i := 1; i+1 < len(inputs); i += 2 {
+		if inputs[i].Cmp(addr) == 0 {
+			results[0].Set(inputs[i+1])
+			found = true
+		}
+	}
+	if !found {
+		return errors.New("memory: load from an uninitialized address")
+	}
+	return nil
+}
+
+// SortTranscript sorts a memory transcript, given as (address, time, value,
+// isWrite) tuples, by address and then by time.
+func SortTranscript(_ ecc.ID, inputs []*big.Int, results []*big.Int) error {
+	if len(inputs)%4 != 0 || len(results) != len(inputs) {
+		return errors.New("memory: invalid transcript length")
+	}
+	n := len(inputs) / 4
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		ai, aj := inputs[4*idx[i]], inputs[4*idx[j]]
+		if c := ai.Cmp(aj); c != 0 {
+			return c < 0
+		}
+		return inputs[4*idx[i]+1].Cmp(inputs[4*idx[j]+1]) < 0
+	})
+	for i, k := range idx {
+		for j := 0; j < 4; j++ {
+			results[4*i+j].Set(inputs[4*k+j])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/memory"
+	"github.com/consensys/gnark/test"
+)
+
+type memoryCircuit struct {
+	Init      [4]frontend.Variable
+	StoreAddr frontend.Variable
+	StoreVal  frontend.Variable
+	LoadAddr  [2]frontend.Variable
+	Loaded    [2]frontend.Variable `gnark:",public"`
+}
+
+func (c *memoryCircuit) Define(api frontend.API) error {
+	m := memory.New(api, 4)
+	m.Init(c.Init[:]...)
+	api.AssertIsEqual(m.Load(c.LoadAddr[0]), c.Loaded[0])
+	m.Store(c.StoreAddr, c.StoreVal)
+	api.AssertIsEqual(m.Load(c.LoadAddr[1]), c.Loaded[1])
+	m.Finalize()
+	return nil
+}
+
+func TestMemory(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	init := [4]frontend.Variable{10, 11, 12, 13}
+
+	assert.ProverSucceeded(&memoryCircuit{}, &memoryCircuit{
+		Init:      init,
+		StoreAddr: 2,
+		StoreVal:  42,
+		LoadAddr:  [2]frontend.Variable{2, 2},
+		Loaded:    [2]frontend.Variable{12, 42},
+	})
+	assert.ProverSucceeded(&memoryCircuit{}, &memoryCircuit{
+		Init:      init,
+		StoreAddr: 3,
+		StoreVal:  42,
+		LoadAddr:  [2]frontend.Variable{0, 1},
+		Loaded:    [2]frontend.Variable{10, 11},
+	})
+	assert.ProverFailed(&memoryCircuit{}, &memoryCircuit{
+		Init:      init,
+		StoreAddr: 2,
+		StoreVal:  42,
+		LoadAddr:  [2]frontend.Variable{2, 2},
+		Loaded:    [2]frontend.Variable{12, 12},
+	})
+}
@@ -0,0 +1,104 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mpt
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/keccak"
+)
+
+// Account is the state of an Ethereum account. Every element of StorageRoot
+// and CodeHash is a byte.
+type Account struct {
+	Nonce       frontend.Variable
+	Balance     frontend.Variable // the balance must fit in the scalar field
+	StorageRoot [32]frontend.Variable
+	CodeHash    [32]frontend.Variable
+}
+
+// VerifyAccount asserts that proof is a valid proof of the account of the
+// given address in the state trie of root stateRoot, and returns the account.
+func VerifyAccount(api frontend.API, stateRoot [32]frontend.Variable, address [20]frontend.Variable, proof Proof) Account {
+	value, length := VerifyProof(api, stateRoot, hashKey(api, address[:]), proof)
+
+	// the account is an RLP list of 68 to 110 bytes, whose header is 0xf8
+	// followed by the size of the payload
+	api.AssertIsEqual(value[0], 0xf8)
+	api.AssertIsEqual(api.Add(value[1], 2), length)
+
+	value = append(value, make([]frontend.Variable, 32)...)
+	for j := MaxValueSize; j < len(value); j++ {
+		value[j] = 0
+	}
+	items := make([]item, 4)
+	var offset frontend.Variable = 2
+	for k := range items {
+		items[k] = parseItem(api, value, offset)
+		offset = items[k].next
+	}
+	api.AssertIsEqual(offset, length)
+	api.AssertIsEqual(items[2].length, 32)
+	api.AssertIsEqual(items[3].length, 32)
+
+	var account Account
+	account.Nonce = toUint(api, value, items[0].offset, items[0].length)
+	account.Balance = toUint(api, value, items[1].offset, items[1].length)
+	copy(account.StorageRoot[:], newSelector(api, items[2].offset, len(value)).window(api, value, 32))
+	copy(account.CodeHash[:], newSelector(api, items[3].offset, len(value)).window(api, value, 32))
+	return account
+}
+
+// VerifyStorage asserts that proof is a valid proof of the storage slot in
+// the storage trie of root storageRoot, and returns the 32 bytes of the value
+// of the slot, big-endian.
+func VerifyStorage(api frontend.API, storageRoot [32]frontend.Variable, slot [32]frontend.Variable, proof Proof) [32]frontend.Variable {
+	value, length := VerifyProof(api, storageRoot, hashKey(api, slot[:]), proof)
+
+	// the value is an RLP string of at most 32 bytes, without its leading
+	// zeroes. It is right-aligned after 32 zeroes, and the window of 32 bytes
+	// ending with it is the value.
+	v := parseItem(api, value, 0)
+	api.AssertIsEqual(v.next, length)
+	api.AssertIsLessOrEqual(v.length, 32)
+
+	padded := make([]frontend.Variable, 32+len(value))
+	for j := range padded {
+		padded[j] = 0
+		if j >= 32 {
+			padded[j] = value[j-32]
+		}
+	}
+	w := newSelector(api, api.Add(v.offset, v.length), len(padded)-31).window(api, padded, 32)
+
+	// the bytes of the window before the string belong to the header
+	lt := newSelector(api, api.Sub(32, v.length), 33).lessThan(api)
+	var res [32]frontend.Variable
+	for j := range res {
+		res[j] = api.Mul(api.Sub(1, lt[j]), w[j])
+	}
+	return res
+}
+
+// hashKey returns the key of data in a secure trie, that is its Keccak-256
+// digest
+func hashKey(api frontend.API, data []frontend.Variable) [32]frontend.Variable {
+	h := keccak.NewLegacyKeccak256(api)
+	h.Write(data...)
+	var key [32]frontend.Variable
+	copy(key[:], h.Sum())
+	return key
+}
@@ -0,0 +1,275 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mpt provides ZKP-circuit functions to verify inclusion proofs in the
+// Merkle-Patricia-Tries of Ethereum, as returned by the eth_getProof RPC
+// method: account proofs in the state trie, and storage proofs in the storage
+// trie of an account.
+//
+// A proof is the list of the RLP-encoded nodes on the path from the root of
+// the trie to the leaf holding the value. The nodes are hashed with
+// Keccak-256 and decoded in the circuit, at a cost which only depends on the
+// maximum number of nodes of the proof: each node costs four Keccak-f[1600]
+// permutations plus the RLP decoding of a node of MaxNodeSize bytes.
+//
+// Only the secure tries of Ethereum, whose keys are Keccak-256 digests, are
+// supported. The nodes embedded in their parent (whose encoding is shorter
+// than 32 bytes) are not supported, and neither are exclusion proofs.
+package mpt
+
+import (
+	"errors"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/keccak"
+	"github.com/consensys/gnark/std/math/bits"
+)
+
+const (
+	// MaxNodeSize is the maximum size of an RLP-encoded node: a branch node
+	// with 16 children hashes and an empty value.
+	MaxNodeSize = 3 + 16*33 + 1
+
+	// MaxValueSize is the maximum size of a value returned by VerifyProof: an
+	// RLP-encoded account with a nonce of 8 bytes and a balance of 32 bytes.
+	MaxValueSize = 2 + 9 + 33 + 33 + 33
+)
+
+// Node is an RLP-encoded node of a proof.
+type Node struct {
+	Bytes  [MaxNodeSize]frontend.Variable // encoding, padded with zeroes
+	Length frontend.Variable              // size of the encoding in bytes
+}
+
+// Proof is an inclusion proof in a Merkle-Patricia-Trie.
+type Proof struct {
+	Nodes []Node            // nodes from the root to the leaf, padded with empty nodes
+	Depth frontend.Variable // number of nodes of the proof, in [1, len(Nodes)]
+}
+
+// NewProof returns a placeholder for a proof of at most maxDepth nodes, to be
+// used in the definition of a circuit.
+func NewProof(maxDepth int) Proof {
+	return Proof{Nodes: make([]Node, maxDepth)}
+}
+
+// AssignProof returns the assignment of the proof made of the given nodes,
+// from the root to the leaf, for a circuit accepting at most maxDepth nodes.
+func AssignProof(nodes [][]byte, maxDepth int) (Proof, error) {
+	if len(nodes) == 0 || len(nodes) > maxDepth {
+		return Proof{}, errors.New("invalid number of nodes")
+	}
+	p := NewProof(maxDepth)
+	p.Depth = len(nodes)
+	for i := range p.Nodes {
+		var node []byte
+		if i < len(nodes) {
+			node = nodes[i]
+		}
+		if len(node) > MaxNodeSize {
+			return Proof{}, errors.New("node too large")
+		}
+		p.Nodes[i].Length = len(node)
+		for j := range p.Nodes[i].Bytes {
+			p.Nodes[i].Bytes[j] = 0
+			if j < len(node) {
+				p.Nodes[i].Bytes[j] = node[j]
+			}
+		}
+	}
+	return p, nil
+}
+
+// VerifyProof asserts that proof is a valid inclusion proof of the key (a
+// Keccak-256 digest) in the trie of the given root hash, and returns the bytes
+// of the value of the key, padded with zeroes to MaxValueSize, and its size.
+func VerifyProof(api frontend.API, root, key [32]frontend.Variable, proof Proof) (value []frontend.Variable, length frontend.Variable) {
+	maxDepth := len(proof.Nodes)
+	if maxDepth == 0 {
+		panic("mpt: empty proof")
+	}
+
+	// nibbles of the key, high nibble first
+	nibbles := make([]frontend.Variable, 64)
+	for i := range key {
+		b := bits.ToBinary(api, key[i], bits.WithNbDigits(8))
+		nibbles[2*i] = bits.FromBinary(api, b[4:], bits.WithUnconstrainedInputs())
+		nibbles[2*i+1] = bits.FromBinary(api, b[:4], bits.WithUnconstrainedInputs())
+	}
+
+	// isLast[i] = (i == Depth-1), active[i] = (i < Depth)
+	isLast := newSelector(api, api.Sub(proof.Depth, 1), maxDepth)
+	active := make([]frontend.Variable, maxDepth)
+	var Σlast frontend.Variable = 0
+	for i := maxDepth - 1; i >= 0; i-- {
+		Σlast = api.Add(Σlast, isLast[i])
+		active[i] = Σlast
+	}
+
+	hash := root[:]
+	var pos frontend.Variable = 0 // number of nibbles of the key consumed
+	steps := make([]step, maxDepth)
+	for i, node := range proof.Nodes {
+		steps[i] = verifyNode(api, node, hash, nibbles, pos, active[i], isLast[i])
+		hash, pos = steps[i].hash, steps[i].pos
+	}
+
+	// the value of the key is the second item of the leaf
+	last := make([]frontend.Variable, len(steps[0].data))
+	for j := range last {
+		last[j] = 0
+		for i := range steps {
+			last[j] = api.Add(last[j], api.Mul(isLast[i], steps[i].data[j]))
+		}
+	}
+	var offset, size frontend.Variable = 0, 0
+	for i := range steps {
+		offset = api.Add(offset, api.Mul(isLast[i], steps[i].value.offset))
+		size = api.Add(size, api.Mul(isLast[i], steps[i].value.length))
+	}
+	api.AssertIsLessOrEqual(size, MaxValueSize)
+	return newSelector(api, offset, len(last)).window(api, last, MaxValueSize), size
+}
+
+// step is the result of the verification of a node of a proof
+type step struct {
+	data  []frontend.Variable // bytes of the node, followed by zeroes
+	value item                // second item of the node, the value of a leaf
+	hash  []frontend.Variable // hash of the next node
+	pos   frontend.Variable   // number of nibbles of the key consumed by the next node
+}
+
+// verifyNode asserts that the node hashes to hash, and that it is a branch
+// or an extension matching the key from the nibble pos if it is not the last
+// node, or a leaf matching the end of the key otherwise. The assertions only
+// hold if the node is active, that is in the proof.
+func verifyNode(api frontend.API, node Node, hash, nibbles []frontend.Variable, pos, active, isLast frontend.Variable) step {
+	// the parsing of the items of a leaf or an extension as a branch reads up
+	// to 16 bytes beyond its end
+	data := make([]frontend.Variable, MaxNodeSize+17)
+	for j := range data {
+		data[j] = 0
+		if j < MaxNodeSize {
+			data[j] = node.Bytes[j]
+		}
+	}
+
+	// the bytes beyond the length are zeroes, and the nodes beyond the depth
+	// are empty
+	lt := newSelector(api, node.Length, MaxNodeSize+1).lessThan(api)
+	for j := 0; j < MaxNodeSize; j++ {
+		api.AssertIsEqual(api.Mul(api.Sub(1, lt[j]), node.Bytes[j]), 0)
+	}
+	assertIf(api, api.Sub(1, active), node.Length)
+
+	h := keccak.NewLegacyKeccak256(api).(*keccak.Digest)
+	digest := h.SumVariableLength(node.Bytes[:], node.Length)
+	for j := range digest {
+		assertIf(api, active, api.Sub(digest[j], hash[j]))
+	}
+
+	// list header:
+	// data[0] in [0xc0, 0xf7]: list of data[0]-0xc0 bytes
+	// data[0] == 0xf8: list of data[1] bytes
+	// data[0] == 0xf9: list of data[1]⋅256+data[2] bytes
+	b := bits.ToBinary(api, data[0], bits.WithNbDigits(8))
+	assertIf(api, active, api.Sub(2, api.Add(b[7], b[6])))
+	isLong := api.Mul(b[5], api.Mul(b[4], b[3]))
+	assertIf(api, isLong, api.Add(b[2], b[1]))
+	isLong2 := api.Mul(isLong, b[0])
+	headerLength := api.Add(1, isLong, isLong2)
+	payload := api.Add(
+		api.Mul(api.Sub(1, isLong), api.Sub(data[0], 0xc0)),
+		api.Mul(api.Sub(isLong, isLong2), data[1]),
+		api.Mul(isLong2, api.Add(api.Mul(data[1], 256), data[2])),
+	)
+	assertIf(api, active, api.Sub(api.Add(headerLength, payload), node.Length))
+
+	// a branch has 17 items, a leaf or an extension 2
+	items := make([]item, 17)
+	var offset frontend.Variable = headerLength
+	for k := range items {
+		items[k] = parseItem(api, data, offset)
+		offset = items[k].next
+	}
+	isBranch := api.IsZero(api.Sub(items[16].next, node.Length))
+	isShort := api.IsZero(api.Sub(items[1].next, node.Length))
+	assertIf(api, active, api.Sub(1, api.Add(isBranch, isShort)))
+
+	// key[j] = nibbles[pos+j]
+	ps := newSelector(api, pos, 65)
+	key := ps.window(api, nibbles, 64)
+
+	// branch: the child is the item of the next nibble of the key
+	assertIf(api, api.Mul(active, isBranch), ps[64])
+	cs := newSelector(api, key[0], 16)
+	var childOffset, childLength frontend.Variable = 0, 0
+	for k := range cs {
+		childOffset = api.Add(childOffset, api.Mul(cs[k], items[k].offset))
+		childLength = api.Add(childLength, api.Mul(cs[k], items[k].length))
+	}
+
+	// leaf or extension: the first item is the hex-prefix encoding of a part
+	// of the key. The high nibble of its first byte is 2 for a leaf, plus 1
+	// if the number of nibbles is odd, in which case the low nibble is the
+	// first nibble of the path; it is 0 otherwise.
+	path := newSelector(api, items[0].offset, len(data)).window(api, data, 33)
+	p0 := bits.ToBinary(api, path[0], bits.WithNbDigits(8))
+	isShortActive := api.Mul(active, isShort)
+	assertIf(api, isShortActive, api.Add(p0[7], p0[6]))
+	isLeaf := api.Mul(isShort, p0[5])
+	isOdd := p0[4]
+	lo := bits.FromBinary(api, p0[:4], bits.WithUnconstrainedInputs())
+	assertIf(api, api.Mul(isShortActive, api.Sub(1, isOdd)), lo)
+	nbNibbles := api.Mul(isShortActive, api.Add(api.Mul(2, api.Sub(items[0].length, 1)), isOdd))
+
+	odd := []frontend.Variable{lo}
+	var even []frontend.Variable
+	for k := 1; k < len(path); k++ {
+		pk := bits.ToBinary(api, path[k], bits.WithNbDigits(8))
+		hk := bits.FromBinary(api, pk[4:], bits.WithUnconstrainedInputs())
+		lk := bits.FromBinary(api, pk[:4], bits.WithUnconstrainedInputs())
+		odd = append(odd, hk, lk)
+		even = append(even, hk, lk)
+	}
+	mask := newSelector(api, nbNibbles, 65).lessThan(api)
+	for j := 0; j < 64; j++ {
+		api.AssertIsEqual(api.Mul(mask[j], api.Sub(key[j], api.Select(isOdd, odd[j], even[j]))), 0)
+	}
+
+	// the last node is the leaf matching the end of the key
+	assertIf(api, isLast, api.Sub(1, isLeaf))
+	assertIf(api, api.Sub(active, isLast), isLeaf)
+	assertIf(api, isLast, api.Sub(api.Add(pos, nbNibbles), 64))
+
+	// the other nodes reference the hash of the next one
+	next := api.Sub(active, isLast)
+	childOffset = api.Select(isBranch, childOffset, items[1].offset)
+	childLength = api.Select(isBranch, childLength, items[1].length)
+	assertIf(api, next, api.Sub(childLength, 32))
+
+	return step{
+		data:  data,
+		value: items[1],
+		hash:  newSelector(api, childOffset, len(data)).window(api, data, 32),
+		pos:   api.Select(next, api.Select(isBranch, api.Add(pos, 1), api.Add(pos, nbNibbles)), 0),
+	}
+}
+
+// assertIf asserts that x == 0 if cond == 1
+func assertIf(api frontend.API, cond, x frontend.Variable) {
+	api.AssertIsEqual(api.Mul(cond, x), 0)
+}
@@ -0,0 +1,281 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mpt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+const maxDepth = 4
+
+type mptCircuit struct {
+	StateRoot    [32]frontend.Variable `gnark:",public"`
+	Address      [20]frontend.Variable
+	AccountProof Proof
+	Nonce        frontend.Variable
+	Balance      frontend.Variable
+	Slot         [32]frontend.Variable
+	StorageProof Proof
+	Value        [32]frontend.Variable `gnark:",public"`
+}
+
+func (circuit *mptCircuit) Define(api frontend.API) error {
+	account := VerifyAccount(api, circuit.StateRoot, circuit.Address, circuit.AccountProof)
+	api.AssertIsEqual(account.Nonce, circuit.Nonce)
+	api.AssertIsEqual(account.Balance, circuit.Balance)
+
+	value := VerifyStorage(api, account.StorageRoot, circuit.Slot, circuit.StorageProof)
+	for i := range value {
+		api.AssertIsEqual(value[i], circuit.Value[i])
+	}
+	return nil
+}
+
+func TestVerifyAccountAndStorage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping MPT proof verification in short mode")
+	}
+	assert := require.New(t)
+
+	// storage trie: slot i holds i³ + 1, and slot 9 a full 32 bytes value
+	storage := newTrie()
+	var slots [][]byte
+	for i := 0; i < 10; i++ {
+		slot := make([]byte, 32)
+		slot[31] = byte(i)
+		v := big.NewInt(int64(i*i*i + 1)).Bytes()
+		if i == 9 {
+			v = keccak256([]byte("value"))
+		}
+		storage.insert(keccak256(slot), rlpString(v))
+		slots = append(slots, slot)
+	}
+	storageRoot, storageProofs := storage.build()
+
+	// state trie: the account 7 holds the storage
+	state := newTrie()
+	var addresses [][]byte
+	for i := 0; i < 10; i++ {
+		address := keccak256([]byte{byte(i)})[:20]
+		root := keccak256(rlpString(nil)) // empty trie
+		if i == 7 {
+			root = storageRoot
+		}
+		nonce := big.NewInt(int64(i * 3)).Bytes()
+		balance := new(big.Int).Lsh(big.NewInt(int64(i+1)), 100).Bytes()
+		account := rlpList(rlpString(nonce), rlpString(balance), rlpString(root), rlpString(keccak256(nil)))
+		state.insert(keccak256(address), account)
+		addresses = append(addresses, address)
+	}
+	stateRoot, accountProofs := state.build()
+
+	for _, i := range []int{1, 9} {
+		var witness mptCircuit
+		var err error
+		copy(witness.StateRoot[:], toVariables(stateRoot))
+		copy(witness.Address[:], toVariables(addresses[7]))
+		witness.AccountProof, err = AssignProof(accountProofs[string(keccak256(addresses[7]))], maxDepth)
+		assert.NoError(err)
+		witness.Nonce = 21
+		witness.Balance = new(big.Int).Lsh(big.NewInt(8), 100)
+		copy(witness.Slot[:], toVariables(slots[i]))
+		witness.StorageProof, err = AssignProof(storageProofs[string(keccak256(slots[i]))], maxDepth)
+		assert.NoError(err)
+		value := make([]byte, 32)
+		if i == 9 {
+			copy(value, keccak256([]byte("value")))
+		} else {
+			value[31] = byte(i*i*i + 1)
+		}
+		copy(witness.Value[:], toVariables(value))
+
+		circuit := mptCircuit{AccountProof: NewProof(maxDepth), StorageProof: NewProof(maxDepth)}
+		assert.NoError(test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16))
+
+		witness.Value[31] = value[31] + 1
+		assert.Error(test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16))
+	}
+}
+
+func toVariables(b []byte) []frontend.Variable {
+	res := make([]frontend.Variable, len(b))
+	for i := range b {
+		res[i] = b[i]
+	}
+	return res
+}
+
+func keccak256(data []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func rlpString(b []byte) []byte {
+	if len(b) == 1 && b[0] < 0x80 {
+		return b
+	}
+	return append(rlpHeader(0x80, len(b)), b...)
+}
+
+func rlpList(items ...[]byte) []byte {
+	var payload []byte
+	for _, item := range items {
+		payload = append(payload, item...)
+	}
+	return append(rlpHeader(0xc0, len(payload)), payload...)
+}
+
+func rlpHeader(offset byte, n int) []byte {
+	if n < 56 {
+		return []byte{offset + byte(n)}
+	}
+	l := big.NewInt(int64(n)).Bytes()
+	return append([]byte{offset + 55 + byte(len(l))}, l...)
+}
+
+// trie is a minimal secure Merkle-Patricia-Trie of RLP-encoded values, built
+// at once from its entries to get their proofs
+type trie struct {
+	keys   [][]byte // nibbles of the keys
+	values [][]byte
+}
+
+func newTrie() *trie {
+	return &trie{}
+}
+
+func (t *trie) insert(key, value []byte) {
+	nibbles := make([]byte, 0, 2*len(key))
+	for _, b := range key {
+		nibbles = append(nibbles, b>>4, b&0xf)
+	}
+	t.keys = append(t.keys, nibbles)
+	t.values = append(t.values, value)
+}
+
+// build returns the root hash of the trie, and the proofs of the entries
+// indexed by key
+func (t *trie) build() ([]byte, map[string][][]byte) {
+	entries := make([]int, len(t.keys))
+	for i := range entries {
+		entries[i] = i
+	}
+	proofs := make(map[string][][]byte)
+	root := t.node(entries, 0, proofs)
+	return keccak256(root), proofs
+}
+
+// node returns the encoding of the node of the given entries, whose keys
+// share their first depth nibbles, and prepends it to their proofs
+func (t *trie) node(entries []int, depth int, proofs map[string][][]byte) []byte {
+	var enc []byte
+	if len(entries) == 1 {
+		e := entries[0]
+		enc = rlpList(rlpString(hexPrefix(t.keys[e][depth:], true)), rlpString(t.values[e]))
+	} else if n := t.commonPrefix(entries, depth); n > 0 {
+		child := t.node(entries, depth+n, proofs)
+		enc = rlpList(rlpString(hexPrefix(t.keys[entries[0]][depth:depth+n], false)), rlpString(keccak256(child)))
+	} else {
+		var children [17][]byte
+		for nibble := range children[:16] {
+			var sub []int
+			for _, e := range entries {
+				if int(t.keys[e][depth]) == nibble {
+					sub = append(sub, e)
+				}
+			}
+			children[nibble] = rlpString(nil)
+			if len(sub) > 0 {
+				children[nibble] = rlpString(keccak256(t.node(sub, depth+1, proofs)))
+			}
+		}
+		children[16] = rlpString(nil)
+		enc = rlpList(children[:]...)
+	}
+	if len(enc) < 32 {
+		panic("embedded nodes are not supported")
+	}
+	for _, e := range entries {
+		key := string(fromNibbles(t.keys[e]))
+		proofs[key] = append([][]byte{enc}, proofs[key]...)
+	}
+	return enc
+}
+
+func (t *trie) commonPrefix(entries []int, depth int) int {
+	n := 0
+	for depth+n < len(t.keys[entries[0]]) {
+		for _, e := range entries[1:] {
+			if t.keys[e][depth+n] != t.keys[entries[0]][depth+n] {
+				return n
+			}
+		}
+		n++
+	}
+	return n
+}
+
+// hexPrefix returns the hex-prefix encoding of the nibbles
+func hexPrefix(nibbles []byte, leaf bool) []byte {
+	var flag byte
+	if leaf {
+		flag = 2
+	}
+	var res []byte
+	if len(nibbles)%2 == 1 {
+		res = append(res, (flag+1)<<4|nibbles[0])
+		nibbles = nibbles[1:]
+	} else {
+		res = append(res, flag<<4)
+	}
+	return append(res, fromNibbles(nibbles)...)
+}
+
+func fromNibbles(nibbles []byte) []byte {
+	res := make([]byte, len(nibbles)/2)
+	for i := range res {
+		res[i] = nibbles[2*i]<<4 | nibbles[2*i+1]
+	}
+	return res
+}
+
+func TestAssignProof(t *testing.T) {
+	assert := require.New(t)
+
+	_, err := AssignProof(nil, 2)
+	assert.Error(err)
+	_, err = AssignProof(make([][]byte, 3), 2)
+	assert.Error(err)
+	_, err = AssignProof([][]byte{make([]byte, MaxNodeSize+1)}, 2)
+	assert.Error(err)
+
+	p, err := AssignProof([][]byte{{1, 2, 3}}, 2)
+	assert.NoError(err)
+	assert.Equal(1, p.Depth)
+	assert.Equal(3, p.Nodes[0].Length)
+	assert.Equal(0, p.Nodes[1].Length)
+	assert.Equal(byte(2), p.Nodes[0].Bytes[1])
+}
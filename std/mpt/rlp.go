@@ -0,0 +1,119 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mpt
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/multiplexer"
+)
+
+// selector holds the indicators sᵢ = (sel == i) of an index sel in [0, n).
+type selector []frontend.Variable
+
+// newSelector returns the indicators of sel, which is constrained to be in
+// [0, n). They are constrained by sᵢ * (sel - i) == 0 and Σsᵢ == 1.
+func newSelector(api frontend.API, sel frontend.Variable, n int) selector {
+	s, err := api.Compiler().NewHint(multiplexer.Indicators, n, sel)
+	if err != nil {
+		panic(err)
+	}
+	var Σs frontend.Variable = 0
+	for i := range s {
+		api.AssertIsEqual(api.Mul(s[i], api.Sub(sel, i)), 0)
+		Σs = api.Add(Σs, s[i])
+	}
+	api.AssertIsEqual(Σs, 1)
+	return s
+}
+
+// read returns values[sel+offset], or 0 if it is beyond the end of values.
+func (s selector) read(api frontend.API, values []frontend.Variable, offset int) frontend.Variable {
+	var res frontend.Variable = 0
+	for i := range s {
+		if i+offset < len(values) {
+			res = api.Add(res, api.Mul(s[i], values[i+offset]))
+		}
+	}
+	return res
+}
+
+// window returns values[sel:sel+n], padded with zeroes beyond the end of
+// values.
+func (s selector) window(api frontend.API, values []frontend.Variable, n int) []frontend.Variable {
+	res := make([]frontend.Variable, n)
+	for j := range res {
+		res[j] = s.read(api, values, j)
+	}
+	return res
+}
+
+// lessThan returns the booleans (i < sel) for i in [0, len(s)).
+func (s selector) lessThan(api frontend.API) []frontend.Variable {
+	res := make([]frontend.Variable, len(s))
+	var Σs frontend.Variable = 0
+	for i := range s {
+		Σs = api.Add(Σs, s[i])
+		res[i] = api.Sub(1, Σs)
+	}
+	return res
+}
+
+// item is an RLP string of length bytes, starting at offset. next is the
+// offset of the following item.
+type item struct {
+	offset, length, next frontend.Variable
+}
+
+// parseItem returns the RLP string whose header starts at offset in data, and
+// which is followed by enough zeroes for the reads beyond the end of the
+// encoding. Lists (that is embedded nodes) and strings longer than 255 bytes
+// are not supported.
+func parseItem(api frontend.API, data []frontend.Variable, offset frontend.Variable) item {
+	s := newSelector(api, offset, len(data))
+	b0, b1 := s.read(api, data, 0), s.read(api, data, 1)
+
+	// b0 < 0x80: single byte
+	// b0 in [0x80, 0xb7]: string of b0-0x80 bytes
+	// b0 == 0xb8: string of b1 bytes
+	b := bits.ToBinary(api, b0, bits.WithNbDigits(8))
+	isString := b[7]
+	api.AssertIsEqual(api.Mul(b[7], b[6]), 0)
+	isLong := api.Mul(isString, api.Mul(b[5], api.Mul(b[4], b[3])))
+	api.AssertIsEqual(api.Mul(isLong, api.Add(b[2], b[1], b[0])), 0)
+
+	headerLength := api.Add(isString, isLong)
+	length := api.Add(
+		api.Sub(1, isString),
+		api.Mul(api.Sub(isString, isLong), api.Sub(b0, 0x80)),
+		api.Mul(isLong, b1),
+	)
+	start := api.Add(offset, headerLength)
+	return item{offset: start, length: length, next: api.Add(start, length)}
+}
+
+// toUint returns the big-endian integer data[offset:offset+length], for a
+// length of at most 32 bytes.
+func toUint(api frontend.API, data []frontend.Variable, offset, length frontend.Variable) frontend.Variable {
+	w := newSelector(api, offset, len(data)).window(api, data, 32)
+	lt := newSelector(api, length, 33).lessThan(api)
+	var res frontend.Variable = 0
+	for j := range w {
+		res = api.Select(lt[j], api.Add(api.Mul(res, 256), w[j]), res)
+	}
+	return res
+}
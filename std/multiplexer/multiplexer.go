@@ -0,0 +1,136 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package multiplexer provides ZKP-circuit functions to read an element of an
+// array at an index which is only known at solving time.
+//
+// Two strategies are provided:
+//   - Mux scans the whole array using indicator variables, and costs about
+//     2*len(inputs) constraints;
+//   - BinaryMux decomposes the index in bits and selects the input with
+//     api.LookupN, and costs about len(inputs) + log2(len(inputs)) constraints
+//     (R1CS).
+//
+// Both functions fail at solving time if the index is not in [0, len(inputs)).
+package multiplexer
+
+import (
+	"math/big"
+	stdbits "math/bits"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/bits"
+)
+
+func init() {
+	hint.Register(Indicators)
+}
+
+// Mux returns inputs[sel], using a linear scan of inputs.
+//
+// A hint computes the indicators bᵢ = (sel == i), which are constrained by
+// bᵢ * (sel - i) == 0 and Σbᵢ == 1. The result is then Σ bᵢ * inputs[i].
+func Mux(api frontend.API, sel frontend.Variable, inputs ...frontend.Variable) frontend.Variable {
+	if len(inputs) == 0 {
+		panic("multiplexer: no input")
+	}
+
+	// if sel is a constant, no constraint is needed.
+	if c, ok := api.Compiler().ConstantValue(sel); ok {
+		return inputs[constantIndex(c, len(inputs))]
+	}
+
+	indicators, err := api.Compiler().NewHint(Indicators, len(inputs), sel)
+	if err != nil {
+		panic(err)
+	}
+
+	var Σb, res frontend.Variable
+	Σb, res = 0, 0
+	for i := 0; i < len(inputs); i++ {
+		// bᵢ * (sel - i) == 0 → bᵢ == 0 for all i != sel
+		api.AssertIsEqual(api.Mul(indicators[i], api.Sub(sel, i)), 0)
+		Σb = api.Add(Σb, indicators[i])
+		res = api.Add(res, api.Mul(indicators[i], inputs[i]))
+	}
+
+	// Σbᵢ == 1 → b_sel == 1, and sel is in [0, len(inputs))
+	api.AssertIsEqual(Σb, 1)
+
+	return res
+}
+
+// BinaryMux returns inputs[sel], using a binary decomposition of sel.
+//
+// The bits of sel select the result with api.LookupN.
+func BinaryMux(api frontend.API, sel frontend.Variable, inputs ...frontend.Variable) frontend.Variable {
+	if len(inputs) == 0 {
+		panic("multiplexer: no input")
+	}
+
+	// if sel is a constant, no constraint is needed.
+	if c, ok := api.Compiler().ConstantValue(sel); ok {
+		return inputs[constantIndex(c, len(inputs))]
+	}
+
+	if len(inputs) == 1 {
+		api.AssertIsEqual(sel, 0)
+		return inputs[0]
+	}
+
+	nbBits := stdbits.Len(uint(len(inputs) - 1))
+	selBits := bits.ToBinary(api, sel, bits.WithNbDigits(nbBits))
+
+	// the decomposition ensures sel < 2^nbBits; if len(inputs) is not a power
+	// of 2, we need to bound it further.
+	if len(inputs) != 1<<nbBits {
+		api.AssertIsLessOrEqual(sel, len(inputs)-1)
+	}
+
+	// pad the inputs to 2^nbBits. The padding values are never selected.
+	values := make([]frontend.Variable, 1<<nbBits)
+	copy(values, inputs)
+	for i := len(inputs); i < len(values); i++ {
+		values[i] = 0
+	}
+
+	return api.LookupN(selBits, values)
+}
+
+// constantIndex returns c as an index of an array of length n, or panics if c
+// is out of range
+func constantIndex(c *big.Int, n int) int {
+	if !c.IsUint64() || c.Uint64() >= uint64(n) {
+		panic("multiplexer: constant selector out of range")
+	}
+	return int(c.Uint64())
+}
+
+// Indicators returns the indicators of the single input sel: the i-th output
+// is 1 if sel == i, 0 otherwise. The number of indicators is defined by the
+// length of the results slice.
+func Indicators(_ ecc.ID, inputs []*big.Int, results []*big.Int) error {
+	sel := inputs[0]
+	for i := 0; i < len(results); i++ {
+		results[i].SetUint64(0)
+	}
+	if sel.IsUint64() && sel.Uint64() < uint64(len(results)) {
+		results[sel.Uint64()].SetUint64(1)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package multiplexer_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/multiplexer"
+	"github.com/consensys/gnark/test"
+)
+
+type muxCircuit struct {
+	Sel    frontend.Variable
+	Inputs [5]frontend.Variable
+	Res    frontend.Variable
+}
+
+func (c *muxCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(multiplexer.Mux(api, c.Sel, c.Inputs[:]...), c.Res)
+	api.AssertIsEqual(multiplexer.BinaryMux(api, c.Sel, c.Inputs[:]...), c.Res)
+	return nil
+}
+
+func TestMux(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	inputs := [5]frontend.Variable{10, 11, 12, 13, 14}
+	for i := 0; i < len(inputs); i++ {
+		assert.ProverSucceeded(&muxCircuit{}, &muxCircuit{Sel: i, Inputs: inputs, Res: 10 + i})
+	}
+	assert.ProverFailed(&muxCircuit{}, &muxCircuit{Sel: 1, Inputs: inputs, Res: 12})
+	assert.ProverFailed(&muxCircuit{}, &muxCircuit{Sel: 5, Inputs: inputs, Res: 0})
+	assert.ProverFailed(&muxCircuit{}, &muxCircuit{Sel: -1, Inputs: inputs, Res: 0})
+}
+
+type binaryMuxCircuit struct {
+	Sel    frontend.Variable
+	Inputs [8]frontend.Variable
+	Res    frontend.Variable
+}
+
+func (c *binaryMuxCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(multiplexer.BinaryMux(api, c.Sel, c.Inputs[:]...), c.Res)
+	return nil
+}
+
+func TestBinaryMuxPowerOfTwo(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	inputs := [8]frontend.Variable{10, 11, 12, 13, 14, 15, 16, 17}
+	for i := 0; i < len(inputs); i++ {
+		assert.ProverSucceeded(&binaryMuxCircuit{}, &binaryMuxCircuit{Sel: i, Inputs: inputs, Res: 10 + i})
+	}
+	assert.ProverFailed(&binaryMuxCircuit{}, &binaryMuxCircuit{Sel: 8, Inputs: inputs, Res: 10})
+}
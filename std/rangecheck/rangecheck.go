@@ -0,0 +1,144 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package rangecheck provides a ZKP-circuit function to assert that variables
+// are in a range [0, 2^bitLen).
+//
+// The range checks of a circuit are collected by a Checker, and the
+// constraints are added when the Checker is finalized:
+//   - if the builder supports lookup tables (see frontend.LookupAPI), the
+//     values are decomposed in bytes, and all the bytes are checked against a
+//     single shared table of 256 entries, which costs about bitLen/8
+//     constraints per check;
+//   - otherwise, the values are decomposed in bits, which costs about bitLen
+//     constraints per check.
+package rangecheck
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/bits"
+)
+
+func init() {
+	hint.Register(Bytes)
+}
+
+// byteTable is the shared table of the lookup strategy: byteTable.Values[i] = i
+var byteTable = frontend.Table{Name: "rangecheck_byte"}
+
+func init() {
+	for i := int64(0); i < 256; i++ {
+		byteTable.Values = append(byteTable.Values, big.NewInt(i))
+	}
+}
+
+type check struct {
+	v      frontend.Variable
+	bitLen int
+}
+
+// Checker collects the range checks of a circuit.
+type Checker struct {
+	api       frontend.API
+	checks    []check
+	finalized bool
+}
+
+// New returns a new Checker. Finalize must be called once all the range checks
+// are collected.
+func New(api frontend.API) *Checker {
+	return &Checker{api: api}
+}
+
+// AssertIsInRange asserts that v is in [0, 2^bitLen). If v is a constant, it is
+// checked at compile time.
+func (c *Checker) AssertIsInRange(v frontend.Variable, bitLen int) {
+	if c.finalized {
+		panic("rangecheck: AssertIsInRange after Finalize")
+	}
+	if bitLen <= 0 || bitLen >= c.api.Compiler().Curve().Info().Fr.Bits {
+		panic(fmt.Sprintf("rangecheck: invalid bit length %d", bitLen))
+	}
+	if cv, ok := c.api.Compiler().ConstantValue(v); ok {
+		if cv.BitLen() > bitLen {
+			panic(fmt.Sprintf("rangecheck: constant %s is not in range [0, 2^%d)", cv.String(), bitLen))
+		}
+		return
+	}
+	c.checks = append(c.checks, check{v: v, bitLen: bitLen})
+}
+
+// Finalize adds the constraints of all the collected range checks.
+func (c *Checker) Finalize() {
+	if c.finalized {
+		panic("rangecheck: already finalized")
+	}
+	c.finalized = true
+
+	if _, ok := c.api.(frontend.LookupAPI); ok {
+		for _, ch := range c.checks {
+			c.checkWithTable(ch)
+		}
+		return
+	}
+	for _, ch := range c.checks {
+		bits.ToBinary(c.api, ch.v, bits.WithNbDigits(ch.bitLen))
+	}
+}
+
+// checkWithTable decomposes ch.v in bytes, and checks each byte with a lookup
+// in byteTable.
+func (c *Checker) checkWithTable(ch check) {
+	api := c.api
+
+	nbBytes := (ch.bitLen + 7) / 8
+	limbs, err := api.Compiler().NewHint(Bytes, nbBytes, ch.v)
+	if err != nil {
+		panic(err)
+	}
+
+	var Σ frontend.Variable = 0
+	coeff := big.NewInt(1)
+	for i := 0; i < nbBytes; i++ {
+		frontend.Lookup(api, &byteTable, limbs[i])
+		Σ = api.Add(Σ, api.Mul(limbs[i], coeff))
+		coeff.Lsh(coeff, 8)
+	}
+	api.AssertIsEqual(Σ, ch.v)
+
+	// the most significant byte must have at most bitLen % 8 bits. As it is in
+	// [0, 256), shifting it by the missing bits can't overflow
+	if r := ch.bitLen % 8; r != 0 {
+		frontend.Lookup(api, &byteTable, api.Mul(limbs[nbBytes-1], 1<<(8-r)))
+	}
+}
+
+// Bytes returns the little-endian bytes of the input. The number of returned
+// bytes is defined by the length of the results slice.
+func Bytes(_ ecc.ID, inputs []*big.Int, results []*big.Int) error {
+	n := new(big.Int).Set(inputs[0])
+	mask := big.NewInt(0xff)
+	for i := 0; i < len(results); i++ {
+		results[i].And(n, mask)
+		n.Rsh(n, 8)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package rangecheck_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/consensys/gnark/test"
+)
+
+type rangeCheckCircuit struct {
+	A, B, C frontend.Variable
+}
+
+func (c *rangeCheckCircuit) Define(api frontend.API) error {
+	checker := rangecheck.New(api)
+	checker.AssertIsInRange(c.A, 5)
+	checker.AssertIsInRange(c.B, 16)
+	checker.AssertIsInRange(c.C, 20)
+	checker.AssertIsInRange(42, 6)
+	checker.Finalize()
+	return nil
+}
+
+func TestRangeCheck(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	assert.ProverSucceeded(&rangeCheckCircuit{}, &rangeCheckCircuit{A: 31, B: 65535, C: 1 << 19})
+	assert.ProverSucceeded(&rangeCheckCircuit{}, &rangeCheckCircuit{A: 0, B: 0, C: 0})
+	assert.ProverFailed(&rangeCheckCircuit{}, &rangeCheckCircuit{A: 32, B: 0, C: 0})
+	assert.ProverFailed(&rangeCheckCircuit{}, &rangeCheckCircuit{A: 0, B: 65536, C: 0})
+	assert.ProverFailed(&rangeCheckCircuit{}, &rangeCheckCircuit{A: 0, B: 0, C: 1 << 20})
+	assert.ProverFailed(&rangeCheckCircuit{}, &rangeCheckCircuit{A: -1, B: 0, C: 0})
+}
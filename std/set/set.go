@@ -0,0 +1,185 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package set provides ZKP-circuit functions to check that a variable belongs,
+// or doesn't belong, to a set.
+//
+// Two kinds of sets are provided:
+//   - Set is a constant set, known when the circuit is compiled (an
+//     allow-list). A membership assertion costs a single lookup if the builder
+//     supports lookup tables (see frontend.LookupAPI), and len(values)
+//     constraints otherwise, as the product of the differences to the values
+//     of the set must be zero;
+//   - SortedList is a list of variables, known at solving time (for example a
+//     deny-list whose commitment is a public input), asserted to be strictly
+//     increasing when it is created. A non-membership assertion finds, with a
+//     hint, the two consecutive values of the list around the variable, and
+//     costs about 4*len(values) constraints, plus two range checks.
+package set
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/multiplexer"
+)
+
+func init() {
+	hint.Register(Index)
+	hint.Register(Gap)
+}
+
+// Set is a constant set of field elements.
+type Set struct {
+	table frontend.Table
+}
+
+// New returns the constant set of the given values. The name identifies the
+// set in the lookup tables of a circuit: two different sets used in the same
+// circuit must have different names.
+func New(name string, values ...*big.Int) *Set {
+	if len(values) == 0 {
+		panic("set: no values")
+	}
+	return &Set{table: frontend.Table{Name: "set_" + name, Values: values}}
+}
+
+// AssertIsMember asserts that v is one of the values of the set.
+//
+// With lookup tables, a hint returns the index i of v in the set, and the
+// lookup of i in the values of the set must return v. Otherwise, the product of
+// the differences v - values[i] must be zero.
+func (s *Set) AssertIsMember(api frontend.API, v frontend.Variable) {
+	if _, ok := api.(frontend.LookupAPI); !ok {
+		api.AssertIsEqual(s.product(api, v), 0)
+		return
+	}
+
+	inputs := []frontend.Variable{v}
+	for _, value := range s.table.Values {
+		inputs = append(inputs, value)
+	}
+	i, err := api.Compiler().NewHint(Index, 1, inputs...)
+	if err != nil {
+		panic(err)
+	}
+	api.AssertIsEqual(frontend.Lookup(api, &s.table, i[0]), v)
+}
+
+// IsMember returns 1 if v is one of the values of the set, 0 otherwise, with
+// len(values) constraints for the product of the differences v - values[i], and
+// api.IsZero.
+func (s *Set) IsMember(api frontend.API, v frontend.Variable) frontend.Variable {
+	return api.IsZero(s.product(api, v))
+}
+
+// product returns Π (v - values[i])
+func (s *Set) product(api frontend.API, v frontend.Variable) frontend.Variable {
+	var res frontend.Variable = 1
+	for _, value := range s.table.Values {
+		res = api.Mul(res, api.Sub(v, value))
+	}
+	return res
+}
+
+// SortedList is a strictly increasing list of variables in [0, 2^nbBits).
+type SortedList struct {
+	api    frontend.API
+	values []frontend.Variable
+	nbBits int
+}
+
+// NewSortedList returns a SortedList of the given values, and asserts that
+// they are in [0, 2^nbBits) and strictly increasing.
+//
+// nbBits must be lower than the number of bits of the field minus one, so that
+// the differences of values can't wrap around the modulus.
+func NewSortedList(api frontend.API, values []frontend.Variable, nbBits int) *SortedList {
+	if len(values) == 0 {
+		panic("set: no values")
+	}
+	if nbBits <= 0 || nbBits >= api.Compiler().Curve().Info().Fr.Bits-1 {
+		panic(fmt.Sprintf("set: invalid number of bits %d", nbBits))
+	}
+	l := &SortedList{api: api, values: values, nbBits: nbBits}
+	api.ToBinary(values[0], nbBits)
+	for i := 1; i < len(values); i++ {
+		// values[i] - values[i-1] - 1 ∈ [0, 2^nbBits)
+		api.ToBinary(api.Sub(values[i], values[i-1], 1), nbBits)
+	}
+	return l
+}
+
+// AssertIsNotMember asserts that v is not one of the values of the list.
+//
+// A hint returns the index i of the gap of the list containing v, such that
+// lo < v < hi, where lo = values[i-1] (or -1 if i == 0) and hi = values[i] (or
+// 2^nbBits if i == len(values)). lo and hi are selected with
+// multiplexer.Mux, and v - lo - 1 and hi - v - 1 are asserted to be in
+// [0, 2^(nbBits+1)).
+func (l *SortedList) AssertIsNotMember(v frontend.Variable) {
+	api := l.api
+	n := len(l.values)
+
+	bounds := make([]frontend.Variable, n+2)
+	bounds[0] = -1
+	copy(bounds[1:], l.values)
+	bounds[n+1] = new(big.Int).Lsh(big.NewInt(1), uint(l.nbBits))
+
+	inputs := append([]frontend.Variable{v}, l.values...)
+	i, err := api.Compiler().NewHint(Gap, 1, inputs...)
+	if err != nil {
+		panic(err)
+	}
+	lo := multiplexer.Mux(api, i[0], bounds[:n+1]...)
+	hi := multiplexer.Mux(api, i[0], bounds[1:]...)
+
+	api.ToBinary(api.Sub(v, lo, 1), l.nbBits+1)
+	api.ToBinary(api.Sub(hi, v, 1), l.nbBits+1)
+}
+
+// Index returns the index of the first input in the other inputs. It fails if
+// the first input is not one of the other inputs.
+func Index(_ ecc.ID, inputs []*big.Int, results []*big.Int) error {
+	for i := 1; i < len(inputs); i++ {
+		if inputs[i].Cmp(inputs[0]) == 0 {
+			results[0].SetUint64(uint64(i - 1))
+			return nil
+		}
+	}
+	return errors.New("set: value not in the set")
+}
+
+// Gap returns the number of the other inputs, sorted in increasing order, which
+// are lower than the first input. It fails if the first input is one of the
+// other inputs.
+func Gap(_ ecc.ID, inputs []*big.Int, results []*big.Int) error {
+	n := 0
+	for i := 1; i < len(inputs); i++ {
+		switch inputs[i].Cmp(inputs[0]) {
+		case 0:
+			return errors.New("set: value in the list")
+		case -1:
+			n++
+		}
+	}
+	results[0].SetUint64(uint64(n))
+	return nil
+}
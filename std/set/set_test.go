@@ -0,0 +1,61 @@
+package set_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/set"
+	"github.com/consensys/gnark/test"
+)
+
+var allowList = set.New("allow", big.NewInt(3), big.NewInt(17), big.NewInt(42), big.NewInt(1000))
+
+type memberCircuit struct {
+	V        frontend.Variable
+	IsMember frontend.Variable `gnark:",public"`
+}
+
+func (c *memberCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(allowList.IsMember(api, c.V), c.IsMember)
+	api.AssertIsEqual(allowList.IsMember(api, 17), 1)
+	allowList.AssertIsMember(api, api.Select(c.IsMember, c.V, 42))
+	return nil
+}
+
+func TestSetMember(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	assert.ProverSucceeded(&memberCircuit{}, &memberCircuit{V: 42, IsMember: 1})
+	assert.ProverSucceeded(&memberCircuit{}, &memberCircuit{V: 3, IsMember: 1})
+	assert.ProverSucceeded(&memberCircuit{}, &memberCircuit{V: 5, IsMember: 0})
+	assert.ProverFailed(&memberCircuit{}, &memberCircuit{V: 5, IsMember: 1})
+	assert.ProverFailed(&memberCircuit{}, &memberCircuit{V: 1000, IsMember: 0})
+}
+
+type notMemberCircuit struct {
+	DenyList [4]frontend.Variable
+	V        frontend.Variable
+}
+
+func (c *notMemberCircuit) Define(api frontend.API) error {
+	l := set.NewSortedList(api, c.DenyList[:], 16)
+	l.AssertIsNotMember(c.V)
+	return nil
+}
+
+func TestSortedListNotMember(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	denyList := [4]frontend.Variable{3, 17, 42, 1000}
+	for _, v := range []int{0, 4, 20, 999, 1001, 65535} {
+		assert.ProverSucceeded(&notMemberCircuit{}, &notMemberCircuit{DenyList: denyList, V: v})
+	}
+	for _, v := range []int{3, 42, 1000, 65536, -1} {
+		assert.ProverFailed(&notMemberCircuit{}, &notMemberCircuit{DenyList: denyList, V: v})
+	}
+
+	// the list must be strictly increasing
+	assert.ProverFailed(&notMemberCircuit{}, &notMemberCircuit{DenyList: [4]frontend.Variable{3, 42, 17, 1000}, V: 20})
+	assert.ProverFailed(&notMemberCircuit{}, &notMemberCircuit{DenyList: [4]frontend.Variable{3, 17, 17, 1000}, V: 20})
+}
@@ -0,0 +1,114 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bls provides a ZKP-circuit function to verify BLS signatures over
+// BLS12-377, in a circuit defined over BW6-761 (whose scalar field is the base
+// field of BLS12-377).
+//
+// Signatures are points of G1 and public keys are points of G2 (minimal
+// signature size). A signature S of the message msg under the public key pk
+// is valid if
+//
+//	e(S, g2) == e(H(msg), pk)
+//
+// where g2 is the generator of G2 and H is the hash to G1 of this package (see
+// HashToG1). As H is defined on field elements with MiMC, it is cheap in a
+// circuit; NativeHashToG1 and NativeSign are its counterparts outside of a
+// circuit.
+package bls
+
+import (
+	"errors"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/sw_bls12377"
+)
+
+// PublicKey stores a BLS public key (to be used in gnark circuit)
+type PublicKey struct {
+	Q sw_bls12377.G2Affine
+}
+
+// Signature stores a BLS signature (to be used in gnark circuit)
+type Signature struct {
+	S sw_bls12377.G1Affine
+}
+
+// Verify verifies the BLS signature sig of msg under the public key pubKey.
+//
+// It asserts that the signature is on the curve; membership of the signature
+// in G1 is not enforced (a point with a non-trivial cofactor component
+// verifies as its projection on G1, which only makes signatures malleable).
+// The public key is expected to be a valid point of G2.
+func Verify(api frontend.API, sig Signature, msg []frontend.Variable, pubKey PublicKey) error {
+	if api.Compiler().Curve() != ecc.BW6_761 {
+		return errors.New("BLS12-377 signatures can only be verified in a BW6-761 circuit")
+	}
+
+	assertIsOnG1(api, sig.S)
+
+	h, err := HashToG1(api, msg)
+	if err != nil {
+		return err
+	}
+
+	// e(S, -g2) * e(H(msg), pk) == 1
+	var negH sw_bls12377.G1Affine
+	negH.Neg(api, h)
+	var g2 sw_bls12377.G2Affine
+	g2.Assign(&g2Gen)
+
+	res, err := sw_bls12377.Pair(api, []sw_bls12377.G1Affine{sig.S, negH}, []sw_bls12377.G2Affine{g2, pubKey.Q})
+	if err != nil {
+		return err
+	}
+	var one sw_bls12377.GT
+	one.SetOne()
+	res.AssertIsEqual(api, one)
+
+	return nil
+}
+
+// VerifyAggregate verifies the BLS signature sig of msg aggregated from the
+// signatures of all the public keys pubKeys, that is, the sum of the individual
+// signatures.
+//
+// The public keys are aggregated with incomplete addition formulas: they must
+// be pairwise distinct, and no public key may be the opposite of the sum of the
+// preceding ones.
+func VerifyAggregate(api frontend.API, sig Signature, msg []frontend.Variable, pubKeys []PublicKey) error {
+	if len(pubKeys) == 0 {
+		return errors.New("no public key to aggregate")
+	}
+	apk := pubKeys[0]
+	for i := 1; i < len(pubKeys); i++ {
+		apk.Q.AddAssign(api, pubKeys[i].Q)
+	}
+	return Verify(api, sig, msg, apk)
+}
+
+// assertIsOnG1 asserts that p is on the curve of BLS12-377: y² == x³ + 1
+func assertIsOnG1(api frontend.API, p sw_bls12377.G1Affine) {
+	api.AssertIsEqual(api.Mul(p.Y, p.Y), g(api, p.X))
+}
+
+var g2Gen bls12377.G2Affine
+
+func init() {
+	_, _, _, g2Gen = bls12377.Generators()
+}
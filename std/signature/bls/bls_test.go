@@ -0,0 +1,94 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bls
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/sw_bls12377"
+	"github.com/consensys/gnark/test"
+)
+
+type hashToG1Circuit struct {
+	Msg [2]frontend.Variable
+	H   sw_bls12377.G1Affine
+}
+
+func (circuit *hashToG1Circuit) Define(api frontend.API) error {
+	h, err := HashToG1(api, circuit.Msg[:])
+	if err != nil {
+		return err
+	}
+	h.AssertIsEqual(api, circuit.H)
+	return nil
+}
+
+func TestHashToG1(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	msg := []*big.Int{big.NewInt(42), big.NewInt(43)}
+	h, err := NativeHashToG1(msg)
+	assert.NoError(err)
+	assert.True(h.IsOnCurve() && h.IsInSubGroup())
+
+	var witness hashToG1Circuit
+	witness.Msg = [2]frontend.Variable{msg[0], msg[1]}
+	witness.H.Assign(&h)
+
+	assert.SolvingSucceeded(&hashToG1Circuit{}, &witness, test.WithCurves(ecc.BW6_761), test.WithBackends(backend.GROTH16))
+}
+
+type verifyCircuit struct {
+	Sig    Signature
+	Msg    [2]frontend.Variable
+	PubKey PublicKey `gnark:",public"`
+}
+
+func (circuit *verifyCircuit) Define(api frontend.API) error {
+	return Verify(api, circuit.Sig, circuit.Msg[:], circuit.PubKey)
+}
+
+func TestVerify(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	sk, err := rand.Int(rand.Reader, fr.Modulus())
+	assert.NoError(err)
+	_, _, _, g2 := bls12377.Generators()
+	var pk bls12377.G2Affine
+	pk.ScalarMultiplication(&g2, sk)
+
+	msg := []*big.Int{big.NewInt(42), big.NewInt(43)}
+	sig, err := NativeSign(sk, msg)
+	assert.NoError(err)
+
+	var witness verifyCircuit
+	witness.Sig.S.Assign(&sig)
+	witness.Msg = [2]frontend.Variable{msg[0], msg[1]}
+	witness.PubKey.Q.Assign(&pk)
+
+	assert.SolvingSucceeded(&verifyCircuit{}, &witness, test.WithCurves(ecc.BW6_761), test.WithBackends(backend.GROTH16))
+
+	witness.Msg = [2]frontend.Variable{msg[0], 44}
+	assert.SolvingFailed(&verifyCircuit{}, &witness, test.WithCurves(ecc.BW6_761), test.WithBackends(backend.GROTH16))
+}
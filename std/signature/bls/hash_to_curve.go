@@ -0,0 +1,391 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bls
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/sw_bls12377"
+	"github.com/consensys/gnark/std/hash/mimc"
+)
+
+// The hash to G1 follows the structure of the hash_to_curve of RFC 9380, with
+// a hash to field suited to circuits:
+//
+//	u0 = MiMC(0, msg...), u1 = MiMC(1, msg...)
+//	H(msg) = [h] (map(u0) + map(u1))
+//
+// where MiMC is the MiMC hash over the scalar field of BW6-761 (the base field
+// Fp of BLS12-377), map is the Shallue-van de Woestijne map of RFC 9380
+// (section 6.6.1) for y² = x³ + 1, and h is the cofactor of G1.
+
+func init() {
+	hint.Register(SqrtHint)
+	hint.Register(ParityHint)
+}
+
+var (
+	fp *big.Int // base field of BLS12-377
+
+	// constants of the Shallue-van de Woestijne map
+	svdwZ, svdwC1, svdwC2, svdwC3, svdwC4 *big.Int
+
+	// non-square of Fp, used to prove that an element is not a square
+	nonResidue *big.Int
+
+	// (p-1)/2, bound of the halves of the canonical field elements
+	halfFp *big.Int
+
+	// cofactor of G1: (x-1)²/3 where x is the seed of BLS12-377
+	cofactor *big.Int
+)
+
+func init() {
+	fp = ecc.BW6_761.Info().Fr.Modulus()
+
+	halfFp = new(big.Int).Sub(fp, big.NewInt(1))
+	halfFp.Rsh(halfFp, 1)
+
+	x, _ := new(big.Int).SetString("8508c00000000001", 16)
+	cofactor = new(big.Int).Sub(x, big.NewInt(1))
+	cofactor.Mul(cofactor, cofactor).Div(cofactor, big.NewInt(3))
+
+	nonResidue = big.NewInt(2)
+	for big.Jacobi(nonResidue, fp) != -1 {
+		nonResidue.Add(nonResidue, big.NewInt(1))
+	}
+
+	// find_z_svdw (RFC 9380, appendix H.1) with A = 0: Z is the first element
+	// of 1, -1, 2, -2, ... such that g(Z) != 0, -3Z²/(4g(Z)) is a non-zero
+	// square, and g(Z) or g(-Z/2) is a square
+	for ctr := int64(1); ; ctr++ {
+		for _, z := range []*big.Int{big.NewInt(ctr), new(big.Int).Sub(fp, big.NewInt(ctr))} {
+			gz := nativeG(z)
+			if gz.Sign() == 0 {
+				continue
+			}
+			t := new(big.Int).Mul(z, z)
+			t.Mul(t, big.NewInt(-3))
+			t.Mul(t, new(big.Int).ModInverse(new(big.Int).Mul(gz, big.NewInt(4)), fp)).Mod(t, fp)
+			if t.Sign() == 0 || big.Jacobi(t, fp) != 1 {
+				continue
+			}
+			mz2 := new(big.Int).Neg(z)
+			mz2.Mul(mz2, new(big.Int).ModInverse(big.NewInt(2), fp)).Mod(mz2, fp)
+			if big.Jacobi(gz, fp) == -1 && big.Jacobi(nativeG(mz2), fp) == -1 {
+				continue
+			}
+			svdwZ = z
+			break
+		}
+		if svdwZ != nil {
+			break
+		}
+	}
+
+	// c1 = g(Z)
+	svdwC1 = nativeG(svdwZ)
+	// c2 = -Z / 2
+	svdwC2 = new(big.Int).Neg(svdwZ)
+	svdwC2.Mul(svdwC2, new(big.Int).ModInverse(big.NewInt(2), fp)).Mod(svdwC2, fp)
+	// c3 = sqrt(-g(Z) * 3Z²), with sgn0(c3) == 0
+	threeZ2 := new(big.Int).Mul(svdwZ, svdwZ)
+	threeZ2.Mul(threeZ2, big.NewInt(3)).Mod(threeZ2, fp)
+	svdwC3 = new(big.Int).Neg(svdwC1)
+	svdwC3.Mul(svdwC3, threeZ2).Mod(svdwC3, fp)
+	svdwC3 = new(big.Int).ModSqrt(svdwC3, fp)
+	if svdwC3.Bit(0) == 1 {
+		svdwC3.Sub(fp, svdwC3)
+	}
+	// c4 = -4g(Z) / 3Z²
+	svdwC4 = new(big.Int).Mul(svdwC1, big.NewInt(-4))
+	svdwC4.Mul(svdwC4, new(big.Int).ModInverse(threeZ2, fp)).Mod(svdwC4, fp)
+}
+
+// HashToG1 returns the hash of msg to G1 (see NativeHashToG1 for the same
+// computation outside of a circuit).
+//
+// The circuit must be defined over BW6-761.
+func HashToG1(api frontend.API, msg []frontend.Variable) (sw_bls12377.G1Affine, error) {
+	if api.Compiler().Curve() != ecc.BW6_761 {
+		return sw_bls12377.G1Affine{}, errors.New("hash to BLS12-377 G1 is only supported in a BW6-761 circuit")
+	}
+
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		return sw_bls12377.G1Affine{}, err
+	}
+	var u [2]frontend.Variable
+	for i := range u {
+		h.Reset()
+		h.Write(i)
+		h.Write(msg...)
+		u[i] = h.Sum()
+	}
+
+	p := mapToCurve(api, u[0])
+	p.AddAssign(api, mapToCurve(api, u[1]))
+
+	return clearCofactor(api, p), nil
+}
+
+// mapToCurve is the straight-line Shallue-van de Woestijne map (RFC 9380,
+// appendix F.1). The square roots and the quadratic residuosity of the
+// intermediate values are computed with hints, and checked in the circuit.
+//
+// If 1 - (u² * c1)² == 0 (inv0 in the RFC), the solver fails.
+func mapToCurve(api frontend.API, u frontend.Variable) sw_bls12377.G1Affine {
+	tv1 := api.Mul(u, u, svdwC1)
+	tv2 := api.Add(1, tv1)
+	tv1 = api.Sub(1, tv1)
+	tv3 := api.Inverse(api.Mul(tv1, tv2))
+	tv4 := api.Mul(u, tv1, tv3, svdwC3)
+	x1 := api.Sub(svdwC2, tv4)
+	e1 := isSquare(api, g(api, x1))
+	x2 := api.Add(svdwC2, tv4)
+	e2 := api.Mul(isSquare(api, g(api, x2)), api.Sub(1, e1))
+	x3 := api.Mul(tv2, tv2, tv3)
+	x3 = api.Add(api.Mul(x3, x3, svdwC4), svdwZ)
+
+	x := api.Select(e1, x1, x3)
+	x = api.Select(e2, x2, x)
+
+	y := sqrt(api, g(api, x))
+
+	// fix the sign of y: sgn0(y) == sgn0(u)
+	bu, by := sgn0(api, u), sgn0(api, y)
+	e3 := api.Sub(1, api.Xor(bu, by))
+	y = api.Select(e3, y, api.Neg(y))
+
+	return sw_bls12377.G1Affine{X: x, Y: y}
+}
+
+// clearCofactor returns [h] p, with a double-and-add on the constant cofactor h
+func clearCofactor(api frontend.API, p sw_bls12377.G1Affine) sw_bls12377.G1Affine {
+	// the scalar multiplication of sw_bls12377 uses the GLV endomorphism,
+	// which is only valid in G1, so we use a plain double-and-add here
+	nbBits := cofactor.BitLen()
+
+	// the first step is done with Double, as DoubleAndAdd(p, p) divides by 0
+	acc := p
+	acc.Double(api, acc)
+	if cofactor.Bit(nbBits-2) == 1 {
+		acc.AddAssign(api, p)
+	}
+	for i := nbBits - 3; i >= 0; i-- {
+		if cofactor.Bit(i) == 1 {
+			acc.DoubleAndAdd(api, &acc, &p)
+		} else {
+			acc.Double(api, acc)
+		}
+	}
+	return acc
+}
+
+// g returns x³ + 1
+func g(api frontend.API, x frontend.Variable) frontend.Variable {
+	return api.Add(api.Mul(x, x, x), 1)
+}
+
+// isSquare returns 1 if x is a square in Fp, 0 otherwise
+func isSquare(api frontend.API, x frontend.Variable) frontend.Variable {
+	res, err := api.Compiler().NewHint(SqrtHint, 2, x)
+	if err != nil {
+		panic(err)
+	}
+	e, s := res[0], res[1]
+
+	// s² == x if x is a square, s² == nonResidue * x otherwise; as nonResidue
+	// is not a square, x can't be both
+	api.AssertIsBoolean(e)
+	api.AssertIsEqual(api.Mul(s, s), api.Select(e, x, api.Mul(x, nonResidue)))
+	return e
+}
+
+// sqrt returns a square root of x, and fails if x is not a square
+func sqrt(api frontend.API, x frontend.Variable) frontend.Variable {
+	res, err := api.Compiler().NewHint(SqrtHint, 2, x)
+	if err != nil {
+		panic(err)
+	}
+	api.AssertIsEqual(res[0], 1)
+	api.AssertIsEqual(api.Mul(res[1], res[1]), x)
+	return res[1]
+}
+
+// sgn0 returns the parity of the canonical representative of x in [0, p)
+func sgn0(api frontend.API, x frontend.Variable) frontend.Variable {
+	res, err := api.Compiler().NewHint(ParityHint, 2, x)
+	if err != nil {
+		panic(err)
+	}
+	b, k := res[0], res[1]
+
+	// x == 2k + b with k ⩽ (p-1)/2, so 2k + b ⩽ p. 2k + b == p would imply x
+	// == 0, for which the parity is wrong; this can only happen if g(x) has a
+	// root, which is excluded by the map (and as u is the output of a hash)
+	api.AssertIsBoolean(b)
+	api.AssertIsLessOrEqual(k, halfFp)
+	api.AssertIsEqual(x, api.Add(api.Mul(k, 2), b))
+	return b
+}
+
+// SqrtHint returns (1, sqrt(x)) if the input x is a square in the base field of
+// BLS12-377, and (0, sqrt(nonResidue * x)) otherwise.
+func SqrtHint(_ ecc.ID, inputs []*big.Int, results []*big.Int) error {
+	x := new(big.Int).Mod(inputs[0], fp)
+	if big.Jacobi(x, fp) >= 0 {
+		results[0].SetUint64(1)
+	} else {
+		results[0].SetUint64(0)
+		x.Mul(x, nonResidue).Mod(x, fp)
+	}
+	if results[1].ModSqrt(x, fp) == nil {
+		return errors.New("no square root")
+	}
+	return nil
+}
+
+// ParityHint returns (b, k) such that the input is 2k + b, with b in {0, 1}.
+func ParityHint(_ ecc.ID, inputs []*big.Int, results []*big.Int) error {
+	results[0].SetUint64(uint64(inputs[0].Bit(0)))
+	results[1].Rsh(inputs[0], 1)
+	return nil
+}
+
+// NativeHashToG1 returns the hash of msg to G1, outside of a circuit (see
+// HashToG1).
+func NativeHashToG1(msg []*big.Int) (bls12377.G1Affine, error) {
+	var u [2]*big.Int
+	buf := make([]byte, (fp.BitLen()+7)/8)
+	for i := range u {
+		h := hash.MIMC_BW6_761.New()
+		for _, m := range append([]*big.Int{big.NewInt(int64(i))}, msg...) {
+			if m.Sign() < 0 || m.Cmp(fp) >= 0 {
+				return bls12377.G1Affine{}, errors.New("message element out of range")
+			}
+			m.FillBytes(buf)
+			h.Write(buf) // #nosec G104 -- does not err
+		}
+		u[i] = new(big.Int).SetBytes(h.Sum(nil))
+	}
+
+	x0, y0 := nativeMapToCurve(u[0])
+	x1, y1 := nativeMapToCurve(u[1])
+	x, y := nativeAdd(x0, y0, x1, y1)
+
+	// [h] (x, y), with the same sequence of operations as clearCofactor
+	nbBits := cofactor.BitLen()
+	rx, ry := nativeAdd(x, y, x, y)
+	for i := nbBits - 2; i >= 0; i-- {
+		if i < nbBits-2 {
+			rx, ry = nativeAdd(rx, ry, rx, ry)
+		}
+		if cofactor.Bit(i) == 1 {
+			rx, ry = nativeAdd(rx, ry, x, y)
+		}
+	}
+
+	var res bls12377.G1Affine
+	res.X.SetBigInt(rx)
+	res.Y.SetBigInt(ry)
+	return res, nil
+}
+
+// NativeSign returns the BLS signature of msg with the private key sk, outside
+// of a circuit.
+func NativeSign(sk *big.Int, msg []*big.Int) (bls12377.G1Affine, error) {
+	h, err := NativeHashToG1(msg)
+	if err != nil {
+		return bls12377.G1Affine{}, err
+	}
+	var sig bls12377.G1Affine
+	sig.ScalarMultiplication(&h, sk)
+	return sig, nil
+}
+
+func nativeMapToCurve(u *big.Int) (x, y *big.Int) {
+	tv1 := new(big.Int).Mul(u, u)
+	tv1.Mul(tv1, svdwC1).Mod(tv1, fp)
+	tv2 := new(big.Int).Add(tv1, big.NewInt(1))
+	tv1.Sub(big.NewInt(1), tv1)
+	tv3 := new(big.Int).Mul(tv1, tv2)
+	tv3.Mod(tv3, fp)
+	if tv3.Sign() != 0 {
+		tv3.ModInverse(tv3, fp)
+	}
+	tv4 := new(big.Int).Mul(u, tv1)
+	tv4.Mul(tv4, tv3).Mul(tv4, svdwC3).Mod(tv4, fp)
+	x1 := new(big.Int).Sub(svdwC2, tv4)
+	x1.Mod(x1, fp)
+	x2 := new(big.Int).Add(svdwC2, tv4)
+	x2.Mod(x2, fp)
+	x3 := new(big.Int).Mul(tv2, tv2)
+	x3.Mul(x3, tv3).Mod(x3, fp)
+	x3.Mul(x3, x3).Mul(x3, svdwC4).Add(x3, svdwZ).Mod(x3, fp)
+
+	switch {
+	case big.Jacobi(nativeG(x1), fp) >= 0:
+		x = x1
+	case big.Jacobi(nativeG(x2), fp) >= 0:
+		x = x2
+	default:
+		x = x3
+	}
+	y = new(big.Int).ModSqrt(nativeG(x), fp)
+	if y.Bit(0) != new(big.Int).Mod(u, fp).Bit(0) {
+		y.Sub(fp, y).Mod(y, fp)
+	}
+	return x, y
+}
+
+// nativeG returns x³ + 1 mod p
+func nativeG(x *big.Int) *big.Int {
+	r := new(big.Int).Mul(x, x)
+	r.Mul(r, x).Add(r, big.NewInt(1))
+	return r.Mod(r, fp)
+}
+
+// nativeAdd returns (x1, y1) + (x2, y2) on y² = x³ + 1 with the affine
+// formulas, which are incomplete (as the ones of sw_bls12377)
+func nativeAdd(x1, y1, x2, y2 *big.Int) (x, y *big.Int) {
+	var λ big.Int
+	if x1.Cmp(x2) == 0 && y1.Cmp(y2) == 0 {
+		// λ = 3x² / 2y
+		λ.Mul(x1, x1).Mul(&λ, big.NewInt(3))
+		d := new(big.Int).Lsh(y1, 1)
+		λ.Mul(&λ, d.ModInverse(d, fp))
+	} else {
+		// λ = (y2 - y1) / (x2 - x1)
+		λ.Sub(y2, y1)
+		d := new(big.Int).Sub(x2, x1)
+		d.Mod(d, fp)
+		λ.Mul(&λ, d.ModInverse(d, fp))
+	}
+	λ.Mod(&λ, fp)
+	x = new(big.Int).Mul(&λ, &λ)
+	x.Sub(x, x1).Sub(x, x2).Mod(x, fp)
+	y = new(big.Int).Sub(x1, x)
+	y.Mul(y, &λ).Sub(y, y1).Mod(y, fp)
+	return x, y
+}
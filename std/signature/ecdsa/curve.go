@@ -0,0 +1,46 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ecdsa
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/std/algebra/weierstrass"
+)
+
+// Curve defines a short Weierstrass curve over an emulated field, along with
+// a generator of prime order (see std/algebra/weierstrass).
+type Curve = weierstrass.Curve
+
+// Secp256k1 returns the parameters of the secp256k1 curve, with the base and
+// scalar fields emulated with 4 limbs of 64 bits.
+func Secp256k1() *Curve {
+	return weierstrass.Secp256k1()
+}
+
+// P256 returns the parameters of the NIST P-256 curve, with the base and
+// scalar fields emulated with 4 limbs of 64 bits.
+func P256() *Curve {
+	return weierstrass.P256()
+}
+
+// NewCurve returns the curve y² = x³ + a*x + b over the field of modulus p,
+// with a generator (gx, gy) of prime order n. The field elements are
+// decomposed into limbs of nbBits bits.
+func NewCurve(p, n, a, b, gx, gy *big.Int, nbBits int) (*Curve, error) {
+	return weierstrass.NewCurve(p, n, a, b, gx, gy, nbBits)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ecdsa provides a ZKP-circuit function to verify an ECDSA signature
+// over a curve whose fields are emulated with std/math/nonnative, such as
+// secp256k1 or P-256.
+package ecdsa
+
+import (
+	"errors"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/weierstrass"
+	"github.com/consensys/gnark/std/math/nonnative"
+)
+
+// PublicKey stores an ECDSA public key (to be used in gnark circuit). The
+// coordinates are elements of the base field of the curve.
+type PublicKey struct {
+	X, Y nonnative.Element
+}
+
+// Signature stores an ECDSA signature (to be used in gnark circuit). R and S
+// are elements of the scalar field of the curve.
+type Signature struct {
+	R, S nonnative.Element
+}
+
+// Verify verifies an ECDSA signature of the message hash msgHash, given as an
+// element of the scalar field of the curve (that is, the hash interpreted as a
+// big-endian integer, truncated to the bit size of the group order if needed).
+//
+// It asserts that the public key is on the curve and that
+//
+//	R = x([msgHash/S]G + [R/S]pubKey) mod n
+//
+// The solver fails if S is not invertible.
+func Verify(api frontend.API, curve *Curve, sig Signature, msgHash nonnative.Element, pubKey PublicKey) error {
+	if curve == nil {
+		return errors.New("missing curve")
+	}
+	if curve.Fp.NbBits() != curve.Fr.NbBits() || curve.Fp.NbLimbs() != curve.Fr.NbLimbs() {
+		return errors.New("base and scalar fields must have the same representation")
+	}
+
+	var r, s, e, u1, u2 nonnative.Element
+	r.Set(api, sig.R)
+	s.Set(api, sig.S)
+	e.Set(api, msgHash)
+
+	q := weierstrass.AffinePoint{X: pubKey.X, Y: pubKey.Y}
+	q.X.Set(api, q.X)
+	q.Y.Set(api, q.Y)
+	curve.AssertIsOnCurve(api, q)
+
+	// u1 = msgHash / s, u2 = r / s
+	u1.Div(api, e, s)
+	u2.Div(api, r, s)
+
+	res := curve.JointScalarMulBase(api, q, u1, u2)
+
+	// x(res) mod n == r
+	var x nonnative.Element
+	x.Reduce(api, res.X)
+	x = curve.Fr.FromLimbs(x.Limbs)
+	x.AssertIsEqual(api, x, r)
+
+	return nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ecdsa
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/nonnative"
+	"github.com/consensys/gnark/test"
+)
+
+type ecdsaCircuit struct {
+	Sig     Signature
+	MsgHash nonnative.Element
+	PubKey  PublicKey `gnark:",public"`
+
+	curve *Curve
+}
+
+func (circuit *ecdsaCircuit) Define(api frontend.API) error {
+	return Verify(api, circuit.curve, circuit.Sig, circuit.MsgHash, circuit.PubKey)
+}
+
+// sign returns an ECDSA signature of msgHash with the private key priv,
+// outside of a circuit.
+func sign(t *testing.T, c *Curve, priv, msgHash *big.Int) (r, s *big.Int) {
+	n := c.Fr.Modulus()
+	for {
+		k, err := rand.Int(rand.Reader, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k.Sign() == 0 {
+			continue
+		}
+		rx, _ := c.NativeScalarMul(c.Gx, c.Gy, k)
+		r = new(big.Int).Mod(rx, n)
+		if r.Sign() == 0 {
+			continue
+		}
+		s = new(big.Int).Mul(r, priv)
+		s.Add(s, msgHash)
+		s.Mul(s, k.ModInverse(k, n)).Mod(s, n)
+		if s.Sign() != 0 {
+			return r, s
+		}
+	}
+}
+
+func TestECDSA(t *testing.T) {
+	for _, c := range []*Curve{Secp256k1(), P256()} {
+		testECDSA(t, c)
+	}
+}
+
+func testECDSA(t *testing.T, c *Curve) {
+	assert := test.NewAssert(t)
+	priv, err := rand.Int(rand.Reader, c.Fr.Modulus())
+	assert.NoError(err)
+	qx, qy := c.NativeScalarMul(c.Gx, c.Gy, priv)
+
+	h := sha256.Sum256([]byte("testing ECDSA"))
+	msgHash := new(big.Int).SetBytes(h[:])
+	r, s := sign(t, c, priv, msgHash)
+
+	circuit := ecdsaCircuit{
+		Sig:     Signature{R: c.Fr.Placeholder(), S: c.Fr.Placeholder()},
+		MsgHash: c.Fr.Placeholder(),
+		PubKey:  PublicKey{X: c.Fp.Placeholder(), Y: c.Fp.Placeholder()},
+		curve:   c,
+	}
+	witness := ecdsaCircuit{
+		Sig:     Signature{R: c.Fr.ConstantFromBig(r), S: c.Fr.ConstantFromBig(s)},
+		MsgHash: c.Fr.ConstantFromBig(msgHash),
+		PubKey:  PublicKey{X: c.Fp.ConstantFromBig(qx), Y: c.Fp.ConstantFromBig(qy)},
+	}
+	assert.NoError(test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16))
+
+	// wrong message
+	witness.MsgHash = c.Fr.ConstantFromBig(new(big.Int).Add(msgHash, big.NewInt(1)))
+	assert.Error(test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16))
+}
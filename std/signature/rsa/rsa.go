@@ -0,0 +1,256 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package rsa provides ZKP-circuit functions to verify RSA signatures (RFC
+// 8017) with SHA-256, using the PKCS #1 v1.5 or the PSS encoding.
+//
+// The public key is a constant of the circuit: the arithmetic modulo N is
+// emulated with std/math/nonnative, whose parameters are derived from N. The
+// signature is an element modulo N, and the message is given by its SHA-256
+// digest as a slice of 32 byte variables. Moduli of 2048 or 4096 bits are
+// typical; the verification then mostly costs the modular exponentiation
+// S^E mod N, that is log2(E) squarings and as many multiplications as there
+// are bits set in E.
+package rsa
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/sha256"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/nonnative"
+)
+
+// NbBits is the number of bits per limb of the elements modulo N.
+const NbBits = 64
+
+// sha256Prefix is the DER encoding of the DigestInfo of a SHA-256 digest,
+// without the digest itself.
+var sha256Prefix = []byte{0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20}
+
+// PublicKey stores an RSA public key. Contrary to the other signature
+// gadgets, it is a constant of the circuit.
+type PublicKey struct {
+	N *big.Int // modulus
+	E int      // public exponent
+}
+
+// Signature stores an RSA signature (to be used in gnark circuit), as an
+// element modulo N.
+type Signature struct {
+	S nonnative.Element
+}
+
+// Params returns the parameters of the emulated ring of integers modulo N, to
+// be used to allocate and assign signatures.
+func (pk PublicKey) Params() (*nonnative.Params, error) {
+	if pk.N == nil {
+		return nil, errors.New("missing modulus")
+	}
+	return nonnative.NewParams(NbBits, pk.N)
+}
+
+// VerifyPKCS1v15 verifies the RSASSA-PKCS1-v1_5 signature sig of the SHA-256
+// digest hashed under the public key pubKey.
+//
+// It asserts that the signature is strictly smaller than N and that
+//
+//	S^E mod N == 0x00 || 0x01 || 0xff...0xff || 0x00 || DigestInfo || hashed
+func VerifyPKCS1v15(api frontend.API, pubKey PublicKey, hashed []frontend.Variable, sig Signature) error {
+	params, err := pubKey.check()
+	if err != nil {
+		return err
+	}
+	if len(hashed) != sha256.Size {
+		return errors.New("hashed must be a SHA-256 digest")
+	}
+	k := (pubKey.N.BitLen() + 7) / 8
+	tLen := len(sha256Prefix) + sha256.Size
+	if k < tLen+11 {
+		return errors.New("modulus too short for the PKCS #1 v1.5 encoding")
+	}
+
+	// EM = 0x00 || 0x01 || PS || 0x00 || T
+	em := make([]frontend.Variable, 0, k)
+	em = append(em, 0, 1)
+	for i := 0; i < k-tLen-3; i++ {
+		em = append(em, 0xff)
+	}
+	em = append(em, 0)
+	for _, b := range sha256Prefix {
+		em = append(em, b)
+	}
+	for _, b := range hashed {
+		bits.ToBinary(api, b, bits.WithNbDigits(8))
+		em = append(em, b)
+	}
+	expected := params.FromLimbs(bytesToLimbs(api, em, params.NbLimbs()))
+
+	m := exp(api, pubKey, sig)
+	m.AssertIsEqual(api, m, expected)
+	return nil
+}
+
+// VerifyPSS verifies the RSASSA-PSS signature sig of the SHA-256 digest
+// hashed under the public key pubKey. The mask generation function is MGF1
+// with SHA-256, and saltLen is the length of the salt in bytes.
+//
+// It asserts that the signature is strictly smaller than N and decodes the
+// encoded message S^E mod N as specified by EMSA-PSS-VERIFY (RFC 8017,
+// section 9.1.2).
+func VerifyPSS(api frontend.API, pubKey PublicKey, hashed []frontend.Variable, sig Signature, saltLen int) error {
+	if _, err := pubKey.check(); err != nil {
+		return err
+	}
+	if len(hashed) != sha256.Size {
+		return errors.New("hashed must be a SHA-256 digest")
+	}
+	emBits := pubKey.N.BitLen() - 1
+	emLen := (emBits + 7) / 8
+	hLen := sha256.Size
+	if saltLen < 0 || emLen < hLen+saltLen+2 {
+		return errors.New("modulus too short for the PSS encoding")
+	}
+
+	m := exp(api, pubKey, sig)
+	m.ReduceStrict(api, m)
+	mBits := m.ToBits(api)
+
+	// m < 2^emBits: this covers both the length of the encoded message and
+	// the leftmost bits of maskedDB, which must be zero
+	for i := emBits; i < len(mBits); i++ {
+		api.AssertIsEqual(mBits[i], 0)
+	}
+
+	// emBytes[i] holds the bits of the i-th byte of EM (big endian), least
+	// significant bit first
+	emBytes := make([][]frontend.Variable, emLen)
+	for i := range emBytes {
+		emBytes[i] = mBits[8*(emLen-1-i) : 8*(emLen-i)]
+	}
+
+	// trailer field
+	assertByte(api, emBytes[emLen-1], 0xbc)
+
+	dbLen := emLen - hLen - 1
+	maskedDB := emBytes[:dbLen]
+	h := make([]frontend.Variable, hLen)
+	for i := range h {
+		h[i] = bits.FromBinary(api, emBytes[dbLen+i], bits.WithUnconstrainedInputs())
+	}
+
+	// DB = maskedDB ⊕ MGF1(H), with the leftmost 8*emLen - emBits bits set to zero
+	dbMask := mgf1(api, h, dbLen)
+	db := make([][]frontend.Variable, dbLen)
+	for i := range db {
+		db[i] = make([]frontend.Variable, 8)
+		for j := range db[i] {
+			db[i][j] = api.Xor(maskedDB[i][j], dbMask[i][j])
+		}
+	}
+	for j := 8 - (8*emLen - emBits); j < 8; j++ {
+		db[0][j] = 0
+	}
+
+	// DB = PS || 0x01 || salt
+	psLen := dbLen - saltLen - 1
+	for i := 0; i < psLen; i++ {
+		assertByte(api, db[i], 0)
+	}
+	assertByte(api, db[psLen], 0x01)
+
+	// H == SHA-256(0x00 * 8 || mHash || salt)
+	hf := sha256.New(api)
+	hf.Write(0, 0, 0, 0, 0, 0, 0, 0)
+	hf.Write(hashed...)
+	for i := dbLen - saltLen; i < dbLen; i++ {
+		hf.Write(bits.FromBinary(api, db[i], bits.WithUnconstrainedInputs()))
+	}
+	hPrime := hf.Sum()
+	for i := range h {
+		api.AssertIsEqual(hPrime[i], h[i])
+	}
+	return nil
+}
+
+// check returns the parameters of the ring modulo N, and an error if the
+// public key is invalid.
+func (pk PublicKey) check() (*nonnative.Params, error) {
+	if pk.N == nil || pk.N.Sign() <= 0 || pk.N.Bit(0) == 0 {
+		return nil, errors.New("invalid modulus")
+	}
+	if pk.E < 2 {
+		return nil, errors.New("invalid public exponent")
+	}
+	return pk.Params()
+}
+
+// exp asserts that the signature is canonical and returns S^E mod N.
+func exp(api frontend.API, pubKey PublicKey, sig Signature) nonnative.Element {
+	var s, m nonnative.Element
+	s.Set(api, sig.S)
+
+	// S < N, so that the signature is not malleable
+	m.ReduceStrict(api, s)
+	for i := range m.Limbs {
+		api.AssertIsEqual(m.Limbs[i], s.Limbs[i])
+	}
+
+	m.Exp(api, s, big.NewInt(int64(pubKey.E)))
+	return m
+}
+
+// mgf1 returns the first length bytes of MGF1-SHA256(seed), each byte being
+// decomposed into its bits, least significant bit first.
+func mgf1(api frontend.API, seed []frontend.Variable, length int) [][]frontend.Variable {
+	res := make([][]frontend.Variable, 0, length+sha256.Size)
+	hf := sha256.New(api)
+	for counter := 0; len(res) < length; counter++ {
+		hf.Reset()
+		hf.Write(seed...)
+		hf.Write(counter>>24, (counter>>16)&0xff, (counter>>8)&0xff, counter&0xff)
+		for _, b := range hf.Sum() {
+			res = append(res, bits.ToBinary(api, b, bits.WithNbDigits(8)))
+		}
+	}
+	return res[:length]
+}
+
+// assertByte asserts that the bits b, least significant first, encode the
+// byte v.
+func assertByte(api frontend.API, b []frontend.Variable, v byte) {
+	for j := range b {
+		api.AssertIsEqual(b[j], (v>>j)&1)
+	}
+}
+
+// bytesToLimbs returns the nbLimbs limbs of NbBits bits, least significant
+// first, of the big-endian integer whose bytes are given.
+func bytesToLimbs(api frontend.API, b []frontend.Variable, nbLimbs uint) []frontend.Variable {
+	limbs := make([]frontend.Variable, nbLimbs)
+	for i := range limbs {
+		limbs[i] = 0
+	}
+	for i := range b {
+		pos := len(b) - 1 - i // position of the byte, from the least significant
+		l := (8 * pos) / NbBits
+		shift := new(big.Int).Lsh(big.NewInt(1), uint((8*pos)%NbBits))
+		limbs[l] = api.Add(limbs[l], api.Mul(b[i], shift))
+	}
+	return limbs
+}
@@ -0,0 +1,93 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rsa
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+type rsaCircuit struct {
+	Sig    Signature
+	Hashed [32]frontend.Variable `gnark:",public"`
+
+	pubKey PublicKey
+	pss    bool
+}
+
+func (circuit *rsaCircuit) Define(api frontend.API) error {
+	if circuit.pss {
+		return VerifyPSS(api, circuit.pubKey, circuit.Hashed[:], circuit.Sig, sha256.Size)
+	}
+	return VerifyPKCS1v15(api, circuit.pubKey, circuit.Hashed[:], circuit.Sig)
+}
+
+func testRSA(t *testing.T, pss bool) {
+	if testing.Short() {
+		t.Skip("skipping RSA test in short mode")
+	}
+	assert := test.NewAssert(t)
+
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(err)
+	pubKey := PublicKey{N: priv.N, E: priv.E}
+	params, err := pubKey.Params()
+	assert.NoError(err)
+
+	hashed := sha256.Sum256([]byte("testing RSA signatures"))
+	var sig []byte
+	if pss {
+		sig, err = rsa.SignPSS(rand.Reader, priv, crypto.SHA256, hashed[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	} else {
+		sig, err = rsa.SignPKCS1v15(rand.Reader, priv, crypto.SHA256, hashed[:])
+	}
+	assert.NoError(err)
+	s := new(big.Int).SetBytes(sig)
+
+	circuit := rsaCircuit{Sig: Signature{S: params.Placeholder()}, pubKey: pubKey, pss: pss}
+	witness := rsaCircuit{Sig: Signature{S: params.ConstantFromBig(s)}}
+	for i := range hashed {
+		witness.Hashed[i] = hashed[i]
+	}
+	assert.NoError(test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16))
+
+	// wrong digest
+	witness.Hashed[0] = hashed[0] ^ 1
+	assert.Error(test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16))
+	witness.Hashed[0] = hashed[0]
+
+	// wrong signature
+	witness.Sig.S = params.ConstantFromBig(new(big.Int).Add(s, big.NewInt(1)))
+	assert.Error(test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16))
+}
+
+func TestPKCS1v15(t *testing.T) {
+	testRSA(t, false)
+}
+
+func TestPSS(t *testing.T) {
+	testRSA(t, true)
+}
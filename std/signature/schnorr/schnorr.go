@@ -0,0 +1,182 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package schnorr provides a ZKP-circuit function to verify a Schnorr
+// signature over secp256k1 as specified by BIP-340, used by Bitcoin Taproot.
+//
+// The fields of secp256k1 are emulated with std/math/nonnative, as in
+// std/signature/ecdsa, and the challenge is computed with the SHA-256 gadget
+// of std/hash/sha256.
+package schnorr
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/weierstrass"
+	gsha256 "github.com/consensys/gnark/std/hash/sha256"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/nonnative"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+func init() {
+	hint.Register(LiftX)
+}
+
+// PublicKey is a BIP-340 public key: the 32 bytes of the x coordinate of the
+// point, big-endian. The point is the one with an even y coordinate.
+type PublicKey [32]frontend.Variable
+
+// Signature is a BIP-340 signature: the x coordinate of the point R and the
+// scalar S, as 32 big-endian bytes each.
+type Signature struct {
+	R, S [32]frontend.Variable
+}
+
+// challengeTag is SHA-256("BIP0340/challenge"), which prefixes twice the
+// input of the challenge hash
+var challengeTag = sha256.Sum256([]byte("BIP0340/challenge"))
+
+// Verify verifies the BIP-340 signature sig of the message msg, given as
+// bytes, under the public key pubKey. It asserts that the public key, R and S
+// are canonical, and that
+//
+//	R = [S]G - [e]P
+//
+// where P is the point of the public key, R has an even y coordinate and
+// e = SHA-256(tag || tag || R || P || msg) mod n with tag the SHA-256 of
+// "BIP0340/challenge". The solver fails if pubKey is not the x coordinate of
+// a point of the curve.
+func Verify(api frontend.API, sig Signature, msg []frontend.Variable, pubKey PublicKey) {
+	curve := weierstrass.Secp256k1()
+
+	// e = tagged hash of R || P || msg; the bytes are range checked by the
+	// SHA-256 gadget
+	h := gsha256.New(api)
+	for i := 0; i < 2; i++ {
+		for _, b := range challengeTag {
+			h.Write(b)
+		}
+	}
+	h.Write(sig.R[:]...)
+	h.Write(pubKey[:]...)
+	h.Write(msg...)
+	var digest [32]frontend.Variable
+	copy(digest[:], h.Sum())
+	e := fromBytes(api, curve.Fr, digest)
+
+	// S is not hashed, its bytes are range checked here
+	bf := uints.New(api)
+	var sBytes [32]frontend.Variable
+	for i := range sBytes {
+		sBytes[i] = bf.ByteValueOf(sig.S[i])
+	}
+	s := fromBytes(api, curve.Fr, sBytes)
+	assertCanonical(api, s)
+	r := fromBytes(api, curve.Fp, sig.R)
+	assertCanonical(api, r)
+
+	// P = lift_x(pubKey), of even y
+	px := fromBytes(api, curve.Fp, pubKey)
+	assertCanonical(api, px)
+	limbs, err := api.Compiler().NewHint(LiftX, int(curve.Fp.NbLimbs()), px.Limbs...)
+	if err != nil {
+		panic(err)
+	}
+	p := weierstrass.AffinePoint{X: px, Y: curve.Fp.FromLimbs(limbs)}
+	curve.AssertIsOnCurve(api, p)
+	assertIsEven(api, p.Y)
+
+	// R = [s]G - [e]P, of even y and of x coordinate r
+	var negE nonnative.Element
+	negE.Neg(api, e)
+	res := curve.JointScalarMulBase(api, p, s, negE)
+	var x nonnative.Element
+	x.ReduceStrict(api, res.X)
+	for i := range x.Limbs {
+		api.AssertIsEqual(x.Limbs[i], r.Limbs[i])
+	}
+	assertIsEven(api, res.Y)
+}
+
+// fromBytes returns the element of the big-endian bytes b, which must be
+// range checked. The limbs of the elements of secp256k1 have 64 bits.
+func fromBytes(api frontend.API, fp *nonnative.Params, b [32]frontend.Variable) nonnative.Element {
+	limbs := make([]frontend.Variable, fp.NbLimbs())
+	for i := range limbs {
+		limbs[i] = 0
+		for j := 0; j < 8; j++ {
+			limbs[i] = api.Add(limbs[i], api.Mul(b[31-8*i-j], new(big.Int).Lsh(big.NewInt(1), uint(8*j))))
+		}
+	}
+	return fp.FromLimbs(limbs)
+}
+
+// assertCanonical asserts that the integer of the limbs of e is lower than
+// the modulus
+func assertCanonical(api frontend.API, e nonnative.Element) {
+	var r nonnative.Element
+	r.ReduceStrict(api, e)
+	for i := range r.Limbs {
+		api.AssertIsEqual(r.Limbs[i], e.Limbs[i])
+	}
+}
+
+// assertIsEven asserts that the representative of e in [0, p) is even
+func assertIsEven(api frontend.API, e nonnative.Element) {
+	var r nonnative.Element
+	r.ReduceStrict(api, e)
+	b := bits.ToBinary(api, r.Limbs[0], bits.WithNbDigits(64))
+	api.AssertIsEqual(b[0], 0)
+}
+
+// LiftX returns the limbs of the even y coordinate of the point of secp256k1
+// whose x coordinate is given by its limbs of 64 bits. It fails if there is
+// no such point.
+func LiftX(_ ecc.ID, inputs []*big.Int, results []*big.Int) error {
+	curve := weierstrass.Secp256k1()
+	p := curve.Fp.Modulus()
+
+	x := new(big.Int)
+	for i := len(inputs) - 1; i >= 0; i-- {
+		x.Lsh(x, 64).Add(x, inputs[i])
+	}
+
+	// y = (x³ + 7)^((p+1)/4), as p = 3 mod 4
+	y2 := new(big.Int).Exp(x, big.NewInt(3), p)
+	y2.Add(y2, curve.B).Mod(y2, p)
+	exp := new(big.Int).Add(p, big.NewInt(1))
+	exp.Rsh(exp, 2)
+	y := new(big.Int).Exp(y2, exp, p)
+	if new(big.Int).Exp(y, big.NewInt(2), p).Cmp(y2) != 0 {
+		return errors.New("x is not the coordinate of a point of the curve")
+	}
+	if y.Bit(0) == 1 {
+		y.Sub(p, y)
+	}
+
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 64), big.NewInt(1))
+	for i := range results {
+		results[i].And(y, mask)
+		y.Rsh(y, 64)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schnorr
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/weierstrass"
+	"github.com/consensys/gnark/test"
+)
+
+type schnorrCircuit struct {
+	Sig    Signature
+	Msg    [32]frontend.Variable
+	PubKey PublicKey `gnark:",public"`
+}
+
+func (circuit *schnorrCircuit) Define(api frontend.API) error {
+	Verify(api, circuit.Sig, circuit.Msg[:], circuit.PubKey)
+	return nil
+}
+
+// sign returns the public key of priv and a BIP-340 signature of msg, outside
+// of a circuit.
+func sign(t *testing.T, priv *big.Int, msg []byte) (pubKey, sig []byte) {
+	c := weierstrass.Secp256k1()
+	n := c.Fr.Modulus()
+
+	px, py := c.NativeScalarMul(c.Gx, c.Gy, priv)
+	d := new(big.Int).Set(priv)
+	if py.Bit(0) == 1 {
+		d.Sub(n, d)
+	}
+
+	k, err := rand.Int(rand.Reader, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rx, ry := c.NativeScalarMul(c.Gx, c.Gy, k)
+	if ry.Bit(0) == 1 {
+		k.Sub(n, k)
+	}
+
+	pubKey, r := bytes32(px), bytes32(rx)
+	h := sha256.New()
+	h.Write(challengeTag[:])
+	h.Write(challengeTag[:])
+	h.Write(r)
+	h.Write(pubKey)
+	h.Write(msg)
+	e := new(big.Int).SetBytes(h.Sum(nil))
+
+	s := new(big.Int).Mul(e, d)
+	s.Add(s, k).Mod(s, n)
+	return pubKey, append(r, bytes32(s)...)
+}
+
+func bytes32(v *big.Int) []byte {
+	res := make([]byte, 32)
+	return v.FillBytes(res)
+}
+
+func TestSchnorr(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	priv, err := rand.Int(rand.Reader, weierstrass.Secp256k1().Fr.Modulus())
+	assert.NoError(err)
+	msg := sha256.Sum256([]byte("testing BIP-340"))
+	pubKey, sig := sign(t, priv, msg[:])
+
+	var witness schnorrCircuit
+	for i := 0; i < 32; i++ {
+		witness.Sig.R[i] = sig[i]
+		witness.Sig.S[i] = sig[32+i]
+		witness.Msg[i] = msg[i]
+		witness.PubKey[i] = pubKey[i]
+	}
+	assert.NoError(test.IsSolved(&schnorrCircuit{}, &witness, ecc.BN254, backend.GROTH16))
+
+	// wrong message
+	witness.Msg[0] = msg[0] ^ 1
+	assert.Error(test.IsSolved(&schnorrCircuit{}, &witness, ecc.BN254, backend.GROTH16))
+}
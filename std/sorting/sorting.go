@@ -0,0 +1,152 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sorting provides ZKP-circuit functions to sort a slice of variables
+// with Batcher's odd-even merge sorting network.
+//
+// The network only depends on the number of values: it is a fixed sequence of
+// comparators (i, j), with i < j, each swapping the values at i and j if
+// values[j] < values[i]. A network sorting n values has O(n⋅log²(n))
+// comparators; each comparator costs a comparison and two constraints to
+// swap the values.
+//
+// The swap bits of the comparators, returned by Sort, describe the
+// permutation from the values to the sorted values: Permute applies it to
+// other slices, for example to sort records by a key.
+package sorting
+
+import (
+	"fmt"
+	"math/big"
+	stdbits "math/bits"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// Option configures Sort
+type Option func(*config) error
+
+type config struct {
+	nbBits int
+}
+
+// WithNbBits declares that the values fit in nbBits bits: the comparisons
+// then cost a decomposition of nbBits+1 bits instead of a comparison over the
+// whole field.
+//
+// The values are not range checked by Sort, the caller must ensure that they
+// are in [0, 2^nbBits).
+func WithNbBits(nbBits int) Option {
+	return func(c *config) error {
+		if nbBits <= 0 {
+			return fmt.Errorf("invalid number of bits %d", nbBits)
+		}
+		c.nbBits = nbBits
+		return nil
+	}
+}
+
+// Sort returns the values sorted in increasing order, and the swap bits of
+// the comparators of the sorting network of len(values) values: swaps[k] is 1
+// if the k-th comparator exchanged its values, 0 otherwise.
+//
+// Equal values are not swapped, but the network is not stable: the order of
+// equal values is not preserved in general.
+func Sort(api frontend.API, values []frontend.Variable, opts ...Option) (sorted, swaps []frontend.Variable) {
+	var cfg config
+	for _, o := range opts {
+		if err := o(&cfg); err != nil {
+			panic(err)
+		}
+	}
+
+	sorted = make([]frontend.Variable, len(values))
+	copy(sorted, values)
+	network := Network(len(values))
+	swaps = make([]frontend.Variable, len(network))
+	for k, c := range network {
+		swaps[k] = isGreater(api, sorted[c[0]], sorted[c[1]], cfg.nbBits)
+		sorted[c[0]], sorted[c[1]] = swap(api, swaps[k], sorted[c[0]], sorted[c[1]])
+	}
+	return sorted, swaps
+}
+
+// Permute applies to values the permutation described by swaps, the swap bits
+// of a sorting network of len(values) values returned by Sort.
+//
+// The swap bits are not constrained to be boolean: they must come from Sort,
+// or be checked by the caller.
+func Permute(api frontend.API, swaps, values []frontend.Variable) []frontend.Variable {
+	network := Network(len(values))
+	if len(swaps) != len(network) {
+		panic(fmt.Sprintf("sorting: %d swap bits for a network of %d comparators", len(swaps), len(network)))
+	}
+	res := make([]frontend.Variable, len(values))
+	copy(res, values)
+	for k, c := range network {
+		res[c[0]], res[c[1]] = swap(api, swaps[k], res[c[0]], res[c[1]])
+	}
+	return res
+}
+
+// Network returns the comparators (i, j), i < j, of Batcher's odd-even merge
+// sorting network of n values.
+//
+// The network of the next power of two is generated, and the comparators
+// involving the padding values are dropped: the padding values are seen as
+// +∞, which a comparator never moves to a lower index.
+func Network(n int) [][2]int {
+	if n <= 1 {
+		return nil
+	}
+	size := 1 << stdbits.Len(uint(n-1))
+
+	var res [][2]int
+	for p := 1; p < size; p *= 2 {
+		for k := p; k >= 1; k /= 2 {
+			for j := k % p; j+k < size; j += 2 * k {
+				for i := 0; i < k && i+j+k < size; i++ {
+					if (i+j)/(2*p) != (i+j+k)/(2*p) {
+						continue
+					}
+					if i+j+k < n {
+						res = append(res, [2]int{i + j, i + j + k})
+					}
+				}
+			}
+		}
+	}
+	return res
+}
+
+// isGreater returns 1 if a > b, 0 otherwise. If nbBits > 0, a and b must fit in
+// nbBits bits, and the result is the bit nbBits of a - b - 1 + 2^nbBits, which
+// is in [0, 2^(nbBits+1)).
+func isGreater(api frontend.API, a, b frontend.Variable, nbBits int) frontend.Variable {
+	if nbBits == 0 {
+		return api.IsLess(b, a)
+	}
+	offset := new(big.Int).Lsh(big.NewInt(1), uint(nbBits))
+	offset.Sub(offset, big.NewInt(1))
+	d := api.Add(api.Sub(a, b), offset)
+	return api.ToBinary(d, nbBits+1)[nbBits]
+}
+
+// swap returns (b, a) if s == 1, (a, b) otherwise
+func swap(api frontend.API, s, a, b frontend.Variable) (frontend.Variable, frontend.Variable) {
+	lo := api.Select(s, b, a)
+	return lo, api.Sub(api.Add(a, b), lo)
+}
@@ -0,0 +1,79 @@
+package sorting_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/sorting"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNetwork checks the networks with the 0-1 principle: a comparator network
+// sorts all its inputs if it sorts all the sequences of 0s and 1s.
+func TestNetwork(t *testing.T) {
+	assert := require.New(t)
+
+	for n := 0; n <= 12; n++ {
+		network := sorting.Network(n)
+		for mask := 0; mask < 1<<n; mask++ {
+			v := make([]int, n)
+			for i := range v {
+				v[i] = (mask >> i) & 1
+			}
+			for _, c := range network {
+				assert.Less(c[0], c[1])
+				if v[c[0]] > v[c[1]] {
+					v[c[0]], v[c[1]] = v[c[1]], v[c[0]]
+				}
+			}
+			assert.True(sort.IntsAreSorted(v), "n = %d, mask = %b", n, mask)
+		}
+	}
+}
+
+type sortCircuit struct {
+	Values, Payload       [6]frontend.Variable
+	Sorted, SortedPayload [6]frontend.Variable `gnark:",public"`
+	nbBits                int
+}
+
+func (c *sortCircuit) Define(api frontend.API) error {
+	var opts []sorting.Option
+	if c.nbBits != 0 {
+		opts = append(opts, sorting.WithNbBits(c.nbBits))
+	}
+	sorted, swaps := sorting.Sort(api, c.Values[:], opts...)
+	payload := sorting.Permute(api, swaps, c.Payload[:])
+	for i := range sorted {
+		api.AssertIsEqual(sorted[i], c.Sorted[i])
+		api.AssertIsEqual(payload[i], c.SortedPayload[i])
+	}
+	return nil
+}
+
+func TestSort(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	good := &sortCircuit{
+		Values:        [6]frontend.Variable{42, 7, 255, 0, 7, 13},
+		Payload:       [6]frontend.Variable{1, 2, 3, 4, 2, 6},
+		Sorted:        [6]frontend.Variable{0, 7, 7, 13, 42, 255},
+		SortedPayload: [6]frontend.Variable{4, 2, 2, 6, 1, 3},
+	}
+	bad := &sortCircuit{
+		Values:        [6]frontend.Variable{42, 7, 255, 0, 7, 13},
+		Payload:       [6]frontend.Variable{1, 2, 3, 4, 2, 6},
+		Sorted:        [6]frontend.Variable{0, 7, 7, 42, 13, 255},
+		SortedPayload: [6]frontend.Variable{4, 2, 2, 1, 6, 3},
+	}
+
+	assert.ProverSucceeded(&sortCircuit{nbBits: 8}, good)
+	assert.ProverFailed(&sortCircuit{nbBits: 8}, bad)
+
+	// the comparisons over the whole field are expensive, the circuit is only solved
+	assert.SolvingSucceeded(&sortCircuit{}, good, test.WithCurves(ecc.BN254))
+	assert.SolvingFailed(&sortCircuit{}, bad, test.WithCurves(ecc.BN254))
+}
@@ -1,3 +1,6 @@
+//go:build !verifieronly
+// +build !verifieronly
+
 /*
 Copyright © 2021 ConsenSys Software Inc.
 
@@ -19,9 +22,11 @@ package test
 import (
 	"errors"
 	"fmt"
+	mrand "math/rand"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/consensys/gnark"
 	"github.com/consensys/gnark-crypto/ecc"
@@ -120,6 +125,12 @@ func (assert *Assert) ProverSucceeded(circuit frontend.Circuit, validAssignment
 			}, curve.String(), "marshal-public/binary")
 		}
 
+		if opt.solverEquivalence {
+			assert.Run(func(assert *Assert) {
+				assert.solverEquivalence(circuit, validAssignment, curve, &opt)
+			}, curve.String(), "solver-equivalence")
+		}
+
 		for _, b := range opt.backends {
 
 			b := b
@@ -132,8 +143,9 @@ func (assert *Assert) ProverSucceeded(circuit frontend.Circuit, validAssignment
 				checkError(err)
 
 				// must not error with big int test engine (only the curveID is needed for this test)
-				err = IsSolved(circuit, validAssignment, curve, backend.UNKNOWN)
+				hints, err := isSolved(circuit, validAssignment, curve, backend.UNKNOWN)
 				checkError(err)
+				proverOpts := opt.proverOptsWithHints(hints)
 
 				assert.t.Parallel()
 
@@ -144,12 +156,16 @@ func (assert *Assert) ProverSucceeded(circuit frontend.Circuit, validAssignment
 
 					// ensure prove / verify works well with valid witnesses
 
-					proof, err := groth16.Prove(ccs, pk, validWitness, opt.proverOpts...)
+					proof, err := groth16.Prove(ccs, pk, validWitness, proverOpts...)
 					checkError(err)
 
 					err = groth16.Verify(proof, vk, validPublicWitness)
 					checkError(err)
 
+					if opt.solidity && curve == ecc.BN254 {
+						assert.solidityVerification(b, vk, proof, validPublicWitness)
+					}
+
 				case backend.PLONK:
 					srs, err := NewKZGSRS(ccs)
 					checkError(err)
@@ -157,12 +173,16 @@ func (assert *Assert) ProverSucceeded(circuit frontend.Circuit, validAssignment
 					pk, vk, err := plonk.Setup(ccs, srs)
 					checkError(err)
 
-					correctProof, err := plonk.Prove(ccs, pk, validWitness, opt.proverOpts...)
+					correctProof, err := plonk.Prove(ccs, pk, validWitness, proverOpts...)
 					checkError(err)
 
 					err = plonk.Verify(correctProof, vk, validPublicWitness)
 					checkError(err)
 
+					if opt.solidity && curve == ecc.BN254 {
+						assert.solidityVerification(b, vk, correctProof, validPublicWitness)
+					}
+
 				default:
 					panic("backend not implemented")
 				}
@@ -188,8 +208,6 @@ func (assert *Assert) ProverFailed(circuit frontend.Circuit, invalidAssignment f
 
 	opt := assert.options(opts...)
 
-	popts := append(opt.proverOpts, backend.IgnoreSolverError())
-
 	for _, curve := range opt.curves {
 
 		// parse assignment
@@ -211,8 +229,9 @@ func (assert *Assert) ProverFailed(circuit frontend.Circuit, invalidAssignment f
 				checkError(err)
 
 				// must error with big int test engine (only the curveID is needed here)
-				err = IsSolved(circuit, invalidAssignment, curve, backend.UNKNOWN)
+				hints, err := isSolved(circuit, invalidAssignment, curve, backend.UNKNOWN)
 				mustError(err)
+				popts := append(opt.proverOptsWithHints(hints), backend.IgnoreSolverError())
 
 				assert.t.Parallel()
 				err = ccs.IsSolved(invalidPublicWitness)
@@ -252,13 +271,18 @@ func (assert *Assert) SolvingSucceeded(circuit frontend.Circuit, validWitness fr
 	opt := assert.options(opts...)
 
 	for _, curve := range opt.curves {
+		curve := curve
 		for _, b := range opt.backends {
-			curve := curve
 			b := b
 			assert.Run(func(assert *Assert) {
 				assert.solvingSucceeded(circuit, validWitness, b, curve, &opt)
 			}, curve.String(), b.String())
 		}
+		if opt.solverEquivalence {
+			assert.Run(func(assert *Assert) {
+				assert.solverEquivalence(circuit, validWitness, curve, &opt)
+			}, curve.String(), "solver-equivalence")
+		}
 	}
 }
 
@@ -274,10 +298,10 @@ func (assert *Assert) solvingSucceeded(circuit frontend.Circuit, validAssignment
 	checkError(err)
 
 	// must not error with big int test engine
-	err = IsSolved(circuit, validAssignment, curve, b)
+	hints, err := isSolved(circuit, validAssignment, curve, b)
 	checkError(err)
 
-	err = ccs.IsSolved(validWitness, opt.proverOpts...)
+	err = ccs.IsSolved(validWitness, opt.proverOptsWithHints(hints)...)
 	checkError(err)
 
 }
@@ -309,10 +333,10 @@ func (assert *Assert) solvingFailed(circuit frontend.Circuit, invalidAssignment
 	checkError(err)
 
 	// must error with big int test engine
-	err = IsSolved(circuit, invalidAssignment, curve, b)
+	hints, err := isSolved(circuit, invalidAssignment, curve, b)
 	mustError(err)
 
-	err = ccs.IsSolved(invalidWitness, opt.proverOpts...)
+	err = ccs.IsSolved(invalidWitness, opt.proverOptsWithHints(hints)...)
 	mustError(err)
 
 }
@@ -379,6 +403,46 @@ func (assert *Assert) Fuzz(circuit frontend.Circuit, fuzzCount int, opts ...Test
 	}
 }
 
+// FuzzWitness fuzzes the given circuit by mutating seedAssignment fuzzCount
+// times (bit flips, boundary values, field overflow candidates, values of the
+// seed corpus), and cross checking the result of the big.Int test execution
+// engine and of the constraint system solver on the mutated witnesses.
+//
+// In particular, it fails the test if the solver (hence the prover) accepts an
+// assignment that the test engine rejects: the circuit is under-constrained.
+// The seed of the mutations is logged, see FuzzWitnessSeed to reproduce a failure.
+func (assert *Assert) FuzzWitness(circuit, seedAssignment frontend.Circuit, fuzzCount int, opts ...TestingOption) {
+	seed := time.Now().UnixNano()
+	assert.Log("fuzzing witness with seed", seed)
+	assert.FuzzWitnessSeed(circuit, seedAssignment, fuzzCount, seed, opts...)
+}
+
+// FuzzWitnessSeed behaves like FuzzWitness, with the mutations drawn from the given seed
+func (assert *Assert) FuzzWitnessSeed(circuit, seedAssignment frontend.Circuit, fuzzCount int, seed int64, opts ...TestingOption) {
+	opt := assert.options(opts...)
+
+	w := shallowClone(circuit)
+
+	for _, curve := range opt.curves {
+		for _, b := range opt.backends {
+			curve := curve
+			b := b
+			assert.Run(func(assert *Assert) {
+				// see Fuzz
+				_, err := assert.compile(circuit, curve, b, opt.compileOpts)
+				assert.NoError(err)
+
+				rng := mrand.New(mrand.NewSource(seed)) //#nosec G404 weak rng is fine here
+				for i := 0; i < fuzzCount; i++ {
+					copyWitness(w, seedAssignment)
+					mutate(w, curve, rng)
+					assert.fuzzer(func(frontend.Circuit, ecc.ID) {}, circuit, w, b, curve, &opt)
+				}
+			}, curve.String(), b.String())
+		}
+	}
+}
+
 func (assert *Assert) fuzzer(fuzzer filler, circuit, w frontend.Circuit, b backend.ID, curve ecc.ID, opt *testingConfig) int {
 	// fuzz a witness
 	fuzzer(w, curve)
@@ -418,24 +482,13 @@ func (assert *Assert) compile(circuit frontend.Circuit, curveID ecc.ID, backendI
 		return ccs, nil
 	}
 
-	var newBuilder frontend.NewBuilder
-
-	switch backendID {
-	case backend.GROTH16:
-		newBuilder = r1cs.NewBuilder
-	case backend.PLONK:
-		newBuilder = scs.NewBuilder
-	default:
-		panic("not implemented")
-	}
-
 	// else compile it and ensure it is deterministic
-	ccs, err := frontend.Compile(curveID, newBuilder, circuit, compileOpts...)
+	ccs, err := frontend.Compile(curveID, newBuilder(backendID), circuit, compileOpts...)
 	if err != nil {
 		return nil, err
 	}
 
-	_ccs, err := frontend.Compile(curveID, newBuilder, circuit, compileOpts...)
+	_ccs, err := frontend.Compile(curveID, newBuilder(backendID), circuit, compileOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrCompilationNotDeterministic, err)
 	}
@@ -450,6 +503,18 @@ func (assert *Assert) compile(circuit frontend.Circuit, curveID ecc.ID, backendI
 	return ccs, nil
 }
 
+// newBuilder returns the constraint system builder of the given backend
+func newBuilder(backendID backend.ID) frontend.NewBuilder {
+	switch backendID {
+	case backend.GROTH16:
+		return r1cs.NewBuilder
+	case backend.PLONK:
+		return scs.NewBuilder
+	default:
+		panic("not implemented")
+	}
+}
+
 // default options
 func (assert *Assert) options(opts ...TestingOption) testingConfig {
 	// apply options
@@ -1,3 +1,6 @@
+//go:build !verifieronly
+// +build !verifieronly
+
 /*
 Copyright © 2021 ConsenSys Software Inc.
 
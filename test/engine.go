@@ -1,3 +1,6 @@
+//go:build !verifieronly
+// +build !verifieronly
+
 /*
 Copyright © 2021 ConsenSys Software Inc.
 
@@ -32,6 +35,8 @@ import (
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/hint"
 	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/compiled"
+	"github.com/consensys/gnark/frontend/cs"
 	"github.com/consensys/gnark/internal/utils"
 )
 
@@ -45,23 +50,39 @@ type engine struct {
 	backendID backend.ID
 	curveID   ecc.ID
 	opt       backend.ProverConfig
-	// mHintsFunctions map[hint.ID]hintFunction
+	committed bool
+
+	// current namespace path, see frontend.Namespace
+	namespaces []string
+
+	// hints executed by the engine, see NewHint
+	hints map[hint.ID]hint.Function
 }
 
 // IsSolved returns an error if the test execution engine failed to execute the given circuit
 // with provided witness as input.
 //
 // The test execution engine implements frontend.API using big.Int operations.
+// The hints are executed directly, registered or not: no backend.WithHints
+// option is needed, for example for the emulated arithmetic of std/math/nonnative.
 //
 // This is an experimental feature.
 func IsSolved(circuit, witness frontend.Circuit, curveID ecc.ID, b backend.ID, opts ...backend.ProverOption) (err error) {
+	_, err = isSolved(circuit, witness, curveID, b, opts...)
+	return
+}
+
+// isSolved behaves like IsSolved, and returns the hints which are not
+// registered (see hint.Register) that the engine executed: the constraint
+// system solver needs them as prover options (see backend.WithHints).
+func isSolved(circuit, witness frontend.Circuit, curveID ecc.ID, b backend.ID, opts ...backend.ProverOption) (hints []hint.Function, err error) {
 	// apply options
 	opt, err := backend.NewProverConfig(opts...)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	e := &engine{backendID: b, curveID: curveID, opt: opt}
+	e := &engine{backendID: b, curveID: curveID, opt: opt, hints: make(map[hint.ID]hint.Function)}
 	if opt.Force {
 		panic("ignoring errors in test.Engine is not supported")
 	}
@@ -82,6 +103,16 @@ func IsSolved(circuit, witness frontend.Circuit, curveID ecc.ID, b backend.ID, o
 		if r := recover(); r != nil {
 			err = fmt.Errorf("%v\n%s", r, string(debug.Stack()))
 		}
+		// the hints executed before an error are also needed by the solver
+		registered := make(map[hint.ID]struct{})
+		for _, h := range hint.GetRegistered() {
+			registered[hint.UUID(h)] = struct{}{}
+		}
+		for id, h := range e.hints {
+			if _, ok := registered[id]; !ok {
+				hints = append(hints, h)
+			}
+		}
 	}()
 
 	err = c.Define(e)
@@ -157,6 +188,31 @@ func (e *engine) Inverse(i1 frontend.Variable) frontend.Variable {
 	return b1
 }
 
+func (e *engine) BatchInvert(vs []frontend.Variable) []frontend.Variable {
+	res := make([]frontend.Variable, len(vs))
+	for i := range vs {
+		res[i] = e.Inverse(vs[i])
+	}
+	return res
+}
+
+func (e *engine) Exp(base, exponent frontend.Variable, nbBits ...int) frontend.Variable {
+	n := e.bitLen()
+	if len(nbBits) == 1 {
+		n = nbBits[0]
+		if n < 0 {
+			panic("invalid nbBits")
+		}
+	}
+
+	b, x := e.toBigInt(base), e.toBigInt(exponent)
+	if x.BitLen() > n {
+		panic(fmt.Sprintf("[Exp] exponent %s (bitLen == %d) doesn't fit on %d bits", x.String(), x.BitLen(), n))
+	}
+	b.Exp(&b, &x, e.modulus())
+	return b
+}
+
 func (e *engine) ToBinary(i1 frontend.Variable, n ...int) []frontend.Variable {
 	nbBits := e.bitLen()
 	if len(n) == 1 {
@@ -209,28 +265,68 @@ func (e *engine) FromBinary(v ...frontend.Variable) frontend.Variable {
 	return r
 }
 
-func (e *engine) Xor(i1, i2 frontend.Variable) frontend.Variable {
-	b1, b2 := e.toBigInt(i1), e.toBigInt(i2)
-	e.mustBeBoolean(&b1)
-	e.mustBeBoolean(&b2)
-	b1.Xor(&b1, &b2)
-	return b1
+func (e *engine) Xor(i1, i2 frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	return e.boolean((*big.Int).Xor, append([]frontend.Variable{i1, i2}, in...))
 }
 
-func (e *engine) Or(i1, i2 frontend.Variable) frontend.Variable {
-	b1, b2 := e.toBigInt(i1), e.toBigInt(i2)
-	e.mustBeBoolean(&b1)
-	e.mustBeBoolean(&b2)
-	b1.Or(&b1, &b2)
-	return b1
+func (e *engine) Or(i1, i2 frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	return e.boolean((*big.Int).Or, append([]frontend.Variable{i1, i2}, in...))
 }
 
-func (e *engine) And(i1, i2 frontend.Variable) frontend.Variable {
-	b1, b2 := e.toBigInt(i1), e.toBigInt(i2)
-	e.mustBeBoolean(&b1)
-	e.mustBeBoolean(&b2)
-	b1.And(&b1, &b2)
-	return b1
+func (e *engine) And(i1, i2 frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	return e.boolean((*big.Int).And, append([]frontend.Variable{i1, i2}, in...))
+}
+
+func (e *engine) Nand(i1, i2 frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	r := e.And(i1, i2, in...).(big.Int)
+	return *r.Xor(&r, big.NewInt(1))
+}
+
+func (e *engine) Nor(i1, i2 frontend.Variable, in ...frontend.Variable) frontend.Variable {
+	r := e.Or(i1, i2, in...).(big.Int)
+	return *r.Xor(&r, big.NewInt(1))
+}
+
+func (e *engine) WordXor(a, b frontend.Variable, nbBits int) frontend.Variable {
+	x, y := e.toWord(a, nbBits), e.toWord(b, nbBits)
+	return *x.Xor(&x, &y)
+}
+
+func (e *engine) WordAnd(a, b frontend.Variable, nbBits int) frontend.Variable {
+	x, y := e.toWord(a, nbBits), e.toWord(b, nbBits)
+	return *x.And(&x, &y)
+}
+
+func (e *engine) WordRotateLeft(a frontend.Variable, k, nbBits int) frontend.Variable {
+	x := e.toWord(a, nbBits)
+	k = cs.RotationOffset(k, nbBits)
+	mask := new(big.Int).Lsh(big.NewInt(1), uint(nbBits))
+	mask.Sub(mask, big.NewInt(1))
+	var res big.Int
+	res.Lsh(&x, uint(k)).Or(&res, new(big.Int).Rsh(&x, uint(nbBits-k))).And(&res, mask)
+	return res
+}
+
+// toWord checks that a fits in a word of nbBits bits
+func (e *engine) toWord(a frontend.Variable, nbBits int) big.Int {
+	cs.CheckWordSize(nbBits)
+	x := e.toBigInt(a)
+	if x.BitLen() > nbBits {
+		panic(fmt.Sprintf("%s doesn't fit in a word of %d bits", x.String(), nbBits))
+	}
+	return x
+}
+
+// boolean checks that the inputs are boolean and combines them with op
+func (e *engine) boolean(op func(z, x, y *big.Int) *big.Int, in []frontend.Variable) big.Int {
+	res := e.toBigInt(in[0])
+	e.mustBeBoolean(&res)
+	for i := 1; i < len(in); i++ {
+		b := e.toBigInt(in[i])
+		e.mustBeBoolean(&b)
+		op(&res, &res, &b)
+	}
+	return res
 }
 
 // Select if b is true, yields i1 else yields i2
@@ -257,6 +353,19 @@ func (e *engine) Lookup2(b0, b1 frontend.Variable, i0, i1, i2, i3 frontend.Varia
 	return e.toBigInt([]frontend.Variable{i0, i1, i2, i3}[lookup.Uint64()])
 }
 
+// LookupN returns values[i], where i is the little endian integer of the bits
+// b, see frontend.API.LookupN
+func (e *engine) LookupN(b []frontend.Variable, values []frontend.Variable) frontend.Variable {
+	cs.CheckLookupSize(len(b), len(values))
+	var lookup uint64
+	for i := range b {
+		s := e.toBigInt(b[i])
+		e.mustBeBoolean(&s)
+		lookup |= s.Uint64() << i
+	}
+	return e.toBigInt(values[lookup])
+}
+
 // IsZero returns 1 if a is zero, 0 otherwise
 func (e *engine) IsZero(i1 frontend.Variable) frontend.Variable {
 	b1 := e.toBigInt(i1)
@@ -275,17 +384,59 @@ func (e *engine) Cmp(i1, i2 frontend.Variable) frontend.Variable {
 	return e.toBigInt(b1.Cmp(&b2))
 }
 
+// IsLess returns 1 if i1<i2, 0 otherwise
+func (e *engine) IsLess(i1, i2 frontend.Variable) frontend.Variable {
+	b1 := e.toBigInt(i1)
+	b2 := e.toBigInt(i2)
+	if b1.Cmp(&b2) == -1 {
+		return (1)
+	}
+	return (0)
+}
+
+// IsInRange returns 1 if lower<=v<=upper, 0 otherwise
+func (e *engine) IsInRange(v, lower, upper frontend.Variable) frontend.Variable {
+	b, l, u := e.toBigInt(v), e.toBigInt(lower), e.toBigInt(upper)
+	if b.Cmp(&l) >= 0 && b.Cmp(&u) <= 0 {
+		return (1)
+	}
+	return (0)
+}
+
 func (e *engine) AssertIsEqual(i1, i2 frontend.Variable) {
 	b1, b2 := e.toBigInt(i1), e.toBigInt(i2)
 	if b1.Cmp(&b2) != 0 {
-		panic(fmt.Sprintf("[assertIsEqual] %s == %s", b1.String(), b2.String()))
+		panic(e.errorf("assertIsEqual", "%s == %s", b1.String(), b2.String()))
 	}
 }
 
 func (e *engine) AssertIsDifferent(i1, i2 frontend.Variable) {
 	b1, b2 := e.toBigInt(i1), e.toBigInt(i2)
 	if b1.Cmp(&b2) == 0 {
-		panic(fmt.Sprintf("[assertIsDifferent] %s != %s", b1.String(), b2.String()))
+		panic(e.errorf("assertIsDifferent", "%s != %s", b1.String(), b2.String()))
+	}
+}
+
+func (e *engine) AssertIsEqualIf(cond, i1, i2 frontend.Variable) {
+	c := e.toBigInt(cond)
+	if c.Sign() == 0 {
+		return
+	}
+	b1, b2 := e.toBigInt(i1), e.toBigInt(i2)
+	if b1.Cmp(&b2) != 0 {
+		panic(e.errorf("assertIsEqualIf", "%s == %s", b1.String(), b2.String()))
+	}
+}
+
+func (e *engine) AssertIsDifferentIf(cond, i1, i2 frontend.Variable) {
+	c := e.toBigInt(cond)
+	e.mustBeBoolean(&c)
+	if c.Sign() == 0 {
+		return
+	}
+	b1, b2 := e.toBigInt(i1), e.toBigInt(i2)
+	if b1.Cmp(&b2) == 0 {
+		panic(e.errorf("assertIsDifferentIf", "%s != %s", b1.String(), b2.String()))
 	}
 }
 
@@ -299,12 +450,33 @@ func (e *engine) AssertIsLessOrEqual(v frontend.Variable, bound frontend.Variabl
 	bValue := e.toBigInt(bound)
 
 	if bValue.Sign() == -1 {
-		panic(fmt.Sprintf("[assertIsLessOrEqual] bound (%s) must be positive", bValue.String()))
+		panic(e.errorf("assertIsLessOrEqual", "bound (%s) must be positive", bValue.String()))
 	}
 
 	b1 := e.toBigInt(v)
 	if b1.Cmp(&bValue) == 1 {
-		panic(fmt.Sprintf("[assertIsLessOrEqual] %s > %s", b1.String(), bValue.String()))
+		panic(e.errorf("assertIsLessOrEqual", "%s > %s", b1.String(), bValue.String()))
+	}
+}
+
+func (e *engine) AssertIsLess(v frontend.Variable, bound frontend.Variable) {
+
+	bValue := e.toBigInt(bound)
+
+	if bValue.Sign() == 0 {
+		panic(e.errorf("assertIsLess", "bound must be strictly positive"))
+	}
+
+	b1 := e.toBigInt(v)
+	if b1.Cmp(&bValue) != -1 {
+		panic(e.errorf("assertIsLess", "%s >= %s", b1.String(), bValue.String()))
+	}
+}
+
+func (e *engine) AssertIsInRange(v, lower, upper frontend.Variable) {
+	b, l, u := e.toBigInt(v), e.toBigInt(lower), e.toBigInt(upper)
+	if b.Cmp(&l) == -1 || b.Cmp(&u) == 1 {
+		panic(e.errorf("assertIsInRange", "%s ∉ [%s, %s]", b.String(), l.String(), u.String()))
 	}
 }
 
@@ -321,19 +493,110 @@ func (e *engine) Println(a ...frontend.Variable) {
 	}
 
 	for i := 0; i < len(a); i++ {
-		v := e.toBigInt(a[i])
-		sbb.WriteString(v.String())
+		e.printArg(&sbb, a[i], "%v")
 		sbb.WriteByte(' ')
 	}
 	fmt.Println(sbb.String())
 }
 
+func (e *engine) Printf(format string, a ...frontend.Variable) {
+	e.printf(format, a)
+}
+
+func (e *engine) PrintfIf(condition frontend.Variable, format string, a ...frontend.Variable) {
+	c := e.toBigInt(condition)
+	if !(c.IsUint64() && c.Uint64() == 1) {
+		return
+	}
+	e.printf(format, a)
+}
+
+func (e *engine) printf(format string, a []frontend.Variable) {
+	var sbb strings.Builder
+	sbb.WriteString("(test.engine) ")
+
+	// prefix log line with file.go:line of the caller of Printf or PrintfIf
+	if _, file, line, ok := runtime.Caller(2); ok {
+		sbb.WriteString(filepath.Base(file))
+		sbb.WriteByte(':')
+		sbb.WriteString(strconv.Itoa(line))
+		sbb.WriteByte(' ')
+	}
+
+	texts, verbs := compiled.SplitFormat(format)
+	for i, verb := range verbs {
+		sbb.WriteString(strings.ReplaceAll(texts[i], "%%", "%"))
+		if i < len(a) {
+			e.printArg(&sbb, a[i], verb)
+		} else {
+			// like fmt.Sprintf, report the missing argument
+			sbb.WriteString("%!" + verb[len(verb)-1:] + "(MISSING)")
+		}
+	}
+	sbb.WriteString(strings.ReplaceAll(texts[len(verbs)], "%%", "%"))
+	if len(a) > len(verbs) {
+		sbb.WriteString("%!(EXTRA ")
+		for i := len(verbs); i < len(a); i++ {
+			if i > len(verbs) {
+				sbb.WriteString(", ")
+			}
+			e.printArg(&sbb, a[i], "%v")
+		}
+		sbb.WriteByte(')')
+	}
+	fmt.Println(sbb.String())
+}
+
+// printArg writes a to sbb with the verb like the compiled Println and Printf do:
+// strings as they are, the variables of structures (for example a nonnative.Element)
+// with their names, and the other arguments as variables
+func (e *engine) printArg(sbb *strings.Builder, a frontend.Variable, verb string) {
+	if s, ok := a.(string); ok {
+		sbb.WriteString(fmt.Sprintf(verb, s))
+		return
+	}
+
+	v := reflect.ValueOf(a)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct || v.Type() == reflect.TypeOf(big.Int{}) {
+		sbb.WriteString(formatBigInt(verb, e.toBigInt(a)))
+		return
+	}
+
+	var fields []string
+	printer := func(visibility schema.Visibility, name string, tValue reflect.Value) error {
+		b := e.toBigInt(tValue.Interface())
+		fields = append(fields, name+": "+formatBigInt(verb, b))
+		return nil
+	}
+	// schema.Parse needs a pointer to the structure
+	pv := reflect.New(v.Type())
+	pv.Elem().Set(v)
+	// ignoring error, printer() doesn't return errors
+	_, _ = schema.Parse(pv.Interface(), tVariable, printer)
+	sbb.WriteByte('{')
+	sbb.WriteString(strings.Join(fields, ", "))
+	sbb.WriteByte('}')
+}
+
+// formatBigInt formats b with the verb, %s and %v print it in decimal
+func formatBigInt(verb string, b big.Int) string {
+	if strings.HasSuffix(verb, "s") || strings.HasSuffix(verb, "v") {
+		verb = verb[:len(verb)-1] + "d"
+	}
+	return fmt.Sprintf(verb, &b)
+}
+
 func (e *engine) NewHint(f hint.Function, nbOutputs int, inputs ...frontend.Variable) ([]frontend.Variable, error) {
 
 	if nbOutputs <= 0 {
 		return nil, fmt.Errorf("hint function must return at least one output")
 	}
 
+	e.hints[hint.UUID(f)] = f
+
 	in := make([]*big.Int, len(inputs))
 
 	for i := 0; i < len(inputs); i++ {
@@ -359,6 +622,25 @@ func (e *engine) NewHint(f hint.Function, nbOutputs int, inputs ...frontend.Vari
 	return out, nil
 }
 
+// Commit returns a hash of the values of v (see compiled.CommitmentHint)
+func (e *engine) Commit(v ...frontend.Variable) frontend.Variable {
+	if e.committed {
+		panic("a circuit can commit only once")
+	}
+	e.committed = true
+
+	in := make([]*big.Int, len(v))
+	for i := 0; i < len(v); i++ {
+		b := e.toBigInt(v[i])
+		in[i] = &b
+	}
+	res := new(big.Int)
+	if err := compiled.CommitmentHint(e.curveID, in, []*big.Int{res}); err != nil {
+		panic("Commit: " + err.Error())
+	}
+	return res
+}
+
 // IsConstant returns true if v is a constant known at compile time
 func (e *engine) IsConstant(v frontend.Variable) bool {
 	// TODO @gbotrel this is a problem. if a circuit component has 2 code path depending
@@ -394,6 +676,23 @@ func (e *engine) AddCounter(from, to frontend.Tag) {
 	// do nothing, we don't measure constraints with the test engine
 }
 
+func (e *engine) PushNamespace(name string) {
+	e.namespaces = append(e.namespaces, name)
+}
+
+func (e *engine) PopNamespace() {
+	e.namespaces = e.namespaces[:len(e.namespaces)-1]
+}
+
+// errorf returns the message of the failed assertion errName, prefixed by the
+// current namespace path as in the debug info of the compiled circuits
+func (e *engine) errorf(errName, format string, a ...interface{}) string {
+	if len(e.namespaces) > 0 {
+		errName = strings.Join(e.namespaces, "/") + "/" + errName
+	}
+	return "[" + errName + "] " + fmt.Sprintf(format, a...)
+}
+
 func (e *engine) toBigInt(i1 frontend.Variable) big.Int {
 	b := utils.FromInterface(i1)
 	b.Mod(&b, e.modulus())
@@ -407,7 +706,7 @@ func (e *engine) bitLen() int {
 
 func (e *engine) mustBeBoolean(b *big.Int) {
 	if !b.IsUint64() || !(b.Uint64() == 0 || b.Uint64() == 1) {
-		panic(fmt.Sprintf("[assertIsBoolean] %s", b.String()))
+		panic(e.errorf("assertIsBoolean", "%s", b.String()))
 	}
 }
 
@@ -1,14 +1,19 @@
 package test
 
 import (
+	"bytes"
 	"fmt"
+	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/consensys/gnark"
+	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/hint"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/nonnative"
 )
 
 type hintCircuit struct {
@@ -63,3 +68,222 @@ func TestBuiltinHints(t *testing.T) {
 	}
 
 }
+
+type cubeComponent struct {
+	X, Y frontend.Variable
+}
+
+func (c *cubeComponent) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X, c.X), c.Y)
+	return nil
+}
+
+type namespaceCircuit struct {
+	X, Y frontend.Variable
+}
+
+func (circuit *namespaceCircuit) Define(api frontend.API) error {
+	defer frontend.Namespace(api, "outer")()
+	return frontend.DefineComponent(api, "cube", &cubeComponent{X: circuit.X, Y: circuit.Y})
+}
+
+func TestNamespace(t *testing.T) {
+	if err := IsSolved(&namespaceCircuit{}, &namespaceCircuit{X: 3, Y: 27}, ecc.BN254, backend.UNKNOWN); err != nil {
+		t.Fatal(err)
+	}
+
+	err := IsSolved(&namespaceCircuit{}, &namespaceCircuit{X: 3, Y: 28}, ecc.BN254, backend.UNKNOWN)
+	if err == nil {
+		t.Fatal("witness shouldn't solve circuit")
+	}
+	if !strings.Contains(err.Error(), "[outer/cube/assertIsEqual]") {
+		t.Fatal("namespace missing in error:", err)
+	}
+}
+
+type printCircuit struct {
+	X, Y frontend.Variable
+}
+
+func (circuit *printCircuit) Define(api frontend.API) error {
+	x3 := api.Mul(circuit.X, circuit.X, circuit.X)
+	api.Println(api.Add(x3, circuit.X))
+	api.AssertIsEqual(x3, circuit.Y)
+	return nil
+}
+
+func TestSolverEquivalence(t *testing.T) {
+	assert := NewAssert(t)
+	assert.SolvingSucceeded(&printCircuit{}, &printCircuit{X: 3, Y: 27}, WithCurves(ecc.BN254), WithSolverEquivalence())
+
+	// the printed values differ
+	var logs [2][]solverLog
+	for i, message := range []string{"x = 3", "x = 4"} {
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, `{"level":"debug","caller":"circuit.go:1","message":%q}`+"\n", message)
+		var err error
+		logs[i], err = parseSolverLogs(&buf)
+		assert.NoError(err)
+	}
+	assert.Equal("circuit.go:1", logs[0][0].Caller)
+	assert.NotEqual(logs[0], logs[1])
+}
+
+func TestFuzzWitness(t *testing.T) {
+	assert := NewAssert(t)
+	assert.FuzzWitness(&namespaceCircuit{}, &namespaceCircuit{X: 3, Y: 27}, 20, WithCurves(ecc.BN254))
+}
+
+// unregisteredHint is not registered with hint.Register
+func unregisteredHint(_ ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+	outputs[0].Mul(inputs[0], inputs[0])
+	return nil
+}
+
+type unregisteredHintCircuit struct {
+	X, Y frontend.Variable
+}
+
+func (circuit *unregisteredHintCircuit) Define(api frontend.API) error {
+	res, err := api.Compiler().NewHint(unregisteredHint, 1, circuit.X)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X), res[0])
+	api.AssertIsEqual(res[0], circuit.Y)
+	return nil
+}
+
+func TestUnregisteredHint(t *testing.T) {
+	assert := NewAssert(t)
+	assert.ProverSucceeded(&unregisteredHintCircuit{}, &unregisteredHintCircuit{X: 3, Y: 9}, WithCurves(ecc.BN254))
+	assert.ProverFailed(&unregisteredHintCircuit{}, &unregisteredHintCircuit{X: 3, Y: 8}, WithCurves(ecc.BN254))
+}
+
+type nonnativeCircuit struct {
+	A, B, Prod nonnative.Element
+}
+
+func (circuit *nonnativeCircuit) Define(api frontend.API) error {
+	var prod nonnative.Element
+	prod.Mul(api, circuit.A, circuit.B)
+	api.Println("a·b =", prod)
+	prod.AssertIsEqual(api, prod, circuit.Prod)
+	return nil
+}
+
+func TestNonnative(t *testing.T) {
+	params, err := nonnative.NewParams(64, ecc.BLS12_381.Info().Fp.Modulus())
+	if err != nil {
+		t.Fatal(err)
+	}
+	circuit := nonnativeCircuit{A: params.Placeholder(), B: params.Placeholder(), Prod: params.Placeholder()}
+	a, b := big.NewInt(1), new(big.Int).Sub(params.Modulus(), big.NewInt(2))
+	witness := nonnativeCircuit{
+		A:    params.ConstantFromBig(a),
+		B:    params.ConstantFromBig(b),
+		Prod: params.ConstantFromBig(new(big.Int).Mul(a, b)),
+	}
+	if err := IsSolved(&circuit, &witness, ecc.BN254, backend.UNKNOWN); err != nil {
+		t.Fatal(err)
+	}
+	witness.Prod = params.ConstantFromBig(big.NewInt(2))
+	if err := IsSolved(&circuit, &witness, ecc.BN254, backend.UNKNOWN); err == nil {
+		t.Fatal("witness shouldn't solve circuit")
+	}
+}
+
+type sliceLenCircuit struct {
+	N int
+	X []frontend.Variable `gnark:",len=N"`
+	Y frontend.Variable   `gnark:",public"`
+}
+
+func (circuit *sliceLenCircuit) Define(api frontend.API) error {
+	sum := frontend.Variable(0)
+	for i := 0; i < len(circuit.X); i++ {
+		sum = api.Add(sum, circuit.X[i])
+	}
+	api.AssertIsEqual(sum, circuit.Y)
+	return nil
+}
+
+func TestSliceLen(t *testing.T) {
+	assert := NewAssert(t)
+
+	assert.ProverSucceeded(&sliceLenCircuit{N: 3}, &sliceLenCircuit{
+		X: []frontend.Variable{1, 2, 3},
+		Y: 6,
+	}, WithCurves(ecc.BN254))
+	assert.ProverFailed(&sliceLenCircuit{N: 3}, &sliceLenCircuit{
+		X: []frontend.Variable{1, 2, 3},
+		Y: 7,
+	}, WithCurves(ecc.BN254))
+}
+
+type wordCircuit struct {
+	X, Y, X16, X8                              frontend.Variable
+	Xor, And, Rot, RotBytes, Rot16, Xor8, Rot8 frontend.Variable `gnark:",public"`
+}
+
+func (circuit *wordCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.WordXor(circuit.X, circuit.Y, 32), circuit.Xor)
+	api.AssertIsEqual(api.WordAnd(circuit.X, circuit.Y, 32), circuit.And)
+	api.AssertIsEqual(api.WordRotateLeft(circuit.X, 7, 32), circuit.Rot)
+	api.AssertIsEqual(api.WordRotateLeft(circuit.X, -8, 32), circuit.RotBytes)
+	api.AssertIsEqual(api.WordRotateLeft(circuit.X16, 3, 16), circuit.Rot16)
+	api.AssertIsEqual(api.WordXor(circuit.X8, 0x78, 8), circuit.Xor8)
+	api.AssertIsEqual(api.WordRotateLeft(circuit.X8, 5, 8), circuit.Rot8)
+
+	// constant inputs
+	api.AssertIsEqual(api.WordAnd(circuit.X, 0xffffffff, 32), circuit.X)
+	api.AssertIsEqual(api.WordXor(0xef, 0x78, 8), 0x97)
+	api.AssertIsEqual(api.WordRotateLeft(0xbeef, 3, 16), 0xf77d)
+	return nil
+}
+
+// TestWord proves the word operations with Groth16, and only solves them with
+// PlonK, as the lookup tables of 2¹⁶ entries make the setup expensive.
+func TestWord(t *testing.T) {
+	assert := NewAssert(t)
+	var circuit wordCircuit
+
+	good := []frontend.Circuit{
+		&wordCircuit{
+			X: 0xdeadbeef, Y: 0x12345678, X16: 0xbeef, X8: 0xef,
+			Xor: 0xcc99e897, And: 0x12241668, Rot: 0x56df77ef, RotBytes: 0xefdeadbe, Rot16: 0xf77d, Xor8: 0x97, Rot8: 0xfd,
+		},
+		&wordCircuit{
+			X: 0xffffffff, Y: 1, X16: 0xffff, X8: 0xff,
+			Xor: 0xfffffffe, And: 1, Rot: 0xffffffff, RotBytes: 0xffffffff, Rot16: 0xffff, Xor8: 0x87, Rot8: 0xff,
+		},
+	}
+
+	bad := []frontend.Circuit{
+		&wordCircuit{
+			X: 0xdeadbeef, Y: 0x12345678, X16: 0xbeef, X8: 0xef,
+			Xor: 0xcc99e896, And: 0x12241668, Rot: 0x56df77ef, RotBytes: 0xefdeadbe, Rot16: 0xf77d, Xor8: 0x97, Rot8: 0xfd,
+		},
+		&wordCircuit{
+			X: 0xdeadbeef, Y: 0x12345678, X16: 0xbeef, X8: 0xef,
+			Xor: 0xcc99e897, And: 0x12241668, Rot: 0x56df77ef, RotBytes: 0xefdeadbe, Rot16: 0xf77d, Xor8: 0x97, Rot8: 0xfe,
+		},
+		&wordCircuit{
+			X: 0xdeadbeef, Y: 0x112345678, X16: 0xbeef, X8: 0xef,
+			Xor: 0x1cc99e897, And: 0x12241668, Rot: 0x56df77ef, RotBytes: 0xefdeadbe, Rot16: 0xf77d, Xor8: 0x97, Rot8: 0xfd,
+		},
+		&wordCircuit{
+			X: 0xdeadbeef, Y: 0x12345678, X16: 0x1beef, X8: 0xef,
+			Xor: 0xcc99e897, And: 0x12241668, Rot: 0x56df77ef, RotBytes: 0xefdeadbe, Rot16: 0xf77f, Xor8: 0x97, Rot8: 0xfd,
+		},
+	}
+
+	for _, w := range good {
+		assert.ProverSucceeded(&circuit, w, WithBackends(backend.GROTH16))
+		assert.SolvingSucceeded(&circuit, w, WithBackends(backend.PLONK), WithCurves(ecc.BN254))
+	}
+	for _, w := range bad {
+		assert.ProverFailed(&circuit, w, WithBackends(backend.GROTH16))
+		assert.SolvingFailed(&circuit, w, WithBackends(backend.PLONK), WithCurves(ecc.BN254))
+	}
+}
@@ -0,0 +1,102 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/rs/zerolog"
+)
+
+// ErrSolversNotEquivalent is returned when the R1CS and SparseR1CS solvers
+// resolve the wires printed by the circuit (api.Println) to different values
+var ErrSolversNotEquivalent = errors.New("R1CS and SparseR1CS solvers are not equivalent")
+
+// solverEquivalence compiles the circuit to a R1CS and a SparseR1CS, solves
+// both with validAssignment, and compares the values printed with api.Println
+// by the two solvers.
+func (assert *Assert) solverEquivalence(circuit frontend.Circuit, validAssignment frontend.Circuit, curve ecc.ID, opt *testingConfig) {
+	validWitness, err := frontend.NewWitness(validAssignment, curve)
+	assert.NoError(err, "can't parse valid assignment")
+
+	hints, err := isSolved(circuit, validAssignment, curve, backend.UNKNOWN)
+	assert.checkError(err, backend.UNKNOWN, curve, validWitness)
+
+	backends := [2]backend.ID{backend.GROTH16, backend.PLONK}
+	var logs [2][]solverLog
+	for i, b := range backends {
+		ccs, err := assert.compile(circuit, curve, b, opt.compileOpts)
+		assert.checkError(err, b, curve, validWitness)
+
+		var buf bytes.Buffer
+		logger := zerolog.New(&buf).Level(zerolog.DebugLevel)
+		proverOpts := append(opt.proverOptsWithHints(hints), backend.WithCircuitLogger(logger))
+		err = ccs.IsSolved(validWitness, proverOpts...)
+		assert.checkError(err, b, curve, validWitness)
+
+		logs[i], err = parseSolverLogs(&buf)
+		assert.NoError(err)
+	}
+
+	var diff strings.Builder
+	for i := 0; i < len(logs[0]) || i < len(logs[1]); i++ {
+		var l0, l1 solverLog
+		if i < len(logs[0]) {
+			l0 = logs[0][i]
+		}
+		if i < len(logs[1]) {
+			l1 = logs[1][i]
+		}
+		if l0 != l1 {
+			fmt.Fprintf(&diff, "\n%s: %s %q\n%s: %s %q", backends[0], l0.Caller, l0.Message, backends[1], l1.Caller, l1.Message)
+		}
+	}
+	if diff.Len() != 0 {
+		assert.checkError(fmt.Errorf("%w:%s", ErrSolversNotEquivalent, diff.String()), backend.UNKNOWN, curve, validWitness)
+	}
+}
+
+// solverLog is a line logged by the solver for an api.Println
+type solverLog struct {
+	Caller  string `json:"caller"`
+	Message string `json:"message"`
+}
+
+// parseSolverLogs parses the JSON lines written by a zerolog.Logger
+func parseSolverLogs(buf *bytes.Buffer) ([]solverLog, error) {
+	var logs []solverLog
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var l solverLog
+		if err := json.Unmarshal(scanner.Bytes(), &l); err != nil {
+			return nil, err
+		}
+		logs = append(logs, l)
+	}
+	return logs, scanner.Err()
+}
@@ -1,3 +1,6 @@
+//go:build !verifieronly
+// +build !verifieronly
+
 package test
 
 import (
@@ -11,6 +14,7 @@ import (
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/schema"
+	"github.com/consensys/gnark/internal/utils"
 )
 
 var seedCorpus []*big.Int
@@ -112,6 +116,72 @@ func randomFiller(w frontend.Circuit, curve ecc.ID) {
 	})
 }
 
+// mutate mutates up to 3 of the inputs of w, with values derived from their
+// current values (bit flips, field overflow candidates), boundary values or
+// values of the seed corpus
+func mutate(w frontend.Circuit, curve ecc.ID, rng *mrand.Rand) {
+	var values []*big.Int
+	var collectHandler schema.LeafHandler = func(visibility schema.Visibility, name string, tInput reflect.Value) error {
+		if visibility == schema.Secret || visibility == schema.Public {
+			v := utils.FromInterface(tInput.Interface())
+			values = append(values, &v)
+		}
+		return nil
+	}
+	// this can't error.
+	_, _ = schema.Parse(w, tVariable, collectHandler)
+	if len(values) == 0 {
+		return
+	}
+
+	m := curve.Info().Fr.Modulus()
+	nbMutations := 1 + rng.Intn(3)
+	for k := 0; k < nbMutations; k++ {
+		i := rng.Intn(len(values))
+		values[i] = mutateValue(values[i], m, rng)
+	}
+
+	i := 0
+	fill(w, func() interface{} {
+		i++
+		return values[i-1]
+	})
+}
+
+// mutateValue returns a mutation of v, for the modulus m
+func mutateValue(v *big.Int, m *big.Int, rng *mrand.Rand) *big.Int {
+	r := new(big.Int)
+	switch rng.Intn(4) {
+	case 0:
+		// bit flip
+		bit := rng.Intn(m.BitLen())
+		return r.SetBit(v, bit, v.Bit(bit)^1)
+	case 1:
+		// boundary values: 0, 1, -1, 2ᵏ-1, 2ᵏ
+		switch k := rng.Intn(m.BitLen() + 1); rng.Intn(3) {
+		case 0:
+			return r.SetInt64(int64(rng.Intn(2)))
+		case 1:
+			return r.Sub(m, big.NewInt(1))
+		default:
+			r.Lsh(big.NewInt(1), uint(k))
+			return r.Sub(r, big.NewInt(int64(rng.Intn(2))))
+		}
+	case 2:
+		// field overflow candidates: v+m, v-m, m-v
+		switch rng.Intn(3) {
+		case 0:
+			return r.Add(v, m)
+		case 1:
+			return r.Sub(v, m)
+		default:
+			return r.Sub(m, v)
+		}
+	default:
+		return r.Mod(seedCorpus[rng.Intn(len(seedCorpus))], m)
+	}
+}
+
 func fill(w frontend.Circuit, nextValue func() interface{}) {
 	var setHandler schema.LeafHandler = func(visibility schema.Visibility, name string, tInput reflect.Value) error {
 		if visibility == schema.Secret || visibility == schema.Public {
@@ -0,0 +1,48 @@
+//go:build go1.18 && !verifieronly
+// +build go1.18,!verifieronly
+
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/require"
+)
+
+// FuzzCircuit runs Assert.FuzzWitnessSeed as a native Go fuzz target, the
+// fuzzing engine providing the seeds of the mutations of seedAssignment.
+// For example
+//
+//	func FuzzMyCircuit(f *testing.F) {
+//		test.FuzzCircuit(f, &myCircuit{}, &myCircuit{X: 3, Y: 27}, test.WithCurves(ecc.BN254))
+//	}
+//
+// then run with go test -fuzz=FuzzMyCircuit. A failing seed is saved by go test
+// in testdata/fuzz, and replayed by subsequent go test runs.
+func FuzzCircuit(f *testing.F, circuit, seedAssignment frontend.Circuit, opts ...TestingOption) {
+	// the compiled circuits are cached across the calls to the fuzz function
+	compiled := make(map[string]frontend.CompiledConstraintSystem)
+
+	f.Add(int64(0))
+	f.Fuzz(func(t *testing.T, seed int64) {
+		assert := &Assert{t, require.New(t), compiled}
+		assert.FuzzWitnessSeed(circuit, seedAssignment, 1, seed, opts...)
+	})
+}
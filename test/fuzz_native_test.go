@@ -0,0 +1,14 @@
+//go:build go1.18
+// +build go1.18
+
+package test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+func FuzzCircuit_namespace(f *testing.F) {
+	FuzzCircuit(f, &namespaceCircuit{}, &namespaceCircuit{X: 3, Y: 27}, WithCurves(ecc.BN254))
+}
@@ -1,3 +1,6 @@
+//go:build !verifieronly
+// +build !verifieronly
+
 /*
 Copyright © 2021 ConsenSys Software Inc.
 
@@ -17,19 +20,12 @@ limitations under the License.
 package test
 
 import (
-	"crypto/rand"
 	"sync"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend/plonk"
 	"github.com/consensys/gnark/frontend"
-
-	kzg_bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/fr/kzg"
-	kzg_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr/kzg"
-	kzg_bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/fr/kzg"
-	kzg_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
-	kzg_bw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/fr/kzg"
-	kzg_bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/fr/kzg"
 )
 
 const srsCachedSize = (1 << 14) + 3
@@ -40,16 +36,13 @@ const srsCachedSize = (1 << 14) + 3
 // /!\ warning /!\: this method is here for convenience only: in production, a SRS generated through MPC should be used.
 func NewKZGSRS(ccs frontend.CompiledConstraintSystem) (kzg.SRS, error) {
 
-	nbConstraints := ccs.GetNbConstraints()
-	_, _, public := ccs.GetNbVariables()
-	sizeSystem := nbConstraints + public
-	kzgSize := ecc.NextPowerOfTwo(uint64(sizeSystem)) + 3
+	kzgSize := plonk.SRSSize(ccs)
 
 	if kzgSize <= srsCachedSize {
 		return getCachedSRS(ccs)
 	}
 
-	return newKZGSRS(ccs.CurveID(), kzgSize)
+	return plonk.NewSRS(ccs.CurveID(), kzgSize, nil)
 
 }
 
@@ -67,35 +60,10 @@ func getCachedSRS(ccs frontend.CompiledConstraintSystem) (kzg.SRS, error) {
 		return srs, nil
 	}
 
-	srs, err := newKZGSRS(ccs.CurveID(), srsCachedSize)
+	srs, err := plonk.NewSRS(ccs.CurveID(), srsCachedSize, nil)
 	if err != nil {
 		return nil, err
 	}
 	srsCache[ccs.CurveID()] = srs
 	return srs, nil
 }
-
-func newKZGSRS(curve ecc.ID, kzgSize uint64) (kzg.SRS, error) {
-
-	alpha, err := rand.Int(rand.Reader, curve.Info().Fr.Modulus())
-	if err != nil {
-		return nil, err
-	}
-
-	switch curve {
-	case ecc.BN254:
-		return kzg_bn254.NewSRS(kzgSize, alpha)
-	case ecc.BLS12_381:
-		return kzg_bls12381.NewSRS(kzgSize, alpha)
-	case ecc.BLS12_377:
-		return kzg_bls12377.NewSRS(kzgSize, alpha)
-	case ecc.BW6_761:
-		return kzg_bw6761.NewSRS(kzgSize, alpha)
-	case ecc.BLS24_315:
-		return kzg_bls24315.NewSRS(kzgSize, alpha)
-	case ecc.BW6_633:
-		return kzg_bw6633.NewSRS(kzgSize, alpha)
-	default:
-		panic("unrecognized R1CS curve type")
-	}
-}
@@ -1,3 +1,6 @@
+//go:build !verifieronly
+// +build !verifieronly
+
 /*
 Copyright © 2021 ConsenSys Software Inc.
 
@@ -19,6 +22,7 @@ package test
 import (
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/hint"
 	"github.com/consensys/gnark/frontend"
 )
 
@@ -31,6 +35,8 @@ type testingConfig struct {
 	backends             []backend.ID
 	curves               []ecc.ID
 	witnessSerialization bool
+	solverEquivalence    bool
+	solidity             bool
 	proverOpts           []backend.ProverOption
 	compileOpts          []frontend.CompileOption
 }
@@ -82,3 +88,48 @@ func WithCompileOpts(compileOpts ...frontend.CompileOption) TestingOption {
 		return nil
 	}
 }
+
+// WithSolverEquivalence is a testing option which, in ProverSucceeded and
+// SolvingSucceeded, solves the valid witness with both the R1CS and the
+// SparseR1CS of the circuit, and checks that the values of the wires printed
+// with api.Println are the same.
+//
+// This catches gadgets which behave differently depending on the constraint
+// system, for example because of how linear expressions are split in PLONK.
+func WithSolverEquivalence() TestingOption {
+	return func(opt *testingConfig) error {
+		opt.solverEquivalence = true
+		return nil
+	}
+}
+
+// WithSolidity is a testing option which, in ProverSucceeded, exports the
+// Solidity verifier of the verifying key, and runs the verification of the
+// proof in the EVM, to check the serialization of the proof and the order of
+// the public inputs expected by the contract. Only BN254 is supported, other
+// curves are not checked.
+//
+// The contract is compiled with solc and run in the EVM of go-ethereum,
+// embedded. As go-ethereum is licensed under the LGPL-3.0, the EVM is only
+// linked in the tests built with the solidity tag:
+//
+//	go test -tags solidity ./...
+//
+// Without the tag, or if solc isn't in the PATH, the check is skipped.
+func WithSolidity() TestingOption {
+	return func(opt *testingConfig) error {
+		opt.solidity = true
+		return nil
+	}
+}
+
+// proverOptsWithHints returns the prover options of the config, with the given
+// hints, executed by the test engine (see isSolved). The returned slice can be
+// appended to.
+func (opt *testingConfig) proverOptsWithHints(hints []hint.Function) []backend.ProverOption {
+	res := make([]backend.ProverOption, 0, len(opt.proverOpts)+2)
+	if len(hints) != 0 {
+		res = append(res, backend.WithHints(hints...))
+	}
+	return append(res, opt.proverOpts...)
+}
@@ -0,0 +1,183 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/solidity"
+	"github.com/consensys/gnark/backend/witness"
+	groth16_bn254 "github.com/consensys/gnark/internal/backend/bn254/groth16"
+	plonk_bn254 "github.com/consensys/gnark/internal/backend/bn254/plonk"
+	witness_bn254 "github.com/consensys/gnark/internal/backend/bn254/witness"
+	"golang.org/x/crypto/sha3"
+)
+
+// ErrSolidityVerification is returned when the Solidity verifier exported from
+// the verifying key rejects a proof
+var ErrSolidityVerification = errors.New("the Solidity verifier rejected the proof")
+
+// solidityVerification runs the verification of the proof by the Solidity
+// verifier exported from vk (see WithSolidity) as a subtest. The subtest is
+// skipped if the tests aren't built with the solidity tag, or if solc is not
+// installed.
+func (assert *Assert) solidityVerification(b backend.ID, vk, proof interface{}, publicWitness *witness.Witness) {
+	assert.Run(func(assert *Assert) {
+		if !evmAvailable {
+			assert.t.Skip("WithSolidity needs the solidity build tag to run the Solidity verifier in the EVM")
+		}
+		if _, err := exec.LookPath("solc"); err != nil {
+			assert.t.Skip("WithSolidity needs solc in the PATH to compile the Solidity verifier")
+		}
+		assert.checkError(verifySolidity(b, vk, proof, publicWitness), b, ecc.BN254, publicWitness)
+	}, "solidity")
+}
+
+// verifySolidity exports the Solidity verifier of vk, compiles it with
+// solc, and runs its verifyProof function on the proof and the public witness
+// in the EVM of go-ethereum, embedded. Only BN254 proofs are supported.
+func verifySolidity(b backend.ID, vk, proof interface{}, publicWitness *witness.Witness) error {
+	dir, err := os.MkdirTemp("", "gnark-solidity")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	type exporter interface {
+		ExportSolidity(w io.Writer, exportOpts ...solidity.ExportOption) error
+	}
+	var (
+		contract string
+		input    []byte
+	)
+	public := *publicWitness.Vector.(*witness_bn254.Witness)
+	switch b {
+	case backend.GROTH16:
+		contract = "Verifier"
+		input = groth16Calldata(proof.(*groth16_bn254.Proof), public)
+	case backend.PLONK:
+		contract = "PlonkVerifier"
+		input = plonkCalldata(proof.(*plonk_bn254.Proof), public)
+	default:
+		panic("backend not implemented")
+	}
+
+	var sol bytes.Buffer
+	if err := vk.(exporter).ExportSolidity(&sol); err != nil {
+		return err
+	}
+	solFile := filepath.Join(dir, contract+".sol")
+	if err := os.WriteFile(solFile, sol.Bytes(), 0600); err != nil {
+		return err
+	}
+
+	// compile the contract: as verifyProof is a view function without
+	// constructor arguments, the runtime bytecode is enough
+	if out, err := exec.Command("solc", "--optimize", "--bin-runtime", "--overwrite", "-o", dir, solFile).CombinedOutput(); err != nil { //#nosec G204 the arguments are ours
+		return fmt.Errorf("solc: %w\n%s", err, out)
+	}
+	code, err := os.ReadFile(filepath.Join(dir, contract+".bin-runtime"))
+	if err != nil {
+		return err
+	}
+	code, err = hex.DecodeString(strings.TrimSpace(string(code)))
+	if err != nil {
+		return fmt.Errorf("solc: invalid bytecode: %w", err)
+	}
+
+	return evmVerify(code, input)
+}
+
+// groth16Calldata returns the ABI encoded call to
+//
+//	verifyProof(uint256[2] a, uint256[2][2] b, uint256[2] c, uint256[n] input)
+//
+// the coordinates in Fp² being encoded imaginary part first
+func groth16Calldata(proof *groth16_bn254.Proof, public []fr.Element) []byte {
+	signature := "verifyProof(uint256[2],uint256[2][2],uint256[2]"
+	if len(public) != 0 {
+		signature += fmt.Sprintf(",uint256[%d]", len(public))
+	}
+	signature += ")"
+
+	res := selector(signature)
+	for _, e := range []interface{ Bytes() [32]byte }{
+		&proof.Ar.X, &proof.Ar.Y,
+		&proof.Bs.X.A1, &proof.Bs.X.A0, &proof.Bs.Y.A1, &proof.Bs.Y.A0,
+		&proof.Krs.X, &proof.Krs.Y,
+	} {
+		b := e.Bytes()
+		res = append(res, b[:]...)
+	}
+	for i := range public {
+		b := public[i].Bytes()
+		res = append(res, b[:]...)
+	}
+	return res
+}
+
+// plonkCalldata returns the ABI encoded call to
+//
+//	verifyProof(bytes proofBytes, uint256[] input)
+//
+// with the proof serialized by MarshalSolidity
+func plonkCalldata(proof *plonk_bn254.Proof, public []fr.Element) []byte {
+	proofBytes := proof.MarshalSolidity()
+	padded := (len(proofBytes) + 31) / 32 * 32
+
+	res := selector("verifyProof(bytes,uint256[])")
+	res = append(res, word(0x40)...)
+	res = append(res, word(uint64(0x40+32+padded))...)
+	res = append(res, word(uint64(len(proofBytes)))...)
+	res = append(res, proofBytes...)
+	res = append(res, make([]byte, padded-len(proofBytes))...)
+	res = append(res, word(uint64(len(public)))...)
+	for i := range public {
+		b := public[i].Bytes()
+		res = append(res, b[:]...)
+	}
+	return res
+}
+
+// selector returns the first 4 bytes of the keccak256 of the signature of a function
+func selector(signature string) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(signature))
+	return h.Sum(nil)[:4]
+}
+
+// word returns the ABI encoding of v, a 32 bytes big endian integer
+func word(v uint64) []byte {
+	var res [32]byte
+	binary.BigEndian.PutUint64(res[24:], v)
+	return res[:]
+}
@@ -0,0 +1,49 @@
+//go:build solidity && !verifieronly
+// +build solidity,!verifieronly
+
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+)
+
+// evmAvailable tells whether evmVerify runs the verifiers. The EVM of
+// go-ethereum (LGPL-3.0) is only linked in the tests built with the solidity
+// tag.
+const evmAvailable = true
+
+// evmVerify runs the call input on the runtime bytecode code of a verifier in
+// the EVM, and returns ErrSolidityVerification if it reverts or doesn't return
+// true. The EVM is configured with the latest forks, so that the precompiled
+// contracts of BN254 are available.
+func evmVerify(code, input []byte) error {
+	ret, _, err := runtime.Execute(code, input, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSolidityVerification, err)
+	}
+
+	// ret is the ABI encoded bool returned by verifyProof
+	if len(ret) != 32 || new(big.Int).SetBytes(ret).Cmp(big.NewInt(1)) != 0 {
+		return fmt.Errorf("%w: returned %x", ErrSolidityVerification, ret)
+	}
+	return nil
+}
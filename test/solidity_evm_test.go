@@ -0,0 +1,27 @@
+//go:build solidity && !verifieronly
+// +build solidity,!verifieronly
+
+package test
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestEVMVerify(t *testing.T) {
+	assert := NewAssert(t)
+
+	code := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		assert.NoError(err)
+		return b
+	}
+
+	// returns the result of the BN254 pairing precompile (0x08) on no pairs,
+	// true if the precompiles are enabled
+	assert.NoError(evmVerify(code("60206000600060006008"+"5afa50"+"60206000f3"), nil))
+
+	// returns 2, or reverts
+	assert.ErrorIs(evmVerify(code("6002600052"+"60206000f3"), nil), ErrSolidityVerification)
+	assert.ErrorIs(evmVerify(code("60006000fd"), nil), ErrSolidityVerification)
+}
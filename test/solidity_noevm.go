@@ -0,0 +1,32 @@
+//go:build !solidity && !verifieronly
+// +build !solidity,!verifieronly
+
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import "errors"
+
+// evmAvailable tells whether evmVerify runs the verifiers. The EVM of
+// go-ethereum (LGPL-3.0) is only linked in the tests built with the solidity
+// tag.
+const evmAvailable = false
+
+// evmVerify is not available without the solidity build tag
+func evmVerify(code, input []byte) error {
+	return errors.New("the EVM needs the solidity build tag")
+}
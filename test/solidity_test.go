@@ -0,0 +1,38 @@
+package test
+
+import (
+	"encoding/hex"
+	"os/exec"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	plonk_bn254 "github.com/consensys/gnark/internal/backend/bn254/plonk"
+)
+
+func TestSolidityCalldata(t *testing.T) {
+	assert := NewAssert(t)
+
+	// the selector of the ERC20 transfer
+	assert.Equal("a9059cbb", hex.EncodeToString(selector("transfer(address,uint256)")))
+
+	public := make([]fr.Element, 2)
+	public[0].SetUint64(3)
+	public[1].SetUint64(27)
+	var proof plonk_bn254.Proof
+	proof.BatchedProof.ClaimedValues = make([]fr.Element, 7)
+	calldata := plonkCalldata(&proof, public)
+	// selector | offsets | proof length | proof | input length | input
+	assert.Equal(4+2*32+32+832+32+2*32, len(calldata))
+	assert.Equal(word(0x40), calldata[4:36])
+	assert.Equal(word(0x40+32+832), calldata[36:68])
+	assert.Equal(word(832), calldata[68:100])
+	assert.Equal(word(2), calldata[100+832:132+832])
+	assert.Equal(word(27), calldata[len(calldata)-32:])
+
+	// WithSolidity is skipped without solc
+	if _, err := exec.LookPath("solc"); err != nil {
+		t.Skip("solc is not installed")
+	}
+	assert.ProverSucceeded(&namespaceCircuit{}, &namespaceCircuit{X: 3, Y: 27}, WithCurves(ecc.BN254), WithSolidity())
+}
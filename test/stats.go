@@ -0,0 +1,181 @@
+//go:build !verifieronly
+// +build !verifieronly
+
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+)
+
+// TotalConstraints fails the test if the number of constraints of the compiled
+// circuit is not within tolerance of expected (|nbConstraints - expected| ⩽ tolerance).
+//
+// As the number of constraints depends on the backend (and to a lesser
+// extent on the curve), this is typically used with WithBackends and WithCurves.
+func (assert *Assert) TotalConstraints(circuit frontend.Circuit, expected, tolerance int, opts ...TestingOption) {
+	opt := assert.options(opts...)
+
+	for _, curve := range opt.curves {
+		for _, b := range opt.backends {
+			curve := curve
+			b := b
+			assert.Run(func(assert *Assert) {
+				ccs, err := assert.compile(circuit, curve, b, opt.compileOpts)
+				assert.NoError(err)
+				nbConstraints := ccs.GetNbConstraints()
+				assert.InDelta(expected, nbConstraints, float64(tolerance),
+					"%s(%s): %d constraints, expected %d±%d", b.String(), curve.String(), nbConstraints, expected, tolerance)
+			}, curve.String(), b.String())
+		}
+	}
+}
+
+// KeySizes fails the test if the size in bytes of the serialized proving key
+// (resp. verifying key) of the compiled circuit exceeds maxProvingKey (resp.
+// maxVerifyingKey). A negative bound is not checked.
+func (assert *Assert) KeySizes(circuit frontend.Circuit, maxProvingKey, maxVerifyingKey int64, opts ...TestingOption) {
+	opt := assert.options(opts...)
+
+	for _, curve := range opt.curves {
+		for _, b := range opt.backends {
+			curve := curve
+			b := b
+			assert.Run(func(assert *Assert) {
+				ccs, err := assert.compile(circuit, curve, b, opt.compileOpts)
+				assert.NoError(err)
+				pk, vk, err := setup(ccs, b)
+				assert.NoError(err)
+
+				pkSize, err := pk.WriteTo(io.Discard)
+				assert.NoError(err)
+				vkSize, err := vk.WriteTo(io.Discard)
+				assert.NoError(err)
+				if maxProvingKey >= 0 {
+					assert.LessOrEqual(pkSize, maxProvingKey, "%s(%s): proving key size", b.String(), curve.String())
+				}
+				if maxVerifyingKey >= 0 {
+					assert.LessOrEqual(vkSize, maxVerifyingKey, "%s(%s): verifying key size", b.String(), curve.String())
+				}
+			}, curve.String(), b.String())
+		}
+	}
+}
+
+// BenchmarkResult holds the measures of Assert.Benchmark for a curve and a backend
+type BenchmarkResult struct {
+	Curve   ecc.ID
+	Backend backend.ID
+
+	NbConstraints int
+
+	// ProvingKeySize and VerifyingKeySize are the sizes in bytes of the serialized keys
+	ProvingKeySize, VerifyingKeySize int64
+
+	CompileTime, SetupTime, ProveTime time.Duration
+}
+
+// String returns a one line, human readable representation of r
+func (r BenchmarkResult) String() string {
+	return fmt.Sprintf("%s(%s): %d constraints, pk %d bytes, vk %d bytes, compile %s, setup %s, prove %s",
+		r.Backend.String(), r.Curve.String(), r.NbConstraints, r.ProvingKeySize, r.VerifyingKeySize,
+		r.CompileTime, r.SetupTime, r.ProveTime)
+}
+
+// Benchmark compiles the circuit, runs the setup and proves validAssignment for
+// each curve and backend, logs and returns the number of constraints, the size
+// of the keys and the time spent in each step.
+//
+// Unlike the other assertions, the compilation is not cached, and the circuit
+// is compiled once.
+func (assert *Assert) Benchmark(circuit frontend.Circuit, validAssignment frontend.Circuit, opts ...TestingOption) []BenchmarkResult {
+	opt := assert.options(opts...)
+
+	var results []BenchmarkResult
+
+	for _, curve := range opt.curves {
+		validWitness, err := frontend.NewWitness(validAssignment, curve)
+		assert.NoError(err, "can't parse valid assignment")
+
+		for _, b := range opt.backends {
+			curve := curve
+			b := b
+			assert.Run(func(assert *Assert) {
+				checkError := func(err error) { assert.checkError(err, b, curve, validWitness) }
+				r := BenchmarkResult{Curve: curve, Backend: b}
+
+				start := time.Now()
+				ccs, err := frontend.Compile(curve, newBuilder(b), circuit, opt.compileOpts...)
+				r.CompileTime = time.Since(start)
+				checkError(err)
+				r.NbConstraints = ccs.GetNbConstraints()
+
+				start = time.Now()
+				pk, vk, err := setup(ccs, b)
+				r.SetupTime = time.Since(start)
+				checkError(err)
+				r.ProvingKeySize, err = pk.WriteTo(io.Discard)
+				checkError(err)
+				r.VerifyingKeySize, err = vk.WriteTo(io.Discard)
+				checkError(err)
+
+				start = time.Now()
+				switch b {
+				case backend.GROTH16:
+					_, err = groth16.Prove(ccs, pk.(groth16.ProvingKey), validWitness, opt.proverOpts...)
+				case backend.PLONK:
+					_, err = plonk.Prove(ccs, pk.(plonk.ProvingKey), validWitness, opt.proverOpts...)
+				default:
+					panic("backend not implemented")
+				}
+				r.ProveTime = time.Since(start)
+				checkError(err)
+
+				assert.Log(r.String())
+				results = append(results, r)
+			}, curve.String(), b.String())
+		}
+	}
+
+	return results
+}
+
+// setup runs the setup of the backend b for ccs; for PLONK, with an unsafe
+// SRS from NewKZGSRS
+func setup(ccs frontend.CompiledConstraintSystem, b backend.ID) (pk, vk io.WriterTo, err error) {
+	switch b {
+	case backend.GROTH16:
+		return groth16.Setup(ccs)
+	case backend.PLONK:
+		srs, err := NewKZGSRS(ccs)
+		if err != nil {
+			return nil, nil, err
+		}
+		return plonk.Setup(ccs, srs)
+	default:
+		panic("backend not implemented")
+	}
+}
@@ -0,0 +1,24 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+)
+
+func TestTotalConstraints(t *testing.T) {
+	assert := NewAssert(t)
+
+	assert.TotalConstraints(&namespaceCircuit{}, 3, 0, WithBackends(backend.GROTH16), WithCurves(ecc.BN254))
+	assert.TotalConstraints(&namespaceCircuit{}, 3, 1, WithBackends(backend.PLONK), WithCurves(ecc.BN254))
+	assert.KeySizes(&namespaceCircuit{}, 1<<12, 1<<12, WithCurves(ecc.BN254))
+
+	results := assert.Benchmark(&namespaceCircuit{}, &namespaceCircuit{X: 3, Y: 27}, WithCurves(ecc.BN254))
+	assert.Equal(len(backend.Implemented()), len(results))
+	for _, r := range results {
+		assert.Equal(ecc.BN254, r.Curve)
+		assert.NotZero(r.NbConstraints)
+		assert.NotZero(r.ProvingKeySize)
+	}
+}